@@ -0,0 +1,29 @@
+// Package api holds the OpenAPI 3 description of the rackd REST API.
+// openapi.yaml is maintained by hand next to the handlers and embedded in
+// the binary, so the running server always describes itself.
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// YAML returns the spec as written
+func YAML() []byte {
+	return specYAML
+}
+
+// JSON returns the spec converted to JSON. It is converted once, on first
+// use.
+var JSON = sync.OnceValues(func() ([]byte, error) {
+	doc, err := decodeYAML(specYAML)
+	if err != nil {
+		return nil, fmt.Errorf("openapi.yaml: %w", err)
+	}
+	return json.Marshal(doc)
+})
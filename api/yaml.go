@@ -0,0 +1,514 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// decodeYAML decodes the block-style YAML the spec is written in: nested
+// mappings and sequences, single-line flow collections, plain and quoted
+// scalars, literal and folded block scalars, and comments. Anchors, tags,
+// multi-line flow collections and multi-document streams are not supported
+// and are reported as errors rather than misread.
+func decodeYAML(data []byte) (any, error) {
+	p := &yamlParser{lines: strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")}
+	indent, _, ok, err := p.peek()
+	if err != nil || !ok {
+		return nil, err
+	}
+	v, err := p.parseNode(indent)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, ok, err := p.peek(); err != nil || ok {
+		return nil, p.errorOr(err, "unexpected content")
+	}
+	return v, nil
+}
+
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("yaml: line %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *yamlParser) errorOr(err error, message string) error {
+	if err != nil {
+		return err
+	}
+	return p.errorf("%s", message)
+}
+
+// peek moves to the next line with content and returns its indent and its
+// text without comments, leaving it unconsumed
+func (p *yamlParser) peek() (int, string, bool, error) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		text := strings.TrimSpace(stripComment(line))
+		if text == "" {
+			continue
+		}
+		if text == "---" || text == "..." {
+			return 0, "", false, p.errorf("multiple documents are not supported")
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if line[indent] == '\t' {
+			return 0, "", false, p.errorf("tabs are not allowed in indentation")
+		}
+		return indent, text, true, nil
+	}
+	return 0, "", false, nil
+}
+
+func (p *yamlParser) parseNode(indent int) (any, error) {
+	_, text, _, _ := p.peek()
+	if isSeqItem(text) {
+		return p.parseSeq(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func (p *yamlParser) parseMap(indent int) (any, error) {
+	m := make(map[string]any)
+	for {
+		ind, text, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || ind < indent || (ind == indent && isSeqItem(text)) {
+			return m, nil
+		}
+		if ind > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		key, rest, ok := splitKey(text)
+		if !ok {
+			return nil, p.errorf("expected a mapping key")
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+		if m[key], err = p.parseValue(indent, rest); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *yamlParser) parseSeq(indent int) (any, error) {
+	s := []any{}
+	for {
+		ind, text, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || ind < indent || (ind == indent && !isSeqItem(text)) {
+			return s, nil
+		}
+		if ind > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		rest := strings.TrimLeft(text[1:], " ")
+		var v any
+		switch {
+		case rest == "":
+			p.pos++
+			next, _, ok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			if ok && next > indent {
+				v, err = p.parseNode(next)
+			}
+			if err != nil {
+				return nil, err
+			}
+		case isMapEntry(rest):
+			// "- key: value" opens a mapping whose keys line up with "key";
+			// blank out the dash and read it as one
+			line := []byte(p.lines[p.pos])
+			line[indent] = ' '
+			p.lines[p.pos] = string(line)
+			v, err = p.parseMap(len(line) - len(strings.TrimLeft(string(line), " ")))
+			if err != nil {
+				return nil, err
+			}
+		default:
+			p.pos++
+			if v, err = p.parseValue(indent, rest); err != nil {
+				return nil, err
+			}
+		}
+		s = append(s, v)
+	}
+}
+
+// parseValue reads the value of a key or sequence item whose line is already
+// consumed: rest is the text after the key, which may be empty when the value
+// is a nested block
+func (p *yamlParser) parseValue(indent int, rest string) (any, error) {
+	switch {
+	case rest == "":
+		next, text, ok, err := p.peek()
+		if err != nil || !ok {
+			return nil, err
+		}
+		// A sequence may sit at the same indent as its key
+		if next > indent || (next == indent && isSeqItem(text)) {
+			return p.parseNode(next)
+		}
+		return nil, nil
+	case rest[0] == '|' || rest[0] == '>':
+		return p.parseBlockScalar(indent, rest)
+	case rest[0] == '&' || rest[0] == '*' || rest[0] == '!':
+		return nil, p.errorf("anchors, aliases and tags are not supported")
+	}
+	v, err := parseInline(rest)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	return v, nil
+}
+
+func (p *yamlParser) parseBlockScalar(indent int, header string) (any, error) {
+	chomp := ""
+	if len(header) > 1 {
+		chomp = header[1:]
+	}
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return nil, p.errorf("unsupported block scalar header %q", header)
+	}
+
+	var lines []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		ind := len(line) - len(strings.TrimLeft(line, " "))
+		if blockIndent < 0 {
+			blockIndent = ind
+		}
+		if ind <= indent || ind < blockIndent {
+			break
+		}
+		lines = append(lines, line[blockIndent:])
+	}
+
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			switch {
+			case header[0] == '|' || line == "":
+				b.WriteByte('\n')
+			case prev == "":
+				// the empty line already wrote the break
+			case line[0] == ' ' || prev[0] == ' ':
+				// more-indented lines keep their breaks when folded
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(line)
+	}
+	switch chomp {
+	case "":
+		b.WriteByte('\n')
+	case "+":
+		b.WriteString(strings.Repeat("\n", trailing+1))
+	}
+	return b.String(), nil
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isMapEntry(text string) bool {
+	if text[0] == '{' || text[0] == '[' {
+		return false
+	}
+	_, _, ok := splitKey(text)
+	return ok
+}
+
+// splitKey splits "key: value" into its key and the value text
+func splitKey(text string) (string, string, bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		key, n, err := parseQuoted(text)
+		if err != nil || n >= len(text) || text[n] != ':' {
+			return "", "", false
+		}
+		if n+1 < len(text) && text[n+1] != ' ' {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(text[n+1:]), true
+	}
+	if key, ok := strings.CutSuffix(text, ":"); ok && !strings.Contains(key, ": ") {
+		return key, "", true
+	}
+	key, rest, ok := strings.Cut(text, ": ")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(rest), true
+}
+
+// stripComment removes a trailing comment. A # starts a comment at the start
+// of the line or after whitespace, outside quoted scalars.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" :[{,-", line[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseInline parses a value written on one line: a flow collection, a
+// quoted scalar or a plain scalar
+func parseInline(text string) (any, error) {
+	if text[0] == '{' || text[0] == '[' || text[0] == '"' || text[0] == '\'' {
+		f := &flowParser{s: text}
+		v, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		if f.skipSpaces(); f.i < len(f.s) {
+			return nil, fmt.Errorf("unexpected %q after value", f.s[f.i:])
+		}
+		return v, nil
+	}
+	return resolvePlain(text), nil
+}
+
+type flowParser struct {
+	s string
+	i int
+}
+
+func (f *flowParser) skipSpaces() {
+	for f.i < len(f.s) && f.s[f.i] == ' ' {
+		f.i++
+	}
+}
+
+func (f *flowParser) value() (any, error) {
+	f.skipSpaces()
+	if f.i >= len(f.s) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+	switch f.s[f.i] {
+	case '{':
+		return f.mapping()
+	case '[':
+		return f.sequence()
+	case '"', '\'':
+		v, n, err := parseQuoted(f.s[f.i:])
+		f.i += n
+		return v, err
+	}
+	return resolvePlain(f.plain(",]}")), nil
+}
+
+// plain reads a plain scalar up to one of the stop characters
+func (f *flowParser) plain(stop string) string {
+	start := f.i
+	for f.i < len(f.s) && strings.IndexByte(stop, f.s[f.i]) < 0 {
+		f.i++
+	}
+	return strings.TrimSpace(f.s[start:f.i])
+}
+
+func (f *flowParser) mapping() (any, error) {
+	m := make(map[string]any)
+	f.i++
+	for {
+		f.skipSpaces()
+		if f.i < len(f.s) && f.s[f.i] == '}' {
+			f.i++
+			return m, nil
+		}
+		var key string
+		if f.i < len(f.s) && (f.s[f.i] == '"' || f.s[f.i] == '\'') {
+			k, n, err := parseQuoted(f.s[f.i:])
+			if err != nil {
+				return nil, err
+			}
+			key = k
+			f.i += n
+			f.skipSpaces()
+		} else {
+			key = f.plain(":,}")
+		}
+		// A key without ':' has a null value
+		if f.i < len(f.s) && f.s[f.i] == ':' {
+			f.i++
+			v, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		} else {
+			m[key] = nil
+		}
+		if err := f.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f *flowParser) sequence() (any, error) {
+	s := []any{}
+	f.i++
+	for {
+		f.skipSpaces()
+		if f.i < len(f.s) && f.s[f.i] == ']' {
+			f.i++
+			return s, nil
+		}
+		v, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+		if err := f.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator consumes the comma between flow entries, leaving the closing
+// bracket for the caller
+func (f *flowParser) separator(end byte) error {
+	f.skipSpaces()
+	switch {
+	case f.i >= len(f.s):
+		return fmt.Errorf("unterminated flow collection")
+	case f.s[f.i] == ',':
+		f.i++
+	case f.s[f.i] != end:
+		return fmt.Errorf("unexpected %q in flow collection", f.s[f.i])
+	}
+	return nil
+}
+
+// parseQuoted parses the quoted scalar at the start of s and returns it with
+// the number of bytes it took
+func parseQuoted(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '\'' && c == '\'':
+			if i+1 < len(s) && s[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			return b.String(), i + 1, nil
+		case quote == '"' && c == '"':
+			return b.String(), i + 1, nil
+		case quote == '"' && c == '\\':
+			n, err := unescape(&b, s[i:])
+			if err != nil {
+				return "", 0, err
+			}
+			i += n - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted scalar")
+}
+
+// unescape writes the escape sequence at the start of s and returns its length
+func unescape(b *strings.Builder, s string) (int, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("unterminated escape sequence")
+	}
+	switch s[1] {
+	case '"', '\\', '/', ' ':
+		b.WriteByte(s[1])
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case '0':
+		b.WriteByte(0)
+	case 'u':
+		if len(s) < 6 {
+			return 0, fmt.Errorf("invalid escape %q", s)
+		}
+		r, err := strconv.ParseUint(s[2:6], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid escape %q", s[:6])
+		}
+		b.WriteRune(rune(r))
+		return 6, nil
+	default:
+		return 0, fmt.Errorf("unsupported escape \\%c", s[1])
+	}
+	return 2, nil
+}
+
+var (
+	yamlInt   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// resolvePlain types a plain scalar using the YAML 1.2 core schema
+func resolvePlain(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if yamlInt.MatchString(s) {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	}
+	if yamlFloat.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
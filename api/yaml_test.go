@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	input := `# leading comment
+openapi: 3.1.0
+info:
+  title: Rackd API # trailing comment
+  version: 1.0.0
+count: 42
+ratio: 0.5
+enabled: true
+nothing: ~
+quoted: "One of: a, b # not a comment"
+single: 'it''s'
+owner: Device's name
+tags: [Devices, 'Bulk Operations']
+ref: { $ref: '#/components/schemas/Error', nullable: true }
+list:
+  - plain
+  - name: id
+    in: path
+  -
+    nested: value
+same_indent:
+- a
+- b
+literal: |
+  line one
+    indented
+
+  line three
+folded: >-
+  folded
+  text
+
+  next paragraph
+after: done
+`
+	want := map[string]any{
+		"openapi": "3.1.0",
+		"info":    map[string]any{"title": "Rackd API", "version": "1.0.0"},
+		"count":   int64(42),
+		"ratio":   0.5,
+		"enabled": true,
+		"nothing": nil,
+		"quoted":  "One of: a, b # not a comment",
+		"single":  "it's",
+		"owner":   "Device's name",
+		"tags":    []any{"Devices", "Bulk Operations"},
+		"ref":     map[string]any{"$ref": "#/components/schemas/Error", "nullable": true},
+		"list": []any{
+			"plain",
+			map[string]any{"name": "id", "in": "path"},
+			map[string]any{"nested": "value"},
+		},
+		"same_indent": []any{"a", "b"},
+		"literal":     "line one\n  indented\n\nline three\n",
+		"folded":      "folded text\nnext paragraph",
+		"after":       "done",
+	}
+
+	got, err := decodeYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("decodeYAML failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		t.Errorf("unexpected result:\n%s", gotJSON)
+	}
+}
+
+func TestDecodeYAML_Errors(t *testing.T) {
+	for name, input := range map[string]string{
+		"bad indent":    "a: 1\n   b: 2\n",
+		"duplicate key": "a: 1\na: 2\n",
+		"anchor":        "a: &x 1\n",
+		"unterminated":  "a: 'open\n",
+		"flow":          "a: [1, 2\n",
+		"documents":     "a: 1\n---\nb: 2\n",
+		"not a mapping": "a: 1\njust text\n",
+	} {
+		if _, err := decodeYAML([]byte(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+	if spec.OpenAPI == "" || len(spec.Paths) == 0 {
+		t.Fatalf("expected openapi version and paths, got %q and %d paths", spec.OpenAPI, len(spec.Paths))
+	}
+	if _, ok := spec.Paths["/api/devices/{id}"]["put"]; !ok {
+		t.Error("expected PUT /api/devices/{id} in spec")
+	}
+}
@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/agent"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/cli"
+)
+
+func Command(version string) *cli.Command {
+	return &cli.Command{
+		Name:  "agent",
+		Usage: "Run or manage remote scanning agents",
+		Commands: []*cli.Command{
+			RunCommand(version),
+			ListCommand(),
+			RegisterCommand(),
+			UpdateCommand(),
+			RotateTokenCommand(),
+			DeleteCommand(),
+		},
+	}
+}
+
+func RunCommand(version string) *cli.Command {
+	return &cli.Command{
+		Name:  "run",
+		Usage: "Run the agent, scanning assigned networks and reporting to the server",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "server", Usage: "Central server URL", EnvVars: []string{"RACKD_AGENT_SERVER"}, Required: true},
+			&cli.StringFlag{Name: "token", Usage: "Agent token", EnvVars: []string{"RACKD_AGENT_TOKEN"}, Required: true},
+			&cli.StringFlag{Name: "heartbeat-interval", Usage: "Heartbeat interval", DefaultValue: "1m"},
+			&cli.StringFlag{Name: "scan-interval", Usage: "Scan interval for networks without a discovery rule", DefaultValue: "24h"},
+			&cli.StringFlag{Name: "scan-timeout", Usage: "Per-host scan timeout", DefaultValue: "30s"},
+			&cli.BoolFlag{Name: "snmpv2c", Usage: "Enable SNMPv2c probing"},
+			&cli.StringFlag{Name: "log-level", Usage: "Log level (trace/debug/info/warn/error)", DefaultValue: "info"},
+			&cli.StringFlag{Name: "log-format", Usage: "Log format (text/json)", DefaultValue: "text"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			log.Init(cmd.GetString("log-format"), cmd.GetString("log-level"), os.Stdout)
+
+			cfg := agent.Config{
+				ServerURL:      cmd.GetString("server"),
+				Token:          cmd.GetString("token"),
+				SNMPv2cEnabled: cmd.GetBool("snmpv2c"),
+				Version:        version,
+			}
+			var err error
+			if cfg.HeartbeatInterval, err = time.ParseDuration(cmd.GetString("heartbeat-interval")); err != nil {
+				return fmt.Errorf("invalid heartbeat interval: %w", err)
+			}
+			if cfg.DefaultScanInterval, err = time.ParseDuration(cmd.GetString("scan-interval")); err != nil {
+				return fmt.Errorf("invalid scan interval: %w", err)
+			}
+			if cfg.ScanTimeout, err = time.ParseDuration(cmd.GetString("scan-timeout")); err != nil {
+				return fmt.Errorf("invalid scan timeout: %w", err)
+			}
+
+			a, err := agent.New(cfg)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			log.Info("Agent started", "server", cfg.ServerURL)
+			err = a.Run(ctx)
+			log.Info("Agent stopped")
+			return err
+		},
+	}
+}
+
+func ListCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List registered agents",
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			c := client.NewClient(client.LoadConfig())
+			resp, err := c.DoRequest("GET", "/api/agents", nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var agents []model.Agent
+			if err := json.NewDecoder(resp.Body).Decode(&agents); err != nil {
+				return err
+			}
+
+			return client.Render(agents, func(bool) {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tNAME\tONLINE\tNETWORKS\tHOSTNAME\tVERSION\tLAST HEARTBEAT")
+				for _, a := range agents {
+					lastHeartbeat := "never"
+					if a.LastHeartbeat != nil {
+						lastHeartbeat = a.LastHeartbeat.Format(time.RFC3339)
+					}
+					fmt.Fprintf(w, "%s\t%s\t%v\t%d\t%s\t%s\t%s\n",
+						a.ID, a.Name, a.Online, len(a.NetworkIDs), a.Hostname, a.Version, lastHeartbeat)
+				}
+				w.Flush()
+			})
+		},
+	}
+}
+
+func RegisterCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "register",
+		Usage: "Register an agent and print its token",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "name", Usage: "Agent name", Required: true},
+			&cli.StringFlag{Name: "description", Usage: "Description"},
+			&cli.StringFlag{Name: "networks", Usage: "Comma-separated network IDs to assign"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			c := client.NewClient(client.LoadConfig())
+			body := model.Agent{
+				Name:        cmd.GetString("name"),
+				Description: cmd.GetString("description"),
+				NetworkIDs:  splitIDs(cmd.GetString("networks")),
+			}
+
+			resp, err := c.DoRequest("POST", "/api/agents", body)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusCreated {
+				return client.HandleError(resp)
+			}
+
+			var reg model.AgentRegistration
+			if err := json.NewDecoder(resp.Body).Decode(&reg); err != nil {
+				return err
+			}
+			printRegistration(&reg)
+			return nil
+		},
+	}
+}
+
+func UpdateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "update",
+		Usage: "Update an agent's name, description or assigned networks",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "id", Usage: "Agent ID", Required: true},
+			&cli.StringFlag{Name: "name", Usage: "Agent name"},
+			&cli.StringFlag{Name: "description", Usage: "Description"},
+			&cli.StringFlag{Name: "networks", Usage: "Comma-separated network IDs to assign (replaces the current list)"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			c := client.NewClient(client.LoadConfig())
+			path := "/api/agents/" + cmd.GetString("id")
+
+			resp, err := c.DoRequest("GET", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var a model.Agent
+			if err := json.NewDecoder(resp.Body).Decode(&a); err != nil {
+				return err
+			}
+			if v := cmd.GetString("name"); v != "" {
+				a.Name = v
+			}
+			if v := cmd.GetString("description"); v != "" {
+				a.Description = v
+			}
+			if cmd.HasFlag("networks") {
+				a.NetworkIDs = splitIDs(cmd.GetString("networks"))
+			}
+
+			resp2, err := c.DoRequest("PUT", path, a)
+			if err != nil {
+				return err
+			}
+			defer resp2.Body.Close()
+			if resp2.StatusCode != http.StatusOK {
+				return client.HandleError(resp2)
+			}
+
+			fmt.Println("Agent updated")
+			return nil
+		},
+	}
+}
+
+func RotateTokenCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "rotate-token",
+		Usage: "Issue a new token for an agent, invalidating the old one",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "id", Usage: "Agent ID", Required: true},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			c := client.NewClient(client.LoadConfig())
+			resp, err := c.DoRequest("POST", "/api/agents/"+cmd.GetString("id")+"/rotate-token", nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var reg model.AgentRegistration
+			if err := json.NewDecoder(resp.Body).Decode(&reg); err != nil {
+				return err
+			}
+			printRegistration(&reg)
+			return nil
+		},
+	}
+}
+
+func DeleteCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "delete",
+		Usage: "Delete an agent",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "id", Usage: "Agent ID", Required: true},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			c := client.NewClient(client.LoadConfig())
+			resp, err := c.DoRequest("DELETE", "/api/agents/"+cmd.GetString("id"), nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusNoContent {
+				return client.HandleError(resp)
+			}
+			fmt.Println("Agent deleted")
+			return nil
+		},
+	}
+}
+
+func printRegistration(reg *model.AgentRegistration) {
+	fmt.Printf("Agent: %s (%s)\n", reg.Agent.Name, reg.Agent.ID)
+	fmt.Printf("Token: %s\n", reg.Token)
+	fmt.Println("Store this token securely - it will not be shown again.")
+	fmt.Printf("Start the agent with: rackd agent run --server <url> --token %s\n", reg.Token)
+}
+
+func splitIDs(s string) []string {
+	ids := []string{}
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandStructure(t *testing.T) {
+	cmd := Command("dev")
+
+	if cmd.Name != "agent" {
+		t.Errorf("expected command name 'agent', got %q", cmd.Name)
+	}
+
+	expectedSubcommands := []string{"run", "list", "register", "update", "rotate-token", "delete"}
+	if len(cmd.Commands) != len(expectedSubcommands) {
+		t.Fatalf("expected %d subcommands, got %d", len(expectedSubcommands), len(cmd.Commands))
+	}
+	for i, expected := range expectedSubcommands {
+		if cmd.Commands[i].Name != expected {
+			t.Errorf("subcommand %d: expected %q, got %q", i, expected, cmd.Commands[i].Name)
+		}
+	}
+}
+
+func TestRunCommandFlags(t *testing.T) {
+	cmd := RunCommand("dev")
+
+	if len(cmd.Flags) != 8 {
+		t.Errorf("expected 8 flags, got %d", len(cmd.Flags))
+	}
+}
+
+func TestSplitIDs(t *testing.T) {
+	got := splitIDs(" net-1, ,net-2,")
+	if want := []string{"net-1", "net-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := splitIDs(""); len(got) != 0 {
+		t.Errorf("expected empty list, got %v", got)
+	}
+}
@@ -0,0 +1,87 @@
+package ansible
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/paularlott/cli"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+)
+
+// Command returns the ansible-inventory command. It follows the Ansible
+// inventory script protocol, so a wrapper script calling
+// `rackd ansible-inventory "$@"` can be passed to ansible with -i.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "ansible-inventory",
+		Usage: "Print devices as an Ansible dynamic inventory",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "list", Usage: "Print the full inventory (default)"},
+			&cli.StringFlag{Name: "host", Usage: "Print the variables of a single host"},
+			&cli.StringFlag{Name: "address-label", Usage: "Address label to use for ansible_host", EnvVars: []string{"RACKD_ANSIBLE_ADDRESS_LABEL"}},
+			&cli.StringFlag{Name: "tags", Usage: "Only include devices with these tags (comma-separated)", EnvVars: []string{"RACKD_ANSIBLE_TAGS"}},
+			&cli.StringFlag{Name: "datacenter-id", Usage: "Only include devices in this datacenter", EnvVars: []string{"RACKD_ANSIBLE_DATACENTER_ID"}},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			c := client.NewClient(client.LoadConfig())
+
+			query := url.Values{}
+			if v := cmd.GetString("address-label"); v != "" {
+				query.Set("address_label", v)
+			}
+			for _, tag := range strings.Split(cmd.GetString("tags"), ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					query.Add("tags", tag)
+				}
+			}
+			if v := cmd.GetString("datacenter-id"); v != "" {
+				query.Set("datacenter_id", v)
+			}
+			path := "/api/ansible/inventory"
+			if len(query) > 0 {
+				path += "?" + query.Encode()
+			}
+
+			resp, err := c.DoRequest("GET", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var inventory map[string]json.RawMessage
+			if err := json.NewDecoder(resp.Body).Decode(&inventory); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if host := cmd.GetString("host"); host != "" {
+				return enc.Encode(hostVars(inventory, host))
+			}
+			return enc.Encode(inventory)
+		},
+	}
+}
+
+// hostVars returns the variables of a host from the inventory's _meta
+// section, or an empty object for unknown hosts as Ansible expects
+func hostVars(inventory map[string]json.RawMessage, host string) json.RawMessage {
+	var meta struct {
+		HostVars map[string]json.RawMessage `json:"hostvars"`
+	}
+	if err := json.Unmarshal(inventory["_meta"], &meta); err == nil {
+		if vars, ok := meta.HostVars[host]; ok {
+			return vars
+		}
+	}
+	return json.RawMessage("{}")
+}
@@ -0,0 +1,30 @@
+package ansible
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCommandStructure(t *testing.T) {
+	cmd := Command()
+
+	if cmd.Name != "ansible-inventory" {
+		t.Errorf("expected command name 'ansible-inventory', got %q", cmd.Name)
+	}
+	if len(cmd.Flags) != 5 {
+		t.Errorf("expected 5 flags, got %d", len(cmd.Flags))
+	}
+}
+
+func TestHostVars(t *testing.T) {
+	inventory := map[string]json.RawMessage{
+		"_meta": json.RawMessage(`{"hostvars":{"web01":{"ansible_host":"10.0.0.10"}}}`),
+	}
+
+	if got := string(hostVars(inventory, "web01")); got != `{"ansible_host":"10.0.0.10"}` {
+		t.Errorf("unexpected host vars: %s", got)
+	}
+	if got := string(hostVars(inventory, "missing")); got != "{}" {
+		t.Errorf("expected empty object for unknown host, got %s", got)
+	}
+}
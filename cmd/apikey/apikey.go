@@ -52,25 +52,25 @@ func ListCommand() *cli.Command {
 				return fmt.Errorf("failed to decode response: %w", err)
 			}
 
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "ID\tNAME\tDESCRIPTION\tCREATED\tLAST USED\tEXPIRES")
-			for _, key := range keys {
-				lastUsed := "never"
-				if key.LastUsedAt != nil {
-					lastUsed = key.LastUsedAt.Format("2006-01-02 15:04")
+			return client.Render(keys, func(bool) {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tNAME\tDESCRIPTION\tCREATED\tLAST USED\tEXPIRES")
+				for _, key := range keys {
+					lastUsed := "never"
+					if key.LastUsedAt != nil {
+						lastUsed = key.LastUsedAt.Format("2006-01-02 15:04")
+					}
+					expires := "never"
+					if key.ExpiresAt != nil {
+						expires = key.ExpiresAt.Format("2006-01-02")
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+						key.ID, key.Name, key.Description,
+						key.CreatedAt.Format("2006-01-02 15:04"),
+						lastUsed, expires)
 				}
-				expires := "never"
-				if key.ExpiresAt != nil {
-					expires = key.ExpiresAt.Format("2006-01-02")
-				}
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
-					key.ID, key.Name, key.Description,
-					key.CreatedAt.Format("2006-01-02 15:04"),
-					lastUsed, expires)
-			}
-			w.Flush()
-
-			return nil
+				w.Flush()
+			})
 		},
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/martinsuchenak/rackd/cmd/client"
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/service"
 	"github.com/martinsuchenak/rackd/internal/storage"
@@ -61,27 +62,28 @@ func listCommand() *cli.Command {
 				return err
 			}
 
-			if len(logs) == 0 {
-				fmt.Println("No audit logs found")
-				return nil
-			}
-
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "TIMESTAMP\tACTION\tRESOURCE\tRESOURCE_ID\tUSER\tIP\tSTATUS")
-
-			for _, log := range logs {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-					log.Timestamp.Format(time.RFC3339),
-					log.Action,
-					log.Resource,
-					log.ResourceID,
-					log.Username,
-					log.IPAddress,
-					log.Status,
-				)
-			}
+			return client.Render(logs, func(bool) {
+				if len(logs) == 0 {
+					fmt.Println("No audit logs found")
+					return
+				}
 
-			return w.Flush()
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "TIMESTAMP\tACTION\tRESOURCE\tRESOURCE_ID\tUSER\tIP\tSTATUS")
+
+				for _, log := range logs {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						log.Timestamp.Format(time.RFC3339),
+						log.Action,
+						log.Resource,
+						log.ResourceID,
+						log.Username,
+						log.IPAddress,
+						log.Status,
+					)
+				}
+				w.Flush()
+			})
 		},
 	}
 }
@@ -1,15 +1,21 @@
 package backup
 
 import (
+	"bufio"
 	"context"
+	"database/sql"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/martinsuchenak/rackd/internal/backup"
 	"github.com/martinsuchenak/rackd/internal/config"
 	"github.com/paularlott/cli"
+
+	_ "modernc.org/sqlite"
 )
 
 func Command() *cli.Command {
@@ -18,60 +24,132 @@ func Command() *cli.Command {
 		Usage: "Backup the rackd database",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "data-dir", Usage: "Data directory (default: from config)", DefaultValue: "./data"},
-			&cli.StringFlag{Name: "output", Usage: "Output file path (default: rackd-backup-<timestamp>.db)"},
+			&cli.StringFlag{Name: "output", Usage: "Output file path (default: the backup directory or S3 bucket from config)"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := config.Load()
-			dataDir := cmd.GetString("data-dir")
-			if dataDir == "" {
-				dataDir = cfg.DataDir
+			if dataDir := cmd.GetString("data-dir"); dataDir != "" {
+				cfg.DataDir = dataDir
 			}
 
-			srcPath := filepath.Join(dataDir, "rackd.db")
-			if _, err := os.Stat(srcPath); os.IsNotExist(err) {
-				return fmt.Errorf("database not found at %s", srcPath)
+			// VACUUM INTO gives a consistent copy even while the server is running
+			if dstPath := cmd.GetString("output"); dstPath != "" {
+				if err := backup.Snapshot(ctx, filepath.Join(cfg.DataDir, "rackd.db"), dstPath); err != nil {
+					return err
+				}
+				info, err := os.Stat(dstPath)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Backup created: %s (%.1f MB)\n", dstPath, float64(info.Size())/1024/1024)
+				return nil
 			}
 
-			dstPath := cmd.GetString("output")
-			if dstPath == "" {
-				dstPath = fmt.Sprintf("rackd-backup-%s.db", time.Now().Format("20060102-150405"))
+			b, err := backup.FromConfig(cfg).Create(ctx)
+			if err != nil {
+				return err
 			}
+			fmt.Printf("Backup created: %s (%.1f MB)\n", b.Location, float64(b.Size)/1024/1024)
+			return nil
+		},
+	}
+}
 
-			src, err := os.Open(srcPath)
-			if err != nil {
-				return fmt.Errorf("failed to open database: %w", err)
+func RestoreCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "Restore the rackd database from a backup (stop the server first)",
+		Arguments: []cli.Argument{
+			&cli.StringArg{Name: "backup", Usage: "Backup file, or the name of a backup in the backup directory or S3 bucket", Required: true},
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "data-dir", Usage: "Data directory (default: from config)", DefaultValue: "./data"},
+			&cli.BoolFlag{Name: "force", Usage: "Skip confirmation"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := config.Load()
+			if dataDir := cmd.GetString("data-dir"); dataDir != "" {
+				cfg.DataDir = dataDir
 			}
-			defer src.Close()
+			dbPath := filepath.Join(cfg.DataDir, "rackd.db")
 
-			dst, err := os.Create(dstPath)
-			if err != nil {
-				return fmt.Errorf("failed to create backup file: %w", err)
+			srcPath := cmd.GetStringArg("backup")
+			if _, err := os.Stat(srcPath); os.IsNotExist(err) {
+				// Not a file, so a backup name: fetch it next to the database
+				fetched := dbPath + ".restore"
+				if err := backup.FromConfig(cfg).Fetch(ctx, srcPath, fetched); err != nil {
+					os.Remove(fetched)
+					return fmt.Errorf("failed to fetch backup %s: %w", srcPath, err)
+				}
+				defer os.Remove(fetched)
+				srcPath = fetched
 			}
-			defer dst.Close()
 
-			n, err := io.Copy(dst, src)
-			if err != nil {
-				os.Remove(dstPath)
-				return fmt.Errorf("backup failed: %w", err)
+			if err := checkBackup(ctx, srcPath); err != nil {
+				return err
+			}
+
+			if !cmd.GetBool("force") {
+				fmt.Printf("This replaces the database at %s. Make sure the server is stopped. Continue? [y/N]: ", dbPath)
+				confirm, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				confirm = strings.TrimSpace(strings.ToLower(confirm))
+				if confirm != "y" && confirm != "yes" {
+					fmt.Println("Restore cancelled")
+					return nil
+				}
 			}
 
-			// Also copy WAL and SHM files if they exist
-			for _, suffix := range []string{"-wal", "-shm"} {
-				walSrc := srcPath + suffix
-				if _, err := os.Stat(walSrc); err == nil {
-					walDst := dstPath + suffix
-					if err := copyFile(walSrc, walDst); err != nil {
-						fmt.Fprintf(os.Stderr, "Warning: failed to copy %s: %v\n", suffix, err)
+			// Keep the current database, with its WAL, in case the restore
+			// was a mistake
+			kept := ""
+			if _, err := os.Stat(dbPath); err == nil {
+				kept = fmt.Sprintf("%s.pre-restore-%s", dbPath, time.Now().Format("20060102-150405"))
+				for _, suffix := range []string{"", "-wal", "-shm"} {
+					if err := os.Rename(dbPath+suffix, kept+suffix); err != nil && !os.IsNotExist(err) {
+						return fmt.Errorf("failed to move the current database aside: %w", err)
 					}
 				}
 			}
 
-			fmt.Printf("Backup created: %s (%.1f MB)\n", dstPath, float64(n)/1024/1024)
+			if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+				return fmt.Errorf("failed to create data directory: %w", err)
+			}
+			if err := copyFile(srcPath, dbPath); err != nil {
+				return fmt.Errorf("restore failed: %w", err)
+			}
+
+			fmt.Printf("Database restored from %s\n", cmd.GetStringArg("backup"))
+			if kept != "" {
+				fmt.Printf("The previous database was kept at %s\n", kept)
+			}
 			return nil
 		},
 	}
 }
 
+// checkBackup verifies path is an intact rackd database
+func checkBackup(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("%s is not a SQLite database: %w", path, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("backup failed the integrity check: %s", result)
+	}
+
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&n); err != nil || n == 0 {
+		return fmt.Errorf("%s is not a rackd database", path)
+	}
+	return nil
+}
+
 func copyFile(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
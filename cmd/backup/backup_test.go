@@ -1,6 +1,8 @@
 package backup
 
 import (
+	"context"
+	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
@@ -52,3 +54,47 @@ func TestCopyFileNonExistent(t *testing.T) {
 		t.Error("expected error for non-existent source, got nil")
 	}
 }
+
+func TestRestoreCommandStructure(t *testing.T) {
+	cmd := RestoreCommand()
+
+	if cmd.Name != "restore" {
+		t.Errorf("expected command name 'restore', got %q", cmd.Name)
+	}
+	if len(cmd.Arguments) != 1 {
+		t.Errorf("expected the backup argument, got %d arguments", len(cmd.Arguments))
+	}
+}
+
+func TestCheckBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+
+	notDB := filepath.Join(tmpDir, "notes.txt")
+	if err := os.WriteFile(notDB, []byte("not a database"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := checkBackup(ctx, notDB); err == nil {
+		t.Error("expected an error for a file that is not a database")
+	}
+
+	dbPath := filepath.Join(tmpDir, "backup.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE devices (name TEXT)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	if err := checkBackup(ctx, dbPath); err == nil {
+		t.Error("expected an error for a database without rackd migrations")
+	}
+
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version TEXT)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	if err := checkBackup(ctx, dbPath); err != nil {
+		t.Errorf("expected a rackd database to pass, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/bench"
+	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/paularlott/cli"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "bench",
+		Usage: "Benchmark storage backends under inventory-shaped load",
+		Description: "Creates devices, lists and searches inventories of each size, and runs a concurrent mix of " +
+			"reads and writes against each backend, then prints a comparison table. Backends run on fresh, " +
+			"temporary storage, so existing data is never touched. sqlite is a database file and memory an " +
+			"in-memory SQLite database; other registered drivers need --dsn.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "backends", Usage: "Backends to benchmark (comma-separated)", DefaultValue: "sqlite,memory"},
+			&cli.StringSliceFlag{Name: "dsn", Usage: "Data source for a registered driver, as driver=source (repeatable)"},
+			&cli.StringFlag{Name: "sizes", Usage: "Inventory sizes to list, search and mix at (comma-separated)", DefaultValue: "1000,10000,100000"},
+			&cli.IntFlag{Name: "creates", Usage: "Devices to create in the CreateDevice benchmark", DefaultValue: bench.DefaultOptions.Creates},
+			&cli.StringFlag{Name: "duration", Usage: "How long each timed benchmark runs", DefaultValue: bench.DefaultOptions.Duration.String()},
+			&cli.IntFlag{Name: "concurrency", Usage: "Workers in the mixed benchmark", DefaultValue: bench.DefaultOptions.Concurrency},
+			&cli.Float64Flag{Name: "write-ratio", Usage: "Share of writes in the mixed benchmark, 0 to 1", DefaultValue: bench.DefaultOptions.WriteRatio},
+			&cli.StringFlag{Name: "data-dir", Usage: "Directory for the sqlite backend's temporary database (default system temp dir)"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			duration, err := time.ParseDuration(cmd.GetString("duration"))
+			if err != nil {
+				return fmt.Errorf("invalid --duration: %w", err)
+			}
+			sizes, err := parseSizes(cmd.GetString("sizes"))
+			if err != nil {
+				return err
+			}
+			opts := bench.Options{
+				Creates:     cmd.GetInt("creates"),
+				Sizes:       sizes,
+				Duration:    duration,
+				Concurrency: cmd.GetInt("concurrency"),
+				WriteRatio:  cmd.GetFloat64("write-ratio"),
+			}
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			dir, err := os.MkdirTemp(cmd.GetString("data-dir"), "rackd-bench-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dir)
+
+			backends, err := resolveBackends(strings.Split(cmd.GetString("backends"), ","), cmd.GetStringSlice("dsn"), dir)
+			if err != nil {
+				return err
+			}
+
+			var results []bench.Result
+			for _, b := range backends {
+				r, err := bench.Run(ctx, b, opts, func(msg string) {
+					fmt.Fprintln(os.Stderr, msg)
+				})
+				if err != nil {
+					return err
+				}
+				results = append(results, r...)
+			}
+
+			return client.Render(results, func(bool) {
+				bench.WriteTable(os.Stdout, results)
+			})
+		},
+	}
+}
+
+func parseSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		size, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --sizes value %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// resolveBackends turns backend names into backends. sqlite and memory are
+// built in; any other name must be a registered driver with a --dsn.
+func resolveBackends(names, dsns []string, dir string) ([]bench.Backend, error) {
+	sources := map[string]string{}
+	for _, dsn := range dsns {
+		driver, source, ok := strings.Cut(dsn, "=")
+		if !ok || driver == "" {
+			return nil, fmt.Errorf("invalid --dsn %q: use driver=source", dsn)
+		}
+		sources[driver] = source
+	}
+
+	builtin := bench.SQLiteBackends(dir)
+	var backends []bench.Backend
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if i := slices.IndexFunc(builtin, func(b bench.Backend) bool { return b.Name == name }); i >= 0 {
+			backends = append(backends, builtin[i])
+			continue
+		}
+		if !slices.Contains(storage.Drivers(), name) {
+			available := append([]string{"memory"}, storage.Drivers()...)
+			slices.Sort(available)
+			return nil, fmt.Errorf("unknown backend %q (available: %s)", name, strings.Join(slices.Compact(available), ", "))
+		}
+		source, ok := sources[name]
+		if !ok {
+			return nil, fmt.Errorf("backend %q needs --dsn %s=<source>", name, name)
+		}
+		backends = append(backends, bench.Backend{Name: name, Driver: name, DataSource: source})
+	}
+	if len(backends) == 0 {
+		return nil, errors.New("no backends to benchmark")
+	}
+	return backends, nil
+}
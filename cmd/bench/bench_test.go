@@ -0,0 +1,68 @@
+package bench
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+func TestResolveBackends(t *testing.T) {
+	backends, err := resolveBackends([]string{"memory", "sqlite", "memory"}, nil, "/tmp/bench")
+	if err != nil {
+		t.Fatalf("resolveBackends failed: %v", err)
+	}
+	if len(backends) != 2 || backends[0].DataSource != ":memory:" || backends[1].DataSource != "/tmp/bench" {
+		t.Fatalf("unexpected backends %+v", backends)
+	}
+	for _, b := range backends {
+		if b.Driver != storage.DefaultDriver {
+			t.Errorf("expected %s to use the %s driver, got %q", b.Name, storage.DefaultDriver, b.Driver)
+		}
+	}
+
+	tests := []struct {
+		names []string
+		dsns  []string
+		want  string
+	}{
+		{[]string{"postgres"}, nil, `unknown backend "postgres" (available: `},
+		{[]string{"memory"}, []string{"sqlite"}, "use driver=source"},
+		{nil, nil, "no backends"},
+	}
+	for _, tt := range tests {
+		_, err := resolveBackends(tt.names, tt.dsns, "/tmp/bench")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("resolveBackends(%v, %v): expected error containing %q, got %v", tt.names, tt.dsns, tt.want, err)
+		}
+	}
+}
+
+func TestParseSizes(t *testing.T) {
+	sizes, err := parseSizes("1000, 10000,,100000")
+	if err != nil || !slices.Equal(sizes, []int{1000, 10000, 100000}) {
+		t.Errorf("unexpected sizes %v, %v", sizes, err)
+	}
+	if _, err := parseSizes("1000,10k"); err == nil || !strings.Contains(err.Error(), `"10k"`) {
+		t.Errorf("expected an invalid size error, got %v", err)
+	}
+}
+
+func TestResolveBackendsRegisteredDriver(t *testing.T) {
+	// Registered drivers can't be removed, so only register once per process
+	if !slices.Contains(storage.Drivers(), "bench-test") {
+		storage.Register("bench-test", func(string) (storage.ExtendedStorage, error) { return nil, nil })
+	}
+
+	if _, err := resolveBackends([]string{"bench-test"}, nil, ""); err == nil || !strings.Contains(err.Error(), "--dsn bench-test=") {
+		t.Errorf("expected a missing --dsn error, got %v", err)
+	}
+	backends, err := resolveBackends([]string{"bench-test"}, []string{"bench-test=db://host/rackd"}, "")
+	if err != nil {
+		t.Fatalf("resolveBackends failed: %v", err)
+	}
+	if len(backends) != 1 || backends[0].Driver != "bench-test" || backends[0].DataSource != "db://host/rackd" {
+		t.Errorf("unexpected backends %+v", backends)
+	}
+}
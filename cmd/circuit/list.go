@@ -18,7 +18,6 @@ func ListCommand() *cli.Command {
 			&cli.StringFlag{Name: "status", Usage: "Filter by status"},
 			&cli.StringFlag{Name: "datacenter", Usage: "Filter by datacenter ID"},
 			&cli.StringFlag{Name: "type", Usage: "Filter by circuit type"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -57,13 +56,21 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(circuits)
-			default:
-				client.PrintYAML(circuits)
-			}
-			return nil
+			return client.PrintList(circuits, circuitColumns)
 		},
 	}
 }
+
+var circuitColumns = []client.Column{
+	{Header: "ID", Field: "id"},
+	{Header: "NAME", Field: "name"},
+	{Header: "CIRCUIT ID", Field: "circuit_id"},
+	{Header: "PROVIDER", Field: "provider"},
+	{Header: "TYPE", Field: "type"},
+	{Header: "STATUS", Field: "status"},
+	{Header: "CAPACITY (MBPS)", Field: "capacity_mbps"},
+	{Header: "DATACENTER A", Field: "datacenter_a_id", Wide: true},
+	{Header: "DATACENTER B", Field: "datacenter_b_id", Wide: true},
+	{Header: "DEVICE A", Field: "device_a_id", Wide: true},
+	{Header: "DEVICE B", Field: "device_b_id", Wide: true},
+}
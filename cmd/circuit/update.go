@@ -29,7 +29,6 @@ func UpdateCommand() *cli.Command {
 			&cli.StringFlag{Name: "port-a", Usage: "Port at endpoint A"},
 			&cli.StringFlag{Name: "port-b", Usage: "Port at endpoint B"},
 			&cli.StringFlag{Name: "description", Usage: "Description"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -98,13 +97,9 @@ func UpdateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(circuit)
-			default:
+			return client.Render(circuit, func(bool) {
 				client.PrintYAML(circuit)
-			}
-			return nil
+			})
 		},
 	}
 }
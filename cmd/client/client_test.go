@@ -1,10 +1,19 @@
 package client
 
 import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/paularlott/cli"
 )
 
 func TestLoadConfig_Defaults(t *testing.T) {
@@ -97,3 +106,153 @@ func TestHandleError(t *testing.T) {
 		t.Errorf("unexpected error message: %v", err)
 	}
 }
+
+func TestWarnings(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Add("Warning", `199 rackd "MAC address aa:bb:cc:00:00:01 is also used by device db01 (dev-2)"`)
+	resp.Header.Add("Warning", "malformed")
+
+	got := Warnings(resp)
+	if len(got) != 1 || got[0] != "MAC address aa:bb:cc:00:00:01 is also used by device db01 (dev-2)" {
+		t.Errorf("unexpected warnings: %q", got)
+	}
+}
+
+func TestHandleError_ValidationDetails(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       io.NopCloser(strings.NewReader(`{"error":"name: name is required","code":"VALIDATION_ERROR","details":[{"field":"name","message":"name is required"}]}`)),
+	}
+
+	err := HandleError(resp)
+	if err.Error() != "VALIDATION_ERROR: name: name is required" {
+		t.Errorf("unexpected error message: %v", err)
+	}
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusBadRequest || len(respErr.Details) == 0 {
+		t.Errorf("expected ResponseError with details, got %#v", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitSuccess},
+		{&ResponseError{StatusCode: http.StatusUnauthorized}, ExitAuth},
+		{&ResponseError{StatusCode: http.StatusForbidden}, ExitAuth},
+		{&ResponseError{StatusCode: http.StatusBadGateway}, ExitServer},
+		{&ResponseError{StatusCode: http.StatusNotFound}, ExitGeneric},
+		{&url.Error{Op: "Get", URL: "http://x", Err: errors.New("connection refused")}, ExitNetwork},
+		{errors.New("boom"), ExitGeneric},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClient_CACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Without the CA the self-signed certificate is rejected
+	if _, err := NewClient(&Config{ServerURL: server.URL, VerifySSL: true}).DoRequest("GET", "/", nil); err == nil {
+		t.Fatal("expected certificate error without CA bundle")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, pemData, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := NewClient(&Config{ServerURL: server.URL, VerifySSL: true, CACert: caFile}).DoRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatalf("expected CA bundle to verify server: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := NewClient(&Config{ServerURL: server.URL, CACert: filepath.Join(t.TempDir(), "missing.pem")}).DoRequest("GET", "/", nil); err == nil {
+		t.Error("expected error for missing CA bundle")
+	}
+}
+
+func TestApplyFlags(t *testing.T) {
+	os.Setenv("RACKD_SERVER_URL", "http://env:9090")
+	os.Setenv("RACKD_TOKEN", "env-token")
+	defer os.Unsetenv("RACKD_SERVER_URL")
+	defer os.Unsetenv("RACKD_TOKEN")
+	defer func() { flagOverrides = overrides{} }()
+
+	var cfg *Config
+	app := &cli.Command{
+		Name:  "rackd",
+		Flags: Flags(),
+		PreRun: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			ApplyFlags(cmd)
+			return ctx, nil
+		},
+		Commands: []*cli.Command{{
+			Name: "list",
+			Run: func(ctx context.Context, cmd *cli.Command) error {
+				cfg = LoadConfig()
+				return nil
+			},
+		}},
+	}
+
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"rackd", "list", "--server", "https://flag:8443", "--insecure", "--timeout", "5s"}
+	if err := app.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if cfg.ServerURL != "https://flag:8443" {
+		t.Errorf("expected flag to override env ServerURL, got %s", cfg.ServerURL)
+	}
+	if cfg.Token != "env-token" {
+		t.Errorf("expected env Token when flag is unset, got %s", cfg.Token)
+	}
+	if cfg.VerifySSL || cfg.Timeout != "5s" {
+		t.Errorf("expected --insecure and --timeout to apply, got VerifySSL=%v Timeout=%s", cfg.VerifySSL, cfg.Timeout)
+	}
+}
+
+func TestOfflineClient(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("RACKD_OFFLINE_DIR", dir)
+
+	if _, err := NewClient(&Config{Offline: true}).DoRequest("GET", "/api/datacenters", nil); err == nil || !strings.Contains(err.Error(), "rackd sync pull") {
+		t.Fatalf("expected an error without an offline copy, got %v", err)
+	}
+
+	c := NewOfflineClient(dir)
+	resp, err := c.DoRequest("POST", "/api/datacenters", map[string]string{"name": "dc1"})
+	if err != nil {
+		t.Fatalf("DoRequest failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 without a token, got %d", resp.StatusCode)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	c = NewClient(&Config{Offline: true})
+	defer c.Close()
+	resp, err = c.DoRequest("GET", "/api/datacenters?name=dc1", nil)
+	if err != nil {
+		t.Fatalf("DoRequest failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"name":"dc1"`) {
+		t.Errorf("expected the datacenter from the offline copy, got %d %s", resp.StatusCode, body)
+	}
+}
@@ -1,7 +1,10 @@
 package client
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -13,6 +16,9 @@ type Config struct {
 	Timeout   string `json:"timeout"`
 	Output    string `json:"output"`
 	VerifySSL bool   `json:"verify_ssl"`
+	CACert    string `json:"ca_cert"`
+	// Offline serves commands from the offline copy instead of the server
+	Offline bool `json:"offline"`
 }
 
 var defaultConfig = Config{
@@ -39,6 +45,38 @@ func LoadConfig() *Config {
 	if v := os.Getenv("RACKD_VERIFY_SSL"); v == "false" || v == "0" {
 		cfg.VerifySSL = false
 	}
+	if caCert := os.Getenv("RACKD_CA_CERT"); caCert != "" {
+		cfg.CACert = caCert
+	}
+	if output := os.Getenv("RACKD_OUTPUT"); output != "" {
+		cfg.Output = output
+	}
+	if v := os.Getenv("RACKD_OFFLINE"); v == "true" || v == "1" {
+		cfg.Offline = true
+	}
+
+	// Global flags win over both
+	if flagOverrides.serverURL != "" {
+		cfg.ServerURL = flagOverrides.serverURL
+	}
+	if flagOverrides.token != "" {
+		cfg.Token = flagOverrides.token
+	}
+	if flagOverrides.caCert != "" {
+		cfg.CACert = flagOverrides.caCert
+	}
+	if flagOverrides.timeout != "" {
+		cfg.Timeout = flagOverrides.timeout
+	}
+	if flagOverrides.output != "" {
+		cfg.Output = flagOverrides.output
+	}
+	if flagOverrides.insecure {
+		cfg.VerifySSL = false
+	}
+	if flagOverrides.offline {
+		cfg.Offline = true
+	}
 
 	return &cfg
 }
@@ -50,6 +88,31 @@ func getConfigDir() string {
 	return filepath.Join(os.Getenv("HOME"), ".config", "rackd")
 }
 
+// TLSConfig builds the TLS settings for talking to the server. A CA bundle
+// is added to the system roots, so a server behind a public certificate
+// still verifies.
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if !c.VerifySSL {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	if c.CACert != "" {
+		pem, err := os.ReadFile(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
 func (c *Config) GetTimeout() time.Duration {
 	d, err := time.ParseDuration(c.Timeout)
 	if err != nil {
@@ -2,9 +2,13 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -16,21 +20,109 @@ const (
 	ExitServer       = 5
 )
 
+// APIError is the error body returned by the server. Details holds
+// field-level validation errors and varies by code, so it is kept raw.
 type APIError struct {
-	Error   string `json:"error"`
-	Code    string `json:"code"`
-	Details string `json:"details,omitempty"`
+	Error   string          `json:"error"`
+	Code    string          `json:"code"`
+	Details json.RawMessage `json:"details,omitempty"`
 }
 
+// ResponseError is a non-success response from the server
+type ResponseError struct {
+	StatusCode int
+	APIError
+}
+
+func (e *ResponseError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.APIError.Error)
+}
+
+// HandleError reads the error body of a failed response
 func HandleError(resp *http.Response) error {
-	var errResp APIError
-	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
-		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	respErr := &ResponseError{StatusCode: resp.StatusCode}
+	json.NewDecoder(resp.Body).Decode(&respErr.APIError)
+	return respErr
+}
+
+// ExitCode picks the process exit code for an error returned by a command
+func ExitCode(err error) int {
+	var respErr *ResponseError
+	var urlErr *url.Error
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.As(err, &respErr):
+		switch {
+		case respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden:
+			return ExitAuth
+		case respErr.StatusCode >= http.StatusInternalServerError:
+			return ExitServer
+		}
+	case errors.As(err, &urlErr):
+		// The request never got a response
+		return ExitNetwork
+	}
+	return ExitGeneric
+}
+
+// PrintErr writes a command's error to stderr. When the output format is
+// json the error is written as a JSON object, so scripts parsing stdout can
+// parse failures the same way.
+func PrintErr(err error) {
+	if LoadConfig().Output != "json" {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return
+	}
+
+	out := struct {
+		Error   string          `json:"error"`
+		Code    string          `json:"code,omitempty"`
+		Status  int             `json:"status,omitempty"`
+		Details json.RawMessage `json:"details,omitempty"`
+	}{Error: err.Error()}
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		out.Error = respErr.APIError.Error
+		out.Code = respErr.Code
+		out.Status = respErr.StatusCode
+		out.Details = respErr.Details
+		if out.Error == "" {
+			out.Error = respErr.Error()
+		}
 	}
-	return fmt.Errorf("%s: %s", errResp.Code, errResp.Error)
+	json.NewEncoder(os.Stderr).Encode(out)
 }
 
 func PrintError(msg string, code int) {
 	fmt.Fprintln(os.Stderr, "Error:", msg)
 	os.Exit(code)
 }
+
+// Warnings returns the text of the Warning headers ("199 rackd \"text\"")
+// of a response
+func Warnings(resp *http.Response) []string {
+	var warnings []string
+	for _, v := range resp.Header.Values("Warning") {
+		parts := strings.SplitN(v, " ", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		text, err := strconv.Unquote(parts[2])
+		if err != nil {
+			text = parts[2]
+		}
+		warnings = append(warnings, text)
+	}
+	return warnings
+}
+
+// PrintWarnings writes the Warning headers of a response to stderr
+func PrintWarnings(resp *http.Response) {
+	for _, w := range Warnings(resp) {
+		fmt.Fprintln(os.Stderr, "Warning:", w)
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"slices"
+
+	"github.com/paularlott/cli"
+)
+
+// overrides holds the global flags of the running command. LoadConfig applies
+// them over the config file and environment.
+type overrides struct {
+	serverURL string
+	token     string
+	caCert    string
+	timeout   string
+	output    string
+	insecure  bool
+	offline   bool
+}
+
+var flagOverrides overrides
+
+// Flags returns the global flags that point the CLI at a server. They have no
+// defaults or environment variables of their own, so an unset flag leaves the
+// config file and RACKD_* variables in charge.
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "server", Usage: "Server URL (overrides RACKD_SERVER_URL)", Global: true},
+		&cli.StringFlag{Name: "token", Usage: "API token (overrides RACKD_TOKEN)", Global: true},
+		&cli.StringFlag{Name: "ca-cert", Usage: "PEM CA bundle to verify the server with (overrides RACKD_CA_CERT)", Global: true},
+		&cli.BoolFlag{Name: "insecure", Usage: "Skip TLS certificate verification", Global: true},
+		&cli.StringFlag{Name: "timeout", Usage: "Request timeout, e.g. 30s", Global: true},
+		&cli.StringFlag{Name: "output", Usage: "Output format: table, wide, json or yaml (overrides RACKD_OUTPUT)", Global: true},
+		&cli.BoolFlag{Name: "offline", Usage: "Work against the offline copy from rackd sync pull (overrides RACKD_OFFLINE)", Global: true},
+	}
+}
+
+// ApplyFlags records the global flags of the command about to run. The root
+// command calls it from PreRun. Commands that write to a file keep their own
+// --output flag for the path, so it is only read as a format elsewhere.
+func ApplyFlags(cmd *cli.Command) error {
+	flagOverrides = overrides{
+		serverURL: cmd.GetString("server"),
+		token:     cmd.GetString("token"),
+		caCert:    cmd.GetString("ca-cert"),
+		timeout:   cmd.GetString("timeout"),
+		insecure:  cmd.GetBool("insecure"),
+		offline:   cmd.GetBool("offline"),
+	}
+
+	if slices.ContainsFunc(cmd.Flags, func(f cli.Flag) bool {
+		sf, ok := f.(*cli.StringFlag)
+		return ok && sf.Name == "output"
+	}) {
+		return nil
+	}
+	if output := cmd.GetString("output"); output != "" {
+		if err := validOutput(output); err != nil {
+			return err
+		}
+		flagOverrides.output = output
+	}
+	return nil
+}
@@ -2,35 +2,51 @@ package client
 
 import (
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
 type Client struct {
 	serverURL  string
 	token      string
 	httpClient *http.Client
+	// err is a configuration error, such as an unreadable CA bundle,
+	// returned by every request
+	err error
 }
 
 func NewClient(cfg *Config) *Client {
-	transport := http.DefaultTransport.(*http.Transport).Clone()
-	if !cfg.VerifySSL {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	if cfg.Offline {
+		dir := OfflineDir()
+		c := NewOfflineClient(dir)
+		if !OfflineExists(dir) {
+			c.err = fmt.Errorf("no offline copy in %s, run rackd sync pull first", dir)
+		}
+		return c
 	}
 
-	return &Client{
-		serverURL: cfg.ServerURL,
+	c := &Client{
+		serverURL: strings.TrimSuffix(cfg.ServerURL, "/"),
 		token:     cfg.Token,
-		httpClient: &http.Client{
-			Timeout:   cfg.GetTimeout(),
-			Transport: transport,
-		},
 	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig, c.err = cfg.TLSConfig()
+	c.httpClient = &http.Client{
+		Timeout:   cfg.GetTimeout(),
+		Transport: transport,
+	}
+	return c
 }
 
 func (c *Client) DoRequest(method, path string, body interface{}) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	var reqBody io.Reader
 	if body != nil {
 		data, err := json.Marshal(body)
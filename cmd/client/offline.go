@@ -0,0 +1,115 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/martinsuchenak/rackd/internal/api"
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/service"
+	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/martinsuchenak/rackd/internal/validate"
+)
+
+// offlineURL is the base URL of requests served from the offline copy. The
+// host is never dialled.
+const offlineURL = "http://offline"
+
+// OfflineDir returns the directory holding the offline copy of the inventory
+// that rackd sync pull fills and --offline commands work against
+func OfflineDir() string {
+	if dir := os.Getenv("RACKD_OFFLINE_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "rackd", "offline")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".local", "share", "rackd", "offline")
+}
+
+// OfflineExists reports whether dir holds an offline copy
+func OfflineExists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "rackd.db"))
+	return err == nil
+}
+
+// NewOfflineClient returns a client that serves its requests in-process from
+// the offline copy in dir, through the same handlers and services as the
+// server. The copy belongs to whoever runs the CLI, so no token is needed.
+// The database is created on the first request if it does not exist.
+func NewOfflineClient(dir string) *Client {
+	return &Client{
+		serverURL:  offlineURL,
+		httpClient: &http.Client{Transport: &offlineTransport{dir: dir}},
+	}
+}
+
+// Close releases the offline database. It does nothing for a server client.
+func (c *Client) Close() error {
+	if t, ok := c.httpClient.Transport.(*offlineTransport); ok {
+		return t.close()
+	}
+	return nil
+}
+
+// offlineTransport answers requests from an API handler over the offline
+// database, opened on first use
+type offlineTransport struct {
+	dir string
+
+	mu      sync.Mutex
+	store   storage.ExtendedStorage
+	handler http.Handler
+}
+
+func (t *offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.handler == nil {
+		if err := os.MkdirAll(t.dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create offline directory: %w", err)
+		}
+		store, err := storage.NewExtendedStorage(t.dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open offline copy: %w", err)
+		}
+		// The offline copy follows the same policies as the server would
+		cfg := config.Load()
+		policies, err := validate.FromConfig(cfg)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		services := service.NewServices(store, nil, nil)
+		services.SetValidationPolicies(policies)
+		services.SetUniqueDeviceNames(cfg.UniqueDeviceNames)
+		h := api.NewHandler(store, nil,
+			api.WithServices(services),
+			api.WithLocalAccess("offline"))
+		mux := http.NewServeMux()
+		h.RegisterRoutes(mux)
+		t.store, t.handler = store, mux
+	}
+
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+func (t *offlineTransport) close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.store == nil {
+		return nil
+	}
+	err := t.store.Close()
+	t.store, t.handler = nil, nil
+	return err
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats accepted by --output, RACKD_OUTPUT and the config file
+const (
+	OutputTable = "table"
+	OutputWide  = "wide"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+func validOutput(format string) error {
+	switch format {
+	case OutputTable, OutputWide, OutputJSON, OutputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q: use table, wide, json or yaml", format)
+}
+
+// Render prints v in the configured output format. json and yaml print v as
+// the server returned it, so scripts see every field; table and wide call
+// table, with wide set for --output wide.
+func Render(v interface{}, table func(wide bool)) error {
+	format := LoadConfig().Output
+	if err := validOutput(format); err != nil {
+		return err
+	}
+
+	switch format {
+	case OutputJSON:
+		PrintJSON(v)
+	case OutputYAML:
+		PrintYAML(v)
+	default:
+		table(format == OutputWide)
+	}
+	return nil
+}
+
+// Column is one column of a table. Value defaults to the item's Field; Wide
+// columns are only shown with --output wide.
+type Column struct {
+	Header string
+	Field  string
+	Value  func(item map[string]interface{}) string
+	Wide   bool
+}
+
+// PrintList renders a list response as a table of columns, or as is for json
+// and yaml
+func PrintList(items []map[string]interface{}, columns []Column) error {
+	return Render(items, func(wide bool) {
+		PrintTable(items, columns, wide)
+	})
+}
+
+// PrintTable prints items as aligned columns, leaving out wide columns unless
+// wide is set
+func PrintTable(items []map[string]interface{}, columns []Column, wide bool) {
+	var shown []Column
+	for _, col := range columns {
+		if !col.Wide || wide {
+			shown = append(shown, col)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(shown))
+	for i, col := range shown {
+		headers[i] = col.Header
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+	cells := make([]string, len(shown))
+	for _, item := range items {
+		for i, col := range shown {
+			if col.Value != nil {
+				cells[i] = col.Value(item)
+			} else {
+				cells[i] = FormatValue(item[col.Field])
+			}
+			// A tab or newline in a value would break the alignment
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cells[i])
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+}
+
+// FormatValue formats a decoded JSON value for a table cell. Whole numbers
+// print without a fraction, lists are comma separated and objects print as
+// compact JSON.
+func FormatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			return strconv.FormatInt(int64(val), 10)
+		}
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case []interface{}:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = FormatValue(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		data, _ := json.Marshal(val)
+		return string(data)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// PrintJSON prints data as indented JSON without HTML escaping, so values
+// come out exactly as the server sent them
+func PrintJSON(data interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(data)
+}
+
+// PrintYAML prints data as block YAML with sorted keys. Typed values are
+// converted through JSON first so they use their JSON field names.
+func PrintYAML(data interface{}) {
+	var v interface{}
+	raw, err := json.Marshal(data)
+	if err == nil {
+		err = json.Unmarshal(raw, &v)
+	}
+	if err != nil {
+		v = data
+	}
+
+	var b strings.Builder
+	writeYAML(&b, v, 0)
+	fmt.Print(b.String())
+}
+
+// writeYAML writes v at the given indent. Scalars and empty collections are
+// written inline by the caller's line; everything else ends with a newline.
+func writeYAML(b *strings.Builder, v interface{}, indent int) {
+	prefix := strings.Repeat("  ", indent)
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 {
+			b.WriteString(prefix + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			b.WriteString(prefix + yamlString(k) + ":")
+			writeYAMLChild(b, val[k], indent+1)
+		}
+	case []interface{}:
+		if len(val) == 0 {
+			b.WriteString(prefix + "[]\n")
+			return
+		}
+		for _, item := range val {
+			b.WriteString(prefix + "-")
+			if m, ok := item.(map[string]interface{}); ok && len(m) > 0 {
+				// The first key shares the dash's line
+				var nested strings.Builder
+				writeYAML(&nested, m, indent+1)
+				b.WriteString(" " + strings.TrimPrefix(nested.String(), prefix+"  "))
+				continue
+			}
+			writeYAMLChild(b, item, indent+1)
+		}
+	default:
+		b.WriteString(prefix + yamlScalar(val) + "\n")
+	}
+}
+
+// writeYAMLChild writes the value after a "key:" or "-"
+func writeYAMLChild(b *strings.Builder, v interface{}, indent int) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+	case []interface{}:
+		if len(val) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+	default:
+		b.WriteString(" " + yamlScalar(val) + "\n")
+		return
+	}
+	b.WriteString("\n")
+	writeYAML(b, v, indent)
+}
+
+func yamlScalar(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(val)
+	case float64:
+		return FormatValue(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// yamlString quotes a map key only when it would not read back as the same
+// plain string. The YAML 1.1 booleans are quoted too, for older parsers.
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "", "true", "false", "null", "~", "yes", "no", "on", "off":
+		return strconv.Quote(s)
+	}
+	if strings.ContainsAny(s, ":#{}[],&*!|>'\"%@` \t\n") || strings.ContainsAny(s[:1], "-?") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/paularlott/cli"
+)
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	fn()
+	w.Close()
+	os.Stdout = old
+
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestPrintTable_Wide(t *testing.T) {
+	items := []map[string]interface{}{
+		{"id": "d1", "name": "web-01", "status": "active", "tags": []interface{}{"a", "b"}, "port": float64(8080)},
+	}
+	columns := []Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "PORT", Field: "port"},
+		{Header: "TAGS", Field: "tags", Wide: true},
+	}
+
+	out := captureStdout(t, func() { PrintTable(items, columns, false) })
+	if strings.Contains(out, "TAGS") {
+		t.Errorf("expected no wide columns, got:\n%s", out)
+	}
+	if !strings.Contains(out, "8080") || strings.Contains(out, "8080.") {
+		t.Errorf("expected whole number without fraction, got:\n%s", out)
+	}
+
+	out = captureStdout(t, func() { PrintTable(items, columns, true) })
+	if !strings.Contains(out, "TAGS") || !strings.Contains(out, "a,b") {
+		t.Errorf("expected wide columns, got:\n%s", out)
+	}
+}
+
+func TestRender(t *testing.T) {
+	defer os.Unsetenv("RACKD_OUTPUT")
+	data := map[string]interface{}{"name": "<web>", "addresses": []interface{}{map[string]interface{}{"ip": "10.0.0.1", "port": float64(22)}}}
+
+	os.Setenv("RACKD_OUTPUT", "json")
+	out := captureStdout(t, func() {
+		if err := Render(data, func(bool) { t.Error("table called for json output") }); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json output is not valid: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, `"<web>"`) {
+		t.Errorf("expected unescaped HTML characters, got %s", out)
+	}
+
+	os.Setenv("RACKD_OUTPUT", "yaml")
+	out = captureStdout(t, func() { Render(data, nil) })
+	want := "addresses:\n  - ip: \"10.0.0.1\"\n    port: 22\nname: \"<web>\"\n"
+	if out != want {
+		t.Errorf("unexpected yaml output:\n%s\nwant:\n%s", out, want)
+	}
+
+	os.Setenv("RACKD_OUTPUT", "wide")
+	var wide bool
+	captureStdout(t, func() { Render(data, func(w bool) { wide = w }) })
+	if !wide {
+		t.Error("expected wide table for --output wide")
+	}
+
+	os.Setenv("RACKD_OUTPUT", "xml")
+	if err := Render(data, func(bool) {}); err == nil {
+		t.Error("expected an error for an unknown output format")
+	}
+}
+
+func TestPrintYAML_Typed(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name"`
+		Empty []string `json:"empty"`
+		Zero  int      `json:"zero"`
+		Nil   *string  `json:"nil"`
+	}
+	out := captureStdout(t, func() { PrintYAML([]item{{Name: "yes", Empty: []string{}}}) })
+	want := "- empty: []\n  name: \"yes\"\n  nil: null\n  zero: 0\n"
+	if out != want {
+		t.Errorf("unexpected yaml output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestApplyFlags_Output(t *testing.T) {
+	defer func() { flagOverrides = overrides{} }()
+
+	run := func(sub func() *cli.Command, args ...string) error {
+		app := &cli.Command{
+			Name:  "rackd",
+			Flags: Flags(),
+			PreRun: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+				return ctx, ApplyFlags(cmd)
+			},
+			Commands: []*cli.Command{sub()},
+		}
+		old := os.Args
+		defer func() { os.Args = old }()
+		os.Args = append([]string{"rackd"}, args...)
+		return app.Execute(context.Background())
+	}
+
+	var output string
+	list := func() *cli.Command {
+		return &cli.Command{
+			Name: "list",
+			Run: func(ctx context.Context, cmd *cli.Command) error {
+				output = LoadConfig().Output
+				return nil
+			},
+		}
+	}
+	if err := run(list, "--output", "wide", "list"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if output != OutputWide {
+		t.Errorf("expected wide output, got %q", output)
+	}
+
+	if err := run(list, "list", "--output", "xml"); err == nil {
+		t.Error("expected an error for an unknown output format")
+	}
+
+	// A command's own --output flag is a file path, not a format
+	export := func() *cli.Command {
+		return &cli.Command{
+			Name:  "export",
+			Flags: []cli.Flag{&cli.StringFlag{Name: "output"}},
+			Run: func(ctx context.Context, cmd *cli.Command) error {
+				output = LoadConfig().Output
+				return nil
+			},
+		}
+	}
+	if err := run(export, "export", "--output", "devices.json"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if output != OutputTable {
+		t.Errorf("expected the default output format, got %q", output)
+	}
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/paularlott/cli"
+)
+
+// Resource is an inventory type that commands can take by name as well as by
+// ID, with shell completion of the names
+type Resource struct {
+	Kind string // singular name, used for the argument and in messages
+	Path string // API collection path
+}
+
+var (
+	Devices     = Resource{Kind: "device", Path: "/api/devices"}
+	Networks    = Resource{Kind: "network", Path: "/api/networks"}
+	Datacenters = Resource{Kind: "datacenter", Path: "/api/datacenters"}
+)
+
+// completionTimeout keeps a slow or unreachable server from hanging the shell
+const completionTimeout = 3 * time.Second
+
+// Arg is the optional positional argument naming the resource, completed from
+// the server
+func (r Resource) Arg() cli.Argument {
+	return &cli.StringArg{
+		Name:           r.Kind,
+		Usage:          fmt.Sprintf("%s name or ID", strings.ToUpper(r.Kind[:1])+r.Kind[1:]),
+		CompletionFunc: r.Complete,
+	}
+}
+
+// IDFlag is the --id flag of commands that also take the resource's Arg
+func (r Resource) IDFlag() cli.Flag {
+	return &cli.StringFlag{Name: "id", Usage: fmt.Sprintf("%s ID (instead of the %s argument)", strings.ToUpper(r.Kind[:1])+r.Kind[1:], r.Kind)}
+}
+
+// Complete lists the resource's names for shell completion, with the ID as
+// the description. Errors are ignored: completion just offers nothing.
+func (r Resource) Complete(ctx context.Context, cmd *cli.Command) []cli.CompletionItem {
+	cfg := LoadConfig()
+	cfg.Timeout = completionTimeout.String()
+	c := NewClient(cfg)
+
+	resp, err := c.DoRequest("GET", r.Path+"?limit=1000", nil)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	var items []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil
+	}
+
+	completions := make([]cli.CompletionItem, 0, len(items))
+	for _, item := range items {
+		if name := GetString(item, "name"); name != "" && !strings.ContainsAny(name, " \t") {
+			completions = append(completions, cli.CompletionItem{Value: name, Description: GetString(item, "id")})
+		}
+	}
+	return completions
+}
+
+// ID returns the ID given by --id, or resolves the name or ID given as the
+// resource's argument
+func (r Resource) ID(c *Client, cmd *cli.Command) (string, error) {
+	if id := cmd.GetString("id"); id != "" {
+		return id, nil
+	}
+	ref := cmd.GetStringArg(r.Kind)
+	if ref == "" {
+		return "", fmt.Errorf("a %s name or --id is required", r.Kind)
+	}
+	return r.Resolve(c, ref)
+}
+
+// Resolve returns the ID of the resource whose ID or name is ref. A name
+// shared by several resources is an error listing their IDs.
+func (r Resource) Resolve(c *Client, ref string) (string, error) {
+	resp, err := c.DoRequest("GET", r.Path+"/"+url.PathEscape(ref), nil)
+	if err != nil {
+		return "", err
+	}
+	// The server may look the reference up by name itself, answering with the
+	// device or with the devices sharing the name
+	var found struct {
+		ID      string                   `json:"id"`
+		Code    string                   `json:"code"`
+		Matches []map[string]interface{} `json:"matches"`
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusConflict:
+		err = json.NewDecoder(resp.Body).Decode(&found)
+	}
+	resp.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusOK {
+		if found.ID != "" {
+			return found.ID, nil
+		}
+		return ref, nil
+	}
+	if found.Code == "AMBIGUOUS_NAME" {
+		ids := make([]string, len(found.Matches))
+		for i, match := range found.Matches {
+			ids[i] = GetString(match, "id")
+		}
+		return "", ambiguousError(r.Kind, ref, ids)
+	}
+
+	resp, err = c.DoRequest("GET", "/api/search?"+url.Values{"q": {ref}}.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", HandleError(resp)
+	}
+
+	var result struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	var ids []string
+	for _, res := range result.Results {
+		item, ok := res[r.Kind].(map[string]interface{})
+		if ok && GetString(res, "type") == r.Kind && GetString(item, "name") == ref {
+			ids = append(ids, GetString(item, "id"))
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("%s %q not found", r.Kind, ref)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", ambiguousError(r.Kind, ref, ids)
+	}
+}
+
+func ambiguousError(kind, ref string, ids []string) error {
+	return fmt.Errorf("%d %ss are named %q, use --id with one of: %s", len(ids), kind, ref, strings.Join(ids, ", "))
+}
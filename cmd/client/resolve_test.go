@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func resolveServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/devices/dev-1", r.URL.Path == "/api/devices/WEB-01":
+			w.Write([]byte(`{"id":"dev-1","name":"web-01"}`))
+		case r.URL.Path == "/api/devices/db":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":"2 devices are named \"db\"","code":"AMBIGUOUS_NAME","matches":[{"id":"dev-5","name":"db"},{"id":"dev-6","name":"DB"}]}`))
+		case r.URL.Path == "/api/devices":
+			w.Write([]byte(`[{"id":"dev-1","name":"web-01"},{"id":"dev-2","name":"db 01"}]`))
+		case r.URL.Path == "/api/search":
+			w.Write([]byte(`{"results":[
+				{"type":"device","device":{"id":"dev-1","name":"web-01"}},
+				{"type":"device","device":{"id":"dev-3","name":"web"}},
+				{"type":"device","device":{"id":"dev-4","name":"web"}},
+				{"type":"network","network":{"id":"net-1","name":"web-01"}}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found","code":"NOT_FOUND"}`))
+		}
+	}))
+}
+
+func TestResource_Resolve(t *testing.T) {
+	server := resolveServer(t)
+	defer server.Close()
+	c := NewClient(&Config{ServerURL: server.URL, Timeout: "5s"})
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "dev-1", want: "dev-1"},
+		{ref: "web-01", want: "dev-1"},
+		{ref: "web", wantErr: "2 devices are named"},
+		{ref: "WEB-01", want: "dev-1"},
+		{ref: "db", wantErr: "one of: dev-5, dev-6"},
+		{ref: "missing", wantErr: "not found"},
+	}
+	for _, tt := range tests {
+		got, err := Devices.Resolve(c, tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", tt.ref, got, err, tt.want)
+		}
+	}
+}
+
+func TestResource_Complete(t *testing.T) {
+	server := resolveServer(t)
+	defer server.Close()
+	os.Setenv("RACKD_SERVER_URL", server.URL)
+	defer os.Unsetenv("RACKD_SERVER_URL")
+
+	items := Devices.Complete(context.Background(), nil)
+	// Names with spaces can't be completed as a single shell word
+	if len(items) != 1 || items[0].Value != "web-01" || items[0].Description != "dev-1" {
+		t.Errorf("unexpected completions: %+v", items)
+	}
+
+	if items := Networks.Complete(context.Background(), nil); len(items) != 0 {
+		t.Errorf("expected no completions on error, got %+v", items)
+	}
+}
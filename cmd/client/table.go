@@ -1,123 +1,92 @@
 package client
 
-import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"strings"
-	"text/tabwriter"
-)
+// DeviceColumns are the columns of device list tables
+var DeviceColumns = []Column{
+	{Header: "ID", Field: "id"},
+	{Header: "NAME", Field: "name"},
+	{Header: "MAKE/MODEL", Field: "make_model"},
+	{Header: "OS", Field: "os"},
+	{Header: "DATACENTER", Field: "datacenter_id"},
+	{Header: "KIND", Field: "kind", Wide: true},
+	{Header: "STATUS", Field: "status", Wide: true},
+	{Header: "ADDRESSES", Value: deviceAddresses, Wide: true},
+	{Header: "TAGS", Field: "tags", Wide: true},
+	{Header: "LOCATION", Field: "location", Wide: true},
+}
+
+// NetworkColumns are the columns of network list tables
+var NetworkColumns = []Column{
+	{Header: "ID", Field: "id"},
+	{Header: "NAME", Field: "name"},
+	{Header: "SUBNET", Field: "subnet"},
+	{Header: "VLAN", Field: "vlan_id"},
+	{Header: "DATACENTER", Field: "datacenter_id"},
+	{Header: "DESCRIPTION", Field: "description", Wide: true},
+	{Header: "UPDATED", Field: "updated_at", Wide: true},
+}
+
+// DatacenterColumns are the columns of datacenter list tables
+var DatacenterColumns = []Column{
+	{Header: "ID", Field: "id"},
+	{Header: "NAME", Field: "name"},
+	{Header: "LOCATION", Field: "location"},
+	{Header: "DESCRIPTION", Field: "description", Wide: true},
+	{Header: "CREATED", Field: "created_at", Wide: true},
+}
+
+// DiscoveredColumns are the columns of discovered device tables
+var DiscoveredColumns = []Column{
+	{Header: "ID", Field: "id"},
+	{Header: "IP", Field: "ip"},
+	{Header: "HOSTNAME", Field: "hostname"},
+	{Header: "STATUS", Field: "status"},
+	{Header: "MAC", Field: "mac_address", Wide: true},
+	{Header: "VENDOR", Field: "vendor", Wide: true},
+	{Header: "OS", Field: "os_guess", Wide: true},
+	{Header: "LAST SEEN", Field: "last_seen", Wide: true},
+}
+
+// ConflictColumns are the columns of conflict tables
+var ConflictColumns = []Column{
+	{Header: "ID", Field: "id"},
+	{Header: "TYPE", Field: "type"},
+	{Header: "STATUS", Field: "status"},
+	{Header: "DESCRIPTION", Field: "description"},
+	{Header: "IP", Field: "ip_address", Wide: true},
+	{Header: "DETECTED", Field: "detected_at", Wide: true},
+}
 
 func PrintDeviceTable(devices []map[string]interface{}) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tMAKE/MODEL\tOS\tDATACENTER")
-	for _, d := range devices {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-			getString(d, "id"),
-			getString(d, "name"),
-			getString(d, "make_model"),
-			getString(d, "os"),
-			getString(d, "datacenter_id"))
-	}
-	w.Flush()
+	PrintTable(devices, DeviceColumns, false)
 }
 
 func PrintNetworkTable(networks []map[string]interface{}) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tSUBNET\tVLAN\tDATACENTER")
-	for _, n := range networks {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\n",
-			getString(n, "id"),
-			getString(n, "name"),
-			getString(n, "subnet"),
-			n["vlan_id"],
-			getString(n, "datacenter_id"))
-	}
-	w.Flush()
+	PrintTable(networks, NetworkColumns, false)
 }
 
 func PrintDatacenterTable(datacenters []map[string]interface{}) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tLOCATION")
-	for _, dc := range datacenters {
-		fmt.Fprintf(w, "%s\t%s\t%s\n",
-			getString(dc, "id"),
-			getString(dc, "name"),
-			getString(dc, "location"))
-	}
-	w.Flush()
+	PrintTable(datacenters, DatacenterColumns, false)
 }
 
 func PrintDiscoveredTable(devices []map[string]interface{}) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tIP\tHOSTNAME\tSTATUS")
-	for _, d := range devices {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-			getString(d, "id"),
-			getString(d, "ip"),
-			getString(d, "hostname"),
-			getString(d, "status"))
-	}
-	w.Flush()
+	PrintTable(devices, DiscoveredColumns, false)
 }
 
 func PrintConflictTable(conflicts []map[string]interface{}) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tTYPE\tSTATUS\tDESCRIPTION")
-	for _, c := range conflicts {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-			getString(c, "id"),
-			getString(c, "type"),
-			getString(c, "status"),
-			getString(c, "description"))
-	}
-	w.Flush()
+	PrintTable(conflicts, ConflictColumns, false)
 }
 
-func PrintJSON(data interface{}) {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	enc.Encode(data)
-}
-
-func PrintYAML(data interface{}) {
-	printYAMLValue(data, 0)
-}
-
-func printYAMLValue(v interface{}, indent int) {
-	prefix := strings.Repeat("  ", indent)
-	switch val := v.(type) {
-	case map[string]interface{}:
-		for k, v := range val {
-			fmt.Printf("%s%s:", prefix, k)
-			if isScalar(v) {
-				fmt.Print(" ")
-				printYAMLValue(v, 0)
-			} else {
-				fmt.Println()
-				printYAMLValue(v, indent+1)
+// deviceAddresses lists a device's address IPs
+func deviceAddresses(d map[string]interface{}) string {
+	var ips []interface{}
+	if addrs, ok := d["addresses"].([]interface{}); ok {
+		for _, a := range addrs {
+			if addr, ok := a.(map[string]interface{}); ok {
+				ips = append(ips, addr["ip"])
 			}
 		}
-	case []interface{}:
-		for _, item := range val {
-			fmt.Printf("%s- ", prefix)
-			printYAMLValue(item, indent+1)
-		}
-	case string:
-		fmt.Printf("%q\n", val)
-	case nil:
-		fmt.Println("null")
-	default:
-		fmt.Printf("%v\n", val)
-	}
-}
-
-func isScalar(v interface{}) bool {
-	switch v.(type) {
-	case map[string]interface{}, []interface{}:
-		return false
 	}
-	return true
+	return FormatValue(ips)
 }
 
 func GetString(m map[string]interface{}, key string) string {
@@ -0,0 +1,110 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/cli"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "cloud",
+		Usage: "Cloud provider sync commands",
+		Commands: []*cli.Command{
+			ProvidersCommand(),
+			SyncCommand(),
+		},
+	}
+}
+
+func ProvidersCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "providers",
+		Usage: "List configured cloud providers",
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			resp, err := c.DoRequest("GET", "/api/cloud/providers", nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var providers []model.CloudProvider
+			if err := json.NewDecoder(resp.Body).Decode(&providers); err != nil {
+				return err
+			}
+
+			if len(providers) == 0 {
+				fmt.Println("No cloud providers configured")
+				return nil
+			}
+			for _, p := range providers {
+				fmt.Println(p.Name)
+			}
+			return nil
+		},
+	}
+}
+
+func SyncCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "Sync servers from cloud providers into the inventory",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "provider", Usage: "Provider to sync (hetzner/hetzner-robot/aws/k8s-<cluster>, default all)"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			path := "/api/cloud/sync"
+			if provider := cmd.GetString("provider"); provider != "" {
+				path += "?provider=" + url.QueryEscape(provider)
+			}
+
+			resp, err := c.DoRequest("POST", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var results []model.CloudSyncResult
+			if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+				return err
+			}
+
+			return client.Render(results, func(bool) {
+				if len(results) == 0 {
+					fmt.Println("No cloud providers configured")
+					return
+				}
+				for _, r := range results {
+					fmt.Printf("%s: %d created, %d updated, %d unchanged, %d missing, %d errors\n",
+						r.Provider, len(r.Created), len(r.Updated), r.Unchanged, len(r.Missing), len(r.Errors))
+					for _, name := range r.Missing {
+						fmt.Printf("  missing: %s\n", name)
+					}
+					for _, e := range r.Errors {
+						fmt.Printf("  error: %s\n", e)
+					}
+				}
+			})
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package cloud
+
+import "testing"
+
+func TestCommandStructure(t *testing.T) {
+	cmd := Command()
+
+	if cmd.Name != "cloud" {
+		t.Errorf("expected command name 'cloud', got %q", cmd.Name)
+	}
+
+	expectedSubcommands := []string{"providers", "sync"}
+	if len(cmd.Commands) != len(expectedSubcommands) {
+		t.Fatalf("expected %d subcommands, got %d", len(expectedSubcommands), len(cmd.Commands))
+	}
+	for i, expected := range expectedSubcommands {
+		if cmd.Commands[i].Name != expected {
+			t.Errorf("subcommand %d: expected %q, got %q", i, expected, cmd.Commands[i].Name)
+		}
+	}
+}
+
+func TestSyncCommandFlags(t *testing.T) {
+	cmd := SyncCommand()
+
+	if len(cmd.Flags) != 1 {
+		t.Errorf("expected 1 flag, got %d", len(cmd.Flags))
+	}
+}
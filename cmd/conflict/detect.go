@@ -17,7 +17,6 @@ func DetectCommand() *cli.Command {
 		Usage: "Detect conflicts in the infrastructure",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "type", Usage: "Type of conflict to detect (duplicate_ip, overlapping_subnet), omit for both"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -54,19 +53,12 @@ func DetectCommand() *cli.Command {
 			var conflicts []map[string]interface{}
 			json.Unmarshal(conflictsJSON, &conflicts)
 
-			fmt.Printf("Detection complete. Found %d conflict(s).\n", len(conflicts))
-
-			if len(conflicts) > 0 {
-				switch cmd.GetString("output") {
-				case "json":
-					client.PrintJSON(conflicts)
-				case "yaml":
-					client.PrintYAML(conflicts)
-				default:
-					client.PrintConflictTable(conflicts)
+			return client.Render(conflicts, func(wide bool) {
+				fmt.Printf("Detection complete. Found %d conflict(s).\n", len(conflicts))
+				if len(conflicts) > 0 {
+					client.PrintTable(conflicts, client.ConflictColumns, wide)
 				}
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -16,7 +16,6 @@ func GetCommand() *cli.Command {
 		Usage: "Get a conflict by ID",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "id", Usage: "Conflict ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -38,15 +37,9 @@ func GetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(conflict)
-			case "yaml":
-				client.PrintYAML(conflict)
-			default:
+			return client.Render(conflict, func(bool) {
 				printConflictDetail(conflict)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -17,7 +17,6 @@ func ListCommand() *cli.Command {
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "type", Usage: "Filter by conflict type (duplicate_ip, overlapping_subnet)"},
 			&cli.StringFlag{Name: "status", Usage: "Filter by status (active, resolved, ignored)"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -51,15 +50,7 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(conflicts)
-			case "yaml":
-				client.PrintYAML(conflicts)
-			default:
-				client.PrintConflictTable(conflicts)
-			}
-			return nil
+			return client.PrintList(conflicts, client.ConflictColumns)
 		},
 	}
 }
@@ -20,7 +20,6 @@ func CreateCommand() *cli.Command {
 			&cli.BoolFlag{Name: "required", Usage: "Mark field as required"},
 			&cli.StringSliceFlag{Name: "options", Usage: "Options for select type (comma-separated or multiple flags)"},
 			&cli.StringFlag{Name: "description", Usage: "Field description"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -54,13 +53,9 @@ func CreateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(field)
-			default:
+			return client.Render(field, func(bool) {
 				client.PrintYAML(field)
-			}
-			return nil
+			})
 		},
 	}
 }
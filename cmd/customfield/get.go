@@ -16,7 +16,6 @@ func GetCommand() *cli.Command {
 		Usage: "Get a custom field definition by ID",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "id", Usage: "Custom field ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -38,13 +37,9 @@ func GetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(field)
-			default:
+			return client.Render(field, func(bool) {
 				client.PrintYAML(field)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -72,11 +67,12 @@ func TypesCommand() *cli.Command {
 				return err
 			}
 
-			fmt.Println("Available custom field types:")
-			for _, t := range types {
-				fmt.Printf("  - %s (%s)\n", t["value"], t["label"])
-			}
-			return nil
+			return client.Render(types, func(bool) {
+				fmt.Println("Available custom field types:")
+				for _, t := range types {
+					fmt.Printf("  - %s (%s)\n", t["value"], t["label"])
+				}
+			})
 		},
 	}
 }
@@ -18,7 +18,6 @@ func ListCommand() *cli.Command {
 		Usage: "List all custom field definitions",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "type", Usage: "Filter by field type (text/number/boolean/select)"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -44,20 +43,15 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(fields)
-			case "yaml":
-				client.PrintYAML(fields)
-			default:
-				printFieldTable(fields)
-			}
-			return nil
+			return client.Render(fields, func(wide bool) {
+				printFieldTable(fields, wide)
+			})
 		},
 	}
 }
 
-func printFieldTable(fields []map[string]interface{}) {
+// printFieldTable truncates IDs unless wide is set
+func printFieldTable(fields []map[string]interface{}, wide bool) {
 	if len(fields) == 0 {
 		println("No custom fields found")
 		return
@@ -67,7 +61,7 @@ func printFieldTable(fields []map[string]interface{}) {
 	fmt.Fprintln(w, "ID\tNAME\tKEY\tTYPE\tREQUIRED")
 	for _, f := range fields {
 		id := getString(f, "id")
-		if len(id) > 8 {
+		if len(id) > 8 && !wide {
 			id = id[:8]
 		}
 		required := "no"
@@ -21,7 +21,6 @@ func UpdateCommand() *cli.Command {
 			&cli.BoolFlag{Name: "required", Usage: "Mark field as required"},
 			&cli.StringSliceFlag{Name: "options", Usage: "Options for select type"},
 			&cli.StringFlag{Name: "description", Usage: "Field description"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -63,13 +62,9 @@ func UpdateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(field)
-			default:
+			return client.Render(field, func(bool) {
 				client.PrintYAML(field)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -19,16 +19,19 @@ func AddCommand() *cli.Command {
 			&cli.StringFlag{Name: "name", Usage: "Datacenter name", Required: true},
 			&cli.StringFlag{Name: "description", Usage: "Datacenter description"},
 			&cli.StringFlag{Name: "location", Usage: "Location"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
+			&cli.StringFlag{Name: "shipping-address", Usage: "Address to ship parts to"},
+			&cli.StringFlag{Name: "access-instructions", Usage: "How to get into the site"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
 
 			dc := model.Datacenter{
-				Name:        cmd.GetString("name"),
-				Description: cmd.GetString("description"),
-				Location:    cmd.GetString("location"),
+				Name:               cmd.GetString("name"),
+				Description:        cmd.GetString("description"),
+				Location:           cmd.GetString("location"),
+				ShippingAddress:    cmd.GetString("shipping-address"),
+				AccessInstructions: cmd.GetString("access-instructions"),
 			}
 
 			resp, err := c.DoRequest("POST", "/api/datacenters", dc)
@@ -46,14 +49,11 @@ func AddCommand() *cli.Command {
 				return err
 			}
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(created)
-			} else {
+			return client.Render(created, func(bool) {
 				fmt.Printf("Datacenter created successfully\n")
 				fmt.Printf("ID: %s\n", created["id"])
 				fmt.Printf("Name: %s\n", created["name"])
-			}
-			return nil
+			})
 		},
 	}
 }
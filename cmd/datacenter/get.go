@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"text/tabwriter"
 
 	"github.com/martinsuchenak/rackd/cmd/client"
 	"github.com/paularlott/cli"
@@ -12,16 +14,19 @@ import (
 
 func GetCommand() *cli.Command {
 	return &cli.Command{
-		Name:  "get",
-		Usage: "Get a datacenter by ID",
+		Name:      "get",
+		Usage:     "Get a datacenter by name or ID",
+		Arguments: []cli.Argument{client.Datacenters.Arg()},
 		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "id", Usage: "Datacenter ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
+			client.Datacenters.IDFlag(),
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
-			dcID := cmd.GetString("id")
+			dcID, err := client.Datacenters.ID(c, cmd)
+			if err != nil {
+				return err
+			}
 
 			resp, err := c.DoRequest("GET", "/api/datacenters/"+dcID, nil)
 			if err != nil {
@@ -38,15 +43,9 @@ func GetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(dc)
-			case "yaml":
-				client.PrintYAML(dc)
-			default:
+			return client.Render(dc, func(bool) {
 				printDatacenterDetail(dc)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -56,6 +55,26 @@ func printDatacenterDetail(dc map[string]interface{}) {
 	fmt.Printf("Name:        %s\n", getString(dc, "name"))
 	fmt.Printf("Description: %s\n", getString(dc, "description"))
 	fmt.Printf("Location:    %s\n", getString(dc, "location"))
+	if v := getString(dc, "shipping_address"); v != "" {
+		fmt.Printf("\nShipping address:\n%s\n", v)
+	}
+	if v := getString(dc, "access_instructions"); v != "" {
+		fmt.Printf("\nAccess instructions:\n%s\n", v)
+	}
+
+	contacts, _ := dc["contacts"].([]interface{})
+	if len(contacts) == 0 {
+		return
+	}
+	fmt.Println("\nContacts (escalation order):")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  ORDER\tROLE\tNAME\tPHONE\tEMAIL")
+	for _, raw := range contacts {
+		c, _ := raw.(map[string]interface{})
+		order, _ := c["escalation_order"].(float64)
+		fmt.Fprintf(w, "  %d\t%s\t%s\t%s\t%s\n", int(order), getString(c, "role"), getString(c, "name"), getString(c, "phone"), getString(c, "email"))
+	}
+	w.Flush()
 }
 
 func getString(m map[string]interface{}, key string) string {
@@ -13,9 +13,6 @@ func ListCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "list",
 		Usage: "List all datacenters",
-		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
-		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
@@ -35,15 +32,7 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(datacenters)
-			case "yaml":
-				client.PrintYAML(datacenters)
-			default:
-				client.PrintDatacenterTable(datacenters)
-			}
-			return nil
+			return client.PrintList(datacenters, client.DatacenterColumns)
 		},
 	}
 }
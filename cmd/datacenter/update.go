@@ -12,19 +12,24 @@ import (
 
 func UpdateCommand() *cli.Command {
 	return &cli.Command{
-		Name:  "update",
-		Usage: "Update a datacenter",
+		Name:      "update",
+		Usage:     "Update a datacenter",
+		Arguments: []cli.Argument{client.Datacenters.Arg()},
 		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "id", Usage: "Datacenter ID", Required: true},
+			client.Datacenters.IDFlag(),
 			&cli.StringFlag{Name: "name", Usage: "Datacenter name"},
 			&cli.StringFlag{Name: "description", Usage: "Datacenter description"},
 			&cli.StringFlag{Name: "location", Usage: "Location"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
+			&cli.StringFlag{Name: "shipping-address", Usage: "Address to ship parts to"},
+			&cli.StringFlag{Name: "access-instructions", Usage: "How to get into the site"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
-			dcID := cmd.GetString("id")
+			dcID, err := client.Datacenters.ID(c, cmd)
+			if err != nil {
+				return err
+			}
 
 			updates := make(map[string]interface{})
 			if v := cmd.GetString("name"); v != "" {
@@ -36,6 +41,12 @@ func UpdateCommand() *cli.Command {
 			if v := cmd.GetString("location"); v != "" {
 				updates["location"] = v
 			}
+			if cmd.HasFlag("shipping-address") {
+				updates["shipping_address"] = cmd.GetString("shipping-address")
+			}
+			if cmd.HasFlag("access-instructions") {
+				updates["access_instructions"] = cmd.GetString("access-instructions")
+			}
 
 			resp, err := c.DoRequest("PUT", "/api/datacenters/"+dcID, updates)
 			if err != nil {
@@ -52,12 +63,9 @@ func UpdateCommand() *cli.Command {
 				return err
 			}
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(updated)
-			} else {
+			return client.Render(updated, func(bool) {
 				fmt.Println("Datacenter updated successfully")
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/paularlott/cli"
+
+	_ "modernc.org/sqlite"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "db",
+		Usage: "Database maintenance",
+		Commands: []*cli.Command{
+			checkCommand(),
+			optimizeCommand(),
+		},
+	}
+}
+
+func checkCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "check",
+		Usage: "Check database integrity, foreign keys and orphaned rows",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "data-dir", Usage: "Data directory", DefaultValue: "./data"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			db, err := openDB(dataDir(cmd))
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			report, err := storage.CheckDatabase(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			for _, line := range report.Integrity {
+				fmt.Printf("Integrity: %s\n", line)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			if len(report.ForeignKeyViolations) > 0 {
+				fmt.Fprintln(w, "\nTABLE\tPARENT\tVIOLATIONS")
+				for _, v := range report.ForeignKeyViolations {
+					fmt.Fprintf(w, "%s\t%s\t%d\n", v.Table, v.Parent, v.Count)
+				}
+			}
+			fmt.Fprintln(w, "\nTABLE\tCOLUMN\tREFERENCES\tORPHANS")
+			for _, o := range report.Orphans {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", o.Table, o.Column, o.References, o.Count)
+			}
+			w.Flush()
+
+			if !report.OK {
+				return fmt.Errorf("database check found problems")
+			}
+			fmt.Println("\nDatabase OK")
+			return nil
+		},
+	}
+}
+
+func optimizeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "optimize",
+		Usage: "Reclaim free space with VACUUM and refresh statistics with ANALYZE",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "data-dir", Usage: "Data directory", DefaultValue: "./data"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			db, err := openDB(dataDir(cmd))
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			result, err := storage.OptimizeDatabase(ctx, db)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Database optimized: %.1f MB -> %.1f MB in %dms\n",
+				float64(result.SizeBefore)/1024/1024, float64(result.SizeAfter)/1024/1024, result.Duration)
+			return nil
+		},
+	}
+}
+
+func dataDir(cmd *cli.Command) string {
+	if dir := cmd.GetString("data-dir"); dir != "" {
+		return dir
+	}
+	return config.Load().DataDir
+}
+
+func openDB(dataDir string) (*sql.DB, error) {
+	dbPath := filepath.Join(dataDir, "rackd.db")
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("database not found at %s", dbPath)
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return db, nil
+}
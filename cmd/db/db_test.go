@@ -0,0 +1,49 @@
+package db
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+func TestCommandStructure(t *testing.T) {
+	cmd := Command()
+
+	if cmd.Name != "db" {
+		t.Errorf("expected command name 'db', got %q", cmd.Name)
+	}
+
+	names := map[string]bool{}
+	for _, sub := range cmd.Commands {
+		names[sub.Name] = true
+		if sub.Run == nil {
+			t.Errorf("%s: Run function should not be nil", sub.Name)
+		}
+	}
+	for _, want := range []string{"check", "optimize"} {
+		if !names[want] {
+			t.Errorf("expected a %q subcommand", want)
+		}
+	}
+}
+
+func TestOpenDB(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := openDB(dir); err == nil || !strings.Contains(err.Error(), "database not found") {
+		t.Errorf("expected a missing database error, got %v", err)
+	}
+
+	store, err := storage.NewSQLiteStorage(dir)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	store.Close()
+
+	db, err := openDB(dir)
+	if err != nil {
+		t.Fatalf("openDB(%s) failed: %v", filepath.Join(dir, "rackd.db"), err)
+	}
+	db.Close()
+}
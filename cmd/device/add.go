@@ -23,14 +23,17 @@ func AddCommand() *cli.Command {
 			&cli.StringFlag{Name: "description", Usage: "Device description"},
 			&cli.StringFlag{Name: "make-model", Usage: "Device make and model"},
 			&cli.StringFlag{Name: "os", Usage: "Operating system"},
+			&cli.StringFlag{Name: "kind", Usage: "Kind (physical, vm, container_host, network, storage, pdu, other)"},
 			&cli.StringFlag{Name: "datacenter", Usage: "Datacenter ID"},
 			&cli.StringFlag{Name: "username", Usage: "Login username"},
 			&cli.StringFlag{Name: "location", Usage: "Physical location"},
 			&cli.StringFlag{Name: "tags", Usage: "Tags (comma-separated)"},
-			&cli.StringFlag{Name: "addresses", Usage: "IP addresses (ip:port:type,...)"},
+			&cli.StringFlag{Name: "interfaces", Usage: "Network interfaces (name:type:speed:vlan:parent[@switch-port][=mac],...)"},
+			&cli.StringFlag{Name: "addresses", Usage: "IP addresses (ip:port:type[@interface][=mac],...)"},
+			&cli.StringFlag{Name: "pools", Usage: "Pool IDs to take an address from, each giving the pool's next free IP (comma-separated)"},
 			&cli.StringFlag{Name: "domains", Usage: "Domain names (comma-separated)"},
 			&cli.StringFlag{Name: "input", Usage: "Read from file (JSON)"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
+			&cli.BoolFlag{Name: "dry-run", Usage: "Validate the device without creating it"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -49,7 +52,12 @@ func AddCommand() *cli.Command {
 				device = parseDeviceFlags(cmd)
 			}
 
-			resp, err := c.DoRequest("POST", "/api/devices", device)
+			path := "/api/devices"
+			dryRun := cmd.GetBool("dry-run")
+			if dryRun {
+				path += "?dry_run=true"
+			}
+			resp, err := c.DoRequest("POST", path, device)
 			if err != nil {
 				return err
 			}
@@ -58,20 +66,31 @@ func AddCommand() *cli.Command {
 			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 				return client.HandleError(resp)
 			}
+			client.PrintWarnings(resp)
 
 			var created map[string]interface{}
 			if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
 				return err
 			}
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(created)
-			} else {
+			return client.Render(created, func(bool) {
+				if dryRun {
+					fmt.Printf("Device %s is valid\n", created["name"])
+					return
+				}
 				fmt.Printf("Device created successfully\n")
 				fmt.Printf("ID: %s\n", created["id"])
 				fmt.Printf("Name: %s\n", created["name"])
-			}
-			return nil
+				if addrs, ok := created["addresses"].([]interface{}); ok && len(addrs) > 0 {
+					ips := make([]string, 0, len(addrs))
+					for _, a := range addrs {
+						if addr, ok := a.(map[string]interface{}); ok {
+							ips = append(ips, client.GetString(addr, "ip"))
+						}
+					}
+					fmt.Printf("Addresses: %s\n", strings.Join(ips, ", "))
+				}
+			})
 		},
 	}
 }
@@ -82,6 +101,7 @@ func parseDeviceFlags(cmd *cli.Command) model.Device {
 		Description:  cmd.GetString("description"),
 		MakeModel:    cmd.GetString("make-model"),
 		OS:           cmd.GetString("os"),
+		Kind:         model.DeviceKind(cmd.GetString("kind")),
 		DatacenterID: cmd.GetString("datacenter"),
 		Username:     cmd.GetString("username"),
 		Location:     cmd.GetString("location"),
@@ -90,9 +110,19 @@ func parseDeviceFlags(cmd *cli.Command) model.Device {
 	if tags := cmd.GetString("tags"); tags != "" {
 		device.Tags = strings.Split(tags, ",")
 	}
+	if ifaces := cmd.GetString("interfaces"); ifaces != "" {
+		device.Interfaces = parseInterfaces(ifaces)
+	}
 	if addrs := cmd.GetString("addresses"); addrs != "" {
 		device.Addresses = parseAddresses(addrs)
 	}
+	if pools := cmd.GetString("pools"); pools != "" {
+		for _, poolID := range strings.Split(pools, ",") {
+			if poolID = strings.TrimSpace(poolID); poolID != "" {
+				device.Addresses = append(device.Addresses, model.Address{PoolID: poolID})
+			}
+		}
+	}
 	if domains := cmd.GetString("domains"); domains != "" {
 		device.Domains = strings.Split(domains, ",")
 	}
@@ -100,14 +130,18 @@ func parseDeviceFlags(cmd *cli.Command) model.Device {
 	return device
 }
 
+// parseAddresses parses ip[:port[:type]][@interface][=mac] entries. The MAC
+// goes after "=" since it contains colons itself.
 func parseAddresses(addrs string) []model.Address {
 	var addresses []model.Address
 	for _, addrStr := range strings.Split(addrs, ",") {
-		parts := strings.Split(strings.TrimSpace(addrStr), ":")
+		addrStr, mac, _ := strings.Cut(strings.TrimSpace(addrStr), "=")
+		addrStr, iface, _ := strings.Cut(addrStr, "@")
+		parts := strings.Split(addrStr, ":")
 		if len(parts) < 1 || parts[0] == "" {
 			continue
 		}
-		addr := model.Address{IP: parts[0], Type: "ipv4"}
+		addr := model.Address{IP: parts[0], Type: "ipv4", MACAddress: strings.TrimSpace(mac), Interface: strings.TrimSpace(iface)}
 		if len(parts) > 1 {
 			if p, err := strconv.Atoi(parts[1]); err == nil && p > 0 {
 				addr.Port = &p
@@ -120,3 +154,32 @@ func parseAddresses(addrs string) []model.Address {
 	}
 	return addresses
 }
+
+// parseInterfaces parses name[:type[:speed[:vlan[:parent]]]][@switch-port][=mac]
+// entries, such as eth0:ethernet:10000::bond0@Gi1/0/1=00:11:22:33:44:55
+func parseInterfaces(ifaces string) []model.Interface {
+	var interfaces []model.Interface
+	for _, ifaceStr := range strings.Split(ifaces, ",") {
+		ifaceStr, mac, _ := strings.Cut(strings.TrimSpace(ifaceStr), "=")
+		ifaceStr, switchPort, _ := strings.Cut(ifaceStr, "@")
+		parts := strings.Split(ifaceStr, ":")
+		if parts[0] == "" {
+			continue
+		}
+		iface := model.Interface{Name: parts[0], MACAddress: strings.TrimSpace(mac), SwitchPort: strings.TrimSpace(switchPort)}
+		if len(parts) > 1 {
+			iface.Type = parts[1]
+		}
+		if len(parts) > 2 {
+			iface.SpeedMbps, _ = strconv.Atoi(parts[2])
+		}
+		if len(parts) > 3 {
+			iface.VLAN, _ = strconv.Atoi(parts[3])
+		}
+		if len(parts) > 4 {
+			iface.Parent = parts[4]
+		}
+		interfaces = append(interfaces, iface)
+	}
+	return interfaces
+}
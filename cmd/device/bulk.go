@@ -0,0 +1,135 @@
+package device
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func BulkCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "bulk",
+		Usage: "Apply changes to many devices at once, reading device IDs from stdin",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "status", Usage: "Set status (planned/ordered/active/maintenance/decommissioned)"},
+			&cli.StringFlag{Name: "description", Usage: "Set description"},
+			&cli.StringFlag{Name: "make-model", Usage: "Set make and model"},
+			&cli.StringFlag{Name: "os", Usage: "Set operating system"},
+			&cli.StringFlag{Name: "username", Usage: "Set login username"},
+			&cli.StringFlag{Name: "location", Usage: "Set physical location"},
+			&cli.StringFlag{Name: "datacenter", Usage: "Move to datacenter ID"},
+			&cli.StringFlag{Name: "add-tags", Usage: "Tags to add (comma-separated)"},
+			&cli.StringFlag{Name: "remove-tags", Usage: "Tags to remove (comma-separated)"},
+			&cli.BoolFlag{Name: "delete", Usage: "Delete the devices (requires --force)"},
+			&cli.BoolFlag{Name: "force", Usage: "Confirm --delete"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			ids, err := readDeviceIDs(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read device IDs: %w", err)
+			}
+			if len(ids) == 0 {
+				return fmt.Errorf("no device IDs given on stdin")
+			}
+
+			operations := bulkOperations(cmd)
+			if cmd.GetBool("delete") {
+				// stdin holds the IDs, so there is no way to prompt
+				if !cmd.GetBool("force") {
+					return fmt.Errorf("--delete requires --force")
+				}
+				if len(operations) > 0 {
+					return fmt.Errorf("--delete cannot be combined with other changes")
+				}
+				operations = append(operations, map[string]any{"op": "delete"})
+			}
+			if len(operations) == 0 {
+				return fmt.Errorf("nothing to do: give at least one change")
+			}
+
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			resp, err := c.DoRequest("POST", "/api/devices/bulk", map[string]any{
+				"device_ids": ids,
+				"operations": operations,
+			})
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var result struct {
+				Total int `json:"total"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+			fmt.Printf("Applied to %d devices\n", result.Total)
+			return nil
+		},
+	}
+}
+
+// bulkOperations builds the request operations from the command's flags, in
+// the order fields, tags, datacenter
+func bulkOperations(cmd *cli.Command) []map[string]any {
+	var operations []map[string]any
+
+	fields := make(map[string]string)
+	for flag, field := range map[string]string{
+		"status":      "status",
+		"description": "description",
+		"make-model":  "make_model",
+		"os":          "os",
+		"username":    "username",
+		"location":    "location",
+	} {
+		if v := cmd.GetString(flag); v != "" {
+			fields[field] = v
+		}
+	}
+	if len(fields) > 0 {
+		operations = append(operations, map[string]any{"op": "update", "fields": fields})
+	}
+	if v := cmd.GetString("add-tags"); v != "" {
+		operations = append(operations, map[string]any{"op": "add_tags", "tags": strings.Split(v, ",")})
+	}
+	if v := cmd.GetString("remove-tags"); v != "" {
+		operations = append(operations, map[string]any{"op": "remove_tags", "tags": strings.Split(v, ",")})
+	}
+	if v := cmd.GetString("datacenter"); v != "" {
+		operations = append(operations, map[string]any{"op": "move_datacenter", "datacenter_id": v})
+	}
+	return operations
+}
+
+// readDeviceIDs reads device IDs separated by whitespace or commas, so the
+// output of `rackd device list --output json | jq -r '.[].id'` can be piped in.
+// Lines starting with # are ignored.
+func readDeviceIDs(r io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, id := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, scanner.Err()
+}
@@ -12,6 +12,13 @@ func Command() *cli.Command {
 			AddCommand(),
 			UpdateCommand(),
 			DeleteCommand(),
+			MergeCommand(),
+			DuplicatesCommand(),
+			VMsCommand(),
+			LabelCommand(),
+			BulkCommand(),
+			SSHCommand(),
+			SSHConfigCommand(),
 		},
 	}
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/martinsuchenak/rackd/cmd/client"
 	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/cli"
 )
 
 func intPtr(i int) *int { return &i }
@@ -51,6 +52,22 @@ func TestParseDeviceFlags(t *testing.T) {
 				{IP: "10.0.0.1", Port: intPtr(80), Type: "ipv4"},
 			},
 		},
+		{
+			name:  "addresses with MAC",
+			input: "192.168.1.1:22:ipv4=aa:bb:cc:dd:ee:ff,10.0.0.1=AA-BB-CC-DD-EE-00",
+			expected: []model.Address{
+				{IP: "192.168.1.1", Port: intPtr(22), Type: "ipv4", MACAddress: "aa:bb:cc:dd:ee:ff"},
+				{IP: "10.0.0.1", Type: "ipv4", MACAddress: "AA-BB-CC-DD-EE-00"},
+			},
+		},
+		{
+			name:  "addresses on interfaces",
+			input: "192.168.1.1:22:ipv4@eth0=aa:bb:cc:dd:ee:ff,10.0.0.1@bond0",
+			expected: []model.Address{
+				{IP: "192.168.1.1", Port: intPtr(22), Type: "ipv4", Interface: "eth0", MACAddress: "aa:bb:cc:dd:ee:ff"},
+				{IP: "10.0.0.1", Type: "ipv4", Interface: "bond0"},
+			},
+		},
 		{
 			name:     "empty input",
 			input:    "",
@@ -78,6 +95,54 @@ func TestParseDeviceFlags(t *testing.T) {
 				if addr.Type != tt.expected[i].Type {
 					t.Errorf("address %d: expected Type %s, got %s", i, tt.expected[i].Type, addr.Type)
 				}
+				if addr.MACAddress != tt.expected[i].MACAddress {
+					t.Errorf("address %d: expected MAC %q, got %q", i, tt.expected[i].MACAddress, addr.MACAddress)
+				}
+				if addr.Interface != tt.expected[i].Interface {
+					t.Errorf("address %d: expected interface %q, got %q", i, tt.expected[i].Interface, addr.Interface)
+				}
+			}
+		})
+	}
+}
+
+func TestParseInterfaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []model.Interface
+	}{
+		{
+			name:     "name only",
+			input:    "eth0",
+			expected: []model.Interface{{Name: "eth0"}},
+		},
+		{
+			name:  "full entries",
+			input: "bond0:bond:20000,eth0:ethernet:10000::bond0@Gi1/0/1=aa:bb:cc:dd:ee:ff, vlan10:vlan:0:10:bond0",
+			expected: []model.Interface{
+				{Name: "bond0", Type: "bond", SpeedMbps: 20000},
+				{Name: "eth0", Type: "ethernet", SpeedMbps: 10000, Parent: "bond0", SwitchPort: "Gi1/0/1", MACAddress: "aa:bb:cc:dd:ee:ff"},
+				{Name: "vlan10", Type: "vlan", VLAN: 10, Parent: "bond0"},
+			},
+		},
+		{
+			name:     "empty input",
+			input:    "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseInterfaces(tt.input)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %d interfaces, got %d", len(tt.expected), len(result))
+			}
+			for i, iface := range result {
+				if iface != tt.expected[i] {
+					t.Errorf("interface %d: expected %+v, got %+v", i, tt.expected[i], iface)
+				}
 			}
 		})
 	}
@@ -127,11 +192,11 @@ func TestCommandStructure(t *testing.T) {
 		t.Errorf("expected command name 'device', got %q", cmd.Name)
 	}
 
-	if len(cmd.Commands) != 5 {
-		t.Errorf("expected 5 subcommands, got %d", len(cmd.Commands))
+	if len(cmd.Commands) != 12 {
+		t.Errorf("expected 12 subcommands, got %d", len(cmd.Commands))
 	}
 
-	expectedSubcommands := []string{"list", "get", "add", "update", "delete"}
+	expectedSubcommands := []string{"list", "get", "add", "update", "delete", "merge", "duplicates", "vms", "label", "bulk", "ssh", "ssh-config"}
 	for i, expected := range expectedSubcommands {
 		if cmd.Commands[i].Name != expected {
 			t.Errorf("subcommand %d: expected %q, got %q", i, expected, cmd.Commands[i].Name)
@@ -159,8 +224,8 @@ func TestGetCommandStructure(t *testing.T) {
 		t.Errorf("expected command name 'get', got %q", cmd.Name)
 	}
 
-	if len(cmd.Flags) < 2 {
-		t.Errorf("expected at least 2 flags (id, output), got %d", len(cmd.Flags))
+	if len(cmd.Flags) < 1 {
+		t.Errorf("expected at least 1 flag (id), got %d", len(cmd.Flags))
 	}
 }
 
@@ -201,6 +266,36 @@ func TestDeleteCommandStructure(t *testing.T) {
 	}
 }
 
+func TestMergeCommandStructure(t *testing.T) {
+	cmd := MergeCommand()
+
+	if cmd.Name != "merge" {
+		t.Errorf("expected command name 'merge', got %q", cmd.Name)
+	}
+
+	required := map[string]bool{}
+	for _, f := range cmd.Flags {
+		if sf, ok := f.(*cli.StringFlag); ok && sf.Required {
+			required[sf.Name] = true
+		}
+	}
+	if !required["id"] || !required["source"] {
+		t.Errorf("expected required id and source flags, got %v", required)
+	}
+}
+
+func TestLabelCommandStructure(t *testing.T) {
+	cmd := LabelCommand()
+
+	if cmd.Name != "label" {
+		t.Errorf("expected command name 'label', got %q", cmd.Name)
+	}
+
+	if len(cmd.Flags) != 5 {
+		t.Errorf("expected 5 flags, got %d", len(cmd.Flags))
+	}
+}
+
 func TestOutputFormats_JSON(t *testing.T) {
 	devices := []map[string]interface{}{
 		{"id": "1", "name": "server1", "make_model": "Dell", "os": "Ubuntu", "datacenter_id": "dc1"},
@@ -337,3 +432,79 @@ func TestMockAPIIntegration(t *testing.T) {
 		t.Errorf("expected 204, got %d", resp.StatusCode)
 	}
 }
+
+func TestReadDeviceIDs(t *testing.T) {
+	input := "dev-1\n# comment\n\ndev-2, dev-3\tdev-4\n"
+	ids, err := readDeviceIDs(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readDeviceIDs failed: %v", err)
+	}
+	expected := []string{"dev-1", "dev-2", "dev-3", "dev-4"}
+	if len(ids) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("id %d: expected %q, got %q", i, expected[i], ids[i])
+		}
+	}
+}
+
+func TestSSHAddress(t *testing.T) {
+	addrs := []model.Address{
+		{IP: "192.168.1.10", Label: "data"},
+		{IP: "10.0.0.10", Label: "Management", Port: intPtr(2222)},
+	}
+
+	addr, err := sshAddress(addrs, "")
+	if err != nil || addr.IP != "10.0.0.10" {
+		t.Errorf("expected the management address, got %v, %v", addr, err)
+	}
+	addr, err = sshAddress(addrs, "data")
+	if err != nil || addr.IP != "192.168.1.10" {
+		t.Errorf("expected the data address, got %v, %v", addr, err)
+	}
+	if _, err := sshAddress(addrs, "oob"); err == nil || !strings.Contains(err.Error(), "data, Management") {
+		t.Errorf("expected an error listing the labels, got %v", err)
+	}
+
+	addr, err = sshAddress(addrs[:1], "")
+	if err != nil || addr.IP != "192.168.1.10" {
+		t.Errorf("expected a fallback to the first address, got %v, %v", addr, err)
+	}
+	if _, err := sshAddress(nil, ""); err == nil {
+		t.Error("expected an error for a device without addresses")
+	}
+}
+
+func TestSSHArgs(t *testing.T) {
+	if got := strings.Join(sshArgs("admin", "10.0.0.10", 2222), " "); got != "-p 2222 admin@10.0.0.10" {
+		t.Errorf("unexpected args %q", got)
+	}
+	if got := strings.Join(sshArgs("", "10.0.0.10", 0), " "); got != "10.0.0.10" {
+		t.Errorf("unexpected args %q", got)
+	}
+}
+
+func TestWriteSSHConfig(t *testing.T) {
+	devices := []model.Device{
+		{ID: "1", Name: "web-01", Username: "admin", Addresses: []model.Address{{IP: "10.0.0.10", Label: "management", Port: intPtr(2222)}}},
+		{ID: "2", Name: "db-01"},
+		{ID: "3", Name: "bad name", Addresses: []model.Address{{IP: "10.0.0.12"}}},
+		{ID: "4", Name: "web-01", Addresses: []model.Address{{IP: "10.0.0.13"}}},
+	}
+
+	var buf bytes.Buffer
+	writeSSHConfig(&buf, devices, "")
+	out := buf.String()
+	for _, want := range []string{
+		"Host web-01\n    HostName 10.0.0.10\n    User admin\n    Port 2222\n",
+		"# Skipped db-01: device has no addresses",
+		`# Skipped "bad name" (3)`,
+		"# Skipped web-01 (4): duplicate name",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func DuplicatesCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "duplicates",
+		Usage: "List devices that look like the same host, to merge with rackd device merge",
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			resp, err := c.DoRequest("GET", "/api/devices/duplicates", nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var candidates []map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&candidates); err != nil {
+				return err
+			}
+
+			return client.PrintList(candidates, duplicateColumns)
+		},
+	}
+}
+
+var duplicateColumns = []client.Column{
+	{Header: "KEEP ID", Field: "device_id"},
+	{Header: "KEEP", Field: "device_name"},
+	{Header: "DUPLICATE ID", Field: "duplicate_id"},
+	{Header: "DUPLICATE", Field: "duplicate_name"},
+	{Header: "REASONS", Field: "reasons"},
+	{Header: "MATCHES", Field: "matches", Wide: true},
+}
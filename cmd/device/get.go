@@ -12,16 +12,19 @@ import (
 
 func GetCommand() *cli.Command {
 	return &cli.Command{
-		Name:  "get",
-		Usage: "Get a device by ID",
+		Name:      "get",
+		Usage:     "Get a device by name or ID",
+		Arguments: []cli.Argument{client.Devices.Arg()},
 		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "id", Usage: "Device ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
+			client.Devices.IDFlag(),
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
-			deviceID := cmd.GetString("id")
+			deviceID, err := client.Devices.ID(c, cmd)
+			if err != nil {
+				return err
+			}
 
 			resp, err := c.DoRequest("GET", "/api/devices/"+deviceID, nil)
 			if err != nil {
@@ -38,15 +41,9 @@ func GetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(device)
-			case "yaml":
-				client.PrintYAML(device)
-			default:
+			return client.Render(device, func(bool) {
 				printDeviceDetail(device)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -57,6 +54,7 @@ func printDeviceDetail(d map[string]interface{}) {
 	fmt.Printf("Description: %s\n", getString(d, "description"))
 	fmt.Printf("Make/Model:  %s\n", getString(d, "make_model"))
 	fmt.Printf("OS:          %s\n", getString(d, "os"))
+	fmt.Printf("Kind:        %s\n", getString(d, "kind"))
 	fmt.Printf("Datacenter:  %s\n", getString(d, "datacenter_id"))
 	fmt.Printf("Location:    %s\n", getString(d, "location"))
 	fmt.Printf("Username:    %s\n", getString(d, "username"))
@@ -72,6 +70,31 @@ func printDeviceDetail(d map[string]interface{}) {
 		fmt.Println()
 	}
 
+	if ifaces, ok := d["interfaces"].([]interface{}); ok && len(ifaces) > 0 {
+		fmt.Println("Interfaces:")
+		for _, i := range ifaces {
+			if iface, ok := i.(map[string]interface{}); ok {
+				fmt.Printf("  - %s (%s)", getString(iface, "name"), getString(iface, "type"))
+				if mac := getString(iface, "mac_address"); mac != "" {
+					fmt.Printf(" %s", mac)
+				}
+				if speed, ok := iface["speed_mbps"].(float64); ok && speed > 0 {
+					fmt.Printf(" %d Mbps", int(speed))
+				}
+				if vlan, ok := iface["vlan"].(float64); ok && vlan > 0 {
+					fmt.Printf(" vlan %d", int(vlan))
+				}
+				if parent := getString(iface, "parent"); parent != "" {
+					fmt.Printf(" in %s", parent)
+				}
+				if port := getString(iface, "switch_port"); port != "" {
+					fmt.Printf(" on %s", port)
+				}
+				fmt.Println()
+			}
+		}
+	}
+
 	if addrs, ok := d["addresses"].([]interface{}); ok && len(addrs) > 0 {
 		fmt.Println("Addresses:")
 		for _, a := range addrs {
@@ -83,6 +106,9 @@ func printDeviceDetail(d map[string]interface{}) {
 				if t := getString(addr, "type"); t != "" {
 					fmt.Printf(" (%s)", t)
 				}
+				if iface := getString(addr, "interface"); iface != "" {
+					fmt.Printf(" on %s", iface)
+				}
 				fmt.Println()
 			}
 		}
@@ -0,0 +1,71 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func LabelCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "label",
+		Usage:     "Download a printable asset label, or just the QR code, for a device",
+		Arguments: []cli.Argument{client.Devices.Arg()},
+		Flags: []cli.Flag{
+			client.Devices.IDFlag(),
+			&cli.StringFlag{Name: "output", Usage: "Output file (default: <device-id>-label.svg, or -qrcode.<format> with --qrcode)"},
+			&cli.StringFlag{Name: "asset-field", Usage: "Custom field holding the asset tag", DefaultValue: "asset_tag"},
+			&cli.BoolFlag{Name: "qrcode", Usage: "Download only the QR code"},
+			&cli.StringFlag{Name: "format", Usage: "QR code format: png or svg", DefaultValue: "png"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+			deviceID, err := client.Devices.ID(c, cmd)
+			if err != nil {
+				return err
+			}
+
+			path := "/api/devices/" + url.PathEscape(deviceID) + "/label?asset_field=" + url.QueryEscape(cmd.GetString("asset-field"))
+			output := deviceID + "-label.svg"
+			if cmd.GetBool("qrcode") {
+				format := cmd.GetString("format")
+				path = "/api/devices/" + url.PathEscape(deviceID) + "/qrcode?format=" + url.QueryEscape(format)
+				output = deviceID + "-qrcode." + format
+			}
+			if o := cmd.GetString("output"); o != "" {
+				output = o
+			}
+
+			resp, err := c.DoRequest("GET", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			if _, err := io.Copy(f, resp.Body); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Printf("Saved %s\n", output)
+			return nil
+		},
+	}
+}
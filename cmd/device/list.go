@@ -21,9 +21,10 @@ func ListCommand() *cli.Command {
 			&cli.StringFlag{Name: "datacenter", Usage: "Filter by datacenter ID"},
 			&cli.StringFlag{Name: "network", Usage: "Filter by network ID"},
 			&cli.StringFlag{Name: "pool", Usage: "Filter by pool ID"},
-			&cli.StringFlag{Name: "status", Usage: "Filter by status (planned, active, maintenance, decommissioned)"},
+			&cli.StringFlag{Name: "status", Usage: "Filter by status (planned, ordered, active, maintenance, decommissioned)"},
+			&cli.StringFlag{Name: "kind", Usage: "Filter by kind (physical, vm, container_host, network, storage, pdu, other)"},
+			&cli.StringFlag{Name: "warranty-expiring", Usage: "Only devices whose warranty expires within this many days (e.g. 90d)"},
 			&cli.IntFlag{Name: "limit", Usage: "Limit number of results"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -48,6 +49,12 @@ func ListCommand() *cli.Command {
 			if status := cmd.GetString("status"); status != "" {
 				params.Set("status", status)
 			}
+			if kind := cmd.GetString("kind"); kind != "" {
+				params.Set("kind", kind)
+			}
+			if within := cmd.GetString("warranty-expiring"); within != "" {
+				params.Set("warranty_expiring_within", within)
+			}
 			if limit := cmd.GetInt("limit"); limit > 0 {
 				params.Set("limit", fmt.Sprintf("%d", limit))
 			}
@@ -72,15 +79,7 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(devices)
-			case "yaml":
-				client.PrintYAML(devices)
-			default:
-				client.PrintDeviceTable(devices)
-			}
-			return nil
+			return client.PrintList(devices, client.DeviceColumns)
 		},
 	}
 }
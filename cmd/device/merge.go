@@ -0,0 +1,64 @@
+package device
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func MergeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "merge",
+		Usage: "Merge a duplicate device into another",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "id", Usage: "Device ID to keep", Required: true},
+			&cli.StringFlag{Name: "source", Usage: "Duplicate device ID to merge and decommission", Required: true},
+			&cli.BoolFlag{Name: "force", Usage: "Skip confirmation"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+			deviceID := cmd.GetString("id")
+			sourceID := cmd.GetString("source")
+
+			if !cmd.GetBool("force") {
+				fmt.Printf("Merge device %s into %s and decommission %s? [y/N]: ", sourceID, deviceID, sourceID)
+				reader := bufio.NewReader(os.Stdin)
+				confirm, _ := reader.ReadString('\n')
+				confirm = strings.TrimSpace(strings.ToLower(confirm))
+				if confirm != "y" && confirm != "yes" {
+					fmt.Println("Merge cancelled")
+					return nil
+				}
+			}
+
+			path := "/api/devices/" + url.PathEscape(deviceID) + "/merge?source=" + url.QueryEscape(sourceID)
+			resp, err := c.DoRequest("POST", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var device map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+				return err
+			}
+
+			return client.Render(device, func(bool) {
+				printDeviceDetail(device)
+			})
+		},
+	}
+}
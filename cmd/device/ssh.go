@@ -0,0 +1,240 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/cli"
+)
+
+// defaultSSHLabel is the address label ssh connects to unless --label is given
+const defaultSSHLabel = "management"
+
+func SSHCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "ssh",
+		Usage:     "Open an SSH session to a device",
+		Arguments: []cli.Argument{client.Devices.Arg()},
+		Flags: []cli.Flag{
+			client.Devices.IDFlag(),
+			&cli.StringFlag{Name: "label", Usage: "Address label to connect to (default: management, else the first address)"},
+			&cli.IntFlag{Name: "port", Usage: "SSH port (default: the address's port, else ssh's default)"},
+			&cli.StringFlag{Name: "user", Usage: "Login user (default: the device's username)"},
+			&cli.BoolFlag{Name: "print", Usage: "Print the ssh command instead of running it"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+			deviceID, err := client.Devices.ID(c, cmd)
+			if err != nil {
+				return err
+			}
+
+			resp, err := c.DoRequest("GET", "/api/devices/"+url.PathEscape(deviceID), nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var device model.Device
+			if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+				return err
+			}
+
+			addr, err := sshAddress(device.Addresses, cmd.GetString("label"))
+			if err != nil {
+				return fmt.Errorf("%s: %w", device.Name, err)
+			}
+			user := cmd.GetString("user")
+			if user == "" {
+				user = device.Username
+			}
+			port := cmd.GetInt("port")
+			if port == 0 && addr.Port != nil {
+				port = *addr.Port
+			}
+			args := sshArgs(user, addr.IP, port)
+
+			if cmd.GetBool("print") {
+				fmt.Println("ssh " + strings.Join(args, " "))
+				return nil
+			}
+
+			ssh := exec.CommandContext(ctx, "ssh", args...)
+			ssh.Stdin, ssh.Stdout, ssh.Stderr = os.Stdin, os.Stdout, os.Stderr
+			if err := ssh.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					// ssh has reported the failure itself; pass its status on
+					os.Exit(exitErr.ExitCode())
+				}
+				return fmt.Errorf("failed to run ssh: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+func SSHConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ssh-config",
+		Usage: "Export an ssh_config snippet for all devices",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "label", Usage: "Address label to connect to (default: management, else the first address)"},
+			&cli.StringFlag{Name: "datacenter", Usage: "Only include devices in this datacenter"},
+			&cli.StringFlag{Name: "tags", Usage: "Only include devices with these tags (comma-separated)"},
+			&cli.StringFlag{Name: "file", Usage: "Write the snippet to a file instead of stdout"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			params := url.Values{}
+			if dc := cmd.GetString("datacenter"); dc != "" {
+				params.Set("datacenter_id", dc)
+			}
+			if tags := cmd.GetString("tags"); tags != "" {
+				params.Set("tags", tags)
+			}
+
+			devices, err := listAllDevices(c, params)
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = os.Stdout
+			if path := cmd.GetString("file"); path != "" {
+				f, err := os.Create(path)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer f.Close()
+				w = f
+			}
+			writeSSHConfig(w, devices, cmd.GetString("label"))
+			return nil
+		},
+	}
+}
+
+// listAllDevices fetches every page of the devices matching params
+func listAllDevices(c *client.Client, params url.Values) ([]model.Device, error) {
+	var all []model.Device
+	params.Set("limit", strconv.Itoa(model.MaxPageSize))
+	for offset := 0; ; {
+		params.Set("offset", strconv.Itoa(offset))
+		resp, err := c.DoRequest("GET", "/api/devices?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []model.Device
+		if resp.StatusCode != http.StatusOK {
+			err = client.HandleError(resp)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&page)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < model.MaxPageSize {
+			return all, nil
+		}
+		offset += len(page)
+	}
+}
+
+// sshAddress picks the address labelled label. Without a label it prefers
+// the management address and falls back to the first address.
+func sshAddress(addrs []model.Address, label string) (model.Address, error) {
+	want := label
+	if want == "" {
+		want = defaultSSHLabel
+	}
+	for _, a := range addrs {
+		if strings.EqualFold(a.Label, want) && a.IP != "" {
+			return a, nil
+		}
+	}
+
+	if label != "" {
+		var labels []string
+		for _, a := range addrs {
+			if a.Label != "" {
+				labels = append(labels, a.Label)
+			}
+		}
+		if len(labels) == 0 {
+			return model.Address{}, fmt.Errorf("no address labelled %q", label)
+		}
+		return model.Address{}, fmt.Errorf("no address labelled %q (labels: %s)", label, strings.Join(labels, ", "))
+	}
+	for _, a := range addrs {
+		if a.IP != "" {
+			return a, nil
+		}
+	}
+	return model.Address{}, errors.New("device has no addresses")
+}
+
+func sshArgs(user, ip string, port int) []string {
+	var args []string
+	if port > 0 {
+		args = append(args, "-p", strconv.Itoa(port))
+	}
+	target := ip
+	if user != "" {
+		target = user + "@" + ip
+	}
+	return append(args, target)
+}
+
+// writeSSHConfig writes a Host entry for each device with an address to
+// connect to. Devices whose name can't be a Host alias, repeats a name
+// already written, or that have no suitable address, are listed as comments.
+func writeSSHConfig(w io.Writer, devices []model.Device, label string) {
+	fmt.Fprintln(w, "# Generated by rackd device ssh-config")
+	written := make(map[string]bool)
+	for _, d := range devices {
+		if d.Name == "" || strings.ContainsAny(d.Name, " \t*?!,\"") {
+			fmt.Fprintf(w, "\n# Skipped %q (%s): name is not a valid Host alias\n", d.Name, d.ID)
+			continue
+		}
+		if written[d.Name] {
+			// ssh would only ever use the first entry
+			fmt.Fprintf(w, "\n# Skipped %s (%s): duplicate name\n", d.Name, d.ID)
+			continue
+		}
+		addr, err := sshAddress(d.Addresses, label)
+		if err != nil {
+			fmt.Fprintf(w, "\n# Skipped %s: %v\n", d.Name, err)
+			continue
+		}
+
+		written[d.Name] = true
+		fmt.Fprintf(w, "\nHost %s\n", d.Name)
+		fmt.Fprintf(w, "    HostName %s\n", addr.IP)
+		if d.Username != "" {
+			fmt.Fprintf(w, "    User %s\n", d.Username)
+		}
+		if addr.Port != nil && *addr.Port > 0 {
+			fmt.Fprintf(w, "    Port %d\n", *addr.Port)
+		}
+	}
+}
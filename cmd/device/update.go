@@ -13,25 +13,32 @@ import (
 
 func UpdateCommand() *cli.Command {
 	return &cli.Command{
-		Name:  "update",
-		Usage: "Update a device",
+		Name:      "update",
+		Usage:     "Update a device",
+		Arguments: []cli.Argument{client.Devices.Arg()},
 		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "id", Usage: "Device ID", Required: true},
+			client.Devices.IDFlag(),
 			&cli.StringFlag{Name: "name", Usage: "Device name"},
 			&cli.StringFlag{Name: "description", Usage: "Device description"},
 			&cli.StringFlag{Name: "make-model", Usage: "Device make and model"},
 			&cli.StringFlag{Name: "os", Usage: "Operating system"},
+			&cli.StringFlag{Name: "kind", Usage: "Kind (physical, vm, container_host, network, storage, pdu, other)"},
 			&cli.StringFlag{Name: "datacenter", Usage: "Datacenter ID"},
 			&cli.StringFlag{Name: "username", Usage: "Login username"},
 			&cli.StringFlag{Name: "location", Usage: "Physical location"},
 			&cli.StringFlag{Name: "tags", Usage: "Tags (comma-separated)"},
+			&cli.StringFlag{Name: "interfaces", Usage: "Replace network interfaces (name:type:speed:vlan:parent[@switch-port][=mac],...)"},
+			&cli.StringFlag{Name: "addresses", Usage: "Replace IP addresses (ip:port:type[@interface][=mac],...)"},
 			&cli.StringFlag{Name: "domains", Usage: "Domain names (comma-separated)"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
+			&cli.BoolFlag{Name: "dry-run", Usage: "Validate the changes without saving them"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
-			deviceID := cmd.GetString("id")
+			deviceID, err := client.Devices.ID(c, cmd)
+			if err != nil {
+				return err
+			}
 
 			updates := make(map[string]interface{})
 			if v := cmd.GetString("name"); v != "" {
@@ -46,6 +53,9 @@ func UpdateCommand() *cli.Command {
 			if v := cmd.GetString("os"); v != "" {
 				updates["os"] = v
 			}
+			if v := cmd.GetString("kind"); v != "" {
+				updates["kind"] = v
+			}
 			if v := cmd.GetString("datacenter"); v != "" {
 				updates["datacenter_id"] = v
 			}
@@ -58,11 +68,22 @@ func UpdateCommand() *cli.Command {
 			if v := cmd.GetString("tags"); v != "" {
 				updates["tags"] = strings.Split(v, ",")
 			}
+			if v := cmd.GetString("interfaces"); v != "" {
+				updates["interfaces"] = parseInterfaces(v)
+			}
+			if v := cmd.GetString("addresses"); v != "" {
+				updates["addresses"] = parseAddresses(v)
+			}
 			if v := cmd.GetString("domains"); v != "" {
 				updates["domains"] = strings.Split(v, ",")
 			}
 
-			resp, err := c.DoRequest("PUT", "/api/devices/"+deviceID, updates)
+			path := "/api/devices/" + deviceID
+			dryRun := cmd.GetBool("dry-run")
+			if dryRun {
+				path += "?dry_run=true"
+			}
+			resp, err := c.DoRequest("PUT", path, updates)
 			if err != nil {
 				return err
 			}
@@ -71,18 +92,20 @@ func UpdateCommand() *cli.Command {
 			if resp.StatusCode != http.StatusOK {
 				return client.HandleError(resp)
 			}
+			client.PrintWarnings(resp)
 
 			var updated map[string]interface{}
 			if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
 				return err
 			}
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(updated)
-			} else {
+			return client.Render(updated, func(bool) {
+				if dryRun {
+					fmt.Println("Device changes are valid")
+					return
+				}
 				fmt.Println("Device updated successfully")
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -0,0 +1,54 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func VMsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "vms",
+		Usage:     "List the virtual machines hosted on a device",
+		Arguments: []cli.Argument{client.Devices.Arg()},
+		Flags: []cli.Flag{
+			client.Devices.IDFlag(),
+			&cli.IntFlag{Name: "limit", Usage: "Limit number of results"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+			deviceID, err := client.Devices.ID(c, cmd)
+			if err != nil {
+				return err
+			}
+
+			path := "/api/devices/" + url.PathEscape(deviceID) + "/vms"
+			if limit := cmd.GetInt("limit"); limit > 0 {
+				path += fmt.Sprintf("?limit=%d", limit)
+			}
+
+			resp, err := c.DoRequest("GET", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var devices []map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&devices); err != nil {
+				return err
+			}
+
+			return client.PrintList(devices, client.DeviceColumns)
+		},
+	}
+}
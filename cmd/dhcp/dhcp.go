@@ -0,0 +1,85 @@
+package dhcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/paularlott/cli"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "dhcp",
+		Usage: "DHCP configuration export",
+		Commands: []*cli.Command{
+			ReservationsCommand(),
+		},
+	}
+}
+
+func ReservationsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "reservations",
+		Usage: "Export static DHCP reservations for devices with a MAC and IPv4 address",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Usage: "Output format (dnsmasq/kea/isc)", DefaultValue: "dnsmasq"},
+			&cli.StringFlag{Name: "network-id", Usage: "Only include addresses in this network"},
+			&cli.StringFlag{Name: "datacenter-id", Usage: "Only include devices in this datacenter"},
+			&cli.StringFlag{Name: "tags", Usage: "Only include devices with these tags (comma-separated)"},
+			&cli.StringFlag{Name: "file", Usage: "Write the reservations to a file instead of stdout"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			c := client.NewClient(client.LoadConfig())
+
+			query := url.Values{}
+			query.Set("format", cmd.GetString("format"))
+			if v := cmd.GetString("network-id"); v != "" {
+				query.Set("network_id", v)
+			}
+			if v := cmd.GetString("datacenter-id"); v != "" {
+				query.Set("datacenter_id", v)
+			}
+			for _, tag := range strings.Split(cmd.GetString("tags"), ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					query.Add("tags", tag)
+				}
+			}
+
+			resp, err := c.DoRequest("GET", "/api/dhcp/reservations?"+query.Encode(), nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			file := cmd.GetString("file")
+			if file == "" {
+				_, err = io.Copy(os.Stdout, resp.Body)
+				return err
+			}
+
+			f, err := os.Create(file)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", file, err)
+			}
+			if _, err := io.Copy(f, resp.Body); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "DHCP reservations written to %s\n", file)
+			return nil
+		},
+	}
+}
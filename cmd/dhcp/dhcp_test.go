@@ -0,0 +1,17 @@
+package dhcp
+
+import "testing"
+
+func TestCommandStructure(t *testing.T) {
+	cmd := Command()
+
+	if cmd.Name != "dhcp" {
+		t.Fatalf("expected command name 'dhcp', got %q", cmd.Name)
+	}
+	if len(cmd.Commands) != 1 || cmd.Commands[0].Name != "reservations" {
+		t.Fatalf("expected a single 'reservations' subcommand, got %d", len(cmd.Commands))
+	}
+	if len(cmd.Commands[0].Flags) != 5 {
+		t.Errorf("expected 5 flags, got %d", len(cmd.Commands[0].Flags))
+	}
+}
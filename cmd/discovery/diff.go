@@ -0,0 +1,104 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/cli"
+)
+
+func DiffCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "diff",
+		Usage: "Compare discovery results against documented inventory",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "network", Usage: "Network ID", Required: true},
+			&cli.IntFlag{Name: "stale-days", Usage: "Days without a sighting before a device is reported missing"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			path := "/api/networks/" + url.PathEscape(cmd.GetString("network")) + "/discovery/diff"
+			if days := cmd.GetInt("stale-days"); days > 0 {
+				path += "?stale_days=" + fmt.Sprintf("%d", days)
+			}
+
+			resp, err := c.DoRequest("GET", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var diff model.DiscoveryDiff
+			if err := json.NewDecoder(resp.Body).Decode(&diff); err != nil {
+				return err
+			}
+
+			return client.Render(diff, func(bool) {
+				printDiff(&diff)
+			})
+		},
+	}
+}
+
+func printDiff(diff *model.DiscoveryDiff) {
+	fmt.Printf("Undocumented hosts (%d)\n", len(diff.Undocumented))
+	if len(diff.Undocumented) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tIP\tHOSTNAME\tLAST SEEN")
+		for _, d := range diff.Undocumented {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.ID, d.IP, d.Hostname, d.LastSeen.Format("2006-01-02 15:04"))
+		}
+		w.Flush()
+	}
+
+	fmt.Printf("\nMissing devices (%d, not seen in %d days)\n", len(diff.Missing), diff.StaleDays)
+	if len(diff.Missing) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tIPS\tLAST SEEN")
+		for _, m := range diff.Missing {
+			lastSeen := "never"
+			if m.LastSeen != nil {
+				lastSeen = m.LastSeen.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.DeviceID, m.DeviceName, strings.Join(m.IPs, ","), lastSeen)
+		}
+		w.Flush()
+	}
+
+	fmt.Printf("\nDrift (%d)\n", len(diff.Drift))
+	if len(diff.Drift) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DEVICE\tIP\tFIELD\tDOCUMENTED\tOBSERVED")
+		for _, d := range diff.Drift {
+			for _, f := range d.Fields {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.DeviceName, d.IP, f.Field, f.Documented, f.Observed)
+			}
+		}
+		w.Flush()
+	}
+
+	fmt.Printf("\nExpiring certificates (%d)\n", len(diff.Certificates))
+	if len(diff.Certificates) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DEVICE\tIP\tPORT\tCOMMON NAME\tEXPIRES\tDAYS LEFT")
+		for _, c := range diff.Certificates {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%d\n", c.DeviceName, c.IP, c.Port, c.Certificate.CommonName,
+				c.Certificate.NotAfter.Format("2006-01-02"), c.DaysLeft)
+		}
+		w.Flush()
+	}
+}
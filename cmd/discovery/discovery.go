@@ -10,6 +10,9 @@ func Command() *cli.Command {
 			ScanCommand(),
 			ListCommand(),
 			PromoteCommand(),
+			IgnoreCommand(),
+			DiffCommand(),
+			SwitchPortsCommand(),
 		},
 	}
 }
@@ -9,11 +9,11 @@ func TestCommandStructure(t *testing.T) {
 		t.Errorf("expected command name 'discovery', got %q", cmd.Name)
 	}
 
-	if len(cmd.Commands) != 3 {
-		t.Errorf("expected 3 subcommands, got %d", len(cmd.Commands))
+	if len(cmd.Commands) != 6 {
+		t.Errorf("expected 6 subcommands, got %d", len(cmd.Commands))
 	}
 
-	expectedSubcommands := []string{"scan", "list", "promote"}
+	expectedSubcommands := []string{"scan", "list", "promote", "ignore", "diff", "switch-ports"}
 	for i, expected := range expectedSubcommands {
 		if cmd.Commands[i].Name != expected {
 			t.Errorf("subcommand %d: expected %q, got %q", i, expected, cmd.Commands[i].Name)
@@ -56,3 +56,39 @@ func TestPromoteCommandFlags(t *testing.T) {
 		t.Errorf("expected 2 flags, got %d", len(cmd.Flags))
 	}
 }
+
+func TestIgnoreCommandFlags(t *testing.T) {
+	cmd := IgnoreCommand()
+
+	if cmd.Name != "ignore" {
+		t.Errorf("expected command name 'ignore', got %q", cmd.Name)
+	}
+
+	if len(cmd.Flags) != 4 {
+		t.Errorf("expected 4 flags, got %d", len(cmd.Flags))
+	}
+}
+
+func TestDiffCommandFlags(t *testing.T) {
+	cmd := DiffCommand()
+
+	if cmd.Name != "diff" {
+		t.Errorf("expected command name 'diff', got %q", cmd.Name)
+	}
+
+	if len(cmd.Flags) != 2 {
+		t.Errorf("expected 2 flags, got %d", len(cmd.Flags))
+	}
+}
+
+func TestSwitchPortsCommandFlags(t *testing.T) {
+	cmd := SwitchPortsCommand()
+
+	if cmd.Name != "switch-ports" {
+		t.Errorf("expected command name 'switch-ports', got %q", cmd.Name)
+	}
+
+	if len(cmd.Flags) != 2 {
+		t.Errorf("expected 2 flags, got %d", len(cmd.Flags))
+	}
+}
@@ -0,0 +1,70 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func IgnoreCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ignore",
+		Usage: "Ignore a discovered device, such as a DHCP guest",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "discovered-id", Usage: "Discovered device ID", Required: true},
+			&cli.StringFlag{Name: "reason", Usage: "Why the device is ignored"},
+			&cli.StringFlag{Name: "until", Usage: "RFC 3339 time the ignore expires (default: never)"},
+			&cli.BoolFlag{Name: "undo", Usage: "Stop ignoring the device"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+			path := "/api/discovery/devices/" + cmd.GetString("discovered-id") + "/ignore"
+
+			method := "POST"
+			var reqBody interface{}
+			if cmd.GetBool("undo") {
+				method = "DELETE"
+			} else {
+				body := map[string]interface{}{"reason": cmd.GetString("reason")}
+				if until := cmd.GetString("until"); until != "" {
+					if _, err := time.Parse(time.RFC3339, until); err != nil {
+						return fmt.Errorf("--until must be an RFC 3339 time, e.g. 2026-12-31T00:00:00Z")
+					}
+					body["until"] = until
+				}
+				reqBody = body
+			}
+
+			resp, err := c.DoRequest(method, path, reqBody)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var device map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+				return err
+			}
+
+			if method == "DELETE" {
+				fmt.Printf("Device %s is no longer ignored\n", device["ip"])
+				return nil
+			}
+			fmt.Printf("Device %s ignored\n", device["ip"])
+			if until, ok := device["ignored_until"]; ok {
+				fmt.Printf("Until: %s\n", until)
+			}
+			return nil
+		},
+	}
+}
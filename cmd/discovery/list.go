@@ -19,7 +19,7 @@ func ListCommand() *cli.Command {
 			&cli.StringFlag{Name: "network", Usage: "Filter by network ID"},
 			&cli.StringFlag{Name: "status", Usage: "Filter by status (online/offline/unknown)"},
 			&cli.IntFlag{Name: "limit", Usage: "Limit number of results"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
+			&cli.BoolFlag{Name: "include-ignored", Usage: "Include ignored devices"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -35,6 +35,9 @@ func ListCommand() *cli.Command {
 			if limit := cmd.GetInt("limit"); limit > 0 {
 				params.Set("limit", fmt.Sprintf("%d", limit))
 			}
+			if cmd.GetBool("include-ignored") {
+				params.Set("include_ignored", "true")
+			}
 
 			path := "/api/discovery/devices"
 			if len(params) > 0 {
@@ -56,12 +59,7 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(devices)
-			} else {
-				client.PrintDiscoveredTable(devices)
-			}
-			return nil
+			return client.PrintList(devices, client.DiscoveredColumns)
 		},
 	}
 }
@@ -0,0 +1,81 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/cli"
+)
+
+func SwitchPortsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "switch-ports",
+		Usage: "Map devices to switch ports from LLDP/CDP neighbor tables",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "credential-id", Usage: "SNMP credential ID", Required: true},
+			&cli.StringFlag{Name: "tag", Usage: "Tag marking the switches to query (default switch)"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			body := map[string]string{
+				"credential_id": cmd.GetString("credential-id"),
+				"tag":           cmd.GetString("tag"),
+			}
+
+			resp, err := c.DoRequest("POST", "/api/discovery/switch-ports/collect", body)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var report model.SwitchPortReport
+			if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+				return err
+			}
+
+			return client.Render(report, func(bool) {
+				printSwitchPorts(&report)
+			})
+		},
+	}
+}
+
+func printSwitchPorts(report *model.SwitchPortReport) {
+	fmt.Printf("Queried %d switches, created %d relationships\n", report.Switches, report.Relationships)
+
+	if len(report.Mappings) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SWITCH\tPORT\tPROTOCOL\tNEIGHBOR\tDEVICE\tSTATUS\tDOCUMENTED")
+		for _, m := range report.Mappings {
+			neighbor := m.NeighborName
+			if neighbor == "" {
+				neighbor = m.MAC
+			}
+			if neighbor == "" {
+				neighbor = m.IP
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", m.SwitchName, m.Port, m.Protocol, neighbor, m.DeviceName, m.Status, m.DocumentedPort)
+		}
+		w.Flush()
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Printf("\nErrors (%d)\n", len(report.Errors))
+		for _, e := range report.Errors {
+			fmt.Printf("  %s: %s\n", e.SwitchName, e.Error)
+		}
+	}
+}
@@ -12,6 +12,7 @@ func Command() *cli.Command {
 			SyncCommand(),
 			ImportCommand(),
 			RecordsCommand(),
+			ExportCommand(),
 		},
 	}
 }
@@ -18,7 +18,7 @@ func TestCommandStructure(t *testing.T) {
 		t.Fatalf("expected command name 'dns', got %q", cmd.Name)
 	}
 
-	expectedSubcommands := []string{"provider", "zone", "sync", "import", "records"}
+	expectedSubcommands := []string{"provider", "zone", "sync", "import", "records", "export"}
 	if len(cmd.Commands) != len(expectedSubcommands) {
 		t.Fatalf("expected %d subcommands, got %d", len(expectedSubcommands), len(cmd.Commands))
 	}
@@ -45,6 +45,11 @@ func TestProviderAndZoneCommandStructure(t *testing.T) {
 	if recordsCmd.Run == nil {
 		t.Fatal("records command should have a Run function")
 	}
+
+	exportCmd := ExportCommand()
+	if exportCmd.Run == nil || len(exportCmd.Flags) != 4 {
+		t.Fatalf("expected export command with 4 flags, got %d", len(exportCmd.Flags))
+	}
 }
 
 func TestMockDNSAPIIntegration(t *testing.T) {
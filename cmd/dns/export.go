@@ -0,0 +1,72 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func ExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Export a BIND-style zone fragment built from device addresses and domains",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "domain", Usage: "Zone domain (e.g. example.com)", Required: true},
+			&cli.IntFlag{Name: "ttl", Usage: "Default TTL written as $TTL (server default 3600)"},
+			&cli.BoolFlag{Name: "no-ptr", Usage: "Leave out reverse (PTR) records"},
+			&cli.StringFlag{Name: "file", Usage: "Write the zone fragment to a file instead of stdout"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			params := url.Values{}
+			if ttl := cmd.GetInt("ttl"); ttl > 0 {
+				params.Set("ttl", fmt.Sprint(ttl))
+			}
+			if cmd.GetBool("no-ptr") {
+				params.Set("ptr", "false")
+			}
+			path := "/api/dns/zone/" + url.PathEscape(cmd.GetString("domain"))
+			if len(params) > 0 {
+				path += "?" + params.Encode()
+			}
+
+			resp, err := c.DoRequest("GET", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			file := cmd.GetString("file")
+			if file == "" {
+				_, err = io.Copy(os.Stdout, resp.Body)
+				return err
+			}
+
+			f, err := os.Create(file)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", file, err)
+			}
+			if _, err := io.Copy(f, resp.Body); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Zone fragment written to %s\n", file)
+			return nil
+		},
+	}
+}
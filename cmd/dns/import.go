@@ -17,7 +17,6 @@ func ImportCommand() *cli.Command {
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "zone", Usage: "Zone ID", Required: true},
 			&cli.BoolFlag{Name: "delete", Usage: "Delete local records not found on provider"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -45,29 +44,24 @@ func ImportCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(result)
-			default:
+			return client.Render(result, func(bool) {
 				client.PrintYAML(result)
-			}
-
-			// Also print a human-readable summary
-			fmt.Println()
-			if recordsImported, ok := result["records_imported"].(float64); ok {
-				fmt.Printf("Records imported: %.0f\n", recordsImported)
-			}
-			if recordsUpdated, ok := result["records_updated"].(float64); ok {
-				fmt.Printf("Records updated: %.0f\n", recordsUpdated)
-			}
-			if recordsSkipped, ok := result["records_skipped"].(float64); ok {
-				fmt.Printf("Records skipped: %.0f\n", recordsSkipped)
-			}
-			if recordsDeleted, ok := result["records_deleted"].(float64); ok {
-				fmt.Printf("Records deleted: %.0f\n", recordsDeleted)
-			}
 
-			return nil
+				// Also print a human-readable summary
+				fmt.Println()
+				if recordsImported, ok := result["records_imported"].(float64); ok {
+					fmt.Printf("Records imported: %.0f\n", recordsImported)
+				}
+				if recordsUpdated, ok := result["records_updated"].(float64); ok {
+					fmt.Printf("Records updated: %.0f\n", recordsUpdated)
+				}
+				if recordsSkipped, ok := result["records_skipped"].(float64); ok {
+					fmt.Printf("Records skipped: %.0f\n", recordsSkipped)
+				}
+				if recordsDeleted, ok := result["records_deleted"].(float64); ok {
+					fmt.Printf("Records deleted: %.0f\n", recordsDeleted)
+				}
+			})
 		},
 	}
 }
@@ -35,7 +35,6 @@ func providerListCommand() *cli.Command {
 		Usage: "List DNS providers",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "type", Usage: "Filter by provider type"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -65,15 +64,9 @@ func providerListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(providers)
-			case "yaml":
-				client.PrintYAML(providers)
-			default:
+			return client.Render(providers, func(bool) {
 				printProviderTable(providers)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -84,7 +77,6 @@ func providerGetCommand() *cli.Command {
 		Usage: "Get a DNS provider by ID",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "id", Usage: "Provider ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -106,15 +98,9 @@ func providerGetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(provider)
-			case "table":
+			return client.Render(provider, func(bool) {
 				printProviderTable([]map[string]interface{}{provider})
-			default:
-				client.PrintYAML(provider)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -130,7 +116,6 @@ func providerCreateCommand() *cli.Command {
 			&cli.StringFlag{Name: "token-env", Usage: "Environment variable name containing the API token"},
 			&cli.StringFlag{Name: "token-file", Usage: "Path to a file containing the API token"},
 			&cli.StringFlag{Name: "description", Usage: "Provider description"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -177,17 +162,11 @@ func providerCreateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(created)
-			case "yaml":
-				client.PrintYAML(created)
-			default:
+			return client.Render(created, func(bool) {
 				fmt.Printf("DNS provider created successfully\n")
 				fmt.Printf("ID: %s\n", created["id"])
 				fmt.Printf("Name: %s\n", created["name"])
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -204,7 +183,6 @@ func providerUpdateCommand() *cli.Command {
 			&cli.StringFlag{Name: "token-env", Usage: "Environment variable name containing the API token"},
 			&cli.StringFlag{Name: "token-file", Usage: "Path to a file containing the API token"},
 			&cli.StringFlag{Name: "description", Usage: "Provider description"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -259,15 +237,9 @@ func providerUpdateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(provider)
-			case "table":
+			return client.Render(provider, func(bool) {
 				printProviderTable([]map[string]interface{}{provider})
-			default:
-				client.PrintYAML(provider)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -21,7 +21,6 @@ func RecordsCommand() *cli.Command {
 			&cli.StringFlag{Name: "type", Usage: "Filter by record type (A, AAAA, CNAME, MX, TXT, PTR, NS, SRV)"},
 			&cli.StringFlag{Name: "device", Usage: "Filter by device ID"},
 			&cli.StringFlag{Name: "name", Usage: "Filter by record name"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -59,26 +58,21 @@ func RecordsCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(records)
-			case "yaml":
-				client.PrintYAML(records)
-			default:
-				printRecordsTable(records)
-			}
-			return nil
+			return client.Render(records, func(wide bool) {
+				printRecordsTable(records, wide)
+			})
 		},
 	}
 }
 
-func printRecordsTable(records []map[string]interface{}) {
+// printRecordsTable truncates long values unless wide is set
+func printRecordsTable(records []map[string]interface{}, wide bool) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	fmt.Fprintln(w, "NAME\tTYPE\tVALUE\tTTL\tDEVICE")
 	for _, r := range records {
 		value := client.GetString(r, "value")
 		// Truncate long values
-		if len(value) > 40 {
+		if len(value) > 40 && !wide {
 			value = value[:37] + "..."
 		}
 		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\n",
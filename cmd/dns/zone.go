@@ -35,7 +35,6 @@ func zoneListCommand() *cli.Command {
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "provider", Usage: "Filter by provider ID"},
 			&cli.StringFlag{Name: "network", Usage: "Filter by network ID"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -68,15 +67,9 @@ func zoneListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(zones)
-			case "yaml":
-				client.PrintYAML(zones)
-			default:
+			return client.Render(zones, func(bool) {
 				printZoneTable(zones)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -87,7 +80,6 @@ func zoneGetCommand() *cli.Command {
 		Usage: "Get a DNS zone by ID",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "id", Usage: "Zone ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -109,15 +101,9 @@ func zoneGetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(zone)
-			case "table":
+			return client.Render(zone, func(bool) {
 				printZoneTable([]map[string]interface{}{zone})
-			default:
-				client.PrintYAML(zone)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -134,7 +120,6 @@ func zoneCreateCommand() *cli.Command {
 			&cli.BoolFlag{Name: "disable-auto-sync", Usage: "Disable automatic sync"},
 			&cli.BoolFlag{Name: "create-ptr", Usage: "Create PTR records"},
 			&cli.IntFlag{Name: "ttl", Usage: "Default TTL"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -175,17 +160,11 @@ func zoneCreateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(created)
-			case "yaml":
-				client.PrintYAML(created)
-			default:
+			return client.Render(created, func(bool) {
 				fmt.Printf("DNS zone created successfully\n")
 				fmt.Printf("ID: %s\n", created["id"])
 				fmt.Printf("Name: %s\n", created["name"])
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -204,7 +183,6 @@ func zoneUpdateCommand() *cli.Command {
 			&cli.BoolFlag{Name: "create-ptr", Usage: "Enable PTR record creation"},
 			&cli.BoolFlag{Name: "no-create-ptr", Usage: "Disable PTR record creation"},
 			&cli.IntFlag{Name: "ttl", Usage: "Default TTL"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -255,15 +233,9 @@ func zoneUpdateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(zone)
-			case "table":
+			return client.Render(zone, func(bool) {
 				printZoneTable([]map[string]interface{}{zone})
-			default:
-				client.PrintYAML(zone)
-			}
-			return nil
+			})
 		},
 	}
 }
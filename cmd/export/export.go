@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/paularlott/cli"
 
@@ -23,6 +25,7 @@ func Command() *cli.Command {
 			NetworksCommand(),
 			DatacentersCommand(),
 			AllCommand(),
+			RunbookCommand(),
 		},
 	}
 }
@@ -31,11 +34,16 @@ func DevicesCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "devices",
 		Usage: "Export devices",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{Name: "format", Usage: "Output format (json/csv)", DefaultValue: "json"},
 			&cli.StringFlag{Name: "output", Usage: "Output file (default: stdout)"},
-		},
+		}, sensitiveFlags()...),
 		Run: func(ctx context.Context, cmd *cli.Command) error {
+			sensitive, err := sensitiveOptions(cmd)
+			if err != nil {
+				return err
+			}
+
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
 
@@ -53,6 +61,9 @@ func DevicesCommand() *cli.Command {
 			if err := json.NewDecoder(resp.Body).Decode(&devices); err != nil {
 				return fmt.Errorf("failed to decode response: %w", err)
 			}
+			if devices, err = export.ProtectDevices(devices, sensitive); err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
 
 			format := export.Format(cmd.GetString("format"))
 			output := cmd.GetString("output")
@@ -141,11 +152,16 @@ func DatacentersCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "datacenters",
 		Usage: "Export datacenters",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{Name: "format", Usage: "Output format (json/csv)", DefaultValue: "json"},
 			&cli.StringFlag{Name: "output", Usage: "Output file (default: stdout)"},
-		},
+		}, sensitiveFlags()...),
 		Run: func(ctx context.Context, cmd *cli.Command) error {
+			sensitive, err := sensitiveOptions(cmd)
+			if err != nil {
+				return err
+			}
+
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
 
@@ -163,6 +179,9 @@ func DatacentersCommand() *cli.Command {
 			if err := json.NewDecoder(resp.Body).Decode(&datacenters); err != nil {
 				return fmt.Errorf("failed to decode response: %w", err)
 			}
+			if datacenters, err = export.ProtectDatacenters(datacenters, sensitive); err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
 
 			format := export.Format(cmd.GetString("format"))
 			output := cmd.GetString("output")
@@ -196,14 +215,18 @@ func AllCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "all",
 		Usage: "Export all data (devices, networks, datacenters)",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{Name: "format", Usage: "Output format (json only)", DefaultValue: "json"},
 			&cli.StringFlag{Name: "output", Usage: "Output file (default: stdout)"},
-		},
+		}, sensitiveFlags()...),
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			if cmd.GetString("format") != "json" {
 				return fmt.Errorf("only JSON format is supported for 'all' export")
 			}
+			sensitive, err := sensitiveOptions(cmd)
+			if err != nil {
+				return err
+			}
 
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
@@ -243,6 +266,13 @@ func AllCommand() *cli.Command {
 			}
 			resp.Body.Close()
 
+			if devices, err = export.ProtectDevices(devices, sensitive); err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
+			if datacenters, err = export.ProtectDatacenters(datacenters, sensitive); err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
+
 			// Create combined export
 			data := map[string]interface{}{
 				"devices":     devices,
@@ -279,3 +309,128 @@ func AllCommand() *cli.Command {
 		},
 	}
 }
+
+func RunbookCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "runbook",
+		Usage: "Export an offline runbook bundle (zip) for a datacenter",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{Name: "datacenter", Usage: "Datacenter ID or name", Required: true},
+			&cli.StringFlag{Name: "out", Usage: "Output zip file (default: <datacenter>.zip)"},
+		}, sensitiveFlags()...),
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			sensitive, err := sensitiveOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			var datacenters []model.Datacenter
+			if err := getJSON(c, "/api/datacenters", &datacenters); err != nil {
+				return err
+			}
+
+			ref := cmd.GetString("datacenter")
+			var dc *model.Datacenter
+			for i := range datacenters {
+				if datacenters[i].ID == ref || strings.EqualFold(datacenters[i].Name, ref) {
+					dc = &datacenters[i]
+					break
+				}
+			}
+			if dc == nil {
+				return fmt.Errorf("datacenter not found: %s", ref)
+			}
+
+			// The list leaves out contacts, so fetch the datacenter itself
+			rb := &export.Runbook{}
+			if err := getJSON(c, "/api/datacenters/"+url.PathEscape(dc.ID), &rb.Datacenter); err != nil {
+				return err
+			}
+			dcQuery := "?datacenter_id=" + url.QueryEscape(dc.ID)
+			if err := getJSON(c, "/api/datacenters/"+url.PathEscape(dc.ID)+"/devices", &rb.Devices); err != nil {
+				return err
+			}
+			if err := getJSON(c, "/api/networks"+dcQuery, &rb.Networks); err != nil {
+				return err
+			}
+			if err := getJSON(c, "/api/circuits"+dcQuery, &rb.Circuits); err != nil {
+				return err
+			}
+			if err := getJSON(c, "/api/relationships", &rb.Relationships); err != nil {
+				return err
+			}
+			if rb.Devices, err = export.ProtectDevices(rb.Devices, sensitive); err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
+			protected, err := export.ProtectDatacenters([]model.Datacenter{rb.Datacenter}, sensitive)
+			if err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
+			rb.Datacenter = protected[0]
+			if rb.Circuits, err = export.ProtectCircuits(rb.Circuits, sensitive); err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
+
+			output := cmd.GetString("out")
+			if output == "" {
+				output = dc.Name + ".zip"
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+
+			if err := export.WriteRunbook(rb, f); err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Exported runbook for %s (%d devices, %d networks, %d circuits) to %s\n",
+				dc.Name, len(rb.Devices), len(rb.Networks), len(rb.Circuits), output)
+			return nil
+		},
+	}
+}
+
+// sensitiveFlags are shared by exports that include usernames, access
+// instructions or contact details
+func sensitiveFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{Name: "omit-sensitive", Usage: "Blank sensitive fields (usernames, access instructions, contacts)"},
+		&cli.StringFlag{Name: "encrypt-to", Usage: "Encrypt sensitive fields to an age recipient (age1...)", EnvVars: []string{"RACKD_EXPORT_RECIPIENT"}},
+	}
+}
+
+func sensitiveOptions(cmd *cli.Command) (export.SensitiveOptions, error) {
+	opts := export.SensitiveOptions{Omit: cmd.GetBool("omit-sensitive")}
+	if recipient := cmd.GetString("encrypt-to"); recipient != "" {
+		if opts.Omit {
+			return opts, fmt.Errorf("--omit-sensitive and --encrypt-to cannot be used together")
+		}
+		r, err := export.ParseAgeRecipient(recipient)
+		if err != nil {
+			return opts, err
+		}
+		opts.Recipient = r
+	}
+	return opts, nil
+}
+
+func getJSON(c *client.Client, path string, v any) error {
+	resp, err := c.DoRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return client.HandleError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
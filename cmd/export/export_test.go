@@ -14,8 +14,8 @@ func TestCommand(t *testing.T) {
 	if cmd.Name != "export" {
 		t.Errorf("Name = %v, want export", cmd.Name)
 	}
-	if len(cmd.Commands) != 4 {
-		t.Errorf("expected 4 subcommands, got %d", len(cmd.Commands))
+	if len(cmd.Commands) != 5 {
+		t.Errorf("expected 5 subcommands, got %d", len(cmd.Commands))
 	}
 }
 
@@ -30,8 +30,8 @@ func TestDevicesCommand(t *testing.T) {
 	if cmd.Run == nil {
 		t.Error("Run function should not be nil")
 	}
-	if len(cmd.Flags) != 2 {
-		t.Errorf("expected 2 flags, got %d", len(cmd.Flags))
+	if len(cmd.Flags) != 4 {
+		t.Errorf("expected 4 flags, got %d", len(cmd.Flags))
 	}
 
 	hasFormat := false
@@ -75,8 +75,8 @@ func TestDatacentersCommand(t *testing.T) {
 	if cmd.Run == nil {
 		t.Error("Run function should not be nil")
 	}
-	if len(cmd.Flags) != 2 {
-		t.Errorf("expected 2 flags, got %d", len(cmd.Flags))
+	if len(cmd.Flags) != 4 {
+		t.Errorf("expected 4 flags, got %d", len(cmd.Flags))
 	}
 }
 
@@ -91,7 +91,53 @@ func TestAllCommand(t *testing.T) {
 	if cmd.Run == nil {
 		t.Error("Run function should not be nil")
 	}
-	if len(cmd.Flags) != 2 {
-		t.Errorf("expected 2 flags, got %d", len(cmd.Flags))
+	if len(cmd.Flags) != 4 {
+		t.Errorf("expected 4 flags, got %d", len(cmd.Flags))
+	}
+}
+
+func TestRunbookCommand(t *testing.T) {
+	cmd := RunbookCommand()
+	if cmd == nil {
+		t.Fatal("RunbookCommand() returned nil")
+	}
+	if cmd.Name != "runbook" {
+		t.Errorf("Name = %v, want runbook", cmd.Name)
+	}
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+	if len(cmd.Flags) != 4 {
+		t.Errorf("expected 4 flags, got %d", len(cmd.Flags))
+	}
+
+	hasDatacenter := false
+	for _, flag := range cmd.Flags {
+		if sf, ok := flag.(*cli.StringFlag); ok && sf.Name == "datacenter" {
+			hasDatacenter = true
+			if !sf.Required {
+				t.Error("datacenter flag should be required")
+			}
+		}
+	}
+	if !hasDatacenter {
+		t.Error("expected datacenter flag")
+	}
+}
+
+func TestSensitiveFlags(t *testing.T) {
+	names := map[string]bool{}
+	for _, flag := range sensitiveFlags() {
+		switch f := flag.(type) {
+		case *cli.BoolFlag:
+			names[f.Name] = true
+		case *cli.StringFlag:
+			names[f.Name] = true
+		}
+	}
+	for _, want := range []string{"omit-sensitive", "encrypt-to"} {
+		if !names[want] {
+			t.Errorf("expected %s flag", want)
+		}
 	}
 }
@@ -0,0 +1,252 @@
+package hypervisor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/cli"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "hypervisor",
+		Usage: "Hypervisor connector commands",
+		Commands: []*cli.Command{
+			ListCommand(),
+			CreateCommand(),
+			DeleteCommand(),
+			SyncCommand(),
+		},
+	}
+}
+
+func ListCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List hypervisor connectors",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "type", Usage: "Filter by type (proxmox, vsphere)"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			path := "/api/hypervisors"
+			if t := cmd.GetString("type"); t != "" {
+				path += "?type=" + url.QueryEscape(t)
+			}
+
+			resp, err := c.DoRequest("GET", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var connectors []model.HypervisorConnector
+			if err := json.NewDecoder(resp.Body).Decode(&connectors); err != nil {
+				return err
+			}
+
+			return client.Render(connectors, func(bool) {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tNAME\tTYPE\tENDPOINT\tENABLED\tLAST SYNC")
+				for _, conn := range connectors {
+					lastSync := "never"
+					if conn.LastSyncAt != nil {
+						lastSync = conn.LastSyncAt.Format("2006-01-02 15:04")
+						if conn.LastSyncError != "" {
+							lastSync += " (failed)"
+						}
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\n", conn.ID, conn.Name, conn.Type, conn.Endpoint, conn.Enabled, lastSync)
+				}
+				w.Flush()
+			})
+		},
+	}
+}
+
+func CreateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "create",
+		Usage: "Create a hypervisor connector",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "name", Usage: "Connector name, used in device tags", Required: true},
+			&cli.StringFlag{Name: "type", Usage: "Connector type (proxmox, vsphere)", Required: true},
+			&cli.StringFlag{Name: "endpoint", Usage: "API URL, e.g. https://pve1:8006 or https://vcenter", Required: true},
+			&cli.StringFlag{Name: "username", Usage: "Proxmox API token ID (user@realm!name) or vCenter user", Required: true},
+			&cli.StringFlag{Name: "secret-env", Usage: "Environment variable name containing the token secret or password"},
+			&cli.StringFlag{Name: "secret-file", Usage: "Path to a file containing the token secret or password"},
+			&cli.StringFlag{Name: "datacenter", Usage: "Datacenter ID for synced devices"},
+			&cli.BoolFlag{Name: "insecure", Usage: "Skip TLS certificate verification"},
+			&cli.BoolFlag{Name: "disabled", Usage: "Create the connector disabled"},
+			&cli.StringFlag{Name: "description", Usage: "Connector description"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			enabled := !cmd.GetBool("disabled")
+			req := model.CreateHypervisorConnectorRequest{
+				Name:               cmd.GetString("name"),
+				Type:               model.HypervisorType(cmd.GetString("type")),
+				Endpoint:           cmd.GetString("endpoint"),
+				Username:           cmd.GetString("username"),
+				InsecureSkipVerify: cmd.GetBool("insecure"),
+				DatacenterID:       cmd.GetString("datacenter"),
+				Enabled:            &enabled,
+				Description:        cmd.GetString("description"),
+			}
+			if v := cmd.GetString("secret-env"); v != "" {
+				secret := os.Getenv(v)
+				if secret == "" {
+					return fmt.Errorf("environment variable %s is empty or not set", v)
+				}
+				req.Secret = strings.TrimSpace(secret)
+			}
+			if v := cmd.GetString("secret-file"); v != "" {
+				content, err := os.ReadFile(v)
+				if err != nil {
+					return fmt.Errorf("failed to read secret file: %w", err)
+				}
+				req.Secret = strings.TrimSpace(string(content))
+			}
+			if req.Secret == "" {
+				return fmt.Errorf("--secret-env or --secret-file is required")
+			}
+
+			resp, err := c.DoRequest("POST", "/api/hypervisors", req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusCreated {
+				return client.HandleError(resp)
+			}
+
+			var created model.HypervisorConnector
+			if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+				return err
+			}
+
+			return client.Render(created, func(bool) {
+				fmt.Printf("Hypervisor connector created successfully\n")
+				fmt.Printf("ID: %s\n", created.ID)
+				fmt.Printf("Name: %s\n", created.Name)
+			})
+		},
+	}
+}
+
+func DeleteCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "delete",
+		Usage: "Delete a hypervisor connector (synced devices are kept)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "id", Usage: "Connector ID", Required: true},
+			&cli.BoolFlag{Name: "force", Usage: "Skip confirmation"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			id := cmd.GetString("id")
+
+			if !cmd.GetBool("force") {
+				fmt.Printf("Are you sure you want to delete hypervisor connector %s? [y/N]: ", id)
+				reader := bufio.NewReader(os.Stdin)
+				confirm, _ := reader.ReadString('\n')
+				confirm = strings.TrimSpace(strings.ToLower(confirm))
+				if confirm != "y" && confirm != "yes" {
+					fmt.Println("Deletion cancelled")
+					return nil
+				}
+			}
+
+			resp, err := c.DoRequest("DELETE", "/api/hypervisors/"+id, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusNoContent {
+				return client.HandleError(resp)
+			}
+
+			fmt.Println("Hypervisor connector deleted successfully")
+			return nil
+		},
+	}
+}
+
+func SyncCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "Sync hosts and VMs from hypervisor connectors into the inventory",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "id", Usage: "Connector to sync (default all enabled connectors)"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			path := "/api/hypervisors/sync"
+			id := cmd.GetString("id")
+			if id != "" {
+				path = "/api/hypervisors/" + id + "/sync"
+			}
+
+			resp, err := c.DoRequest("POST", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var results []model.HypervisorSyncResult
+			if id != "" {
+				var result model.HypervisorSyncResult
+				if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+					return err
+				}
+				results = append(results, result)
+			} else if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+				return err
+			}
+
+			return client.Render(results, func(bool) {
+				if len(results) == 0 {
+					fmt.Println("No hypervisor connectors enabled")
+					return
+				}
+				for _, r := range results {
+					fmt.Printf("%s: %d created, %d updated, %d unchanged, %d missing, %d errors\n",
+						r.Provider, len(r.Created), len(r.Updated), r.Unchanged, len(r.Missing), len(r.Errors))
+					for _, name := range r.Missing {
+						fmt.Printf("  missing: %s\n", name)
+					}
+					for _, e := range r.Errors {
+						fmt.Printf("  error: %s\n", e)
+					}
+				}
+			})
+		},
+	}
+}
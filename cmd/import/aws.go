@@ -0,0 +1,97 @@
+package importcmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/paularlott/cli"
+
+	"github.com/martinsuchenak/rackd/internal/cloud"
+)
+
+func AWSCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "aws",
+		Usage: "Import AWS EC2 instances, with their VPCs and subnets as networks",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "region", Usage: "Comma-separated regions (default AWS_REGION, the profile's region or us-east-1)"},
+			&cli.StringFlag{Name: "profile", Usage: "Profile in ~/.aws/credentials (default the AWS_ACCESS_KEY_ID environment variables, then the default profile)", EnvVars: []string{"AWS_PROFILE"}},
+			&cli.StringFlag{Name: "provider", Usage: "Provider name for tags and matching, e.g. aws-prod", DefaultValue: "aws"},
+			&cli.StringFlag{Name: "name-from", Usage: "Device name for new instances: tag (the Name tag) or id (the instance ID)", DefaultValue: "tag"},
+			&cli.StringFlag{Name: "id-field", Usage: "Text custom field to store the instance ID in"},
+			&cli.BoolFlag{Name: "dry-run", Usage: "Show the instances without importing"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			provider := cmd.GetString("provider")
+			if err := cloud.ValidProviderName(provider); err != nil {
+				return err
+			}
+			nameFrom := cmd.GetString("name-from")
+			if nameFrom != "tag" && nameFrom != "id" {
+				return fmt.Errorf("--name-from must be tag or id")
+			}
+
+			creds, err := awsCredentials(cmd.GetString("profile"))
+			if err != nil {
+				return err
+			}
+			regions := cmp.Or(cmd.GetString("region"), os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), creds.Region, "us-east-1")
+			var regionList []string
+			for _, r := range strings.Split(regions, ",") {
+				if r = strings.TrimSpace(r); r != "" {
+					regionList = append(regionList, r)
+				}
+			}
+
+			ec2 := cloud.NewAWSEC2(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken, regionList)
+			ec2.IncludeNetworks()
+			instances, err := ec2.ListServers(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list instances: %w", err)
+			}
+
+			idField := cmd.GetString("id-field")
+			for i := range instances {
+				if nameFrom == "id" {
+					instances[i].Name = instances[i].ID
+				}
+				if idField != "" {
+					instances[i].CustomFields = map[string]string{idField: instances[i].ID}
+				}
+			}
+
+			fmt.Printf("Read %d instances from %s\n", len(instances), strings.Join(regionList, ", "))
+
+			if cmd.GetBool("dry-run") {
+				for _, inst := range instances {
+					subnet := ""
+					if inst.Subnet != nil {
+						subnet = inst.Subnet.ID + " " + inst.Subnet.CIDR
+					}
+					fmt.Printf("  %s (%s) %s %s %s\n", inst.Name, inst.Status,
+						strings.Join(append(inst.PrivateIPs, inst.PublicIPs...), ","), inst.Type, subnet)
+				}
+				fmt.Println("Dry run - no changes made")
+				return nil
+			}
+
+			return importCloudServers(provider, instances)
+		},
+	}
+}
+
+// awsCredentials uses the named profile, then the AWS_ACCESS_KEY_ID
+// environment variables, then the default profile
+func awsCredentials(profile string) (*cloud.AWSProfile, error) {
+	if profile == "" && os.Getenv("AWS_ACCESS_KEY_ID") != "" {
+		return &cloud.AWSProfile{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+	return cloud.LoadAWSProfile(profile)
+}
@@ -19,11 +19,14 @@ import (
 func Command() *cli.Command {
 	return &cli.Command{
 		Name:  "import",
-		Usage: "Import data from CSV or JSON",
+		Usage: "Import data from CSV, JSON, NetBox, Kubernetes or AWS",
 		Commands: []*cli.Command{
 			DevicesCommand(),
 			NetworksCommand(),
 			DatacentersCommand(),
+			NetBoxCommand(),
+			K8sCommand(),
+			AWSCommand(),
 		},
 	}
 }
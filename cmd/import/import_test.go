@@ -14,8 +14,8 @@ func TestCommand(t *testing.T) {
 	if cmd.Name != "import" {
 		t.Errorf("Name = %v, want import", cmd.Name)
 	}
-	if len(cmd.Commands) != 3 {
-		t.Errorf("expected 3 subcommands, got %d", len(cmd.Commands))
+	if len(cmd.Commands) != 6 {
+		t.Errorf("expected 6 subcommands, got %d", len(cmd.Commands))
 	}
 }
 
@@ -79,3 +79,61 @@ func TestDatacentersCommand(t *testing.T) {
 		t.Errorf("expected 3 flags, got %d", len(cmd.Flags))
 	}
 }
+
+func TestNetBoxCommand(t *testing.T) {
+	cmd := NetBoxCommand()
+	if cmd == nil {
+		t.Fatal("NetBoxCommand() returned nil")
+	}
+	if cmd.Name != "netbox" {
+		t.Errorf("Name = %v, want netbox", cmd.Name)
+	}
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+	if len(cmd.Flags) != 4 {
+		t.Errorf("expected 4 flags, got %d", len(cmd.Flags))
+	}
+
+	required := map[string]bool{}
+	for _, flag := range cmd.Flags {
+		if sf, ok := flag.(*cli.StringFlag); ok && sf.Required {
+			required[sf.Name] = true
+		}
+	}
+	if !required["url"] || !required["token"] {
+		t.Errorf("expected required url and token flags, got %v", required)
+	}
+}
+
+func TestK8sCommand(t *testing.T) {
+	cmd := K8sCommand()
+	if cmd == nil {
+		t.Fatal("K8sCommand() returned nil")
+	}
+	if cmd.Name != "k8s" {
+		t.Errorf("Name = %v, want k8s", cmd.Name)
+	}
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+	if len(cmd.Flags) != 4 {
+		t.Errorf("expected 4 flags, got %d", len(cmd.Flags))
+	}
+}
+
+func TestAWSCommand(t *testing.T) {
+	cmd := AWSCommand()
+	if cmd == nil {
+		t.Fatal("AWSCommand() returned nil")
+	}
+	if cmd.Name != "aws" {
+		t.Errorf("Name = %v, want aws", cmd.Name)
+	}
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+	if len(cmd.Flags) != 6 {
+		t.Errorf("expected 6 flags, got %d", len(cmd.Flags))
+	}
+}
@@ -0,0 +1,105 @@
+package importcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paularlott/cli"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/cloud"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func K8sCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "k8s",
+		Usage: "Import the nodes of a Kubernetes cluster as devices",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "kubeconfig", Usage: "Path to the kubeconfig file (default ~/.kube/config)", EnvVars: []string{"KUBECONFIG"}},
+			&cli.StringFlag{Name: "context", Usage: "Kubeconfig context to use (default the current context)"},
+			&cli.StringFlag{Name: "cluster-name", Usage: "Cluster name for tags and matching (default the context's cluster)"},
+			&cli.BoolFlag{Name: "dry-run", Usage: "Show the nodes without importing"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			path := cmd.GetString("kubeconfig")
+			if path == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("failed to find home directory: %w", err)
+				}
+				path = filepath.Join(home, ".kube", "config")
+			}
+			// KUBECONFIG may list several files; only the first is read
+			path, _, _ = strings.Cut(path, string(os.PathListSeparator))
+
+			k, err := cloud.NewKubernetesFromKubeconfig(path, cmd.GetString("context"), cmd.GetString("cluster-name"))
+			if err != nil {
+				return err
+			}
+			nodes, err := k.ListServers(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list nodes: %w", err)
+			}
+
+			fmt.Printf("Read %d nodes from cluster %s\n", len(nodes), k.Cluster())
+
+			if cmd.GetBool("dry-run") {
+				for _, n := range nodes {
+					fmt.Printf("  %s (%s) %s %s\n", n.Name, n.Status,
+						strings.Join(append(n.PrivateIPs, n.PublicIPs...), ","), n.Image)
+				}
+				fmt.Println("Dry run - no changes made")
+				return nil
+			}
+
+			return importCloudServers(k.Name(), nodes)
+		},
+	}
+}
+
+// importCloudServers sends servers read on this machine to the server, which
+// syncs them as if the provider had reported them
+func importCloudServers(provider string, servers []cloud.Server) error {
+	c := client.NewClient(client.LoadConfig())
+	resp, err := c.DoRequest("POST", "/api/cloud/import", map[string]any{
+		"provider": provider,
+		"servers":  servers,
+	})
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return client.HandleError(resp)
+	}
+
+	var result model.CloudSyncResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	fmt.Printf("\nImport complete:\n")
+	fmt.Printf("  Created:   %d\n", len(result.Created))
+	fmt.Printf("  Updated:   %d\n", len(result.Updated))
+	fmt.Printf("  Unchanged: %d\n", result.Unchanged)
+	fmt.Printf("  Missing:   %d\n", len(result.Missing))
+	for _, name := range result.Missing {
+		fmt.Printf("    - %s\n", name)
+	}
+
+	if len(result.Errors) > 0 {
+		fmt.Printf("\nErrors:\n")
+		for _, e := range result.Errors {
+			fmt.Printf("  - %s\n", e)
+		}
+		return fmt.Errorf("import completed with %d errors", len(result.Errors))
+	}
+	return nil
+}
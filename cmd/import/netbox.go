@@ -0,0 +1,171 @@
+package importcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/paularlott/cli"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/importdata"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func NetBoxCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "netbox",
+		Usage: "Import sites, prefixes, IP ranges, IP addresses and devices from NetBox",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "url", Usage: "NetBox base URL", Required: true, EnvVars: []string{"NETBOX_URL"}},
+			&cli.StringFlag{Name: "token", Usage: "NetBox API token", Required: true, EnvVars: []string{"NETBOX_TOKEN"}},
+			&cli.StringFlag{Name: "conflict", Usage: "How to handle records that already exist (skip/update/fail)", DefaultValue: string(importdata.ConflictSkip)},
+			&cli.BoolFlag{Name: "dry-run", Usage: "Show what would be imported without making changes"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			mode := importdata.ConflictMode(cmd.GetString("conflict"))
+			dryRun := cmd.GetBool("dry-run")
+
+			nb := importdata.NewNetBoxClient(cmd.GetString("url"), cmd.GetString("token"))
+			imp, err := nb.Fetch(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to read NetBox: %w", err)
+			}
+
+			fmt.Printf("Read %d sites, %d prefixes, %d IP ranges and %d devices from NetBox\n",
+				len(imp.Datacenters), len(imp.Networks), len(imp.Pools), len(imp.Devices))
+			for _, w := range imp.Warnings {
+				fmt.Printf("  warning: %s\n", w)
+			}
+
+			target := &apiTarget{c: client.NewClient(client.LoadConfig())}
+			result, err := importdata.ApplyNetBox(ctx, target, imp, mode, dryRun)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Println("\nDry run - no changes made. Planned changes:")
+				for _, action := range result.Actions {
+					fmt.Printf("  %s\n", action)
+				}
+			}
+
+			fmt.Printf("\nImport complete:\n")
+			failed := 0
+			for _, r := range []struct {
+				kind   string
+				result importdata.ImportResult
+			}{
+				{"Datacenters", result.Datacenters},
+				{"Networks", result.Networks},
+				{"Pools", result.Pools},
+				{"Devices", result.Devices},
+			} {
+				fmt.Printf("  %-12s %d created, %d updated, %d skipped, %d failed\n",
+					r.kind+":", r.result.Created, r.result.Updated, r.result.Skipped, r.result.Failed)
+				for _, e := range r.result.Errors {
+					fmt.Printf("    - %s\n", e)
+				}
+				failed += r.result.Failed
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("import completed with %d errors", failed)
+			}
+			return nil
+		},
+	}
+}
+
+// apiTarget applies a NetBox import through the rackd API
+type apiTarget struct {
+	c *client.Client
+}
+
+func (t *apiTarget) ListDatacenters(ctx context.Context) ([]model.Datacenter, error) {
+	return listAll[model.Datacenter](t.c, "/api/datacenters")
+}
+
+func (t *apiTarget) ListNetworks(ctx context.Context) ([]model.Network, error) {
+	return listAll[model.Network](t.c, "/api/networks")
+}
+
+func (t *apiTarget) ListPools(ctx context.Context, networkID string) ([]model.NetworkPool, error) {
+	var pools []model.NetworkPool
+	err := t.do("GET", "/api/networks/"+url.PathEscape(networkID)+"/pools", nil, &pools)
+	return pools, err
+}
+
+func (t *apiTarget) ListDevices(ctx context.Context) ([]model.Device, error) {
+	return listAll[model.Device](t.c, "/api/devices")
+}
+
+func (t *apiTarget) SaveDatacenter(ctx context.Context, dc *model.Datacenter) error {
+	if dc.ID == "" {
+		return t.do("POST", "/api/datacenters", dc, dc)
+	}
+	return t.do("PUT", "/api/datacenters/"+url.PathEscape(dc.ID), dc, dc)
+}
+
+func (t *apiTarget) SaveNetwork(ctx context.Context, network *model.Network) error {
+	if network.ID == "" {
+		return t.do("POST", "/api/networks", network, network)
+	}
+	return t.do("PUT", "/api/networks/"+url.PathEscape(network.ID), network, network)
+}
+
+func (t *apiTarget) SavePool(ctx context.Context, pool *model.NetworkPool) error {
+	if pool.ID == "" {
+		return t.do("POST", "/api/networks/"+url.PathEscape(pool.NetworkID)+"/pools", pool, pool)
+	}
+	return t.do("PUT", "/api/pools/"+url.PathEscape(pool.ID), pool, pool)
+}
+
+func (t *apiTarget) SaveDevice(ctx context.Context, device *model.Device) error {
+	if device.ID == "" {
+		return t.do("POST", "/api/devices", device, device)
+	}
+	return t.do("PUT", "/api/devices/"+url.PathEscape(device.ID), device, device)
+}
+
+func (t *apiTarget) do(method, path string, body, out any) error {
+	resp, err := t.c.DoRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return client.HandleError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// listAll fetches every page of a rackd list endpoint
+func listAll[T any](c *client.Client, path string) ([]T, error) {
+	var all []T
+	for offset := 0; ; {
+		resp, err := c.DoRequest("GET", fmt.Sprintf("%s?limit=%d&offset=%d", path, model.MaxPageSize, offset), nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []T
+		if resp.StatusCode != http.StatusOK {
+			err = client.HandleError(resp)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&page)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < model.MaxPageSize {
+			return all, nil
+		}
+		offset += len(page)
+	}
+}
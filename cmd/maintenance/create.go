@@ -0,0 +1,85 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func CreateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "create",
+		Usage: "Schedule a maintenance window for a device or every device with a tag",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "device", Usage: "Device ID"},
+			&cli.StringFlag{Name: "tag", Usage: "Cover every device with this tag"},
+			&cli.StringFlag{Name: "start", Usage: "Start time, RFC3339 (default now)"},
+			&cli.StringFlag{Name: "end", Usage: "End time, RFC3339"},
+			&cli.StringFlag{Name: "duration", Usage: "Length of the window instead of an end time, e.g. 2h"},
+			&cli.StringFlag{Name: "description", Usage: "What the maintenance is for"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			start := time.Now().UTC()
+			if v := cmd.GetString("start"); v != "" {
+				t, err := time.Parse(time.RFC3339, v)
+				if err != nil {
+					return fmt.Errorf("invalid --start: %w", err)
+				}
+				start = t
+			}
+
+			var end time.Time
+			switch {
+			case cmd.GetString("end") != "":
+				t, err := time.Parse(time.RFC3339, cmd.GetString("end"))
+				if err != nil {
+					return fmt.Errorf("invalid --end: %w", err)
+				}
+				end = t
+			case cmd.GetString("duration") != "":
+				d, err := time.ParseDuration(cmd.GetString("duration"))
+				if err != nil {
+					return fmt.Errorf("invalid --duration: %w", err)
+				}
+				end = start.Add(d)
+			default:
+				return fmt.Errorf("either --end or --duration is required")
+			}
+
+			data := map[string]any{
+				"device_id":   cmd.GetString("device"),
+				"tag":         cmd.GetString("tag"),
+				"starts_at":   start.Format(time.RFC3339),
+				"ends_at":     end.Format(time.RFC3339),
+				"description": cmd.GetString("description"),
+			}
+
+			resp, err := c.DoRequest("POST", "/api/maintenance", data)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusCreated {
+				return client.HandleError(resp)
+			}
+
+			var window map[string]any
+			if err := json.NewDecoder(resp.Body).Decode(&window); err != nil {
+				return err
+			}
+
+			return client.Render(window, func(bool) {
+				client.PrintYAML(window)
+			})
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func ListCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List maintenance windows",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "device", Usage: "Filter by device ID"},
+			&cli.StringFlag{Name: "tag", Usage: "Filter by tag"},
+			&cli.StringFlag{Name: "status", Usage: "Filter by status (active/upcoming/past/current)"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			params := url.Values{}
+			if device := cmd.GetString("device"); device != "" {
+				params.Set("device_id", device)
+			}
+			if tag := cmd.GetString("tag"); tag != "" {
+				params.Set("tag", tag)
+			}
+			if status := cmd.GetString("status"); status != "" {
+				params.Set("status", status)
+			}
+			path := "/api/maintenance"
+			if len(params) > 0 {
+				path += "?" + params.Encode()
+			}
+
+			resp, err := c.DoRequest("GET", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var windows []map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&windows); err != nil {
+				return err
+			}
+
+			return client.PrintList(windows, windowColumns)
+		},
+	}
+}
+
+var windowColumns = []client.Column{
+	{Header: "ID", Field: "id"},
+	{Header: "DEVICE", Field: "device_id"},
+	{Header: "TAG", Field: "tag"},
+	{Header: "STARTS", Field: "starts_at"},
+	{Header: "ENDS", Field: "ends_at"},
+	{Header: "STATUS", Field: "status"},
+	{Header: "DESCRIPTION", Field: "description", Wide: true},
+	{Header: "CREATED BY", Field: "created_by", Wide: true},
+}
@@ -0,0 +1,15 @@
+package maintenance
+
+import "github.com/paularlott/cli"
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "maintenance",
+		Usage: "Maintenance window management commands",
+		Commands: []*cli.Command{
+			ListCommand(),
+			CreateCommand(),
+			DeleteCommand(),
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package mcpstdio
+
+import (
+	"context"
+	"os"
+
+	"github.com/paularlott/cli"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/mcp"
+	"github.com/martinsuchenak/rackd/internal/service"
+	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/martinsuchenak/rackd/internal/validate"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "mcp-stdio",
+		Usage: "Serve the MCP tools over stdin/stdout against local storage",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "data-dir", Usage: "Data directory containing rackd.db", DefaultValue: "./data"},
+			&cli.StringFlag{Name: "log-level", Usage: "Log level (trace/debug/info/warn/error)", DefaultValue: "warn"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := config.Load()
+
+			// stdout carries the protocol, so logs must go to stderr
+			log.Init(cfg.LogFormat, cmd.GetString("log-level"), os.Stderr)
+
+			store, err := storage.NewExtendedStorage(cmd.GetString("data-dir"))
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			services := service.NewServices(store, nil, nil)
+			services.SetSearchLimits(service.SearchLimits{MaxRows: cfg.SearchMaxRows, Timeout: cfg.SearchTimeout})
+			policies, err := validate.FromConfig(cfg)
+			if err != nil {
+				return err
+			}
+			services.SetValidationPolicies(policies)
+			services.SetUniqueDeviceNames(cfg.UniqueDeviceNames)
+
+			return mcp.NewServer(services, store, false).ServeStdio(ctx, os.Stdin, os.Stdout)
+		},
+	}
+}
@@ -1,11 +1,13 @@
 package migrate
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/martinsuchenak/rackd/internal/config"
@@ -21,6 +23,8 @@ func Command() *cli.Command {
 		Usage: "Database migration management",
 		Commands: []*cli.Command{
 			statusCommand(),
+			upCommand(),
+			downCommand(),
 			runCommand(),
 		},
 	}
@@ -72,12 +76,13 @@ func statusCommand() *cli.Command {
 	}
 }
 
-func runCommand() *cli.Command {
+func upCommand() *cli.Command {
 	return &cli.Command{
-		Name:  "run",
-		Usage: "Run pending migrations",
+		Name:  "up",
+		Usage: "Apply pending migrations",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "data-dir", Usage: "Data directory", DefaultValue: "./data"},
+			&cli.BoolFlag{Name: "dry-run", Usage: "Run the migrations in a transaction that is rolled back"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := config.Load()
@@ -92,35 +97,94 @@ func runCommand() *cli.Command {
 			}
 			defer db.Close()
 
-			// Check pending count first
-			statuses, err := storage.GetMigrationStatus(ctx, db)
+			dryRun := cmd.GetBool("dry-run")
+			applied, err := storage.MigrateUp(ctx, db, dryRun)
 			if err != nil {
-				return fmt.Errorf("failed to get migration status: %w", err)
-			}
-
-			pending := 0
-			for _, s := range statuses {
-				if !s.Applied {
-					pending++
-				}
+				return fmt.Errorf("migration failed: %w", err)
 			}
 
-			if pending == 0 {
+			if len(applied) == 0 {
 				fmt.Println("Database is up to date, no pending migrations")
 				return nil
 			}
+			printMigrations(applied, dryRun, "Would apply", "Applied")
+			return nil
+		},
+	}
+}
+
+// runCommand is the original name of up, kept so existing scripts work
+func runCommand() *cli.Command {
+	cmd := upCommand()
+	cmd.Name = "run"
+	cmd.Usage = "Apply pending migrations (same as up)"
+	return cmd
+}
+
+func downCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "down",
+		Usage: "Revert the most recently applied migrations (stop the server first)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "data-dir", Usage: "Data directory", DefaultValue: "./data"},
+			&cli.IntFlag{Name: "steps", Usage: "Number of migrations to revert", DefaultValue: 1},
+			&cli.BoolFlag{Name: "dry-run", Usage: "Revert the migrations in a transaction that is rolled back"},
+			&cli.BoolFlag{Name: "force", Usage: "Skip confirmation"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := config.Load()
+			dataDir := cmd.GetString("data-dir")
+			if dataDir == "" {
+				dataDir = cfg.DataDir
+			}
+
+			db, err := openDB(dataDir)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
 
-			fmt.Printf("Running %d pending migration(s)...\n", pending)
-			if err := storage.RunMigrations(ctx, db); err != nil {
+			steps := cmd.GetInt("steps")
+			dryRun := cmd.GetBool("dry-run")
+			if !dryRun && !cmd.GetBool("force") {
+				fmt.Printf("Reverting %d migration(s) can drop tables and columns along with their data. Make sure the server is stopped and you have a backup. Continue? [y/N]: ", steps)
+				confirm, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				confirm = strings.TrimSpace(strings.ToLower(confirm))
+				if confirm != "y" && confirm != "yes" {
+					fmt.Println("Migration cancelled")
+					return nil
+				}
+			}
+
+			reverted, err := storage.MigrateDown(ctx, db, steps, dryRun)
+			if err != nil {
 				return fmt.Errorf("migration failed: %w", err)
 			}
 
-			fmt.Println("All migrations applied successfully")
+			if len(reverted) == 0 {
+				fmt.Println("No applied migrations to revert")
+				return nil
+			}
+			printMigrations(reverted, dryRun, "Would revert", "Reverted")
 			return nil
 		},
 	}
 }
 
+// printMigrations lists the migrations up or down ran, or would have run
+func printMigrations(ms []*storage.Migration, dryRun bool, planned, done string) {
+	verb := done
+	if dryRun {
+		verb = planned
+	}
+	for _, m := range ms {
+		fmt.Printf("%s %s %s\n", verb, m.Version, m.Name)
+	}
+	if dryRun {
+		fmt.Println("\nDry run, no changes were made")
+	}
+}
+
 func openDB(dataDir string) (*sql.DB, error) {
 	dbPath := filepath.Join(dataDir, "rackd.db")
 	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
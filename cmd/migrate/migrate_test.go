@@ -13,11 +13,11 @@ func TestCommandStructure(t *testing.T) {
 		t.Errorf("expected command name 'migrate', got %q", cmd.Name)
 	}
 
-	if len(cmd.Commands) != 2 {
-		t.Errorf("expected 2 subcommands, got %d", len(cmd.Commands))
+	if len(cmd.Commands) != 4 {
+		t.Errorf("expected 4 subcommands, got %d", len(cmd.Commands))
 	}
 
-	expectedSubcommands := []string{"status", "run"}
+	expectedSubcommands := []string{"status", "up", "down", "run"}
 	for i, expected := range expectedSubcommands {
 		if cmd.Commands[i].Name != expected {
 			t.Errorf("subcommand %d: expected %q, got %q", i, expected, cmd.Commands[i].Name)
@@ -63,3 +63,12 @@ func TestOpenDBWithRealFile(t *testing.T) {
 		t.Errorf("ping failed: %v", err)
 	}
 }
+
+func TestDownCommandFlags(t *testing.T) {
+	cmd := downCommand()
+
+	// data-dir, steps, dry-run, force
+	if len(cmd.Flags) != 4 {
+		t.Errorf("expected 4 flags, got %d", len(cmd.Flags))
+	}
+}
@@ -27,7 +27,6 @@ func CreateCommand() *cli.Command {
 			&cli.StringFlag{Name: "description", Usage: "Description"},
 			&cli.BoolFlag{Name: "disabled", Usage: "Create as disabled"},
 			&cli.StringFlag{Name: "tags", Usage: "Comma-separated tags"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -80,13 +79,9 @@ func CreateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(mapping)
-			default:
+			return client.Render(mapping, func(bool) {
 				client.PrintYAML(mapping)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -15,7 +15,6 @@ func GetCommand() *cli.Command {
 		Usage: "Get a NAT mapping by ID",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "id", Usage: "NAT mapping ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -37,13 +36,9 @@ func GetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(mapping)
-			default:
+			return client.Render(mapping, func(bool) {
 				client.PrintYAML(mapping)
-			}
-			return nil
+			})
 		},
 	}
 }
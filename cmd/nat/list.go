@@ -19,7 +19,6 @@ func ListCommand() *cli.Command {
 			&cli.StringFlag{Name: "protocol", Usage: "Filter by protocol (tcp/udp/any)"},
 			&cli.StringFlag{Name: "device", Usage: "Filter by device ID"},
 			&cli.StringFlag{Name: "datacenter", Usage: "Filter by datacenter ID"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -61,13 +60,24 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(mappings)
-			default:
-				client.PrintYAML(mappings)
-			}
-			return nil
+			return client.PrintList(mappings, mappingColumns)
 		},
 	}
 }
+
+var mappingColumns = []client.Column{
+	{Header: "ID", Field: "id"},
+	{Header: "NAME", Field: "name"},
+	{Header: "EXTERNAL", Value: func(m map[string]interface{}) string {
+		return client.FormatValue(m["external_ip"]) + ":" + client.FormatValue(m["external_port"])
+	}},
+	{Header: "INTERNAL", Value: func(m map[string]interface{}) string {
+		return client.FormatValue(m["internal_ip"]) + ":" + client.FormatValue(m["internal_port"])
+	}},
+	{Header: "PROTOCOL", Field: "protocol"},
+	{Header: "ENABLED", Field: "enabled"},
+	{Header: "DEVICE", Field: "device_id", Wide: true},
+	{Header: "DATACENTER", Field: "datacenter_id", Wide: true},
+	{Header: "TAGS", Field: "tags", Wide: true},
+	{Header: "DESCRIPTION", Field: "description", Wide: true},
+}
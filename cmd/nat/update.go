@@ -29,7 +29,6 @@ func UpdateCommand() *cli.Command {
 			&cli.BoolFlag{Name: "enabled", Usage: "Enable the mapping"},
 			&cli.BoolFlag{Name: "disabled", Usage: "Disable the mapping"},
 			&cli.StringFlag{Name: "tags", Usage: "Comma-separated tags"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "yaml"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -98,13 +97,9 @@ func UpdateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(mapping)
-			default:
+			return client.Render(mapping, func(bool) {
 				client.PrintYAML(mapping)
-			}
-			return nil
+			})
 		},
 	}
 }
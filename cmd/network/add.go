@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/martinsuchenak/rackd/cmd/client"
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -20,8 +21,11 @@ func AddCommand() *cli.Command {
 			&cli.StringFlag{Name: "subnet", Usage: "Network subnet (CIDR)", Required: true},
 			&cli.StringFlag{Name: "description", Usage: "Network description"},
 			&cli.IntFlag{Name: "vlan", Usage: "VLAN ID"},
+			&cli.StringFlag{Name: "gateway", Usage: "Default gateway IP"},
+			&cli.StringFlag{Name: "dns-servers", Usage: "DNS server IPs (comma-separated)"},
+			&cli.IntFlag{Name: "mtu", Usage: "MTU"},
 			&cli.StringFlag{Name: "datacenter", Usage: "Datacenter ID"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
+			&cli.BoolFlag{Name: "dry-run", Usage: "Validate the network without creating it"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -32,10 +36,18 @@ func AddCommand() *cli.Command {
 				Subnet:       cmd.GetString("subnet"),
 				Description:  cmd.GetString("description"),
 				VLANID:       cmd.GetInt("vlan"),
+				Gateway:      cmd.GetString("gateway"),
+				DNSServers:   strings.Split(cmd.GetString("dns-servers"), ","),
+				MTU:          cmd.GetInt("mtu"),
 				DatacenterID: cmd.GetString("datacenter"),
 			}
 
-			resp, err := c.DoRequest("POST", "/api/networks", network)
+			path := "/api/networks"
+			dryRun := cmd.GetBool("dry-run")
+			if dryRun {
+				path += "?dry_run=true"
+			}
+			resp, err := c.DoRequest("POST", path, network)
 			if err != nil {
 				return err
 			}
@@ -50,14 +62,15 @@ func AddCommand() *cli.Command {
 				return err
 			}
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(created)
-			} else {
+			return client.Render(created, func(bool) {
+				if dryRun {
+					fmt.Printf("Network %s is valid\n", created["name"])
+					return
+				}
 				fmt.Printf("Network created successfully\n")
 				fmt.Printf("ID: %s\n", created["id"])
 				fmt.Printf("Name: %s\n", created["name"])
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 
@@ -14,16 +15,21 @@ import (
 
 func DeleteCommand() *cli.Command {
 	return &cli.Command{
-		Name:  "delete",
-		Usage: "Delete a network",
+		Name:      "delete",
+		Usage:     "Delete a network",
+		Arguments: []cli.Argument{client.Networks.Arg()},
 		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "id", Usage: "Network ID", Required: true},
+			client.Networks.IDFlag(),
 			&cli.BoolFlag{Name: "force", Usage: "Skip confirmation"},
+			&cli.StringFlag{Name: "cascade", Usage: "Dependent records: detach (default) unlinks them, true deletes them too, deny refuses while any exist"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
-			networkID := cmd.GetString("id")
+			networkID, err := client.Networks.ID(c, cmd)
+			if err != nil {
+				return err
+			}
 
 			if !cmd.GetBool("force") {
 				fmt.Printf("Are you sure you want to delete network %s? [y/N]: ", networkID)
@@ -36,7 +42,11 @@ func DeleteCommand() *cli.Command {
 				}
 			}
 
-			resp, err := c.DoRequest("DELETE", "/api/networks/"+networkID, nil)
+			path := "/api/networks/" + networkID
+			if cascade := cmd.GetString("cascade"); cascade != "" {
+				path += "?cascade=" + url.QueryEscape(cascade)
+			}
+			resp, err := c.DoRequest("DELETE", path, nil)
 			if err != nil {
 				return err
 			}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/martinsuchenak/rackd/cmd/client"
 	"github.com/paularlott/cli"
@@ -12,16 +13,19 @@ import (
 
 func GetCommand() *cli.Command {
 	return &cli.Command{
-		Name:  "get",
-		Usage: "Get a network by ID",
+		Name:      "get",
+		Usage:     "Get a network by name or ID",
+		Arguments: []cli.Argument{client.Networks.Arg()},
 		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "id", Usage: "Network ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
+			client.Networks.IDFlag(),
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
-			networkID := cmd.GetString("id")
+			networkID, err := client.Networks.ID(c, cmd)
+			if err != nil {
+				return err
+			}
 
 			resp, err := c.DoRequest("GET", "/api/networks/"+networkID, nil)
 			if err != nil {
@@ -38,15 +42,9 @@ func GetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(network)
-			case "yaml":
-				client.PrintYAML(network)
-			default:
+			return client.Render(network, func(bool) {
 				printNetworkDetail(network)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -59,6 +57,21 @@ func printNetworkDetail(n map[string]interface{}) {
 	if vlan, ok := n["vlan_id"].(float64); ok {
 		fmt.Printf("VLAN:        %d\n", int(vlan))
 	}
+	if gateway := getString(n, "gateway"); gateway != "" {
+		fmt.Printf("Gateway:     %s\n", gateway)
+	}
+	if servers, ok := n["dns_servers"].([]interface{}); ok && len(servers) > 0 {
+		names := make([]string, 0, len(servers))
+		for _, s := range servers {
+			if name, ok := s.(string); ok {
+				names = append(names, name)
+			}
+		}
+		fmt.Printf("DNS servers: %s\n", strings.Join(names, ", "))
+	}
+	if mtu, ok := n["mtu"].(float64); ok && mtu > 0 {
+		fmt.Printf("MTU:         %d\n", int(mtu))
+	}
 	fmt.Printf("Datacenter:  %s\n", getString(n, "datacenter_id"))
 }
 
@@ -18,7 +18,6 @@ func ListCommand() *cli.Command {
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "datacenter", Usage: "Filter by datacenter ID"},
 			&cli.IntFlag{Name: "vlan", Usage: "Filter by VLAN ID"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -52,15 +51,7 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(networks)
-			case "yaml":
-				client.PrintYAML(networks)
-			default:
-				client.PrintNetworkTable(networks)
-			}
-			return nil
+			return client.PrintList(networks, client.NetworkColumns)
 		},
 	}
 }
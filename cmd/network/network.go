@@ -10,6 +10,7 @@ func Command() *cli.Command {
 			ListCommand(),
 			GetCommand(),
 			AddCommand(),
+			UpdateCommand(),
 			DeleteCommand(),
 			PoolCommand(),
 		},
@@ -50,11 +50,11 @@ func TestCommandStructure(t *testing.T) {
 		t.Errorf("expected command name 'network', got %q", cmd.Name)
 	}
 
-	if len(cmd.Commands) != 5 {
-		t.Errorf("expected 5 subcommands, got %d", len(cmd.Commands))
+	if len(cmd.Commands) != 6 {
+		t.Errorf("expected 6 subcommands, got %d", len(cmd.Commands))
 	}
 
-	expectedSubcommands := []string{"list", "get", "add", "delete", "pool"}
+	expectedSubcommands := []string{"list", "get", "add", "update", "delete", "pool"}
 	for i, expected := range expectedSubcommands {
 		if cmd.Commands[i].Name != expected {
 			t.Errorf("subcommand %d: expected %q, got %q", i, expected, cmd.Commands[i].Name)
@@ -100,8 +100,8 @@ func TestGetCommandStructure(t *testing.T) {
 		t.Errorf("expected command name 'get', got %q", cmd.Name)
 	}
 
-	if len(cmd.Flags) < 2 {
-		t.Errorf("expected at least 2 flags (id, output), got %d", len(cmd.Flags))
+	if len(cmd.Flags) < 1 {
+		t.Errorf("expected at least 1 flag (id), got %d", len(cmd.Flags))
 	}
 }
 
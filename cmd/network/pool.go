@@ -5,8 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
-	"text/tabwriter"
 
 	"github.com/martinsuchenak/rackd/cmd/client"
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -30,7 +28,6 @@ func poolListCommand() *cli.Command {
 		Usage: "List pools for a network",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "network", Usage: "Network ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -52,12 +49,7 @@ func poolListCommand() *cli.Command {
 				return err
 			}
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(pools)
-			} else {
-				printPoolTable(pools)
-			}
-			return nil
+			return client.PrintList(pools, poolColumns)
 		},
 	}
 }
@@ -72,7 +64,6 @@ func poolAddCommand() *cli.Command {
 			&cli.StringFlag{Name: "start", Usage: "Start IP", Required: true},
 			&cli.StringFlag{Name: "end", Usage: "End IP", Required: true},
 			&cli.StringFlag{Name: "description", Usage: "Pool description"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -102,27 +93,20 @@ func poolAddCommand() *cli.Command {
 				return err
 			}
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(created)
-			} else {
+			return client.Render(created, func(bool) {
 				fmt.Printf("Pool created successfully\n")
 				fmt.Printf("ID: %s\n", created["id"])
 				fmt.Printf("Name: %s\n", created["name"])
-			}
-			return nil
+			})
 		},
 	}
 }
 
-func printPoolTable(pools []map[string]interface{}) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tSTART IP\tEND IP")
-	for _, p := range pools {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-			getString(p, "id"),
-			getString(p, "name"),
-			getString(p, "start_ip"),
-			getString(p, "end_ip"))
-	}
-	w.Flush()
+var poolColumns = []client.Column{
+	{Header: "ID", Field: "id"},
+	{Header: "NAME", Field: "name"},
+	{Header: "START IP", Field: "start_ip"},
+	{Header: "END IP", Field: "end_ip"},
+	{Header: "TAGS", Field: "tags", Wide: true},
+	{Header: "DESCRIPTION", Field: "description", Wide: true},
 }
@@ -0,0 +1,94 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func UpdateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "update",
+		Usage:     "Update a network",
+		Arguments: []cli.Argument{client.Networks.Arg()},
+		Flags: []cli.Flag{
+			client.Networks.IDFlag(),
+			&cli.StringFlag{Name: "name", Usage: "Network name"},
+			&cli.StringFlag{Name: "subnet", Usage: "Network subnet (CIDR)"},
+			&cli.StringFlag{Name: "description", Usage: "Network description"},
+			&cli.IntFlag{Name: "vlan", Usage: "VLAN ID, 0 to clear"},
+			&cli.StringFlag{Name: "gateway", Usage: "Default gateway IP, empty to clear"},
+			&cli.StringFlag{Name: "dns-servers", Usage: "DNS server IPs (comma-separated), replacing the current ones"},
+			&cli.IntFlag{Name: "mtu", Usage: "MTU, 0 for the default"},
+			&cli.StringFlag{Name: "datacenter", Usage: "Datacenter ID"},
+			&cli.BoolFlag{Name: "dry-run", Usage: "Validate the changes without saving them"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+			networkID, err := client.Networks.ID(c, cmd)
+			if err != nil {
+				return err
+			}
+
+			updates := make(map[string]interface{})
+			if v := cmd.GetString("name"); v != "" {
+				updates["name"] = v
+			}
+			if v := cmd.GetString("subnet"); v != "" {
+				updates["subnet"] = v
+			}
+			if v := cmd.GetString("description"); v != "" {
+				updates["description"] = v
+			}
+			if v := cmd.GetString("datacenter"); v != "" {
+				updates["datacenter_id"] = v
+			}
+			if cmd.HasFlag("vlan") {
+				updates["vlan_id"] = cmd.GetInt("vlan")
+			}
+			if cmd.HasFlag("gateway") {
+				updates["gateway"] = cmd.GetString("gateway")
+			}
+			if cmd.HasFlag("dns-servers") {
+				updates["dns_servers"] = strings.Split(cmd.GetString("dns-servers"), ",")
+			}
+			if cmd.HasFlag("mtu") {
+				updates["mtu"] = cmd.GetInt("mtu")
+			}
+
+			path := "/api/networks/" + networkID
+			dryRun := cmd.GetBool("dry-run")
+			if dryRun {
+				path += "?dry_run=true"
+			}
+			resp, err := c.DoRequest("PUT", path, updates)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var updated map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+				return err
+			}
+
+			return client.Render(updated, func(bool) {
+				if dryRun {
+					fmt.Printf("Network %s is valid\n", updated["name"])
+					return
+				}
+				fmt.Println("Network updated successfully")
+			})
+		},
+	}
+}
@@ -5,8 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
-	"text/tabwriter"
+	"strings"
 
 	"github.com/martinsuchenak/rackd/cmd/client"
 	"github.com/paularlott/cli"
@@ -27,9 +26,6 @@ func listCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "list",
 		Usage: "List registered OAuth clients",
-		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
-		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			c := client.NewClient(client.LoadConfig())
 			resp, err := c.DoRequest("GET", "/api/oauth/clients", nil)
@@ -44,32 +40,7 @@ func listCommand() *cli.Command {
 			var clients []map[string]interface{}
 			json.NewDecoder(resp.Body).Decode(&clients)
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(clients)
-				return nil
-			}
-
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "CLIENT ID\tNAME\tGRANT TYPES\tCONFIDENTIAL\tCREATED")
-			for _, cl := range clients {
-				grants := ""
-				if g, ok := cl["grant_types"].([]interface{}); ok {
-					for i, v := range g {
-						if i > 0 {
-							grants += ", "
-						}
-						grants += fmt.Sprint(v)
-					}
-				}
-				fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\n",
-					client.GetString(cl, "client_id"),
-					client.GetString(cl, "client_name"),
-					grants,
-					cl["is_confidential"],
-					client.GetString(cl, "created_at"))
-			}
-			w.Flush()
-			return nil
+			return client.PrintList(clients, clientColumns)
 		},
 	}
 }
@@ -96,3 +67,15 @@ func deleteCommand() *cli.Command {
 		},
 	}
 }
+
+var clientColumns = []client.Column{
+	{Header: "CLIENT ID", Field: "client_id"},
+	{Header: "NAME", Field: "client_name"},
+	{Header: "GRANT TYPES", Value: func(cl map[string]interface{}) string {
+		return strings.ReplaceAll(client.FormatValue(cl["grant_types"]), ",", ", ")
+	}},
+	{Header: "CONFIDENTIAL", Field: "is_confidential"},
+	{Header: "CREATED", Field: "created_at"},
+	{Header: "REDIRECT URIS", Field: "redirect_uris", Wide: true},
+	{Header: "SCOPE", Field: "scope", Wide: true},
+}
@@ -0,0 +1,42 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+)
+
+func ListCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List the reports that can be run",
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			resp, err := c.DoRequest("GET", "/api/reports", nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			var reports []map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&reports); err != nil {
+				return err
+			}
+
+			return client.PrintList(reports, []client.Column{
+				{Header: "NAME", Field: "name"},
+				{Header: "TITLE", Field: "title"},
+				{Header: "DESCRIPTION", Field: "description"},
+			})
+		},
+	}
+}
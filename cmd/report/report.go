@@ -0,0 +1,14 @@
+package report
+
+import "github.com/paularlott/cli"
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "report",
+		Usage: "Run canned inventory reports",
+		Commands: []*cli.Command{
+			ListCommand(),
+			RunCommand(),
+		},
+	}
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/cli"
+)
+
+func RunCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "run",
+		Usage: "Run a report, printing it as a table or saving it as JSON, CSV, XLSX or PDF",
+		Arguments: []cli.Argument{
+			&cli.StringArg{Name: "name", Usage: "Report name, see rackd report list", Required: true},
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Usage: "Output format: table, json, csv, xlsx or pdf (default: from the --output extension, else table)"},
+			&cli.StringFlag{Name: "output", Usage: "Output file (default: stdout)"},
+			&cli.IntFlag{Name: "days", Usage: "Window in days for warranty-expiry and unscanned-networks"},
+			&cli.StringFlag{Name: "datacenter", Usage: "Datacenter ID or name for exposure"},
+			&cli.StringFlag{Name: "tag", Usage: "Device tag for exposure"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			output := cmd.GetString("output")
+			format := cmd.GetString("format")
+			if format == "" {
+				format = strings.TrimPrefix(filepath.Ext(output), ".")
+			}
+			if format == "" {
+				format = "table"
+			}
+			switch format {
+			case "table", "json", "csv", "xlsx", "pdf":
+			default:
+				return fmt.Errorf("invalid format %q: use table, json, csv, xlsx or pdf", format)
+			}
+
+			query := url.Values{}
+			if format != "table" {
+				query.Set("format", format)
+			}
+			if days := cmd.GetInt("days"); days > 0 {
+				query.Set("days", strconv.Itoa(days))
+			}
+			for _, name := range []string{"datacenter", "tag"} {
+				if value := cmd.GetString(name); value != "" {
+					query.Set(name, value)
+				}
+			}
+			path := "/api/reports/" + url.PathEscape(cmd.GetStringArg("name"))
+			if len(query) > 0 {
+				path += "?" + query.Encode()
+			}
+
+			cfg := client.LoadConfig()
+			c := client.NewClient(cfg)
+
+			resp, err := c.DoRequest("GET", path, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return client.HandleError(resp)
+			}
+
+			if format == "table" {
+				var report model.Report
+				if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+					return fmt.Errorf("failed to decode response: %w", err)
+				}
+				printReportTable(&report)
+				return nil
+			}
+
+			if output == "" {
+				_, err := io.Copy(os.Stdout, resp.Body)
+				return err
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			if _, err := io.Copy(f, resp.Body); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Saved %s\n", output)
+			return nil
+		},
+	}
+}
+
+// printReportTable prints the report's columns as upper-case headers over
+// its rows
+func printReportTable(report *model.Report) {
+	if len(report.Rows) == 0 {
+		fmt.Printf("%s: no results\n", report.Title)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(report.Columns))
+	for i, col := range report.Columns {
+		headers[i] = strings.ToUpper(col)
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, row := range report.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = client.FormatValue(v)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+}
@@ -22,7 +22,6 @@ func CreateCommand() *cli.Command {
 			&cli.StringFlag{Name: "purpose", Usage: "Purpose of the reservation"},
 			&cli.IntFlag{Name: "expires", Usage: "Days until expiration (0 for no expiration)"},
 			&cli.StringFlag{Name: "notes", Usage: "Additional notes"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -68,16 +67,10 @@ func CreateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(reservation)
-			case "yaml":
-				client.PrintYAML(reservation)
-			default:
+			return client.Render(reservation, func(bool) {
 				fmt.Printf("Reservation created successfully\n\n")
 				printReservationDetail(reservation)
-			}
-			return nil
+			})
 		},
 	}
 }
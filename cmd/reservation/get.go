@@ -16,7 +16,6 @@ func GetCommand() *cli.Command {
 		Usage: "Get a reservation by ID",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "id", Usage: "Reservation ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -38,15 +37,9 @@ func GetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(reservation)
-			case "yaml":
-				client.PrintYAML(reservation)
-			default:
+			return client.Render(reservation, func(bool) {
 				printReservationDetail(reservation)
-			}
-			return nil
+			})
 		},
 	}
 }
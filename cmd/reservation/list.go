@@ -21,7 +21,6 @@ func ListCommand() *cli.Command {
 			&cli.StringFlag{Name: "pool", Usage: "Filter by pool ID"},
 			&cli.StringFlag{Name: "status", Usage: "Filter by status (active, expired, claimed, released)"},
 			&cli.StringFlag{Name: "reserved-by", Usage: "Filter by user who reserved"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -58,20 +57,15 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(reservations)
-			case "yaml":
-				client.PrintYAML(reservations)
-			default:
-				printReservationTable(reservations)
-			}
-			return nil
+			return client.Render(reservations, func(wide bool) {
+				printReservationTable(reservations, wide)
+			})
 		},
 	}
 }
 
-func printReservationTable(reservations []map[string]interface{}) {
+// printReservationTable truncates IDs and expiry times unless wide is set
+func printReservationTable(reservations []map[string]interface{}, wide bool) {
 	if len(reservations) == 0 {
 		println("No reservations found")
 		return
@@ -81,15 +75,15 @@ func printReservationTable(reservations []map[string]interface{}) {
 	fmt.Fprintln(w, "ID\tIP ADDRESS\tPOOL\tHOSTNAME\tSTATUS\tRESERVED BY\tEXPIRES")
 	for _, r := range reservations {
 		id := getString(r, "id")
-		if len(id) > 8 {
+		if len(id) > 8 && !wide {
 			id = id[:8]
 		}
 		poolID := getString(r, "pool_id")
-		if len(poolID) > 8 {
+		if len(poolID) > 8 && !wide {
 			poolID = poolID[:8]
 		}
 		expires := getString(r, "expires_at")
-		if expires != "" && len(expires) > 10 {
+		if len(expires) > 10 && !wide {
 			expires = expires[:10]
 		}
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
@@ -69,8 +69,8 @@ func TestListCommandStructure(t *testing.T) {
 		t.Errorf("expected command name 'list', got %q", cmd.Name)
 	}
 
-	if len(cmd.Flags) < 4 {
-		t.Errorf("expected at least 4 flags, got %d", len(cmd.Flags))
+	if len(cmd.Flags) < 3 {
+		t.Errorf("expected at least 3 flags, got %d", len(cmd.Flags))
 	}
 }
 
@@ -81,8 +81,8 @@ func TestGetCommandStructure(t *testing.T) {
 		t.Errorf("expected command name 'get', got %q", cmd.Name)
 	}
 
-	if len(cmd.Flags) < 2 {
-		t.Errorf("expected at least 2 flags (id, output), got %d", len(cmd.Flags))
+	if len(cmd.Flags) < 1 {
+		t.Errorf("expected at least 1 flag (id), got %d", len(cmd.Flags))
 	}
 }
 
@@ -144,7 +144,7 @@ func TestPrintReservationTable(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	printReservationTable(reservations)
+	printReservationTable(reservations, false)
 
 	w.Close()
 	os.Stdout = old
@@ -21,7 +21,6 @@ func UpdateCommand() *cli.Command {
 			&cli.StringFlag{Name: "purpose", Usage: "Purpose of the reservation"},
 			&cli.IntFlag{Name: "expires", Usage: "Days until expiration from now (0 to clear expiration)"},
 			&cli.StringFlag{Name: "notes", Usage: "Additional notes"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -67,16 +66,10 @@ func UpdateCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(reservation)
-			case "yaml":
-				client.PrintYAML(reservation)
-			default:
+			return client.Render(reservation, func(bool) {
 				fmt.Printf("Reservation updated successfully\n\n")
 				printReservationDetail(reservation)
-			}
-			return nil
+			})
 		},
 	}
 }
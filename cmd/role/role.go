@@ -63,21 +63,21 @@ func ListRolesCommand() *cli.Command {
 				return fmt.Errorf("failed to decode response: %w", err)
 			}
 
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "ID\tNAME\tDESCRIPTION\tSYSTEM\tCREATED\tUPDATED")
-			for _, role := range roles {
-				system := "no"
-				if role.IsSystem {
-					system = "yes"
+			return client.Render(roles, func(bool) {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tNAME\tDESCRIPTION\tSYSTEM\tCREATED\tUPDATED")
+				for _, role := range roles {
+					system := "no"
+					if role.IsSystem {
+						system = "yes"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+						role.ID, role.Name, role.Description, system,
+						role.CreatedAt.Format("2006-01-02 15:04"),
+						role.UpdatedAt.Format("2006-01-02 15:04"))
 				}
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
-					role.ID, role.Name, role.Description, system,
-					role.CreatedAt.Format("2006-01-02 15:04"),
-					role.UpdatedAt.Format("2006-01-02 15:04"))
-			}
-			w.Flush()
-
-			return nil
+				w.Flush()
+			})
 		},
 	}
 }
@@ -133,16 +133,16 @@ func ListPermissionsCommand() *cli.Command {
 				return fmt.Errorf("failed to decode response: %w", err)
 			}
 
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "ID\tNAME\tRESOURCE\tACTION\tCREATED")
-			for _, perm := range permissions {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-					perm.ID, perm.Name, perm.Resource, perm.Action,
-					perm.CreatedAt.Format("2006-01-02 15:04"))
-			}
-			w.Flush()
-
-			return nil
+			return client.Render(permissions, func(bool) {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tNAME\tRESOURCE\tACTION\tCREATED")
+				for _, perm := range permissions {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+						perm.ID, perm.Name, perm.Resource, perm.Action,
+						perm.CreatedAt.Format("2006-01-02 15:04"))
+				}
+				w.Flush()
+			})
 		},
 	}
 }
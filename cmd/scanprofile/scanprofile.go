@@ -5,10 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/martinsuchenak/rackd/cmd/client"
 	"github.com/paularlott/cli"
@@ -32,9 +30,6 @@ func listCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "list",
 		Usage: "List scan profiles",
-		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
-		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			c := client.NewClient(client.LoadConfig())
 			resp, err := c.DoRequest("GET", "/api/scan-profiles", nil)
@@ -51,25 +46,7 @@ func listCommand() *cli.Command {
 				return err
 			}
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(profiles)
-				return nil
-			}
-
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "ID\tNAME\tTYPE\tTIMEOUT\tWORKERS\tSNMP\tSSH")
-			for _, p := range profiles {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%v\t%v\t%v\n",
-					client.GetString(p, "id"),
-					client.GetString(p, "name"),
-					client.GetString(p, "scan_type"),
-					p["timeout_sec"],
-					p["max_workers"],
-					p["enable_snmp"],
-					p["enable_ssh"])
-			}
-			w.Flush()
-			return nil
+			return client.PrintList(profiles, profileColumns)
 		},
 	}
 }
@@ -80,7 +57,6 @@ func getCommand() *cli.Command {
 		Usage: "Get a scan profile by ID",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "id", Usage: "Profile ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "json"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			c := client.NewClient(client.LoadConfig())
@@ -96,13 +72,9 @@ func getCommand() *cli.Command {
 			var profile map[string]interface{}
 			json.NewDecoder(resp.Body).Decode(&profile)
 
-			switch cmd.GetString("output") {
-			case "yaml":
+			return client.Render(profile, func(bool) {
 				client.PrintYAML(profile)
-			default:
-				client.PrintJSON(profile)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -261,3 +233,15 @@ func parsePorts(s string) []int {
 	}
 	return ports
 }
+
+var profileColumns = []client.Column{
+	{Header: "ID", Field: "id"},
+	{Header: "NAME", Field: "name"},
+	{Header: "TYPE", Field: "scan_type"},
+	{Header: "TIMEOUT", Field: "timeout_sec"},
+	{Header: "WORKERS", Field: "max_workers"},
+	{Header: "SNMP", Field: "enable_snmp"},
+	{Header: "SSH", Field: "enable_ssh"},
+	{Header: "PORTS", Field: "ports", Wide: true},
+	{Header: "DESCRIPTION", Field: "description", Wide: true},
+}
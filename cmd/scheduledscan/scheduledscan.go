@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
-	"text/tabwriter"
 
 	"github.com/martinsuchenak/rackd/cmd/client"
 	"github.com/paularlott/cli"
@@ -33,7 +31,6 @@ func listCommand() *cli.Command {
 		Usage: "List scheduled scans",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "network", Usage: "Filter by network ID"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			c := client.NewClient(client.LoadConfig())
@@ -55,29 +52,7 @@ func listCommand() *cli.Command {
 			var scans []map[string]interface{}
 			json.NewDecoder(resp.Body).Decode(&scans)
 
-			if cmd.GetString("output") == "json" {
-				client.PrintJSON(scans)
-				return nil
-			}
-
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "ID\tNAME\tNETWORK\tPROFILE\tCRON\tENABLED\tLAST RUN")
-			for _, s := range scans {
-				lastRun := "never"
-				if v, ok := s["last_run_at"].(string); ok && v != "" {
-					lastRun = v
-				}
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%v\t%s\n",
-					client.GetString(s, "id"),
-					client.GetString(s, "name"),
-					client.GetString(s, "network_id"),
-					client.GetString(s, "profile_id"),
-					client.GetString(s, "cron_expression"),
-					s["enabled"],
-					lastRun)
-			}
-			w.Flush()
-			return nil
+			return client.PrintList(scans, scanColumns)
 		},
 	}
 }
@@ -88,7 +63,6 @@ func getCommand() *cli.Command {
 		Usage: "Get a scheduled scan by ID",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "id", Usage: "Scheduled scan ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "json"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			c := client.NewClient(client.LoadConfig())
@@ -104,13 +78,9 @@ func getCommand() *cli.Command {
 			var scan map[string]interface{}
 			json.NewDecoder(resp.Body).Decode(&scan)
 
-			switch cmd.GetString("output") {
-			case "yaml":
+			return client.Render(scan, func(bool) {
 				client.PrintYAML(scan)
-			default:
-				client.PrintJSON(scan)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -247,3 +217,20 @@ func deleteCommand() *cli.Command {
 		},
 	}
 }
+
+var scanColumns = []client.Column{
+	{Header: "ID", Field: "id"},
+	{Header: "NAME", Field: "name"},
+	{Header: "NETWORK", Field: "network_id"},
+	{Header: "PROFILE", Field: "profile_id"},
+	{Header: "CRON", Field: "cron_expression"},
+	{Header: "ENABLED", Field: "enabled"},
+	{Header: "LAST RUN", Value: func(s map[string]interface{}) string {
+		if v := client.GetString(s, "last_run_at"); v != "" {
+			return v
+		}
+		return "never"
+	}},
+	{Header: "NEXT RUN", Field: "next_run_at", Wide: true},
+	{Header: "DESCRIPTION", Field: "description", Wide: true},
+}
@@ -146,9 +146,9 @@ func TestListCommandFlags(t *testing.T) {
 		t.Fatalf("expected first subcommand to be 'list', got %q", listCmd.Name)
 	}
 
-	// Should have network filter and output flags
-	if len(listCmd.Flags) < 2 {
-		t.Errorf("expected at least 2 flags (network, output), got %d", len(listCmd.Flags))
+	// Should have the network filter; --output is global
+	if len(listCmd.Flags) < 1 {
+		t.Errorf("expected at least 1 flag (network), got %d", len(listCmd.Flags))
 	}
 }
 
@@ -0,0 +1,451 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/netip"
+	"os"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/paularlott/cli"
+)
+
+// maxNetworks is how many /22 networks fit in 10.0.0.0/8
+const maxNetworks = 1 << 14
+
+// hostsPerNetwork is how many addresses each seeded network hands out
+const hostsPerNetwork = 1000
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "seed",
+		Usage: "Fill empty storage with fake inventory for demos and testing",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "data-dir", Usage: "Data directory (default ./data)"},
+			&cli.IntFlag{Name: "devices", Usage: "Number of devices", DefaultValue: 500},
+			&cli.IntFlag{Name: "datacenters", Usage: "Number of datacenters", DefaultValue: 3},
+			&cli.IntFlag{Name: "networks", Usage: "Number of networks, spread across the datacenters", DefaultValue: 20},
+			&cli.IntFlag{Name: "seed", Usage: "Random seed; the same seed generates the same inventory", DefaultValue: 1},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			if v := cmd.GetString("data-dir"); v != "" {
+				config.SetOverride("DATA_DIR", v)
+			}
+			cfg := config.Load()
+
+			opts := options{
+				Devices:     cmd.GetInt("devices"),
+				Datacenters: cmd.GetInt("datacenters"),
+				Networks:    cmd.GetInt("networks"),
+				Seed:        int64(cmd.GetInt("seed")),
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+
+			store, err := storage.Open(cfg.StorageDriver, cfg.StorageDataSource())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			svc := service.NewServices(store, nil, nil)
+			ctx = service.SystemContext(ctx, "seed")
+			return run(ctx, svc, opts, os.Stdout)
+		},
+	}
+}
+
+// options is the size of the inventory to generate
+type options struct {
+	Devices     int
+	Datacenters int
+	Networks    int
+	Seed        int64
+}
+
+func (o options) validate() error {
+	switch {
+	case o.Datacenters < 1:
+		return errors.New("--datacenters must be at least 1")
+	case o.Networks < o.Datacenters:
+		return errors.New("--networks must be at least --datacenters, so every datacenter has a network")
+	case o.Networks > maxNetworks:
+		return fmt.Errorf("--networks must be at most %d", maxNetworks)
+	case o.Devices < 0:
+		return errors.New("--devices cannot be negative")
+	case o.Devices > o.Networks*hostsPerNetwork:
+		return fmt.Errorf("--devices must be at most %d for %d networks", o.Networks*hostsPerNetwork, o.Networks)
+	}
+	return nil
+}
+
+// run generates the inventory. It refuses to touch storage that already has
+// networks or devices, so demo data never mixes with real data.
+func run(ctx context.Context, svc *service.Services, opts options, out io.Writer) error {
+	if err := checkEmpty(ctx, svc); err != nil {
+		return err
+	}
+
+	g := &generator{
+		svc: svc,
+		rng: rand.New(rand.NewPCG(uint64(opts.Seed), 0)),
+	}
+	if err := g.datacenters(ctx, opts.Datacenters); err != nil {
+		return err
+	}
+	if err := g.networks(ctx, opts.Networks); err != nil {
+		return err
+	}
+	if err := g.devices(ctx, opts.Devices); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Created %d datacenters, %d networks, %d pools, %d devices and %d relationships\n",
+		len(g.dcs), len(g.nets), len(g.nets), g.deviceCount, g.relationshipCount)
+	return nil
+}
+
+func checkEmpty(ctx context.Context, svc *service.Services) error {
+	one := model.Pagination{Limit: 1}
+	devices, err := svc.Devices.List(ctx, &model.DeviceFilter{Pagination: one})
+	if err != nil {
+		return err
+	}
+	networks, err := svc.Networks.List(ctx, &model.NetworkFilter{Pagination: one})
+	if err != nil {
+		return err
+	}
+	if len(devices) > 0 || len(networks) > 0 {
+		return errors.New("storage already has inventory; seed an empty data directory")
+	}
+	return nil
+}
+
+// site is a datacenter the generator can name things after
+type site struct {
+	code     string
+	name     string
+	location string
+}
+
+var sites = []site{
+	{"lon", "London LD8", "London, United Kingdom"},
+	{"fra", "Frankfurt FR5", "Frankfurt, Germany"},
+	{"ams", "Amsterdam AM7", "Amsterdam, Netherlands"},
+	{"nyc", "New York NY9", "Secaucus, New Jersey, United States"},
+	{"sjc", "San Jose SV5", "San Jose, California, United States"},
+	{"sin", "Singapore SG3", "Singapore"},
+	{"tyo", "Tokyo TY11", "Tokyo, Japan"},
+	{"syd", "Sydney SY4", "Sydney, Australia"},
+}
+
+// segments name the networks in each datacenter, in order
+var segments = []string{"servers", "mgmt", "storage", "dmz", "db", "k8s", "backup", "lab"}
+
+// role is a kind of device and how it is described and connected
+type role struct {
+	name       string
+	kind       model.DeviceKind
+	weight     int
+	makeModels []string
+	oses       []string
+	tags       []string
+}
+
+var (
+	roleHypervisor = role{"hv", model.DeviceKindPhysical, 10,
+		[]string{"Dell PowerEdge R750", "HPE ProLiant DL380 Gen10", "Supermicro SYS-2029U"},
+		[]string{"Proxmox VE 8.2", "VMware ESXi 8.0"}, []string{"hypervisor"}}
+	roleDatabase = role{"db", model.DeviceKindPhysical, 8,
+		[]string{"Dell PowerEdge R650", "HPE ProLiant DL360 Gen10"},
+		[]string{"Rocky Linux 9.4", "Ubuntu 22.04 LTS"}, []string{"db"}}
+	roleSwitch = role{"sw", model.DeviceKindNetwork, 5,
+		[]string{"Arista 7050SX3", "Juniper QFX5120", "Cisco Nexus 93180YC"},
+		[]string{"EOS 4.31", "Junos 23.2", "NX-OS 10.3"}, []string{"network"}}
+	roleStorage = role{"nas", model.DeviceKindStorage, 3,
+		[]string{"NetApp AFF A250", "Pure Storage FlashArray//X20"},
+		[]string{"ONTAP 9.14", "Purity 6.5"}, []string{"storage"}}
+	rolePDU = role{"pdu", model.DeviceKindPDU, 2,
+		[]string{"APC AP8941", "Raritan PX3-5190"},
+		nil, []string{"power"}}
+	roleWeb = role{"web", model.DeviceKindVM, 30,
+		[]string{"KVM virtual machine", "VMware virtual machine"},
+		[]string{"Ubuntu 24.04 LTS", "Debian 12"}, []string{"web"}}
+	roleApp = role{"app", model.DeviceKindVM, 42,
+		[]string{"KVM virtual machine", "VMware virtual machine"},
+		[]string{"Ubuntu 24.04 LTS", "Rocky Linux 9.4", "Debian 12"}, []string{"app"}}
+)
+
+// roles are created in this order, so the devices others depend on exist
+// first
+var roles = []role{roleSwitch, roleHypervisor, roleDatabase, roleStorage, rolePDU, roleWeb, roleApp}
+
+type seededNetwork struct {
+	network *model.Network
+	pool    *model.NetworkPool
+	next    netip.Addr
+	dc      int
+}
+
+type generator struct {
+	svc *service.Services
+	rng *rand.Rand
+
+	dcs   []*model.Datacenter
+	nets  []*seededNetwork
+	dcNet [][]*seededNetwork
+	// byRole holds the IDs of the devices of each role, by datacenter, for
+	// the relationships of later devices
+	byRole map[string][][]string
+
+	deviceCount       int
+	relationshipCount int
+}
+
+func (g *generator) datacenters(ctx context.Context, n int) error {
+	g.dcNet = make([][]*seededNetwork, n)
+	for i := range n {
+		s := sites[i%len(sites)]
+		name := s.name
+		if i >= len(sites) {
+			name = fmt.Sprintf("%s-%d", s.name, i/len(sites)+1)
+		}
+		dc := &model.Datacenter{
+			Name:        name,
+			Location:    s.location,
+			Description: "Demo datacenter created by rackd seed",
+		}
+		if err := g.svc.Datacenters.Create(ctx, dc); err != nil {
+			return fmt.Errorf("datacenter %s: %w", name, err)
+		}
+		g.dcs = append(g.dcs, dc)
+	}
+	return nil
+}
+
+// networks creates /22 networks from 10.0.0.0/8, spread over the
+// datacenters in turn, each with a pool covering its host addresses
+func (g *generator) networks(ctx context.Context, n int) error {
+	for i := range n {
+		dcIndex := i % len(g.dcs)
+		perDC := len(g.dcNet[dcIndex])
+		segment := segments[perDC%len(segments)]
+		if perDC >= len(segments) {
+			segment = fmt.Sprintf("%s%d", segment, perDC/len(segments)+1)
+		}
+
+		base := netip.AddrFrom4([4]byte{10, byte(i >> 6), byte(i & 63 << 2), 0})
+		prefix := netip.PrefixFrom(base, 22)
+		gateway := base.Next()
+		vlan := 100 + i
+
+		network := &model.Network{
+			Name:         fmt.Sprintf("%s-%s", g.code(dcIndex), segment),
+			Subnet:       prefix.String(),
+			VLANID:       vlan,
+			Gateway:      gateway.String(),
+			DNSServers:   []string{gateway.String()},
+			MTU:          1500,
+			DatacenterID: g.dcs[dcIndex].ID,
+			Description:  fmt.Sprintf("%s network, VLAN %d", segment, vlan),
+		}
+		if err := g.svc.Networks.Create(ctx, network); err != nil {
+			return fmt.Errorf("network %s: %w", network.Name, err)
+		}
+
+		// Hosts start at .10 of the first /24, leaving room for the
+		// gateway and friends, and run to the end of the /22
+		start := netip.AddrFrom4([4]byte{10, byte(i >> 6), byte(i & 63 << 2), 10})
+		end := netip.AddrFrom4([4]byte{10, byte(i >> 6), byte(i&63<<2 | 3), 254})
+		pool := &model.NetworkPool{
+			NetworkID:   network.ID,
+			Name:        network.Name + "-hosts",
+			StartIP:     start.String(),
+			EndIP:       end.String(),
+			Description: "Host addresses",
+		}
+		if err := g.svc.Pools.Create(ctx, pool); err != nil {
+			return fmt.Errorf("pool %s: %w", pool.Name, err)
+		}
+
+		sn := &seededNetwork{network: network, pool: pool, next: start, dc: dcIndex}
+		g.nets = append(g.nets, sn)
+		g.dcNet[dcIndex] = append(g.dcNet[dcIndex], sn)
+	}
+	return nil
+}
+
+// devices creates n devices, split across the roles by weight and across
+// the datacenters in turn
+func (g *generator) devices(ctx context.Context, n int) error {
+	totalWeight := 0
+	g.byRole = map[string][][]string{}
+	for _, r := range roles {
+		totalWeight += r.weight
+		g.byRole[r.name] = make([][]string, len(g.dcs))
+	}
+
+	created := 0
+	for i, r := range roles {
+		count := n * r.weight / totalWeight
+		if i == len(roles)-1 {
+			count = n - created
+		}
+		for j := range count {
+			if err := g.device(ctx, r, j); err != nil {
+				return err
+			}
+		}
+		created += count
+	}
+	return nil
+}
+
+func (g *generator) device(ctx context.Context, r role, index int) error {
+	dcIndex := index % len(g.dcs)
+	number := index/len(g.dcs) + 1
+	name := fmt.Sprintf("%s-%s-%03d", g.code(dcIndex), r.name, number)
+
+	device := &model.Device{
+		Name:         name,
+		Hostname:     name + ".example.net",
+		Description:  fmt.Sprintf("Demo %s created by rackd seed", r.name),
+		MakeModel:    pick(g.rng, r.makeModels),
+		DatacenterID: g.dcs[dcIndex].ID,
+		Kind:         r.kind,
+		Status:       g.status(),
+		Tags:         append([]string{g.environment()}, r.tags...),
+	}
+	if len(r.oses) > 0 {
+		device.OS = pick(g.rng, r.oses)
+	}
+	if r.kind != model.DeviceKindVM {
+		device.Location = fmt.Sprintf("Row %c, Rack %02d, U%d", 'A'+rune(g.rng.IntN(4)), g.rng.IntN(20)+1, g.rng.IntN(40)+1)
+	}
+
+	nets := g.dcNet[dcIndex]
+	sn := nets[g.rng.IntN(len(nets))]
+	if sn.next.Compare(netip.MustParseAddr(sn.pool.EndIP)) > 0 {
+		// This network is full; take the next one in the datacenter
+		// that is not
+		for _, other := range nets {
+			if other.next.Compare(netip.MustParseAddr(other.pool.EndIP)) <= 0 {
+				sn = other
+				break
+			}
+		}
+	}
+	if sn.next.Compare(netip.MustParseAddr(sn.pool.EndIP)) <= 0 {
+		device.Interfaces = []model.Interface{{
+			Name:       "eth0",
+			Type:       model.InterfaceTypeEthernet,
+			MACAddress: g.mac(),
+			SpeedMbps:  10000,
+			VLAN:       sn.network.VLANID,
+		}}
+		device.Addresses = []model.Address{{
+			IP:        sn.next.String(),
+			Type:      model.AddressTypeIPv4,
+			Label:     "primary",
+			NetworkID: sn.network.ID,
+			PoolID:    sn.pool.ID,
+			Interface: "eth0",
+		}}
+		sn.next = sn.next.Next()
+	}
+
+	if err := g.svc.Devices.Create(ctx, device); err != nil {
+		return fmt.Errorf("device %s: %w", name, err)
+	}
+	g.byRole[r.name][dcIndex] = append(g.byRole[r.name][dcIndex], device.ID)
+	g.deviceCount++
+	return g.relate(ctx, r, device.ID, dcIndex)
+}
+
+// relate links a device to the infrastructure in its datacenter: servers
+// and storage to a switch, VMs to a hypervisor, and applications to a
+// database
+func (g *generator) relate(ctx context.Context, r role, id string, dc int) error {
+	link := func(parentID, childID, relType string) error {
+		if err := g.svc.Relationships.Add(ctx, parentID, childID, relType, ""); err != nil {
+			return fmt.Errorf("relationship %s: %w", relType, err)
+		}
+		g.relationshipCount++
+		return nil
+	}
+
+	switch r.name {
+	case roleHypervisor.name, roleDatabase.name, roleStorage.name:
+		if sw := g.random(roleSwitch, dc); sw != "" {
+			return link(id, sw, model.RelationshipConnectedTo)
+		}
+	case roleWeb.name, roleApp.name:
+		if hv := g.random(roleHypervisor, dc); hv != "" {
+			if err := link(id, hv, model.RelationshipHostedOn); err != nil {
+				return err
+			}
+		}
+		if db := g.random(roleDatabase, dc); db != "" && g.rng.IntN(3) > 0 {
+			return link(id, db, model.RelationshipDependsOn)
+		}
+	}
+	return nil
+}
+
+// random returns a device of the role in the datacenter, or "" if there are
+// none
+func (g *generator) random(r role, dc int) string {
+	devices := g.byRole[r.name][dc]
+	if len(devices) == 0 {
+		return ""
+	}
+	return devices[g.rng.IntN(len(devices))]
+}
+
+func (g *generator) code(dc int) string {
+	code := sites[dc%len(sites)].code
+	if dc >= len(sites) {
+		code = fmt.Sprintf("%s%d", code, dc/len(sites)+1)
+	}
+	return code
+}
+
+// status returns mostly active devices, with a few on their way in or out
+func (g *generator) status() model.DeviceStatus {
+	switch n := g.rng.IntN(100); {
+	case n < 85:
+		return model.DeviceStatusActive
+	case n < 90:
+		return model.DeviceStatusPlanned
+	case n < 93:
+		return model.DeviceStatusOrdered
+	case n < 97:
+		return model.DeviceStatusMaintenance
+	default:
+		return model.DeviceStatusDecommissioned
+	}
+}
+
+func (g *generator) environment() string {
+	if g.rng.IntN(100) < 70 {
+		return "prod"
+	}
+	return pick(g.rng, []string{"staging", "dev"})
+}
+
+// mac returns a locally administered unicast MAC address
+func (g *generator) mac() string {
+	return fmt.Sprintf("02:%02x:%02x:%02x:%02x:%02x",
+		g.rng.IntN(256), g.rng.IntN(256), g.rng.IntN(256), g.rng.IntN(256), g.rng.IntN(256))
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.IntN(len(values))]
+}
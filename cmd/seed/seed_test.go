@@ -0,0 +1,112 @@
+package seed
+
+import (
+	"bytes"
+	"context"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+	"github.com/martinsuchenak/rackd/internal/storagetest"
+)
+
+func seed(t *testing.T, opts options) (*service.Services, context.Context, string) {
+	t.Helper()
+	svc := service.NewServices(storagetest.NewStorage(t), nil, nil)
+	ctx := service.SystemContext(context.Background(), "test")
+	var out bytes.Buffer
+	if err := run(ctx, svc, opts, &out); err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+	return svc, ctx, out.String()
+}
+
+func TestSeed(t *testing.T) {
+	svc, ctx, out := seed(t, options{Devices: 60, Datacenters: 2, Networks: 5, Seed: 1})
+
+	dcs, _ := svc.Datacenters.List(ctx, &model.DatacenterFilter{})
+	networks, _ := svc.Networks.List(ctx, &model.NetworkFilter{})
+	devices, err := svc.Devices.ListAll(ctx, model.DeviceFilter{})
+	if err != nil {
+		t.Fatalf("ListAll failed: %v", err)
+	}
+	// Storage starts with a default datacenter
+	if len(dcs) != 3 || len(networks) != 5 || len(devices) != 60 {
+		t.Fatalf("expected 3 datacenters, 5 networks and 60 devices, got %d, %d and %d", len(dcs), len(networks), len(devices))
+	}
+	if !strings.HasPrefix(out, "Created 2 datacenters, 5 networks, 5 pools, 60 devices and ") {
+		t.Errorf("unexpected summary %q", out)
+	}
+
+	subnets := map[string]netip.Prefix{}
+	for _, n := range networks {
+		subnets[n.ID] = netip.MustParsePrefix(n.Subnet)
+	}
+	ips := map[string]bool{}
+	kinds := map[model.DeviceKind]int{}
+	for _, d := range devices {
+		kinds[d.Kind]++
+		if len(d.Addresses) != 1 {
+			t.Fatalf("%s: expected one address, got %d", d.Name, len(d.Addresses))
+		}
+		addr := d.Addresses[0]
+		if !subnets[addr.NetworkID].Contains(netip.MustParseAddr(addr.IP)) {
+			t.Errorf("%s: %s is outside its network", d.Name, addr.IP)
+		}
+		if ips[addr.IP] {
+			t.Errorf("%s: %s is used twice", d.Name, addr.IP)
+		}
+		ips[addr.IP] = true
+	}
+	if kinds[model.DeviceKindVM] == 0 || kinds[model.DeviceKindPhysical] == 0 || kinds[model.DeviceKindNetwork] == 0 {
+		t.Errorf("expected a mix of device kinds, got %v", kinds)
+	}
+
+	rels, err := svc.Relationships.ListAll(ctx)
+	if err != nil {
+		t.Fatalf("ListAll relationships failed: %v", err)
+	}
+	types := map[string]int{}
+	for _, r := range rels {
+		types[r.Type]++
+	}
+	if types[model.RelationshipHostedOn] == 0 || types[model.RelationshipDependsOn] == 0 || types[model.RelationshipConnectedTo] == 0 {
+		t.Errorf("expected hosted_on, depends_on and connected_to relationships, got %v", types)
+	}
+}
+
+func TestSeedIsReproducible(t *testing.T) {
+	opts := options{Devices: 20, Datacenters: 1, Networks: 2, Seed: 7}
+	_, _, first := seed(t, opts)
+	_, _, second := seed(t, opts)
+	if first != second {
+		t.Errorf("expected the same seed to give the same inventory, got %q and %q", first, second)
+	}
+}
+
+func TestSeedRefusesExistingInventory(t *testing.T) {
+	svc, ctx, _ := seed(t, options{Devices: 1, Datacenters: 1, Networks: 1, Seed: 1})
+	err := run(ctx, svc, options{Devices: 1, Datacenters: 1, Networks: 1, Seed: 1}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "already has inventory") {
+		t.Errorf("expected an existing inventory error, got %v", err)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	for _, opts := range []options{
+		{Devices: 10, Datacenters: 0, Networks: 1},
+		{Devices: 10, Datacenters: 3, Networks: 2},
+		{Devices: -1, Datacenters: 1, Networks: 1},
+		{Devices: hostsPerNetwork + 1, Datacenters: 1, Networks: 1},
+		{Devices: 10, Datacenters: 1, Networks: maxNetworks + 1},
+	} {
+		if err := opts.validate(); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+	if err := (options{Devices: 500, Datacenters: 3, Networks: 20}).validate(); err != nil {
+		t.Errorf("expected the defaults to be valid, got %v", err)
+	}
+}
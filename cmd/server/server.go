@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/martinsuchenak/rackd/internal/config"
 	"github.com/martinsuchenak/rackd/internal/credentials"
@@ -17,38 +18,44 @@ import (
 
 func Command() *cli.Command {
 	return &cli.Command{
-		Name:  "server",
-		Usage: "Start the HTTP/MCP server",
+		Name:        "server",
+		Usage:       "Start the HTTP/MCP server",
+		Description: "Storage drivers: " + strings.Join(storage.Drivers(), ", "),
 		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "data-dir", Usage: "Data directory", DefaultValue: "./data"},
-			&cli.StringFlag{Name: "listen-addr", Usage: "Listen address", DefaultValue: ":8080"},
-			&cli.StringFlag{Name: "log-level", Usage: "Log level (trace/debug/info/warn/error)", DefaultValue: "info"},
-			&cli.StringFlag{Name: "log-format", Usage: "Log format (text/json)", DefaultValue: "text"},
-			&cli.StringFlag{Name: "discovery-interval", Usage: "Discovery scan interval", DefaultValue: "24h"},
+			&cli.StringFlag{Name: "config", Usage: "YAML or TOML config file (overrides CONFIG_FILE); reloaded on SIGHUP"},
+			&cli.StringFlag{Name: "data-dir", Usage: "Data directory (default ./data)"},
+			&cli.StringFlag{Name: "storage-driver", Usage: "Storage driver (" + strings.Join(storage.Drivers(), "/") + ", default sqlite)"},
+			&cli.StringFlag{Name: "storage-dsn", Usage: "Data source for the storage driver (default the data directory)"},
+			&cli.StringFlag{Name: "listen-addr", Usage: "Listen address (default :8080)"},
+			&cli.StringFlag{Name: "log-level", Usage: "Log level (trace/debug/info/warn/error, default info)"},
+			&cli.StringFlag{Name: "log-format", Usage: "Log format (text/json, default text)"},
+			&cli.StringFlag{Name: "discovery-interval", Usage: "Discovery scan interval (default 24h)"},
 			&cli.BoolFlag{Name: "dev-mode", Usage: "Development mode (relaxes security: no TLS cookies, no rate limiting, allows missing ENCRYPTION_KEY)"},
+			&cli.BoolFlag{Name: "generate-token", Usage: "On first run, mint a strong admin API token and save it to <data-dir>/admin.token"},
+			&cli.StringFlag{Name: "tls-cert", Usage: "TLS certificate file (PEM); serves HTTPS with --tls-key"},
+			&cli.StringFlag{Name: "tls-key", Usage: "TLS private key file (PEM)"},
+			&cli.StringFlag{Name: "acme-host", Usage: "Hostnames to get Let's Encrypt certificates for (comma-separated)"},
+			&cli.StringFlag{Name: "acme-email", Usage: "Contact email for the ACME account"},
+			&cli.StringFlag{Name: "http-redirect-addr", Usage: "Plain HTTP listen address that redirects to HTTPS (default with --acme-host: :80)"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
-			cfg := config.Load()
-
-			// Override config with CLI flags
-			if v := cmd.GetString("data-dir"); v != "" {
-				cfg.DataDir = v
-			}
-			if v := cmd.GetString("listen-addr"); v != "" {
-				cfg.ListenAddr = v
+			// Flags win over the environment and the config file, also
+			// when the file is reloaded
+			for flag, key := range flagSettings {
+				if v := cmd.GetString(flag); v != "" {
+					config.SetOverride(key, v)
+				}
 			}
-			if v := cmd.GetString("log-level"); v != "" {
-				cfg.LogLevel = v
-			}
-			if v := cmd.GetString("log-format"); v != "" {
-				cfg.LogFormat = v
+			cfg, err := config.LoadFile(cmd.GetString("config"))
+			if err != nil {
+				return err
 			}
 
 			// Dev mode: relax security defaults for local development
 			devMode := cmd.GetBool("dev-mode")
+			cfg.GenerateToken = cmd.GetBool("generate-token")
 			if devMode {
-				cfg.CookieSecure = false
-				cfg.RateLimitEnabled = false
+				cfg.EnableDevMode()
 				log.Init(cfg.LogFormat, cfg.LogLevel, os.Stdout)
 				log.Warn("Development mode enabled: cookie secure=false, rate limiting disabled")
 			}
@@ -56,16 +63,27 @@ func Command() *cli.Command {
 			if err := cfg.Validate(); err != nil {
 				return err
 			}
+			authWarnings, err := cfg.ValidateAuth()
+			if err != nil {
+				return fmt.Errorf("refusing to start: %w", err)
+			}
 
 			if !devMode {
 				log.Init(cfg.LogFormat, cfg.LogLevel, os.Stdout)
 			}
+			for _, w := range authWarnings {
+				log.Warn("Insecure authentication configuration: " + w)
+			}
+
+			encryptionKey, hasKey, err := getEncryptionKey(devMode)
+			if err != nil {
+				return fmt.Errorf("refusing to start: %w", err)
+			}
 
-			store, err := storage.NewExtendedStorage(cfg.DataDir)
+			store, err := storage.Open(cfg.StorageDriver, cfg.StorageDataSource())
 			if err != nil {
 				return err
 			}
-			encryptionKey, hasKey := getEncryptionKey(devMode)
 
 			// If no encryption key, run basic server without advanced features
 			if !hasKey {
@@ -96,28 +114,44 @@ func Command() *cli.Command {
 	}
 }
 
-func getEncryptionKey(devMode bool) ([]byte, bool) {
+// flagSettings maps the server flags to the settings they override
+var flagSettings = map[string]string{
+	"data-dir":           "DATA_DIR",
+	"storage-driver":     "STORAGE_DRIVER",
+	"storage-dsn":        "STORAGE_DSN",
+	"listen-addr":        "LISTEN_ADDR",
+	"log-level":          "LOG_LEVEL",
+	"log-format":         "LOG_FORMAT",
+	"discovery-interval": "DISCOVERY_INTERVAL",
+	"tls-cert":           "TLS_CERT",
+	"tls-key":            "TLS_KEY",
+	"acme-host":          "ACME_HOSTS",
+	"acme-email":         "ACME_EMAIL",
+	"http-redirect-addr": "HTTP_REDIRECT_ADDR",
+}
+
+// getEncryptionKey returns the credentials encryption key. A key that is set
+// but malformed is an error rather than silently disabling encrypted features.
+func getEncryptionKey(devMode bool) ([]byte, bool, error) {
 	keyHex := os.Getenv("ENCRYPTION_KEY")
 	if keyHex == "" {
 		if !devMode {
-			return nil, false
+			return nil, false, nil
 		}
 		fmt.Fprintln(os.Stderr, "Warning: ENCRYPTION_KEY not set - generating random key (credentials will not persist across restarts)")
 		key := make([]byte, 32)
 		if _, err := rand.Read(key); err != nil {
-			return nil, false
+			return nil, false, nil
 		}
-		return key, true
+		return key, true, nil
 	}
 
 	key, err := hex.DecodeString(keyHex)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: invalid ENCRYPTION_KEY (must be hex-encoded): %v\n", err)
-		return nil, false
+		return nil, false, fmt.Errorf("invalid ENCRYPTION_KEY (must be hex-encoded, generate one with `openssl rand -hex 32`): %w", err)
 	}
 	if len(key) != 32 {
-		fmt.Fprintln(os.Stderr, "Warning: invalid ENCRYPTION_KEY (must be 32 bytes / 64 hex chars)")
-		return nil, false
+		return nil, false, fmt.Errorf("invalid ENCRYPTION_KEY (must be 32 bytes / 64 hex chars, generate one with `openssl rand -hex 32`)")
 	}
-	return key, true
+	return key, true, nil
 }
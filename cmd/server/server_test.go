@@ -1,7 +1,10 @@
 package server
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/paularlott/cli"
 )
 
 func TestCommand(t *testing.T) {
@@ -15,7 +18,59 @@ func TestCommand(t *testing.T) {
 		t.Error("expected Run function to be set")
 	}
 
-	if len(cmd.Flags) != 6 {
-		t.Errorf("expected 6 flags, got %d", len(cmd.Flags))
+	if len(cmd.Flags) != 15 {
+		t.Errorf("expected 15 flags, got %d", len(cmd.Flags))
+	}
+
+	if !strings.Contains(cmd.Description, "sqlite") {
+		t.Errorf("expected the help to list the storage drivers, got %q", cmd.Description)
+	}
+}
+
+func TestFlagSettingsAreFlags(t *testing.T) {
+	names := map[string]bool{}
+	for _, f := range Command().Flags {
+		if sf, ok := f.(*cli.StringFlag); ok {
+			names[sf.Name] = true
+		}
+	}
+	for flag := range flagSettings {
+		if !names[flag] {
+			t.Errorf("flagSettings has %q, which is not a string flag", flag)
+		}
+	}
+}
+
+func TestGetEncryptionKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		devMode bool
+		wantKey bool
+		wantErr string
+	}{
+		{"unset", "", false, false, ""},
+		{"unset dev mode", "", true, true, ""},
+		{"valid", strings.Repeat("ab", 32), false, true, ""},
+		{"not hex", "not-hex", false, false, "hex-encoded"},
+		{"too short", "abcd", false, false, "32 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ENCRYPTION_KEY", tt.value)
+			key, ok, err := getEncryptionKey(tt.devMode)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.wantKey || (ok && len(key) != 32) {
+				t.Errorf("got key=%d bytes ok=%v, want ok=%v", len(key), ok, tt.wantKey)
+			}
+		})
 	}
 }
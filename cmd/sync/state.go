@@ -0,0 +1,189 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// stateFile sits next to the offline database and records, for every synced
+// resource, the updated_at each side had when they last matched
+const stateFile = "sync.json"
+
+type state struct {
+	Server   string            `json:"server"`
+	PulledAt *time.Time        `json:"pulled_at,omitempty"`
+	PushedAt *time.Time        `json:"pushed_at,omitempty"`
+	Records  map[string]record `json:"records"`
+}
+
+type record struct {
+	Server string `json:"server"`
+	Local  string `json:"local"`
+}
+
+func loadState(dir string) (*state, error) {
+	st := &state{Records: map[string]record{}}
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("invalid sync state in %s: %w", dir, err)
+	}
+	if st.Records == nil {
+		st.Records = map[string]record{}
+	}
+	return st, nil
+}
+
+func (st *state) save(dir string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, stateFile), data, 0600)
+}
+
+// checkServer refuses to mix two servers in one offline copy, since the
+// records would match resources by ID across unrelated inventories
+func (st *state) checkServer(server string) error {
+	if st.Server != "" && st.Server != server && len(st.Records) > 0 {
+		return fmt.Errorf("the offline copy was pulled from %s, not %s; set RACKD_OFFLINE_DIR to keep a separate copy", st.Server, server)
+	}
+	st.Server = server
+	return nil
+}
+
+// item is a resource as the API returns it. Every field is copied through
+// as is, so sync keeps up with the models without changes here.
+type item map[string]interface{}
+
+func (it item) str(key string) string {
+	s, _ := it[key].(string)
+	return s
+}
+
+// entry is a resource that differs between the server and the offline copy
+type entry struct {
+	kind    *kind
+	id      string
+	server  item // nil when the server does not have it
+	local   item // nil when the offline copy does not have it
+	tracked bool // synced before, so a missing side means it was deleted
+
+	serverChanged bool
+	localChanged  bool
+}
+
+func (e *entry) key() string { return e.kind.name + "/" + e.id }
+
+func (e *entry) name() string {
+	for _, it := range []item{e.local, e.server} {
+		if name := it.str("name"); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// conflict reports whether both sides changed the resource since the last
+// sync. Deleting it on both sides is not a conflict.
+func (e *entry) conflict() bool {
+	return e.serverChanged && e.localChanged && (e.server != nil || e.local != nil)
+}
+
+// describe explains what happened to the resource on one side
+func (e *entry) describe(it item) string {
+	switch {
+	case it == nil:
+		return "deleted"
+	case !e.tracked:
+		return "created"
+	}
+	return "updated"
+}
+
+func (e *entry) state() string {
+	switch {
+	case e.conflict():
+		return fmt.Sprintf("conflict: %s locally, %s on server", e.describe(e.local), e.describe(e.server))
+	case e.localChanged && e.serverChanged:
+		return "deleted on both sides"
+	case e.localChanged:
+		return e.describe(e.local) + " locally"
+	}
+	return e.describe(e.server) + " on server"
+}
+
+// diff compares the resources of one kind on both sides against the records
+// of the last sync and returns those that changed, by name. With serverKnown
+// false the server could not be reached and only local changes are found.
+func diff(k *kind, server, local []item, records map[string]record, serverKnown bool) []*entry {
+	byID := map[string]*entry{}
+	get := func(id string) *entry {
+		e, ok := byID[id]
+		if !ok {
+			e = &entry{kind: k, id: id}
+			byID[id] = e
+		}
+		return e
+	}
+	for _, it := range server {
+		get(it.str("id")).server = it
+	}
+	for _, it := range local {
+		get(it.str("id")).local = it
+	}
+	prefix := k.name + "/"
+	for key := range records {
+		if id, ok := strings.CutPrefix(key, prefix); ok {
+			get(id)
+		}
+	}
+
+	var changed []*entry
+	for _, e := range byID {
+		rec, tracked := records[e.key()]
+		e.tracked = tracked
+		e.localChanged = sideChanged(e.local, tracked, rec.Local)
+		if serverKnown {
+			e.serverChanged = sideChanged(e.server, tracked, rec.Server)
+		}
+		if e.localChanged || e.serverChanged {
+			changed = append(changed, e)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		if changed[i].name() != changed[j].name() {
+			return changed[i].name() < changed[j].name()
+		}
+		return changed[i].id < changed[j].id
+	})
+	return changed
+}
+
+// dropSeedData marks what a new offline copy holds before its first pull,
+// such as the default datacenter, as deleted on the server, so the pull
+// removes it instead of leaving it to be pushed
+func dropSeedData(changed []*entry) {
+	for _, e := range changed {
+		if e.server == nil && !e.tracked {
+			e.tracked, e.localChanged, e.serverChanged = true, false, true
+		}
+	}
+}
+
+func sideChanged(it item, tracked bool, updatedAt string) bool {
+	if it == nil {
+		return tracked
+	}
+	return !tracked || it.str("updated_at") != updatedAt
+}
@@ -0,0 +1,386 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/cli"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "Keep an offline copy of the inventory for --offline and reconcile it with the server",
+		Commands: []*cli.Command{
+			pullCommand(),
+			pushCommand(),
+			statusCommand(),
+		},
+	}
+}
+
+func pullCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "pull",
+		Usage: "Copy server changes into the offline copy, creating it if needed",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "force", Usage: "Resolve conflicts by taking the server's version"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			return run(true, false, cmd.GetBool("force"))
+		},
+	}
+}
+
+func pushCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "push",
+		Usage: "Send changes made in the offline copy to the server",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "force", Usage: "Resolve conflicts by taking the offline version"},
+		},
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			return run(false, false, cmd.GetBool("force"))
+		},
+	}
+}
+
+func statusCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Show what differs between the offline copy and the server",
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			return run(false, true, false)
+		},
+	}
+}
+
+// run opens both sides and syncs them. The offline copy is reached through
+// the same API as the server, served in-process, so both directions create,
+// update and delete through the handlers and services and are validated the
+// same way.
+func run(pull, statusOnly, force bool) error {
+	cfg := client.LoadConfig()
+	cfg.Offline = false
+	dir := client.OfflineDir()
+	fresh := !client.OfflineExists(dir)
+	if !pull && fresh {
+		return fmt.Errorf("no offline copy in %s, run rackd sync pull first", dir)
+	}
+
+	local := client.NewOfflineClient(dir)
+	defer local.Close()
+	s := &syncer{server: client.NewClient(cfg), local: local, force: force}
+
+	st, err := loadState(dir)
+	if err != nil {
+		return err
+	}
+	if err := st.checkServer(cfg.ServerURL); err != nil {
+		return err
+	}
+
+	if statusOnly {
+		return s.status(st)
+	}
+
+	changed, err := s.diffAll(st, true)
+	if err != nil {
+		return err
+	}
+	if fresh {
+		dropSeedData(changed)
+	}
+	res := s.apply(st, changed, pull)
+
+	now := time.Now().UTC()
+	if pull {
+		st.PulledAt = &now
+	} else {
+		st.PushedAt = &now
+	}
+	if err := st.save(dir); err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return res.report(pull)
+}
+
+// kind is a resource type copied by sync
+type kind struct {
+	name string
+	// path is the collection, with each resource at path/{id}
+	path string
+	// list returns every resource of the kind on one side
+	list func(c *client.Client) ([]item, error)
+	// createPath is where a new resource is posted
+	createPath func(it item) string
+}
+
+// kinds are in dependency order: a resource can only refer to kinds before
+// it, so they are created in this order and deleted in reverse
+var kinds = []*kind{
+	{
+		name:       "datacenter",
+		path:       "/api/datacenters",
+		list:       func(c *client.Client) ([]item, error) { return listAll(c, "/api/datacenters") },
+		createPath: func(item) string { return "/api/datacenters" },
+	},
+	{
+		name:       "network",
+		path:       "/api/networks",
+		list:       func(c *client.Client) ([]item, error) { return listAll(c, "/api/networks") },
+		createPath: func(item) string { return "/api/networks" },
+	},
+	{
+		name:       "pool",
+		path:       "/api/pools",
+		list:       listPools,
+		createPath: func(it item) string { return "/api/networks/" + it.str("network_id") + "/pools" },
+	},
+	{
+		name:       "device",
+		path:       "/api/devices",
+		list:       func(c *client.Client) ([]item, error) { return listAll(c, "/api/devices") },
+		createPath: func(item) string { return "/api/devices" },
+	},
+}
+
+// listAll fetches every page of a list endpoint
+func listAll(c *client.Client, path string) ([]item, error) {
+	var all []item
+	for offset := 0; ; offset += model.MaxPageSize {
+		var page []item
+		if err := call(c, http.MethodGet, fmt.Sprintf("%s?limit=%d&offset=%d", path, model.MaxPageSize, offset), nil, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < model.MaxPageSize {
+			return all, nil
+		}
+	}
+}
+
+// listPools fetches the pools of every network, as pools are only listed
+// per network
+func listPools(c *client.Client) ([]item, error) {
+	networks, err := listAll(c, "/api/networks")
+	if err != nil {
+		return nil, err
+	}
+	var pools []item
+	for _, n := range networks {
+		var page []item
+		if err := call(c, http.MethodGet, "/api/networks/"+n.str("id")+"/pools", nil, &page); err != nil {
+			return nil, err
+		}
+		pools = append(pools, page...)
+	}
+	return pools, nil
+}
+
+// call makes a request and decodes the response into out, if given
+func call(c *client.Client, method, path string, body, out interface{}) error {
+	resp, err := c.DoRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return client.HandleError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type syncer struct {
+	server *client.Client
+	local  *client.Client
+	force  bool
+}
+
+// diffAll lists every kind on both sides and returns what changed, in kind
+// order. Without the server only local changes are returned.
+func (s *syncer) diffAll(st *state, withServer bool) ([]*entry, error) {
+	var changed []*entry
+	for _, k := range kinds {
+		local, err := k.list(s.local)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list offline %ss: %w", k.name, err)
+		}
+		var server []item
+		if withServer {
+			if server, err = k.list(s.server); err != nil {
+				return nil, fmt.Errorf("failed to list %ss on the server: %w", k.name, err)
+			}
+		}
+		changed = append(changed, diff(k, server, local, st.Records, withServer)...)
+	}
+	return changed, nil
+}
+
+// result counts what a pull or push did
+type result struct {
+	applied   int
+	conflicts int
+	failed    int
+}
+
+func (r *result) report(pull bool) error {
+	verb := "Pushed"
+	if pull {
+		verb = "Pulled"
+	}
+	fmt.Printf("%s %d change(s)", verb, r.applied)
+	if r.conflicts > 0 {
+		fmt.Printf(", skipped %d conflict(s)", r.conflicts)
+	}
+	fmt.Println()
+	if r.failed > 0 {
+		return fmt.Errorf("%d change(s) failed", r.failed)
+	}
+	return nil
+}
+
+// apply copies each changed resource from the side that changed it. Pull
+// only takes server changes and push only local ones; a conflict is skipped
+// unless forced, when the side being copied from wins. Deletes run first, in
+// reverse kind order, so nothing is removed while still referred to.
+func (s *syncer) apply(st *state, changed []*entry, pull bool) *result {
+	winner := "offline"
+	if pull {
+		winner = "server"
+	}
+
+	res := &result{}
+	var deletes, writes []*entry
+	for _, e := range changed {
+		if e.conflict() && !s.force {
+			fmt.Printf("Conflict: %s %s (%s), use --force to keep the %s version\n", e.kind.name, e.name(), e.state(), winner)
+			res.conflicts++
+			continue
+		}
+		if !e.conflict() && ((pull && !e.serverChanged) || (!pull && !e.localChanged)) {
+			continue
+		}
+		src := e.local
+		if pull {
+			src = e.server
+		}
+		if src == nil {
+			deletes = append(deletes, e)
+		} else {
+			writes = append(writes, e)
+		}
+	}
+
+	for i := len(deletes) - 1; i >= 0; i-- {
+		s.copyEntry(st, deletes[i], pull, res)
+	}
+	for _, e := range writes {
+		s.copyEntry(st, e, pull, res)
+	}
+	return res
+}
+
+func (s *syncer) copyEntry(st *state, e *entry, pull bool, res *result) {
+	src, dst, dstItem := e.local, s.server, e.server
+	if pull {
+		src, dst, dstItem = e.server, s.local, e.local
+	}
+
+	var err error
+	switch {
+	case src == nil && dstItem == nil:
+		// Deleted on both sides
+	case src == nil:
+		err = call(dst, http.MethodDelete, e.kind.path+"/"+e.id, nil, nil)
+	default:
+		err = s.write(dst, e, src, dstItem)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s %s: %v\n", e.kind.name, e.name(), err)
+		res.failed++
+		return
+	}
+
+	if src == nil {
+		delete(st.Records, e.key())
+	} else if err := s.record(st, e); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s %s: %v\n", e.kind.name, e.name(), err)
+		res.failed++
+		return
+	}
+	fmt.Printf("%s %s %s\n", e.kind.name, e.name(), e.describe(src))
+	res.applied++
+}
+
+// write creates or updates the resource on dst, which holds it as dstItem
+// if at all. One deleted there since the last sync is restored from the
+// trash first, as it keeps its ID.
+func (s *syncer) write(dst *client.Client, e *entry, src, dstItem item) error {
+	exists := dstItem != nil
+	if !exists && e.tracked {
+		exists = call(dst, http.MethodPost, "/api/trash/"+e.id+"/restore", nil, nil) == nil
+	}
+	if !exists {
+		return call(dst, http.MethodPost, e.kind.createPath(src), src, nil)
+	}
+
+	// Empty fields are left out of the JSON, but still have to be cleared
+	body := item{}
+	for key, v := range dstItem {
+		if _, ok := v.(string); ok {
+			body[key] = ""
+		}
+	}
+	for key, v := range src {
+		body[key] = v
+	}
+	return call(dst, http.MethodPut, e.kind.path+"/"+e.id, body, nil)
+}
+
+// record reads the resource back from both sides and stores their
+// updated_at as the new point where they match
+func (s *syncer) record(st *state, e *entry) error {
+	var server, local item
+	if err := call(s.server, http.MethodGet, e.kind.path+"/"+e.id, nil, &server); err != nil {
+		return err
+	}
+	if err := call(s.local, http.MethodGet, e.kind.path+"/"+e.id, nil, &local); err != nil {
+		return err
+	}
+	st.Records[e.key()] = record{Server: server.str("updated_at"), Local: local.str("updated_at")}
+	return nil
+}
+
+// status lists the pending changes. If the server cannot be reached only
+// the local ones are shown.
+func (s *syncer) status(st *state) error {
+	changed, err := s.diffAll(st, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v, showing local changes only\n", err)
+		if changed, err = s.diffAll(st, false); err != nil {
+			return err
+		}
+	}
+
+	if len(changed) == 0 {
+		fmt.Printf("The offline copy is in sync with %s\n", st.Server)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tID\tSTATE")
+	for _, e := range changed {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.kind.name, e.name(), e.id, e.state())
+	}
+	return w.Flush()
+}
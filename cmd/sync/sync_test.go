@@ -0,0 +1,205 @@
+package sync
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/internal/api"
+	"github.com/martinsuchenak/rackd/internal/service"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// newTestServer serves the API over a fresh database and points sync at it
+// and at an empty offline directory. It runs as the system caller, so the
+// test needs no API key. It returns clients for both sides.
+func newTestServer(t *testing.T) (server, local *client.Client) {
+	t.Helper()
+	store, err := storage.NewExtendedStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	h := api.NewHandler(store, nil, api.WithServices(service.NewServices(store, nil, nil)), api.WithLocalAccess("test"))
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+
+	dir := t.TempDir()
+	t.Setenv("RACKD_SERVER_URL", srv.URL)
+	t.Setenv("RACKD_OFFLINE_DIR", dir)
+	t.Setenv("RACKD_OFFLINE", "")
+
+	local = client.NewOfflineClient(dir)
+	t.Cleanup(func() {
+		local.Close()
+		srv.Close()
+		store.Close()
+	})
+	return client.NewClient(&client.Config{ServerURL: srv.URL, Timeout: "5s"}), local
+}
+
+func mustCall(t *testing.T, c *client.Client, method, path string, body interface{}) item {
+	t.Helper()
+	var out item
+	if method == http.MethodDelete {
+		if err := call(c, method, path, body, nil); err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		return nil
+	}
+	if err := call(c, method, path, body, &out); err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	return out
+}
+
+// runSync runs a sync with the offline client closed, as the command opens
+// its own, and returns the error it reported
+func runSync(t *testing.T, local *client.Client, pull, force bool) {
+	t.Helper()
+	local.Close()
+	stdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+	defer func() { os.Stdout = stdout }()
+	if err := run(pull, false, force); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+}
+
+func pendingChanges(t *testing.T, server, local *client.Client) map[string]string {
+	t.Helper()
+	st, err := loadState(client.OfflineDir())
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	s := &syncer{server: server, local: local}
+	changed, err := s.diffAll(st, true)
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	states := map[string]string{}
+	for _, e := range changed {
+		states[e.name()] = e.state()
+	}
+	return states
+}
+
+func TestSyncPullPush(t *testing.T) {
+	server, local := newTestServer(t)
+
+	dc := mustCall(t, server, http.MethodPost, "/api/datacenters", map[string]string{"name": "dc1"})
+	network := mustCall(t, server, http.MethodPost, "/api/networks", map[string]interface{}{"name": "lan", "subnet": "10.0.0.0/24", "datacenter_id": dc["id"]})
+	mustCall(t, server, http.MethodPost, "/api/networks/"+network.str("id")+"/pools", map[string]string{"name": "servers", "start_ip": "10.0.0.10", "end_ip": "10.0.0.50"})
+	device := mustCall(t, server, http.MethodPost, "/api/devices", map[string]interface{}{"name": "web-01", "hostname": "web-01.lan", "datacenter_id": dc["id"]})
+
+	runSync(t, local, true, false)
+	if pending := pendingChanges(t, server, local); len(pending) != 0 {
+		t.Fatalf("expected the offline copy to match after a pull, got %v", pending)
+	}
+	got := mustCall(t, local, http.MethodGet, "/api/devices/"+device.str("id"), nil)
+	if got.str("hostname") != "web-01.lan" || got.str("datacenter_id") != dc.str("id") {
+		t.Errorf("unexpected offline device: %v", got)
+	}
+
+	// Offline edits are pending until pushed, including a cleared field
+	mustCall(t, local, http.MethodPut, "/api/devices/"+device.str("id"), map[string]string{"description": "edited offline", "hostname": ""})
+	mustCall(t, local, http.MethodPost, "/api/datacenters", map[string]string{"name": "dc2"})
+	pending := pendingChanges(t, server, local)
+	if pending["web-01"] != "updated locally" || pending["dc2"] != "created locally" || len(pending) != 2 {
+		t.Fatalf("unexpected pending changes: %v", pending)
+	}
+
+	runSync(t, local, false, false)
+	got = mustCall(t, server, http.MethodGet, "/api/devices/"+device.str("id"), nil)
+	if got.str("description") != "edited offline" || got.str("hostname") != "" {
+		t.Errorf("expected the offline edit on the server, got %v", got)
+	}
+	if pending := pendingChanges(t, server, local); len(pending) != 0 {
+		t.Fatalf("expected nothing pending after a push, got %v", pending)
+	}
+
+	// A resource changed on both sides is a conflict, left alone unless forced
+	mustCall(t, server, http.MethodPut, "/api/devices/"+device.str("id"), map[string]string{"description": "edited on server"})
+	mustCall(t, local, http.MethodPut, "/api/devices/"+device.str("id"), map[string]string{"description": "edited again offline"})
+	if state := pendingChanges(t, server, local)["web-01"]; state != "conflict: updated locally, updated on server" {
+		t.Fatalf("expected a conflict, got %q", state)
+	}
+	runSync(t, local, true, false)
+	if got := mustCall(t, local, http.MethodGet, "/api/devices/"+device.str("id"), nil); got.str("description") != "edited again offline" {
+		t.Errorf("expected the conflict to keep the offline version, got %q", got.str("description"))
+	}
+	runSync(t, local, true, true)
+	if got := mustCall(t, local, http.MethodGet, "/api/devices/"+device.str("id"), nil); got.str("description") != "edited on server" {
+		t.Errorf("expected --force to take the server version, got %q", got.str("description"))
+	}
+
+	// Deletes on the server reach the offline copy
+	mustCall(t, server, http.MethodDelete, "/api/devices/"+device.str("id"), nil)
+	runSync(t, local, true, false)
+	if err := call(local, http.MethodGet, "/api/devices/"+device.str("id"), nil, nil); err == nil {
+		t.Error("expected the device to be deleted from the offline copy")
+	}
+	if pending := pendingChanges(t, server, local); len(pending) != 0 {
+		t.Fatalf("expected nothing pending after the delete, got %v", pending)
+	}
+}
+
+func TestSyncServerMismatch(t *testing.T) {
+	_, local := newTestServer(t)
+	runSync(t, local, true, false)
+	mustCall(t, local, http.MethodPost, "/api/datacenters", map[string]string{"name": "dc1"})
+	runSync(t, local, false, false)
+
+	t.Setenv("RACKD_SERVER_URL", "http://other.example")
+	local.Close()
+	if err := run(true, false, false); err == nil {
+		t.Error("expected an error pulling from a different server")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	k := kinds[0]
+	at := func(id, updatedAt string) item { return item{"id": id, "name": id, "updated_at": updatedAt} }
+	records := map[string]record{
+		"datacenter/same":     {Server: "s1", Local: "l1"},
+		"datacenter/local":    {Server: "s1", Local: "l1"},
+		"datacenter/server":   {Server: "s1", Local: "l1"},
+		"datacenter/both":     {Server: "s1", Local: "l1"},
+		"datacenter/gone":     {Server: "s1", Local: "l1"},
+		"datacenter/localdel": {Server: "s1", Local: "l1"},
+	}
+	server := []item{at("same", "s1"), at("local", "s1"), at("server", "s2"), at("both", "s2"), at("localdel", "s1"), at("new", "s1")}
+	local := []item{at("same", "l1"), at("local", "l2"), at("server", "l1"), at("both", "l2"), at("new", "l1")}
+
+	want := map[string]string{
+		"local":    "updated locally",
+		"server":   "updated on server",
+		"both":     "conflict: updated locally, updated on server",
+		"gone":     "deleted on both sides",
+		"localdel": "deleted locally",
+		"new":      "conflict: created locally, created on server",
+	}
+	got := map[string]string{}
+	for _, e := range diff(k, server, local, records, true) {
+		got[e.id] = e.state()
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d changes, got %v", len(want), got)
+	}
+	for id, state := range want {
+		if got[id] != state {
+			t.Errorf("%s: expected %q, got %q", id, state, got[id])
+		}
+	}
+
+	// Without the server only local changes are found
+	got = map[string]string{}
+	for _, e := range diff(k, nil, local, records, false) {
+		got[e.id] = e.state()
+	}
+	if len(got) != 5 || got["server"] != "" || got["localdel"] != "deleted locally" || got["new"] != "created locally" {
+		t.Errorf("unexpected local-only changes: %v", got)
+	}
+}
@@ -0,0 +1,347 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/paularlott/cli"
+	ui "github.com/paularlott/cli/tui"
+)
+
+// noRack labels devices without a location; the inventory has no rack model,
+// so a device's location is its rack
+const noRack = "(no location)"
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "tui",
+		Usage: "Browse datacenters, racks and devices in an interactive terminal UI",
+		Run: func(ctx context.Context, cmd *cli.Command) error {
+			b := &browser{c: client.NewClient(client.LoadConfig())}
+			b.t = ui.New(ui.Config{
+				StatusLeft:  "rackd",
+				StatusRight: "Enter search · /browse · /exit",
+				HideHeaders: true,
+				OnSubmit:    b.search,
+				OnEscape:    b.browse,
+				Commands: []*ui.Command{
+					{Name: "browse", Description: "Browse datacenters, racks and devices", Handler: func(string) { b.browse() }},
+					{Name: "search", Description: "Search devices", Handler: b.search},
+					{Name: "clear", Description: "Clear the output", Handler: func(string) { b.t.ClearOutput() }},
+					{Name: "exit", Description: "Exit the browser", Handler: func(string) { b.t.Exit() }},
+				},
+			})
+
+			b.t.AddMessage(ui.RoleSystem, "Type to search devices, or press Esc to browse datacenters.")
+			b.browse()
+			return b.t.Run(ctx)
+		},
+	}
+}
+
+// browser drives the menus. Each level is fetched when it is opened, and
+// carries a back function to reopen its parent.
+type browser struct {
+	c *client.Client
+	t *ui.TUI
+}
+
+// get fetches path into v, reporting failures in the output
+func (b *browser) get(path string, v interface{}) bool {
+	b.t.StartSpinner("Loading")
+	defer b.t.StopSpinner()
+
+	resp, err := b.c.DoRequest("GET", path, nil)
+	if err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			err = client.HandleError(resp)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(v)
+		}
+	}
+	if err != nil {
+		b.t.AddMessage(ui.RoleSystem, "Error: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func (b *browser) browse() {
+	var datacenters []map[string]interface{}
+	if !b.get("/api/datacenters", &datacenters) {
+		return
+	}
+
+	items := make([]*ui.MenuItem, 0, len(datacenters)+1)
+	for _, dc := range datacenters {
+		dc := dc
+		items = append(items, &ui.MenuItem{
+			Label: client.GetString(dc, "name"),
+			OnSelect: func(*ui.MenuItem, string) {
+				b.racks(client.GetString(dc, "name"), "/api/datacenters/"+client.GetString(dc, "id")+"/devices")
+			},
+		})
+	}
+	items = append(items, &ui.MenuItem{
+		Label: "(no datacenter)",
+		OnSelect: func(*ui.MenuItem, string) {
+			b.racks("(no datacenter)", "")
+		},
+	})
+	b.t.OpenMenu(&ui.Menu{Title: "Datacenters", Items: items})
+}
+
+// racks lists the racks of the devices at path. An empty path lists devices
+// not assigned to any datacenter.
+func (b *browser) racks(title, path string) {
+	var devices []map[string]interface{}
+	if path == "" {
+		if !b.get("/api/devices", &devices) {
+			return
+		}
+		devices = slices.DeleteFunc(devices, func(d map[string]interface{}) bool {
+			return client.GetString(d, "datacenter_id") != ""
+		})
+	} else if !b.get(path, &devices) {
+		return
+	}
+
+	back := func() { b.racks(title, path) }
+	racks := groupByRack(devices)
+	items := []*ui.MenuItem{{Label: "‹ Back", OnSelect: func(*ui.MenuItem, string) { b.browse() }}}
+	for _, rack := range racks {
+		rack := rack
+		items = append(items, &ui.MenuItem{
+			Label: fmt.Sprintf("%s (%d)", rack.name, len(rack.devices)),
+			OnSelect: func(*ui.MenuItem, string) {
+				b.devices(title+" › "+rack.name, rack.devices, back)
+			},
+		})
+	}
+	b.t.OpenMenu(&ui.Menu{Title: title, Items: items})
+}
+
+func (b *browser) devices(title string, devices []map[string]interface{}, back func()) {
+	reopen := func() { b.devices(title, devices, back) }
+	items := []*ui.MenuItem{{Label: "‹ Back", OnSelect: func(*ui.MenuItem, string) { back() }}}
+	for _, d := range devices {
+		id := client.GetString(d, "id")
+		items = append(items, &ui.MenuItem{
+			Label: deviceLabel(d),
+			OnSelect: func(*ui.MenuItem, string) {
+				b.device(id, reopen)
+			},
+		})
+	}
+	b.t.OpenMenu(&ui.Menu{Title: title, Items: items})
+}
+
+// device shows a device's details and offers to edit its tags
+func (b *browser) device(id string, back func()) {
+	var d map[string]interface{}
+	if !b.get("/api/devices/"+url.PathEscape(id), &d) {
+		back()
+		return
+	}
+	b.t.AddMessage(ui.RoleAssistant, formatDevice(d))
+
+	tags := deviceTags(d)
+	b.t.OpenMenu(&ui.Menu{
+		Title: client.GetString(d, "name"),
+		Items: []*ui.MenuItem{
+			{Label: "‹ Back", OnSelect: func(*ui.MenuItem, string) { back() }},
+			{
+				Label:  "Edit tags",
+				Prompt: "Tags: " + strings.Join(tags, ", ") + " (list to replace, +tag/-tag to change)",
+				OnSelect: func(_ *ui.MenuItem, input string) {
+					if strings.TrimSpace(input) != "" {
+						b.setTags(id, editTags(tags, input))
+					}
+					b.device(id, back)
+				},
+			},
+		},
+	})
+}
+
+func (b *browser) setTags(id string, tags []string) {
+	resp, err := b.c.DoRequest("PUT", "/api/devices/"+url.PathEscape(id), map[string]interface{}{"tags": tags})
+	if err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			err = client.HandleError(resp)
+		}
+	}
+	if err != nil {
+		b.t.AddMessage(ui.RoleSystem, "Error: "+err.Error())
+		return
+	}
+	b.t.AddMessage(ui.RoleSystem, "Tags updated")
+}
+
+func (b *browser) search(query string) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return
+	}
+	b.t.AddMessage(ui.RoleUser, query)
+
+	var result struct {
+		Results []struct {
+			Type   string                 `json:"type"`
+			Device map[string]interface{} `json:"device"`
+		} `json:"results"`
+	}
+	if !b.get("/api/search?"+url.Values{"q": {query}}.Encode(), &result) {
+		return
+	}
+	var devices []map[string]interface{}
+	for _, r := range result.Results {
+		if r.Type == "device" && r.Device != nil {
+			devices = append(devices, r.Device)
+		}
+	}
+	if len(devices) == 0 {
+		b.t.AddMessage(ui.RoleSystem, "No devices match "+query)
+		return
+	}
+	b.devices("Search: "+query, devices, b.t.CloseMenu)
+}
+
+type rack struct {
+	name    string
+	devices []map[string]interface{}
+}
+
+// groupByRack groups devices by location, sorted by name with devices
+// without a location last
+func groupByRack(devices []map[string]interface{}) []rack {
+	index := make(map[string]int)
+	var racks []rack
+	for _, d := range devices {
+		name := strings.TrimSpace(client.GetString(d, "location"))
+		if name == "" {
+			name = noRack
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(racks)
+			index[name] = i
+			racks = append(racks, rack{name: name})
+		}
+		racks[i].devices = append(racks[i].devices, d)
+	}
+
+	slices.SortFunc(racks, func(a, b rack) int {
+		if (a.name == noRack) != (b.name == noRack) {
+			if a.name == noRack {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	return racks
+}
+
+func deviceLabel(d map[string]interface{}) string {
+	label := client.GetString(d, "name")
+	if status := client.GetString(d, "status"); status != "" {
+		label += " [" + status + "]"
+	}
+	return label
+}
+
+func deviceTags(d map[string]interface{}) []string {
+	var tags []string
+	if list, ok := d["tags"].([]interface{}); ok {
+		for _, t := range list {
+			if s, ok := t.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	}
+	return tags
+}
+
+// editTags applies a tag edit. Input of only +tag and -tag entries adds and
+// removes tags; anything else replaces the tags, and "-" alone clears them.
+func editTags(current []string, input string) []string {
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 1 && fields[0] == "-" {
+		return []string{}
+	}
+
+	incremental := true
+	for _, f := range fields {
+		if !strings.HasPrefix(f, "+") && !strings.HasPrefix(f, "-") {
+			incremental = false
+			break
+		}
+	}
+
+	tags := []string{}
+	if incremental {
+		tags = append(tags, current...)
+	}
+	for _, f := range fields {
+		switch {
+		case incremental && strings.HasPrefix(f, "-"):
+			tags = slices.DeleteFunc(tags, func(t string) bool { return t == f[1:] })
+		case incremental:
+			f = f[1:]
+			fallthrough
+		default:
+			if f != "" && !slices.Contains(tags, f) {
+				tags = append(tags, f)
+			}
+		}
+	}
+	return tags
+}
+
+func formatDevice(d map[string]interface{}) string {
+	var sb strings.Builder
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "%-12s %s\n", label+":", value)
+		}
+	}
+	field("Name", client.GetString(d, "name"))
+	field("ID", client.GetString(d, "id"))
+	field("Status", client.GetString(d, "status"))
+	field("Hostname", client.GetString(d, "hostname"))
+	field("Description", client.GetString(d, "description"))
+	field("Make/Model", client.GetString(d, "make_model"))
+	field("OS", client.GetString(d, "os"))
+	field("Datacenter", client.GetString(d, "datacenter_id"))
+	field("Location", client.GetString(d, "location"))
+	field("Tags", strings.Join(deviceTags(d), ", "))
+	field("Domains", client.FormatValue(d["domains"]))
+
+	if addrs, ok := d["addresses"].([]interface{}); ok && len(addrs) > 0 {
+		sb.WriteString("Addresses:\n")
+		for _, a := range addrs {
+			addr, ok := a.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			sb.WriteString("  - " + client.GetString(addr, "ip"))
+			if port, ok := addr["port"].(float64); ok && port > 0 {
+				fmt.Fprintf(&sb, ":%d", int(port))
+			}
+			if t := client.GetString(addr, "type"); t != "" {
+				sb.WriteString(" (" + t + ")")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	cmd := Command()
+	if cmd.Name != "tui" {
+		t.Errorf("expected name 'tui', got %q", cmd.Name)
+	}
+	if cmd.Run == nil {
+		t.Error("expected Run to be set")
+	}
+}
+
+func TestGroupByRack(t *testing.T) {
+	devices := []map[string]interface{}{
+		{"name": "a", "location": "R2"},
+		{"name": "b"},
+		{"name": "c", "location": "R1"},
+		{"name": "d", "location": "R2"},
+	}
+
+	racks := groupByRack(devices)
+	var names []string
+	for _, r := range racks {
+		names = append(names, r.name)
+	}
+	if want := []string{"R1", "R2", noRack}; !slices.Equal(names, want) {
+		t.Fatalf("expected racks %v, got %v", want, names)
+	}
+	if len(racks[1].devices) != 2 {
+		t.Errorf("expected 2 devices in R2, got %d", len(racks[1].devices))
+	}
+}
+
+func TestEditTags(t *testing.T) {
+	current := []string{"web", "prod"}
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"db, staging", []string{"db", "staging"}},
+		{"+db -prod", []string{"web", "db"}},
+		{"+web", []string{"web", "prod"}},
+		{"-", []string{}},
+		{"-web,-prod", []string{}},
+	}
+	for _, tt := range tests {
+		if got := editTags(current, tt.input); !slices.Equal(got, tt.want) {
+			t.Errorf("editTags(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+	if !slices.Equal(current, []string{"web", "prod"}) {
+		t.Errorf("current tags were modified: %v", current)
+	}
+}
+
+func TestFormatDevice(t *testing.T) {
+	out := formatDevice(map[string]interface{}{
+		"name": "web-01",
+		"tags": []interface{}{"a", "b"},
+		"addresses": []interface{}{
+			map[string]interface{}{"ip": "10.0.0.1", "port": float64(22), "type": "ipv4"},
+		},
+	})
+	for _, want := range []string{"Name:        web-01", "Tags:        a, b", "  - 10.0.0.1:22 (ipv4)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "OS:") {
+		t.Errorf("expected empty fields to be left out:\n%s", out)
+	}
+}
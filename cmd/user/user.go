@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/paularlott/cli"
@@ -62,25 +63,25 @@ func ListCommand() *cli.Command {
 				return fmt.Errorf("failed to decode response: %w", err)
 			}
 
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "ID\tUSERNAME\tEMAIL\tNAME\tADMIN\tACTIVE\tCREATED")
-			for _, user := range users {
-				admin := "no"
-				if user.IsAdmin {
-					admin = "yes"
+			return client.Render(users, func(bool) {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tUSERNAME\tEMAIL\tNAME\tADMIN\tACTIVE\tCREATED")
+				for _, user := range users {
+					admin := "no"
+					if user.IsAdmin {
+						admin = "yes"
+					}
+					active := "no"
+					if user.IsActive {
+						active = "yes"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						user.ID, user.Username, user.Email,
+						user.FullName, admin, active,
+						user.CreatedAt.Format("2006-01-02 15:04"))
 				}
-				active := "no"
-				if user.IsActive {
-					active = "yes"
-				}
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-					user.ID, user.Username, user.Email,
-					user.FullName, admin, active,
-					user.CreatedAt.Format("2006-01-02 15:04"))
-			}
-			w.Flush()
-
-			return nil
+				w.Flush()
+			})
 		},
 	}
 }
@@ -112,6 +113,10 @@ func CreateCommand() *cli.Command {
 				Name:  "role-id",
 				Usage: "Assign role ID to the user (can be repeated)",
 			},
+			&cli.StringSliceFlag{
+				Name:  "role",
+				Usage: "Assign role by name, e.g. viewer or operator (can be repeated)",
+			},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -120,6 +125,12 @@ func CreateCommand() *cli.Command {
 			username := cmd.GetString("username")
 			email := cmd.GetString("email")
 
+			// Resolve role names before prompting, so a typo doesn't waste the password
+			namedRoleIDs, err := roleIDsByName(c, cmd.GetStringSlice("role"))
+			if err != nil {
+				return err
+			}
+
 			fmt.Printf("Enter password for %s: ", username)
 			password1Bytes, err := term.ReadPassword(int(os.Stdin.Fd()))
 			if err != nil {
@@ -145,7 +156,7 @@ func CreateCommand() *cli.Command {
 				return fmt.Errorf("password must be at least 8 characters")
 			}
 
-			roleIDs := cmd.GetStringSlice("role-id")
+			roleIDs := append(cmd.GetStringSlice("role-id"), namedRoleIDs...)
 
 			req := map[string]interface{}{
 				"username":  username,
@@ -236,6 +247,14 @@ func UpdateCommand() *cli.Command {
 				Name:  "remove-role-id",
 				Usage: "Revoke role ID from the user (can be repeated)",
 			},
+			&cli.StringSliceFlag{
+				Name:  "add-role",
+				Usage: "Assign role by name (can be repeated)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "remove-role",
+				Usage: "Revoke role by name (can be repeated)",
+			},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -246,6 +265,16 @@ func UpdateCommand() *cli.Command {
 			addRoleIDs := cmd.GetStringSlice("add-role-id")
 			removeRoleIDs := cmd.GetStringSlice("remove-role-id")
 
+			namedRoleIDs, err := roleIDsByName(c, cmd.GetStringSlice("add-role"))
+			if err != nil {
+				return err
+			}
+			addRoleIDs = append(addRoleIDs, namedRoleIDs...)
+			if namedRoleIDs, err = roleIDsByName(c, cmd.GetStringSlice("remove-role")); err != nil {
+				return err
+			}
+			removeRoleIDs = append(removeRoleIDs, namedRoleIDs...)
+
 			if cmd.GetBool("active") && cmd.GetBool("inactive") {
 				return fmt.Errorf("cannot set both --active and --inactive")
 			}
@@ -341,6 +370,44 @@ func UpdateCommand() *cli.Command {
 	}
 }
 
+// roleIDsByName returns the IDs of the roles with the given names
+func roleIDsByName(c *client.Client, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	resp, err := c.DoRequest("GET", "/api/roles", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, client.HandleError(resp)
+	}
+
+	var roles []model.RoleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&roles); err != nil {
+		return nil, fmt.Errorf("failed to decode roles: %w", err)
+	}
+
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		id := ""
+		for _, role := range roles {
+			if strings.EqualFold(role.Name, name) {
+				id = role.ID
+				break
+			}
+		}
+		if id == "" {
+			return nil, fmt.Errorf("role %q not found", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 func assignRole(c *client.Client, userID, roleID string) error {
 	resp, err := c.DoRequest("POST", "/api/users/grant-role", model.GrantRoleRequest{
 		UserID: userID,
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/martinsuchenak/rackd/cmd/client"
@@ -152,3 +153,36 @@ func TestUserRoleHelpersAndUserAPIIntegration(t *testing.T) {
 		t.Fatalf("expected captured JSON request bodies, got %d", len(seenBodies))
 	}
 }
+
+func TestRoleIDsByName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/roles" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"id": "role-admin", "name": "admin"},
+			{"id": "role-viewer", "name": "viewer"},
+		})
+	}))
+	defer server.Close()
+
+	c := client.NewClient(&client.Config{ServerURL: server.URL, Timeout: "5s"})
+
+	ids, err := roleIDsByName(c, []string{"Viewer", "admin"})
+	if err != nil {
+		t.Fatalf("roleIDsByName failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "role-viewer" || ids[1] != "role-admin" {
+		t.Fatalf("unexpected role IDs: %v", ids)
+	}
+
+	if _, err := roleIDsByName(c, []string{"editor"}); err == nil || !strings.Contains(err.Error(), `"editor" not found`) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	if ids, err := roleIDsByName(c, nil); err != nil || ids != nil {
+		t.Fatalf("expected no lookup without names, got %v, %v", ids, err)
+	}
+}
@@ -16,9 +16,6 @@ func EventsCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "events",
 		Usage: "List all available event types",
-		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
-		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
 			c := client.NewClient(cfg)
@@ -38,15 +35,9 @@ func EventsCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(events)
-			case "yaml":
-				client.PrintYAML(events)
-			default:
+			return client.Render(events, func(bool) {
 				printEventsTable(events)
-			}
-			return nil
+			})
 		},
 	}
 }
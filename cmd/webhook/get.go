@@ -15,7 +15,6 @@ func GetCommand() *cli.Command {
 		Usage: "Get a webhook by ID",
 		Flags: []cli.Flag{
 			&cli.StringFlag{Name: "id", Usage: "Webhook ID", Required: true},
-			&cli.StringFlag{Name: "output", Usage: "Output format (json/yaml)", DefaultValue: "json"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -37,13 +36,9 @@ func GetCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "yaml":
+			return client.Render(webhook, func(bool) {
 				client.PrintYAML(webhook)
-			default:
-				client.PrintJSON(webhook)
-			}
-			return nil
+			})
 		},
 	}
 }
@@ -18,7 +18,6 @@ func ListCommand() *cli.Command {
 		Usage: "List all webhooks",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{Name: "active", Usage: "Show only active webhooks"},
-			&cli.StringFlag{Name: "output", Usage: "Output format (table/json/yaml)", DefaultValue: "table"},
 		},
 		Run: func(ctx context.Context, cmd *cli.Command) error {
 			cfg := client.LoadConfig()
@@ -44,20 +43,15 @@ func ListCommand() *cli.Command {
 				return err
 			}
 
-			switch cmd.GetString("output") {
-			case "json":
-				client.PrintJSON(webhooks)
-			case "yaml":
-				client.PrintYAML(webhooks)
-			default:
-				printWebhookTable(webhooks)
-			}
-			return nil
+			return client.Render(webhooks, func(wide bool) {
+				printWebhookTable(webhooks, wide)
+			})
 		},
 	}
 }
 
-func printWebhookTable(webhooks []map[string]interface{}) {
+// printWebhookTable truncates IDs and event lists unless wide is set
+func printWebhookTable(webhooks []map[string]interface{}, wide bool) {
 	if len(webhooks) == 0 {
 		println("No webhooks found")
 		return
@@ -67,7 +61,7 @@ func printWebhookTable(webhooks []map[string]interface{}) {
 	fmt.Fprintln(w, "ID\tNAME\tURL\tACTIVE\tEVENTS")
 	for _, wh := range webhooks {
 		id := getString(wh, "id")
-		if len(id) > 8 {
+		if len(id) > 8 && !wide {
 			id = id[:8]
 		}
 		active := "no"
@@ -82,7 +76,7 @@ func printWebhookTable(webhooks []map[string]interface{}) {
 				}
 				events += e.(string)
 			}
-			if len(events) > 30 {
+			if len(events) > 30 && !wide {
 				events = events[:27] + "..."
 			}
 		}
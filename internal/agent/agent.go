@@ -0,0 +1,304 @@
+// Package agent implements the remote scanning agent. An agent runs at a site
+// the central server cannot reach, scans its assigned networks locally and
+// pushes the results to the server over the API.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/discovery"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// Config holds agent settings
+type Config struct {
+	ServerURL           string
+	Token               string
+	HeartbeatInterval   time.Duration
+	DefaultScanInterval time.Duration // Used for networks without a discovery rule
+	ScanTimeout         time.Duration // Per-host probe timeout
+	SNMPv2cEnabled      bool
+	Version             string
+}
+
+// Agent scans assigned networks and reports to the central server
+type Agent struct {
+	cfg      Config
+	client   *http.Client
+	store    storage.ExtendedStorage
+	scanner  *discovery.UnifiedScanner
+	hostname string
+
+	mu       sync.Mutex
+	lastScan map[string]time.Time
+	scanning bool
+	wg       sync.WaitGroup
+}
+
+// New creates an agent. Scan results are staged in an in-memory database so
+// the regular discovery scanner can be reused unchanged.
+func New(cfg Config) (*Agent, error) {
+	if cfg.ServerURL == "" {
+		return nil, errors.New("server URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("agent token is required")
+	}
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = time.Minute
+	}
+	if cfg.DefaultScanInterval <= 0 {
+		cfg.DefaultScanInterval = 24 * time.Hour
+	}
+	if cfg.ScanTimeout <= 0 {
+		cfg.ScanTimeout = 30 * time.Second
+	}
+	cfg.ServerURL = strings.TrimRight(cfg.ServerURL, "/")
+
+	store, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local storage: %w", err)
+	}
+	hostname, _ := os.Hostname()
+
+	return &Agent{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		store:    store,
+		scanner:  discovery.NewUnifiedScanner(store, nil, cfg.ScanTimeout, cfg.SNMPv2cEnabled),
+		hostname: hostname,
+		lastScan: make(map[string]time.Time),
+	}, nil
+}
+
+// Run sends heartbeats and scans due networks until ctx is cancelled. Scans
+// run in the background so heartbeats continue during long scans.
+func (a *Agent) Run(ctx context.Context) error {
+	defer a.store.Close()
+	defer a.wg.Wait()
+
+	ticker := time.NewTicker(a.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		cfg, err := a.Heartbeat(ctx)
+		if err != nil {
+			log.Error("Agent heartbeat failed", "error", err)
+		} else {
+			a.startDueScans(ctx, cfg)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Heartbeat reports the agent as alive and fetches its assigned networks
+func (a *Agent) Heartbeat(ctx context.Context) (*model.AgentConfig, error) {
+	var cfg model.AgentConfig
+	hb := model.AgentHeartbeat{Hostname: a.hostname, Version: a.cfg.Version}
+	if err := a.post(ctx, "/api/agent/heartbeat", hb, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (a *Agent) startDueScans(ctx context.Context, cfg *model.AgentConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.scanning {
+		return
+	}
+
+	now := time.Now()
+	var due []model.AgentNetwork
+	for _, n := range cfg.Networks {
+		interval, enabled := a.scanInterval(n.Rule)
+		if !enabled {
+			continue
+		}
+		if last, ok := a.lastScan[n.Network.ID]; ok && now.Sub(last) < interval {
+			continue
+		}
+		due = append(due, n)
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	a.scanning = true
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		for _, n := range due {
+			if ctx.Err() != nil {
+				break
+			}
+			if err := a.ScanNetwork(ctx, n); err != nil {
+				log.Error("Agent scan failed", "network", n.Network.Name, "error", err)
+			}
+			a.mu.Lock()
+			a.lastScan[n.Network.ID] = time.Now()
+			a.mu.Unlock()
+		}
+		a.mu.Lock()
+		a.scanning = false
+		a.mu.Unlock()
+	}()
+}
+
+// scanInterval returns how often a network is scanned. A network whose
+// discovery rule is disabled is not scanned by the agent.
+func (a *Agent) scanInterval(rule *model.DiscoveryRule) (time.Duration, bool) {
+	if rule == nil {
+		return a.cfg.DefaultScanInterval, true
+	}
+	if !rule.Enabled {
+		return 0, false
+	}
+	if rule.IntervalHours > 0 {
+		return time.Duration(rule.IntervalHours) * time.Hour, true
+	}
+	return a.cfg.DefaultScanInterval, true
+}
+
+// ScanNetwork scans one assigned network locally and pushes the results
+func (a *Agent) ScanNetwork(ctx context.Context, n model.AgentNetwork) error {
+	network := n.Network
+	scanType := model.ScanTypeFull
+	if n.Rule != nil && n.Rule.ScanType != "" {
+		scanType = n.Rule.ScanType
+	}
+
+	if err := a.stageNetwork(ctx, &network, n.Rule); err != nil {
+		return err
+	}
+
+	log.Info("Agent scan started", "network", network.Name, "subnet", network.Subnet, "scan_type", scanType)
+	started := time.Now().UTC()
+	result := &model.AgentScanResult{NetworkID: network.ID, ScanType: scanType, StartedAt: &started}
+
+	scan, err := a.scanner.Scan(ctx, &network, scanType)
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		scan, err = a.waitForScan(ctx, scan.ID)
+		if err != nil {
+			return err
+		}
+		result.TotalHosts = scan.TotalHosts
+		if scan.Status == model.ScanStatusFailed {
+			result.Error = scan.ErrorMessage
+		}
+	}
+
+	devices, err := a.store.ListDiscoveredDevices(ctx, network.ID)
+	if err != nil {
+		return err
+	}
+	result.Devices = devices
+
+	var recorded model.DiscoveryScan
+	if err := a.post(ctx, "/api/agent/results", result, &recorded); err != nil {
+		return fmt.Errorf("failed to submit results: %w", err)
+	}
+
+	// Results are pushed per scan, the server merges them with earlier sightings
+	if err := a.store.DeleteDiscoveredDevicesByNetwork(ctx, network.ID); err != nil {
+		log.Warn("Failed to clear staged devices", "network", network.Name, "error", err)
+	}
+	log.Info("Agent scan submitted", "network", network.Name, "found", len(devices), "scan_id", recorded.ID)
+	return nil
+}
+
+// stageNetwork mirrors the network and its discovery rule into local storage
+// so the scanner can read the rule's settings (e.g. DNS resolution)
+func (a *Agent) stageNetwork(ctx context.Context, network *model.Network, rule *model.DiscoveryRule) error {
+	local := *network
+	local.DatacenterID = ""
+	if _, err := a.store.GetNetwork(ctx, local.ID); err != nil {
+		if !errors.Is(err, storage.ErrNetworkNotFound) {
+			return err
+		}
+		if err := a.store.CreateNetwork(ctx, &local); err != nil {
+			return fmt.Errorf("failed to stage network: %w", err)
+		}
+	} else if err := a.store.UpdateNetwork(ctx, &local); err != nil {
+		return fmt.Errorf("failed to stage network: %w", err)
+	}
+
+	if rule != nil {
+		localRule := *rule
+		if existing, err := a.store.GetDiscoveryRuleByNetwork(ctx, local.ID); err == nil {
+			localRule.ID = existing.ID
+		}
+		if err := a.store.SaveDiscoveryRule(ctx, &localRule); err != nil {
+			return fmt.Errorf("failed to stage discovery rule: %w", err)
+		}
+	}
+	return nil
+}
+
+func (a *Agent) waitForScan(ctx context.Context, scanID string) (*model.DiscoveryScan, error) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		scan, err := a.scanner.GetScanStatus(ctx, scanID)
+		if err != nil {
+			return nil, err
+		}
+		if scan.Status == model.ScanStatusCompleted || scan.Status == model.ScanStatusFailed {
+			return scan, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			a.scanner.CancelScan(context.Background(), scanID)
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Agent) post(ctx context.Context, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.ServerURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.cfg.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: unexpected status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestNew_RequiresServerAndToken(t *testing.T) {
+	if _, err := New(Config{Token: "t"}); err == nil {
+		t.Error("expected error without server URL")
+	}
+	if _, err := New(Config{ServerURL: "http://localhost"}); err == nil {
+		t.Error("expected error without token")
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	var gotAuth string
+	var gotHB model.AgentHeartbeat
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/agent/heartbeat" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotHB)
+		json.NewEncoder(w).Encode(model.AgentConfig{
+			AgentID:  "agent-1",
+			Name:     "site-a",
+			Networks: []model.AgentNetwork{{Network: model.Network{ID: "net-1", Subnet: "10.0.0.0/24"}}},
+		})
+	}))
+	defer srv.Close()
+
+	a, err := New(Config{ServerURL: srv.URL + "/", Token: "secret", Version: "1.2.3"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer a.store.Close()
+
+	cfg, err := a.Heartbeat(context.Background())
+	if err != nil {
+		t.Fatalf("Heartbeat failed: %v", err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("expected bearer token, got %q", gotAuth)
+	}
+	if gotHB.Version != "1.2.3" {
+		t.Errorf("expected version 1.2.3, got %q", gotHB.Version)
+	}
+	if cfg.AgentID != "agent-1" || len(cfg.Networks) != 1 || cfg.Networks[0].Network.ID != "net-1" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestHeartbeat_Unauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	a, err := New(Config{ServerURL: srv.URL, Token: "bad"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer a.store.Close()
+
+	if _, err := a.Heartbeat(context.Background()); err == nil {
+		t.Error("expected error for unauthorized heartbeat")
+	}
+}
+
+func TestScanInterval(t *testing.T) {
+	a := &Agent{cfg: Config{DefaultScanInterval: 6 * time.Hour}}
+
+	tests := []struct {
+		name     string
+		rule     *model.DiscoveryRule
+		interval time.Duration
+		enabled  bool
+	}{
+		{"no rule", nil, 6 * time.Hour, true},
+		{"disabled rule", &model.DiscoveryRule{Enabled: false, IntervalHours: 1}, 0, false},
+		{"rule interval", &model.DiscoveryRule{Enabled: true, IntervalHours: 2}, 2 * time.Hour, true},
+		{"rule without interval", &model.DiscoveryRule{Enabled: true}, 6 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interval, enabled := a.scanInterval(tt.rule)
+			if interval != tt.interval || enabled != tt.enabled {
+				t.Errorf("expected (%v, %v), got (%v, %v)", tt.interval, tt.enabled, interval, enabled)
+			}
+		})
+	}
+}
+
+func TestStageNetwork(t *testing.T) {
+	a, err := New(Config{ServerURL: "http://localhost", Token: "t"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer a.store.Close()
+	ctx := context.Background()
+
+	network := &model.Network{ID: "net-1", Name: "remote", Subnet: "10.0.0.0/24", DatacenterID: "dc-central"}
+	rule := &model.DiscoveryRule{ID: "rule-1", NetworkID: "net-1", Enabled: true, ScanType: model.ScanTypeQuick}
+
+	// Staging twice must update rather than fail
+	for i := 0; i < 2; i++ {
+		if err := a.stageNetwork(ctx, network, rule); err != nil {
+			t.Fatalf("stageNetwork #%d failed: %v", i+1, err)
+		}
+	}
+
+	local, err := a.store.GetNetwork(ctx, "net-1")
+	if err != nil {
+		t.Fatalf("GetNetwork failed: %v", err)
+	}
+	if local.DatacenterID != "" {
+		t.Errorf("expected datacenter to be cleared, got %q", local.DatacenterID)
+	}
+	if network.DatacenterID != "dc-central" {
+		t.Error("stageNetwork must not modify the assigned network")
+	}
+	if _, err := a.store.GetDiscoveryRuleByNetwork(ctx, "net-1"); err != nil {
+		t.Errorf("expected staged rule: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+type agentContextKey struct{}
+
+type agentRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	NetworkIDs  []string `json:"network_ids"`
+}
+
+func (h *Handler) listAgents(w http.ResponseWriter, r *http.Request) {
+	agents, err := h.svc.Agents.List(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, agents)
+}
+
+func (h *Handler) getAgent(w http.ResponseWriter, r *http.Request) {
+	agent, err := h.svc.Agents.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, agent)
+}
+
+// registerAgent creates an agent. The token is only returned here and by
+// rotateAgentToken.
+func (h *Handler) registerAgent(w http.ResponseWriter, r *http.Request) {
+	var req agentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	reg, err := h.svc.Agents.Register(r.Context(), &model.Agent{
+		Name:        req.Name,
+		Description: req.Description,
+		NetworkIDs:  req.NetworkIDs,
+	})
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	log.Info("Agent registered", "name", reg.Agent.Name, "id", reg.Agent.ID)
+	h.writeJSON(w, http.StatusCreated, reg)
+}
+
+func (h *Handler) updateAgent(w http.ResponseWriter, r *http.Request) {
+	var req agentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	agent := &model.Agent{
+		ID:          r.PathValue("id"),
+		Name:        req.Name,
+		Description: req.Description,
+		NetworkIDs:  req.NetworkIDs,
+	}
+	if err := h.svc.Agents.Update(r.Context(), agent); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	updated, err := h.svc.Agents.Get(r.Context(), agent.ID)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *Handler) rotateAgentToken(w http.ResponseWriter, r *http.Request) {
+	reg, err := h.svc.Agents.RotateToken(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	log.Info("Agent token rotated", "name", reg.Agent.Name, "id", reg.Agent.ID)
+	h.writeJSON(w, http.StatusOK, reg)
+}
+
+func (h *Handler) deleteAgent(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Agents.Delete(r.Context(), r.PathValue("id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// agentAuth authenticates a remote agent by its Bearer token. Agent tokens
+// are separate from API keys and only grant access to the agent endpoints.
+func (h *Handler) agentAuth(next http.HandlerFunc) http.HandlerFunc {
+	return LimitBody(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			h.writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized")
+			return
+		}
+
+		agent, err := h.svc.Agents.Authenticate(r.Context(), strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			log.Debug("Agent auth failed", "path", r.URL.Path, "error", err)
+			h.handleServiceError(w, err)
+			return
+		}
+
+		ctx := service.WithCaller(r.Context(), &service.Caller{
+			Type:      service.CallerTypeSystem,
+			Username:  "agent:" + agent.Name,
+			IPAddress: getClientIP(r, h.trustProxy),
+			Source:    "agent",
+		})
+		ctx = context.WithValue(ctx, agentContextKey{}, agent)
+		next(w, r.WithContext(ctx))
+	})
+}
+
+func agentFromContext(ctx context.Context) *model.Agent {
+	agent, _ := ctx.Value(agentContextKey{}).(*model.Agent)
+	return agent
+}
+
+func (h *Handler) agentHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var hb model.AgentHeartbeat
+	if err := json.NewDecoder(r.Body).Decode(&hb); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	cfg, err := h.svc.Agents.Heartbeat(r.Context(), agentFromContext(r.Context()), &hb)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, cfg)
+}
+
+func (h *Handler) agentSubmitResults(w http.ResponseWriter, r *http.Request) {
+	var result model.AgentScanResult
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	scan, err := h.svc.Agents.SubmitResults(r.Context(), agentFromContext(r.Context()), &result)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, scan)
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestAgentHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	network := &model.Network{Name: "remote-site", Subnet: "10.20.0.0/24"}
+	if err := store.CreateNetwork(context.Background(), network); err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+
+	var reg model.AgentRegistration
+
+	t.Run("Register", func(t *testing.T) {
+		body, _ := json.Marshal(model.Agent{Name: "site-a", NetworkIDs: []string{network.ID}})
+		req := authReq(httptest.NewRequest("POST", "/api/agents", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &reg); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if reg.Token == "" || reg.Agent == nil || reg.Agent.ID == "" {
+			t.Fatalf("expected agent and token, got %+v", reg)
+		}
+	})
+
+	t.Run("Register_UnknownNetwork", func(t *testing.T) {
+		body, _ := json.Marshal(model.Agent{Name: "site-b", NetworkIDs: []string{"missing"}})
+		req := authReq(httptest.NewRequest("POST", "/api/agents", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Heartbeat", func(t *testing.T) {
+		body, _ := json.Marshal(model.AgentHeartbeat{Hostname: "scanner01", Version: "1.0.0"})
+		req := authReqWithToken(httptest.NewRequest("POST", "/api/agent/heartbeat", bytes.NewReader(body)), reg.Token)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var cfg model.AgentConfig
+		if err := json.Unmarshal(w.Body.Bytes(), &cfg); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if len(cfg.Networks) != 1 || cfg.Networks[0].Network.ID != network.ID {
+			t.Errorf("expected assigned network, got %+v", cfg.Networks)
+		}
+	})
+
+	t.Run("Heartbeat_BadToken", func(t *testing.T) {
+		req := authReqWithToken(httptest.NewRequest("POST", "/api/agent/heartbeat", bytes.NewReader([]byte("{}"))), "not-a-token")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("expected %d, got %d: %s", http.StatusUnauthorized, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Heartbeat_APIKeyRejected", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/agent/heartbeat", bytes.NewReader([]byte("{}"))))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("expected %d, got %d: %s", http.StatusUnauthorized, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("SubmitResults", func(t *testing.T) {
+		result := model.AgentScanResult{
+			NetworkID:  network.ID,
+			ScanType:   model.ScanTypeQuick,
+			TotalHosts: 254,
+			Devices:    []model.DiscoveredDevice{{IP: "10.20.0.5", Hostname: "printer", Status: "online"}},
+		}
+		body, _ := json.Marshal(result)
+		req := authReqWithToken(httptest.NewRequest("POST", "/api/agent/results", bytes.NewReader(body)), reg.Token)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		devices, err := store.ListDiscoveredDevices(context.Background(), network.ID)
+		if err != nil {
+			t.Fatalf("ListDiscoveredDevices failed: %v", err)
+		}
+		if len(devices) != 1 || devices[0].IP != "10.20.0.5" {
+			t.Errorf("expected submitted device to be stored, got %+v", devices)
+		}
+	})
+
+	t.Run("SubmitResults_UnassignedNetwork", func(t *testing.T) {
+		body, _ := json.Marshal(model.AgentScanResult{NetworkID: "other-network"})
+		req := authReqWithToken(httptest.NewRequest("POST", "/api/agent/results", bytes.NewReader(body)), reg.Token)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected %d, got %d: %s", http.StatusForbidden, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("List_ShowsOnline", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/agents", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var agents []model.Agent
+		if err := json.Unmarshal(w.Body.Bytes(), &agents); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if len(agents) != 1 || !agents[0].Online || agents[0].Hostname != "scanner01" {
+			t.Errorf("expected one online agent, got %+v", agents)
+		}
+	})
+
+	t.Run("RotateToken", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/agents/"+reg.Agent.ID+"/rotate-token", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		// The old token no longer authenticates
+		req = authReqWithToken(httptest.NewRequest("POST", "/api/agent/heartbeat", bytes.NewReader([]byte("{}"))), reg.Token)
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("expected %d for old token, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("DELETE", "/api/agents/"+reg.Agent.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+	})
+}
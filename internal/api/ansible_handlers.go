@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/export"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// getAnsibleInventory returns the device inventory in Ansible dynamic
+// inventory format
+func (h *Handler) getAnsibleInventory(w http.ResponseWriter, r *http.Request) {
+	filter := model.DeviceFilter{
+		Tags:         parseArrayParam(r, "tags"),
+		DatacenterID: r.URL.Query().Get("datacenter_id"),
+		Status:       model.DeviceStatus(r.URL.Query().Get("status")),
+	}
+	devices, err := h.svc.Devices.ListAll(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	datacenters, err := h.svc.Datacenters.List(r.Context(), &model.DatacenterFilter{Pagination: model.Pagination{Limit: model.MaxPageSize}})
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	opts := export.AnsibleOptions{AddressLabel: r.URL.Query().Get("address_label")}
+	h.writeJSON(w, http.StatusOK, export.AnsibleInventory(devices, datacenters, opts))
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestAnsibleInventoryHandler(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	dc := &model.Datacenter{Name: "fra1"}
+	if err := store.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("failed to create datacenter: %v", err)
+	}
+	devices := []*model.Device{
+		{Name: "web01", Username: "deploy", DatacenterID: dc.ID, Tags: []string{"web"},
+			Addresses: []model.Address{{IP: "10.0.0.10", Type: "ipv4"}, {IP: "192.168.0.10", Type: "ipv4", Label: "mgmt"}}},
+		{Name: "db01", OS: "debian", Tags: []string{"db"}, Addresses: []model.Address{{IP: "10.0.0.20", Type: "ipv4"}}},
+	}
+	for _, d := range devices {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("failed to create device: %v", err)
+		}
+	}
+
+	get := func(path string) map[string]json.RawMessage {
+		t.Helper()
+		req := authReq(httptest.NewRequest("GET", path, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var inv map[string]json.RawMessage
+		if err := json.Unmarshal(w.Body.Bytes(), &inv); err != nil {
+			t.Fatalf("failed to unmarshal inventory: %v", err)
+		}
+		return inv
+	}
+
+	t.Run("Groups", func(t *testing.T) {
+		inv := get("/api/ansible/inventory")
+		for _, group := range []string{"all", "_meta", "tag_web", "tag_db", "datacenter_fra1", "os_debian"} {
+			if _, ok := inv[group]; !ok {
+				t.Errorf("expected group %s in inventory", group)
+			}
+		}
+	})
+
+	t.Run("AddressLabel", func(t *testing.T) {
+		inv := get("/api/ansible/inventory?address_label=mgmt")
+		var meta struct {
+			HostVars map[string]map[string]any `json:"hostvars"`
+		}
+		json.Unmarshal(inv["_meta"], &meta)
+		if meta.HostVars["web01"]["ansible_host"] != "192.168.0.10" {
+			t.Errorf("expected mgmt address, got %v", meta.HostVars["web01"]["ansible_host"])
+		}
+		if meta.HostVars["web01"]["ansible_user"] != "deploy" {
+			t.Errorf("expected ansible_user, got %v", meta.HostVars["web01"]["ansible_user"])
+		}
+	})
+
+	t.Run("FilterByTag", func(t *testing.T) {
+		inv := get("/api/ansible/inventory?tags=db")
+		if _, ok := inv["tag_web"]; ok {
+			t.Error("expected web devices to be filtered out")
+		}
+	})
+}
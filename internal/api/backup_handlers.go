@@ -0,0 +1,13 @@
+package api
+
+import "net/http"
+
+// createBackup serves POST /api/admin/backup, taking a database backup now
+func (h *Handler) createBackup(w http.ResponseWriter, r *http.Request) {
+	b, err := h.svc.Backups.Create(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, b)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/backup"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+func TestBackupHandlers_Create(t *testing.T) {
+	env := setupExtendedTestHandler(t, false, false, false, false)
+	defer env.close()
+
+	w := performRequest(env.mux, authReq(httptest.NewRequest("POST", "/api/admin/backup", nil)))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected an error without a backup manager, got %d", w.Code)
+	}
+
+	dataDir := t.TempDir()
+	fileStore, err := storage.NewSQLiteStorage(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	fileStore.Close()
+	backupDir := filepath.Join(dataDir, "backups")
+	env.handler.svc.Backups.SetManager(backup.NewManager(filepath.Join(dataDir, "rackd.db"), backupDir, backup.NewDir(backupDir), 0))
+
+	w = performRequest(env.mux, authReq(httptest.NewRequest("POST", "/api/admin/backup", nil)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var b model.Backup
+	if err := json.NewDecoder(w.Body).Decode(&b); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, b.Name)); err != nil {
+		t.Errorf("expected the backup file, got %v", err)
+	}
+
+	_, token := env.createAPIUser(t, "backup-operator")
+	w = performRequest(env.mux, authReqWithToken(httptest.NewRequest("POST", "/api/admin/backup", nil), token))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected non-admin backups to be forbidden, got %d", w.Code)
+	}
+}
@@ -2,10 +2,14 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
 )
 
 func TestBulkHandlers(t *testing.T) {
@@ -72,6 +76,69 @@ func TestBulkHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("BulkDeviceOperations", func(t *testing.T) {
+		ctx := context.Background()
+		dc := &model.Datacenter{Name: "bulk-dc"}
+		if err := env.store.CreateDatacenter(ctx, dc); err != nil {
+			t.Fatalf("failed to create datacenter: %v", err)
+		}
+		var ids []string
+		for _, name := range []string{"bulk-op-1", "bulk-op-2"} {
+			d := &model.Device{Name: name, Tags: []string{"old"}}
+			if err := env.store.CreateDevice(ctx, d); err != nil {
+				t.Fatalf("failed to create device: %v", err)
+			}
+			ids = append(ids, d.ID)
+		}
+		idsJSON, _ := json.Marshal(ids)
+
+		body := `{"device_ids":` + string(idsJSON) + `,"operations":[
+			{"op":"update","fields":{"status":"maintenance","location":"Rack B2"}},
+			{"op":"add_tags","tags":["retagged"]},
+			{"op":"remove_tags","tags":["old"]},
+			{"op":"move_datacenter","datacenter_id":"` + dc.ID + `"}]}`
+		w := performRequest(env.mux, authReq(httptest.NewRequest("POST", "/api/devices/bulk", bytes.NewBufferString(body))))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		for _, id := range ids {
+			d, err := env.store.GetDevice(ctx, id)
+			if err != nil {
+				t.Fatalf("failed to get device: %v", err)
+			}
+			if d.Status != model.DeviceStatusMaintenance || d.Location != "Rack B2" || d.DatacenterID != dc.ID {
+				t.Errorf("device %s not updated: status=%q location=%q datacenter=%q", id, d.Status, d.Location, d.DatacenterID)
+			}
+			if len(d.Tags) != 1 || d.Tags[0] != "retagged" {
+				t.Errorf("device %s: expected tags [retagged], got %v", id, d.Tags)
+			}
+		}
+
+		// One unknown ID rolls back the whole request
+		body = `{"device_ids":["` + ids[0] + `","missing-device"],"operations":[{"op":"add_tags","tags":["partial"]}]}`
+		w = performRequest(env.mux, authReq(httptest.NewRequest("POST", "/api/devices/bulk", bytes.NewBufferString(body))))
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "missing-device") {
+			t.Fatalf("expected 400 naming the missing device, got %d: %s", w.Code, w.Body.String())
+		}
+		if d, _ := env.store.GetDevice(ctx, ids[0]); len(d.Tags) != 1 {
+			t.Errorf("expected failed request to be rolled back, got tags %v", d.Tags)
+		}
+
+		w = performRequest(env.mux, authReq(httptest.NewRequest("POST", "/api/devices/bulk", bytes.NewBufferString(`{"device_ids":["x"],"operations":[{"op":"delete"},{"op":"add_tags","tags":["a"]}]}`))))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for delete combined with other operations, got %d: %s", w.Code, w.Body.String())
+		}
+
+		body = `{"device_ids":` + string(idsJSON) + `,"operations":[{"op":"delete"}]}`
+		w = performRequest(env.mux, authReq(httptest.NewRequest("POST", "/api/devices/bulk", bytes.NewBufferString(body))))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if _, err := env.store.GetDevice(ctx, ids[1]); err == nil {
+			t.Error("expected device to be deleted")
+		}
+	})
+
 	t.Run("BulkOperations_ForbiddenWithoutPermission", func(t *testing.T) {
 		_, limitedToken := env.createAPIUser(t, "limited-bulk-user")
 
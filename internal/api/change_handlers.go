@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// approvalExempt lists the paths a caller in approval mode can still change
+// directly: reviewing changes, their own session, keys and password
+func approvalExempt(r *http.Request) bool {
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/api/changes"),
+		strings.HasPrefix(path, "/api/auth/"),
+		strings.HasPrefix(path, "/api/keys"):
+		return true
+	case strings.HasPrefix(path, "/api/users/") && strings.HasSuffix(path, "/password"):
+		return r.PathValue("id") == service.CallerFrom(r.Context()).UserID
+	}
+	return false
+}
+
+// approvalGate stores a change by a caller that needs approval as a change
+// request instead of running it, and answers 202 Accepted with the change
+// request. Reads and dry runs pass through.
+func (h *Handler) approvalGate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next(w, r)
+			return
+		}
+		if _, dryRun := writeContext(r); dryRun || !h.svc.Changes.RequiresApproval(r.Context()) || approvalExempt(r) {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.badRequest(w, "Request body too large")
+			return
+		}
+		body = bytes.TrimSpace(body)
+		if len(body) > 0 && !json.Valid(body) {
+			h.badRequest(w, "Only JSON request bodies can be submitted for approval")
+			return
+		}
+
+		change := &model.ChangeRequest{
+			Method: r.Method,
+			Path:   r.URL.RequestURI(),
+			Body:   body,
+		}
+		// Record what is being changed, where the path names one resource
+		if r.Method != http.MethodPost {
+			if status, before := h.replay(r, http.MethodGet, r.URL.Path, nil); status == http.StatusOK && json.Valid(before) {
+				change.Before = before
+			}
+		}
+
+		if err := h.svc.Changes.Submit(r.Context(), change); err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		log.Info("Change held for approval", "id", change.ID, "method", change.Method, "path", change.Path, "user", change.RequestedByName)
+		w.Header().Set("Location", "/api/changes/"+change.ID)
+		h.writeJSON(w, http.StatusAccepted, change)
+	}
+}
+
+// replay runs a request through the change mux with the caller from r and
+// returns the response status and body
+func (h *Handler) replay(r *http.Request, method, target string, body []byte) (int, []byte) {
+	req, err := http.NewRequestWithContext(r.Context(), method, target, bytes.NewReader(body))
+	if err != nil {
+		return http.StatusBadRequest, nil
+	}
+	req.RemoteAddr = r.RemoteAddr
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := &changeRecorder{header: http.Header{}}
+	h.changeMux.ServeHTTP(rec, req)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.status, rec.body.Bytes()
+}
+
+// changeRecorder keeps the response to a replayed request
+type changeRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (c *changeRecorder) Header() http.Header { return c.header }
+
+func (c *changeRecorder) WriteHeader(status int) {
+	if c.status == 0 {
+		c.status = status
+	}
+}
+
+func (c *changeRecorder) Write(b []byte) (int, error) {
+	c.WriteHeader(http.StatusOK)
+	return c.body.Write(b)
+}
+
+func (h *Handler) listChanges(w http.ResponseWriter, r *http.Request) {
+	filter := &model.ChangeRequestFilter{
+		Pagination: parsePagination(r),
+		Status:     model.ChangeStatus(r.URL.Query().Get("status")),
+	}
+	switch filter.Status {
+	case "", model.ChangePending, model.ChangeApproved, model.ChangeApplied, model.ChangeFailed, model.ChangeRejected:
+	default:
+		h.badRequest(w, "status must be one of pending, approved, applied, failed, rejected")
+		return
+	}
+
+	changes, err := h.svc.Changes.List(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, changes)
+}
+
+func (h *Handler) getChange(w http.ResponseWriter, r *http.Request) {
+	change, err := h.svc.Changes.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, change)
+}
+
+// approveChange approves a pending change and applies it as the user who
+// requested it. The change is returned with the outcome: applied, or failed
+// with the status and body of the error.
+func (h *Handler) approveChange(w http.ResponseWriter, r *http.Request) {
+	var review model.ChangeReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil && !errors.Is(err, io.EOF) {
+		h.invalidJSON(w)
+		return
+	}
+
+	change, err := h.svc.Changes.Approve(r.Context(), r.PathValue("id"), review.Comment)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	status, result := h.applyChange(r, change)
+	if err := h.svc.Changes.RecordResult(r.Context(), change, status, result); err != nil {
+		h.internalError(w, err)
+		return
+	}
+	log.Info("Change approved", "id", change.ID, "status", change.Status, "result_status", status)
+	h.writeJSON(w, http.StatusOK, change)
+}
+
+// applyChange replays an approved change as its requester, who must still be
+// an active user. Permissions are checked as for the original request.
+func (h *Handler) applyChange(r *http.Request, change *model.ChangeRequest) (int, []byte) {
+	user, err := h.store.GetUser(r.Context(), change.RequestedBy)
+	if err != nil || !user.IsActive {
+		result, _ := json.Marshal(map[string]string{"error": "Requester is no longer an active user", "code": "REQUESTER_INACTIVE"})
+		return http.StatusForbidden, result
+	}
+
+	ctx := service.WithCaller(r.Context(), &service.Caller{
+		Type:      service.CallerTypeUser,
+		UserID:    user.ID,
+		Username:  user.Username,
+		IPAddress: getClientIP(r, h.trustProxy),
+		Source:    "change-request",
+	})
+	status, result := h.replay(r.WithContext(ctx), change.Method, change.Path, change.Body)
+	if len(result) > 0 && !json.Valid(result) {
+		result, _ = json.Marshal(strings.TrimSpace(string(result)))
+	}
+	return status, result
+}
+
+func (h *Handler) rejectChange(w http.ResponseWriter, r *http.Request) {
+	var review model.ChangeReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil && !errors.Is(err, io.EOF) {
+		h.invalidJSON(w)
+		return
+	}
+
+	change, err := h.svc.Changes.Reject(r.Context(), r.PathValue("id"), review.Comment)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	log.Info("Change rejected", "id", change.ID)
+	h.writeJSON(w, http.StatusOK, change)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+func TestChangeRequestHandlers(t *testing.T) {
+	_, store := setupTestHandler(t)
+	defer store.Close()
+	services := service.NewServices(store, nil, nil)
+	services.SetApprovalMode(true)
+	h := NewHandler(store, nil, WithServices(services))
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	userID, token := createAPIUserForStore(t, store, "change-operator")
+	operator, err := store.GetRoleByName(ctx, "operator")
+	if err != nil {
+		t.Fatalf("failed to get operator role: %v", err)
+	}
+	if err := store.AssignRoleToUser(ctx, userID, operator.ID); err != nil {
+		t.Fatalf("failed to assign role: %v", err)
+	}
+
+	device := &model.Device{Name: "web-01"}
+	if err := store.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("failed to create device: %v", err)
+	}
+
+	decodeChange := func(t *testing.T, w *httptest.ResponseRecorder) model.ChangeRequest {
+		t.Helper()
+		var change model.ChangeRequest
+		if err := json.NewDecoder(w.Body).Decode(&change); err != nil {
+			t.Fatalf("failed to decode change: %v", err)
+		}
+		return change
+	}
+
+	// An operator's update is held, with the device as it was
+	req := authReqWithToken(httptest.NewRequest("PUT", "/api/devices/"+device.ID, bytes.NewBufferString(`{"name":"web-02"}`)), token)
+	w := performRequest(mux, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	change := decodeChange(t, w)
+	if w.Header().Get("Location") != "/api/changes/"+change.ID || change.Status != model.ChangePending || len(change.Before) == 0 {
+		t.Fatalf("unexpected held change: %+v", change)
+	}
+	if got, _ := store.GetDevice(ctx, device.ID); got.Name != "web-01" {
+		t.Fatalf("expected the device unchanged until approval, got %q", got.Name)
+	}
+
+	// Dry runs and reads are answered directly
+	w = performRequest(mux, authReqWithToken(httptest.NewRequest("POST", "/api/devices?dry_run=true", bytes.NewBufferString(`{"name":"web-03"}`)), token))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected dry run to answer %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	w = performRequest(mux, authReqWithToken(httptest.NewRequest("GET", "/api/changes?status=pending", nil), token))
+	var changes []model.ChangeRequest
+	if err := json.NewDecoder(w.Body).Decode(&changes); err != nil || len(changes) != 1 {
+		t.Fatalf("expected the operator to see 1 pending change, got %d (%v)", len(changes), err)
+	}
+
+	// Only admins review
+	w = performRequest(mux, authReqWithToken(httptest.NewRequest("POST", "/api/changes/"+change.ID+"/approve", nil), token))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected the operator to be forbidden from approving, got %d", w.Code)
+	}
+
+	w = performRequest(mux, authReq(httptest.NewRequest("POST", "/api/changes/"+change.ID+"/approve", bytes.NewBufferString(`{"comment":"ok"}`))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	change = decodeChange(t, w)
+	if change.Status != model.ChangeApplied || change.ResultStatus != http.StatusOK || change.ReviewComment != "ok" {
+		t.Fatalf("unexpected approved change: %+v", change)
+	}
+	if got, _ := store.GetDevice(ctx, device.ID); got.Name != "web-02" {
+		t.Errorf("expected the approved change applied, got %q", got.Name)
+	}
+
+	w = performRequest(mux, authReq(httptest.NewRequest("POST", "/api/changes/"+change.ID+"/reject", nil)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected %d reviewing a reviewed change, got %d", http.StatusConflict, w.Code)
+	}
+
+	// A rejected change is never applied
+	w = performRequest(mux, authReqWithToken(httptest.NewRequest("DELETE", "/api/devices/"+device.ID, nil), token))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	change = decodeChange(t, w)
+	w = performRequest(mux, authReq(httptest.NewRequest("POST", "/api/changes/"+change.ID+"/reject", nil)))
+	if w.Code != http.StatusOK || decodeChange(t, w).Status != model.ChangeRejected {
+		t.Fatalf("expected the change rejected, got %d", w.Code)
+	}
+	if _, err := store.GetDevice(ctx, device.ID); err != nil {
+		t.Errorf("expected the device kept after rejection, got %v", err)
+	}
+
+	// Admins are not held
+	w = performRequest(mux, authReq(httptest.NewRequest("PUT", "/api/devices/"+device.ID, bytes.NewBufferString(`{"name":"web-04"}`))))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected an admin's update applied directly, got %d: %s", w.Code, w.Body.String())
+	}
+}
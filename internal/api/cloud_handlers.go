@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/cloud"
+)
+
+// listCloudProviders returns the cloud providers with credentials configured
+func (h *Handler) listCloudProviders(w http.ResponseWriter, r *http.Request) {
+	providers, err := h.svc.Cloud.Providers(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, providers)
+}
+
+// syncCloud syncs servers from one provider (?provider=) or all providers
+func (h *Handler) syncCloud(w http.ResponseWriter, r *http.Request) {
+	results, err := h.svc.Cloud.Sync(r.Context(), r.URL.Query().Get("provider"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, results)
+}
+
+type cloudImportRequest struct {
+	Provider string         `json:"provider"`
+	Servers  []cloud.Server `json:"servers"`
+}
+
+// importCloud syncs servers listed by the client, such as the nodes of a
+// Kubernetes cluster read by "rackd import k8s"
+func (h *Handler) importCloud(w http.ResponseWriter, r *http.Request) {
+	var req cloudImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	result, err := h.svc.Cloud.Import(r.Context(), req.Provider, req.Servers)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
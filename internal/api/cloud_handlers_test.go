@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestCloudHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	t.Run("ListProviders_Empty", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/cloud/providers", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var providers []model.CloudProvider
+		if err := json.Unmarshal(w.Body.Bytes(), &providers); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if len(providers) != 0 {
+			t.Errorf("expected no providers, got %d", len(providers))
+		}
+	})
+
+	t.Run("Sync_UnknownProvider", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/cloud/sync?provider=hetzner", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Sync_NoProviders", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/cloud/sync", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if w.Body.String() != "[]\n" {
+			t.Errorf("expected empty result list, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		body := `{"provider":"k8s-prod","servers":[{"id":"u-1","name":"node-1","private_ips":["10.0.0.5"]}]}`
+		req := authReq(httptest.NewRequest("POST", "/api/cloud/import", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var result model.CloudSyncResult
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if result.Provider != "k8s-prod" || len(result.Created) != 1 {
+			t.Errorf("unexpected import result: %+v", result)
+		}
+	})
+
+	t.Run("Import_InvalidProvider", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/cloud/import", strings.NewReader(`{"provider":"","servers":[]}`)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat(`{"name":"web-01"},`, 100)
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			w.Header().Set("Content-Type", "application/json")
+		}
+		io.WriteString(w, body)
+	}))
+
+	serve := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/api/devices", "br, gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected a gzipped response, got headers %v", w.Header())
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("failed to read gzip body: %v", err)
+	}
+	if got, _ := io.ReadAll(gz); string(got) != body {
+		t.Errorf("expected the body to round trip, got %d bytes", len(got))
+	}
+
+	tests := []struct {
+		name, path, acceptEncoding string
+	}{
+		{"not accepted", "/api/devices", ""},
+		{"refused", "/api/devices", "gzip;q=0, identity"},
+		{"event stream", "/events", "gzip"},
+		{"no content", "/empty", "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(tt.path, tt.acceptEncoding)
+			if enc := w.Header().Get("Content-Encoding"); enc != "" {
+				t.Errorf("expected no encoding, got %q", enc)
+			}
+			if w.Code == http.StatusOK && w.Body.String() != body {
+				t.Errorf("expected the body unchanged")
+			}
+		})
+	}
+}
@@ -59,6 +59,11 @@ func (b *UIConfigBuilder) SetEdition(edition string) {
 	b.config.Edition = edition
 }
 
+// AddFeature advertises an optional feature to the frontend
+func (b *UIConfigBuilder) AddFeature(feature string) {
+	b.config.Features = append(b.config.Features, feature)
+}
+
 func (b *UIConfigBuilder) SetUser(user *UserInfo) {
 	b.config.UserInfo = user
 }
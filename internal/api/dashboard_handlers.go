@@ -30,6 +30,25 @@ func (h *Handler) getDashboardStats(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, stats)
 }
 
+// getDatacenterStats returns aggregated statistics for a datacenter's
+// dashboard page
+func (h *Handler) getDatacenterStats(w http.ResponseWriter, r *http.Request) {
+	recentLimit := parseIntParam(r, "recent_limit", 10)
+	if recentLimit < 1 {
+		recentLimit = 1
+	} else if recentLimit > 100 {
+		recentLimit = 100
+	}
+
+	stats, err := h.svc.Dashboard.GetDatacenterStats(r.Context(), r.PathValue("id"), recentLimit)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, stats)
+}
+
 // getUtilizationTrend returns utilization trend data for charts
 func (h *Handler) getUtilizationTrend(w http.ResponseWriter, r *http.Request) {
 	resourceType := model.SnapshotType(r.URL.Query().Get("type"))
@@ -58,3 +77,22 @@ func (h *Handler) getUtilizationTrend(w http.ResponseWriter, r *http.Request) {
 
 	h.writeJSON(w, http.StatusOK, trend)
 }
+
+// getMetricsHistory returns the daily history of inventory metrics for trend
+// charts, with exhaustion forecasts for growing utilization
+func (h *Handler) getMetricsHistory(w http.ResponseWriter, r *http.Request) {
+	days := parseIntParam(r, "days", 90)
+	if days < 1 {
+		days = 1
+	} else if days > 3650 {
+		days = 3650
+	}
+
+	series, err := h.svc.Dashboard.MetricsHistory(r.Context(), parseArrayParam(r, "name"), r.URL.Query().Get("resource_id"), days)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, series)
+}
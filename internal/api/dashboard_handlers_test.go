@@ -2,10 +2,12 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -173,4 +175,84 @@ func TestDashboardHandlers(t *testing.T) {
 			t.Errorf("expected %d, got %d", http.StatusUnauthorized, w.Code)
 		}
 	})
+
+	t.Run("GetMetricsHistory", func(t *testing.T) {
+		today := time.Now().UTC()
+		day := func(ago int) string { return today.AddDate(0, 0, -ago).Format(time.DateOnly) }
+		if err := store.RecordInventoryMetrics(context.Background(), []model.InventoryMetric{
+			{Day: day(40), Name: model.MetricDevicesTotal, Value: 5},
+			{Day: day(2), Name: model.MetricDevicesTotal, Value: 8},
+			{Day: day(2), Name: model.MetricPoolsUtilization, Value: 60},
+			{Day: day(1), Name: model.MetricPoolsUtilization, Value: 61},
+			{Day: day(0), Name: model.MetricPoolsUtilization, Value: 62},
+		}); err != nil {
+			t.Fatalf("failed to record metrics: %v", err)
+		}
+
+		req := authReq(httptest.NewRequest("GET", "/api/metrics/history?days=30&name=devices.total&name=pools.utilization", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var series []model.MetricSeries
+		if err := json.Unmarshal(w.Body.Bytes(), &series); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		// The 40 day old count is outside the window
+		if len(series) != 2 || len(series[0].Points) != 1 || series[0].Points[0].Value != 8 {
+			t.Fatalf("unexpected series %+v", series)
+		}
+		if f := series[1].Forecast; f == nil || f.DaysLeft != 38 || f.ExhaustedOn != day(-38) {
+			t.Errorf("expected pools to run out in 38 days, got %+v", f)
+		}
+	})
+
+	t.Run("GetDatacenterStats", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/datacenters", bytes.NewBufferString(`{"name":"fra1"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		var dc model.Datacenter
+		json.Unmarshal(w.Body.Bytes(), &dc)
+
+		req = authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(`{"name":"web-01","os":"Debian 12","datacenter_id":"`+dc.ID+`","tags":["web"]}`)))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		req = authReq(httptest.NewRequest("GET", "/api/datacenters/"+dc.ID+"/stats", nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var stats model.DatacenterStats
+		if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if stats.DatacenterID != dc.ID || stats.TotalDevices != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+		if len(stats.DevicesByTag) != 1 || stats.DevicesByTag[0].Value != "web" {
+			t.Errorf("expected the web tag, got %v", stats.DevicesByTag)
+		}
+		if len(stats.RecentChanges) != 1 || stats.RecentChanges[0].Name != "web-01" {
+			t.Errorf("expected web-01 as the recent change, got %v", stats.RecentChanges)
+		}
+	})
+
+	t.Run("GetDatacenterStats_NotFound", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/datacenters/missing/stats", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+		}
+	})
 }
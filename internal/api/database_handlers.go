@@ -0,0 +1,24 @@
+package api
+
+import "net/http"
+
+// checkDatabaseIntegrity serves GET /api/admin/db/check. A database with problems
+// still gets 200; the report's ok field says whether it is healthy.
+func (h *Handler) checkDatabaseIntegrity(w http.ResponseWriter, r *http.Request) {
+	report, err := h.svc.Database.Check(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, report)
+}
+
+// optimizeDatabase serves POST /api/admin/db/optimize
+func (h *Handler) optimizeDatabase(w http.ResponseWriter, r *http.Request) {
+	result, err := h.svc.Database.Optimize(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
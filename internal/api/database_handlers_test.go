@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestDatabaseHandlers(t *testing.T) {
+	env := setupExtendedTestHandler(t, false, false, false, false)
+	defer env.close()
+
+	w := performRequest(env.mux, authReq(httptest.NewRequest("GET", "/api/admin/db/check", nil)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var report model.DBCheckReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !report.OK {
+		t.Errorf("expected the test database to pass, got %+v", report)
+	}
+
+	w = performRequest(env.mux, authReq(httptest.NewRequest("POST", "/api/admin/db/optimize", nil)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result model.DBOptimizeResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.SizeAfter == 0 {
+		t.Errorf("expected the database size, got %+v", result)
+	}
+
+	_, token := env.createAPIUser(t, "db-operator")
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/api/admin/db/check", nil),
+		httptest.NewRequest("POST", "/api/admin/db/optimize", nil),
+	} {
+		w = performRequest(env.mux, authReqWithToken(req, token))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: expected non-admins to be forbidden, got %d", req.Method, req.URL.Path, w.Code)
+		}
+	}
+}
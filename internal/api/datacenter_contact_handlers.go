@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func (h *Handler) listDatacenterContacts(w http.ResponseWriter, r *http.Request) {
+	contacts, err := h.svc.Datacenters.ListContacts(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, contacts)
+}
+
+func (h *Handler) createDatacenterContact(w http.ResponseWriter, r *http.Request) {
+	var contact model.DatacenterContact
+	if err := json.NewDecoder(r.Body).Decode(&contact); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+	contact.ID = ""
+	contact.DatacenterID = r.PathValue("id")
+
+	if err := h.svc.Datacenters.CreateContact(r.Context(), &contact); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, contact)
+}
+
+func (h *Handler) getDatacenterContact(w http.ResponseWriter, r *http.Request) {
+	contact, err := h.svc.Datacenters.GetContact(r.Context(), r.PathValue("id"), r.PathValue("contact_id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, contact)
+}
+
+func (h *Handler) updateDatacenterContact(w http.ResponseWriter, r *http.Request) {
+	contact, err := h.svc.Datacenters.GetContact(r.Context(), r.PathValue("id"), r.PathValue("contact_id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	var updates map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	if role, ok := updates["role"].(string); ok {
+		contact.Role = role
+	}
+	if name, ok := updates["name"].(string); ok {
+		contact.Name = name
+	}
+	if email, ok := updates["email"].(string); ok {
+		contact.Email = email
+	}
+	if phone, ok := updates["phone"].(string); ok {
+		contact.Phone = phone
+	}
+	if order, ok := updates["escalation_order"].(float64); ok {
+		contact.EscalationOrder = int(order)
+	}
+
+	if err := h.svc.Datacenters.UpdateContact(r.Context(), contact); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, contact)
+}
+
+func (h *Handler) deleteDatacenterContact(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Datacenters.DeleteContact(r.Context(), r.PathValue("id"), r.PathValue("contact_id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -9,7 +9,8 @@ import (
 
 func (h *Handler) listDatacenters(w http.ResponseWriter, r *http.Request) {
 	filter := &model.DatacenterFilter{
-		Name: r.URL.Query().Get("name"),
+		Pagination: parsePagination(r),
+		Name:       r.URL.Query().Get("name"),
 	}
 
 	dcs, err := h.svc.Datacenters.List(r.Context(), filter)
@@ -51,6 +52,7 @@ func (h *Handler) getDatacenter(w http.ResponseWriter, r *http.Request) {
 		h.handleServiceError(w, err)
 		return
 	}
+	setETag(w, dc.UpdatedAt)
 	h.writeJSON(w, http.StatusOK, dc)
 }
 
@@ -66,6 +68,11 @@ func (h *Handler) updateDatacenter(w http.ResponseWriter, r *http.Request) {
 		h.handleServiceError(w, err)
 		return
 	}
+	ctx, ok := h.checkIfMatch(w, r, dc.UpdatedAt)
+	if !ok {
+		return
+	}
+	r = r.WithContext(ctx)
 
 	var updates map[string]any
 	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
@@ -82,6 +89,12 @@ func (h *Handler) updateDatacenter(w http.ResponseWriter, r *http.Request) {
 	if description, ok := updates["description"].(string); ok {
 		dc.Description = description
 	}
+	if shippingAddress, ok := updates["shipping_address"].(string); ok {
+		dc.ShippingAddress = shippingAddress
+	}
+	if accessInstructions, ok := updates["access_instructions"].(string); ok {
+		dc.AccessInstructions = accessInstructions
+	}
 
 	if errs := ValidateDatacenter(dc); len(errs) > 0 {
 		h.writeValidationErrors(w, errs)
@@ -92,6 +105,7 @@ func (h *Handler) updateDatacenter(w http.ResponseWriter, r *http.Request) {
 		h.handleServiceError(w, err)
 		return
 	}
+	setETag(w, dc.UpdatedAt)
 	h.writeJSON(w, http.StatusOK, dc)
 }
 
@@ -102,7 +116,11 @@ func (h *Handler) deleteDatacenter(w http.ResponseWriter, r *http.Request) {
 		h.badRequest(w, "ID is required")
 		return
 	}
-	if err := h.svc.Datacenters.Delete(r.Context(), id); err != nil {
+	policy, ok := h.parseDeletePolicy(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Datacenters.Delete(r.Context(), id, policy); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
@@ -116,12 +134,31 @@ func (h *Handler) getDatacenterDevices(w http.ResponseWriter, r *http.Request) {
 		h.badRequest(w, "ID is required")
 		return
 	}
+	view, ok := h.parseDeviceView(w, r)
+	if !ok {
+		return
+	}
 	devices, err := h.svc.Datacenters.GetDevices(r.Context(), id)
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
-	h.writeJSON(w, http.StatusOK, devices)
+	h.writeDevices(w, r, view, devices)
+}
+
+func (h *Handler) getDatacenterPoolStats(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		h.badRequest(w, "ID is required")
+		return
+	}
+	summary, err := h.svc.Pools.DatacenterStats(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, summary)
 }
 
 func (h *Handler) searchDatacenters(w http.ResponseWriter, r *http.Request) {
@@ -8,13 +8,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"time"
 
 	"github.com/martinsuchenak/rackd/internal/auth"
 	"github.com/martinsuchenak/rackd/internal/log"
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/service"
 	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/martinsuchenak/rackd/internal/storagetest"
 )
 
 const testAPIKeyValue = "test-api-key-secret"
@@ -25,84 +25,29 @@ func init() {
 
 func setupTestHandler(t *testing.T) (*Handler, storage.ExtendedStorage) {
 	t.Helper()
-	store, err := storage.NewSQLiteStorage(":memory:")
-	if err != nil {
-		t.Fatalf("failed to create storage: %v", err)
-	}
+	store := newTestStore(t)
+	h := NewHandler(store, nil,
+		WithServices(service.NewServices(store, nil, nil)),
+	)
+	return h, store
+}
+
+// newTestStore returns in-memory storage holding an admin user with ID
+// "test-user-id" that authenticates with testAPIKeyValue
+func newTestStore(t *testing.T) storage.ExtendedStorage {
+	t.Helper()
+	store := storagetest.NewStorage(t)
 
-	// Create a test user with admin role for RBAC
 	passwordHash, _ := auth.HashPassword("test-password")
-	testUser := &model.User{
+	storagetest.CreateAdmin(t, store, &model.User{
 		ID:           "test-user-id",
 		Username:     "testuser",
 		Email:        "test@example.com",
 		PasswordHash: passwordHash,
 		IsActive:     true,
 		IsAdmin:      true,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-	if err := store.CreateUser(context.Background(), testUser); err != nil {
-		t.Fatalf("failed to create test user: %v", err)
-	}
-
-	// Get the admin role (created by bootstrap)
-	roles, err := store.ListRoles(context.Background(), nil)
-	var adminRoleID string
-	if err != nil || len(roles) == 0 {
-		// Create admin role if it doesn't exist
-		adminRole := &model.Role{
-			ID:          "admin-role-id",
-			Name:        "admin",
-			Description: "Administrator role",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		}
-		if err := store.CreateRole(context.Background(), adminRole); err != nil {
-			t.Fatalf("failed to create admin role: %v", err)
-		}
-		adminRoleID = adminRole.ID
-	} else {
-		adminRoleID = roles[0].ID
-	}
-
-	// Get all existing permissions (created by migrations) and assign to admin role
-	allPerms, err := store.ListPermissions(context.Background(), &model.PermissionFilter{
-		Pagination: model.Pagination{Limit: model.MaxPageSize},
-	})
-	if err != nil {
-		t.Fatalf("failed to list permissions: %v", err)
-	}
-	var permissionIDs []string
-	for _, p := range allPerms {
-		permissionIDs = append(permissionIDs, p.ID)
-	}
-
-	// Assign all permissions to admin role
-	if err := store.SetRolePermissions(context.Background(), adminRoleID, permissionIDs); err != nil {
-		t.Fatalf("failed to set role permissions: %v", err)
-	}
-
-	// Assign the admin role to the test user
-	if err := store.AssignRoleToUser(context.Background(), testUser.ID, adminRoleID); err != nil {
-		t.Fatalf("failed to assign admin role: %v", err)
-	}
-
-	// Create an API key associated with the test user
-	apiKey := &model.APIKey{
-		ID:     "test-key-id",
-		Name:   "test-key",
-		Key:    auth.HashToken(testAPIKeyValue),
-		UserID: testUser.ID,
-	}
-	if err := store.CreateAPIKey(context.Background(), apiKey); err != nil {
-		t.Fatalf("failed to create test API key: %v", err)
-	}
-
-	h := NewHandler(store, nil,
-		WithServices(service.NewServices(store, nil, nil)),
-	)
-	return h, store
+	}, testAPIKeyValue)
+	return store
 }
 
 // authReq adds the test API key Bearer token to a request
@@ -239,6 +184,69 @@ func TestDatacenterHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateDatacenter_IfMatch", func(t *testing.T) {
+		testIfMatch(t, mux, "/api/datacenters/"+dcID, `{"description":"edited"}`)
+	})
+
+	t.Run("DatacenterContacts", func(t *testing.T) {
+		do := func(method, path, body string) *httptest.ResponseRecorder {
+			req := authReq(httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			return w
+		}
+		base := "/api/datacenters/" + dcID
+
+		w := do("PUT", base, `{"shipping_address":"1 Dock Road","access_instructions":"Badge at reception"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		w = do("POST", base+"/contacts", `{"role":"Remote hands","name":"Ana","phone":"+1 555 0100","escalation_order":2}`)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		var contact model.DatacenterContact
+		json.Unmarshal(w.Body.Bytes(), &contact)
+		if w = do("POST", base+"/contacts", `{"name":"Bea","email":"bea@example.com","escalation_order":1}`); w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if w = do("POST", base+"/contacts", `{"name":"Cy","email":"not-an-email"}`); w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d for a bad email, got %d", http.StatusBadRequest, w.Code)
+		}
+		if w = do("POST", "/api/datacenters/nonexistent/contacts", `{"name":"Cy","phone":"1"}`); w.Code != http.StatusNotFound {
+			t.Errorf("expected %d for a missing datacenter, got %d", http.StatusNotFound, w.Code)
+		}
+
+		if w = do("PUT", base+"/contacts/"+contact.ID, `{"escalation_order":0}`); w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		w = do("GET", base, "")
+		var dc model.Datacenter
+		json.Unmarshal(w.Body.Bytes(), &dc)
+		if dc.ShippingAddress != "1 Dock Road" || dc.AccessInstructions != "Badge at reception" {
+			t.Errorf("site metadata not returned: %+v", dc)
+		}
+		if len(dc.Contacts) != 2 || dc.Contacts[0].Name != "Ana" || dc.Contacts[1].Name != "Bea" {
+			t.Errorf("expected contacts in escalation order, got %+v", dc.Contacts)
+		}
+
+		if w = do("DELETE", "/api/datacenters/nonexistent/contacts/"+contact.ID, ""); w.Code != http.StatusNotFound {
+			t.Errorf("expected %d deleting through another datacenter, got %d", http.StatusNotFound, w.Code)
+		}
+		if w = do("DELETE", base+"/contacts/"+contact.ID, ""); w.Code != http.StatusNoContent {
+			t.Errorf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+		w = do("GET", base+"/contacts", "")
+		var contacts []model.DatacenterContact
+		json.Unmarshal(w.Body.Bytes(), &contacts)
+		if w.Code != http.StatusOK || len(contacts) != 1 {
+			t.Errorf("expected 1 contact left, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
 	t.Run("GetDatacenterDevices", func(t *testing.T) {
 		req := authReq(httptest.NewRequest("GET", "/api/datacenters/"+dcID+"/devices", nil))
 		w := httptest.NewRecorder()
@@ -259,6 +267,38 @@ func TestDatacenterHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("DeleteDatacenter_DenyWithDependents", func(t *testing.T) {
+		device := &model.Device{Name: "dc-device", DatacenterID: dcID}
+		if err := store.CreateDevice(context.Background(), device); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+		req := authReq(httptest.NewRequest("DELETE", "/api/datacenters/"+dcID+"?cascade=deny", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected %d, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Code       string         `json:"code"`
+			Dependents map[string]int `json:"dependents"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if resp.Code != "HAS_DEPENDENTS" || resp.Dependents["devices"] != 1 {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("DeleteDatacenter_InvalidCascade", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("DELETE", "/api/datacenters/"+dcID+"?cascade=maybe", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
 	t.Run("DeleteDatacenter", func(t *testing.T) {
 		req := authReq(httptest.NewRequest("DELETE", "/api/datacenters/"+dcID, nil))
 		w := httptest.NewRecorder()
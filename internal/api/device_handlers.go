@@ -1,8 +1,13 @@
 package api
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -16,6 +21,20 @@ func (h *Handler) listDevices(w http.ResponseWriter, r *http.Request) {
 		NetworkID:    r.URL.Query().Get("network_id"),
 		PoolID:       r.URL.Query().Get("pool_id"),
 		Status:       model.DeviceStatus(r.URL.Query().Get("status")),
+		Kind:         model.DeviceKind(r.URL.Query().Get("kind")),
+		HostID:       r.URL.Query().Get("host_id"),
+	}
+	// lifecycle is an alias for status
+	if filter.Status == "" {
+		filter.Status = model.DeviceStatus(r.URL.Query().Get("lifecycle"))
+	}
+	if within := r.URL.Query().Get("warranty_expiring_within"); within != "" {
+		days, ok := parseDays(within)
+		if !ok {
+			h.badRequest(w, "warranty_expiring_within must be a number of days, such as 90d")
+			return
+		}
+		filter.WarrantyDays = days
 	}
 	// Handle stale filter - if stale=true, use default of 7 days
 	if r.URL.Query().Get("stale") == "true" {
@@ -23,12 +42,28 @@ func (h *Handler) listDevices(w http.ResponseWriter, r *http.Request) {
 	} else if staleDays := parseIntParam(r, "stale_days", 0); staleDays > 0 {
 		filter.StaleDays = staleDays
 	}
+	view, ok := h.parseDeviceView(w, r)
+	if !ok {
+		return
+	}
+	// Stale and warranty filters select by the clock, so their results change
+	// without any write
+	if filter.StaleDays == 0 && filter.WarrantyDays == 0 {
+		changedAt, err := h.svc.Devices.ChangedAt(r.Context(), view.embed)
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		if !checkModifiedSince(w, r, changedAt) {
+			return
+		}
+	}
 	devices, err := h.svc.Devices.List(r.Context(), filter)
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
-	h.writeJSON(w, http.StatusOK, devices)
+	h.writeDevices(w, r, view, devices)
 }
 
 func (h *Handler) createDevice(w http.ResponseWriter, r *http.Request) {
@@ -42,13 +77,31 @@ func (h *Handler) createDevice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.Devices.Create(r.Context(), &device); err != nil {
+	ctx, dryRun := writeContext(r)
+	if err := h.svc.Devices.Create(ctx, &device); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
+	h.writeMACWarnings(w, r, &device)
+	if dryRun {
+		h.writeJSON(w, http.StatusOK, device)
+		return
+	}
 	h.writeJSON(w, http.StatusCreated, device)
 }
 
+// writeMACWarnings adds a Warning header for every other device that shares
+// a MAC address with device. Callers without list permission get none.
+func (h *Handler) writeMACWarnings(w http.ResponseWriter, r *http.Request, device *model.Device) {
+	duplicates, err := h.svc.Devices.DuplicateMACs(r.Context(), device)
+	if err != nil {
+		return
+	}
+	for _, dup := range duplicates {
+		w.Header().Add("Warning", fmt.Sprintf("199 rackd %q", dup.Warning()))
+	}
+}
+
 func (h *Handler) getDevice(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if id == "" {
@@ -56,12 +109,30 @@ func (h *Handler) getDevice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	device, err := h.svc.Devices.Get(r.Context(), id)
+	view, ok := h.parseDeviceView(w, r)
+	if !ok {
+		return
+	}
+	device, err := h.svc.Devices.Lookup(r.Context(), id)
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
-	h.writeJSON(w, http.StatusOK, device)
+	setETag(w, device.UpdatedAt)
+	h.writeDevice(w, r, view, device)
+}
+
+func (h *Handler) getDeviceVMs(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.parseDeviceView(w, r)
+	if !ok {
+		return
+	}
+	devices, err := h.svc.Devices.ListVMs(r.Context(), r.PathValue("id"), parsePagination(r))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeDevices(w, r, view, devices)
 }
 
 func (h *Handler) updateDevice(w http.ResponseWriter, r *http.Request) {
@@ -76,6 +147,11 @@ func (h *Handler) updateDevice(w http.ResponseWriter, r *http.Request) {
 		h.handleServiceError(w, err)
 		return
 	}
+	ctx, ok := h.checkIfMatch(w, r, device.UpdatedAt)
+	if !ok {
+		return
+	}
+	r = r.WithContext(ctx)
 
 	var updates map[string]any
 	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
@@ -107,6 +183,9 @@ func (h *Handler) updateDevice(w http.ResponseWriter, r *http.Request) {
 	if location, ok := updates["location"].(string); ok {
 		device.Location = location
 	}
+	if kind, ok := updates["kind"].(string); ok {
+		device.Kind = model.DeviceKind(kind)
+	}
 	if status, ok := updates["status"].(string); ok {
 		device.Status = model.DeviceStatus(status)
 	}
@@ -116,12 +195,19 @@ func (h *Handler) updateDevice(w http.ResponseWriter, r *http.Request) {
 			device.DecommissionDate = &t
 		}
 	}
+	if errs := applyLifecycleDates(device, updates); len(errs) > 0 {
+		h.writeValidationErrors(w, errs)
+		return
+	}
 	if tags, ok := updates["tags"].([]any); ok {
 		device.Tags = toStringSlice(tags)
 	}
 	if domains, ok := updates["domains"].([]any); ok {
 		device.Domains = toStringSlice(domains)
 	}
+	if interfaces, ok := updates["interfaces"].([]any); ok {
+		device.Interfaces = toInterfaceSlice(interfaces)
+	}
 	if addresses, ok := updates["addresses"].([]any); ok {
 		device.Addresses = toAddressSlice(addresses)
 	}
@@ -134,13 +220,57 @@ func (h *Handler) updateDevice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.Devices.Update(r.Context(), device); err != nil {
+	ctx, dryRun := writeContext(r)
+	if err := h.svc.Devices.Update(ctx, device); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
+	h.writeMACWarnings(w, r, device)
+	if !dryRun {
+		setETag(w, device.UpdatedAt)
+	}
 	h.writeJSON(w, http.StatusOK, device)
 }
 
+// applyLifecycleDates sets the purchase, warranty and end-of-life dates
+// present in updates, clearing those given as an empty string
+func applyLifecycleDates(device *model.Device, updates map[string]any) ValidationErrors {
+	var errs ValidationErrors
+	for _, date := range []struct {
+		field string
+		dst   **time.Time
+	}{
+		{"purchase_date", &device.PurchaseDate},
+		{"warranty_expiry", &device.WarrantyExpiry},
+		{"end_of_life", &device.EndOfLife},
+	} {
+		value, ok := updates[date.field].(string)
+		if !ok {
+			continue
+		}
+		if value == "" {
+			*date.dst = nil
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: date.field, Message: date.field + " must be an RFC 3339 timestamp"})
+			continue
+		}
+		*date.dst = &t
+	}
+	return errs
+}
+
+// parseDays reads a number of days written as 90d or 90
+func parseDays(s string) (int, bool) {
+	days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+	if err != nil || days <= 0 {
+		return 0, false
+	}
+	return days, true
+}
+
 func (h *Handler) deleteDevice(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if id == "" {
@@ -148,13 +278,46 @@ func (h *Handler) deleteDevice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.Devices.Delete(r.Context(), id); err != nil {
+	policy, ok := h.parseDeletePolicy(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Devices.Delete(r.Context(), id, policy); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) mergeDevice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		h.badRequest(w, "ID is required")
+		return
+	}
+
+	source := r.PathValue("source")
+	if source == "" {
+		source = r.URL.Query().Get("source")
+	}
+	device, err := h.svc.Devices.Merge(r.Context(), id, source)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, device)
+}
+
+// getDeviceDuplicates lists pairs of devices that look like the same host
+func (h *Handler) getDeviceDuplicates(w http.ResponseWriter, r *http.Request) {
+	candidates, err := h.svc.Devices.Duplicates(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, candidates)
+}
+
 func (h *Handler) searchDevices(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
@@ -208,15 +371,53 @@ func toAddressSlice(arr []any) []model.Address {
 			if switchPort, ok := m["switch_port"].(string); ok {
 				addr.SwitchPort = switchPort
 			}
+			if mac, ok := m["mac_address"].(string); ok {
+				addr.MACAddress = mac
+			}
 			if poolID, ok := m["pool_id"].(string); ok {
 				addr.PoolID = poolID
 			}
+			if iface, ok := m["interface"].(string); ok {
+				addr.Interface = iface
+			}
 			result = append(result, addr)
 		}
 	}
 	return result
 }
 
+func toInterfaceSlice(arr []any) []model.Interface {
+	result := make([]model.Interface, 0, len(arr))
+	for _, v := range arr {
+		if m, ok := v.(map[string]any); ok {
+			iface := model.Interface{}
+			if name, ok := m["name"].(string); ok {
+				iface.Name = name
+			}
+			if t, ok := m["type"].(string); ok {
+				iface.Type = t
+			}
+			if mac, ok := m["mac_address"].(string); ok {
+				iface.MACAddress = mac
+			}
+			if speed, ok := m["speed_mbps"].(float64); ok {
+				iface.SpeedMbps = int(speed)
+			}
+			if switchPort, ok := m["switch_port"].(string); ok {
+				iface.SwitchPort = switchPort
+			}
+			if vlan, ok := m["vlan"].(float64); ok {
+				iface.VLAN = int(vlan)
+			}
+			if parent, ok := m["parent"].(string); ok {
+				iface.Parent = parent
+			}
+			result = append(result, iface)
+		}
+	}
+	return result
+}
+
 func toCustomFieldSlice(arr []any) []model.CustomFieldValueInput {
 	result := make([]model.CustomFieldValueInput, 0, len(arr))
 	for _, v := range arr {
@@ -234,9 +435,23 @@ func toCustomFieldSlice(arr []any) []model.CustomFieldValueInput {
 	return result
 }
 
+// maxBulkOperationDevices caps the devices of one bulk operations request.
+// Each device only costs a few statements, so this is higher than the limit
+// on bulk create and update.
+const maxBulkOperationDevices = 1000
+
+// bulkCreateDevices handles POST /api/devices/bulk. A JSON array creates the
+// devices it holds; a JSON object is a list of operations to apply to
+// existing devices.
 func (h *Handler) bulkCreateDevices(w http.ResponseWriter, r *http.Request) {
+	body := bufio.NewReader(r.Body)
+	if first, err := peekJSONDelimiter(body); err == nil && first == '{' {
+		h.bulkApplyDeviceOperations(w, r, body)
+		return
+	}
+
 	var devices []*model.Device
-	if err := json.NewDecoder(r.Body).Decode(&devices); err != nil {
+	if err := json.NewDecoder(body).Decode(&devices); err != nil {
 		h.invalidJSON(w)
 		return
 	}
@@ -253,6 +468,42 @@ func (h *Handler) bulkCreateDevices(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, result)
 }
 
+func (h *Handler) bulkApplyDeviceOperations(w http.ResponseWriter, r *http.Request, body io.Reader) {
+	var req model.BulkDeviceRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+	if len(req.DeviceIDs) > maxBulkOperationDevices {
+		h.badRequest(w, fmt.Sprintf("Maximum %d devices allowed in bulk operations", maxBulkOperationDevices))
+		return
+	}
+
+	result, err := h.svc.Bulk.ApplyDeviceOperations(r.Context(), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// peekJSONDelimiter returns the first non-whitespace byte of a JSON body
+// without consuming it
+func peekJSONDelimiter(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.Discard(1)
+		default:
+			return b[0], nil
+		}
+	}
+}
+
 func (h *Handler) bulkUpdateDevices(w http.ResponseWriter, r *http.Request) {
 	var devices []*model.Device
 	if err := json.NewDecoder(r.Body).Decode(&devices); err != nil {
@@ -347,6 +598,7 @@ func (h *Handler) getDeviceStatusCounts(w http.ResponseWriter, r *http.Request)
 	// Ensure all status keys are present with 0 if no devices
 	result := map[string]int{
 		"planned":        0,
+		"ordered":        0,
 		"active":         0,
 		"maintenance":    0,
 		"decommissioned": 0,
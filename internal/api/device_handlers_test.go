@@ -2,10 +2,16 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/validate"
 )
 
 func TestDeviceHandlers(t *testing.T) {
@@ -137,6 +143,35 @@ func TestDeviceHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateDevice_WithInterfaces", func(t *testing.T) {
+		body := `{"interfaces":[{"name":"bond0","type":"bond"},{"name":"eth0","mac_address":"AA-BB-CC-DD-EE-FF","speed_mbps":10000,"parent":"bond0"}],` +
+			`"addresses":[{"ip":"10.0.0.2","type":"ipv4","interface":"bond0"}]}`
+		req := authReq(httptest.NewRequest("PUT", "/api/devices/"+deviceID, bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var device model.Device
+		json.Unmarshal(w.Body.Bytes(), &device)
+		if len(device.Interfaces) != 2 || device.Interfaces[1].Parent != "bond0" || device.Interfaces[1].MACAddress != "aa:bb:cc:dd:ee:ff" {
+			t.Errorf("unexpected interfaces: %+v", device.Interfaces)
+		}
+		if len(device.Addresses) != 1 || device.Addresses[0].Interface != "bond0" {
+			t.Errorf("expected the address on bond0, got %+v", device.Addresses)
+		}
+
+		body = `{"interfaces":[{"name":"eth0"}],"addresses":[{"ip":"10.0.0.2","interface":"bond0"}]}`
+		req = authReq(httptest.NewRequest("PUT", "/api/devices/"+deviceID, bytes.NewBufferString(body)))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d for an address on an unknown interface, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
 	t.Run("UpdateDevice_WithTagsAndDomains", func(t *testing.T) {
 		body := `{"tags":["updated","test"],"domains":["example.com","test.local"]}`
 		req := authReq(httptest.NewRequest("PUT", "/api/devices/"+deviceID, bytes.NewBufferString(body)))
@@ -161,6 +196,55 @@ func TestDeviceHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateDevice_LifecycleDates", func(t *testing.T) {
+		expiry := time.Now().UTC().AddDate(0, 0, 30).Format(time.RFC3339)
+		body := `{"status":"ordered","purchase_date":"2024-03-01T00:00:00Z","warranty_expiry":"` + expiry + `"}`
+		req := authReq(httptest.NewRequest("PUT", "/api/devices/"+deviceID, bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		for _, query := range []string{"warranty_expiring_within=90d&lifecycle=ordered", "warranty_expiring_within=31"} {
+			req = authReq(httptest.NewRequest("GET", "/api/devices?"+query, nil))
+			w = httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			var devices []map[string]any
+			json.Unmarshal(w.Body.Bytes(), &devices)
+			if len(devices) != 1 || devices[0]["id"] != deviceID {
+				t.Errorf("%s: expected the device, got %s", query, w.Body.String())
+			}
+		}
+		req = authReq(httptest.NewRequest("GET", "/api/devices?warranty_expiring_within=10d", nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if strings.Contains(w.Body.String(), deviceID) {
+			t.Errorf("expected no device expiring within 10 days, got %s", w.Body.String())
+		}
+
+		req = authReq(httptest.NewRequest("GET", "/api/devices?warranty_expiring_within=soon", nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d for an invalid duration, got %d", http.StatusBadRequest, w.Code)
+		}
+
+		// An empty string clears a date, an invalid one is rejected
+		req = authReq(httptest.NewRequest("PUT", "/api/devices/"+deviceID, bytes.NewBufferString(`{"warranty_expiry":"","status":"active"}`)))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if strings.Contains(w.Body.String(), "warranty_expiry") || !strings.Contains(w.Body.String(), "purchase_date") {
+			t.Errorf("expected only the warranty to be cleared, got %s", w.Body.String())
+		}
+		req = authReq(httptest.NewRequest("PUT", "/api/devices/"+deviceID, bytes.NewBufferString(`{"end_of_life":"next year"}`)))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d for an invalid date, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
 	t.Run("UpdateDevice_NotFound", func(t *testing.T) {
 		body := `{"name":"Updated"}`
 		req := authReq(httptest.NewRequest("PUT", "/api/devices/nonexistent", bytes.NewBufferString(body)))
@@ -182,6 +266,10 @@ func TestDeviceHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateDevice_IfMatch", func(t *testing.T) {
+		testIfMatch(t, mux, "/api/devices/"+deviceID, `{"description":"edited"}`)
+	})
+
 	t.Run("SearchDevices", func(t *testing.T) {
 		req := authReq(httptest.NewRequest("GET", "/api/search?q=server&type=devices", nil))
 		w := httptest.NewRecorder()
@@ -202,6 +290,160 @@ func TestDeviceHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("MergeDevice", func(t *testing.T) {
+		body := `{"name":"server2-dup","tags":["scanned"],"addresses":[{"ip":"10.0.0.99","type":"ipv4"}]}`
+		req := authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		var created map[string]any
+		json.Unmarshal(w.Body.Bytes(), &created)
+		sourceID := created["id"].(string)
+
+		req = authReq(httptest.NewRequest("POST", "/api/devices/"+deviceID+"/merge?source="+sourceID, nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var merged map[string]any
+		json.Unmarshal(w.Body.Bytes(), &merged)
+		if addrs, _ := merged["addresses"].([]any); len(addrs) < 2 {
+			t.Errorf("expected merged device to gain source address, got %v", merged["addresses"])
+		}
+	})
+
+	t.Run("MergeDevice_MissingSource", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/devices/"+deviceID+"/merge", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("MergeDevice_NotFound", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/devices/"+deviceID+"/merge?source=nonexistent", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("DuplicatesAndMergeByPath", func(t *testing.T) {
+		var ids []string
+		for _, body := range []string{
+			`{"name":"mail-01","addresses":[{"ip":"10.9.0.1","type":"ipv4"}]}`,
+			`{"name":"MAIL01.example.com","addresses":[{"ip":"10.9.0.1","type":"ipv4"}]}`,
+		} {
+			req := authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			var created map[string]any
+			json.Unmarshal(w.Body.Bytes(), &created)
+			ids = append(ids, created["id"].(string))
+		}
+
+		findPair := func() *model.DuplicateCandidate {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/devices/duplicates", nil)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			var candidates []model.DuplicateCandidate
+			json.Unmarshal(w.Body.Bytes(), &candidates)
+			for i, c := range candidates {
+				if c.DeviceID == ids[0] && c.DuplicateID == ids[1] {
+					return &candidates[i]
+				}
+			}
+			return nil
+		}
+		if c := findPair(); c == nil || len(c.Reasons) != 2 {
+			t.Fatalf("expected the pair to be reported by IP and name, got %+v", c)
+		}
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("POST", "/api/devices/"+ids[0]+"/merge/"+ids[1], nil)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if c := findPair(); c != nil {
+			t.Errorf("expected the merged pair to be gone, got %+v", c)
+		}
+	})
+
+	t.Run("PolicyViolation", func(t *testing.T) {
+		h.svc.SetValidationPolicies(&validate.Policies{ProductionTag: "production", RequiredTags: []string{"owner"}})
+		defer h.svc.SetValidationPolicies(&validate.Policies{})
+
+		body := `{"name":"prod-01","tags":["production"]}`
+		req := authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected %d, got %d: %s", http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Code    string               `json:"code"`
+			Details []validate.Violation `json:"details"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Code != "POLICY_VIOLATION" || len(resp.Details) != 1 ||
+			resp.Details[0].Field != "tags" || resp.Details[0].Rule != validate.RuleRequiredTags {
+			t.Errorf("unexpected response: %s", w.Body.String())
+		}
+	})
+
+	t.Run("MACAddress_DuplicateWarning", func(t *testing.T) {
+		create := func(body string) *httptest.ResponseRecorder {
+			req := authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+			}
+			return w
+		}
+
+		w := create(`{"name":"mac-host","addresses":[{"ip":"10.9.0.1","type":"ipv4","mac_address":"AA-BB-CC-09-00-01"}]}`)
+		var created map[string]any
+		json.Unmarshal(w.Body.Bytes(), &created)
+		addr := created["addresses"].([]any)[0].(map[string]any)
+		if addr["mac_address"] != "aa:bb:cc:09:00:01" {
+			t.Errorf("expected normalized MAC, got %v", addr["mac_address"])
+		}
+		if warnings := w.Header().Values("Warning"); len(warnings) != 0 {
+			t.Errorf("expected no warnings for a unique MAC, got %v", warnings)
+		}
+
+		w = create(`{"name":"mac-vm","addresses":[{"ip":"10.9.0.2","type":"ipv4","mac_address":"aa:bb:cc:09:00:01"}]}`)
+		warnings := w.Header().Values("Warning")
+		if len(warnings) != 1 || !strings.Contains(warnings[0], "is also used by device mac-host") {
+			t.Errorf("expected duplicate MAC warning, got %v", warnings)
+		}
+	})
+
+	t.Run("MACAddress_Invalid", func(t *testing.T) {
+		body := `{"name":"bad-mac","addresses":[{"ip":"10.9.0.3","type":"ipv4","mac_address":"zz:zz"}]}`
+		req := authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
 	t.Run("DeleteDevice", func(t *testing.T) {
 		req := authReq(httptest.NewRequest("DELETE", "/api/devices/"+deviceID, nil))
 		w := httptest.NewRecorder()
@@ -222,3 +464,283 @@ func TestDeviceHandlers(t *testing.T) {
 		}
 	})
 }
+
+func TestDryRunWrites(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	t.Run("CreateDevice", func(t *testing.T) {
+		body := `{"name":"dry-server","addresses":[{"ip":"10.0.0.5"}]}`
+		req := authReq(httptest.NewRequest("POST", "/api/devices?dry_run=true", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var device model.Device
+		if err := json.Unmarshal(w.Body.Bytes(), &device); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(device.Addresses) != 1 || device.Addresses[0].Type != model.AddressTypeIPv4 {
+			t.Errorf("expected normalized ipv4 address, got %+v", device.Addresses)
+		}
+
+		devices, err := store.ListDevices(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ListDevices: %v", err)
+		}
+		if len(devices) != 0 {
+			t.Errorf("expected no devices to be saved, got %d", len(devices))
+		}
+	})
+
+	t.Run("CreateDevice_Invalid", func(t *testing.T) {
+		body := `{"description":"No name"}`
+		req := authReq(httptest.NewRequest("POST", "/api/devices?dry_run=true", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("UpdateDevice", func(t *testing.T) {
+		device := &model.Device{Name: "real-server"}
+		if err := store.CreateDevice(context.Background(), device); err != nil {
+			t.Fatalf("CreateDevice: %v", err)
+		}
+
+		body := `{"name":"renamed-server"}`
+		req := authReq(httptest.NewRequest("PUT", "/api/devices/"+device.ID+"?dry_run=true", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		stored, err := store.GetDevice(context.Background(), device.ID)
+		if err != nil {
+			t.Fatalf("GetDevice: %v", err)
+		}
+		if stored.Name != "real-server" {
+			t.Errorf("expected name to be unchanged, got %q", stored.Name)
+		}
+	})
+
+	t.Run("UpdateDevice_NotFound", func(t *testing.T) {
+		body := `{"name":"ghost"}`
+		req := authReq(httptest.NewRequest("PUT", "/api/devices/nonexistent?dry_run=true", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("CreateNetwork", func(t *testing.T) {
+		body := `{"name":"dry-net","subnet":"10.10.0.0/24"}`
+		req := authReq(httptest.NewRequest("POST", "/api/networks?dry_run=true", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		networks, err := store.ListNetworks(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ListNetworks: %v", err)
+		}
+		if len(networks) != 0 {
+			t.Errorf("expected no networks to be saved, got %d", len(networks))
+		}
+	})
+}
+
+func TestDeviceNames(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	web := &model.Device{Name: "web-01"}
+	for _, d := range []*model.Device{web, {Name: "db"}, {Name: "DB"}} {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice: %v", err)
+		}
+	}
+
+	t.Run("GetDevice_ByName", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/devices/WEB-01", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var device model.Device
+		if err := json.Unmarshal(w.Body.Bytes(), &device); err != nil || device.ID != web.ID {
+			t.Errorf("expected device %s, got %+v (%v)", web.ID, device, err)
+		}
+	})
+
+	t.Run("GetDevice_AmbiguousName", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/devices/db", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected %d, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Code    string         `json:"code"`
+			Matches []model.Device `json:"matches"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if resp.Code != "AMBIGUOUS_NAME" || len(resp.Matches) != 2 {
+			t.Errorf("expected AMBIGUOUS_NAME with 2 matches, got %+v", resp)
+		}
+	})
+
+	t.Run("CreateDevice_UniqueNames", func(t *testing.T) {
+		h.svc.SetUniqueDeviceNames(true)
+		defer h.svc.SetUniqueDeviceNames(false)
+
+		body := `{"name":"Web-01"}`
+		req := authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected %d, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestCreateDevice_AllocatesFromPool(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	network := &model.Network{Name: "net", Subnet: "10.20.0.0/24"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "servers", StartIP: "10.20.0.10", EndIP: "10.20.0.20"}
+	if err := store.CreateNetworkPool(ctx, pool); err != nil {
+		t.Fatalf("CreateNetworkPool: %v", err)
+	}
+
+	body := `{"name":"app-1","addresses":[{"pool_id":"` + pool.ID + `"}]}`
+	req := authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var device model.Device
+	if err := json.Unmarshal(w.Body.Bytes(), &device); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(device.Addresses) != 1 || device.Addresses[0].IP != "10.20.0.10" || device.Addresses[0].NetworkID != network.ID {
+		t.Errorf("expected 10.20.0.10 in the pool's network, got %+v", device.Addresses)
+	}
+
+	body = `{"name":"app-2","addresses":[{"label":"mgmt"}]}`
+	req = authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for an address without IP or pool, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestDeviceFieldsAndEmbed(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "Frankfurt", Location: "FRA1"}
+	if err := store.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("failed to create datacenter: %v", err)
+	}
+	network := &model.Network{Name: "servers", Subnet: "10.1.0.0/24", VLANID: 10, DatacenterID: dc.ID}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	device := &model.Device{
+		Name:         "web-01",
+		DatacenterID: dc.ID,
+		Addresses:    []model.Address{{IP: "10.1.0.5", Type: "ipv4", NetworkID: network.ID}},
+	}
+	if err := store.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("failed to create device: %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		return performRequest(mux, authReq(httptest.NewRequest("GET", path, nil)))
+	}
+
+	// Only the chosen fields, in the order asked for
+	w := get("/api/devices?fields=name,id")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got, want := strings.TrimSpace(w.Body.String()), `[{"name":"web-01","id":"`+device.ID+`"}]`; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	// Embedded records are filled in from one lookup each
+	w = get("/api/devices/" + device.ID + "?embed=datacenter,network")
+	var embedded model.Device
+	if err := json.NewDecoder(w.Body).Decode(&embedded); err != nil {
+		t.Fatalf("failed to decode device: %v", err)
+	}
+	if embedded.Datacenter == nil || embedded.Datacenter.Name != "Frankfurt" || embedded.Datacenter.Location != "FRA1" {
+		t.Errorf("expected the datacenter inline, got %+v", embedded.Datacenter)
+	}
+	if len(embedded.Addresses) != 1 || embedded.Addresses[0].Network == nil || embedded.Addresses[0].Network.Subnet != "10.1.0.0/24" {
+		t.Errorf("expected the network inline on the address, got %+v", embedded.Addresses)
+	}
+
+	w = get("/api/datacenters/" + dc.ID + "/devices?embed=datacenter&fields=name,datacenter")
+	if got, want := strings.TrimSpace(w.Body.String()), `[{"name":"web-01","datacenter":{"id":"`+dc.ID+`","name":"Frankfurt","location":"FRA1"}}]`; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	// Without embed nothing is inlined
+	w = get("/api/devices/" + device.ID)
+	if strings.Contains(w.Body.String(), `"datacenter":`) {
+		t.Errorf("expected no inline datacenter without embed, got %s", w.Body.String())
+	}
+
+	for _, path := range []string{"/api/devices?fields=name,secret", "/api/devices?embed=pool"} {
+		if w := get(path); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected %d, got %d", path, http.StatusBadRequest, w.Code)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/martinsuchenak/rackd/internal/export"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// getDHCPReservations renders static DHCP reservations for device addresses
+// that have both a MAC and an IPv4 address. Filtered to a network, the
+// output also carries the network's gateway, DNS servers and MTU.
+func (h *Handler) getDHCPReservations(w http.ResponseWriter, r *http.Request) {
+	format := export.DHCPFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = export.DHCPFormatDnsmasq
+	}
+	if !slices.Contains(export.ValidDHCPFormats, format) {
+		h.badRequest(w, "format must be one of dnsmasq, kea or isc")
+		return
+	}
+
+	networkID := r.URL.Query().Get("network_id")
+	var network *model.Network
+	if networkID != "" {
+		var err error
+		if network, err = h.svc.Networks.Get(r.Context(), networkID); err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+	}
+	filter := model.DeviceFilter{
+		Tags:         parseArrayParam(r, "tags"),
+		DatacenterID: r.URL.Query().Get("datacenter_id"),
+		NetworkID:    networkID,
+	}
+	devices, err := h.svc.Devices.ListAll(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	body, err := export.RenderDHCPReservations(export.DHCPReservations(devices, networkID), network, format)
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	contentType := "text/plain; charset=utf-8"
+	if format == export.DHCPFormatKea {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestDHCPReservationsHandler(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	network := &model.Network{Name: "servers", Subnet: "10.0.0.0/24", Gateway: "10.0.0.1"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	devices := []*model.Device{
+		{Name: "web01", Addresses: []model.Address{
+			{IP: "10.0.0.10", Type: "ipv4", NetworkID: network.ID, MACAddress: "aa:bb:cc:00:00:10"},
+			{IP: "192.168.0.10", Type: "ipv4", MACAddress: "aa:bb:cc:00:00:11"},
+		}},
+		{Name: "db01", Addresses: []model.Address{{IP: "10.0.0.20", Type: "ipv4", NetworkID: network.ID}}},
+	}
+	for _, d := range devices {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("failed to create device: %v", err)
+		}
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", path, nil)))
+		return w
+	}
+
+	t.Run("DefaultDnsmasq", func(t *testing.T) {
+		w := get("/api/dhcp/reservations")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, "dhcp-host=aa:bb:cc:00:00:10,10.0.0.10,web01\n") ||
+			!strings.Contains(body, "dhcp-host=aa:bb:cc:00:00:11,192.168.0.10,web01-2\n") {
+			t.Errorf("unexpected dnsmasq output:\n%s", body)
+		}
+		if strings.Contains(body, "db01") {
+			t.Errorf("device without MAC should be left out:\n%s", body)
+		}
+	})
+
+	t.Run("KeaForNetwork", func(t *testing.T) {
+		w := get("/api/dhcp/reservations?format=kea&network_id=" + network.ID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected JSON content type, got %q", ct)
+		}
+		var kea struct {
+			Subnet       string              `json:"subnet"`
+			OptionData   []map[string]string `json:"option-data"`
+			Reservations []map[string]string `json:"reservations"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &kea); err != nil {
+			t.Fatalf("failed to decode kea output: %v", err)
+		}
+		if len(kea.Reservations) != 1 || kea.Reservations[0]["ip-address"] != "10.0.0.10" {
+			t.Errorf("expected only the network's reservation, got %v", kea.Reservations)
+		}
+		if kea.Subnet != "10.0.0.0/24" || len(kea.OptionData) != 1 || kea.OptionData[0]["data"] != "10.0.0.1" {
+			t.Errorf("expected the network's subnet and gateway, got %s", w.Body.String())
+		}
+
+		if w := get("/api/dhcp/reservations?network_id=missing"); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 for an unknown network, got %d", w.Code)
+		}
+	})
+
+	t.Run("ISC", func(t *testing.T) {
+		w := get("/api/dhcp/reservations?format=isc")
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "host web01 {") {
+			t.Errorf("unexpected isc output %d:\n%s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		if w := get("/api/dhcp/reservations?format=dhcpd"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", w.Code)
+		}
+	})
+}
@@ -2,12 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/martinsuchenak/rackd/internal/log"
 	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
 )
 
 type startScanRequest struct {
@@ -74,10 +79,62 @@ func (h *Handler) cancelScan(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, scan)
 }
 
+// streamScanProgress serves GET /api/discovery/scans/{id}/events, a
+// Server-Sent Events stream of the scan's progress. It sends the current
+// state first and ends once the scan completes or fails.
+func (h *Handler) streamScanProgress(w http.ResponseWriter, r *http.Request) {
+	scan, updates, stop, err := h.svc.Discovery.WatchScan(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	defer stop()
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	writeScanEvent(w, scan)
+	if err := rc.Flush(); err != nil || updates == nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(logStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			writeScanEvent(w, &update)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeScanEvent(w http.ResponseWriter, scan *model.DiscoveryScan) {
+	data, _ := json.Marshal(scan)
+	fmt.Fprintf(w, "event: progress\ndata: %s\n\n", data)
+}
+
 func (h *Handler) listDiscoveredDevices(w http.ResponseWriter, r *http.Request) {
 	networkID := r.URL.Query().Get("network_id")
 
-	devices, err := h.svc.Discovery.ListDevices(r.Context(), networkID)
+	includeIgnored := r.URL.Query().Get("include_ignored") == "true"
+
+	devices, err := h.svc.Discovery.ListDevices(r.Context(), networkID, includeIgnored)
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
@@ -85,6 +142,76 @@ func (h *Handler) listDiscoveredDevices(w http.ResponseWriter, r *http.Request)
 	h.writeJSON(w, http.StatusOK, devices)
 }
 
+func (h *Handler) getDiscoveryDiff(w http.ResponseWriter, r *http.Request) {
+	networkID := r.PathValue("id")
+	staleDays := parseIntParam(r, "stale_days", service.DefaultDiffStaleDays)
+
+	diff, err := h.svc.Discovery.Diff(r.Context(), networkID, staleDays)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, diff)
+}
+
+// listPortObservations serves GET /api/discovery/ports, the port history
+// discovery recorded
+func (h *Handler) listPortObservations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := &model.PortObservationFilter{
+		Pagination: parsePagination(r),
+		NetworkID:  q.Get("network_id"),
+	}
+	if ip := q.Get("ip"); ip != "" {
+		filter.IPs = []string{ip}
+	}
+	if val := q.Get("open"); val != "" {
+		open, err := strconv.ParseBool(val)
+		if err != nil {
+			h.badRequest(w, "open must be true or false")
+			return
+		}
+		filter.Open = &open
+	}
+	if val := q.Get("opened_since"); val != "" {
+		since, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			h.badRequest(w, "opened_since must be an RFC 3339 timestamp")
+			return
+		}
+		filter.OpenedSince = &since
+	}
+
+	ports, err := h.svc.Discovery.ListPorts(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, ports)
+}
+
+// getScanPortChanges serves GET /api/discovery/scans/{id}/ports, the ports
+// the scan found newly opened or closed
+func (h *Handler) getScanPortChanges(w http.ResponseWriter, r *http.Request) {
+	changes, err := h.svc.Discovery.ScanPortChanges(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, changes)
+}
+
+// getDevicePorts serves GET /api/devices/{id}/ports, the port history of the
+// device's addresses
+func (h *Handler) getDevicePorts(w http.ResponseWriter, r *http.Request) {
+	ports, err := h.svc.Discovery.DevicePorts(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, ports)
+}
+
 type promoteRequest struct {
 	Name         string `json:"name"`
 	MakeModel    string `json:"make_model"`
@@ -120,6 +247,40 @@ func (h *Handler) promoteDevice(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusCreated, promoted)
 }
 
+type ignoreDeviceRequest struct {
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until"`
+}
+
+func (h *Handler) ignoreDiscoveredDevice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	// Both fields are optional: an empty body ignores the device for good
+	var req ignoreDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.invalidJSON(w)
+		return
+	}
+
+	device, err := h.svc.Discovery.IgnoreDevice(r.Context(), id, req.Reason, req.Until)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, device)
+}
+
+func (h *Handler) unignoreDiscoveredDevice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	device, err := h.svc.Discovery.UnignoreDevice(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, device)
+}
+
 func (h *Handler) deleteDiscoveredDevice(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
@@ -160,11 +321,15 @@ func (h *Handler) listDiscoveryRules(w http.ResponseWriter, r *http.Request) {
 }
 
 type discoveryRuleRequest struct {
-	NetworkID     string `json:"network_id"`
-	Enabled       bool   `json:"enabled"`
-	ScanType      string `json:"scan_type"`
-	IntervalHours int    `json:"interval_hours"`
-	ExcludeIPs    string `json:"exclude_ips"`
+	NetworkID          string `json:"network_id"`
+	Enabled            bool   `json:"enabled"`
+	ScanType           string `json:"scan_type"`
+	IntervalHours      int    `json:"interval_hours"`
+	ExcludeIPs         string `json:"exclude_ips"`
+	PassiveEnabled     bool   `json:"passive_enabled"`
+	DNSResolution      bool   `json:"dns_resolution"`
+	DNSServer          string `json:"dns_server"`
+	MaxConcurrentScans int    `json:"max_concurrent_scans"`
 }
 
 func (h *Handler) createDiscoveryRule(w http.ResponseWriter, r *http.Request) {
@@ -180,14 +345,18 @@ func (h *Handler) createDiscoveryRule(w http.ResponseWriter, r *http.Request) {
 
 	now := time.Now()
 	rule := &model.DiscoveryRule{
-		ID:            uuid.Must(uuid.NewV7()).String(),
-		NetworkID:     req.NetworkID,
-		Enabled:       req.Enabled,
-		ScanType:      req.ScanType,
-		IntervalHours: req.IntervalHours,
-		ExcludeIPs:    req.ExcludeIPs,
-		CreatedAt:     now,
-		UpdatedAt:     now,
+		ID:                 uuid.Must(uuid.NewV7()).String(),
+		NetworkID:          req.NetworkID,
+		Enabled:            req.Enabled,
+		ScanType:           req.ScanType,
+		IntervalHours:      req.IntervalHours,
+		ExcludeIPs:         req.ExcludeIPs,
+		PassiveEnabled:     req.PassiveEnabled,
+		DNSResolution:      req.DNSResolution,
+		DNSServer:          req.DNSServer,
+		MaxConcurrentScans: req.MaxConcurrentScans,
+		CreatedAt:          now,
+		UpdatedAt:          now,
 	}
 	if rule.ScanType == "" {
 		rule.ScanType = model.ScanTypeQuick
@@ -235,6 +404,10 @@ func (h *Handler) updateDiscoveryRule(w http.ResponseWriter, r *http.Request) {
 		existing.IntervalHours = req.IntervalHours
 	}
 	existing.ExcludeIPs = req.ExcludeIPs
+	existing.PassiveEnabled = req.PassiveEnabled
+	existing.DNSResolution = req.DNSResolution
+	existing.DNSServer = req.DNSServer
+	existing.MaxConcurrentScans = req.MaxConcurrentScans
 	existing.UpdatedAt = time.Now()
 	if err := h.svc.Discovery.UpdateRule(r.Context(), existing); err != nil {
 		h.handleServiceError(w, err)
@@ -253,6 +426,220 @@ func (h *Handler) deleteDiscoveryRule(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) listAutoPromotionRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.svc.Discovery.ListAutoPromotionRules(r.Context(), r.URL.Query().Get("network_id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, rules)
+}
+
+type autoPromotionRuleRequest struct {
+	NetworkID       string         `json:"network_id"`
+	Name            string         `json:"name"`
+	Enabled         *bool          `json:"enabled"`
+	MinConfidence   int            `json:"min_confidence"`
+	MinFactorPoints map[string]int `json:"min_factor_points"`
+	HostnamePattern string         `json:"hostname_pattern"`
+	MinScans        int            `json:"min_scans"`
+	NameTemplate    string         `json:"name_template"`
+	Tags            []string       `json:"tags"`
+	DatacenterID    string         `json:"datacenter_id"`
+}
+
+func (req *autoPromotionRuleRequest) apply(rule *model.AutoPromotionRule) {
+	rule.NetworkID = req.NetworkID
+	rule.Name = req.Name
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	rule.MinConfidence = req.MinConfidence
+	rule.MinFactorPoints = req.MinFactorPoints
+	rule.HostnamePattern = req.HostnamePattern
+	rule.MinScans = req.MinScans
+	rule.NameTemplate = req.NameTemplate
+	rule.Tags = req.Tags
+	rule.DatacenterID = req.DatacenterID
+}
+
+func (h *Handler) createAutoPromotionRule(w http.ResponseWriter, r *http.Request) {
+	var req autoPromotionRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	rule := &model.AutoPromotionRule{Enabled: true}
+	req.apply(rule)
+	if err := h.svc.Discovery.CreateAutoPromotionRule(r.Context(), rule); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, rule)
+}
+
+func (h *Handler) getAutoPromotionRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.svc.Discovery.GetAutoPromotionRule(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, rule)
+}
+
+func (h *Handler) updateAutoPromotionRule(w http.ResponseWriter, r *http.Request) {
+	existing, err := h.svc.Discovery.GetAutoPromotionRule(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	var req autoPromotionRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+	if req.NetworkID == "" {
+		req.NetworkID = existing.NetworkID
+	}
+	req.apply(existing)
+	if err := h.svc.Discovery.UpdateAutoPromotionRule(r.Context(), existing); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, existing)
+}
+
+func (h *Handler) deleteAutoPromotionRule(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Discovery.DeleteAutoPromotionRule(r.Context(), r.PathValue("id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) listScanBlackouts(w http.ResponseWriter, r *http.Request) {
+	blackouts, err := h.svc.Discovery.ListScanBlackouts(r.Context(), r.URL.Query().Get("network_id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, blackouts)
+}
+
+type scanBlackoutRequest struct {
+	NetworkID       string   `json:"network_id"`
+	Name            string   `json:"name"`
+	Enabled         *bool    `json:"enabled"`
+	Type            string   `json:"type"`
+	Weekdays        []string `json:"weekdays"`
+	StartTime       string   `json:"start_time"`
+	EndTime         string   `json:"end_time"`
+	CronExpression  string   `json:"cron_expression"`
+	DurationMinutes int      `json:"duration_minutes"`
+	Timezone        string   `json:"timezone"`
+	Description     string   `json:"description"`
+}
+
+func (req *scanBlackoutRequest) apply(blackout *model.ScanBlackout) {
+	blackout.NetworkID = req.NetworkID
+	blackout.Name = req.Name
+	if req.Enabled != nil {
+		blackout.Enabled = *req.Enabled
+	}
+	blackout.Type = req.Type
+	blackout.Weekdays = req.Weekdays
+	blackout.StartTime = req.StartTime
+	blackout.EndTime = req.EndTime
+	blackout.CronExpression = req.CronExpression
+	blackout.DurationMinutes = req.DurationMinutes
+	blackout.Timezone = req.Timezone
+	blackout.Description = req.Description
+}
+
+func (h *Handler) createScanBlackout(w http.ResponseWriter, r *http.Request) {
+	var req scanBlackoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	blackout := &model.ScanBlackout{Enabled: true}
+	req.apply(blackout)
+	if err := h.svc.Discovery.CreateScanBlackout(r.Context(), blackout); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, blackout)
+}
+
+func (h *Handler) getScanBlackout(w http.ResponseWriter, r *http.Request) {
+	blackout, err := h.svc.Discovery.GetScanBlackout(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, blackout)
+}
+
+func (h *Handler) updateScanBlackout(w http.ResponseWriter, r *http.Request) {
+	existing, err := h.svc.Discovery.GetScanBlackout(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	var req scanBlackoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+	req.apply(existing)
+	if err := h.svc.Discovery.UpdateScanBlackout(r.Context(), existing); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, existing)
+}
+
+func (h *Handler) deleteScanBlackout(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Discovery.DeleteScanBlackout(r.Context(), r.PathValue("id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) runAutoPromotion(w http.ResponseWriter, r *http.Request) {
+	results, err := h.svc.Discovery.RunAutoPromotion(r.Context(), r.URL.Query().Get("network_id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, results)
+}
+
 func isValidScanType(t string) bool {
 	return t == model.ScanTypeQuick || t == model.ScanTypeFull || t == model.ScanTypeDeep
 }
+
+type switchPortCollectRequest struct {
+	CredentialID string `json:"credential_id"`
+	Tag          string `json:"tag"`
+}
+
+func (h *Handler) collectSwitchPorts(w http.ResponseWriter, r *http.Request) {
+	var req switchPortCollectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	report, err := h.svc.Discovery.CollectSwitchPorts(r.Context(), req.CredentialID, req.Tag)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, report)
+}
@@ -6,11 +6,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/martinsuchenak/rackd/internal/auth"
 	"github.com/martinsuchenak/rackd/internal/discovery"
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/service"
@@ -18,7 +19,8 @@ import (
 )
 
 type mockScanner struct {
-	store storage.ExtendedStorage
+	store     storage.ExtendedStorage
+	neighbors []discovery.SwitchNeighbor
 }
 
 func (m *mockScanner) Scan(ctx context.Context, network *model.Network, scanType string) (*model.DiscoveryScan, error) {
@@ -43,79 +45,13 @@ func (m *mockScanner) CancelScan(ctx context.Context, scanID string) error {
 	return nil
 }
 
+func (m *mockScanner) SwitchNeighbors(ctx context.Context, ip, snmpCredID string) ([]discovery.SwitchNeighbor, error) {
+	return m.neighbors, nil
+}
+
 func setupTestHandlerWithScanner(t *testing.T) (*Handler, storage.ExtendedStorage, discovery.Scanner) {
 	t.Helper()
-	store, err := storage.NewSQLiteStorage(":memory:")
-	if err != nil {
-		t.Fatalf("failed to create storage: %v", err)
-	}
-
-	// Create a test user with admin role for RBAC
-	passwordHash, _ := auth.HashPassword("test-password")
-	testUser := &model.User{
-		ID:           "test-user-id",
-		Username:     "testuser",
-		Email:        "test@example.com",
-		PasswordHash: passwordHash,
-		IsActive:     true,
-		IsAdmin:      true,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-	if err := store.CreateUser(context.Background(), testUser); err != nil {
-		t.Fatalf("failed to create test user: %v", err)
-	}
-
-	// Get the admin role (created by bootstrap)
-	roles, err := store.ListRoles(context.Background(), nil)
-	var adminRoleID string
-	if err != nil || len(roles) == 0 {
-		// Create admin role if it doesn't exist
-		adminRole := &model.Role{
-			ID:          "admin-role-id",
-			Name:        "admin",
-			Description: "Administrator role",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		}
-		if err := store.CreateRole(context.Background(), adminRole); err != nil {
-			t.Fatalf("failed to create admin role: %v", err)
-		}
-		adminRoleID = adminRole.ID
-	} else {
-		adminRoleID = roles[0].ID
-	}
-
-	// Get all existing permissions (created by migrations) and assign to admin role
-	allPerms, err := store.ListPermissions(context.Background(), nil)
-	if err != nil {
-		t.Fatalf("failed to list permissions: %v", err)
-	}
-	var permissionIDs []string
-	for _, p := range allPerms {
-		permissionIDs = append(permissionIDs, p.ID)
-	}
-
-	// Assign all permissions to admin role
-	if err := store.SetRolePermissions(context.Background(), adminRoleID, permissionIDs); err != nil {
-		t.Fatalf("failed to set role permissions: %v", err)
-	}
-
-	// Assign the admin role to the test user
-	if err := store.AssignRoleToUser(context.Background(), testUser.ID, adminRoleID); err != nil {
-		t.Fatalf("failed to assign admin role: %v", err)
-	}
-
-	// Create an API key associated with the test user
-	apiKey := &model.APIKey{
-		ID:     "test-key-id",
-		Name:   "test-key",
-		Key:    auth.HashToken(testAPIKeyValue),
-		UserID: testUser.ID,
-	}
-	if err := store.CreateAPIKey(context.Background(), apiKey); err != nil {
-		t.Fatalf("failed to create test API key: %v", err)
-	}
+	store := newTestStore(t)
 
 	scanner := &mockScanner{store: store}
 	h := NewHandler(store, scanner,
@@ -252,6 +188,34 @@ func TestDiscoveryHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("StreamScanProgress", func(t *testing.T) {
+		// The mock scanner cannot stream, so only the current state is sent
+		req := authReq(httptest.NewRequest("GET", "/api/discovery/scans/"+scanID+"/events", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("expected event stream, got %q", ct)
+		}
+		body := w.Body.String()
+		if !strings.HasPrefix(body, "event: progress\ndata: {") || !strings.Contains(body, `"id":"`+scanID+`"`) {
+			t.Errorf("unexpected stream: %q", body)
+		}
+	})
+
+	t.Run("StreamScanProgress_NotFound", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/discovery/scans/nonexistent/events", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
 	t.Run("Discovery_ForbiddenWithoutPermission", func(t *testing.T) {
 		_, limitedToken := createAPIUserForStore(t, store, "limited-discovery-user")
 
@@ -290,6 +254,62 @@ func TestDiscoveryHandlers(t *testing.T) {
 			t.Errorf("expected %d, got %d", http.StatusOK, w.Code)
 		}
 	})
+
+	t.Run("IgnoreDiscoveredDevice", func(t *testing.T) {
+		guest := &model.DiscoveredDevice{IP: "192.168.1.77", NetworkID: network.ID, Status: "online"}
+		store.CreateDiscoveredDevice(context.Background(), guest)
+
+		body := `{"reason":"guest Wi-Fi","until":"` + time.Now().Add(24*time.Hour).UTC().Format(time.RFC3339) + `"}`
+		req := authReq(httptest.NewRequest("POST", "/api/discovery/devices/"+guest.ID+"/ignore", bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var ignored model.DiscoveredDevice
+		json.NewDecoder(w.Body).Decode(&ignored)
+		if ignored.Status != model.DiscoveredStatusIgnored || ignored.IgnoreReason != "guest Wi-Fi" || ignored.IgnoredUntil == nil {
+			t.Fatalf("unexpected ignored device: %+v", ignored)
+		}
+
+		listIPs := func(query string) []string {
+			req := authReq(httptest.NewRequest("GET", "/api/discovery/devices?network_id="+network.ID+query, nil))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			var devices []model.DiscoveredDevice
+			json.NewDecoder(w.Body).Decode(&devices)
+			var ips []string
+			for _, d := range devices {
+				ips = append(ips, d.IP)
+			}
+			return ips
+		}
+		if slices.Contains(listIPs(""), guest.IP) {
+			t.Error("expected ignored device left out of the list")
+		}
+		if !slices.Contains(listIPs("&include_ignored=true"), guest.IP) {
+			t.Error("expected ignored device listed with include_ignored")
+		}
+
+		req = authReq(httptest.NewRequest("DELETE", "/api/discovery/devices/"+guest.ID+"/ignore", nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if !slices.Contains(listIPs(""), guest.IP) {
+			t.Error("expected unignored device listed again")
+		}
+	})
+
+	t.Run("IgnoreDiscoveredDevice_EmptyBodyAndNotFound", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/discovery/devices/nonexistent/ignore", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+		}
+	})
 }
 
 func TestDiscoveryRuleHandlers(t *testing.T) {
@@ -305,7 +325,7 @@ func TestDiscoveryRuleHandlers(t *testing.T) {
 	var ruleID string
 
 	t.Run("CreateDiscoveryRule", func(t *testing.T) {
-		body := `{"network_id":"` + network.ID + `","enabled":true,"scan_type":"full","interval_hours":24}`
+		body := `{"network_id":"` + network.ID + `","enabled":true,"scan_type":"full","interval_hours":24,"max_concurrent_scans":16}`
 		req := authReq(httptest.NewRequest("POST", "/api/discovery/rules", bytes.NewBufferString(body)))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
@@ -318,6 +338,9 @@ func TestDiscoveryRuleHandlers(t *testing.T) {
 		var rule model.DiscoveryRule
 		json.NewDecoder(w.Body).Decode(&rule)
 		ruleID = rule.ID
+		if rule.MaxConcurrentScans != 16 {
+			t.Errorf("expected max_concurrent_scans 16, got %d", rule.MaxConcurrentScans)
+		}
 	})
 
 	t.Run("CreateDiscoveryRule_WithDefaults", func(t *testing.T) {
@@ -580,3 +603,369 @@ func TestPromoteDevice(t *testing.T) {
 		}
 	})
 }
+
+func TestDiscoveryDiff(t *testing.T) {
+	h, store, _ := setupTestHandlerWithScanner(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	network := &model.Network{Name: "DiffNet", Subnet: "10.20.0.0/24"}
+	store.CreateNetwork(context.Background(), network)
+
+	documented := &model.Device{Name: "db-1", Addresses: []model.Address{{IP: "10.20.0.5", Type: "ipv4"}}}
+	store.CreateDevice(context.Background(), documented)
+
+	unknown := &model.DiscoveredDevice{IP: "10.20.0.50", NetworkID: network.ID, Status: "online"}
+	store.CreateDiscoveredDevice(context.Background(), unknown)
+
+	t.Run("Diff", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/networks/"+network.ID+"/discovery/diff?stale_days=3", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var diff model.DiscoveryDiff
+		json.NewDecoder(w.Body).Decode(&diff)
+		if diff.StaleDays != 3 {
+			t.Errorf("expected stale_days 3, got %d", diff.StaleDays)
+		}
+		if len(diff.Undocumented) != 1 || diff.Undocumented[0].IP != "10.20.0.50" {
+			t.Errorf("expected one undocumented host, got %#v", diff.Undocumented)
+		}
+		if len(diff.Missing) != 1 || diff.Missing[0].DeviceName != "db-1" {
+			t.Errorf("expected db-1 to be missing, got %#v", diff.Missing)
+		}
+	})
+
+	t.Run("Diff_NetworkNotFound", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/networks/nonexistent/discovery/diff", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestPortObservations(t *testing.T) {
+	h, store, _ := setupTestHandlerWithScanner(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	network := &model.Network{Name: "PortNet", Subnet: "10.30.0.0/24"}
+	store.CreateNetwork(ctx, network)
+	device := &model.Device{Name: "web-1", Addresses: []model.Address{{IP: "10.30.0.5", Type: "ipv4"}}}
+	store.CreateDevice(ctx, device)
+
+	earlier := &model.DiscoveryScan{NetworkID: network.ID, Status: model.ScanStatusCompleted}
+	store.CreateDiscoveryScan(ctx, earlier)
+	later := &model.DiscoveryScan{NetworkID: network.ID, Status: model.ScanStatusCompleted}
+	store.CreateDiscoveryScan(ctx, later)
+
+	host := &model.DiscoveredDevice{IP: "10.30.0.5", NetworkID: network.ID, OpenPorts: []int{22, 80}}
+	if err := store.RecordPortObservations(ctx, earlier.ID, host, []int{22, 80, 3389}); err != nil {
+		t.Fatalf("RecordPortObservations failed: %v", err)
+	}
+	host.OpenPorts = []int{22, 3389}
+	if err := store.RecordPortObservations(ctx, later.ID, host, []int{22, 80, 3389}); err != nil {
+		t.Fatalf("RecordPortObservations failed: %v", err)
+	}
+	other := &model.DiscoveredDevice{IP: "10.30.0.6", NetworkID: network.ID, OpenPorts: []int{443}}
+	store.RecordPortObservations(ctx, earlier.ID, other, nil)
+
+	get := func(t *testing.T, path string, want int, v any) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", path, nil)))
+		if w.Code != want {
+			t.Fatalf("GET %s: expected %d, got %d: %s", path, want, w.Code, w.Body.String())
+		}
+		if v != nil {
+			json.NewDecoder(w.Body).Decode(v)
+		}
+	}
+
+	t.Run("ScanPortChanges", func(t *testing.T) {
+		var changes model.PortChanges
+		get(t, "/api/discovery/scans/"+later.ID+"/ports", http.StatusOK, &changes)
+		if len(changes.Opened) != 1 || changes.Opened[0].Port != 3389 {
+			t.Errorf("expected port 3389 newly opened, got %+v", changes.Opened)
+		}
+		if len(changes.Closed) != 1 || changes.Closed[0].Port != 80 {
+			t.Errorf("expected port 80 closed, got %+v", changes.Closed)
+		}
+		get(t, "/api/discovery/scans/nonexistent/ports", http.StatusNotFound, nil)
+	})
+
+	t.Run("DevicePorts", func(t *testing.T) {
+		var ports []model.PortObservation
+		get(t, "/api/devices/"+device.ID+"/ports", http.StatusOK, &ports)
+		if len(ports) != 3 {
+			t.Fatalf("expected 3 ports of web-1, got %+v", ports)
+		}
+		for _, p := range ports {
+			if p.IP != "10.30.0.5" {
+				t.Errorf("expected only web-1's ports, got %+v", p)
+			}
+		}
+		get(t, "/api/devices/nonexistent/ports", http.StatusNotFound, nil)
+	})
+
+	t.Run("ListPorts", func(t *testing.T) {
+		var ports []model.PortObservation
+		get(t, "/api/discovery/ports?network_id="+network.ID+"&open=true", http.StatusOK, &ports)
+		if len(ports) != 3 {
+			t.Errorf("expected 3 open ports, got %+v", ports)
+		}
+		get(t, "/api/discovery/ports?ip=10.30.0.6", http.StatusOK, &ports)
+		if len(ports) != 1 || ports[0].Port != 443 {
+			t.Errorf("expected port 443 of 10.30.0.6, got %+v", ports)
+		}
+		get(t, "/api/discovery/ports?opened_since="+time.Now().Add(time.Hour).UTC().Format(time.RFC3339), http.StatusOK, &ports)
+		if len(ports) != 0 {
+			t.Errorf("expected no ports opened in the future, got %+v", ports)
+		}
+		get(t, "/api/discovery/ports?open=maybe", http.StatusBadRequest, nil)
+		get(t, "/api/discovery/ports?opened_since=yesterday", http.StatusBadRequest, nil)
+	})
+}
+
+func TestAutoPromotionRuleHandlers(t *testing.T) {
+	h, store, _ := setupTestHandlerWithScanner(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	network := &model.Network{Name: "AutoNet", Subnet: "10.30.0.0/24"}
+	store.CreateNetwork(context.Background(), network)
+
+	discovered := &model.DiscoveredDevice{IP: "10.30.0.10", Hostname: "srv-01", NetworkID: network.ID, Confidence: 90, ScanCount: 2}
+	store.CreateDiscoveredDevice(context.Background(), discovered)
+
+	var rule model.AutoPromotionRule
+	t.Run("Create", func(t *testing.T) {
+		body := `{"network_id":"` + network.ID + `","name":"servers","min_confidence":80,"hostname_pattern":"^srv-","min_scans":2,"tags":["auto"]}`
+		req := authReq(httptest.NewRequest("POST", "/api/discovery/promotion-rules", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		json.NewDecoder(w.Body).Decode(&rule)
+		if !rule.Enabled || rule.NameTemplate != "{hostname}" {
+			t.Errorf("expected enabled rule with default template, got %+v", rule)
+		}
+	})
+
+	t.Run("Create_InvalidPattern", func(t *testing.T) {
+		body := `{"network_id":"` + network.ID + `","name":"bad","hostname_pattern":"("}`
+		req := authReq(httptest.NewRequest("POST", "/api/discovery/promotion-rules", bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/discovery/promotion-rules/run?network_id="+network.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var results []model.AutoPromotionResult
+		json.NewDecoder(w.Body).Decode(&results)
+		if len(results) != 1 || results[0].DeviceName != "srv-01" {
+			t.Fatalf("expected srv-01 to be promoted, got %+v", results)
+		}
+		device, err := store.GetDevice(context.Background(), results[0].DeviceID)
+		if err != nil || len(device.Tags) != 1 || device.Tags[0] != "auto" {
+			t.Errorf("expected promoted device with default tags, got %+v (%v)", device, err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		body := `{"name":"servers","enabled":false}`
+		req := authReq(httptest.NewRequest("PUT", "/api/discovery/promotion-rules/"+rule.ID, bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var updated model.AutoPromotionRule
+		json.NewDecoder(w.Body).Decode(&updated)
+		if updated.Enabled || updated.NetworkID != network.ID {
+			t.Errorf("expected disabled rule on same network, got %+v", updated)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("DELETE", "/api/discovery/promotion-rules/"+rule.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+
+		req = authReq(httptest.NewRequest("GET", "/api/discovery/promotion-rules/"+rule.ID, nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
+
+func TestScanBlackoutHandlers(t *testing.T) {
+	h, store, _ := setupTestHandlerWithScanner(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	network := &model.Network{Name: "FrozenNet", Subnet: "10.31.0.0/24"}
+	store.CreateNetwork(context.Background(), network)
+
+	var blackout model.ScanBlackout
+	t.Run("Create", func(t *testing.T) {
+		body := `{"network_id":"` + network.ID + `","name":"change freeze","type":"weekly","weekdays":["Fri"],"start_time":"18:00","end_time":"08:00"}`
+		req := authReq(httptest.NewRequest("POST", "/api/discovery/blackouts", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		json.NewDecoder(w.Body).Decode(&blackout)
+		if !blackout.Enabled || len(blackout.Weekdays) != 1 || blackout.Weekdays[0] != "fri" {
+			t.Errorf("expected enabled blackout with normalised weekdays, got %+v", blackout)
+		}
+	})
+
+	t.Run("Create_InvalidCron", func(t *testing.T) {
+		body := `{"name":"bad","type":"cron","cron_expression":"whenever","duration_minutes":60}`
+		req := authReq(httptest.NewRequest("POST", "/api/discovery/blackouts", bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/discovery/blackouts?network_id="+network.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var blackouts []model.ScanBlackout
+		json.NewDecoder(w.Body).Decode(&blackouts)
+		if len(blackouts) != 1 || blackouts[0].ID != blackout.ID {
+			t.Errorf("expected the created blackout, got %+v", blackouts)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		body := `{"name":"month end","type":"cron","cron_expression":"0 0 1 * *","duration_minutes":240,"enabled":false}`
+		req := authReq(httptest.NewRequest("PUT", "/api/discovery/blackouts/"+blackout.ID, bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var updated model.ScanBlackout
+		json.NewDecoder(w.Body).Decode(&updated)
+		if updated.Enabled || updated.NetworkID != "" || updated.Type != model.BlackoutCron || updated.StartTime != "" {
+			t.Errorf("expected disabled global cron blackout, got %+v", updated)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("DELETE", "/api/discovery/blackouts/"+blackout.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+
+		req = authReq(httptest.NewRequest("GET", "/api/discovery/blackouts/"+blackout.ID, nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
+
+func TestCollectSwitchPorts(t *testing.T) {
+	h, store, scanner := setupTestHandlerWithScanner(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	sw := &model.Device{Name: "sw-core-01", Tags: []string{"switch"}, Addresses: []model.Address{{IP: "10.30.0.2", Type: "ipv4"}}}
+	store.CreateDevice(context.Background(), sw)
+	server := &model.Device{Name: "web-1", Addresses: []model.Address{{IP: "10.30.0.10", Type: "ipv4", MACAddress: "00:11:22:33:44:55"}}}
+	store.CreateDevice(context.Background(), server)
+	scanner.(*mockScanner).neighbors = []discovery.SwitchNeighbor{
+		{LocalPort: "Gi1/0/10", Protocol: discovery.NeighborLLDP, MAC: "00:11:22:33:44:55", Name: "web-1"},
+	}
+
+	t.Run("Collect", func(t *testing.T) {
+		body := `{"credential_id": "cred-snmp"}`
+		req := authReq(httptest.NewRequest("POST", "/api/discovery/switch-ports/collect", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var report model.SwitchPortReport
+		json.NewDecoder(w.Body).Decode(&report)
+		if report.Switches != 1 || len(report.Mappings) != 1 || report.Mappings[0].Status != model.SwitchPortFilled {
+			t.Fatalf("expected web-1 port to be filled, got %#v", report)
+		}
+
+		updated, _ := store.GetDevice(context.Background(), server.ID)
+		if updated.Addresses[0].SwitchPort != "Gi1/0/10" {
+			t.Errorf("expected switch port Gi1/0/10, got %q", updated.Addresses[0].SwitchPort)
+		}
+		rels, _ := store.GetRelationships(context.Background(), server.ID)
+		if len(rels) != 1 || rels[0].Type != model.RelationshipConnectedTo {
+			t.Errorf("expected a connected_to relationship, got %#v", rels)
+		}
+	})
+
+	t.Run("MissingCredential", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/discovery/switch-ports/collect", strings.NewReader(`{}`)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
 )
 
 // Provider endpoints
@@ -405,4 +406,19 @@ func (h *Handler) promoteDNSRecord(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, record)
 }
 
-
+// exportDNSZone renders a BIND-style zone fragment for a domain from device
+// addresses and domains
+func (h *Handler) exportDNSZone(w http.ResponseWriter, r *http.Request) {
+	opts := service.ZoneExportOptions{
+		TTL:        parseIntParam(r, "ttl", service.DefaultZoneTTL),
+		IncludePTR: r.URL.Query().Get("ptr") != "false",
+	}
+	zone, err := h.svc.DNS.ExportZone(r.Context(), r.PathValue("domain"), opts)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(zone))
+}
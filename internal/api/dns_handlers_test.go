@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -242,3 +244,76 @@ func TestDNSHandlers(t *testing.T) {
 		}
 	})
 }
+
+func TestDNSHandlers_ExportZone(t *testing.T) {
+	env := setupDNSTestHandler(t)
+	defer env.close()
+
+	ctx := context.Background()
+	network := &model.Network{Name: "zone-lan", Subnet: "10.70.0.0/24", VLANID: 70, Gateway: "10.70.0.1", DNSServers: []string{"10.70.0.53"}, MTU: 9000}
+	if err := env.store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("failed to seed network: %v", err)
+	}
+	devices := []*model.Device{
+		{ID: "zone-web", Name: "web01", Hostname: "web01.example.test", Domains: []string{"www.example.test", "example.test"},
+			Addresses: []model.Address{{IP: "10.70.0.10", Type: "ipv4", NetworkID: network.ID}, {IP: "2001:db8::10", Type: "ipv6"}}},
+		{ID: "zone-db", Name: "db01", Hostname: "db01", Domains: []string{"db.example.test", "db.other.test"},
+			Addresses: []model.Address{{IP: "10.70.0.20", Type: "ipv4"}}},
+		{ID: "zone-old", Name: "old01", Hostname: "old01.example.test", Status: model.DeviceStatusDecommissioned,
+			Addresses: []model.Address{{IP: "10.70.0.30", Type: "ipv4"}}},
+	}
+	for _, d := range devices {
+		if err := env.store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("failed to seed device: %v", err)
+		}
+	}
+
+	w := performRequest(env.mux, authReq(httptest.NewRequest("GET", "/api/dns/zone/Example.Test.?ttl=600", nil)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	body := w.Body.String()
+	// Compare records with their column padding collapsed
+	var lines []string
+	for line := range strings.Lines(body) {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	for _, want := range []string{
+		"$ORIGIN example.test.",
+		"$TTL 600",
+		"@ IN A 10.70.0.10",
+		"@ IN AAAA 2001:db8::10",
+		"db IN A 10.70.0.20",
+		"web01 IN A 10.70.0.10",
+		"www IN AAAA 2001:db8::10",
+		"10.0.70.10.in-addr.arpa. IN PTR web01.example.test.",
+		"20.0.70.10.in-addr.arpa. IN PTR db.example.test.",
+		"; zone-lan 10.70.0.0/24: VLAN 70, gateway 10.70.0.1, DNS 10.70.0.53, MTU 9000",
+	} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("zone fragment missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "other.test") || strings.Contains(body, "old01") {
+		t.Errorf("zone fragment has records outside the zone or for decommissioned devices:\n%s", body)
+	}
+
+	w = performRequest(env.mux, authReq(httptest.NewRequest("GET", "/api/dns/zone/example.test?ptr=false", nil)))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "PTR") || !strings.Contains(w.Body.String(), "$TTL 3600") {
+		t.Errorf("expected default TTL and no PTR records, got %d:\n%s", w.Code, w.Body.String())
+	}
+
+	w = performRequest(env.mux, authReq(httptest.NewRequest("GET", "/api/dns/zone/bad_domain!", nil)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid domain, got %d", w.Code)
+	}
+
+	_, limitedToken := createAPIUserForStore(t, env.store, "limited-zone-user")
+	w = performRequest(env.mux, authReqWithToken(httptest.NewRequest("GET", "/api/dns/zone/example.test", nil), limitedToken))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
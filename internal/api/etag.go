@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// resourceETag derives a strong ETag from a resource's updated_at. The value
+// is the quoted RFC 3339 timestamp, so a client holding a copy from a list
+// response can send If-Match: "<updated_at>" without a GET first.
+func resourceETag(updatedAt time.Time) string {
+	return `"` + updatedAt.UTC().Format(time.RFC3339Nano) + `"`
+}
+
+func setETag(w http.ResponseWriter, updatedAt time.Time) {
+	w.Header().Set("ETag", resourceETag(updatedAt))
+}
+
+// checkIfMatch compares the request's If-Match header with the current
+// version of a resource. It writes a 412 response and returns false when none
+// of the listed tags match; a request without If-Match always passes. The
+// returned context holds the update to that version, so a change made after
+// the check still fails the update rather than being overwritten.
+func (h *Handler) checkIfMatch(w http.ResponseWriter, r *http.Request, updatedAt time.Time) (context.Context, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return r.Context(), true
+	}
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return r.Context(), true
+		}
+		if etagMatches(tag, updatedAt) {
+			return storage.WithExpectedUpdatedAt(r.Context(), updatedAt), true
+		}
+	}
+	setETag(w, updatedAt)
+	h.writePreconditionFailed(w)
+	return nil, false
+}
+
+func (h *Handler) writePreconditionFailed(w http.ResponseWriter) {
+	h.writeError(w, http.StatusPreconditionFailed, "PRECONDITION_FAILED",
+		"Resource was modified by someone else; reload it and try again")
+}
+
+// etagMatches compares the timestamps rather than the strings, so tags built
+// from updated_at in another time zone or precision still match. Weak tags
+// never match, as If-Match uses strong comparison.
+func etagMatches(tag string, updatedAt time.Time) bool {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339Nano, tag[1:len(tag)-1])
+	if err != nil {
+		return false
+	}
+	return t.Equal(updatedAt)
+}
+
+// checkModifiedSince sets Last-Modified for a listing that last changed at
+// changedAt and answers 304 when the client's If-Modified-Since copy is still
+// current. It returns false when the 304 was written. A listing changed
+// within the current second gets no Last-Modified, as another change in the
+// same second would carry the same time.
+func checkModifiedSince(w http.ResponseWriter, r *http.Request, changedAt time.Time) bool {
+	changedAt = changedAt.UTC().Truncate(time.Second)
+	if changedAt.IsZero() || !changedAt.Before(time.Now().UTC().Truncate(time.Second)) {
+		return true
+	}
+	w.Header().Set("Last-Modified", changedAt.Format(http.TimeFormat))
+	// Without this browsers may reuse the copy for a while without asking
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// If-None-Match takes precedence when both are sent
+	if r.Header.Get("If-None-Match") != "" {
+		return true
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || changedAt.After(since) {
+		return true
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return false
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testIfMatch checks the ETag / If-Match round trip on a resource: a stale
+// tag is refused with 412, the current one is accepted and replaced.
+func testIfMatch(t *testing.T, mux *http.ServeMux, path, body string) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", path, nil)))
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected ETag on GET %s", path)
+	}
+
+	put := func(ifMatch string) *httptest.ResponseRecorder {
+		req := authReq(httptest.NewRequest("PUT", path, bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w = put(etag)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d with current ETag, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	newETag := w.Header().Get("ETag")
+	if newETag == "" || newETag == etag {
+		t.Fatalf("expected a new ETag after update, got %q (was %q)", newETag, etag)
+	}
+
+	// Someone else's update has moved the resource on
+	w = put(etag)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected %d with stale ETag, got %d: %s", http.StatusPreconditionFailed, w.Code, w.Body.String())
+	}
+	if w.Header().Get("ETag") != newETag {
+		t.Errorf("expected 412 to carry the current ETag %q, got %q", newETag, w.Header().Get("ETag"))
+	}
+
+	if w = put("*"); w.Code != http.StatusOK {
+		t.Errorf("expected %d with If-Match: *, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 1, 12, 30, 0, 123456000, time.UTC)
+	tests := map[string]bool{
+		resourceETag(updatedAt):              true,
+		`"2026-03-01T13:30:00.123456+01:00"`: true,
+		`"2026-03-01T12:30:00Z"`:             false,
+		`W/"2026-03-01T12:30:00.123456Z"`:    false,
+		`2026-03-01T12:30:00.123456Z`:        false,
+		`"not a time"`:                       false,
+	}
+	for tag, want := range tests {
+		if got := etagMatches(tag, updatedAt); got != want {
+			t.Errorf("etagMatches(%s) = %v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestListIfModifiedSince(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	// Stamp the listings an hour back, as a change within the current second
+	// gets no Last-Modified
+	rewind := func() {
+		changed := time.Now().Add(-time.Hour).Unix()
+		if _, err := store.DB().Exec(`UPDATE collection_changes SET changed_at = ?`, changed); err != nil {
+			t.Fatalf("failed to rewind collection_changes: %v", err)
+		}
+	}
+	get := func(path, since string) *httptest.ResponseRecorder {
+		req := authReq(httptest.NewRequest("GET", path, nil))
+		if since != "" {
+			req.Header.Set("If-Modified-Since", since)
+		}
+		return performRequest(mux, req)
+	}
+
+	for _, path := range []string{"/api/devices", "/api/networks"} {
+		rewind()
+		w := get(path, "")
+		lastModified := w.Header().Get("Last-Modified")
+		if w.Code != http.StatusOK || lastModified == "" {
+			t.Fatalf("%s: expected %d with Last-Modified, got %d (%q)", path, http.StatusOK, w.Code, lastModified)
+		}
+
+		w = get(path, lastModified)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("%s: expected %d with no body, got %d", path, http.StatusNotModified, w.Code)
+		}
+
+		// A filter that depends on the clock is always answered in full
+		if w := get(path+"?stale=true&as_of="+time.Now().UTC().Format(time.RFC3339), lastModified); w.Code != http.StatusOK {
+			t.Errorf("%s: expected %d for a time-dependent listing, got %d", path, http.StatusOK, w.Code)
+		}
+	}
+
+	// Any change to the collection, including a delete, answers in full
+	rewind()
+	lastModified := get("/api/devices", "").Header().Get("Last-Modified")
+	w := performRequest(mux, authReq(httptest.NewRequest("POST", "/api/devices", bytes.NewBufferString(`{"name":"web-01"}`))))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if w := get("/api/devices", lastModified); w.Code != http.StatusOK {
+		t.Errorf("expected %d after a change, got %d", http.StatusOK, w.Code)
+	}
+}
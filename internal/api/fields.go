@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// deviceFields are the JSON fields of a device that ?fields= can select
+var deviceFields = jsonFieldNames(reflect.TypeFor[model.Device]())
+
+// deviceView is how a device request asked for devices to be shown: only
+// the fields chosen with ?fields=, and with the records chosen with ?embed=
+// inline
+type deviceView struct {
+	fields []string
+	embed  []string
+}
+
+// parseDeviceView reads ?fields= and ?embed=, writing a 400 response and
+// returning false when either names something unknown
+func (h *Handler) parseDeviceView(w http.ResponseWriter, r *http.Request) (deviceView, bool) {
+	view := deviceView{
+		fields: parseListParam(r, "fields"),
+		embed:  parseListParam(r, "embed"),
+	}
+	for _, field := range view.fields {
+		if !deviceFields[field] {
+			h.badRequest(w, "Unknown field "+field)
+			return view, false
+		}
+	}
+	if err := service.ValidateEmbed(view.embed); err != nil {
+		h.handleServiceError(w, err)
+		return view, false
+	}
+	return view, true
+}
+
+// writeDevices embeds the related records and writes the devices with the
+// chosen fields
+func (h *Handler) writeDevices(w http.ResponseWriter, r *http.Request, view deviceView, devices []model.Device) {
+	if err := h.svc.Devices.Embed(r.Context(), devices, view.embed); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	if len(view.fields) == 0 {
+		h.writeJSON(w, http.StatusOK, devices)
+		return
+	}
+	selected := make([]json.RawMessage, len(devices))
+	for i := range devices {
+		device, err := selectFields(&devices[i], view.fields)
+		if err != nil {
+			h.internalError(w, err)
+			return
+		}
+		selected[i] = device
+	}
+	h.writeJSON(w, http.StatusOK, selected)
+}
+
+// writeDevice is writeDevices for a single device
+func (h *Handler) writeDevice(w http.ResponseWriter, r *http.Request, view deviceView, device *model.Device) {
+	devices := []model.Device{*device}
+	if err := h.svc.Devices.Embed(r.Context(), devices, view.embed); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	if len(view.fields) == 0 {
+		h.writeJSON(w, http.StatusOK, devices[0])
+		return
+	}
+	selected, err := selectFields(&devices[0], view.fields)
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, selected)
+}
+
+// selectFields encodes v as a JSON object holding only the given fields, in
+// the order given. Fields left out of v's encoding, such as empty omitempty
+// fields, stay out.
+func selectFields(v any, fields []string) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, field := range fields {
+		value, ok := all[field]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(field)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonFieldNames returns the JSON names of a struct's exported fields
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// parseListParam reads a comma-separated query parameter, dropping empty
+// and repeated entries
+func parseListParam(r *http.Request, name string) []string {
+	var values []string
+	for value := range strings.SplitSeq(r.URL.Query().Get(name), ",") {
+		value = strings.TrimSpace(value)
+		if value != "" && !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// grafanaDeviceStatuses are the statuses with a devices.<status> metric
+var grafanaDeviceStatuses = []model.DeviceStatus{
+	model.DeviceStatusPlanned,
+	model.DeviceStatusOrdered,
+	model.DeviceStatusActive,
+	model.DeviceStatusMaintenance,
+	model.DeviceStatusDecommissioned,
+}
+
+// grafanaDeviceMetrics returns the device count metrics
+func grafanaDeviceMetrics() []string {
+	names := []string{model.MetricDevicesTotal, model.MetricDevicesDiscovered}
+	for _, status := range grafanaDeviceStatuses {
+		names = append(names, model.MetricDevicesStatus(status))
+	}
+	return names
+}
+
+// grafanaMetricNames returns every metric the datasource can query. Device
+// counts come from the daily inventory metrics and utilization from the
+// snapshots, both recorded by the snapshot worker.
+func grafanaMetricNames() []string {
+	return append(grafanaDeviceMetrics(), model.MetricPoolsUtilization, model.MetricNetworksUtilization)
+}
+
+// GrafanaRange is the dashboard time range of a Grafana request
+type GrafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// GrafanaTarget is one query of a Grafana panel. Payload narrows the
+// utilization metrics to one pool or network with "id".
+type GrafanaTarget struct {
+	Target  string         `json:"target"`
+	RefID   string         `json:"refId"`
+	Hide    bool           `json:"hide"`
+	Payload map[string]any `json:"payload"`
+}
+
+// GrafanaQueryRequest is the body Grafana posts to the query endpoint
+type GrafanaQueryRequest struct {
+	Range   GrafanaRange    `json:"range"`
+	Targets []GrafanaTarget `json:"targets"`
+}
+
+// GrafanaSeries is a time series, each datapoint a [value, unix ms] pair
+type GrafanaSeries struct {
+	Target     string       `json:"target"`
+	RefID      string       `json:"refId,omitempty"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// GrafanaAnnotationRequest is the body Grafana posts to the annotations
+// endpoint. The query holds key=value filters on the audit log.
+type GrafanaAnnotationRequest struct {
+	Range      GrafanaRange `json:"range"`
+	Annotation struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+	} `json:"annotation"`
+}
+
+// GrafanaAnnotation is an audit event shown on a Grafana panel
+type GrafanaAnnotation struct {
+	Time  int64    `json:"time"`
+	Title string   `json:"title"`
+	Text  string   `json:"text"`
+	Tags  []string `json:"tags"`
+}
+
+// grafanaTestConnection answers the datasource's connection test
+func (h *Handler) grafanaTestConnection(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// grafanaSearch lists the metrics whose names contain the posted target,
+// for the query editor of older datasource versions
+func (h *Handler) grafanaSearch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Target string `json:"target"`
+	}
+	// The body is optional; an empty search lists every metric
+	json.NewDecoder(r.Body).Decode(&req)
+
+	names := []string{}
+	for _, name := range grafanaMetricNames() {
+		if strings.Contains(name, req.Target) {
+			names = append(names, name)
+		}
+	}
+	h.writeJSON(w, http.StatusOK, names)
+}
+
+// grafanaMetrics lists the metrics with the payload options the query
+// editor of newer datasource versions offers
+func (h *Handler) grafanaMetrics(w http.ResponseWriter, r *http.Request) {
+	type payload struct {
+		Label string `json:"label"`
+		Name  string `json:"name"`
+	}
+	type metric struct {
+		Label    string    `json:"label"`
+		Value    string    `json:"value"`
+		Payloads []payload `json:"payloads"`
+	}
+
+	metrics := []metric{}
+	for _, name := range grafanaMetricNames() {
+		m := metric{Label: name, Value: name, Payloads: []payload{}}
+		switch name {
+		case model.MetricPoolsUtilization:
+			m.Payloads = append(m.Payloads, payload{Label: "Pool ID", Name: "id"})
+		case model.MetricNetworksUtilization:
+			m.Payloads = append(m.Payloads, payload{Label: "Network ID", Name: "id"})
+		}
+		metrics = append(metrics, m)
+	}
+	h.writeJSON(w, http.StatusOK, metrics)
+}
+
+// grafanaQuery returns a time series per target. Device counts have a
+// datapoint per day, at midnight UTC, and the current count when the range
+// runs to now; utilization metrics return one series per pool or network,
+// named after it, with a datapoint per snapshot.
+func (h *Handler) grafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var req GrafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.badRequest(w, "Invalid JSON")
+		return
+	}
+	now := time.Now()
+	to := req.Range.To
+	live := to.IsZero() || !to.Before(now.Add(-time.Minute))
+	if live {
+		to = now
+	}
+	from := req.Range.From
+	if from.IsZero() {
+		from = to.Add(-24 * time.Hour)
+	}
+
+	var stats *model.DashboardStats
+	series := []GrafanaSeries{}
+	for _, t := range req.Targets {
+		if t.Hide || t.Target == "" {
+			continue
+		}
+
+		switch t.Target {
+		case model.MetricPoolsUtilization, model.MetricNetworksUtilization:
+			snapshotType := model.SnapshotTypePool
+			if t.Target == model.MetricNetworksUtilization {
+				snapshotType = model.SnapshotTypeNetwork
+			}
+			snapshots, err := h.svc.Dashboard.ListSnapshots(r.Context(), snapshotType, from, to)
+			if err != nil {
+				h.handleServiceError(w, err)
+				return
+			}
+			series = append(series, utilizationSeries(snapshots, t)...)
+			continue
+		}
+
+		if !slices.Contains(grafanaDeviceMetrics(), t.Target) {
+			h.badRequest(w, fmt.Sprintf("unknown metric %q", t.Target))
+			return
+		}
+		days := int(now.Sub(from).Hours()/24) + 1
+		history, err := h.svc.Dashboard.MetricsHistory(r.Context(), []string{t.Target}, "", days)
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+
+		s := GrafanaSeries{Target: t.Target, RefID: t.RefID, Datapoints: [][2]float64{}}
+		today := now.UTC().Format(time.DateOnly)
+		for _, hs := range history {
+			for _, p := range hs.Points {
+				day, err := time.Parse(time.DateOnly, p.Day)
+				// Today's count is replaced by the current one
+				if err != nil || day.Before(from.UTC().Truncate(24*time.Hour)) || day.After(to) || (live && p.Day == today) {
+					continue
+				}
+				s.Datapoints = append(s.Datapoints, [2]float64{p.Value, float64(day.UnixMilli())})
+			}
+		}
+		if live {
+			if stats == nil {
+				if stats, err = h.svc.Dashboard.GetStats(r.Context(), 0, 0); err != nil {
+					h.handleServiceError(w, err)
+					return
+				}
+			}
+			s.Datapoints = append(s.Datapoints, [2]float64{float64(deviceCount(stats, t.Target)), float64(to.UnixMilli())})
+		}
+		series = append(series, s)
+	}
+	h.writeJSON(w, http.StatusOK, series)
+}
+
+// deviceCount returns the current value of a device count metric
+func deviceCount(stats *model.DashboardStats, metric string) int {
+	counts := stats.DeviceStatusCounts
+	switch metric {
+	case model.MetricDevicesTotal:
+		return stats.TotalDevices
+	case model.MetricDevicesDiscovered:
+		return stats.DiscoveredDevices
+	case model.MetricDevicesStatus(model.DeviceStatusPlanned):
+		return counts.Planned
+	case model.MetricDevicesStatus(model.DeviceStatusOrdered):
+		return counts.Ordered
+	case model.MetricDevicesStatus(model.DeviceStatusActive):
+		return counts.Active
+	case model.MetricDevicesStatus(model.DeviceStatusMaintenance):
+		return counts.Maintenance
+	case model.MetricDevicesStatus(model.DeviceStatusDecommissioned):
+		return counts.Decommissioned
+	}
+	return 0
+}
+
+// utilizationSeries groups snapshots, oldest first, into a series per
+// resource, in the order each resource first appears
+func utilizationSeries(snapshots []model.UtilizationSnapshot, t GrafanaTarget) []GrafanaSeries {
+	id, _ := t.Payload["id"].(string)
+	index := map[string]int{}
+	var series []GrafanaSeries
+	for _, s := range snapshots {
+		if id != "" && s.ResourceID != id {
+			continue
+		}
+		i, ok := index[s.ResourceID]
+		if !ok {
+			name := s.ResourceName
+			if name == "" {
+				name = s.ResourceID
+			}
+			i = len(series)
+			index[s.ResourceID] = i
+			series = append(series, GrafanaSeries{Target: name, RefID: t.RefID, Datapoints: [][2]float64{}})
+		}
+		series[i].Datapoints = append(series[i].Datapoints, [2]float64{s.Utilization, float64(s.Timestamp.UnixMilli())})
+	}
+	return series
+}
+
+// grafanaAnnotations returns the audit events in the range as annotations.
+// The annotation query filters them with space separated key=value pairs on
+// resource, action and source; a bare word filters on resource.
+func (h *Handler) grafanaAnnotations(w http.ResponseWriter, r *http.Request) {
+	var req GrafanaAnnotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.badRequest(w, "Invalid JSON")
+		return
+	}
+
+	filter := &model.AuditFilter{Pagination: model.Pagination{Limit: model.MaxPageSize}}
+	if !req.Range.From.IsZero() {
+		filter.StartTime = &req.Range.From
+	}
+	if !req.Range.To.IsZero() {
+		filter.EndTime = &req.Range.To
+	}
+	for _, field := range strings.Fields(req.Annotation.Query) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			key, value = "resource", field
+		}
+		switch key {
+		case "resource":
+			filter.Resource = value
+		case "action":
+			filter.Action = value
+		case "source":
+			filter.Source = value
+		default:
+			h.badRequest(w, fmt.Sprintf("unknown annotation filter %q: use resource, action or source", key))
+			return
+		}
+	}
+
+	logs, err := h.svc.Audit.List(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	annotations := make([]GrafanaAnnotation, 0, len(logs))
+	for _, l := range logs {
+		text := l.Resource
+		if l.ResourceID != "" {
+			text += " " + l.ResourceID
+		}
+		if l.Username != "" {
+			text += " by " + l.Username
+		}
+		if l.Status != "" && l.Status != "success" {
+			text += " (" + l.Status + ")"
+		}
+		tags := []string{}
+		for _, tag := range []string{l.Resource, l.Action, l.Source} {
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		annotations = append(annotations, GrafanaAnnotation{
+			Time:  l.Timestamp.UnixMilli(),
+			Title: l.Action + " " + l.Resource,
+			Text:  text,
+			Tags:  tags,
+		})
+	}
+	h.writeJSON(w, http.StatusOK, annotations)
+}
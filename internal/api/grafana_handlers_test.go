@@ -0,0 +1,201 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestGrafanaHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	for _, d := range []*model.Device{
+		{Name: "web01", Status: model.DeviceStatusActive},
+		{Name: "web02", Status: model.DeviceStatusActive},
+		{Name: "db01", Status: model.DeviceStatusPlanned},
+	} {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("failed to create device: %v", err)
+		}
+	}
+
+	// More snapshots than fit in one page, across two pools
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := range 1200 {
+		pool := "pool-a"
+		if i%2 == 1 {
+			pool = "pool-b"
+		}
+		snapshot := &model.UtilizationSnapshot{
+			Type: model.SnapshotTypePool, ResourceID: pool, ResourceName: "Pool " + pool[5:],
+			Utilization: float64(i % 100), Timestamp: now.Add(-time.Duration(1200-i) * time.Minute),
+		}
+		if err := store.CreateSnapshot(ctx, snapshot); err != nil {
+			t.Fatalf("failed to create snapshot: %v", err)
+		}
+	}
+
+	for _, l := range []*model.AuditLog{
+		{Timestamp: now.Add(-time.Hour), Action: "create", Resource: "device", ResourceID: "dev-1", Username: "alice", Status: "success", Source: "api"},
+		{Timestamp: now.Add(-30 * time.Minute), Action: "delete", Resource: "network", ResourceID: "net-1", Username: "bob", Status: "success", Source: "cli"},
+		{Timestamp: now.Add(-72 * time.Hour), Action: "delete", Resource: "device", ResourceID: "dev-2", Status: "success"},
+	} {
+		if err := store.CreateAuditLog(ctx, l); err != nil {
+			t.Fatalf("failed to create audit log: %v", err)
+		}
+	}
+
+	post := func(path string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("POST", path, bytes.NewReader(data))))
+		return w
+	}
+	dayRange := GrafanaRange{From: now.Add(-24 * time.Hour), To: now.Add(time.Minute)}
+
+	t.Run("TestConnection", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/grafana", nil)))
+		if w.Code != http.StatusOK {
+			t.Errorf("expected %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		w := post("/api/grafana/search", map[string]string{"target": "utilization"})
+		var names []string
+		json.Unmarshal(w.Body.Bytes(), &names)
+		if !slices.Equal(names, []string{"pools.utilization", "networks.utilization"}) {
+			t.Errorf("unexpected metrics %v", names)
+		}
+
+		w = post("/api/grafana/metrics", map[string]string{})
+		var metrics []struct {
+			Value    string `json:"value"`
+			Payloads []struct {
+				Name string `json:"name"`
+			} `json:"payloads"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &metrics)
+		if len(metrics) != len(grafanaMetricNames()) || metrics[0].Value != "devices.total" {
+			t.Errorf("unexpected metrics %+v", metrics)
+		}
+	})
+
+	t.Run("DeviceCounts", func(t *testing.T) {
+		w := post("/api/grafana/query", GrafanaQueryRequest{Range: dayRange, Targets: []GrafanaTarget{
+			{Target: "devices.total", RefID: "A"},
+			{Target: "devices.active", RefID: "B"},
+			{Target: "devices.planned", RefID: "C", Hide: true},
+		}})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var series []GrafanaSeries
+		json.Unmarshal(w.Body.Bytes(), &series)
+		if len(series) != 2 || series[0].Datapoints[0][0] != 3 || series[1].Datapoints[0][0] != 2 || series[1].RefID != "B" {
+			t.Errorf("unexpected series %+v", series)
+		}
+	})
+
+	t.Run("DeviceCountHistory", func(t *testing.T) {
+		yesterday := now.AddDate(0, 0, -1).Truncate(24 * time.Hour)
+		if err := store.RecordInventoryMetrics(ctx, []model.InventoryMetric{
+			{Day: yesterday.Format(time.DateOnly), Name: model.MetricDevicesTotal, Value: 1},
+			{Day: now.AddDate(0, 0, -5).Format(time.DateOnly), Name: model.MetricDevicesTotal, Value: 1},
+		}); err != nil {
+			t.Fatalf("failed to record metrics: %v", err)
+		}
+
+		w := post("/api/grafana/query", GrafanaQueryRequest{Range: dayRange, Targets: []GrafanaTarget{{Target: "devices.total"}}})
+		var series []GrafanaSeries
+		json.Unmarshal(w.Body.Bytes(), &series)
+		want := [][2]float64{{1, float64(yesterday.UnixMilli())}}
+		if len(series) != 1 || len(series[0].Datapoints) != 2 || series[0].Datapoints[0] != want[0] || series[0].Datapoints[1][0] != 3 {
+			t.Fatalf("expected yesterday's count then the current one, got %+v", series)
+		}
+
+		// A range in the past has no current count
+		past := GrafanaRange{From: now.AddDate(0, 0, -2), To: now.Add(-12 * time.Hour)}
+		w = post("/api/grafana/query", GrafanaQueryRequest{Range: past, Targets: []GrafanaTarget{{Target: "devices.total"}}})
+		json.Unmarshal(w.Body.Bytes(), &series)
+		if len(series) != 1 || len(series[0].Datapoints) != 1 {
+			t.Errorf("expected only yesterday's count, got %+v", series)
+		}
+	})
+
+	t.Run("PoolUtilization", func(t *testing.T) {
+		w := post("/api/grafana/query", GrafanaQueryRequest{Range: dayRange, Targets: []GrafanaTarget{{Target: "pools.utilization"}}})
+		var series []GrafanaSeries
+		json.Unmarshal(w.Body.Bytes(), &series)
+		if len(series) != 2 || series[0].Target != "Pool a" || len(series[0].Datapoints)+len(series[1].Datapoints) != 1200 {
+			t.Fatalf("expected a series per pool with every snapshot, got %d series", len(series))
+		}
+		points := series[0].Datapoints
+		if !slices.IsSortedFunc(points, func(a, b [2]float64) int { return int(a[1] - b[1]) }) {
+			t.Error("expected datapoints oldest first")
+		}
+
+		w = post("/api/grafana/query", GrafanaQueryRequest{Range: dayRange, Targets: []GrafanaTarget{
+			{Target: "pools.utilization", Payload: map[string]any{"id": "pool-b"}},
+		}})
+		json.Unmarshal(w.Body.Bytes(), &series)
+		if len(series) != 1 || series[0].Target != "Pool b" {
+			t.Errorf("expected only pool-b, got %+v", series)
+		}
+	})
+
+	t.Run("UnknownMetric", func(t *testing.T) {
+		w := post("/api/grafana/query", GrafanaQueryRequest{Targets: []GrafanaTarget{{Target: "racks.total"}}})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("Annotations", func(t *testing.T) {
+		annotations := func(query string) []GrafanaAnnotation {
+			t.Helper()
+			req := GrafanaAnnotationRequest{Range: dayRange}
+			req.Annotation.Query = query
+			w := post("/api/grafana/annotations", req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			var got []GrafanaAnnotation
+			json.Unmarshal(w.Body.Bytes(), &got)
+			return got
+		}
+
+		// The three day old delete is out of range
+		if got := annotations(""); len(got) != 2 {
+			t.Fatalf("expected 2 annotations in range, got %+v", got)
+		}
+		got := annotations("device")
+		if len(got) != 1 || got[0].Title != "create device" || got[0].Text != "device dev-1 by alice" ||
+			got[0].Time != now.Add(-time.Hour).UnixMilli() || !slices.Equal(got[0].Tags, []string{"device", "create", "api"}) {
+			t.Errorf("unexpected annotation %+v", got)
+		}
+		if got := annotations("action=delete source=cli"); len(got) != 1 || got[0].Title != "delete network" {
+			t.Errorf("unexpected annotations %+v", got)
+		}
+
+		req := GrafanaAnnotationRequest{}
+		req.Annotation.Query = "user=alice"
+		if w := post("/api/grafana/annotations", req); w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d for an unknown filter, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/service"
 	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/martinsuchenak/rackd/internal/validate"
 )
 
 type Handler struct {
@@ -27,7 +29,17 @@ type Handler struct {
 	cookieSecure     bool
 	sessionTTL       time.Duration
 	trustProxy       bool
+	publicURL        string
+	uiBasePath       string
+	apiDocs          bool
+	localSource      string
+	idempotencyTTL   time.Duration
 	svc              *service.Services
+
+	// changeMux serves approved change requests, which are replayed with
+	// the requester already in the context; replaying marks its Handler
+	changeMux *http.ServeMux
+	replaying bool
 }
 
 // HandlerOption configures a Handler during construction.
@@ -71,6 +83,38 @@ func WithTrustProxy(trustProxy bool) HandlerOption {
 	return func(h *Handler) { h.trustProxy = trustProxy }
 }
 
+// WithPublicURL sets the base URL used in links to the web UI, such as the
+// ones in device QR codes. Without it the request's host is used.
+func WithPublicURL(url string) HandlerOption {
+	return func(h *Handler) { h.publicURL = url }
+}
+
+// WithUIBasePath sets the path prefix the web UI is served under behind a
+// reverse proxy, such as /rackd, for the pages and redirects the API sends
+// browsers to.
+func WithUIBasePath(basePath string) HandlerOption {
+	return func(h *Handler) { h.uiBasePath = basePath }
+}
+
+// WithAPIDocs serves Swagger UI at /api/docs.
+func WithAPIDocs(enabled bool) HandlerOption {
+	return func(h *Handler) { h.apiDocs = enabled }
+}
+
+// WithLocalAccess serves every authenticated route as the system caller
+// named by source, without checking credentials. It is only for handlers run
+// in-process over a local database, such as the CLI's offline mode, and must
+// never be used for a handler that listens on the network.
+func WithLocalAccess(source string) HandlerOption {
+	return func(h *Handler) { h.localSource = source }
+}
+
+// WithIdempotencyTTL sets how long responses to POSTs sent with an
+// Idempotency-Key header are kept for retries. Zero disables the header.
+func WithIdempotencyTTL(ttl time.Duration) HandlerOption {
+	return func(h *Handler) { h.idempotencyTTL = ttl }
+}
+
 // WithServices sets the service registry.
 func WithServices(svc *service.Services) HandlerOption {
 	return func(h *Handler) { h.svc = svc }
@@ -86,8 +130,27 @@ func NewHandler(s storage.ExtendedStorage, scanner discovery.Scanner, opts ...Ha
 }
 
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	if !h.replaying && h.svc != nil && h.svc.Changes != nil {
+		replay := *h
+		replay.replaying = true
+		h.changeMux = http.NewServeMux()
+		replay.RegisterRoutes(h.changeMux)
+	}
+
 	wrapAuth := func(handler http.HandlerFunc) http.HandlerFunc {
+		if h.replaying {
+			return LimitBody(handler)
+		}
+		if h.svc != nil && h.svc.Changes != nil {
+			handler = h.approvalGate(handler)
+		}
+		handler = h.idempotency(handler)
 		handler = LimitBody(handler)
+		if h.localSource != "" {
+			return func(w http.ResponseWriter, r *http.Request) {
+				handler(w, r.WithContext(service.SystemContext(r.Context(), h.localSource)))
+			}
+		}
 		if h.sessionManager != nil {
 			return AuthMiddlewareWithSessions(h.store, h.sessionManager, handler)
 		}
@@ -117,6 +180,13 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("PUT /api/datacenters/{id}", wrapAuth(h.updateDatacenter))
 	mux.HandleFunc("DELETE /api/datacenters/{id}", wrapAuth(h.deleteDatacenter))
 	mux.HandleFunc("GET /api/datacenters/{id}/devices", wrapAuth(h.getDatacenterDevices))
+	mux.HandleFunc("GET /api/datacenters/{id}/pools/stats", wrapAuth(h.getDatacenterPoolStats))
+	mux.HandleFunc("GET /api/datacenters/{id}/stats", wrapAuth(h.getDatacenterStats))
+	mux.HandleFunc("GET /api/datacenters/{id}/contacts", wrapAuth(h.listDatacenterContacts))
+	mux.HandleFunc("POST /api/datacenters/{id}/contacts", wrapAuth(h.createDatacenterContact))
+	mux.HandleFunc("GET /api/datacenters/{id}/contacts/{contact_id}", wrapAuth(h.getDatacenterContact))
+	mux.HandleFunc("PUT /api/datacenters/{id}/contacts/{contact_id}", wrapAuth(h.updateDatacenterContact))
+	mux.HandleFunc("DELETE /api/datacenters/{id}/contacts/{contact_id}", wrapAuth(h.deleteDatacenterContact))
 
 	// Network routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/networks", wrapAuth(h.listNetworks))
@@ -125,52 +195,157 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("PUT /api/networks/{id}", wrapAuth(h.updateNetwork))
 	mux.HandleFunc("DELETE /api/networks/{id}", wrapAuth(h.deleteNetwork))
 	mux.HandleFunc("GET /api/networks/{id}/devices", wrapAuth(h.getNetworkDevices))
+	mux.HandleFunc("GET /api/networks/{id}/children", wrapAuth(h.getNetworkChildren))
+	mux.HandleFunc("POST /api/networks/{id}/allocate-subnet", wrapAuth(h.allocateSubnet))
 	mux.HandleFunc("GET /api/networks/{id}/utilization", wrapAuth(h.getNetworkUtilization))
+	mux.HandleFunc("GET /api/networks/{id}/history", wrapAuth(h.getNetworkHistory))
 	mux.HandleFunc("GET /api/networks/{id}/pools", wrapAuth(h.listNetworkPools))
 	mux.HandleFunc("POST /api/networks/{id}/pools", wrapAuth(h.createNetworkPool))
+	mux.HandleFunc("GET /api/networks/{id}/pools/stats", wrapAuth(h.getNetworkPoolStats))
+	mux.HandleFunc("GET /api/networks/{id}/discovery/diff", wrapAuth(h.getDiscoveryDiff))
 
 	// Pool routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/pools/{id}", wrapAuth(h.getNetworkPool))
 	mux.HandleFunc("PUT /api/pools/{id}", wrapAuth(h.updateNetworkPool))
 	mux.HandleFunc("DELETE /api/pools/{id}", wrapAuth(h.deleteNetworkPool))
+	mux.HandleFunc("GET /api/pools/{id}/history", wrapAuth(h.getPoolHistory))
 	mux.HandleFunc("GET /api/pools/{id}/next-ip", wrapAuth(h.getNextIP))
 	mux.HandleFunc("GET /api/pools/{id}/heatmap", wrapAuth(h.getPoolHeatmap))
+	mux.HandleFunc("GET /api/pools/{id}/stats", wrapAuth(h.getPoolStats))
 
 	// Device routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/devices", wrapAuth(h.listDevices))
 	mux.HandleFunc("POST /api/devices", wrapAuth(h.createDevice))
 	mux.HandleFunc("GET /api/devices/status-counts", wrapAuth(h.getDeviceStatusCounts))
+	mux.HandleFunc("GET /api/devices/duplicates", wrapAuth(h.getDeviceDuplicates))
 	mux.HandleFunc("GET /api/devices/{id}", wrapAuth(h.getDevice))
 	mux.HandleFunc("PUT /api/devices/{id}", wrapAuth(h.updateDevice))
 	mux.HandleFunc("DELETE /api/devices/{id}", wrapAuth(h.deleteDevice))
+	mux.HandleFunc("POST /api/devices/{id}/merge", wrapAuth(h.mergeDevice))
+	mux.HandleFunc("POST /api/devices/{id}/merge/{source}", wrapAuth(h.mergeDevice))
+	mux.HandleFunc("GET /api/devices/{id}/vms", wrapAuth(h.getDeviceVMs))
+	mux.HandleFunc("GET /api/devices/{id}/qrcode", wrapAuth(h.getDeviceQRCode))
+	mux.HandleFunc("GET /api/devices/{id}/label", wrapAuth(h.getDeviceLabel))
+	mux.HandleFunc("GET /api/devices/{id}/ports", wrapAuth(h.getDevicePorts))
 
 	// Dashboard routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/dashboard", wrapAuth(h.getDashboardStats))
 	mux.HandleFunc("GET /api/dashboard/trend", wrapAuth(h.getUtilizationTrend))
+	mux.HandleFunc("GET /api/metrics/history", wrapAuth(h.getMetricsHistory))
+
+	// Report routes (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/reports", wrapAuth(h.listReports))
+	mux.HandleFunc("GET /api/reports/{name}", wrapAuth(h.runReport))
+	mux.HandleFunc("GET /api/reports/schedules", wrapAuth(h.listReportSchedules))
+	mux.HandleFunc("POST /api/reports/schedules", wrapAuth(h.createReportSchedule))
+	mux.HandleFunc("GET /api/reports/schedules/{id}", wrapAuth(h.getReportSchedule))
+	mux.HandleFunc("PUT /api/reports/schedules/{id}", wrapAuth(h.updateReportSchedule))
+	mux.HandleFunc("DELETE /api/reports/schedules/{id}", wrapAuth(h.deleteReportSchedule))
+	mux.HandleFunc("POST /api/reports/schedules/{id}/run", wrapAuth(h.runReportSchedule))
 
 	// Relationship routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/relationships", wrapAuth(h.listAllRelationships))
+	mux.HandleFunc("GET /api/relationships/types", wrapAuth(h.listRelationshipTypes))
 	mux.HandleFunc("POST /api/devices/{id}/relationships", wrapAuth(h.addRelationship))
 	mux.HandleFunc("GET /api/devices/{id}/relationships", wrapAuth(h.getRelationships))
 	mux.HandleFunc("GET /api/devices/{id}/related", wrapAuth(h.getRelatedDevices))
+	mux.HandleFunc("GET /api/devices/{id}/impact", wrapAuth(h.getDeviceImpact))
 	mux.HandleFunc("PATCH /api/devices/{id}/relationships/{child_id}/{type}", wrapAuth(h.updateRelationshipNotes))
 	mux.HandleFunc("DELETE /api/devices/{id}/relationships/{child_id}/{type}", wrapAuth(h.removeRelationship))
 
+	// Topology routes (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/topology", wrapAuth(h.getTopology))
+	mux.HandleFunc("GET /api/topology/query", wrapAuth(h.queryTopology))
+
+	// Trash routes (RBAC enforced per item type in service layer)
+	mux.HandleFunc("GET /api/trash", wrapAuth(h.listTrash))
+	mux.HandleFunc("POST /api/trash/{id}/restore", wrapAuth(h.restoreTrashItem))
+	mux.HandleFunc("DELETE /api/trash/{id}", wrapAuth(h.purgeTrashItem))
+
 	// Discovery routes (RBAC enforced in service layer)
 	mux.HandleFunc("POST /api/discovery/networks/{id}/scan", wrapAuth(h.startScan))
 	mux.HandleFunc("GET /api/discovery/scans", wrapAuth(h.listScans))
 	mux.HandleFunc("GET /api/discovery/scans/{id}", wrapAuth(h.getScan))
 	mux.HandleFunc("POST /api/discovery/scans/{id}/cancel", wrapAuth(h.cancelScan))
+	mux.HandleFunc("GET /api/discovery/scans/{id}/events", wrapAuth(h.streamScanProgress))
+	mux.HandleFunc("GET /api/discovery/scans/{id}/ports", wrapAuth(h.getScanPortChanges))
 	mux.HandleFunc("DELETE /api/discovery/scans/{id}", wrapAuth(h.deleteDiscoveryScan))
 	mux.HandleFunc("GET /api/discovery/devices", wrapAuth(h.listDiscoveredDevices))
 	mux.HandleFunc("DELETE /api/discovery/devices", wrapAuth(h.deleteDiscoveredDevicesByNetwork))
 	mux.HandleFunc("DELETE /api/discovery/devices/{id}", wrapAuth(h.deleteDiscoveredDevice))
 	mux.HandleFunc("POST /api/discovery/devices/{id}/promote", wrapAuth(h.promoteDevice))
+	mux.HandleFunc("POST /api/discovery/devices/{id}/ignore", wrapAuth(h.ignoreDiscoveredDevice))
+	mux.HandleFunc("DELETE /api/discovery/devices/{id}/ignore", wrapAuth(h.unignoreDiscoveredDevice))
+	mux.HandleFunc("GET /api/discovery/ports", wrapAuth(h.listPortObservations))
 	mux.HandleFunc("GET /api/discovery/rules", wrapAuth(h.listDiscoveryRules))
 	mux.HandleFunc("POST /api/discovery/rules", wrapAuth(h.createDiscoveryRule))
 	mux.HandleFunc("GET /api/discovery/rules/{id}", wrapAuth(h.getDiscoveryRule))
 	mux.HandleFunc("PUT /api/discovery/rules/{id}", wrapAuth(h.updateDiscoveryRule))
 	mux.HandleFunc("DELETE /api/discovery/rules/{id}", wrapAuth(h.deleteDiscoveryRule))
+	mux.HandleFunc("GET /api/discovery/promotion-rules", wrapAuth(h.listAutoPromotionRules))
+	mux.HandleFunc("POST /api/discovery/promotion-rules", wrapAuth(h.createAutoPromotionRule))
+	mux.HandleFunc("POST /api/discovery/promotion-rules/run", wrapAuth(h.runAutoPromotion))
+	mux.HandleFunc("GET /api/discovery/promotion-rules/{id}", wrapAuth(h.getAutoPromotionRule))
+	mux.HandleFunc("PUT /api/discovery/promotion-rules/{id}", wrapAuth(h.updateAutoPromotionRule))
+	mux.HandleFunc("DELETE /api/discovery/promotion-rules/{id}", wrapAuth(h.deleteAutoPromotionRule))
+	mux.HandleFunc("POST /api/discovery/switch-ports/collect", wrapAuth(h.collectSwitchPorts))
+	mux.HandleFunc("GET /api/discovery/blackouts", wrapAuth(h.listScanBlackouts))
+	mux.HandleFunc("POST /api/discovery/blackouts", wrapAuth(h.createScanBlackout))
+	mux.HandleFunc("GET /api/discovery/blackouts/{id}", wrapAuth(h.getScanBlackout))
+	mux.HandleFunc("PUT /api/discovery/blackouts/{id}", wrapAuth(h.updateScanBlackout))
+	mux.HandleFunc("DELETE /api/discovery/blackouts/{id}", wrapAuth(h.deleteScanBlackout))
+
+	// Remote agent management (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/agents", wrapAuth(h.listAgents))
+	mux.HandleFunc("POST /api/agents", wrapSensitiveAuth(h.registerAgent))
+	mux.HandleFunc("GET /api/agents/{id}", wrapAuth(h.getAgent))
+	mux.HandleFunc("PUT /api/agents/{id}", wrapAuth(h.updateAgent))
+	mux.HandleFunc("DELETE /api/agents/{id}", wrapAuth(h.deleteAgent))
+	mux.HandleFunc("POST /api/agents/{id}/rotate-token", wrapSensitiveAuth(h.rotateAgentToken))
+
+	// Endpoints called by remote agents (agent token auth)
+	mux.HandleFunc("POST /api/agent/heartbeat", h.agentAuth(h.agentHeartbeat))
+	mux.HandleFunc("POST /api/agent/results", h.agentAuth(h.agentSubmitResults))
+
+	// Availability monitoring routes (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/status", wrapAuth(h.getStatusSummary))
+	mux.HandleFunc("GET /api/devices/{id}/status", wrapAuth(h.getDeviceStatus))
+	mux.HandleFunc("GET /api/monitor/checks", wrapAuth(h.listMonitorChecks))
+	mux.HandleFunc("POST /api/monitor/checks", wrapAuth(h.createMonitorCheck))
+	mux.HandleFunc("GET /api/monitor/checks/{id}", wrapAuth(h.getMonitorCheck))
+	mux.HandleFunc("PUT /api/monitor/checks/{id}", wrapAuth(h.updateMonitorCheck))
+	mux.HandleFunc("DELETE /api/monitor/checks/{id}", wrapAuth(h.deleteMonitorCheck))
+
+	// Maintenance window routes (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/maintenance", wrapAuth(h.listMaintenanceWindows))
+	mux.HandleFunc("POST /api/maintenance", wrapAuth(h.createMaintenanceWindow))
+	mux.HandleFunc("GET /api/maintenance/{id}", wrapAuth(h.getMaintenanceWindow))
+	mux.HandleFunc("PUT /api/maintenance/{id}", wrapAuth(h.updateMaintenanceWindow))
+	mux.HandleFunc("DELETE /api/maintenance/{id}", wrapAuth(h.deleteMaintenanceWindow))
+
+	// Ansible dynamic inventory (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/ansible/inventory", wrapAuth(h.getAnsibleInventory))
+
+	// Prometheus HTTP service discovery (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/prometheus/sd", wrapAuth(h.getPrometheusSD))
+
+	// Grafana JSON datasource (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/grafana", wrapAuth(h.grafanaTestConnection))
+	mux.HandleFunc("POST /api/grafana/search", wrapAuth(h.grafanaSearch))
+	mux.HandleFunc("POST /api/grafana/metrics", wrapAuth(h.grafanaMetrics))
+	mux.HandleFunc("POST /api/grafana/query", wrapAuth(h.grafanaQuery))
+	mux.HandleFunc("POST /api/grafana/annotations", wrapAuth(h.grafanaAnnotations))
+
+	// DHCP reservation export (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/dhcp/reservations", wrapAuth(h.getDHCPReservations))
+
+	// Data-source query for Terraform/OpenTofu (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/v1/query", wrapAuth(h.query))
+
+	// Cloud sync routes (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/cloud/providers", wrapAuth(h.listCloudProviders))
+	mux.HandleFunc("POST /api/cloud/sync", wrapAuth(h.syncCloud))
+	mux.HandleFunc("POST /api/cloud/import", wrapAuth(h.importCloud))
 
 	// Credentials routes (if storage is configured)
 	if h.credStore != nil {
@@ -218,6 +393,7 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 
 	// Search routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/search", wrapAuth(h.search))
+	mux.HandleFunc("POST /api/search/structured", wrapAuth(h.structuredSearch))
 
 	// Audit log routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/audit", wrapAuth(h.listAuditLogs))
@@ -228,6 +404,18 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/logs", wrapAuth(h.listLogs))
 	mux.HandleFunc("GET /api/logs/export", wrapAuth(h.exportLogs))
 	mux.HandleFunc("GET /api/logs/{id}", wrapAuth(h.getLogEntry))
+	mux.HandleFunc("GET /api/admin/logs/stream", wrapAuth(h.streamLogs))
+	mux.HandleFunc("POST /api/admin/backup", wrapAuth(h.createBackup))
+	mux.HandleFunc("GET /api/admin/db/check", wrapAuth(h.checkDatabaseIntegrity))
+	mux.HandleFunc("POST /api/admin/db/optimize", wrapAuth(h.optimizeDatabase))
+
+	// Change request routes (approval mode; access checked in service layer)
+	if h.svc != nil && h.svc.Changes != nil {
+		mux.HandleFunc("GET /api/changes", wrapAuth(h.listChanges))
+		mux.HandleFunc("GET /api/changes/{id}", wrapAuth(h.getChange))
+		mux.HandleFunc("POST /api/changes/{id}/approve", wrapAuth(h.approveChange))
+		mux.HandleFunc("POST /api/changes/{id}/reject", wrapAuth(h.rejectChange))
+	}
 
 	// Auth routes (no auth required for login)
 	loginHandler := LimitBody(h.login)
@@ -238,6 +426,17 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/auth/logout", wrapAuth(h.logout))
 	mux.HandleFunc("GET /api/auth/me", wrapAuth(h.getCurrentUser))
 
+	// OIDC single sign-on (conditional)
+	if h.svc != nil && h.svc.OIDC != nil {
+		oidcLogin, oidcCallback := http.HandlerFunc(h.oidcLogin), http.HandlerFunc(h.oidcCallback)
+		if h.loginRateLimiter != nil {
+			oidcLogin = LoginRateLimitMiddleware(h.loginRateLimiter, h.trustProxy, oidcLogin)
+			oidcCallback = LoginRateLimitMiddleware(h.loginRateLimiter, h.trustProxy, oidcCallback)
+		}
+		mux.HandleFunc("GET /api/auth/oidc/login", oidcLogin)
+		mux.HandleFunc("GET /api/auth/oidc/callback", oidcCallback)
+	}
+
 	// User routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/users", wrapAuth(h.listUsers))
 	mux.HandleFunc("POST /api/users", wrapSensitiveAuth(h.createUser))
@@ -295,6 +494,8 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("DELETE /api/reservations/{id}", wrapAuth(h.deleteReservation))
 	mux.HandleFunc("POST /api/reservations/{id}/release", wrapAuth(h.releaseReservation))
 	mux.HandleFunc("GET /api/pools/{id}/reservations", wrapAuth(h.listPoolReservations))
+	mux.HandleFunc("POST /api/pools/{id}/allocate", wrapAuth(h.allocatePoolIP))
+	mux.HandleFunc("POST /api/pools/{id}/release", wrapAuth(h.releasePoolIP))
 
 	// Webhook routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/webhooks", wrapAuth(h.listWebhooks))
@@ -307,6 +508,14 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/webhooks/{id}/deliveries", wrapAuth(h.listWebhookDeliveries))
 	mux.HandleFunc("GET /api/webhooks/{id}/deliveries/{deliveryId}", wrapAuth(h.getWebhookDelivery))
 
+	// Notification channel routes (RBAC enforced in service layer)
+	mux.HandleFunc("GET /api/notifications", wrapAuth(h.listNotificationChannels))
+	mux.HandleFunc("POST /api/notifications", wrapAuth(h.createNotificationChannel))
+	mux.HandleFunc("GET /api/notifications/{id}", wrapAuth(h.getNotificationChannel))
+	mux.HandleFunc("PUT /api/notifications/{id}", wrapAuth(h.updateNotificationChannel))
+	mux.HandleFunc("DELETE /api/notifications/{id}", wrapAuth(h.deleteNotificationChannel))
+	mux.HandleFunc("POST /api/notifications/{id}/test", wrapAuth(h.testNotificationChannel))
+
 	// Custom field routes (RBAC enforced in service layer)
 	mux.HandleFunc("GET /api/custom-fields", wrapAuth(h.listCustomFieldDefinitions))
 	mux.HandleFunc("POST /api/custom-fields", wrapAuth(h.createCustomFieldDefinition))
@@ -348,6 +557,7 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 		mux.HandleFunc("POST /api/dns/zones/{id}/sync", wrapAuth(h.syncDNSZone))
 		mux.HandleFunc("POST /api/dns/zones/{id}/import", wrapAuth(h.importDNSZone))
 		mux.HandleFunc("GET /api/dns/zones/{id}/records", wrapAuth(h.listDNSZoneRecords))
+		mux.HandleFunc("GET /api/dns/zone/{domain}", wrapAuth(h.exportDNSZone))
 		// Record routes
 		mux.HandleFunc("GET /api/dns/records/{id}", wrapAuth(h.getDNSRecord))
 		mux.HandleFunc("PUT /api/dns/records/{id}", wrapAuth(h.updateDNSRecord))
@@ -356,12 +566,31 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 		mux.HandleFunc("POST /api/dns/records/{id}/promote", wrapAuth(h.promoteDNSRecord))
 	}
 
+	// Hypervisor connector routes (RBAC enforced in service layer)
+	if h.svc != nil && h.svc.Hypervisors != nil {
+		mux.HandleFunc("GET /api/hypervisors", wrapAuth(h.listHypervisorConnectors))
+		mux.HandleFunc("POST /api/hypervisors", wrapAuth(h.createHypervisorConnector))
+		mux.HandleFunc("POST /api/hypervisors/sync", wrapAuth(h.syncHypervisors))
+		mux.HandleFunc("GET /api/hypervisors/{id}", wrapAuth(h.getHypervisorConnector))
+		mux.HandleFunc("PUT /api/hypervisors/{id}", wrapAuth(h.updateHypervisorConnector))
+		mux.HandleFunc("DELETE /api/hypervisors/{id}", wrapAuth(h.deleteHypervisorConnector))
+		mux.HandleFunc("POST /api/hypervisors/{id}/sync", wrapAuth(h.syncHypervisorConnector))
+	}
+
 	// Health check routes (no auth required)
 	mux.HandleFunc("GET /healthz", h.healthz)
 	mux.HandleFunc("GET /readyz", h.readyz)
 
 	// Metrics route (requires auth)
 	mux.HandleFunc("GET /metrics", wrapAuth(h.metricsHandler))
+
+	// OpenAPI spec (no auth required)
+	mux.HandleFunc("GET /api/openapi.json", h.getOpenAPISpec)
+	mux.HandleFunc("GET /api/openapi.yaml", h.getOpenAPISpecYAML)
+	if h.apiDocs {
+		mux.HandleFunc("GET /api/docs", h.getAPIDocs)
+		mux.HandleFunc("GET /api/docs/init.js", h.getAPIDocsInit)
+	}
 }
 
 func (h *Handler) writeJSON(w http.ResponseWriter, status int, data any) {
@@ -400,12 +629,30 @@ func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
 		h.writeError(w, http.StatusConflict, "ALREADY_EXISTS", err.Error())
 	case errors.Is(err, service.ErrIPNotAvailable):
 		h.writeError(w, http.StatusConflict, "IP_NOT_AVAILABLE", "No IP addresses available")
+	case errors.Is(err, service.ErrSubnetNotAvailable):
+		h.writeError(w, http.StatusConflict, "SUBNET_NOT_AVAILABLE", "No subnet of the requested size available")
 	case errors.Is(err, service.ErrValidation):
 		h.writeValidationErrors(w, toValidationErrors(err))
+	case errors.Is(err, service.ErrPolicyViolation):
+		h.writePolicyViolation(w, err)
 	case errors.Is(err, service.ErrSelfDelete):
 		h.writeError(w, http.StatusBadRequest, "CANNOT_DELETE_SELF", err.Error())
 	case errors.Is(err, service.ErrSystemRole):
 		h.writeError(w, http.StatusBadRequest, "SYSTEM_ROLE", err.Error())
+	case errors.Is(err, service.ErrQueryTooBroad):
+		h.writeQueryTooBroad(w, err)
+	case errors.Is(err, service.ErrInvalidQuery):
+		h.writeInvalidQuery(w, err)
+	case errors.Is(err, service.ErrHasDependents):
+		h.writeHasDependents(w, err)
+	case errors.Is(err, service.ErrAmbiguousName):
+		h.writeAmbiguousName(w, err)
+	case errors.Is(err, service.ErrChangeNotPending):
+		h.writeError(w, http.StatusConflict, "NOT_PENDING", err.Error())
+	case errors.Is(err, service.ErrApprovalRequired):
+		h.writeError(w, http.StatusForbidden, "APPROVAL_REQUIRED", err.Error())
+	case errors.Is(err, storage.ErrModified):
+		h.writePreconditionFailed(w)
 	default:
 		h.internalError(w, err)
 	}
@@ -438,6 +685,73 @@ func (h *Handler) writeValidationErrors(w http.ResponseWriter, errs ValidationEr
 	})
 }
 
+func (h *Handler) writeQueryTooBroad(w http.ResponseWriter, err error) {
+	var hints []string
+	var tooBroad *service.QueryTooBroadError
+	if errors.As(err, &tooBroad) {
+		hints = tooBroad.Hints
+	}
+	h.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+		"error": err.Error(),
+		"code":  "QUERY_TOO_BROAD",
+		"hints": hints,
+	})
+}
+
+// writeInvalidQuery writes a 400 whose details hold the character offsets of
+// the part of the query at fault
+func (h *Handler) writeInvalidQuery(w http.ResponseWriter, err error) {
+	details := map[string]int{}
+	var queryErr *service.InvalidQueryError
+	if errors.As(err, &queryErr) {
+		details = map[string]int{"start": queryErr.Start, "end": queryErr.End}
+	}
+	h.writeJSON(w, http.StatusBadRequest, map[string]any{
+		"error":   err.Error(),
+		"code":    "INVALID_QUERY",
+		"details": details,
+	})
+}
+
+func (h *Handler) writePolicyViolation(w http.ResponseWriter, err error) {
+	violations := []validate.Violation{}
+	var policyErr *service.PolicyViolationError
+	if errors.As(err, &policyErr) {
+		violations = policyErr.Violations
+	}
+	h.writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+		"error":   err.Error(),
+		"code":    "POLICY_VIOLATION",
+		"details": violations,
+	})
+}
+
+func (h *Handler) writeHasDependents(w http.ResponseWriter, err error) {
+	var depErr *service.DependentsError
+	dependents := map[string]int{}
+	if errors.As(err, &depErr) {
+		dependents = depErr.Dependents
+	}
+	h.writeJSON(w, http.StatusConflict, map[string]any{
+		"error":      err.Error(),
+		"code":       "HAS_DEPENDENTS",
+		"dependents": dependents,
+	})
+}
+
+func (h *Handler) writeAmbiguousName(w http.ResponseWriter, err error) {
+	matches := []model.Device{}
+	var ambErr *service.AmbiguousNameError
+	if errors.As(err, &ambErr) {
+		matches = ambErr.Matches
+	}
+	h.writeJSON(w, http.StatusConflict, map[string]any{
+		"error":   err.Error(),
+		"code":    "AMBIGUOUS_NAME",
+		"matches": matches,
+	})
+}
+
 func parseArrayParam(r *http.Request, name string) []string {
 	values := r.URL.Query()[name]
 	if len(values) == 0 {
@@ -458,6 +772,42 @@ func parseIntParam(r *http.Request, name string, defaultValue int) int {
 	return result
 }
 
+// parseDeletePolicy reads the optional cascade query parameter. It writes a
+// 400 response and returns false when the value is invalid.
+func (h *Handler) parseDeletePolicy(w http.ResponseWriter, r *http.Request) (model.DeletePolicy, bool) {
+	policy, ok := model.ParseDeletePolicy(r.URL.Query().Get("cascade"))
+	if !ok {
+		h.badRequest(w, "cascade must be true, detach or deny")
+		return "", false
+	}
+	return policy, true
+}
+
+// parseAsOf reads the optional as_of query parameter (RFC 3339). It writes a
+// 400 response and returns false when the value is invalid.
+func (h *Handler) parseAsOf(w http.ResponseWriter, r *http.Request) (*time.Time, bool) {
+	val := r.URL.Query().Get("as_of")
+	if val == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		h.badRequest(w, "as_of must be an RFC 3339 timestamp")
+		return nil, false
+	}
+	return &t, true
+}
+
+// writeContext returns the context for a create or update, marked as a dry
+// run when the dry_run query parameter is true. A dry run validates and
+// normalizes the resource without saving it.
+func writeContext(r *http.Request) (context.Context, bool) {
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dryRun {
+		return service.WithDryRun(r.Context()), true
+	}
+	return r.Context(), false
+}
+
 // parsePagination reads limit/offset from query params and clamps to safe bounds.
 func parsePagination(r *http.Request) model.Pagination {
 	p := model.Pagination{
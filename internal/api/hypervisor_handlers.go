@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// listHypervisorConnectors returns the hypervisor connectors
+func (h *Handler) listHypervisorConnectors(w http.ResponseWriter, r *http.Request) {
+	filter := &model.HypervisorConnectorFilter{}
+	if t := r.URL.Query().Get("type"); t != "" {
+		filter.Type = model.HypervisorType(t)
+	}
+	if enabled := r.URL.Query().Get("enabled"); enabled != "" {
+		enabledBool := enabled == "true"
+		filter.Enabled = &enabledBool
+	}
+
+	connectors, err := h.svc.Hypervisors.List(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, connectors)
+}
+
+// createHypervisorConnector creates a new hypervisor connector
+func (h *Handler) createHypervisorConnector(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateHypervisorConnectorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	connector, err := h.svc.Hypervisors.Create(r.Context(), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, connector)
+}
+
+// getHypervisorConnector returns a single hypervisor connector by ID
+func (h *Handler) getHypervisorConnector(w http.ResponseWriter, r *http.Request) {
+	connector, err := h.svc.Hypervisors.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, connector)
+}
+
+// updateHypervisorConnector updates an existing hypervisor connector
+func (h *Handler) updateHypervisorConnector(w http.ResponseWriter, r *http.Request) {
+	var req model.UpdateHypervisorConnectorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	connector, err := h.svc.Hypervisors.Update(r.Context(), r.PathValue("id"), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, connector)
+}
+
+// deleteHypervisorConnector deletes a hypervisor connector
+func (h *Handler) deleteHypervisorConnector(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Hypervisors.Delete(r.Context(), r.PathValue("id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// syncHypervisorConnector syncs the hosts and VMs of one connector
+func (h *Handler) syncHypervisorConnector(w http.ResponseWriter, r *http.Request) {
+	result, err := h.svc.Hypervisors.Sync(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// syncHypervisors syncs every enabled connector
+func (h *Handler) syncHypervisors(w http.ResponseWriter, r *http.Request) {
+	results, err := h.svc.Hypervisors.SyncAll(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, results)
+}
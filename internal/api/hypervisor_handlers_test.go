@@ -0,0 +1,77 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/credentials"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+func TestHypervisorHandlers(t *testing.T) {
+	baseHandler, store := setupTestHandler(t)
+	defer store.Close()
+
+	services := service.NewServices(store, nil, nil)
+	encryptor, err := credentials.NewEncryptor([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+	services.SetHypervisorService(store, encryptor)
+	h := NewHandler(store, baseHandler.scanner, WithServices(services))
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	body := `{"name":"pve","type":"proxmox","endpoint":"https://pve1:8006","username":"root@pam!rackd","secret":"token-secret"}`
+	w := performRequest(mux, authReq(httptest.NewRequest("POST", "/api/hypervisors", bytes.NewBufferString(body))))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "token-secret") {
+		t.Fatal("connector secret should not be exposed")
+	}
+	var connector model.HypervisorConnector
+	if err := json.Unmarshal(w.Body.Bytes(), &connector); err != nil {
+		t.Fatalf("failed to decode connector: %v", err)
+	}
+
+	w = performRequest(mux, authReq(httptest.NewRequest("POST", "/api/hypervisors", bytes.NewBufferString(body))))
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a duplicate name, got %d: %s", w.Code, w.Body.String())
+	}
+	w = performRequest(mux, authReq(httptest.NewRequest("POST", "/api/hypervisors", bytes.NewBufferString(`{"name":"x","type":"xen"}`))))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid connector, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = performRequest(mux, authReq(httptest.NewRequest("PUT", "/api/hypervisors/"+connector.ID, bytes.NewBufferString(`{"enabled":false}`))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = performRequest(mux, authReq(httptest.NewRequest("GET", "/api/hypervisors?enabled=false", nil)))
+	var connectors []model.HypervisorConnector
+	if err := json.Unmarshal(w.Body.Bytes(), &connectors); err != nil || len(connectors) != 1 || connectors[0].Enabled {
+		t.Fatalf("expected the disabled connector, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Disabled connectors are left out of a full sync
+	w = performRequest(mux, authReq(httptest.NewRequest("POST", "/api/hypervisors/sync", nil)))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("expected an empty sync, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = performRequest(mux, authReq(httptest.NewRequest("DELETE", "/api/hypervisors/"+connector.ID, nil)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	w = performRequest(mux, authReq(httptest.NewRequest("GET", "/api/hypervisors/"+connector.ID, nil)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotentHeaders are the response headers replayed with a stored response
+var idempotentHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// idempotency makes a POST sent with an Idempotency-Key header safe to retry.
+// The first response is stored for the key; a retry with the same key and
+// request gets it back instead of running again. Reusing a key for a
+// different request fails with 422, and a retry while the first request is
+// still running with 409. 5xx responses are not stored, so those requests
+// can be retried.
+func (h *Handler) idempotency(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" || h.idempotencyTTL <= 0 {
+			next(w, r)
+			return
+		}
+		if !validRequestID(key) {
+			h.writeError(w, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", "Idempotency-Key must be up to 128 letters, digits, '-', '_', '.' or ':'")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.badRequest(w, "Request body too large")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+		hash.Write(body)
+
+		rec := &model.IdempotencyRecord{
+			Key:         key,
+			Method:      r.Method,
+			Path:        r.URL.RequestURI(),
+			RequestHash: hex.EncodeToString(hash.Sum(nil)),
+			ExpiresAt:   time.Now().UTC().Add(h.idempotencyTTL),
+		}
+		if caller := service.CallerFrom(r.Context()); caller != nil {
+			rec.UserID = caller.UserID
+		}
+
+		existing, err := h.store.ReserveIdempotencyKey(r.Context(), rec)
+		if err != nil {
+			h.internalError(w, err)
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.RequestHash != rec.RequestHash:
+				h.writeError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+			case existing.Status == 0:
+				h.writeError(w, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE", "A request with this Idempotency-Key is still in progress")
+			default:
+				for name, value := range existing.Headers {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(existing.Status)
+				w.Write(existing.Body)
+			}
+			return
+		}
+
+		// Free the key unless a response is stored, including when the
+		// handler panics
+		stored := false
+		defer func() {
+			if !stored {
+				if err := h.store.ReleaseIdempotencyKey(context.WithoutCancel(r.Context()), rec.UserID, rec.Key); err != nil {
+					log.Error("Failed to release idempotency key", "error", err)
+				}
+			}
+		}()
+
+		rw := &idempotencyRecorder{ResponseWriter: w}
+		next(rw, r)
+
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		if rw.status >= http.StatusInternalServerError {
+			return
+		}
+		rec.Status = rw.status
+		rec.Body = rw.body.Bytes()
+		rec.Headers = make(map[string]string)
+		for _, name := range idempotentHeaders {
+			if value := w.Header().Get(name); value != "" {
+				rec.Headers[name] = value
+			}
+		}
+		if err := h.store.CompleteIdempotencyKey(context.WithoutCancel(r.Context()), rec); err != nil {
+			log.Error("Failed to store idempotent response", "error", err)
+			return
+		}
+		stored = true
+	}
+}
+
+// idempotencyRecorder passes a response through while keeping a copy
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *idempotencyRecorder) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *idempotencyRecorder) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rw *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	_, store := setupTestHandler(t)
+	defer store.Close()
+	h := NewHandler(store, nil,
+		WithServices(service.NewServices(store, nil, nil)),
+		WithIdempotencyTTL(time.Hour),
+	)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req := authReq(httptest.NewRequest("POST", "/api/datacenters", bytes.NewBufferString(body)))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		return performRequest(mux, req)
+	}
+	countDatacenters := func() int {
+		dcs, _ := store.ListDatacenters(context.Background(), nil)
+		return len(dcs)
+	}
+
+	existing := countDatacenters()
+	first := post("create-dc-1", `{"name":"DC1"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, first.Code, first.Body.String())
+	}
+	var created model.Datacenter
+	json.Unmarshal(first.Body.Bytes(), &created)
+
+	// A retry gets the original response and creates nothing
+	retry := post("create-dc-1", `{"name":"DC1"}`)
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected a replayed %d, got %d (%q)", http.StatusCreated, retry.Code, retry.Header().Get("Idempotent-Replayed"))
+	}
+	var replayed model.Datacenter
+	json.Unmarshal(retry.Body.Bytes(), &replayed)
+	if replayed.ID != created.ID || retry.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected the original datacenter %s, got %s", created.ID, replayed.ID)
+	}
+	if n := countDatacenters() - existing; n != 1 {
+		t.Errorf("expected 1 datacenter created with the retry, got %d", n)
+	}
+
+	if w := post("create-dc-1", `{"name":"DC2"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected %d reusing a key for another request, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+	if w := post("bad key!", `{"name":"DC2"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for an invalid key, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// Without a key requests are not deduplicated
+	post("", `{"name":"DC3"}`)
+	post("", `{"name":"DC3"}`)
+	if n := countDatacenters() - existing; n != 3 {
+		t.Errorf("expected 3 datacenters created, got %d", n)
+	}
+
+	// Keys belong to their user
+	_, token := createAPIUserForStore(t, store, "idempotency-user")
+	req := authReqWithToken(httptest.NewRequest("POST", "/api/datacenters", bytes.NewBufferString(`{"name":"DC1"}`)), token)
+	req.Header.Set("Idempotency-Key", "create-dc-1")
+	if w := performRequest(mux, req); w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("expected another user's key not to replay")
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/martinsuchenak/rackd/internal/export"
+	"github.com/martinsuchenak/rackd/internal/qrcode"
+)
+
+// deviceURL returns the web UI link to a device, based on PUBLIC_URL or the
+// host the request was made to and the UI base path
+func (h *Handler) deviceURL(r *http.Request, id string) string {
+	base := h.publicURL
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil || (h.trustProxy && r.Header.Get("X-Forwarded-Proto") == "https") {
+			scheme = "https"
+		}
+		base = scheme + "://" + r.Host + h.uiBasePath
+	}
+	return base + "/devices/detail?id=" + url.QueryEscape(id)
+}
+
+// getDeviceQRCode returns a QR code linking to the device, as PNG or SVG
+func (h *Handler) getDeviceQRCode(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "png" && format != "svg" {
+		h.badRequest(w, "format must be one of png or svg")
+		return
+	}
+	scale := 8
+	if v := r.URL.Query().Get("scale"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 40 {
+			h.badRequest(w, "scale must be between 1 and 40")
+			return
+		}
+		scale = n
+	}
+
+	device, err := h.svc.Devices.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	code, err := qrcode.Encode(h.deviceURL(r, device.ID))
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if format == "svg" {
+		contentType = "image/svg+xml"
+		err = code.WriteSVG(&buf, scale)
+	} else {
+		err = code.WritePNG(&buf, scale)
+	}
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// getDeviceLabel returns a printable SVG asset label for the device
+func (h *Handler) getDeviceLabel(w http.ResponseWriter, r *http.Request) {
+	assetField := r.URL.Query().Get("asset_field")
+	if assetField == "" {
+		assetField = "asset_tag"
+	}
+
+	label, err := h.svc.Devices.Label(r.Context(), r.PathValue("id"), assetField)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteDeviceLabelSVG(label, h.deviceURL(r, label.DeviceID), &buf); err != nil {
+		h.internalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
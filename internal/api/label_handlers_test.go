@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestDeviceQRCodeAndLabelHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	dc := &model.Datacenter{Name: "Frankfurt"}
+	if err := store.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("failed to create datacenter: %v", err)
+	}
+	field := &model.CustomFieldDefinition{Name: "Asset tag", Key: "asset_tag", Type: model.CustomFieldTypeText}
+	if err := store.CreateCustomFieldDefinition(ctx, field); err != nil {
+		t.Fatalf("failed to create custom field: %v", err)
+	}
+	device := &model.Device{
+		Name:         "web01",
+		DatacenterID: dc.ID,
+		Location:     "Rack 4, U12",
+		CustomFields: []model.CustomFieldValueInput{{FieldID: field.ID, Value: "A-1042"}},
+	}
+	if err := store.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("failed to create device: %v", err)
+	}
+
+	w := performRequest(mux, authReq(httptest.NewRequest("GET", "/api/devices/"+device.ID+"/qrcode?scale=2", nil)))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expected a PNG, got %d %s: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if _, err := png.Decode(w.Body); err != nil {
+		t.Errorf("invalid PNG: %v", err)
+	}
+
+	w = performRequest(mux, authReq(httptest.NewRequest("GET", "/api/devices/"+device.ID+"/qrcode?format=svg", nil)))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "<svg") {
+		t.Errorf("expected an SVG, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, target := range []string{"/qrcode?format=gif", "/qrcode?scale=0"} {
+		w = performRequest(mux, authReq(httptest.NewRequest("GET", "/api/devices/"+device.ID+target, nil)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", target, w.Code)
+		}
+	}
+	w = performRequest(mux, authReq(httptest.NewRequest("GET", "/api/devices/missing/qrcode", nil)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown device, got %d", w.Code)
+	}
+
+	w = performRequest(mux, authReq(httptest.NewRequest("GET", "/api/devices/"+device.ID+"/label", nil)))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("expected an SVG label, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, want := range []string{">web01<", ">A-1042<", ">Frankfurt<", ">Rack 4, U12<"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("label missing %q", want)
+		}
+	}
+
+	w = performRequest(mux, authReq(httptest.NewRequest("GET", "/api/devices/"+device.ID+"/label?asset_field=serial", nil)))
+	if strings.Contains(w.Body.String(), "A-1042") {
+		t.Error("expected no asset tag for an unknown field")
+	}
+}
+
+func TestDeviceURL(t *testing.T) {
+	h := &Handler{}
+	r := httptest.NewRequest("GET", "/api/devices/dev-1/qrcode", nil)
+	r.Host = "rackd.internal:8080"
+	if got := h.deviceURL(r, "dev-1"); got != "http://rackd.internal:8080/devices/detail?id=dev-1" {
+		t.Errorf("deviceURL = %q", got)
+	}
+
+	// The forwarded scheme is only trusted behind a proxy
+	r.Header.Set("X-Forwarded-Proto", "https")
+	if got := h.deviceURL(r, "dev-1"); !strings.HasPrefix(got, "http://") {
+		t.Errorf("expected X-Forwarded-Proto to be ignored, got %q", got)
+	}
+	h.trustProxy = true
+	if got := h.deviceURL(r, "dev-1"); !strings.HasPrefix(got, "https://") {
+		t.Errorf("expected X-Forwarded-Proto to be used, got %q", got)
+	}
+
+	h.publicURL = "https://rackd.example.com"
+	if got := h.deviceURL(r, "dev-1"); got != "https://rackd.example.com/devices/detail?id=dev-1" {
+		t.Errorf("deviceURL = %q", got)
+	}
+}
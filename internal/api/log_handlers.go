@@ -1,6 +1,8 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -86,3 +88,66 @@ func (h *Handler) exportLogs(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Write(data)
 }
+
+// logStreamKeepAlive is how often an idle log stream sends a comment so
+// proxies do not close the connection
+const logStreamKeepAlive = 15 * time.Second
+
+// streamLogs serves GET /api/admin/logs/stream, a Server-Sent Events stream
+// of recent log entries followed by new ones as they are logged
+func (h *Handler) streamLogs(w http.ResponseWriter, r *http.Request) {
+	backlog := parseIntParam(r, "backlog", model.DefaultPageSize)
+	if backlog < 0 || backlog > model.MaxPageSize {
+		h.badRequest(w, fmt.Sprintf("backlog must be between 0 and %d", model.MaxPageSize))
+		return
+	}
+
+	recent, entries, cancel, err := h.svc.Logs.Stream(r.Context(), strings.ToLower(r.URL.Query().Get("level")), backlog)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	defer cancel()
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sent := make(map[string]bool, len(recent))
+	for _, entry := range recent {
+		writeLogEvent(w, entry)
+		sent[entry.ID] = true
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(logStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry := <-entries:
+			if sent[entry.ID] {
+				continue
+			}
+			writeLogEvent(w, entry)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeLogEvent(w http.ResponseWriter, entry model.LogEntry) {
+	data, _ := json.Marshal(entry)
+	fmt.Fprintf(w, "id: %s\nevent: log\ndata: %s\n\n", entry.ID, data)
+}
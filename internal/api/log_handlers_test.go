@@ -1,11 +1,15 @@
 package api
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	appLog "github.com/martinsuchenak/rackd/internal/log"
 )
@@ -51,3 +55,64 @@ func TestLogHandlers_ListGetAndExport(t *testing.T) {
 		t.Fatalf("expected CSV export to include test entry, got %s", w.Body.String())
 	}
 }
+
+func TestLogHandlers_Stream(t *testing.T) {
+	env := setupExtendedTestHandler(t, false, false, false, false)
+	defer env.close()
+
+	appLog.Init("console", "debug", io.Discard)
+	appLog.Info("stream-info-entry")
+	appLog.Warn("stream-backlog-entry", "component", "webhook")
+
+	srv := httptest.NewServer(env.mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := authReq(httptest.NewRequestWithContext(ctx, "GET", srv.URL+"/api/admin/logs/stream?level=warn", nil))
+	req.RequestURI = ""
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	events := bufio.NewScanner(resp.Body)
+	nextData := func() string {
+		t.Helper()
+		for events.Scan() {
+			if line := events.Text(); strings.HasPrefix(line, "data: ") {
+				return line
+			}
+		}
+		t.Fatalf("stream ended: %v", events.Err())
+		return ""
+	}
+
+	if data := nextData(); !strings.Contains(data, "stream-backlog-entry") {
+		t.Fatalf("expected backlog warn entry first, got %s", data)
+	}
+
+	appLog.Info("stream-live-info")
+	appLog.Error("stream-live-error")
+	if data := nextData(); !strings.Contains(data, "stream-live-error") {
+		t.Fatalf("expected live error entry, got %s", data)
+	}
+
+	_, token := env.createAPIUser(t, "log-viewer")
+	w := performRequest(env.mux, authReqWithToken(httptest.NewRequest("GET", "/api/admin/logs/stream", nil), token))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected non-admin stream to be forbidden, got %d", w.Code)
+	}
+
+	w = performRequest(env.mux, authReq(httptest.NewRequest("GET", "/api/admin/logs/stream?level=loud", nil)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid level to be rejected, got %d", w.Code)
+	}
+}
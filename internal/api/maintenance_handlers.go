@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func (h *Handler) listMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	filter := &model.MaintenanceWindowFilter{
+		Pagination: parsePagination(r),
+		DeviceID:   r.URL.Query().Get("device_id"),
+		Tags:       parseArrayParam(r, "tag"),
+		Status:     r.URL.Query().Get("status"),
+	}
+	windows, err := h.svc.Maintenance.List(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, windows)
+}
+
+type maintenanceWindowRequest struct {
+	DeviceID    string    `json:"device_id"`
+	Tag         string    `json:"tag"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Description string    `json:"description"`
+}
+
+func (req *maintenanceWindowRequest) apply(window *model.MaintenanceWindow) {
+	window.DeviceID = req.DeviceID
+	window.Tag = req.Tag
+	window.StartsAt = req.StartsAt
+	window.EndsAt = req.EndsAt
+	window.Description = req.Description
+}
+
+func (h *Handler) createMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	var req maintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	window := &model.MaintenanceWindow{}
+	req.apply(window)
+	if err := h.svc.Maintenance.Create(r.Context(), window); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, window)
+}
+
+func (h *Handler) getMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	window, err := h.svc.Maintenance.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, window)
+}
+
+func (h *Handler) updateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	existing, err := h.svc.Maintenance.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	var req maintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+	req.apply(existing)
+	if err := h.svc.Maintenance.Update(r.Context(), existing); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, existing)
+}
+
+func (h *Handler) deleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Maintenance.Delete(r.Context(), r.PathValue("id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestMaintenanceHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	device := &model.Device{Name: "web01", Addresses: []model.Address{{IP: "10.0.0.10", Type: "ipv4"}}}
+	if err := store.CreateDevice(context.Background(), device); err != nil {
+		t.Fatalf("failed to create device: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var window model.MaintenanceWindow
+
+	t.Run("Create", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{
+			"device_id": device.ID, "description": "Firmware upgrade",
+			"starts_at": now.Add(-time.Hour), "ends_at": now.Add(time.Hour),
+		})
+		req := authReq(httptest.NewRequest("POST", "/api/maintenance", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &window); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if window.ID == "" || window.Status != model.MaintenanceActive {
+			t.Errorf("expected active window, got %+v", window)
+		}
+	})
+
+	t.Run("Create_Invalid", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{"device_id": device.ID, "starts_at": now, "ends_at": now.Add(-time.Hour)})
+		req := authReq(httptest.NewRequest("POST", "/api/maintenance", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		for query, want := range map[string]int{"?status=active": 1, "?status=upcoming": 0, "?device_id=" + device.ID: 1, "?tag=rack-a": 0} {
+			req := authReq(httptest.NewRequest("GET", "/api/maintenance"+query, nil))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: expected %d, got %d: %s", query, http.StatusOK, w.Code, w.Body.String())
+			}
+			var windows []model.MaintenanceWindow
+			json.Unmarshal(w.Body.Bytes(), &windows)
+			if len(windows) != want {
+				t.Errorf("%s: expected %d windows, got %d", query, want, len(windows))
+			}
+		}
+	})
+
+	t.Run("List_InvalidStatus", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/maintenance?status=soon", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("DeviceIncludesMaintenance", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/devices/"+device.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var got model.Device
+		json.Unmarshal(w.Body.Bytes(), &got)
+		if len(got.Maintenance) != 1 || got.Maintenance[0].ID != window.ID {
+			t.Errorf("expected maintenance window in device response, got %+v", got.Maintenance)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{
+			"device_id": device.ID, "description": "Firmware upgrade",
+			"starts_at": now.Add(time.Hour), "ends_at": now.Add(2 * time.Hour),
+		})
+		req := authReq(httptest.NewRequest("PUT", "/api/maintenance/"+window.ID, bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var updated model.MaintenanceWindow
+		json.Unmarshal(w.Body.Bytes(), &updated)
+		if updated.Status != model.MaintenanceUpcoming {
+			t.Errorf("expected upcoming window, got %+v", updated)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("DELETE", "/api/maintenance/"+window.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+
+		req = authReq(httptest.NewRequest("GET", "/api/maintenance/"+window.ID, nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d after delete, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/martinsuchenak/rackd/internal/auth"
 	"github.com/martinsuchenak/rackd/internal/log"
 	"github.com/martinsuchenak/rackd/internal/metrics"
@@ -29,17 +30,24 @@ const (
 	sessionCookieName = "rackd_session"
 )
 
-// LoggingMiddleware logs all HTTP requests and records metrics
+// RequestIDHeader carries the ID that correlates a request with its log lines
+const RequestIDHeader = "X-Request-ID"
+
+// LoggingMiddleware assigns each request an ID, writes an access log line
+// for it and records metrics. A well-formed X-Request-ID from the client, such
+// as one set by a proxy, is kept; otherwise a new ID is generated. The ID is
+// returned in the response header and carried in the request context for
+// log.Ctx.
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		log.Debug("HTTP request started",
-			"method", r.Method,
-			"path", r.URL.Path,
-			"query", r.URL.RawQuery,
-			"remote_addr", r.RemoteAddr,
-		)
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		r = r.WithContext(log.WithRequestID(r.Context(), requestID))
 
 		// Wrap response writer to capture status code
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
@@ -47,11 +55,14 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(wrapped, r)
 
 		duration := time.Since(start)
-		log.Debug("HTTP request completed",
+		log.Info("HTTP request",
+			"request_id", requestID,
 			"method", r.Method,
 			"path", r.URL.Path,
+			"query", r.URL.RawQuery,
 			"status", wrapped.statusCode,
 			"duration_ms", duration.Milliseconds(),
+			"remote_addr", r.RemoteAddr,
 		)
 
 		// Record metrics
@@ -59,6 +70,20 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// validRequestID reports whether id is safe to reuse from a client: short and
+// limited to characters that can't forge log fields
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
+	}
+	return true
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -69,6 +94,12 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer to flush
+// streaming responses
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // API key authentication errors
 var (
 	ErrAuthInvalidToken = fmt.Errorf("invalid token")
@@ -271,3 +271,30 @@ func TestLimitBody(t *testing.T) {
 		t.Errorf("expected status %d for large body, got %d", http.StatusRequestEntityTooLarge, w.Code)
 	}
 }
+
+func TestLoggingMiddlewareRequestID(t *testing.T) {
+	var seen string
+	handler := LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = log.RequestID(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/devices", nil))
+	if seen == "" || w.Header().Get(RequestIDHeader) != seen {
+		t.Fatalf("expected a generated request ID in the context and response, got %q and %q", seen, w.Header().Get(RequestIDHeader))
+	}
+
+	for id, keep := range map[string]bool{
+		"proxy-1234.abc":          true,
+		"bad id\nlevel=error":     false,
+		string(make([]byte, 200)): false,
+	} {
+		req := httptest.NewRequest("GET", "/api/devices", nil)
+		req.Header.Set(RequestIDHeader, id)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if (seen == id) != keep || w.Header().Get(RequestIDHeader) != seen {
+			t.Errorf("request ID %q: got %q, keep=%v", id, seen, keep)
+		}
+	}
+}
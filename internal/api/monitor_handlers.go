@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func (h *Handler) listMonitorChecks(w http.ResponseWriter, r *http.Request) {
+	checks, err := h.svc.Monitor.ListChecks(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, checks)
+}
+
+type monitorCheckRequest struct {
+	Name            string `json:"name"`
+	DeviceID        string `json:"device_id"`
+	Tag             string `json:"tag"`
+	Type            string `json:"type"`
+	Port            int    `json:"port"`
+	IntervalSeconds int    `json:"interval_seconds"`
+	TimeoutSeconds  int    `json:"timeout_seconds"`
+	Enabled         *bool  `json:"enabled"`
+}
+
+func (req *monitorCheckRequest) apply(check *model.MonitorCheck) {
+	check.Name = req.Name
+	check.DeviceID = req.DeviceID
+	check.Tag = req.Tag
+	check.Type = req.Type
+	check.Port = req.Port
+	check.IntervalSeconds = req.IntervalSeconds
+	check.TimeoutSeconds = req.TimeoutSeconds
+	if req.Enabled != nil {
+		check.Enabled = *req.Enabled
+	}
+}
+
+func (h *Handler) createMonitorCheck(w http.ResponseWriter, r *http.Request) {
+	var req monitorCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	check := &model.MonitorCheck{Enabled: true}
+	req.apply(check)
+	if err := h.svc.Monitor.CreateCheck(r.Context(), check); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, check)
+}
+
+func (h *Handler) getMonitorCheck(w http.ResponseWriter, r *http.Request) {
+	check, err := h.svc.Monitor.GetCheck(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, check)
+}
+
+func (h *Handler) updateMonitorCheck(w http.ResponseWriter, r *http.Request) {
+	existing, err := h.svc.Monitor.GetCheck(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	var req monitorCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+	req.apply(existing)
+	if err := h.svc.Monitor.UpdateCheck(r.Context(), existing); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, existing)
+}
+
+func (h *Handler) deleteMonitorCheck(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Monitor.DeleteCheck(r.Context(), r.PathValue("id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getDeviceStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.svc.Monitor.DeviceStatus(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, status)
+}
+
+func (h *Handler) getStatusSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.svc.Monitor.Summary(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, summary)
+}
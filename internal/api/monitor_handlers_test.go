@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestMonitorHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	device := &model.Device{Name: "web01", Addresses: []model.Address{{IP: "10.0.0.10", Type: "ipv4"}}}
+	if err := store.CreateDevice(context.Background(), device); err != nil {
+		t.Fatalf("failed to create device: %v", err)
+	}
+
+	var check model.MonitorCheck
+
+	t.Run("CreateCheck", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{"name": "ping", "device_id": device.ID, "type": "icmp"})
+		req := authReq(httptest.NewRequest("POST", "/api/monitor/checks", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &check); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if check.ID == "" || !check.Enabled || check.IntervalSeconds != 60 {
+			t.Errorf("expected enabled check with default interval, got %+v", check)
+		}
+	})
+
+	t.Run("CreateCheck_Invalid", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{"name": "ssh", "device_id": device.ID, "type": "tcp"})
+		req := authReq(httptest.NewRequest("POST", "/api/monitor/checks", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("UpdateCheck", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{"name": "ping", "device_id": device.ID, "type": "icmp", "enabled": false})
+		req := authReq(httptest.NewRequest("PUT", "/api/monitor/checks/"+check.ID, bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var updated model.MonitorCheck
+		json.Unmarshal(w.Body.Bytes(), &updated)
+		if updated.Enabled || updated.IntervalSeconds != 60 {
+			t.Errorf("expected disabled check, got %+v", updated)
+		}
+	})
+
+	t.Run("StatusSummary", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/status", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var summary model.MonitorSummary
+		if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if summary.Total != 0 {
+			t.Errorf("expected no monitored devices while the check is disabled, got %+v", summary)
+		}
+	})
+
+	now := time.Now().UTC()
+	if err := store.SaveMonitorResult(context.Background(), &model.MonitorResult{
+		CheckID: check.ID, DeviceID: device.ID, IP: "10.0.0.10", Status: model.MonitorStatusDown,
+		LastCheckedAt: now, LastChangeAt: now,
+	}); err != nil {
+		t.Fatalf("failed to save result: %v", err)
+	}
+
+	t.Run("DeviceStatus", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/devices/"+device.ID+"/status", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var status model.DeviceMonitoring
+		json.Unmarshal(w.Body.Bytes(), &status)
+		if status.Status != model.MonitorStatusDown || len(status.Checks) != 1 {
+			t.Errorf("expected down status with one check, got %+v", status)
+		}
+	})
+
+	t.Run("DeviceStatus_NotFound", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/devices/missing/status", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("DeviceIncludesMonitoring", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/devices/"+device.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var got model.Device
+		json.Unmarshal(w.Body.Bytes(), &got)
+		if got.Monitoring == nil || got.Monitoring.Status != model.MonitorStatusDown {
+			t.Errorf("expected monitoring in device response, got %+v", got.Monitoring)
+		}
+	})
+
+	t.Run("DeleteCheck", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("DELETE", "/api/monitor/checks/"+check.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+
+		req = authReq(httptest.NewRequest("GET", "/api/monitor/checks/"+check.ID, nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d after delete, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
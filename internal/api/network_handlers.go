@@ -9,12 +9,36 @@ import (
 
 func (h *Handler) listNetworks(w http.ResponseWriter, r *http.Request) {
 	filter := &model.NetworkFilter{
+		Pagination:   parsePagination(r),
 		Name:         r.URL.Query().Get("name"),
 		DatacenterID: r.URL.Query().Get("datacenter_id"),
+		ParentID:     r.URL.Query().Get("parent_id"),
 		VLANID:       parseIntParam(r, "vlan_id", 0),
 	}
 
-	networks, err := h.svc.Networks.List(r.Context(), filter)
+	asOf, ok := h.parseAsOf(w, r)
+	if !ok {
+		return
+	}
+
+	if asOf == nil {
+		changedAt, err := h.svc.Networks.ChangedAt(r.Context())
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		if !checkModifiedSince(w, r, changedAt) {
+			return
+		}
+	}
+
+	var networks []model.Network
+	var err error
+	if asOf != nil {
+		networks, err = h.svc.Networks.ListAsOf(r.Context(), *asOf, filter)
+	} else {
+		networks, err = h.svc.Networks.List(r.Context(), filter)
+	}
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
@@ -29,10 +53,15 @@ func (h *Handler) createNetwork(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.Networks.Create(r.Context(), &network); err != nil {
+	ctx, dryRun := writeContext(r)
+	if err := h.svc.Networks.Create(ctx, &network); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
+	if dryRun {
+		h.writeJSON(w, http.StatusOK, network)
+		return
+	}
 	h.writeJSON(w, http.StatusCreated, network)
 }
 
@@ -43,14 +72,38 @@ func (h *Handler) getNetwork(w http.ResponseWriter, r *http.Request) {
 		h.badRequest(w, "ID is required")
 		return
 	}
-	network, err := h.svc.Networks.Get(r.Context(), id)
+	asOf, ok := h.parseAsOf(w, r)
+	if !ok {
+		return
+	}
+
+	var network *model.Network
+	var err error
+	if asOf != nil {
+		network, err = h.svc.Networks.GetAsOf(r.Context(), id, *asOf)
+	} else {
+		network, err = h.svc.Networks.Get(r.Context(), id)
+	}
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
+	// A past version cannot be updated, so only the current one gets an ETag
+	if asOf == nil {
+		setETag(w, network.UpdatedAt)
+	}
 	h.writeJSON(w, http.StatusOK, network)
 }
 
+func (h *Handler) getNetworkHistory(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.svc.Networks.History(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, versions)
+}
+
 func (h *Handler) updateNetwork(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
@@ -63,6 +116,11 @@ func (h *Handler) updateNetwork(w http.ResponseWriter, r *http.Request) {
 		h.handleServiceError(w, err)
 		return
 	}
+	ctx, ok := h.checkIfMatch(w, r, network.UpdatedAt)
+	if !ok {
+		return
+	}
+	r = r.WithContext(ctx)
 
 	var updates map[string]any
 	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
@@ -79,6 +137,15 @@ func (h *Handler) updateNetwork(w http.ResponseWriter, r *http.Request) {
 	if vlanID, ok := updates["vlan_id"].(float64); ok {
 		network.VLANID = int(vlanID)
 	}
+	if gateway, ok := updates["gateway"].(string); ok {
+		network.Gateway = gateway
+	}
+	if servers, ok := updates["dns_servers"].([]any); ok {
+		network.DNSServers = toStringSlice(servers)
+	}
+	if mtu, ok := updates["mtu"].(float64); ok {
+		network.MTU = int(mtu)
+	}
 	if datacenterID, ok := updates["datacenter_id"].(string); ok {
 		network.DatacenterID = datacenterID
 	}
@@ -86,10 +153,14 @@ func (h *Handler) updateNetwork(w http.ResponseWriter, r *http.Request) {
 		network.Description = description
 	}
 
-	if err := h.svc.Networks.Update(r.Context(), network); err != nil {
+	ctx, dryRun := writeContext(r)
+	if err := h.svc.Networks.Update(ctx, network); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
+	if !dryRun {
+		setETag(w, network.UpdatedAt)
+	}
 	h.writeJSON(w, http.StatusOK, network)
 }
 
@@ -100,7 +171,11 @@ func (h *Handler) deleteNetwork(w http.ResponseWriter, r *http.Request) {
 		h.badRequest(w, "ID is required")
 		return
 	}
-	if err := h.svc.Networks.Delete(r.Context(), id); err != nil {
+	policy, ok := h.parseDeletePolicy(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Networks.Delete(r.Context(), id, policy); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
@@ -114,12 +189,52 @@ func (h *Handler) getNetworkDevices(w http.ResponseWriter, r *http.Request) {
 		h.badRequest(w, "ID is required")
 		return
 	}
+	view, ok := h.parseDeviceView(w, r)
+	if !ok {
+		return
+	}
 	devices, err := h.svc.Networks.GetDevices(r.Context(), id)
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
-	h.writeJSON(w, http.StatusOK, devices)
+	h.writeDevices(w, r, view, devices)
+}
+
+func (h *Handler) getNetworkChildren(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		h.badRequest(w, "ID is required")
+		return
+	}
+	children, err := h.svc.Networks.Children(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, children)
+}
+
+func (h *Handler) allocateSubnet(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		h.badRequest(w, "ID is required")
+		return
+	}
+	var req model.AllocateSubnetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	network, err := h.svc.Networks.AllocateSubnet(r.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, network)
 }
 
 func (h *Handler) getNetworkUtilization(w http.ResponseWriter, r *http.Request) {
@@ -144,7 +259,18 @@ func (h *Handler) listNetworkPools(w http.ResponseWriter, r *http.Request) {
 		h.badRequest(w, "ID is required")
 		return
 	}
-	pools, err := h.svc.Pools.ListByNetwork(r.Context(), id)
+	asOf, ok := h.parseAsOf(w, r)
+	if !ok {
+		return
+	}
+
+	var pools []model.NetworkPool
+	var err error
+	if asOf != nil {
+		pools, err = h.svc.Pools.ListByNetworkAsOf(r.Context(), id, *asOf)
+	} else {
+		pools, err = h.svc.Pools.ListByNetwork(r.Context(), id)
+	}
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
@@ -166,10 +292,15 @@ func (h *Handler) createNetworkPool(w http.ResponseWriter, r *http.Request) {
 	}
 	pool.NetworkID = networkID
 
-	if err := h.svc.Pools.Create(r.Context(), &pool); err != nil {
+	ctx, dryRun := writeContext(r)
+	if err := h.svc.Pools.Create(ctx, &pool); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
+	if dryRun {
+		h.writeJSON(w, http.StatusOK, pool)
+		return
+	}
 	h.writeJSON(w, http.StatusCreated, pool)
 }
 
@@ -180,7 +311,18 @@ func (h *Handler) getNetworkPool(w http.ResponseWriter, r *http.Request) {
 		h.badRequest(w, "ID is required")
 		return
 	}
-	pool, err := h.svc.Pools.Get(r.Context(), id)
+	asOf, ok := h.parseAsOf(w, r)
+	if !ok {
+		return
+	}
+
+	var pool *model.NetworkPool
+	var err error
+	if asOf != nil {
+		pool, err = h.svc.Pools.GetAsOf(r.Context(), id, *asOf)
+	} else {
+		pool, err = h.svc.Pools.Get(r.Context(), id)
+	}
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
@@ -188,6 +330,15 @@ func (h *Handler) getNetworkPool(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, pool)
 }
 
+func (h *Handler) getPoolHistory(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.svc.Pools.History(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, versions)
+}
+
 func (h *Handler) updateNetworkPool(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
@@ -228,7 +379,8 @@ func (h *Handler) updateNetworkPool(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := h.svc.Pools.Update(r.Context(), pool); err != nil {
+	ctx, _ := writeContext(r)
+	if err := h.svc.Pools.Update(ctx, pool); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
@@ -242,7 +394,11 @@ func (h *Handler) deleteNetworkPool(w http.ResponseWriter, r *http.Request) {
 		h.badRequest(w, "ID is required")
 		return
 	}
-	if err := h.svc.Pools.Delete(r.Context(), id); err != nil {
+	policy, ok := h.parseDeletePolicy(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Pools.Delete(r.Context(), id, policy); err != nil {
 		h.handleServiceError(w, err)
 		return
 	}
@@ -279,6 +435,36 @@ func (h *Handler) getPoolHeatmap(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, heatmap)
 }
 
+func (h *Handler) getPoolStats(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		h.badRequest(w, "ID is required")
+		return
+	}
+	stats, err := h.svc.Pools.GetStats(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, stats)
+}
+
+func (h *Handler) getNetworkPoolStats(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		h.badRequest(w, "ID is required")
+		return
+	}
+	summary, err := h.svc.Pools.NetworkStats(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, summary)
+}
+
 func (h *Handler) searchNetworks(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
@@ -2,10 +2,15 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
 )
 
 func TestNetworkHandlers(t *testing.T) {
@@ -134,6 +139,28 @@ func TestNetworkHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateNetwork_Settings", func(t *testing.T) {
+		body := `{"gateway":"192.168.0.1","dns_servers":["192.168.0.53","192.168.0.54"],"mtu":9000}`
+		req := authReq(httptest.NewRequest("PUT", "/api/networks/"+netID, bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var network model.Network
+		json.Unmarshal(w.Body.Bytes(), &network)
+		if network.Gateway != "192.168.0.1" || len(network.DNSServers) != 2 || network.MTU != 9000 || network.VLANID != 201 {
+			t.Errorf("unexpected network: %+v", network)
+		}
+
+		req = authReq(httptest.NewRequest("PUT", "/api/networks/"+netID, bytes.NewBufferString(`{"gateway":"10.0.0.1"}`)))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d for a gateway outside the subnet, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
 	t.Run("UpdateNetwork_NotFound", func(t *testing.T) {
 		body := `{"name":"Updated"}`
 		req := authReq(httptest.NewRequest("PUT", "/api/networks/nonexistent", bytes.NewBufferString(body)))
@@ -155,6 +182,10 @@ func TestNetworkHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateNetwork_IfMatch", func(t *testing.T) {
+		testIfMatch(t, mux, "/api/networks/"+netID, `{"description":"edited"}`)
+	})
+
 	t.Run("GetNetworkDevices", func(t *testing.T) {
 		req := authReq(httptest.NewRequest("GET", "/api/networks/"+netID+"/devices", nil))
 		w := httptest.NewRecorder()
@@ -175,6 +206,96 @@ func TestNetworkHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("GetNetworkChildren", func(t *testing.T) {
+		body := `{"name":"Net2-child","subnet":"192.168.0.128/25"}`
+		req := authReq(httptest.NewRequest("POST", "/api/networks", bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		var child model.Network
+		json.NewDecoder(w.Body).Decode(&child)
+		defer store.DeleteNetwork(context.Background(), child.ID, model.DeleteDetach)
+		if child.ParentID != netID {
+			t.Errorf("expected parent_id %s, got %q", netID, child.ParentID)
+		}
+
+		req = authReq(httptest.NewRequest("GET", "/api/networks/"+netID+"/children", nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var children []model.Network
+		json.NewDecoder(w.Body).Decode(&children)
+		if len(children) != 1 || children[0].ID != child.ID {
+			t.Errorf("expected the new network as the only child, got %+v", children)
+		}
+	})
+
+	t.Run("GetNetworkChildren_NotFound", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/networks/nonexistent/children", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("AllocateSubnet", func(t *testing.T) {
+		body := `{"prefix_length":26,"name":"Net2-alloc"}`
+		req := authReq(httptest.NewRequest("POST", "/api/networks/"+netID+"/allocate-subnet", bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		var network model.Network
+		json.NewDecoder(w.Body).Decode(&network)
+		defer store.DeleteNetwork(context.Background(), network.ID, model.DeleteDetach)
+		if network.Subnet != "192.168.0.0/26" || network.ParentID != netID {
+			t.Errorf("expected 192.168.0.0/26 under %s, got %s under %q", netID, network.Subnet, network.ParentID)
+		}
+
+		body = `{"prefix_length":24}`
+		req = authReq(httptest.NewRequest("POST", "/api/networks/"+netID+"/allocate-subnet", bytes.NewBufferString(body)))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d for a prefix that does not fit, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+
+		body = `{"prefix_length":25}`
+		req = authReq(httptest.NewRequest("POST", "/api/networks/"+netID+"/allocate-subnet", bytes.NewBufferString(body)))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		json.NewDecoder(w.Body).Decode(&network)
+		defer store.DeleteNetwork(context.Background(), network.ID, model.DeleteDetach)
+
+		req = authReq(httptest.NewRequest("POST", "/api/networks/"+netID+"/allocate-subnet", bytes.NewBufferString(body)))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected %d once the network is full, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("CreateNetwork_DuplicateSubnet", func(t *testing.T) {
+		body := `{"name":"Net1-dup","subnet":"10.0.0.0/24"}`
+		req := authReq(httptest.NewRequest("POST", "/api/networks", bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
 	t.Run("GetNetworkUtilization", func(t *testing.T) {
 		req := authReq(httptest.NewRequest("GET", "/api/networks/"+netID+"/utilization", nil))
 		w := httptest.NewRecorder()
@@ -368,6 +489,62 @@ func TestNetworkHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("GetPoolStats", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/pools/"+poolID+"/stats", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var stats model.PoolStats
+		if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("failed to decode stats: %v", err)
+		}
+		if stats.Total != 41 || stats.Free != 41 {
+			t.Errorf("expected 41 free IPs, got %+v", stats)
+		}
+	})
+
+	t.Run("GetPoolStats_NotFound", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/pools/nonexistent/stats", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("GetNetworkPoolStats", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/networks/"+netID+"/pools/stats", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var summary model.PoolStatsSummary
+		if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+			t.Fatalf("failed to decode summary: %v", err)
+		}
+		if summary.PoolCount != 1 || summary.IPv4.Total != 41 {
+			t.Errorf("expected one pool of 41 IPs, got %+v", summary)
+		}
+	})
+
+	t.Run("GetPoolStats_NetworkAndDatacenterNotFound", func(t *testing.T) {
+		for _, path := range []string{"/api/networks/nonexistent/pools/stats", "/api/datacenters/nonexistent/pools/stats"} {
+			req := authReq(httptest.NewRequest("GET", path, nil))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("%s: expected %d, got %d", path, http.StatusNotFound, w.Code)
+			}
+		}
+	})
+
 	t.Run("DeleteNetworkPool", func(t *testing.T) {
 		req := authReq(httptest.NewRequest("DELETE", "/api/pools/"+poolID, nil))
 		w := httptest.NewRecorder()
@@ -408,3 +585,119 @@ func TestNetworkHandlers(t *testing.T) {
 		}
 	})
 }
+
+func TestNetworkHistoryHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	network := &model.Network{Name: "office", Subnet: "10.1.0.0/24"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "dhcp", StartIP: "10.1.0.100", EndIP: "10.1.0.150"}
+	if err := store.CreateNetworkPool(ctx, pool); err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	asOf := url.QueryEscape(time.Now().UTC().Format(time.RFC3339Nano))
+	time.Sleep(2 * time.Millisecond)
+
+	network.Subnet = "10.1.0.0/23"
+	if err := store.UpdateNetwork(ctx, network); err != nil {
+		t.Fatalf("failed to update network: %v", err)
+	}
+	if err := store.DeleteNetworkPool(ctx, pool.ID, model.DeleteDetach); err != nil {
+		t.Fatalf("failed to delete pool: %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := authReq(httptest.NewRequest("GET", path, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("GetNetwork_AsOf", func(t *testing.T) {
+		w := get("/api/networks/" + network.ID + "?as_of=" + asOf)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var got model.Network
+		json.Unmarshal(w.Body.Bytes(), &got)
+		if got.Subnet != "10.1.0.0/24" {
+			t.Errorf("expected subnet before the change, got %s", got.Subnet)
+		}
+	})
+
+	t.Run("ListNetworks_AsOf", func(t *testing.T) {
+		w := get("/api/networks?as_of=" + asOf)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var got []model.Network
+		json.Unmarshal(w.Body.Bytes(), &got)
+		if len(got) != 1 || got[0].Subnet != "10.1.0.0/24" {
+			t.Errorf("expected one network with the old subnet, got %+v", got)
+		}
+	})
+
+	t.Run("ListNetworkPools_AsOf", func(t *testing.T) {
+		w := get("/api/networks/" + network.ID + "/pools?as_of=" + asOf)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var got []model.NetworkPool
+		json.Unmarshal(w.Body.Bytes(), &got)
+		if len(got) != 1 || got[0].ID != pool.ID {
+			t.Errorf("expected the deleted pool, got %+v", got)
+		}
+	})
+
+	t.Run("GetPool_AsOf", func(t *testing.T) {
+		if w := get("/api/pools/" + pool.ID); w.Code != http.StatusNotFound {
+			t.Errorf("expected %d for deleted pool, got %d", http.StatusNotFound, w.Code)
+		}
+		if w := get("/api/pools/" + pool.ID + "?as_of=" + asOf); w.Code != http.StatusOK {
+			t.Errorf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("AsOf_Invalid", func(t *testing.T) {
+		if w := get("/api/networks?as_of=yesterday"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("NetworkHistory", func(t *testing.T) {
+		w := get("/api/networks/" + network.ID + "/history")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var versions []model.ResourceVersion
+		json.Unmarshal(w.Body.Bytes(), &versions)
+		if len(versions) != 2 || versions[1].Action != model.VersionActionUpdate {
+			t.Errorf("expected create and update versions, got %+v", versions)
+		}
+	})
+
+	t.Run("PoolHistory", func(t *testing.T) {
+		w := get("/api/pools/" + pool.ID + "/history")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var versions []model.ResourceVersion
+		json.Unmarshal(w.Body.Bytes(), &versions)
+		if len(versions) != 2 || versions[1].Action != model.VersionActionDelete {
+			t.Errorf("expected create and delete versions, got %+v", versions)
+		}
+	})
+
+	t.Run("History_NotFound", func(t *testing.T) {
+		if w := get("/api/networks/missing/history"); w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// listNotificationChannels returns the notification channels
+func (h *Handler) listNotificationChannels(w http.ResponseWriter, r *http.Request) {
+	filter := &model.NotificationChannelFilter{}
+	if t := r.URL.Query().Get("type"); t != "" {
+		filter.Type = model.NotificationChannelType(t)
+	}
+	if activeStr := r.URL.Query().Get("active"); activeStr != "" {
+		active := activeStr == "true"
+		filter.Active = &active
+	}
+
+	channels, err := h.svc.Notifications.List(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, channels)
+}
+
+// createNotificationChannel creates a new notification channel
+func (h *Handler) createNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateNotificationChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	channel, err := h.svc.Notifications.Create(r.Context(), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, channel)
+}
+
+// getNotificationChannel returns a single notification channel by ID
+func (h *Handler) getNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	channel, err := h.svc.Notifications.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, channel)
+}
+
+// updateNotificationChannel updates an existing notification channel
+func (h *Handler) updateNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	var req model.UpdateNotificationChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	channel, err := h.svc.Notifications.Update(r.Context(), r.PathValue("id"), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, channel)
+}
+
+// deleteNotificationChannel deletes a notification channel
+func (h *Handler) deleteNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Notifications.Delete(r.Context(), r.PathValue("id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// testNotificationChannel sends a test notification to a channel
+func (h *Handler) testNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	result, err := h.svc.Notifications.Test(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestNotificationHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	var channel model.NotificationChannel
+
+	t.Run("Create", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{
+			"name": "ops", "type": "ntfy", "url": "https://ntfy.invalid/rackd", "secret": "tk_abc", "changes_only": true,
+		})
+		req := authReq(httptest.NewRequest("POST", "/api/notifications", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "tk_abc") {
+			t.Errorf("response leaks the secret: %s", w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &channel); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if channel.ID == "" || !channel.HasSecret || !channel.Active || !channel.ChangesOnly {
+			t.Errorf("unexpected channel %+v", channel)
+		}
+	})
+
+	t.Run("Create_Invalid", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{"name": "mail", "type": "email", "smtp_host": "mail.example.com"})
+		req := authReq(httptest.NewRequest("POST", "/api/notifications", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		for query, want := range map[string]int{"": 1, "?type=ntfy": 1, "?type=email": 0, "?active=false": 0} {
+			req := authReq(httptest.NewRequest("GET", "/api/notifications"+query, nil))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("%q: expected %d, got %d: %s", query, http.StatusOK, w.Code, w.Body.String())
+			}
+			var channels []model.NotificationChannel
+			json.Unmarshal(w.Body.Bytes(), &channels)
+			if len(channels) != want {
+				t.Errorf("%q: expected %d channels, got %d", query, want, len(channels))
+			}
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{"active": false})
+		req := authReq(httptest.NewRequest("PUT", "/api/notifications/"+channel.ID, bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var updated model.NotificationChannel
+		json.Unmarshal(w.Body.Bytes(), &updated)
+		if updated.Active || !updated.HasSecret {
+			t.Errorf("expected inactive channel keeping its secret, got %+v", updated)
+		}
+	})
+
+	t.Run("Test", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("POST", "/api/notifications/"+channel.ID+"/test", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var result model.NotificationTestResult
+		json.Unmarshal(w.Body.Bytes(), &result)
+		if result.Success || result.Error == "" {
+			t.Errorf("expected the unresolvable host to fail the test, got %+v", result)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("DELETE", "/api/notifications/"+channel.ID, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+
+		req = authReq(httptest.NewRequest("GET", "/api/notifications/"+channel.ID, nil))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d after delete, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
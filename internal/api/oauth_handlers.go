@@ -58,7 +58,7 @@ func (h *Handler) oauthAuthorize(w http.ResponseWriter, r *http.Request) {
 	// The SPA will then fetch this endpoint to get the consent data
 	if isBrowserNavigation {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		w.Write(ui.IndexHTML())
+		w.Write(ui.IndexHTML(ui.WithBasePath(h.uiBasePath)))
 		return
 	}
 
@@ -67,7 +67,7 @@ func (h *Handler) oauthAuthorize(w http.ResponseWriter, r *http.Request) {
 	if session == nil {
 		// Redirect to login with return URL
 		returnURL := r.URL.String()
-		http.Redirect(w, r, "/login?redirect="+url.QueryEscape(returnURL), http.StatusFound)
+		http.Redirect(w, r, h.uiBasePath+"/login?redirect="+url.QueryEscape(returnURL), http.StatusFound)
 		return
 	}
 
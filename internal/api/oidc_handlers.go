@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// oidcStateCookieName binds a login started in a browser to its callback. It
+// is SameSite=Lax because the callback is a cross-site navigation from the
+// provider.
+const oidcStateCookieName = "rackd_oidc_state"
+
+// oidcLogin starts an OIDC login by sending the browser to the provider.
+// GET /api/auth/oidc/login?redirect=/path
+func (h *Handler) oidcLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.svc.OIDC.StartLogin(r.Context(), safeRedirect(r.URL.Query().Get("redirect")))
+	if err != nil {
+		log.Error("Failed to start OIDC login", "error", err)
+		h.redirectLoginError(w, r, "Single sign-on is unavailable, please try again later")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    state,
+		Path:     "/api/auth/oidc",
+		HttpOnly: true,
+		Secure:   h.cookieSecure,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   600,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// oidcCallback completes an OIDC login and signs the user in.
+// GET /api/auth/oidc/callback?code=...&state=...
+func (h *Handler) oidcCallback(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    "",
+		Path:     "/api/auth/oidc",
+		HttpOnly: true,
+		Secure:   h.cookieSecure,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		log.Warn("OIDC provider returned an error", "error", e, "description", q.Get("error_description"))
+		h.redirectLoginError(w, r, "Sign-in was cancelled or refused by the identity provider")
+		return
+	}
+
+	state := q.Get("state")
+	cookie, err := r.Cookie(oidcStateCookieName)
+	if err != nil || state == "" || cookie.Value != state {
+		h.redirectLoginError(w, r, service.ErrOIDCInvalidState.Error())
+		return
+	}
+
+	result, redirect, err := h.svc.OIDC.CompleteLogin(r.Context(), state, q.Get("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCInvalidState), errors.Is(err, service.ErrOIDCNoRole), errors.Is(err, service.ErrOIDCUserExists):
+			h.redirectLoginError(w, r, err.Error())
+		case errors.Is(err, service.ErrUnauthenticated):
+			h.redirectLoginError(w, r, "Your account is disabled")
+		default:
+			log.Error("OIDC login failed", "error", err)
+			h.redirectLoginError(w, r, "Single sign-on failed, please try again")
+		}
+		return
+	}
+
+	log.Info("User logged in", "username", result.User.Username, "user_id", result.User.ID, "method", "oidc")
+	h.setSessionCookie(w, result.Session.Token)
+	http.Redirect(w, r, h.uiBasePath+redirect, http.StatusFound)
+}
+
+// redirectLoginError sends the browser back to the login page with message
+func (h *Handler) redirectLoginError(w http.ResponseWriter, r *http.Request, message string) {
+	http.Redirect(w, r, h.uiBasePath+"/login?"+url.Values{"error": {message}}.Encode(), http.StatusFound)
+}
+
+// safeRedirect returns path if it is a local path, else "/", so the login
+// redirect can't send users to another site
+func safeRedirect(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+	return path
+}
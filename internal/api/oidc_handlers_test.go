@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/martinsuchenak/rackd/internal/auth"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+func TestOIDCLoginFlow(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	// Fake provider: the token endpoint returns an ID token for whoever is
+	// set in claims
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	signingKey := jose.JSONWebKey{Key: key, KeyID: "key-1", Algorithm: string(jose.ES256), Use: "sig"}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: signingKey}, nil)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	var claims map[string]any
+	var idp *httptest.Server
+	idp = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 idp.URL,
+				"authorization_endpoint": idp.URL + "/authorize",
+				"token_endpoint":         idp.URL + "/token",
+				"jwks_uri":               idp.URL + "/jwks",
+			})
+		case "/jwks":
+			json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{signingKey.Public()}})
+		case "/token":
+			payload, _ := json.Marshal(claims)
+			jws, _ := signer.Sign(payload)
+			token, _ := jws.CompactSerialize()
+			json.NewEncoder(w).Encode(map[string]string{"id_token": token})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer idp.Close()
+
+	sm := auth.NewSessionManager(time.Hour, nil)
+	defer sm.Stop()
+	provider := auth.NewOIDCProvider(auth.OIDCConfig{
+		IssuerURL:   idp.URL,
+		ClientID:    "rackd",
+		RedirectURL: "http://rackd.test/api/auth/oidc/callback",
+	})
+	h.svc.OIDC = service.NewOIDCService(store, sm, provider, map[string][]string{"netops": {"operator"}}, "", false)
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	// login starts the flow and returns the state cookie and the callback URL
+	// the provider would send the browser to
+	login := func(t *testing.T, username string, groups []any) (*http.Cookie, string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/auth/oidc/login?redirect=/devices", nil))
+		if w.Code != http.StatusFound {
+			t.Fatalf("expected redirect to the provider, got %d: %s", w.Code, w.Body.String())
+		}
+		loc, _ := url.Parse(w.Header().Get("Location"))
+		if !strings.HasPrefix(loc.String(), idp.URL+"/authorize") {
+			t.Fatalf("unexpected provider redirect %s", loc)
+		}
+		claims = map[string]any{
+			"iss":                idp.URL,
+			"aud":                "rackd",
+			"sub":                "sub-" + username,
+			"exp":                float64(time.Now().Add(time.Hour).Unix()),
+			"nonce":              loc.Query().Get("nonce"),
+			"preferred_username": username,
+			"email":              username + "@example.com",
+			"groups":             groups,
+		}
+		return w.Result().Cookies()[0], "/api/auth/oidc/callback?code=abc&state=" + url.QueryEscape(loc.Query().Get("state"))
+	}
+
+	t.Run("MappedGroup", func(t *testing.T) {
+		stateCookie, callback := login(t, "carol", []any{"netops"})
+
+		req := httptest.NewRequest("GET", callback, nil)
+		req.AddCookie(stateCookie)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusFound || w.Header().Get("Location") != "/devices" {
+			t.Fatalf("expected redirect to /devices, got %d %s", w.Code, w.Header().Get("Location"))
+		}
+		var session *http.Cookie
+		for _, c := range w.Result().Cookies() {
+			if c.Name == sessionCookieName {
+				session = c
+			}
+		}
+		if session == nil || session.Value == "" {
+			t.Fatal("expected a session cookie")
+		}
+
+		user, err := store.GetUserByUsername(context.Background(), "carol")
+		if err != nil {
+			t.Fatalf("expected user to be provisioned: %v", err)
+		}
+		roles, _ := store.GetUserRoles(context.Background(), user.ID)
+		if len(roles) != 1 || roles[0].Name != "operator" {
+			t.Fatalf("expected the operator role, got %+v", roles)
+		}
+		if s, err := sm.GetSession(session.Value); err != nil || s.UserID != user.ID {
+			t.Fatalf("expected the cookie to be a session for %s, got %+v, %v", user.ID, s, err)
+		}
+	})
+
+	t.Run("UnmappedGroup", func(t *testing.T) {
+		stateCookie, callback := login(t, "mallory", []any{"contractors"})
+
+		req := httptest.NewRequest("GET", callback, nil)
+		req.AddCookie(stateCookie)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusFound || !strings.HasPrefix(w.Header().Get("Location"), "/login?error=") {
+			t.Fatalf("expected redirect to the login page with an error, got %d %s", w.Code, w.Header().Get("Location"))
+		}
+		if _, err := store.GetUserByUsername(context.Background(), "mallory"); err == nil {
+			t.Fatal("expected no user to be created without a mapped group")
+		}
+	})
+
+	t.Run("UsernameOfLocalUser", func(t *testing.T) {
+		local := &model.User{Username: "dave", PasswordHash: "x", IsActive: true}
+		if err := store.CreateUser(context.Background(), local); err != nil {
+			t.Fatalf("CreateUser failed: %v", err)
+		}
+		stateCookie, callback := login(t, "dave", []any{"netops"})
+
+		req := httptest.NewRequest("GET", callback, nil)
+		req.AddCookie(stateCookie)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusFound || !strings.HasPrefix(w.Header().Get("Location"), "/login?error=") {
+			t.Fatalf("expected redirect to the login page with an error, got %d %s", w.Code, w.Header().Get("Location"))
+		}
+		if roles, _ := store.GetUserRoles(context.Background(), local.ID); len(roles) != 0 {
+			t.Fatalf("expected the local user's roles to be left alone, got %+v", roles)
+		}
+	})
+
+	t.Run("RenamedAtProvider", func(t *testing.T) {
+		carol, err := store.GetUserByUsername(context.Background(), "carol")
+		if err != nil {
+			t.Fatalf("GetUserByUsername failed: %v", err)
+		}
+		stateCookie, callback := login(t, "carol", []any{"netops"})
+		claims["preferred_username"] = "caroline"
+
+		req := httptest.NewRequest("GET", callback, nil)
+		req.AddCookie(stateCookie)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusFound || w.Header().Get("Location") != "/devices" {
+			t.Fatalf("expected redirect to /devices, got %d %s", w.Code, w.Header().Get("Location"))
+		}
+		if _, err := store.GetUserByUsername(context.Background(), "caroline"); err == nil {
+			t.Fatal("expected the login to reuse the user linked to the subject, not create a new one")
+		}
+		if user, err := store.GetUser(context.Background(), carol.ID); err != nil || user.OIDCSubject != "sub-carol" {
+			t.Fatalf("expected carol to stay linked to sub-carol, got %+v, %v", user, err)
+		}
+	})
+
+	t.Run("MissingStateCookie", func(t *testing.T) {
+		_, callback := login(t, "carol", []any{"netops"})
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", callback, nil))
+
+		if w.Code != http.StatusFound || !strings.HasPrefix(w.Header().Get("Location"), "/login?error=") {
+			t.Fatalf("expected redirect to the login page with an error, got %d %s", w.Code, w.Header().Get("Location"))
+		}
+	})
+}
+
+func TestSafeRedirect(t *testing.T) {
+	for in, want := range map[string]string{
+		"/devices?x=1":         "/devices?x=1",
+		"":                     "/",
+		"https://evil.example": "/",
+		"//evil.example/path":  "/",
+		"/\\evil.example/path": "/",
+		"devices":              "/",
+	} {
+		if got := safeRedirect(in); got != want {
+			t.Errorf("safeRedirect(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
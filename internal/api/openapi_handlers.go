@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+
+	apispec "github.com/martinsuchenak/rackd/api"
+)
+
+// swaggerUIVersion pins the Swagger UI release the docs page loads from unpkg
+const swaggerUIVersion = "5.17.14"
+
+// apiDocsCSP relaxes the default policy just enough for the docs page to load
+// Swagger UI from unpkg
+const apiDocsCSP = "default-src 'self'; script-src 'self' https://unpkg.com; style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data: https://unpkg.com; font-src 'self'; connect-src 'self'; frame-ancestors 'none'; base-uri 'self'; form-action 'self'"
+
+const apiDocsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>rackd API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+  <script src="/api/docs/init.js"></script>
+</body>
+</html>
+`
+
+// apiDocsInit is served as a file rather than inline so the page needs no
+// 'unsafe-inline' scripts. Requests carry the session cookie and the header
+// the CSRF check expects, so "Try it out" works for a logged-in user.
+const apiDocsInit = `SwaggerUIBundle({
+  url: '/api/openapi.json',
+  dom_id: '#swagger-ui',
+  requestInterceptor: (req) => {
+    req.headers['X-Requested-With'] = 'XMLHttpRequest';
+    return req;
+  },
+});
+`
+
+// getOpenAPISpec serves the OpenAPI 3 spec as JSON. It needs no auth: the
+// spec describes the API, not the data behind it.
+func (h *Handler) getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	spec, err := apispec.JSON()
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+func (h *Handler) getOpenAPISpecYAML(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(apispec.YAML())
+}
+
+func (h *Handler) getAPIDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Security-Policy", apiDocsCSP)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(apiDocsPage))
+}
+
+func (h *Handler) getAPIDocsInit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Write([]byte(apiDocsInit))
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	apispec "github.com/martinsuchenak/rackd/api"
+)
+
+func TestOpenAPIHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	t.Run("SpecJSON_NoAuth", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/openapi.json", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected application/json, got %q", ct)
+		}
+		var spec map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if spec["openapi"] == nil || spec["paths"] == nil {
+			t.Error("expected openapi and paths in spec")
+		}
+	})
+
+	t.Run("SpecYAML", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/openapi.yaml", nil))
+
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "openapi:") {
+			t.Errorf("expected YAML spec, got %d: %.40s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Docs_DisabledByDefault", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/docs", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("Docs_Enabled", func(t *testing.T) {
+		docsMux := http.NewServeMux()
+		NewHandler(store, nil, WithAPIDocs(true)).RegisterRoutes(docsMux)
+		handler := SecurityHeaders(docsMux)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/docs", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+		}
+		if !strings.Contains(w.Header().Get("Content-Security-Policy"), "https://unpkg.com") {
+			t.Errorf("expected docs CSP to allow unpkg, got %q", w.Header().Get("Content-Security-Policy"))
+		}
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/docs/init.js", nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "/api/openapi.json") {
+			t.Errorf("expected init script, got %d", w.Code)
+		}
+	})
+}
+
+// TestOpenAPISpecCoversRoutes keeps the hand-written spec in step with the
+// routes: every route registered in handlers.go must be documented.
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	src, err := os.ReadFile("handlers.go")
+	if err != nil {
+		t.Fatalf("read handlers.go: %v", err)
+	}
+	data, err := apispec.JSON()
+	if err != nil {
+		t.Fatalf("spec: %v", err)
+	}
+	var spec struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("spec: %v", err)
+	}
+
+	routes := regexp.MustCompile(`mux\.HandleFunc\("([A-Z]+) (/[^"]*)"`).FindAllStringSubmatch(string(src), -1)
+	if len(routes) == 0 {
+		t.Fatal("found no routes in handlers.go")
+	}
+	for _, route := range routes {
+		method, path := strings.ToLower(route[1]), route[2]
+		if _, ok := spec.Paths[path][method]; !ok {
+			t.Errorf("%s %s is not documented in api/openapi.yaml", route[1], path)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/martinsuchenak/rackd/internal/export"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// getPrometheusSD returns device scrape targets in the Prometheus
+// http_sd_config format
+func (h *Handler) getPrometheusSD(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := export.PrometheusOptions{AddressLabel: q.Get("address_label")}
+	if v := q.Get("port"); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil || port < 1 || port > 65535 {
+			h.badRequest(w, "port must be between 1 and 65535")
+			return
+		}
+		opts.Port = port
+	}
+
+	filter := model.DeviceFilter{
+		Tags:         parseArrayParam(r, "tag"),
+		DatacenterID: q.Get("datacenter_id"),
+		Status:       model.DeviceStatus(q.Get("status")),
+	}
+	devices, err := h.svc.Devices.ListAll(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	datacenters, err := h.svc.Datacenters.List(r.Context(), &model.DatacenterFilter{Pagination: model.Pagination{Limit: model.MaxPageSize}})
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, export.PrometheusTargets(devices, datacenters, opts))
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/export"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestPrometheusSDHandler(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	dc := &model.Datacenter{Name: "fra1"}
+	if err := store.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("failed to create datacenter: %v", err)
+	}
+	devices := []*model.Device{
+		{Name: "web01", DatacenterID: dc.ID, Tags: []string{"web", "exporter"},
+			Addresses: []model.Address{{IP: "10.0.0.10", Type: "ipv4"}, {IP: "192.168.0.10", Type: "ipv4", Label: "mgmt"}}},
+		{Name: "db01", Tags: []string{"db"}, Addresses: []model.Address{{IP: "10.0.0.20", Type: "ipv4"}}},
+	}
+	for _, d := range devices {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("failed to create device: %v", err)
+		}
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", path, nil)))
+		return w
+	}
+
+	w := get("/api/prometheus/sd?tag=exporter&address_label=mgmt&port=9100")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var groups []export.PrometheusTargetGroup
+	if err := json.Unmarshal(w.Body.Bytes(), &groups); err != nil {
+		t.Fatalf("failed to unmarshal target groups: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("expected only the exporter device, got %+v", groups)
+	}
+	if len(groups[0].Targets) != 1 || groups[0].Targets[0] != "192.168.0.10:9100" {
+		t.Errorf("expected the mgmt address with the port, got %v", groups[0].Targets)
+	}
+	if groups[0].Labels["__meta_rackd_datacenter"] != "fra1" || groups[0].Labels["__meta_rackd_device_name"] != "web01" {
+		t.Errorf("unexpected labels %v", groups[0].Labels)
+	}
+
+	if w := get("/api/prometheus/sd?tag=none"); w.Code != http.StatusOK || w.Body.String() != "[]\n" {
+		t.Errorf("expected an empty list, got %d %q", w.Code, w.Body.String())
+	}
+	if w := get("/api/prometheus/sd?port=70000"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for an invalid port, got %d", http.StatusBadRequest, w.Code)
+	}
+}
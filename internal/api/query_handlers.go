@@ -0,0 +1,354 @@
+package api
+
+import (
+	"cmp"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// QueryResponse is the response of the data-source query endpoint. Items are
+// sorted by name and ID and carry no timestamps or monitoring results, so
+// repeated queries return byte-identical JSON until the inventory changes.
+type QueryResponse struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+	Items any    `json:"items"`
+}
+
+// QueryDevice is a device as returned by the query endpoint
+type QueryDevice struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Hostname       string         `json:"hostname"`
+	Status         string         `json:"status"`
+	OS             string         `json:"os"`
+	MakeModel      string         `json:"make_model"`
+	Username       string         `json:"username"`
+	Location       string         `json:"location"`
+	DatacenterID   string         `json:"datacenter_id"`
+	DatacenterName string         `json:"datacenter_name"`
+	Tags           []string       `json:"tags"`
+	Domains        []string       `json:"domains"`
+	Addresses      []QueryAddress `json:"addresses"`
+}
+
+// QueryAddress is a device address with its network and pool assignment
+type QueryAddress struct {
+	IP          string `json:"ip"`
+	Type        string `json:"type"`
+	Label       string `json:"label"`
+	Port        int    `json:"port"`
+	NetworkID   string `json:"network_id"`
+	NetworkName string `json:"network_name"`
+	Subnet      string `json:"subnet"`
+	VLANID      int    `json:"vlan_id"`
+	PoolID      string `json:"pool_id"`
+	PoolName    string `json:"pool_name"`
+	MACAddress  string `json:"mac_address"`
+}
+
+// QueryNetwork is a network with its pools as returned by the query endpoint
+type QueryNetwork struct {
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	Subnet         string      `json:"subnet"`
+	VLANID         int         `json:"vlan_id"`
+	Description    string      `json:"description"`
+	DatacenterID   string      `json:"datacenter_id"`
+	DatacenterName string      `json:"datacenter_name"`
+	Pools          []QueryPool `json:"pools"`
+}
+
+// QueryPool is a pool as returned by the query endpoint
+type QueryPool struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	NetworkID   string   `json:"network_id"`
+	NetworkName string   `json:"network_name"`
+	Subnet      string   `json:"subnet"`
+	StartIP     string   `json:"start_ip"`
+	EndIP       string   `json:"end_ip"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// queryIndex holds the datacenters, networks and pools used to resolve
+// filters and fill in names
+type queryIndex struct {
+	datacenters []model.Datacenter
+	networks    []model.Network
+	pools       []model.NetworkPool
+}
+
+func (h *Handler) loadQueryIndex(r *http.Request) (*queryIndex, error) {
+	ctx := r.Context()
+	idx := &queryIndex{}
+	var err error
+	idx.datacenters, err = collectPages(func(p model.Pagination) ([]model.Datacenter, error) {
+		return h.svc.Datacenters.List(ctx, &model.DatacenterFilter{Pagination: p})
+	})
+	if err != nil {
+		return nil, err
+	}
+	idx.networks, err = collectPages(func(p model.Pagination) ([]model.Network, error) {
+		return h.svc.Networks.List(ctx, &model.NetworkFilter{Pagination: p})
+	})
+	if err != nil {
+		return nil, err
+	}
+	idx.pools, err = collectPages(func(p model.Pagination) ([]model.NetworkPool, error) {
+		return h.svc.Pools.List(ctx, &model.NetworkPoolFilter{Pagination: p})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func (idx *queryIndex) datacenter(ref string) *model.Datacenter {
+	for i := range idx.datacenters {
+		if idx.datacenters[i].ID == ref || strings.EqualFold(idx.datacenters[i].Name, ref) {
+			return &idx.datacenters[i]
+		}
+	}
+	return nil
+}
+
+func (idx *queryIndex) network(ref string) *model.Network {
+	for i := range idx.networks {
+		if idx.networks[i].ID == ref || strings.EqualFold(idx.networks[i].Name, ref) {
+			return &idx.networks[i]
+		}
+	}
+	return nil
+}
+
+func (idx *queryIndex) pool(id string) *model.NetworkPool {
+	for i := range idx.pools {
+		if idx.pools[i].ID == id {
+			return &idx.pools[i]
+		}
+	}
+	return nil
+}
+
+func (idx *queryIndex) datacenterName(id string) string {
+	if dc := idx.datacenter(id); dc != nil && id != "" {
+		return dc.Name
+	}
+	return ""
+}
+
+// query serves GET /api/v1/query, a stable read-only view of the inventory
+// for Terraform/OpenTofu http data sources
+func (h *Handler) query(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	queryType := q.Get("type")
+	switch queryType {
+	case "device", "network", "pool":
+	case "":
+		h.badRequest(w, "query parameter 'type' is required (device, network or pool)")
+		return
+	default:
+		h.badRequest(w, "invalid type: must be device, network or pool")
+		return
+	}
+
+	idx, err := h.loadQueryIndex(r)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	var datacenterID, networkID string
+	if ref := q.Get("datacenter"); ref != "" {
+		dc := idx.datacenter(ref)
+		if dc == nil {
+			h.badRequest(w, "unknown datacenter: "+ref)
+			return
+		}
+		datacenterID = dc.ID
+	}
+	if ref := q.Get("network"); ref != "" {
+		n := idx.network(ref)
+		if n == nil {
+			h.badRequest(w, "unknown network: "+ref)
+			return
+		}
+		networkID = n.ID
+	}
+	tags := parseArrayParam(r, "tag")
+	name := q.Get("name")
+	if queryType == "network" && len(tags) > 0 {
+		h.badRequest(w, "tag filter applies to device and pool queries only")
+		return
+	}
+
+	var items any
+	count := 0
+	switch queryType {
+	case "device":
+		devices, err := h.svc.Devices.ListAll(r.Context(), model.DeviceFilter{
+			Tags:         tags,
+			DatacenterID: datacenterID,
+			NetworkID:    networkID,
+			Status:       model.DeviceStatus(q.Get("status")),
+		})
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		result := []QueryDevice{}
+		for _, d := range devices {
+			if name == "" || d.Name == name {
+				result = append(result, idx.queryDevice(d))
+			}
+		}
+		slices.SortFunc(result, func(a, b QueryDevice) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+		})
+		items, count = result, len(result)
+
+	case "network":
+		result := []QueryNetwork{}
+		for _, n := range idx.networks {
+			if (datacenterID != "" && n.DatacenterID != datacenterID) ||
+				(networkID != "" && n.ID != networkID) ||
+				(name != "" && n.Name != name) {
+				continue
+			}
+			qn := QueryNetwork{
+				ID: n.ID, Name: n.Name, Subnet: n.Subnet, VLANID: n.VLANID, Description: n.Description,
+				DatacenterID: n.DatacenterID, DatacenterName: idx.datacenterName(n.DatacenterID),
+				Pools: []QueryPool{},
+			}
+			for _, p := range idx.pools {
+				if p.NetworkID == n.ID {
+					qn.Pools = append(qn.Pools, idx.queryPool(p))
+				}
+			}
+			sortQueryPools(qn.Pools)
+			result = append(result, qn)
+		}
+		slices.SortFunc(result, func(a, b QueryNetwork) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+		})
+		items, count = result, len(result)
+
+	case "pool":
+		result := []QueryPool{}
+		for _, p := range idx.pools {
+			if (networkID != "" && p.NetworkID != networkID) ||
+				(name != "" && p.Name != name) ||
+				!hasAllTags(p.Tags, tags) {
+				continue
+			}
+			if datacenterID != "" {
+				if n := idx.network(p.NetworkID); n == nil || n.DatacenterID != datacenterID {
+					continue
+				}
+			}
+			result = append(result, idx.queryPool(p))
+		}
+		sortQueryPools(result)
+		items, count = result, len(result)
+	}
+
+	h.writeJSON(w, http.StatusOK, QueryResponse{Type: queryType, Count: count, Items: items})
+}
+
+func (idx *queryIndex) queryDevice(d model.Device) QueryDevice {
+	qd := QueryDevice{
+		ID: d.ID, Name: d.Name, Hostname: d.Hostname, Status: string(d.Status), OS: d.OS,
+		MakeModel: d.MakeModel, Username: d.Username, Location: d.Location,
+		DatacenterID: d.DatacenterID, DatacenterName: idx.datacenterName(d.DatacenterID),
+		Tags:      sortedStrings(d.Tags),
+		Domains:   sortedStrings(d.Domains),
+		Addresses: []QueryAddress{},
+	}
+	for _, a := range d.Addresses {
+		qa := QueryAddress{IP: a.IP, Type: a.Type, Label: a.Label, NetworkID: a.NetworkID, PoolID: a.PoolID, MACAddress: a.MACAddress}
+		if a.Port != nil {
+			qa.Port = *a.Port
+		}
+		if a.NetworkID != "" {
+			if n := idx.network(a.NetworkID); n != nil {
+				qa.NetworkName, qa.Subnet, qa.VLANID = n.Name, n.Subnet, n.VLANID
+			}
+		}
+		if a.PoolID != "" {
+			if p := idx.pool(a.PoolID); p != nil {
+				qa.PoolName = p.Name
+			}
+		}
+		qd.Addresses = append(qd.Addresses, qa)
+	}
+	slices.SortFunc(qd.Addresses, func(a, b QueryAddress) int {
+		return cmp.Or(compareIPs(a.IP, b.IP), cmp.Compare(a.Label, b.Label), cmp.Compare(a.Port, b.Port))
+	})
+	return qd
+}
+
+func (idx *queryIndex) queryPool(p model.NetworkPool) QueryPool {
+	qp := QueryPool{
+		ID: p.ID, Name: p.Name, NetworkID: p.NetworkID, StartIP: p.StartIP, EndIP: p.EndIP,
+		Description: p.Description, Tags: sortedStrings(p.Tags),
+	}
+	if n := idx.network(p.NetworkID); n != nil {
+		qp.NetworkName, qp.Subnet = n.Name, n.Subnet
+	}
+	return qp
+}
+
+func sortQueryPools(pools []QueryPool) {
+	slices.SortFunc(pools, func(a, b QueryPool) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+}
+
+// compareIPs orders addresses numerically, falling back to string order for
+// values that do not parse
+func compareIPs(a, b string) int {
+	ipA, errA := netip.ParseAddr(a)
+	ipB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return cmp.Compare(a, b)
+	}
+	return ipA.Compare(ipB)
+}
+
+func sortedStrings(values []string) []string {
+	sorted := append([]string{}, values...)
+	slices.Sort(sorted)
+	return sorted
+}
+
+func hasAllTags(have, want []string) bool {
+	for _, tag := range want {
+		if !slices.Contains(have, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// collectPages fetches every page of a paginated list
+func collectPages[T any](list func(model.Pagination) ([]T, error)) ([]T, error) {
+	var all []T
+	p := model.Pagination{Limit: model.MaxPageSize}
+	for {
+		page, err := list(p)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < p.Limit {
+			return all, nil
+		}
+		p.Offset += len(page)
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestQueryHandler(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	dc := &model.Datacenter{Name: "fra1"}
+	if err := store.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("failed to create datacenter: %v", err)
+	}
+	network := &model.Network{Name: "prod", Subnet: "10.0.0.0/24", VLANID: 100, DatacenterID: dc.ID}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "servers", StartIP: "10.0.0.10", EndIP: "10.0.0.50", Tags: []string{"servers"}}
+	if err := store.CreateNetworkPool(ctx, pool); err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	devices := []*model.Device{
+		{Name: "web02", DatacenterID: dc.ID, Tags: []string{"web", "prod"},
+			Addresses: []model.Address{{IP: "10.0.0.12", Type: "ipv4", NetworkID: network.ID, PoolID: pool.ID}}},
+		{Name: "web01", DatacenterID: dc.ID, Tags: []string{"web"},
+			Addresses: []model.Address{{IP: "10.0.0.20", Type: "ipv4"}, {IP: "10.0.0.11", Type: "ipv4", NetworkID: network.ID, PoolID: pool.ID}}},
+		{Name: "db01", Tags: []string{"db"}},
+	}
+	for _, d := range devices {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("failed to create device: %v", err)
+		}
+	}
+
+	get := func(path string, wantStatus int) []byte {
+		t.Helper()
+		req := authReq(httptest.NewRequest("GET", path, nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != wantStatus {
+			t.Fatalf("GET %s: expected %d, got %d: %s", path, wantStatus, w.Code, w.Body.String())
+		}
+		return w.Body.Bytes()
+	}
+
+	t.Run("devices by tag and datacenter name", func(t *testing.T) {
+		body := get("/api/v1/query?type=device&tag=web&datacenter=fra1", http.StatusOK)
+		var resp struct {
+			Count int           `json:"count"`
+			Items []QueryDevice `json:"items"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Count != 2 || len(resp.Items) != 2 {
+			t.Fatalf("expected 2 devices, got %d", resp.Count)
+		}
+		web01 := resp.Items[0]
+		if web01.Name != "web01" || resp.Items[1].Name != "web02" {
+			t.Errorf("expected devices sorted by name, got %s, %s", web01.Name, resp.Items[1].Name)
+		}
+		if web01.DatacenterName != "fra1" {
+			t.Errorf("datacenter_name = %q, want fra1", web01.DatacenterName)
+		}
+		if len(web01.Addresses) != 2 || web01.Addresses[0].IP != "10.0.0.11" {
+			t.Fatalf("expected addresses sorted by IP, got %+v", web01.Addresses)
+		}
+		addr := web01.Addresses[0]
+		if addr.NetworkName != "prod" || addr.Subnet != "10.0.0.0/24" || addr.VLANID != 100 || addr.PoolName != "servers" {
+			t.Errorf("address missing network or pool details: %+v", addr)
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		first := get("/api/v1/query?type=device", http.StatusOK)
+		second := get("/api/v1/query?type=device", http.StatusOK)
+		if string(first) != string(second) {
+			t.Error("expected identical responses for identical queries")
+		}
+	})
+
+	t.Run("networks with pools", func(t *testing.T) {
+		body := get("/api/v1/query?type=network&datacenter="+dc.ID, http.StatusOK)
+		var resp struct {
+			Items []QueryNetwork `json:"items"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Items) != 1 || len(resp.Items[0].Pools) != 1 || resp.Items[0].Pools[0].Name != "servers" {
+			t.Fatalf("expected prod network with servers pool, got %+v", resp.Items)
+		}
+	})
+
+	t.Run("pools by network and tag", func(t *testing.T) {
+		body := get("/api/v1/query?type=pool&network=prod&tag=servers", http.StatusOK)
+		var resp struct {
+			Items []QueryPool `json:"items"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Items) != 1 || resp.Items[0].Subnet != "10.0.0.0/24" {
+			t.Fatalf("expected servers pool, got %+v", resp.Items)
+		}
+	})
+
+	t.Run("empty result is an empty list", func(t *testing.T) {
+		body := get("/api/v1/query?type=device&name=missing", http.StatusOK)
+		var resp map[string]json.RawMessage
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if string(resp["items"]) != "[]" {
+			t.Errorf("items = %s, want []", resp["items"])
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		get("/api/v1/query", http.StatusBadRequest)
+		get("/api/v1/query?type=rack", http.StatusBadRequest)
+		get("/api/v1/query?type=device&datacenter=nowhere", http.StatusBadRequest)
+		get("/api/v1/query?type=network&tag=web", http.StatusBadRequest)
+	})
+}
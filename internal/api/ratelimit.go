@@ -38,15 +38,25 @@ func NewRateLimiter(requests int, window time.Duration) *RateLimiter {
 	return rl
 }
 
+// SetLimit changes the requests allowed per window. Clients keep their
+// current bucket until it next resets.
+func (rl *RateLimiter) SetLimit(requests int, window time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.requests = requests
+	rl.window = window
+}
+
 // Allow checks if a request should be allowed
 func (rl *RateLimiter) Allow(clientID string) bool {
 	rl.mu.RLock()
 	bucket, exists := rl.clients[clientID]
+	requests, window := rl.requests, rl.window
 	rl.mu.RUnlock()
 
 	if !exists {
 		bucket = &clientBucket{
-			tokens:    rl.requests,
+			tokens:    requests,
 			lastReset: time.Now(),
 		}
 		rl.mu.Lock()
@@ -58,8 +68,8 @@ func (rl *RateLimiter) Allow(clientID string) bool {
 	defer bucket.mu.Unlock()
 
 	// Reset bucket if window expired
-	if time.Since(bucket.lastReset) > rl.window {
-		bucket.tokens = rl.requests
+	if time.Since(bucket.lastReset) > window {
+		bucket.tokens = requests
 		bucket.lastReset = time.Now()
 	}
 
@@ -75,17 +85,18 @@ func (rl *RateLimiter) Allow(clientID string) bool {
 func (rl *RateLimiter) GetRemaining(clientID string) int {
 	rl.mu.RLock()
 	bucket, exists := rl.clients[clientID]
+	requests, window := rl.requests, rl.window
 	rl.mu.RUnlock()
 
 	if !exists {
-		return rl.requests
+		return requests
 	}
 
 	bucket.mu.Lock()
 	defer bucket.mu.Unlock()
 
-	if time.Since(bucket.lastReset) > rl.window {
-		return rl.requests
+	if time.Since(bucket.lastReset) > window {
+		return requests
 	}
 
 	return bucket.tokens
@@ -95,16 +106,17 @@ func (rl *RateLimiter) GetRemaining(clientID string) int {
 func (rl *RateLimiter) GetResetTime(clientID string) time.Time {
 	rl.mu.RLock()
 	bucket, exists := rl.clients[clientID]
+	window := rl.window
 	rl.mu.RUnlock()
 
 	if !exists {
-		return time.Now().Add(rl.window)
+		return time.Now().Add(window)
 	}
 
 	bucket.mu.Lock()
 	defer bucket.mu.Unlock()
 
-	return bucket.lastReset.Add(rl.window)
+	return bucket.lastReset.Add(window)
 }
 
 func (rl *RateLimiter) cleanupLoop() {
@@ -51,6 +51,23 @@ func TestRateLimiterGetRemaining(t *testing.T) {
 	}
 }
 
+func TestRateLimiterSetLimit(t *testing.T) {
+	limiter := NewRateLimiter(1, time.Hour)
+	limiter.Allow("client1")
+	if limiter.Allow("client1") {
+		t.Fatal("2nd request should be blocked")
+	}
+
+	limiter.SetLimit(5, time.Millisecond)
+	if remaining := limiter.GetRemaining("new-client"); remaining != 5 {
+		t.Errorf("Expected 5 remaining for a new client, got %d", remaining)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if !limiter.Allow("client1") {
+		t.Error("Request should be allowed once the shorter window has passed")
+	}
+}
+
 func TestRateLimitMiddleware(t *testing.T) {
 	limiter := NewRateLimiter(2, 1*time.Second)
 	middleware := RateLimitMiddleware(limiter, false)
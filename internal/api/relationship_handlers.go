@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -22,6 +23,15 @@ func (h *Handler) listAllRelationships(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, rels)
 }
 
+func (h *Handler) listRelationshipTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := h.svc.Relationships.Types(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, types)
+}
+
 func (h *Handler) addRelationship(w http.ResponseWriter, r *http.Request) {
 	parentID := r.PathValue("id")
 	var req addRelationshipRequest
@@ -33,8 +43,8 @@ func (h *Handler) addRelationship(w http.ResponseWriter, r *http.Request) {
 		h.badRequest(w, "child_id and type are required")
 		return
 	}
-	if !isValidRelationshipType(req.Type) {
-		h.badRequest(w, "type must be contains, connected_to, or depends_on")
+	if _, ok := model.LookupRelationshipType(req.Type); !ok {
+		h.badRequest(w, "type must be one of: "+strings.Join(model.RelationshipTypeNames(), ", "))
 		return
 	}
 
@@ -68,6 +78,18 @@ func (h *Handler) getRelatedDevices(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, devices)
 }
 
+func (h *Handler) getDeviceImpact(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+	depth := parseIntParam(r, "depth", 0)
+
+	impact, err := h.svc.Relationships.Impact(r.Context(), deviceID, depth)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, impact)
+}
+
 func (h *Handler) removeRelationship(w http.ResponseWriter, r *http.Request) {
 	parentID := r.PathValue("id")
 	childID := r.PathValue("child_id")
@@ -101,7 +123,3 @@ func (h *Handler) updateRelationshipNotes(w http.ResponseWriter, r *http.Request
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
-
-func isValidRelationshipType(t string) bool {
-	return t == model.RelationshipContains || t == model.RelationshipConnectedTo || t == model.RelationshipDependsOn
-}
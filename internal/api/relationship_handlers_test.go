@@ -108,6 +108,43 @@ func TestRelationshipHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("AddRelationship_Cycle", func(t *testing.T) {
+		body := `{"child_id":"` + device1.ID + `","type":"contains"}`
+		req := authReq(httptest.NewRequest("POST", "/api/devices/"+device2.ID+"/relationships", bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("AddRelationship_SelfReference", func(t *testing.T) {
+		body := `{"child_id":"` + device1.ID + `","type":"connected_to"}`
+		req := authReq(httptest.NewRequest("POST", "/api/devices/"+device1.ID+"/relationships", bytes.NewBufferString(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("ListRelationshipTypes", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/relationships/types", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var types []model.RelationshipType
+		json.NewDecoder(w.Body).Decode(&types)
+		if len(types) != len(model.RelationshipTypes) {
+			t.Errorf("expected %d types, got %+v", len(model.RelationshipTypes), types)
+		}
+	})
+
 	t.Run("GetRelationships", func(t *testing.T) {
 		req := authReq(httptest.NewRequest("GET", "/api/devices/"+device1.ID+"/relationships", nil))
 		w := httptest.NewRecorder()
@@ -154,6 +191,45 @@ func TestRelationshipHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("GetDeviceImpact", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/devices/"+device2.ID+"/impact", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var impact model.DeviceImpact
+		json.NewDecoder(w.Body).Decode(&impact)
+		// another-child depends on child-device and is connected to parent-device
+		if len(impact.Affected) != 2 || impact.Affected[0].DeviceID != device3.ID || impact.Affected[1].DeviceID != device1.ID {
+			t.Errorf("unexpected affected devices: %+v", impact.Affected)
+		}
+		if impact.MaxDepth != 5 {
+			t.Errorf("expected default depth 5, got %d", impact.MaxDepth)
+		}
+	})
+
+	t.Run("GetDeviceImpact_InvalidDepth", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/devices/"+device2.ID+"/impact?depth=50", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("GetDeviceImpact_NotFound", func(t *testing.T) {
+		req := authReq(httptest.NewRequest("GET", "/api/devices/nonexistent/impact", nil))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+		}
+	})
+
 	t.Run("RemoveRelationship", func(t *testing.T) {
 		req := authReq(httptest.NewRequest("DELETE", "/api/devices/"+device1.ID+"/relationships/"+device2.ID+"/contains", nil))
 		w := httptest.NewRecorder()
@@ -164,3 +240,56 @@ func TestRelationshipHandlers(t *testing.T) {
 		}
 	})
 }
+
+func TestDeviceVMsHandler(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	host := &model.Device{Name: "esx-01"}
+	vm := &model.Device{Name: "vm-01", Kind: model.DeviceKindVM}
+	sw := &model.Device{Name: "switch-01", Kind: model.DeviceKindNetwork}
+	store.CreateDevice(context.Background(), host)
+	store.CreateDevice(context.Background(), vm)
+	store.CreateDevice(context.Background(), sw)
+
+	addRelationship := func(parentID, childID string) int {
+		body := `{"child_id":"` + childID + `","type":"hosted_on"}`
+		req := authReq(httptest.NewRequest("POST", "/api/devices/"+parentID+"/relationships", bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := addRelationship(vm.ID, sw.ID); code != http.StatusBadRequest {
+		t.Errorf("expected a vm on a switch to be rejected, got %d", code)
+	}
+	if code := addRelationship(host.ID, vm.ID); code != http.StatusBadRequest {
+		t.Errorf("expected a physical device on a vm to be rejected, got %d", code)
+	}
+	if code := addRelationship(vm.ID, host.ID); code != http.StatusCreated {
+		t.Fatalf("expected hosted_on to be created, got %d", code)
+	}
+
+	req := authReq(httptest.NewRequest("GET", "/api/devices/"+host.ID+"/vms", nil))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var vms []model.Device
+	json.NewDecoder(w.Body).Decode(&vms)
+	if len(vms) != 1 || vms[0].ID != vm.ID || vms[0].Kind != model.DeviceKindVM {
+		t.Errorf("expected vm-01 on esx-01, got %+v", vms)
+	}
+
+	req = authReq(httptest.NewRequest("GET", "/api/devices/missing/vms", nil))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected %d for an unknown host, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/export"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// listReports returns the canned reports that can be run
+func (h *Handler) listReports(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.svc.Reports.List())
+}
+
+// runReport runs a canned report and renders it as JSON, CSV, XLSX or PDF
+func (h *Handler) runReport(w http.ResponseWriter, r *http.Request) {
+	format := export.Format(r.URL.Query().Get("format"))
+	if format == "" {
+		format = export.FormatJSON
+	}
+	var contentType string
+	switch format {
+	case export.FormatJSON:
+		contentType = "application/json"
+	case export.FormatCSV:
+		contentType = "text/csv"
+	case export.FormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case export.FormatPDF:
+		contentType = "application/pdf"
+	default:
+		h.badRequest(w, "format must be json, csv, xlsx or pdf")
+		return
+	}
+
+	params := model.ReportParams{
+		Days:       parseIntParam(r, "days", 0),
+		Datacenter: r.URL.Query().Get("datacenter"),
+		Tag:        r.URL.Query().Get("tag"),
+	}
+	report, err := h.svc.Reports.Run(r.Context(), r.PathValue("name"), params)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := export.ExportReport(report, format, &buf); err != nil {
+		h.internalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if format != export.FormatJSON {
+		w.Header().Set("Content-Disposition", "attachment; filename="+report.Name+"."+string(format))
+	}
+	w.Write(buf.Bytes())
+}
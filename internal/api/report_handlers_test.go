@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestReportHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	store.CreateDevice(context.Background(), &model.Device{Name: "web-01", OS: "Debian 12"})
+
+	t.Run("List", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports", nil)))
+		var reports []model.ReportInfo
+		json.Unmarshal(w.Body.Bytes(), &reports)
+		if w.Code != http.StatusOK || len(reports) != 6 {
+			t.Errorf("expected 6 reports, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("RunJSON", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/os-distribution", nil)))
+		var report model.Report
+		json.Unmarshal(w.Body.Bytes(), &report)
+		if w.Code != http.StatusOK || len(report.Rows) != 1 || report.Rows[0][0] != "Debian 12" {
+			t.Errorf("unexpected response %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("RunCSV", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/os-distribution?format=csv", nil)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/csv" {
+			t.Errorf("expected text/csv, got %q", ct)
+		}
+		if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "os-distribution.csv") {
+			t.Errorf("unexpected Content-Disposition %q", cd)
+		}
+		if w.Body.String() != "os,devices,percent\nDebian 12,1,100\n" {
+			t.Errorf("unexpected CSV %q", w.Body.String())
+		}
+	})
+
+	t.Run("RunXLSX", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/ip-utilization?format=xlsx", nil)))
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "PK") {
+			t.Errorf("expected an XLSX file, got %d: %.40s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("RunPDF", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/os-distribution?format=pdf", nil)))
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" || !strings.HasPrefix(w.Body.String(), "%PDF-") {
+			t.Errorf("expected a PDF file, got %d: %.40s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/os-distribution?format=docx", nil)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("UnknownReport", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/missing", nil)))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
+
+func TestExposureReport(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	dc1, dc2 := &model.Datacenter{Name: "DC-1"}, &model.Datacenter{Name: "DC-2"}
+	store.CreateDatacenter(ctx, dc1)
+	store.CreateDatacenter(ctx, dc2)
+	store.CreateDevice(ctx, &model.Device{Name: "db-01", DatacenterID: dc1.ID, Tags: []string{"db"}, Addresses: []model.Address{
+		{IP: "10.0.1.20", Type: "ipv4", Label: "eth0"},
+		{IP: "10.0.9.20", Type: "ipv4", Label: "iDRAC-mgmt"},
+	}})
+	store.CreateDevice(ctx, &model.Device{Name: "web-01", DatacenterID: dc2.ID, Addresses: []model.Address{
+		{IP: "10.0.2.10", Type: "ipv4"},
+	}})
+
+	for _, host := range []*model.DiscoveredDevice{
+		{IP: "10.0.1.20", OpenPorts: []int{22, 3306, 5432}},
+		{IP: "10.0.9.20", OpenPorts: []int{23}},
+		{IP: "10.0.2.10", OpenPorts: []int{3389}},
+	} {
+		if err := store.RecordPortObservations(ctx, "scan-1", host, nil); err != nil {
+			t.Fatalf("RecordPortObservations failed: %v", err)
+		}
+	}
+	// MySQL has since been closed
+	store.RecordPortObservations(ctx, "scan-2", &model.DiscoveredDevice{IP: "10.0.1.20", OpenPorts: []int{22, 5432}}, []int{22, 3306, 5432})
+
+	run := func(t *testing.T, query string) [][]any {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/exposure"+query, nil)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var report model.Report
+		json.Unmarshal(w.Body.Bytes(), &report)
+		return report.Rows
+	}
+
+	rows := run(t, "")
+	if len(rows) != 2 || rows[0][0] != "db-01" || rows[0][5] != float64(5432) || rows[0][6] != "postgresql" || rows[0][7] != "database" ||
+		rows[1][0] != "web-01" || rows[1][6] != "rdp" {
+		t.Errorf("expected postgresql on db-01 and rdp on web-01, got %v", rows)
+	}
+	if rows := run(t, "?datacenter=dc-1"); len(rows) != 1 || rows[0][0] != "db-01" || rows[0][1] != "DC-1" {
+		t.Errorf("expected only db-01 in DC-1, got %v", rows)
+	}
+	if rows := run(t, "?tag=db&datacenter="+dc2.ID); len(rows) != 0 {
+		t.Errorf("expected nothing tagged db in DC-2, got %v", rows)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/exposure?datacenter=nowhere", nil)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for an unknown datacenter, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// listReportSchedules returns the report schedules
+func (h *Handler) listReportSchedules(w http.ResponseWriter, r *http.Request) {
+	filter := &model.ReportScheduleFilter{Report: r.URL.Query().Get("report")}
+	if enabledStr := r.URL.Query().Get("enabled"); enabledStr != "" {
+		enabled := enabledStr == "true"
+		filter.Enabled = &enabled
+	}
+
+	schedules, err := h.svc.ReportSchedules.List(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, schedules)
+}
+
+// createReportSchedule creates a new report schedule
+func (h *Handler) createReportSchedule(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateReportScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	schedule, err := h.svc.ReportSchedules.Create(r.Context(), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, schedule)
+}
+
+// getReportSchedule returns a single report schedule by ID
+func (h *Handler) getReportSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.svc.ReportSchedules.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, schedule)
+}
+
+// updateReportSchedule updates an existing report schedule
+func (h *Handler) updateReportSchedule(w http.ResponseWriter, r *http.Request) {
+	var req model.UpdateReportScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	schedule, err := h.svc.ReportSchedules.Update(r.Context(), r.PathValue("id"), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, schedule)
+}
+
+// deleteReportSchedule deletes a report schedule
+func (h *Handler) deleteReportSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.ReportSchedules.Delete(r.Context(), r.PathValue("id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runReportSchedule runs a report schedule now and returns it with the
+// outcome
+func (h *Handler) runReportSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.svc.ReportSchedules.Run(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, schedule)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestReportScheduleHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	channel := &model.NotificationChannel{Name: "hook", Type: model.NotificationChannelWebhook, URL: "http://127.0.0.1:1/hook", Active: true}
+	if err := store.CreateNotificationChannel(context.Background(), channel); err != nil {
+		t.Fatalf("CreateNotificationChannel failed: %v", err)
+	}
+
+	var schedule model.ReportSchedule
+
+	t.Run("Create", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{
+			"name": "weekly exposure", "report": "exposure", "format": "pdf", "cron_expression": "0 7 * * 1", "channel_ids": []string{channel.ID},
+		})
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("POST", "/api/reports/schedules", bytes.NewReader(body))))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		json.Unmarshal(w.Body.Bytes(), &schedule)
+		if schedule.ID == "" || !schedule.Enabled || schedule.NextRunAt == nil || schedule.CreatedBy != "test-user-id" {
+			t.Errorf("unexpected schedule %+v", schedule)
+		}
+	})
+
+	t.Run("Create_Invalid", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{
+			"name": "too often", "report": "exposure", "cron_expression": "* * * * *", "channel_ids": []string{channel.ID},
+		})
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("POST", "/api/reports/schedules", bytes.NewReader(body))))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		for query, want := range map[string]int{"": 1, "?report=exposure": 1, "?report=warranty-expiry": 0, "?enabled=false": 0} {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/schedules"+query, nil)))
+			var schedules []model.ReportSchedule
+			json.Unmarshal(w.Body.Bytes(), &schedules)
+			if w.Code != http.StatusOK || len(schedules) != want {
+				t.Errorf("%q: expected %d schedules, got %d: %s", query, want, len(schedules), w.Body.String())
+			}
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{"enabled": false, "format": "csv"})
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("PUT", "/api/reports/schedules/"+schedule.ID, bytes.NewReader(body))))
+		var got model.ReportSchedule
+		json.Unmarshal(w.Body.Bytes(), &got)
+		if w.Code != http.StatusOK || got.Enabled || got.Format != "csv" || got.NextRunAt != nil {
+			t.Errorf("unexpected response %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		// The webhook points at loopback, which the notification client refuses
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("POST", "/api/reports/schedules/"+schedule.ID+"/run", nil)))
+		var got model.ReportSchedule
+		json.Unmarshal(w.Body.Bytes(), &got)
+		if w.Code != http.StatusOK || got.LastRunAt == nil || got.LastError == "" {
+			t.Errorf("expected the failed delivery recorded, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("DELETE", "/api/reports/schedules/"+schedule.ID, nil)))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected %d, got %d", http.StatusNoContent, w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", "/api/reports/schedules/"+schedule.ID, nil)))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"time"
 
@@ -99,6 +101,42 @@ func (h *Handler) releaseReservation(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) allocatePoolIP(w http.ResponseWriter, r *http.Request) {
+	poolID := r.PathValue("id")
+
+	// Every field is optional, so an empty body allocates with the defaults
+	var req model.AllocateIPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.invalidJSON(w)
+		return
+	}
+
+	reservation, err := h.svc.Reservations.Allocate(r.Context(), poolID, &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, reservation)
+}
+
+func (h *Handler) releasePoolIP(w http.ResponseWriter, r *http.Request) {
+	poolID := r.PathValue("id")
+
+	var req model.ReleaseIPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	if err := h.svc.Reservations.ReleaseIP(r.Context(), poolID, req.IPAddress); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"message": "Reservation released successfully",
+	})
+}
+
 func (h *Handler) listPoolReservations(w http.ResponseWriter, r *http.Request) {
 	poolID := r.PathValue("id")
 
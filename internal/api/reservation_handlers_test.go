@@ -86,6 +86,61 @@ func TestReservationHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("AllocateAndReleasePoolIP", func(t *testing.T) {
+		w := performRequest(env.mux, authReq(httptest.NewRequest("POST", "/api/pools/pool-phase2/allocate", nil)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var first model.Reservation
+		if err := json.Unmarshal(w.Body.Bytes(), &first); err != nil {
+			t.Fatalf("failed to decode reservation: %v", err)
+		}
+		if first.IPAddress == "" || first.ExpiresAt == nil {
+			t.Fatalf("expected an IP and expiry, got %+v", first)
+		}
+
+		allocReq := authReq(httptest.NewRequest("POST", "/api/pools/pool-phase2/allocate", bytes.NewBufferString(`{"ttl_seconds":60,"hostname":"alloc-host"}`)))
+		allocReq.Header.Set("Content-Type", "application/json")
+		w = performRequest(env.mux, allocReq)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var second model.Reservation
+		if err := json.Unmarshal(w.Body.Bytes(), &second); err != nil {
+			t.Fatalf("failed to decode reservation: %v", err)
+		}
+		if second.IPAddress == first.IPAddress {
+			t.Fatalf("expected a different IP than %s", first.IPAddress)
+		}
+
+		releaseReq := authReq(httptest.NewRequest("POST", "/api/pools/pool-phase2/release", bytes.NewBufferString(`{"ip_address":"`+first.IPAddress+`"}`)))
+		releaseReq.Header.Set("Content-Type", "application/json")
+		w = performRequest(env.mux, releaseReq)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		// Released, so a second release finds nothing
+		releaseReq = authReq(httptest.NewRequest("POST", "/api/pools/pool-phase2/release", bytes.NewBufferString(`{"ip_address":"`+first.IPAddress+`"}`)))
+		releaseReq.Header.Set("Content-Type", "application/json")
+		w = performRequest(env.mux, releaseReq)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = performRequest(env.mux, authReq(httptest.NewRequest("POST", "/api/pools/missing-pool/allocate", nil)))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+
+		badReq := authReq(httptest.NewRequest("POST", "/api/pools/pool-phase2/allocate", bytes.NewBufferString(`{"ttl_seconds":-1}`)))
+		badReq.Header.Set("Content-Type", "application/json")
+		w = performRequest(env.mux, badReq)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
 	t.Run("Reservation_ForbiddenWithoutPermission", func(t *testing.T) {
 		_, limitedToken := env.createAPIUser(t, "limited-reservation-user")
 
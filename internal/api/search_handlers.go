@@ -1,9 +1,13 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/search"
+	"github.com/martinsuchenak/rackd/internal/service"
 )
 
 type SearchResult struct {
@@ -17,6 +21,20 @@ type SearchResponse struct {
 	Results []SearchResult `json:"results"`
 }
 
+// StructuredSearchRequest is the body of a structured search
+type StructuredSearchRequest struct {
+	Query string `json:"query"`
+}
+
+// StructuredSearchResponse is a structured query as parsed, with the devices
+// it matches
+type StructuredSearchResponse struct {
+	Query   string         `json:"query"`
+	AST     *search.Node   `json:"ast"`
+	Count   int            `json:"count"`
+	Devices []model.Device `json:"devices"`
+}
+
 func (h *Handler) search(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
@@ -25,9 +43,15 @@ func (h *Handler) search(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var results []SearchResult
+	// The structured query language only applies to devices
+	structured := search.IsStructured(query)
 
 	if h.svc != nil && h.svc.Devices != nil {
 		devices, err := h.svc.Devices.Search(r.Context(), query)
+		if errors.Is(err, service.ErrQueryTooBroad) || errors.Is(err, service.ErrValidation) {
+			h.handleServiceError(w, err)
+			return
+		}
 		if err == nil {
 			for i := range devices {
 				results = append(results, SearchResult{
@@ -38,8 +62,12 @@ func (h *Handler) search(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if h.svc != nil && h.svc.Networks != nil {
+	if h.svc != nil && h.svc.Networks != nil && !structured {
 		networks, err := h.svc.Networks.Search(r.Context(), query)
+		if errors.Is(err, service.ErrQueryTooBroad) {
+			h.handleServiceError(w, err)
+			return
+		}
 		if err == nil {
 			for i := range networks {
 				results = append(results, SearchResult{
@@ -50,8 +78,12 @@ func (h *Handler) search(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if h.svc != nil && h.svc.Datacenters != nil {
+	if h.svc != nil && h.svc.Datacenters != nil && !structured {
 		datacenters, err := h.svc.Datacenters.Search(r.Context(), query)
+		if errors.Is(err, service.ErrQueryTooBroad) {
+			h.handleServiceError(w, err)
+			return
+		}
 		if err == nil {
 			for i := range datacenters {
 				results = append(results, SearchResult{
@@ -64,3 +96,29 @@ func (h *Handler) search(w http.ResponseWriter, r *http.Request) {
 
 	h.writeJSON(w, http.StatusOK, SearchResponse{Results: results})
 }
+
+// structuredSearch parses a structured device query and returns its syntax
+// tree with the matching devices, so the UI can show how the query was read.
+// A query that does not parse is a 400 locating the fault.
+func (h *Handler) structuredSearch(w http.ResponseWriter, r *http.Request) {
+	var req StructuredSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.invalidJSON(w)
+		return
+	}
+
+	q, devices, err := h.svc.Devices.StructuredSearch(r.Context(), req.Query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	if devices == nil {
+		devices = []model.Device{}
+	}
+	h.writeJSON(w, http.StatusOK, StructuredSearchResponse{
+		Query:   req.Query,
+		AST:     q.AST(),
+		Count:   len(devices),
+		Devices: devices,
+	})
+}
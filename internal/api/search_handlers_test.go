@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -79,3 +81,151 @@ func TestSearch(t *testing.T) {
 		t.Error("Expected to find network in results")
 	}
 }
+
+func TestSearch_QueryTooBroad(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	services := service.NewServices(store, nil, nil)
+	services.SetSearchLimits(service.SearchLimits{MaxRows: 1})
+	handler := NewHandler(store, nil, WithServices(services))
+	ctx := context.Background()
+
+	for _, name := range []string{"web-1", "web-2"} {
+		if err := store.CreateDevice(ctx, &model.Device{Name: name}); err != nil {
+			t.Fatalf("Failed to create device: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/search?q=web", nil)
+	req = req.WithContext(service.SystemContext(req.Context(), "test"))
+	w := httptest.NewRecorder()
+
+	handler.search(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Code  string   `json:"code"`
+		Hints []string `json:"hints"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Code != "QUERY_TOO_BROAD" {
+		t.Errorf("Expected code QUERY_TOO_BROAD, got %q", response.Code)
+	}
+	if len(response.Hints) == 0 {
+		t.Error("Expected hints in response")
+	}
+}
+
+func TestSearch_StructuredQuery(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	handler := NewHandler(store, nil, WithServices(service.NewServices(store, nil, nil)))
+	ctx := context.Background()
+
+	if err := store.CreateNetwork(ctx, &model.Network{Name: "prod-net", Subnet: "10.1.0.0/16"}); err != nil {
+		t.Fatalf("Failed to create network: %v", err)
+	}
+	for _, d := range []*model.Device{
+		{Name: "web-1", OS: "Ubuntu 22.04", Tags: []string{"prod"}, Addresses: []model.Address{{IP: "10.1.4.20"}}},
+		{Name: "web-2", OS: "Ubuntu 22.04", Tags: []string{"prod", "decom"}, Addresses: []model.Address{{IP: "10.1.4.21"}}},
+		{Name: "web-3", OS: "Ubuntu 22.04", Tags: []string{"prod"}, Addresses: []model.Address{{IP: "10.2.0.1"}}},
+	} {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("Failed to create device: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/search?q="+url.QueryEscape("tag:prod os:ubuntu ip:10.1.0.0/16 -tag:decom"), nil)
+	req = req.WithContext(service.SystemContext(req.Context(), "test"))
+	w := httptest.NewRecorder()
+	handler.search(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	// Networks and datacenters are not searched with a structured query
+	if len(response.Results) != 1 || response.Results[0].Device == nil || response.Results[0].Device.Name != "web-1" {
+		t.Fatalf("Expected only web-1, got %+v", response.Results)
+	}
+
+	req = httptest.NewRequest("GET", "/api/search?q="+url.QueryEscape("(tag:prod"), nil)
+	req = req.WithContext(service.SystemContext(req.Context(), "test"))
+	w = httptest.NewRecorder()
+	handler.search(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an invalid query, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestStructuredSearch(t *testing.T) {
+	h, store := setupTestHandler(t)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	ctx := context.Background()
+
+	for _, d := range []*model.Device{
+		{Name: "web-1", Tags: []string{"prod"}, Addresses: []model.Address{{IP: "10.1.4.20"}}},
+		{Name: "web-2", Tags: []string{"prod"}, Addresses: []model.Address{{IP: "10.2.0.1"}}},
+	} {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("Failed to create device: %v", err)
+		}
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := authReq(httptest.NewRequest("POST", "/api/search/structured", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"query": "tag:prod ip:10.1.0.0/16"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response StructuredSearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Count != 1 || response.Devices[0].Name != "web-1" {
+		t.Errorf("Expected only web-1, got %+v", response.Devices)
+	}
+	if ast := response.AST; ast.Type != "and" || len(ast.Children) != 2 ||
+		ast.Children[1].Field != "ip" || ast.Children[1].Value != "10.1.0.0/16" || ast.Children[1].Start != 9 || ast.Children[1].End != 23 {
+		t.Errorf("Unexpected AST %+v", ast)
+	}
+
+	w = post(`{"query": "tag:prod OR (ip:10.1.0.0/33"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an invalid query, got %d: %s", w.Code, w.Body.String())
+	}
+	var errResp struct {
+		Code    string         `json:"code"`
+		Details map[string]int `json:"details"`
+	}
+	json.NewDecoder(w.Body).Decode(&errResp)
+	if errResp.Code != "INVALID_QUERY" || errResp.Details["start"] != 13 || errResp.Details["end"] != 27 {
+		t.Errorf("Expected INVALID_QUERY spanning the CIDR, got %+v", errResp)
+	}
+
+	if w := post(`{`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid JSON, got %d", w.Code)
+	}
+}
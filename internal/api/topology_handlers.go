@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/export"
+)
+
+// getTopology returns the device and network graph of a datacenter, or of
+// everything when no datacenter is given, as JSON or Graphviz DOT
+func (h *Handler) getTopology(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "dot" {
+		h.badRequest(w, "format must be one of json or dot")
+		return
+	}
+
+	topology, err := h.svc.Topology.Get(r.Context(), r.URL.Query().Get("datacenter"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	if format == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		export.WriteTopologyDOT(topology, w)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, topology)
+}
+
+// queryTopology walks the path given in q through the inventory and returns
+// the chains of devices, networks and datacenters it matched
+func (h *Handler) queryTopology(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		h.badRequest(w, "query parameter 'q' is required")
+		return
+	}
+
+	result, err := h.svc.Topology.Query(r.Context(), query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestTopologyHandler(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	ctx := context.Background()
+	dc := &model.Datacenter{Name: "dc1"}
+	if err := store.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("failed to create datacenter: %v", err)
+	}
+	supernet := &model.Network{Name: "campus", Subnet: "10.0.0.0/16", DatacenterID: dc.ID}
+	servers := &model.Network{Name: "servers", Subnet: "10.0.1.0/24", DatacenterID: dc.ID}
+	shared := &model.Network{Name: "shared", Subnet: "172.16.0.0/24"}
+	for _, n := range []*model.Network{supernet, servers, shared} {
+		if err := store.CreateNetwork(ctx, n); err != nil {
+			t.Fatalf("failed to create network: %v", err)
+		}
+	}
+	web := &model.Device{Name: "web01", DatacenterID: dc.ID, Addresses: []model.Address{
+		{IP: "10.0.1.10", Type: "ipv4", NetworkID: servers.ID, SwitchPort: "Gi1/0/24"},
+		{IP: "172.16.0.10", Type: "ipv4", NetworkID: shared.ID},
+	}}
+	sw := &model.Device{Name: "sw01", DatacenterID: dc.ID}
+	other := &model.Device{Name: "remote01"}
+	for _, d := range []*model.Device{web, sw, other} {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("failed to create device: %v", err)
+		}
+	}
+	if err := store.AddRelationship(ctx, sw.ID, web.ID, model.RelationshipConnectedTo, "uplink"); err != nil {
+		t.Fatalf("failed to add relationship: %v", err)
+	}
+	if err := store.AddRelationship(ctx, web.ID, other.ID, model.RelationshipDependsOn, ""); err != nil {
+		t.Fatalf("failed to add relationship: %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest("GET", path, nil)))
+		return w
+	}
+
+	t.Run("Datacenter", func(t *testing.T) {
+		w := get("/api/topology?datacenter=" + dc.ID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var topology model.Topology
+		if err := json.NewDecoder(w.Body).Decode(&topology); err != nil {
+			t.Fatalf("failed to decode topology: %v", err)
+		}
+
+		nodes := make(map[string]string)
+		for _, n := range topology.Nodes {
+			nodes[n.ID] = n.Type
+		}
+		if len(nodes) != 5 || nodes[shared.ID] != model.TopologyNodeNetwork || nodes[web.ID] != model.TopologyNodeDevice {
+			t.Errorf("expected both devices and all three networks, got %+v", topology.Nodes)
+		}
+		if _, ok := nodes[other.ID]; ok {
+			t.Error("expected the device outside the datacenter to be left out")
+		}
+
+		edges := make(map[string]model.TopologyEdge)
+		for _, e := range topology.Edges {
+			edges[e.Type+" "+e.Source+" "+e.Target] = e
+		}
+		if len(edges) != 4 {
+			t.Errorf("expected 4 edges, got %+v", topology.Edges)
+		}
+		if e, ok := edges["member "+web.ID+" "+servers.ID]; !ok || e.Label != "10.0.1.10" || e.SwitchPort != "Gi1/0/24" {
+			t.Errorf("expected a member edge with IP and switch port, got %+v", e)
+		}
+		if _, ok := edges["member "+web.ID+" "+shared.ID]; !ok {
+			t.Error("expected a member edge to the network outside the datacenter")
+		}
+		if _, ok := edges["subnet "+supernet.ID+" "+servers.ID]; !ok {
+			t.Error("expected a subnet edge from the supernet")
+		}
+		if e, ok := edges["connected_to "+sw.ID+" "+web.ID]; !ok || e.Label != "uplink" {
+			t.Errorf("expected a connected_to edge labelled with its notes, got %+v", e)
+		}
+	})
+
+	t.Run("DOT", func(t *testing.T) {
+		w := get("/api/topology?datacenter=" + dc.ID + "&format=dot")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/vnd.graphviz") {
+			t.Errorf("expected a Graphviz content type, got %q", ct)
+		}
+		body := w.Body.String()
+		if !strings.HasPrefix(body, "graph ") || !strings.Contains(body, `"`+sw.ID+`" -- "`+web.ID+`" [style=dashed, label="connected_to"]`) {
+			t.Errorf("unexpected DOT output:\n%s", body)
+		}
+	})
+
+	t.Run("All", func(t *testing.T) {
+		w := get("/api/topology")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var topology model.Topology
+		json.NewDecoder(w.Body).Decode(&topology)
+		if len(topology.Nodes) != 6 {
+			t.Errorf("expected every device and network, got %d nodes", len(topology.Nodes))
+		}
+	})
+
+	t.Run("UnknownDatacenter", func(t *testing.T) {
+		if w := get("/api/topology?datacenter=missing"); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		if w := get("/api/topology?format=svg"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		w := get("/api/topology/query?q=" + url.QueryEscape("devices[name:remote01].parents(depends_on).datacenter"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var result model.TopologyQuery
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		if result.Count != 1 || len(result.Steps) != 3 {
+			t.Fatalf("expected one path of three steps, got %+v", result)
+		}
+		var labels []string
+		for _, node := range result.Paths[0] {
+			labels = append(labels, node.Type+":"+node.Label)
+		}
+		if got := strings.Join(labels, " "); got != "device:remote01 device:web01 datacenter:dc1" {
+			t.Errorf("unexpected path %s", got)
+		}
+	})
+
+	t.Run("QueryNetworks", func(t *testing.T) {
+		w := get("/api/topology/query?q=" + url.QueryEscape("datacenters[DC1].networks[serv*].devices"))
+		var result model.TopologyQuery
+		json.Unmarshal(w.Body.Bytes(), &result)
+		if w.Code != http.StatusOK || result.Count != 1 || result.Paths[0][2].ID != web.ID {
+			t.Errorf("unexpected response %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("QueryInvalid", func(t *testing.T) {
+		for _, q := range []string{"", "devices.parents(owns)", "networks.parents"} {
+			if w := get("/api/topology/query?q=" + url.QueryEscape(q)); w.Code != http.StatusBadRequest {
+				t.Errorf("%q: expected 400, got %d", q, w.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func (h *Handler) listTrash(w http.ResponseWriter, r *http.Request) {
+	filter := &model.TrashFilter{Pagination: parsePagination(r)}
+	if t := r.URL.Query().Get("type"); t != "" {
+		filter.Types = []string{t}
+	}
+
+	items, err := h.svc.Trash.List(r.Context(), filter)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, items)
+}
+
+func (h *Handler) restoreTrashItem(w http.ResponseWriter, r *http.Request) {
+	item, err := h.svc.Trash.Restore(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, item)
+}
+
+func (h *Handler) purgeTrashItem(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Trash.Purge(r.Context(), r.PathValue("id")); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
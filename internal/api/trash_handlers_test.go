@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestTrashHandlers(t *testing.T) {
+	h, store := setupTestHandler(t)
+	defer store.Close()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	device := &model.Device{Name: "web01"}
+	if err := store.CreateDevice(context.Background(), device); err != nil {
+		t.Fatalf("failed to create device: %v", err)
+	}
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, authReq(httptest.NewRequest(method, path, nil)))
+		return w
+	}
+
+	t.Run("DeleteMovesToTrash", func(t *testing.T) {
+		if w := do("DELETE", "/api/devices/"+device.ID+"?cascade=true"); w.Code != http.StatusNoContent {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+		if w := do("GET", "/api/devices/"+device.ID); w.Code != http.StatusNotFound {
+			t.Errorf("expected the trashed device to be hidden, got %d", w.Code)
+		}
+	})
+
+	t.Run("ListTrash", func(t *testing.T) {
+		w := do("GET", "/api/trash?type=device")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var items []model.TrashItem
+		json.NewDecoder(w.Body).Decode(&items)
+		if len(items) != 1 || items[0].ID != device.ID || items[0].DeletePolicy != model.DeleteCascade {
+			t.Errorf("unexpected trash: %+v", items)
+		}
+	})
+
+	t.Run("ListTrash_InvalidType", func(t *testing.T) {
+		if w := do("GET", "/api/trash?type=pool"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("RestoreTrashItem", func(t *testing.T) {
+		w := do("POST", "/api/trash/"+device.ID+"/restore")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if w := do("GET", "/api/devices/"+device.ID); w.Code != http.StatusOK {
+			t.Errorf("expected the restored device, got %d", w.Code)
+		}
+		if w := do("POST", "/api/trash/"+device.ID+"/restore"); w.Code != http.StatusNotFound {
+			t.Errorf("expected %d once restored, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("PurgeTrashItem", func(t *testing.T) {
+		do("DELETE", "/api/devices/"+device.ID)
+		if w := do("DELETE", "/api/trash/"+device.ID); w.Code != http.StatusNoContent {
+			t.Fatalf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+		if w := do("POST", "/api/trash/"+device.ID+"/restore"); w.Code != http.StatusNotFound {
+			t.Errorf("expected the purged device to be gone, got %d", w.Code)
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/validate"
 )
 
 // ValidationError represents a validation failure
@@ -48,7 +49,7 @@ func ValidateDevice(device *model.Device) ValidationErrors {
 	if device.Hostname != "" {
 		if len(device.Hostname) > 253 {
 			errs = append(errs, ValidationError{Field: "hostname", Message: "hostname must be 253 characters or less"})
-		} else if !isValidHostname(device.Hostname) {
+		} else if !validate.IsHostname(device.Hostname) {
 			errs = append(errs, ValidationError{Field: "hostname", Message: "hostname contains invalid characters"})
 		}
 	}
@@ -91,9 +92,11 @@ func validateAddress(addr model.Address, index int) ValidationErrors {
 	var errs ValidationErrors
 	fieldPrefix := fmt.Sprintf("addresses[%d]", index)
 
-	// IP is required for addresses
+	// IP is required for addresses, unless it is to be allocated from a pool
 	if strings.TrimSpace(addr.IP) == "" {
-		errs = append(errs, ValidationError{Field: fieldPrefix + ".ip", Message: "IP address is required"})
+		if addr.PoolID == "" {
+			errs = append(errs, ValidationError{Field: fieldPrefix + ".ip", Message: "IP address or pool_id is required"})
+		}
 	} else if !isValidIP(addr.IP) {
 		errs = append(errs, ValidationError{Field: fieldPrefix + ".ip", Message: "invalid IP address format"})
 	}
@@ -105,9 +108,12 @@ func validateAddress(addr model.Address, index int) ValidationErrors {
 		}
 	}
 
-	// Type length validation (type is a freeform label, not strictly validated)
+	// Type length validation (type is a freeform label, but ipv4 and ipv6
+	// must match the IP)
 	if len(addr.Type) > 64 {
 		errs = append(errs, ValidationError{Field: fieldPrefix + ".type", Message: "type must be 64 characters or less"})
+	} else if _, err := model.ResolveAddressType(addr.IP, addr.Type); err != nil {
+		errs = append(errs, ValidationError{Field: fieldPrefix + ".type", Message: err.Error()})
 	}
 
 	// Label length check
@@ -115,6 +121,13 @@ func validateAddress(addr model.Address, index int) ValidationErrors {
 		errs = append(errs, ValidationError{Field: fieldPrefix + ".label", Message: "label must be 128 characters or less"})
 	}
 
+	// MAC address validation (if provided)
+	if addr.MACAddress != "" {
+		if _, ok := model.NormalizeMAC(addr.MACAddress); !ok {
+			errs = append(errs, ValidationError{Field: fieldPrefix + ".mac_address", Message: "invalid MAC address format"})
+		}
+	}
+
 	return errs
 }
 
@@ -222,15 +235,6 @@ func isValidCIDR(cidr string) bool {
 	return err == nil
 }
 
-var hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*$`)
-
-func isValidHostname(hostname string) bool {
-	if len(hostname) > 253 {
-		return false
-	}
-	return hostnameRegex.MatchString(hostname)
-}
-
 var domainRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*$`)
 
 func isValidDomain(domain string) bool {
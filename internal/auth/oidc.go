@@ -0,0 +1,404 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// OIDCConfig configures login through an OpenID Connect provider
+type OIDCConfig struct {
+	IssuerURL     string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string   // rackd's callback URL, registered with the provider
+	Scopes        []string // requested scopes; "openid" is always added
+	UsernameClaim string   // claim used as the rackd username
+	GroupsClaim   string   // claim listing the user's groups
+}
+
+// OIDCIdentity is the user a provider authenticated
+type OIDCIdentity struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Email    string
+	FullName string
+	Groups   []string
+}
+
+// oidcMetadata is the part of the provider's discovery document rackd uses
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider runs the authorization code flow (with PKCE) against an
+// OpenID Connect provider
+type OIDCProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *oidcMetadata
+
+	keysMu        sync.Mutex
+	keys          *jose.JSONWebKeySet
+	keysFetchedAt time.Time
+}
+
+// oidcClockSkew is how far the provider's clock may be ahead of ours
+const oidcClockSkew = time.Minute
+
+// oidcKeysRefetchInterval limits how often an ID token signed with an
+// unknown key makes rackd fetch the provider's keys again
+const oidcKeysRefetchInterval = time.Minute
+
+// oidcSigningAlgorithms are the ID token signature algorithms accepted. The
+// symmetric HS algorithms are not, as rackd only trusts the provider's keys.
+var oidcSigningAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+var errNoOIDCKey = errors.New("no provider key matches the token")
+
+func NewOIDCProvider(cfg OIDCConfig) *OIDCProvider {
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "preferred_username"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	return &OIDCProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// discover fetches the provider's discovery document. It is cached once
+// fetched, so an unreachable provider at startup doesn't disable login.
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	issuer := strings.TrimSuffix(p.cfg.IssuerURL, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var md oidcMetadata
+	if err := p.doJSON(req, &md); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimSuffix(md.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match %q", md.Issuer, p.cfg.IssuerURL)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: authorization, token or jwks endpoint missing")
+	}
+	p.metadata = &md
+	return p.metadata, nil
+}
+
+// AuthCodeURL returns the provider URL to send the browser to. state, nonce
+// and codeVerifier must be kept to complete the login with Exchange.
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(md.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return md.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the identity in the ID
+// token, with groups from the userinfo endpoint if the token has none. The ID
+// token's signature is checked against the provider's published keys, and
+// its issuer, audience, expiry and nonce against this login.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.cfg.ClientSecret == "" {
+		// Public client
+		form.Set("client_id", p.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	if err := p.doJSON(req, &tokens); err != nil {
+		return nil, fmt.Errorf("oidc token exchange: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("oidc token exchange: no id_token in response")
+	}
+
+	claims, err := p.verifyIDToken(ctx, md.JWKSURI, tokens.IDToken)
+	if err != nil {
+		return nil, fmt.Errorf("oidc id_token: %w", err)
+	}
+	if err := p.validateIDToken(claims, md.Issuer, nonce, time.Now()); err != nil {
+		return nil, fmt.Errorf("oidc id_token: %w", err)
+	}
+
+	if _, ok := claims[p.cfg.GroupsClaim]; !ok && md.UserinfoEndpoint != "" && tokens.AccessToken != "" {
+		if err := p.mergeUserinfo(ctx, md.UserinfoEndpoint, tokens.AccessToken, claims); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.identity(claims)
+}
+
+func (p *OIDCProvider) validateIDToken(claims map[string]any, issuer, nonce string, now time.Time) error {
+	if iss, _ := claims["iss"].(string); iss != issuer {
+		return fmt.Errorf("issuer %q does not match %q", iss, issuer)
+	}
+
+	var audiences []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		audiences = []string{aud}
+	case []any:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+	}
+	if !slices.Contains(audiences, p.cfg.ClientID) {
+		return errors.New("not issued for this client")
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != p.cfg.ClientID {
+		return errors.New("authorized party is not this client")
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok || now.After(time.Unix(int64(exp), 0).Add(oidcClockSkew)) {
+		return errors.New("expired")
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return errors.New("nonce mismatch")
+	}
+	return nil
+}
+
+// mergeUserinfo adds the userinfo endpoint's claims to claims, keeping those
+// already in the ID token
+func (p *OIDCProvider) mergeUserinfo(ctx context.Context, endpoint, accessToken string, claims map[string]any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	var info map[string]any
+	if err := p.doJSON(req, &info); err != nil {
+		return fmt.Errorf("oidc userinfo: %w", err)
+	}
+	if sub, _ := info["sub"].(string); sub != claims["sub"] {
+		return errors.New("oidc userinfo: subject does not match id_token")
+	}
+	for k, v := range info {
+		if _, ok := claims[k]; !ok {
+			claims[k] = v
+		}
+	}
+	return nil
+}
+
+func (p *OIDCProvider) identity(claims map[string]any) (*OIDCIdentity, error) {
+	str := func(name string) string {
+		s, _ := claims[name].(string)
+		return s
+	}
+
+	id := &OIDCIdentity{
+		Issuer:   str("iss"),
+		Subject:  str("sub"),
+		Username: str(p.cfg.UsernameClaim),
+		Email:    str("email"),
+		FullName: str("name"),
+	}
+	if id.Subject == "" {
+		return nil, errors.New("oidc id_token: no subject")
+	}
+	if id.Username == "" {
+		id.Username = id.Email
+	}
+	if id.Username == "" {
+		return nil, fmt.Errorf("oidc id_token: no %s or email claim to use as the username", p.cfg.UsernameClaim)
+	}
+
+	switch groups := claims[p.cfg.GroupsClaim].(type) {
+	case []any:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				id.Groups = append(id.Groups, s)
+			}
+		}
+	case string:
+		id.Groups = strings.Fields(groups)
+	}
+	return id, nil
+}
+
+func (p *OIDCProvider) doJSON(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d: %s", req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// verifyIDToken checks the signature of an ID token and returns its claims.
+// A token signed with a key rackd hasn't seen makes it fetch the provider's
+// keys again, so a provider rotating its keys doesn't break login.
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, jwksURI, token string) (map[string]any, error) {
+	jws, err := jose.ParseSigned(token, oidcSigningAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	if len(jws.Signatures) != 1 {
+		return nil, errors.New("malformed token: expected one signature")
+	}
+
+	payload, err := p.verifySignature(ctx, jwksURI, jws, false)
+	if errors.Is(err, errNoOIDCKey) {
+		payload, err = p.verifySignature(ctx, jwksURI, jws, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	return claims, nil
+}
+
+// verifySignature returns the payload of jws if one of the provider's
+// signing keys verifies it
+func (p *OIDCProvider) verifySignature(ctx context.Context, jwksURI string, jws *jose.JSONWebSignature, refresh bool) ([]byte, error) {
+	keys, err := p.signingKeys(ctx, jwksURI, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := keys.Keys
+	if kid := jws.Signatures[0].Header.KeyID; kid != "" {
+		candidates = keys.Key(kid)
+	}
+	matched := false
+	for _, key := range candidates {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		matched = true
+		if payload, err := jws.Verify(key); err == nil {
+			return payload, nil
+		}
+	}
+	if !matched {
+		return nil, errNoOIDCKey
+	}
+	return nil, errors.New("invalid signature")
+}
+
+// signingKeys returns the provider's key set, fetching it on first use or
+// when refresh is set and it was last fetched long enough ago
+func (p *OIDCProvider) signingKeys(ctx context.Context, jwksURI string, refresh bool) (*jose.JSONWebKeySet, error) {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+	if p.keys != nil && (!refresh || time.Since(p.keysFetchedAt) < oidcKeysRefetchInterval) {
+		return p.keys, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var keys jose.JSONWebKeySet
+	if err := p.doJSON(req, &keys); err != nil {
+		return nil, fmt.Errorf("oidc keys: %w", err)
+	}
+	p.keys, p.keysFetchedAt = &keys, time.Now()
+	return p.keys, nil
+}
+
+// GenerateOIDCSecret returns a random value for a state, nonce or PKCE code
+// verifier
+func GenerateOIDCSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
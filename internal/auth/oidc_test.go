@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// fakeOIDCProvider is an OIDC provider whose token endpoint returns an ID
+// token with claims signed by key, checking the PKCE verifier against the
+// last challenge
+type fakeOIDCProvider struct {
+	*httptest.Server
+	key       jose.JSONWebKey
+	claims    map[string]any
+	userinfo  map[string]any
+	challenge string
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+	p := &fakeOIDCProvider{key: newSigningKey(t, "key-1")}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"userinfo_endpoint":      p.URL + "/userinfo",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{p.key.Public()}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "rackd" || secret != "s3cret" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"id_token":     signJWT(t, p.key, p.claims),
+		})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(p.userinfo)
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func newSigningKey(t *testing.T, kid string) jose.JSONWebKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	return jose.JSONWebKey{Key: key, KeyID: kid, Algorithm: string(jose.ES256), Use: "sig"}
+}
+
+func signJWT(t *testing.T, key jose.JSONWebKey, claims map[string]any) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, nil)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	payload, _ := json.Marshal(claims)
+	jws, err := signer.Sign(payload)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	token, err := jws.CompactSerialize()
+	if err != nil {
+		t.Fatalf("CompactSerialize failed: %v", err)
+	}
+	return token
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	idp := newFakeOIDCProvider(t)
+	provider := NewOIDCProvider(OIDCConfig{
+		IssuerURL:    idp.URL,
+		ClientID:     "rackd",
+		ClientSecret: "s3cret",
+		RedirectURL:  "https://rackd.example.com/api/auth/oidc/callback",
+		Scopes:       []string{"profile", "email"},
+	})
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if u.Path != "/authorize" || q.Get("state") != "state-1" || q.Get("nonce") != "nonce-1" ||
+		q.Get("scope") != "openid profile email" || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected auth URL %s", authURL)
+	}
+	idp.challenge = q.Get("code_challenge")
+
+	valid := func() map[string]any {
+		return map[string]any{
+			"iss":                idp.URL,
+			"aud":                []any{"rackd"},
+			"sub":                "user-123",
+			"exp":                float64(time.Now().Add(time.Hour).Unix()),
+			"nonce":              "nonce-1",
+			"preferred_username": "alice",
+			"email":              "alice@example.com",
+			"name":               "Alice",
+		}
+	}
+	idp.userinfo = map[string]any{"sub": "user-123", "groups": []any{"netops", "staff"}}
+
+	idp.claims = valid()
+	id, err := provider.Exchange(ctx, "good-code", "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if id.Username != "alice" || id.Email != "alice@example.com" || id.FullName != "Alice" ||
+		strings.Join(id.Groups, ",") != "netops,staff" {
+		t.Fatalf("unexpected identity %+v", id)
+	}
+
+	if _, err := provider.Exchange(ctx, "good-code", "wrong-verifier", "nonce-1"); err == nil {
+		t.Fatal("expected a wrong PKCE verifier to be refused")
+	}
+
+	tests := []struct {
+		name   string
+		modify func(map[string]any)
+		want   string
+	}{
+		{"wrong issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }, "issuer"},
+		{"wrong audience", func(c map[string]any) { c["aud"] = "someone-else" }, "not issued for this client"},
+		{"expired", func(c map[string]any) { c["exp"] = float64(time.Now().Add(-time.Hour).Unix()) }, "expired"},
+		{"wrong nonce", func(c map[string]any) { c["nonce"] = "replayed" }, "nonce"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.claims = valid()
+			tt.modify(idp.claims)
+			_, err := provider.Exchange(ctx, "good-code", "verifier-1", "nonce-1")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestOIDCProviderVerifiesSignature(t *testing.T) {
+	idp := newFakeOIDCProvider(t)
+	provider := NewOIDCProvider(OIDCConfig{
+		IssuerURL:    idp.URL,
+		ClientID:     "rackd",
+		ClientSecret: "s3cret",
+		RedirectURL:  "https://rackd.example.com/api/auth/oidc/callback",
+	})
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	idp.challenge = u.Query().Get("code_challenge")
+	claims := map[string]any{
+		"iss":                idp.URL,
+		"aud":                "rackd",
+		"sub":                "user-123",
+		"exp":                float64(time.Now().Add(time.Hour).Unix()),
+		"nonce":              "nonce-1",
+		"preferred_username": "alice",
+		"groups":             []any{"staff"},
+	}
+	idp.claims = claims
+	if _, err := provider.Exchange(ctx, "good-code", "verifier-1", "nonce-1"); err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+
+	// The provider rotates to a new key: the keys are fetched again, though
+	// not more than once per interval
+	idp.key = newSigningKey(t, "key-2")
+	if _, err := provider.Exchange(ctx, "good-code", "verifier-1", "nonce-1"); err == nil {
+		t.Fatal("expected the keys not to be refetched straight after fetching them")
+	}
+	provider.keysFetchedAt = time.Now().Add(-oidcKeysRefetchInterval)
+	if _, err := provider.Exchange(ctx, "good-code", "verifier-1", "nonce-1"); err != nil {
+		t.Fatalf("Exchange after key rotation failed: %v", err)
+	}
+}
+
+func TestOIDCProviderRejectsForgedToken(t *testing.T) {
+	trusted := newSigningKey(t, "key-1")
+	keys := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{trusted.Public()}}
+	provider := NewOIDCProvider(OIDCConfig{ClientID: "rackd"})
+	provider.keys, provider.keysFetchedAt = keys, time.Now()
+	claims := map[string]any{"sub": "admin"}
+
+	if _, err := provider.verifyIDToken(context.Background(), "", signJWT(t, trusted, claims)); err != nil {
+		t.Fatalf("expected a token signed with the provider key to verify, got %v", err)
+	}
+
+	payload, _ := json.Marshal(claims)
+	unsigned := "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(payload) + "."
+	if _, err := provider.verifyIDToken(context.Background(), "", unsigned); err == nil {
+		t.Error("expected an unsigned token to be refused")
+	}
+
+	// Signed with another key that claims the provider's key ID
+	forger := newSigningKey(t, "key-1")
+	_, err := provider.verifyIDToken(context.Background(), "", signJWT(t, forger, claims))
+	if err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("expected a forged signature to be refused, got %v", err)
+	}
+}
+
+func TestOIDCProviderIdentityFallsBackToEmail(t *testing.T) {
+	provider := NewOIDCProvider(OIDCConfig{ClientID: "rackd"})
+	id, err := provider.identity(map[string]any{"sub": "x", "email": "bob@example.com", "groups": "a b"})
+	if err != nil {
+		t.Fatalf("identity failed: %v", err)
+	}
+	if id.Username != "bob@example.com" || strings.Join(id.Groups, ",") != "a,b" {
+		t.Fatalf("unexpected identity %+v", id)
+	}
+
+	if _, err := provider.identity(map[string]any{"sub": "x"}); err == nil {
+		t.Fatal("expected an error without a username or email claim")
+	}
+}
@@ -0,0 +1,193 @@
+// Package backup writes consistent snapshots of the rackd database to a
+// backups directory or an S3 bucket and prunes the ones past their retention.
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+
+	_ "modernc.org/sqlite"
+)
+
+// nameFormat is the timestamp layout of backup names, rackd-<timestamp>.db
+const nameFormat = "20060102-150405"
+
+var namePattern = regexp.MustCompile(`^rackd-\d{8}-\d{6}\.db$`)
+
+var ErrNotFound = errors.New("backup not found")
+
+// Destination is where finished backups are kept
+type Destination interface {
+	// Store moves the backup file at path into the destination under name
+	// and returns where it now lives
+	Store(ctx context.Context, name, path string) (string, error)
+	// List returns the backups in the destination, in any order
+	List(ctx context.Context) ([]model.Backup, error)
+	// Fetch copies the named backup to the local file dst
+	Fetch(ctx context.Context, name, dst string) error
+	Delete(ctx context.Context, name string) error
+}
+
+// Manager takes backups of a database file and applies the retention
+type Manager struct {
+	dbPath        string
+	stagingDir    string
+	dest          Destination
+	retentionDays int
+	mu            sync.Mutex
+	now           func() time.Time
+}
+
+// NewManager creates a manager backing up the database at dbPath. Backups
+// are written to stagingDir before being stored in dest. A retention of 0
+// keeps every backup.
+func NewManager(dbPath, stagingDir string, dest Destination, retentionDays int) *Manager {
+	return &Manager{
+		dbPath:        dbPath,
+		stagingDir:    stagingDir,
+		dest:          dest,
+		retentionDays: retentionDays,
+		now:           time.Now,
+	}
+}
+
+// FromConfig creates a manager for the server's database. Backups go to the
+// S3 bucket when BACKUP_S3_BUCKET is set and to BACKUP_DIR otherwise.
+func FromConfig(cfg *config.Config) *Manager {
+	dir := cfg.BackupDir
+	if dir == "" {
+		dir = filepath.Join(cfg.DataDir, "backups")
+	}
+
+	var dest Destination = NewDir(dir)
+	if cfg.BackupS3Bucket != "" {
+		dest = NewS3(S3Config{
+			Bucket:          cfg.BackupS3Bucket,
+			Prefix:          cfg.BackupS3Prefix,
+			Region:          cfg.BackupS3Region,
+			Endpoint:        cfg.BackupS3Endpoint,
+			AccessKeyID:     cfg.BackupS3AccessKeyID,
+			SecretAccessKey: cfg.BackupS3SecretKey,
+			SessionToken:    cfg.BackupS3Token,
+		})
+	}
+	return NewManager(filepath.Join(cfg.DataDir, "rackd.db"), dir, dest, cfg.BackupRetentionDays)
+}
+
+// Create writes a new backup and prunes the ones past the retention.
+// Failing to prune is logged rather than returned: the backup itself was
+// taken.
+func (m *Manager) Create(ctx context.Context) (*model.Backup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.stagingDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	now := m.now().UTC()
+	name := "rackd-" + now.Format(nameFormat) + ".db"
+	staged := filepath.Join(m.stagingDir, name+".tmp")
+	if err := Snapshot(ctx, m.dbPath, staged); err != nil {
+		os.Remove(staged)
+		return nil, err
+	}
+	defer os.Remove(staged)
+
+	info, err := os.Stat(staged)
+	if err != nil {
+		return nil, err
+	}
+	location, err := m.dest.Store(ctx, name, staged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store backup: %w", err)
+	}
+
+	if _, err := m.prune(ctx); err != nil {
+		log.Warn("Failed to prune old backups", "error", err)
+	}
+	return &model.Backup{Name: name, Location: location, Size: info.Size(), CreatedAt: now.Truncate(time.Second)}, nil
+}
+
+// List returns the stored backups, newest first
+func (m *Manager) List(ctx context.Context) ([]model.Backup, error) {
+	backups, err := m.dest.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name > backups[j].Name })
+	return backups, nil
+}
+
+// Fetch copies the named backup to the local file dst
+func (m *Manager) Fetch(ctx context.Context, name, dst string) error {
+	if !namePattern.MatchString(name) {
+		return ErrNotFound
+	}
+	return m.dest.Fetch(ctx, name, dst)
+}
+
+// prune deletes the backups older than the retention, always keeping the
+// newest so a stalled schedule never leaves nothing to restore
+func (m *Manager) prune(ctx context.Context) (int, error) {
+	if m.retentionDays <= 0 {
+		return 0, nil
+	}
+	backups, err := m.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := m.now().UTC().AddDate(0, 0, -m.retentionDays)
+	deleted := 0
+	for i, b := range backups {
+		if i == 0 || !b.CreatedAt.Before(cutoff) {
+			continue
+		}
+		if err := m.dest.Delete(ctx, b.Name); err != nil {
+			return deleted, fmt.Errorf("failed to delete backup %s: %w", b.Name, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Snapshot writes a consistent copy of the database at dbPath to dst with
+// VACUUM INTO, which is safe while the server is writing. It uses its own
+// connection so the server's writer stays free; dst must not exist.
+func Snapshot(ctx context.Context, dbPath, dst string) error {
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("database not found at %s", dbPath)
+	}
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, `VACUUM INTO ?`, dst); err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	return nil
+}
+
+// createdAt returns the time encoded in a backup name
+func createdAt(name string) (time.Time, bool) {
+	if !namePattern.MatchString(name) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(nameFormat, name[len("rackd-"):len(name)-len(".db")])
+	return t, err == nil
+}
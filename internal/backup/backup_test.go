@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestDatabase creates a SQLite database file holding a single row
+func newTestDatabase(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rackd.db")
+	db, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE devices (name TEXT); INSERT INTO devices VALUES ('web-01')`); err != nil {
+		t.Fatalf("failed to populate database: %v", err)
+	}
+	return path
+}
+
+func TestManagerCreate(t *testing.T) {
+	dbPath := newTestDatabase(t)
+	dir := t.TempDir()
+	m := NewManager(dbPath, dir, NewDir(dir), 0)
+	m.now = func() time.Time { return time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC) }
+
+	b, err := m.Create(context.Background())
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if b.Name != "rackd-20261015-020000.db" || b.Location != filepath.Join(dir, b.Name) || b.Size == 0 {
+		t.Errorf("unexpected backup: %+v", b)
+	}
+
+	db, err := sql.Open("sqlite", b.Location)
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer db.Close()
+	var name string
+	if err := db.QueryRow(`SELECT name FROM devices`).Scan(&name); err != nil || name != "web-01" {
+		t.Errorf("expected the backup to hold the data, got %q, %v", name, err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the backup in the directory, got %d entries", len(entries))
+	}
+}
+
+func TestManagerPrune(t *testing.T) {
+	dbPath := newTestDatabase(t)
+	dir := t.TempDir()
+	m := NewManager(dbPath, dir, NewDir(dir), 7)
+	ctx := context.Background()
+
+	for _, day := range []int{1, 5, 14} {
+		m.now = func() time.Time { return time.Date(2026, 10, day, 2, 0, 0, 0, time.UTC) }
+		if _, err := m.Create(ctx); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	backups, err := m.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Name != "rackd-20261014-020000.db" {
+		t.Errorf("expected only the newest backup to remain, got %+v", backups)
+	}
+
+	// The newest backup is kept however old it is
+	m.now = func() time.Time { return time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC) }
+	if n, err := m.prune(ctx); err != nil || n != 0 {
+		t.Errorf("expected the last backup to be kept, pruned %d, %v", n, err)
+	}
+}
+
+func TestManagerFetch(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(newTestDatabase(t), dir, NewDir(dir), 0)
+	ctx := context.Background()
+
+	b, err := m.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	dst := filepath.Join(t.TempDir(), "restore.db")
+	if err := m.Fetch(ctx, b.Name, dst); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if info, err := os.Stat(dst); err != nil || info.Size() != b.Size {
+		t.Errorf("expected the fetched copy to match the backup, got %v, %v", info, err)
+	}
+
+	for _, name := range []string{"rackd-20200101-000000.db", "../rackd.db"} {
+		if err := m.Fetch(ctx, name, dst); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Fetch(%q): expected ErrNotFound, got %v", name, err)
+		}
+	}
+}
+
+func TestSnapshotMissingDatabase(t *testing.T) {
+	dir := t.TempDir()
+	if err := Snapshot(context.Background(), filepath.Join(dir, "missing.db"), filepath.Join(dir, "out.db")); err == nil {
+		t.Error("expected an error for a missing database")
+	}
+}
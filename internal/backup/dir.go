@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// Dir keeps backups as files in a local directory
+type Dir struct {
+	path string
+}
+
+// NewDir creates a destination for the directory at path
+func NewDir(path string) *Dir {
+	return &Dir{path: path}
+}
+
+func (d *Dir) Store(_ context.Context, name, path string) (string, error) {
+	if err := os.MkdirAll(d.path, 0700); err != nil {
+		return "", err
+	}
+	dst := filepath.Join(d.path, name)
+	if err := os.Rename(path, dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+func (d *Dir) List(_ context.Context) ([]model.Backup, error) {
+	entries, err := os.ReadDir(d.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []model.Backup
+	for _, e := range entries {
+		created, ok := createdAt(e.Name())
+		if !ok || !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, model.Backup{
+			Name:      e.Name(),
+			Location:  filepath.Join(d.path, e.Name()),
+			Size:      info.Size(),
+			CreatedAt: created,
+		})
+	}
+	return backups, nil
+}
+
+func (d *Dir) Fetch(_ context.Context, name, dst string) error {
+	in, err := os.Open(filepath.Join(d.path, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (d *Dir) Delete(_ context.Context, name string) error {
+	return os.Remove(filepath.Join(d.path, name))
+}
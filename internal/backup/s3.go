@@ -0,0 +1,222 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/awsauth"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// S3Config holds the bucket and credentials of an S3 destination
+type S3Config struct {
+	Bucket          string
+	Prefix          string // key prefix, e.g. "rackd/"
+	Region          string
+	Endpoint        string // for S3-compatible services; defaults to AWS
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3 keeps backups as objects in an S3 bucket, or any S3-compatible store.
+// Requests use path-style URLs and are signed with AWS Signature Version 4,
+// as the EC2 cloud provider does, so no SDK dependency is required.
+type S3 struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 creates an S3 destination. The region defaults to us-east-1.
+func NewS3(cfg S3Config) *S3 {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &S3{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Minute},
+		now:    time.Now,
+	}
+}
+
+func (s *S3) Store(ctx context.Context, name, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(name), f)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/vnd.sqlite3")
+	if err := s.do(req, hex.EncodeToString(h.Sum(nil)), nil); err != nil {
+		return "", err
+	}
+	return "s3://" + s.cfg.Bucket + "/" + s.cfg.Prefix + name, nil
+}
+
+type s3ListResponse struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3) List(ctx context.Context) ([]model.Backup, error) {
+	var backups []model.Backup
+	token := ""
+	for {
+		params := url.Values{}
+		params.Set("list-type", "2")
+		params.Set("prefix", s.cfg.Prefix)
+		if token != "" {
+			params.Set("continuation-token", token)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.Endpoint+"/"+url.PathEscape(s.cfg.Bucket)+"?"+awsauth.EncodeQuery(params), nil)
+		if err != nil {
+			return nil, err
+		}
+		var resp s3ListResponse
+		if err := s.do(req, awsauth.EmptyPayloadHash, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, obj := range resp.Contents {
+			name := strings.TrimPrefix(obj.Key, s.cfg.Prefix)
+			created, ok := createdAt(name)
+			if !ok {
+				continue
+			}
+			backups = append(backups, model.Backup{
+				Name:      name,
+				Location:  "s3://" + s.cfg.Bucket + "/" + obj.Key,
+				Size:      obj.Size,
+				CreatedAt: created,
+			})
+		}
+
+		if !resp.IsTruncated || resp.NextContinuationToken == "" {
+			return backups, nil
+		}
+		token = resp.NextContinuationToken
+	}
+}
+
+func (s *S3) Fetch(ctx context.Context, name, dst string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(name), nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, awsauth.EmptyPayloadHash)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (s *S3) Delete(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(name), nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, awsauth.EmptyPayloadHash, nil)
+}
+
+func (s *S3) objectURL(name string) string {
+	key := s.cfg.Prefix + name
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return s.cfg.Endpoint + "/" + url.PathEscape(s.cfg.Bucket) + "/" + strings.Join(segments, "/")
+}
+
+// do signs and sends req, decoding an XML response into out when set
+func (s *S3) do(req *http.Request, payloadHash string, out any) error {
+	s.sign(req, payloadHash)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return s3Error(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func s3Error(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var apiErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if xml.Unmarshal(data, &apiErr) == nil && apiErr.Code != "" {
+		return fmt.Errorf("%s: %s", apiErr.Code, apiErr.Message)
+	}
+	return fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (s *S3) sign(req *http.Request, payloadHash string) {
+	creds := awsauth.Credentials{
+		AccessKeyID:     s.cfg.AccessKeyID,
+		SecretAccessKey: s.cfg.SecretAccessKey,
+		SessionToken:    s.cfg.SessionToken,
+	}
+	awsauth.Sign(req, s.cfg.Region, "s3", creds, payloadHash, s.now())
+}
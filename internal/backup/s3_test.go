@@ -0,0 +1,139 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 serves the object calls the S3 destination makes against one bucket
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/20261015/eu-west-1/s3/aws4_request") ||
+		r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>unsigned request</Message></Error>`)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/backups/")
+	switch {
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == "/backups":
+		prefix := r.URL.Query().Get("prefix")
+		var keys []string
+		for k := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		fmt.Fprint(w, `<ListBucketResult>`)
+		for _, k := range keys {
+			fmt.Fprintf(w, `<Contents><Key>%s</Key><Size>%d</Size></Contents>`, k, len(f.objects[k]))
+		}
+		fmt.Fprint(w, `<IsTruncated>false</IsTruncated></ListBucketResult>`)
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Destination(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{"other/unrelated.txt": []byte("x")}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	dest := NewS3(S3Config{
+		Bucket:          "backups",
+		Prefix:          "rackd/",
+		Region:          "eu-west-1",
+		Endpoint:        srv.URL,
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+	dest.now = func() time.Time { return time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC) }
+
+	dir := t.TempDir()
+	m := NewManager(newTestDatabase(t), dir, dest, 0)
+	m.now = dest.now
+	ctx := context.Background()
+
+	b, err := m.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if b.Location != "s3://backups/rackd/rackd-20261015-020000.db" {
+		t.Errorf("unexpected location %q", b.Location)
+	}
+	if got := len(fake.objects["rackd/rackd-20261015-020000.db"]); int64(got) != b.Size {
+		t.Errorf("expected %d bytes uploaded, got %d", b.Size, got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected the staged copy to be removed, got %d entries", len(entries))
+	}
+
+	backups, err := m.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Name != b.Name || backups[0].Size != b.Size {
+		t.Errorf("unexpected backups: %+v", backups)
+	}
+
+	dst := filepath.Join(t.TempDir(), "restore.db")
+	if err := m.Fetch(ctx, b.Name, dst); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if err := m.Fetch(ctx, "rackd-20200101-000000.db", dst); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := dest.Delete(ctx, b.Name); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok := fake.objects["rackd/"+b.Name]; ok {
+		t.Error("expected the object to be deleted")
+	}
+}
+
+func TestS3Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+	}))
+	defer srv.Close()
+
+	dest := NewS3(S3Config{Bucket: "backups", Endpoint: srv.URL, AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	_, err := dest.List(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("expected the S3 error code, got %v", err)
+	}
+}
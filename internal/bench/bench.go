@@ -0,0 +1,344 @@
+// Package bench measures storage backends under inventory-shaped load:
+// creating devices, listing and searching inventories of different sizes,
+// and a concurrent mix of reads and writes. rackd bench runs it against each
+// backend and prints a comparison table; the package benchmarks run the same
+// operations under go test -bench.
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// Backend is a storage driver and the data source to benchmark it on
+type Backend struct {
+	Name       string
+	Driver     string
+	DataSource string
+}
+
+// Open opens the backend's storage
+func (b Backend) Open() (storage.ExtendedStorage, error) {
+	return storage.Open(b.Driver, b.DataSource)
+}
+
+// SQLiteBackends are the built-in backends: an SQLite database file in dir,
+// and an in-memory SQLite database
+func SQLiteBackends(dir string) []Backend {
+	return []Backend{
+		{Name: "sqlite", Driver: storage.DefaultDriver, DataSource: dir},
+		{Name: "memory", Driver: storage.DefaultDriver, DataSource: ":memory:"},
+	}
+}
+
+// Options sizes a benchmark run
+type Options struct {
+	// Creates is how many devices CreateDevice creates
+	Creates int
+	// Sizes are the inventory sizes the list, search and mixed benchmarks
+	// run at, in increasing order
+	Sizes []int
+	// Duration is how long each timed benchmark runs for
+	Duration time.Duration
+	// Concurrency is how many workers the mixed benchmark runs
+	Concurrency int
+	// WriteRatio is the share of the mixed benchmark's operations that are
+	// writes, between 0 and 1
+	WriteRatio float64
+}
+
+// DefaultOptions are the options rackd bench uses unless told otherwise
+var DefaultOptions = Options{
+	Creates:     1000,
+	Sizes:       []int{1000, 10000, 100000},
+	Duration:    2 * time.Second,
+	Concurrency: 8,
+	WriteRatio:  0.2,
+}
+
+// Validate checks the options describe a run that can be made
+func (o Options) Validate() error {
+	switch {
+	case o.Creates < 1:
+		return errors.New("creates must be at least 1")
+	case o.Duration <= 0:
+		return errors.New("duration must be positive")
+	case o.Concurrency < 1:
+		return errors.New("concurrency must be at least 1")
+	case o.WriteRatio < 0 || o.WriteRatio > 1:
+		return errors.New("write ratio must be between 0 and 1")
+	}
+	// The inventory only grows, so each size has to be reachable from the
+	// devices CreateDevice left behind
+	last := o.Creates
+	for _, size := range o.Sizes {
+		if size < last {
+			return fmt.Errorf("sizes must be increasing and at least creates (%d), got %d", o.Creates, size)
+		}
+		last = size
+	}
+	return nil
+}
+
+// Result is one benchmark on one backend
+type Result struct {
+	Backend   string        `json:"backend"`
+	Benchmark string        `json:"benchmark"`
+	Ops       int           `json:"ops"`
+	Errors    int           `json:"errors"`
+	Elapsed   time.Duration `json:"elapsed_ns"`
+	Avg       time.Duration `json:"avg_ns"`
+	P95       time.Duration `json:"p95_ns"`
+}
+
+// OpsPerSec is the throughput of the benchmark
+func (r Result) OpsPerSec() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Ops) / r.Elapsed.Seconds()
+}
+
+// Run benchmarks a backend, reporting each benchmark as it starts. The
+// backend should start out empty.
+func Run(ctx context.Context, backend Backend, opts Options, progress func(string)) ([]Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if progress == nil {
+		progress = func(string) {}
+	}
+
+	store, err := backend.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", backend.Name, err)
+	}
+	defer store.Close()
+
+	var results []Result
+	add := func(name string, r Result) {
+		r.Backend, r.Benchmark = backend.Name, name
+		results = append(results, r)
+	}
+
+	progress(fmt.Sprintf("%s: CreateDevice x%d", backend.Name, opts.Creates))
+	ids := make([]string, 0, opts.Creates)
+	r, err := timeN(opts.Creates, func(i int) error {
+		d := NewDevice(i)
+		if err := store.CreateDevice(ctx, d); err != nil {
+			return err
+		}
+		ids = append(ids, d.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: CreateDevice: %w", backend.Name, err)
+	}
+	add("CreateDevice", r)
+
+	for _, size := range opts.Sizes {
+		progress(fmt.Sprintf("%s: growing inventory to %d devices", backend.Name, size))
+		for i := len(ids); i < size; i++ {
+			d := NewDevice(i)
+			if err := store.CreateDevice(ctx, d); err != nil {
+				return nil, fmt.Errorf("%s: seed device %d: %w", backend.Name, i, err)
+			}
+			ids = append(ids, d.ID)
+		}
+
+		progress(fmt.Sprintf("%s: ListDevices, SearchDevices and mixed load at %d devices", backend.Name, size))
+		add(fmt.Sprintf("ListDevices/%d", size), timeFor(opts.Duration, func(int) error {
+			_, err := store.ListDevices(ctx, &model.DeviceFilter{})
+			return err
+		}))
+		add(fmt.Sprintf("SearchDevices/%d", size), timeFor(opts.Duration, func(i int) error {
+			_, err := store.SearchDevices(ctx, SearchQuery(i))
+			return err
+		}))
+		add(fmt.Sprintf("Mixed/%d", size), mixed(ctx, store, ids[:size], opts))
+	}
+	return results, nil
+}
+
+// mixed runs concurrent workers that read and update devices for the
+// duration
+func mixed(ctx context.Context, store storage.ExtendedStorage, ids []string, opts Options) Result {
+	workers := max(opts.Concurrency, 1)
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		errors    int
+		wg        sync.WaitGroup
+	)
+
+	start := time.Now()
+	deadline := start.Add(opts.Duration)
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(uint64(w), 0))
+			var own []time.Duration
+			ownErrors := 0
+			for time.Now().Before(deadline) {
+				opStart := time.Now()
+				if err := mixedOp(ctx, store, ids, rng, opts.WriteRatio); err != nil {
+					ownErrors++
+				}
+				own = append(own, time.Since(opStart))
+			}
+			mu.Lock()
+			latencies = append(latencies, own...)
+			errors += ownErrors
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	r := summarize(latencies, time.Since(start))
+	r.Errors = errors
+	return r
+}
+
+// mixedOp is one operation of the mixed benchmark: an update, or a get,
+// list or search
+func mixedOp(ctx context.Context, store storage.ExtendedStorage, ids []string, rng *rand.Rand, writeRatio float64) error {
+	id := ids[rng.IntN(len(ids))]
+	if rng.Float64() < writeRatio {
+		d, err := store.GetDevice(ctx, id)
+		if err != nil {
+			return err
+		}
+		d.Description = fmt.Sprintf("Updated by the benchmark at %s", time.Now().Format(time.RFC3339Nano))
+		return store.UpdateDevice(ctx, d)
+	}
+
+	switch rng.IntN(3) {
+	case 0:
+		_, err := store.GetDevice(ctx, id)
+		return err
+	case 1:
+		_, err := store.ListDevices(ctx, &model.DeviceFilter{Tags: []string{fmt.Sprintf("group-%d", rng.IntN(10))}})
+		return err
+	default:
+		_, err := store.SearchDevices(ctx, SearchQuery(rng.IntN(len(ids))))
+		return err
+	}
+}
+
+// timeN runs op n times, stopping at the first error
+func timeN(n int, op func(i int) error) (Result, error) {
+	latencies := make([]time.Duration, 0, n)
+	start := time.Now()
+	for i := range n {
+		opStart := time.Now()
+		if err := op(i); err != nil {
+			return Result{}, err
+		}
+		latencies = append(latencies, time.Since(opStart))
+	}
+	return summarize(latencies, time.Since(start)), nil
+}
+
+// timeFor runs op repeatedly for the duration, counting errors
+func timeFor(d time.Duration, op func(i int) error) Result {
+	var latencies []time.Duration
+	errors := 0
+	start := time.Now()
+	for i := 0; i == 0 || time.Since(start) < d; i++ {
+		opStart := time.Now()
+		if err := op(i); err != nil {
+			errors++
+		}
+		latencies = append(latencies, time.Since(opStart))
+	}
+	r := summarize(latencies, time.Since(start))
+	r.Errors = errors
+	return r
+}
+
+func summarize(latencies []time.Duration, elapsed time.Duration) Result {
+	r := Result{Ops: len(latencies), Elapsed: elapsed}
+	if len(latencies) == 0 {
+		return r
+	}
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	slices.Sort(latencies)
+	r.Avg = total / time.Duration(len(latencies))
+	r.P95 = latencies[(len(latencies)*95-1)/100]
+	return r
+}
+
+// NewDevice returns the i-th benchmark device. Devices spread over ten tag
+// groups and have unique names and addresses.
+func NewDevice(i int) *model.Device {
+	return &model.Device{
+		Name:        fmt.Sprintf("bench-device-%d", i),
+		Hostname:    fmt.Sprintf("host-%d.bench.local", i),
+		Description: fmt.Sprintf("Benchmark device number %d for performance testing", i),
+		MakeModel:   "Dell PowerEdge R650",
+		OS:          "Ubuntu 24.04 LTS",
+		Kind:        model.DeviceKindPhysical,
+		Status:      model.DeviceStatusActive,
+		Tags:        []string{"bench", fmt.Sprintf("group-%d", i%10)},
+		Addresses: []model.Address{
+			{IP: fmt.Sprintf("10.%d.%d.%d", i>>16&255, i>>8&255, i&255), Type: model.AddressTypeIPv4},
+		},
+	}
+}
+
+// SearchQuery returns the i-th query of a rotation of exact, prefix and
+// broad searches
+func SearchQuery(i int) string {
+	queries := []string{fmt.Sprintf("host-%d", i), "bench-device-1", "Dell", "Ubuntu"}
+	return queries[i%len(queries)]
+}
+
+// WriteTable prints results as a table, one row per benchmark and backend,
+// grouped by benchmark so backends can be compared side by side
+func WriteTable(w io.Writer, results []Result) {
+	var order []string
+	byBenchmark := map[string][]Result{}
+	for _, r := range results {
+		if _, ok := byBenchmark[r.Benchmark]; !ok {
+			order = append(order, r.Benchmark)
+		}
+		byBenchmark[r.Benchmark] = append(byBenchmark[r.Benchmark], r)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BENCHMARK\tBACKEND\tOPS\tOPS/SEC\tAVG\tP95\tERRORS")
+	for _, name := range order {
+		for _, r := range byBenchmark[name] {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%.0f\t%s\t%s\t%d\n",
+				r.Benchmark, r.Backend, r.Ops, r.OpsPerSec(), round(r.Avg), round(r.P95), r.Errors)
+		}
+	}
+	tw.Flush()
+}
+
+// round trims a latency for display
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	case d >= time.Microsecond:
+		return d.Round(10 * time.Nanosecond)
+	}
+	return d
+}
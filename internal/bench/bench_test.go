@@ -0,0 +1,188 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+func TestRun(t *testing.T) {
+	opts := Options{Creates: 20, Sizes: []int{20, 50}, Duration: 20 * time.Millisecond, Concurrency: 4, WriteRatio: 0.5}
+	var results []Result
+	for _, backend := range SQLiteBackends(t.TempDir()) {
+		r, err := Run(context.Background(), backend, opts, nil)
+		if err != nil {
+			t.Fatalf("%s: Run failed: %v", backend.Name, err)
+		}
+		results = append(results, r...)
+	}
+
+	// CreateDevice, then list, search and mixed at each size, per backend
+	if len(results) != 2*7 {
+		t.Fatalf("expected 14 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Ops == 0 || r.Errors != 0 || r.Avg <= 0 || r.P95 < r.Avg/2 {
+			t.Errorf("%s %s: unexpected result %+v", r.Backend, r.Benchmark, r)
+		}
+	}
+	if results[0].Benchmark != "CreateDevice" || results[0].Ops != 20 {
+		t.Errorf("expected 20 creates first, got %+v", results[0])
+	}
+
+	var out bytes.Buffer
+	WriteTable(&out, results)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 15 || !strings.HasPrefix(lines[0], "BENCHMARK") {
+		t.Fatalf("unexpected table:\n%s", out.String())
+	}
+	// Backends are side by side for each benchmark
+	if !strings.HasPrefix(lines[1], "CreateDevice") || !strings.HasPrefix(lines[2], "CreateDevice") ||
+		!strings.Contains(lines[1], "sqlite") || !strings.Contains(lines[2], "memory") {
+		t.Errorf("expected CreateDevice rows for both backends first:\n%s", out.String())
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	if err := DefaultOptions.Validate(); err != nil {
+		t.Errorf("default options invalid: %v", err)
+	}
+	tests := []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"no creates", func(o *Options) { o.Creates = 0 }},
+		{"no duration", func(o *Options) { o.Duration = 0 }},
+		{"no workers", func(o *Options) { o.Concurrency = 0 }},
+		{"write ratio", func(o *Options) { o.WriteRatio = 1.5 }},
+		{"size below creates", func(o *Options) { o.Sizes = []int{500} }},
+		{"decreasing sizes", func(o *Options) { o.Sizes = []int{10000, 1000} }},
+	}
+	for _, tt := range tests {
+		opts := DefaultOptions
+		tt.modify(&opts)
+		if err := opts.Validate(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+// The benchmarks below run the same operations as rackd bench under
+// go test -bench, against the file and in-memory SQLite backends. 100k
+// device inventories are skipped with -short.
+
+func openBackends(b *testing.B) map[string]storage.ExtendedStorage {
+	b.Helper()
+	stores := map[string]storage.ExtendedStorage{}
+	for _, backend := range SQLiteBackends(b.TempDir()) {
+		store, err := backend.Open()
+		if err != nil {
+			b.Fatalf("%s: %v", backend.Name, err)
+		}
+		b.Cleanup(func() { store.Close() })
+		stores[backend.Name] = store
+	}
+	return stores
+}
+
+func fill(b *testing.B, store storage.ExtendedStorage, from, to int) []string {
+	b.Helper()
+	ids := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		d := NewDevice(i)
+		if err := store.CreateDevice(context.Background(), d); err != nil {
+			b.Fatalf("create device %d: %v", i, err)
+		}
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+func benchSizes() []int {
+	if testing.Short() {
+		return []int{1000, 10000}
+	}
+	return []int{1000, 10000, 100000}
+}
+
+func BenchmarkCreateDevice(b *testing.B) {
+	for name, store := range openBackends(b) {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			// Device numbers carry on between runs so names stay unique
+			var n int
+			for b.Loop() {
+				if err := store.CreateDevice(ctx, NewDevice(n)); err != nil {
+					b.Fatal(err)
+				}
+				n++
+			}
+		})
+	}
+}
+
+func BenchmarkListDevices(b *testing.B) {
+	for name, store := range openBackends(b) {
+		count := 0
+		for _, size := range benchSizes() {
+			fill(b, store, count, size)
+			count = size
+			b.Run(fmt.Sprintf("%s/%d", name, size), func(b *testing.B) {
+				ctx := context.Background()
+				for b.Loop() {
+					if _, err := store.ListDevices(ctx, &model.DeviceFilter{}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkSearchDevices(b *testing.B) {
+	for name, store := range openBackends(b) {
+		count := 0
+		for _, size := range benchSizes() {
+			fill(b, store, count, size)
+			count = size
+			b.Run(fmt.Sprintf("%s/%d", name, size), func(b *testing.B) {
+				ctx := context.Background()
+				i := 0
+				for b.Loop() {
+					if _, err := store.SearchDevices(ctx, SearchQuery(i)); err != nil {
+						b.Fatal(err)
+					}
+					i++
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkMixed runs the read/write mix of rackd bench in parallel, 20%
+// writes, at 10k devices
+func BenchmarkMixed(b *testing.B) {
+	for name, store := range openBackends(b) {
+		ids := fill(b, store, 0, 10000)
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			var seed atomic.Uint64
+			b.RunParallel(func(pb *testing.PB) {
+				rng := rand.New(rand.NewPCG(seed.Add(1), 0))
+				for pb.Next() {
+					if err := mixedOp(ctx, store, ids, rng, 0.2); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
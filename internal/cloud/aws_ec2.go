@@ -0,0 +1,301 @@
+package cloud
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/awsauth"
+)
+
+const ec2APIVersion = "2016-11-15"
+
+// AWSEC2 lists instances from the AWS EC2 DescribeInstances API. Requests are
+// signed with AWS Signature Version 4 so no SDK dependency is required.
+type AWSEC2 struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	regions         []string
+	networks        bool
+	client          *http.Client
+
+	// endpoint overrides the regional endpoint, used in tests
+	endpoint string
+	now      func() time.Time
+}
+
+// NewAWSEC2 creates an EC2 provider for the given regions. When no regions
+// are given, us-east-1 is used.
+func NewAWSEC2(accessKeyID, secretAccessKey, sessionToken string, regions []string) *AWSEC2 {
+	if len(regions) == 0 {
+		regions = []string{"us-east-1"}
+	}
+	return &AWSEC2{
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    sessionToken,
+		regions:         regions,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		now:             time.Now,
+	}
+}
+
+// Name returns the provider name
+func (a *AWSEC2) Name() string {
+	return "aws"
+}
+
+// IncludeNetworks makes ListServers also report the VPC and subnet of each
+// instance, which needs the ec2:DescribeVpcs and ec2:DescribeSubnets
+// permissions
+func (a *AWSEC2) IncludeNetworks() {
+	a.networks = true
+}
+
+type ec2DescribeInstancesResponse struct {
+	Reservations []struct {
+		Instances []ec2Instance `xml:"instancesSet>item"`
+	} `xml:"reservationSet>item"`
+	NextToken string `xml:"nextToken"`
+}
+
+type ec2Instance struct {
+	InstanceID       string `xml:"instanceId"`
+	InstanceType     string `xml:"instanceType"`
+	ImageID          string `xml:"imageId"`
+	PrivateIPAddress string `xml:"privateIpAddress"`
+	IPAddress        string `xml:"ipAddress"`
+	VpcID            string `xml:"vpcId"`
+	SubnetID         string `xml:"subnetId"`
+	State            struct {
+		Name string `xml:"name"`
+	} `xml:"instanceState"`
+	Placement struct {
+		AvailabilityZone string `xml:"availabilityZone"`
+	} `xml:"placement"`
+	Tags              []ec2Tag `xml:"tagSet>item"`
+	NetworkInterfaces []struct {
+		IPv6Addresses []struct {
+			Address string `xml:"ipv6Address"`
+		} `xml:"ipv6AddressesSet>item"`
+	} `xml:"networkInterfaceSet>item"`
+}
+
+type ec2Tag struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+type ec2DescribeVpcsResponse struct {
+	Vpcs []struct {
+		VpcID     string   `xml:"vpcId"`
+		CidrBlock string   `xml:"cidrBlock"`
+		Tags      []ec2Tag `xml:"tagSet>item"`
+	} `xml:"vpcSet>item"`
+	NextToken string `xml:"nextToken"`
+}
+
+type ec2DescribeSubnetsResponse struct {
+	Subnets []struct {
+		SubnetID  string   `xml:"subnetId"`
+		CidrBlock string   `xml:"cidrBlock"`
+		Tags      []ec2Tag `xml:"tagSet>item"`
+	} `xml:"subnetSet>item"`
+	NextToken string `xml:"nextToken"`
+}
+
+// ListServers returns all non-terminated instances across the configured regions
+func (a *AWSEC2) ListServers(ctx context.Context) ([]Server, error) {
+	var servers []Server
+	for _, region := range a.regions {
+		regionServers, err := a.listRegion(ctx, region)
+		if err != nil {
+			return nil, fmt.Errorf("region %s: %w", region, err)
+		}
+		servers = append(servers, regionServers...)
+	}
+	return servers, nil
+}
+
+func (a *AWSEC2) listRegion(ctx context.Context, region string) ([]Server, error) {
+	var vpcs, subnets map[string]*Network
+	if a.networks {
+		var err error
+		if vpcs, err = a.listVPCs(ctx, region); err != nil {
+			return nil, err
+		}
+		if subnets, err = a.listSubnets(ctx, region); err != nil {
+			return nil, err
+		}
+	}
+
+	var servers []Server
+	err := ec2Paginate("DescribeInstances", func(params url.Values) (string, error) {
+		var resp ec2DescribeInstancesResponse
+		if err := a.call(ctx, region, params, &resp); err != nil {
+			return "", err
+		}
+		for _, r := range resp.Reservations {
+			for _, inst := range r.Instances {
+				if inst.State.Name == "terminated" || inst.State.Name == "shutting-down" {
+					continue
+				}
+				server := inst.toServer(region)
+				server.Network = vpcs[inst.VpcID]
+				server.Subnet = subnets[inst.SubnetID]
+				servers = append(servers, server)
+			}
+		}
+		return resp.NextToken, nil
+	})
+	return servers, err
+}
+
+func (a *AWSEC2) listVPCs(ctx context.Context, region string) (map[string]*Network, error) {
+	vpcs := make(map[string]*Network)
+	err := ec2Paginate("DescribeVpcs", func(params url.Values) (string, error) {
+		var resp ec2DescribeVpcsResponse
+		if err := a.call(ctx, region, params, &resp); err != nil {
+			return "", err
+		}
+		for _, v := range resp.Vpcs {
+			vpcs[v.VpcID] = &Network{ID: v.VpcID, Name: ec2NameTag(v.Tags), CIDR: v.CidrBlock}
+		}
+		return resp.NextToken, nil
+	})
+	return vpcs, err
+}
+
+func (a *AWSEC2) listSubnets(ctx context.Context, region string) (map[string]*Network, error) {
+	subnets := make(map[string]*Network)
+	err := ec2Paginate("DescribeSubnets", func(params url.Values) (string, error) {
+		var resp ec2DescribeSubnetsResponse
+		if err := a.call(ctx, region, params, &resp); err != nil {
+			return "", err
+		}
+		for _, sn := range resp.Subnets {
+			subnets[sn.SubnetID] = &Network{ID: sn.SubnetID, Name: ec2NameTag(sn.Tags), CIDR: sn.CidrBlock}
+		}
+		return resp.NextToken, nil
+	})
+	return subnets, err
+}
+
+// ec2Paginate calls an EC2 Describe action until page returns no next token
+func ec2Paginate(action string, page func(params url.Values) (string, error)) error {
+	nextToken := ""
+	for {
+		params := url.Values{}
+		params.Set("Action", action)
+		params.Set("Version", ec2APIVersion)
+		params.Set("MaxResults", "1000")
+		if nextToken != "" {
+			params.Set("NextToken", nextToken)
+		}
+
+		var err error
+		if nextToken, err = page(params); err != nil {
+			return err
+		}
+		if nextToken == "" {
+			return nil
+		}
+	}
+}
+
+func ec2NameTag(tags []ec2Tag) string {
+	for _, t := range tags {
+		if t.Key == "Name" {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+func (inst ec2Instance) toServer(region string) Server {
+	server := Server{
+		ID:     inst.InstanceID,
+		Name:   inst.InstanceID,
+		Status: inst.State.Name,
+		Region: region,
+		Type:   inst.InstanceType,
+		Image:  inst.ImageID,
+	}
+
+	labels := make(map[string]string, len(inst.Tags))
+	for _, t := range inst.Tags {
+		if t.Key == "Name" && t.Value != "" {
+			server.Name = t.Value
+			continue
+		}
+		labels[t.Key] = t.Value
+	}
+	server.Tags = labelTags(labels)
+
+	if inst.PrivateIPAddress != "" {
+		server.PrivateIPs = append(server.PrivateIPs, inst.PrivateIPAddress)
+	}
+	if inst.IPAddress != "" {
+		server.PublicIPs = append(server.PublicIPs, inst.IPAddress)
+	}
+	// EC2 IPv6 addresses are globally routable
+	for _, ni := range inst.NetworkInterfaces {
+		for _, v6 := range ni.IPv6Addresses {
+			if v6.Address != "" {
+				server.PublicIPs = append(server.PublicIPs, v6.Address)
+			}
+		}
+	}
+	return server
+}
+
+func (a *AWSEC2) call(ctx context.Context, region string, params url.Values, out any) error {
+	endpoint := a.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://ec2.%s.amazonaws.com", region)
+	}
+
+	body := params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	creds := awsauth.Credentials{
+		AccessKeyID:     a.accessKeyID,
+		SecretAccessKey: a.secretAccessKey,
+		SessionToken:    a.sessionToken,
+	}
+	awsauth.Sign(req, region, "ec2", creds, awsauth.PayloadHash([]byte(body)), a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code    string `xml:"Errors>Error>Code"`
+			Message string `xml:"Errors>Error>Message"`
+		}
+		if xml.Unmarshal(data, &apiErr) == nil && apiErr.Code != "" {
+			return fmt.Errorf("%s: %s", apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if err := xml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
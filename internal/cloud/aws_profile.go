@@ -0,0 +1,107 @@
+package cloud
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AWSProfile holds the static credentials and default region of a profile
+// from the AWS shared config files
+type AWSProfile struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+}
+
+// LoadAWSProfile reads a profile from ~/.aws/credentials and ~/.aws/config,
+// or the files named by AWS_SHARED_CREDENTIALS_FILE and AWS_CONFIG_FILE.
+// Only static access keys are supported, not SSO or assume-role profiles.
+func LoadAWSProfile(profile string) (*AWSProfile, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find home directory: %w", err)
+	}
+	credentialsPath := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsPath == "" {
+		credentialsPath = filepath.Join(home, ".aws", "credentials")
+	}
+	configPath := os.Getenv("AWS_CONFIG_FILE")
+	if configPath == "" {
+		configPath = filepath.Join(home, ".aws", "config")
+	}
+	return loadAWSProfile(credentialsPath, configPath, profile)
+}
+
+func loadAWSProfile(credentialsPath, configPath, profile string) (*AWSProfile, error) {
+	if profile == "" {
+		profile = "default"
+	}
+	// The config file prefixes every profile but the default with "profile "
+	configSection := profile
+	if profile != "default" {
+		configSection = "profile " + profile
+	}
+
+	config, err := readINISection(configPath, configSection)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := readINISection(credentialsPath, profile)
+	if err != nil {
+		return nil, err
+	}
+	// Keys in the credentials file take precedence over the config file
+	for k, v := range creds {
+		config[k] = v
+	}
+
+	p := &AWSProfile{
+		AccessKeyID:     config["aws_access_key_id"],
+		SecretAccessKey: config["aws_secret_access_key"],
+		SessionToken:    config["aws_session_token"],
+		Region:          config["region"],
+	}
+	if p.AccessKeyID == "" || p.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS profile %q has no access keys (SSO and assume-role profiles are not supported)", profile)
+	}
+	return p, nil
+}
+
+// readINISection returns the keys of one section of an INI file. A missing
+// file is treated as empty.
+func readINISection(path, section string) (map[string]string, error) {
+	values := make(map[string]string)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	inSection := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inSection = strings.TrimSpace(line[1:len(line)-1]) == section
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && inSection {
+			values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return values, nil
+}
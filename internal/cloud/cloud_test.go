@@ -0,0 +1,482 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+)
+
+func TestHetznerCloudListServers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprint(w, `{"servers":[{"id":42,"name":"web-1","status":"running","labels":{"env":"prod","role":""},
+				"server_type":{"name":"cx22"},"image":{"name":"ubuntu-24.04","description":"Ubuntu 24.04"},
+				"datacenter":{"location":{"name":"fsn1"}},"public_net":{"ipv4":{"ip":"203.0.113.10"}},
+				"private_net":[{"ip":"10.0.0.2"}]}],"meta":{"pagination":{"next_page":2}}}`)
+		case "2":
+			fmt.Fprint(w, `{"servers":[{"id":43,"name":"db-1","status":"off","labels":{},"server_type":{"name":"cx32"},
+				"image":null,"datacenter":{"location":{"name":"nbg1"}},"public_net":{"ipv4":null},"private_net":[]}],
+				"meta":{"pagination":{"next_page":null}}}`)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewHetznerCloud("secret")
+	p.endpoint = srv.URL
+	servers, err := p.ListServers(context.Background())
+	if err != nil {
+		t.Fatalf("ListServers failed: %v", err)
+	}
+	if len(servers) != 2 {
+		t.Fatalf("expected 2 servers, got %d", len(servers))
+	}
+
+	web := servers[0]
+	if web.ID != "42" || web.Name != "web-1" || web.Region != "fsn1" || web.Type != "cx22" || web.Image != "Ubuntu 24.04" {
+		t.Errorf("unexpected server: %+v", web)
+	}
+	if !slices.Equal(web.Tags, []string{"env=prod", "role"}) {
+		t.Errorf("unexpected tags: %v", web.Tags)
+	}
+	if !slices.Equal(web.PublicIPs, []string{"203.0.113.10"}) || !slices.Equal(web.PrivateIPs, []string{"10.0.0.2"}) {
+		t.Errorf("unexpected addresses: public=%v private=%v", web.PublicIPs, web.PrivateIPs)
+	}
+	if len(servers[1].PublicIPs) != 0 || servers[1].Image != "" {
+		t.Errorf("expected no addresses or image for db-1, got %+v", servers[1])
+	}
+}
+
+func TestHetznerCloudError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	p := NewHetznerCloud("wrong")
+	p.endpoint = srv.URL
+	if _, err := p.ListServers(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
+
+func TestHetznerRobotListServers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "robot" || pass != "pw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"server":{"server_ip":"198.51.100.5","server_number":321,"server_name":"storage-1","product":"SX64","dc":"FSN1-DC14","status":"ready","cancelled":false}},
+			{"server":{"server_ip":"198.51.100.6","server_number":322,"server_name":"","product":"AX41","dc":"HEL1-DC2","status":"ready","cancelled":false}},
+			{"server":{"server_ip":"198.51.100.7","server_number":323,"server_name":"old","product":"AX41","dc":"NBG1-DC3","status":"ready","cancelled":true}}
+		]`)
+	}))
+	defer srv.Close()
+
+	p := NewHetznerRobot("robot", "pw")
+	p.endpoint = srv.URL
+	servers, err := p.ListServers(context.Background())
+	if err != nil {
+		t.Fatalf("ListServers failed: %v", err)
+	}
+	if len(servers) != 2 {
+		t.Fatalf("expected cancelled server to be skipped, got %d servers", len(servers))
+	}
+	if servers[0].ID != "321" || servers[0].Region != "fsn1" || servers[0].Type != "SX64" {
+		t.Errorf("unexpected server: %+v", servers[0])
+	}
+	if servers[1].Name != "robot-322" || servers[1].Region != "hel1" {
+		t.Errorf("expected fallback name and hel1 region, got %+v", servers[1])
+	}
+}
+
+const ec2Page1 = `<?xml version="1.0" encoding="UTF-8"?>
+<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <reservationSet>
+    <item>
+      <instancesSet>
+        <item>
+          <instanceId>i-0abc</instanceId>
+          <imageId>ami-123</imageId>
+          <instanceState><name>running</name></instanceState>
+          <instanceType>t3.micro</instanceType>
+          <privateIpAddress>172.31.0.10</privateIpAddress>
+          <ipAddress>3.3.3.3</ipAddress>
+          <vpcId>vpc-1</vpcId>
+          <subnetId>subnet-1</subnetId>
+          <tagSet>
+            <item><key>Name</key><value>api-1</value></item>
+            <item><key>team</key><value>platform</value></item>
+          </tagSet>
+          <networkInterfaceSet>
+            <item><ipv6AddressesSet><item><ipv6Address>2600:1f18::10</ipv6Address></item></ipv6AddressesSet></item>
+          </networkInterfaceSet>
+        </item>
+        <item>
+          <instanceId>i-0dead</instanceId>
+          <instanceState><name>terminated</name></instanceState>
+        </item>
+      </instancesSet>
+    </item>
+  </reservationSet>
+  <nextToken>page2</nextToken>
+</DescribeInstancesResponse>`
+
+const ec2Page2 = `<?xml version="1.0" encoding="UTF-8"?>
+<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <reservationSet>
+    <item>
+      <instancesSet>
+        <item>
+          <instanceId>i-0def</instanceId>
+          <instanceState><name>stopped</name></instanceState>
+          <instanceType>m5.large</instanceType>
+          <privateIpAddress>172.31.0.11</privateIpAddress>
+        </item>
+      </instancesSet>
+    </item>
+  </reservationSet>
+</DescribeInstancesResponse>`
+
+func TestAWSEC2ListServers(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if r.Form.Get("Action") != "DescribeInstances" || r.Form.Get("Version") != ec2APIVersion {
+			t.Errorf("unexpected request params: %v", r.Form)
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20261015/eu-central-1/ec2/aws4_request") {
+			t.Errorf("unexpected Authorization header: %s", auth)
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Errorf("expected session token header")
+		}
+		if r.Form.Get("NextToken") == "page2" {
+			fmt.Fprint(w, ec2Page2)
+			return
+		}
+		fmt.Fprint(w, ec2Page1)
+	}))
+	defer srv.Close()
+
+	p := NewAWSEC2("AKID", "SECRET", "session", []string{"eu-central-1"})
+	p.endpoint = srv.URL
+	p.now = func() time.Time { return time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC) }
+
+	servers, err := p.ListServers(context.Background())
+	if err != nil {
+		t.Fatalf("ListServers failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 paginated calls, got %d", calls)
+	}
+	if len(servers) != 2 {
+		t.Fatalf("expected terminated instance to be skipped, got %d servers", len(servers))
+	}
+
+	api := servers[0]
+	if api.ID != "i-0abc" || api.Name != "api-1" || api.Region != "eu-central-1" || api.Type != "t3.micro" {
+		t.Errorf("unexpected server: %+v", api)
+	}
+	if !slices.Equal(api.Tags, []string{"team=platform"}) {
+		t.Errorf("unexpected tags: %v", api.Tags)
+	}
+	if !slices.Equal(api.PrivateIPs, []string{"172.31.0.10"}) || !slices.Equal(api.PublicIPs, []string{"3.3.3.3", "2600:1f18::10"}) {
+		t.Errorf("unexpected addresses: private=%v public=%v", api.PrivateIPs, api.PublicIPs)
+	}
+	if servers[1].Name != "i-0def" {
+		t.Errorf("expected instance ID as name when untagged, got %q", servers[1].Name)
+	}
+}
+
+func TestAWSEC2IncludeNetworks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		switch r.Form.Get("Action") {
+		case "DescribeVpcs":
+			fmt.Fprint(w, `<DescribeVpcsResponse><vpcSet><item><vpcId>vpc-1</vpcId><cidrBlock>172.31.0.0/16</cidrBlock>
+				<tagSet><item><key>Name</key><value>prod</value></item></tagSet></item></vpcSet></DescribeVpcsResponse>`)
+		case "DescribeSubnets":
+			fmt.Fprint(w, `<DescribeSubnetsResponse><subnetSet><item><subnetId>subnet-1</subnetId>
+				<cidrBlock>172.31.0.0/20</cidrBlock></item></subnetSet></DescribeSubnetsResponse>`)
+		case "DescribeInstances":
+			if r.Form.Get("NextToken") == "page2" {
+				fmt.Fprint(w, ec2Page2)
+				return
+			}
+			fmt.Fprint(w, ec2Page1)
+		default:
+			t.Errorf("unexpected action %q", r.Form.Get("Action"))
+		}
+	}))
+	defer srv.Close()
+
+	p := NewAWSEC2("AKID", "SECRET", "", []string{"eu-central-1"})
+	p.endpoint = srv.URL
+	p.IncludeNetworks()
+	servers, err := p.ListServers(context.Background())
+	if err != nil {
+		t.Fatalf("ListServers failed: %v", err)
+	}
+	if len(servers) != 2 {
+		t.Fatalf("expected 2 servers, got %d", len(servers))
+	}
+	api := servers[0]
+	if api.Network == nil || *api.Network != (Network{ID: "vpc-1", Name: "prod", CIDR: "172.31.0.0/16"}) {
+		t.Errorf("unexpected network: %+v", api.Network)
+	}
+	if api.Subnet == nil || *api.Subnet != (Network{ID: "subnet-1", CIDR: "172.31.0.0/20"}) {
+		t.Errorf("unexpected subnet: %+v", api.Subnet)
+	}
+	if servers[1].Network != nil || servers[1].Subnet != nil {
+		t.Errorf("expected no network for an instance outside a VPC, got %+v", servers[1])
+	}
+}
+
+func TestLoadAWSProfile(t *testing.T) {
+	dir := t.TempDir()
+	credentialsPath := filepath.Join(dir, "credentials")
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(credentialsPath, []byte(`[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+# Temporary credentials
+[prod]
+aws_access_key_id=AKIDPROD
+aws_secret_access_key=prod-secret
+aws_session_token=prod-token
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte(`[default]
+region = us-west-2
+
+[profile prod]
+region = eu-central-1
+
+[profile sso]
+sso_start_url = https://example.awsapps.com/start
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := loadAWSProfile(credentialsPath, configPath, "")
+	if err != nil {
+		t.Fatalf("loadAWSProfile failed: %v", err)
+	}
+	if *p != (AWSProfile{AccessKeyID: "AKIDDEFAULT", SecretAccessKey: "default-secret", Region: "us-west-2"}) {
+		t.Errorf("unexpected default profile: %+v", p)
+	}
+	p, err = loadAWSProfile(credentialsPath, configPath, "prod")
+	if err != nil {
+		t.Fatalf("loadAWSProfile failed: %v", err)
+	}
+	if *p != (AWSProfile{AccessKeyID: "AKIDPROD", SecretAccessKey: "prod-secret", SessionToken: "prod-token", Region: "eu-central-1"}) {
+		t.Errorf("unexpected prod profile: %+v", p)
+	}
+	if _, err := loadAWSProfile(credentialsPath, configPath, "sso"); err == nil || !strings.Contains(err.Error(), "no access keys") {
+		t.Errorf("expected an error for a profile without keys, got %v", err)
+	}
+	if _, err := loadAWSProfile(filepath.Join(dir, "missing"), configPath, "prod"); err == nil {
+		t.Error("expected an error when the credentials file is missing")
+	}
+}
+
+func TestAWSEC2Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `<Response><Errors><Error><Code>AuthFailure</Code><Message>bad credentials</Message></Error></Errors></Response>`)
+	}))
+	defer srv.Close()
+
+	p := NewAWSEC2("AKID", "SECRET", "", nil)
+	p.endpoint = srv.URL
+	_, err := p.ListServers(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "AuthFailure") || !strings.Contains(err.Error(), "us-east-1") {
+		t.Errorf("expected AuthFailure error for default region, got %v", err)
+	}
+}
+
+func writeKubeconfig(t *testing.T, server string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	data := `apiVersion: v1
+kind: Config
+current-context: prod
+contexts:
+- name: prod
+  context: {cluster: prod-cluster, user: admin}
+- name: plugin
+  context: {cluster: prod-cluster, user: sso}
+clusters:
+- name: prod-cluster
+  cluster: {server: "` + server + `"}
+users:
+- name: admin
+  user: {token: secret}
+- name: sso
+  user:
+    exec: {command: aws}
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKubernetesListServers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.URL.Path != "/api/v1/nodes" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("continue") {
+		case "":
+			fmt.Fprint(w, `{"metadata":{"continue":"next"},"items":[{"metadata":{"uid":"u-1","name":"node-1",
+				"labels":{"topology.kubernetes.io/region":"eu-west-1","node.kubernetes.io/instance-type":"m5.large","role":"worker"}},
+				"status":{"addresses":[{"type":"InternalIP","address":"10.0.0.5"},{"type":"ExternalIP","address":"203.0.113.5"},
+				{"type":"Hostname","address":"node-1"}],"conditions":[{"type":"Ready","status":"True"}],
+				"nodeInfo":{"osImage":"Ubuntu 24.04 LTS","kernelVersion":"6.8.0-1"}}}]}`)
+		case "next":
+			fmt.Fprint(w, `{"metadata":{},"items":[{"metadata":{"uid":"u-2","name":"node-2"},
+				"status":{"conditions":[{"type":"Ready","status":"Unknown"}],"nodeInfo":{}}}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	k, err := NewKubernetesFromKubeconfig(writeKubeconfig(t, srv.URL), "", "")
+	if err != nil {
+		t.Fatalf("NewKubernetesFromKubeconfig failed: %v", err)
+	}
+	if k.Name() != "k8s-prod-cluster" {
+		t.Errorf("unexpected provider name %q", k.Name())
+	}
+	servers, err := k.ListServers(context.Background())
+	if err != nil {
+		t.Fatalf("ListServers failed: %v", err)
+	}
+	if len(servers) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(servers))
+	}
+
+	node := servers[0]
+	if node.ID != "u-1" || node.Name != "node-1" || node.Status != "Ready" || node.Region != "eu-west-1" ||
+		node.Type != "m5.large" || node.Image != "Ubuntu 24.04 LTS (kernel 6.8.0-1)" {
+		t.Errorf("unexpected node: %+v", node)
+	}
+	for _, tag := range []string{"role=worker", "k8s-cluster=prod-cluster"} {
+		if !slices.Contains(node.Tags, tag) {
+			t.Errorf("expected tag %q, got %v", tag, node.Tags)
+		}
+	}
+	if !slices.Equal(node.PrivateIPs, []string{"10.0.0.5"}) || !slices.Equal(node.PublicIPs, []string{"203.0.113.5"}) {
+		t.Errorf("unexpected addresses: private=%v public=%v", node.PrivateIPs, node.PublicIPs)
+	}
+	if servers[1].Status != "NotReady" || servers[1].Image != "" {
+		t.Errorf("unexpected node-2: %+v", servers[1])
+	}
+}
+
+func TestNewKubernetesFromKubeconfig(t *testing.T) {
+	path := writeKubeconfig(t, "https://k8s.example.com/")
+
+	k, err := NewKubernetesFromKubeconfig(path, "prod", "prod")
+	if err != nil {
+		t.Fatalf("NewKubernetesFromKubeconfig failed: %v", err)
+	}
+	if k.Name() != "k8s-prod" || k.endpoint != "https://k8s.example.com" {
+		t.Errorf("unexpected provider: name=%q endpoint=%q", k.Name(), k.endpoint)
+	}
+
+	for _, tc := range []struct{ context, cluster string }{
+		{"missing", ""},
+		{"plugin", ""},
+		{"prod", "has space"},
+	} {
+		if _, err := NewKubernetesFromKubeconfig(path, tc.context, tc.cluster); err == nil {
+			t.Errorf("expected error for context %q cluster %q", tc.context, tc.cluster)
+		}
+	}
+	if _, err := NewKubernetesFromKubeconfig(filepath.Join(t.TempDir(), "none"), "", ""); err == nil {
+		t.Error("expected error for missing kubeconfig")
+	}
+}
+
+func TestParseRegionMap(t *testing.T) {
+	m, err := ParseRegionMap("fsn1=Falkenstein, eu-central-1 = dc-123")
+	if err != nil {
+		t.Fatalf("ParseRegionMap failed: %v", err)
+	}
+	if m["fsn1"] != "Falkenstein" || m["eu-central-1"] != "dc-123" {
+		t.Errorf("unexpected map: %v", m)
+	}
+
+	if m, err := ParseRegionMap(""); err != nil || len(m) != 0 {
+		t.Errorf("expected empty map, got %v, %v", m, err)
+	}
+	for _, bad := range []string{"fsn1", "=dc", "fsn1="} {
+		if _, err := ParseRegionMap(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestProvidersFromConfig(t *testing.T) {
+	if p, _ := ProvidersFromConfig(&config.Config{}); len(p) != 0 {
+		t.Errorf("expected no providers, got %d", len(p))
+	}
+
+	cfg := &config.Config{
+		HetznerCloudToken:    "token",
+		HetznerRobotUser:     "user",
+		HetznerRobotPassword: "pw",
+		AWSAccessKeyID:       "AKID",
+		AWSSecretAccessKey:   "SECRET",
+		AWSRegions:           "eu-west-1, us-east-2",
+	}
+	providers, err := ProvidersFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("ProvidersFromConfig failed: %v", err)
+	}
+	var names []string
+	for _, p := range providers {
+		names = append(names, p.Name())
+	}
+	if !slices.Equal(names, []string{"hetzner", "hetzner-robot", "aws"}) {
+		t.Errorf("unexpected providers: %v", names)
+	}
+	if aws := providers[2].(*AWSEC2); !slices.Equal(aws.regions, []string{"eu-west-1", "us-east-2"}) {
+		t.Errorf("unexpected AWS regions: %v", aws.regions)
+	}
+
+	cfg = &config.Config{K8sKubeconfigs: writeKubeconfig(t, "https://k8s.example.com")}
+	if providers, err = ProvidersFromConfig(cfg); err != nil || len(providers) != 1 || providers[0].Name() != "k8s-prod-cluster" {
+		t.Errorf("expected Kubernetes provider, got %v (%v)", providers, err)
+	}
+	cfg.K8sKubeconfigs = filepath.Join(t.TempDir(), "none")
+	if _, err := ProvidersFromConfig(cfg); err == nil {
+		t.Error("expected error for unreadable kubeconfig")
+	}
+}
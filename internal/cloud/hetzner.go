@@ -0,0 +1,217 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	hetznerCloudEndpoint = "https://api.hetzner.cloud/v1"
+	hetznerRobotEndpoint = "https://robot-ws.your-server.de"
+)
+
+// HetznerCloud lists servers from the Hetzner Cloud API
+type HetznerCloud struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewHetznerCloud creates a Hetzner Cloud provider using an API token
+func NewHetznerCloud(token string) *HetznerCloud {
+	return &HetznerCloud{
+		endpoint: hetznerCloudEndpoint,
+		token:    token,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the provider name
+func (h *HetznerCloud) Name() string {
+	return "hetzner"
+}
+
+type hetznerServersResponse struct {
+	Servers []struct {
+		ID         int64             `json:"id"`
+		Name       string            `json:"name"`
+		Status     string            `json:"status"`
+		Labels     map[string]string `json:"labels"`
+		ServerType struct {
+			Name string `json:"name"`
+		} `json:"server_type"`
+		Image *struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		} `json:"image"`
+		Datacenter struct {
+			Location struct {
+				Name string `json:"name"`
+			} `json:"location"`
+		} `json:"datacenter"`
+		PublicNet struct {
+			IPv4 *struct {
+				IP string `json:"ip"`
+			} `json:"ipv4"`
+		} `json:"public_net"`
+		PrivateNet []struct {
+			IP string `json:"ip"`
+		} `json:"private_net"`
+	} `json:"servers"`
+	Meta struct {
+		Pagination struct {
+			NextPage *int `json:"next_page"`
+		} `json:"pagination"`
+	} `json:"meta"`
+}
+
+// ListServers returns all servers in the project, following pagination
+func (h *HetznerCloud) ListServers(ctx context.Context) ([]Server, error) {
+	var servers []Server
+	page := 1
+	for {
+		var resp hetznerServersResponse
+		url := fmt.Sprintf("%s/servers?page=%d&per_page=50", h.endpoint, page)
+		if err := h.get(ctx, url, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, s := range resp.Servers {
+			server := Server{
+				ID:     strconv.FormatInt(s.ID, 10),
+				Name:   s.Name,
+				Status: s.Status,
+				Region: s.Datacenter.Location.Name,
+				Type:   s.ServerType.Name,
+				Tags:   labelTags(s.Labels),
+			}
+			if s.Image != nil {
+				server.Image = s.Image.Description
+				if server.Image == "" {
+					server.Image = s.Image.Name
+				}
+			}
+			if s.PublicNet.IPv4 != nil && s.PublicNet.IPv4.IP != "" {
+				server.PublicIPs = append(server.PublicIPs, s.PublicNet.IPv4.IP)
+			}
+			for _, pn := range s.PrivateNet {
+				if pn.IP != "" {
+					server.PrivateIPs = append(server.PrivateIPs, pn.IP)
+				}
+			}
+			servers = append(servers, server)
+		}
+
+		if resp.Meta.Pagination.NextPage == nil {
+			return servers, nil
+		}
+		page = *resp.Meta.Pagination.NextPage
+	}
+}
+
+func (h *HetznerCloud) get(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+h.token)
+	return doJSON(h.client, req, out)
+}
+
+// HetznerRobot lists dedicated servers from the Hetzner Robot webservice
+type HetznerRobot struct {
+	endpoint string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewHetznerRobot creates a Hetzner Robot provider using webservice credentials
+func NewHetznerRobot(user, password string) *HetznerRobot {
+	return &HetznerRobot{
+		endpoint: hetznerRobotEndpoint,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the provider name
+func (h *HetznerRobot) Name() string {
+	return "hetzner-robot"
+}
+
+type hetznerRobotServer struct {
+	Server struct {
+		ServerIP     string `json:"server_ip"`
+		ServerNumber int64  `json:"server_number"`
+		ServerName   string `json:"server_name"`
+		Product      string `json:"product"`
+		DC           string `json:"dc"`
+		Status       string `json:"status"`
+		Cancelled    bool   `json:"cancelled"`
+	} `json:"server"`
+}
+
+// ListServers returns all dedicated servers on the account. The region is
+// the location part of the Robot datacenter name, e.g. "fsn1" for
+// "FSN1-DC14", so it lines up with Hetzner Cloud locations.
+func (h *HetznerRobot) ListServers(ctx context.Context) ([]Server, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.endpoint+"/server", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(h.user, h.password)
+
+	var resp []hetznerRobotServer
+	if err := doJSON(h.client, req, &resp); err != nil {
+		return nil, err
+	}
+
+	servers := make([]Server, 0, len(resp))
+	for _, r := range resp {
+		s := r.Server
+		if s.Cancelled {
+			continue
+		}
+		region, _, _ := strings.Cut(s.DC, "-")
+		server := Server{
+			ID:     strconv.FormatInt(s.ServerNumber, 10),
+			Name:   s.ServerName,
+			Status: s.Status,
+			Region: strings.ToLower(region),
+			Type:   s.Product,
+		}
+		if server.Name == "" {
+			server.Name = "robot-" + server.ID
+		}
+		if s.ServerIP != "" {
+			server.PublicIPs = []string{s.ServerIP}
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// doJSON performs req and decodes a JSON response into out
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: unexpected status %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package cloud
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Node labels read into server fields
+const (
+	k8sRegionLabel       = "topology.kubernetes.io/region"
+	k8sInstanceTypeLabel = "node.kubernetes.io/instance-type"
+)
+
+// Kubernetes lists the nodes of a Kubernetes cluster. Each cluster is its own
+// provider, named "k8s-<cluster>", so several clusters can be synced side by
+// side.
+type Kubernetes struct {
+	cluster  string
+	endpoint string
+	client   *http.Client
+	auth     func(req *http.Request)
+}
+
+// kubeconfig is the subset of a kubeconfig file needed to reach a cluster
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+			Username              string `yaml:"username"`
+			Password              string `yaml:"password"`
+			Exec                  any    `yaml:"exec"`
+			AuthProvider          any    `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// NewKubernetesFromKubeconfig creates a provider for the cluster of a
+// kubeconfig context, the current context when contextName is empty. The
+// cluster is named after the context's cluster unless clusterName is set.
+// Token, client certificate and basic authentication are supported; exec and
+// auth-provider plugins are not.
+func NewKubernetesFromKubeconfig(path, contextName, clusterName string) (*Kubernetes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	var kc kubeconfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+
+	if contextName == "" {
+		contextName = kc.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("kubeconfig %s has no current context", path)
+	}
+	ctxIdx := -1
+	for i := range kc.Contexts {
+		if kc.Contexts[i].Name == contextName {
+			ctxIdx = i
+		}
+	}
+	if ctxIdx < 0 {
+		return nil, fmt.Errorf("context %q not found in kubeconfig %s", contextName, path)
+	}
+	kctx := kc.Contexts[ctxIdx].Context
+
+	k := &Kubernetes{cluster: clusterName, auth: func(*http.Request) {}}
+	if k.cluster == "" {
+		k.cluster = kctx.Cluster
+	}
+	if err := ValidProviderName(k.Name()); err != nil {
+		return nil, fmt.Errorf("%w; set a cluster name", err)
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	found := false
+	for _, c := range kc.Clusters {
+		if c.Name != kctx.Cluster {
+			continue
+		}
+		found = true
+		k.endpoint = strings.TrimSuffix(c.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		ca, err := kubeconfigBytes(c.Cluster.CertificateAuthorityData, c.Cluster.CertificateAuthority, dir)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %q certificate authority: %w", c.Name, err)
+		}
+		if ca != nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("cluster %q certificate authority holds no certificates", c.Name)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	if !found || k.endpoint == "" {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig %s", kctx.Cluster, path)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name != kctx.User {
+			continue
+		}
+		user := u.User
+		if user.Exec != nil || user.AuthProvider != nil {
+			return nil, fmt.Errorf("user %q uses an exec or auth-provider plugin, which is not supported; use a token or client certificate", u.Name)
+		}
+
+		token := user.Token
+		if token == "" && user.TokenFile != "" {
+			b, err := os.ReadFile(kubeconfigPath(user.TokenFile, dir))
+			if err != nil {
+				return nil, fmt.Errorf("user %q token file: %w", u.Name, err)
+			}
+			token = strings.TrimSpace(string(b))
+		}
+		switch {
+		case token != "":
+			k.auth = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }
+		case user.Username != "":
+			k.auth = func(req *http.Request) { req.SetBasicAuth(user.Username, user.Password) }
+		}
+
+		cert, err := kubeconfigBytes(user.ClientCertificateData, user.ClientCertificate, dir)
+		if err != nil {
+			return nil, fmt.Errorf("user %q client certificate: %w", u.Name, err)
+		}
+		key, err := kubeconfigBytes(user.ClientKeyData, user.ClientKey, dir)
+		if err != nil {
+			return nil, fmt.Errorf("user %q client key: %w", u.Name, err)
+		}
+		if cert != nil && key != nil {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("user %q client certificate: %w", u.Name, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+	}
+
+	k.client = &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+	return k, nil
+}
+
+// kubeconfigBytes returns base64 data from the kubeconfig, or else the
+// contents of the file it names, resolved against the kubeconfig's directory
+func kubeconfigBytes(data, file, dir string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return os.ReadFile(kubeconfigPath(file, dir))
+	}
+	return nil, nil
+}
+
+func kubeconfigPath(file, dir string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(dir, file)
+}
+
+// Name returns the provider name
+func (k *Kubernetes) Name() string {
+	return "k8s-" + k.cluster
+}
+
+// Cluster returns the cluster name
+func (k *Kubernetes) Cluster() string {
+	return k.cluster
+}
+
+type k8sNodeList struct {
+	Metadata struct {
+		Continue string `json:"continue"`
+	} `json:"metadata"`
+	Items []struct {
+		Metadata struct {
+			UID    string            `json:"uid"`
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Status struct {
+			Addresses []struct {
+				Type    string `json:"type"`
+				Address string `json:"address"`
+			} `json:"addresses"`
+			Conditions []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+			NodeInfo struct {
+				OSImage       string `json:"osImage"`
+				KernelVersion string `json:"kernelVersion"`
+			} `json:"nodeInfo"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// ListServers returns the cluster's nodes. Node labels become tags, along
+// with a k8s-cluster tag; the OS is the node's OS image and kernel version.
+func (k *Kubernetes) ListServers(ctx context.Context) ([]Server, error) {
+	var servers []Server
+	cont := ""
+	for {
+		query := url.Values{"limit": {"500"}}
+		if cont != "" {
+			query.Set("continue", cont)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.endpoint+"/api/v1/nodes?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		k.auth(req)
+
+		var list k8sNodeList
+		if err := doJSON(k.client, req, &list); err != nil {
+			return nil, err
+		}
+
+		for _, n := range list.Items {
+			server := Server{
+				ID:     n.Metadata.UID,
+				Name:   n.Metadata.Name,
+				Status: "NotReady",
+				Region: n.Metadata.Labels[k8sRegionLabel],
+				Type:   n.Metadata.Labels[k8sInstanceTypeLabel],
+				Image:  n.Status.NodeInfo.OSImage,
+				Tags:   append(labelTags(n.Metadata.Labels), "k8s-cluster="+k.cluster),
+			}
+			if kernel := n.Status.NodeInfo.KernelVersion; kernel != "" {
+				server.Image = strings.TrimSpace(server.Image + " (kernel " + kernel + ")")
+			}
+			for _, c := range n.Status.Conditions {
+				if c.Type == "Ready" && c.Status == "True" {
+					server.Status = "Ready"
+				}
+			}
+			for _, addr := range n.Status.Addresses {
+				switch addr.Type {
+				case "InternalIP":
+					server.PrivateIPs = append(server.PrivateIPs, addr.Address)
+				case "ExternalIP":
+					server.PublicIPs = append(server.PublicIPs, addr.Address)
+				}
+			}
+			servers = append(servers, server)
+		}
+
+		if list.Metadata.Continue == "" {
+			return servers, nil
+		}
+		cont = list.Metadata.Continue
+	}
+}
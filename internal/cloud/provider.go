@@ -0,0 +1,125 @@
+// Package cloud pulls server inventories from cloud and hosting providers so
+// they can be synced into rackd alongside on-premises devices.
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+)
+
+// Provider lists the servers of a single cloud account
+type Provider interface {
+	// Name returns the provider name, used in device tags and API calls
+	Name() string
+
+	// ListServers returns every server visible to the account
+	ListServers(ctx context.Context) ([]Server, error)
+}
+
+// Server is a machine reported by a provider. It is also the body of an
+// import, so servers listed outside the server can be synced.
+type Server struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Status     string   `json:"status,omitempty"` // Provider-native status, e.g. "running"
+	Region     string   `json:"region,omitempty"` // Provider region or location, e.g. "fsn1", "eu-central-1"
+	Type       string   `json:"type,omitempty"`   // Server or instance type
+	Image      string   `json:"image,omitempty"`  // Operating system or image name
+	PrivateIPs []string `json:"private_ips,omitempty"`
+	PublicIPs  []string `json:"public_ips,omitempty"`
+	Tags       []string `json:"tags,omitempty"` // Provider labels/tags as "key=value" or "key"
+
+	// Network and Subnet are the private network (an AWS VPC) and subnet the
+	// private IPs belong to, when the provider reports them
+	Network *Network `json:"network,omitempty"`
+	Subnet  *Network `json:"subnet,omitempty"`
+
+	// CustomFields sets device custom fields by key, e.g. the instance ID
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+}
+
+// Network is a provider network or subnet
+type Network struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	CIDR string `json:"cidr"`
+}
+
+// ProvidersFromConfig builds the providers that have credentials configured.
+// It fails when a configured kubeconfig cannot be used.
+func ProvidersFromConfig(cfg *config.Config) ([]Provider, error) {
+	var providers []Provider
+	if cfg.HetznerCloudToken != "" {
+		providers = append(providers, NewHetznerCloud(cfg.HetznerCloudToken))
+	}
+	if cfg.HetznerRobotUser != "" && cfg.HetznerRobotPassword != "" {
+		providers = append(providers, NewHetznerRobot(cfg.HetznerRobotUser, cfg.HetznerRobotPassword))
+	}
+	if cfg.AWSAccessKeyID != "" && cfg.AWSSecretAccessKey != "" {
+		providers = append(providers, NewAWSEC2(cfg.AWSAccessKeyID, cfg.AWSSecretAccessKey, cfg.AWSSessionToken, splitList(cfg.AWSRegions)))
+	}
+	for _, path := range splitList(cfg.K8sKubeconfigs) {
+		k, err := NewKubernetesFromKubeconfig(path, "", "")
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, k)
+	}
+	return providers, nil
+}
+
+// ValidProviderName reports whether name can be used as a provider name in
+// device tags
+func ValidProviderName(name string) error {
+	if name == "" {
+		return errors.New("provider name is required")
+	}
+	if strings.ContainsAny(name, ": ,") {
+		return fmt.Errorf("provider name %q must not contain colons, commas or spaces", name)
+	}
+	return nil
+}
+
+// ParseRegionMap parses a region to datacenter mapping in the form
+// "region=datacenter,region=datacenter". Datacenters may be given by ID or name.
+func ParseRegionMap(spec string) (map[string]string, error) {
+	regions := make(map[string]string)
+	for _, entry := range splitList(spec) {
+		region, dc, ok := strings.Cut(entry, "=")
+		region, dc = strings.TrimSpace(region), strings.TrimSpace(dc)
+		if !ok || region == "" || dc == "" {
+			return nil, fmt.Errorf("invalid region mapping %q (expected region=datacenter)", entry)
+		}
+		regions[region] = dc
+	}
+	return regions, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// labelTags converts provider key/value labels to sorted tags
+func labelTags(labels map[string]string) []string {
+	tags := make([]string, 0, len(labels))
+	for k, v := range labels {
+		if v == "" {
+			tags = append(tags, k)
+		} else {
+			tags = append(tags, k+"="+v)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/paularlott/cli/env"
@@ -11,8 +13,13 @@ import (
 
 type Config struct {
 	DataDir                 string
+	StorageDriver           string
+	StorageDSN              string
 	ListenAddr              string
 	RequestTimeout          time.Duration
+	ShutdownTimeout         time.Duration
+	IdempotencyTTL          time.Duration
+	CompressionEnabled      bool
 	LogFormat               string
 	LogLevel                string
 	DiscoveryInterval       time.Duration
@@ -21,6 +28,7 @@ type Config struct {
 	DiscoveryCleanupDays    int
 	DiscoveryScanOnStartup  bool
 	DiscoverySNMPv2cEnabled bool
+	DiscoveryMappings       string
 	RateLimitEnabled        bool
 	RateLimitRequests       int
 	RateLimitWindow         time.Duration
@@ -33,6 +41,8 @@ type Config struct {
 	LoginRateLimitWindow    time.Duration
 	CookieSecure            bool
 	TrustProxy              bool
+	PublicURL               string
+	UIBasePath              string
 	InitialAdminUsername    string
 	InitialAdminPassword    string
 	InitialAdminEmail       string
@@ -44,12 +54,130 @@ type Config struct {
 	MCPOAuthAccessTokenTTL  time.Duration
 	MCPOAuthRefreshTokenTTL time.Duration
 
+	// OIDC single sign-on for the web UI and API sessions
+	OIDCIssuerURL     string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string
+	OIDCScopes        string
+	OIDCUsernameClaim string
+	OIDCGroupsClaim   string
+	OIDCRoleMap       string
+	OIDCDefaultRole   string
+	// OIDCLinkExistingUsers lets a first OIDC login take over the local
+	// account with the same username
+	OIDCLinkExistingUsers bool
+
 	// Utilization snapshots
 	SnapshotInterval      time.Duration
 	SnapshotRetentionDays int
 
+	// Trash: deleted devices, networks and datacenters are purged after
+	// this many days; 0 keeps them until purged by hand
+	TrashRetentionDays int
+
+	// Database backups: taken every BackupInterval (0 disables the schedule)
+	// into BackupDir, or the S3 bucket when one is set
+	BackupDir           string
+	BackupInterval      time.Duration
+	BackupRetentionDays int
+	BackupS3Bucket      string
+	BackupS3Prefix      string
+	BackupS3Region      string
+	BackupS3Endpoint    string
+	BackupS3AccessKeyID string
+	BackupS3SecretKey   string
+	BackupS3Token       string
+
 	// DNS sync
 	DNSSyncInterval time.Duration
+
+	// Hypervisor sync
+	HypervisorSyncInterval time.Duration
+
+	// Cloud sync
+	CloudSyncInterval    time.Duration
+	CloudRegionMap       string
+	HetznerCloudToken    string
+	HetznerRobotUser     string
+	HetznerRobotPassword string
+	AWSAccessKeyID       string
+	AWSSecretAccessKey   string
+	AWSSessionToken      string
+	AWSRegions           string
+	K8sKubeconfigs       string
+
+	// Availability monitoring
+	MonitorEnabled bool
+	MonitorWorkers int
+
+	// SMTP server for email notification channels without their own
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Days before a warranty expires to send reminders, comma-separated
+	WarrantyReminderDays string
+
+	// Search cost limits
+	SearchMaxRows int
+	SearchTimeout time.Duration
+
+	// Swagger UI at /api/docs
+	APIDocsEnabled bool
+
+	// Validation policies devices must follow, rejected with 422 when broken
+	ValidationHostnamePattern  string
+	ValidationHostnameFQDN     bool
+	ValidationProductionTag    string
+	ValidationRequiredTags     string
+	ValidationNamingPatterns   string
+	ValidationAddressInNetwork bool
+
+	// Reject a device whose name another device already uses, with 409
+	UniqueDeviceNames bool
+
+	// Hold changes by users who are not admins as change requests until an
+	// admin approves them
+	ApprovalMode bool
+
+	// TLS: a certificate and key, or certificates from an ACME CA such as
+	// Let's Encrypt for ACMEHosts
+	TLSCert          string
+	TLSKey           string
+	ACMEHosts        string
+	ACMEEmail        string
+	ACMECacheDir     string
+	ACMEDirectoryURL string
+	HTTPRedirectAddr string
+
+	// ConfigFile is the YAML or TOML file the settings were read from, if any
+	ConfigFile string
+
+	// Set from server command-line flags
+	DevMode       bool
+	GenerateToken bool
+}
+
+// MinPasswordLength is the minimum length accepted for INITIAL_ADMIN_PASSWORD
+const MinPasswordLength = 12
+
+// weakPasswords are rejected for INITIAL_ADMIN_PASSWORD regardless of length
+var weakPasswords = map[string]bool{
+	"password":      true,
+	"password123":   true,
+	"password1234":  true,
+	"changeme":      true,
+	"changeme123":   true,
+	"admin":         true,
+	"admin123":      true,
+	"administrator": true,
+	"rackd":         true,
+	"letmein":       true,
+	"123456789012":  true,
+	"qwertyuiop":    true,
 }
 
 var cfg Config
@@ -59,8 +187,13 @@ func Load() *Config {
 
 	cfg = Config{
 		DataDir:                 getEnv("DATA_DIR", "./data"),
+		StorageDriver:           getEnv("STORAGE_DRIVER", "sqlite"),
+		StorageDSN:              getEnv("STORAGE_DSN", ""),
 		ListenAddr:              getEnv("LISTEN_ADDR", ":8080"),
 		RequestTimeout:          getDurationEnv("REQUEST_TIMEOUT", 30*time.Second),
+		ShutdownTimeout:         getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second),
+		IdempotencyTTL:          getDurationEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+		CompressionEnabled:      getBoolEnv("COMPRESSION_ENABLED", true),
 		LogFormat:               getEnv("LOG_FORMAT", "text"),
 		LogLevel:                getEnv("LOG_LEVEL", "info"),
 		DiscoveryInterval:       getDurationEnv("DISCOVERY_INTERVAL", 24*time.Hour),
@@ -69,6 +202,7 @@ func Load() *Config {
 		DiscoveryCleanupDays:    getIntEnv("DISCOVERY_CLEANUP_DAYS", 30),
 		DiscoveryScanOnStartup:  getBoolEnv("DISCOVERY_SCAN_ON_STARTUP", false),
 		DiscoverySNMPv2cEnabled: getBoolEnv("DISCOVERY_SNMPV2C_ENABLED", false),
+		DiscoveryMappings:       getEnv("DISCOVERY_SERVICE_MAPPINGS", ""),
 		RateLimitEnabled:        getBoolEnv("RATE_LIMIT_ENABLED", true),
 		RateLimitRequests:       getIntEnv("RATE_LIMIT_REQUESTS", 100),
 		RateLimitWindow:         getDurationEnv("RATE_LIMIT_WINDOW", 1*time.Minute),
@@ -81,6 +215,8 @@ func Load() *Config {
 		LoginRateLimitWindow:    getDurationEnv("LOGIN_RATE_LIMIT_WINDOW", 1*time.Minute),
 		CookieSecure:            getBoolEnv("COOKIE_SECURE", true),
 		TrustProxy:              getBoolEnv("TRUST_PROXY", false),
+		PublicURL:               strings.TrimSuffix(getEnv("PUBLIC_URL", ""), "/"),
+		UIBasePath:              strings.TrimSuffix(getEnv("UI_BASE_PATH", ""), "/"),
 		InitialAdminUsername:    getEnv("INITIAL_ADMIN_USERNAME", ""),
 		InitialAdminPassword:    getEnv("INITIAL_ADMIN_PASSWORD", ""),
 		InitialAdminEmail:       getEnv("INITIAL_ADMIN_EMAIL", "admin@localhost"),
@@ -91,10 +227,82 @@ func Load() *Config {
 		MCPOAuthAccessTokenTTL:  getDurationEnv("MCP_OAUTH_ACCESS_TOKEN_TTL", 1*time.Hour),
 		MCPOAuthRefreshTokenTTL: getDurationEnv("MCP_OAUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
 
+		OIDCIssuerURL:     getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:      getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:   getEnv("OIDC_REDIRECT_URL", ""),
+		OIDCScopes:        getEnv("OIDC_SCOPES", "openid profile email groups"),
+		OIDCUsernameClaim: getEnv("OIDC_USERNAME_CLAIM", "preferred_username"),
+		OIDCGroupsClaim:   getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCRoleMap:       getEnv("OIDC_ROLE_MAP", ""),
+		OIDCDefaultRole:   getEnv("OIDC_DEFAULT_ROLE", ""),
+
+		OIDCLinkExistingUsers: getBoolEnv("OIDC_LINK_EXISTING_USERS", false),
+
 		SnapshotInterval:      getDurationEnv("SNAPSHOT_INTERVAL", 1*time.Hour),
 		SnapshotRetentionDays: getIntEnv("SNAPSHOT_RETENTION_DAYS", 90),
 
+		TrashRetentionDays: getIntEnv("TRASH_RETENTION_DAYS", 30),
+
+		BackupDir:           getEnv("BACKUP_DIR", ""),
+		BackupInterval:      getDurationEnv("BACKUP_INTERVAL", 0),
+		BackupRetentionDays: getIntEnv("BACKUP_RETENTION_DAYS", 30),
+		BackupS3Bucket:      getEnv("BACKUP_S3_BUCKET", ""),
+		BackupS3Prefix:      getEnv("BACKUP_S3_PREFIX", ""),
+		BackupS3Region:      getEnv("BACKUP_S3_REGION", getEnv("AWS_REGION", "")),
+		BackupS3Endpoint:    getEnv("BACKUP_S3_ENDPOINT", ""),
+		BackupS3AccessKeyID: getEnv("BACKUP_S3_ACCESS_KEY_ID", getEnv("AWS_ACCESS_KEY_ID", "")),
+		BackupS3SecretKey:   getEnv("BACKUP_S3_SECRET_ACCESS_KEY", getEnv("AWS_SECRET_ACCESS_KEY", "")),
+		BackupS3Token:       getEnv("BACKUP_S3_SESSION_TOKEN", getEnv("AWS_SESSION_TOKEN", "")),
+
 		DNSSyncInterval: getDurationEnv("DNS_SYNC_INTERVAL", 1*time.Hour),
+
+		HypervisorSyncInterval: getDurationEnv("HYPERVISOR_SYNC_INTERVAL", 1*time.Hour),
+
+		CloudSyncInterval:    getDurationEnv("CLOUD_SYNC_INTERVAL", 0),
+		CloudRegionMap:       getEnv("CLOUD_REGION_MAP", ""),
+		HetznerCloudToken:    getEnv("HETZNER_CLOUD_TOKEN", ""),
+		HetznerRobotUser:     getEnv("HETZNER_ROBOT_USER", ""),
+		HetznerRobotPassword: getEnv("HETZNER_ROBOT_PASSWORD", ""),
+		AWSAccessKeyID:       getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey:   getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		AWSSessionToken:      getEnv("AWS_SESSION_TOKEN", ""),
+		AWSRegions:           getEnv("CLOUD_AWS_REGIONS", getEnv("AWS_REGION", "")),
+		K8sKubeconfigs:       getEnv("CLOUD_K8S_KUBECONFIGS", ""),
+
+		MonitorEnabled: getBoolEnv("MONITOR_ENABLED", false),
+		MonitorWorkers: getIntEnv("MONITOR_WORKERS", 20),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getIntEnv("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", ""),
+
+		WarrantyReminderDays: getEnv("WARRANTY_REMINDER_DAYS", "30,7,1"),
+
+		SearchMaxRows: getIntEnv("SEARCH_MAX_ROWS", 1000),
+		SearchTimeout: getDurationEnv("SEARCH_TIMEOUT", 5*time.Second),
+
+		APIDocsEnabled: getBoolEnv("API_DOCS_ENABLED", false),
+
+		ValidationHostnamePattern:  getEnv("VALIDATION_HOSTNAME_PATTERN", ""),
+		ValidationHostnameFQDN:     getBoolEnv("VALIDATION_HOSTNAME_FQDN", false),
+		ValidationProductionTag:    getEnv("VALIDATION_PRODUCTION_TAG", "production"),
+		ValidationRequiredTags:     getEnv("VALIDATION_REQUIRED_TAGS", ""),
+		ValidationNamingPatterns:   getEnv("VALIDATION_NAMING_PATTERNS", ""),
+		ValidationAddressInNetwork: getBoolEnv("VALIDATION_ADDRESS_IN_NETWORK", false),
+
+		UniqueDeviceNames: getBoolEnv("UNIQUE_DEVICE_NAMES", false),
+		ApprovalMode:      getBoolEnv("APPROVAL_MODE", false),
+
+		TLSCert:          getEnv("TLS_CERT", ""),
+		TLSKey:           getEnv("TLS_KEY", ""),
+		ACMEHosts:        getEnv("ACME_HOSTS", ""),
+		ACMEEmail:        getEnv("ACME_EMAIL", ""),
+		ACMECacheDir:     getEnv("ACME_CACHE_DIR", ""),
+		ACMEDirectoryURL: getEnv("ACME_DIRECTORY_URL", ""),
+		HTTPRedirectAddr: getEnv("HTTP_REDIRECT_ADDR", ""),
 	}
 
 	return &cfg
@@ -117,6 +325,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid LOG_FORMAT: %s (must be text or json)", c.LogFormat)
 	}
 
+	if c.IdempotencyTTL < 0 {
+		return fmt.Errorf("IDEMPOTENCY_TTL must not be negative, got %v", c.IdempotencyTTL)
+	}
+
 	if c.DiscoveryInterval <= 0 {
 		return fmt.Errorf("DISCOVERY_INTERVAL must be positive, got %v", c.DiscoveryInterval)
 	}
@@ -146,13 +358,179 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("AUDIT_RETENTION_DAYS must be positive, got %d", c.AuditRetentionDays)
 	}
 
+	if c.MonitorEnabled && c.MonitorWorkers <= 0 {
+		return fmt.Errorf("MONITOR_WORKERS must be positive, got %d", c.MonitorWorkers)
+	}
+
+	if c.SMTPPort <= 0 || c.SMTPPort > 65535 {
+		return fmt.Errorf("SMTP_PORT must be between 1 and 65535, got %d", c.SMTPPort)
+	}
+
+	if c.SMTPHost != "" && c.SMTPFrom == "" {
+		return fmt.Errorf("SMTP_FROM is required when SMTP_HOST is set")
+	}
+
+	if _, err := c.WarrantyReminderDayList(); err != nil {
+		return err
+	}
+
+	if c.TrashRetentionDays < 0 {
+		return fmt.Errorf("TRASH_RETENTION_DAYS must not be negative, got %d", c.TrashRetentionDays)
+	}
+
+	if c.BackupInterval < 0 {
+		return fmt.Errorf("BACKUP_INTERVAL must not be negative, got %v", c.BackupInterval)
+	}
+
+	if c.BackupRetentionDays < 0 {
+		return fmt.Errorf("BACKUP_RETENTION_DAYS must not be negative, got %d", c.BackupRetentionDays)
+	}
+
+	if c.BackupS3Bucket != "" && (c.BackupS3AccessKeyID == "" || c.BackupS3SecretKey == "") {
+		return fmt.Errorf("BACKUP_S3_ACCESS_KEY_ID and BACKUP_S3_SECRET_ACCESS_KEY (or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY) are required when BACKUP_S3_BUCKET is set")
+	}
+
+	if c.SearchMaxRows < 0 {
+		return fmt.Errorf("SEARCH_MAX_ROWS must not be negative, got %d", c.SearchMaxRows)
+	}
+
+	if c.SearchTimeout < 0 {
+		return fmt.Errorf("SEARCH_TIMEOUT must not be negative, got %v", c.SearchTimeout)
+	}
+
+	if c.PublicURL != "" && !strings.HasPrefix(c.PublicURL, "http://") && !strings.HasPrefix(c.PublicURL, "https://") {
+		return fmt.Errorf("PUBLIC_URL must start with http:// or https://, got %q", c.PublicURL)
+	}
+
+	if c.UIBasePath != "" && (!strings.HasPrefix(c.UIBasePath, "/") || strings.ContainsAny(c.UIBasePath, "?#\"")) {
+		return fmt.Errorf("UI_BASE_PATH must be a path starting with /, such as /rackd, got %q", c.UIBasePath)
+	}
+
 	if c.MCPOAuthEnabled && c.MCPOAuthIssuerURL == "" {
 		return fmt.Errorf("MCP_OAUTH_ISSUER_URL is required when MCP_OAUTH_ENABLED is true")
 	}
 
+	if c.OIDCIssuerURL != "" {
+		if c.OIDCClientID == "" || c.OIDCRedirectURL == "" {
+			return fmt.Errorf("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER_URL is set")
+		}
+		if c.OIDCRoleMap == "" && c.OIDCDefaultRole == "" {
+			return fmt.Errorf("OIDC_ROLE_MAP or OIDC_DEFAULT_ROLE is required when OIDC_ISSUER_URL is set")
+		}
+		// The provider's signing keys are fetched from the issuer, so over
+		// plain HTTP anyone on the path could sign ID tokens
+		if !strings.HasPrefix(c.OIDCIssuerURL, "https://") && !c.DevMode {
+			return fmt.Errorf("OIDC_ISSUER_URL must be an https:// URL, got %q", c.OIDCIssuerURL)
+		}
+	}
+
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("TLS_CERT and TLS_KEY must be set together")
+	}
+
+	if c.TLSCert != "" && c.ACMEHosts != "" {
+		return fmt.Errorf("TLS_CERT and ACME_HOSTS cannot both be set")
+	}
+
+	if c.HTTPRedirectAddr != "" && !c.TLSEnabled() {
+		return fmt.Errorf("HTTP_REDIRECT_ADDR requires TLS_CERT and TLS_KEY or ACME_HOSTS")
+	}
+
 	return nil
 }
 
+// StorageDataSource returns what the storage driver opens: STORAGE_DSN, or
+// the data directory when it is not set
+func (c *Config) StorageDataSource() string {
+	if c.StorageDSN != "" {
+		return c.StorageDSN
+	}
+	return c.DataDir
+}
+
+// TLSEnabled reports whether the server serves HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCert != "" || c.ACMEHosts != ""
+}
+
+// ACMEHostList returns the hostnames to request certificates for
+func (c *Config) ACMEHostList() []string {
+	var hosts []string
+	for _, h := range strings.Split(c.ACMEHosts, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// WarrantyReminderDayList returns the days before a warranty expires to send
+// reminders, largest first
+func (c *Config) WarrantyReminderDayList() ([]int, error) {
+	var days []int
+	for _, d := range strings.Split(c.WarrantyReminderDays, ",") {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+		n, err := strconv.Atoi(d)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("WARRANTY_REMINDER_DAYS must be positive numbers of days separated by commas, got %q", c.WarrantyReminderDays)
+		}
+		if !slices.Contains(days, n) {
+			days = append(days, n)
+		}
+	}
+	slices.SortFunc(days, func(a, b int) int { return b - a })
+	return days, nil
+}
+
+// ValidateAuth checks authentication-related settings. Problems that leave
+// the server open to trivial takeover are returned as an error; in dev mode
+// they are downgraded to warnings. Less severe issues are always returned as
+// warnings for the caller to log.
+func (c *Config) ValidateAuth() ([]string, error) {
+	var warnings []string
+
+	if c.InitialAdminUsername != "" {
+		if c.InitialAdminPassword == "" {
+			return nil, fmt.Errorf("INITIAL_ADMIN_PASSWORD is required when INITIAL_ADMIN_USERNAME is set")
+		}
+		if problem := weakPasswordProblem(c.InitialAdminUsername, c.InitialAdminPassword); problem != "" {
+			msg := fmt.Sprintf("INITIAL_ADMIN_PASSWORD %s; set a random password of at least %d characters (e.g. `openssl rand -base64 24`) or unset it once the initial admin exists", problem, MinPasswordLength)
+			if !c.DevMode {
+				return nil, fmt.Errorf("%s", msg)
+			}
+			warnings = append(warnings, msg)
+		}
+	} else if c.InitialAdminPassword != "" {
+		warnings = append(warnings, "INITIAL_ADMIN_PASSWORD is set but INITIAL_ADMIN_USERNAME is not; no initial admin will be created")
+	}
+
+	if c.MCPOAuthEnabled && strings.HasPrefix(c.MCPOAuthIssuerURL, "http://") && !c.DevMode {
+		warnings = append(warnings, "MCP_OAUTH_ISSUER_URL uses http://; OAuth tokens will be issued over an unencrypted connection. Use an https:// URL behind TLS")
+	}
+
+	if !c.CookieSecure && !c.DevMode {
+		warnings = append(warnings, "COOKIE_SECURE is false; session cookies will be sent over plain HTTP. Only disable it for local development")
+	}
+
+	return warnings, nil
+}
+
+func weakPasswordProblem(username, password string) string {
+	if len(password) < MinPasswordLength {
+		return fmt.Sprintf("is shorter than %d characters", MinPasswordLength)
+	}
+	lower := strings.ToLower(password)
+	if weakPasswords[lower] || lower == strings.ToLower(username) {
+		return "is a commonly used or guessable password"
+	}
+	if strings.Count(password, password[:1]) == len(password) {
+		return "is a single repeated character"
+	}
+	return ""
+}
+
 func (c *Config) String() string {
 	return fmt.Sprintf("Config{DataDir:%s, ListenAddr:%s, LogFormat:%s, LogLevel:%s, DiscoveryInterval:%v, DiscoveryMaxConcurrent:%d, DiscoveryTimeout:%v, DiscoveryCleanupDays:%d, DiscoveryScanOnStartup:%v, DiscoverySNMPv2cEnabled:%v, RateLimitEnabled:%v, RateLimitRequests:%d, RateLimitWindow:%v}",
 		c.DataDir,
@@ -171,15 +549,30 @@ func (c *Config) String() string {
 	)
 }
 
-func getEnv(key, defaultValue string) string {
+// lookup returns a setting: a command-line override, then the environment
+// variable, then the config file
+func lookup(key string) string {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	knownKeys[key] = true
+	if value, ok := overrides[key]; ok {
+		return value
+	}
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
+	return fileValues[key]
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := lookup(key); value != "" {
+		return value
+	}
 	return defaultValue
 }
 
 func getIntEnv(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		if result, err := strconv.Atoi(value); err == nil {
 			return result
 		}
@@ -188,7 +581,7 @@ func getIntEnv(key string, defaultValue int) int {
 }
 
 func getBoolEnv(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		if result, err := strconv.ParseBool(value); err == nil {
 			return result
 		}
@@ -197,7 +590,7 @@ func getBoolEnv(key string, defaultValue bool) bool {
 }
 
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		if result, err := time.ParseDuration(value); err == nil {
 			return result
 		}
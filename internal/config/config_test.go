@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -23,6 +25,21 @@ func TestLoad(t *testing.T) {
 	if cfg.LogLevel != "info" {
 		t.Errorf("Expected default LogLevel info, got %s", cfg.LogLevel)
 	}
+	if cfg.StorageDriver != "sqlite" || cfg.StorageDataSource() != "./data" {
+		t.Errorf("Expected the sqlite driver on the data directory, got %s %s", cfg.StorageDriver, cfg.StorageDataSource())
+	}
+}
+
+func TestStorageDataSource(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("STORAGE_DRIVER", "mysql")
+	os.Setenv("STORAGE_DSN", "rackd:secret@tcp(db:3306)/rackd")
+	defer os.Clearenv()
+
+	cfg := Load()
+	if cfg.StorageDriver != "mysql" || cfg.StorageDataSource() != "rackd:secret@tcp(db:3306)/rackd" {
+		t.Errorf("Expected STORAGE_DSN as the data source, got %s %s", cfg.StorageDriver, cfg.StorageDataSource())
+	}
 }
 
 func TestLoadWithEnvVars(t *testing.T) {
@@ -179,6 +196,31 @@ func TestValidate(t *testing.T) {
 	}
 	os.Unsetenv("DISCOVERY_MAX_CONCURRENT")
 
+	os.Clearenv()
+	os.Setenv("PUBLIC_URL", "rackd.example.com")
+	cfg = Load()
+
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "PUBLIC_URL") {
+		t.Errorf("Expected error for a PUBLIC_URL without a scheme, got: %v", err)
+	}
+	os.Setenv("PUBLIC_URL", "https://rackd.example.com/")
+	if cfg = Load(); cfg.PublicURL != "https://rackd.example.com" {
+		t.Errorf("Expected the trailing slash to be trimmed, got %q", cfg.PublicURL)
+	}
+	os.Unsetenv("PUBLIC_URL")
+
+	os.Clearenv()
+	os.Setenv("UI_BASE_PATH", "rackd")
+	if err = Load().Validate(); err == nil || !strings.Contains(err.Error(), "UI_BASE_PATH") {
+		t.Errorf("Expected error for a UI_BASE_PATH without a leading slash, got: %v", err)
+	}
+	os.Setenv("UI_BASE_PATH", "/rackd/")
+	if cfg = Load(); cfg.UIBasePath != "/rackd" || cfg.Validate() != nil {
+		t.Errorf("Expected /rackd, got %q", cfg.UIBasePath)
+	}
+	os.Unsetenv("UI_BASE_PATH")
+
 	os.Clearenv()
 	cfg = Load()
 
@@ -193,3 +235,325 @@ func TestConfigStringRedaction(t *testing.T) {
 	// Authentication now uses API keys stored in database
 	t.Skip("Legacy token redaction test - no longer applicable")
 }
+
+func TestValidateAuth(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          Config
+		wantErr      string
+		wantWarnings int
+	}{
+		{"no initial admin", Config{CookieSecure: true}, "", 0},
+		{"strong password", Config{InitialAdminUsername: "admin", InitialAdminPassword: "c0rrect-horse-battery", CookieSecure: true}, "", 0},
+		{"missing password", Config{InitialAdminUsername: "admin", CookieSecure: true}, "INITIAL_ADMIN_PASSWORD is required", 0},
+		{"short password", Config{InitialAdminUsername: "admin", InitialAdminPassword: "secret", CookieSecure: true}, "shorter than", 0},
+		{"common password", Config{InitialAdminUsername: "admin", InitialAdminPassword: "password1234", CookieSecure: true}, "commonly used", 0},
+		{"password equals username", Config{InitialAdminUsername: "administrator1", InitialAdminPassword: "Administrator1", CookieSecure: true}, "commonly used", 0},
+		{"repeated character", Config{InitialAdminUsername: "admin", InitialAdminPassword: "aaaaaaaaaaaaaaaa", CookieSecure: true}, "repeated", 0},
+		{"weak password in dev mode", Config{InitialAdminUsername: "admin", InitialAdminPassword: "secret", DevMode: true}, "", 1},
+		{"password without username", Config{InitialAdminPassword: "c0rrect-horse-battery", CookieSecure: true}, "", 1},
+		{"insecure cookies", Config{}, "", 1},
+		{"http oauth issuer", Config{CookieSecure: true, MCPOAuthEnabled: true, MCPOAuthIssuerURL: "http://rackd.example.com"}, "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := tt.cfg.ValidateAuth()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.wantWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"certificate", Config{TLSCert: "cert.pem", TLSKey: "key.pem", HTTPRedirectAddr: ":80"}, ""},
+		{"acme", Config{ACMEHosts: "rackd.example.com"}, ""},
+		{"cert without key", Config{TLSCert: "cert.pem"}, "TLS_CERT and TLS_KEY"},
+		{"cert and acme", Config{TLSCert: "cert.pem", TLSKey: "key.pem", ACMEHosts: "rackd.example.com"}, "cannot both be set"},
+		{"redirect without tls", Config{HTTPRedirectAddr: ":80"}, "HTTP_REDIRECT_ADDR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			cfg := Load()
+			cfg.TLSCert, cfg.TLSKey = tt.cfg.TLSCert, tt.cfg.TLSKey
+			cfg.ACMEHosts, cfg.HTTPRedirectAddr = tt.cfg.ACMEHosts, tt.cfg.HTTPRedirectAddr
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateOIDC(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"role map", Config{OIDCIssuerURL: "https://idp.example.com", OIDCClientID: "rackd", OIDCRedirectURL: "https://rackd.example.com/api/auth/oidc/callback", OIDCRoleMap: "netops=operator"}, ""},
+		{"default role", Config{OIDCIssuerURL: "https://idp.example.com", OIDCClientID: "rackd", OIDCRedirectURL: "https://rackd.example.com/api/auth/oidc/callback", OIDCDefaultRole: "viewer"}, ""},
+		{"no client", Config{OIDCIssuerURL: "https://idp.example.com", OIDCDefaultRole: "viewer"}, "OIDC_CLIENT_ID"},
+		{"no roles", Config{OIDCIssuerURL: "https://idp.example.com", OIDCClientID: "rackd", OIDCRedirectURL: "https://rackd.example.com/api/auth/oidc/callback"}, "OIDC_ROLE_MAP"},
+		{"http issuer", Config{OIDCIssuerURL: "http://idp.example.com", OIDCClientID: "rackd", OIDCRedirectURL: "https://rackd.example.com/api/auth/oidc/callback", OIDCDefaultRole: "viewer"}, "https://"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			cfg := Load()
+			cfg.OIDCIssuerURL, cfg.OIDCClientID, cfg.OIDCRedirectURL = tt.cfg.OIDCIssuerURL, tt.cfg.OIDCClientID, tt.cfg.OIDCRedirectURL
+			cfg.OIDCRoleMap, cfg.OIDCDefaultRole = tt.cfg.OIDCRoleMap, tt.cfg.OIDCDefaultRole
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateBackups(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"scheduled", Config{BackupInterval: 24 * time.Hour, BackupRetentionDays: 7}, ""},
+		{"s3", Config{BackupS3Bucket: "rackd-backups", BackupS3AccessKeyID: "AKID", BackupS3SecretKey: "secret"}, ""},
+		{"negative interval", Config{BackupInterval: -time.Hour}, "BACKUP_INTERVAL"},
+		{"negative retention", Config{BackupRetentionDays: -1}, "BACKUP_RETENTION_DAYS"},
+		{"s3 without credentials", Config{BackupS3Bucket: "rackd-backups"}, "BACKUP_S3_ACCESS_KEY_ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			cfg := Load()
+			cfg.BackupInterval, cfg.BackupRetentionDays = tt.cfg.BackupInterval, tt.cfg.BackupRetentionDays
+			cfg.BackupS3Bucket, cfg.BackupS3AccessKeyID, cfg.BackupS3SecretKey = tt.cfg.BackupS3Bucket, tt.cfg.BackupS3AccessKeyID, tt.cfg.BackupS3SecretKey
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestACMEHostList(t *testing.T) {
+	cfg := Config{ACMEHosts: " rackd.example.com, ,ipam.example.com "}
+	hosts := cfg.ACMEHostList()
+	if len(hosts) != 2 || hosts[0] != "rackd.example.com" || hosts[1] != "ipam.example.com" {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+}
+
+func TestWarrantyReminderDayList(t *testing.T) {
+	cfg := Config{WarrantyReminderDays: " 7, 30,,1,7 "}
+	days, err := cfg.WarrantyReminderDayList()
+	if err != nil || !slices.Equal(days, []int{30, 7, 1}) {
+		t.Errorf("expected [30 7 1], got %v (%v)", days, err)
+	}
+
+	for _, value := range []string{"30,soon", "0", "-7"} {
+		cfg.WarrantyReminderDays = value
+		if _, err := cfg.WarrantyReminderDayList(); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestValidateSMTP(t *testing.T) {
+	os.Clearenv()
+	cfg := Load()
+	cfg.SMTPHost = "mail.example.com"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "SMTP_FROM") {
+		t.Errorf("expected SMTP_FROM to be required, got %v", err)
+	}
+	cfg.SMTPFrom = "rackd@example.com"
+	cfg.SMTPPort = 70000
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "SMTP_PORT") {
+		t.Errorf("expected an SMTP_PORT error, got %v", err)
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	yamlFile := `
+data_dir: /var/lib/rackd
+log:
+  level: debug
+  format: json
+discovery:
+  interval: 6h
+  max_concurrent: 4
+rate_limit:
+  enabled: false
+acme:
+  hosts: [rackd.example.com, rackd.example.net]
+backup:
+  s3:
+    bucket: backups
+    access_key_id: AKID
+    secret_access_key: secret
+`
+	tomlFile := `
+data_dir = "/var/lib/rackd"
+acme.hosts = ["rackd.example.com", "rackd.example.net"]
+
+[log]
+level = "debug"
+format = "json"
+
+[discovery]
+interval = "6h"
+max_concurrent = 4
+
+[rate_limit]
+enabled = false
+
+[backup.s3]
+bucket = "backups"
+access_key_id = "AKID"
+secret_access_key = "secret"
+`
+	for name, content := range map[string]string{"rackd.yaml": yamlFile, "rackd.toml": tomlFile} {
+		t.Run(name, func(t *testing.T) {
+			os.Clearenv()
+			cfg, err := LoadFile(writeConfigFile(t, name, content))
+			if err != nil {
+				t.Fatalf("LoadFile failed: %v", err)
+			}
+			if cfg.DataDir != "/var/lib/rackd" || cfg.LogLevel != "debug" || cfg.LogFormat != "json" {
+				t.Errorf("unexpected top-level settings: %s %s %s", cfg.DataDir, cfg.LogLevel, cfg.LogFormat)
+			}
+			if cfg.DiscoveryInterval != 6*time.Hour || cfg.DiscoveryMaxConcurrent != 4 || cfg.RateLimitEnabled {
+				t.Errorf("unexpected typed settings: %v %d %v", cfg.DiscoveryInterval, cfg.DiscoveryMaxConcurrent, cfg.RateLimitEnabled)
+			}
+			if cfg.ACMEHosts != "rackd.example.com,rackd.example.net" || cfg.BackupS3Bucket != "backups" {
+				t.Errorf("unexpected list or nested settings: %q %q", cfg.ACMEHosts, cfg.BackupS3Bucket)
+			}
+			if cfg.ListenAddr != ":8080" {
+				t.Errorf("expected unset settings to keep their defaults, got %s", cfg.ListenAddr)
+			}
+		})
+	}
+}
+
+func TestLoadFilePrecedence(t *testing.T) {
+	os.Clearenv()
+	defer func() { overrides = map[string]string{} }()
+	path := writeConfigFile(t, "rackd.yaml", "log:\n  level: debug\nlisten_addr: ':9000'\ndata_dir: /srv/rackd\n")
+	t.Setenv("LISTEN_ADDR", ":9100")
+	SetOverride("DATA_DIR", "/flag/dir")
+
+	t.Setenv("CONFIG_FILE", path)
+	cfg, err := LoadFile("")
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.LogLevel != "debug" || cfg.ListenAddr != ":9100" || cfg.DataDir != "/flag/dir" {
+		t.Errorf("expected file < env < flag, got %s %s %s", cfg.LogLevel, cfg.ListenAddr, cfg.DataDir)
+	}
+	if cfg.ConfigFile != path {
+		t.Errorf("expected ConfigFile from CONFIG_FILE, got %q", cfg.ConfigFile)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	os.Clearenv()
+	tests := []struct {
+		name, content, wantErr string
+	}{
+		{"rackd.yaml", "log:\n  levle: debug\n", "unknown settings"},
+		{"rackd.yaml", "log: [", "failed to parse"},
+		{"rackd.json", "{}", "must be .yaml"},
+	}
+	for _, tt := range tests {
+		_, err := LoadFile(writeConfigFile(t, tt.name, tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s %q: expected error containing %q, got %v", tt.name, tt.content, tt.wantErr, err)
+		}
+	}
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestReloadAndChanges(t *testing.T) {
+	os.Clearenv()
+	path := writeConfigFile(t, "rackd.yaml", "log:\n  level: info\n")
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	cfg.EnableDevMode()
+
+	if err := os.WriteFile(path, []byte("log:\n  level: debug\nlisten_addr: ':9000'\nrate_limit:\n  requests: 50\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	next, err := cfg.Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if !next.DevMode || next.CookieSecure || next.RateLimitEnabled {
+		t.Error("expected the reload to keep dev mode")
+	}
+
+	applied, restart := cfg.Changes(next)
+	if strings.Join(applied, ",") != "LogLevel,RateLimitRequests" {
+		t.Errorf("unexpected applied changes: %v", applied)
+	}
+	if strings.Join(restart, ",") != "ListenAddr" {
+		t.Errorf("unexpected restart changes: %v", restart)
+	}
+
+	if err := os.WriteFile(path, []byte("log:\n  level: loud\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.Reload(); err == nil {
+		t.Error("expected an invalid reload to fail validation")
+	}
+}
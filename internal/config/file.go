@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	settingsMu sync.Mutex
+	// overrides are set from command-line flags and win over everything
+	overrides = map[string]string{}
+	// fileValues are the settings read from the config file, by name
+	fileValues = map[string]string{}
+	// knownKeys are the setting names Load reads, to catch typos in the file
+	knownKeys = map[string]bool{}
+)
+
+// reloadable are the settings a running server applies on SIGHUP. Anything
+// else that changes needs a restart.
+var reloadable = map[string]bool{
+	"LogLevel":               true,
+	"RateLimitRequests":      true,
+	"RateLimitWindow":        true,
+	"LoginRateLimitRequests": true,
+	"LoginRateLimitWindow":   true,
+}
+
+// SetOverride sets a setting by its environment variable name, taking
+// precedence over the environment and the config file. Server flags use it
+// so they still win when the file is reloaded.
+func SetOverride(key, value string) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	overrides[key] = value
+}
+
+// LoadFile loads the configuration from the environment and the YAML or
+// TOML file at path, or at CONFIG_FILE when path is empty. Settings in the
+// file are named like the environment variables, with sections joining
+// their keys: log.level is LOG_LEVEL and backup.s3.bucket is
+// BACKUP_S3_BUCKET. Environment variables win over the file.
+func LoadFile(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	values := map[string]string{}
+	if path != "" {
+		var err error
+		if values, err = readConfigFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	settingsMu.Lock()
+	fileValues = values
+	settingsMu.Unlock()
+
+	// Load returns the shared package config, which a reload must not change
+	loaded := *Load()
+	c := &loaded
+	c.ConfigFile = path
+
+	var unknown []string
+	for key := range values {
+		if !knownKeys[key] {
+			unknown = append(unknown, strings.ToLower(key))
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown settings in %s: %s", path, strings.Join(unknown, ", "))
+	}
+	return c, nil
+}
+
+// Reload reads the environment and config file again, keeping the flags the
+// server was started with, and validates the result
+func (c *Config) Reload() (*Config, error) {
+	next, err := LoadFile(c.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	next.GenerateToken = c.GenerateToken
+	if c.DevMode {
+		next.EnableDevMode()
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// Changes compares c with next and returns the names of the changed
+// settings, split into those a running server can apply and those that
+// need a restart
+func (c *Config) Changes(next *Config) (applied, restart []string) {
+	cur, nxt := reflect.ValueOf(c).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < cur.NumField(); i++ {
+		name := cur.Type().Field(i).Name
+		if reflect.DeepEqual(cur.Field(i).Interface(), nxt.Field(i).Interface()) {
+			continue
+		}
+		if reloadable[name] {
+			applied = append(applied, name)
+		} else {
+			restart = append(restart, name)
+		}
+	}
+	return applied, restart
+}
+
+// EnableDevMode relaxes the security defaults for local development
+func (c *Config) EnableDevMode() {
+	c.DevMode = true
+	c.CookieSecure = false
+	c.RateLimitEnabled = false
+}
+
+// readConfigFile parses a YAML or TOML file, picked by its extension, into
+// settings named like the environment variables
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var tree map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	if err := flatten(values, "", tree); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flatten walks the sections of a config file, turning each value into the
+// string its environment variable would hold. Lists become comma-separated.
+func flatten(values map[string]string, prefix string, tree map[string]any) error {
+	for key, v := range tree {
+		name := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flatten(values, name, v); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				s, err := scalar(item)
+				if err != nil {
+					return fmt.Errorf("%s: %w", strings.ToLower(name), err)
+				}
+				items[i] = s
+			}
+			values[name] = strings.Join(items, ",")
+		default:
+			s, err := scalar(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", strings.ToLower(name), err)
+			}
+			values[name] = s
+		}
+	}
+	return nil
+}
+
+func scalar(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", v)
+}
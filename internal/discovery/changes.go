@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// scanChanges works out how a network changed during a scan, compared with
+// the discovered devices recorded before the scan started. A host is new when
+// it was never discovered on the network, and has disappeared when the
+// previous completed scan saw it but this one did not. Only ports the scan
+// probes count as closed, so a quick scan after a deep one does not report
+// every port the quick scan skips. Ignored devices are not reported.
+type scanChanges struct {
+	probed   map[int]bool
+	previous map[string]bool // IPs seen by the previous completed scan
+	ignored  map[string]bool
+	seen     map[string]bool
+
+	mu      sync.Mutex
+	created []string
+	changed []model.ServiceChange
+}
+
+// newScanChanges starts tracking a scan of ports. known holds the discovered
+// devices of the network before the scan; those last seen at or after
+// previousScan were seen by the previous completed scan. With no previous
+// scan nothing is reported as disappeared.
+func newScanChanges(known []model.DiscoveredDevice, previousScan *time.Time, ports []int) *scanChanges {
+	c := &scanChanges{
+		probed:   make(map[int]bool, len(ports)),
+		previous: make(map[string]bool),
+		ignored:  make(map[string]bool),
+		seen:     make(map[string]bool),
+	}
+	for _, port := range ports {
+		c.probed[port] = true
+	}
+	for _, d := range known {
+		if d.Ignored() {
+			c.ignored[d.IP] = true
+		} else if previousScan != nil && !d.LastSeen.Before(*previousScan) {
+			c.previous[d.IP] = true
+		}
+	}
+	return c
+}
+
+// record notes a host found by the scan. existing is the discovered device
+// already recorded for its IP, if any.
+func (c *scanChanges) record(existing, found *model.DiscoveredDevice) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seen[found.IP] = true
+	if c.ignored[found.IP] {
+		return
+	}
+	if existing == nil {
+		c.created = append(c.created, found.IP)
+		return
+	}
+
+	change := model.ServiceChange{IP: found.IP}
+	for _, port := range found.OpenPorts {
+		if !slices.Contains(existing.OpenPorts, port) {
+			change.OpenedPorts = append(change.OpenedPorts, port)
+		}
+	}
+	for _, port := range existing.OpenPorts {
+		if c.probed[port] && !slices.Contains(found.OpenPorts, port) {
+			change.ClosedPorts = append(change.ClosedPorts, port)
+		}
+	}
+	if len(change.OpenedPorts) > 0 || len(change.ClosedPorts) > 0 {
+		slices.Sort(change.OpenedPorts)
+		slices.Sort(change.ClosedPorts)
+		c.changed = append(c.changed, change)
+	}
+}
+
+// summarise adds the changes to the payload of the completed event, in IP
+// order
+func (c *scanChanges) summarise(payload *model.EventPayloadDiscovery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var disappeared []string
+	for ip := range c.previous {
+		if !c.seen[ip] {
+			disappeared = append(disappeared, ip)
+		}
+	}
+
+	payload.NewHosts = slices.SortedFunc(slices.Values(c.created), compareIPs)
+	payload.DisappearedHosts = slices.SortedFunc(slices.Values(disappeared), compareIPs)
+	payload.ChangedServices = slices.SortedFunc(slices.Values(c.changed), func(a, b model.ServiceChange) int {
+		return compareIPs(a.IP, b.IP)
+	})
+}
+
+// compareIPs orders IP addresses numerically, falling back to a string
+// comparison for anything that does not parse
+func compareIPs(a, b string) int {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return addrA.Compare(addrB)
+}
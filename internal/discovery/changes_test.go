@@ -0,0 +1,74 @@
+package discovery
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestScanChanges(t *testing.T) {
+	previousScan := time.Now().Add(-time.Hour)
+	known := []model.DiscoveredDevice{
+		{IP: "10.0.0.10", OpenPorts: []int{22, 80}, LastSeen: previousScan.Add(time.Minute)},
+		{IP: "10.0.0.9", OpenPorts: []int{22}, LastSeen: previousScan.Add(time.Minute)},
+		{IP: "10.0.0.20", OpenPorts: []int{22, 8443}, LastSeen: previousScan.Add(time.Minute)},
+		// Gone before the previous scan, so not reported again
+		{IP: "10.0.0.30", OpenPorts: []int{22}, LastSeen: previousScan.Add(-24 * time.Hour)},
+	}
+
+	c := newScanChanges(known, &previousScan, []int{22, 80, 443})
+	c.record(&known[0], &model.DiscoveredDevice{IP: "10.0.0.10", OpenPorts: []int{22, 443}})
+	// 8443 is not probed by this scan, so it is not reported as closed
+	c.record(&known[2], &model.DiscoveredDevice{IP: "10.0.0.20", OpenPorts: []int{22}})
+	c.record(nil, &model.DiscoveredDevice{IP: "10.0.0.100", OpenPorts: []int{80}})
+	c.record(nil, &model.DiscoveredDevice{IP: "10.0.0.11", OpenPorts: []int{80}})
+
+	var payload model.EventPayloadDiscovery
+	c.summarise(&payload)
+
+	if want := []string{"10.0.0.11", "10.0.0.100"}; !reflect.DeepEqual(payload.NewHosts, want) {
+		t.Errorf("NewHosts = %v, want %v", payload.NewHosts, want)
+	}
+	if want := []string{"10.0.0.9"}; !reflect.DeepEqual(payload.DisappearedHosts, want) {
+		t.Errorf("DisappearedHosts = %v, want %v", payload.DisappearedHosts, want)
+	}
+	want := []model.ServiceChange{{IP: "10.0.0.10", OpenedPorts: []int{443}, ClosedPorts: []int{80}}}
+	if !reflect.DeepEqual(payload.ChangedServices, want) {
+		t.Errorf("ChangedServices = %+v, want %+v", payload.ChangedServices, want)
+	}
+	if !payload.HasChanges() {
+		t.Error("HasChanges = false, want true")
+	}
+}
+
+func TestScanChangesSkipsIgnored(t *testing.T) {
+	previousScan := time.Now().Add(-time.Hour)
+	known := []model.DiscoveredDevice{
+		{IP: "10.0.0.9", Status: model.DiscoveredStatusIgnored, OpenPorts: []int{22}, LastSeen: previousScan.Add(time.Minute)},
+		{IP: "10.0.0.10", Status: model.DiscoveredStatusIgnored, OpenPorts: []int{22}, LastSeen: previousScan.Add(time.Minute)},
+	}
+
+	c := newScanChanges(known, &previousScan, []int{22, 80})
+	c.record(&known[1], &model.DiscoveredDevice{IP: "10.0.0.10", OpenPorts: []int{80}})
+
+	var payload model.EventPayloadDiscovery
+	c.summarise(&payload)
+	if payload.HasChanges() {
+		t.Errorf("payload = %+v, want ignored hosts left out", payload)
+	}
+}
+
+func TestScanChangesFirstScan(t *testing.T) {
+	known := []model.DiscoveredDevice{{IP: "10.0.0.9", OpenPorts: []int{22}, LastSeen: time.Now()}}
+
+	c := newScanChanges(known, nil, []int{22})
+	c.record(&known[0], &model.DiscoveredDevice{IP: "10.0.0.9", OpenPorts: []int{22}})
+
+	var payload model.EventPayloadDiscovery
+	c.summarise(&payload)
+	if payload.HasChanges() {
+		t.Errorf("payload = %+v, want no changes", payload)
+	}
+}
@@ -0,0 +1,101 @@
+package discovery
+
+import (
+	"fmt"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// Points of the confidence factors that count things or give partial credit
+const (
+	openPortPoints   = 5 // per open port
+	sightingPoints   = 5 // per scan after the first
+	unknownMACPoints = 5 // for a MAC address of an unknown vendor
+)
+
+// ConfidenceEvidence is what probing a host showed beyond the fields of its
+// discovered device
+type ConfidenceEvidence struct {
+	Responded  bool   // the host answered a probe or announced itself
+	ReverseDNS string // PTR name of the IP
+	SNMP       bool   // the host answered SNMP
+}
+
+// ScoreConfidence sets a discovered device's confidence, from 0 to 100, and
+// the breakdown explaining it. The MAC address, vendor, open ports and scan
+// count are read from the device. Every factor is listed, including those
+// that added nothing, in model.ConfidenceFactors order.
+func ScoreConfidence(device *model.DiscoveredDevice, ev ConfidenceEvidence) {
+	factors := make([]model.ConfidenceFactor, 0, len(model.ConfidenceFactors))
+
+	if ev.Responded {
+		factors = append(factors, confidenceFactor(model.ConfidenceFactorPing, model.ConfidenceFactorMax[model.ConfidenceFactorPing], "host is up"))
+	} else {
+		factors = append(factors, confidenceFactor(model.ConfidenceFactorPing, 0, "no response"))
+	}
+
+	if ev.ReverseDNS != "" {
+		factors = append(factors, confidenceFactor(model.ConfidenceFactorReverseDNS, model.ConfidenceFactorMax[model.ConfidenceFactorReverseDNS], ev.ReverseDNS))
+	} else {
+		factors = append(factors, confidenceFactor(model.ConfidenceFactorReverseDNS, 0, "no PTR record"))
+	}
+
+	switch {
+	case device.MACAddress != "" && device.Vendor != "":
+		factors = append(factors, confidenceFactor(model.ConfidenceFactorMACVendor, model.ConfidenceFactorMax[model.ConfidenceFactorMACVendor], device.Vendor))
+	case device.MACAddress != "":
+		factors = append(factors, confidenceFactor(model.ConfidenceFactorMACVendor, unknownMACPoints, "unknown vendor"))
+	default:
+		factors = append(factors, confidenceFactor(model.ConfidenceFactorMACVendor, 0, "no MAC address"))
+	}
+
+	factors = append(factors, confidenceFactor(model.ConfidenceFactorOpenPorts, len(device.OpenPorts)*openPortPoints, plural(len(device.OpenPorts), "open port")))
+
+	if ev.SNMP {
+		factors = append(factors, confidenceFactor(model.ConfidenceFactorSNMP, model.ConfidenceFactorMax[model.ConfidenceFactorSNMP], "answered SNMP"))
+	} else {
+		factors = append(factors, confidenceFactor(model.ConfidenceFactorSNMP, 0, "no SNMP response"))
+	}
+
+	factors = append(factors, sightingsFactor(device.ScanCount))
+
+	device.ConfidenceBreakdown = factors
+	device.Confidence = confidenceTotal(factors)
+}
+
+// ScoreSightings updates the repeat sightings factor of a scored device
+// after its scan count changed
+func ScoreSightings(device *model.DiscoveredDevice) {
+	for i, f := range device.ConfidenceBreakdown {
+		if f.Factor == model.ConfidenceFactorRepeatSightings {
+			device.ConfidenceBreakdown[i] = sightingsFactor(device.ScanCount)
+			device.Confidence = confidenceTotal(device.ConfidenceBreakdown)
+			return
+		}
+	}
+}
+
+func sightingsFactor(scans int) model.ConfidenceFactor {
+	return confidenceFactor(model.ConfidenceFactorRepeatSightings, max(scans-1, 0)*sightingPoints, "seen by "+plural(scans, "scan"))
+}
+
+// confidenceFactor caps points at the factor's maximum
+func confidenceFactor(factor string, points int, detail string) model.ConfidenceFactor {
+	limit := model.ConfidenceFactorMax[factor]
+	return model.ConfidenceFactor{Factor: factor, Points: min(points, limit), Max: limit, Detail: detail}
+}
+
+func confidenceTotal(factors []model.ConfidenceFactor) int {
+	total := 0
+	for _, f := range factors {
+		total += f.Points
+	}
+	return total
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,78 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestScoreConfidence(t *testing.T) {
+	device := &model.DiscoveredDevice{
+		MACAddress: "00:11:22:33:44:55",
+		Vendor:     "Dell Inc.",
+		OpenPorts:  []int{22, 80, 443, 8080},
+		ScanCount:  1,
+	}
+	ScoreConfidence(device, ConfidenceEvidence{Responded: true, ReverseDNS: "srv-01.example.com"})
+
+	// 20 ping + 20 reverse DNS + 15 vendor + 15 ports (capped) + 0 SNMP + 0 sightings
+	if device.Confidence != 70 {
+		t.Errorf("expected confidence 70, got %d", device.Confidence)
+	}
+	if len(device.ConfidenceBreakdown) != len(model.ConfidenceFactors) {
+		t.Fatalf("expected every factor listed, got %+v", device.ConfidenceBreakdown)
+	}
+	for i, f := range device.ConfidenceBreakdown {
+		if f.Factor != model.ConfidenceFactors[i] || f.Max != model.ConfidenceFactorMax[f.Factor] || f.Detail == "" {
+			t.Errorf("unexpected factor %d: %+v", i, f)
+		}
+	}
+	if got := device.FactorPoints(model.ConfidenceFactorOpenPorts); got != 15 {
+		t.Errorf("expected open ports capped at 15, got %d", got)
+	}
+	if got := device.ConfidenceBreakdown[1].Detail; got != "srv-01.example.com" {
+		t.Errorf("expected PTR name as reverse DNS detail, got %q", got)
+	}
+}
+
+func TestScoreConfidenceUnknownVendor(t *testing.T) {
+	device := &model.DiscoveredDevice{MACAddress: "02:00:00:00:00:01"}
+	ScoreConfidence(device, ConfidenceEvidence{SNMP: true})
+
+	if got := device.FactorPoints(model.ConfidenceFactorMACVendor); got != unknownMACPoints {
+		t.Errorf("expected %d points for an unknown vendor, got %d", unknownMACPoints, got)
+	}
+	if got := device.FactorPoints(model.ConfidenceFactorPing); got != 0 {
+		t.Errorf("expected no ping points without a response, got %d", got)
+	}
+	if device.Confidence != unknownMACPoints+20 {
+		t.Errorf("expected confidence %d, got %d", unknownMACPoints+20, device.Confidence)
+	}
+}
+
+func TestScoreSightings(t *testing.T) {
+	device := &model.DiscoveredDevice{ScanCount: 1}
+	ScoreConfidence(device, ConfidenceEvidence{Responded: true})
+	if device.Confidence != 20 {
+		t.Fatalf("expected confidence 20, got %d", device.Confidence)
+	}
+
+	device.ScanCount = 2
+	ScoreSightings(device)
+	if device.Confidence != 25 || device.FactorPoints(model.ConfidenceFactorRepeatSightings) != sightingPoints {
+		t.Errorf("expected one repeat sighting to add %d points, got %+v", sightingPoints, device.ConfidenceBreakdown)
+	}
+
+	device.ScanCount = 10
+	ScoreSightings(device)
+	if device.Confidence != 30 {
+		t.Errorf("expected repeat sightings capped at 10 points, got confidence %d", device.Confidence)
+	}
+
+	// Devices stored before scoring have no breakdown to update
+	unscored := &model.DiscoveredDevice{ScanCount: 3, Confidence: 2}
+	ScoreSightings(unscored)
+	if unscored.Confidence != 2 || unscored.ConfidenceBreakdown != nil {
+		t.Errorf("expected unscored device left alone, got %+v", unscored)
+	}
+}
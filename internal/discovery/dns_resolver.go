@@ -0,0 +1,143 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dnsLookup is the subset of net.Resolver used by DNSResolver
+type dnsLookup interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// DNSResult holds the outcome of resolving a single live IP
+type DNSResult struct {
+	// Names are the PTR names for the IP, without the trailing dot
+	Names []string
+	// Mismatches describe forward/reverse inconsistencies
+	Mismatches []string
+}
+
+// DNSResolver performs PTR lookups for live IPs and verifies that PTR names
+// and the domains of existing inventory devices resolve back to the same
+// address via A/AAAA records
+type DNSResolver struct {
+	lookup  dnsLookup
+	timeout time.Duration
+}
+
+// NewDNSResolver creates a resolver. When server is empty the system
+// resolver is used, otherwise queries go to server (host or host:port).
+func NewDNSResolver(server string, timeout time.Duration) (*DNSResolver, error) {
+	r := &DNSResolver{lookup: net.DefaultResolver, timeout: timeout}
+	if server == "" {
+		return r, nil
+	}
+
+	addr, err := NormalizeDNSServer(server)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	r.lookup = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	return r, nil
+}
+
+// NormalizeDNSServer validates a DNS server given as host or host:port and
+// returns it as host:port, defaulting to port 53
+func NormalizeDNSServer(server string) (string, error) {
+	server = strings.TrimSpace(server)
+	if server == "" {
+		return "", fmt.Errorf("DNS server is empty")
+	}
+
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		// No port, possibly a bare IPv6 address
+		host, port = strings.Trim(server, "[]"), "53"
+	}
+	if host == "" || strings.ContainsAny(host, " /") {
+		return "", fmt.Errorf("invalid DNS server %q", server)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return "", fmt.Errorf("invalid DNS server port %q", port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// Resolve performs a PTR lookup for ip and forward-verifies every PTR name
+// and every domain in domains. Lookup failures for the PTR itself are not
+// reported as mismatches since many hosts simply have no reverse record.
+func (r *DNSResolver) Resolve(ctx context.Context, ip string, domains []string) *DNSResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result := &DNSResult{Names: []string{}, Mismatches: []string{}}
+	target := net.ParseIP(ip)
+	if target == nil {
+		return result
+	}
+
+	seen := make(map[string]bool)
+	if names, err := r.lookup.LookupAddr(ctx, ip); err == nil {
+		for _, name := range names {
+			name = strings.TrimSuffix(name, ".")
+			if name == "" || seen[strings.ToLower(name)] {
+				continue
+			}
+			seen[strings.ToLower(name)] = true
+			result.Names = append(result.Names, name)
+		}
+	}
+
+	for _, name := range result.Names {
+		if msg := r.verifyForward(ctx, "PTR name", name, target); msg != "" {
+			result.Mismatches = append(result.Mismatches, msg)
+		}
+	}
+
+	for _, domain := range domains {
+		domain = strings.TrimSuffix(strings.TrimSpace(domain), ".")
+		if domain == "" {
+			continue
+		}
+		if msg := r.verifyForward(ctx, "domain", domain, target); msg != "" {
+			result.Mismatches = append(result.Mismatches, msg)
+		}
+		if len(result.Names) > 0 && !seen[strings.ToLower(domain)] {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Sprintf("domain %s is not among PTR names (%s)", domain, strings.Join(result.Names, ", ")))
+		}
+	}
+	return result
+}
+
+// verifyForward checks that name has an A/AAAA record for target and returns
+// a mismatch description if it does not
+func (r *DNSResolver) verifyForward(ctx context.Context, kind, name string, target net.IP) string {
+	addrs, err := r.lookup.LookupIPAddr(ctx, name)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Sprintf("%s %s does not resolve", kind, name)
+	}
+
+	resolved := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		if a.IP.Equal(target) {
+			return ""
+		}
+		resolved = append(resolved, a.IP.String())
+	}
+	sort.Strings(resolved)
+	return fmt.Sprintf("%s %s resolves to %s, not %s", kind, name, strings.Join(resolved, ", "), target)
+}
@@ -0,0 +1,110 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeDNSLookup struct {
+	ptr     map[string][]string
+	forward map[string][]string
+}
+
+func (f *fakeDNSLookup) LookupAddr(_ context.Context, addr string) ([]string, error) {
+	if names, ok := f.ptr[addr]; ok {
+		return names, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func (f *fakeDNSLookup) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := f.forward[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	var addrs []net.IPAddr
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func TestDNSResolverResolve(t *testing.T) {
+	r := &DNSResolver{
+		timeout: time.Second,
+		lookup: &fakeDNSLookup{
+			ptr: map[string][]string{
+				"10.0.0.1": {"web1.example.com.", "WEB1.example.com."},
+				"10.0.0.2": {"stale.example.com."},
+			},
+			forward: map[string][]string{
+				"web1.example.com":  {"10.0.0.1", "2001:db8::1"},
+				"stale.example.com": {"10.0.0.9"},
+				"app.example.com":   {"10.0.0.2"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		ip             string
+		domains        []string
+		wantNames      []string
+		wantMismatches []string
+	}{
+		{"consistent", "10.0.0.1", []string{"web1.example.com"}, []string{"web1.example.com"}, nil},
+		{"no ptr", "10.0.0.3", nil, nil, nil},
+		{"ptr points elsewhere", "10.0.0.2", []string{"app.example.com"}, []string{"stale.example.com"},
+			[]string{"PTR name stale.example.com resolves to 10.0.0.9, not 10.0.0.2", "domain app.example.com is not among PTR names"}},
+		{"unresolvable domain", "10.0.0.3", []string{"missing.example.com."}, nil,
+			[]string{"domain missing.example.com does not resolve"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := r.Resolve(context.Background(), tt.ip, tt.domains)
+			if strings.Join(result.Names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("Names = %v, want %v", result.Names, tt.wantNames)
+			}
+			if len(result.Mismatches) != len(tt.wantMismatches) {
+				t.Fatalf("Mismatches = %v, want %v", result.Mismatches, tt.wantMismatches)
+			}
+			for i, want := range tt.wantMismatches {
+				if !strings.HasPrefix(result.Mismatches[i], want) {
+					t.Errorf("Mismatches[%d] = %q, want prefix %q", i, result.Mismatches[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeDNSServer(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"10.0.0.53", "10.0.0.53:53", false},
+		{"10.0.0.53:5353", "10.0.0.53:5353", false},
+		{"ns1.example.com", "ns1.example.com:53", false},
+		{"2001:db8::53", "[2001:db8::53]:53", false},
+		{"[2001:db8::53]:53", "[2001:db8::53]:53", false},
+		{"", "", true},
+		{"10.0.0.53:dns", "", true},
+		{"10.0.0.53:70000", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeDNSServer(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeDNSServer(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeDNSServer(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
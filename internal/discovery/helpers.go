@@ -11,6 +11,7 @@ const MaxSubnetBits = 16
 var ErrSubnetTooLarge = fmt.Errorf("subnet too large: maximum /%d allowed", 32-MaxSubnetBits)
 var ErrScanNotFound = fmt.Errorf("scan not found")
 var ErrScanNotRunning = fmt.Errorf("scan is not running or pending")
+var ErrScannerShutdown = fmt.Errorf("interrupted by server shutdown")
 
 func countHosts(ipNet *net.IPNet) int {
 	ones, bits := ipNet.Mask.Size()
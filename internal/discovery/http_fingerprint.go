@@ -0,0 +1,175 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// HTTPPorts and TLSPorts are the open ports that get an HTTP fingerprint;
+// TLSPorts also have their certificate recorded
+var (
+	HTTPPorts = []int{80, 443, 8443}
+	TLSPorts  = []int{443, 8443}
+)
+
+// maxTitleBody is how much of a page is read looking for its title
+const maxTitleBody = 64 << 10
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// HTTPFingerprint is what an HTTP or HTTPS service revealed about itself
+type HTTPFingerprint struct {
+	Port        int
+	TLS         bool
+	Server      string
+	Title       string
+	Certificate *model.TLSCertificate
+}
+
+// HTTPFingerprinter records the certificates and HTTP server headers and
+// page titles of web services. Certificates are read without verification,
+// since the point is to see what a host serves, trusted or not.
+type HTTPFingerprinter struct {
+	timeout time.Duration
+}
+
+func NewHTTPFingerprinter(timeout time.Duration) *HTTPFingerprinter {
+	return &HTTPFingerprinter{timeout: timeout}
+}
+
+// Fingerprint probes an open HTTP(S) port. It returns nil when the port
+// yields neither a certificate nor an HTTP response.
+func (f *HTTPFingerprinter) Fingerprint(ctx context.Context, ip string, port int) *HTTPFingerprint {
+	fp := &HTTPFingerprint{Port: port, TLS: slices.Contains(TLSPorts, port)}
+	addr := net.JoinHostPort(ip, fmt.Sprint(port))
+
+	if fp.TLS {
+		fp.Certificate = f.certificate(ctx, addr)
+	}
+	f.httpInfo(ctx, fp, addr)
+
+	if fp.Certificate == nil && fp.Server == "" && fp.Title == "" {
+		return nil
+	}
+	return fp
+}
+
+// certificate returns the leaf certificate presented during a TLS handshake
+func (f *HTTPFingerprinter) certificate(ctx context.Context, addr string) *model.TLSCertificate {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: f.timeout},
+		Config:    &tls.Config{InsecureSkipVerify: true},
+	}
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil
+	}
+	return certificateInfo(certs[0])
+}
+
+func certificateInfo(cert *x509.Certificate) *model.TLSCertificate {
+	info := &model.TLSCertificate{
+		CommonName: cert.Subject.CommonName,
+		Issuer:     cert.Issuer.CommonName,
+		NotBefore:  cert.NotBefore.UTC(),
+		NotAfter:   cert.NotAfter.UTC(),
+	}
+	if info.Issuer == "" {
+		info.Issuer = cert.Issuer.String()
+	}
+	info.SANs = append(info.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	return info
+}
+
+// httpInfo requests the root page and records the Server header and title.
+// Redirects are not followed; the redirect response is fingerprinted.
+func (f *HTTPFingerprinter) httpInfo(ctx context.Context, fp *HTTPFingerprint, addr string) {
+	client := &http.Client{
+		Timeout: f.timeout,
+		Transport: &http.Transport{
+			DialContext:       (&net.Dialer{Timeout: f.timeout}).DialContext,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	scheme := "http"
+	if fp.TLS {
+		scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+addr+"/", nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("User-Agent", "rackd-discovery")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	fp.Server = strings.TrimSpace(resp.Header.Get("Server"))
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxTitleBody))
+	fp.Title = pageTitle(body)
+}
+
+// pageTitle extracts the text of an HTML page's title element
+func pageTitle(body []byte) string {
+	m := titlePattern.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+}
+
+// ApplyHTTPFingerprint records a fingerprint on the service for its port,
+// adding the service when the banner grabber did not identify it
+func ApplyHTTPFingerprint(services []model.ServiceInfo, fp *HTTPFingerprint) []model.ServiceInfo {
+	i := slices.IndexFunc(services, func(s model.ServiceInfo) bool { return s.Port == fp.Port })
+	if i < 0 {
+		service := "http"
+		if fp.TLS {
+			service = "https"
+		}
+		services = append(services, model.ServiceInfo{Port: fp.Port, Protocol: "tcp", Service: service})
+		i = len(services) - 1
+	}
+
+	svc := &services[i]
+	svc.HTTPServer = fp.Server
+	svc.HTTPTitle = fp.Title
+	svc.Certificate = fp.Certificate
+	// The Server header names the product when the banner did not
+	if fp.Server != "" && (svc.Version == "" || svc.Version == "TLS/SSL" || svc.Version == "HTTP/1.1") {
+		svc.Version = fp.Server
+	}
+	return services
+}
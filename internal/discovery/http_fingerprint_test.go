@@ -0,0 +1,75 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestHTTPFingerprinterReadsServerAndTitle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.24.0")
+		w.Write([]byte("<html><head><TITLE>\n  Router &amp; Admin\n</TITLE></head></html>"))
+	}))
+	defer srv.Close()
+
+	f := NewHTTPFingerprinter(2 * time.Second)
+	fp := &HTTPFingerprint{Port: 80}
+	f.httpInfo(context.Background(), fp, strings.TrimPrefix(srv.URL, "http://"))
+	if fp.Server != "nginx/1.24.0" || fp.Title != "Router & Admin" {
+		t.Fatalf("unexpected fingerprint: %+v", fp)
+	}
+}
+
+func TestHTTPFingerprinterReadsCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "Caddy")
+		http.Redirect(w, r, "/login", http.StatusFound)
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "https://")
+
+	f := NewHTTPFingerprinter(2 * time.Second)
+	cert := f.certificate(context.Background(), addr)
+	if cert == nil {
+		t.Fatal("expected certificate from TLS server")
+	}
+	leaf := srv.Certificate()
+	if !cert.NotAfter.Equal(leaf.NotAfter) || len(cert.SANs) == 0 || cert.Issuer == "" {
+		t.Fatalf("unexpected certificate: %+v", cert)
+	}
+
+	// Redirects are fingerprinted rather than followed
+	fp := &HTTPFingerprint{Port: 443, TLS: true}
+	f.httpInfo(context.Background(), fp, addr)
+	if fp.Server != "Caddy" {
+		t.Fatalf("expected Server header from the redirect, got %+v", fp)
+	}
+}
+
+func TestHTTPFingerprinterClosedPort(t *testing.T) {
+	f := NewHTTPFingerprinter(200 * time.Millisecond)
+	if fp := f.Fingerprint(context.Background(), "127.0.0.1", 1); fp != nil {
+		t.Fatalf("expected nil fingerprint for closed port, got %+v", fp)
+	}
+}
+
+func TestApplyHTTPFingerprint(t *testing.T) {
+	cert := &model.TLSCertificate{CommonName: "example.com"}
+	services := []model.ServiceInfo{{Port: 443, Protocol: "tcp", Service: "https", Version: "TLS/SSL"}}
+
+	services = ApplyHTTPFingerprint(services, &HTTPFingerprint{Port: 443, TLS: true, Server: "nginx", Certificate: cert})
+	if len(services) != 1 || services[0].Version != "nginx" || services[0].HTTPServer != "nginx" || services[0].Certificate != cert {
+		t.Fatalf("expected fingerprint on existing service, got %+v", services)
+	}
+
+	services = ApplyHTTPFingerprint(services, &HTTPFingerprint{Port: 80, Title: "Welcome"})
+	if len(services) != 2 || services[1].Service != "http" || services[1].HTTPTitle != "Welcome" || services[1].Version != "" {
+		t.Fatalf("expected new http service, got %+v", services)
+	}
+}
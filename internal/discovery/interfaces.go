@@ -19,3 +19,17 @@ type AdvancedScanner interface {
 	GetNetwork(ctx context.Context, id string) (*model.Network, error)
 	ScanAdvanced(ctx context.Context, network *model.Network, profile *model.ScanProfile, snmpCredID, sshCredID string) (*model.DiscoveryScan, error)
 }
+
+// ProgressWatcher is implemented by scanners that stream the progress of the
+// scans they run
+type ProgressWatcher interface {
+	// WatchScan returns a channel of progress updates for a scan, closed
+	// once the scan completes or fails, and a function that ends the watch
+	WatchScan(scanID string) (<-chan model.DiscoveryScan, func())
+}
+
+// SwitchNeighborCollector is implemented by scanners that can read the LLDP
+// and CDP neighbor tables of a switch over SNMP
+type SwitchNeighborCollector interface {
+	SwitchNeighbors(ctx context.Context, ip, snmpCredID string) ([]SwitchNeighbor, error)
+}
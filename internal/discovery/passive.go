@@ -0,0 +1,161 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const (
+	PassiveSourceMDNS = "mdns"
+	PassiveSourceSSDP = "ssdp"
+)
+
+var (
+	mdnsGroupAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+	ssdpGroupAddr = &net.UDPAddr{IP: net.IPv4(239, 255, 255, 250), Port: 1900}
+)
+
+// PassiveAnnouncement is a host announcement overheard on the local segment.
+type PassiveAnnouncement struct {
+	IP       string
+	Hostname string
+	Source   string
+	Service  model.ServiceInfo
+	SeenAt   time.Time
+}
+
+// PassiveListener joins the mDNS and SSDP multicast groups and reports every
+// announcement it hears. It never transmits anything on the network.
+type PassiveListener struct {
+	handler func(PassiveAnnouncement)
+	mdns    *mDNSScanner
+}
+
+// NewPassiveListener creates a listener that calls handler for each announcement.
+func NewPassiveListener(handler func(PassiveAnnouncement)) *PassiveListener {
+	return &PassiveListener{
+		handler: handler,
+		mdns:    NewmDNSScanner(0),
+	}
+}
+
+// Run listens until ctx is cancelled. It returns an error only when neither
+// multicast group could be joined.
+func (l *PassiveListener) Run(ctx context.Context) error {
+	mdnsConn, mdnsErr := net.ListenMulticastUDP("udp4", nil, mdnsGroupAddr)
+	ssdpConn, ssdpErr := net.ListenMulticastUDP("udp4", nil, ssdpGroupAddr)
+	if mdnsErr != nil && ssdpErr != nil {
+		return fmt.Errorf("failed to join multicast groups: mdns: %v, ssdp: %v", mdnsErr, ssdpErr)
+	}
+
+	var wg sync.WaitGroup
+	if mdnsErr == nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.readLoop(ctx, mdnsConn, l.parseMDNS)
+		}()
+	}
+	if ssdpErr == nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.readLoop(ctx, ssdpConn, parseSSDPAnnouncement)
+		}()
+	}
+
+	<-ctx.Done()
+	if mdnsConn != nil {
+		mdnsConn.Close()
+	}
+	if ssdpConn != nil {
+		ssdpConn.Close()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (l *PassiveListener) readLoop(ctx context.Context, conn *net.UDPConn, parse func([]byte, net.Addr) []PassiveAnnouncement) {
+	buf := make([]byte, 9000)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		now := time.Now()
+		for _, a := range parse(buf[:n], addr) {
+			a.SeenAt = now
+			l.handler(a)
+		}
+	}
+}
+
+func (l *PassiveListener) parseMDNS(data []byte, addr net.Addr) []PassiveAnnouncement {
+	var out []PassiveAnnouncement
+	for _, r := range l.mdns.parsemDNSResponse(data, addr) {
+		if r.IP == "" {
+			continue
+		}
+		out = append(out, PassiveAnnouncement{
+			IP:       r.IP,
+			Hostname: r.Hostname,
+			Source:   PassiveSourceMDNS,
+			Service:  model.ServiceInfo{Port: mdnsGroupAddr.Port, Protocol: PassiveSourceMDNS, Service: r.Type},
+		})
+	}
+	return out
+}
+
+// parseSSDPAnnouncement parses an SSDP NOTIFY ssdp:alive message. Byebye
+// messages and M-SEARCH requests from other hosts are ignored.
+func parseSSDPAnnouncement(data []byte, addr net.Addr) []PassiveAnnouncement {
+	tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(data)))
+	line, err := tp.ReadLine()
+	if err != nil || !strings.HasPrefix(strings.ToUpper(line), "NOTIFY ") {
+		return nil
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && len(header) == 0 {
+		return nil
+	}
+	if !strings.EqualFold(header.Get("NTS"), "ssdp:alive") {
+		return nil
+	}
+
+	ip := ""
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		ip = udpAddr.IP.String()
+	}
+	if ip == "" {
+		return nil
+	}
+
+	service := header.Get("NT")
+	if service == "" {
+		service = header.Get("USN")
+	}
+	info := model.ServiceInfo{
+		Port:     ssdpGroupAddr.Port,
+		Protocol: PassiveSourceSSDP,
+		Service:  service,
+		Version:  header.Get("SERVER"),
+	}
+	info.Product, info.ProductVersion, info.Info = ParseServiceVersion(info.Version)
+	return []PassiveAnnouncement{{
+		IP:      ip,
+		Source:  PassiveSourceSSDP,
+		Service: info,
+	}}
+}
@@ -0,0 +1,87 @@
+package discovery
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseSSDPAnnouncement(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.ParseIP("192.168.1.50"), Port: 1900}
+
+	alive := "NOTIFY * HTTP/1.1\r\n" +
+		"HOST: 239.255.255.250:1900\r\n" +
+		"NT: urn:schemas-upnp-org:device:MediaRenderer:1\r\n" +
+		"NTS: ssdp:alive\r\n" +
+		"SERVER: Linux/5.10 UPnP/1.0 Sonos/70.3\r\n" +
+		"USN: uuid:RINCON_1234::urn:schemas-upnp-org:device:MediaRenderer:1\r\n\r\n"
+
+	got := parseSSDPAnnouncement([]byte(alive), addr)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 announcement, got %d", len(got))
+	}
+	a := got[0]
+	if a.IP != "192.168.1.50" {
+		t.Errorf("IP = %q, want 192.168.1.50", a.IP)
+	}
+	if a.Source != PassiveSourceSSDP {
+		t.Errorf("Source = %q, want %q", a.Source, PassiveSourceSSDP)
+	}
+	if a.Service.Service != "urn:schemas-upnp-org:device:MediaRenderer:1" {
+		t.Errorf("Service = %q", a.Service.Service)
+	}
+	if a.Service.Version != "Linux/5.10 UPnP/1.0 Sonos/70.3" {
+		t.Errorf("Version = %q", a.Service.Version)
+	}
+	if a.Service.Port != 1900 {
+		t.Errorf("Port = %d, want 1900", a.Service.Port)
+	}
+}
+
+func TestParseSSDPAnnouncement_Ignored(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.ParseIP("192.168.1.50"), Port: 1900}
+
+	tests := []struct {
+		name string
+		msg  string
+	}{
+		{"byebye", "NOTIFY * HTTP/1.1\r\nNT: upnp:rootdevice\r\nNTS: ssdp:byebye\r\n\r\n"},
+		{"m-search", "M-SEARCH * HTTP/1.1\r\nMAN: \"ssdp:discover\"\r\nST: ssdp:all\r\n\r\n"},
+		{"garbage", "\x00\x01\x02"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSSDPAnnouncement([]byte(tt.msg), addr); len(got) != 0 {
+				t.Errorf("expected no announcements, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestPassiveListener_ParseMDNS(t *testing.T) {
+	l := NewPassiveListener(func(PassiveAnnouncement) {})
+
+	// Unsolicited response with one A record for printer.local -> 192.168.1.20
+	packet := []byte{
+		0x00, 0x00, 0x84, 0x00, // id, flags (response, authoritative)
+		0x00, 0x00, 0x00, 0x01, // qdcount 0, ancount 1
+		0x00, 0x00, 0x00, 0x00, // nscount, arcount
+		0x07, 'p', 'r', 'i', 'n', 't', 'e', 'r',
+		0x05, 'l', 'o', 'c', 'a', 'l', 0x00,
+		0x00, 0x01, 0x80, 0x01, // type A, class IN (cache flush)
+		0x00, 0x00, 0x00, 0x78, // ttl
+		0x00, 0x04, 192, 168, 1, 20,
+	}
+	addr := &net.UDPAddr{IP: net.ParseIP("192.168.1.20"), Port: 5353}
+
+	got := l.parseMDNS(packet, addr)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 announcement, got %d", len(got))
+	}
+	if got[0].IP != "192.168.1.20" || got[0].Hostname != "printer" {
+		t.Errorf("unexpected announcement: %+v", got[0])
+	}
+	if got[0].Source != PassiveSourceMDNS || got[0].Service.Protocol != PassiveSourceMDNS {
+		t.Errorf("unexpected source: %+v", got[0])
+	}
+}
@@ -0,0 +1,75 @@
+package discovery
+
+import (
+	"sync"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// progressBuffer is how many updates a watcher can fall behind before older
+// updates are dropped in favour of the latest one
+const progressBuffer = 16
+
+// progressWatchers fans the progress of running scans out to watchers. A
+// watcher's channel is closed once its scan completes or fails.
+type progressWatchers struct {
+	mu       sync.Mutex
+	watchers map[string]map[chan model.DiscoveryScan]struct{}
+}
+
+func newProgressWatchers() *progressWatchers {
+	return &progressWatchers{watchers: make(map[string]map[chan model.DiscoveryScan]struct{})}
+}
+
+// watch subscribes to the progress of a scan. The returned function ends the
+// subscription and is safe to call after the channel was closed.
+func (p *progressWatchers) watch(scanID string) (<-chan model.DiscoveryScan, func()) {
+	ch := make(chan model.DiscoveryScan, progressBuffer)
+
+	p.mu.Lock()
+	if p.watchers[scanID] == nil {
+		p.watchers[scanID] = make(map[chan model.DiscoveryScan]struct{})
+	}
+	p.watchers[scanID][ch] = struct{}{}
+	p.mu.Unlock()
+
+	return ch, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := p.watchers[scanID][ch]; ok {
+			delete(p.watchers[scanID], ch)
+			if len(p.watchers[scanID]) == 0 {
+				delete(p.watchers, scanID)
+			}
+			close(ch)
+		}
+	}
+}
+
+// publish sends a snapshot of a scan to its watchers without blocking, and
+// closes their channels when the scan is finished
+func (p *progressWatchers) publish(scan model.DiscoveryScan) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	finished := scan.Status == model.ScanStatusCompleted || scan.Status == model.ScanStatusFailed
+	for ch := range p.watchers[scan.ID] {
+		select {
+		case ch <- scan:
+		default:
+			// Drop the oldest update; only the publisher sends, so there is
+			// room for the latest one afterwards
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- scan
+		}
+		if finished {
+			close(ch)
+		}
+	}
+	if finished {
+		delete(p.watchers, scan.ID)
+	}
+}
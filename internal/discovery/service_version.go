@@ -0,0 +1,157 @@
+package discovery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// Mapping targets supported by service mapping rules
+const (
+	MappingTargetOS        = "os"
+	MappingTargetMakeModel = "make_model"
+)
+
+// protocolWords are leading banner tokens that name the protocol rather than
+// the product, e.g. "ESMTP Postfix".
+var protocolWords = map[string]bool{
+	"ESMTP": true,
+	"SMTP":  true,
+	"FTP":   true,
+	"POP3":  true,
+	"IMAP":  true,
+	"IMAP4": true,
+}
+
+// ParseServiceVersion splits a raw version banner such as
+// "nginx/1.18.0 (Ubuntu)" or "OpenSSH_8.9p1 Ubuntu-3ubuntu0.1" into product,
+// version and any trailing information.
+func ParseServiceVersion(raw string) (product, version, info string) {
+	raw = strings.TrimSpace(raw)
+	if i := strings.IndexAny(raw, "\r\n"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	raw = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(raw, "("), ")"))
+	if raw == "" {
+		return "", "", ""
+	}
+
+	tokens := strings.Fields(raw)
+	for len(tokens) > 1 && protocolWords[strings.ToUpper(tokens[0])] {
+		tokens = tokens[1:]
+	}
+
+	first, rest := tokens[0], tokens[1:]
+	switch {
+	case strings.Contains(first, "/") && startsWithDigit(first[strings.Index(first, "/")+1:]):
+		i := strings.Index(first, "/")
+		product, version = first[:i], first[i+1:]
+	case strings.Contains(first, "_") && startsWithDigit(first[strings.Index(first, "_")+1:]):
+		i := strings.Index(first, "_")
+		product, version = first[:i], first[i+1:]
+	case len(rest) > 0 && startsWithDigit(rest[0]):
+		product, version = first, rest[0]
+		rest = rest[1:]
+	default:
+		product = first
+	}
+
+	info = strings.Join(rest, " ")
+	info = strings.TrimSpace(strings.NewReplacer("(", "", ")", "").Replace(info))
+	return product, version, info
+}
+
+// ParseServices fills the structured fields of each service from its raw
+// version string. Services that already carry a product are left untouched.
+func ParseServices(services []model.ServiceInfo) []model.ServiceInfo {
+	for i := range services {
+		if services[i].Product != "" || services[i].Version == "" {
+			continue
+		}
+		services[i].Product, services[i].ProductVersion, services[i].Info = ParseServiceVersion(services[i].Version)
+	}
+	return services
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// ServiceMapping maps a match on a parsed service field to a device field,
+// e.g. "info=Ubuntu => os=Ubuntu Linux".
+type ServiceMapping struct {
+	Field  string // service, product, version or info
+	Match  string // case-insensitive substring
+	Target string // os or make_model
+	Value  string
+}
+
+// ParseServiceMappings parses a semicolon separated list of mapping rules in
+// the form "field=match=>target=value".
+func ParseServiceMappings(spec string) ([]ServiceMapping, error) {
+	var mappings []ServiceMapping
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lhs, rhs, ok := strings.Cut(part, "=>")
+		if !ok {
+			return nil, fmt.Errorf("invalid service mapping %q: expected field=match=>target=value", part)
+		}
+		field, match, ok1 := strings.Cut(strings.TrimSpace(lhs), "=")
+		target, value, ok2 := strings.Cut(strings.TrimSpace(rhs), "=")
+		if !ok1 || !ok2 || strings.TrimSpace(match) == "" || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("invalid service mapping %q: expected field=match=>target=value", part)
+		}
+		m := ServiceMapping{
+			Field:  strings.ToLower(strings.TrimSpace(field)),
+			Match:  strings.TrimSpace(match),
+			Target: strings.ToLower(strings.TrimSpace(target)),
+			Value:  strings.TrimSpace(value),
+		}
+		switch m.Field {
+		case "service", "product", "version", "info":
+		default:
+			return nil, fmt.Errorf("invalid service mapping %q: unknown field %q", part, m.Field)
+		}
+		if m.Target != MappingTargetOS && m.Target != MappingTargetMakeModel {
+			return nil, fmt.Errorf("invalid service mapping %q: unknown target %q", part, m.Target)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, nil
+}
+
+// ApplyServiceMappings returns the device field values selected by the first
+// matching rule for each target.
+func ApplyServiceMappings(mappings []ServiceMapping, services []model.ServiceInfo) map[string]string {
+	result := make(map[string]string)
+	for _, m := range mappings {
+		if _, done := result[m.Target]; done {
+			continue
+		}
+		for _, svc := range services {
+			if strings.Contains(strings.ToLower(serviceField(svc, m.Field)), strings.ToLower(m.Match)) {
+				result[m.Target] = m.Value
+				break
+			}
+		}
+	}
+	return result
+}
+
+func serviceField(svc model.ServiceInfo, field string) string {
+	switch field {
+	case "service":
+		return svc.Service
+	case "product":
+		return svc.Product
+	case "version":
+		return svc.ProductVersion
+	case "info":
+		return svc.Info
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestParseServiceVersion(t *testing.T) {
+	tests := []struct {
+		raw     string
+		product string
+		version string
+		info    string
+	}{
+		{"nginx/1.18.0 (Ubuntu)", "nginx", "1.18.0", "Ubuntu"},
+		{"OpenSSH_8.9p1 Ubuntu-3ubuntu0.1", "OpenSSH", "8.9p1", "Ubuntu-3ubuntu0.1"},
+		{"Microsoft-IIS/10.0", "Microsoft-IIS", "10.0", ""},
+		{"(vsFTPd 3.0.3)", "vsFTPd", "3.0.3", ""},
+		{"ESMTP Postfix (Debian/GNU)", "Postfix", "", "Debian/GNU"},
+		{"Linux/5.4 UPnP/1.0 MiniUPnPd/2.1", "Linux", "5.4", "UPnP/1.0 MiniUPnPd/2.1"},
+		{"TLS/SSL", "TLS/SSL", "", ""},
+		{"Apache/2.4.41 (Ubuntu)\r\nDate: today", "Apache", "2.4.41", "Ubuntu"},
+		{"", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		product, version, info := ParseServiceVersion(tt.raw)
+		if product != tt.product || version != tt.version || info != tt.info {
+			t.Errorf("ParseServiceVersion(%q) = (%q, %q, %q), want (%q, %q, %q)",
+				tt.raw, product, version, info, tt.product, tt.version, tt.info)
+		}
+	}
+}
+
+func TestParseServices_KeepsExistingProduct(t *testing.T) {
+	services := ParseServices([]model.ServiceInfo{
+		{Service: "http", Version: "nginx/1.18.0"},
+		{Service: "ssh", Version: "OpenSSH_9.0", Product: "custom"},
+		{Service: "https"},
+	})
+
+	if services[0].Product != "nginx" || services[0].ProductVersion != "1.18.0" {
+		t.Errorf("Expected nginx 1.18.0, got %+v", services[0])
+	}
+	if services[1].Product != "custom" || services[1].ProductVersion != "" {
+		t.Errorf("Expected existing product to be kept, got %+v", services[1])
+	}
+	if services[2].Product != "" {
+		t.Errorf("Expected no product without a version, got %+v", services[2])
+	}
+}
+
+func TestParseServiceMappings(t *testing.T) {
+	mappings, err := ParseServiceMappings("info=Ubuntu=>os=Ubuntu Linux; product=Microsoft-IIS => os=Windows Server;")
+	if err != nil {
+		t.Fatalf("ParseServiceMappings returned error: %v", err)
+	}
+	if len(mappings) != 2 {
+		t.Fatalf("Expected 2 mappings, got %d", len(mappings))
+	}
+	want := ServiceMapping{Field: "product", Match: "Microsoft-IIS", Target: MappingTargetOS, Value: "Windows Server"}
+	if mappings[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, mappings[1])
+	}
+
+	if mappings, err := ParseServiceMappings(""); err != nil || len(mappings) != 0 {
+		t.Errorf("Expected no mappings for empty spec, got %v, %v", mappings, err)
+	}
+
+	for _, spec := range []string{"product=Ubuntu", "color=red=>os=Linux", "product=Ubuntu=>hostname=x", "product==>os=Linux"} {
+		if _, err := ParseServiceMappings(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestApplyServiceMappings(t *testing.T) {
+	mappings, _ := ParseServiceMappings("product=openssh=>os=Linux;info=ubuntu=>os=Ubuntu;service=ipp=>make_model=Printer")
+	services := ParseServices([]model.ServiceInfo{
+		{Port: 22, Service: "ssh", Version: "OpenSSH_8.9p1 Ubuntu-3ubuntu0.1"},
+	})
+
+	result := ApplyServiceMappings(mappings, services)
+	if result[MappingTargetOS] != "Linux" {
+		t.Errorf("Expected first matching rule to win, got %q", result[MappingTargetOS])
+	}
+	if _, ok := result[MappingTargetMakeModel]; ok {
+		t.Errorf("Expected no make_model mapping, got %q", result[MappingTargetMakeModel])
+	}
+}
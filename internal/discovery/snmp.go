@@ -46,6 +46,22 @@ type SNMPInterface struct {
 }
 
 func (s *SNMPScanner) Scan(ctx context.Context, ip string, credentialID string) (*SNMPResult, error) {
+	client, err := s.connect(ip, credentialID)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Conn.Close()
+
+	result := &SNMPResult{}
+	s.getSysInfo(client, result)
+	s.getInterfaces(client, result)
+	s.getARPTable(client, result)
+
+	return result, nil
+}
+
+// connect opens an SNMP session to ip with the stored credential
+func (s *SNMPScanner) connect(ip string, credentialID string) (*gosnmp.GoSNMP, error) {
 	cred, err := s.credStore.Get(credentialID)
 	if err != nil {
 		return nil, fmt.Errorf("credential lookup failed: %w", err)
@@ -85,14 +101,7 @@ func (s *SNMPScanner) Scan(ctx context.Context, ip string, credentialID string)
 	if err := client.ConnectIPv4(); err != nil {
 		return nil, fmt.Errorf("SNMP connect failed: %w", err)
 	}
-	defer client.Conn.Close()
-
-	result := &SNMPResult{}
-	s.getSysInfo(client, result)
-	s.getInterfaces(client, result)
-	s.getARPTable(client, result)
-
-	return result, nil
+	return client, nil
 }
 
 func (s *SNMPScanner) getSysInfo(client *gosnmp.GoSNMP, result *SNMPResult) {
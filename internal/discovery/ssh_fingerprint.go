@@ -0,0 +1,53 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"golang.org/x/crypto/ssh"
+)
+
+// errHostKeyCollected ends a handshake once the server presented its key
+var errHostKeyCollected = errors.New("host key collected")
+
+// SSHFingerprinter collects the host key an SSH server presents. Only the
+// key exchange runs, so no credentials are needed and nothing is logged in.
+type SSHFingerprinter struct {
+	timeout time.Duration
+}
+
+func NewSSHFingerprinter(timeout time.Duration) *SSHFingerprinter {
+	return &SSHFingerprinter{timeout: timeout}
+}
+
+// Fingerprint returns the SHA256 fingerprint of the host key served at addr,
+// or nil when the SSH handshake does not get that far
+func (f *SSHFingerprinter) Fingerprint(ctx context.Context, addr string) *model.SSHHostKey {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{Timeout: f.timeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var key *model.SSHHostKey
+	config := &ssh.ClientConfig{
+		User: "rackd-discovery",
+		HostKeyCallback: func(_ string, _ net.Addr, pub ssh.PublicKey) error {
+			key = &model.SSHHostKey{KeyType: pub.Type(), Fingerprint: ssh.FingerprintSHA256(pub)}
+			return errHostKeyCollected
+		},
+		Timeout: f.timeout,
+	}
+	// The handshake always fails: the callback rejects the key it collects
+	_, _, _, _ = ssh.NewClientConn(conn, addr, config)
+	return key
+}
@@ -0,0 +1,72 @@
+package discovery
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHFingerprinterCollectsHostKey(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				ssh.NewServerConn(conn, config)
+			}()
+		}
+	}()
+
+	f := NewSSHFingerprinter(2 * time.Second)
+	key := f.Fingerprint(context.Background(), listener.Addr().String())
+	if key == nil {
+		t.Fatal("expected a host key")
+	}
+	if key.KeyType != ssh.KeyAlgoED25519 || key.Fingerprint != ssh.FingerprintSHA256(signer.PublicKey()) {
+		t.Errorf("unexpected host key %+v", key)
+	}
+}
+
+func TestSSHFingerprinterNotSSH(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+
+	f := NewSSHFingerprinter(time.Second)
+	if key := f.Fingerprint(context.Background(), listener.Addr().String()); key != nil {
+		t.Fatalf("expected no host key from a non-SSH service, got %+v", key)
+	}
+}
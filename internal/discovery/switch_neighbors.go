@@ -0,0 +1,301 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Neighbor protocols
+const (
+	NeighborLLDP = "lldp"
+	NeighborCDP  = "cdp"
+)
+
+const (
+	oidIfName            = "1.3.6.1.2.1.31.1.1.1.1"
+	oidLLDPLocPortTable  = "1.0.8802.1.1.2.1.3.7.1"
+	oidLLDPLocPortID     = "1.0.8802.1.1.2.1.3.7.1.3"
+	oidLLDPLocPortDesc   = "1.0.8802.1.1.2.1.3.7.1.4"
+	oidLLDPRemTable      = "1.0.8802.1.1.2.1.4.1.1"
+	oidLLDPRemManAddrSub = "1.0.8802.1.1.2.1.4.2.1.3"
+	oidCDPCacheTable     = "1.3.6.1.4.1.9.9.23.1.2.1.1"
+)
+
+// SwitchNeighbor is a device a switch sees on one of its ports through LLDP
+// or CDP. MAC, IP and Name are whatever the neighbor advertised.
+type SwitchNeighbor struct {
+	LocalPort  string
+	Protocol   string
+	MAC        string
+	IP         string
+	Name       string
+	RemotePort string
+}
+
+// SwitchNeighbors reads the LLDP and CDP neighbor tables of a switch
+func (s *UnifiedScanner) SwitchNeighbors(ctx context.Context, ip, snmpCredID string) ([]SwitchNeighbor, error) {
+	return s.snmpScanner.Neighbors(ctx, ip, snmpCredID)
+}
+
+// Neighbors walks the LLDP-MIB and CISCO-CDP-MIB neighbor tables of a switch.
+// A switch that answers neither walk is reported as an error.
+func (s *SNMPScanner) Neighbors(ctx context.Context, ip string, credentialID string) ([]SwitchNeighbor, error) {
+	client, err := s.connect(ip, credentialID)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Conn.Close()
+
+	ifNames := make(map[int]string)
+	if pdus, err := client.WalkAll(oidIfName); err == nil {
+		for _, pdu := range pdus {
+			if idx, ok := oidIndex(pdu.Name, oidIfName); ok && len(idx) == 1 {
+				ifNames[idx[0]] = pduString(pdu)
+			}
+		}
+	}
+
+	var neighbors []SwitchNeighbor
+	remote, lldpErr := client.WalkAll(oidLLDPRemTable)
+	if lldpErr == nil {
+		locPorts, _ := client.WalkAll(oidLLDPLocPortTable)
+		mgmt, _ := client.WalkAll(oidLLDPRemManAddrSub)
+		neighbors = append(neighbors, lldpNeighbors(remote, mgmt, locPorts)...)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	cache, cdpErr := client.WalkAll(oidCDPCacheTable)
+	if cdpErr == nil {
+		neighbors = append(neighbors, cdpNeighbors(cache, ifNames)...)
+	}
+	if lldpErr != nil && cdpErr != nil {
+		return nil, fmt.Errorf("LLDP and CDP walks failed: %w", lldpErr)
+	}
+	return neighbors, nil
+}
+
+// lldpNeighbors builds neighbors from walks of the LLDP remote systems
+// table, the remote management address table and the local port table
+func lldpNeighbors(remote, mgmt, localPorts []gosnmp.SnmpPDU) []SwitchNeighbor {
+	// Local port names, preferring the port ID when it is readable
+	portIDs := make(map[int]string)
+	portDescs := make(map[int]string)
+	for _, pdu := range localPorts {
+		if idx, ok := oidIndex(pdu.Name, oidLLDPLocPortID); ok && len(idx) == 1 {
+			portIDs[idx[0]] = printable(pdu)
+		} else if idx, ok := oidIndex(pdu.Name, oidLLDPLocPortDesc); ok && len(idx) == 1 {
+			portDescs[idx[0]] = pduString(pdu)
+		}
+	}
+
+	// Remote entries are indexed by time mark, local port and remote index
+	type entry struct {
+		localPort                   int
+		chassisSubtype, portSubtype int
+		chassisID, portID           []byte
+		portDesc, sysName           string
+		ip                          string
+	}
+	entries := make(map[[2]int]*entry)
+	var order [][2]int
+	get := func(localPort, remIndex int) *entry {
+		key := [2]int{localPort, remIndex}
+		if entries[key] == nil {
+			entries[key] = &entry{localPort: localPort}
+			order = append(order, key)
+		}
+		return entries[key]
+	}
+
+	for _, pdu := range remote {
+		idx, ok := oidIndex(pdu.Name, oidLLDPRemTable)
+		if !ok || len(idx) != 4 {
+			continue
+		}
+		e := get(idx[2], idx[3])
+		switch idx[0] {
+		case 4:
+			e.chassisSubtype = pduInt(pdu)
+		case 5:
+			e.chassisID = pduBytes(pdu)
+		case 6:
+			e.portSubtype = pduInt(pdu)
+		case 7:
+			e.portID = pduBytes(pdu)
+		case 8:
+			e.portDesc = pduString(pdu)
+		case 9:
+			e.sysName = pduString(pdu)
+		}
+	}
+
+	// The management address is encoded in the index: time mark, local
+	// port, remote index, address subtype, address length and the address
+	for _, pdu := range mgmt {
+		idx, ok := oidIndex(pdu.Name, oidLLDPRemManAddrSub)
+		if !ok || len(idx) != 9 || idx[3] != 1 || idx[4] != 4 {
+			continue
+		}
+		key := [2]int{idx[1], idx[2]}
+		if e := entries[key]; e != nil && e.ip == "" {
+			e.ip = net.IPv4(byte(idx[5]), byte(idx[6]), byte(idx[7]), byte(idx[8])).String()
+		}
+	}
+
+	var neighbors []SwitchNeighbor
+	for _, key := range order {
+		e := entries[key]
+		n := SwitchNeighbor{
+			LocalPort: portIDs[e.localPort],
+			Protocol:  NeighborLLDP,
+			Name:      e.sysName,
+		}
+		if n.LocalPort == "" {
+			n.LocalPort = portDescs[e.localPort]
+		}
+		if n.LocalPort == "" {
+			n.LocalPort = strconv.Itoa(e.localPort)
+		}
+
+		// Subtype 3 port IDs are the MAC of the neighbor's own interface,
+		// which identifies the address better than a chassis MAC
+		switch {
+		case e.portSubtype == 3 && len(e.portID) == 6:
+			n.MAC = formatMAC(e.portID)
+		case e.chassisSubtype == 4 && len(e.chassisID) == 6:
+			n.MAC = formatMAC(e.chassisID)
+		}
+		if e.portSubtype != 3 && utf8.Valid(e.portID) {
+			n.RemotePort = string(e.portID)
+		}
+		if n.RemotePort == "" {
+			n.RemotePort = e.portDesc
+		}
+		n.IP = e.ip
+		if n.IP == "" && e.chassisSubtype == 5 && len(e.chassisID) == 5 && e.chassisID[0] == 1 {
+			n.IP = net.IP(e.chassisID[1:5]).String()
+		}
+		if n.MAC == "" && n.IP == "" && n.Name == "" {
+			continue
+		}
+		neighbors = append(neighbors, n)
+	}
+	return neighbors
+}
+
+// cdpNeighbors builds neighbors from a walk of the CDP cache table, which is
+// indexed by the local interface and a device index
+func cdpNeighbors(cache []gosnmp.SnmpPDU, ifNames map[int]string) []SwitchNeighbor {
+	type entry struct {
+		ifIndex  int
+		address  []byte
+		deviceID string
+		port     string
+	}
+	entries := make(map[[2]int]*entry)
+	var order [][2]int
+
+	for _, pdu := range cache {
+		idx, ok := oidIndex(pdu.Name, oidCDPCacheTable)
+		if !ok || len(idx) != 3 {
+			continue
+		}
+		key := [2]int{idx[1], idx[2]}
+		e := entries[key]
+		if e == nil {
+			e = &entry{ifIndex: idx[1]}
+			entries[key] = e
+			order = append(order, key)
+		}
+		switch idx[0] {
+		case 4:
+			e.address = pduBytes(pdu)
+		case 6:
+			e.deviceID = pduString(pdu)
+		case 7:
+			e.port = pduString(pdu)
+		}
+	}
+
+	var neighbors []SwitchNeighbor
+	for _, key := range order {
+		e := entries[key]
+		n := SwitchNeighbor{
+			LocalPort:  ifNames[e.ifIndex],
+			Protocol:   NeighborCDP,
+			Name:       e.deviceID,
+			RemotePort: e.port,
+		}
+		if n.LocalPort == "" {
+			n.LocalPort = strconv.Itoa(e.ifIndex)
+		}
+		if len(e.address) == 4 {
+			n.IP = net.IP(e.address).String()
+		}
+		if n.IP == "" && n.Name == "" {
+			continue
+		}
+		neighbors = append(neighbors, n)
+	}
+	return neighbors
+}
+
+// oidIndex returns the numeric parts of an OID after a table or column
+// prefix
+func oidIndex(name, prefix string) ([]int, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(name, "."), prefix+".")
+	if !ok {
+		return nil, false
+	}
+	parts := strings.Split(rest, ".")
+	idx := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, false
+		}
+		idx[i] = n
+	}
+	return idx, true
+}
+
+func pduBytes(pdu gosnmp.SnmpPDU) []byte {
+	switch v := pdu.Value.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
+
+func pduString(pdu gosnmp.SnmpPDU) string {
+	return strings.TrimRight(string(pduBytes(pdu)), "\x00")
+}
+
+func pduInt(pdu gosnmp.SnmpPDU) int {
+	return int(gosnmp.ToBigInt(pdu.Value).Int64())
+}
+
+// printable returns an octet string value unless it holds binary data, such
+// as a MAC address
+func printable(pdu gosnmp.SnmpPDU) string {
+	s := pduString(pdu)
+	for _, r := range s {
+		if r < 0x20 || r == utf8.RuneError {
+			return ""
+		}
+	}
+	return s
+}
+
+func formatMAC(b []byte) string {
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", b[0], b[1], b[2], b[3], b[4], b[5])
+}
@@ -0,0 +1,94 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func octets(name string, value []byte) gosnmp.SnmpPDU {
+	return gosnmp.SnmpPDU{Name: "." + name, Type: gosnmp.OctetString, Value: value}
+}
+
+func integer(name string, value int) gosnmp.SnmpPDU {
+	return gosnmp.SnmpPDU{Name: "." + name, Type: gosnmp.Integer, Value: value}
+}
+
+func TestLLDPNeighbors(t *testing.T) {
+	localPorts := []gosnmp.SnmpPDU{
+		octets(oidLLDPLocPortID+".12", []byte("Gi1/0/12")),
+		octets(oidLLDPLocPortDesc+".12", []byte("GigabitEthernet1/0/12")),
+		// A MAC port ID is not a usable name, so the description is used
+		octets(oidLLDPLocPortID+".13", []byte{0x00, 0x1b, 0x21, 0x0a, 0x0b, 0x0c}),
+		octets(oidLLDPLocPortDesc+".13", []byte("Gi1/0/13")),
+	}
+	remote := []gosnmp.SnmpPDU{
+		// web-01 advertises its interface MAC as the port ID
+		integer(oidLLDPRemTable+".4.0.12.1", 4),
+		octets(oidLLDPRemTable+".5.0.12.1", []byte{0xaa, 0xbb, 0xcc, 0x00, 0x00, 0x01}),
+		integer(oidLLDPRemTable+".6.0.12.1", 3),
+		octets(oidLLDPRemTable+".7.0.12.1", []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}),
+		octets(oidLLDPRemTable+".8.0.12.1", []byte("eth0")),
+		octets(oidLLDPRemTable+".9.0.12.1", []byte("web-01")),
+		// db-01 only gives a chassis MAC and an interface name
+		integer(oidLLDPRemTable+".4.0.13.2", 4),
+		octets(oidLLDPRemTable+".5.0.13.2", []byte{0x00, 0x66, 0x77, 0x88, 0x99, 0xaa}),
+		integer(oidLLDPRemTable+".6.0.13.2", 5),
+		octets(oidLLDPRemTable+".7.0.13.2", []byte("eno1")),
+		octets(oidLLDPRemTable+".9.0.13.2", []byte("db-01.example.com")),
+	}
+	mgmt := []gosnmp.SnmpPDU{
+		integer(oidLLDPRemManAddrSub+".0.13.2.1.4.10.0.0.20", 2),
+	}
+
+	neighbors := lldpNeighbors(remote, mgmt, localPorts)
+	if len(neighbors) != 2 {
+		t.Fatalf("expected 2 neighbors, got %+v", neighbors)
+	}
+
+	web := neighbors[0]
+	if web.LocalPort != "Gi1/0/12" || web.Protocol != NeighborLLDP || web.MAC != "00:11:22:33:44:55" ||
+		web.Name != "web-01" || web.RemotePort != "eth0" || web.IP != "" {
+		t.Errorf("unexpected web-01 neighbor: %+v", web)
+	}
+	db := neighbors[1]
+	if db.LocalPort != "Gi1/0/13" || db.MAC != "00:66:77:88:99:aa" || db.IP != "10.0.0.20" ||
+		db.Name != "db-01.example.com" || db.RemotePort != "eno1" {
+		t.Errorf("unexpected db-01 neighbor: %+v", db)
+	}
+}
+
+func TestCDPNeighbors(t *testing.T) {
+	cache := []gosnmp.SnmpPDU{
+		octets(oidCDPCacheTable+".4.10101.3", []byte{10, 0, 0, 30}),
+		octets(oidCDPCacheTable+".6.10101.3", []byte("ap-01")),
+		octets(oidCDPCacheTable+".7.10101.3", []byte("GigabitEthernet0")),
+		// Unknown interface index falls back to the number
+		octets(oidCDPCacheTable+".6.20.1", []byte("phone-7")),
+		// Entries without an address or name are dropped
+		octets(oidCDPCacheTable+".7.21.1", []byte("Port 1")),
+	}
+	ifNames := map[int]string{10101: "Gi1/0/1"}
+
+	neighbors := cdpNeighbors(cache, ifNames)
+	if len(neighbors) != 2 {
+		t.Fatalf("expected 2 neighbors, got %+v", neighbors)
+	}
+	if n := neighbors[0]; n.LocalPort != "Gi1/0/1" || n.Protocol != NeighborCDP || n.IP != "10.0.0.30" ||
+		n.Name != "ap-01" || n.RemotePort != "GigabitEthernet0" {
+		t.Errorf("unexpected ap-01 neighbor: %+v", n)
+	}
+	if n := neighbors[1]; n.LocalPort != "20" || n.Name != "phone-7" || n.IP != "" {
+		t.Errorf("unexpected phone-7 neighbor: %+v", n)
+	}
+}
+
+func TestOIDIndex(t *testing.T) {
+	idx, ok := oidIndex(".1.3.6.1.2.1.31.1.1.1.1.7", oidIfName)
+	if !ok || len(idx) != 1 || idx[0] != 7 {
+		t.Errorf("expected index [7], got %v %v", idx, ok)
+	}
+	if _, ok := oidIndex(".1.3.6.1.2.1.31.1.1.1.10.7", oidIfName); ok {
+		t.Error("expected a different column not to match")
+	}
+}
@@ -2,9 +2,11 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -13,18 +15,27 @@ import (
 	"github.com/martinsuchenak/rackd/internal/credentials"
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/martinsuchenak/rackd/internal/webhook"
 )
 
 type UnifiedScanner struct {
 	storage         storage.DiscoveryStorage
 	netStorage      storage.NetworkStorage
+	deviceStorage   storage.DeviceStorage
 	credStore       credentials.Storage
 	scans           map[string]*model.DiscoveryScan
 	cancelFuncs     map[string]context.CancelFunc
+	progress        *progressWatchers
+	ctx             context.Context // parent of every scan, cancelled by Shutdown
+	shutdown        context.CancelCauseFunc
+	wg              sync.WaitGroup // running scans
 	arpScanner      *ARPScanner
 	snmpScanner     *SNMPScanner
 	sshScanner      *SSHScanner
 	bannerGrabber   *BannerGrabber
+	httpPrinter     *HTTPFingerprinter
+	sshPrinter      *SSHFingerprinter
+	hostKeys        storage.SSHHostKeyStorage
 	osFingerprinter *OSFingerprinter
 	ouiDatabase     *OUIDatabase
 	netbiosScanner  *NetBIOSScanner
@@ -41,16 +52,24 @@ func NewUnifiedScanner(store storage.ExtendedStorage, credStore credentials.Stor
 	// Load ARP table asynchronously to avoid blocking server startup
 	go arpScanner.LoadARPTable()
 
+	ctx, shutdown := context.WithCancelCause(context.Background())
 	return &UnifiedScanner{
 		storage:         store,
 		netStorage:      store,
+		deviceStorage:   store,
 		credStore:       credStore,
 		scans:           make(map[string]*model.DiscoveryScan),
 		cancelFuncs:     make(map[string]context.CancelFunc),
+		progress:        newProgressWatchers(),
+		ctx:             ctx,
+		shutdown:        shutdown,
 		arpScanner:      arpScanner,
 		snmpScanner:     NewSNMPScanner(credStore, timeout, snmpV2cEnabled),
 		sshScanner:      NewSSHScannerWithHostKeys(credStore, timeout, NewDBHostKeyStore(store)),
 		bannerGrabber:   NewBannerGrabber(2 * time.Second),
+		httpPrinter:     NewHTTPFingerprinter(5 * time.Second),
+		sshPrinter:      NewSSHFingerprinter(5 * time.Second),
+		hostKeys:        store,
 		osFingerprinter: NewOSFingerprinter(2 * time.Second),
 		ouiDatabase:     NewOUIDatabase(),
 		netbiosScanner:  NewNetBIOSScanner(5 * time.Second),
@@ -111,20 +130,29 @@ func (s *UnifiedScanner) ScanWithOptions(ctx context.Context, network *model.Net
 		TotalHosts: countHosts(ipNet),
 	}
 
+	if err := s.ctx.Err(); err != nil {
+		return nil, ErrScannerShutdown
+	}
 	if err := s.storage.CreateDiscoveryScan(ctx, scan); err != nil {
 		return nil, err
 	}
 
 	// Use a detached context for the background scan goroutine so it outlives
-	// the HTTP request. Cancellation is handled explicitly via CancelScan().
-	ctxCancellable, cancel := context.WithCancel(context.Background())
+	// the HTTP request. Cancellation is handled explicitly via CancelScan()
+	// and Shutdown().
+	ctxCancellable, cancel := context.WithCancel(s.ctx)
 
 	s.mu.Lock()
 	s.scans[scan.ID] = scan
 	s.cancelFuncs[scan.ID] = cancel
 	s.mu.Unlock()
 
-	go s.runScanWithOptions(ctxCancellable, scan, network, ipNet, opts)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		s.runScanWithOptions(ctxCancellable, scan, network, ipNet, opts)
+	}()
 
 	return scan, nil
 }
@@ -139,6 +167,13 @@ func (s *UnifiedScanner) GetScanStatus(ctx context.Context, scanID string) (*mod
 	return s.storage.GetDiscoveryScan(ctx, scanID)
 }
 
+// WatchScan streams the progress of a scan started by this scanner. Scans
+// that already finished, or were started before a restart, send nothing and
+// their channel stays open until the watch ends.
+func (s *UnifiedScanner) WatchScan(scanID string) (<-chan model.DiscoveryScan, func()) {
+	return s.progress.watch(scanID)
+}
+
 func (s *UnifiedScanner) CancelScan(ctx context.Context, scanID string) error {
 	s.mu.Lock()
 	scan, ok := s.scans[scanID]
@@ -164,8 +199,10 @@ func (s *UnifiedScanner) CancelScan(ctx context.Context, scanID string) error {
 	scan.ErrorMessage = "scan cancelled"
 	now := time.Now()
 	scan.CompletedAt = &now
+	scanCopy := *scan
 
 	s.mu.Unlock()
+	s.progress.publish(scanCopy)
 
 	// Cancel the context to stop running goroutines
 	cancel()
@@ -183,11 +220,35 @@ func (s *UnifiedScanner) CancelScan(ctx context.Context, scanID string) error {
 	return nil
 }
 
+// Shutdown stops all running scans and waits for them to record how far they
+// got, or for ctx to be done. Scans cannot be started once it is called.
+func (s *UnifiedScanner) Shutdown(ctx context.Context) error {
+	s.shutdown(ErrScannerShutdown)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // networkScanResults holds results from per-network broadcast scans run once before the per-host loop.
 type networkScanResults struct {
 	netbios map[string][]NetBIOSResult // keyed by IP
 	mdns    map[string][]mDNSResult    // keyed by IP
 	lldp    map[string]*LLDPResult     // keyed by mgmt IP
+
+	inventory map[string]*model.Device // inventory devices on the network keyed by IP
+
+	// Set when the network's discovery rule enables DNS resolution
+	dns        *DNSResolver
+	dnsDomains map[string][]string // inventory device domains keyed by IP
 }
 
 func (s *UnifiedScanner) runNetworkScans(ctx context.Context, subnet string, scanType string) *networkScanResults {
@@ -246,13 +307,21 @@ func (s *UnifiedScanner) runScanWithOptions(ctx context.Context, scan *model.Dis
 	if err := s.storage.UpdateDiscoveryScan(ctx, scan); err != nil {
 		log.Printf("discovery: failed to update scan status: %v", err)
 	}
+	s.progress.publish(*scan)
+
+	webhook.Publish(model.EventTypeDiscoveryStarted, &model.EventPayloadDiscovery{
+		ScanID:      scan.ID,
+		NetworkID:   network.ID,
+		NetworkName: network.Name,
+		ScanType:    scan.ScanType,
+	})
+	changes := s.trackChanges(ctx, scan, opts)
 
 	ips := expandCIDR(ipNet)
 	scan.TotalHosts = len(ips)
 
 	params := s.adaptiveScanner.CalculateParameters(network.Subnet, opts.ScanType)
-	semaphore := make(chan struct{}, params.Workers)
-	var wg sync.WaitGroup
+	workers := s.scanWorkers(ctx, network.ID, params.Workers)
 	var foundCount int
 	var scanMu sync.Mutex
 
@@ -261,70 +330,176 @@ func (s *UnifiedScanner) runScanWithOptions(ctx context.Context, scan *model.Dis
 
 	// Run per-network broadcast scans once (NetBIOS, mDNS, LLDP)
 	netResults := s.runNetworkScans(ctx, network.Subnet, opts.ScanType)
+	s.loadInventory(ctx, network, netResults)
+	s.prepareDNSResolution(ctx, network, netResults)
 
-	for i, ip := range ips {
-		select {
-		case <-ctx.Done():
-			scan.Status = model.ScanStatusFailed
-			scan.ErrorMessage = "scan cancelled"
-			if err := s.storage.UpdateDiscoveryScan(ctx, scan); err != nil {
-				log.Printf("discovery: failed to update cancelled scan: %v", err)
-			}
-			return
-		default:
-		}
-
+	// A fixed pool of workers probes the hosts; feeding stops as soon as the
+	// scan is cancelled
+	hosts := make(chan string)
+	var wg sync.WaitGroup
+	for range workers {
 		wg.Add(1)
-		semaphore <- struct{}{}
-
-		go func(ip string, index int) {
+		go func() {
 			defer wg.Done()
-			defer func() { <-semaphore }()
-
-			device := s.discoverHostWithOptions(ctx, ip, network.ID, opts, params.Timeout, netResults)
-			if device != nil {
-				existing, _ := s.storage.GetDiscoveredDeviceByIP(ctx, network.ID, ip)
-				if existing != nil {
-					device.ID = existing.ID
-					device.FirstSeen = existing.FirstSeen
-					if err := s.storage.UpdateDiscoveredDevice(ctx, device); err != nil {
-						log.Printf("discovery: failed to update device %s: %v", ip, err)
-					}
-				} else {
-					if err := s.storage.CreateDiscoveredDevice(ctx, device); err != nil {
-						log.Printf("discovery: failed to create device %s: %v", ip, err)
-					}
-				}
+			for ip := range hosts {
+				found := s.scanHost(ctx, scan.ID, ip, network.ID, opts, params.Timeout, netResults, changes)
 
 				scanMu.Lock()
-				foundCount++
+				if found {
+					foundCount++
+				}
+				scan.ScannedHosts++
+				scan.FoundHosts = foundCount
+				scan.ProgressPercent = float64(scan.ScannedHosts) / float64(scan.TotalHosts) * 100
+				// Copy scan state under lock to avoid race with concurrent reads
+				scanCopy := *scan
 				scanMu.Unlock()
-			}
 
-			scanMu.Lock()
-			scan.ScannedHosts = index + 1
-			scan.FoundHosts = foundCount
-			scan.ProgressPercent = float64(scan.ScannedHosts) / float64(scan.TotalHosts) * 100
-			// Copy scan state under lock to avoid race with concurrent reads
-			scanCopy := *scan
-			scanMu.Unlock()
+				_ = s.storage.UpdateDiscoveryScan(ctx, &scanCopy)
+				s.progress.publish(scanCopy)
+			}
+		}()
+	}
 
-			_ = s.storage.UpdateDiscoveryScan(ctx, &scanCopy)
-		}(ip, i)
+feed:
+	for _, ip := range ips {
+		select {
+		case hosts <- ip:
+		case <-ctx.Done():
+			break feed
+		}
 	}
+	close(hosts)
 
+	// Hosts already being probed finish (quickly, once ctx is cancelled) so
+	// the progress recorded below covers every host the scan got through
 	wg.Wait()
 
+	if ctx.Err() != nil {
+		s.stopScan(ctx, scan)
+		return
+	}
+
 	completedAt := time.Now()
 	scan.Status = model.ScanStatusCompleted
 	scan.CompletedAt = &completedAt
 	if err := s.storage.UpdateDiscoveryScan(ctx, scan); err != nil {
 		log.Printf("discovery: failed to update completed scan: %v", err)
 	}
+	s.progress.publish(*scan)
+
+	completed := &model.EventPayloadDiscovery{
+		ScanID:       scan.ID,
+		NetworkID:    network.ID,
+		NetworkName:  network.Name,
+		ScanType:     scan.ScanType,
+		DevicesFound: foundCount,
+		Duration:     completedAt.Sub(now).Milliseconds(),
+	}
+	changes.summarise(completed)
+	webhook.Publish(model.EventTypeDiscoveryCompleted, completed)
 
 	s.cleanupCompletedScans()
 }
 
+// scanHost probes one host and saves what it finds, reporting whether the
+// host is up
+func (s *UnifiedScanner) scanHost(ctx context.Context, scanID, ip, networkID string, opts *ScanOptions, timeout time.Duration, netResults *networkScanResults, changes *scanChanges) bool {
+	device := s.discoverHostWithOptions(ctx, ip, networkID, opts, timeout, netResults)
+	if device == nil {
+		return false
+	}
+	if slices.Contains(device.OpenPorts, 22) && ctx.Err() == nil {
+		s.recordSSHHostKey(ctx, ip, netResults)
+	}
+
+	existing, _ := s.storage.GetDiscoveredDeviceByIP(ctx, networkID, ip)
+	if existing != nil {
+		device.ID = existing.ID
+		device.FirstSeen = existing.FirstSeen
+		device.ScanCount = existing.ScanCount + 1
+		ScoreSightings(device)
+		if err := s.storage.UpdateDiscoveredDevice(ctx, device); err != nil {
+			log.Printf("discovery: failed to update device %s: %v", ip, err)
+		}
+	} else {
+		device.ScanCount = 1
+		ScoreSightings(device)
+		if err := s.storage.CreateDiscoveredDevice(ctx, device); err != nil {
+			log.Printf("discovery: failed to create device %s: %v", ip, err)
+		}
+	}
+	changes.record(existing, device)
+
+	// A cancelled scan may have stopped probing part way, so its missing
+	// ports do not count as closed
+	probed := opts.getPorts()
+	if ctx.Err() != nil {
+		probed = nil
+	}
+	if err := s.storage.RecordPortObservations(ctx, scanID, device, probed); err != nil {
+		log.Printf("discovery: failed to record ports of %s: %v", ip, err)
+	}
+	return true
+}
+
+// scanWorkers returns how many hosts a scan of the network probes at once:
+// the adaptive default, capped by the network's discovery rule
+func (s *UnifiedScanner) scanWorkers(ctx context.Context, networkID string, adaptive int) int {
+	workers := max(adaptive, 1)
+	rule, err := s.storage.GetDiscoveryRuleByNetwork(ctx, networkID)
+	if err == nil && rule.MaxConcurrentScans > 0 && rule.MaxConcurrentScans < workers {
+		workers = rule.MaxConcurrentScans
+	}
+	return workers
+}
+
+// trackChanges snapshots the discovered devices of the scan's network, so the
+// completed event can report what changed since the previous completed scan
+func (s *UnifiedScanner) trackChanges(ctx context.Context, scan *model.DiscoveryScan, opts *ScanOptions) *scanChanges {
+	known, err := s.storage.ListDiscoveredDevices(ctx, scan.NetworkID)
+	if err != nil {
+		log.Printf("discovery: failed to list discovered devices for scan %s: %v", scan.ID, err)
+	}
+
+	var previousScan *time.Time
+	scans, err := s.storage.ListDiscoveryScans(ctx, scan.NetworkID)
+	if err != nil {
+		log.Printf("discovery: failed to list previous scans for scan %s: %v", scan.ID, err)
+	}
+	for _, prev := range scans {
+		if prev.ID != scan.ID && prev.Status == model.ScanStatusCompleted && prev.StartedAt != nil {
+			previousScan = prev.StartedAt
+			break
+		}
+	}
+	return newScanChanges(known, previousScan, opts.getPorts())
+}
+
+// stopScan records the progress of a scan whose context was cancelled, either
+// by CancelScan or by Shutdown
+func (s *UnifiedScanner) stopScan(ctx context.Context, scan *model.DiscoveryScan) {
+	s.mu.Lock()
+	scan.Status = model.ScanStatusFailed
+	if errors.Is(context.Cause(ctx), ErrScannerShutdown) {
+		scan.ErrorMessage = ErrScannerShutdown.Error()
+	} else {
+		scan.ErrorMessage = "scan cancelled"
+	}
+	if scan.CompletedAt == nil {
+		now := time.Now()
+		scan.CompletedAt = &now
+	}
+	scanCopy := *scan
+	s.mu.Unlock()
+
+	// ctx is cancelled, but the checkpoint must still be written
+	if err := s.storage.UpdateDiscoveryScan(context.WithoutCancel(ctx), &scanCopy); err != nil {
+		log.Printf("discovery: failed to record progress of stopped scan %s: %v", scan.ID, err)
+	}
+	s.progress.publish(scanCopy)
+}
+
 func (s *UnifiedScanner) discoverHostWithOptions(ctx context.Context, ip string, networkID string, opts *ScanOptions, timeout time.Duration, netResults *networkScanResults) *model.DiscoveredDevice {
 	// Check context at the very start
 	select {
@@ -334,7 +509,7 @@ func (s *UnifiedScanner) discoverHostWithOptions(ctx context.Context, ip string,
 	}
 
 	// Combined alive check + port scan: scan ports once and reuse results
-	ports := s.scanPorts(ip, opts.getPorts(), timeout)
+	ports := s.scanPorts(ctx, ip, opts.getPorts(), timeout)
 	if len(ports) == 0 {
 		return nil
 	}
@@ -357,6 +532,7 @@ func (s *UnifiedScanner) discoverHostWithOptions(ctx context.Context, ip string,
 	}
 
 	scorer := NewConfidenceScorer()
+	evidence := ConfidenceEvidence{Responded: true}
 
 	// Check context before DNS lookup
 	select {
@@ -364,10 +540,21 @@ func (s *UnifiedScanner) discoverHostWithOptions(ctx context.Context, ip string,
 		return nil
 	default:
 	}
-	names, err := net.LookupAddr(ip)
-	if err == nil && len(names) > 0 {
-		hostname := strings.TrimSuffix(names[0], ".")
-		scorer.Add(hostname, "dns", GetHostnameSourceConfidence("dns"))
+	if netResults != nil && netResults.dns != nil {
+		result := netResults.dns.Resolve(ctx, ip, netResults.dnsDomains[ip])
+		device.DNSNames = result.Names
+		device.DNSMismatches = result.Mismatches
+		if len(result.Names) > 0 {
+			scorer.Add(result.Names[0], "dns", GetHostnameSourceConfidence("dns"))
+			evidence.ReverseDNS = result.Names[0]
+		}
+	} else {
+		names, err := net.LookupAddr(ip)
+		if err == nil && len(names) > 0 {
+			hostname := strings.TrimSuffix(names[0], ".")
+			scorer.Add(hostname, "dns", GetHostnameSourceConfidence("dns"))
+			evidence.ReverseDNS = hostname
+		}
 	}
 
 	if opts.SSHCredID != "" {
@@ -395,6 +582,7 @@ func (s *UnifiedScanner) discoverHostWithOptions(ctx context.Context, ip string,
 		default:
 		}
 		if snmpResult, err := s.snmpScanner.Scan(ctx, ip, opts.SNMPCredID); err == nil {
+			evidence.SNMP = true
 			if snmpResult.SysName != "" {
 				scorer.Add(snmpResult.SysName, "snmp", GetHostnameSourceConfidence("snmp"))
 			}
@@ -468,11 +656,16 @@ func (s *UnifiedScanner) discoverHostWithOptions(ctx context.Context, ip string,
 		conflict := s.correlator.Correlate(allSources)
 		if conflict != nil && conflict.Recommended != "" {
 			device.Hostname = conflict.Recommended
-			_, confidence := scorer.GetBest()
-			device.Confidence = confidence
 		}
 	}
 
+	// Vendor lookup from MAC address
+	if device.MACAddress != "" && device.Vendor == "" {
+		device.Vendor = s.ouiDatabase.Lookup(device.MACAddress)
+	}
+	// The score is final here: banners and the OS do not count towards it
+	ScoreConfidence(device, evidence)
+
 	// Check context before banner grabbing
 	select {
 	case <-ctx.Done():
@@ -489,6 +682,15 @@ func (s *UnifiedScanner) discoverHostWithOptions(ctx context.Context, ip string,
 			Version:  banner.Version,
 		})
 	}
+	for _, port := range ports {
+		if !slices.Contains(HTTPPorts, port) || ctx.Err() != nil {
+			continue
+		}
+		if fp := s.httpPrinter.Fingerprint(ctx, ip, port); fp != nil {
+			device.Services = ApplyHTTPFingerprint(device.Services, fp)
+		}
+	}
+	device.Services = ParseServices(device.Services)
 
 	// OS fingerprinting (for deep scans only, when no OS detected yet)
 	if opts.ScanType == model.ScanTypeDeep && device.OSGuess == "" {
@@ -500,17 +702,9 @@ func (s *UnifiedScanner) discoverHostWithOptions(ctx context.Context, ip string,
 		fp := s.osFingerprinter.Fingerprint(ip)
 		if fp.OSFamily != OSTypeUnknown {
 			device.OSGuess = GetOSTypeFromFamily(fp.OSFamily)
-			if fp.Confidence > device.Confidence {
-				device.Confidence = fp.Confidence
-			}
 		}
 	}
 
-	// Vendor lookup from MAC address
-	if device.MACAddress != "" && device.Vendor == "" {
-		device.Vendor = s.ouiDatabase.Lookup(device.MACAddress)
-	}
-
 	// Device type classification
 	deviceInfo := &DeviceInfo{
 		OS:     device.OSGuess,
@@ -533,13 +727,112 @@ func (s *UnifiedScanner) discoverHostWithOptions(ctx context.Context, ip string,
 	return device
 }
 
-func (s *UnifiedScanner) scanPorts(ip string, ports []int, timeout time.Duration) []int {
+// loadInventory collects the inventory devices with an address on the
+// network, for SSH host key alerts. An IP shared by several devices maps to
+// the first one listed.
+func (s *UnifiedScanner) loadInventory(ctx context.Context, network *model.Network, netResults *networkScanResults) {
+	netResults.inventory = make(map[string]*model.Device)
+
+	devices, err := s.deviceStorage.ListDevices(ctx, &model.DeviceFilter{
+		NetworkID:  network.ID,
+		Pagination: model.Pagination{Limit: model.MaxPageSize},
+	})
+	if err != nil {
+		log.Printf("discovery: failed to list devices on network %s: %v", network.ID, err)
+		return
+	}
+	for i := range devices {
+		for _, addr := range devices[i].Addresses {
+			if _, ok := netResults.inventory[addr.IP]; !ok {
+				netResults.inventory[addr.IP] = &devices[i]
+			}
+		}
+	}
+}
+
+// prepareDNSResolution enables the DNS resolver step when the network's
+// discovery rule asks for it, and collects the domains of inventory devices
+// on the network for forward verification
+func (s *UnifiedScanner) prepareDNSResolution(ctx context.Context, network *model.Network, netResults *networkScanResults) {
+	rule, err := s.storage.GetDiscoveryRuleByNetwork(ctx, network.ID)
+	if err != nil || !rule.DNSResolution {
+		return
+	}
+
+	resolver, err := NewDNSResolver(rule.DNSServer, 5*time.Second)
+	if err != nil {
+		log.Printf("discovery: invalid DNS server for network %s: %v", network.ID, err)
+		return
+	}
+	netResults.dns = resolver
+	netResults.dnsDomains = make(map[string][]string)
+
+	devices, err := s.deviceStorage.ListDevices(ctx, &model.DeviceFilter{
+		NetworkID:  network.ID,
+		Pagination: model.Pagination{Limit: model.MaxPageSize},
+	})
+	if err != nil {
+		log.Printf("discovery: failed to list devices for DNS verification: %v", err)
+		return
+	}
+	for _, d := range devices {
+		if len(d.Domains) == 0 {
+			continue
+		}
+		for _, addr := range d.Addresses {
+			netResults.dnsDomains[addr.IP] = append(netResults.dnsDomains[addr.IP], d.Domains...)
+		}
+	}
+}
+
+// recordSSHHostKey collects the host key of the SSH server on port 22 of ip.
+// A key that differs from the one collected before for its type is logged,
+// and published when ip belongs to an inventory device.
+func (s *UnifiedScanner) recordSSHHostKey(ctx context.Context, ip string, netResults *networkScanResults) {
+	key := s.sshPrinter.Fingerprint(ctx, net.JoinHostPort(ip, "22"))
+	if key == nil {
+		return
+	}
+	key.IP = ip
+	previous, err := s.hostKeys.RecordSSHHostFingerprint(ctx, key)
+	if err != nil {
+		log.Printf("discovery: failed to record SSH host key of %s: %v", ip, err)
+		return
+	}
+	if previous == nil || previous.Fingerprint == key.Fingerprint {
+		return
+	}
+
+	var device *model.Device
+	if netResults != nil {
+		device = netResults.inventory[ip]
+	}
+	if device == nil {
+		log.Printf("discovery: %s host key of %s changed from %s to %s", key.KeyType, ip, previous.Fingerprint, key.Fingerprint)
+		return
+	}
+	log.Printf("discovery: %s host key of device %s at %s changed from %s to %s", key.KeyType, device.Name, ip, previous.Fingerprint, key.Fingerprint)
+	webhook.Publish(model.EventTypeSSHHostKeyChanged, &model.EventPayloadSSHHostKey{
+		DeviceID:            device.ID,
+		DeviceName:          device.Name,
+		IP:                  ip,
+		KeyType:             key.KeyType,
+		Fingerprint:         key.Fingerprint,
+		PreviousFingerprint: previous.Fingerprint,
+	})
+}
+
+func (s *UnifiedScanner) scanPorts(ctx context.Context, ip string, ports []int, timeout time.Duration) []int {
 	if len(ports) == 0 {
 		ports = []int{22, 80, 443, 3389}
 	}
+	dialer := net.Dialer{Timeout: timeout}
 	var open []int
 	for _, port := range ports {
-		conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", ip, port), timeout)
+		if ctx.Err() != nil {
+			return nil
+		}
+		conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", ip, port))
 		if err == nil {
 			conn.Close()
 			open = append(open, port)
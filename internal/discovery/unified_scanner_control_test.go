@@ -113,7 +113,7 @@ func TestUnifiedScannerQuickNetworkScansAndHelpers(t *testing.T) {
 	defer listener.Close()
 
 	port := listener.Addr().(*net.TCPAddr).Port
-	open := scanner.scanPorts("127.0.0.1", []int{port}, 200*time.Millisecond)
+	open := scanner.scanPorts(context.Background(), "127.0.0.1", []int{port}, 200*time.Millisecond)
 	if len(open) != 1 || open[0] != port {
 		t.Fatalf("expected open port %d, got %v", port, open)
 	}
@@ -235,3 +235,151 @@ func TestUnifiedScannerRunScanWithOptionsCompletesAndPersists(t *testing.T) {
 		t.Fatalf("expected one persisted discovered device, got %+v", devices)
 	}
 }
+
+func TestUnifiedScannerShutdownRecordsProgress(t *testing.T) {
+	scanner, store := newTestUnifiedScanner(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	// TEST-NET-1 addresses don't answer, so the scan is still running
+	network := &model.Network{ID: "net-shutdown", Name: "Shutdown", Subnet: "192.0.2.0/24"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	scan, err := scanner.Scan(ctx, network, model.ScanTypeQuick)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	if err := scanner.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	got, err := store.GetDiscoveryScan(ctx, scan.ID)
+	if err != nil {
+		t.Fatalf("GetDiscoveryScan failed: %v", err)
+	}
+	if got.Status != model.ScanStatusFailed || got.ErrorMessage != ErrScannerShutdown.Error() || got.CompletedAt == nil {
+		t.Fatalf("unexpected scan after shutdown: %+v", got)
+	}
+	if got.ScannedHosts >= got.TotalHosts {
+		t.Fatalf("expected a partial scan, got %d of %d hosts", got.ScannedHosts, got.TotalHosts)
+	}
+
+	if _, err := scanner.Scan(ctx, network, model.ScanTypeQuick); err != ErrScannerShutdown {
+		t.Fatalf("expected ErrScannerShutdown after shutdown, got %v", err)
+	}
+}
+
+func TestProgressWatchersKeepLatestAndCloseWhenFinished(t *testing.T) {
+	p := newProgressWatchers()
+	updates, stop := p.watch("scan-1")
+	defer stop()
+	other, stopOther := p.watch("scan-2")
+
+	for i := 1; i <= progressBuffer+5; i++ {
+		p.publish(model.DiscoveryScan{ID: "scan-1", Status: model.ScanStatusRunning, ScannedHosts: i})
+	}
+	p.publish(model.DiscoveryScan{ID: "scan-1", Status: model.ScanStatusCompleted, ScannedHosts: progressBuffer + 5})
+
+	var last model.DiscoveryScan
+	count := 0
+	for scan := range updates {
+		last = scan
+		count++
+	}
+	if count != progressBuffer || last.Status != model.ScanStatusCompleted {
+		t.Fatalf("expected %d updates ending with completion, got %d ending with %+v", progressBuffer, count, last)
+	}
+
+	select {
+	case scan := <-other:
+		t.Fatalf("unexpected update for another scan: %+v", scan)
+	default:
+	}
+	stopOther()
+	if _, ok := <-other; ok {
+		t.Fatal("expected stopped watch to be closed")
+	}
+}
+
+func TestUnifiedScannerWatchScanStreamsProgress(t *testing.T) {
+	scanner, store := newTestUnifiedScanner(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	network := &model.Network{ID: "net-watch", Name: "Loopback", Subnet: "127.0.0.0/30"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	scan := &model.DiscoveryScan{ID: "scan-watch", NetworkID: network.ID, Status: model.ScanStatusPending, ScanType: model.ScanTypeQuick}
+	if err := store.CreateDiscoveryScan(ctx, scan); err != nil {
+		t.Fatalf("CreateDiscoveryScan failed: %v", err)
+	}
+	_, ipNet, _ := net.ParseCIDR(network.Subnet)
+
+	updates, stop := scanner.WatchScan(scan.ID)
+	defer stop()
+	scanner.runScanWithOptions(ctx, scan, network, ipNet, &ScanOptions{
+		NetworkID: network.ID,
+		ScanType:  model.ScanTypeQuick,
+		Profile:   &model.ScanProfile{Ports: []int{1}},
+	})
+
+	var got []model.DiscoveryScan
+	for update := range updates {
+		got = append(got, update)
+	}
+	if len(got) < 2 || got[0].Status != model.ScanStatusRunning {
+		t.Fatalf("expected running then progress updates, got %+v", got)
+	}
+	last := got[len(got)-1]
+	if last.Status != model.ScanStatusCompleted || last.ScannedHosts != last.TotalHosts {
+		t.Fatalf("expected final update for the completed scan, got %+v", last)
+	}
+}
+
+func TestUnifiedScannerScanWorkersUsesRuleCap(t *testing.T) {
+	scanner, store := newTestUnifiedScanner(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	network := &model.Network{ID: "net-workers", Name: "Workers", Subnet: "10.0.0.0/24"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	if got := scanner.scanWorkers(ctx, network.ID, 50); got != 50 {
+		t.Fatalf("expected adaptive workers without a rule, got %d", got)
+	}
+
+	rule := &model.DiscoveryRule{NetworkID: network.ID, ScanType: model.ScanTypeQuick, IntervalHours: 24, MaxConcurrentScans: 4}
+	if err := store.SaveDiscoveryRule(ctx, rule); err != nil {
+		t.Fatalf("SaveDiscoveryRule failed: %v", err)
+	}
+	if got := scanner.scanWorkers(ctx, network.ID, 50); got != 4 {
+		t.Fatalf("expected rule to cap workers at 4, got %d", got)
+	}
+	if got := scanner.scanWorkers(ctx, network.ID, 2); got != 2 {
+		t.Fatalf("expected rule cap not to raise workers, got %d", got)
+	}
+}
+
+func TestUnifiedScannerScanPortsHonorsCancelledContext(t *testing.T) {
+	scanner, store := newTestUnifiedScanner(t)
+	defer store.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	port := listener.Addr().(*net.TCPAddr).Port
+	if open := scanner.scanPorts(ctx, "127.0.0.1", []int{port}, time.Second); len(open) != 0 {
+		t.Fatalf("expected no ports probed after cancellation, got %v", open)
+	}
+}
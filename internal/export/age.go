@@ -0,0 +1,36 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// ParseAgeRecipient parses an age X25519 recipient ("age1..."). Values
+// encrypted to it can be decrypted with the standard age tool and the
+// matching identity.
+func ParseAgeRecipient(s string) (age.Recipient, error) {
+	r, err := age.ParseX25519Recipient(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid age recipient: %w", err)
+	}
+	return r, nil
+}
+
+// ageEncrypt encrypts plaintext into a binary age file for recipient
+func ageEncrypt(recipient age.Recipient, plaintext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,143 @@
+package export
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// AnsibleOptions controls how devices are mapped to Ansible hosts
+type AnsibleOptions struct {
+	// AddressLabel selects the address used as ansible_host. Devices without
+	// an address carrying the label fall back to their first address, then
+	// their hostname.
+	AddressLabel string
+}
+
+// AnsibleInventory builds an Ansible dynamic inventory (the JSON expected
+// from an inventory script called with --list). Devices are grouped by tag
+// (tag_<tag>), datacenter (datacenter_<name>) and OS (os_<os>); devices in
+// none of these land in ungrouped. Decommissioned devices are left out.
+func AnsibleInventory(devices []model.Device, datacenters []model.Datacenter, opts AnsibleOptions) map[string]any {
+	dcNames := make(map[string]string, len(datacenters))
+	for _, dc := range datacenters {
+		dcNames[dc.ID] = dc.Name
+	}
+
+	groups := make(map[string][]string)
+	hostvars := make(map[string]map[string]any)
+	for _, d := range devices {
+		if d.Status == model.DeviceStatusDecommissioned {
+			continue
+		}
+
+		host := d.Name
+		if _, taken := hostvars[host]; taken || host == "" {
+			host = d.Name + "_" + d.ID
+		}
+
+		vars := map[string]any{"rackd_id": d.ID}
+		if addr := ansibleHost(&d, opts.AddressLabel); addr != "" {
+			vars["ansible_host"] = addr
+		}
+		if d.Username != "" {
+			vars["ansible_user"] = d.Username
+		}
+		if d.Status != "" {
+			vars["rackd_status"] = string(d.Status)
+		}
+		if d.MakeModel != "" {
+			vars["rackd_make_model"] = d.MakeModel
+		}
+		if d.OS != "" {
+			vars["rackd_os"] = d.OS
+		}
+		if d.Location != "" {
+			vars["rackd_location"] = d.Location
+		}
+		if len(d.Tags) > 0 {
+			vars["rackd_tags"] = d.Tags
+		}
+		hostvars[host] = vars
+
+		var memberOf []string
+		for _, tag := range d.Tags {
+			memberOf = append(memberOf, ansibleGroupName("tag", tag))
+		}
+		if d.DatacenterID != "" {
+			name := dcNames[d.DatacenterID]
+			if name == "" {
+				name = d.DatacenterID
+			}
+			vars["rackd_datacenter"] = name
+			memberOf = append(memberOf, ansibleGroupName("datacenter", name))
+		}
+		if d.OS != "" {
+			memberOf = append(memberOf, ansibleGroupName("os", d.OS))
+		}
+
+		grouped := false
+		for _, g := range memberOf {
+			if g == "" || slices.Contains(groups[g], host) {
+				continue
+			}
+			groups[g] = append(groups[g], host)
+			grouped = true
+		}
+		if !grouped {
+			groups["ungrouped"] = append(groups["ungrouped"], host)
+		}
+	}
+
+	inventory := map[string]any{"_meta": map[string]any{"hostvars": hostvars}}
+	children := make([]string, 0, len(groups)+1)
+	for name, hosts := range groups {
+		sort.Strings(hosts)
+		inventory[name] = map[string]any{"hosts": hosts}
+		if name != "ungrouped" {
+			children = append(children, name)
+		}
+	}
+	sort.Strings(children)
+	inventory["all"] = map[string]any{"children": append(children, "ungrouped")}
+	return inventory
+}
+
+func ansibleHost(d *model.Device, label string) string {
+	if label != "" {
+		for _, a := range d.Addresses {
+			if strings.EqualFold(a.Label, label) && a.IP != "" {
+				return a.IP
+			}
+		}
+	}
+	for _, a := range d.Addresses {
+		if a.IP != "" {
+			return a.IP
+		}
+	}
+	return d.Hostname
+}
+
+// ansibleGroupName builds a valid Ansible group name: lowercase letters,
+// digits and underscores, prefixed with the grouping it came from
+func ansibleGroupName(prefix, value string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(value) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "_")
+	if name == "" {
+		return ""
+	}
+	return prefix + "_" + name
+}
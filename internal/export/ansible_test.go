@@ -0,0 +1,91 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestAnsibleInventory(t *testing.T) {
+	devices := []model.Device{
+		{
+			ID: "dev-1", Name: "web-1", OS: "Ubuntu 24.04", Username: "deploy", DatacenterID: "dc-1",
+			Tags: []string{"web", "Production"},
+			Addresses: []model.Address{
+				{IP: "10.0.0.11", Label: "data"},
+				{IP: "192.168.0.11", Label: "MGMT"},
+			},
+		},
+		{ID: "dev-2", Name: "db-1", DatacenterID: "dc-1", Addresses: []model.Address{{IP: "10.0.0.21"}}},
+		{ID: "dev-3", Name: "printer", Hostname: "printer.local"},
+		{ID: "dev-4", Name: "old", Status: model.DeviceStatusDecommissioned, Tags: []string{"web"}},
+	}
+	datacenters := []model.Datacenter{{ID: "dc-1", Name: "FRA-1"}}
+
+	inv := AnsibleInventory(devices, datacenters, AnsibleOptions{AddressLabel: "mgmt"})
+
+	// Round-trip through JSON to compare the structure Ansible will see
+	data, err := json.Marshal(inv)
+	if err != nil {
+		t.Fatalf("failed to marshal inventory: %v", err)
+	}
+	var got map[string]struct {
+		Hosts    []string                  `json:"hosts"`
+		Children []string                  `json:"children"`
+		HostVars map[string]map[string]any `json:"hostvars"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to unmarshal inventory: %v", err)
+	}
+
+	groups := map[string][]string{
+		"tag_web":          {"web-1"},
+		"tag_production":   {"web-1"},
+		"datacenter_fra_1": {"db-1", "web-1"},
+		"os_ubuntu_24_04":  {"web-1"},
+		"ungrouped":        {"printer"},
+	}
+	for name, hosts := range groups {
+		if !reflect.DeepEqual(got[name].Hosts, hosts) {
+			t.Errorf("group %s: expected %v, got %v", name, hosts, got[name].Hosts)
+		}
+	}
+	wantChildren := []string{"datacenter_fra_1", "os_ubuntu_24_04", "tag_production", "tag_web", "ungrouped"}
+	if !reflect.DeepEqual(got["all"].Children, wantChildren) {
+		t.Errorf("expected all children %v, got %v", wantChildren, got["all"].Children)
+	}
+
+	hostvars := got["_meta"].HostVars
+	if _, ok := hostvars["old"]; ok {
+		t.Error("decommissioned devices should be excluded")
+	}
+	if hostvars["web-1"]["ansible_host"] != "192.168.0.11" || hostvars["web-1"]["ansible_user"] != "deploy" {
+		t.Errorf("unexpected web-1 vars: %v", hostvars["web-1"])
+	}
+	if hostvars["db-1"]["ansible_host"] != "10.0.0.21" {
+		t.Errorf("expected fallback to first address, got %v", hostvars["db-1"]["ansible_host"])
+	}
+	if hostvars["printer"]["ansible_host"] != "printer.local" {
+		t.Errorf("expected fallback to hostname, got %v", hostvars["printer"]["ansible_host"])
+	}
+	if _, ok := hostvars["db-1"]["ansible_user"]; ok {
+		t.Error("ansible_user should be omitted without a username")
+	}
+}
+
+func TestAnsibleGroupName(t *testing.T) {
+	tests := map[string]string{
+		"web":             "tag_web",
+		"Ubuntu 24.04":    "tag_ubuntu_24_04",
+		"--edge--":        "tag_edge",
+		"k8s/worker-pool": "tag_k8s_worker_pool",
+		"!!!":             "",
+	}
+	for in, want := range tests {
+		if got := ansibleGroupName("tag", in); got != want {
+			t.Errorf("ansibleGroupName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// DHCPFormat selects the DHCP server configuration syntax of a reservation export
+type DHCPFormat string
+
+const (
+	DHCPFormatDnsmasq DHCPFormat = "dnsmasq"
+	DHCPFormatKea     DHCPFormat = "kea"
+	DHCPFormatISC     DHCPFormat = "isc"
+)
+
+// ValidDHCPFormats lists the supported reservation export formats
+var ValidDHCPFormats = []DHCPFormat{DHCPFormatDnsmasq, DHCPFormatKea, DHCPFormatISC}
+
+// DHCPReservation is a static lease for one device address
+type DHCPReservation struct {
+	Hostname string
+	MAC      string
+	IP       string
+	DeviceID string
+}
+
+// DHCPReservations collects a reservation for every IPv4 device address that
+// has a valid MAC. When networkID is set only addresses in that network are
+// used. Decommissioned devices are left out. Hostnames are made unique, since
+// ISC dhcpd rejects duplicate host declarations.
+func DHCPReservations(devices []model.Device, networkID string) []DHCPReservation {
+	var reservations []DHCPReservation
+	used := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, d := range devices {
+		if d.Status == model.DeviceStatusDecommissioned {
+			continue
+		}
+		for _, a := range d.Addresses {
+			if networkID != "" && a.NetworkID != networkID {
+				continue
+			}
+			addr, err := netip.ParseAddr(a.IP)
+			if err != nil || !addr.Unmap().Is4() {
+				continue
+			}
+			hw, err := net.ParseMAC(a.MACAddress)
+			if err != nil || len(hw) != 6 {
+				continue
+			}
+			mac, ip := hw.String(), addr.Unmap().String()
+			if seen[mac+" "+ip] {
+				continue
+			}
+			seen[mac+" "+ip] = true
+
+			base := dhcpHostname(&d)
+			host := base
+			for n := 2; used[host]; n++ {
+				host = fmt.Sprintf("%s-%d", base, n)
+			}
+			used[host] = true
+			reservations = append(reservations, DHCPReservation{Hostname: host, MAC: mac, IP: ip, DeviceID: d.ID})
+		}
+	}
+	slices.SortFunc(reservations, func(a, b DHCPReservation) int {
+		return netip.MustParseAddr(a.IP).Compare(netip.MustParseAddr(b.IP))
+	})
+	return reservations
+}
+
+// RenderDHCPReservations writes reservations in the given DHCP server format:
+// dhcp-host lines for dnsmasq, host declarations for ISC dhcpd and a
+// "reservations" JSON list for Kea DHCPv4. With an IPv4 network the output
+// also hands out its gateway, DNS servers and MTU: as dhcp-option lines for
+// dnsmasq, a subnet declaration for ISC dhcpd, and for Kea the list becomes
+// a subnet4 entry with option-data.
+func RenderDHCPReservations(reservations []DHCPReservation, network *model.Network, format DHCPFormat) ([]byte, error) {
+	opts := dhcpSubnetOptions(network)
+
+	var buf bytes.Buffer
+	switch format {
+	case DHCPFormatDnsmasq:
+		buf.WriteString("# DHCP reservations generated by rackd\n")
+		if opts != nil {
+			fmt.Fprintf(&buf, "# Network %s (%s)%s\n", network.Name, opts.subnet, vlanComment(network))
+			if opts.router != "" {
+				fmt.Fprintf(&buf, "dhcp-option=option:router,%s\n", opts.router)
+			}
+			if len(opts.dnsServers) > 0 {
+				fmt.Fprintf(&buf, "dhcp-option=option:dns-server,%s\n", strings.Join(opts.dnsServers, ","))
+			}
+			if opts.mtu > 0 {
+				fmt.Fprintf(&buf, "dhcp-option=option:mtu,%d\n", opts.mtu)
+			}
+		}
+		for _, r := range reservations {
+			fmt.Fprintf(&buf, "dhcp-host=%s,%s,%s\n", r.MAC, r.IP, r.Hostname)
+		}
+	case DHCPFormatISC:
+		buf.WriteString("# DHCP reservations generated by rackd\n")
+		if opts != nil {
+			fmt.Fprintf(&buf, "# Network %s (%s)%s\n", network.Name, opts.subnet, vlanComment(network))
+			fmt.Fprintf(&buf, "subnet %s netmask %s {\n", opts.subnet.Addr(), net.IP(net.CIDRMask(opts.subnet.Bits(), 32)))
+			if opts.router != "" {
+				fmt.Fprintf(&buf, "  option routers %s;\n", opts.router)
+			}
+			if len(opts.dnsServers) > 0 {
+				fmt.Fprintf(&buf, "  option domain-name-servers %s;\n", strings.Join(opts.dnsServers, ", "))
+			}
+			if opts.mtu > 0 {
+				fmt.Fprintf(&buf, "  option interface-mtu %d;\n", opts.mtu)
+			}
+			buf.WriteString("}\n")
+		}
+		for _, r := range reservations {
+			fmt.Fprintf(&buf, "host %s {\n  hardware ethernet %s;\n  fixed-address %s;\n  option host-name \"%s\";\n}\n", r.Hostname, r.MAC, r.IP, r.Hostname)
+		}
+	case DHCPFormatKea:
+		type keaReservation struct {
+			HWAddress string `json:"hw-address"`
+			IPAddress string `json:"ip-address"`
+			Hostname  string `json:"hostname"`
+		}
+		type keaOption struct {
+			Name string `json:"name"`
+			Data string `json:"data"`
+		}
+		out := struct {
+			Subnet       string           `json:"subnet,omitempty"`
+			OptionData   []keaOption      `json:"option-data,omitempty"`
+			Reservations []keaReservation `json:"reservations"`
+		}{Reservations: make([]keaReservation, 0, len(reservations))}
+		if opts != nil {
+			out.Subnet = opts.subnet.String()
+			if opts.router != "" {
+				out.OptionData = append(out.OptionData, keaOption{Name: "routers", Data: opts.router})
+			}
+			if len(opts.dnsServers) > 0 {
+				out.OptionData = append(out.OptionData, keaOption{Name: "domain-name-servers", Data: strings.Join(opts.dnsServers, ", ")})
+			}
+			if opts.mtu > 0 {
+				out.OptionData = append(out.OptionData, keaOption{Name: "interface-mtu", Data: strconv.Itoa(opts.mtu)})
+			}
+		}
+		for _, r := range reservations {
+			out.Reservations = append(out.Reservations, keaReservation{HWAddress: r.MAC, IPAddress: r.IP, Hostname: r.Hostname})
+		}
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported DHCP format %q", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// dhcpOptions are the DHCPv4 settings of a network
+type dhcpOptions struct {
+	subnet     netip.Prefix
+	router     string
+	dnsServers []string
+	mtu        int
+}
+
+// dhcpSubnetOptions returns the DHCPv4 settings of an IPv4 network, or nil
+// for no network or an IPv6 one. IPv6 DNS servers are left out.
+func dhcpSubnetOptions(network *model.Network) *dhcpOptions {
+	if network == nil {
+		return nil
+	}
+	prefix, err := netip.ParsePrefix(network.Subnet)
+	if err != nil || !prefix.Addr().Is4() {
+		return nil
+	}
+	opts := &dhcpOptions{subnet: prefix.Masked(), mtu: network.MTU}
+	if gw, err := netip.ParseAddr(network.Gateway); err == nil && gw.Unmap().Is4() {
+		opts.router = gw.Unmap().String()
+	}
+	for _, server := range network.DNSServers {
+		if addr, err := netip.ParseAddr(server); err == nil && addr.Unmap().Is4() {
+			opts.dnsServers = append(opts.dnsServers, addr.Unmap().String())
+		}
+	}
+	return opts
+}
+
+func vlanComment(network *model.Network) string {
+	if network.VLANID > 0 {
+		return fmt.Sprintf(", VLAN %d", network.VLANID)
+	}
+	return ""
+}
+
+// dhcpHostname returns the short host name of a device as a DNS label:
+// lowercase letters, digits and hyphens
+func dhcpHostname(d *model.Device) string {
+	name := d.Hostname
+	if name == "" {
+		name = d.Name
+	}
+	name, _, _ = strings.Cut(name, ".")
+
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			hyphen = false
+		} else if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+	host := strings.TrimSuffix(b.String(), "-")
+	if len(host) > 63 {
+		host = strings.TrimSuffix(host[:63], "-")
+	}
+	if host == "" {
+		return "host"
+	}
+	return host
+}
@@ -0,0 +1,150 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestDHCPReservations(t *testing.T) {
+	devices := []model.Device{
+		{ID: "dev-1", Name: "Web 01", Hostname: "web01.example.com", Addresses: []model.Address{
+			{IP: "10.0.0.20", MACAddress: "AA-BB-CC-00-00-01", NetworkID: "net-1"},
+			{IP: "192.168.0.20", MACAddress: "aa:bb:cc:00:00:02", NetworkID: "net-2"},
+			{IP: "2001:db8::20", MACAddress: "aa:bb:cc:00:00:01"},
+		}},
+		{ID: "dev-2", Name: "web01", Addresses: []model.Address{{IP: "10.0.0.10", MACAddress: "aa:bb:cc:00:00:03", NetworkID: "net-1"}}},
+		{ID: "dev-3", Name: "nomac", Addresses: []model.Address{{IP: "10.0.0.30", NetworkID: "net-1"}}},
+		{ID: "dev-4", Name: "badmac", Addresses: []model.Address{{IP: "10.0.0.40", MACAddress: "not-a-mac"}}},
+		{ID: "dev-5", Name: "old", Status: model.DeviceStatusDecommissioned,
+			Addresses: []model.Address{{IP: "10.0.0.50", MACAddress: "aa:bb:cc:00:00:05"}}},
+	}
+
+	got := DHCPReservations(devices, "")
+	want := []DHCPReservation{
+		{Hostname: "web01-3", MAC: "aa:bb:cc:00:00:03", IP: "10.0.0.10", DeviceID: "dev-2"},
+		{Hostname: "web01", MAC: "aa:bb:cc:00:00:01", IP: "10.0.0.20", DeviceID: "dev-1"},
+		{Hostname: "web01-2", MAC: "aa:bb:cc:00:00:02", IP: "192.168.0.20", DeviceID: "dev-1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected reservations:\n got %+v\nwant %+v", got, want)
+	}
+
+	if got := DHCPReservations(devices, "net-2"); len(got) != 1 || got[0].IP != "192.168.0.20" {
+		t.Errorf("expected only the net-2 address, got %+v", got)
+	}
+}
+
+func TestRenderDHCPReservations(t *testing.T) {
+	reservations := []DHCPReservation{{Hostname: "web01", MAC: "aa:bb:cc:00:00:01", IP: "10.0.0.20"}}
+
+	out, err := RenderDHCPReservations(reservations, nil, DHCPFormatDnsmasq)
+	if err != nil {
+		t.Fatalf("dnsmasq render failed: %v", err)
+	}
+	if !strings.Contains(string(out), "dhcp-host=aa:bb:cc:00:00:01,10.0.0.20,web01\n") {
+		t.Errorf("unexpected dnsmasq output:\n%s", out)
+	}
+
+	out, err = RenderDHCPReservations(reservations, nil, DHCPFormatISC)
+	if err != nil {
+		t.Fatalf("isc render failed: %v", err)
+	}
+	for _, want := range []string{"host web01 {", "hardware ethernet aa:bb:cc:00:00:01;", "fixed-address 10.0.0.20;"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("isc output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = RenderDHCPReservations(reservations, nil, DHCPFormatKea)
+	if err != nil {
+		t.Fatalf("kea render failed: %v", err)
+	}
+	var kea struct {
+		Reservations []map[string]string `json:"reservations"`
+	}
+	if err := json.Unmarshal(out, &kea); err != nil {
+		t.Fatalf("kea output is not JSON: %v", err)
+	}
+	wantKea := []map[string]string{{"hw-address": "aa:bb:cc:00:00:01", "ip-address": "10.0.0.20", "hostname": "web01"}}
+	if !reflect.DeepEqual(kea.Reservations, wantKea) {
+		t.Errorf("unexpected kea reservations: %v", kea.Reservations)
+	}
+
+	out, err = RenderDHCPReservations(nil, nil, DHCPFormatKea)
+	if err != nil || !strings.Contains(string(out), `"reservations": []`) {
+		t.Errorf("expected empty kea reservation list, got %s (%v)", out, err)
+	}
+
+	if _, err := RenderDHCPReservations(reservations, nil, "dhcpd"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestRenderDHCPReservations_NetworkOptions(t *testing.T) {
+	reservations := []DHCPReservation{{Hostname: "web01", MAC: "aa:bb:cc:00:00:01", IP: "10.0.0.20"}}
+	network := &model.Network{
+		Name: "lan", Subnet: "10.0.0.0/24", VLANID: 10, Gateway: "10.0.0.1",
+		DNSServers: []string{"10.0.0.53", "2001:db8::53", "10.0.0.54"}, MTU: 9000,
+	}
+
+	out, err := RenderDHCPReservations(reservations, network, DHCPFormatDnsmasq)
+	if err != nil {
+		t.Fatalf("dnsmasq render failed: %v", err)
+	}
+	for _, want := range []string{
+		"# Network lan (10.0.0.0/24), VLAN 10\n",
+		"dhcp-option=option:router,10.0.0.1\n",
+		"dhcp-option=option:dns-server,10.0.0.53,10.0.0.54\n",
+		"dhcp-option=option:mtu,9000\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("dnsmasq output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = RenderDHCPReservations(reservations, network, DHCPFormatISC)
+	if err != nil {
+		t.Fatalf("isc render failed: %v", err)
+	}
+	for _, want := range []string{
+		"subnet 10.0.0.0 netmask 255.255.255.0 {\n",
+		"  option routers 10.0.0.1;\n",
+		"  option domain-name-servers 10.0.0.53, 10.0.0.54;\n",
+		"  option interface-mtu 9000;\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("isc output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = RenderDHCPReservations(reservations, network, DHCPFormatKea)
+	if err != nil {
+		t.Fatalf("kea render failed: %v", err)
+	}
+	var kea struct {
+		Subnet       string              `json:"subnet"`
+		OptionData   []map[string]string `json:"option-data"`
+		Reservations []map[string]string `json:"reservations"`
+	}
+	if err := json.Unmarshal(out, &kea); err != nil {
+		t.Fatalf("kea output is not JSON: %v", err)
+	}
+	wantOptions := []map[string]string{
+		{"name": "routers", "data": "10.0.0.1"},
+		{"name": "domain-name-servers", "data": "10.0.0.53, 10.0.0.54"},
+		{"name": "interface-mtu", "data": "9000"},
+	}
+	if kea.Subnet != "10.0.0.0/24" || !reflect.DeepEqual(kea.OptionData, wantOptions) || len(kea.Reservations) != 1 {
+		t.Errorf("unexpected kea subnet: %s", out)
+	}
+
+	// An IPv6 network has no DHCPv4 options
+	out, _ = RenderDHCPReservations(reservations, &model.Network{Name: "v6", Subnet: "2001:db8::/64", MTU: 1500}, DHCPFormatDnsmasq)
+	if strings.Contains(string(out), "dhcp-option") {
+		t.Errorf("expected no options for an IPv6 network:\n%s", out)
+	}
+}
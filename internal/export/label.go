@@ -0,0 +1,75 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/qrcode"
+)
+
+// Label dimensions in millimetres, matching common 62 x 29 mm address labels
+const (
+	labelWidth  = 62
+	labelHeight = 29
+	labelQRSize = 25
+	// labelLineChars is roughly what fits beside the QR code at 3 mm
+	labelLineChars = 22
+)
+
+// WriteDeviceLabelSVG renders a printable asset label: a QR code linking to
+// url on the left and the device's name, asset tag, hostname and location on
+// the right. The SVG is sized in millimetres so it prints at label size.
+func WriteDeviceLabelSVG(label *model.DeviceLabel, url string, w io.Writer) error {
+	code, err := qrcode.Encode(url)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%dmm" height="%dmm" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif">`+"\n",
+		labelWidth, labelHeight, labelWidth, labelHeight)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`+"\n", labelWidth, labelHeight)
+
+	modules := code.Size + 2*qrcode.QuietZone
+	fmt.Fprintf(&b, `<g transform="translate(2 2) scale(%.4f)" shape-rendering="crispEdges"><path fill="#000" d="%s"/></g>`+"\n",
+		float64(labelQRSize)/float64(modules), code.SVGPath())
+
+	lines := []struct {
+		text   string
+		size   float64
+		weight string
+	}{
+		{label.Name, 4, "bold"},
+		{label.AssetTag, 3.2, "bold"},
+		{label.Hostname, 2.6, "normal"},
+		{label.Datacenter, 2.6, "normal"},
+		{label.Location, 2.6, "normal"},
+	}
+	y := 6.0
+	for _, l := range lines {
+		if l.text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="29" y="%.1f" font-size="%.1f" font-weight="%s">%s</text>`+"\n",
+			y, l.size, l.weight, labelEscape(l.text))
+		y += l.size + 1.8
+	}
+	b.WriteString("</svg>\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// labelEscape shortens s to fit on one line and escapes it for XML
+func labelEscape(s string) string {
+	if utf8.RuneCountInString(s) > labelLineChars {
+		s = string([]rune(s)[:labelLineChars-1]) + "…"
+	}
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
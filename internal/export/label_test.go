@@ -0,0 +1,51 @@
+package export
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestWriteDeviceLabelSVG(t *testing.T) {
+	label := &model.DeviceLabel{
+		DeviceID:   "dev-1",
+		Name:       "web<01> & friends with a long name",
+		AssetTag:   "A-1042",
+		Datacenter: "Frankfurt",
+		Location:   "Rack 4, U12",
+	}
+
+	var b strings.Builder
+	if err := WriteDeviceLabelSVG(label, "https://rackd.example.com/devices/detail?id=dev-1", &b); err != nil {
+		t.Fatalf("WriteDeviceLabelSVG failed: %v", err)
+	}
+	got := b.String()
+
+	// The output must be well-formed XML despite the markup in the name
+	dec := xml.NewDecoder(strings.NewReader(got))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err.Error() != "EOF" {
+				t.Fatalf("invalid SVG: %v", err)
+			}
+			break
+		}
+	}
+
+	for _, want := range []string{
+		`width="62mm" height="29mm"`,
+		`>web&lt;01&gt; &amp; friends wit…</text>`,
+		`>A-1042</text>`,
+		`>Rack 4, U12</text>`,
+		`<path fill="#000" d="M4 4h7v1h-7z`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("label missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "<text") != 4 {
+		t.Errorf("expected the empty hostname to be left out:\n%s", got)
+	}
+}
@@ -0,0 +1,228 @@
+package export
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Page layout of PDF tables, in points: A4 landscape with half-inch margins
+const (
+	pdfPageWidth  = 842
+	pdfPageHeight = 595
+	pdfMargin     = 36
+	pdfTitleSize  = 14
+	pdfTextSize   = 8
+	pdfLineHeight = 11
+	pdfCellPad    = 4
+)
+
+// pdfTableTop is the baseline of a page's header row, below the title and
+// subtitle
+const pdfTableTop = pdfPageHeight - pdfMargin - pdfTitleSize - 2*pdfLineHeight - 8
+
+// pdfRowsPerPage is how many rows fit under the header row above the footer
+const pdfRowsPerPage = (pdfTableTop - pdfMargin - 2*pdfLineHeight) / pdfLineHeight
+
+// helveticaWidths are the widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// WritePDF writes a PDF document with a table of rows under a bold header
+// row, headed by a title and a subtitle. The columns are sized to their
+// contents and cut to fit the page; numbers are right-aligned. The header
+// row repeats on every page. Text outside Latin-1 is replaced with "?".
+func WritePDF(w io.Writer, title, subtitle string, header []string, rows [][]any) error {
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		cells[i] = make([]string, len(header))
+		for col := range header {
+			if col < len(row) {
+				cells[i][col] = reportCell(row[col])
+			}
+		}
+	}
+
+	natural := make([]float64, len(header))
+	for col, h := range header {
+		natural[col] = pdfTextWidth(h, pdfTextSize) * 1.1
+		for _, row := range cells {
+			natural[col] = max(natural[col], pdfTextWidth(row[col], pdfTextSize))
+		}
+		natural[col] += 2 * pdfCellPad
+	}
+	widths := pdfColumnWidths(natural, pdfPageWidth-2*pdfMargin)
+
+	var pages []string
+	for start := 0; start == 0 || start < len(rows); start += pdfRowsPerPage {
+		end := min(start+pdfRowsPerPage, len(rows))
+		pages = append(pages, pdfPage(title, subtitle, header, cells[start:end], rows[start:end], widths, len(pages)+1))
+	}
+
+	var b bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...any) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\nendobj\n")
+	}
+
+	// Objects 1-4 are the catalog, the page tree and the fonts; each page is
+	// followed by its content stream, and the document information is last
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i)
+		content = strings.ReplaceAll(content, "{pages}", fmt.Sprint(len(pages)))
+		object("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+	}
+	object("<< /Title (%s) /Producer (Rackd) >>", pdfString(title))
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, len(offsets), xref)
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// pdfPage returns the content stream of a page. The page count is left as
+// {pages} until every page is laid out.
+func pdfPage(title, subtitle string, header []string, cells [][]string, rows [][]any, widths []float64, page int) string {
+	var b strings.Builder
+	text := func(font string, size float64, x, y float64, s string) {
+		fmt.Fprintf(&b, "BT /%s %g Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+	}
+
+	y := float64(pdfPageHeight - pdfMargin - pdfTitleSize)
+	text("F2", pdfTitleSize, pdfMargin, y, title)
+	y -= 1.5 * pdfLineHeight
+	text("F1", pdfTextSize+1, pdfMargin, y, subtitle)
+
+	y = pdfTableTop
+	x := float64(pdfMargin)
+	for col, h := range header {
+		text("F2", pdfTextSize, x+pdfCellPad, y, pdfFit(h, widths[col]-2*pdfCellPad, pdfTextSize*1.1))
+		x += widths[col]
+	}
+	fmt.Fprintf(&b, "0.5 w %d %.2f m %.2f %.2f l S\n", pdfMargin, y-3, x, y-3)
+
+	if len(cells) == 0 {
+		text("F1", pdfTextSize, pdfMargin+pdfCellPad, y-pdfLineHeight-2, "No results")
+	}
+	for i, row := range cells {
+		y -= pdfLineHeight
+		if i == 0 {
+			y -= 2
+		}
+		x = pdfMargin
+		for col, cell := range row {
+			cell = pdfFit(cell, widths[col]-2*pdfCellPad, pdfTextSize)
+			cellX := x + pdfCellPad
+			if col < len(rows[i]) {
+				if _, ok := xlsxNumber(rows[i][col]); ok {
+					cellX = x + widths[col] - pdfCellPad - pdfTextWidth(cell, pdfTextSize)
+				}
+			}
+			text("F1", pdfTextSize, cellX, y, cell)
+			x += widths[col]
+		}
+	}
+
+	fmt.Fprintf(&b, "BT /F1 %d Tf %d %d Td (Page %d of {pages}) Tj ET", pdfTextSize, pdfMargin, pdfMargin-pdfLineHeight, page)
+	return b.String()
+}
+
+// pdfColumnWidths shares out the page width. Columns narrower than an even
+// share of what is left keep their natural width; the wider ones split the
+// rest evenly.
+func pdfColumnWidths(natural []float64, available float64) []float64 {
+	order := make([]int, len(natural))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(natural[a], natural[b])
+	})
+
+	widths := make([]float64, len(natural))
+	remaining := available
+	for i, col := range order {
+		widths[col] = min(natural[col], remaining/float64(len(order)-i))
+		remaining -= widths[col]
+	}
+	return widths
+}
+
+// pdfFit cuts s with an ellipsis to fit width points at size
+func pdfFit(s string, width, size float64) string {
+	if pdfTextWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if cut := string(runes) + "..."; pdfTextWidth(cut, size) <= width {
+			return cut
+		}
+	}
+	return ""
+}
+
+// pdfTextWidth is the width of s in Helvetica at size, in points.
+// Characters outside ASCII count as a digit.
+func pdfTextWidth(s string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfString encodes s as the contents of a PDF literal string in
+// WinAnsiEncoding, which matches Latin-1 for the characters kept
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// PrometheusOptions controls which device addresses become scrape targets
+type PrometheusOptions struct {
+	// AddressLabel selects the addresses used as targets. Devices without an
+	// address carrying the label are left out, so exporters listening on a
+	// management interface are never scraped on another one. Without a label
+	// each device's first address is used, then its hostname.
+	AddressLabel string
+	// Port is added to every target. Without it, targets use the address's
+	// own port if it has one, and Prometheus's default otherwise.
+	Port int
+}
+
+// PrometheusTargetGroup is one entry of a Prometheus http_sd_config response
+type PrometheusTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// PrometheusTargets builds the target groups returned to Prometheus HTTP
+// service discovery: one group per device, labelled with __meta_rackd_*
+// labels for relabelling. Tags are in __meta_rackd_tags joined and wrapped
+// in commas, as Prometheus does for Consul, so a regex like .*,web,.* matches
+// one tag. Decommissioned devices are left out.
+func PrometheusTargets(devices []model.Device, datacenters []model.Datacenter, opts PrometheusOptions) []PrometheusTargetGroup {
+	dcNames := make(map[string]string, len(datacenters))
+	for _, dc := range datacenters {
+		dcNames[dc.ID] = dc.Name
+	}
+
+	groups := make([]PrometheusTargetGroup, 0, len(devices))
+	for _, d := range devices {
+		if d.Status == model.DeviceStatusDecommissioned {
+			continue
+		}
+		targets := prometheusTargets(&d, opts)
+		if len(targets) == 0 {
+			continue
+		}
+
+		labels := map[string]string{
+			"__meta_rackd_device_id":   d.ID,
+			"__meta_rackd_device_name": d.Name,
+		}
+		set := func(name, value string) {
+			if value != "" {
+				labels["__meta_rackd_"+name] = value
+			}
+		}
+		set("hostname", d.Hostname)
+		set("status", string(d.Status))
+		set("kind", string(d.Kind))
+		set("os", d.OS)
+		set("make_model", d.MakeModel)
+		set("location", d.Location)
+		if len(d.Tags) > 0 {
+			labels["__meta_rackd_tags"] = "," + strings.Join(d.Tags, ",") + ","
+		}
+		if d.DatacenterID != "" {
+			name := dcNames[d.DatacenterID]
+			if name == "" {
+				name = d.DatacenterID
+			}
+			labels["__meta_rackd_datacenter"] = name
+			labels["__meta_rackd_datacenter_id"] = d.DatacenterID
+		}
+		groups = append(groups, PrometheusTargetGroup{Targets: targets, Labels: labels})
+	}
+	return groups
+}
+
+func prometheusTargets(d *model.Device, opts PrometheusOptions) []string {
+	var targets []string
+	for _, a := range d.Addresses {
+		if a.IP == "" || (opts.AddressLabel != "" && !strings.EqualFold(a.Label, opts.AddressLabel)) {
+			continue
+		}
+		port := opts.Port
+		if port == 0 && a.Port != nil {
+			port = *a.Port
+		}
+		targets = append(targets, prometheusTarget(a.IP, port))
+		if opts.AddressLabel == "" {
+			break
+		}
+	}
+	if len(targets) == 0 && opts.AddressLabel == "" && d.Hostname != "" {
+		targets = append(targets, prometheusTarget(d.Hostname, opts.Port))
+	}
+	return targets
+}
+
+// prometheusTarget joins a host and port, bracketing IPv6 addresses
+func prometheusTarget(host string, port int) string {
+	if port == 0 {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
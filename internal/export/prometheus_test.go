@@ -0,0 +1,88 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestPrometheusTargets(t *testing.T) {
+	port := 9182
+	devices := []model.Device{
+		{
+			ID: "dev-1", Name: "web-1", Hostname: "web-1.example.com", OS: "Ubuntu 24.04", DatacenterID: "dc-1",
+			Status: model.DeviceStatusActive, Tags: []string{"web", "exporter"},
+			Addresses: []model.Address{
+				{IP: "10.0.0.11", Label: "data"},
+				{IP: "192.168.0.11", Label: "MGMT"},
+			},
+		},
+		{ID: "dev-2", Name: "db-1", DatacenterID: "dc-x", Addresses: []model.Address{{IP: "fd00::21", Label: "mgmt"}}},
+		{ID: "dev-3", Name: "win-1", Addresses: []model.Address{{IP: "10.0.0.31", Port: &port}}},
+		{ID: "dev-4", Name: "printer", Hostname: "printer.local"},
+		{ID: "dev-5", Name: "old", Status: model.DeviceStatusDecommissioned, Addresses: []model.Address{{IP: "10.0.0.51", Label: "mgmt"}}},
+	}
+	datacenters := []model.Datacenter{{ID: "dc-1", Name: "FRA-1"}}
+
+	targets := func(groups []PrometheusTargetGroup) map[string][]string {
+		byName := map[string][]string{}
+		for _, g := range groups {
+			byName[g.Labels["__meta_rackd_device_name"]] = g.Targets
+		}
+		return byName
+	}
+
+	t.Run("FirstAddress", func(t *testing.T) {
+		got := targets(PrometheusTargets(devices, datacenters, PrometheusOptions{}))
+		want := map[string][]string{
+			"web-1":   {"10.0.0.11"},
+			"db-1":    {"[fd00::21]"},
+			"win-1":   {"10.0.0.31:9182"},
+			"printer": {"printer.local"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("AddressLabelAndPort", func(t *testing.T) {
+		got := targets(PrometheusTargets(devices, datacenters, PrometheusOptions{AddressLabel: "mgmt", Port: 9100}))
+		want := map[string][]string{
+			"web-1": {"192.168.0.11:9100"},
+			"db-1":  {"[fd00::21]:9100"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Labels", func(t *testing.T) {
+		groups := PrometheusTargets(devices[:2], datacenters, PrometheusOptions{})
+		want := map[string]string{
+			"__meta_rackd_device_id":     "dev-1",
+			"__meta_rackd_device_name":   "web-1",
+			"__meta_rackd_hostname":      "web-1.example.com",
+			"__meta_rackd_status":        "active",
+			"__meta_rackd_os":            "Ubuntu 24.04",
+			"__meta_rackd_tags":          ",web,exporter,",
+			"__meta_rackd_datacenter":    "FRA-1",
+			"__meta_rackd_datacenter_id": "dc-1",
+		}
+		if !reflect.DeepEqual(groups[0].Labels, want) {
+			t.Errorf("expected labels %v, got %v", want, groups[0].Labels)
+		}
+		// Unknown datacenters fall back to the ID
+		if groups[1].Labels["__meta_rackd_datacenter"] != "dc-x" {
+			t.Errorf("expected datacenter ID fallback, got %v", groups[1].Labels)
+		}
+	})
+
+	t.Run("EmptyIsArray", func(t *testing.T) {
+		data, _ := json.Marshal(PrometheusTargets(nil, nil, PrometheusOptions{}))
+		if string(data) != "[]" {
+			t.Errorf("expected an empty JSON array for Prometheus, got %s", data)
+		}
+	})
+}
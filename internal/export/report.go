@@ -0,0 +1,59 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// ExportReport writes a report as JSON, CSV with a header row, an XLSX
+// workbook with the report's title as the sheet name, or a PDF table under
+// the report's title and generation time
+func ExportReport(report *model.Report, format Format, w io.Writer) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case FormatCSV:
+		return exportReportCSV(report, w)
+	case FormatXLSX:
+		return WriteXLSX(w, report.Title, report.Columns, report.Rows)
+	case FormatPDF:
+		return WritePDF(w, report.Title, "Generated "+report.GeneratedAt.UTC().Format(time.RFC3339), report.Columns, report.Rows)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+func exportReportCSV(report *model.Report, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(report.Columns); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = reportCell(v)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// reportCell formats a report cell as text, writing floats without an
+// exponent
+func reportCell(v any) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,149 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+var testReport = &model.Report{
+	Name:    "os-distribution",
+	Title:   "OS distribution",
+	Columns: []string{"os", "devices", "percent"},
+	Rows: [][]any{
+		{"Debian 12", 2, 66.67},
+		{"Windows <2022> & co", int64(1), 33.33},
+	},
+}
+
+func TestExportReportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportReport(testReport, FormatCSV, &buf); err != nil {
+		t.Fatalf("ExportReport failed: %v", err)
+	}
+	want := "os,devices,percent\nDebian 12,2,66.67\nWindows <2022> & co,1,33.33\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestExportReportXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportReport(testReport, FormatXLSX, &buf); err != nil {
+		t.Fatalf("ExportReport failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip file: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="OS distribution"`) {
+		t.Errorf("expected the title as the sheet name: %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">os</t></is></c>`,
+		`<c r="B2"><v>2</v></c>`,
+		`<c r="C3"><v>33.33</v></c>`,
+		`Windows &lt;2022&gt; &amp; co`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("expected sheet to contain %s", want)
+		}
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestExportReportPDF(t *testing.T) {
+	report := *testReport
+	report.Rows = append(slices.Clone(report.Rows), []any{"Ubuntu (LTS) \\ café ✓", 0, 0.0})
+	var buf bytes.Buffer
+	if err := ExportReport(&report, FormatPDF, &buf); err != nil {
+		t.Fatalf("ExportReport failed: %v", err)
+	}
+	pdf := buf.String()
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatalf("not a PDF document: %q", pdf)
+	}
+	for _, want := range []string{
+		"(OS distribution) Tj",
+		"(os) Tj",
+		"(Windows <2022> & co) Tj",
+		`(Ubuntu \(LTS\) \\ caf\351 ?) Tj`,
+		"(Page 1 of 1) Tj",
+		"/Count 1",
+	} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("expected PDF to contain %s", want)
+		}
+	}
+
+	// The cross-reference table must point at the objects
+	var xref int
+	if _, err := fmt.Sscanf(pdf[strings.LastIndex(pdf, "startxref\n"):], "startxref\n%d", &xref); err != nil {
+		t.Fatalf("no startxref: %v", err)
+	}
+	if !strings.HasPrefix(pdf[xref:], "xref\n0 8\n") {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := strings.Split(pdf[xref:], "\n")[3:10]
+	for i, entry := range entries {
+		var offset int
+		fmt.Sscanf(entry, "%d", &offset)
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(pdf[offset:], want) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:offset+10])
+		}
+	}
+}
+
+func TestExportReportPDFPages(t *testing.T) {
+	report := &model.Report{Title: "Big", Columns: []string{"n"}}
+	for i := range 2*pdfRowsPerPage + 1 {
+		report.Rows = append(report.Rows, []any{i})
+	}
+	var buf bytes.Buffer
+	if err := ExportReport(report, FormatPDF, &buf); err != nil {
+		t.Fatalf("ExportReport failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "/Count 3") || !strings.Contains(buf.String(), "(Page 3 of 3) Tj") {
+		t.Error("expected three pages")
+	}
+}
+
+func TestPDFColumnWidths(t *testing.T) {
+	got := pdfColumnWidths([]float64{50, 400, 30, 600}, 680)
+	want := []float64{50, 300, 30, 300}
+	if !slices.Equal(got, want) {
+		t.Errorf("pdfColumnWidths = %v, want %v", got, want)
+	}
+}
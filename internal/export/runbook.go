@@ -0,0 +1,332 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// Runbook holds everything needed to render an offline runbook bundle for a
+// single datacenter
+type Runbook struct {
+	Datacenter    model.Datacenter
+	Devices       []model.Device
+	Networks      []model.Network
+	Circuits      []model.Circuit
+	Relationships []model.DeviceRelationship
+	GeneratedAt   time.Time
+}
+
+// Rack is a device that contains other devices, rendered as an elevation
+type Rack struct {
+	Device   model.Device
+	Children []model.Device
+}
+
+// Contact is an entry on the runbook contact sheet
+type Contact struct {
+	Role  string
+	Name  string
+	Phone string
+	Email string
+	Notes string
+}
+
+// WriteRunbook writes a zip archive containing an HTML report, CSV inventory,
+// rack elevations, a network diagram and a contact sheet
+func WriteRunbook(rb *Runbook, w io.Writer) error {
+	if rb.GeneratedAt.IsZero() {
+		rb.GeneratedAt = time.Now().UTC()
+	}
+
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"index.html", rb.writeHTML},
+		{"inventory/devices.csv", func(w io.Writer) error { return exportDevicesCSV(rb.Devices, w) }},
+		{"inventory/networks.csv", func(w io.Writer) error { return exportNetworksCSV(rb.Networks, w) }},
+		{"inventory/circuits.csv", rb.writeCircuitsCSV},
+		{"racks.txt", rb.writeRackElevations},
+		{"network.dot", rb.writeNetworkDiagram},
+		{"contacts.csv", rb.writeContactsCSV},
+		{"manifest.json", rb.writeManifest},
+	}
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: rb.GeneratedAt})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", f.name, err)
+		}
+		if err := f.write(fw); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// Racks returns devices that contain other devices along with their
+// children ordered by location
+func (rb *Runbook) Racks() []Rack {
+	byID := make(map[string]model.Device, len(rb.Devices))
+	for _, d := range rb.Devices {
+		byID[d.ID] = d
+	}
+
+	children := make(map[string][]model.Device)
+	for _, rel := range rb.Relationships {
+		if rel.Type != model.RelationshipContains {
+			continue
+		}
+		parent, ok1 := byID[rel.ParentID]
+		child, ok2 := byID[rel.ChildID]
+		if ok1 && ok2 {
+			children[parent.ID] = append(children[parent.ID], child)
+		}
+	}
+
+	racks := make([]Rack, 0, len(children))
+	for id, kids := range children {
+		sort.SliceStable(kids, func(i, j int) bool {
+			if kids[i].Location != kids[j].Location {
+				return kids[i].Location < kids[j].Location
+			}
+			return kids[i].Name < kids[j].Name
+		})
+		racks = append(racks, Rack{Device: byID[id], Children: kids})
+	}
+	sort.Slice(racks, func(i, j int) bool { return racks[i].Device.Name < racks[j].Device.Name })
+	return racks
+}
+
+// Contacts returns the contact sheet entries for the datacenter: its own
+// contacts in escalation order, then the circuit providers
+func (rb *Runbook) Contacts() []Contact {
+	var contacts []Contact
+	for _, c := range rb.Datacenter.Contacts {
+		contacts = append(contacts, Contact{
+			Role:  c.Role,
+			Name:  c.Name,
+			Phone: c.Phone,
+			Email: c.Email,
+			Notes: fmt.Sprintf("Escalation %d", c.EscalationOrder),
+		})
+	}
+	for _, c := range rb.Circuits {
+		if c.ContactName == "" && c.ContactPhone == "" && c.ContactEmail == "" {
+			continue
+		}
+		contacts = append(contacts, Contact{
+			Role:  "Circuit provider: " + c.Provider,
+			Name:  c.ContactName,
+			Phone: c.ContactPhone,
+			Email: c.ContactEmail,
+			Notes: strings.TrimSpace(c.Name + " " + c.CircuitID),
+		})
+	}
+	return contacts
+}
+
+func (rb *Runbook) writeCircuitsCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"id", "name", "circuit_id", "provider", "type", "status", "capacity_mbps", "port_a", "port_b", "ip_address_a", "ip_address_b", "vlan_id", "contract_number"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, c := range rb.Circuits {
+		row := []string{
+			c.ID, c.Name, c.CircuitID, c.Provider, c.Type, string(c.Status),
+			fmt.Sprintf("%d", c.CapacityMbps), c.PortA, c.PortB, c.IPAddressA, c.IPAddressB,
+			fmt.Sprintf("%d", c.VLANID), c.ContractNumber,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rb *Runbook) writeContactsCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"role", "name", "phone", "email", "notes"}); err != nil {
+		return err
+	}
+	for _, c := range rb.Contacts() {
+		if err := writer.Write([]string{c.Role, c.Name, c.Phone, c.Email, c.Notes}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rb *Runbook) writeRackElevations(w io.Writer) error {
+	racks := rb.Racks()
+	if len(racks) == 0 {
+		_, err := fmt.Fprintln(w, "No racks defined. Model racks as devices with \"contains\" relationships to their members.")
+		return err
+	}
+	for _, rack := range racks {
+		fmt.Fprintf(w, "%s", rack.Device.Name)
+		if rack.Device.Location != "" {
+			fmt.Fprintf(w, " (%s)", rack.Device.Location)
+		}
+		fmt.Fprintf(w, "\n%s\n", strings.Repeat("=", 60))
+		for _, d := range rack.Children {
+			fmt.Fprintf(w, "| %-12s | %-28s | %-12s |\n", d.Location, d.Name, primaryIP(d))
+		}
+		if _, err := fmt.Fprintf(w, "%s\n\n", strings.Repeat("=", 60)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNetworkDiagram renders networks, their devices and device
+// relationships as a Graphviz graph
+func (rb *Runbook) writeNetworkDiagram(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "graph %q {\n", rb.Datacenter.Name)
+	b.WriteString("  rankdir=LR;\n  node [fontname=\"Helvetica\"];\n")
+
+	subnets := make([]*net.IPNet, len(rb.Networks))
+	for i, n := range rb.Networks {
+		_, subnets[i], _ = net.ParseCIDR(n.Subnet)
+		label := n.Name + "\\n" + n.Subnet
+		if n.VLANID > 0 {
+			label += fmt.Sprintf("\\nVLAN %d", n.VLANID)
+		}
+		fmt.Fprintf(&b, "  %q [shape=box, style=filled, fillcolor=\"#dbeafe\", label=\"%s\"];\n", "net:"+n.ID, dotEscape(label))
+	}
+
+	for _, d := range rb.Devices {
+		fmt.Fprintf(&b, "  %q [shape=ellipse, label=\"%s\"];\n", "dev:"+d.ID, dotEscape(d.Name))
+		for _, a := range d.Addresses {
+			for i, n := range rb.Networks {
+				ip := net.ParseIP(a.IP)
+				if a.NetworkID == n.ID || (a.NetworkID == "" && ip != nil && subnets[i] != nil && subnets[i].Contains(ip)) {
+					fmt.Fprintf(&b, "  %q -- %q [label=\"%s\"];\n", "net:"+n.ID, "dev:"+d.ID, dotEscape(a.IP))
+				}
+			}
+		}
+	}
+
+	inDC := make(map[string]bool, len(rb.Devices))
+	for _, d := range rb.Devices {
+		inDC[d.ID] = true
+	}
+	for _, rel := range rb.Relationships {
+		if inDC[rel.ParentID] && inDC[rel.ChildID] {
+			fmt.Fprintf(&b, "  %q -- %q [style=dashed, label=\"%s\"];\n", "dev:"+rel.ParentID, "dev:"+rel.ChildID, dotEscape(rel.Type))
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (rb *Runbook) writeManifest(w io.Writer) error {
+	manifest := map[string]any{
+		"datacenter":    rb.Datacenter,
+		"generated_at":  rb.GeneratedAt,
+		"devices":       len(rb.Devices),
+		"networks":      len(rb.Networks),
+		"circuits":      len(rb.Circuits),
+		"relationships": len(rb.Relationships),
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(manifest)
+}
+
+func (rb *Runbook) writeHTML(w io.Writer) error {
+	return runbookTemplate.Execute(w, rb)
+}
+
+func primaryIP(d model.Device) string {
+	if len(d.Addresses) == 0 {
+		return ""
+	}
+	return d.Addresses[0].IP
+}
+
+func dotEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+var runbookTemplate = template.Must(template.New("runbook").Funcs(template.FuncMap{
+	"primaryIP": primaryIP,
+	"join":      strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Runbook: {{.Datacenter.Name}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; margin: 2rem; color: #111; }
+h1 { margin-bottom: 0; }
+.meta { color: #555; margin-top: 0.25rem; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; font-size: 0.9rem; }
+th, td { border: 1px solid #ccc; padding: 0.3rem 0.5rem; text-align: left; vertical-align: top; }
+th { background: #f3f4f6; }
+@media print { h2 { page-break-before: always; } h2:first-of-type { page-break-before: avoid; } }
+</style>
+</head>
+<body>
+<h1>{{.Datacenter.Name}}</h1>
+<p class="meta">{{.Datacenter.Location}} &middot; generated {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}</p>
+{{with .Datacenter.Description}}<p>{{.}}</p>{{end}}
+{{with .Datacenter.ShippingAddress}}<h3>Shipping Address</h3>
+<p style="white-space: pre-line">{{.}}</p>{{end}}
+{{with .Datacenter.AccessInstructions}}<h3>Access Instructions</h3>
+<p style="white-space: pre-line">{{.}}</p>{{end}}
+
+<h2>Contacts</h2>
+{{with .Contacts}}<table>
+<tr><th>Role</th><th>Name</th><th>Phone</th><th>Email</th><th>Notes</th></tr>
+{{range .}}<tr><td>{{.Role}}</td><td>{{.Name}}</td><td>{{.Phone}}</td><td>{{.Email}}</td><td>{{.Notes}}</td></tr>
+{{end}}</table>{{else}}<p>No contacts recorded.</p>{{end}}
+
+<h2>Networks</h2>
+{{with .Networks}}<table>
+<tr><th>Name</th><th>Subnet</th><th>VLAN</th><th>Description</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{.Subnet}}</td><td>{{if .VLANID}}{{.VLANID}}{{end}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>{{else}}<p>No networks.</p>{{end}}
+
+<h2>Rack Elevations</h2>
+{{range .Racks}}<h3>{{.Device.Name}}{{with .Device.Location}} ({{.}}){{end}}</h3>
+<table>
+<tr><th>Position</th><th>Device</th><th>IP</th><th>Make/Model</th></tr>
+{{range .Children}}<tr><td>{{.Location}}</td><td>{{.Name}}</td><td>{{primaryIP .}}</td><td>{{.MakeModel}}</td></tr>
+{{end}}</table>
+{{else}}<p>No racks defined.</p>{{end}}
+
+<h2>Devices</h2>
+{{with .Devices}}<table>
+<tr><th>Name</th><th>Hostname</th><th>Status</th><th>Addresses</th><th>Make/Model</th><th>OS</th><th>Location</th><th>Tags</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{.Hostname}}</td><td>{{.Status}}</td><td>{{range .Addresses}}{{.IP}}<br>{{end}}</td><td>{{.MakeModel}}</td><td>{{.OS}}</td><td>{{.Location}}</td><td>{{join .Tags ", "}}</td></tr>
+{{end}}</table>{{else}}<p>No devices.</p>{{end}}
+
+<h2>Circuits</h2>
+{{with .Circuits}}<table>
+<tr><th>Name</th><th>Circuit ID</th><th>Provider</th><th>Status</th><th>Capacity</th><th>Ports</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{.CircuitID}}</td><td>{{.Provider}}</td><td>{{.Status}}</td><td>{{.CapacityMbps}} Mbps</td><td>{{.PortA}} / {{.PortB}}</td></tr>
+{{end}}</table>{{else}}<p>No circuits.</p>{{end}}
+
+<p class="meta">The network diagram is in network.dot; render it with <code>dot -Tsvg network.dot -o network.svg</code>.</p>
+</body>
+</html>
+`))
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func testRunbook() *Runbook {
+	return &Runbook{
+		Datacenter: model.Datacenter{ID: "dc-1", Name: "fra1", Location: "Frankfurt"},
+		Devices: []model.Device{
+			{ID: "rack-1", Name: "rack-a01", Location: "Row A"},
+			{ID: "dev-2", Name: "web-2", Location: "U12", Addresses: []model.Address{{IP: "10.0.0.12"}}},
+			{ID: "dev-1", Name: "web-1", Location: "U10", Addresses: []model.Address{{IP: "10.0.0.11", NetworkID: "net-1"}}},
+		},
+		Networks: []model.Network{
+			{ID: "net-1", Name: "prod", Subnet: "10.0.0.0/24", VLANID: 100},
+		},
+		Circuits: []model.Circuit{
+			{ID: "c-1", Name: "uplink", CircuitID: "CKT-1", Provider: "Acme", ContactName: "NOC", ContactPhone: "+49 123"},
+			{ID: "c-2", Name: "backup", Provider: "NoContact"},
+		},
+		Relationships: []model.DeviceRelationship{
+			{ParentID: "rack-1", ChildID: "dev-2", Type: model.RelationshipContains},
+			{ParentID: "rack-1", ChildID: "dev-1", Type: model.RelationshipContains},
+			{ParentID: "dev-1", ChildID: "missing", Type: model.RelationshipContains},
+		},
+		GeneratedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWriteRunbook(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRunbook(testRunbook(), &buf); err != nil {
+		t.Fatalf("WriteRunbook failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	expected := map[string]string{
+		"index.html":             "fra1",
+		"inventory/devices.csv":  "web-1",
+		"inventory/networks.csv": "10.0.0.0/24",
+		"inventory/circuits.csv": "CKT-1",
+		"racks.txt":              "rack-a01",
+		"network.dot":            `"net:net-1" -- "dev:dev-1"`,
+		"contacts.csv":           "+49 123",
+		"manifest.json":          `"devices": 3`,
+	}
+	for name, want := range expected {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("missing %s in bundle", name)
+			continue
+		}
+		if !strings.Contains(content, want) {
+			t.Errorf("%s does not contain %q", name, want)
+		}
+	}
+
+	// Addresses without a network ID are matched to networks by subnet
+	if !strings.Contains(files["network.dot"], `"net:net-1" -- "dev:dev-2"`) {
+		t.Error("expected dev-2 to be linked to net-1 by subnet")
+	}
+}
+
+func TestRunbookRacks(t *testing.T) {
+	racks := testRunbook().Racks()
+	if len(racks) != 1 {
+		t.Fatalf("expected 1 rack, got %d", len(racks))
+	}
+	if racks[0].Device.Name != "rack-a01" {
+		t.Errorf("rack = %s, want rack-a01", racks[0].Device.Name)
+	}
+	if len(racks[0].Children) != 2 || racks[0].Children[0].Name != "web-1" {
+		t.Errorf("expected children ordered by location, got %+v", racks[0].Children)
+	}
+}
+
+func TestRunbookContacts(t *testing.T) {
+	contacts := testRunbook().Contacts()
+	if len(contacts) != 1 {
+		t.Fatalf("expected 1 contact, got %d", len(contacts))
+	}
+	if contacts[0].Name != "NOC" || !strings.Contains(contacts[0].Role, "Acme") {
+		t.Errorf("unexpected contact: %+v", contacts[0])
+	}
+}
+
+func TestRunbookContacts_DatacenterFirst(t *testing.T) {
+	rb := testRunbook()
+	rb.Datacenter.Contacts = []model.DatacenterContact{{Role: "Remote hands", Name: "Ana", Phone: "+49 555", EscalationOrder: 1}}
+	rb.Datacenter.AccessInstructions = "Badge at reception"
+
+	contacts := rb.Contacts()
+	if len(contacts) != 2 || contacts[0].Name != "Ana" || contacts[0].Notes != "Escalation 1" || contacts[1].Name != "NOC" {
+		t.Fatalf("expected the datacenter contact before the circuit provider, got %+v", contacts)
+	}
+
+	var buf bytes.Buffer
+	if err := rb.writeHTML(&buf); err != nil {
+		t.Fatalf("writeHTML failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Badge at reception") {
+		t.Error("expected access instructions in the HTML report")
+	}
+}
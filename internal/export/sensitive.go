@@ -0,0 +1,114 @@
+package export
+
+import (
+	"encoding/base64"
+
+	"filippo.io/age"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// SensitiveDeviceFields lists the device fields handled by ProtectDevices:
+// the login username and the user who last changed the status. Exports carry
+// no scan data or stored credentials.
+var SensitiveDeviceFields = []string{"username", "status_changed_by"}
+
+// SensitiveDatacenterFields lists the datacenter fields handled by
+// ProtectDatacenters. Access instructions often hold door or cage codes.
+var SensitiveDatacenterFields = []string{"access_instructions", "contacts.name", "contacts.email", "contacts.phone"}
+
+// SensitiveCircuitFields lists the circuit fields handled by ProtectCircuits:
+// the provider contact and the contract number used to authenticate with
+// the provider.
+var SensitiveCircuitFields = []string{"contract_number", "contact_name", "contact_phone", "contact_email"}
+
+// SensitiveOptions controls how sensitive fields are written to exports
+type SensitiveOptions struct {
+	// Omit blanks sensitive fields
+	Omit bool
+	// Recipient encrypts sensitive fields when set. Each value becomes a
+	// base64-encoded age file; decrypt with
+	// `echo <value> | base64 -d | age -d -i key.txt`.
+	Recipient age.Recipient
+}
+
+func (o SensitiveOptions) enabled() bool {
+	return o.Omit || o.Recipient != nil
+}
+
+// ProtectDevices returns copies of devices with sensitive fields omitted or
+// encrypted. Devices are returned unchanged when neither option is set.
+func ProtectDevices(devices []model.Device, opts SensitiveOptions) ([]model.Device, error) {
+	if !opts.enabled() {
+		return devices, nil
+	}
+
+	protected := make([]model.Device, len(devices))
+	for i, d := range devices {
+		if err := opts.protect(&d.Username, &d.StatusChangedBy); err != nil {
+			return nil, err
+		}
+		protected[i] = d
+	}
+	return protected, nil
+}
+
+// ProtectDatacenters returns copies of datacenters with sensitive fields
+// omitted or encrypted
+func ProtectDatacenters(datacenters []model.Datacenter, opts SensitiveOptions) ([]model.Datacenter, error) {
+	if !opts.enabled() {
+		return datacenters, nil
+	}
+
+	protected := make([]model.Datacenter, len(datacenters))
+	for i, dc := range datacenters {
+		if err := opts.protect(&dc.AccessInstructions); err != nil {
+			return nil, err
+		}
+		contacts := make([]model.DatacenterContact, len(dc.Contacts))
+		for j, c := range dc.Contacts {
+			if err := opts.protect(&c.Name, &c.Email, &c.Phone); err != nil {
+				return nil, err
+			}
+			contacts[j] = c
+		}
+		if dc.Contacts != nil {
+			dc.Contacts = contacts
+		}
+		protected[i] = dc
+	}
+	return protected, nil
+}
+
+// ProtectCircuits returns copies of circuits with sensitive fields omitted or
+// encrypted
+func ProtectCircuits(circuits []model.Circuit, opts SensitiveOptions) ([]model.Circuit, error) {
+	if !opts.enabled() {
+		return circuits, nil
+	}
+
+	protected := make([]model.Circuit, len(circuits))
+	for i, c := range circuits {
+		if err := opts.protect(&c.ContractNumber, &c.ContactName, &c.ContactPhone, &c.ContactEmail); err != nil {
+			return nil, err
+		}
+		protected[i] = c
+	}
+	return protected, nil
+}
+
+// protect blanks or encrypts each of values in place
+func (o SensitiveOptions) protect(values ...*string) error {
+	for _, value := range values {
+		if o.Omit || *value == "" {
+			*value = ""
+			continue
+		}
+		ciphertext, err := ageEncrypt(o.Recipient, []byte(*value))
+		if err != nil {
+			return err
+		}
+		*value = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+	return nil
+}
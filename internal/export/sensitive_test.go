@@ -0,0 +1,180 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestParseAgeRecipient(t *testing.T) {
+	recipient := testAgeIdentity(t).Recipient().String()
+	if _, err := ParseAgeRecipient(" " + recipient + "\n"); err != nil {
+		t.Fatalf("ParseAgeRecipient failed: %v", err)
+	}
+
+	for _, bad := range []string{"", "age1", "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", recipient[:len(recipient)-1] + "q"} {
+		if _, err := ParseAgeRecipient(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+// TestAgeEncryptDecryptsWithAge checks the output is a standard age file by
+// decrypting it with the age library
+func TestAgeEncryptDecryptsWithAge(t *testing.T) {
+	identity := testAgeIdentity(t)
+	recipient, err := ParseAgeRecipient(identity.Recipient().String())
+	if err != nil {
+		t.Fatalf("ParseAgeRecipient failed: %v", err)
+	}
+
+	// Sizes around age's 64 KiB payload chunks
+	for _, size := range []int{0, 5, 64 * 1024, 64*1024 + 1} {
+		plaintext := bytes.Repeat([]byte("x"), size)
+		ciphertext, err := ageEncrypt(recipient, plaintext)
+		if err != nil {
+			t.Fatalf("ageEncrypt failed: %v", err)
+		}
+		r, err := age.Decrypt(bytes.NewReader(ciphertext), identity)
+		if err != nil {
+			t.Fatalf("size %d: age.Decrypt failed: %v", size, err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("size %d: reading plaintext failed: %v", size, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestProtectDevices(t *testing.T) {
+	devices := []model.Device{{ID: "dev-1", Name: "web-1", Username: "deploy", StatusChangedBy: "alice"}, {ID: "dev-2", Name: "web-2"}}
+
+	t.Run("unchanged", func(t *testing.T) {
+		got, err := ProtectDevices(devices, SensitiveOptions{})
+		if err != nil {
+			t.Fatalf("ProtectDevices failed: %v", err)
+		}
+		if got[0].Username != "deploy" {
+			t.Errorf("username = %q, want deploy", got[0].Username)
+		}
+	})
+
+	t.Run("omit", func(t *testing.T) {
+		got, err := ProtectDevices(devices, SensitiveOptions{Omit: true})
+		if err != nil {
+			t.Fatalf("ProtectDevices failed: %v", err)
+		}
+		if got[0].Username != "" || got[0].StatusChangedBy != "" {
+			t.Errorf("username, status_changed_by = %q, %q, want empty", got[0].Username, got[0].StatusChangedBy)
+		}
+		if devices[0].Username != "deploy" {
+			t.Error("input devices should not be modified")
+		}
+	})
+
+	t.Run("encrypt", func(t *testing.T) {
+		identity := testAgeIdentity(t)
+		got, err := ProtectDevices(devices, SensitiveOptions{Recipient: identity.Recipient()})
+		if err != nil {
+			t.Fatalf("ProtectDevices failed: %v", err)
+		}
+		if plain := testAgeDecrypt(t, identity, got[0].Username); plain != "deploy" {
+			t.Errorf("decrypted username = %q, want deploy", plain)
+		}
+		if got[1].Username != "" {
+			t.Errorf("empty username should stay empty, got %q", got[1].Username)
+		}
+
+		var buf bytes.Buffer
+		if err := ExportDevices(got, FormatCSV, &buf); err != nil {
+			t.Fatalf("ExportDevices failed: %v", err)
+		}
+		if strings.Contains(buf.String(), "deploy") {
+			t.Error("CSV export should not contain the plaintext username")
+		}
+	})
+}
+
+func TestProtectDatacenters(t *testing.T) {
+	datacenters := []model.Datacenter{{
+		ID:                 "dc-1",
+		Name:               "fra1",
+		AccessInstructions: "cage code 4711",
+		Contacts:           []model.DatacenterContact{{Role: "remote hands", Name: "Bob", Email: "bob@example.com", Phone: "+49 30 1234"}},
+	}}
+
+	got, err := ProtectDatacenters(datacenters, SensitiveOptions{Omit: true})
+	if err != nil {
+		t.Fatalf("ProtectDatacenters failed: %v", err)
+	}
+	c := got[0].Contacts[0]
+	if got[0].AccessInstructions != "" || c.Name != "" || c.Email != "" || c.Phone != "" {
+		t.Errorf("expected sensitive fields to be blank, got %+v", got[0])
+	}
+	if c.Role != "remote hands" || got[0].Name != "fra1" {
+		t.Errorf("expected other fields to be kept, got %+v", got[0])
+	}
+	if datacenters[0].Contacts[0].Name != "Bob" {
+		t.Error("input datacenters should not be modified")
+	}
+
+	identity := testAgeIdentity(t)
+	got, err = ProtectDatacenters(datacenters, SensitiveOptions{Recipient: identity.Recipient()})
+	if err != nil {
+		t.Fatalf("ProtectDatacenters failed: %v", err)
+	}
+	if plain := testAgeDecrypt(t, identity, got[0].Contacts[0].Phone); plain != "+49 30 1234" {
+		t.Errorf("decrypted phone = %q", plain)
+	}
+}
+
+func TestProtectCircuits(t *testing.T) {
+	circuits := []model.Circuit{{ID: "c-1", Name: "uplink", ContractNumber: "K-99", ContactName: "NOC", ContactPhone: "112", ContactEmail: "noc@isp.example"}}
+
+	got, err := ProtectCircuits(circuits, SensitiveOptions{Omit: true})
+	if err != nil {
+		t.Fatalf("ProtectCircuits failed: %v", err)
+	}
+	if c := got[0]; c.ContractNumber != "" || c.ContactName != "" || c.ContactPhone != "" || c.ContactEmail != "" {
+		t.Errorf("expected sensitive fields to be blank, got %+v", c)
+	}
+	if got[0].Name != "uplink" {
+		t.Errorf("expected the name to be kept, got %q", got[0].Name)
+	}
+}
+
+func testAgeIdentity(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity failed: %v", err)
+	}
+	return identity
+}
+
+// testAgeDecrypt decrypts a base64-encoded age file with the age library
+func testAgeDecrypt(t *testing.T, identity age.Identity, value string) string {
+	t.Helper()
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		t.Fatalf("encrypted value is not base64: %v", err)
+	}
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identity)
+	if err != nil {
+		t.Fatalf("age.Decrypt failed: %v", err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading plaintext failed: %v", err)
+	}
+	return string(plain)
+}
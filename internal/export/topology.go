@@ -0,0 +1,51 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// WriteTopologyDOT renders a topology graph in Graphviz DOT syntax. Networks
+// are boxes and devices ellipses; address membership is a solid line labelled
+// with the IP and switch port, nesting a bold line, and device relationships
+// dashed lines labelled with their type.
+func WriteTopologyDOT(t *model.Topology, w io.Writer) error {
+	var b strings.Builder
+	name := "topology"
+	if t.DatacenterID != "" {
+		name = "topology " + t.DatacenterID
+	}
+	fmt.Fprintf(&b, "graph %q {\n", name)
+	b.WriteString("  rankdir=LR;\n  node [fontname=\"Helvetica\"];\n")
+
+	for _, n := range t.Nodes {
+		switch n.Type {
+		case model.TopologyNodeNetwork:
+			fmt.Fprintf(&b, "  %q [shape=box, style=filled, fillcolor=\"#dbeafe\", label=\"%s\"];\n", n.ID, dotEscape(n.Label+"\\n"+n.Subnet))
+		default:
+			fmt.Fprintf(&b, "  %q [shape=ellipse, label=\"%s\"];\n", n.ID, dotEscape(n.Label))
+		}
+	}
+
+	for _, e := range t.Edges {
+		switch e.Type {
+		case model.TopologyEdgeMember:
+			label := e.Label
+			if e.SwitchPort != "" {
+				label += "\\n" + e.SwitchPort
+			}
+			fmt.Fprintf(&b, "  %q -- %q [label=\"%s\"];\n", e.Target, e.Source, dotEscape(label))
+		case model.TopologyEdgeSubnet:
+			fmt.Fprintf(&b, "  %q -- %q [style=bold];\n", e.Source, e.Target)
+		default:
+			fmt.Fprintf(&b, "  %q -- %q [style=dashed, label=\"%s\"];\n", e.Source, e.Target, dotEscape(e.Type))
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
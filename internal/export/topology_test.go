@@ -0,0 +1,47 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestWriteTopologyDOT(t *testing.T) {
+	topology := &model.Topology{
+		DatacenterID: "dc-1",
+		Nodes: []model.TopologyNode{
+			{ID: "net-1", Type: model.TopologyNodeNetwork, Label: "campus", Subnet: "10.0.0.0/16"},
+			{ID: "net-2", Type: model.TopologyNodeNetwork, Label: "servers", Subnet: "10.0.1.0/24"},
+			{ID: "dev-1", Type: model.TopologyNodeDevice, Label: `web "01"`},
+			{ID: "dev-2", Type: model.TopologyNodeDevice, Label: "sw01"},
+		},
+		Edges: []model.TopologyEdge{
+			{Source: "dev-1", Target: "net-2", Type: model.TopologyEdgeMember, Label: "10.0.1.10", SwitchPort: "Gi1/0/24"},
+			{Source: "net-1", Target: "net-2", Type: model.TopologyEdgeSubnet},
+			{Source: "dev-2", Target: "dev-1", Type: model.RelationshipConnectedTo},
+		},
+	}
+
+	var b strings.Builder
+	if err := WriteTopologyDOT(topology, &b); err != nil {
+		t.Fatalf("WriteTopologyDOT failed: %v", err)
+	}
+	got := b.String()
+
+	for _, want := range []string{
+		`graph "topology dc-1" {`,
+		`"net-2" [shape=box, style=filled, fillcolor="#dbeafe", label="servers\n10.0.1.0/24"];`,
+		`"dev-1" [shape=ellipse, label="web \"01\""];`,
+		`"net-2" -- "dev-1" [label="10.0.1.10\nGi1/0/24"];`,
+		`"net-1" -- "net-2" [style=bold];`,
+		`"dev-2" -- "dev-1" [style=dashed, label="connected_to"];`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected DOT output to contain %s, got:\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "}\n") {
+		t.Errorf("expected DOT output to close the graph, got:\n%s", got)
+	}
+}
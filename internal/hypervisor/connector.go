@@ -0,0 +1,88 @@
+// Package hypervisor reads the hosts and virtual machines of virtualisation
+// platforms so they can be synced into rackd with the VMs linked to their
+// hosts.
+package hypervisor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Connector reads the inventory of one hypervisor cluster or vCenter
+type Connector interface {
+	// Inventory returns every host and VM the credentials can see
+	Inventory(ctx context.Context) (*Inventory, error)
+}
+
+// Inventory is the hosts and VMs reported by a connector
+type Inventory struct {
+	Hosts []Host
+	VMs   []VM
+}
+
+// Host is a hypervisor host
+type Host struct {
+	ID     string
+	Name   string
+	Status string // Platform-native status, e.g. "online"
+	OS     string
+	IPs    []string
+}
+
+// VM is a virtual machine or container and the host it runs on
+type VM struct {
+	ID     string
+	Name   string
+	Status string // Platform-native status, e.g. "running"
+	HostID string
+	OS     string
+	IPs    []string
+	Tags   []string
+}
+
+func newHTTPClient(insecureSkipVerify bool) *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecureSkipVerify},
+			Proxy:           http.ProxyFromEnvironment,
+		},
+	}
+}
+
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("%s %s: unexpected status %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// usableIP reports whether ip is worth recording: loopback and link-local
+// addresses are left out
+func usableIP(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && !parsed.IsLoopback() && !parsed.IsLinkLocalUnicast() && !parsed.IsUnspecified()
+}
+
+func trimEndpoint(endpoint string) string {
+	return strings.TrimSuffix(endpoint, "/")
+}
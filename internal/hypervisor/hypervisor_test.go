@@ -0,0 +1,135 @@
+package hypervisor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestProxmoxInventory(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "PVEAPIToken=root@pam!rackd=secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api2/json/cluster/status":
+			fmt.Fprint(w, `{"data":[{"type":"cluster","name":"lab"},
+				{"type":"node","name":"pve1","ip":"10.0.0.11","online":1},
+				{"type":"node","name":"pve2","ip":"10.0.0.12","online":0}]}`)
+		case "/api2/json/cluster/resources":
+			fmt.Fprint(w, `{"data":[
+				{"vmid":100,"name":"web-1","node":"pve1","status":"running","type":"qemu","tags":"prod;web"},
+				{"vmid":101,"name":"dns-1","node":"pve1","status":"running","type":"lxc"},
+				{"vmid":102,"name":"old","node":"pve2","status":"stopped","type":"qemu"},
+				{"vmid":9000,"name":"tmpl","node":"pve1","status":"stopped","type":"qemu","template":1}]}`)
+		case "/api2/json/nodes/pve1/qemu/100/agent/network-get-interfaces":
+			fmt.Fprint(w, `{"data":{"result":[{"ip-addresses":[{"ip-address":"127.0.0.1"}]},
+				{"ip-addresses":[{"ip-address":"10.0.1.5"},{"ip-address":"fe80::1"}]}]}}`)
+		case "/api2/json/nodes/pve1/lxc/101/interfaces":
+			fmt.Fprint(w, `{"data":[{"inet":"127.0.0.1/8"},{"inet":"10.0.1.6/24","inet6":"2001:db8::6/64"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	inv, err := NewProxmox(srv.URL+"/", "root@pam!rackd", "secret", false).Inventory(context.Background())
+	if err != nil {
+		t.Fatalf("Inventory failed: %v", err)
+	}
+	if len(inv.Hosts) != 2 || inv.Hosts[0].Status != "online" || inv.Hosts[1].Status != "offline" ||
+		!slices.Equal(inv.Hosts[0].IPs, []string{"10.0.0.11"}) {
+		t.Errorf("unexpected hosts: %+v", inv.Hosts)
+	}
+	if len(inv.VMs) != 3 {
+		t.Fatalf("expected 3 VMs without the template, got %+v", inv.VMs)
+	}
+	web := inv.VMs[0]
+	if web.ID != "100" || web.HostID != "pve1" || !slices.Equal(web.Tags, []string{"prod", "web"}) ||
+		!slices.Equal(web.IPs, []string{"10.0.1.5"}) {
+		t.Errorf("unexpected VM: %+v", web)
+	}
+	if !slices.Equal(inv.VMs[1].IPs, []string{"10.0.1.6", "2001:db8::6"}) {
+		t.Errorf("unexpected container addresses: %v", inv.VMs[1].IPs)
+	}
+	if len(inv.VMs[2].IPs) != 0 {
+		t.Errorf("expected no addresses for a stopped VM, got %v", inv.VMs[2].IPs)
+	}
+
+	if _, err := NewProxmox(srv.URL, "root@pam!rackd", "wrong", false).Inventory(context.Background()); err == nil {
+		t.Error("expected an error for a bad token")
+	}
+}
+
+func TestVSphereInventory(t *testing.T) {
+	loggedOut := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/session" {
+			switch r.Method {
+			case http.MethodPost:
+				if user, pass, ok := r.BasicAuth(); !ok || user != "reader" || pass != "secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `"session-1"`)
+			case http.MethodDelete:
+				loggedOut = true
+			}
+			return
+		}
+		if r.Header.Get("vmware-api-session-id") != "session-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/vcenter/host":
+			fmt.Fprint(w, `[{"host":"host-1","name":"10.0.0.21","connection_state":"CONNECTED"},
+				{"host":"host-2","name":"esx2.example.com","connection_state":"DISCONNECTED"}]`)
+		case "/api/vcenter/vm":
+			if r.URL.Query().Get("hosts") == "host-1" {
+				fmt.Fprint(w, `[{"vm":"vm-1","name":"app-1","power_state":"POWERED_ON"},
+					{"vm":"vm-2","name":"app-2","power_state":"POWERED_ON"}]`)
+			} else {
+				fmt.Fprint(w, `[{"vm":"vm-3","name":"app-3","power_state":"POWERED_OFF"}]`)
+			}
+		case "/api/vcenter/vm/vm-1/guest/identity":
+			fmt.Fprint(w, `{"ip_address":"10.0.2.5","full_name":{"default_message":"Ubuntu Linux (64-bit)"}}`)
+		default:
+			// vm-2 has no VMware Tools running
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	inv, err := NewVSphere(srv.URL, "reader", "secret", false).Inventory(context.Background())
+	if err != nil {
+		t.Fatalf("Inventory failed: %v", err)
+	}
+	if !loggedOut {
+		t.Error("expected the session to be deleted")
+	}
+	if len(inv.Hosts) != 2 || !slices.Equal(inv.Hosts[0].IPs, []string{"10.0.0.21"}) || len(inv.Hosts[1].IPs) != 0 {
+		t.Errorf("unexpected hosts: %+v", inv.Hosts)
+	}
+	if len(inv.VMs) != 3 {
+		t.Fatalf("expected 3 VMs, got %+v", inv.VMs)
+	}
+	if vm := inv.VMs[0]; vm.HostID != "host-1" || vm.OS != "Ubuntu Linux (64-bit)" || !slices.Equal(vm.IPs, []string{"10.0.2.5"}) {
+		t.Errorf("unexpected VM: %+v", vm)
+	}
+	if vm := inv.VMs[1]; vm.OS != "" || len(vm.IPs) != 0 {
+		t.Errorf("expected no guest details without VMware Tools, got %+v", vm)
+	}
+	if inv.VMs[2].HostID != "host-2" {
+		t.Errorf("unexpected host for app-3: %+v", inv.VMs[2])
+	}
+
+	if _, err := NewVSphere(srv.URL, "reader", "wrong", false).Inventory(context.Background()); err == nil {
+		t.Error("expected a login error")
+	}
+}
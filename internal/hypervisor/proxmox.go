@@ -0,0 +1,161 @@
+package hypervisor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Proxmox reads a Proxmox VE cluster through its API. It authenticates with
+// an API token; the token only needs the PVEAuditor role.
+type Proxmox struct {
+	endpoint string
+	tokenID  string
+	secret   string
+	client   *http.Client
+}
+
+// NewProxmox creates a Proxmox VE connector. endpoint is the API base URL,
+// e.g. https://pve1:8006, and tokenID names the token as user@realm!name.
+func NewProxmox(endpoint, tokenID, secret string, insecureSkipVerify bool) *Proxmox {
+	return &Proxmox{
+		endpoint: trimEndpoint(endpoint),
+		tokenID:  tokenID,
+		secret:   secret,
+		client:   newHTTPClient(insecureSkipVerify),
+	}
+}
+
+func (p *Proxmox) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"/api2/json"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "PVEAPIToken="+p.tokenID+"="+p.secret)
+	return doJSON(p.client, req, out)
+}
+
+type proxmoxClusterStatus struct {
+	Data []struct {
+		Type   string `json:"type"`
+		Name   string `json:"name"`
+		IP     string `json:"ip"`
+		Online int    `json:"online"`
+	} `json:"data"`
+}
+
+type proxmoxResources struct {
+	Data []struct {
+		VMID     int    `json:"vmid"`
+		Name     string `json:"name"`
+		Node     string `json:"node"`
+		Status   string `json:"status"`
+		Type     string `json:"type"`
+		Tags     string `json:"tags"`
+		Template int    `json:"template"`
+	} `json:"data"`
+}
+
+type proxmoxAgentInterfaces struct {
+	Data struct {
+		Result []struct {
+			IPAddresses []struct {
+				IPAddress string `json:"ip-address"`
+			} `json:"ip-addresses"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+type proxmoxLXCInterfaces struct {
+	Data []struct {
+		Inet  string `json:"inet"`
+		Inet6 string `json:"inet6"`
+	} `json:"data"`
+}
+
+// Inventory returns the cluster nodes and their QEMU VMs and LXC containers.
+// Templates are skipped. VM addresses come from the QEMU guest agent or the
+// container's interfaces, so stopped VMs have none.
+func (p *Proxmox) Inventory(ctx context.Context) (*Inventory, error) {
+	var status proxmoxClusterStatus
+	if err := p.get(ctx, "/cluster/status", &status); err != nil {
+		return nil, err
+	}
+	inv := &Inventory{}
+	for _, n := range status.Data {
+		if n.Type != "node" {
+			continue
+		}
+		host := Host{ID: n.Name, Name: n.Name, Status: "offline", OS: "Proxmox VE"}
+		if n.Online == 1 {
+			host.Status = "online"
+		}
+		if usableIP(n.IP) {
+			host.IPs = []string{n.IP}
+		}
+		inv.Hosts = append(inv.Hosts, host)
+	}
+
+	var resources proxmoxResources
+	if err := p.get(ctx, "/cluster/resources?type=vm", &resources); err != nil {
+		return nil, err
+	}
+	for _, r := range resources.Data {
+		if r.Template == 1 {
+			continue
+		}
+		vm := VM{
+			ID:     strconv.Itoa(r.VMID),
+			Name:   r.Name,
+			Status: r.Status,
+			HostID: r.Node,
+		}
+		for _, tag := range strings.Split(r.Tags, ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				vm.Tags = append(vm.Tags, tag)
+			}
+		}
+		if r.Status == "running" {
+			vm.IPs = p.vmAddresses(ctx, r.Node, r.Type, r.VMID)
+		}
+		inv.VMs = append(inv.VMs, vm)
+	}
+	return inv, nil
+}
+
+// vmAddresses returns the addresses of a running VM or container. VMs without
+// a guest agent have none, which is not an error.
+func (p *Proxmox) vmAddresses(ctx context.Context, node, kind string, vmid int) []string {
+	var ips []string
+	add := func(ip string) {
+		ip, _, _ = strings.Cut(ip, "/")
+		if usableIP(ip) {
+			ips = append(ips, ip)
+		}
+	}
+
+	switch kind {
+	case "qemu":
+		var ifaces proxmoxAgentInterfaces
+		if err := p.get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/agent/network-get-interfaces", node, vmid), &ifaces); err != nil {
+			return nil
+		}
+		for _, iface := range ifaces.Data.Result {
+			for _, addr := range iface.IPAddresses {
+				add(addr.IPAddress)
+			}
+		}
+	case "lxc":
+		var ifaces proxmoxLXCInterfaces
+		if err := p.get(ctx, fmt.Sprintf("/nodes/%s/lxc/%d/interfaces", node, vmid), &ifaces); err != nil {
+			return nil
+		}
+		for _, iface := range ifaces.Data {
+			add(iface.Inet)
+			add(iface.Inet6)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,125 @@
+package hypervisor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// VSphere reads hosts and VMs from vCenter through its REST API (vSphere 7.0
+// Update 2 or later). A read-only vCenter user is sufficient.
+type VSphere struct {
+	endpoint string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewVSphere creates a vSphere connector. endpoint is the vCenter base URL,
+// e.g. https://vcenter.example.com.
+func NewVSphere(endpoint, username, password string, insecureSkipVerify bool) *VSphere {
+	return &VSphere{
+		endpoint: trimEndpoint(endpoint),
+		username: username,
+		password: password,
+		client:   newHTTPClient(insecureSkipVerify),
+	}
+}
+
+type vsphereHost struct {
+	Host            string `json:"host"`
+	Name            string `json:"name"`
+	ConnectionState string `json:"connection_state"`
+}
+
+type vsphereVM struct {
+	VM         string `json:"vm"`
+	Name       string `json:"name"`
+	PowerState string `json:"power_state"`
+}
+
+type vsphereGuestIdentity struct {
+	IPAddress string `json:"ip_address"`
+	FullName  struct {
+		DefaultMessage string `json:"default_message"`
+	} `json:"full_name"`
+}
+
+// Inventory returns the ESXi hosts and the VMs on each. The OS and address
+// of powered-on VMs come from VMware Tools, so VMs without it have neither.
+func (v *VSphere) Inventory(ctx context.Context) (*Inventory, error) {
+	session, err := v.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer v.logout(session)
+
+	var hosts []vsphereHost
+	if err := v.get(ctx, session, "/api/vcenter/host", &hosts); err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{}
+	for _, h := range hosts {
+		host := Host{ID: h.Host, Name: h.Name, Status: h.ConnectionState, OS: "VMware ESXi"}
+		if usableIP(h.Name) {
+			host.IPs = []string{h.Name}
+		}
+		inv.Hosts = append(inv.Hosts, host)
+
+		var vms []vsphereVM
+		if err := v.get(ctx, session, "/api/vcenter/vm?hosts="+url.QueryEscape(h.Host), &vms); err != nil {
+			return nil, err
+		}
+		for _, m := range vms {
+			vm := VM{ID: m.VM, Name: m.Name, Status: m.PowerState, HostID: h.Host}
+			if m.PowerState == "POWERED_ON" {
+				var identity vsphereGuestIdentity
+				// Fails with 503 when VMware Tools is not running
+				if err := v.get(ctx, session, "/api/vcenter/vm/"+url.PathEscape(m.VM)+"/guest/identity", &identity); err == nil {
+					vm.OS = identity.FullName.DefaultMessage
+					if usableIP(identity.IPAddress) {
+						vm.IPs = []string{identity.IPAddress}
+					}
+				}
+			}
+			inv.VMs = append(inv.VMs, vm)
+		}
+	}
+	return inv, nil
+}
+
+func (v *VSphere) login(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint+"/api/session", nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(v.username, v.password)
+
+	var session string
+	if err := doJSON(v.client, req, &session); err != nil {
+		return "", fmt.Errorf("vCenter login failed: %w", err)
+	}
+	return session, nil
+}
+
+func (v *VSphere) logout(session string) {
+	req, err := http.NewRequest(http.MethodDelete, v.endpoint+"/api/session", nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("vmware-api-session-id", session)
+	if resp, err := v.client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (v *VSphere) get(ctx context.Context, session, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.endpoint+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("vmware-api-session-id", session)
+	return doJSON(v.client, req, out)
+}
@@ -0,0 +1,737 @@
+package importdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const netboxPageSize = 1000
+
+// NetBoxClient reads sites, prefixes, IP ranges, IP addresses and devices
+// from the NetBox REST API
+type NetBoxClient struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewNetBoxClient creates a client for the NetBox instance at baseURL using an
+// API token
+func NewNetBoxClient(baseURL, token string) *NetBoxClient {
+	return &NetBoxClient{
+		endpoint: strings.TrimSuffix(baseURL, "/"),
+		token:    token,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type netboxRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type netboxTag struct {
+	Name string `json:"name"`
+}
+
+type netboxSite struct {
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Facility        string     `json:"facility"`
+	PhysicalAddress string     `json:"physical_address"`
+	Region          *netboxRef `json:"region"`
+}
+
+type netboxPrefix struct {
+	ID          int    `json:"id"`
+	Prefix      string `json:"prefix"`
+	Description string `json:"description"`
+	// NetBox 4.2 replaced the site field with a generic scope
+	Site      *netboxRef `json:"site"`
+	ScopeType string     `json:"scope_type"`
+	Scope     *netboxRef `json:"scope"`
+	VLAN      *struct {
+		VID int `json:"vid"`
+	} `json:"vlan"`
+}
+
+type netboxIPRange struct {
+	ID           int         `json:"id"`
+	StartAddress string      `json:"start_address"`
+	EndAddress   string      `json:"end_address"`
+	Description  string      `json:"description"`
+	Tags         []netboxTag `json:"tags"`
+}
+
+type netboxIPAddress struct {
+	ID                 int    `json:"id"`
+	Address            string `json:"address"`
+	DNSName            string `json:"dns_name"`
+	AssignedObjectType string `json:"assigned_object_type"`
+	AssignedObject     *struct {
+		Name   string     `json:"name"`
+		Device *netboxRef `json:"device"`
+	} `json:"assigned_object"`
+}
+
+type netboxDevice struct {
+	ID          int        `json:"id"`
+	Name        *string    `json:"name"`
+	Description string     `json:"description"`
+	Site        *netboxRef `json:"site"`
+	Rack        *netboxRef `json:"rack"`
+	Position    *float64   `json:"position"`
+	Platform    *netboxRef `json:"platform"`
+	DeviceType  *struct {
+		Model        string     `json:"model"`
+		Manufacturer *netboxRef `json:"manufacturer"`
+	} `json:"device_type"`
+	Status *struct {
+		Value string `json:"value"`
+	} `json:"status"`
+	Tags []netboxTag `json:"tags"`
+}
+
+type netboxInventory struct {
+	sites       []netboxSite
+	prefixes    []netboxPrefix
+	ipRanges    []netboxIPRange
+	ipAddresses []netboxIPAddress
+	devices     []netboxDevice
+}
+
+// Fetch reads the NetBox inventory and maps it onto rackd objects
+func (n *NetBoxClient) Fetch(ctx context.Context) (*NetBoxImport, error) {
+	var inv netboxInventory
+	var err error
+	if inv.sites, err = netboxList[netboxSite](ctx, n, "dcim/sites"); err != nil {
+		return nil, err
+	}
+	if inv.prefixes, err = netboxList[netboxPrefix](ctx, n, "ipam/prefixes"); err != nil {
+		return nil, err
+	}
+	if inv.ipRanges, err = netboxList[netboxIPRange](ctx, n, "ipam/ip-ranges"); err != nil {
+		return nil, err
+	}
+	if inv.ipAddresses, err = netboxList[netboxIPAddress](ctx, n, "ipam/ip-addresses"); err != nil {
+		return nil, err
+	}
+	if inv.devices, err = netboxList[netboxDevice](ctx, n, "dcim/devices"); err != nil {
+		return nil, err
+	}
+	return mapNetBox(&inv), nil
+}
+
+// netboxList fetches every page of a NetBox list endpoint
+func netboxList[T any](ctx context.Context, n *NetBoxClient, path string) ([]T, error) {
+	var all []T
+	next := fmt.Sprintf("%s/api/%s/?limit=%d", n.endpoint, path, netboxPageSize)
+	for next != "" {
+		var page struct {
+			Next    *string `json:"next"`
+			Results []T     `json:"results"`
+		}
+		if err := n.get(ctx, next, &page); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", path, err)
+		}
+		all = append(all, page.Results...)
+		next = ""
+		if page.Next != nil {
+			next = *page.Next
+		}
+	}
+	return all, nil
+}
+
+func (n *NetBoxClient) get(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+n.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// NetBoxImport is a NetBox inventory mapped onto rackd objects. References
+// between objects are by NetBox key (site name, prefix) and are resolved to
+// rackd IDs when the import is applied.
+type NetBoxImport struct {
+	Datacenters []model.Datacenter `json:"datacenters"`
+	Networks    []NetBoxNetwork    `json:"networks"`
+	Pools       []NetBoxPool       `json:"pools"`
+	Devices     []NetBoxDevice     `json:"devices"`
+	Warnings    []string           `json:"warnings,omitempty"`
+}
+
+// NetBoxNetwork is a network mapped from a NetBox prefix
+type NetBoxNetwork struct {
+	Network model.Network `json:"network"`
+	Site    string        `json:"site,omitempty"`
+}
+
+// NetBoxPool is a pool mapped from a NetBox IP range
+type NetBoxPool struct {
+	Pool   model.NetworkPool `json:"pool"`
+	Prefix string            `json:"prefix"`
+}
+
+// NetBoxDevice is a device mapped from a NetBox device and its IP addresses
+type NetBoxDevice struct {
+	Device model.Device `json:"device"`
+	Site   string       `json:"site,omitempty"`
+}
+
+func mapNetBox(inv *netboxInventory) *NetBoxImport {
+	imp := &NetBoxImport{}
+
+	for _, s := range inv.sites {
+		dc := model.Datacenter{Name: s.Name, Description: s.Description, Location: s.PhysicalAddress}
+		if dc.Location == "" {
+			dc.Location = s.Facility
+		}
+		if dc.Location == "" && s.Region != nil {
+			dc.Location = s.Region.Name
+		}
+		imp.Datacenters = append(imp.Datacenters, dc)
+	}
+
+	var prefixes []netip.Prefix
+	for _, p := range inv.prefixes {
+		prefix, err := netip.ParsePrefix(p.Prefix)
+		if err != nil {
+			imp.Warnings = append(imp.Warnings, fmt.Sprintf("prefix %d: invalid prefix %q", p.ID, p.Prefix))
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+
+		n := NetBoxNetwork{Network: model.Network{
+			Name:        p.Description,
+			Subnet:      prefix.Masked().String(),
+			Description: p.Description,
+		}}
+		if n.Network.Name == "" {
+			n.Network.Name = n.Network.Subnet
+		}
+		if p.VLAN != nil {
+			n.Network.VLANID = p.VLAN.VID
+		}
+		if p.Site != nil {
+			n.Site = p.Site.Name
+		} else if p.ScopeType == "dcim.site" && p.Scope != nil {
+			n.Site = p.Scope.Name
+		}
+		imp.Networks = append(imp.Networks, n)
+	}
+
+	for _, r := range inv.ipRanges {
+		start, errStart := netip.ParsePrefix(r.StartAddress)
+		end, errEnd := netip.ParsePrefix(r.EndAddress)
+		if errStart != nil || errEnd != nil {
+			imp.Warnings = append(imp.Warnings, fmt.Sprintf("IP range %d: invalid addresses %s-%s", r.ID, r.StartAddress, r.EndAddress))
+			continue
+		}
+		prefix, ok := mostSpecificPrefix(prefixes, start.Addr())
+		if !ok {
+			imp.Warnings = append(imp.Warnings, fmt.Sprintf("IP range %s-%s: no containing prefix, skipped", start.Addr(), end.Addr()))
+			continue
+		}
+		pool := model.NetworkPool{
+			Name:        r.Description,
+			StartIP:     start.Addr().String(),
+			EndIP:       end.Addr().String(),
+			Description: r.Description,
+			Tags:        netboxTagNames(r.Tags),
+		}
+		if pool.Name == "" {
+			pool.Name = pool.StartIP + "-" + pool.EndIP
+		}
+		imp.Pools = append(imp.Pools, NetBoxPool{Pool: pool, Prefix: prefix.String()})
+	}
+
+	devices := make(map[int]int)
+	for _, d := range inv.devices {
+		if d.Name == nil || *d.Name == "" {
+			imp.Warnings = append(imp.Warnings, fmt.Sprintf("device %d: unnamed device skipped", d.ID))
+			continue
+		}
+		device := model.Device{
+			Name:        *d.Name,
+			Description: d.Description,
+			Status:      netboxDeviceStatus(d.Status),
+			Tags:        netboxTagNames(d.Tags),
+		}
+		if d.DeviceType != nil {
+			device.MakeModel = d.DeviceType.Model
+			if d.DeviceType.Manufacturer != nil {
+				device.MakeModel = strings.TrimSpace(d.DeviceType.Manufacturer.Name + " " + d.DeviceType.Model)
+			}
+		}
+		if d.Platform != nil {
+			device.OS = d.Platform.Name
+		}
+		if d.Rack != nil {
+			device.Location = d.Rack.Name
+			if d.Position != nil {
+				device.Location += fmt.Sprintf(" U%g", *d.Position)
+			}
+		}
+		nd := NetBoxDevice{Device: device}
+		if d.Site != nil {
+			nd.Site = d.Site.Name
+		}
+		devices[d.ID] = len(imp.Devices)
+		imp.Devices = append(imp.Devices, nd)
+	}
+
+	unassigned := 0
+	for _, a := range inv.ipAddresses {
+		if a.AssignedObjectType != "dcim.interface" || a.AssignedObject == nil || a.AssignedObject.Device == nil {
+			unassigned++
+			continue
+		}
+		i, ok := devices[a.AssignedObject.Device.ID]
+		if !ok {
+			unassigned++
+			continue
+		}
+		prefix, err := netip.ParsePrefix(a.Address)
+		if err != nil {
+			imp.Warnings = append(imp.Warnings, fmt.Sprintf("IP address %d: invalid address %q", a.ID, a.Address))
+			continue
+		}
+		addrType := "ipv4"
+		if prefix.Addr().Is6() {
+			addrType = "ipv6"
+		}
+		device := &imp.Devices[i].Device
+		device.Addresses = append(device.Addresses, model.Address{
+			IP:    prefix.Addr().String(),
+			Type:  addrType,
+			Label: a.AssignedObject.Name,
+		})
+		if a.DNSName != "" && !slices.Contains(device.Domains, a.DNSName) {
+			device.Domains = append(device.Domains, a.DNSName)
+		}
+	}
+	if unassigned > 0 {
+		imp.Warnings = append(imp.Warnings, fmt.Sprintf("%d IP addresses not assigned to a device interface were skipped", unassigned))
+	}
+
+	return imp
+}
+
+// netboxDeviceStatus maps NetBox device statuses onto rackd lifecycle statuses
+func netboxDeviceStatus(status *struct {
+	Value string `json:"value"`
+}) model.DeviceStatus {
+	if status == nil {
+		return model.DeviceStatusActive
+	}
+	switch status.Value {
+	case "planned", "staged", "inventory":
+		return model.DeviceStatusPlanned
+	case "offline", "failed":
+		return model.DeviceStatusMaintenance
+	case "decommissioning":
+		return model.DeviceStatusDecommissioned
+	default:
+		return model.DeviceStatusActive
+	}
+}
+
+func netboxTagNames(tags []netboxTag) []string {
+	var names []string
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func mostSpecificPrefix(prefixes []netip.Prefix, addr netip.Addr) (netip.Prefix, bool) {
+	var best netip.Prefix
+	found := false
+	for _, p := range prefixes {
+		if p.Contains(addr) && (!found || p.Bits() > best.Bits()) {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+// NetBoxTarget is the rackd inventory a NetBox import is applied to. Save
+// methods create the object when its ID is empty and update it otherwise,
+// setting the ID of created objects.
+type NetBoxTarget interface {
+	ListDatacenters(ctx context.Context) ([]model.Datacenter, error)
+	ListNetworks(ctx context.Context) ([]model.Network, error)
+	ListPools(ctx context.Context, networkID string) ([]model.NetworkPool, error)
+	ListDevices(ctx context.Context) ([]model.Device, error)
+	SaveDatacenter(ctx context.Context, dc *model.Datacenter) error
+	SaveNetwork(ctx context.Context, network *model.Network) error
+	SavePool(ctx context.Context, pool *model.NetworkPool) error
+	SaveDevice(ctx context.Context, device *model.Device) error
+}
+
+// NetBoxResult contains the results of applying a NetBox import
+type NetBoxResult struct {
+	Datacenters ImportResult
+	Networks    ImportResult
+	Pools       ImportResult
+	Devices     ImportResult
+	// Actions lists what was (or, in a dry run, would be) done per object
+	Actions []string
+}
+
+// ApplyNetBox applies a NetBox import to target. Existing records are
+// matched by datacenter name, network subnet, pool range and device name and
+// handled according to mode: ConflictSkip leaves them alone, ConflictUpdate
+// fills them in from NetBox (adding missing addresses and tags) and
+// ConflictFail aborts before any change is made. With dryRun set nothing is
+// written and the result describes the planned changes.
+func ApplyNetBox(ctx context.Context, target NetBoxTarget, imp *NetBoxImport, mode ConflictMode, dryRun bool) (*NetBoxResult, error) {
+	switch mode {
+	case ConflictSkip, ConflictUpdate, ConflictFail:
+	default:
+		return nil, fmt.Errorf("invalid conflict mode %q: must be skip, update or fail", mode)
+	}
+
+	a := &netboxApplier{target: target, mode: mode, dryRun: dryRun, result: &NetBoxResult{}, pools: make(map[string][]model.NetworkPool)}
+	var err error
+	if a.datacenters, err = target.ListDatacenters(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list datacenters: %w", err)
+	}
+	if a.networks, err = target.ListNetworks(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+	if a.devices, err = target.ListDevices(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	if mode == ConflictFail {
+		if conflicts, err := a.conflicts(ctx, imp); err != nil {
+			return nil, err
+		} else if len(conflicts) > 0 {
+			return nil, fmt.Errorf("%d records already exist: %s", len(conflicts), strings.Join(conflicts, ", "))
+		}
+	}
+
+	for _, dc := range imp.Datacenters {
+		a.applyDatacenter(ctx, dc)
+	}
+	for _, n := range imp.Networks {
+		a.applyNetwork(ctx, n)
+	}
+	for _, p := range imp.Pools {
+		a.applyPool(ctx, p)
+	}
+	for _, d := range imp.Devices {
+		a.applyDevice(ctx, d)
+	}
+	return a.result, nil
+}
+
+type netboxApplier struct {
+	target      NetBoxTarget
+	mode        ConflictMode
+	dryRun      bool
+	result      *NetBoxResult
+	datacenters []model.Datacenter
+	networks    []model.Network
+	devices     []model.Device
+	pools       map[string][]model.NetworkPool
+}
+
+func (a *netboxApplier) conflicts(ctx context.Context, imp *NetBoxImport) ([]string, error) {
+	var conflicts []string
+	for _, dc := range imp.Datacenters {
+		if a.findDatacenter(dc.Name) != nil {
+			conflicts = append(conflicts, "datacenter "+dc.Name)
+		}
+	}
+	for _, n := range imp.Networks {
+		if a.findNetwork(n.Network.Subnet) != nil {
+			conflicts = append(conflicts, "network "+n.Network.Subnet)
+		}
+	}
+	for _, p := range imp.Pools {
+		existing, err := a.findPool(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			conflicts = append(conflicts, "pool "+p.Pool.Name)
+		}
+	}
+	for _, d := range imp.Devices {
+		if a.findDevice(d.Device.Name) != nil {
+			conflicts = append(conflicts, "device "+d.Device.Name)
+		}
+	}
+	return conflicts, nil
+}
+
+func (a *netboxApplier) findDatacenter(name string) *model.Datacenter {
+	for i := range a.datacenters {
+		if strings.EqualFold(a.datacenters[i].Name, name) {
+			return &a.datacenters[i]
+		}
+	}
+	return nil
+}
+
+func (a *netboxApplier) findNetwork(subnet string) *model.Network {
+	for i := range a.networks {
+		if a.networks[i].Subnet == subnet {
+			return &a.networks[i]
+		}
+	}
+	return nil
+}
+
+func (a *netboxApplier) findPool(ctx context.Context, p NetBoxPool) (*model.NetworkPool, error) {
+	network := a.findNetwork(p.Prefix)
+	if network == nil || network.ID == "" {
+		return nil, nil
+	}
+	pools, ok := a.pools[network.ID]
+	if !ok {
+		var err error
+		if pools, err = a.target.ListPools(ctx, network.ID); err != nil {
+			return nil, fmt.Errorf("failed to list pools of network %s: %w", network.Subnet, err)
+		}
+		a.pools[network.ID] = pools
+	}
+	for i := range pools {
+		if pools[i].StartIP == p.Pool.StartIP && pools[i].EndIP == p.Pool.EndIP {
+			return &pools[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (a *netboxApplier) findDevice(name string) *model.Device {
+	for i := range a.devices {
+		if strings.EqualFold(a.devices[i].Name, name) {
+			return &a.devices[i]
+		}
+	}
+	return nil
+}
+
+// datacenterID resolves a NetBox site name to a rackd datacenter ID
+func (a *netboxApplier) datacenterID(site string) string {
+	if site == "" {
+		return ""
+	}
+	if dc := a.findDatacenter(site); dc != nil {
+		return dc.ID
+	}
+	return ""
+}
+
+// networkFor returns the most specific rackd network containing ip
+func (a *netboxApplier) networkFor(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	id, bits := "", -1
+	for _, n := range a.networks {
+		prefix, err := netip.ParsePrefix(n.Subnet)
+		if err == nil && prefix.Contains(addr) && prefix.Bits() > bits {
+			id, bits = n.ID, prefix.Bits()
+		}
+	}
+	return id
+}
+
+// action decides what to do with an imported object given whether a
+// matching record already exists
+func (a *netboxApplier) action(exists bool) string {
+	switch {
+	case !exists:
+		return "create"
+	case a.mode == ConflictUpdate:
+		return "update"
+	default:
+		return "skip"
+	}
+}
+
+// save reports whether an action writes to the target
+func (a *netboxApplier) save(action string) bool {
+	return action != "skip" && !a.dryRun
+}
+
+func (a *netboxApplier) record(res *ImportResult, action, kind, name string, err error) {
+	res.Total++
+	label := kind + " " + name
+	if err != nil {
+		res.Failed++
+		res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", label, err))
+		return
+	}
+	switch action {
+	case "create":
+		res.Created++
+	case "update":
+		res.Updated++
+	default:
+		res.Skipped++
+		label += " (exists)"
+	}
+	a.result.Actions = append(a.result.Actions, action+" "+label)
+}
+
+func (a *netboxApplier) applyDatacenter(ctx context.Context, dc model.Datacenter) {
+	existing := a.findDatacenter(dc.Name)
+	action := a.action(existing != nil)
+	if !a.save(action) {
+		if existing == nil {
+			a.datacenters = append(a.datacenters, dc)
+		}
+		a.record(&a.result.Datacenters, action, "datacenter", dc.Name, nil)
+		return
+	}
+	if existing != nil {
+		updated := *existing
+		setIfEmpty(&updated.Location, dc.Location)
+		setIfEmpty(&updated.Description, dc.Description)
+		dc = updated
+	}
+	err := a.target.SaveDatacenter(ctx, &dc)
+	if err == nil {
+		if existing != nil {
+			*existing = dc
+		} else {
+			a.datacenters = append(a.datacenters, dc)
+		}
+	}
+	a.record(&a.result.Datacenters, action, "datacenter", dc.Name, err)
+}
+
+func (a *netboxApplier) applyNetwork(ctx context.Context, n NetBoxNetwork) {
+	network := n.Network
+	network.DatacenterID = a.datacenterID(n.Site)
+	existing := a.findNetwork(network.Subnet)
+	action := a.action(existing != nil)
+	if !a.save(action) {
+		if existing == nil {
+			a.networks = append(a.networks, network)
+		}
+		a.record(&a.result.Networks, action, "network", network.Subnet, nil)
+		return
+	}
+	if existing != nil {
+		updated := *existing
+		setIfEmpty(&updated.Description, network.Description)
+		setIfEmpty(&updated.DatacenterID, network.DatacenterID)
+		if updated.VLANID == 0 {
+			updated.VLANID = network.VLANID
+		}
+		network = updated
+	}
+	err := a.target.SaveNetwork(ctx, &network)
+	if err == nil {
+		if existing != nil {
+			*existing = network
+		} else {
+			a.networks = append(a.networks, network)
+		}
+	}
+	a.record(&a.result.Networks, action, "network", network.Subnet, err)
+}
+
+func (a *netboxApplier) applyPool(ctx context.Context, p NetBoxPool) {
+	existing, err := a.findPool(ctx, p)
+	if err != nil {
+		a.record(&a.result.Pools, "create", "pool", p.Pool.Name, err)
+		return
+	}
+	action := a.action(existing != nil)
+	if !a.save(action) {
+		a.record(&a.result.Pools, action, "pool", p.Pool.Name, nil)
+		return
+	}
+	pool := p.Pool
+	if existing != nil {
+		pool = *existing
+		setIfEmpty(&pool.Description, p.Pool.Description)
+		pool.Tags = unionStrings(pool.Tags, p.Pool.Tags)
+	} else if network := a.findNetwork(p.Prefix); network != nil && network.ID != "" {
+		pool.NetworkID = network.ID
+	} else {
+		a.record(&a.result.Pools, action, "pool", pool.Name, fmt.Errorf("network %s was not imported", p.Prefix))
+		return
+	}
+	a.record(&a.result.Pools, action, "pool", pool.Name, a.target.SavePool(ctx, &pool))
+}
+
+func (a *netboxApplier) applyDevice(ctx context.Context, d NetBoxDevice) {
+	device := d.Device
+	device.DatacenterID = a.datacenterID(d.Site)
+	device.Addresses = slices.Clone(device.Addresses)
+	for i := range device.Addresses {
+		device.Addresses[i].NetworkID = a.networkFor(device.Addresses[i].IP)
+	}
+	existing := a.findDevice(device.Name)
+	action := a.action(existing != nil)
+	if !a.save(action) {
+		a.record(&a.result.Devices, action, "device", device.Name, nil)
+		return
+	}
+	if existing != nil {
+		updated := *existing
+		setIfEmpty(&updated.Description, device.Description)
+		setIfEmpty(&updated.MakeModel, device.MakeModel)
+		setIfEmpty(&updated.OS, device.OS)
+		setIfEmpty(&updated.Location, device.Location)
+		setIfEmpty(&updated.DatacenterID, device.DatacenterID)
+		updated.Tags = unionStrings(updated.Tags, device.Tags)
+		updated.Domains = unionStrings(updated.Domains, device.Domains)
+		for _, addr := range device.Addresses {
+			if !slices.ContainsFunc(updated.Addresses, func(e model.Address) bool { return e.IP == addr.IP }) {
+				updated.Addresses = append(updated.Addresses, addr)
+			}
+		}
+		device = updated
+	}
+	a.record(&a.result.Devices, action, "device", device.Name, a.target.SaveDevice(ctx, &device))
+}
+
+func setIfEmpty(dst *string, value string) {
+	if *dst == "" {
+		*dst = value
+	}
+}
+
+func unionStrings(have, add []string) []string {
+	for _, v := range add {
+		if !slices.Contains(have, v) {
+			have = append(have, v)
+		}
+	}
+	return have
+}
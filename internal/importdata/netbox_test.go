@@ -0,0 +1,274 @@
+package importdata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+var netboxFixtures = map[string]string{
+	"/api/dcim/sites/": `{"results": [{"id": 1, "name": "fra1", "facility": "Equinix FR5"}]}`,
+	"/api/ipam/prefixes/": `{"results": [
+		{"id": 1, "prefix": "10.0.0.0/16", "description": ""},
+		{"id": 2, "prefix": "10.0.1.0/24", "description": "servers", "site": {"id": 1, "name": "fra1"}, "vlan": {"vid": 20}}
+	]}`,
+	"/api/ipam/ip-ranges/": `{"results": [{"id": 1, "start_address": "10.0.1.100/24", "end_address": "10.0.1.150/24", "description": "dhcp"}]}`,
+	"/api/ipam/ip-addresses/": `{"results": [
+		{"id": 1, "address": "10.0.1.10/24", "dns_name": "web01.example.com", "assigned_object_type": "dcim.interface",
+		 "assigned_object": {"name": "eth0", "device": {"id": 7, "name": "web01"}}},
+		{"id": 2, "address": "10.0.9.9/24", "assigned_object_type": null, "assigned_object": null}
+	]}`,
+}
+
+// netboxDevicesPages serves devices over two pages to exercise pagination
+var netboxDevicesPages = []string{
+	`{"next": "%s/api/dcim/devices/?limit=1000&offset=1", "results": [
+		{"id": 7, "name": "web01", "site": {"id": 1, "name": "fra1"}, "rack": {"id": 1, "name": "R12"}, "position": 20,
+		 "platform": {"id": 1, "name": "Ubuntu 24.04"}, "device_type": {"model": "R650", "manufacturer": {"id": 1, "name": "Dell"}},
+		 "status": {"value": "active"}, "tags": [{"name": "web"}]}
+	]}`,
+	`{"next": null, "results": [{"id": 8, "name": null}, {"id": 9, "name": "spare01", "status": {"value": "inventory"}}]}`,
+}
+
+func newNetBoxServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path == "/api/dcim/devices/" {
+			page := netboxDevicesPages[0]
+			if r.URL.Query().Get("offset") == "1" {
+				page = netboxDevicesPages[1]
+			}
+			fmt.Fprintf(w, page, srv.URL)
+			return
+		}
+		body, ok := netboxFixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNetBoxClient_Fetch(t *testing.T) {
+	srv := newNetBoxServer(t)
+
+	imp, err := NewNetBoxClient(srv.URL+"/", "secret").Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(imp.Datacenters) != 1 || imp.Datacenters[0].Location != "Equinix FR5" {
+		t.Errorf("unexpected datacenters: %+v", imp.Datacenters)
+	}
+	if len(imp.Networks) != 2 {
+		t.Fatalf("expected 2 networks, got %d", len(imp.Networks))
+	}
+	if n := imp.Networks[1]; n.Network.Name != "servers" || n.Network.VLANID != 20 || n.Site != "fra1" {
+		t.Errorf("unexpected network: %+v", n)
+	}
+	if imp.Networks[0].Network.Name != "10.0.0.0/16" {
+		t.Errorf("network without description should be named by prefix, got %q", imp.Networks[0].Network.Name)
+	}
+	if len(imp.Pools) != 1 || imp.Pools[0].Prefix != "10.0.1.0/24" || imp.Pools[0].Pool.StartIP != "10.0.1.100" {
+		t.Errorf("expected pool in the most specific prefix, got %+v", imp.Pools)
+	}
+
+	if len(imp.Devices) != 2 {
+		t.Fatalf("expected 2 named devices, got %d", len(imp.Devices))
+	}
+	web := imp.Devices[0].Device
+	if web.MakeModel != "Dell R650" || web.OS != "Ubuntu 24.04" || web.Location != "R12 U20" {
+		t.Errorf("unexpected device mapping: %+v", web)
+	}
+	if len(web.Addresses) != 1 || web.Addresses[0].IP != "10.0.1.10" || web.Addresses[0].Label != "eth0" {
+		t.Errorf("unexpected addresses: %+v", web.Addresses)
+	}
+	if len(web.Domains) != 1 || web.Domains[0] != "web01.example.com" {
+		t.Errorf("unexpected domains: %v", web.Domains)
+	}
+	if imp.Devices[1].Device.Status != model.DeviceStatusPlanned {
+		t.Errorf("inventory status should map to planned, got %q", imp.Devices[1].Device.Status)
+	}
+	if len(imp.Warnings) != 2 {
+		t.Errorf("expected warnings for the unnamed device and unassigned IP, got %v", imp.Warnings)
+	}
+
+	if _, err := NewNetBoxClient(srv.URL, "wrong").Fetch(context.Background()); err == nil {
+		t.Error("expected error for rejected token")
+	}
+}
+
+type fakeNetBoxTarget struct {
+	datacenters []model.Datacenter
+	networks    []model.Network
+	pools       []model.NetworkPool
+	devices     []model.Device
+	saves       int
+	nextID      int
+}
+
+func (f *fakeNetBoxTarget) id(id *string) {
+	if *id == "" {
+		f.nextID++
+		*id = fmt.Sprintf("id-%d", f.nextID)
+	}
+	f.saves++
+}
+
+func (f *fakeNetBoxTarget) ListDatacenters(context.Context) ([]model.Datacenter, error) {
+	return f.datacenters, nil
+}
+
+func (f *fakeNetBoxTarget) ListNetworks(context.Context) ([]model.Network, error) {
+	return f.networks, nil
+}
+
+func (f *fakeNetBoxTarget) ListPools(_ context.Context, networkID string) ([]model.NetworkPool, error) {
+	var pools []model.NetworkPool
+	for _, p := range f.pools {
+		if p.NetworkID == networkID {
+			pools = append(pools, p)
+		}
+	}
+	return pools, nil
+}
+
+func (f *fakeNetBoxTarget) ListDevices(context.Context) ([]model.Device, error) {
+	return f.devices, nil
+}
+
+func (f *fakeNetBoxTarget) SaveDatacenter(_ context.Context, dc *model.Datacenter) error {
+	f.id(&dc.ID)
+	return nil
+}
+
+func (f *fakeNetBoxTarget) SaveNetwork(_ context.Context, n *model.Network) error {
+	f.id(&n.ID)
+	return nil
+}
+
+func (f *fakeNetBoxTarget) SavePool(_ context.Context, p *model.NetworkPool) error {
+	if p.NetworkID == "" {
+		return fmt.Errorf("pool without network")
+	}
+	f.id(&p.ID)
+	f.pools = append(f.pools, *p)
+	return nil
+}
+
+func (f *fakeNetBoxTarget) SaveDevice(_ context.Context, d *model.Device) error {
+	f.id(&d.ID)
+	f.devices = append(f.devices, *d)
+	return nil
+}
+
+func fetchTestImport(t *testing.T) *NetBoxImport {
+	t.Helper()
+	imp, err := NewNetBoxClient(newNetBoxServer(t).URL, "secret").Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	return imp
+}
+
+func TestApplyNetBox(t *testing.T) {
+	existing := func() *fakeNetBoxTarget {
+		return &fakeNetBoxTarget{
+			datacenters: []model.Datacenter{{ID: "dc-1", Name: "FRA1"}},
+			devices: []model.Device{{ID: "dev-1", Name: "web01", Tags: []string{"prod"},
+				Addresses: []model.Address{{IP: "192.168.1.10", Type: "ipv4"}}}},
+		}
+	}
+
+	t.Run("create and skip", func(t *testing.T) {
+		target := existing()
+		result, err := ApplyNetBox(context.Background(), target, fetchTestImport(t), ConflictSkip, false)
+		if err != nil {
+			t.Fatalf("ApplyNetBox failed: %v", err)
+		}
+		if result.Datacenters.Skipped != 1 || result.Networks.Created != 2 || result.Pools.Created != 1 {
+			t.Errorf("unexpected result: %+v", result)
+		}
+		if result.Devices.Created != 1 || result.Devices.Skipped != 1 {
+			t.Errorf("unexpected device result: %+v", result.Devices)
+		}
+		if len(target.pools) != 1 || target.pools[0].NetworkID == "" {
+			t.Fatalf("expected pool linked to created network, got %+v", target.pools)
+		}
+
+		spare := target.devices[len(target.devices)-1]
+		if spare.Name != "spare01" || spare.DatacenterID != "" {
+			t.Errorf("unexpected created device: %+v", spare)
+		}
+	})
+
+	t.Run("update merges into existing records", func(t *testing.T) {
+		target := existing()
+		result, err := ApplyNetBox(context.Background(), target, fetchTestImport(t), ConflictUpdate, false)
+		if err != nil {
+			t.Fatalf("ApplyNetBox failed: %v", err)
+		}
+		if result.Datacenters.Updated != 1 || result.Devices.Updated != 1 {
+			t.Errorf("unexpected result: %+v", result)
+		}
+		var web *model.Device
+		for i := range target.devices {
+			if target.devices[i].Name == "web01" && len(target.devices[i].Addresses) > 1 {
+				web = &target.devices[i]
+			}
+		}
+		if web == nil {
+			t.Fatalf("expected web01 to be updated with NetBox addresses, got %+v", target.devices)
+		}
+		if web.ID != "dev-1" || web.DatacenterID != "dc-1" || len(web.Tags) != 2 {
+			t.Errorf("unexpected updated device: %+v", web)
+		}
+		if web.Addresses[1].NetworkID == "" {
+			t.Error("expected NetBox address linked to the most specific network")
+		}
+	})
+
+	t.Run("fail aborts before changes", func(t *testing.T) {
+		target := existing()
+		_, err := ApplyNetBox(context.Background(), target, fetchTestImport(t), ConflictFail, false)
+		if err == nil || !strings.Contains(err.Error(), "datacenter fra1") {
+			t.Fatalf("expected conflict error, got %v", err)
+		}
+		if target.saves != 0 {
+			t.Errorf("expected no writes, got %d", target.saves)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		target := existing()
+		result, err := ApplyNetBox(context.Background(), target, fetchTestImport(t), ConflictUpdate, true)
+		if err != nil {
+			t.Fatalf("ApplyNetBox failed: %v", err)
+		}
+		if target.saves != 0 {
+			t.Errorf("expected no writes, got %d", target.saves)
+		}
+		if len(result.Actions) != 6 || result.Actions[0] != "update datacenter fra1" {
+			t.Errorf("unexpected planned actions: %v", result.Actions)
+		}
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		if _, err := ApplyNetBox(context.Background(), existing(), &NetBoxImport{}, "merge", false); err == nil {
+			t.Error("expected error for invalid conflict mode")
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package log
+
+import (
+	"context"
+
+	"github.com/paularlott/logger"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID, which Ctx adds
+// to every line logged through it
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Ctx returns the logger for ctx, tagging lines with the request ID so they
+// can be correlated with the access log
+func Ctx(ctx context.Context) logger.Logger {
+	if id := RequestID(ctx); id != "" {
+		return defaultLogger.With("request_id", id)
+	}
+	return defaultLogger
+}
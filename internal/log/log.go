@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,10 @@ import (
 
 var defaultLogger logger.Logger
 
+// outputLevel is the least severe level written out. Every level is still
+// captured for the recent log view.
+var outputLevel atomic.Int32
+
 const recentLogCapacity = 2000
 
 type capturingLogger struct {
@@ -26,12 +31,22 @@ type capturingLogger struct {
 }
 
 type recentLogStore struct {
-	mu      sync.RWMutex
-	entries []model.LogEntry
+	mu          sync.RWMutex
+	entries     []model.LogEntry
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	minLevel string
+	ch       chan model.LogEntry
 }
 
+// levels orders log levels from least to most severe
+var levels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
 var recentLogs = &recentLogStore{
-	entries: make([]model.LogEntry, 0, recentLogCapacity),
+	entries:     make([]model.LogEntry, 0, recentLogCapacity),
+	subscribers: make(map[*subscriber]struct{}),
 }
 
 func Init(logFormat string, logLevel string, writer io.Writer) {
@@ -42,13 +57,14 @@ func Init(logFormat string, logLevel string, writer io.Writer) {
 	if logFormat == "" {
 		logFormat = "console"
 	}
-	if logLevel == "" {
+	if !ValidLevel(logLevel) {
 		logLevel = "info"
 	}
 
 	recentLogs.clear()
+	SetLevel(logLevel)
 	base := logslog.New(logslog.Config{
-		Level:  logLevel,
+		Level:  "trace",
 		Format: logFormat,
 		Writer: writer,
 	})
@@ -110,29 +126,78 @@ func ClearRecentEntries() {
 	recentLogs.clear()
 }
 
+// Subscribe returns a channel that receives entries at or above minLevel as
+// they are logged, and a function that ends the subscription. Entries are
+// dropped rather than blocking the logger when the subscriber falls more than
+// buffer entries behind.
+func Subscribe(minLevel string, buffer int) (<-chan model.LogEntry, func()) {
+	return recentLogs.subscribe(minLevel, buffer)
+}
+
+// SetLevel changes the least severe level written out, including for
+// loggers already derived with With. Unknown levels are ignored.
+func SetLevel(level string) {
+	if i := levelIndex(level); i >= 0 {
+		outputLevel.Store(int32(i))
+	}
+}
+
+// ValidLevel reports whether level is a known log level
+func ValidLevel(level string) bool {
+	return levelIndex(level) >= 0
+}
+
+// LevelAtLeast reports whether level is at or above minLevel. An empty
+// minLevel matches every level.
+func LevelAtLeast(level, minLevel string) bool {
+	if minLevel == "" {
+		return true
+	}
+	return levelIndex(level) >= levelIndex(minLevel)
+}
+
+func levelIndex(level string) int {
+	for i, l := range levels {
+		if strings.EqualFold(l, level) {
+			return i
+		}
+	}
+	return -1
+}
+
 func (l *capturingLogger) Trace(msg string, keysAndValues ...any) {
 	l.capture("trace", msg, keysAndValues...)
-	l.inner.Trace(msg, keysAndValues...)
+	if l.enabled("trace") {
+		l.inner.Trace(msg, keysAndValues...)
+	}
 }
 
 func (l *capturingLogger) Debug(msg string, keysAndValues ...any) {
 	l.capture("debug", msg, keysAndValues...)
-	l.inner.Debug(msg, keysAndValues...)
+	if l.enabled("debug") {
+		l.inner.Debug(msg, keysAndValues...)
+	}
 }
 
 func (l *capturingLogger) Info(msg string, keysAndValues ...any) {
 	l.capture("info", msg, keysAndValues...)
-	l.inner.Info(msg, keysAndValues...)
+	if l.enabled("info") {
+		l.inner.Info(msg, keysAndValues...)
+	}
 }
 
 func (l *capturingLogger) Warn(msg string, keysAndValues ...any) {
 	l.capture("warn", msg, keysAndValues...)
-	l.inner.Warn(msg, keysAndValues...)
+	if l.enabled("warn") {
+		l.inner.Warn(msg, keysAndValues...)
+	}
 }
 
 func (l *capturingLogger) Error(msg string, keysAndValues ...any) {
 	l.capture("error", msg, keysAndValues...)
-	l.inner.Error(msg, keysAndValues...)
+	if l.enabled("error") {
+		l.inner.Error(msg, keysAndValues...)
+	}
 }
 
 func (l *capturingLogger) Fatal(msg string, keysAndValues ...any) {
@@ -140,6 +205,10 @@ func (l *capturingLogger) Fatal(msg string, keysAndValues ...any) {
 	l.inner.Fatal(msg, keysAndValues...)
 }
 
+func (l *capturingLogger) enabled(level string) bool {
+	return int32(levelIndex(level)) >= outputLevel.Load()
+}
+
 func (l *capturingLogger) With(key string, value any) logger.Logger {
 	fields := cloneFields(l.fields)
 	fields[key] = sanitizeField(key, stringifyValue(value))
@@ -251,6 +320,14 @@ func sanitizeField(key, value string) string {
 func (s *recentLogStore) add(entry model.LogEntry) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		if LevelAtLeast(entry.Level, sub.minLevel) {
+			select {
+			case sub.ch <- entry:
+			default:
+			}
+		}
+	}
 	if len(s.entries) == recentLogCapacity {
 		copy(s.entries, s.entries[1:])
 		s.entries[len(s.entries)-1] = entry
@@ -272,6 +349,9 @@ func (s *recentLogStore) list(filter *model.LogFilter) []model.LogEntry {
 			if filter.Level != "" && !strings.EqualFold(entry.Level, filter.Level) {
 				continue
 			}
+			if !LevelAtLeast(entry.Level, filter.MinLevel) {
+				continue
+			}
 			if filter.Source != "" && !strings.EqualFold(entry.Source, filter.Source) {
 				continue
 			}
@@ -332,6 +412,22 @@ func (s *recentLogStore) get(id string) (*model.LogEntry, bool) {
 	return nil, false
 }
 
+func (s *recentLogStore) subscribe(minLevel string, buffer int) (<-chan model.LogEntry, func()) {
+	sub := &subscriber{minLevel: minLevel, ch: make(chan model.LogEntry, buffer)}
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subscribers, sub)
+			s.mu.Unlock()
+		})
+	}
+}
+
 func (s *recentLogStore) clear() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
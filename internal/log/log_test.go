@@ -2,8 +2,11 @@ package log
 
 import (
 	"bytes"
+	"context"
 	"strings"
 	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
 )
 
 func TestInit(t *testing.T) {
@@ -120,6 +123,67 @@ func TestInitWithNilWriter(t *testing.T) {
 	}
 }
 
+func TestSubscribe(t *testing.T) {
+	Init("console", "debug", &bytes.Buffer{})
+
+	entries, cancel := Subscribe("warn", 1)
+	Info("below threshold")
+	Warn("first warning")
+	Error("dropped while subscriber is full")
+
+	entry := <-entries
+	if entry.Message != "first warning" {
+		t.Errorf("expected first warning, got %q", entry.Message)
+	}
+	select {
+	case extra := <-entries:
+		t.Errorf("expected entries beyond the buffer to be dropped, got %q", extra.Message)
+	default:
+	}
+
+	cancel()
+	cancel()
+	Error("after cancel")
+	select {
+	case extra := <-entries:
+		t.Errorf("expected no entries after cancel, got %q", extra.Message)
+	default:
+	}
+}
+
+func TestLevelAtLeast(t *testing.T) {
+	tests := []struct {
+		level, min string
+		want       bool
+	}{
+		{"error", "warn", true},
+		{"warn", "warn", true},
+		{"info", "warn", false},
+		{"debug", "", true},
+		{"WARN", "warn", true},
+	}
+	for _, tt := range tests {
+		if got := LevelAtLeast(tt.level, tt.min); got != tt.want {
+			t.Errorf("LevelAtLeast(%q, %q) = %v, want %v", tt.level, tt.min, got, tt.want)
+		}
+	}
+	if ValidLevel("loud") || !ValidLevel("Error") {
+		t.Error("ValidLevel returned unexpected result")
+	}
+}
+
+func TestListRecentEntries_MinLevel(t *testing.T) {
+	Init("console", "debug", &bytes.Buffer{})
+	Debug("debug entry")
+	Warn("warn entry")
+	Error("error entry")
+
+	entries := ListRecentEntries(&model.LogFilter{MinLevel: "warn"})
+	if len(entries) != 2 || entries[0].Message != "error entry" {
+		t.Fatalf("expected warn and error entries newest first, got %+v", entries)
+	}
+}
+
 type testError struct {
 	msg string
 }
@@ -127,3 +191,43 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.msg
 }
+
+func TestCtxAddsRequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	Init("json", "info", buf)
+
+	Ctx(WithRequestID(context.Background(), "req-42")).Info("storage entry")
+	if !strings.Contains(buf.String(), `"request_id":"req-42"`) {
+		t.Fatalf("expected the request ID in the log line, got %q", buf.String())
+	}
+
+	buf.Reset()
+	Ctx(context.Background()).Info("plain entry")
+	if strings.Contains(buf.String(), "request_id") {
+		t.Fatalf("expected no request ID without one in the context, got %q", buf.String())
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	Init("console", "info", buf)
+	derived := With("component", "test")
+
+	Debug("hidden debug")
+	SetLevel("debug")
+	derived.Debug("visible debug")
+	SetLevel("bogus")
+	Debug("still visible")
+
+	output := buf.String()
+	if strings.Contains(output, "hidden debug") {
+		t.Error("expected debug to be filtered at info")
+	}
+	if !strings.Contains(output, "visible debug") || !strings.Contains(output, "still visible") {
+		t.Errorf("expected debug after SetLevel, got %q", output)
+	}
+	if len(ListRecentEntries(&model.LogFilter{})) != 3 {
+		t.Error("expected every level to be captured")
+	}
+	Init("console", "info", buf)
+}
@@ -0,0 +1,18 @@
+package mcp
+
+import (
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/paularlott/mcp"
+)
+
+const cascadeUsage = "What to do with dependent records when the item is purged from the trash: detach (default) unlinks them, true deletes them too, deny refuses while any exist"
+
+// mcpDeletePolicy reads the cascade parameter of a delete tool
+func mcpDeletePolicy(req *mcp.ToolRequest) (model.DeletePolicy, error) {
+	cascade, _ := req.String("cascade")
+	policy, ok := model.ParseDeletePolicy(cascade)
+	if !ok {
+		return "", mcp.NewToolErrorInvalidParams("cascade must be true, detach or deny")
+	}
+	return policy, nil
+}
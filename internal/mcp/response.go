@@ -0,0 +1,26 @@
+package mcp
+
+import (
+	"encoding/json"
+
+	"github.com/paularlott/mcp"
+)
+
+// jsonResponse returns data both as JSON text, for clients that only read
+// content blocks, and as structured content so agents get the fields without
+// re-parsing the text. Structured content must be an object, so lists and
+// other non-object values are wrapped under "items".
+func jsonResponse(data interface{}) *mcp.ToolResponse {
+	resp := mcp.NewToolResponseJSON(data)
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return resp
+	}
+	var structured map[string]interface{}
+	if err := json.Unmarshal(raw, &structured); err != nil || structured == nil {
+		structured = map[string]interface{}{"items": json.RawMessage(raw)}
+	}
+	resp.StructuredContent = structured
+	return resp
+}
@@ -58,6 +58,11 @@ func (s *Server) registerTools() {
 	s.registerConflictTools()
 	s.registerAuditTools()
 	s.registerDNSTools()
+	s.registerCloudTools()
+	s.registerHypervisorTools()
+	s.registerMonitorTools()
+	s.registerTrashTools()
+	s.registerReportTools()
 }
 
 func (s *Server) HandleRequest(w http.ResponseWriter, r *http.Request) {
@@ -110,6 +115,11 @@ func (s *Server) HandleRequest(w http.ResponseWriter, r *http.Request) {
 		}
 
 		r = r.WithContext(service.WithCaller(r.Context(), caller))
+		// MCP has no change requests, so in approval mode these callers
+		// can only read
+		if s.svc.Changes != nil && s.svc.Changes.RequiresApproval(r.Context()) {
+			caller.ReadOnly = true
+		}
 	} else {
 		r = r.WithContext(service.SystemContext(r.Context(), "mcp"))
 	}
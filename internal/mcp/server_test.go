@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/martinsuchenak/rackd/internal/auth"
@@ -245,6 +248,7 @@ func TestToolsRegistered(t *testing.T) {
 		"network_list",
 		"network_get",
 		"pool_get_next_ip",
+		"device_status",
 	}
 
 	toolNames := make(map[string]bool)
@@ -311,6 +315,31 @@ func TestDeviceSave_Create(t *testing.T) {
 	}
 }
 
+func TestDeviceSave_MACAddressWarnings(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	save := func(name, ip string) string {
+		t.Helper()
+		resp := callTool(t, srv, "device_save", map[string]interface{}{
+			"name":      name,
+			"addresses": []map[string]interface{}{{"ip": ip, "type": "ipv4", "mac_address": "AA:BB:CC:00:00:01"}},
+		})
+		if resp["error"] != nil {
+			t.Fatalf("unexpected error: %v", resp["error"])
+		}
+		content := resp["result"].(map[string]interface{})["content"].([]interface{})
+		return content[0].(map[string]interface{})["text"].(string)
+	}
+
+	if text := save("host", "10.0.0.1"); strings.Contains(text, "warnings") || !strings.Contains(text, `"mac_address":"aa:bb:cc:00:00:01"`) {
+		t.Errorf("expected normalized MAC and no warnings, got %s", text)
+	}
+	if text := save("vm", "10.0.0.2"); !strings.Contains(text, "is also used by device host") {
+		t.Errorf("expected duplicate MAC warning, got %s", text)
+	}
+}
+
 func TestDeviceList(t *testing.T) {
 	srv, store := newTestServer(t)
 	defer store.Close()
@@ -327,6 +356,76 @@ func TestDeviceList(t *testing.T) {
 	}
 }
 
+func TestDeviceList_StructuredQuery(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	callTool(t, srv, "device_save", map[string]interface{}{
+		"name": "query-prod", "tags": []string{"prod"},
+		"addresses": []map[string]interface{}{{"ip": "10.1.0.5", "type": "ipv4"}},
+	})
+	callTool(t, srv, "device_save", map[string]interface{}{
+		"name": "query-decom", "tags": []string{"prod", "decom"},
+		"addresses": []map[string]interface{}{{"ip": "10.1.0.6", "type": "ipv4"}},
+	})
+
+	resp := callTool(t, srv, "device_list", map[string]interface{}{"query": "tag:prod ip:10.1.0.0/24 -tag:decom"})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	text := resp["result"].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	if !strings.Contains(text, "query-prod") || strings.Contains(text, "query-decom") {
+		t.Errorf("expected only query-prod, got %s", text)
+	}
+}
+
+func TestStructuredContent(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	callTool(t, srv, "device_save", map[string]interface{}{"name": "structured-device", "tags": []string{"prod"}})
+
+	resp := callTool(t, srv, "device_list", map[string]interface{}{})
+	structured, ok := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected structured content for device_list, got %v", resp["result"])
+	}
+	items, _ := structured["items"].([]interface{})
+	if len(items) != 1 || structured["has_more"] != false {
+		t.Fatalf("expected one device and pagination fields, got %v", structured)
+	}
+	id := items[0].(map[string]interface{})["id"].(string)
+
+	resp = callTool(t, srv, "device_get", map[string]interface{}{"id": id})
+	structured, _ = resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	if structured["name"] != "structured-device" {
+		t.Errorf("expected device fields in structured content, got %v", structured)
+	}
+
+	// Search results are a list, so they are wrapped under items
+	resp = callTool(t, srv, "device_list", map[string]interface{}{"query": "tag:prod"})
+	structured, _ = resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	if items, _ := structured["items"].([]interface{}); len(items) != 1 {
+		t.Errorf("expected search results wrapped under items, got %v", structured)
+	}
+}
+
+func TestDeviceStatus(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	resp := callTool(t, srv, "device_status", map[string]interface{}{})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+
+	resp = callTool(t, srv, "device_status", map[string]interface{}{"id": "missing"})
+	result, _ := resp["result"].(map[string]interface{})
+	if resp["error"] == nil && (result == nil || result["isError"] != true) {
+		t.Errorf("expected error for unknown device, got %v", resp)
+	}
+}
+
 func TestDatacenterSave_Create(t *testing.T) {
 	srv, store := newTestServer(t)
 	defer store.Close()
@@ -357,6 +456,31 @@ func TestDatacenterList(t *testing.T) {
 	}
 }
 
+func TestDatacenterGet_IncludesContacts(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	dc := &model.Datacenter{Name: "contacts-dc", AccessInstructions: "Call ahead"}
+	if err := store.CreateDatacenter(context.Background(), dc); err != nil {
+		t.Fatalf("CreateDatacenter failed: %v", err)
+	}
+	resp := callTool(t, srv, "datacenter_contact_save", map[string]interface{}{
+		"datacenter_id": dc.ID, "name": "Remote Hands Desk", "role": "remote hands", "phone": "+1 555 0100",
+	})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+
+	resp = callTool(t, srv, "datacenter_get", map[string]interface{}{"id": dc.ID})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	text := resp["result"].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	if !strings.Contains(text, "Remote Hands Desk") || !strings.Contains(text, "Call ahead") {
+		t.Errorf("expected contacts and access instructions, got %s", text)
+	}
+}
+
 func TestNetworkSave_Create(t *testing.T) {
 	srv, store := newTestServer(t)
 	defer store.Close()
@@ -388,6 +512,22 @@ func TestNetworkList(t *testing.T) {
 	}
 }
 
+func TestNetworkList_AsOf(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	resp := callTool(t, srv, "network_list", map[string]interface{}{"as_of": "2000-01-01T00:00:00Z"})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+
+	resp = callTool(t, srv, "network_list", map[string]interface{}{"as_of": "last week"})
+	result, _ := resp["result"].(map[string]interface{})
+	if resp["error"] == nil && (result == nil || result["isError"] != true) {
+		t.Errorf("expected error for invalid as_of, got %v", resp)
+	}
+}
+
 func TestDiscoveryScan(t *testing.T) {
 	srv, store := newTestServer(t)
 	defer store.Close()
@@ -492,6 +632,115 @@ func TestGetRelationships(t *testing.T) {
 	}
 }
 
+func TestDeviceImpact(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	app := &model.Device{Name: "app"}
+	db := &model.Device{Name: "db"}
+	store.CreateDevice(ctx, app)
+	store.CreateDevice(ctx, db)
+	store.AddRelationship(ctx, app.ID, db.ID, model.RelationshipDependsOn, "")
+
+	resp := callTool(t, srv, "device_impact", map[string]interface{}{
+		"id":    db.ID,
+		"depth": 2,
+	})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	structured := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	affected, _ := structured["affected"].([]interface{})
+	if len(affected) != 1 || affected[0].(map[string]interface{})["device_id"] != app.ID {
+		t.Errorf("expected app to be affected, got %v", structured)
+	}
+}
+
+func TestDeviceWarrantyExpiring(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	at := func(days int) string { return time.Now().UTC().AddDate(0, 0, days).Format(time.RFC3339) }
+	callTool(t, srv, "device_save", map[string]interface{}{"name": "b-later", "warranty_expiry": at(60)})
+	callTool(t, srv, "device_save", map[string]interface{}{"name": "a-sooner", "warranty_expiry": at(20)})
+	callTool(t, srv, "device_save", map[string]interface{}{"name": "c-next-year", "warranty_expiry": at(300)})
+
+	resp := callTool(t, srv, "device_warranty_expiring", map[string]interface{}{})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	structured := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	items, _ := structured["items"].([]interface{})
+	if len(items) != 2 || items[0].(map[string]interface{})["name"] != "a-sooner" {
+		t.Errorf("expected the two warranties within 90 days, soonest first, got %v", items)
+	}
+
+	resp = callTool(t, srv, "device_save", map[string]interface{}{"name": "bad", "warranty_expiry": "soon"})
+	if resp["error"] == nil {
+		t.Error("expected an error for an invalid date")
+	}
+}
+
+func TestDatacenterDeleteCascade(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "dc1"}
+	store.CreateDatacenter(ctx, dc)
+	device := &model.Device{Name: "web-01", DatacenterID: dc.ID}
+	store.CreateDevice(ctx, device)
+
+	resp := callTool(t, srv, "datacenter_delete", map[string]interface{}{"id": dc.ID, "cascade": "deny"})
+	if resp["error"] == nil && resp["result"].(map[string]interface{})["isError"] != true {
+		t.Fatal("expected deny to refuse a datacenter with devices")
+	}
+
+	resp = callTool(t, srv, "datacenter_delete", map[string]interface{}{"id": dc.ID, "cascade": "true"})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	if _, err := store.GetDevice(ctx, device.ID); err != nil {
+		t.Errorf("expected the device to stay until the datacenter is purged, got %v", err)
+	}
+	if err := store.PurgeTrashItem(ctx, dc.ID); err != nil {
+		t.Fatalf("PurgeTrashItem failed: %v", err)
+	}
+	if _, err := store.GetDevice(ctx, device.ID); err == nil {
+		t.Error("expected cascade to delete the device on purge")
+	}
+}
+
+func TestTrashListAndRestore(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	device := &model.Device{Name: "web-01"}
+	store.CreateDevice(ctx, device)
+
+	resp := callTool(t, srv, "device_delete", map[string]interface{}{"id": device.ID})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+
+	resp = callTool(t, srv, "trash_list", map[string]interface{}{"type": "device"})
+	content := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	items := content["items"].([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["id"] != device.ID {
+		t.Fatalf("expected the device in the trash, got %v", content)
+	}
+
+	resp = callTool(t, srv, "trash_restore", map[string]interface{}{"id": device.ID})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	if _, err := store.GetDevice(ctx, device.ID); err != nil {
+		t.Errorf("expected the device to be restored, got %v", err)
+	}
+}
+
 func TestInner(t *testing.T) {
 	srv, store := newTestServer(t)
 	defer store.Close()
@@ -501,3 +750,256 @@ func TestInner(t *testing.T) {
 		t.Fatal("Inner() should return the underlying MCP server")
 	}
 }
+
+func TestDeviceMerge(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	callTool(t, srv, "device_save", map[string]interface{}{"name": "keep", "tags": []string{"web"}})
+	callTool(t, srv, "device_save", map[string]interface{}{"name": "duplicate", "tags": []string{"scanned"}})
+	devices, _ := store.ListDevices(context.Background(), nil)
+	if len(devices) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(devices))
+	}
+	ids := map[string]string{}
+	for _, d := range devices {
+		ids[d.Name] = d.ID
+	}
+
+	resp := callTool(t, srv, "device_merge", map[string]interface{}{
+		"id":     ids["keep"],
+		"source": ids["duplicate"],
+	})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+
+	resp = callTool(t, srv, "device_duplicates", map[string]interface{}{})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+
+	source, err := store.GetDevice(context.Background(), ids["duplicate"])
+	if err != nil {
+		t.Fatalf("GetDevice failed: %v", err)
+	}
+	if source.Status != model.DeviceStatusDecommissioned {
+		t.Errorf("expected duplicate to be decommissioned, got %q", source.Status)
+	}
+}
+
+func TestDevicesBulkSave(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	callTool(t, srv, "device_save", map[string]interface{}{"name": "existing"})
+	devices, _ := store.ListDevices(context.Background(), nil)
+	if len(devices) != 1 {
+		t.Fatalf("expected 1 device, got %d", len(devices))
+	}
+
+	resp := callTool(t, srv, "devices_bulk_save", map[string]interface{}{
+		"devices": []map[string]interface{}{
+			{"name": "row-1", "tags": []string{"imported"}, "addresses": []map[string]interface{}{{"ip": "10.9.0.1", "type": "ipv4"}}},
+			{"hostname": "missing-name"},
+			{"id": devices[0].ID, "name": "existing", "os": "debian"},
+		},
+	})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+
+	structured := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	if structured["created"] != float64(1) || structured["updated"] != float64(1) || structured["failed"] != float64(1) {
+		t.Fatalf("expected 1 created, 1 updated and 1 failed, got %v", structured)
+	}
+	results := structured["results"].([]interface{})
+	if r := results[0].(map[string]interface{}); r["status"] != "created" || r["id"] == nil {
+		t.Errorf("expected the first row to be created with an ID, got %v", r)
+	}
+	if r := results[1].(map[string]interface{}); r["status"] != "failed" || r["error"] == nil {
+		t.Errorf("expected the row without a name to fail, got %v", r)
+	}
+
+	updated, err := store.GetDevice(context.Background(), devices[0].ID)
+	if err != nil || updated.OS != "debian" {
+		t.Errorf("expected the existing device to be updated, got %v (err %v)", updated, err)
+	}
+
+	tooMany := make([]map[string]interface{}, maxBulkSaveDevices+1)
+	for i := range tooMany {
+		tooMany[i] = map[string]interface{}{"name": fmt.Sprintf("bulk-%d", i)}
+	}
+	resp = callTool(t, srv, "devices_bulk_save", map[string]interface{}{"devices": tooMany})
+	if resp["error"] == nil {
+		t.Error("expected an error for more than the maximum number of devices")
+	}
+}
+
+func TestPoolGetNextIP_Allocates(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "alloc-net", Subnet: "10.8.0.0/24"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "alloc-pool", StartIP: "10.8.0.10", EndIP: "10.8.0.11"}
+	if err := store.CreateNetworkPool(ctx, pool); err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+
+	// Each call reserves its IP, so the next call gets the one after
+	for _, want := range []string{"10.8.0.10", "10.8.0.11"} {
+		resp := callTool(t, srv, "pool_get_next_ip", map[string]interface{}{"pool_id": pool.ID, "ttl_seconds": 120})
+		if resp["error"] != nil {
+			t.Fatalf("unexpected error: %v", resp["error"])
+		}
+		structured := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+		if structured["ip"] != want {
+			t.Errorf("expected %s, got %v", want, structured["ip"])
+		}
+	}
+
+	reserved, err := store.GetReservationsByPool(ctx, pool.ID)
+	if err != nil || len(reserved) != 2 {
+		t.Fatalf("expected 2 active reservations, got %d (err %v)", len(reserved), err)
+	}
+
+	resp := callTool(t, srv, "pool_get_next_ip", map[string]interface{}{"pool_id": pool.ID})
+	if resp["error"] == nil && resp["result"].(map[string]interface{})["isError"] != true {
+		t.Error("expected an error once the pool is exhausted")
+	}
+}
+
+func TestNetworkAllocateSubnet(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	supernet := &model.Network{Name: "super", Subnet: "10.9.0.0/22"}
+	if err := store.CreateNetwork(ctx, supernet); err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	if err := store.CreateNetwork(ctx, &model.Network{Name: "existing", Subnet: "10.9.0.0/24"}); err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+
+	resp := callTool(t, srv, "network_allocate_subnet", map[string]interface{}{"network_id": supernet.ID, "prefix_length": 24, "name": "app"})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	structured := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	if structured["subnet"] != "10.9.1.0/24" || structured["parent_id"] != supernet.ID {
+		t.Errorf("expected 10.9.1.0/24 under %s, got %v", supernet.ID, structured)
+	}
+
+	resp = callTool(t, srv, "network_allocate_subnet", map[string]interface{}{"network_id": supernet.ID, "prefix_length": 22})
+	if resp["error"] == nil && resp["result"].(map[string]interface{})["isError"] != true {
+		t.Error("expected an error for a prefix length that does not fit")
+	}
+}
+
+func TestReportRun(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	store.CreateDevice(context.Background(), &model.Device{Name: "web-01", OS: "Debian 12"})
+
+	resp := callTool(t, srv, "report_run", map[string]interface{}{"name": "os-distribution"})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	content := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	rows := content["rows"].([]interface{})
+	if len(rows) != 1 || rows[0].([]interface{})[0] != "Debian 12" {
+		t.Errorf("expected one Debian 12 row, got %v", content)
+	}
+}
+
+func TestReportExposure(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "DC-1"}
+	store.CreateDatacenter(ctx, dc)
+	store.CreateDevice(ctx, &model.Device{Name: "sw-01", DatacenterID: dc.ID, Addresses: []model.Address{{IP: "10.0.0.2", Type: "ipv4"}}})
+	store.RecordPortObservations(ctx, "scan-1", &model.DiscoveredDevice{IP: "10.0.0.2", OpenPorts: []int{23}}, nil)
+
+	resp := callTool(t, srv, "report_exposure", map[string]interface{}{"datacenter": "DC-1"})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	content := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	rows := content["rows"].([]interface{})
+	if len(rows) != 1 || rows[0].([]interface{})[6] != "telnet" {
+		t.Errorf("expected telnet on sw-01, got %v", content)
+	}
+}
+
+func TestTopologyQuery(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "DC-1"}
+	store.CreateDatacenter(ctx, dc)
+	db := &model.Device{Name: "db-01", Tags: []string{"db"}}
+	app := &model.Device{Name: "app-01", DatacenterID: dc.ID}
+	store.CreateDevice(ctx, db)
+	store.CreateDevice(ctx, app)
+	store.AddRelationship(ctx, app.ID, db.ID, model.RelationshipDependsOn, "")
+
+	resp := callTool(t, srv, "topology_query", map[string]interface{}{"query": "devices[tag:db].parents(depends_on).datacenter"})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	content := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	paths := content["paths"].([]interface{})
+	if len(paths) != 1 || paths[0].([]interface{})[2].(map[string]interface{})["label"] != "DC-1" {
+		t.Errorf("expected db-01 > app-01 > DC-1, got %v", content)
+	}
+}
+
+func TestDeviceFindFree(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "DC-1"}
+	store.CreateDatacenter(ctx, dc)
+	store.CreateDevice(ctx, &model.Device{Name: "web-01", DatacenterID: dc.ID, Location: "Row A, Rack 01, U1-U40"})
+	store.CreateDevice(ctx, &model.Device{Name: "web-02", DatacenterID: dc.ID, Location: "Row A, Rack 02, U1"})
+	network := &model.Network{Name: "prod", Subnet: "10.7.0.0/24", DatacenterID: dc.ID}
+	store.CreateNetwork(ctx, network)
+	store.CreateNetworkPool(ctx, &model.NetworkPool{NetworkID: network.ID, Name: "mgmt", StartIP: "10.7.0.10", EndIP: "10.7.0.20", Tags: []string{"mgmt"}})
+	store.CreateNetworkPool(ctx, &model.NetworkPool{NetworkID: network.ID, Name: "servers", StartIP: "10.7.0.100", EndIP: "10.7.0.200", Tags: []string{"web"}})
+
+	resp := callTool(t, srv, "device_find_free", map[string]interface{}{"network": "prod", "units": 2, "tags": []string{"web"}})
+	if resp["error"] != nil {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	content := resp["result"].(map[string]interface{})["structuredContent"].(map[string]interface{})
+	racks := content["racks"].([]interface{})
+	if content["datacenter_id"] != dc.ID || len(racks) != 2 || racks[0].(map[string]interface{})["location"] != "Row A, Rack 01, U41-U42" {
+		t.Errorf("expected rack 01 U41-U42 first, got %v", content)
+	}
+	address := content["address"].(map[string]interface{})
+	if address["ip"] != "10.7.0.100" || address["pool_name"] != "servers" {
+		t.Errorf("expected 10.7.0.100 from the servers pool, got %v", address)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"units": 2},
+		{"datacenter": "nowhere"},
+		{"datacenter": dc.ID, "units": 50},
+	} {
+		resp = callTool(t, srv, "device_find_free", args)
+		respErr, _ := resp["error"].(map[string]interface{})
+		if respErr == nil || respErr["code"] != float64(-32602) {
+			t.Errorf("expected an invalid params error for %v, got %v", args, resp)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/paularlott/mcp"
+
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// ServeStdio serves MCP over newline-delimited JSON-RPC on in and out, the
+// transport desktop clients use when they launch rackd themselves. Whoever
+// can run the process can already open the database, so requests run as the
+// system caller rather than authenticating. It returns once in is closed.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx = service.SystemContext(ctx, "mcp-stdio")
+	reader := bufio.NewReader(in)
+	protocolVersion := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			resp := s.handleStdioMessage(ctx, line, &protocolVersion)
+			if resp != nil {
+				if _, werr := out.Write(append(resp, '\n')); werr != nil {
+					return werr
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handleStdioMessage runs one JSON-RPC message through the MCP server and
+// returns the response line, or nil for notifications, which get no reply
+func (s *Server) handleStdioMessage(ctx context.Context, msg []byte, protocolVersion *string) []byte {
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return stdioError(nil, mcp.ErrorCodeParseError, "Parse error")
+	}
+	if len(envelope.ID) == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/mcp", bytes.NewReader(msg))
+	if err != nil {
+		return stdioError(envelope.ID, mcp.ErrorCodeInternalError, err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if *protocolVersion != "" {
+		req.Header.Set("MCP-Protocol-Version", *protocolVersion)
+	}
+
+	w := &stdioResponseWriter{header: http.Header{}, status: http.StatusOK}
+	s.mcpServer.HandleRequest(w, req)
+
+	// Transport-level failures come back as plain HTTP errors rather than
+	// JSON-RPC, so wrap them for the client
+	if w.status >= http.StatusBadRequest {
+		return stdioError(envelope.ID, mcp.ErrorCodeInvalidRequest, strings.TrimSpace(w.body.String()))
+	}
+
+	if envelope.Method == "initialize" {
+		var resp struct {
+			Result struct {
+				ProtocolVersion string `json:"protocolVersion"`
+			} `json:"result"`
+		}
+		if json.Unmarshal(w.body.Bytes(), &resp) == nil && resp.Result.ProtocolVersion != "" {
+			*protocolVersion = resp.Result.ProtocolVersion
+		}
+	}
+	return bytes.TrimSpace(w.body.Bytes())
+}
+
+func stdioError(id json.RawMessage, code int, message string) []byte {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	resp, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   map[string]interface{}{"code": code, "message": message},
+	})
+	return resp
+}
+
+// stdioResponseWriter captures the response the MCP server writes for one
+// message
+type stdioResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *stdioResponseWriter) Header() http.Header { return w.header }
+
+func (w *stdioResponseWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+
+func (w *stdioResponseWriter) WriteHeader(status int) { w.status = status }
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestServeStdio(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"device_save","arguments":{"name":"stdio-device"}}}`,
+		`not json`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := srv.ServeStdio(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 responses (no reply to the notification), got %d: %s", len(lines), out.String())
+	}
+
+	var resps []map[string]interface{}
+	for _, line := range lines {
+		var resp map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("response is not JSON: %q", line)
+		}
+		resps = append(resps, resp)
+	}
+
+	if resps[0]["result"] == nil {
+		t.Errorf("expected initialize result, got %v", resps[0])
+	}
+	if resps[1]["error"] != nil || !strings.Contains(lines[1], "stdio-device") {
+		t.Errorf("expected device_save to succeed, got %s", lines[1])
+	}
+	if resps[2]["error"] == nil {
+		t.Errorf("expected a parse error for invalid input, got %v", resps[2])
+	}
+}
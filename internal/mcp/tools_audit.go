@@ -56,5 +56,5 @@ func (s *Server) handleAuditList(ctx context.Context, req *mcp.ToolRequest) (*mc
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(entries), nil
+	return jsonResponse(entries), nil
 }
@@ -78,7 +78,7 @@ func (s *Server) handleCircuitList(ctx context.Context, req *mcp.ToolRequest) (*
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(circuits, len(circuits), pg)), nil
+	return jsonResponse(paginatedResponse(circuits, len(circuits), pg)), nil
 }
 
 func (s *Server) handleCircuitGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -87,7 +87,7 @@ func (s *Server) handleCircuitGet(ctx context.Context, req *mcp.ToolRequest) (*m
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(circuit), nil
+	return jsonResponse(circuit), nil
 }
 
 func (s *Server) handleCircuitSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -123,7 +123,7 @@ func (s *Server) handleCircuitSave(ctx context.Context, req *mcp.ToolRequest) (*
 		if err != nil {
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
-		return mcp.NewToolResponseJSON(circuit), nil
+		return jsonResponse(circuit), nil
 	}
 
 	// Update
@@ -147,7 +147,7 @@ func (s *Server) handleCircuitSave(ctx context.Context, req *mcp.ToolRequest) (*
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(circuit), nil
+	return jsonResponse(circuit), nil
 }
 
 func (s *Server) handleCircuitDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -155,5 +155,5 @@ func (s *Server) handleCircuitDelete(ctx context.Context, req *mcp.ToolRequest)
 	if err := s.svc.Circuits.Delete(ctx, id); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
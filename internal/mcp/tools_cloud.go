@@ -0,0 +1,24 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/paularlott/mcp"
+)
+
+func (s *Server) registerCloudTools() {
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("cloud_sync", "Sync servers from cloud providers (Hetzner Cloud/Robot, AWS EC2) into the device inventory. Returns created, updated and missing devices per provider",
+			mcp.String("provider", "Provider to sync (hetzner, hetzner-robot, aws, k8s-<cluster>). Syncs all configured providers when omitted"),
+		).Discoverable("cloud", "sync", "hetzner", "aws", "ec2", "import", "hybrid"),
+		s.handleCloudSync,
+	)
+}
+
+func (s *Server) handleCloudSync(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	results, err := s.svc.Cloud.Sync(ctx, req.StringOr("provider", ""))
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(results), nil
+}
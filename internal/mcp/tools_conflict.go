@@ -46,7 +46,7 @@ func (s *Server) handleConflictList(ctx context.Context, req *mcp.ToolRequest) (
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(conflicts, len(conflicts), pg)), nil
+	return jsonResponse(paginatedResponse(conflicts, len(conflicts), pg)), nil
 }
 
 func (s *Server) handleConflictDetect(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -58,7 +58,7 @@ func (s *Server) handleConflictDetect(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]interface{}{
+	return jsonResponse(map[string]interface{}{
 		"duplicate_ips":       dupIPs,
 		"overlapping_subnets": overlapping,
 	}), nil
@@ -73,5 +73,5 @@ func (s *Server) handleConflictResolve(ctx context.Context, req *mcp.ToolRequest
 	if err := s.svc.Conflicts.Resolve(ctx, resolution); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "resolved", "conflict_id": conflictID}), nil
+	return jsonResponse(map[string]string{"status": "resolved", "conflict_id": conflictID}), nil
 }
@@ -45,13 +45,13 @@ func (s *Server) registerCustomFieldTools() {
 
 func (s *Server) handleCustomFieldList(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	if s.svc.CustomFields == nil {
-		return mcp.NewToolResponseJSON([]interface{}{}), nil
+		return jsonResponse([]interface{}{}), nil
 	}
 	fields, err := s.svc.CustomFields.ListDefinitions(ctx, nil)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(fields), nil
+	return jsonResponse(fields), nil
 }
 
 func (s *Server) handleCustomFieldGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -63,7 +63,7 @@ func (s *Server) handleCustomFieldGet(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(field), nil
+	return jsonResponse(field), nil
 }
 
 func (s *Server) handleCustomFieldSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -89,7 +89,7 @@ func (s *Server) handleCustomFieldSave(ctx context.Context, req *mcp.ToolRequest
 		if err != nil {
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
-		return mcp.NewToolResponseJSON(field), nil
+		return jsonResponse(field), nil
 	}
 
 	updateReq := &model.UpdateCustomFieldDefinitionRequest{
@@ -107,7 +107,7 @@ func (s *Server) handleCustomFieldSave(ctx context.Context, req *mcp.ToolRequest
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(field), nil
+	return jsonResponse(field), nil
 }
 
 func (s *Server) handleCustomFieldDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -118,5 +118,5 @@ func (s *Server) handleCustomFieldDelete(ctx context.Context, req *mcp.ToolReque
 	if err := s.svc.CustomFields.DeleteDefinition(ctx, id); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
@@ -2,10 +2,15 @@ package mcp
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/paularlott/mcp"
 
 	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
 )
 
 func (s *Server) registerSearchTools() {
@@ -21,12 +26,14 @@ func (s *Server) registerDeviceTools() {
 	// Native tools — core daily use
 	s.mcpServer.RegisterTool(
 		mcp.NewTool("device_list", "List devices with optional filters",
-			mcp.String("query", "Search query"),
+			mcp.String("query", "Search query: plain text, or structured terms such as `tag:prod os:ubuntu ip:10.1.0.0/16 -tag:decom` with OR, NOT and parentheses (fields: name, hostname, description, os, model, location, username, status, kind, tag, domain, ip, mac, network, datacenter). Other filters are ignored when set"),
 			mcp.StringArray("tags", "Filter by tags"),
 			mcp.String("datacenter_id", "Filter by datacenter"),
 			mcp.String("network_id", "Filter by network"),
 			mcp.String("pool_id", "Filter by IP pool"),
-			mcp.String("status", "Filter by status (planned, active, maintenance, decommissioned)"),
+			mcp.String("status", "Filter by status (planned, ordered, active, maintenance, decommissioned)"),
+			mcp.String("kind", "Filter by kind (physical, vm, container_host, network, storage, pdu, other)"),
+			mcp.String("host_id", "Filter to the virtual machines hosted on this device"),
 			mcp.Number("limit", "Max results to return (default 100, max 1000)"),
 			mcp.Number("offset", "Number of results to skip for pagination"),
 		),
@@ -34,8 +41,8 @@ func (s *Server) registerDeviceTools() {
 	)
 
 	s.mcpServer.RegisterTool(
-		mcp.NewTool("device_get", "Get a device by ID",
-			mcp.String("id", "Device ID", mcp.Required()),
+		mcp.NewTool("device_get", "Get a device by ID or name, with the SSH host key fingerprints discovery collected from its addresses",
+			mcp.String("id", "Device ID or name", mcp.Required()),
 		),
 		s.handleDeviceGet,
 	)
@@ -48,24 +55,31 @@ func (s *Server) registerDeviceTools() {
 			mcp.String("description", "Device description"),
 			mcp.String("make_model", "Device make and model"),
 			mcp.String("os", "Operating system"),
-			mcp.String("status", "Status (planned, active, maintenance, decommissioned)"),
+			mcp.String("kind", "Kind (physical, vm, container_host, network, storage, pdu, other; default physical)"),
+			mcp.String("status", "Status (planned, ordered, active, maintenance, decommissioned)"),
 			mcp.String("datacenter_id", "Datacenter ID"),
 			mcp.String("username", "Login username"),
 			mcp.String("location", "Physical location"),
+			mcp.String("purchase_date", "Purchase date, RFC3339"),
+			mcp.String("warranty_expiry", "Warranty expiry date, RFC3339"),
+			mcp.String("end_of_life", "Vendor end-of-life date, RFC3339"),
 			mcp.StringArray("tags", "Device tags"),
-			mcp.ObjectArray("addresses", "IP addresses", mcp.String("ip", "IP address"), mcp.String("type", "Address type")),
+			mcp.ObjectArray("interfaces", "Network interfaces; bond members and VLANs name their parent", mcp.String("name", "Interface name, such as eth0 or bond0"), mcp.String("type", "ethernet, bond, vlan or virtual (default ethernet)"), mcp.String("mac_address", "MAC address"), mcp.Number("speed_mbps", "Link speed in Mbps"), mcp.String("switch_port", "Switch port the interface is cabled to"), mcp.Number("vlan", "VLAN ID"), mcp.String("parent", "Name of the bond or interface this one belongs to")),
+			mcp.ObjectArray("addresses", "IP addresses", mcp.String("ip", "IP address"), mcp.String("type", "Address type"), mcp.String("mac_address", "MAC address"), mcp.String("pool_id", "IP pool ID; without an ip, the pool's next free IP is assigned"), mcp.String("interface", "Name of the interface the address is on")),
 			mcp.StringArray("domains", "Domain names"),
 			mcp.ObjectArray("custom_fields", "Custom field values",
 				mcp.String("field_id", "Custom field definition ID"),
 				mcp.String("value", "Field value"),
 			),
+			mcp.Boolean("dry_run", "Validate and return the normalized device without saving it"),
 		),
 		s.handleDeviceSave,
 	)
 
 	s.mcpServer.RegisterTool(
-		mcp.NewTool("device_delete", "Delete a device",
+		mcp.NewTool("device_delete", "Delete a device. It goes to the trash and can be restored with trash_restore.",
 			mcp.String("id", "Device ID", mcp.Required()),
+			mcp.String("cascade", cascadeUsage),
 		),
 		s.handleDeviceDelete,
 	)
@@ -75,7 +89,7 @@ func (s *Server) registerDeviceTools() {
 		mcp.NewTool("device_add_relationship", "Add a relationship between devices",
 			mcp.String("parent_id", "Parent device ID", mcp.Required()),
 			mcp.String("child_id", "Child device ID", mcp.Required()),
-			mcp.String("type", "Relationship type (contains, connected_to, depends_on)", mcp.Required()),
+			mcp.String("type", "Relationship type (contains, connected_to, depends_on, hosted_on). hosted_on puts a vm (parent) on its hypervisor or container host (child)", mcp.Required()),
 			mcp.String("notes", "Optional notes"),
 		).Discoverable("device", "relationship", "link", "connect", "dependency"),
 		s.handleAddRelationship,
@@ -88,6 +102,94 @@ func (s *Server) registerDeviceTools() {
 		s.handleGetRelationships,
 	)
 
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("device_impact", "Show what would be affected if a device went down, and what the device itself depends on, by following depends_on, hosted_on and connected_to relationships",
+			mcp.String("id", "Device ID", mcp.Required()),
+			mcp.Number("depth", "Maximum number of hops to follow (default 5, max 10)"),
+		).Discoverable("device", "impact", "dependency", "outage", "blast radius"),
+		s.handleDeviceImpact,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("topology_query", "Answer multi-hop questions in one call by walking a path through devices, relationships, networks and datacenters, e.g. devices[tag:db].parents(depends_on).datacenter for the datacenters of the devices that depend on database servers. Steps are joined by dots: start with devices, networks or datacenters; from a device go to parents, children (optionally of relationship types in parentheses), networks or datacenter; from a network to devices or datacenter; from a datacenter to devices or networks. Device steps take a search query in square brackets, network and datacenter steps a name or ID. Returns one chain of nodes per match.",
+			mcp.String("query", "Path to walk", mcp.Required()),
+		).Discoverable("topology", "relationship", "dependency", "path", "traverse", "graph", "multi-hop"),
+		s.handleTopologyQuery,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("device_find_free", "Suggest where to place a new device: racks in the datacenter with enough contiguous free units, tightest fit first, and the next free IP of a pool in the network, or any network of the datacenter, that has the tags. Racks are read from device locations such as \"Row A, Rack 07, U12\"; each suggestion includes the location to give the device. The IP is not reserved; reserve it with pool_get_next_ip or assign it with device_save.",
+			mcp.String("datacenter", "Datacenter ID or name; defaults to the network's datacenter"),
+			mcp.Number("units", "Rack units the device needs (default 1)"),
+			mcp.Number("rack_units", "Height of the racks in units (default 42)"),
+			mcp.String("network", "Network ID or name to take the IP from"),
+			mcp.StringArray("tags", "Tags the IP pool must have"),
+		).Discoverable("device", "placement", "provision", "rack", "free space", "rack units", "free ip", "capacity"),
+		s.handleDeviceFindFree,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("device_vms", "List the virtual machines hosted on a device",
+			mcp.String("id", "Host device ID", mcp.Required()),
+			mcp.Number("limit", "Max results to return (default 100, max 1000)"),
+			mcp.Number("offset", "Number of results to skip for pagination"),
+		).Discoverable("device", "vm", "virtual machine", "hypervisor", "host", "guest"),
+		s.handleDeviceVMs,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("device_duplicates", "Find pairs of devices that look like the same host: a shared MAC address, an IP on the same network, or the same name ignoring case, domain and separators. The older device of each pair is suggested to keep; merge with device_merge.").Discoverable("device", "duplicate", "deduplicate", "merge", "same host"),
+		s.handleDeviceDuplicates,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("device_merge", "Merge a duplicate device into another. The kept device gains the duplicate's addresses, tags, domains and relationships; the duplicate is decommissioned.",
+			mcp.String("id", "ID of the device to keep", mcp.Required()),
+			mcp.String("source", "ID of the duplicate device to merge and decommission", mcp.Required()),
+		).Discoverable("device", "merge", "duplicate", "deduplicate", "combine"),
+		s.handleDeviceMerge,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("device_warranty_expiring", "List devices whose warranty is still running but expires within the given number of days, soonest first",
+			mcp.Number("days", "Days ahead to look (default 90)"),
+			mcp.Number("limit", "Max results to return (default 100, max 1000)"),
+			mcp.Number("offset", "Number of results to skip for pagination"),
+		).Discoverable("device", "warranty", "expiry", "lifecycle", "renewal", "support"),
+		s.handleDeviceWarrantyExpiring,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("devices_bulk_save", "Create or update up to 100 devices in one call, e.g. rows converted from a spreadsheet. Each item takes the same fields as device_save; items with an id are updated. Every item is saved on its own and reported in results, so one bad row does not stop the rest.",
+			mcp.ObjectArray("devices", "Devices to save",
+				mcp.String("id", "Device ID (omit for new device)"),
+				mcp.String("name", "Device name"),
+				mcp.String("hostname", "Hostname"),
+				mcp.String("description", "Device description"),
+				mcp.String("make_model", "Device make and model"),
+				mcp.String("os", "Operating system"),
+				mcp.String("kind", "Kind (physical, vm, container_host, network, storage, pdu, other; default physical)"),
+				mcp.String("status", "Status (planned, ordered, active, maintenance, decommissioned)"),
+				mcp.String("datacenter_id", "Datacenter ID"),
+				mcp.String("username", "Login username"),
+				mcp.String("location", "Physical location"),
+				mcp.String("purchase_date", "Purchase date, RFC3339"),
+				mcp.String("warranty_expiry", "Warranty expiry date, RFC3339"),
+				mcp.String("end_of_life", "Vendor end-of-life date, RFC3339"),
+				mcp.StringArray("tags", "Device tags"),
+				mcp.ObjectArray("interfaces", "Network interfaces; bond members and VLANs name their parent", mcp.String("name", "Interface name, such as eth0 or bond0"), mcp.String("type", "ethernet, bond, vlan or virtual (default ethernet)"), mcp.String("mac_address", "MAC address"), mcp.Number("speed_mbps", "Link speed in Mbps"), mcp.String("switch_port", "Switch port the interface is cabled to"), mcp.Number("vlan", "VLAN ID"), mcp.String("parent", "Name of the bond or interface this one belongs to")),
+				mcp.ObjectArray("addresses", "IP addresses", mcp.String("ip", "IP address"), mcp.String("type", "Address type"), mcp.String("mac_address", "MAC address"), mcp.String("pool_id", "IP pool ID; without an ip, the pool's next free IP is assigned"), mcp.String("interface", "Name of the interface the address is on")),
+				mcp.StringArray("domains", "Domain names"),
+				mcp.ObjectArray("custom_fields", "Custom field values",
+					mcp.String("field_id", "Custom field definition ID"),
+					mcp.String("value", "Field value"),
+				),
+				mcp.Required(),
+			),
+		).Discoverable("device", "bulk", "batch", "import", "spreadsheet", "csv", "many"),
+		s.handleDevicesBulkSave,
+	)
+
 	s.mcpServer.RegisterTool(
 		mcp.NewTool("device_get_custom_fields", "Get custom field values with definitions for a device",
 			mcp.String("id", "Device ID", mcp.Required()),
@@ -100,10 +202,19 @@ func (s *Server) registerDeviceTools() {
 
 func (s *Server) handleSearch(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	query, _ := req.String("query")
-	devices, _ := s.svc.Devices.Search(ctx, query)
-	networks, _ := s.svc.Networks.Search(ctx, query)
-	datacenters, _ := s.svc.Datacenters.Search(ctx, query)
-	return mcp.NewToolResponseJSON(map[string]interface{}{
+	devices, err := s.svc.Devices.Search(ctx, query)
+	if errors.Is(err, service.ErrQueryTooBroad) {
+		return nil, queryTooBroadError(err)
+	}
+	networks, err := s.svc.Networks.Search(ctx, query)
+	if errors.Is(err, service.ErrQueryTooBroad) {
+		return nil, queryTooBroadError(err)
+	}
+	datacenters, err := s.svc.Datacenters.Search(ctx, query)
+	if errors.Is(err, service.ErrQueryTooBroad) {
+		return nil, queryTooBroadError(err)
+	}
+	return jsonResponse(map[string]interface{}{
 		"devices":     devices,
 		"networks":    networks,
 		"datacenters": datacenters,
@@ -112,14 +223,28 @@ func (s *Server) handleSearch(ctx context.Context, req *mcp.ToolRequest) (*mcp.T
 
 // Device handlers
 
+// queryTooBroadError reports a search over its cost limits together with the
+// hints, so the assistant can retry with a narrower query
+func queryTooBroadError(err error) error {
+	msg := err.Error()
+	var tooBroad *service.QueryTooBroadError
+	if errors.As(err, &tooBroad) && len(tooBroad.Hints) > 0 {
+		msg += "; " + strings.Join(tooBroad.Hints, "; ")
+	}
+	return mcp.NewToolErrorInvalidParams(msg)
+}
+
 func (s *Server) handleDeviceList(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	query := req.StringOr("query", "")
 	if query != "" {
 		devices, err := s.svc.Devices.Search(ctx, query)
+		if errors.Is(err, service.ErrQueryTooBroad) {
+			return nil, queryTooBroadError(err)
+		}
 		if err != nil {
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
-		return mcp.NewToolResponseJSON(devices), nil
+		return jsonResponse(devices), nil
 	}
 
 	filter := &model.DeviceFilter{
@@ -129,24 +254,81 @@ func (s *Server) handleDeviceList(ctx context.Context, req *mcp.ToolRequest) (*m
 		NetworkID:    req.StringOr("network_id", ""),
 		PoolID:       req.StringOr("pool_id", ""),
 		Status:       model.DeviceStatus(req.StringOr("status", "")),
+		Kind:         model.DeviceKind(req.StringOr("kind", "")),
+		HostID:       req.StringOr("host_id", ""),
 	}
 	devices, err := s.svc.Devices.List(ctx, filter)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(devices, len(devices), filter.Pagination)), nil
+	return jsonResponse(paginatedResponse(devices, len(devices), filter.Pagination)), nil
+}
+
+func (s *Server) handleDeviceVMs(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	id, _ := req.String("id")
+	pg := mcpPagination(req)
+	devices, err := s.svc.Devices.ListVMs(ctx, id, pg)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(paginatedResponse(devices, len(devices), pg)), nil
+}
+
+func (s *Server) handleDeviceWarrantyExpiring(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	days := req.IntOr("days", 90)
+	if days <= 0 {
+		return nil, mcp.NewToolErrorInvalidParams("days must be positive")
+	}
+	filter := &model.DeviceFilter{
+		Pagination:   mcpPagination(req),
+		WarrantyDays: days,
+	}
+	devices, err := s.svc.Devices.List(ctx, filter)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(paginatedResponse(devices, len(devices), filter.Pagination)), nil
 }
 
 func (s *Server) handleDeviceGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	id, _ := req.String("id")
-	device, err := s.svc.Devices.Get(ctx, id)
+	device, err := s.svc.Devices.Lookup(ctx, id)
+	var ambErr *service.AmbiguousNameError
+	if errors.As(err, &ambErr) {
+		// List the candidates so the assistant can retry with an ID
+		ids := make([]string, len(ambErr.Matches))
+		for i, match := range ambErr.Matches {
+			ids[i] = match.ID
+		}
+		return nil, mcp.NewToolErrorInvalidParams(fmt.Sprintf("%s, use one of these IDs: %s", err, strings.Join(ids, ", ")))
+	}
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(device), nil
+	return jsonResponse(device), nil
 }
 
 func (s *Server) handleDeviceSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	if req.BoolOr("dry_run", false) {
+		ctx = service.WithDryRun(ctx)
+	}
+	device, warnings, err := s.saveDevice(ctx, req)
+	if errors.Is(err, service.ErrPolicyViolation) {
+		// The message lists each violation so the assistant can correct them
+		return nil, mcp.NewToolErrorInvalidParams(err.Error())
+	}
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(struct {
+		*model.Device
+		Warnings []string `json:"warnings,omitempty"`
+	}{device, warnings}), nil
+}
+
+// saveDevice creates the device described by req, or updates it when req has
+// an ID, and returns it with warnings about likely mistakes such as shared MACs
+func (s *Server) saveDevice(ctx context.Context, req *mcp.ToolRequest) (*model.Device, []string, error) {
 	id := req.StringOr("id", "")
 	name, _ := req.String("name")
 
@@ -157,6 +339,7 @@ func (s *Server) handleDeviceSave(ctx context.Context, req *mcp.ToolRequest) (*m
 		Description:  req.StringOr("description", ""),
 		MakeModel:    req.StringOr("make_model", ""),
 		OS:           req.StringOr("os", ""),
+		Kind:         model.DeviceKind(req.StringOr("kind", "")),
 		Status:       model.DeviceStatus(req.StringOr("status", "")),
 		DatacenterID: req.StringOr("datacenter_id", ""),
 		Username:     req.StringOr("username", ""),
@@ -165,26 +348,58 @@ func (s *Server) handleDeviceSave(ctx context.Context, req *mcp.ToolRequest) (*m
 		Domains:      req.StringSliceOr("domains", []string{}),
 	}
 
+	for _, date := range []struct {
+		field string
+		dst   **time.Time
+	}{
+		{"purchase_date", &device.PurchaseDate},
+		{"warranty_expiry", &device.WarrantyExpiry},
+		{"end_of_life", &device.EndOfLife},
+	} {
+		if val := req.StringOr(date.field, ""); val != "" {
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return nil, nil, mcp.NewToolErrorInvalidParams(date.field + " must be RFC3339 format, e.g. 2026-01-31T00:00:00Z")
+			}
+			*date.dst = &t
+		}
+	}
+
+	for _, iface := range req.ObjectSliceOr("interfaces", nil) {
+		name, _ := iface["name"].(string)
+		ifaceType, _ := iface["type"].(string)
+		mac, _ := iface["mac_address"].(string)
+		speed, _ := iface["speed_mbps"].(float64)
+		switchPort, _ := iface["switch_port"].(string)
+		vlan, _ := iface["vlan"].(float64)
+		parent, _ := iface["parent"].(string)
+		device.Interfaces = append(device.Interfaces, model.Interface{Name: name, Type: ifaceType, MACAddress: mac,
+			SpeedMbps: int(speed), SwitchPort: switchPort, VLAN: int(vlan), Parent: parent})
+	}
+
 	for _, addr := range req.ObjectSliceOr("addresses", nil) {
 		ip, _ := addr["ip"].(string)
 		addrType, _ := addr["type"].(string)
-		if ip != "" {
-			device.Addresses = append(device.Addresses, model.Address{IP: ip, Type: addrType})
+		mac, _ := addr["mac_address"].(string)
+		poolID, _ := addr["pool_id"].(string)
+		iface, _ := addr["interface"].(string)
+		if ip != "" || poolID != "" {
+			device.Addresses = append(device.Addresses, model.Address{IP: ip, Type: addrType, MACAddress: mac, PoolID: poolID, Interface: iface})
 		}
 	}
 
 	if id == "" {
 		if err := s.svc.Devices.Create(ctx, device); err != nil {
-			return nil, mcp.NewToolErrorInternal(err.Error())
+			return nil, nil, err
 		}
 	} else {
 		if err := s.svc.Devices.Update(ctx, device); err != nil {
-			return nil, mcp.NewToolErrorInternal(err.Error())
+			return nil, nil, err
 		}
 	}
 
 	// Apply custom fields if provided
-	if cfRaw := req.ObjectSliceOr("custom_fields", nil); len(cfRaw) > 0 && s.svc.CustomFields != nil {
+	if cfRaw := req.ObjectSliceOr("custom_fields", nil); len(cfRaw) > 0 && s.svc.CustomFields != nil && !service.IsDryRun(ctx) {
 		var inputs []model.CustomFieldValueInput
 		for _, cf := range cfRaw {
 			fieldID, _ := cf["field_id"].(string)
@@ -198,15 +413,118 @@ func (s *Server) handleDeviceSave(ctx context.Context, req *mcp.ToolRequest) (*m
 		}
 	}
 
-	return mcp.NewToolResponseJSON(device), nil
+	// Shared MACs are allowed but usually a mistake, so point them out
+	var warnings []string
+	if duplicates, err := s.svc.Devices.DuplicateMACs(ctx, device); err == nil {
+		for _, dup := range duplicates {
+			warnings = append(warnings, dup.Warning())
+		}
+	}
+	return device, warnings, nil
+}
+
+// maxBulkSaveDevices matches the API's limit on bulk create and update
+const maxBulkSaveDevices = 100
+
+// bulkSaveResult reports the outcome of one devices_bulk_save item
+type bulkSaveResult struct {
+	Index    int      `json:"index"`
+	Name     string   `json:"name,omitempty"`
+	ID       string   `json:"id,omitempty"`
+	Status   string   `json:"status"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func (s *Server) handleDevicesBulkSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	items, err := req.ObjectSlice("devices")
+	if err != nil || len(items) == 0 {
+		return nil, mcp.NewToolErrorInvalidParams("devices must be a non-empty array of device objects")
+	}
+	if len(items) > maxBulkSaveDevices {
+		return nil, mcp.NewToolErrorInvalidParams(fmt.Sprintf("at most %d devices can be saved per call, got %d", maxBulkSaveDevices, len(items)))
+	}
+
+	results := make([]bulkSaveResult, 0, len(items))
+	created, updated, failed := 0, 0, 0
+	for i, item := range items {
+		itemReq := mcp.NewToolRequest(item)
+		result := bulkSaveResult{Index: i, Name: itemReq.StringOr("name", "")}
+		device, warnings, err := s.saveDevice(ctx, itemReq)
+		switch {
+		case err != nil:
+			result.Status = "failed"
+			result.Error = err.Error()
+			failed++
+		case itemReq.StringOr("id", "") == "":
+			result.Status = "created"
+			created++
+		default:
+			result.Status = "updated"
+			updated++
+		}
+		if err == nil {
+			result.ID = device.ID
+			result.Warnings = warnings
+		}
+		results = append(results, result)
+	}
+
+	return jsonResponse(map[string]interface{}{
+		"total":   len(items),
+		"created": created,
+		"updated": updated,
+		"failed":  failed,
+		"results": results,
+	}), nil
 }
 
 func (s *Server) handleDeviceDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	id, _ := req.String("id")
-	if err := s.svc.Devices.Delete(ctx, id); err != nil {
+	policy, err := mcpDeletePolicy(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.svc.Devices.Delete(ctx, id, policy); err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
+}
+
+func (s *Server) handleDeviceDuplicates(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	candidates, err := s.svc.Devices.Duplicates(ctx)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(candidates), nil
+}
+
+func (s *Server) handleDeviceFindFree(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	suggestion, err := s.svc.Devices.FindFree(ctx, model.PlacementRequest{
+		Datacenter: req.StringOr("datacenter", ""),
+		Network:    req.StringOr("network", ""),
+		Units:      req.IntOr("units", 0),
+		RackUnits:  req.IntOr("rack_units", 0),
+		Tags:       req.StringSliceOr("tags", nil),
+	})
+	if errors.Is(err, service.ErrValidation) || errors.Is(err, service.ErrNotFound) {
+		// The message names the unknown datacenter or network, or the bad units
+		return nil, mcp.NewToolErrorInvalidParams(err.Error())
+	}
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(suggestion), nil
+}
+
+func (s *Server) handleDeviceMerge(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	id, _ := req.String("id")
+	source, _ := req.String("source")
+	device, err := s.svc.Devices.Merge(ctx, id, source)
+	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(device), nil
 }
 
 func (s *Server) handleAddRelationship(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -215,14 +533,14 @@ func (s *Server) handleAddRelationship(ctx context.Context, req *mcp.ToolRequest
 	relType, _ := req.String("type")
 	notes := req.StringOr("notes", "")
 
-	if relType != model.RelationshipContains && relType != model.RelationshipConnectedTo && relType != model.RelationshipDependsOn {
-		return nil, mcp.NewToolErrorInvalidParams("type must be one of: contains, connected_to, depends_on")
+	if _, ok := model.LookupRelationshipType(relType); !ok {
+		return nil, mcp.NewToolErrorInvalidParams("type must be one of: " + strings.Join(model.RelationshipTypeNames(), ", "))
 	}
 
 	if err := s.svc.Relationships.Add(ctx, parentID, childID, relType, notes); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "created"}), nil
+	return jsonResponse(map[string]string{"status": "created"}), nil
 }
 
 func (s *Server) handleGetRelationships(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -231,17 +549,35 @@ func (s *Server) handleGetRelationships(ctx context.Context, req *mcp.ToolReques
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(rels), nil
+	return jsonResponse(rels), nil
+}
+
+func (s *Server) handleDeviceImpact(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	id, _ := req.String("id")
+	impact, err := s.svc.Relationships.Impact(ctx, id, req.IntOr("depth", 0))
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(impact), nil
+}
+
+func (s *Server) handleTopologyQuery(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	query, _ := req.String("query")
+	result, err := s.svc.Topology.Query(ctx, query)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(result), nil
 }
 
 func (s *Server) handleDeviceGetCustomFields(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	id, _ := req.String("id")
 	if s.svc.CustomFields == nil {
-		return mcp.NewToolResponseJSON([]interface{}{}), nil
+		return jsonResponse([]interface{}{}), nil
 	}
 	fields, err := s.svc.CustomFields.GetValuesWithDefinitions(ctx, id)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(fields), nil
+	return jsonResponse(fields), nil
 }
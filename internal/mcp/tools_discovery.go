@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"context"
+	"time"
 
 	"github.com/paularlott/mcp"
 
@@ -20,6 +21,7 @@ func (s *Server) registerDiscoveryTools() {
 	s.mcpServer.RegisterTool(
 		mcp.NewTool("discovery_list", "List discovered devices",
 			mcp.String("network_id", "Filter by network ID"),
+			mcp.Boolean("include_ignored", "Include ignored devices"),
 		).Discoverable("discovery", "scan", "list", "found", "detected"),
 		s.handleListDiscovered,
 	)
@@ -31,6 +33,31 @@ func (s *Server) registerDiscoveryTools() {
 		).Discoverable("discovery", "promote", "import", "inventory", "add"),
 		s.handlePromoteDevice,
 	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("discovery_ignore", "Ignore a discovered device, such as a DHCP guest, so it leaves the discovered device list and diff reports",
+			mcp.String("discovered_id", "Discovered device ID", mcp.Required()),
+			mcp.String("reason", "Why the device is ignored"),
+			mcp.String("until", "RFC 3339 time the ignore expires; ignored for good when omitted"),
+		).Discoverable("discovery", "ignore", "suppress", "hide", "transient"),
+		s.handleIgnoreDiscovered,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("discovery_diff", "Compare discovery results for a network with documented devices: undocumented hosts, missing devices, and attribute drift",
+			mcp.String("network_id", "Network ID", mcp.Required()),
+			mcp.Number("stale_days", "Days since last sighting before a documented device counts as missing (default 7)"),
+		).Discoverable("discovery", "diff", "drift", "undocumented", "missing", "reconcile", "audit"),
+		s.handleDiscoveryDiff,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("discovery_switch_ports", "Read LLDP/CDP neighbor tables from switches over SNMP, fill or verify the switch port of matched device addresses, and link devices to their switch",
+			mcp.String("credential_id", "SNMP credential ID", mcp.Required()),
+			mcp.String("tag", "Tag marking the switches to query (default switch)"),
+		).Discoverable("discovery", "switch", "port", "lldp", "cdp", "neighbor", "cabling"),
+		s.handleCollectSwitchPorts,
+	)
 }
 
 func (s *Server) handleStartScan(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -45,16 +72,16 @@ func (s *Server) handleStartScan(ctx context.Context, req *mcp.ToolRequest) (*mc
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(scan), nil
+	return jsonResponse(scan), nil
 }
 
 func (s *Server) handleListDiscovered(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	networkID := req.StringOr("network_id", "")
-	devices, err := s.svc.Discovery.ListDevices(ctx, networkID)
+	devices, err := s.svc.Discovery.ListDevices(ctx, networkID, req.BoolOr("include_ignored", false))
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(devices), nil
+	return jsonResponse(devices), nil
 }
 
 func (s *Server) handlePromoteDevice(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -71,5 +98,47 @@ func (s *Server) handlePromoteDevice(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(promoted), nil
+	return jsonResponse(promoted), nil
+}
+
+func (s *Server) handleIgnoreDiscovered(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	discoveredID, _ := req.String("discovered_id")
+	reason := req.StringOr("reason", "")
+
+	var until *time.Time
+	if v := req.StringOr("until", ""); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, mcp.NewToolErrorInvalidParams("until must be an RFC 3339 time")
+		}
+		until = &t
+	}
+
+	device, err := s.svc.Discovery.IgnoreDevice(ctx, discoveredID, reason, until)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(device), nil
+}
+
+func (s *Server) handleDiscoveryDiff(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	networkID, _ := req.String("network_id")
+	staleDays := req.IntOr("stale_days", 0)
+
+	diff, err := s.svc.Discovery.Diff(ctx, networkID, staleDays)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(diff), nil
+}
+
+func (s *Server) handleCollectSwitchPorts(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	credentialID, _ := req.String("credential_id")
+	tag := req.StringOr("tag", "")
+
+	report, err := s.svc.Discovery.CollectSwitchPorts(ctx, credentialID, tag)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(report), nil
 }
@@ -20,7 +20,7 @@ func (s *Server) handleDNSProviderList(ctx context.Context, req *mcp.ToolRequest
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(providers, len(providers), pg)), nil
+	return jsonResponse(paginatedResponse(providers, len(providers), pg)), nil
 }
 
 func (s *Server) handleDNSProviderGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -29,7 +29,7 @@ func (s *Server) handleDNSProviderGet(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(provider), nil
+	return jsonResponse(provider), nil
 }
 
 func (s *Server) handleDNSProviderSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -49,7 +49,7 @@ func (s *Server) handleDNSProviderSave(ctx context.Context, req *mcp.ToolRequest
 		if err != nil {
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
-		return mcp.NewToolResponseJSON(provider), nil
+		return jsonResponse(provider), nil
 	}
 
 	updateReq := &model.UpdateDNSProviderRequest{
@@ -71,7 +71,7 @@ func (s *Server) handleDNSProviderSave(ctx context.Context, req *mcp.ToolRequest
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(provider), nil
+	return jsonResponse(provider), nil
 }
 
 func (s *Server) handleDNSProviderDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -79,18 +79,18 @@ func (s *Server) handleDNSProviderDelete(ctx context.Context, req *mcp.ToolReque
 	if err := s.svc.DNS.DeleteProvider(ctx, id); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
 
 func (s *Server) handleDNSProviderTest(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	id, _ := req.String("id")
 	if err := s.svc.DNS.TestProvider(ctx, id); err != nil {
-		return mcp.NewToolResponseJSON(map[string]any{
+		return jsonResponse(map[string]any{
 			"success": false,
 			"error":   err.Error(),
 		}), nil
 	}
-	return mcp.NewToolResponseJSON(map[string]any{"success": true}), nil
+	return jsonResponse(map[string]any{"success": true}), nil
 }
 
 // Zone handlers
@@ -108,7 +108,7 @@ func (s *Server) handleDNSZoneList(ctx context.Context, req *mcp.ToolRequest) (*
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(zones, len(zones), pg)), nil
+	return jsonResponse(paginatedResponse(zones, len(zones), pg)), nil
 }
 
 func (s *Server) handleDNSZoneGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -117,7 +117,7 @@ func (s *Server) handleDNSZoneGet(ctx context.Context, req *mcp.ToolRequest) (*m
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(zone), nil
+	return jsonResponse(zone), nil
 }
 
 func (s *Server) handleDNSZoneSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -148,7 +148,7 @@ func (s *Server) handleDNSZoneSave(ctx context.Context, req *mcp.ToolRequest) (*
 		if err != nil {
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
-		return mcp.NewToolResponseJSON(zone), nil
+		return jsonResponse(zone), nil
 	}
 
 	updateReq := &model.UpdateDNSZoneRequest{Name: &name}
@@ -175,7 +175,7 @@ func (s *Server) handleDNSZoneSave(ctx context.Context, req *mcp.ToolRequest) (*
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(zone), nil
+	return jsonResponse(zone), nil
 }
 
 func (s *Server) handleDNSZoneDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -183,7 +183,7 @@ func (s *Server) handleDNSZoneDelete(ctx context.Context, req *mcp.ToolRequest)
 	if err := s.svc.DNS.DeleteZone(ctx, id); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
 
 func (s *Server) handleDNSZoneSync(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -192,7 +192,7 @@ func (s *Server) handleDNSZoneSync(ctx context.Context, req *mcp.ToolRequest) (*
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(result), nil
+	return jsonResponse(result), nil
 }
 
 func (s *Server) handleDNSZoneImport(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -201,7 +201,7 @@ func (s *Server) handleDNSZoneImport(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(result), nil
+	return jsonResponse(result), nil
 }
 
 // Record handlers
@@ -224,7 +224,7 @@ func (s *Server) handleDNSRecordList(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(records, len(records), pg)), nil
+	return jsonResponse(paginatedResponse(records, len(records), pg)), nil
 }
 
 func (s *Server) handleDNSRecordGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -233,7 +233,7 @@ func (s *Server) handleDNSRecordGet(ctx context.Context, req *mcp.ToolRequest) (
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(record), nil
+	return jsonResponse(record), nil
 }
 
 func (s *Server) handleDNSRecordSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -260,7 +260,7 @@ func (s *Server) handleDNSRecordSave(ctx context.Context, req *mcp.ToolRequest)
 		if err != nil {
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
-		return mcp.NewToolResponseJSON(record), nil
+		return jsonResponse(record), nil
 	}
 
 	updateReq := &model.UpdateDNSRecordRequest{
@@ -279,7 +279,7 @@ func (s *Server) handleDNSRecordSave(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(record), nil
+	return jsonResponse(record), nil
 }
 
 func (s *Server) handleDNSRecordDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -287,7 +287,7 @@ func (s *Server) handleDNSRecordDelete(ctx context.Context, req *mcp.ToolRequest
 	if err := s.svc.DNS.DeleteRecord(ctx, id); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
 
 func (s *Server) handleDNSRecordLink(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -304,5 +304,5 @@ func (s *Server) handleDNSRecordLink(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(record), nil
+	return jsonResponse(record), nil
 }
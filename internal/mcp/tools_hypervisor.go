@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/paularlott/mcp"
+)
+
+func (s *Server) registerHypervisorTools() {
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("hypervisor_sync", "Sync hosts and VMs from Proxmox VE and vSphere connectors into the device inventory, linking each VM to its host. Returns created, updated and missing devices per connector",
+			mcp.String("id", "Connector ID to sync. Syncs all enabled connectors when omitted"),
+		).Discoverable("hypervisor", "sync", "proxmox", "vsphere", "vcenter", "esxi", "vm", "import"),
+		s.handleHypervisorSync,
+	)
+}
+
+func (s *Server) handleHypervisorSync(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	if s.svc.Hypervisors == nil {
+		return nil, mcp.NewToolErrorInternal("hypervisor connectors require ENCRYPTION_KEY to be set")
+	}
+	if id := req.StringOr("id", ""); id != "" {
+		result, err := s.svc.Hypervisors.Sync(ctx, id)
+		if err != nil {
+			return nil, mcp.NewToolErrorInternal(err.Error())
+		}
+		return jsonResponse(result), nil
+	}
+	results, err := s.svc.Hypervisors.SyncAll(ctx)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(results), nil
+}
@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/paularlott/mcp"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// device_status is a native tool since "is it up?" is the most common question
+func (s *Server) registerMonitorTools() {
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("device_status", "Is it up? Get the availability of a device from its ping/TCP checks, or a summary of all monitored devices listing those that are down or degraded",
+			mcp.String("id", "Device ID. Returns the summary of all monitored devices when omitted"),
+		),
+		s.handleDeviceStatus,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("maintenance_list", "List maintenance windows, soonest start first. Devices in an active window are not reported as down or missing.",
+			mcp.String("device_id", "Windows on this device"),
+			mcp.StringArray("tags", "Windows on any of these tags"),
+			mcp.String("status", "Filter by status (active, upcoming, past, current). current is active and upcoming"),
+			mcp.Number("limit", "Max results to return (default 100, max 1000)"),
+			mcp.Number("offset", "Number of results to skip for pagination"),
+		).Discoverable("maintenance", "window", "downtime", "outage", "scheduled", "planned"),
+		s.handleMaintenanceList,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("maintenance_save", "Create or update a maintenance window on a device or on every device with a tag",
+			mcp.String("id", "Maintenance window ID (omit for new)"),
+			mcp.String("device_id", "Device under maintenance. Set either device_id or tag"),
+			mcp.String("tag", "Cover every device carrying this tag"),
+			mcp.String("starts_at", "Start time, RFC3339", mcp.Required()),
+			mcp.String("ends_at", "End time, RFC3339", mcp.Required()),
+			mcp.String("description", "What the maintenance is for"),
+		).Discoverable("maintenance", "window", "downtime", "schedule", "create", "update"),
+		s.handleMaintenanceSave,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("maintenance_delete", "Delete a maintenance window",
+			mcp.String("id", "Maintenance window ID", mcp.Required()),
+		).Discoverable("maintenance", "window", "downtime", "delete", "remove"),
+		s.handleMaintenanceDelete,
+	)
+}
+
+func (s *Server) handleDeviceStatus(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	if id := req.StringOr("id", ""); id != "" {
+		status, err := s.svc.Monitor.DeviceStatus(ctx, id)
+		if err != nil {
+			return nil, mcp.NewToolErrorInternal(err.Error())
+		}
+		return jsonResponse(status), nil
+	}
+
+	summary, err := s.svc.Monitor.Summary(ctx)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(summary), nil
+}
+
+func (s *Server) handleMaintenanceList(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	pg := mcpPagination(req)
+	filter := &model.MaintenanceWindowFilter{
+		Pagination: pg,
+		DeviceID:   req.StringOr("device_id", ""),
+		Tags:       req.StringSliceOr("tags", nil),
+		Status:     req.StringOr("status", ""),
+	}
+	windows, err := s.svc.Maintenance.List(ctx, filter)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(paginatedResponse(windows, len(windows), pg)), nil
+}
+
+func (s *Server) handleMaintenanceSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	startsAt, err := time.Parse(time.RFC3339, req.StringOr("starts_at", ""))
+	if err != nil {
+		return nil, mcp.NewToolErrorInvalidParams("starts_at must be RFC3339 format")
+	}
+	endsAt, err := time.Parse(time.RFC3339, req.StringOr("ends_at", ""))
+	if err != nil {
+		return nil, mcp.NewToolErrorInvalidParams("ends_at must be RFC3339 format")
+	}
+
+	window := &model.MaintenanceWindow{}
+	id := req.StringOr("id", "")
+	if id != "" {
+		if window, err = s.svc.Maintenance.Get(ctx, id); err != nil {
+			return nil, mcp.NewToolErrorInternal(err.Error())
+		}
+	}
+	window.DeviceID = req.StringOr("device_id", "")
+	window.Tag = req.StringOr("tag", "")
+	window.StartsAt = startsAt
+	window.EndsAt = endsAt
+	window.Description = req.StringOr("description", window.Description)
+
+	if id == "" {
+		err = s.svc.Maintenance.Create(ctx, window)
+	} else {
+		err = s.svc.Maintenance.Update(ctx, window)
+	}
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(window), nil
+}
+
+func (s *Server) handleMaintenanceDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	id, _ := req.String("id")
+	if err := s.svc.Maintenance.Delete(ctx, id); err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
+}
@@ -72,7 +72,7 @@ func (s *Server) handleNATList(ctx context.Context, req *mcp.ToolRequest) (*mcp.
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(mappings, len(mappings), pg)), nil
+	return jsonResponse(paginatedResponse(mappings, len(mappings), pg)), nil
 }
 
 func (s *Server) handleNATGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -81,7 +81,7 @@ func (s *Server) handleNATGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.T
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(mapping), nil
+	return jsonResponse(mapping), nil
 }
 
 func (s *Server) handleNATSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -109,7 +109,7 @@ func (s *Server) handleNATSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.
 		if err != nil {
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
-		return mcp.NewToolResponseJSON(mapping), nil
+		return jsonResponse(mapping), nil
 	}
 
 	// Update — only set fields that were provided
@@ -135,7 +135,7 @@ func (s *Server) handleNATSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(mapping), nil
+	return jsonResponse(mapping), nil
 }
 
 func (s *Server) handleNATDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -143,5 +143,5 @@ func (s *Server) handleNATDelete(ctx context.Context, req *mcp.ToolRequest) (*mc
 	if err := s.svc.NAT.Delete(ctx, id); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
@@ -2,10 +2,12 @@ package mcp
 
 import (
 	"context"
+	"time"
 
 	"github.com/paularlott/mcp"
 
 	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/service"
 )
 
 func (s *Server) registerNetworkTools() {
@@ -19,7 +21,7 @@ func (s *Server) registerNetworkTools() {
 	)
 
 	s.mcpServer.RegisterTool(
-		mcp.NewTool("datacenter_get", "Get a datacenter by ID",
+		mcp.NewTool("datacenter_get", "Get a datacenter by ID, with its shipping address, access instructions and contacts in escalation order",
 			mcp.String("id", "Datacenter ID", mcp.Required()),
 		),
 		s.handleDatacenterGet,
@@ -28,6 +30,7 @@ func (s *Server) registerNetworkTools() {
 	s.mcpServer.RegisterTool(
 		mcp.NewTool("network_list", "List all networks",
 			mcp.String("datacenter_id", "Filter by datacenter"),
+			mcp.String("as_of", "Show networks as they were at this time (RFC3339)"),
 			mcp.Number("limit", "Max results to return (default 100, max 1000)"),
 			mcp.Number("offset", "Number of results to skip for pagination"),
 		),
@@ -37,13 +40,18 @@ func (s *Server) registerNetworkTools() {
 	s.mcpServer.RegisterTool(
 		mcp.NewTool("network_get", "Get a network by ID",
 			mcp.String("id", "Network ID", mcp.Required()),
+			mcp.String("as_of", "Show the network as it was at this time (RFC3339)"),
 		),
 		s.handleNetworkGet,
 	)
 
 	s.mcpServer.RegisterTool(
-		mcp.NewTool("pool_get_next_ip", "Get the next available IP from a pool",
+		mcp.NewTool("pool_get_next_ip", "Allocate the next available IP from a pool, reserving it so no other caller gets the same IP",
 			mcp.String("pool_id", "Pool ID", mcp.Required()),
+			mcp.Number("ttl_seconds", "Seconds to hold the reservation (default 3600)"),
+			mcp.String("device_id", "Device the IP is reserved for"),
+			mcp.String("hostname", "Hostname the IP is reserved for"),
+			mcp.String("purpose", "Purpose of the reservation"),
 		),
 		s.handleGetNextIP,
 	)
@@ -55,13 +63,37 @@ func (s *Server) registerNetworkTools() {
 			mcp.String("name", "Datacenter name", mcp.Required()),
 			mcp.String("location", "Physical location"),
 			mcp.String("description", "Description"),
-		).Discoverable("datacenter", "create", "update", "location", "facility"),
+			mcp.String("shipping_address", "Address to ship parts to"),
+			mcp.String("access_instructions", "How to get into the site"),
+		).Discoverable("datacenter", "create", "update", "location", "facility", "shipping", "access"),
 		s.handleDatacenterSave,
 	)
 
 	s.mcpServer.RegisterTool(
-		mcp.NewTool("datacenter_delete", "Delete a datacenter",
+		mcp.NewTool("datacenter_contact_save", "Create or update a datacenter contact, such as remote hands or the facility NOC",
+			mcp.String("datacenter_id", "Datacenter ID", mcp.Required()),
+			mcp.String("id", "Contact ID (omit for new)"),
+			mcp.String("name", "Person or team name", mcp.Required()),
+			mcp.String("role", "Role, e.g. remote hands"),
+			mcp.String("email", "Email address"),
+			mcp.String("phone", "Phone number"),
+			mcp.Number("escalation_order", "Position in the escalation order, lowest is called first"),
+		).Discoverable("datacenter", "contact", "remote hands", "escalation", "on-call", "phone"),
+		s.handleDatacenterContactSave,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("datacenter_contact_delete", "Delete a datacenter contact",
+			mcp.String("datacenter_id", "Datacenter ID", mcp.Required()),
+			mcp.String("id", "Contact ID", mcp.Required()),
+		).Discoverable("datacenter", "contact", "delete", "remove"),
+		s.handleDatacenterContactDelete,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("datacenter_delete", "Delete a datacenter. It goes to the trash and can be restored with trash_restore.",
 			mcp.String("id", "Datacenter ID", mcp.Required()),
+			mcp.String("cascade", cascadeUsage),
 		).Discoverable("datacenter", "delete", "remove"),
 		s.handleDatacenterDelete,
 	)
@@ -73,14 +105,30 @@ func (s *Server) registerNetworkTools() {
 			mcp.String("subnet", "CIDR subnet (e.g., 192.168.1.0/24)", mcp.Required()),
 			mcp.String("datacenter_id", "Datacenter ID"),
 			mcp.Number("vlan_id", "VLAN ID"),
+			mcp.String("gateway", "Default gateway IP, inside the subnet"),
+			mcp.StringArray("dns_servers", "DNS server IPs handed out on the network"),
+			mcp.Number("mtu", "MTU, 0 for the default"),
 			mcp.String("description", "Description"),
-		).Discoverable("network", "subnet", "create", "update", "vlan"),
+			mcp.Boolean("dry_run", "Validate and return the network without saving it"),
+		).Discoverable("network", "subnet", "create", "update", "vlan", "gateway", "dns", "mtu"),
 		s.handleNetworkSave,
 	)
 
 	s.mcpServer.RegisterTool(
-		mcp.NewTool("network_delete", "Delete a network",
+		mcp.NewTool("network_allocate_subnet", "Create a network for the next free subnet of a given prefix length inside a supernet",
+			mcp.String("network_id", "Supernet network ID", mcp.Required()),
+			mcp.Number("prefix_length", "Prefix length of the new subnet (e.g., 24)", mcp.Required()),
+			mcp.String("name", "Name of the new network (defaults to its subnet)"),
+			mcp.Number("vlan_id", "VLAN ID"),
+			mcp.String("description", "Description"),
+		).Discoverable("network", "subnet", "allocate", "carve", "supernet", "cidr"),
+		s.handleNetworkAllocateSubnet,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("network_delete", "Delete a network. It goes to the trash and can be restored with trash_restore.",
 			mcp.String("id", "Network ID", mcp.Required()),
+			mcp.String("cascade", cascadeUsage),
 		).Discoverable("network", "delete", "remove"),
 		s.handleNetworkDelete,
 	)
@@ -88,7 +136,8 @@ func (s *Server) registerNetworkTools() {
 	s.mcpServer.RegisterTool(
 		mcp.NewTool("pool_list", "List IP pools for a network",
 			mcp.String("network_id", "Network ID", mcp.Required()),
-		).Discoverable("pool", "ip", "network", "list", "range"),
+			mcp.String("as_of", "Show pools as they were at this time (RFC3339)"),
+		).Discoverable("pool", "ip", "network", "list", "range", "history"),
 		s.handlePoolList,
 	)
 }
@@ -102,7 +151,7 @@ func (s *Server) handleDatacenterList(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(dcs, len(dcs), pg)), nil
+	return jsonResponse(paginatedResponse(dcs, len(dcs), pg)), nil
 }
 
 func (s *Server) handleDatacenterGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -111,7 +160,7 @@ func (s *Server) handleDatacenterGet(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(dc), nil
+	return jsonResponse(dc), nil
 }
 
 func (s *Server) handleDatacenterSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -119,10 +168,12 @@ func (s *Server) handleDatacenterSave(ctx context.Context, req *mcp.ToolRequest)
 	name, _ := req.String("name")
 
 	dc := &model.Datacenter{
-		ID:          id,
-		Name:        name,
-		Location:    req.StringOr("location", ""),
-		Description: req.StringOr("description", ""),
+		ID:                 id,
+		Name:               name,
+		Location:           req.StringOr("location", ""),
+		Description:        req.StringOr("description", ""),
+		ShippingAddress:    req.StringOr("shipping_address", ""),
+		AccessInstructions: req.StringOr("access_instructions", ""),
 	}
 
 	if id == "" {
@@ -134,15 +185,58 @@ func (s *Server) handleDatacenterSave(ctx context.Context, req *mcp.ToolRequest)
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
 	}
-	return mcp.NewToolResponseJSON(dc), nil
+	return jsonResponse(dc), nil
 }
 
 func (s *Server) handleDatacenterDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	id, _ := req.String("id")
-	if err := s.svc.Datacenters.Delete(ctx, id); err != nil {
+	policy, err := mcpDeletePolicy(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.svc.Datacenters.Delete(ctx, id, policy); err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
+}
+
+func (s *Server) handleDatacenterContactSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	datacenterID, _ := req.String("datacenter_id")
+	name, _ := req.String("name")
+	contact := &model.DatacenterContact{
+		ID:              req.StringOr("id", ""),
+		DatacenterID:    datacenterID,
+		Role:            req.StringOr("role", ""),
+		Name:            name,
+		Email:           req.StringOr("email", ""),
+		Phone:           req.StringOr("phone", ""),
+		EscalationOrder: req.IntOr("escalation_order", 0),
+	}
+
+	if contact.ID == "" {
+		if err := s.svc.Datacenters.CreateContact(ctx, contact); err != nil {
+			return nil, mcp.NewToolErrorInternal(err.Error())
+		}
+		return jsonResponse(contact), nil
+	}
+	existing, err := s.svc.Datacenters.GetContact(ctx, datacenterID, contact.ID)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	contact.CreatedAt = existing.CreatedAt
+	if err := s.svc.Datacenters.UpdateContact(ctx, contact); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(contact), nil
+}
+
+func (s *Server) handleDatacenterContactDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	datacenterID, _ := req.String("datacenter_id")
+	id, _ := req.String("id")
+	if err := s.svc.Datacenters.DeleteContact(ctx, datacenterID, id); err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
 
 // Network handlers
@@ -153,20 +247,38 @@ func (s *Server) handleNetworkList(ctx context.Context, req *mcp.ToolRequest) (*
 		Pagination:   pg,
 		DatacenterID: req.StringOr("datacenter_id", ""),
 	}
-	networks, err := s.svc.Networks.List(ctx, filter)
+	asOf, err := mcpAsOf(req)
+	if err != nil {
+		return nil, err
+	}
+	var networks []model.Network
+	if asOf != nil {
+		networks, err = s.svc.Networks.ListAsOf(ctx, *asOf, filter)
+	} else {
+		networks, err = s.svc.Networks.List(ctx, filter)
+	}
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(networks, len(networks), pg)), nil
+	return jsonResponse(paginatedResponse(networks, len(networks), pg)), nil
 }
 
 func (s *Server) handleNetworkGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	id, _ := req.String("id")
-	network, err := s.svc.Networks.Get(ctx, id)
+	asOf, err := mcpAsOf(req)
+	if err != nil {
+		return nil, err
+	}
+	var network *model.Network
+	if asOf != nil {
+		network, err = s.svc.Networks.GetAsOf(ctx, id, *asOf)
+	} else {
+		network, err = s.svc.Networks.Get(ctx, id)
+	}
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(network), nil
+	return jsonResponse(network), nil
 }
 
 func (s *Server) handleNetworkSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -180,9 +292,15 @@ func (s *Server) handleNetworkSave(ctx context.Context, req *mcp.ToolRequest) (*
 		Subnet:       subnet,
 		DatacenterID: req.StringOr("datacenter_id", ""),
 		VLANID:       req.IntOr("vlan_id", 0),
+		Gateway:      req.StringOr("gateway", ""),
+		DNSServers:   req.StringSliceOr("dns_servers", []string{}),
+		MTU:          req.IntOr("mtu", 0),
 		Description:  req.StringOr("description", ""),
 	}
 
+	if req.BoolOr("dry_run", false) {
+		ctx = service.WithDryRun(ctx)
+	}
 	if id == "" {
 		if err := s.svc.Networks.Create(ctx, network); err != nil {
 			return nil, mcp.NewToolErrorInternal(err.Error())
@@ -192,33 +310,78 @@ func (s *Server) handleNetworkSave(ctx context.Context, req *mcp.ToolRequest) (*
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
 	}
-	return mcp.NewToolResponseJSON(network), nil
+	return jsonResponse(network), nil
+}
+
+func (s *Server) handleNetworkAllocateSubnet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	networkID, _ := req.String("network_id")
+	network, err := s.svc.Networks.AllocateSubnet(ctx, networkID, &model.AllocateSubnetRequest{
+		PrefixLength: req.IntOr("prefix_length", 0),
+		Name:         req.StringOr("name", ""),
+		VLANID:       req.IntOr("vlan_id", 0),
+		Description:  req.StringOr("description", ""),
+	})
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(network), nil
 }
 
 func (s *Server) handleNetworkDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	id, _ := req.String("id")
-	if err := s.svc.Networks.Delete(ctx, id); err != nil {
+	policy, err := mcpDeletePolicy(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.svc.Networks.Delete(ctx, id, policy); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
 
 // Pool handlers
 
 func (s *Server) handlePoolList(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	networkID, _ := req.String("network_id")
-	pools, err := s.svc.Pools.ListByNetwork(ctx, networkID)
+	asOf, err := mcpAsOf(req)
+	if err != nil {
+		return nil, err
+	}
+	var pools []model.NetworkPool
+	if asOf != nil {
+		pools, err = s.svc.Pools.ListByNetworkAsOf(ctx, networkID, *asOf)
+	} else {
+		pools, err = s.svc.Pools.ListByNetwork(ctx, networkID)
+	}
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(pools), nil
+	return jsonResponse(pools), nil
+}
+
+// mcpAsOf reads the optional as_of parameter used for historical lookups
+func mcpAsOf(req *mcp.ToolRequest) (*time.Time, error) {
+	val := req.StringOr("as_of", "")
+	if val == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return nil, mcp.NewToolErrorInvalidParams("as_of must be RFC3339 format, e.g. 2026-01-31T00:00:00Z")
+	}
+	return &t, nil
 }
 
 func (s *Server) handleGetNextIP(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
 	poolID, _ := req.String("pool_id")
-	ip, err := s.svc.Pools.GetNextIP(ctx, poolID)
+	reservation, err := s.svc.Reservations.Allocate(ctx, poolID, &model.AllocateIPRequest{
+		TTLSeconds: req.IntOr("ttl_seconds", 0),
+		DeviceID:   req.StringOr("device_id", ""),
+		Hostname:   req.StringOr("hostname", ""),
+		Purpose:    req.StringOr("purpose", ""),
+	})
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"ip": ip}), nil
+	return jsonResponse(map[string]any{"ip": reservation.IPAddress, "reservation": reservation}), nil
 }
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/paularlott/mcp"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func (s *Server) registerReportTools() {
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("report_list", "List the canned inventory reports that report_run can run").Discoverable("report", "summary", "statistics"),
+		s.handleReportList,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("report_run", "Run a canned inventory report: devices-per-datacenter, os-distribution, ip-utilization, warranty-expiry, unscanned-networks or exposure. Returns columns and rows.",
+			mcp.String("name", "Report name", mcp.Required()),
+			mcp.Number("days", "Window in days for warranty-expiry (default 90) and unscanned-networks (default 30)"),
+			mcp.String("datacenter", "Datacenter ID or name, for exposure"),
+			mcp.String("tag", "Device tag, for exposure"),
+		).Discoverable("report", "summary", "statistics", "warranty", "utilization", "operating system", "unscanned"),
+		s.handleReportRun,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("report_exposure", "List the risky services (telnet, FTP, RDP, VNC, SMB, databases) discovery found open on device addresses not labelled as management, such as mgmt, oob or ipmi. Answers questions like \"what's exposed in DC-1?\". Returns columns and rows.",
+			mcp.String("datacenter", "Datacenter ID or name"),
+			mcp.String("tag", "Only devices with this tag"),
+		).Discoverable("exposure", "attack surface", "open ports", "security", "risky services", "telnet", "rdp", "database"),
+		s.handleReportExposure,
+	)
+}
+
+func (s *Server) handleReportList(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	return jsonResponse(s.svc.Reports.List()), nil
+}
+
+func (s *Server) handleReportRun(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	name, _ := req.String("name")
+	report, err := s.svc.Reports.Run(ctx, name, model.ReportParams{
+		Days:       req.IntOr("days", 0),
+		Datacenter: req.StringOr("datacenter", ""),
+		Tag:        req.StringOr("tag", ""),
+	})
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(report), nil
+}
+
+func (s *Server) handleReportExposure(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	report, err := s.svc.Reports.Run(ctx, "exposure", model.ReportParams{
+		Datacenter: req.StringOr("datacenter", ""),
+		Tag:        req.StringOr("tag", ""),
+	})
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(report), nil
+}
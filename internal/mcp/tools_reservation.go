@@ -78,7 +78,7 @@ func (s *Server) handleReservationList(ctx context.Context, req *mcp.ToolRequest
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(reservations, len(reservations), pg)), nil
+	return jsonResponse(paginatedResponse(reservations, len(reservations), pg)), nil
 }
 
 func (s *Server) handleReservationGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -87,7 +87,7 @@ func (s *Server) handleReservationGet(ctx context.Context, req *mcp.ToolRequest)
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(reservation), nil
+	return jsonResponse(reservation), nil
 }
 
 func (s *Server) handleReservationCreate(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -112,7 +112,7 @@ func (s *Server) handleReservationCreate(ctx context.Context, req *mcp.ToolReque
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(reservation), nil
+	return jsonResponse(reservation), nil
 }
 
 func (s *Server) handleReservationUpdate(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -135,7 +135,7 @@ func (s *Server) handleReservationUpdate(ctx context.Context, req *mcp.ToolReque
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(reservation), nil
+	return jsonResponse(reservation), nil
 }
 
 func (s *Server) handleReservationRelease(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -143,7 +143,7 @@ func (s *Server) handleReservationRelease(ctx context.Context, req *mcp.ToolRequ
 	if err := s.svc.Reservations.Release(ctx, id); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "released", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "released", "id": id}), nil
 }
 
 func (s *Server) handleReservationDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -151,5 +151,5 @@ func (s *Server) handleReservationDelete(ctx context.Context, req *mcp.ToolReque
 	if err := s.svc.Reservations.Delete(ctx, id); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
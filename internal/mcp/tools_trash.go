@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/paularlott/mcp"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// registerTrashTools adds listing and restoring of deleted items. Purging is
+// left to the API and the retention job so an agent cannot make a delete
+// permanent.
+func (s *Server) registerTrashTools() {
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("trash_list", "List deleted devices, networks and datacenters that can still be restored",
+			mcp.String("type", "Filter by type: device, network or datacenter"),
+			mcp.Number("limit", "Maximum number of items to return (default 100)"),
+			mcp.Number("offset", "Offset for pagination"),
+		).Discoverable("trash", "deleted", "restore", "undo", "recycle"),
+		s.handleTrashList,
+	)
+
+	s.mcpServer.RegisterTool(
+		mcp.NewTool("trash_restore", "Restore a deleted device, network or datacenter from the trash",
+			mcp.String("id", "ID of the deleted item", mcp.Required()),
+		).Discoverable("trash", "deleted", "restore", "undo", "undelete"),
+		s.handleTrashRestore,
+	)
+}
+
+func (s *Server) handleTrashList(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	pg := mcpPagination(req)
+	filter := &model.TrashFilter{Pagination: pg}
+	if t := req.StringOr("type", ""); t != "" {
+		filter.Types = []string{t}
+	}
+
+	items, err := s.svc.Trash.List(ctx, filter)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(paginatedResponse(items, len(items), pg)), nil
+}
+
+func (s *Server) handleTrashRestore(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+	id, _ := req.String("id")
+	item, err := s.svc.Trash.Restore(ctx, id)
+	if err != nil {
+		return nil, mcp.NewToolErrorInternal(err.Error())
+	}
+	return jsonResponse(item), nil
+}
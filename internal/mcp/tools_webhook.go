@@ -58,7 +58,7 @@ func (s *Server) handleWebhookList(ctx context.Context, req *mcp.ToolRequest) (*
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(paginatedResponse(webhooks, len(webhooks), pg)), nil
+	return jsonResponse(paginatedResponse(webhooks, len(webhooks), pg)), nil
 }
 
 func (s *Server) handleWebhookGet(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -67,7 +67,7 @@ func (s *Server) handleWebhookGet(ctx context.Context, req *mcp.ToolRequest) (*m
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(webhook), nil
+	return jsonResponse(webhook), nil
 }
 
 func (s *Server) handleWebhookSave(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -95,7 +95,7 @@ func (s *Server) handleWebhookSave(ctx context.Context, req *mcp.ToolRequest) (*
 		if err != nil {
 			return nil, mcp.NewToolErrorInternal(err.Error())
 		}
-		return mcp.NewToolResponseJSON(webhook), nil
+		return jsonResponse(webhook), nil
 	}
 
 	active := req.BoolOr("active", true)
@@ -118,7 +118,7 @@ func (s *Server) handleWebhookSave(ctx context.Context, req *mcp.ToolRequest) (*
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(webhook), nil
+	return jsonResponse(webhook), nil
 }
 
 func (s *Server) handleWebhookDelete(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -126,7 +126,7 @@ func (s *Server) handleWebhookDelete(ctx context.Context, req *mcp.ToolRequest)
 	if err := s.svc.Webhooks.Delete(ctx, id); err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(map[string]string{"status": "deleted", "id": id}), nil
+	return jsonResponse(map[string]string{"status": "deleted", "id": id}), nil
 }
 
 func (s *Server) handleWebhookPing(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
@@ -135,5 +135,5 @@ func (s *Server) handleWebhookPing(ctx context.Context, req *mcp.ToolRequest) (*
 	if err != nil {
 		return nil, mcp.NewToolErrorInternal(err.Error())
 	}
-	return mcp.NewToolResponseJSON(delivery), nil
+	return jsonResponse(delivery), nil
 }
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// AgentOfflineAfter is how long after its last heartbeat an agent is
+// reported as offline
+const AgentOfflineAfter = 5 * time.Minute
+
+// Agent is a remote scanner that runs discovery at a site the central
+// server cannot reach and pushes results back over the API
+type Agent struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	NetworkIDs    []string   `json:"network_ids"`
+	Hostname      string     `json:"hostname,omitempty"`
+	Version       string     `json:"version,omitempty"`
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"`
+	Online        bool       `json:"online"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// IsOnline reports whether the agent has sent a heartbeat recently
+func (a *Agent) IsOnline(now time.Time) bool {
+	return a.LastHeartbeat != nil && now.Sub(*a.LastHeartbeat) < AgentOfflineAfter
+}
+
+// AgentRegistration is returned when an agent is registered or its token is
+// rotated. The token is only shown once.
+type AgentRegistration struct {
+	Agent *Agent `json:"agent"`
+	Token string `json:"token"`
+}
+
+// AgentHeartbeat is sent periodically by a running agent
+type AgentHeartbeat struct {
+	Hostname string `json:"hostname"`
+	Version  string `json:"version"`
+}
+
+// AgentNetwork is a network assigned to an agent with its discovery rule
+type AgentNetwork struct {
+	Network Network        `json:"network"`
+	Rule    *DiscoveryRule `json:"rule,omitempty"`
+}
+
+// AgentConfig is returned to an agent on heartbeat and lists the networks it
+// should scan
+type AgentConfig struct {
+	AgentID  string         `json:"agent_id"`
+	Name     string         `json:"name"`
+	Networks []AgentNetwork `json:"networks"`
+}
+
+// AgentScanResult is pushed by an agent after scanning one network
+type AgentScanResult struct {
+	NetworkID  string             `json:"network_id"`
+	ScanType   string             `json:"scan_type"`
+	StartedAt  *time.Time         `json:"started_at,omitempty"`
+	TotalHosts int                `json:"total_hosts"`
+	Error      string             `json:"error,omitempty"`
+	Devices    []DiscoveredDevice `json:"devices"`
+}
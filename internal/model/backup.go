@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Backup is a snapshot of the database written by the backup subsystem
+type Backup struct {
+	Name      string    `json:"name"`
+	Location  string    `json:"location"` // file path, or s3://bucket/key
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
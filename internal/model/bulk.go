@@ -0,0 +1,38 @@
+package model
+
+// BulkOperationType names a change applied by a bulk device request
+type BulkOperationType string
+
+const (
+	BulkOpUpdate         BulkOperationType = "update"
+	BulkOpAddTags        BulkOperationType = "add_tags"
+	BulkOpRemoveTags     BulkOperationType = "remove_tags"
+	BulkOpMoveDatacenter BulkOperationType = "move_datacenter"
+	BulkOpDelete         BulkOperationType = "delete"
+)
+
+// BulkDeviceFields holds the device fields a bulk update may set. Nil fields
+// are left unchanged.
+type BulkDeviceFields struct {
+	Status      *DeviceStatus `json:"status,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	MakeModel   *string       `json:"make_model,omitempty"`
+	OS          *string       `json:"os,omitempty"`
+	Username    *string       `json:"username,omitempty"`
+	Location    *string       `json:"location,omitempty"`
+}
+
+// BulkOperation is one change applied to every device of a bulk request
+type BulkOperation struct {
+	Op           BulkOperationType `json:"op"`
+	Fields       *BulkDeviceFields `json:"fields,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	DatacenterID string            `json:"datacenter_id,omitempty"`
+}
+
+// BulkDeviceRequest applies a list of operations, in order, to a set of
+// devices. The whole request succeeds or fails as one.
+type BulkDeviceRequest struct {
+	DeviceIDs  []string        `json:"device_ids"`
+	Operations []BulkOperation `json:"operations"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChangeStatus is the state of a change request
+type ChangeStatus string
+
+const (
+	ChangePending  ChangeStatus = "pending"
+	ChangeApproved ChangeStatus = "approved" // being applied
+	ChangeApplied  ChangeStatus = "applied"
+	ChangeFailed   ChangeStatus = "failed"
+	ChangeRejected ChangeStatus = "rejected"
+)
+
+// ChangeRequest is an API mutation held for review in approval mode. Method,
+// Path and Body are the request as it was made; Before is what a GET of the
+// same path returned when it was submitted, so the two show the change.
+// Approving it replays the request as the requester and records the
+// response in ResultStatus and Result.
+type ChangeRequest struct {
+	ID              string          `json:"id"`
+	Status          ChangeStatus    `json:"status"`
+	Method          string          `json:"method"`
+	Path            string          `json:"path"`
+	Body            json.RawMessage `json:"body,omitempty"`
+	Before          json.RawMessage `json:"before,omitempty"`
+	RequestedBy     string          `json:"requested_by"`
+	RequestedByName string          `json:"requested_by_name"`
+	ReviewedBy      string          `json:"reviewed_by,omitempty"`
+	ReviewedByName  string          `json:"reviewed_by_name,omitempty"`
+	ReviewComment   string          `json:"review_comment,omitempty"`
+	ResultStatus    int             `json:"result_status,omitempty"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	ReviewedAt      *time.Time      `json:"reviewed_at,omitempty"`
+}
+
+// ChangeRequestFilter defines filter criteria for listing change requests
+type ChangeRequestFilter struct {
+	Pagination
+	Status      ChangeStatus
+	RequestedBy string
+}
+
+// ChangeReview is an approver's decision on a change request
+type ChangeReview struct {
+	Comment string `json:"comment"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// CloudProvider describes a configured cloud sync provider
+type CloudProvider struct {
+	Name string `json:"name"`
+}
+
+// CloudSyncResult summarises a sync of one cloud provider into the inventory.
+// Device lists hold device names.
+type CloudSyncResult struct {
+	Provider  string    `json:"provider"`
+	Created   []string  `json:"created"`
+	Updated   []string  `json:"updated"`
+	Unchanged int       `json:"unchanged"`
+	Missing   []string  `json:"missing"`
+	Errors    []string  `json:"errors"`
+	SyncedAt  time.Time `json:"synced_at"`
+}
@@ -2,16 +2,38 @@ package model
 
 import "time"
 
+// Datacenter is a site holding devices and networks. ShippingAddress and
+// AccessInstructions tell remote hands where to send parts and how to get
+// in; Contacts are only loaded when a single datacenter is fetched.
 type Datacenter struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Location    string    `json:"location"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                 string              `json:"id"`
+	Name               string              `json:"name"`
+	Location           string              `json:"location"`
+	Description        string              `json:"description"`
+	ShippingAddress    string              `json:"shipping_address"`
+	AccessInstructions string              `json:"access_instructions"`
+	Contacts           []DatacenterContact `json:"contacts,omitempty"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+}
+
+// DatacenterContact is a person or team to call about a datacenter, such as
+// remote hands or the facility NOC. Contacts are escalated to in ascending
+// EscalationOrder.
+type DatacenterContact struct {
+	ID              string    `json:"id"`
+	DatacenterID    string    `json:"datacenter_id"`
+	Role            string    `json:"role"`
+	Name            string    `json:"name"`
+	Email           string    `json:"email"`
+	Phone           string    `json:"phone"`
+	EscalationOrder int       `json:"escalation_order"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 type DatacenterFilter struct {
 	Pagination
 	Name string
+	IDs  []string
 }
@@ -0,0 +1,29 @@
+package model
+
+// DeletePolicy decides what happens to the records that depend on a device,
+// datacenter, network or pool when it is deleted
+type DeletePolicy string
+
+const (
+	// DeleteDetach unlinks dependents and keeps them. Dependents that cannot
+	// exist on their own, such as a network's pools, are deleted.
+	DeleteDetach DeletePolicy = "detach"
+	// DeleteCascade deletes the dependents along with the resource
+	DeleteCascade DeletePolicy = "cascade"
+	// DeleteDeny refuses the delete while any dependents exist
+	DeleteDeny DeletePolicy = "deny"
+)
+
+// ParseDeletePolicy reads the value of a cascade parameter. An empty value
+// selects detach and "true" is accepted for cascade.
+func ParseDeletePolicy(v string) (DeletePolicy, bool) {
+	switch v {
+	case "", string(DeleteDetach):
+		return DeleteDetach, true
+	case "true", string(DeleteCascade):
+		return DeleteCascade, true
+	case string(DeleteDeny):
+		return DeleteDeny, true
+	}
+	return "", false
+}
@@ -1,12 +1,19 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+)
 
 // DeviceStatus represents the lifecycle status of a device
 type DeviceStatus string
 
 const (
 	DeviceStatusPlanned       DeviceStatus = "planned"
+	DeviceStatusOrdered       DeviceStatus = "ordered"
 	DeviceStatusActive        DeviceStatus = "active"
 	DeviceStatusMaintenance   DeviceStatus = "maintenance"
 	DeviceStatusDecommissioned DeviceStatus = "decommissioned"
@@ -15,6 +22,7 @@ const (
 // ValidDeviceStatuses contains all valid device statuses
 var ValidDeviceStatuses = []DeviceStatus{
 	DeviceStatusPlanned,
+	DeviceStatusOrdered,
 	DeviceStatusActive,
 	DeviceStatusMaintenance,
 	DeviceStatusDecommissioned,
@@ -35,6 +43,47 @@ func (s DeviceStatus) String() string {
 	return string(s)
 }
 
+// DeviceKind is what sort of thing a device is
+type DeviceKind string
+
+const (
+	DeviceKindPhysical      DeviceKind = "physical"
+	DeviceKindVM            DeviceKind = "vm"
+	DeviceKindContainerHost DeviceKind = "container_host"
+	DeviceKindNetwork       DeviceKind = "network"
+	DeviceKindStorage       DeviceKind = "storage"
+	DeviceKindPDU           DeviceKind = "pdu"
+	DeviceKindOther         DeviceKind = "other"
+)
+
+// ValidDeviceKinds contains all valid device kinds
+var ValidDeviceKinds = []DeviceKind{
+	DeviceKindPhysical,
+	DeviceKindVM,
+	DeviceKindContainerHost,
+	DeviceKindNetwork,
+	DeviceKindStorage,
+	DeviceKindPDU,
+	DeviceKindOther,
+}
+
+// IsValid checks if the kind is a valid device kind
+func (k DeviceKind) IsValid() bool {
+	for _, kind := range ValidDeviceKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// CanHostVMs reports whether virtual machines can be hosted on a device of
+// this kind: a hypervisor is a physical server, and a container host such as
+// a Proxmox node runs virtual machines next to its containers
+func (k DeviceKind) CanHostVMs() bool {
+	return k == DeviceKindPhysical || k == DeviceKindContainerHost
+}
+
 type Device struct {
 	ID               string       `json:"id"`
 	Name             string       `json:"name"`
@@ -43,16 +92,25 @@ type Device struct {
 	MakeModel        string       `json:"make_model"`
 	OS               string       `json:"os"`
 	DatacenterID     string       `json:"datacenter_id,omitempty"`
+	Datacenter       *DatacenterRef `json:"datacenter,omitempty"`
 	Username         string       `json:"username,omitempty"`
 	Location         string       `json:"location,omitempty"`
+	Kind             DeviceKind   `json:"kind"`
 	Status           DeviceStatus `json:"status"`
 	DecommissionDate *time.Time   `json:"decommission_date,omitempty"`
 	StatusChangedAt  *time.Time   `json:"status_changed_at,omitempty"`
 	StatusChangedBy  string       `json:"status_changed_by,omitempty"`
+	PurchaseDate     *time.Time   `json:"purchase_date,omitempty"`
+	WarrantyExpiry   *time.Time   `json:"warranty_expiry,omitempty"`
+	EndOfLife        *time.Time   `json:"end_of_life,omitempty"`
 	Tags             []string     `json:"tags"`
+	Interfaces       []Interface  `json:"interfaces"`
 	Addresses        []Address    `json:"addresses"`
 	Domains          []string     `json:"domains"`
 	CustomFields     []CustomFieldValueInput `json:"custom_fields,omitempty"`
+	Monitoring       *DeviceMonitoring `json:"monitoring,omitempty"`
+	Maintenance      []MaintenanceWindow `json:"maintenance,omitempty"`
+	SSHHostKeys      []SSHHostKey `json:"ssh_host_keys,omitempty"`
 	CreatedAt        time.Time    `json:"created_at"`
 	UpdatedAt        time.Time    `json:"updated_at"`
 }
@@ -64,8 +122,149 @@ type Address struct {
 	Type       string `json:"type"`
 	Label      string `json:"label"`
 	NetworkID  string `json:"network_id,omitempty"`
+	Network    *NetworkRef `json:"network,omitempty"`
 	SwitchPort string `json:"switch_port,omitempty"`
 	PoolID     string `json:"pool_id,omitempty"`
+	MACAddress string `json:"mac_address,omitempty"`
+	Interface  string `json:"interface,omitempty"`
+}
+
+// DatacenterRef is the datacenter shown inline in a device requested with
+// ?embed=datacenter
+type DatacenterRef struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+}
+
+// NetworkRef is the network shown inline in an address of a device requested
+// with ?embed=network
+type NetworkRef struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Subnet string `json:"subnet"`
+	VLANID int    `json:"vlan_id"`
+}
+
+// Interface is a network interface of a device, such as a NIC, a bond of
+// NICs or a VLAN on top of either. Addresses name the interface they are
+// on, and bond members and VLANs name their parent, so several IPs can share
+// a port and several ports can share an IP.
+type Interface struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	MACAddress string `json:"mac_address,omitempty"`
+	SpeedMbps  int    `json:"speed_mbps,omitempty"`
+	SwitchPort string `json:"switch_port,omitempty"`
+	VLAN       int    `json:"vlan,omitempty"`
+	Parent     string `json:"parent,omitempty"`
+}
+
+// Interface types
+const (
+	InterfaceTypeEthernet = "ethernet"
+	InterfaceTypeBond     = "bond"
+	InterfaceTypeVLAN     = "vlan"
+	InterfaceTypeVirtual  = "virtual"
+)
+
+// ValidInterfaceTypes lists the accepted interface types
+var ValidInterfaceTypes = []string{InterfaceTypeEthernet, InterfaceTypeBond, InterfaceTypeVLAN, InterfaceTypeVirtual}
+
+// DefaultInterfaceName names the interface that addresses documented before
+// interfaces existed were moved onto
+const DefaultInterfaceName = "default"
+
+// Address types name the family of an address's IP
+const (
+	AddressTypeIPv4 = "ipv4"
+	AddressTypeIPv6 = "ipv6"
+)
+
+// AddressFamily returns the address type matching ip, or "" if it does not
+// parse. IPv4-mapped IPv6 addresses count as IPv4, as they do in storage.
+func AddressFamily(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	if addr.Unmap().Is4() {
+		return AddressTypeIPv4
+	}
+	return AddressTypeIPv6
+}
+
+// ResolveAddressType returns the type to store for an address: the family of
+// its IP when addrType is empty. It fails when addrType names the other
+// family. Other types are free-form and kept as they are.
+func ResolveAddressType(ip, addrType string) (string, error) {
+	family := AddressFamily(ip)
+	switch strings.ToLower(strings.TrimSpace(addrType)) {
+	case "":
+		return family, nil
+	case AddressTypeIPv4, AddressTypeIPv6:
+		addrType = strings.ToLower(strings.TrimSpace(addrType))
+		if family != "" && addrType != family {
+			return "", fmt.Errorf("type %s does not match %s, which is an %s address", addrType, ip, family)
+		}
+	}
+	return addrType, nil
+}
+
+// MACDuplicate is a MAC address that is also recorded on another device
+type MACDuplicate struct {
+	MACAddress string `json:"mac_address"`
+	DeviceID   string `json:"device_id"`
+	DeviceName string `json:"device_name"`
+}
+
+// Warning describes the duplicate for users
+func (d MACDuplicate) Warning() string {
+	return fmt.Sprintf("MAC address %s is also used by device %s (%s)", d.MACAddress, d.DeviceName, d.DeviceID)
+}
+
+// DuplicateReason is a kind of evidence that two devices are the same host
+type DuplicateReason string
+
+const (
+	DuplicateReasonMAC  DuplicateReason = "mac"
+	DuplicateReasonIP   DuplicateReason = "ip"
+	DuplicateReasonName DuplicateReason = "name"
+)
+
+// DuplicateCandidate is a pair of devices that look like the same host. The
+// older device is suggested as the one to keep.
+type DuplicateCandidate struct {
+	DeviceID      string            `json:"device_id"`
+	DeviceName    string            `json:"device_name"`
+	DuplicateID   string            `json:"duplicate_id"`
+	DuplicateName string            `json:"duplicate_name"`
+	Reasons       []DuplicateReason `json:"reasons"`
+	// Matches are the shared MAC addresses and IPs, and the normalised name
+	Matches []string `json:"matches"`
+}
+
+// NormalizeMAC returns mac as lowercase colon-separated hex. It reports false
+// when mac is not a 48-bit hardware address.
+func NormalizeMAC(mac string) (string, bool) {
+	hw, err := net.ParseMAC(strings.TrimSpace(mac))
+	if err != nil || len(hw) != 6 {
+		return "", false
+	}
+	return hw.String(), true
+}
+
+// DeviceLabel is the text printed on a device's asset label next to a QR
+// code linking to the device. Rack and unit are whatever the device's
+// location records.
+type DeviceLabel struct {
+	DeviceID   string `json:"device_id"`
+	Name       string `json:"name"`
+	Hostname   string `json:"hostname,omitempty"`
+	AssetTag   string `json:"asset_tag,omitempty"`
+	Datacenter string `json:"datacenter,omitempty"`
+	Location   string `json:"location,omitempty"`
 }
 
 type DeviceFilter struct {
@@ -75,7 +274,10 @@ type DeviceFilter struct {
 	NetworkID    string
 	PoolID       string
 	Status       DeviceStatus
+	Kind         DeviceKind
+	HostID       string // If set, filter the VMs hosted on this device
 	StaleDays    int // If > 0, filter devices not seen in discovery for X days
+	WarrantyDays int // If > 0, filter devices whose warranty expires within X days, soonest first
 	CustomFields []CustomFieldFilter
 }
 
@@ -3,23 +3,93 @@ package model
 import "time"
 
 type DiscoveredDevice struct {
-	ID                 string        `json:"id"`
-	IP                 string        `json:"ip"`
-	MACAddress         string        `json:"mac_address"`
-	Hostname           string        `json:"hostname"`
-	NetworkID          string        `json:"network_id"`
-	Status             string        `json:"status"`
-	Confidence         int           `json:"confidence"`
-	OSGuess            string        `json:"os_guess"`
-	Vendor             string        `json:"vendor"`
-	OpenPorts          []int         `json:"open_ports"`
-	Services           []ServiceInfo `json:"services"`
-	FirstSeen          time.Time     `json:"first_seen"`
-	LastSeen           time.Time     `json:"last_seen"`
-	PromotedToDeviceID string        `json:"promoted_to_device_id,omitempty"`
-	PromotedAt         *time.Time    `json:"promoted_at,omitempty"`
-	CreatedAt          time.Time     `json:"created_at"`
-	UpdatedAt          time.Time     `json:"updated_at"`
+	ID                  string             `json:"id"`
+	IP                  string             `json:"ip"`
+	MACAddress          string             `json:"mac_address"`
+	Hostname            string             `json:"hostname"`
+	NetworkID           string             `json:"network_id"`
+	Status              string             `json:"status"`
+	Confidence          int                `json:"confidence"`
+	ConfidenceBreakdown []ConfidenceFactor `json:"confidence_breakdown"`
+	OSGuess             string             `json:"os_guess"`
+	Vendor              string             `json:"vendor"`
+	OpenPorts           []int              `json:"open_ports"`
+	Services            []ServiceInfo      `json:"services"`
+	FirstSeen           time.Time          `json:"first_seen"`
+	LastSeen            time.Time          `json:"last_seen"`
+	ScanCount           int                `json:"scan_count"`
+	DNSNames            []string           `json:"dns_names"`
+	DNSMismatches       []string           `json:"dns_mismatches"`
+	PromotedToDeviceID  string             `json:"promoted_to_device_id,omitempty"`
+	PromotedAt          *time.Time         `json:"promoted_at,omitempty"`
+	IgnoredAt           *time.Time         `json:"ignored_at,omitempty"`
+	IgnoreReason        string             `json:"ignore_reason,omitempty"`
+	IgnoredUntil        *time.Time         `json:"ignored_until,omitempty"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+}
+
+// DiscoveredStatusIgnored is the status of a discovered device a user chose
+// to ignore, such as a DHCP guest. Ignored devices are left out of the
+// discovered device list, diff reports, scan change summaries and
+// auto-promotion until the ignore expires or is lifted.
+const DiscoveredStatusIgnored = "ignored"
+
+// Ignored reports whether the device is ignored
+func (d *DiscoveredDevice) Ignored() bool {
+	return d.Status == DiscoveredStatusIgnored
+}
+
+// Confidence factors: the evidence a discovered device's confidence score is
+// made of. Each adds up to its ConfidenceFactorMax points.
+const (
+	ConfidenceFactorPing            = "ping"             // the host answered a probe or announced itself
+	ConfidenceFactorReverseDNS      = "reverse_dns"      // the IP has a PTR record
+	ConfidenceFactorMACVendor       = "mac_vendor"       // the MAC address is known, and its vendor
+	ConfidenceFactorOpenPorts       = "open_ports"       // points per open port
+	ConfidenceFactorSNMP            = "snmp"             // the host answered SNMP
+	ConfidenceFactorRepeatSightings = "repeat_sightings" // points per scan after the first
+)
+
+// ConfidenceFactors lists the confidence factors in breakdown order
+var ConfidenceFactors = []string{
+	ConfidenceFactorPing,
+	ConfidenceFactorReverseDNS,
+	ConfidenceFactorMACVendor,
+	ConfidenceFactorOpenPorts,
+	ConfidenceFactorSNMP,
+	ConfidenceFactorRepeatSightings,
+}
+
+// ConfidenceFactorMax is the most points each factor adds. The maxima add up
+// to 100, the highest confidence.
+var ConfidenceFactorMax = map[string]int{
+	ConfidenceFactorPing:            20,
+	ConfidenceFactorReverseDNS:      20,
+	ConfidenceFactorMACVendor:       15,
+	ConfidenceFactorOpenPorts:       15,
+	ConfidenceFactorSNMP:            20,
+	ConfidenceFactorRepeatSightings: 10,
+}
+
+// ConfidenceFactor is one line of a confidence breakdown: the points a factor
+// added to the score, out of Max, and the evidence behind them
+type ConfidenceFactor struct {
+	Factor string `json:"factor"`
+	Points int    `json:"points"`
+	Max    int    `json:"max"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// FactorPoints returns the points the named factor added to the device's
+// confidence, 0 when the breakdown does not list it
+func (d *DiscoveredDevice) FactorPoints(factor string) int {
+	for _, f := range d.ConfidenceBreakdown {
+		if f.Factor == factor {
+			return f.Points
+		}
+	}
+	return 0
 }
 
 type ServiceInfo struct {
@@ -27,6 +97,78 @@ type ServiceInfo struct {
 	Protocol string `json:"protocol"`
 	Service  string `json:"service"`
 	Version  string `json:"version"`
+
+	// Structured fields parsed from the raw Version banner
+	Product        string `json:"product,omitempty"`
+	ProductVersion string `json:"product_version,omitempty"`
+	Info           string `json:"info,omitempty"`
+
+	// Fingerprint of HTTP and TLS services
+	HTTPServer  string          `json:"http_server,omitempty"`
+	HTTPTitle   string          `json:"http_title,omitempty"`
+	Certificate *TLSCertificate `json:"certificate,omitempty"`
+}
+
+// TLSCertificate describes the certificate a TLS service presented
+type TLSCertificate struct {
+	CommonName string    `json:"common_name"`
+	SANs       []string  `json:"sans,omitempty"`
+	Issuer     string    `json:"issuer"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+}
+
+// SSHHostKey is the fingerprint of an SSH host key that discovery collected
+// from an IP. When the key of a type changes, the old fingerprint is kept in
+// PreviousFingerprint and FirstSeen restarts.
+type SSHHostKey struct {
+	IP                  string     `json:"ip"`
+	KeyType             string     `json:"key_type"`
+	Fingerprint         string     `json:"fingerprint"`
+	PreviousFingerprint string     `json:"previous_fingerprint,omitempty"`
+	ChangedAt           *time.Time `json:"changed_at,omitempty"`
+	FirstSeen           time.Time  `json:"first_seen"`
+	LastSeen            time.Time  `json:"last_seen"`
+}
+
+// PortObservation is the history of a TCP port discovery found open on an
+// IP. A port stays open until a scan that probes it finds it closed; when it
+// opens again, a new open period starts at OpenedAt while FirstSeen keeps the
+// first sighting. OpenedScanID and ClosedScanID name the scans that saw the
+// latest open and close, when a scan did.
+type PortObservation struct {
+	IP           string     `json:"ip"`
+	Port         int        `json:"port"`
+	NetworkID    string     `json:"network_id"`
+	Service      string     `json:"service"`
+	Banner       string     `json:"banner"`
+	Open         bool       `json:"open"`
+	FirstSeen    time.Time  `json:"first_seen"`
+	LastSeen     time.Time  `json:"last_seen"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	OpenedScanID string     `json:"opened_scan_id,omitempty"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+	ClosedScanID string     `json:"closed_scan_id,omitempty"`
+}
+
+// PortObservationFilter selects port observations
+type PortObservationFilter struct {
+	Pagination
+	NetworkID    string
+	IPs          []string
+	Open         *bool
+	OpenedSince  *time.Time
+	OpenedScanID string
+	ClosedScanID string
+}
+
+// PortChanges are the ports a scan found newly opened and closed, compared
+// with what earlier scans saw. A port that has changed again since appears
+// under the later scan instead.
+type PortChanges struct {
+	ScanID string            `json:"scan_id"`
+	Opened []PortObservation `json:"opened"`
+	Closed []PortObservation `json:"closed"`
 }
 
 type DiscoveryScan struct {
@@ -46,16 +188,25 @@ type DiscoveryScan struct {
 }
 
 type DiscoveryRule struct {
-	ID            string    `json:"id"`
-	NetworkID     string    `json:"network_id"`
-	Enabled       bool      `json:"enabled"`
-	ScanType      string    `json:"scan_type"`
-	IntervalHours int       `json:"interval_hours"`
-	ExcludeIPs    string    `json:"exclude_ips"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID             string `json:"id"`
+	NetworkID      string `json:"network_id"`
+	Enabled        bool   `json:"enabled"`
+	ScanType       string `json:"scan_type"`
+	IntervalHours  int    `json:"interval_hours"`
+	ExcludeIPs     string `json:"exclude_ips"`
+	PassiveEnabled bool   `json:"passive_enabled"`
+	DNSResolution  bool   `json:"dns_resolution"`
+	DNSServer      string `json:"dns_server"`
+	// MaxConcurrentScans caps how many hosts a scan of the network probes at
+	// once; 0 leaves it to the adaptive default for the subnet size
+	MaxConcurrentScans int       `json:"max_concurrent_scans"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
+// MaxScanWorkers is the highest MaxConcurrentScans a discovery rule can set
+const MaxScanWorkers = 256
+
 const (
 	ScanTypeQuick = "quick"
 	ScanTypeFull  = "full"
@@ -68,3 +219,126 @@ const (
 	ScanStatusCompleted = "completed"
 	ScanStatusFailed    = "failed"
 )
+
+// DiscoveryDiff compares recent discovery results for a network with the
+// documented inventory.
+type DiscoveryDiff struct {
+	NetworkID    string                  `json:"network_id"`
+	StaleDays    int                     `json:"stale_days"`
+	GeneratedAt  time.Time               `json:"generated_at"`
+	Undocumented []DiscoveredDevice      `json:"undocumented"`
+	Missing      []DiscoveryMissingEntry `json:"missing"`
+	Drift        []DiscoveryDriftEntry   `json:"drift"`
+	// Certificates lists documented devices serving a TLS certificate that
+	// expired or expires soon
+	Certificates []DiscoveryCertificateEntry `json:"certificates"`
+}
+
+// DiscoveryMissingEntry is a documented device not seen by recent scans
+type DiscoveryMissingEntry struct {
+	DeviceID   string     `json:"device_id"`
+	DeviceName string     `json:"device_name"`
+	IPs        []string   `json:"ips"`
+	LastSeen   *time.Time `json:"last_seen,omitempty"`
+}
+
+// DiscoveryDriftEntry is a documented device whose observed attributes
+// differ from the record
+type DiscoveryDriftEntry struct {
+	DeviceID     string       `json:"device_id"`
+	DeviceName   string       `json:"device_name"`
+	DiscoveredID string       `json:"discovered_id"`
+	IP           string       `json:"ip"`
+	Fields       []DriftField `json:"fields"`
+}
+
+// DiscoveryCertificateEntry is an expired or expiring certificate served by a
+// documented device
+type DiscoveryCertificateEntry struct {
+	DeviceID     string         `json:"device_id"`
+	DeviceName   string         `json:"device_name"`
+	DiscoveredID string         `json:"discovered_id"`
+	IP           string         `json:"ip"`
+	Port         int            `json:"port"`
+	Certificate  TLSCertificate `json:"certificate"`
+	Expired      bool           `json:"expired"`
+	DaysLeft     int            `json:"days_left"`
+}
+
+// DriftField describes a single attribute mismatch
+type DriftField struct {
+	Field      string `json:"field"`
+	Documented string `json:"documented"`
+	Observed   string `json:"observed"`
+}
+
+// AutoPromotionRule promotes discovered devices on a network to inventory
+// once they meet every configured condition
+type AutoPromotionRule struct {
+	ID            string `json:"id"`
+	NetworkID     string `json:"network_id"`
+	Name          string `json:"name"`
+	Enabled       bool   `json:"enabled"`
+	MinConfidence int    `json:"min_confidence"`
+	// MinFactorPoints holds the least points named confidence factors
+	// must have added, on top of MinConfidence
+	MinFactorPoints map[string]int `json:"min_factor_points"`
+	HostnamePattern string         `json:"hostname_pattern"`
+	MinScans        int            `json:"min_scans"`
+	NameTemplate    string         `json:"name_template"`
+	Tags            []string       `json:"tags"`
+	DatacenterID    string         `json:"datacenter_id,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// AutoPromotionResult describes a device promoted by an auto-promotion rule
+type AutoPromotionResult struct {
+	RuleID       string `json:"rule_id"`
+	DiscoveredID string `json:"discovered_id"`
+	DeviceID     string `json:"device_id"`
+	DeviceName   string `json:"device_name"`
+	IP           string `json:"ip"`
+}
+
+// Outcomes of mapping a switch neighbor to a documented device
+const (
+	SwitchPortFilled    = "filled"    // the address had no switch port, which was set
+	SwitchPortVerified  = "verified"  // the address already had this switch port
+	SwitchPortMismatch  = "mismatch"  // the address has a different switch port, left unchanged
+	SwitchPortLinked    = "linked"    // matched by name only, so no address was updated
+	SwitchPortUnmatched = "unmatched" // no documented device matched the neighbor
+)
+
+// SwitchPortMapping is a device a switch sees on one of its ports through
+// LLDP or CDP, and what collection did with it
+type SwitchPortMapping struct {
+	SwitchID       string `json:"switch_id"`
+	SwitchName     string `json:"switch_name"`
+	Port           string `json:"port"`
+	Protocol       string `json:"protocol"`
+	NeighborName   string `json:"neighbor_name,omitempty"`
+	NeighborPort   string `json:"neighbor_port,omitempty"`
+	MAC            string `json:"mac,omitempty"`
+	IP             string `json:"ip,omitempty"`
+	DeviceID       string `json:"device_id,omitempty"`
+	DeviceName     string `json:"device_name,omitempty"`
+	AddressID      string `json:"address_id,omitempty"`
+	DocumentedPort string `json:"documented_port,omitempty"`
+	Status         string `json:"status"`
+}
+
+// SwitchPortError records a switch whose neighbors could not be read
+type SwitchPortError struct {
+	SwitchID   string `json:"switch_id"`
+	SwitchName string `json:"switch_name"`
+	Error      string `json:"error"`
+}
+
+// SwitchPortReport is the result of collecting switch port neighbors
+type SwitchPortReport struct {
+	Switches      int                 `json:"switches"`
+	Mappings      []SwitchPortMapping `json:"mappings"`
+	Errors        []SwitchPortError   `json:"errors"`
+	Relationships int                 `json:"relationships_created"`
+}
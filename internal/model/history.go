@@ -0,0 +1,25 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Resource version actions
+const (
+	VersionActionCreate = "create"
+	VersionActionUpdate = "update"
+	VersionActionDelete = "delete"
+)
+
+// ResourceVersion is one recorded change of a network or pool. Data holds the
+// full resource after the change and is empty for deletes.
+type ResourceVersion struct {
+	Version    int64           `json:"version"`
+	Resource   string          `json:"resource"`
+	ResourceID string          `json:"resource_id"`
+	Action     string          `json:"action"`
+	ChangedAt  time.Time       `json:"changed_at"`
+	ChangedBy  string          `json:"changed_by,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
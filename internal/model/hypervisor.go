@@ -0,0 +1,85 @@
+package model
+
+import "time"
+
+// HypervisorType is the virtualisation platform a connector talks to
+type HypervisorType string
+
+const (
+	HypervisorTypeProxmox HypervisorType = "proxmox"
+	HypervisorTypeVSphere HypervisorType = "vsphere"
+)
+
+// ValidHypervisorTypes contains all valid hypervisor types
+var ValidHypervisorTypes = []HypervisorType{
+	HypervisorTypeProxmox,
+	HypervisorTypeVSphere,
+}
+
+// IsValid checks if the hypervisor type is valid
+func (t HypervisorType) IsValid() bool {
+	for _, ht := range ValidHypervisorTypes {
+		if t == ht {
+			return true
+		}
+	}
+	return false
+}
+
+// HypervisorConnector syncs the hosts and VMs of a Proxmox VE cluster or
+// vCenter into the inventory. Username is the Proxmox API token ID
+// (user@realm!name) or the vCenter user; Secret is the token secret or
+// password and is stored encrypted.
+type HypervisorConnector struct {
+	ID                 string         `json:"id"`
+	Name               string         `json:"name"`
+	Type               HypervisorType `json:"type"`
+	Endpoint           string         `json:"endpoint"`
+	Username           string         `json:"username"`
+	Secret             string         `json:"-"` // Write-only, never exposed in JSON
+	InsecureSkipVerify bool           `json:"insecure_skip_verify"`
+	DatacenterID       string         `json:"datacenter_id,omitempty"`
+	Enabled            bool           `json:"enabled"`
+	Description        string         `json:"description"`
+	LastSyncAt         *time.Time     `json:"last_sync_at,omitempty"`
+	LastSyncError      string         `json:"last_sync_error,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+// HypervisorSyncResult summarises a sync of one connector; Provider holds
+// the connector name
+type HypervisorSyncResult = CloudSyncResult
+
+// HypervisorConnectorFilter for filtering hypervisor connectors
+type HypervisorConnectorFilter struct {
+	Pagination
+	Type    HypervisorType
+	Enabled *bool
+}
+
+// CreateHypervisorConnectorRequest represents the input for creating a
+// hypervisor connector. Enabled defaults to true.
+type CreateHypervisorConnectorRequest struct {
+	Name               string         `json:"name"`
+	Type               HypervisorType `json:"type"`
+	Endpoint           string         `json:"endpoint"`
+	Username           string         `json:"username"`
+	Secret             string         `json:"secret"`
+	InsecureSkipVerify bool           `json:"insecure_skip_verify"`
+	DatacenterID       string         `json:"datacenter_id"`
+	Enabled            *bool          `json:"enabled"`
+	Description        string         `json:"description"`
+}
+
+// UpdateHypervisorConnectorRequest represents the input for updating a
+// hypervisor connector
+type UpdateHypervisorConnectorRequest struct {
+	Endpoint           *string `json:"endpoint,omitempty"`
+	Username           *string `json:"username,omitempty"`
+	Secret             *string `json:"secret,omitempty"`
+	InsecureSkipVerify *bool   `json:"insecure_skip_verify,omitempty"`
+	DatacenterID       *string `json:"datacenter_id,omitempty"`
+	Enabled            *bool   `json:"enabled,omitempty"`
+	Description        *string `json:"description,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// IdempotencyRecord is the stored response to a POST sent with an
+// Idempotency-Key header, replayed when the request is retried with the same
+// key. Status is 0 while the first request is still running.
+type IdempotencyRecord struct {
+	Key         string            `json:"key"`
+	UserID      string            `json:"user_id"`
+	Method      string            `json:"method"`
+	Path        string            `json:"path"`
+	RequestHash string            `json:"request_hash"`
+	Status      int               `json:"status"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        []byte            `json:"-"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+}
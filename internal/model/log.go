@@ -15,7 +15,10 @@ type LogEntry struct {
 // LogFilter provides filtering and pagination for recent log queries.
 type LogFilter struct {
 	Pagination
-	Level     string
+	Level string
+	// MinLevel matches entries at or above the level (e.g. warn matches warn,
+	// error and fatal)
+	MinLevel  string
 	Source    string
 	Query     string
 	StartTime *time.Time
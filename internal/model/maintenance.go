@@ -0,0 +1,35 @@
+package model
+
+// DBCheckReport is the result of a database integrity check. OK is false
+// when any of the checks found a problem.
+type DBCheckReport struct {
+	OK                   bool                    `json:"ok"`
+	Integrity            []string                `json:"integrity"` // "ok", or the problems PRAGMA integrity_check found
+	ForeignKeyViolations []DBForeignKeyViolation `json:"foreign_key_violations"`
+	Orphans              []DBOrphanCount         `json:"orphans"`
+	Duration             int64                   `json:"duration_ms"`
+}
+
+// DBForeignKeyViolation counts the rows of a table whose foreign key points
+// at a missing row of the parent table
+type DBForeignKeyViolation struct {
+	Table  string `json:"table"`
+	Parent string `json:"parent"`
+	Count  int    `json:"count"`
+}
+
+// DBOrphanCount counts the rows whose column references a record that no
+// longer exists
+type DBOrphanCount struct {
+	Table      string `json:"table"`
+	Column     string `json:"column"`
+	References string `json:"references"`
+	Count      int    `json:"count"`
+}
+
+// DBOptimizeResult reports the database size before and after VACUUM
+type DBOptimizeResult struct {
+	SizeBefore int64 `json:"size_before"`
+	SizeAfter  int64 `json:"size_after"`
+	Duration   int64 `json:"duration_ms"`
+}
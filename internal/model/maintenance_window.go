@@ -0,0 +1,59 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Maintenance window states, relative to the current time. Current covers
+// both active and upcoming windows.
+const (
+	MaintenanceActive   = "active"
+	MaintenanceUpcoming = "upcoming"
+	MaintenancePast     = "past"
+	MaintenanceCurrent  = "current"
+)
+
+// MaintenanceWindow is a period when a device, or every device carrying a
+// tag, is expected to be unavailable. Availability problems and missing
+// discovery sightings are not reported for devices in an active window.
+type MaintenanceWindow struct {
+	ID          string    `json:"id"`
+	DeviceID    string    `json:"device_id,omitempty"`
+	Tag         string    `json:"tag,omitempty"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StatusAt returns whether the window is active, upcoming or past at t
+func (w *MaintenanceWindow) StatusAt(t time.Time) string {
+	switch {
+	case t.Before(w.StartsAt):
+		return MaintenanceUpcoming
+	case t.Before(w.EndsAt):
+		return MaintenanceActive
+	}
+	return MaintenancePast
+}
+
+// Covers reports whether the window applies to device
+func (w *MaintenanceWindow) Covers(device *Device) bool {
+	if w.DeviceID != "" {
+		return w.DeviceID == device.ID
+	}
+	return w.Tag != "" && slices.Contains(device.Tags, w.Tag)
+}
+
+// MaintenanceWindowFilter selects maintenance windows. With DeviceID or Tags
+// set, windows on the device or on any of the tags match.
+type MaintenanceWindowFilter struct {
+	Pagination
+	DeviceID string
+	Tags     []string
+	Status   string
+}
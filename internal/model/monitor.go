@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// Monitor check types
+const (
+	MonitorCheckICMP = "icmp"
+	MonitorCheckTCP  = "tcp"
+)
+
+// Monitor statuses. A device is degraded when some of its checks are down.
+const (
+	MonitorStatusUp       = "up"
+	MonitorStatusDown     = "down"
+	MonitorStatusDegraded = "degraded"
+	MonitorStatusUnknown  = "unknown"
+)
+
+// MonitorCheck is an availability check run on an interval against one
+// device, or against every device carrying a tag
+type MonitorCheck struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	DeviceID        string    `json:"device_id,omitempty"`
+	Tag             string    `json:"tag,omitempty"`
+	Type            string    `json:"type"`
+	Port            int       `json:"port,omitempty"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	TimeoutSeconds  int       `json:"timeout_seconds"`
+	Enabled         bool      `json:"enabled"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// MonitorResult is the latest outcome of a check against one device
+type MonitorResult struct {
+	CheckID       string     `json:"check_id"`
+	CheckName     string     `json:"check_name"`
+	DeviceID      string     `json:"device_id"`
+	Type          string     `json:"type"`
+	Port          int        `json:"port,omitempty"`
+	IP            string     `json:"ip"`
+	Status        string     `json:"status"`
+	LatencyMs     float64    `json:"latency_ms"`
+	Message       string     `json:"message,omitempty"`
+	LastCheckedAt time.Time  `json:"last_checked_at"`
+	LastUpAt      *time.Time `json:"last_up_at,omitempty"`
+	LastChangeAt  time.Time  `json:"last_change_at"`
+}
+
+// DeviceMonitoring summarises the check results for a device
+type DeviceMonitoring struct {
+	Status     string          `json:"status"`
+	LastSeenAt *time.Time      `json:"last_seen_at,omitempty"`
+	Checks     []MonitorResult `json:"checks"`
+}
+
+// MonitorDeviceStatus is a monitored device in the status summary
+type MonitorDeviceStatus struct {
+	DeviceID   string     `json:"device_id"`
+	DeviceName string     `json:"device_name"`
+	Status     string     `json:"status"`
+	Since      time.Time  `json:"since"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// MonitorSummary counts monitored devices by status and lists the ones that
+// are not fully up
+type MonitorSummary struct {
+	Total       int                   `json:"total"`
+	Up          int                   `json:"up"`
+	Down        int                   `json:"down"`
+	Degraded    int                   `json:"degraded"`
+	Unknown     int                   `json:"unknown"`
+	Maintenance int                   `json:"maintenance"`
+	Problems    []MonitorDeviceStatus `json:"problems"`
+	CheckedAt   time.Time             `json:"checked_at"`
+}
@@ -2,12 +2,18 @@ package model
 
 import "time"
 
+// Network is a subnet. Gateway, DNSServers and MTU describe what hosts on it
+// are configured with; they are exported with its DHCP reservations and zone.
 type Network struct {
 	ID           string    `json:"id"`
 	Name         string    `json:"name"`
 	Subnet       string    `json:"subnet"`
 	VLANID       int       `json:"vlan_id"`
+	Gateway      string    `json:"gateway"`
+	DNSServers   []string  `json:"dns_servers"`
+	MTU          int       `json:"mtu"`
 	DatacenterID string    `json:"datacenter_id"`
+	ParentID     string    `json:"parent_id"`
 	Description  string    `json:"description"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
@@ -29,7 +35,9 @@ type NetworkFilter struct {
 	Pagination
 	Name         string
 	DatacenterID string
+	ParentID     string
 	VLANID       int
+	IDs          []string
 }
 
 type NetworkPoolFilter struct {
@@ -38,10 +46,69 @@ type NetworkPoolFilter struct {
 	Tags      []string
 }
 
+// NetworkUtilization reports address usage of a network. An IPv6 subnet can
+// hold more addresses than an int, so TotalIPs and AvailableIPs saturate and
+// TotalAddresses and AvailableAddresses carry the exact counts as decimal
+// strings.
 type NetworkUtilization struct {
-	NetworkID    string  `json:"network_id"`
-	TotalIPs     int     `json:"total_ips"`
-	UsedIPs      int     `json:"used_ips"`
-	AvailableIPs int     `json:"available_ips"`
-	Utilization  float64 `json:"utilization"`
+	NetworkID          string  `json:"network_id"`
+	Family             string  `json:"family"`
+	PrefixLength       int     `json:"prefix_length"`
+	TotalIPs           int     `json:"total_ips"`
+	UsedIPs            int     `json:"used_ips"`
+	AvailableIPs       int     `json:"available_ips"`
+	TotalAddresses     string  `json:"total_addresses"`
+	AvailableAddresses string  `json:"available_addresses"`
+	Utilization        float64 `json:"utilization"`
+}
+
+// PoolCounts counts the IPs of one or more pools. Utilization is the share
+// of IPs that are used or reserved. As in NetworkUtilization, Total and Free
+// saturate for IPv6 ranges and TotalAddresses and FreeAddresses are exact.
+type PoolCounts struct {
+	Total          int     `json:"total"`
+	Used           int     `json:"used"`
+	Reserved       int     `json:"reserved"`
+	Free           int     `json:"free"`
+	TotalAddresses string  `json:"total_addresses"`
+	FreeAddresses  string  `json:"free_addresses"`
+	Utilization    float64 `json:"utilization"`
+}
+
+// PoolStats reports the IP counts of a pool
+type PoolStats struct {
+	PoolID    string `json:"pool_id"`
+	PoolName  string `json:"pool_name"`
+	NetworkID string `json:"network_id"`
+	Family    string `json:"family"`
+	PoolCounts
+}
+
+// PoolStatsSummary aggregates the stats of the pools of a network or a
+// datacenter. IPv4 and IPv6 are totalled apart, as a single IPv6 pool would
+// otherwise drown out every IPv4 figure.
+type PoolStatsSummary struct {
+	NetworkID    string      `json:"network_id,omitempty"`
+	DatacenterID string      `json:"datacenter_id,omitempty"`
+	PoolCount    int         `json:"pool_count"`
+	IPv4         PoolCounts  `json:"ipv4"`
+	IPv6         PoolCounts  `json:"ipv6"`
+	Pools        []PoolStats `json:"pools"`
+}
+
+// PoolStatsFilter selects the pools of a network or of a datacenter's
+// networks
+type PoolStatsFilter struct {
+	NetworkID    string
+	DatacenterID string
+}
+
+// AllocateSubnetRequest represents a request to carve the next free subnet of
+// the given prefix length out of a supernet. The new network inherits the
+// supernet's datacenter.
+type AllocateSubnetRequest struct {
+	PrefixLength int    `json:"prefix_length"`
+	Name         string `json:"name,omitempty"` // Optional - defaults to the allocated subnet
+	VLANID       int    `json:"vlan_id,omitempty"`
+	Description  string `json:"description,omitempty"`
 }
@@ -0,0 +1,121 @@
+package model
+
+import "time"
+
+// NotificationChannelType is the kind of service a notification channel
+// delivers to
+type NotificationChannelType string
+
+const (
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+	NotificationChannelEmail   NotificationChannelType = "email"
+	NotificationChannelGotify  NotificationChannelType = "gotify"
+	NotificationChannelNtfy    NotificationChannelType = "ntfy"
+)
+
+// ValidNotificationChannelTypes contains all valid notification channel types
+var ValidNotificationChannelTypes = []NotificationChannelType{
+	NotificationChannelWebhook,
+	NotificationChannelEmail,
+	NotificationChannelGotify,
+	NotificationChannelNtfy,
+}
+
+// IsValid checks if the notification channel type is valid
+func (t NotificationChannelType) IsValid() bool {
+	for _, ct := range ValidNotificationChannelTypes {
+		if t == ct {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannel sends a human-readable summary of events to a person
+// or team. URL is the webhook endpoint, the Gotify server or the ntfy topic
+// URL; email channels use the SMTP fields, or the server's SMTP settings when
+// SMTPHost is empty. Secret is the Gotify application token, the ntfy access
+// token or the SMTP password. Emails go to the To addresses and to the email
+// addresses of the users in UserIDs. TitleTemplate and BodyTemplate replace
+// the default message with Go text/template templates. With ChangesOnly,
+// discovery scans that found nothing new, missing or changed are not
+// reported.
+type NotificationChannel struct {
+	ID            string                  `json:"id"`
+	Name          string                  `json:"name"`
+	Type          NotificationChannelType `json:"type"`
+	URL           string                  `json:"url,omitempty"`
+	Secret        string                  `json:"-"` // Never exposed in API responses
+	HasSecret     bool                    `json:"has_secret"`
+	SMTPHost      string                  `json:"smtp_host,omitempty"`
+	SMTPPort      int                     `json:"smtp_port,omitempty"`
+	Username      string                  `json:"username,omitempty"`
+	From          string                  `json:"from,omitempty"`
+	To            []string                `json:"to,omitempty"`
+	UserIDs       []string                `json:"user_ids,omitempty"`
+	Events        []EventType             `json:"events"`
+	TitleTemplate string                  `json:"title_template,omitempty"`
+	BodyTemplate  string                  `json:"body_template,omitempty"`
+	ChangesOnly   bool                    `json:"changes_only"`
+	Active        bool                    `json:"active"`
+	Description   string                  `json:"description,omitempty"`
+	CreatedBy     string                  `json:"created_by,omitempty"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+}
+
+// NotificationChannelFilter for querying notification channels
+type NotificationChannelFilter struct {
+	Pagination
+	Type   NotificationChannelType
+	Active *bool
+}
+
+// NotificationTestResult is the outcome of sending a test notification
+type NotificationTestResult struct {
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+	Duration int64  `json:"duration_ms"`
+}
+
+// CreateNotificationChannelRequest represents a request to create a
+// notification channel. Events defaults to discovery.completed and Active to
+// true.
+type CreateNotificationChannelRequest struct {
+	Name          string                  `json:"name"`
+	Type          NotificationChannelType `json:"type"`
+	URL           string                  `json:"url,omitempty"`
+	Secret        string                  `json:"secret,omitempty"`
+	SMTPHost      string                  `json:"smtp_host,omitempty"`
+	SMTPPort      int                     `json:"smtp_port,omitempty"`
+	Username      string                  `json:"username,omitempty"`
+	From          string                  `json:"from,omitempty"`
+	To            []string                `json:"to,omitempty"`
+	UserIDs       []string                `json:"user_ids,omitempty"`
+	Events        []EventType             `json:"events,omitempty"`
+	TitleTemplate string                  `json:"title_template,omitempty"`
+	BodyTemplate  string                  `json:"body_template,omitempty"`
+	ChangesOnly   bool                    `json:"changes_only"`
+	Active        *bool                   `json:"active,omitempty"`
+	Description   string                  `json:"description,omitempty"`
+}
+
+// UpdateNotificationChannelRequest represents a request to update a
+// notification channel. The type cannot be changed.
+type UpdateNotificationChannelRequest struct {
+	Name          *string      `json:"name,omitempty"`
+	URL           *string      `json:"url,omitempty"`
+	Secret        *string      `json:"secret,omitempty"`
+	SMTPHost      *string      `json:"smtp_host,omitempty"`
+	SMTPPort      *int         `json:"smtp_port,omitempty"`
+	Username      *string      `json:"username,omitempty"`
+	From          *string      `json:"from,omitempty"`
+	To            *[]string    `json:"to,omitempty"`
+	UserIDs       *[]string    `json:"user_ids,omitempty"`
+	Events        *[]EventType `json:"events,omitempty"`
+	TitleTemplate *string      `json:"title_template,omitempty"`
+	BodyTemplate  *string      `json:"body_template,omitempty"`
+	ChangesOnly   *bool        `json:"changes_only,omitempty"`
+	Active        *bool        `json:"active,omitempty"`
+	Description   *string      `json:"description,omitempty"`
+}
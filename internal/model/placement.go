@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultRackUnits is the height of a rack unless a placement request says
+// otherwise
+const DefaultRackUnits = 42
+
+// rackUnitPattern matches the rack units at the end of a device location,
+// such as "U12" or "U12-U13"
+var rackUnitPattern = regexp.MustCompile(`(?i)^(.*?)[\s,;/]*\bU\s?(\d{1,3})(?:\s?-\s?U?\s?(\d{1,3}))?$`)
+
+// ParseRackLocation splits a device location such as "Row A, Rack 07, U12"
+// or "Rack 3 U20-U21" into the rack and its lowest and highest unit. It
+// reports false when the location does not end in units after a rack.
+func ParseRackLocation(location string) (rack string, bottom, top int, ok bool) {
+	m := rackUnitPattern.FindStringSubmatch(strings.TrimSpace(location))
+	if m == nil || m[1] == "" {
+		return "", 0, 0, false
+	}
+	bottom, _ = strconv.Atoi(m[2])
+	top = bottom
+	if m[3] != "" {
+		top, _ = strconv.Atoi(m[3])
+	}
+	if bottom > top {
+		bottom, top = top, bottom
+	}
+	if bottom < 1 {
+		return "", 0, 0, false
+	}
+	return m[1], bottom, top, true
+}
+
+// RackLocation formats a rack and units as a device location, the inverse
+// of ParseRackLocation
+func RackLocation(rack string, bottom, top int) string {
+	if bottom == top {
+		return fmt.Sprintf("%s, U%d", rack, bottom)
+	}
+	return fmt.Sprintf("%s, U%d-U%d", rack, bottom, top)
+}
+
+// PlacementRequest describes a device to find room for. Datacenter and
+// Network are IDs or names; Tags are tags the IP pool must have.
+type PlacementRequest struct {
+	Datacenter string
+	Network    string
+	Units      int // rack units the device takes, default 1
+	RackUnits  int // height of the racks, default DefaultRackUnits
+	Tags       []string
+}
+
+// PlacementSuggestion is where a new device could go: racks with enough
+// contiguous free units, best fit first, and the next free IP of a pool
+// that fits. Notes explain what could not be found.
+type PlacementSuggestion struct {
+	DatacenterID   string            `json:"datacenter_id"`
+	DatacenterName string            `json:"datacenter_name"`
+	Units          int               `json:"units"`
+	Racks          []RackPlacement   `json:"racks"`
+	Address        *AddressPlacement `json:"address,omitempty"`
+	Notes          []string          `json:"notes,omitempty"`
+}
+
+// RackPlacement is a free run of units in a rack. Racks are the racks device
+// locations name, and Location is the location to give the new device.
+type RackPlacement struct {
+	Rack      string `json:"rack"`
+	Location  string `json:"location"`
+	Bottom    int    `json:"bottom_unit"`
+	Top       int    `json:"top_unit"`
+	FreeUnits int    `json:"free_units"` // in the whole rack
+	Devices   int    `json:"devices"`
+}
+
+// AddressPlacement is the next free IP of a pool. It is not reserved.
+type AddressPlacement struct {
+	IP          string `json:"ip"`
+	PoolID      string `json:"pool_id"`
+	PoolName    string `json:"pool_name"`
+	NetworkID   string `json:"network_id"`
+	NetworkName string `json:"network_name"`
+}
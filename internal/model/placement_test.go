@@ -0,0 +1,35 @@
+package model
+
+import "testing"
+
+func TestParseRackLocation(t *testing.T) {
+	tests := []struct {
+		location    string
+		rack        string
+		bottom, top int
+		ok          bool
+	}{
+		{"Row A, Rack 07, U12", "Row A, Rack 07", 12, 12, true},
+		{"Rack 3 U20-U21", "Rack 3", 20, 21, true},
+		{"rack-3/u21-20", "rack-3", 20, 21, true},
+		{"Rack 3, U 5 - 6", "Rack 3", 5, 6, true},
+		{"U12", "", 0, 0, false},
+		{"Rack 3, U0", "", 0, 0, false},
+		{"Server room", "", 0, 0, false},
+		{"Row A, RU12", "", 0, 0, false},
+	}
+	for _, tt := range tests {
+		rack, bottom, top, ok := ParseRackLocation(tt.location)
+		if rack != tt.rack || bottom != tt.bottom || top != tt.top || ok != tt.ok {
+			t.Errorf("ParseRackLocation(%q) = %q, %d, %d, %v; want %q, %d, %d, %v",
+				tt.location, rack, bottom, top, ok, tt.rack, tt.bottom, tt.top, tt.ok)
+		}
+	}
+
+	if got := RackLocation("Row A, Rack 07", 12, 12); got != "Row A, Rack 07, U12" {
+		t.Errorf("RackLocation = %q", got)
+	}
+	if got := RackLocation("Rack 3", 20, 21); got != "Rack 3, U20-U21" {
+		t.Errorf("RackLocation = %q", got)
+	}
+}
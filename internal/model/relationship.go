@@ -14,4 +14,93 @@ const (
 	RelationshipContains    = "contains"
 	RelationshipConnectedTo = "connected_to"
 	RelationshipDependsOn   = "depends_on"
+	RelationshipHostedOn    = "hosted_on"
 )
+
+// RelationshipType describes a relationship type and how its direction reads.
+// Undirected types link two devices symmetrically, so a link from A to B is
+// the same link as one from B to A. Acyclic types form a hierarchy in which a
+// device can never reach itself by following links of that type.
+type RelationshipType struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Directed    bool   `json:"directed"`
+	Acyclic     bool   `json:"acyclic"`
+	ParentLabel string `json:"parent_label"` // how the parent relates to the child
+	ChildLabel  string `json:"child_label"`  // how the child relates to the parent
+}
+
+// RelationshipTypes is the catalog of supported relationship types
+var RelationshipTypes = []RelationshipType{
+	{
+		Name:        RelationshipContains,
+		Description: "Physical or logical containment, such as a rack holding servers or a chassis holding blades",
+		Directed:    true,
+		Acyclic:     true,
+		ParentLabel: "contains",
+		ChildLabel:  "contained by",
+	},
+	{
+		Name:        RelationshipConnectedTo,
+		Description: "A network or power connection between two devices",
+		Directed:    false,
+		Acyclic:     false,
+		ParentLabel: "connected to",
+		ChildLabel:  "connected to",
+	},
+	{
+		Name:        RelationshipDependsOn,
+		Description: "The parent relies on the child to function, such as an application server on its database",
+		Directed:    true,
+		Acyclic:     true,
+		ParentLabel: "depends on",
+		ChildLabel:  "supports",
+	},
+	{
+		Name:        RelationshipHostedOn,
+		Description: "A virtual machine running on its hypervisor or container host. The parent must be a vm and can have only one host.",
+		Directed:    true,
+		Acyclic:     true,
+		ParentLabel: "hosted on",
+		ChildLabel:  "hosts",
+	},
+}
+
+// LookupRelationshipType returns the catalog entry for a relationship type
+func LookupRelationshipType(name string) (RelationshipType, bool) {
+	for _, t := range RelationshipTypes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return RelationshipType{}, false
+}
+
+// RelationshipTypeNames returns the names of the catalog's relationship types
+func RelationshipTypeNames() []string {
+	names := make([]string, len(RelationshipTypes))
+	for i, t := range RelationshipTypes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// ImpactedDevice is a device reached while walking relationships from the
+// device under analysis
+type ImpactedDevice struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Depth    int    `json:"depth"` // hops from the analyzed device
+	Via      string `json:"via"`   // device the walk reached this one from
+	Type     string `json:"type"`  // relationship type of that last hop
+}
+
+// DeviceImpact lists what a device's failure would affect and what the device
+// itself relies on, following depends_on and connected_to relationships
+type DeviceImpact struct {
+	DeviceID  string           `json:"device_id"`
+	MaxDepth  int              `json:"max_depth"`
+	Affected  []ImpactedDevice `json:"affected"`   // devices that would be affected if this one went down
+	DependsOn []ImpactedDevice `json:"depends_on"` // devices this one relies on
+	Truncated bool             `json:"truncated"`  // the depth limit stopped the walk before it ended
+}
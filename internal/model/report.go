@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// ReportInfo describes a canned report
+type ReportInfo struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Report is the result of running a canned report: a table with a header
+// row of Columns. Cells are strings or numbers, so spreadsheets can total them.
+type Report struct {
+	Name        string    `json:"name"`
+	Title       string    `json:"title"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Columns     []string  `json:"columns"`
+	Rows        [][]any   `json:"rows"`
+}
+
+// ReportParams tune a report. Days is the warranty window for
+// warranty-expiry and the scan age for unscanned-networks; zero picks the
+// report's default. Datacenter, by ID or name, and Tag narrow the devices of
+// exposure.
+type ReportParams struct {
+	Days       int
+	Datacenter string
+	Tag        string
+}
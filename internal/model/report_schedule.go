@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// MinReportInterval is the shortest time allowed between two runs of a
+// report schedule
+const MinReportInterval = time.Hour
+
+// ReportSchedule runs a canned report on a cron schedule, in the server's
+// time zone, and sends it as a CSV, PDF or XLSX attachment to notification
+// channels. The report runs with the permissions of the user who created the
+// schedule. LastError holds why the last run failed, or which channels it
+// could not reach.
+type ReportSchedule struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Report         string     `json:"report"`
+	Days           int        `json:"days,omitempty"`
+	Datacenter     string     `json:"datacenter,omitempty"`
+	Tag            string     `json:"tag,omitempty"`
+	Format         string     `json:"format"`
+	CronExpression string     `json:"cron_expression"`
+	ChannelIDs     []string   `json:"channel_ids"`
+	Enabled        bool       `json:"enabled"`
+	Description    string     `json:"description,omitempty"`
+	CreatedBy      string     `json:"created_by,omitempty"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Params returns the parameters the schedule runs its report with
+func (s *ReportSchedule) Params() ReportParams {
+	return ReportParams{Days: s.Days, Datacenter: s.Datacenter, Tag: s.Tag}
+}
+
+// ReportScheduleFilter for querying report schedules
+type ReportScheduleFilter struct {
+	Pagination
+	Report  string
+	Enabled *bool
+}
+
+// CreateReportScheduleRequest represents a request to create a report
+// schedule. Format defaults to csv and Enabled to true.
+type CreateReportScheduleRequest struct {
+	Name           string   `json:"name"`
+	Report         string   `json:"report"`
+	Days           int      `json:"days,omitempty"`
+	Datacenter     string   `json:"datacenter,omitempty"`
+	Tag            string   `json:"tag,omitempty"`
+	Format         string   `json:"format,omitempty"`
+	CronExpression string   `json:"cron_expression"`
+	ChannelIDs     []string `json:"channel_ids"`
+	Enabled        *bool    `json:"enabled,omitempty"`
+	Description    string   `json:"description,omitempty"`
+}
+
+// UpdateReportScheduleRequest represents a request to update a report
+// schedule
+type UpdateReportScheduleRequest struct {
+	Name           *string   `json:"name,omitempty"`
+	Report         *string   `json:"report,omitempty"`
+	Days           *int      `json:"days,omitempty"`
+	Datacenter     *string   `json:"datacenter,omitempty"`
+	Tag            *string   `json:"tag,omitempty"`
+	Format         *string   `json:"format,omitempty"`
+	CronExpression *string   `json:"cron_expression,omitempty"`
+	ChannelIDs     *[]string `json:"channel_ids,omitempty"`
+	Enabled        *bool     `json:"enabled,omitempty"`
+	Description    *string   `json:"description,omitempty"`
+}
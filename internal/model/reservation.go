@@ -17,6 +17,7 @@ type Reservation struct {
 	ID          string            `json:"id"`
 	PoolID      string            `json:"pool_id"`
 	IPAddress   string            `json:"ip_address"`
+	DeviceID    string            `json:"device_id,omitempty"`
 	Hostname    string            `json:"hostname,omitempty"`
 	Purpose     string            `json:"purpose,omitempty"`
 	ReservedBy  string            `json:"reserved_by"`
@@ -55,6 +56,21 @@ type UpdateReservationRequest struct {
 	Notes     string     `json:"notes,omitempty"`
 }
 
+// AllocateIPRequest represents a request to reserve the next free IP of a
+// pool. The reservation lapses after TTLSeconds unless it is released first.
+type AllocateIPRequest struct {
+	TTLSeconds int    `json:"ttl_seconds,omitempty"` // Optional - defaults to one hour
+	DeviceID   string `json:"device_id,omitempty"`
+	Hostname   string `json:"hostname,omitempty"`
+	Purpose    string `json:"purpose,omitempty"`
+	Notes      string `json:"notes,omitempty"`
+}
+
+// ReleaseIPRequest represents a request to release a pool IP's reservation
+type ReleaseIPRequest struct {
+	IPAddress string `json:"ip_address"`
+}
+
 // IsExpired checks if the reservation has expired
 func (r *Reservation) IsExpired() bool {
 	if r.ExpiresAt == nil {
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Scan blackout types
+const (
+	BlackoutWeekly = "weekly"
+	BlackoutCron   = "cron"
+)
+
+// BlackoutWeekdays are the day names accepted in a weekly blackout
+var BlackoutWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ScanBlackout is a recurring period during which scheduled discovery scans
+// must not run, on one network or on every network when NetworkID is empty.
+// A weekly blackout runs from StartTime to EndTime ("HH:MM") on each of its
+// weekdays, every day when none are listed, and ends the next day when
+// EndTime is not after StartTime. A cron blackout starts at each match of
+// CronExpression and lasts DurationMinutes. Times are in Timezone, UTC when
+// empty.
+type ScanBlackout struct {
+	ID              string    `json:"id"`
+	NetworkID       string    `json:"network_id,omitempty"`
+	Name            string    `json:"name"`
+	Enabled         bool      `json:"enabled"`
+	Type            string    `json:"type"`
+	Weekdays        []string  `json:"weekdays,omitempty"`
+	StartTime       string    `json:"start_time,omitempty"`
+	EndTime         string    `json:"end_time,omitempty"`
+	CronExpression  string    `json:"cron_expression,omitempty"`
+	DurationMinutes int       `json:"duration_minutes,omitempty"`
+	Timezone        string    `json:"timezone,omitempty"`
+	Description     string    `json:"description,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Location returns the time zone the blackout is defined in
+func (b *ScanBlackout) Location() (*time.Location, error) {
+	if b.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(b.Timezone)
+}
+
+// Schedule parses the cron expression of a cron blackout
+func (b *ScanBlackout) Schedule() (cron.Schedule, error) {
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	return parser.Parse(b.CronExpression)
+}
+
+// Covers reports whether the blackout applies to scans of a network
+func (b *ScanBlackout) Covers(networkID string) bool {
+	return b.NetworkID == "" || b.NetworkID == networkID
+}
+
+// ActiveAt reports whether an enabled blackout is in effect at t, and when
+// the window in effect ends. Invalid definitions are never active.
+func (b *ScanBlackout) ActiveAt(t time.Time) (time.Time, bool) {
+	if !b.Enabled {
+		return time.Time{}, false
+	}
+	loc, err := b.Location()
+	if err != nil {
+		return time.Time{}, false
+	}
+	t = t.In(loc)
+
+	switch b.Type {
+	case BlackoutCron:
+		schedule, err := b.Schedule()
+		if err != nil || b.DurationMinutes <= 0 {
+			return time.Time{}, false
+		}
+		duration := time.Duration(b.DurationMinutes) * time.Minute
+		// Windows that started within the last duration are still open
+		var end time.Time
+		for start := schedule.Next(t.Add(-duration)); !start.After(t); start = schedule.Next(start) {
+			end = start.Add(duration)
+		}
+		return end, !end.IsZero()
+
+	case BlackoutWeekly:
+		startTime, err := time.Parse("15:04", b.StartTime)
+		if err != nil {
+			return time.Time{}, false
+		}
+		endTime, err := time.Parse("15:04", b.EndTime)
+		if err != nil {
+			return time.Time{}, false
+		}
+		// A window that wraps past midnight may have started the day before
+		for offset := -1; offset <= 0; offset++ {
+			day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, loc)
+			if !b.onWeekday(day.Weekday()) {
+				continue
+			}
+			start := time.Date(day.Year(), day.Month(), day.Day(), startTime.Hour(), startTime.Minute(), 0, 0, loc)
+			end := time.Date(day.Year(), day.Month(), day.Day(), endTime.Hour(), endTime.Minute(), 0, 0, loc)
+			if !end.After(start) {
+				end = end.AddDate(0, 0, 1)
+			}
+			if !t.Before(start) && t.Before(end) {
+				return end, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (b *ScanBlackout) onWeekday(day time.Weekday) bool {
+	if len(b.Weekdays) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(b.Weekdays, func(name string) bool {
+		d, ok := BlackoutWeekdays[name]
+		return ok && d == day
+	})
+}
+
+// ActiveBlackout returns the blackout in effect for a network at t, and the
+// time it ends. When several overlap the one ending last is returned.
+func ActiveBlackout(blackouts []ScanBlackout, networkID string, t time.Time) (*ScanBlackout, time.Time) {
+	var active *ScanBlackout
+	var until time.Time
+	for i := range blackouts {
+		if !blackouts[i].Covers(networkID) {
+			continue
+		}
+		if end, ok := blackouts[i].ActiveAt(t); ok && end.After(until) {
+			active = &blackouts[i]
+			until = end
+		}
+	}
+	return active, until
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScanBlackoutActiveAt(t *testing.T) {
+	// 2026-10-16 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+	overnight := ScanBlackout{Enabled: true, Type: BlackoutWeekly, Weekdays: []string{"fri"}, StartTime: "22:00", EndTime: "06:00"}
+	monthStart := ScanBlackout{Enabled: true, Type: BlackoutCron, CronExpression: "0 0 1 * *", DurationMinutes: 120}
+
+	tests := []struct {
+		name     string
+		blackout ScanBlackout
+		t        time.Time
+		active   bool
+		end      time.Time
+	}{
+		{"before weekly window", overnight, at(16, 21, 59), false, time.Time{}},
+		{"in weekly window", overnight, at(16, 22, 0), true, at(17, 6, 0)},
+		{"weekly window past midnight", overnight, at(17, 5, 59), true, at(17, 6, 0)},
+		{"weekly window ended", overnight, at(17, 6, 0), false, time.Time{}},
+		{"other weekday", overnight, at(15, 23, 0), false, time.Time{}},
+		{"in cron window", monthStart, time.Date(2026, 11, 1, 1, 30, 0, 0, time.UTC), true, time.Date(2026, 11, 1, 2, 0, 0, 0, time.UTC)},
+		{"cron window ended", monthStart, time.Date(2026, 11, 1, 2, 0, 0, 0, time.UTC), false, time.Time{}},
+		{"disabled", ScanBlackout{Type: BlackoutWeekly, StartTime: "00:00", EndTime: "00:00"}, at(16, 12, 0), false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, active := tt.blackout.ActiveAt(tt.t)
+			if active != tt.active || !end.Equal(tt.end) {
+				t.Errorf("ActiveAt(%v) = %v, %v; want %v, %v", tt.t, end, active, tt.end, tt.active)
+			}
+		})
+	}
+}
+
+func TestScanBlackoutTimezone(t *testing.T) {
+	b := ScanBlackout{Enabled: true, Type: BlackoutWeekly, StartTime: "09:00", EndTime: "17:00", Timezone: "America/New_York"}
+	// 14:00 UTC is 10:00 in New York during daylight saving time
+	if _, active := b.ActiveAt(time.Date(2026, 7, 1, 14, 0, 0, 0, time.UTC)); !active {
+		t.Error("expected blackout active during New York business hours")
+	}
+	if _, active := b.ActiveAt(time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC)); active {
+		t.Error("expected blackout inactive before 09:00 New York time")
+	}
+}
+
+func TestActiveBlackout(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	blackouts := []ScanBlackout{
+		{Name: "other network", NetworkID: "net-2", Enabled: true, Type: BlackoutWeekly, StartTime: "00:00", EndTime: "23:00"},
+		{Name: "global", Enabled: true, Type: BlackoutWeekly, StartTime: "11:00", EndTime: "13:00"},
+		{Name: "network", NetworkID: "net-1", Enabled: true, Type: BlackoutWeekly, StartTime: "11:00", EndTime: "14:00"},
+	}
+
+	active, until := ActiveBlackout(blackouts, "net-1", now)
+	if active == nil || active.Name != "network" || !until.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("expected the network blackout ending last, got %+v until %v", active, until)
+	}
+	if active, _ := ActiveBlackout(blackouts, "net-3", now); active == nil || active.Name != "global" {
+		t.Errorf("expected the global blackout for an uncovered network, got %+v", active)
+	}
+	if active, _ := ActiveBlackout(blackouts, "net-3", now.Add(2*time.Hour)); active != nil {
+		t.Errorf("expected no blackout, got %+v", active)
+	}
+}
@@ -40,6 +40,7 @@ type SnapshotFilter struct {
 // DeviceStatusCounts for dashboard device status breakdown
 type DeviceStatusCounts struct {
 	Planned       int `json:"planned"`
+	Ordered       int `json:"ordered"`
 	Active        int `json:"active"`
 	Maintenance   int `json:"maintenance"`
 	Decommissioned int `json:"decommissioned"`
@@ -104,3 +105,105 @@ type DashboardStats struct {
 	StaleThresholdDays int          `json:"stale_threshold_days"`
 	StaleDeviceList    []StaleDevice `json:"stale_device_list"`
 }
+
+// ValueCount counts the devices sharing a tag or operating system
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// RackUsage counts the devices a rack contains. Racks are devices with
+// "contains" relationships to their members.
+type RackUsage struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Location string `json:"location,omitempty"`
+	Devices  int    `json:"devices"`
+}
+
+// RecentChange is a device or network that was recently created or updated
+type RecentChange struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DatacenterStats aggregated statistics for a datacenter's dashboard page
+type DatacenterStats struct {
+	DatacenterID string `json:"datacenter_id"`
+
+	// Devices
+	TotalDevices       int                `json:"total_devices"`
+	DeviceStatusCounts DeviceStatusCounts `json:"device_status_counts"`
+	DevicesByTag       []ValueCount       `json:"devices_by_tag"`
+	DevicesByOS        []ValueCount       `json:"devices_by_os"`
+
+	// Networks and pools
+	TotalNetworks   int              `json:"total_networks"`
+	PoolUtilization PoolStatsSummary `json:"pool_utilization"`
+
+	// Rack space
+	Racks []RackUsage `json:"racks"`
+
+	RecentChanges []RecentChange `json:"recent_changes"`
+}
+
+// Inventory metrics recorded daily by the snapshot worker. Pool metrics are
+// recorded for each pool and, without a resource ID, for all pools together.
+const (
+	MetricDevicesTotal        = "devices.total"
+	MetricDevicesDiscovered   = "devices.discovered"
+	MetricPoolsUtilization    = "pools.utilization"
+	MetricPoolsUsedIPs        = "pools.used_ips"
+	MetricPoolsTotalIPs       = "pools.total_ips"
+	MetricNetworksUtilization = "networks.utilization"
+)
+
+// MetricDevicesStatus is the name of the metric counting devices with a status
+func MetricDevicesStatus(status DeviceStatus) string {
+	return "devices." + string(status)
+}
+
+// InventoryMetric is the value of a metric on a day
+type InventoryMetric struct {
+	Day          string    `json:"day"` // YYYY-MM-DD, UTC
+	Name         string    `json:"name"`
+	ResourceID   string    `json:"resource_id,omitempty"`
+	ResourceName string    `json:"resource_name,omitempty"`
+	Value        float64   `json:"value"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
+
+// InventoryMetricFilter for querying inventory metrics
+type InventoryMetricFilter struct {
+	Names      []string
+	ResourceID string
+	// Since is the first day returned, as YYYY-MM-DD
+	Since string
+}
+
+// MetricPoint is the value of a metric on a day
+type MetricPoint struct {
+	Day   string  `json:"day"`
+	Value float64 `json:"value"`
+}
+
+// MetricSeries is the daily history of a metric, for one pool when
+// ResourceID is set
+type MetricSeries struct {
+	Name         string              `json:"name"`
+	ResourceID   string              `json:"resource_id,omitempty"`
+	ResourceName string              `json:"resource_name,omitempty"`
+	Points       []MetricPoint       `json:"points"`
+	Forecast     *ExhaustionForecast `json:"forecast,omitempty"`
+}
+
+// ExhaustionForecast extrapolates a growing utilization metric to the day it
+// reaches 100%, from a straight line fitted to its history
+type ExhaustionForecast struct {
+	// GrowthPerDay is the fitted growth in percentage points per day
+	GrowthPerDay float64 `json:"growth_per_day"`
+	DaysLeft     int     `json:"days_left"`
+	ExhaustedOn  string  `json:"exhausted_on"`
+}
@@ -0,0 +1,54 @@
+package model
+
+// Topology node types
+const (
+	TopologyNodeDevice     = "device"
+	TopologyNodeNetwork    = "network"
+	TopologyNodeDatacenter = "datacenter" // topology queries only
+)
+
+// Topology edge types besides the device relationship types, which are used
+// as edge types unchanged
+const (
+	TopologyEdgeMember = "member" // device address in a network
+	TopologyEdgeSubnet = "subnet" // supernet to nested network
+)
+
+// Topology is a graph of devices and networks and the links between them
+type Topology struct {
+	DatacenterID string         `json:"datacenter_id,omitempty"`
+	Nodes        []TopologyNode `json:"nodes"`
+	Edges        []TopologyEdge `json:"edges"`
+}
+
+// TopologyNode is a device or network in a topology graph
+type TopologyNode struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Label        string `json:"label"`
+	DatacenterID string `json:"datacenter_id,omitempty"`
+	Subnet       string `json:"subnet,omitempty"` // networks only
+	Status       string `json:"status,omitempty"` // devices only
+}
+
+// TopologyEdge links two nodes. Relationship edges run from parent to child,
+// member edges from device to network and subnet edges from supernet to
+// subnet.
+type TopologyEdge struct {
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	Type       string `json:"type"`
+	Label      string `json:"label,omitempty"`       // address IP or relationship notes
+	SwitchPort string `json:"switch_port,omitempty"` // member edges only
+}
+
+// TopologyQuery is the result of walking a path through the inventory, such
+// as devices[tag:db].parents(depends_on).datacenter. Each of Paths is one
+// chain of nodes the path matched, with a node per step.
+type TopologyQuery struct {
+	Query     string           `json:"query"`
+	Steps     []string         `json:"steps"`
+	Count     int              `json:"count"`
+	Paths     [][]TopologyNode `json:"paths"`
+	Truncated bool             `json:"truncated,omitempty"` // more than MaxTopologyQueryPaths matched
+}
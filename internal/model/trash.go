@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Resource types that can be moved to the trash
+const (
+	TrashTypeDevice     = "device"
+	TrashTypeNetwork    = "network"
+	TrashTypeDatacenter = "datacenter"
+)
+
+// TrashItem is a deleted device, network or datacenter that can still be
+// restored until it is purged
+type TrashItem struct {
+	ID           string       `json:"id"`
+	Type         string       `json:"type"`
+	Name         string       `json:"name"`
+	DeletedAt    time.Time    `json:"deleted_at"`
+	DeletedBy    string       `json:"deleted_by,omitempty"`
+	DeletePolicy DeletePolicy `json:"delete_policy"`
+	PurgeAfter   *time.Time   `json:"purge_after,omitempty"` // unset when automatic purging is disabled
+}
+
+// TrashFilter holds the criteria for listing the trash
+type TrashFilter struct {
+	Pagination
+	Types         []string  // limit to these resource types; empty lists all
+	DeletedBefore time.Time // only items deleted before this time, when set
+}
@@ -13,6 +13,10 @@ type User struct {
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
+	// OIDCIssuer and OIDCSubject identify the user at the OIDC provider
+	// they sign in with; empty for users that sign in with a password
+	OIDCIssuer  string `json:"-"`
+	OIDCSubject string `json:"-"`
 }
 
 type UserFilter struct {
@@ -28,6 +28,12 @@ const (
 
 	// Pool events
 	EventTypePoolUtilization EventType = "pool.utilization_high"
+
+	// Availability and lifecycle events
+	EventTypeDeviceDown             EventType = "monitor.device_down"
+	EventTypeDeviceUp               EventType = "monitor.device_up"
+	EventTypeDeviceWarrantyExpiring EventType = "device.warranty_expiring"
+	EventTypeSSHHostKeyChanged      EventType = "device.ssh_host_key_changed"
 )
 
 // AllEventTypes contains all available event types
@@ -45,6 +51,10 @@ var AllEventTypes = []EventType{
 	EventTypeConflictDetected,
 	EventTypeConflictResolved,
 	EventTypePoolUtilization,
+	EventTypeDeviceDown,
+	EventTypeDeviceUp,
+	EventTypeDeviceWarrantyExpiring,
+	EventTypeSSHHostKeyChanged,
 }
 
 // IsValid checks if the event type is valid
@@ -154,11 +164,32 @@ type EventPayloadNetwork struct {
 	Subnet string `json:"subnet"`
 }
 
-// EventPayloadDiscovery contains discovery event data
+// EventPayloadDiscovery contains discovery event data. Completed scans also
+// summarise how the network changed since the previous completed scan: hosts
+// never discovered before, hosts seen by the previous scan but not this one,
+// and known hosts whose open ports changed.
 type EventPayloadDiscovery struct {
-	NetworkID   string `json:"network_id,omitempty"`
-	DevicesFound int   `json:"devices_found,omitempty"`
-	Duration    int64  `json:"duration_ms,omitempty"`
+	ScanID           string          `json:"scan_id,omitempty"`
+	NetworkID        string          `json:"network_id,omitempty"`
+	NetworkName      string          `json:"network_name,omitempty"`
+	ScanType         string          `json:"scan_type,omitempty"`
+	DevicesFound     int             `json:"devices_found,omitempty"`
+	Duration         int64           `json:"duration_ms,omitempty"`
+	NewHosts         []string        `json:"new_hosts,omitempty"`
+	DisappearedHosts []string        `json:"disappeared_hosts,omitempty"`
+	ChangedServices  []ServiceChange `json:"changed_services,omitempty"`
+}
+
+// HasChanges reports whether the scan found new, disappeared or changed hosts
+func (p *EventPayloadDiscovery) HasChanges() bool {
+	return len(p.NewHosts) > 0 || len(p.DisappearedHosts) > 0 || len(p.ChangedServices) > 0
+}
+
+// ServiceChange lists the ports that opened or closed on a known host
+type ServiceChange struct {
+	IP          string `json:"ip"`
+	OpenedPorts []int  `json:"opened_ports,omitempty"`
+	ClosedPorts []int  `json:"closed_ports,omitempty"`
 }
 
 // EventPayloadConflict contains conflict event data
@@ -178,6 +209,46 @@ type EventPayloadPoolUtilization struct {
 	Threshold   float64 `json:"threshold"`
 }
 
+// EventPayloadAvailability contains the device availability change found by a
+// monitor check
+type EventPayloadAvailability struct {
+	DeviceID       string `json:"device_id"`
+	DeviceName     string `json:"device_name"`
+	CheckID        string `json:"check_id"`
+	CheckName      string `json:"check_name"`
+	CheckType      string `json:"check_type"`
+	Port           int    `json:"port,omitempty"`
+	IP             string `json:"ip"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+	Message        string `json:"message,omitempty"`
+}
+
+// EventPayloadWarranty lists the devices whose warranty expires in Days days
+type EventPayloadWarranty struct {
+	Days    int              `json:"days"`
+	Devices []WarrantyDevice `json:"devices"`
+}
+
+// WarrantyDevice is a device in a warranty reminder
+type WarrantyDevice struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	WarrantyExpiry time.Time `json:"warranty_expiry"`
+}
+
+// EventPayloadSSHHostKey reports a documented device whose SSH host key
+// changed between discovery scans, after a reinstall or because another host
+// answers for it
+type EventPayloadSSHHostKey struct {
+	DeviceID            string `json:"device_id"`
+	DeviceName          string `json:"device_name"`
+	IP                  string `json:"ip"`
+	KeyType             string `json:"key_type"`
+	Fingerprint         string `json:"fingerprint"`
+	PreviousFingerprint string `json:"previous_fingerprint"`
+}
+
 // CreateWebhookRequest represents a request to create a webhook
 type CreateWebhookRequest struct {
 	Name        string      `json:"name"`
@@ -0,0 +1,98 @@
+// Package monitor implements the ICMP and TCP probes used by availability
+// checks.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// Prober checks whether an address is reachable and returns the round-trip time
+type Prober interface {
+	Probe(ctx context.Context, checkType, ip string, port int, timeout time.Duration) (time.Duration, error)
+}
+
+// NetProber probes with the system ping command and TCP connects. ICMP uses
+// the ping binary rather than raw sockets so rackd does not need
+// CAP_NET_RAW.
+type NetProber struct{}
+
+// NewNetProber creates a prober using the host network
+func NewNetProber() *NetProber {
+	return &NetProber{}
+}
+
+// Probe runs a single check against ip
+func (p *NetProber) Probe(ctx context.Context, checkType, ip string, port int, timeout time.Duration) (time.Duration, error) {
+	if net.ParseIP(ip) == nil {
+		return 0, fmt.Errorf("invalid IP address %q", ip)
+	}
+
+	switch checkType {
+	case model.MonitorCheckTCP:
+		return probeTCP(ctx, ip, port, timeout)
+	case model.MonitorCheckICMP:
+		return probeICMP(ctx, ip, timeout)
+	default:
+		return 0, fmt.Errorf("unsupported check type %q", checkType)
+	}
+}
+
+func probeTCP(ctx context.Context, ip string, port int, timeout time.Duration) (time.Duration, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+	if err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(start)
+	conn.Close()
+	return elapsed, nil
+}
+
+func probeICMP(ctx context.Context, ip string, timeout time.Duration) (time.Duration, error) {
+	timeoutSec := int(timeout.Seconds())
+	if timeoutSec < 1 {
+		timeoutSec = 1
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "ping", "-c", "1", "-t", strconv.Itoa(timeoutSec), ip)
+	default: // linux and others
+		cmd = exec.CommandContext(ctx, "ping", "-c", "1", "-W", strconv.Itoa(timeoutSec), ip)
+	}
+
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("no reply from %s", ip)
+	}
+	if rtt, ok := parsePingTime(string(output)); ok {
+		return rtt, nil
+	}
+	return time.Since(start), nil
+}
+
+var pingTimeRegex = regexp.MustCompile(`time[=<]([\d.]+) ?ms`)
+
+func parsePingTime(output string) (time.Duration, bool) {
+	matches := pingTimeRegex.FindStringSubmatch(output)
+	if len(matches) < 2 {
+		return 0, false
+	}
+	ms, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(ms * float64(time.Millisecond)), true
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestProbeTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	p := NewNetProber()
+	if _, err := p.Probe(context.Background(), model.MonitorCheckTCP, "127.0.0.1", port, time.Second); err != nil {
+		t.Errorf("expected open port to be up: %v", err)
+	}
+
+	ln.Close()
+	if _, err := p.Probe(context.Background(), model.MonitorCheckTCP, "127.0.0.1", port, time.Second); err == nil {
+		t.Error("expected closed port to be down")
+	}
+}
+
+func TestProbe_InvalidInput(t *testing.T) {
+	p := NewNetProber()
+	if _, err := p.Probe(context.Background(), model.MonitorCheckICMP, "127.0.0.1; rm -rf /", 0, time.Second); err == nil {
+		t.Error("expected error for invalid IP")
+	}
+	if _, err := p.Probe(context.Background(), "http", "127.0.0.1", 80, time.Second); err == nil {
+		t.Error("expected error for unsupported check type")
+	}
+}
+
+func TestParsePingTime(t *testing.T) {
+	tests := []struct {
+		output string
+		want   time.Duration
+		ok     bool
+	}{
+		{"64 bytes from 10.0.0.1: icmp_seq=1 ttl=64 time=1.23 ms", 1230 * time.Microsecond, true},
+		{"64 bytes from 10.0.0.1: icmp_seq=0 ttl=64 time=0.081 ms", 81 * time.Microsecond, true},
+		{"Reply from 10.0.0.1: bytes=32 time<1ms TTL=128", time.Millisecond, true},
+		{"Reply from 10.0.0.1: bytes=32 time=12ms TTL=128", 12 * time.Millisecond, true},
+		{"Request timeout", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePingTime(tt.output)
+		if ok != tt.ok || (ok && (got-tt.want) > time.Microsecond) {
+			t.Errorf("parsePingTime(%q) = %v, %v; want %v, %v", tt.output, got, ok, tt.want, tt.ok)
+		}
+	}
+}
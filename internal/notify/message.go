@@ -0,0 +1,229 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// EventTest is the type of test notifications, which only the channel being
+// tested receives. The payload names the channel.
+const EventTest model.EventType = "notification.test"
+
+// EventReportScheduled is the type of scheduled report messages, which only
+// the channels of the report schedule receive. The payload names the
+// schedule and the report.
+const EventReportScheduled model.EventType = "report.scheduled"
+
+// Message is a notification rendered for people: a one-line title and a
+// plain text body. Event is the event it describes, for channels that pass
+// the structured data on. Attachments are sent by email, webhook and ntfy
+// channels; Gotify channels only get the text.
+type Message struct {
+	Title       string       `json:"title"`
+	Body        string       `json:"message"`
+	Event       model.Event  `json:"event"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a file sent with a message. Content is base64 encoded in
+// JSON.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
+
+// FormatEvent renders an event as a notification. Completed discovery scans,
+// availability changes, warranty reminders and changed SSH host keys are
+// summarised; other events list their payload.
+func FormatEvent(event model.Event) *Message {
+	if p := discoveryPayload(event); p != nil && event.Type == model.EventTypeDiscoveryCompleted {
+		return &Message{Title: discoveryTitle(p), Body: discoveryBody(p), Event: event}
+	}
+	switch p := event.Payload.(type) {
+	case *model.EventPayloadAvailability:
+		return &Message{Title: availabilityTitle(p), Body: availabilityBody(p), Event: event}
+	case *model.EventPayloadWarranty:
+		return &Message{Title: warrantyTitle(p), Body: warrantyBody(p), Event: event}
+	case *model.EventPayloadSSHHostKey:
+		return &Message{Title: fmt.Sprintf("Rackd: the SSH host key of %s changed", p.DeviceName), Body: hostKeyBody(p), Event: event}
+	}
+	if event.Type == EventTest {
+		return &Message{
+			Title: "Rackd: test notification",
+			Body:  fmt.Sprintf("This is a test notification from Rackd for the %s channel.\n", channelName(event.Payload)),
+			Event: event,
+		}
+	}
+
+	body, err := json.MarshalIndent(event.Payload, "", "  ")
+	if err != nil {
+		body = []byte(fmt.Sprint(event.Payload))
+	}
+	return &Message{Title: "Rackd: " + string(event.Type), Body: string(body), Event: event}
+}
+
+// FormatReport renders a report run by the named schedule as a notification
+// with the report attached
+func FormatReport(schedule string, report *model.Report, attachment Attachment) *Message {
+	rows := fmt.Sprintf("%d rows", len(report.Rows))
+	if len(report.Rows) == 1 {
+		rows = "1 row"
+	}
+	return &Message{
+		Title: fmt.Sprintf("Rackd: %s report", report.Title),
+		Body: fmt.Sprintf("The %s schedule ran the %s report at %s: %s, attached as %s.\n",
+			schedule, report.Title, report.GeneratedAt.UTC().Format(time.RFC3339), rows, attachment.Filename),
+		Event: model.Event{
+			Type:      EventReportScheduled,
+			Timestamp: report.GeneratedAt,
+			Payload:   map[string]any{"schedule": schedule, "report": report.Name, "rows": len(report.Rows)},
+		},
+		Attachments: []Attachment{attachment},
+	}
+}
+
+// IsUnchangedScan reports whether event is a completed discovery scan that
+// found no new, disappeared or changed hosts
+func IsUnchangedScan(event model.Event) bool {
+	p := discoveryPayload(event)
+	return p != nil && event.Type == model.EventTypeDiscoveryCompleted && !p.HasChanges()
+}
+
+func discoveryPayload(event model.Event) *model.EventPayloadDiscovery {
+	switch p := event.Payload.(type) {
+	case *model.EventPayloadDiscovery:
+		return p
+	case model.EventPayloadDiscovery:
+		return &p
+	}
+	return nil
+}
+
+func discoveryTitle(p *model.EventPayloadDiscovery) string {
+	network := p.NetworkName
+	if network == "" {
+		network = p.NetworkID
+	}
+	if !p.HasChanges() {
+		return fmt.Sprintf("Rackd: discovery scan of %s found no changes", network)
+	}
+	return fmt.Sprintf("Rackd: discovery scan of %s: %d new, %d disappeared, %d changed", network,
+		len(p.NewHosts), len(p.DisappearedHosts), len(p.ChangedServices))
+}
+
+func discoveryBody(p *model.EventPayloadDiscovery) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d hosts found", p.DevicesFound)
+	if p.ScanType != "" {
+		fmt.Fprintf(&b, " by a %s scan", p.ScanType)
+	}
+	if p.Duration > 0 {
+		fmt.Fprintf(&b, " in %s", (time.Duration(p.Duration) * time.Millisecond).Round(time.Second))
+	}
+	b.WriteString(".\n")
+
+	if !p.HasChanges() {
+		b.WriteString("\nNo new, disappeared or changed hosts since the previous scan.\n")
+		return b.String()
+	}
+	writeHosts(&b, "New hosts", p.NewHosts)
+	writeHosts(&b, "Disappeared hosts", p.DisappearedHosts)
+	if len(p.ChangedServices) > 0 {
+		fmt.Fprintf(&b, "\nChanged services (%d):\n", len(p.ChangedServices))
+		for _, c := range p.ChangedServices {
+			var parts []string
+			if len(c.OpenedPorts) > 0 {
+				parts = append(parts, "opened "+joinPorts(c.OpenedPorts))
+			}
+			if len(c.ClosedPorts) > 0 {
+				parts = append(parts, "closed "+joinPorts(c.ClosedPorts))
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", c.IP, strings.Join(parts, "; "))
+		}
+	}
+	return b.String()
+}
+
+func availabilityTitle(p *model.EventPayloadAvailability) string {
+	if p.Status == model.MonitorStatusUp {
+		return fmt.Sprintf("Rackd: %s is up again", p.DeviceName)
+	}
+	return fmt.Sprintf("Rackd: %s is %s", p.DeviceName, p.Status)
+}
+
+func availabilityBody(p *model.EventPayloadAvailability) string {
+	var b strings.Builder
+	check := p.CheckType
+	if p.Port > 0 {
+		check += " port " + strconv.Itoa(p.Port)
+	}
+	fmt.Fprintf(&b, "The %s check (%s) of %s at %s changed from %s to %s.\n",
+		p.CheckName, check, p.DeviceName, p.IP, p.PreviousStatus, p.Status)
+	if p.Message != "" {
+		fmt.Fprintf(&b, "\n%s\n", p.Message)
+	}
+	return b.String()
+}
+
+func warrantyTitle(p *model.EventPayloadWarranty) string {
+	if len(p.Devices) == 1 {
+		return fmt.Sprintf("Rackd: the warranty of %s expires in %s", p.Devices[0].Name, days(p.Days))
+	}
+	return fmt.Sprintf("Rackd: %d device warranties expire in %s", len(p.Devices), days(p.Days))
+}
+
+func warrantyBody(p *model.EventPayloadWarranty) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Warranties expiring in %s:\n", days(p.Days))
+	for _, d := range p.Devices {
+		fmt.Fprintf(&b, "  %s: %s\n", d.Name, d.WarrantyExpiry.Format(time.DateOnly))
+	}
+	return b.String()
+}
+
+func hostKeyBody(p *model.EventPayloadSSHHostKey) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The %s host key of %s at %s changed.\n\n", p.KeyType, p.DeviceName, p.IP)
+	fmt.Fprintf(&b, "  Previous: %s\n  Current:  %s\n\n", p.PreviousFingerprint, p.Fingerprint)
+	b.WriteString("Unless the host was reinstalled or its keys regenerated, another host may be answering for it.\n")
+	return b.String()
+}
+
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return strconv.Itoa(n) + " days"
+}
+
+// channelName returns the channel a test notification is for
+func channelName(payload any) string {
+	if m, ok := payload.(map[string]string); ok {
+		return m["channel"]
+	}
+	return ""
+}
+
+func writeHosts(b *strings.Builder, heading string, ips []string) {
+	if len(ips) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s (%d):\n", heading, len(ips))
+	for _, ip := range ips {
+		fmt.Fprintf(b, "  %s\n", ip)
+	}
+}
+
+func joinPorts(ports []int) string {
+	s := make([]string, len(ports))
+	for i, port := range ports {
+		s[i] = strconv.Itoa(port)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/martinsuchenak/rackd/internal/webhook"
+)
+
+// Store is the storage the notifier reads channels and user recipients from
+type Store interface {
+	storage.NotificationStorage
+	GetUser(ctx context.Context, id string) (*model.User, error)
+}
+
+// Notifier sends published events, such as discovery results, availability
+// changes and warranty reminders, to the notification channels subscribed to
+// them. Failed notifications are logged, not retried.
+type Notifier struct {
+	store  Store
+	sender *Sender
+}
+
+// NewNotifier creates a notifier
+func NewNotifier(store Store, sender *Sender) *Notifier {
+	return &Notifier{store: store, sender: sender}
+}
+
+// SMTPConfigured reports whether email channels can use the server's SMTP
+// relay
+func (n *Notifier) SMTPConfigured() bool {
+	return n.sender.SMTPConfigured()
+}
+
+// Start subscribes the notifier to the global event bus. Notifications are
+// sent by the event handler, so webhook.Drain waits for them on shutdown.
+func (n *Notifier) Start() {
+	webhook.Subscribe(n.HandleEvent)
+}
+
+// HandleEvent notifies every active channel subscribed to the event
+func (n *Notifier) HandleEvent(event model.Event) {
+	ctx := context.Background()
+
+	channels, err := n.store.GetNotificationChannelsForEvent(ctx, event.Type)
+	if err != nil {
+		log.Error("Failed to get notification channels", "event", event.Type, "error", err)
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	unchanged := IsUnchangedScan(event)
+
+	var wg sync.WaitGroup
+	for _, ch := range channels {
+		if ch.ChangesOnly && unchanged {
+			continue
+		}
+		wg.Add(1)
+		go func(ch model.NotificationChannel) {
+			defer wg.Done()
+			if err := n.Notify(ctx, &ch, event); err != nil {
+				log.Warn("Notification failed", "channel", ch.Name, "type", ch.Type, "event", event.Type, "error", err)
+			}
+		}(ch)
+	}
+	wg.Wait()
+}
+
+// Notify renders event with the channel's templates and sends it. Emails go
+// to the channel's addresses and its users' addresses.
+func (n *Notifier) Notify(ctx context.Context, ch *model.NotificationChannel, event model.Event) error {
+	msg, err := Render(ch, event)
+	if err != nil {
+		return err
+	}
+	return n.Deliver(ctx, ch, msg)
+}
+
+// Deliver sends an already rendered message to a channel, such as a
+// scheduled report. Emails go to the channel's addresses and its users'
+// addresses.
+func (n *Notifier) Deliver(ctx context.Context, ch *model.NotificationChannel, msg *Message) error {
+	if ch.Type == model.NotificationChannelEmail && len(ch.UserIDs) > 0 {
+		resolved := *ch
+		resolved.To = n.recipients(ctx, ch)
+		ch = &resolved
+	}
+	if err := n.sender.Send(ctx, ch, msg); err != nil {
+		return fmt.Errorf("%s: %w", ch.Type, err)
+	}
+	return nil
+}
+
+// recipients returns the channel's addresses followed by those of its users,
+// without duplicates. Users that no longer exist or have no email address
+// are skipped.
+func (n *Notifier) recipients(ctx context.Context, ch *model.NotificationChannel) []string {
+	seen := make(map[string]bool)
+	var to []string
+	add := func(addr string) {
+		if key := strings.ToLower(addr); addr != "" && !seen[key] {
+			seen[key] = true
+			to = append(to, addr)
+		}
+	}
+
+	for _, addr := range ch.To {
+		add(addr)
+	}
+	for _, id := range ch.UserIDs {
+		user, err := n.store.GetUser(ctx, id)
+		if err != nil {
+			log.Warn("Skipping notification recipient", "channel", ch.Name, "user", id, "error", err)
+			continue
+		}
+		if user.Email == "" {
+			log.Warn("Skipping notification recipient without an email address", "channel", ch.Name, "user", user.Username)
+			continue
+		}
+		add(user.Email)
+	}
+	return to
+}
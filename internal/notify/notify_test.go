@@ -0,0 +1,359 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+func init() {
+	log.Init("text", "error", io.Discard)
+}
+
+func scanEvent(p *model.EventPayloadDiscovery) model.Event {
+	return model.Event{Type: model.EventTypeDiscoveryCompleted, Timestamp: time.Now(), Payload: p}
+}
+
+func TestFormatEventDiscoverySummary(t *testing.T) {
+	msg := FormatEvent(scanEvent(&model.EventPayloadDiscovery{
+		NetworkName:      "office",
+		ScanType:         "full",
+		DevicesFound:     12,
+		Duration:         34_000,
+		NewHosts:         []string{"10.0.0.5", "10.0.0.6"},
+		DisappearedHosts: []string{"10.0.0.9"},
+		ChangedServices:  []model.ServiceChange{{IP: "10.0.0.7", OpenedPorts: []int{443, 8443}, ClosedPorts: []int{80}}},
+	}))
+
+	if want := "Rackd: discovery scan of office: 2 new, 1 disappeared, 1 changed"; msg.Title != want {
+		t.Errorf("Title = %q, want %q", msg.Title, want)
+	}
+	for _, want := range []string{
+		"12 hosts found by a full scan in 34s.",
+		"New hosts (2):\n  10.0.0.5\n  10.0.0.6\n",
+		"Disappeared hosts (1):\n  10.0.0.9\n",
+		"10.0.0.7: opened 443, 8443; closed 80\n",
+	} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("Body missing %q:\n%s", want, msg.Body)
+		}
+	}
+}
+
+func TestFormatEventUnchangedScan(t *testing.T) {
+	event := scanEvent(&model.EventPayloadDiscovery{NetworkID: "net-1", DevicesFound: 3})
+	msg := FormatEvent(event)
+	if want := "Rackd: discovery scan of net-1 found no changes"; msg.Title != want {
+		t.Errorf("Title = %q, want %q", msg.Title, want)
+	}
+	if !IsUnchangedScan(event) {
+		t.Error("IsUnchangedScan = false, want true")
+	}
+
+	other := model.Event{Type: model.EventTypeConflictDetected, Payload: model.EventPayloadConflict{ID: "c1", Type: "duplicate_ip"}}
+	msg = FormatEvent(other)
+	if msg.Title != "Rackd: conflict.detected" || !strings.Contains(msg.Body, `"duplicate_ip"`) {
+		t.Errorf("generic message = %+v", msg)
+	}
+	if IsUnchangedScan(other) {
+		t.Error("IsUnchangedScan(conflict) = true, want false")
+	}
+}
+
+// recordedRequest is a request received by the test server
+type recordedRequest struct {
+	path   string
+	header http.Header
+	body   string
+}
+
+func newRecordingServer(t *testing.T) (*httptest.Server, *Sender, <-chan recordedRequest) {
+	t.Helper()
+	requests := make(chan recordedRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- recordedRequest{path: r.URL.Path, header: r.Header, body: string(body)}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	// The SSRF-safe client refuses loopback, so use the server's own client
+	return srv, &Sender{client: srv.Client(), dialer: &net.Dialer{}}, requests
+}
+
+func TestSenderHTTPChannels(t *testing.T) {
+	srv, sender, requests := newRecordingServer(t)
+	msg := &Message{Title: "Scan\r\ndone", Body: "2 new hosts", Event: model.Event{Type: model.EventTypeDiscoveryCompleted}}
+	ctx := context.Background()
+
+	if err := sender.Send(ctx, &model.NotificationChannel{Type: model.NotificationChannelWebhook, URL: srv.URL + "/hook", Secret: "tok"}, msg); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+	req := <-requests
+	var got Message
+	if err := json.Unmarshal([]byte(req.body), &got); err != nil || got.Body != "2 new hosts" || got.Event.Type != model.EventTypeDiscoveryCompleted {
+		t.Errorf("webhook body = %s (%v)", req.body, err)
+	}
+	if req.header.Get("Authorization") != "Bearer tok" {
+		t.Errorf("webhook Authorization = %q", req.header.Get("Authorization"))
+	}
+
+	if err := sender.Send(ctx, &model.NotificationChannel{Type: model.NotificationChannelGotify, URL: srv.URL + "/", Secret: "app-token"}, msg); err != nil {
+		t.Fatalf("gotify: %v", err)
+	}
+	req = <-requests
+	if req.path != "/message" || req.header.Get("X-Gotify-Key") != "app-token" || !strings.Contains(req.body, `"priority":5`) {
+		t.Errorf("gotify request = %+v", req)
+	}
+
+	if err := sender.Send(ctx, &model.NotificationChannel{Type: model.NotificationChannelNtfy, URL: srv.URL + "/rackd"}, msg); err != nil {
+		t.Fatalf("ntfy: %v", err)
+	}
+	req = <-requests
+	if req.path != "/rackd" || req.body != "2 new hosts" || req.header.Get("Title") != "Scan  done" || req.header.Get("Authorization") != "" {
+		t.Errorf("ntfy request = %+v", req)
+	}
+
+	err := sender.Send(ctx, &model.NotificationChannel{Type: model.NotificationChannelNtfy, URL: srv.URL + "/fail"}, msg)
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Errorf("failing ntfy = %v, want status 401 error", err)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	date := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	msg := &Message{Title: "Scan\ndone", Body: "line 1\nline 2\n"}
+	got := string(emailMessage("rackd@example.com", []string{"ops@example.com", "noc@example.com"}, msg, date))
+
+	for _, want := range []string{
+		"From: rackd@example.com\r\n",
+		"To: ops@example.com, noc@example.com\r\n",
+		"Subject: Scan done\r\n",
+		"Date: Fri, 16 Oct 2026 09:00:00 +0000\r\n",
+		"\r\n\r\nline 1\r\nline 2\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestEmailMessageAttachments(t *testing.T) {
+	msg := &Message{
+		Title:       "Rackd: Exposure report",
+		Body:        "3 rows\n",
+		Attachments: []Attachment{{Filename: "exposure.csv", ContentType: "text/csv", Content: []byte("device,port\nweb-01,23\n")}},
+	}
+	raw := emailMessage("rackd@example.com", []string{"ops@example.com"}, msg, time.Now())
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage failed: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q (%v)", parsed.Header.Get("Content-Type"), err)
+	}
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	text, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("no text part: %v", err)
+	}
+	if body, _ := io.ReadAll(text); string(body) != "3 rows\r\n" {
+		t.Errorf("text part = %q", body)
+	}
+	file, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("no attachment part: %v", err)
+	}
+	if file.FileName() != "exposure.csv" || file.Header.Get("Content-Transfer-Encoding") != "base64" {
+		t.Errorf("attachment headers = %v", file.Header)
+	}
+	encoded, _ := io.ReadAll(file)
+	if content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", "")); err != nil || string(content) != "device,port\nweb-01,23\n" {
+		t.Errorf("attachment content = %q (%v)", content, err)
+	}
+}
+
+func TestSenderAttachments(t *testing.T) {
+	srv, sender, requests := newRecordingServer(t)
+	msg := FormatReport("weekly", &model.Report{Name: "exposure", Title: "Exposure", Rows: [][]any{{"web-01", 23}}},
+		Attachment{Filename: "exposure.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")})
+	ctx := context.Background()
+
+	if err := sender.Send(ctx, &model.NotificationChannel{Type: model.NotificationChannelWebhook, URL: srv.URL + "/hook"}, msg); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+	req := <-requests
+	var got Message
+	if err := json.Unmarshal([]byte(req.body), &got); err != nil || len(got.Attachments) != 1 || string(got.Attachments[0].Content) != "%PDF-1.4" ||
+		got.Event.Type != EventReportScheduled {
+		t.Errorf("webhook body = %s (%v)", req.body, err)
+	}
+
+	if err := sender.Send(ctx, &model.NotificationChannel{Type: model.NotificationChannelNtfy, URL: srv.URL + "/rackd"}, msg); err != nil {
+		t.Fatalf("ntfy: %v", err)
+	}
+	req = <-requests
+	if req.body != "%PDF-1.4" || req.header.Get("Filename") != "exposure.pdf" || !strings.Contains(req.header.Get("Message"), "1 row, attached as exposure.pdf") {
+		t.Errorf("ntfy request = %+v", req)
+	}
+}
+
+func TestSMTPSettings(t *testing.T) {
+	sender := NewSender(SMTPConfig{Host: "relay.example.com", Port: 25, Username: "rackd", Password: "pw", From: "rackd@example.com"})
+
+	got := sender.smtpSettings(&model.NotificationChannel{From: "noc@example.com"})
+	if got.Host != "relay.example.com" || got.Port != 25 || got.Password != "pw" || got.From != "noc@example.com" {
+		t.Errorf("relay settings = %+v", got)
+	}
+
+	got = sender.smtpSettings(&model.NotificationChannel{SMTPHost: "mail.example.com", Secret: "own", From: "ops@example.com"})
+	if got.Host != "mail.example.com" || got.Port != 587 || got.Username != "" || got.Password != "own" {
+		t.Errorf("channel settings = %+v", got)
+	}
+
+	err := NewSender(SMTPConfig{}).Send(context.Background(), &model.NotificationChannel{Type: model.NotificationChannelEmail, To: []string{"ops@example.com"}}, &Message{})
+	if err == nil || !strings.Contains(err.Error(), "SMTP_HOST") {
+		t.Errorf("email without a server = %v", err)
+	}
+}
+
+func TestFormatEventAvailabilityAndWarranty(t *testing.T) {
+	msg := FormatEvent(model.Event{Type: model.EventTypeDeviceDown, Payload: &model.EventPayloadAvailability{
+		DeviceName: "web01", CheckName: "https", CheckType: "tcp", Port: 443, IP: "10.0.0.5",
+		Status: "down", PreviousStatus: "up", Message: "connection refused",
+	}})
+	if msg.Title != "Rackd: web01 is down" || !strings.Contains(msg.Body, "The https check (tcp port 443) of web01 at 10.0.0.5 changed from up to down.") {
+		t.Errorf("availability message = %+v", msg)
+	}
+
+	expiry := time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC)
+	msg = FormatEvent(model.Event{Type: model.EventTypeDeviceWarrantyExpiring, Payload: &model.EventPayloadWarranty{
+		Days:    30,
+		Devices: []model.WarrantyDevice{{Name: "web01", WarrantyExpiry: expiry}, {Name: "db01", WarrantyExpiry: expiry}},
+	}})
+	if msg.Title != "Rackd: 2 device warranties expire in 30 days" || !strings.Contains(msg.Body, "  db01: 2026-11-15\n") {
+		t.Errorf("warranty message = %+v", msg)
+	}
+}
+
+func TestFormatEventSSHHostKey(t *testing.T) {
+	msg := FormatEvent(model.Event{Type: model.EventTypeSSHHostKeyChanged, Payload: &model.EventPayloadSSHHostKey{
+		DeviceName: "web01", IP: "10.0.0.5", KeyType: "ssh-ed25519",
+		Fingerprint: "SHA256:new", PreviousFingerprint: "SHA256:old",
+	}})
+	if msg.Title != "Rackd: the SSH host key of web01 changed" || !strings.Contains(msg.Body, "Previous: SHA256:old\n  Current:  SHA256:new") {
+		t.Errorf("host key message = %+v", msg)
+	}
+}
+
+func TestRender(t *testing.T) {
+	event := scanEvent(&model.EventPayloadDiscovery{NetworkName: "office", NewHosts: []string{"10.0.0.5", "10.0.0.6"}})
+
+	msg, err := Render(&model.NotificationChannel{}, event)
+	if err != nil || msg.Title != "Rackd: discovery scan of office: 2 new, 0 disappeared, 0 changed" {
+		t.Errorf("default message = %+v (%v)", msg, err)
+	}
+
+	msg, err = Render(&model.NotificationChannel{
+		TitleTemplate: "[{{upper .Payload.network_name}}] {{.Event}}",
+		BodyTemplate:  "New: {{join .Payload.new_hosts \", \"}}{{.Payload.missing}}\n---\n{{.Body}}",
+	}, event)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if msg.Title != "[OFFICE] discovery.completed" {
+		t.Errorf("Title = %q", msg.Title)
+	}
+	if !strings.HasPrefix(msg.Body, "New: 10.0.0.5, 10.0.0.6\n---\n") || !strings.Contains(msg.Body, "New hosts (2)") {
+		t.Errorf("Body = %q", msg.Body)
+	}
+
+	if err := ParseTemplate("{{.Payload.network_name"); err == nil {
+		t.Error("ParseTemplate accepted an unclosed action")
+	}
+	if _, err := Render(&model.NotificationChannel{BodyTemplate: "{{join}}"}, event); err == nil {
+		t.Error("Render accepted a template that fails to execute")
+	}
+}
+
+// channelStore serves fixed channels and users to the notifier
+type channelStore struct {
+	storage.NotificationStorage
+	channels []model.NotificationChannel
+	users    map[string]*model.User
+}
+
+func (s *channelStore) GetUser(_ context.Context, id string) (*model.User, error) {
+	if u, ok := s.users[id]; ok {
+		return u, nil
+	}
+	return nil, storage.ErrUserNotFound
+}
+
+func (s *channelStore) GetNotificationChannelsForEvent(_ context.Context, eventType model.EventType) ([]model.NotificationChannel, error) {
+	var channels []model.NotificationChannel
+	for _, ch := range s.channels {
+		for _, et := range ch.Events {
+			if et == eventType {
+				channels = append(channels, ch)
+			}
+		}
+	}
+	return channels, nil
+}
+
+func TestNotifierHandleEvent(t *testing.T) {
+	srv, sender, requests := newRecordingServer(t)
+	events := []model.EventType{model.EventTypeDiscoveryCompleted}
+	store := &channelStore{channels: []model.NotificationChannel{
+		{Name: "all", Type: model.NotificationChannelNtfy, URL: srv.URL + "/all", Events: events},
+		{Name: "changes", Type: model.NotificationChannelNtfy, URL: srv.URL + "/changes", Events: events, ChangesOnly: true},
+	}}
+	n := NewNotifier(store, sender)
+
+	n.HandleEvent(scanEvent(&model.EventPayloadDiscovery{NetworkID: "net-1"}))
+	n.HandleEvent(scanEvent(&model.EventPayloadDiscovery{NetworkID: "net-1", NewHosts: []string{"10.0.0.5"}}))
+	n.HandleEvent(model.Event{Type: model.EventTypeDeviceCreated})
+
+	// HandleEvent returns once every notification was sent
+	counts := map[string]int{}
+	for len(requests) > 0 {
+		counts[(<-requests).path]++
+	}
+	if counts["/all"] != 2 || counts["/changes"] != 1 || len(counts) != 2 {
+		t.Errorf("deliveries = %v, want 2 to /all and 1 to /changes", counts)
+	}
+}
+
+func TestNotifierRecipients(t *testing.T) {
+	store := &channelStore{users: map[string]*model.User{
+		"u1": {ID: "u1", Username: "alice", Email: "alice@example.com"},
+		"u2": {ID: "u2", Username: "bob"},
+		"u3": {ID: "u3", Username: "ops", Email: "OPS@example.com"},
+	}}
+	n := NewNotifier(store, NewSender(SMTPConfig{}))
+
+	ch := &model.NotificationChannel{Name: "mail", To: []string{"ops@example.com"}, UserIDs: []string{"u1", "u2", "u3", "gone"}}
+	got := n.recipients(context.Background(), ch)
+	if len(got) != 2 || got[0] != "ops@example.com" || got[1] != "alice@example.com" {
+		t.Errorf("recipients = %v, want [ops@example.com alice@example.com]", got)
+	}
+}
@@ -0,0 +1,284 @@
+// Package notify sends human-readable summaries of events, such as the
+// changes found by a discovery scan, to webhook, email, Gotify and ntfy
+// notification channels.
+package notify
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/webhook"
+)
+
+// Timeout bounds a single notification
+const Timeout = 30 * time.Second
+
+// gotifyPriority is the priority of Gotify messages; 4-7 show a notification
+// on Android clients
+const gotifyPriority = 5
+
+// SMTPConfig is the server's SMTP relay, used by email channels that don't
+// name their own SMTP server
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Sender delivers messages to notification channels
+type Sender struct {
+	client *http.Client
+	dialer *net.Dialer
+	smtp   SMTPConfig
+}
+
+// NewSender creates a sender. HTTP requests go through the SSRF-safe webhook
+// client.
+func NewSender(smtp SMTPConfig) *Sender {
+	return &Sender{
+		client: webhook.NewSecureHTTPClient(Timeout),
+		dialer: &net.Dialer{Timeout: Timeout},
+		smtp:   smtp,
+	}
+}
+
+// SMTPConfigured reports whether email channels can use the server's SMTP
+// relay
+func (s *Sender) SMTPConfigured() bool {
+	return s.smtp.Host != ""
+}
+
+// Send delivers msg to ch
+func (s *Sender) Send(ctx context.Context, ch *model.NotificationChannel, msg *Message) error {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	switch ch.Type {
+	case model.NotificationChannelWebhook:
+		return s.sendWebhook(ctx, ch, msg)
+	case model.NotificationChannelGotify:
+		return s.sendGotify(ctx, ch, msg)
+	case model.NotificationChannelNtfy:
+		return s.sendNtfy(ctx, ch, msg)
+	case model.NotificationChannelEmail:
+		return s.sendEmail(ctx, ch, msg)
+	}
+	return fmt.Errorf("unknown notification channel type: %s", ch.Type)
+}
+
+// sendWebhook posts the message, with the event it describes, as JSON
+func (s *Sender) sendWebhook(ctx context.Context, ch *model.NotificationChannel, msg *Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to serialize message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ch.Secret != "" {
+		req.Header.Set("Authorization", "Bearer "+ch.Secret)
+	}
+	return s.do(req)
+}
+
+// sendGotify creates a message through the Gotify application API
+func (s *Sender) sendGotify(ctx context.Context, ch *model.NotificationChannel, msg *Message) error {
+	body, err := json.Marshal(map[string]any{
+		"title":    msg.Title,
+		"message":  msg.Body,
+		"priority": gotifyPriority,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to serialize message: %w", err)
+	}
+	url := strings.TrimRight(ch.URL, "/") + "/message"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", ch.Secret)
+	return s.do(req)
+}
+
+// sendNtfy publishes the message to the ntfy topic URL. ntfy takes one
+// attachment per message, as the request body, so a message with
+// attachments sends the first one and moves the text to the Message header.
+func (s *Sender) sendNtfy(ctx context.Context, ch *model.NotificationChannel, msg *Message) error {
+	var body io.Reader = strings.NewReader(msg.Body)
+	if len(msg.Attachments) > 0 {
+		body = bytes.NewReader(msg.Attachments[0].Content)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.URL, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if len(msg.Attachments) > 0 {
+		req.Header.Set("Filename", headerValue(msg.Attachments[0].Filename))
+		req.Header.Set("Message", headerValue(msg.Body))
+	}
+	req.Header.Set("Title", headerValue(msg.Title))
+	req.Header.Set("Tags", "rackd")
+	if ch.Secret != "" {
+		req.Header.Set("Authorization", "Bearer "+ch.Secret)
+	}
+	return s.do(req)
+}
+
+func (s *Sender) do(req *http.Request) error {
+	req.Header.Set("User-Agent", "Rackd-Notify/1.0")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
+
+// smtpSettings returns the SMTP server the channel names, or the server's
+// relay when it names none. The channel's From wins either way.
+func (s *Sender) smtpSettings(ch *model.NotificationChannel) SMTPConfig {
+	cfg := SMTPConfig{Host: ch.SMTPHost, Port: ch.SMTPPort, Username: ch.Username, Password: ch.Secret, From: ch.From}
+	if cfg.Host == "" {
+		cfg = s.smtp
+		if ch.From != "" {
+			cfg.From = ch.From
+		}
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return cfg
+}
+
+// sendEmail sends the message over SMTP. Port 465 uses implicit TLS; other
+// ports upgrade with STARTTLS when the server offers it. The connection is
+// authenticated when a username is set.
+func (s *Sender) sendEmail(ctx context.Context, ch *model.NotificationChannel, msg *Message) error {
+	cfg := s.smtpSettings(ch)
+	if cfg.Host == "" {
+		return fmt.Errorf("no SMTP server: set the channel's smtp_host or SMTP_HOST")
+	}
+	if len(ch.To) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12}
+
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if cfg.Port == 465 {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && cfg.Port != 465 {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, to := range ch.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(emailMessage(cfg.From, ch.To, msg, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// emailMessage builds a plain text RFC 5322 message, or a multipart/mixed
+// one carrying the text and the attachments
+func emailMessage(from string, to []string, msg *Message, date time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(msg.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	text := strings.ReplaceAll(msg.Body, "\n", "\r\n")
+	if len(msg.Attachments) == 0 {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		b.WriteString("\r\n")
+		b.WriteString(text)
+		return b.Bytes()
+	}
+
+	mw := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%q\r\n", mw.Boundary())
+	b.WriteString("\r\n")
+	part, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	io.WriteString(part, text)
+	for _, a := range msg.Attachments {
+		part, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(cmp.Or(a.ContentType, "application/octet-stream"), map[string]string{"name": a.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		encoded := base64.StdEncoding.EncodeToString(a.Content)
+		for len(encoded) > 76 {
+			io.WriteString(part, encoded[:76]+"\r\n")
+			encoded = encoded[76:]
+		}
+		io.WriteString(part, encoded+"\r\n")
+	}
+	mw.Close()
+	return b.Bytes()
+}
+
+// headerValue keeps a value on one header line
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
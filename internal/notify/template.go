@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// TemplateData is what channel title and body templates are executed with.
+// Payload is the event payload as webhooks receive it, so its fields are
+// named like the JSON, such as {{.Payload.network_name}}. Title and Body are
+// the default message.
+type TemplateData struct {
+	Event     model.EventType
+	Timestamp time.Time
+	Payload   any
+	Title     string
+	Body      string
+}
+
+// templateFuncs are the functions templates can call besides the built-ins
+var templateFuncs = template.FuncMap{
+	"join":  joinAny,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseTemplate checks that a title or body template is valid
+func ParseTemplate(text string) error {
+	_, err := parseTemplate(text)
+	return err
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("notification").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// Render returns the message a channel sends for an event: the default
+// message, with its title and body replaced by the channel's templates
+func Render(ch *model.NotificationChannel, event model.Event) (*Message, error) {
+	msg := FormatEvent(event)
+	if ch.TitleTemplate == "" && ch.BodyTemplate == "" {
+		return msg, nil
+	}
+
+	data := TemplateData{Event: event.Type, Timestamp: event.Timestamp, Payload: jsonPayload(event.Payload), Title: msg.Title, Body: msg.Body}
+	if ch.TitleTemplate != "" {
+		title, err := execute(ch.TitleTemplate, data)
+		if err != nil {
+			return nil, fmt.Errorf("title template: %w", err)
+		}
+		msg.Title = strings.TrimSpace(title)
+	}
+	if ch.BodyTemplate != "" {
+		body, err := execute(ch.BodyTemplate, data)
+		if err != nil {
+			return nil, fmt.Errorf("body template: %w", err)
+		}
+		msg.Body = body
+	}
+	return msg, nil
+}
+
+func execute(text string, data TemplateData) (string, error) {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	// Payload fields an event lacks are missing map keys, which print as
+	// "<no value>" even with missingkey=zero
+	return strings.ReplaceAll(b.String(), "<no value>", ""), nil
+}
+
+// jsonPayload converts a payload to the maps and slices its JSON decodes to
+func jsonPayload(payload any) any {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return payload
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return payload
+	}
+	return v
+}
+
+// joinAny joins a list from a payload, such as {{join .Payload.new_hosts ", "}}
+func joinAny(values any, sep string) string {
+	list, ok := values.([]any)
+	if !ok {
+		if values == nil {
+			return ""
+		}
+		return fmt.Sprint(values)
+	}
+	s := make([]string, len(list))
+	for i, v := range list {
+		s[i] = fmt.Sprint(v)
+	}
+	return strings.Join(s, sep)
+}
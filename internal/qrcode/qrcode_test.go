@@ -0,0 +1,219 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// The version 1-M example from ISO/IEC 18004 annex I, "01234567"
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+	if got := rsRemainder(data, rsGenerator(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = % X, want % X", got, want)
+	}
+}
+
+func TestFormatAndVersionInfo(t *testing.T) {
+	c := newCode(7)
+	c.drawFunctionPatterns()
+	c.drawFormat(5)
+	// Level M with mask 5 is 100000011001110, read along row 8 from the left
+	want := "100000011001110"
+	var got strings.Builder
+	for _, x := range []int{0, 1, 2, 3, 4, 5, 7} {
+		got.WriteString(bit(c.Dark(x, 8)))
+	}
+	for _, y := range []int{8, 7, 5, 4, 3, 2, 1, 0} {
+		got.WriteString(bit(c.Dark(8, y)))
+	}
+	if got.String() != want {
+		t.Errorf("format bits = %s, want %s", got.String(), want)
+	}
+
+	// Version 7 is 000111110010010100, most significant bit last at (5, size-9)
+	wantVersion := 0x07C94
+	gotVersion := 0
+	for i := 17; i >= 0; i-- {
+		gotVersion <<= 1
+		if c.Dark(i/3, c.Size-11+i%3) {
+			gotVersion |= 1
+		}
+	}
+	if gotVersion != wantVersion {
+		t.Errorf("version bits = %05X, want %05X", gotVersion, wantVersion)
+	}
+}
+
+func bit(dark bool) string {
+	if dark {
+		return "1"
+	}
+	return "0"
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, data := range []string{
+		"https://rackd.example.com/devices/detail?id=0f8e7d6c-5b4a-3928-1706-f5e4d3c2b1a0",
+		"a",
+		strings.Repeat("x", 150),
+	} {
+		c, err := Encode(data)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) failed: %v", len(data), err)
+		}
+		if got := decode(t, c); got != data {
+			t.Errorf("decoded %q, want %q", got, data)
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("x", 214)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+// decode reads a code back using only its format bits and version, as a
+// scanner would once it has located the code
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+	format := 0
+	for _, x := range []int{0, 1, 2, 3, 4, 5, 7} {
+		format = format<<1 | boolInt(c.Dark(x, 8))
+	}
+	for _, y := range []int{8, 7, 5, 4, 3, 2, 1, 0} {
+		format = format<<1 | boolInt(c.Dark(8, y))
+	}
+	format ^= 0x5412
+	if format>>13 != 0 {
+		t.Fatalf("unexpected error correction level %d", format>>13)
+	}
+	mask := format >> 10 & 7
+
+	// Rebuild the function pattern map and read the data modules unmasked
+	ref := newCode(c.Version)
+	ref.drawFunctionPatterns()
+	for y := range ref.modules {
+		copy(ref.modules[y], c.modules[y])
+	}
+	ref.applyMask(mask)
+	layout := versionsM[c.Version]
+	total := layout.dataCodewords() + (layout.short+layout.long)*layout.ecPerBlock
+	raw := make([]byte, total)
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if ref.function[y][x] || i >= total*8 {
+					continue
+				}
+				raw[i/8] |= byte(boolInt(ref.modules[y][x])) << (7 - i%8)
+				i++
+			}
+		}
+	}
+
+	// De-interleave the data codewords and check each block's error correction
+	blocks := make([][]byte, layout.short+layout.long)
+	pos := 0
+	for k := 0; k <= layout.shortDataLen; k++ {
+		for b := range blocks {
+			if k < layout.shortDataLen || b >= layout.short {
+				blocks[b] = append(blocks[b], raw[pos])
+				pos++
+			}
+		}
+	}
+	generator := rsGenerator(layout.ecPerBlock)
+	var data []byte
+	for b, block := range blocks {
+		ec := rsRemainder(block, generator)
+		for k := range ec {
+			if raw[pos+k*len(blocks)+b] != ec[k] {
+				t.Fatalf("block %d has bad error correction", b)
+			}
+		}
+		data = append(data, block...)
+	}
+
+	if data[0]>>4 != 0b0100 {
+		t.Fatalf("unexpected mode %04b", data[0]>>4)
+	}
+	var bits bitReader
+	bits.data = data
+	bits.read(4)
+	n := bits.read(8)
+	if c.Version >= 10 {
+		n = n<<8 | bits.read(8)
+	}
+	out := make([]byte, n)
+	for k := range out {
+		out[k] = byte(bits.read(8))
+	}
+	return string(out)
+}
+
+type bitReader struct {
+	data []byte
+	n    int
+}
+
+func (r *bitReader) read(count int) int {
+	v := 0
+	for ; count > 0; count-- {
+		v = v<<1 | int(r.data[r.n/8]>>(7-r.n%8)&1)
+		r.n++
+	}
+	return v
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestWritePNGAndSVG(t *testing.T) {
+	c, err := Encode("https://rackd.example.com")
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.WritePNG(&buf, 4); err != nil {
+		t.Fatalf("WritePNG failed: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+	if want := (c.Size + 2*QuietZone) * 4; img.Bounds().Dx() != want {
+		t.Errorf("PNG width = %d, want %d", img.Bounds().Dx(), want)
+	}
+	// The top-left finder starts after the quiet zone
+	if r, _, _, _ := img.At(QuietZone*4, QuietZone*4).RGBA(); r != 0 {
+		t.Error("expected a dark finder module")
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Error("expected a light quiet zone")
+	}
+
+	buf.Reset()
+	if err := c.WriteSVG(&buf, 4); err != nil {
+		t.Fatalf("WriteSVG failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<svg") || !strings.Contains(buf.String(), `d="M4 4h7v1h-7z`) {
+		t.Errorf("unexpected SVG: %.120s", buf.String())
+	}
+}
@@ -0,0 +1,406 @@
+package search
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// MaxPathSteps is the most steps a path may have, its start included
+const MaxPathSteps = 8
+
+// A path walks the inventory graph one step at a time, e.g.
+//
+//	devices[tag:db].parents(depends_on).datacenter
+//
+// finds the devices tagged db, then the devices that depend on them, then the
+// datacenters those are in. The first step is devices, networks or
+// datacenters. Each following step moves from the nodes the previous step
+// reached to their neighbours:
+//
+//	from a device:     parents, children, networks, datacenter
+//	from a network:    devices, datacenter
+//	from a datacenter: devices, networks
+//
+// parents and children follow device relationships, of any type or of the
+// types listed in parentheses. A step reaching devices takes a device query
+// in square brackets; a step reaching networks or datacenters takes a name or
+// ID, with * and ? wildcards.
+type Path struct {
+	steps []pathStep
+}
+
+type pathStep struct {
+	text     string
+	kind     string
+	types    []string
+	query    *Query
+	name     *termNode
+	nodeType string
+}
+
+// nextSteps lists the steps allowed after each node type, and the node type
+// each step reaches. An empty from is the start of the path.
+var nextSteps = map[string]map[string]string{
+	"": {
+		"devices":     model.TopologyNodeDevice,
+		"networks":    model.TopologyNodeNetwork,
+		"datacenters": model.TopologyNodeDatacenter,
+	},
+	model.TopologyNodeDevice: {
+		"parents":    model.TopologyNodeDevice,
+		"children":   model.TopologyNodeDevice,
+		"networks":   model.TopologyNodeNetwork,
+		"datacenter": model.TopologyNodeDatacenter,
+	},
+	model.TopologyNodeNetwork: {
+		"devices":    model.TopologyNodeDevice,
+		"datacenter": model.TopologyNodeDatacenter,
+	},
+	model.TopologyNodeDatacenter: {
+		"devices":  model.TopologyNodeDevice,
+		"networks": model.TopologyNodeNetwork,
+	},
+}
+
+// ParsePath parses a path
+func ParsePath(path string) (*Path, error) {
+	texts, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(texts) > MaxPathSteps {
+		return nil, fmt.Errorf("path has %d steps, at most %d are allowed", len(texts), MaxPathSteps)
+	}
+
+	p := &Path{}
+	from := ""
+	for _, text := range texts {
+		step, err := parseStep(text, from)
+		if err != nil {
+			return nil, err
+		}
+		p.steps = append(p.steps, step)
+		from = step.nodeType
+	}
+	return p, nil
+}
+
+// splitPath splits a path at the dots outside brackets, parentheses and
+// quotes
+func splitPath(path string) ([]string, error) {
+	var steps []string
+	var cur strings.Builder
+	depth, inQuote := 0, false
+	for _, r := range path {
+		switch {
+		case inQuote:
+			inQuote = r != '"'
+		case r == '"':
+			inQuote = true
+		case r == '[' || r == '(':
+			depth++
+		case r == ']' || r == ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected %q", r)
+			}
+		case r == '.' && depth == 0:
+			steps = append(steps, strings.TrimSpace(cur.String()))
+			cur.Reset()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("missing closing bracket")
+	}
+	steps = append(steps, strings.TrimSpace(cur.String()))
+	if len(steps) == 1 && steps[0] == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+	for i, s := range steps {
+		if s == "" {
+			return nil, fmt.Errorf("step %d is empty", i+1)
+		}
+	}
+	return steps, nil
+}
+
+// parseStep parses one step: a name, then relationship types in parentheses
+// and a filter in square brackets, both optional
+func parseStep(text, from string) (pathStep, error) {
+	step := pathStep{text: text}
+	rest := text
+	end := strings.IndexAny(rest, "([")
+	if end < 0 {
+		end = len(rest)
+	}
+	step.kind, rest = strings.TrimSpace(rest[:end]), strings.TrimSpace(rest[end:])
+
+	nodeType, ok := nextSteps[from][step.kind]
+	if !ok {
+		if from == "" {
+			return step, fmt.Errorf("%q: a path starts with devices, networks or datacenters", text)
+		}
+		return step, fmt.Errorf("%q: %s cannot follow a %s", text, step.kind, from)
+	}
+	step.nodeType = nodeType
+
+	if strings.HasPrefix(rest, "(") {
+		if step.kind != "parents" && step.kind != "children" {
+			return step, fmt.Errorf("%q: only parents and children take relationship types", text)
+		}
+		types, after, ok := strings.Cut(rest[1:], ")")
+		if !ok {
+			return step, fmt.Errorf("%q: missing closing parenthesis", text)
+		}
+		for _, name := range strings.Split(types, ",") {
+			relType, ok := model.LookupRelationshipType(strings.TrimSpace(name))
+			if !ok {
+				return step, fmt.Errorf("%q: unknown relationship type %q", text, strings.TrimSpace(name))
+			}
+			step.types = append(step.types, relType.Name)
+		}
+		rest = strings.TrimSpace(after)
+	}
+
+	if strings.HasPrefix(rest, "[") {
+		if !strings.HasSuffix(rest, "]") {
+			return step, fmt.Errorf("%q: unexpected text after the filter", text)
+		}
+		filter := strings.TrimSpace(rest[1 : len(rest)-1])
+		if filter == "" {
+			return step, fmt.Errorf("%q: filter is empty", text)
+		}
+		if nodeType == model.TopologyNodeDevice {
+			q, err := Parse(filter)
+			if err != nil {
+				return step, fmt.Errorf("%q: %w", text, err)
+			}
+			step.query = q
+		} else {
+			step.name = newTermNode("", strings.Trim(filter, `"`))
+		}
+		rest = ""
+	}
+
+	if rest != "" {
+		return step, fmt.Errorf("%q: unexpected %q", text, rest)
+	}
+	return step, nil
+}
+
+// Steps returns the steps of the path as written
+func (p *Path) Steps() []string {
+	steps := make([]string, len(p.steps))
+	for i, s := range p.steps {
+		steps[i] = s.text
+	}
+	return steps
+}
+
+// Reaches reports whether a step of the path reaches nodes of the topology
+// node type
+func (p *Path) Reaches(nodeType string) bool {
+	return slices.ContainsFunc(p.steps, func(s pathStep) bool { return s.nodeType == nodeType })
+}
+
+// FollowsRelationships reports whether the path has a parents or children
+// step
+func (p *Path) FollowsRelationships() bool {
+	return slices.ContainsFunc(p.steps, func(s pathStep) bool { return s.kind == "parents" || s.kind == "children" })
+}
+
+// Uses reports whether a device query in the path has a term on the
+// canonical field
+func (p *Path) Uses(field string) bool {
+	return slices.ContainsFunc(p.steps, func(s pathStep) bool { return s.query != nil && s.query.Uses(field) })
+}
+
+// Graph is the inventory a path walks. Only the parts the path reaches need
+// to be filled in, and datacenters for device queries on datacenter names.
+type Graph struct {
+	Devices       []model.Device
+	Networks      []model.Network
+	Datacenters   []model.Datacenter
+	Relationships []model.DeviceRelationship
+
+	devices     map[string]*model.Device
+	networks    map[string]*model.Network
+	datacenters map[string]*model.Datacenter
+	env         *Env
+}
+
+func (g *Graph) index() {
+	g.devices = make(map[string]*model.Device, len(g.Devices))
+	for i := range g.Devices {
+		g.devices[g.Devices[i].ID] = &g.Devices[i]
+	}
+	g.networks = make(map[string]*model.Network, len(g.Networks))
+	for i := range g.Networks {
+		g.networks[g.Networks[i].ID] = &g.Networks[i]
+	}
+	g.datacenters = make(map[string]*model.Datacenter, len(g.Datacenters))
+	g.env = &Env{DatacenterNames: make(map[string]string, len(g.Datacenters))}
+	for i := range g.Datacenters {
+		g.datacenters[g.Datacenters[i].ID] = &g.Datacenters[i]
+		g.env.DatacenterNames[g.Datacenters[i].ID] = g.Datacenters[i].Name
+	}
+}
+
+// Walk returns every chain of nodes the path matches, with a node per step,
+// ordered by the names of the nodes along it. A chain that cannot take a step
+// is dropped. Walk stops after limit chains and reports whether there were
+// more; a limit of 0 means no limit.
+func (p *Path) Walk(g *Graph, limit int) ([][]model.TopologyNode, bool) {
+	g.index()
+	chains := [][]model.TopologyNode{}
+	for _, node := range p.steps[0].filter(g, g.start(p.steps[0])) {
+		chains = append(chains, []model.TopologyNode{node})
+	}
+
+	for _, step := range p.steps[1:] {
+		var next [][]model.TopologyNode
+		for _, chain := range chains {
+			for _, node := range step.filter(g, g.neighbours(chain[len(chain)-1], step)) {
+				if limit > 0 && len(next) == limit {
+					return next, true
+				}
+				next = append(next, append(slices.Clip(chain), node))
+			}
+		}
+		if next == nil {
+			return [][]model.TopologyNode{}, false
+		}
+		chains = next
+	}
+
+	if limit > 0 && len(chains) > limit {
+		return chains[:limit], true
+	}
+	return chains, false
+}
+
+// start returns every node of the first step's type
+func (g *Graph) start(step pathStep) []model.TopologyNode {
+	var nodes []model.TopologyNode
+	switch step.nodeType {
+	case model.TopologyNodeDevice:
+		for i := range g.Devices {
+			nodes = append(nodes, deviceNode(&g.Devices[i]))
+		}
+	case model.TopologyNodeNetwork:
+		for i := range g.Networks {
+			nodes = append(nodes, networkNode(&g.Networks[i]))
+		}
+	case model.TopologyNodeDatacenter:
+		for i := range g.Datacenters {
+			nodes = append(nodes, datacenterNode(&g.Datacenters[i]))
+		}
+	}
+	return nodes
+}
+
+// neighbours returns the nodes one step from node
+func (g *Graph) neighbours(node model.TopologyNode, step pathStep) []model.TopologyNode {
+	var nodes []model.TopologyNode
+	addDevice := func(id string) {
+		if d := g.devices[id]; d != nil {
+			nodes = append(nodes, deviceNode(d))
+		}
+	}
+	addNetwork := func(id string) {
+		if n := g.networks[id]; n != nil {
+			nodes = append(nodes, networkNode(n))
+		}
+	}
+	addDatacenter := func(id string) {
+		if dc := g.datacenters[id]; dc != nil {
+			nodes = append(nodes, datacenterNode(dc))
+		}
+	}
+
+	switch node.Type + "." + step.kind {
+	case "device.parents", "device.children":
+		for _, r := range g.Relationships {
+			if len(step.types) > 0 && !slices.Contains(step.types, r.Type) {
+				continue
+			}
+			if step.kind == "parents" && r.ChildID == node.ID {
+				addDevice(r.ParentID)
+			} else if step.kind == "children" && r.ParentID == node.ID {
+				addDevice(r.ChildID)
+			}
+		}
+	case "device.networks":
+		if d := g.devices[node.ID]; d != nil {
+			for _, a := range d.Addresses {
+				addNetwork(a.NetworkID)
+			}
+		}
+	case "device.datacenter", "network.datacenter":
+		addDatacenter(node.DatacenterID)
+	case "network.devices":
+		for i := range g.Devices {
+			if slices.ContainsFunc(g.Devices[i].Addresses, func(a model.Address) bool { return a.NetworkID == node.ID }) {
+				nodes = append(nodes, deviceNode(&g.Devices[i]))
+			}
+		}
+	case "datacenter.devices":
+		for i := range g.Devices {
+			if g.Devices[i].DatacenterID == node.ID {
+				nodes = append(nodes, deviceNode(&g.Devices[i]))
+			}
+		}
+	case "datacenter.networks":
+		for i := range g.Networks {
+			if g.Networks[i].DatacenterID == node.ID {
+				nodes = append(nodes, networkNode(&g.Networks[i]))
+			}
+		}
+	}
+	return nodes
+}
+
+// filter keeps the nodes matching the step's filter, once each, sorted by
+// label and ID
+func (s pathStep) filter(g *Graph, nodes []model.TopologyNode) []model.TopologyNode {
+	slices.SortFunc(nodes, func(a, b model.TopologyNode) int {
+		return cmp.Or(cmp.Compare(a.Label, b.Label), cmp.Compare(a.ID, b.ID))
+	})
+	nodes = slices.CompactFunc(nodes, func(a, b model.TopologyNode) bool { return a.ID == b.ID })
+	return slices.DeleteFunc(nodes, func(n model.TopologyNode) bool {
+		switch {
+		case s.query != nil:
+			return !s.query.Match(g.devices[n.ID], g.env)
+		case s.name != nil:
+			return !s.name.matchExact(n.ID) && !s.name.matchExact(n.Label)
+		}
+		return false
+	})
+}
+
+func deviceNode(d *model.Device) model.TopologyNode {
+	return model.TopologyNode{
+		ID: d.ID, Type: model.TopologyNodeDevice, Label: d.Name,
+		DatacenterID: d.DatacenterID, Status: string(d.Status),
+	}
+}
+
+func networkNode(n *model.Network) model.TopologyNode {
+	return model.TopologyNode{
+		ID: n.ID, Type: model.TopologyNodeNetwork, Label: n.Name,
+		DatacenterID: n.DatacenterID, Subnet: n.Subnet,
+	}
+}
+
+func datacenterNode(dc *model.Datacenter) model.TopologyNode {
+	return model.TopologyNode{ID: dc.ID, Type: model.TopologyNodeDatacenter, Label: dc.Name}
+}
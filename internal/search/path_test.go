@@ -0,0 +1,123 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func testGraph() *Graph {
+	return &Graph{
+		Devices: testDevices(),
+		Networks: []model.Network{
+			{ID: "net-1", Name: "lon-servers", Subnet: "10.1.0.0/16", DatacenterID: "dc-1"},
+			{ID: "net-2", Name: "par-servers", Subnet: "10.2.0.0/16", DatacenterID: "dc-2"},
+		},
+		Datacenters: []model.Datacenter{{ID: "dc-1", Name: "London"}, {ID: "dc-2", Name: "Paris"}},
+		Relationships: []model.DeviceRelationship{
+			// web-01 and old-web depend on db-01, which is hosted on web-01
+			{ParentID: "dev-1", ChildID: "dev-2", Type: model.RelationshipDependsOn},
+			{ParentID: "dev-3", ChildID: "dev-2", Type: model.RelationshipDependsOn},
+			{ParentID: "dev-2", ChildID: "dev-1", Type: model.RelationshipHostedOn},
+		},
+	}
+}
+
+// pathLabels renders each chain as its labels joined by " > "
+func pathLabels(paths [][]model.TopologyNode) []string {
+	var labels []string
+	for _, path := range paths {
+		var nodes []string
+		for _, n := range path {
+			nodes = append(nodes, n.Label)
+		}
+		labels = append(labels, strings.Join(nodes, " > "))
+	}
+	return labels
+}
+
+func TestPathWalk(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"devices[tag:db].parents(depends_on).datacenter", []string{"db-01 > web-01 > London"}},
+		{"devices[tag:db].parents(depends_on)", []string{"db-01 > old-web", "db-01 > web-01"}},
+		{"devices[tag:db].parents(depends_on)[-tag:decom]", []string{"db-01 > web-01"}},
+		{"devices[tag:db].parents", []string{"db-01 > old-web", "db-01 > web-01"}},
+		{"devices[name:web-01].children", []string{"web-01 > db-01"}},
+		{"devices[name:web-01].parents(hosted_on, contains)", []string{"web-01 > db-01"}},
+		{"devices[dc:paris].children(depends_on)", nil},
+		{"devices[tag:prod].networks", []string{"web-01 > lon-servers"}},
+		{"networks[LON-*].devices.parents", []string{"lon-servers > web-01 > db-01"}},
+		{"networks.datacenter", []string{"lon-servers > London", "par-servers > Paris"}},
+		{"datacenters[Paris].devices", []string{"Paris > db-01"}},
+		{"datacenters[dc-1].networks", []string{"London > lon-servers"}},
+		{"devices[kind:vm].datacenter[london]", nil},
+		{`datacenters["London"]`, []string{"London"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p, err := ParsePath(tt.path)
+			if err != nil {
+				t.Fatalf("ParsePath failed: %v", err)
+			}
+			paths, truncated := p.Walk(testGraph(), 0)
+			got := pathLabels(paths)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || truncated {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathWalkLimit(t *testing.T) {
+	p, _ := ParsePath("devices.datacenter")
+	paths, truncated := p.Walk(testGraph(), 1)
+	if len(paths) != 1 || !truncated {
+		t.Errorf("expected one path and truncated, got %d (%v)", len(paths), truncated)
+	}
+	if _, truncated := p.Walk(testGraph(), 2); truncated {
+		t.Error("expected two paths to fit a limit of two")
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	for _, path := range []string{
+		"",
+		"racks",
+		"devices.",
+		"devices..datacenter",
+		"devices[tag:db",
+		"devices[]",
+		"devices[tag:db] extra",
+		"devices.parents(owns)",
+		"devices.datacenter(depends_on)",
+		"networks.parents",
+		"datacenters.datacenter",
+		"devices[(tag:db]",
+		`devices[name:"web]`,
+		"devices" + strings.Repeat(".parents", MaxPathSteps),
+	} {
+		if _, err := ParsePath(path); err == nil {
+			t.Errorf("%q: expected an error", path)
+		}
+	}
+}
+
+func TestPathReaches(t *testing.T) {
+	p, err := ParsePath("devices[dc:london].parents.networks")
+	if err != nil {
+		t.Fatalf("ParsePath failed: %v", err)
+	}
+	if !p.Reaches(model.TopologyNodeDevice) || !p.Reaches(model.TopologyNodeNetwork) || p.Reaches(model.TopologyNodeDatacenter) {
+		t.Error("expected the path to reach devices and networks only")
+	}
+	if !p.FollowsRelationships() || !p.Uses("datacenter") || p.Uses("tag") {
+		t.Error("expected the path to follow relationships and query datacenters")
+	}
+	if got := strings.Join(p.Steps(), " "); got != "devices[dc:london] parents networks" {
+		t.Errorf("unexpected steps %q", got)
+	}
+}
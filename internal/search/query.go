@@ -0,0 +1,579 @@
+// Package search parses and evaluates the structured device query language,
+// e.g. `tag:prod os:ubuntu ip:10.1.0.0/16 -tag:decom`.
+//
+// A query is a list of terms joined by AND, which is implied between
+// adjacent terms. OR joins alternatives, NOT or a leading "-" negates a term,
+// and parentheses group. AND binds tighter than OR. A term is either
+// field:value or a bare word matched against the same columns as the plain
+// text search. Values containing spaces are quoted: name:"web 01".
+//
+// Paths (see ParsePath) walk from devices to their relationships, networks
+// and datacenters, using device queries as filters along the way.
+package search
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// Fields maps every field name accepted in a query, including aliases, to
+// its canonical name
+var Fields = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"hostname":    "hostname",
+	"host":        "hostname",
+	"description": "description",
+	"desc":        "description",
+	"os":          "os",
+	"model":       "make_model",
+	"make":        "make_model",
+	"make_model":  "make_model",
+	"location":    "location",
+	"loc":         "location",
+	"username":    "username",
+	"user":        "username",
+	"status":      "status",
+	"kind":        "kind",
+	"tag":         "tag",
+	"domain":      "domain",
+	"ip":          "ip",
+	"mac":         "mac",
+	"network":     "network",
+	"datacenter":  "datacenter",
+	"dc":          "datacenter",
+}
+
+// Query is a parsed device query
+type Query struct {
+	root node
+}
+
+// Env supplies data that is not stored on the device itself
+type Env struct {
+	// DatacenterNames maps datacenter IDs to names, so datacenter: can match
+	// either
+	DatacenterNames map[string]string
+}
+
+// SyntaxError is a query that does not parse. Start and End are the
+// character offsets of the part of the query at fault, End exclusive; both
+// are the length of the query when it ends too early.
+type SyntaxError struct {
+	Message string
+	Start   int
+	End     int
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Message
+}
+
+// Node is a node of a parsed query, for clients that show how a query was
+// understood. Type is "and", "or" or "not" with Children, or "term" with the
+// canonical Field and the Value as matched; a bare word is a term without a
+// field. Start and End are the character offsets of the node in the query,
+// End exclusive.
+type Node struct {
+	Type     string  `json:"type"`
+	Field    string  `json:"field,omitempty"`
+	Value    string  `json:"value,omitempty"`
+	Children []*Node `json:"children,omitempty"`
+	Start    int     `json:"start"`
+	End      int     `json:"end"`
+}
+
+type node interface {
+	match(d *model.Device, env *Env) bool
+	ast() *Node
+}
+
+// span is where a node or token is in the query, in characters
+type span struct{ start, end int }
+
+type andNode struct {
+	span
+	children []node
+}
+
+type orNode struct {
+	span
+	children []node
+}
+
+type notNode struct {
+	span
+	n node
+}
+
+// termNode is field:value, or a bare word when field is empty
+type termNode struct {
+	span
+	field  string
+	value  string
+	glob   *regexp.Regexp
+	prefix netip.Prefix
+}
+
+// IsStructured reports whether query uses the structured syntax: a known
+// field:value term, an operator, a negation or parentheses. Other queries
+// are plain text searches. Values such as MAC and IPv6 addresses contain
+// colons, so only known field names count.
+func IsStructured(query string) bool {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return true
+	}
+	for _, t := range tokens {
+		if !t.quoted && (t.text == "(" || t.text == ")" || t.text == "OR" || t.text == "AND" || t.text == "NOT" ||
+			(len(t.text) > 1 && t.text[0] == '-')) {
+			return true
+		}
+		if field, _, ok := strings.Cut(t.text, ":"); ok && !t.quoted {
+			if _, known := Fields[strings.ToLower(field)]; known {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Parse parses a structured query. A query that does not parse is a
+// *SyntaxError.
+func Parse(query string) (*Query, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	length := utf8.RuneCountInString(query)
+	if len(tokens) == 0 {
+		return nil, &SyntaxError{Message: "query is empty", Start: 0, End: length}
+	}
+	p := &parser{tokens: tokens, length: length}
+	root, _, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.tokens[p.pos].errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return &Query{root: root}, nil
+}
+
+// Match reports whether d matches the query. env may be nil.
+func (q *Query) Match(d *model.Device, env *Env) bool {
+	if env == nil {
+		env = &Env{}
+	}
+	return q.root.match(d, env)
+}
+
+// AST returns the syntax tree of the query
+func (q *Query) AST() *Node {
+	return q.root.ast()
+}
+
+// Uses reports whether the query has a term on the canonical field
+func (q *Query) Uses(field string) bool {
+	var uses func(n node) bool
+	uses = func(n node) bool {
+		switch n := n.(type) {
+		case *andNode:
+			return slices.ContainsFunc(n.children, uses)
+		case *orNode:
+			return slices.ContainsFunc(n.children, uses)
+		case *notNode:
+			return uses(n.n)
+		case *termNode:
+			return n.field == field
+		}
+		return false
+	}
+	return uses(q.root)
+}
+
+type token struct {
+	span
+	text string
+	// quoted is set when the whole token is quoted, which makes it a
+	// literal word rather than an operator or field:value
+	quoted bool
+}
+
+// errorf returns a syntax error spanning the token
+func (t token) errorf(format string, args ...any) *SyntaxError {
+	return &SyntaxError{Message: fmt.Sprintf(format, args...), Start: t.start, End: t.end}
+}
+
+// tokenize splits a query into words, quoted values and parentheses. A quoted
+// value stays part of its word, so name:"web 01" is one token.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	var cur strings.Builder
+	quoted, inQuote := false, false
+	start := -1
+	flush := func(end int) {
+		if cur.Len() > 0 || quoted {
+			tokens = append(tokens, token{span: span{start, end}, text: cur.String(), quoted: quoted})
+		}
+		cur.Reset()
+		quoted = false
+		start = -1
+	}
+	i := 0
+	for _, r := range query {
+		if start < 0 && !(r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '(' || r == ')') {
+			start = i
+		}
+		switch {
+		case inQuote:
+			if r == '"' {
+				inQuote = false
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"':
+			// Only a token that opens with a quote is literal
+			inQuote, quoted = true, quoted || cur.Len() == 0
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush(i)
+		case r == '(' || r == ')':
+			// A leading "-" stays attached, so -( negates the group
+			if r == '(' && cur.String() == "-" {
+				tokens = append(tokens, token{span: span{start, i}, text: "NOT"})
+				cur.Reset()
+				start = -1
+			} else {
+				flush(i)
+			}
+			tokens = append(tokens, token{span: span{i, i + 1}, text: string(r)})
+		default:
+			cur.WriteRune(r)
+		}
+		i++
+	}
+	if inQuote {
+		return nil, &SyntaxError{Message: "unterminated quote", Start: start, End: i}
+	}
+	flush(i)
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	length int // of the query, in characters
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) isOperator(op string) bool {
+	t, ok := p.peek()
+	return ok && !t.quoted && t.text == op
+}
+
+// endError is the error for a query that ends where more was expected
+func (p *parser) endError(message string) *SyntaxError {
+	return &SyntaxError{Message: message, Start: p.length, End: p.length}
+}
+
+// The parse functions return the node and where it is in the query, which
+// includes any parentheses around it
+func (p *parser) parseOr() (node, span, error) {
+	first, extent, err := p.parseAnd()
+	if err != nil {
+		return nil, span{}, err
+	}
+	nodes := []node{first}
+	for p.isOperator("OR") {
+		p.pos++
+		n, next, err := p.parseAnd()
+		if err != nil {
+			return nil, span{}, err
+		}
+		nodes = append(nodes, n)
+		extent.end = next.end
+	}
+	if len(nodes) == 1 {
+		return first, extent, nil
+	}
+	return &orNode{span: extent, children: nodes}, extent, nil
+}
+
+func (p *parser) parseAnd() (node, span, error) {
+	var nodes []node
+	var extent span
+	for {
+		if t, ok := p.peek(); ok && !t.quoted && t.text == "AND" {
+			p.pos++
+			if _, more := p.peek(); len(nodes) == 0 || !more {
+				return nil, span{}, t.errorf("AND needs a term on each side")
+			}
+		} else if !ok || (!t.quoted && (t.text == "OR" || t.text == ")")) {
+			break
+		}
+		n, next, err := p.parseUnary()
+		if err != nil {
+			return nil, span{}, err
+		}
+		if len(nodes) == 0 {
+			extent.start = next.start
+		}
+		extent.end = next.end
+		nodes = append(nodes, n)
+	}
+	switch len(nodes) {
+	case 0:
+		if t, ok := p.peek(); ok {
+			return nil, span{}, t.errorf("unexpected %q", t.text)
+		}
+		return nil, span{}, p.endError("query ends where a term was expected")
+	case 1:
+		return nodes[0], extent, nil
+	}
+	return &andNode{span: extent, children: nodes}, extent, nil
+}
+
+func (p *parser) parseUnary() (node, span, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, span{}, p.endError("query ends where a term was expected")
+	}
+	if !t.quoted {
+		switch {
+		case t.text == "NOT":
+			p.pos++
+			n, extent, err := p.parseUnary()
+			if err != nil {
+				return nil, span{}, err
+			}
+			extent.start = t.start
+			return &notNode{span: extent, n: n}, extent, nil
+		case t.text == "(":
+			p.pos++
+			n, _, err := p.parseOr()
+			if err != nil {
+				return nil, span{}, err
+			}
+			if !p.isOperator(")") {
+				return nil, span{}, t.errorf("missing closing parenthesis")
+			}
+			closing := p.tokens[p.pos]
+			p.pos++
+			return n, span{t.start, closing.end}, nil
+		case t.text == "OR" || t.text == "AND" || t.text == ")":
+			return nil, span{}, t.errorf("unexpected %q", t.text)
+		case len(t.text) > 1 && t.text[0] == '-':
+			p.pos++
+			term := t
+			term.text, term.start = t.text[1:], t.start+1
+			n, err := parseTerm(term)
+			if err != nil {
+				return nil, span{}, err
+			}
+			return &notNode{span: t.span, n: n}, t.span, nil
+		}
+	}
+	p.pos++
+	n, err := parseTerm(t)
+	return n, t.span, err
+}
+
+func parseTerm(t token) (node, error) {
+	name, value, ok := strings.Cut(t.text, ":")
+	field, known := Fields[strings.ToLower(name)]
+	if t.quoted || !ok || !known {
+		term := newTermNode("", t.text)
+		term.span = t.span
+		return term, nil
+	}
+	if value == "" {
+		return nil, t.errorf("%s: needs a value", name)
+	}
+
+	term := newTermNode(field, value)
+	term.span = t.span
+	switch field {
+	case "status":
+		if !model.DeviceStatus(term.value).IsValid() {
+			return nil, t.errorf("unknown status %q", value)
+		}
+	case "kind":
+		if !model.DeviceKind(term.value).IsValid() {
+			return nil, t.errorf("unknown kind %q", value)
+		}
+	case "ip":
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, t.errorf("invalid CIDR %q", value)
+			}
+			term.prefix = prefix.Masked()
+		}
+	case "mac":
+		term.value = strings.NewReplacer("-", ":", ".", "").Replace(term.value)
+	}
+	return term, nil
+}
+
+func newTermNode(field, value string) *termNode {
+	t := &termNode{field: field, value: strings.ToLower(value)}
+	if strings.ContainsAny(t.value, "*?") {
+		pattern := regexp.QuoteMeta(t.value)
+		pattern = strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(pattern)
+		t.glob = regexp.MustCompile("^" + pattern + "$")
+	}
+	return t
+}
+
+func (n *andNode) match(d *model.Device, env *Env) bool {
+	for _, c := range n.children {
+		if !c.match(d, env) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *orNode) match(d *model.Device, env *Env) bool {
+	for _, c := range n.children {
+		if c.match(d, env) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *notNode) match(d *model.Device, env *Env) bool {
+	return !n.n.match(d, env)
+}
+
+func (n *andNode) ast() *Node {
+	return &Node{Type: "and", Children: childASTs(n.children), Start: n.start, End: n.end}
+}
+
+func (n *orNode) ast() *Node {
+	return &Node{Type: "or", Children: childASTs(n.children), Start: n.start, End: n.end}
+}
+
+func (n *notNode) ast() *Node {
+	return &Node{Type: "not", Children: []*Node{n.n.ast()}, Start: n.start, End: n.end}
+}
+
+func (t *termNode) ast() *Node {
+	value := t.value
+	if t.prefix.IsValid() {
+		value = t.prefix.String()
+	}
+	return &Node{Type: "term", Field: t.field, Value: value, Start: t.start, End: t.end}
+}
+
+func childASTs(nodes []node) []*Node {
+	children := make([]*Node, len(nodes))
+	for i, n := range nodes {
+		children[i] = n.ast()
+	}
+	return children
+}
+
+func (t *termNode) match(d *model.Device, env *Env) bool {
+	switch t.field {
+	case "":
+		return t.matchText(d)
+	case "id":
+		return strings.EqualFold(d.ID, t.value)
+	case "name":
+		return t.matchString(d.Name)
+	case "hostname":
+		return t.matchString(d.Hostname)
+	case "description":
+		return t.matchString(d.Description)
+	case "os":
+		return t.matchString(d.OS)
+	case "make_model":
+		return t.matchString(d.MakeModel)
+	case "location":
+		return t.matchString(d.Location)
+	case "username":
+		return t.matchString(d.Username)
+	case "status":
+		return string(d.Status) == t.value
+	case "kind":
+		return string(d.Kind) == t.value
+	case "tag":
+		// Tags match whole, unless the value has a wildcard
+		return slices.ContainsFunc(d.Tags, t.matchExact)
+	case "domain":
+		return slices.ContainsFunc(d.Domains, t.matchString)
+	case "datacenter":
+		return d.DatacenterID != "" && (t.matchExact(d.DatacenterID) || t.matchExact(env.DatacenterNames[d.DatacenterID]))
+	case "network":
+		return slices.ContainsFunc(d.Addresses, func(a model.Address) bool { return a.NetworkID != "" && t.matchExact(a.NetworkID) })
+	case "ip":
+		return slices.ContainsFunc(d.Addresses, func(a model.Address) bool { return t.matchIP(a.IP) })
+	case "mac":
+		return slices.ContainsFunc(d.Addresses, func(a model.Address) bool {
+			return a.MACAddress != "" && strings.Contains(strings.ToLower(a.MACAddress), t.value)
+		})
+	}
+	return false
+}
+
+// matchText matches a bare word against the columns of the plain text search
+func (t *termNode) matchText(d *model.Device) bool {
+	for _, s := range []string{d.Name, d.Hostname, d.Description, d.MakeModel, d.OS, d.Location} {
+		if t.matchString(s) {
+			return true
+		}
+	}
+	if slices.ContainsFunc(d.Tags, t.matchString) || slices.ContainsFunc(d.Domains, t.matchString) {
+		return true
+	}
+	return slices.ContainsFunc(d.Addresses, func(a model.Address) bool {
+		return t.matchString(a.IP) || t.matchString(a.MACAddress)
+	})
+}
+
+// matchString matches a case-insensitive substring, or the whole value when
+// the term has * or ? wildcards
+func (t *termNode) matchString(s string) bool {
+	if s == "" {
+		return false
+	}
+	if t.glob != nil {
+		return t.glob.MatchString(strings.ToLower(s))
+	}
+	return strings.Contains(strings.ToLower(s), t.value)
+}
+
+// matchExact matches the whole value case-insensitively, with wildcards
+func (t *termNode) matchExact(s string) bool {
+	if t.glob != nil {
+		return t.glob.MatchString(strings.ToLower(s))
+	}
+	return strings.EqualFold(s, t.value)
+}
+
+// matchIP matches a CIDR by containment, a full address by equality and
+// anything else as a prefix, so ip:10.1. finds 10.1.x.x
+func (t *termNode) matchIP(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if t.prefix.IsValid() {
+		return err == nil && t.prefix.Contains(addr.Unmap())
+	}
+	if want, perr := netip.ParseAddr(t.value); perr == nil {
+		return err == nil && addr.Unmap() == want.Unmap()
+	}
+	return strings.HasPrefix(strings.ToLower(ip), t.value)
+}
@@ -0,0 +1,163 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func testDevices() []model.Device {
+	return []model.Device{
+		{
+			ID: "dev-1", Name: "web-01", Hostname: "web01.example.com", OS: "Ubuntu 22.04",
+			Status: model.DeviceStatusActive, DatacenterID: "dc-1", Location: "Rack A1",
+			Tags: []string{"prod", "web"}, Domains: []string{"example.com"},
+			Addresses: []model.Address{{IP: "10.1.2.10", NetworkID: "net-1", MACAddress: "aa:bb:cc:00:00:01"}},
+		},
+		{
+			ID: "dev-2", Name: "db-01", OS: "Debian 12", Status: model.DeviceStatusMaintenance, Kind: model.DeviceKindVM,
+			DatacenterID: "dc-2", Tags: []string{"prod", "db"},
+			Addresses: []model.Address{{IP: "10.2.0.5"}, {IP: "2001:db8::5"}},
+		},
+		{
+			ID: "dev-3", Name: "old-web", OS: "Ubuntu 18.04", Status: model.DeviceStatusDecommissioned,
+			Tags: []string{"prod", "decom"}, Description: "Replaced by web 01",
+			Addresses: []model.Address{{IP: "10.1.200.7"}},
+		},
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	env := &Env{DatacenterNames: map[string]string{"dc-1": "London", "dc-2": "Paris"}}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"tag:prod os:ubuntu -tag:decom", []string{"dev-1"}},
+		{"ip:10.1.0.0/16", []string{"dev-1", "dev-3"}},
+		{"ip:10.2.0.5", []string{"dev-2"}},
+		{"ip:10.1.", []string{"dev-1", "dev-3"}},
+		{"ip:2001:db8::/32", []string{"dev-2"}},
+		{"tag:web OR tag:db", []string{"dev-1", "dev-2"}},
+		{"tag:prod AND NOT (status:active OR status:maintenance)", []string{"dev-3"}},
+		{"-(tag:web OR tag:db)", []string{"dev-3"}},
+		{"status:maintenance", []string{"dev-2"}},
+		{"kind:vm", []string{"dev-2"}},
+		{"dc:london", []string{"dev-1"}},
+		{"datacenter:dc-2", []string{"dev-2"}},
+		{"network:net-1", []string{"dev-1"}},
+		{"mac:AA-BB-CC", []string{"dev-1"}},
+		{"name:*-01", []string{"dev-1", "dev-2"}},
+		{"tag:pro", nil},
+		{"tag:pro*", []string{"dev-1", "dev-2", "dev-3"}},
+		{`desc:"web 01"`, []string{"dev-3"}},
+		{`ubuntu "web 01"`, []string{"dev-3"}},
+		{"domain:example.com location:rack", []string{"dev-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.query, err)
+			}
+			var got []string
+			for _, d := range testDevices() {
+				if q.Match(&d, env) {
+					got = append(got, d.ID)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	// Each error spans the part of the query at fault
+	tests := []struct {
+		query      string
+		start, end int
+	}{
+		{"", 0, 0},
+		{"tag:", 0, 4},
+		{"(tag:prod", 0, 1},
+		{"tag:prod)", 8, 9},
+		{"OR tag:prod", 0, 2},
+		{"tag:prod AND", 9, 12},
+		{"status:broken", 0, 13},
+		{"kind:blade", 0, 10},
+		{"ip:10.0.0.0/33", 0, 14},
+		{`name:"web`, 0, 9},
+		{"web -status:gone", 5, 16},
+		{"tag:prod OR", 11, 11},
+		{"tag:é (", 7, 7},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q): expected a syntax error, got %v", tt.query, err)
+			continue
+		}
+		if syntaxErr.Start != tt.start || syntaxErr.End != tt.end {
+			t.Errorf("Parse(%q): %q spans %d-%d, want %d-%d", tt.query, syntaxErr.Message, syntaxErr.Start, syntaxErr.End, tt.start, tt.end)
+		}
+	}
+}
+
+func TestQueryAST(t *testing.T) {
+	q, err := Parse(`web (tag:prod OR ip:10.1.0.0/16) -(dc:london) NOT name:"db 01"`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, _ := json.Marshal(q.AST())
+	want := `{"type":"and","children":[` +
+		`{"type":"term","value":"web","start":0,"end":3},` +
+		`{"type":"or","children":[{"type":"term","field":"tag","value":"prod","start":5,"end":13},{"type":"term","field":"ip","value":"10.1.0.0/16","start":17,"end":31}],"start":5,"end":31},` +
+		`{"type":"not","children":[{"type":"term","field":"datacenter","value":"london","start":35,"end":44}],"start":33,"end":45},` +
+		`{"type":"not","children":[{"type":"term","field":"name","value":"db 01","start":50,"end":62}],"start":46,"end":62}` +
+		`],"start":0,"end":62}`
+	if string(got) != want {
+		t.Errorf("unexpected AST\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestIsStructured(t *testing.T) {
+	tests := map[string]bool{
+		"web":                  false,
+		"web-01":               false,
+		"aa:bb:cc:00:00:01":    false,
+		"fe80::1":              false,
+		`"tag:prod"`:           false,
+		"tag:prod":             true,
+		"OS:ubuntu":            true,
+		"web -tag:decom":       true,
+		"web OR db":            true,
+		"(web)":                true,
+		`name:"web 01"`:        true,
+		"ip:10.0.0.0/8 status": true,
+	}
+	for query, want := range tests {
+		if got := IsStructured(query); got != want {
+			t.Errorf("IsStructured(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestQueryUses(t *testing.T) {
+	q, err := Parse("tag:prod OR -(dc:london)")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !q.Uses("datacenter") || !q.Uses("tag") || q.Uses("ip") {
+		t.Error("Uses reported the wrong fields")
+	}
+}
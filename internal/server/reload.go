@@ -0,0 +1,62 @@
+package server
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/martinsuchenak/rackd/internal/api"
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/log"
+)
+
+// reloadOnSignal reloads the configuration on SIGHUP until the returned
+// function is called. apiLimiter is nil when rate limiting is disabled.
+func reloadOnSignal(cfg *config.Config, apiLimiter, loginLimiter *api.RateLimiter) func() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	done := make(chan struct{})
+
+	running := *cfg
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				next, err := running.Reload()
+				if err != nil {
+					log.Error("Configuration reload failed, keeping the current settings", "error", err)
+					continue
+				}
+				applyConfig(&running, next, apiLimiter, loginLimiter)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
+
+// applyConfig applies the settings of next that can change while running
+// to the server and to running, and logs the others as needing a restart
+func applyConfig(running, next *config.Config, apiLimiter, loginLimiter *api.RateLimiter) {
+	applied, restart := running.Changes(next)
+
+	running.LogLevel = next.LogLevel
+	log.SetLevel(next.LogLevel)
+
+	running.RateLimitRequests, running.RateLimitWindow = next.RateLimitRequests, next.RateLimitWindow
+	if apiLimiter != nil {
+		apiLimiter.SetLimit(next.RateLimitRequests, next.RateLimitWindow)
+	}
+	running.LoginRateLimitRequests, running.LoginRateLimitWindow = next.LoginRateLimitRequests, next.LoginRateLimitWindow
+	loginLimiter.SetLimit(next.LoginRateLimitRequests, next.LoginRateLimitWindow)
+
+	log.Info("Configuration reloaded", "file", next.ConfigFile, "applied", applied)
+	if len(restart) > 0 {
+		log.Warn("Changed settings take effect after a restart", "settings", restart)
+	}
+}
@@ -4,21 +4,24 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
 	"github.com/martinsuchenak/rackd/internal/api"
 	"github.com/martinsuchenak/rackd/internal/auth"
+	"github.com/martinsuchenak/rackd/internal/backup"
+	"github.com/martinsuchenak/rackd/internal/cloud"
 	"github.com/martinsuchenak/rackd/internal/config"
 	"github.com/martinsuchenak/rackd/internal/credentials"
 	"github.com/martinsuchenak/rackd/internal/discovery"
 	"github.com/martinsuchenak/rackd/internal/log"
 	"github.com/martinsuchenak/rackd/internal/mcp"
+	"github.com/martinsuchenak/rackd/internal/notify"
 	"github.com/martinsuchenak/rackd/internal/service"
 	"github.com/martinsuchenak/rackd/internal/storage"
 	"github.com/martinsuchenak/rackd/internal/ui"
+	"github.com/martinsuchenak/rackd/internal/validate"
+	"github.com/martinsuchenak/rackd/internal/webhook"
 	"github.com/martinsuchenak/rackd/internal/worker"
 	"github.com/redis/go-redis/v9"
 )
@@ -73,32 +76,100 @@ func RunWithAdvancedFeatures(
 	if err := storage.BootstrapInitialAdmin(store, cfg, sessionManager); err != nil {
 		return fmt.Errorf("failed to bootstrap initial admin: %w", err)
 	}
+	if cfg.GenerateToken {
+		if _, err := storage.BootstrapAdminToken(store, cfg); err != nil {
+			return fmt.Errorf("failed to generate admin token: %w", err)
+		}
+	}
 
 	scanner := discovery.NewUnifiedScanner(store, credStore, 30*time.Second, cfg.DiscoverySNMPv2cEnabled)
 	scheduler := worker.NewScheduler(store, scanner, cfg)
 	scheduler.Start()
 
 	// Initialize scheduled scan worker (unified scanner supports both basic and advanced scans)
-	scheduledWorker := worker.NewScheduledScanWorker(scheduledStore, profileStore, scanner)
+	scheduledWorker := worker.NewScheduledScanWorker(scheduledStore, profileStore, store, scanner)
 	if err := scheduledWorker.Start(); err != nil {
 		log.Error("Failed to start scheduled scan worker", "error", err)
 	}
 
+	// Passive mDNS/SSDP listener for networks that opt in via their discovery rule
+	passiveWorker := worker.NewPassiveDiscoveryWorker(store)
+	passiveWorker.Start()
+
+	// Deliver events to webhooks, retrying failed deliveries
+	webhookWorker := webhook.NewWorker(store, webhook.DefaultDeliveryConfig())
+	webhookWorker.Start()
+
+	// Send event summaries, such as discovery scan changes, to notification channels
+	notifier := notify.NewNotifier(store, notify.NewSender(notify.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	}))
+	notifier.Start()
+
 	// Create services registry
 	services := service.NewServices(store, sessionManager, scanner)
+	services.SetSearchLimits(service.SearchLimits{MaxRows: cfg.SearchMaxRows, Timeout: cfg.SearchTimeout})
+	serviceMappings, err := discovery.ParseServiceMappings(cfg.DiscoveryMappings)
+	if err != nil {
+		return fmt.Errorf("invalid DISCOVERY_SERVICE_MAPPINGS: %w", err)
+	}
+	services.Discovery.SetServiceMappings(serviceMappings)
+	policies, err := validate.FromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	services.SetValidationPolicies(policies)
+	services.SetUniqueDeviceNames(cfg.UniqueDeviceNames)
+	services.SetApprovalMode(cfg.ApprovalMode)
+
+	// Promote discovered devices matching auto-promotion rules
+	autoPromotionWorker := worker.NewAutoPromotionWorker(services.Discovery)
+	autoPromotionWorker.Start()
+
+	// Sync servers from cloud providers with configured credentials
+	cloudWorker, err := startCloudSync(cfg, services)
+	if err != nil {
+		return err
+	}
+
+	// Availability checks for documented devices
+	monitorWorker := startMonitor(cfg, services)
+
+	// Purge deleted items once they outlive the trash retention
+	trashWorker := startTrashPurge(cfg, services)
+
+	// Scheduled database backups
+	backupWorker := startBackups(cfg, services)
+
+	// Reminders of expiring warranties, sent to notification channels
+	services.Notifications.SetNotifier(notifier)
+	warrantyWorker, err := startWarrantyReminders(cfg, services)
+	if err != nil {
+		return err
+	}
+
+	// Scheduled reports, sent to notification channels
+	services.ReportSchedules.SetNotifier(notifier)
+	reportScheduleWorker := worker.NewReportScheduleWorker(services.ReportSchedules)
+	reportScheduleWorker.Start()
 
 	// Set optional services with their storage types
 	services.SetCredentialsStorage(credStore)
 	services.SetProfileStorage(profileStore)
 	services.SetScheduledScanStorage(scheduledStore)
 
-	// DNS service setup (requires encryption for provider credentials)
+	// DNS and hypervisor services (require encryption for provider credentials)
 	if encryptionKey != nil {
 		encryptor, err := credentials.NewEncryptor(encryptionKey)
 		if err != nil {
 			return fmt.Errorf("failed to create encryptor for DNS service: %w", err)
 		}
 		services.SetDNSService(store, encryptor)
+		services.SetHypervisorService(store, encryptor)
 
 		// Initialize and start DNS sync worker if interval is configured
 		if cfg.DNSSyncInterval > 0 {
@@ -108,6 +179,15 @@ func RunWithAdvancedFeatures(
 		} else {
 			log.Info("DNS sync disabled (interval set to 0)")
 		}
+
+		// Sync hosts and VMs from enabled hypervisor connectors
+		if cfg.HypervisorSyncInterval > 0 {
+			hypervisorWorker := worker.NewHypervisorSyncWorker(services.Hypervisors, cfg.HypervisorSyncInterval)
+			hypervisorWorker.Start()
+			defer hypervisorWorker.Stop()
+		} else {
+			log.Info("Hypervisor sync disabled (interval set to 0)")
+		}
 	}
 
 	// OAuth setup (conditional) - must be before RegisterRoutes
@@ -119,16 +199,26 @@ func RunWithAdvancedFeatures(
 		log.Info("MCP OAuth 2.1 enabled", "issuer", cfg.MCPOAuthIssuerURL)
 	}
 
+	// OIDC single sign-on (conditional) - must be before RegisterRoutes
+	if err := setupOIDC(cfg, store, sessionManager, services); err != nil {
+		return err
+	}
+
 	// API routes
 	log.Info("Login rate limiting enabled", "requests", cfg.LoginRateLimitRequests, "window", cfg.LoginRateLimitWindow)
+	loginLimiter := api.NewRateLimiter(cfg.LoginRateLimitRequests, cfg.LoginRateLimitWindow)
 	handler := api.NewHandler(store, scanner,
 		api.WithSessionManager(sessionManager),
 		api.WithCredentialsStorage(credStore),
 		api.WithProfileStorage(profileStore),
 		api.WithScheduledScanStorage(scheduledStore),
-		api.WithLoginRateLimiter(api.NewRateLimiter(cfg.LoginRateLimitRequests, cfg.LoginRateLimitWindow)),
+		api.WithLoginRateLimiter(loginLimiter),
 		api.WithCookieConfig(cfg.CookieSecure, cfg.SessionTTL),
 		api.WithTrustProxy(cfg.TrustProxy),
+		api.WithPublicURL(cfg.PublicURL),
+		api.WithUIBasePath(cfg.UIBasePath),
+		api.WithAPIDocs(cfg.APIDocsEnabled),
+		api.WithIdempotencyTTL(cfg.IdempotencyTTL),
 		api.WithServices(services),
 	)
 	handler.RegisterRoutes(mux)
@@ -146,20 +236,27 @@ func RunWithAdvancedFeatures(
 
 	// UI config (minimal)
 	uiBuilder := api.NewUIConfigBuilder()
+	if services.OIDC != nil {
+		uiBuilder.AddFeature("oidc")
+	}
 
 	// UI config endpoint
 	mux.HandleFunc("GET /api/config", uiBuilder.HandlerWithSession(sessionManager, store))
 
 	// Static UI
-	ui.RegisterRoutes(mux)
+	ui.RegisterRoutes(mux, ui.WithBasePath(cfg.UIBasePath))
 
 	// Apply middleware chain
-	var httpHandler http.Handler = mux
+	var httpHandler http.Handler = ui.StripBasePath(cfg.UIBasePath, mux)
+	var limiter *api.RateLimiter
 	if cfg.RateLimitEnabled {
 		log.Info("Rate limiting enabled", "requests", cfg.RateLimitRequests, "window", cfg.RateLimitWindow)
-		limiter := api.NewRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
+		limiter = api.NewRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
 		httpHandler = api.RateLimitMiddleware(limiter, cfg.TrustProxy)(httpHandler)
 	}
+
+	// Apply log level and rate limit changes on SIGHUP
+	defer reloadOnSignal(cfg, limiter, loginLimiter)()
 	httpHandler = api.LoggingMiddleware(api.SecurityHeaders(httpHandler))
 	if cfg.AuditEnabled {
 		log.Info("Audit logging enabled (storage-level)", "retention_days", cfg.AuditRetentionDays)
@@ -169,6 +266,9 @@ func RunWithAdvancedFeatures(
 	if cfg.RequestTimeout > 0 {
 		httpHandler = http.TimeoutHandler(httpHandler, cfg.RequestTimeout, `{"error": "Request timeout"}`)
 	}
+	if cfg.CompressionEnabled {
+		httpHandler = api.Compress(httpHandler)
+	}
 
 	server := &http.Server{
 		Addr:         cfg.ListenAddr,
@@ -179,23 +279,30 @@ func RunWithAdvancedFeatures(
 	}
 
 	// Graceful shutdown
-	errCh := make(chan error, 1)
-	go func() {
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		<-sigCh
-
-		log.Info("Shutting down...")
+	errCh := shutdownOnSignal(cfg, server, func() {
 		scheduler.Stop()
 		scheduledWorker.Stop()
+		passiveWorker.Stop()
+		autoPromotionWorker.Stop()
+		if cloudWorker != nil {
+			cloudWorker.Stop()
+		}
+		if monitorWorker != nil {
+			monitorWorker.Stop()
+		}
+		if trashWorker != nil {
+			trashWorker.Stop()
+		}
+		if backupWorker != nil {
+			backupWorker.Stop()
+		}
+		if warrantyWorker != nil {
+			warrantyWorker.Stop()
+		}
+		reportScheduleWorker.Stop()
+	}, scanner, webhookWorker)
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		errCh <- server.Shutdown(ctx)
-	}()
-
-	log.Info("Starting server", "addr", cfg.ListenAddr)
-	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+	if err := listenAndServe(cfg, server); err != http.ErrServerClosed {
 		return err
 	}
 	return <-errCh
@@ -244,13 +351,80 @@ func RunWithCustomRoutes(cfg *config.Config, store storage.ExtendedStorage, regi
 	if err := storage.BootstrapInitialAdmin(store, cfg, sessionManager); err != nil {
 		return fmt.Errorf("failed to bootstrap initial admin: %w", err)
 	}
+	if cfg.GenerateToken {
+		if _, err := storage.BootstrapAdminToken(store, cfg); err != nil {
+			return fmt.Errorf("failed to generate admin token: %w", err)
+		}
+	}
 
 	scanner := discovery.NewUnifiedScanner(store, nil, 30*time.Second, cfg.DiscoverySNMPv2cEnabled)
 	scheduler := worker.NewScheduler(store, scanner, cfg)
 	scheduler.Start()
 
+	// Passive mDNS/SSDP listener for networks that opt in via their discovery rule
+	passiveWorker := worker.NewPassiveDiscoveryWorker(store)
+	passiveWorker.Start()
+
+	// Deliver events to webhooks, retrying failed deliveries
+	webhookWorker := webhook.NewWorker(store, webhook.DefaultDeliveryConfig())
+	webhookWorker.Start()
+
+	// Send event summaries, such as discovery scan changes, to notification channels
+	notifier := notify.NewNotifier(store, notify.NewSender(notify.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	}))
+	notifier.Start()
+
 	// Create services registry
 	services := service.NewServices(store, sessionManager, scanner)
+	services.SetSearchLimits(service.SearchLimits{MaxRows: cfg.SearchMaxRows, Timeout: cfg.SearchTimeout})
+	serviceMappings, err := discovery.ParseServiceMappings(cfg.DiscoveryMappings)
+	if err != nil {
+		return fmt.Errorf("invalid DISCOVERY_SERVICE_MAPPINGS: %w", err)
+	}
+	services.Discovery.SetServiceMappings(serviceMappings)
+	policies, err := validate.FromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	services.SetValidationPolicies(policies)
+	services.SetUniqueDeviceNames(cfg.UniqueDeviceNames)
+	services.SetApprovalMode(cfg.ApprovalMode)
+
+	// Promote discovered devices matching auto-promotion rules
+	autoPromotionWorker := worker.NewAutoPromotionWorker(services.Discovery)
+	autoPromotionWorker.Start()
+
+	// Sync servers from cloud providers with configured credentials
+	cloudWorker, err := startCloudSync(cfg, services)
+	if err != nil {
+		return err
+	}
+
+	// Availability checks for documented devices
+	monitorWorker := startMonitor(cfg, services)
+
+	// Purge deleted items once they outlive the trash retention
+	trashWorker := startTrashPurge(cfg, services)
+
+	// Scheduled database backups
+	backupWorker := startBackups(cfg, services)
+
+	// Reminders of expiring warranties, sent to notification channels
+	services.Notifications.SetNotifier(notifier)
+	warrantyWorker, err := startWarrantyReminders(cfg, services)
+	if err != nil {
+		return err
+	}
+
+	// Scheduled reports, sent to notification channels
+	services.ReportSchedules.SetNotifier(notifier)
+	reportScheduleWorker := worker.NewReportScheduleWorker(services.ReportSchedules)
+	reportScheduleWorker.Start()
 
 	// OAuth setup (conditional) - must be before RegisterRoutes
 	if cfg.MCPOAuthEnabled {
@@ -261,12 +435,22 @@ func RunWithCustomRoutes(cfg *config.Config, store storage.ExtendedStorage, regi
 		log.Info("MCP OAuth 2.1 enabled", "issuer", cfg.MCPOAuthIssuerURL)
 	}
 
+	// OIDC single sign-on (conditional) - must be before RegisterRoutes
+	if err := setupOIDC(cfg, store, sessionManager, services); err != nil {
+		return err
+	}
+
 	// API routes
+	loginLimiter := api.NewRateLimiter(cfg.LoginRateLimitRequests, cfg.LoginRateLimitWindow)
 	handler := api.NewHandler(store, scanner,
 		api.WithSessionManager(sessionManager),
-		api.WithLoginRateLimiter(api.NewRateLimiter(cfg.LoginRateLimitRequests, cfg.LoginRateLimitWindow)),
+		api.WithLoginRateLimiter(loginLimiter),
 		api.WithCookieConfig(cfg.CookieSecure, cfg.SessionTTL),
 		api.WithTrustProxy(cfg.TrustProxy),
+		api.WithPublicURL(cfg.PublicURL),
+		api.WithUIBasePath(cfg.UIBasePath),
+		api.WithAPIDocs(cfg.APIDocsEnabled),
+		api.WithIdempotencyTTL(cfg.IdempotencyTTL),
 		api.WithServices(services),
 	)
 	handler.RegisterRoutes(mux)
@@ -284,20 +468,27 @@ func RunWithCustomRoutes(cfg *config.Config, store storage.ExtendedStorage, regi
 
 	// UI config (minimal)
 	uiBuilder := api.NewUIConfigBuilder()
+	if services.OIDC != nil {
+		uiBuilder.AddFeature("oidc")
+	}
 
 	// UI config endpoint
 	mux.HandleFunc("GET /api/config", uiBuilder.HandlerWithSession(sessionManager, store))
 
 	// Static UI
-	ui.RegisterRoutes(mux)
+	ui.RegisterRoutes(mux, ui.WithBasePath(cfg.UIBasePath))
 
 	// Apply middleware chain
-	var httpHandler http.Handler = mux
+	var httpHandler http.Handler = ui.StripBasePath(cfg.UIBasePath, mux)
+	var limiter *api.RateLimiter
 	if cfg.RateLimitEnabled {
 		log.Info("Rate limiting enabled", "requests", cfg.RateLimitRequests, "window", cfg.RateLimitWindow)
-		limiter := api.NewRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
+		limiter = api.NewRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
 		httpHandler = api.RateLimitMiddleware(limiter, cfg.TrustProxy)(httpHandler)
 	}
+
+	// Apply log level and rate limit changes on SIGHUP
+	defer reloadOnSignal(cfg, limiter, loginLimiter)()
 	httpHandler = api.LoggingMiddleware(api.SecurityHeaders(httpHandler))
 	if cfg.AuditEnabled {
 		log.Info("Audit logging enabled (storage-level)", "retention_days", cfg.AuditRetentionDays)
@@ -307,6 +498,9 @@ func RunWithCustomRoutes(cfg *config.Config, store storage.ExtendedStorage, regi
 	if cfg.RequestTimeout > 0 {
 		httpHandler = http.TimeoutHandler(httpHandler, cfg.RequestTimeout, `{"error": "Request timeout"}`)
 	}
+	if cfg.CompressionEnabled {
+		httpHandler = api.Compress(httpHandler)
+	}
 
 	server := &http.Server{
 		Addr:         cfg.ListenAddr,
@@ -317,23 +511,134 @@ func RunWithCustomRoutes(cfg *config.Config, store storage.ExtendedStorage, regi
 	}
 
 	// Graceful shutdown
-	errCh := make(chan error, 1)
-	go func() {
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		<-sigCh
-
-		log.Info("Shutting down...")
+	errCh := shutdownOnSignal(cfg, server, func() {
 		scheduler.Stop()
+		passiveWorker.Stop()
+		autoPromotionWorker.Stop()
+		if cloudWorker != nil {
+			cloudWorker.Stop()
+		}
+		if monitorWorker != nil {
+			monitorWorker.Stop()
+		}
+		if trashWorker != nil {
+			trashWorker.Stop()
+		}
+		if backupWorker != nil {
+			backupWorker.Stop()
+		}
+		if warrantyWorker != nil {
+			warrantyWorker.Stop()
+		}
+		reportScheduleWorker.Stop()
+	}, scanner, webhookWorker)
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		errCh <- server.Shutdown(ctx)
-	}()
-
-	log.Info("Starting server", "addr", cfg.ListenAddr)
-	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+	if err := listenAndServe(cfg, server); err != http.ErrServerClosed {
 		return err
 	}
 	return <-errCh
 }
+
+// setupOIDC enables login through the OIDC provider at OIDC_ISSUER_URL, if
+// one is configured
+func setupOIDC(cfg *config.Config, store storage.ExtendedStorage, sessionManager *auth.SessionManager, services *service.Services) error {
+	if cfg.OIDCIssuerURL == "" {
+		return nil
+	}
+	roleMap, err := service.ParseOIDCRoleMap(cfg.OIDCRoleMap)
+	if err != nil {
+		return fmt.Errorf("invalid OIDC_ROLE_MAP: %w", err)
+	}
+	provider := auth.NewOIDCProvider(auth.OIDCConfig{
+		IssuerURL:     cfg.OIDCIssuerURL,
+		ClientID:      cfg.OIDCClientID,
+		ClientSecret:  cfg.OIDCClientSecret,
+		RedirectURL:   cfg.OIDCRedirectURL,
+		Scopes:        strings.Fields(strings.ReplaceAll(cfg.OIDCScopes, ",", " ")),
+		UsernameClaim: cfg.OIDCUsernameClaim,
+		GroupsClaim:   cfg.OIDCGroupsClaim,
+	})
+	services.OIDC = service.NewOIDCService(store, sessionManager, provider, roleMap, cfg.OIDCDefaultRole, cfg.OIDCLinkExistingUsers)
+	log.Info("OIDC single sign-on enabled", "issuer", cfg.OIDCIssuerURL, "mapped_groups", len(roleMap))
+	return nil
+}
+
+// startCloudSync configures the cloud providers that have credentials set and
+// starts the periodic sync worker when CLOUD_SYNC_INTERVAL is non-zero. The
+// returned worker is nil when periodic sync is disabled.
+func startCloudSync(cfg *config.Config, services *service.Services) (*worker.CloudSyncWorker, error) {
+	regionMap, err := cloud.ParseRegionMap(cfg.CloudRegionMap)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CLOUD_REGION_MAP: %w", err)
+	}
+	providers, err := cloud.ProvidersFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CLOUD_K8S_KUBECONFIGS: %w", err)
+	}
+	services.Cloud.SetProviders(providers, regionMap)
+
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	if cfg.CloudSyncInterval <= 0 {
+		log.Info("Cloud sync providers configured but periodic sync disabled (CLOUD_SYNC_INTERVAL not set)", "providers", len(providers))
+		return nil, nil
+	}
+	w := worker.NewCloudSyncWorker(services.Cloud, cfg.CloudSyncInterval)
+	w.Start()
+	return w, nil
+}
+
+// startMonitor starts the availability check worker when MONITOR_ENABLED is
+// set. Checks can be configured either way; the returned worker is nil when
+// monitoring is disabled.
+func startMonitor(cfg *config.Config, services *service.Services) *worker.MonitorWorker {
+	if !cfg.MonitorEnabled {
+		return nil
+	}
+	services.Monitor.SetWorkers(cfg.MonitorWorkers)
+	w := worker.NewMonitorWorker(services.Monitor)
+	w.Start()
+	return w
+}
+
+// startTrashPurge starts the worker that purges expired trash items. The
+// returned worker is nil when TRASH_RETENTION_DAYS is 0 and items stay in the
+// trash until purged by hand.
+func startTrashPurge(cfg *config.Config, services *service.Services) *worker.TrashPurgeWorker {
+	services.Trash.SetRetention(cfg.TrashRetentionDays)
+	if cfg.TrashRetentionDays == 0 {
+		return nil
+	}
+	w := worker.NewTrashPurgeWorker(services.Trash)
+	w.Start()
+	return w
+}
+
+// startWarrantyReminders starts the worker sending warranty reminders. The
+// returned worker is nil when WARRANTY_REMINDER_DAYS is empty.
+func startWarrantyReminders(cfg *config.Config, services *service.Services) (*worker.WarrantyReminderWorker, error) {
+	days, err := cfg.WarrantyReminderDayList()
+	if err != nil {
+		return nil, err
+	}
+	if len(days) == 0 {
+		return nil, nil
+	}
+	w := worker.NewWarrantyReminderWorker(services.Notifications, days)
+	w.Start()
+	return w, nil
+}
+
+// startBackups sets up database backups, which can always be taken on demand,
+// and starts the worker taking them every BACKUP_INTERVAL. The returned
+// worker is nil when the interval is 0.
+func startBackups(cfg *config.Config, services *service.Services) *worker.BackupWorker {
+	services.Backups.SetManager(backup.FromConfig(cfg))
+	if cfg.BackupInterval == 0 {
+		return nil
+	}
+	w := worker.NewBackupWorker(services.Backups, cfg.BackupInterval)
+	w.Start()
+	return w
+}
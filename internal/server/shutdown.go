@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/discovery"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/webhook"
+)
+
+// shutdownOnSignal shuts down gracefully on SIGINT or SIGTERM and sends the
+// result of shutting down server on the returned channel
+func shutdownOnSignal(cfg *config.Config, server *http.Server, stopWorkers func(), scanner *discovery.UnifiedScanner, webhooks *webhook.Worker) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		<-sigCh
+
+		log.Info("Shutting down...", "timeout", cfg.ShutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		errCh <- shutdown(ctx, server, stopWorkers, scanner, webhooks)
+	}()
+	return errCh
+}
+
+// shutdown stops the background workers so no new scans start, drains
+// in-flight requests, then waits for running scans to record their progress
+// and for pending webhook deliveries and notifications. Whatever is still
+// running when ctx is done is abandoned.
+func shutdown(ctx context.Context, server *http.Server, stopWorkers func(), scanner *discovery.UnifiedScanner, webhooks *webhook.Worker) error {
+	stopWorkers()
+
+	err := server.Shutdown(ctx)
+	if err != nil {
+		log.Warn("Timed out draining HTTP connections", "error", err)
+	}
+	if err := scanner.Shutdown(ctx); err != nil {
+		log.Warn("Timed out waiting for discovery scans to stop", "error", err)
+	}
+	if err := webhooks.Shutdown(ctx); err != nil {
+		log.Warn("Timed out flushing webhook deliveries", "error", err)
+	}
+	log.Info("Shutdown complete")
+	return err
+}
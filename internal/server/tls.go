@@ -0,0 +1,127 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/log"
+)
+
+// defaultACMERedirectAddr is where ACME mode answers HTTP-01 challenges and
+// redirects to HTTPS unless HTTP_REDIRECT_ADDR is set
+const defaultACMERedirectAddr = ":80"
+
+// listenAndServe serves plain HTTP, or HTTPS when TLS is configured. With
+// TLS, a second plain HTTP listener redirects to HTTPS; it is closed when
+// server is shut down.
+func listenAndServe(cfg *config.Config, server *http.Server) error {
+	redirect, err := configureTLS(cfg, server)
+	if err != nil {
+		return err
+	}
+	if !cfg.TLSEnabled() {
+		log.Info("Starting server", "addr", cfg.ListenAddr)
+		return server.ListenAndServe()
+	}
+
+	if redirect != nil {
+		server.RegisterOnShutdown(func() { redirect.Close() })
+		go func() {
+			log.Info("Redirecting HTTP to HTTPS", "addr", redirect.Addr)
+			if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error("HTTP redirect listener failed", "addr", redirect.Addr, "error", err)
+			}
+		}()
+	}
+
+	log.Info("Starting server with TLS", "addr", cfg.ListenAddr)
+	return server.ListenAndServeTLS("", "")
+}
+
+// configureTLS sets server's TLS config from cfg and returns the HTTP
+// redirect server, or nil when there is none
+func configureTLS(cfg *config.Config, server *http.Server) (*http.Server, error) {
+	redirectAddr := cfg.HTTPRedirectAddr
+	var redirectHandler http.Handler = httpsRedirect(cfg.ListenAddr)
+
+	switch {
+	case cfg.TLSCert != "":
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		server.TLSConfig = &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{cert},
+		}
+
+	case cfg.ACMEHosts != "":
+		manager := newACMEManager(cfg)
+		server.TLSConfig = manager.TLSConfig()
+		server.TLSConfig.MinVersion = tls.VersionTLS12
+		log.Info("Using ACME certificates", "hosts", cfg.ACMEHostList(), "cache", manager.Cache)
+
+		// The redirect listener also answers HTTP-01 challenges
+		redirectHandler = manager.HTTPHandler(redirectHandler)
+		if redirectAddr == "" {
+			redirectAddr = defaultACMERedirectAddr
+		}
+
+	default:
+		return nil, nil
+	}
+
+	if redirectAddr == "" {
+		return nil, nil
+	}
+	return &http.Server{
+		Addr:              redirectAddr,
+		Handler:           redirectHandler,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}, nil
+}
+
+func newACMEManager(cfg *config.Config) *autocert.Manager {
+	cacheDir := cfg.ACMECacheDir
+	if cacheDir == "" {
+		cacheDir = filepath.Join(cfg.DataDir, "acme")
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.ACMEHostList()...),
+		Cache:      autocert.DirCache(cacheDir),
+		Email:      cfg.ACMEEmail,
+	}
+	if cfg.ACMEDirectoryURL != "" {
+		manager.Client = &acme.Client{DirectoryURL: cfg.ACMEDirectoryURL}
+	}
+	return manager
+}
+
+// httpsRedirect redirects requests to the same host and path on the HTTPS
+// listener at listenAddr
+func httpsRedirect(listenAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(listenAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		} else if net.ParseIP(host) != nil && net.ParseIP(host).To4() == nil {
+			host = "[" + host + "]"
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+)
+
+func TestHTTPSRedirect(t *testing.T) {
+	tests := []struct {
+		listenAddr string
+		host       string
+		want       string
+	}{
+		{":443", "rackd.example.com", "https://rackd.example.com/api/devices?q=web"},
+		{":443", "rackd.example.com:80", "https://rackd.example.com/api/devices?q=web"},
+		{":8443", "rackd.example.com:8080", "https://rackd.example.com:8443/api/devices?q=web"},
+		{":443", "[::1]:80", "https://[::1]/api/devices?q=web"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/devices?q=web", nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+		httpsRedirect(tt.listenAddr).ServeHTTP(w, req)
+
+		if w.Code != http.StatusMovedPermanently {
+			t.Errorf("%s: expected 301, got %d", tt.host, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != tt.want {
+			t.Errorf("%s: expected redirect to %s, got %s", tt.host, tt.want, got)
+		}
+	}
+}
+
+func TestConfigureTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+
+	// Plain HTTP
+	server := &http.Server{}
+	redirect, err := configureTLS(&config.Config{ListenAddr: ":8080"}, server)
+	if err != nil || redirect != nil || server.TLSConfig != nil {
+		t.Fatalf("expected no TLS, got redirect=%v tls=%v err=%v", redirect, server.TLSConfig, err)
+	}
+
+	// Certificate without a redirect listener
+	cfg := &config.Config{ListenAddr: ":8443", TLSCert: certFile, TLSKey: keyFile}
+	redirect, err = configureTLS(cfg, server)
+	if err != nil {
+		t.Fatalf("configureTLS failed: %v", err)
+	}
+	if redirect != nil || server.TLSConfig == nil || len(server.TLSConfig.Certificates) != 1 {
+		t.Errorf("expected one certificate and no redirect, got redirect=%v tls=%v", redirect, server.TLSConfig)
+	}
+
+	cfg.HTTPRedirectAddr = ":8080"
+	if redirect, err = configureTLS(cfg, &http.Server{}); err != nil || redirect == nil || redirect.Addr != ":8080" {
+		t.Errorf("expected a redirect listener on :8080, got %v, %v", redirect, err)
+	}
+
+	cfg.TLSKey = filepath.Join(dir, "missing.pem")
+	if _, err := configureTLS(cfg, &http.Server{}); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+
+	// ACME answers challenges on :80 by default
+	cfg = &config.Config{ListenAddr: ":443", DataDir: dir, ACMEHosts: "rackd.example.com"}
+	server = &http.Server{}
+	redirect, err = configureTLS(cfg, server)
+	if err != nil || redirect == nil || redirect.Addr != defaultACMERedirectAddr {
+		t.Fatalf("expected a redirect listener on %s, got %v, %v", defaultACMERedirectAddr, redirect, err)
+	}
+	if server.TLSConfig == nil || server.TLSConfig.GetCertificate == nil {
+		t.Error("expected certificates from the ACME manager")
+	}
+}
+
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
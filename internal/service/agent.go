@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/auth"
+	"github.com/martinsuchenak/rackd/internal/discovery"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// AgentService manages remote scanning agents. Registration and network
+// assignment are governed by the discovery permissions; agents themselves
+// authenticate with their own token and may only report on the networks
+// assigned to them.
+type AgentService struct {
+	store storage.ExtendedStorage
+}
+
+func NewAgentService(store storage.ExtendedStorage) *AgentService {
+	return &AgentService{store: store}
+}
+
+func (s *AgentService) List(ctx context.Context) ([]model.Agent, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "list"); err != nil {
+		return nil, err
+	}
+
+	agents, err := s.store.ListAgents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range agents {
+		agents[i].Online = agents[i].IsOnline(now)
+	}
+	return agents, nil
+}
+
+func (s *AgentService) Get(ctx context.Context, id string) (*model.Agent, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "read"); err != nil {
+		return nil, err
+	}
+
+	agent, err := s.store.GetAgent(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrAgentNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	agent.Online = agent.IsOnline(time.Now())
+	return agent, nil
+}
+
+// Register creates an agent and returns its token, which is only shown once
+func (s *AgentService) Register(ctx context.Context, agent *model.Agent) (*model.AgentRegistration, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "create"); err != nil {
+		return nil, err
+	}
+	if err := s.validateAgent(ctx, agent); err != nil {
+		return nil, err
+	}
+
+	token, err := auth.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateAgent(enrichAuditCtx(ctx), agent, auth.HashToken(token)); err != nil {
+		if errors.Is(err, storage.ErrDuplicateAgentName) {
+			return nil, ValidationErrors{{Field: "name", Message: "An agent with this name already exists"}}
+		}
+		return nil, err
+	}
+	return &model.AgentRegistration{Agent: agent, Token: token}, nil
+}
+
+// Update changes an agent's name, description and assigned networks
+func (s *AgentService) Update(ctx context.Context, agent *model.Agent) error {
+	if err := requirePermission(ctx, s.store, "discovery", "update"); err != nil {
+		return err
+	}
+	if agent.ID == "" {
+		return ValidationErrors{{Field: "id", Message: "ID is required"}}
+	}
+	if err := s.validateAgent(ctx, agent); err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateAgent(enrichAuditCtx(ctx), agent); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrAgentNotFound):
+			return ErrNotFound
+		case errors.Is(err, storage.ErrDuplicateAgentName):
+			return ValidationErrors{{Field: "name", Message: "An agent with this name already exists"}}
+		}
+		return err
+	}
+	return nil
+}
+
+// RotateToken issues a new token for an agent, invalidating the old one
+func (s *AgentService) RotateToken(ctx context.Context, id string) (*model.AgentRegistration, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "update"); err != nil {
+		return nil, err
+	}
+
+	token, err := auth.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateAgentToken(enrichAuditCtx(ctx), id, auth.HashToken(token)); err != nil {
+		if errors.Is(err, storage.ErrAgentNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	agent, err := s.store.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AgentRegistration{Agent: agent, Token: token}, nil
+}
+
+func (s *AgentService) Delete(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, s.store, "discovery", "delete"); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteAgent(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrAgentNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *AgentService) validateAgent(ctx context.Context, agent *model.Agent) error {
+	var errs ValidationErrors
+	agent.Name = strings.TrimSpace(agent.Name)
+	if agent.Name == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "Name is required"})
+	}
+
+	seen := make(map[string]bool, len(agent.NetworkIDs))
+	networkIDs := make([]string, 0, len(agent.NetworkIDs))
+	for _, id := range agent.NetworkIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.store.GetNetwork(ctx, id); err != nil {
+			if !errors.Is(err, storage.ErrNetworkNotFound) {
+				return err
+			}
+			errs = append(errs, ValidationError{Field: "network_ids", Message: fmt.Sprintf("Network %s not found", id)})
+			continue
+		}
+		networkIDs = append(networkIDs, id)
+	}
+	agent.NetworkIDs = networkIDs
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Authenticate resolves an agent token to its agent
+func (s *AgentService) Authenticate(ctx context.Context, token string) (*model.Agent, error) {
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	agent, err := s.store.GetAgentByTokenHash(ctx, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, storage.ErrAgentNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+	return agent, nil
+}
+
+// Heartbeat records that an agent is alive and returns the networks it
+// should scan, with their discovery rules
+func (s *AgentService) Heartbeat(ctx context.Context, agent *model.Agent, hb *model.AgentHeartbeat) (*model.AgentConfig, error) {
+	if err := s.store.UpdateAgentHeartbeat(ctx, agent.ID, hb); err != nil {
+		if errors.Is(err, storage.ErrAgentNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+
+	cfg := &model.AgentConfig{AgentID: agent.ID, Name: agent.Name, Networks: []model.AgentNetwork{}}
+	for _, id := range agent.NetworkIDs {
+		network, err := s.store.GetNetwork(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNetworkNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		assigned := model.AgentNetwork{Network: *network}
+		if rule, err := s.store.GetDiscoveryRuleByNetwork(ctx, id); err == nil {
+			assigned.Rule = rule
+		}
+		cfg.Networks = append(cfg.Networks, assigned)
+	}
+	return cfg, nil
+}
+
+// SubmitResults stores the devices found by an agent scan. Devices are merged
+// with earlier sightings by IP, exactly as central scans are, and a completed
+// scan record is written so agent scans show up in the scan history.
+func (s *AgentService) SubmitResults(ctx context.Context, agent *model.Agent, result *model.AgentScanResult) (*model.DiscoveryScan, error) {
+	if result.NetworkID == "" {
+		return nil, ValidationErrors{{Field: "network_id", Message: "Network ID is required"}}
+	}
+	assigned := false
+	for _, id := range agent.NetworkIDs {
+		if id == result.NetworkID {
+			assigned = true
+			break
+		}
+	}
+	if !assigned {
+		return nil, ErrForbidden
+	}
+
+	now := time.Now().UTC()
+	scan := &model.DiscoveryScan{
+		NetworkID:       result.NetworkID,
+		Status:          model.ScanStatusCompleted,
+		ScanType:        result.ScanType,
+		TotalHosts:      result.TotalHosts,
+		ScannedHosts:    result.TotalHosts,
+		FoundHosts:      len(result.Devices),
+		ProgressPercent: 100,
+		StartedAt:       result.StartedAt,
+		CompletedAt:     &now,
+	}
+	if result.Error != "" {
+		scan.Status = model.ScanStatusFailed
+		scan.ErrorMessage = fmt.Sprintf("agent %s: %s", agent.Name, result.Error)
+	}
+	if err := s.store.CreateDiscoveryScan(ctx, scan); err != nil {
+		return nil, err
+	}
+
+	for i := range result.Devices {
+		device := result.Devices[i]
+		if device.IP == "" {
+			continue
+		}
+		device.NetworkID = result.NetworkID
+		device.PromotedToDeviceID = ""
+		device.PromotedAt = nil
+
+		// Agents do not report which ports they probed, so their results
+		// open ports but never close them
+		if err := s.store.RecordPortObservations(ctx, scan.ID, &device, nil); err != nil {
+			log.Warn("Failed to record agent-discovered ports", "agent", agent.Name, "ip", device.IP, "error", err)
+		}
+
+		existing, _ := s.store.GetDiscoveredDeviceByIP(ctx, result.NetworkID, device.IP)
+		if existing != nil {
+			device.ID = existing.ID
+			device.FirstSeen = existing.FirstSeen
+			device.ScanCount = existing.ScanCount + 1
+			discovery.ScoreSightings(&device)
+			device.PromotedToDeviceID = existing.PromotedToDeviceID
+			device.PromotedAt = existing.PromotedAt
+			if err := s.store.UpdateDiscoveredDevice(ctx, &device); err != nil {
+				log.Warn("Failed to update agent-discovered device", "agent", agent.Name, "ip", device.IP, "error", err)
+			}
+			continue
+		}
+		device.ID = ""
+		device.ScanCount = 1
+		discovery.ScoreSightings(&device)
+		if err := s.store.CreateDiscoveredDevice(ctx, &device); err != nil {
+			log.Warn("Failed to create agent-discovered device", "agent", agent.Name, "ip", device.IP, "error", err)
+		}
+	}
+
+	log.Info("Agent scan results received", "agent", agent.Name, "network_id", result.NetworkID, "found", len(result.Devices))
+	return scan, nil
+}
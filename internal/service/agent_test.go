@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// agentTestStorage adds the agent and discovered-device writes used by
+// AgentService to the shared service test storage
+type agentTestStorage struct {
+	*serviceTestStorage
+	agents     map[string]*model.Agent
+	tokens     map[string]string
+	discovered map[string]*model.DiscoveredDevice
+	scans      []*model.DiscoveryScan
+}
+
+func newAgentTestStorage() *agentTestStorage {
+	store := &agentTestStorage{
+		serviceTestStorage: newServiceTestStorage(),
+		agents:             make(map[string]*model.Agent),
+		tokens:             make(map[string]string),
+		discovered:         make(map[string]*model.DiscoveredDevice),
+	}
+	store.setPermission("user-1", "discovery", "create", true)
+	store.networks = append(store.networks, model.Network{ID: "net-1", Name: "remote", Subnet: "10.0.0.0/24"})
+	return store
+}
+
+func (s *agentTestStorage) CreateAgent(_ context.Context, agent *model.Agent, tokenHash string) error {
+	for _, existing := range s.agents {
+		if existing.Name == agent.Name {
+			return storage.ErrDuplicateAgentName
+		}
+	}
+	agent.ID = "agent-" + agent.Name
+	cloned := *agent
+	s.agents[agent.ID] = &cloned
+	s.tokens[tokenHash] = agent.ID
+	return nil
+}
+
+func (s *agentTestStorage) GetAgentByTokenHash(_ context.Context, tokenHash string) (*model.Agent, error) {
+	id, ok := s.tokens[tokenHash]
+	if !ok {
+		return nil, storage.ErrAgentNotFound
+	}
+	cloned := *s.agents[id]
+	return &cloned, nil
+}
+
+func (s *agentTestStorage) CreateDiscoveryScan(_ context.Context, scan *model.DiscoveryScan) error {
+	s.scans = append(s.scans, scan)
+	return nil
+}
+
+func (s *agentTestStorage) GetDiscoveredDeviceByIP(_ context.Context, networkID, ip string) (*model.DiscoveredDevice, error) {
+	if d, ok := s.discovered[networkID+"/"+ip]; ok {
+		cloned := *d
+		return &cloned, nil
+	}
+	return nil, storage.ErrDeviceNotFound
+}
+
+func (s *agentTestStorage) CreateDiscoveredDevice(_ context.Context, device *model.DiscoveredDevice) error {
+	device.ID = "disc-" + device.IP
+	cloned := *device
+	s.discovered[device.NetworkID+"/"+device.IP] = &cloned
+	return nil
+}
+
+func (s *agentTestStorage) UpdateDiscoveredDevice(_ context.Context, device *model.DiscoveredDevice) error {
+	cloned := *device
+	s.discovered[device.NetworkID+"/"+device.IP] = &cloned
+	return nil
+}
+
+func (s *agentTestStorage) RecordPortObservations(_ context.Context, _ string, _ *model.DiscoveredDevice, _ []int) error {
+	return nil
+}
+
+func TestAgentServiceRegister(t *testing.T) {
+	store := newAgentTestStorage()
+	svc := NewAgentService(store)
+	ctx := userContext("user-1")
+
+	reg, err := svc.Register(ctx, &model.Agent{Name: " site-a ", NetworkIDs: []string{"net-1", "net-1", ""}})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if reg.Token == "" {
+		t.Fatal("expected a token")
+	}
+	if reg.Agent.Name != "site-a" || len(reg.Agent.NetworkIDs) != 1 {
+		t.Errorf("expected trimmed name and deduplicated networks, got %+v", reg.Agent)
+	}
+
+	agent, err := svc.Authenticate(context.Background(), reg.Token)
+	if err != nil || agent.ID != reg.Agent.ID {
+		t.Errorf("expected token to authenticate agent, got %v, %v", agent, err)
+	}
+	if _, err := svc.Authenticate(context.Background(), "wrong"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+
+	_, err = svc.Register(ctx, &model.Agent{Name: "site-a"})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Errorf("expected validation error for duplicate name, got %v", err)
+	}
+
+	_, err = svc.Register(ctx, &model.Agent{Name: "site-b", NetworkIDs: []string{"missing"}})
+	if !errors.As(err, &verrs) || verrs[0].Field != "network_ids" {
+		t.Errorf("expected network_ids validation error, got %v", err)
+	}
+}
+
+func TestAgentServiceRegister_RequiresPermission(t *testing.T) {
+	svc := NewAgentService(newAgentTestStorage())
+
+	if _, err := svc.Register(userContext("user-2"), &model.Agent{Name: "site-a"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestAgentServiceSubmitResults(t *testing.T) {
+	store := newAgentTestStorage()
+	svc := NewAgentService(store)
+	agent := &model.Agent{ID: "agent-1", Name: "site-a", NetworkIDs: []string{"net-1"}}
+	ctx := context.Background()
+
+	t.Run("UnassignedNetwork", func(t *testing.T) {
+		_, err := svc.SubmitResults(ctx, agent, &model.AgentScanResult{NetworkID: "net-2"})
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden, got %v", err)
+		}
+	})
+
+	t.Run("CreatesThenMerges", func(t *testing.T) {
+		result := &model.AgentScanResult{
+			NetworkID: "net-1",
+			ScanType:  model.ScanTypeQuick,
+			Devices:   []model.DiscoveredDevice{{IP: "10.0.0.5", Hostname: "old"}},
+		}
+		if _, err := svc.SubmitResults(ctx, agent, result); err != nil {
+			t.Fatalf("first SubmitResults failed: %v", err)
+		}
+
+		// Mark as promoted so the merge must preserve it
+		store.discovered["net-1/10.0.0.5"].PromotedToDeviceID = "device-1"
+
+		result.Devices = []model.DiscoveredDevice{{IP: "10.0.0.5", Hostname: "new"}}
+		scan, err := svc.SubmitResults(ctx, agent, result)
+		if err != nil {
+			t.Fatalf("second SubmitResults failed: %v", err)
+		}
+		if scan.Status != model.ScanStatusCompleted || scan.FoundHosts != 1 {
+			t.Errorf("unexpected scan record: %+v", scan)
+		}
+
+		got := store.discovered["net-1/10.0.0.5"]
+		if got.Hostname != "new" || got.ScanCount != 2 || got.PromotedToDeviceID != "device-1" || got.ID != "disc-10.0.0.5" {
+			t.Errorf("expected merged device, got %+v", got)
+		}
+	})
+
+	t.Run("FailedScan", func(t *testing.T) {
+		scan, err := svc.SubmitResults(ctx, agent, &model.AgentScanResult{NetworkID: "net-1", Error: "permission denied"})
+		if err != nil {
+			t.Fatalf("SubmitResults failed: %v", err)
+		}
+		if scan.Status != model.ScanStatusFailed || scan.ErrorMessage != "agent site-a: permission denied" {
+			t.Errorf("expected failed scan record, got %+v", scan)
+		}
+	})
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// DefaultAutoPromotionNameTemplate names promoted devices after their hostname
+const DefaultAutoPromotionNameTemplate = "{hostname}"
+
+func (s *DiscoveryService) ListAutoPromotionRules(ctx context.Context, networkID string) ([]model.AutoPromotionRule, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "list"); err != nil {
+		return nil, err
+	}
+	return s.store.ListAutoPromotionRules(ctx, networkID)
+}
+
+func (s *DiscoveryService) GetAutoPromotionRule(ctx context.Context, id string) (*model.AutoPromotionRule, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "read"); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.store.GetAutoPromotionRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrAutoPromotionRuleNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *DiscoveryService) CreateAutoPromotionRule(ctx context.Context, rule *model.AutoPromotionRule) error {
+	if err := requirePermission(ctx, s.store, "discovery", "create"); err != nil {
+		return err
+	}
+	if err := s.validateAutoPromotionRule(ctx, rule); err != nil {
+		return err
+	}
+	return s.store.CreateAutoPromotionRule(enrichAuditCtx(ctx), rule)
+}
+
+func (s *DiscoveryService) UpdateAutoPromotionRule(ctx context.Context, rule *model.AutoPromotionRule) error {
+	if err := requirePermission(ctx, s.store, "discovery", "update"); err != nil {
+		return err
+	}
+	if rule.ID == "" {
+		return ValidationErrors{{Field: "id", Message: "ID is required"}}
+	}
+	if err := s.validateAutoPromotionRule(ctx, rule); err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateAutoPromotionRule(enrichAuditCtx(ctx), rule); err != nil {
+		if errors.Is(err, storage.ErrAutoPromotionRuleNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *DiscoveryService) DeleteAutoPromotionRule(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, s.store, "discovery", "delete"); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteAutoPromotionRule(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrAutoPromotionRuleNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *DiscoveryService) validateAutoPromotionRule(ctx context.Context, rule *model.AutoPromotionRule) error {
+	var errs ValidationErrors
+	if rule.NetworkID == "" {
+		errs = append(errs, ValidationError{Field: "network_id", Message: "Network ID is required"})
+	} else if _, err := s.store.GetNetwork(ctx, rule.NetworkID); err != nil {
+		if !errors.Is(err, storage.ErrNetworkNotFound) {
+			return err
+		}
+		errs = append(errs, ValidationError{Field: "network_id", Message: "Network not found"})
+	}
+	if strings.TrimSpace(rule.Name) == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "Name is required"})
+	}
+	if rule.MinConfidence < 0 || rule.MinConfidence > 100 {
+		errs = append(errs, ValidationError{Field: "min_confidence", Message: "Minimum confidence must be between 0 and 100"})
+	}
+	if rule.MinScans < 0 {
+		errs = append(errs, ValidationError{Field: "min_scans", Message: "Minimum scans cannot be negative"})
+	}
+	for factor, min := range rule.MinFactorPoints {
+		max, ok := model.ConfidenceFactorMax[factor]
+		if !ok {
+			errs = append(errs, ValidationError{Field: "min_factor_points", Message: fmt.Sprintf("Unknown confidence factor %q", factor)})
+		} else if min < 0 || min > max {
+			errs = append(errs, ValidationError{Field: "min_factor_points", Message: fmt.Sprintf("Minimum points for %s must be between 0 and %d", factor, max)})
+		}
+	}
+	if rule.HostnamePattern != "" {
+		if _, err := regexp.Compile(rule.HostnamePattern); err != nil {
+			errs = append(errs, ValidationError{Field: "hostname_pattern", Message: "Hostname pattern is not a valid regular expression"})
+		}
+	}
+	if rule.NameTemplate == "" {
+		rule.NameTemplate = DefaultAutoPromotionNameTemplate
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// RunAutoPromotion evaluates enabled auto-promotion rules and promotes every
+// unpromoted, unignored discovered device that satisfies one. When networkID is empty
+// all networks are evaluated.
+func (s *DiscoveryService) RunAutoPromotion(ctx context.Context, networkID string) ([]model.AutoPromotionResult, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "create"); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.store.ListAutoPromotionRules(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []model.AutoPromotionResult{}
+	promoted := make(map[string]bool)
+	devicesByNetwork := make(map[string][]model.DiscoveredDevice)
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		var hostnameRe *regexp.Regexp
+		if rule.HostnamePattern != "" {
+			if hostnameRe, err = regexp.Compile(rule.HostnamePattern); err != nil {
+				log.Warn("Skipping auto-promotion rule with invalid hostname pattern", "rule_id", rule.ID, "error", err)
+				continue
+			}
+		}
+
+		discovered, ok := devicesByNetwork[rule.NetworkID]
+		if !ok {
+			if discovered, err = s.store.ListDiscoveredDevices(ctx, rule.NetworkID); err != nil {
+				return results, err
+			}
+			devicesByNetwork[rule.NetworkID] = discovered
+		}
+
+		for _, dd := range discovered {
+			if dd.PromotedToDeviceID != "" || dd.Ignored() || promoted[dd.ID] || !matchesAutoPromotionRule(&rule, hostnameRe, &dd) {
+				continue
+			}
+
+			device := &model.Device{
+				Name:         renderAutoPromotionName(rule.NameTemplate, &dd),
+				Tags:         append([]string{}, rule.Tags...),
+				DatacenterID: rule.DatacenterID,
+			}
+			created, err := s.PromoteDevice(ctx, dd.ID, device)
+			if err != nil {
+				log.Warn("Auto-promotion failed", "rule_id", rule.ID, "discovered_id", dd.ID, "ip", dd.IP, "error", err)
+				continue
+			}
+			promoted[dd.ID] = true
+			results = append(results, model.AutoPromotionResult{
+				RuleID:       rule.ID,
+				DiscoveredID: dd.ID,
+				DeviceID:     created.ID,
+				DeviceName:   created.Name,
+				IP:           dd.IP,
+			})
+		}
+	}
+
+	if len(results) > 0 {
+		log.Info("Auto-promoted discovered devices", "count", len(results))
+	}
+	return results, nil
+}
+
+func matchesAutoPromotionRule(rule *model.AutoPromotionRule, hostnameRe *regexp.Regexp, dd *model.DiscoveredDevice) bool {
+	if dd.Confidence < rule.MinConfidence {
+		return false
+	}
+	if dd.ScanCount < rule.MinScans {
+		return false
+	}
+	for factor, min := range rule.MinFactorPoints {
+		if dd.FactorPoints(factor) < min {
+			return false
+		}
+	}
+	if hostnameRe != nil && !hostnameRe.MatchString(dd.Hostname) {
+		return false
+	}
+	return true
+}
+
+// renderAutoPromotionName expands {hostname}, {short_hostname}, {ip},
+// {ip_dashed} and {vendor} in a name template. Hostname placeholders fall
+// back to the dashed IP when the device has no hostname.
+func renderAutoPromotionName(template string, dd *model.DiscoveredDevice) string {
+	ipDashed := strings.NewReplacer(".", "-", ":", "-").Replace(dd.IP)
+	hostname := dd.Hostname
+	if hostname == "" {
+		hostname = ipDashed
+	}
+	short, _, _ := strings.Cut(hostname, ".")
+	if dd.Hostname == "" {
+		short = ipDashed
+	}
+
+	name := strings.NewReplacer(
+		"{hostname}", hostname,
+		"{short_hostname}", short,
+		"{ip}", dd.IP,
+		"{ip_dashed}", ipDashed,
+		"{vendor}", dd.Vendor,
+	).Replace(template)
+	if name = strings.TrimSpace(name); name == "" {
+		return ipDashed
+	}
+	return name
+}
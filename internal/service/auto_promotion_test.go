@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestDiscoveryService_RunAutoPromotionAppliesConditions(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "create", true)
+	store.rules = []model.AutoPromotionRule{
+		{ID: "rule-off", NetworkID: "net-1", Enabled: false},
+		{
+			ID: "rule-1", NetworkID: "net-1", Enabled: true,
+			MinConfidence: 80, HostnamePattern: `^srv-`, MinScans: 3,
+			NameTemplate: "{short_hostname}", Tags: []string{"auto"}, DatacenterID: "dc-1",
+		},
+	}
+	store.discovered["match"] = &model.DiscoveredDevice{ID: "match", NetworkID: "net-1", IP: "10.0.0.5", Hostname: "srv-web.example.com", Confidence: 90, ScanCount: 3}
+	store.discovered["low-confidence"] = &model.DiscoveredDevice{ID: "low-confidence", NetworkID: "net-1", IP: "10.0.0.6", Hostname: "srv-db", Confidence: 50, ScanCount: 5}
+	store.discovered["few-scans"] = &model.DiscoveredDevice{ID: "few-scans", NetworkID: "net-1", IP: "10.0.0.7", Hostname: "srv-app", Confidence: 90, ScanCount: 1}
+	store.discovered["wrong-name"] = &model.DiscoveredDevice{ID: "wrong-name", NetworkID: "net-1", IP: "10.0.0.8", Hostname: "printer", Confidence: 90, ScanCount: 9}
+	store.discovered["promoted"] = &model.DiscoveredDevice{ID: "promoted", NetworkID: "net-1", IP: "10.0.0.9", Hostname: "srv-old", Confidence: 90, ScanCount: 9, PromotedToDeviceID: "dev-1"}
+	store.discovered["ignored"] = &model.DiscoveredDevice{ID: "ignored", NetworkID: "net-1", IP: "10.0.0.10", Hostname: "srv-guest", Confidence: 90, ScanCount: 9, Status: model.DiscoveredStatusIgnored}
+
+	results, err := NewDiscoveryService(store, nil).RunAutoPromotion(userContext("user-1"), "net-1")
+	if err != nil {
+		t.Fatalf("RunAutoPromotion returned unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].DiscoveredID != "match" || results[0].RuleID != "rule-1" {
+		t.Fatalf("expected only the matching device to be promoted, got %#v", results)
+	}
+	if store.created == nil || store.created.Name != "srv-web" || store.created.DatacenterID != "dc-1" {
+		t.Fatalf("expected templated name and datacenter, got %#v", store.created)
+	}
+	if len(store.created.Tags) != 1 || store.created.Tags[0] != "auto" {
+		t.Fatalf("expected default tags, got %#v", store.created.Tags)
+	}
+}
+
+func TestDiscoveryService_RunAutoPromotionChecksFactorPoints(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "create", true)
+	store.rules = []model.AutoPromotionRule{{
+		ID: "rule-1", NetworkID: "net-1", Enabled: true, MinConfidence: 40,
+		MinFactorPoints: map[string]int{model.ConfidenceFactorSNMP: 20},
+	}}
+	snmp := []model.ConfidenceFactor{{Factor: model.ConfidenceFactorSNMP, Points: 20, Max: 20}}
+	ports := []model.ConfidenceFactor{{Factor: model.ConfidenceFactorOpenPorts, Points: 15, Max: 15}}
+	store.discovered["snmp"] = &model.DiscoveredDevice{ID: "snmp", NetworkID: "net-1", IP: "10.0.0.5", Confidence: 40, ConfidenceBreakdown: snmp}
+	store.discovered["ports"] = &model.DiscoveredDevice{ID: "ports", NetworkID: "net-1", IP: "10.0.0.6", Confidence: 90, ConfidenceBreakdown: ports}
+
+	results, err := NewDiscoveryService(store, nil).RunAutoPromotion(userContext("user-1"), "net-1")
+	if err != nil {
+		t.Fatalf("RunAutoPromotion returned unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].DiscoveredID != "snmp" {
+		t.Fatalf("expected only the device with SNMP points to be promoted, got %#v", results)
+	}
+}
+
+func TestDiscoveryService_CreateAutoPromotionRuleFactorRange(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "create", true)
+	store.networks["net-1"] = &model.Network{ID: "net-1"}
+	svc := NewDiscoveryService(store, nil)
+
+	err := svc.CreateAutoPromotionRule(userContext("user-1"), &model.AutoPromotionRule{
+		NetworkID: "net-1", Name: "too many", MinFactorPoints: map[string]int{model.ConfidenceFactorRepeatSightings: 11},
+	})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Field != "min_factor_points" {
+		t.Fatalf("expected min_factor_points validation error, got %v", err)
+	}
+}
+
+func TestDiscoveryService_RunAutoPromotionRequiresPermission(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	if _, err := NewDiscoveryService(store, nil).RunAutoPromotion(userContext("user-1"), ""); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestDiscoveryService_CreateAutoPromotionRuleValidation(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "create", true)
+	svc := NewDiscoveryService(store, nil)
+
+	err := svc.CreateAutoPromotionRule(userContext("user-1"), &model.AutoPromotionRule{
+		NetworkID:       "missing",
+		MinConfidence:   120,
+		MinScans:        -1,
+		HostnamePattern: "(",
+		MinFactorPoints: map[string]int{"uptime": 5},
+	})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, v := range verrs {
+		fields[v.Field] = true
+	}
+	for _, f := range []string{"network_id", "name", "min_confidence", "min_scans", "hostname_pattern", "min_factor_points"} {
+		if !fields[f] {
+			t.Errorf("expected validation error for %s, got %v", f, verrs)
+		}
+	}
+}
+
+func TestRenderAutoPromotionName(t *testing.T) {
+	tests := []struct {
+		template string
+		device   model.DiscoveredDevice
+		want     string
+	}{
+		{"{hostname}", model.DiscoveredDevice{IP: "10.0.0.1", Hostname: "web.example.com"}, "web.example.com"},
+		{"{short_hostname}", model.DiscoveredDevice{IP: "10.0.0.1", Hostname: "web.example.com"}, "web"},
+		{"{hostname}", model.DiscoveredDevice{IP: "10.0.0.1"}, "10-0-0-1"},
+		{"host-{ip_dashed}", model.DiscoveredDevice{IP: "10.0.0.1"}, "host-10-0-0-1"},
+		{"{vendor}", model.DiscoveredDevice{IP: "10.0.0.1"}, "10-0-0-1"},
+	}
+	for _, tt := range tests {
+		if got := renderAutoPromotionName(tt.template, &tt.device); got != tt.want {
+			t.Errorf("renderAutoPromotionName(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/martinsuchenak/rackd/internal/backup"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// errBackupsUnavailable is returned when the server has no backup manager,
+// as when running on an in-memory database
+var errBackupsUnavailable = errors.New("backups are not available")
+
+// BackupService takes database backups. A backup holds every record and
+// credential, so it is limited to admins.
+type BackupService struct {
+	store   storage.ExtendedStorage
+	manager *backup.Manager
+}
+
+func NewBackupService(store storage.ExtendedStorage) *BackupService {
+	return &BackupService{store: store}
+}
+
+// SetManager sets the manager that writes and stores the backups
+func (s *BackupService) SetManager(m *backup.Manager) {
+	s.manager = m
+}
+
+// Create takes a backup now and prunes those past the retention
+func (s *BackupService) Create(ctx context.Context) (*model.Backup, error) {
+	if err := requireAdmin(ctx, s.store); err != nil {
+		return nil, err
+	}
+	if s.manager == nil {
+		return nil, errBackupsUnavailable
+	}
+
+	b, err := s.manager.Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Database backup created", "name", b.Name, "location", b.Location, "size", b.Size)
+	return b, nil
+}
+
+// List returns the stored backups, newest first
+func (s *BackupService) List(ctx context.Context) ([]model.Backup, error) {
+	if err := requireAdmin(ctx, s.store); err != nil {
+		return nil, err
+	}
+	if s.manager == nil {
+		return nil, errBackupsUnavailable
+	}
+	return s.manager.List(ctx)
+}
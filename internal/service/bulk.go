@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/storage"
@@ -50,6 +53,91 @@ func (s *BulkService) RemoveTags(ctx context.Context, deviceIDs []string, tags [
 	return s.store.BulkRemoveTags(enrichAuditCtx(ctx), deviceIDs, tags)
 }
 
+// ApplyDeviceOperations applies a list of operations to a set of devices in
+// one transaction. Update, tag and datacenter operations need devices:update;
+// delete needs devices:delete and cannot be combined with other operations.
+func (s *BulkService) ApplyDeviceOperations(ctx context.Context, req *model.BulkDeviceRequest) (*storage.BulkResult, error) {
+	action := "update"
+	if slices.ContainsFunc(req.Operations, func(op model.BulkOperation) bool { return op.Op == model.BulkOpDelete }) {
+		action = "delete"
+	}
+	if err := requirePermission(ctx, s.store, "devices", action); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateBulkDeviceRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var changedBy string
+	if caller := CallerFrom(ctx); caller != nil {
+		changedBy = caller.UserID
+	}
+
+	result, err := s.store.ApplyBulkDeviceOperations(enrichAuditCtx(ctx), req, changedBy)
+	if errors.Is(err, storage.ErrDeviceNotFound) {
+		return nil, ValidationErrors{{Field: "device_ids", Message: err.Error()}}
+	}
+	return result, err
+}
+
+func (s *BulkService) validateBulkDeviceRequest(ctx context.Context, req *model.BulkDeviceRequest) error {
+	// A device listed twice would be deleted twice
+	seen := make(map[string]bool, len(req.DeviceIDs))
+	req.DeviceIDs = slices.DeleteFunc(req.DeviceIDs, func(id string) bool {
+		dup := seen[id]
+		seen[id] = true
+		return dup
+	})
+
+	var errs ValidationErrors
+	if len(req.DeviceIDs) == 0 {
+		errs = append(errs, ValidationError{Field: "device_ids", Message: "At least one device ID is required"})
+	}
+	if slices.Contains(req.DeviceIDs, "") {
+		errs = append(errs, ValidationError{Field: "device_ids", Message: "Device IDs must not be empty"})
+	}
+	if len(req.Operations) == 0 {
+		errs = append(errs, ValidationError{Field: "operations", Message: "At least one operation is required"})
+	}
+
+	for i, op := range req.Operations {
+		field := fmt.Sprintf("operations[%d]", i)
+		switch op.Op {
+		case model.BulkOpUpdate:
+			if op.Fields == nil {
+				errs = append(errs, ValidationError{Field: field + ".fields", Message: "Fields are required for update"})
+			} else if op.Fields.Status != nil && !op.Fields.Status.IsValid() {
+				errs = append(errs, ValidationError{Field: field + ".fields.status", Message: "Invalid status. Must be one of: planned, ordered, active, maintenance, decommissioned"})
+			}
+		case model.BulkOpAddTags, model.BulkOpRemoveTags:
+			if len(op.Tags) == 0 || slices.Contains(op.Tags, "") {
+				errs = append(errs, ValidationError{Field: field + ".tags", Message: "At least one non-empty tag is required"})
+			}
+		case model.BulkOpMoveDatacenter:
+			if op.DatacenterID == "" {
+				errs = append(errs, ValidationError{Field: field + ".datacenter_id", Message: "Datacenter ID is required"})
+			} else if _, err := s.store.GetDatacenter(ctx, op.DatacenterID); err != nil {
+				if !errors.Is(err, storage.ErrDatacenterNotFound) {
+					return err
+				}
+				errs = append(errs, ValidationError{Field: field + ".datacenter_id", Message: "Datacenter not found"})
+			}
+		case model.BulkOpDelete:
+			if len(req.Operations) > 1 {
+				errs = append(errs, ValidationError{Field: field, Message: "Delete cannot be combined with other operations"})
+			}
+		default:
+			errs = append(errs, ValidationError{Field: field + ".op", Message: "Invalid operation. Must be one of: update, add_tags, remove_tags, move_datacenter, delete"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 func (s *BulkService) CreateNetworks(ctx context.Context, networks []*model.Network) (*storage.BulkResult, error) {
 	if err := requirePermission(ctx, s.store, "networks", "create"); err != nil {
 		return nil, err
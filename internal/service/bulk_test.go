@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -27,3 +28,42 @@ func TestBulkService_DelegatesOperationsAfterPermissionChecks(t *testing.T) {
 		t.Fatalf("expected bulk create networks to delegate, op=%q err=%v", store.lastBulkOp, err)
 	}
 }
+
+func TestBulkService_ApplyDeviceOperations(t *testing.T) {
+	store := newServiceTestStorage()
+	store.bulkResult = &storage.BulkResult{Total: 1, Success: 1}
+	store.setPermission("user-1", "devices", "update", true)
+	svc := NewBulkService(store)
+
+	req := &model.BulkDeviceRequest{
+		DeviceIDs:  []string{"dev-1", "dev-1"},
+		Operations: []model.BulkOperation{{Op: model.BulkOpAddTags, Tags: []string{"core"}}},
+	}
+	if _, err := svc.ApplyDeviceOperations(userContext("user-1"), req); err != nil || store.lastBulkOp != "apply-device-operations" {
+		t.Fatalf("expected bulk operations to delegate, op=%q err=%v", store.lastBulkOp, err)
+	}
+	if len(req.DeviceIDs) != 1 {
+		t.Errorf("expected duplicate device IDs to be dropped, got %v", req.DeviceIDs)
+	}
+
+	// Delete needs devices:delete
+	req = &model.BulkDeviceRequest{DeviceIDs: []string{"dev-1"}, Operations: []model.BulkOperation{{Op: model.BulkOpDelete}}}
+	if _, err := svc.ApplyDeviceOperations(userContext("user-1"), req); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected forbidden for delete, got %v", err)
+	}
+
+	invalid := model.DeviceStatus("broken")
+	req = &model.BulkDeviceRequest{
+		DeviceIDs: []string{"dev-1"},
+		Operations: []model.BulkOperation{
+			{Op: model.BulkOpUpdate, Fields: &model.BulkDeviceFields{Status: &invalid}},
+			{Op: model.BulkOpRemoveTags},
+			{Op: "rename"},
+		},
+	}
+	_, err := svc.ApplyDeviceOperations(userContext("user-1"), req)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 3 {
+		t.Fatalf("expected 3 validation errors, got %v", err)
+	}
+}
@@ -20,6 +20,7 @@ type Caller struct {
 	IPAddress string
 	Source    string
 	Scopes    []string // OAuth token scopes; if non-nil, limits effective permissions
+	ReadOnly  bool     // approval mode: only read, list and export are allowed
 }
 
 func (c *Caller) IsSystem() bool {
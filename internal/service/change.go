@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/martinsuchenak/rackd/internal/auth"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// ChangeService holds changes for review in approval mode. Changes made by
+// non-admin tokens are stored as change requests instead of being applied;
+// an admin approves or rejects them and approved ones are replayed as the
+// requester. Admins can see and review every change, other users only see
+// their own.
+type ChangeService struct {
+	store storage.ExtendedStorage
+}
+
+func NewChangeService(store storage.ExtendedStorage) *ChangeService {
+	return &ChangeService{store: store}
+}
+
+// RequiresApproval reports whether changes by the caller must go through a
+// change request: the caller is a user who is not an admin
+func (s *ChangeService) RequiresApproval(ctx context.Context) bool {
+	caller := CallerFrom(ctx)
+	if caller == nil || caller.IsSystem() || caller.UserID == "" {
+		return false
+	}
+	isAdmin, err := auth.IsAdmin(ctx, s.store, caller.UserID)
+	return err != nil || !isAdmin
+}
+
+// Submit stores a change request for the caller
+func (s *ChangeService) Submit(ctx context.Context, change *model.ChangeRequest) error {
+	caller := CallerFrom(ctx)
+	if caller == nil || caller.UserID == "" {
+		return ErrUnauthenticated
+	}
+	if !s.RequiresApproval(ctx) {
+		return ErrForbidden
+	}
+	change.ID = ""
+	change.RequestedBy = caller.UserID
+	change.RequestedByName = caller.Username
+	change.ReviewedBy, change.ReviewedByName, change.ReviewComment = "", "", ""
+	change.ResultStatus, change.Result, change.ReviewedAt = 0, nil, nil
+	return s.store.CreateChangeRequest(enrichAuditCtx(ctx), change)
+}
+
+// List returns change requests. Users who are not admins only get their own.
+func (s *ChangeService) List(ctx context.Context, filter *model.ChangeRequestFilter) ([]model.ChangeRequest, error) {
+	caller := CallerFrom(ctx)
+	if caller == nil || (caller.UserID == "" && !caller.IsSystem()) {
+		return nil, ErrUnauthenticated
+	}
+	if filter == nil {
+		filter = &model.ChangeRequestFilter{}
+	}
+	if !caller.IsSystem() {
+		if isAdmin, _ := auth.IsAdmin(ctx, s.store, caller.UserID); !isAdmin {
+			filter.RequestedBy = caller.UserID
+		}
+	}
+	return s.store.ListChangeRequests(ctx, filter)
+}
+
+// Get returns a change request. Another user's change is reported as not
+// found unless the caller is an admin.
+func (s *ChangeService) Get(ctx context.Context, id string) (*model.ChangeRequest, error) {
+	caller := CallerFrom(ctx)
+	if caller == nil || (caller.UserID == "" && !caller.IsSystem()) {
+		return nil, ErrUnauthenticated
+	}
+	change, err := s.store.GetChangeRequest(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrChangeRequestNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !caller.IsSystem() && change.RequestedBy != caller.UserID {
+		if isAdmin, _ := auth.IsAdmin(ctx, s.store, caller.UserID); !isAdmin {
+			return nil, ErrNotFound
+		}
+	}
+	return change, nil
+}
+
+// Approve marks a pending change as approved. The caller applies it and
+// records the outcome with RecordResult.
+func (s *ChangeService) Approve(ctx context.Context, id, comment string) (*model.ChangeRequest, error) {
+	return s.review(ctx, id, model.ChangeApproved, comment)
+}
+
+// Reject marks a pending change as rejected; it is never applied
+func (s *ChangeService) Reject(ctx context.Context, id, comment string) (*model.ChangeRequest, error) {
+	return s.review(ctx, id, model.ChangeRejected, comment)
+}
+
+func (s *ChangeService) review(ctx context.Context, id string, status model.ChangeStatus, comment string) (*model.ChangeRequest, error) {
+	if err := requireAdmin(ctx, s.store); err != nil {
+		return nil, err
+	}
+	change, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	caller := CallerFrom(ctx)
+	// Nobody reviews their own change
+	if change.RequestedBy == caller.UserID {
+		return nil, ErrForbidden
+	}
+
+	change.Status = status
+	change.ReviewedBy = caller.UserID
+	change.ReviewedByName = caller.Username
+	change.ReviewComment = comment
+	if err := s.store.ReviewChangeRequest(enrichAuditCtx(ctx), change); err != nil {
+		if errors.Is(err, storage.ErrChangeNotPending) {
+			return nil, ErrChangeNotPending
+		}
+		if errors.Is(err, storage.ErrChangeRequestNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return change, nil
+}
+
+// RecordResult stores the response to an approved change: applied for a 2xx
+// status, failed otherwise
+func (s *ChangeService) RecordResult(ctx context.Context, change *model.ChangeRequest, status int, body []byte) error {
+	change.Status = model.ChangeFailed
+	if status >= http.StatusOK && status < http.StatusMultipleChoices {
+		change.Status = model.ChangeApplied
+	}
+	change.ResultStatus = status
+	change.Result = body
+	return s.store.SetChangeRequestResult(ctx, change.ID, change.Status, status, body)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func newChangeTestService() (*serviceTestStorage, *ChangeService) {
+	store := newServiceTestStorage()
+	store.userRoles["admin-1"] = []model.Role{{ID: "admin", Name: "admin"}}
+	store.userRoles["admin-2"] = []model.Role{{ID: "admin", Name: "admin"}}
+	store.userRoles["user-1"] = []model.Role{{ID: "operator", Name: "operator"}}
+	return store, NewChangeService(store)
+}
+
+func TestChangeService_RequiresApproval(t *testing.T) {
+	_, svc := newChangeTestService()
+
+	if !svc.RequiresApproval(userContext("user-1")) {
+		t.Error("expected a non-admin user to need approval")
+	}
+	if svc.RequiresApproval(userContext("admin-1")) {
+		t.Error("expected an admin not to need approval")
+	}
+	if svc.RequiresApproval(SystemContext(context.Background(), "cli")) {
+		t.Error("expected the system caller not to need approval")
+	}
+
+}
+
+func TestServices_SetApprovalMode(t *testing.T) {
+	services := NewServices(newServiceTestStorage(), nil, nil)
+	if services.Changes != nil {
+		t.Fatal("expected no change service outside approval mode")
+	}
+	services.SetApprovalMode(true)
+	if services.Changes == nil || !services.Changes.RequiresApproval(userContext("user-1")) {
+		t.Error("expected approval mode to hold changes by non-admins")
+	}
+	services.SetApprovalMode(false)
+	if services.Changes != nil {
+		t.Error("expected turning approval mode off to drop the change service")
+	}
+}
+
+func TestChangeService_ReviewFlow(t *testing.T) {
+	store, svc := newChangeTestService()
+
+	change := &model.ChangeRequest{Method: "DELETE", Path: "/api/devices/dev-1"}
+	if err := svc.Submit(userContext("user-1"), change); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if change.RequestedBy != "user-1" || change.Status != model.ChangePending {
+		t.Fatalf("unexpected change after submit: %+v", change)
+	}
+	if err := svc.Submit(userContext("admin-1"), &model.ChangeRequest{Method: "DELETE", Path: "/api/devices/dev-2"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for a caller that needs no approval, got %v", err)
+	}
+
+	// Other users' changes are hidden from non-admins
+	store.changes["other"] = &model.ChangeRequest{ID: "other", Status: model.ChangePending, RequestedBy: "user-2"}
+	if _, err := svc.Get(userContext("user-1"), "other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another user's change, got %v", err)
+	}
+	if _, err := svc.List(userContext("user-1"), nil); err != nil || store.changeFilter.RequestedBy != "user-1" {
+		t.Errorf("expected list scoped to caller, got filter %+v (%v)", store.changeFilter, err)
+	}
+	if all, _ := svc.List(userContext("admin-1"), nil); len(all) != 2 {
+		t.Errorf("expected admin to see 2 changes, got %d", len(all))
+	}
+
+	if _, err := svc.Approve(userContext("user-1"), change.ID, ""); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for a non-admin approver, got %v", err)
+	}
+	approved, err := svc.Approve(userContext("admin-1"), change.ID, "ok")
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if approved.Status != model.ChangeApproved || approved.ReviewedBy != "admin-1" || approved.ReviewComment != "ok" {
+		t.Errorf("unexpected approved change: %+v", approved)
+	}
+	if _, err := svc.Reject(userContext("admin-2"), change.ID, ""); !errors.Is(err, ErrChangeNotPending) {
+		t.Errorf("expected ErrChangeNotPending for a reviewed change, got %v", err)
+	}
+
+	// Nobody reviews their own change
+	store.changes["own"] = &model.ChangeRequest{ID: "own", Status: model.ChangePending, RequestedBy: "admin-1"}
+	if _, err := svc.Reject(userContext("admin-1"), "own", ""); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden reviewing own change, got %v", err)
+	}
+}
+
+func TestRequirePermission_ReadOnlyCaller(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "read", true)
+	store.setPermission("user-1", "devices", "update", true)
+	ctx := WithCaller(context.Background(), &Caller{Type: CallerTypeUser, UserID: "user-1", ReadOnly: true})
+
+	if err := requirePermission(ctx, store, "devices", "read"); err != nil {
+		t.Errorf("expected read to be allowed, got %v", err)
+	}
+	if err := requirePermission(ctx, store, "devices", "update"); !errors.Is(err, ErrApprovalRequired) {
+		t.Errorf("expected ErrApprovalRequired for update, got %v", err)
+	}
+}
@@ -0,0 +1,544 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/cloud"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// CloudService syncs servers from cloud providers into the device inventory.
+// Synced devices carry a "cloud:<provider>:<id>" tag which is used to match
+// them on subsequent syncs, so renaming a device in rackd is safe.
+type CloudService struct {
+	store     storage.ExtendedStorage
+	devices   *DeviceService
+	mu        sync.RWMutex
+	providers []cloud.Provider
+	regionMap map[string]string
+}
+
+// NewCloudService creates a cloud sync service with no providers configured
+func NewCloudService(store storage.ExtendedStorage, devices *DeviceService) *CloudService {
+	return &CloudService{store: store, devices: devices, regionMap: map[string]string{}}
+}
+
+// SetProviders configures the providers to sync and the mapping from
+// provider regions to datacenter IDs or names
+func (s *CloudService) SetProviders(providers []cloud.Provider, regionMap map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.providers = providers
+	if regionMap == nil {
+		regionMap = map[string]string{}
+	}
+	s.regionMap = regionMap
+}
+
+// Providers lists the configured providers
+func (s *CloudService) Providers(ctx context.Context) ([]model.CloudProvider, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]model.CloudProvider, 0, len(s.providers))
+	for _, p := range s.providers {
+		result = append(result, model.CloudProvider{Name: p.Name()})
+	}
+	return result, nil
+}
+
+// Sync pulls servers from the named provider, or from every provider when
+// name is empty, and creates or updates the matching devices. Devices that
+// disappeared from the provider are reported as missing but never deleted.
+func (s *CloudService) Sync(ctx context.Context, name string) ([]model.CloudSyncResult, error) {
+	if err := requirePermission(ctx, s.store, "devices", "create"); err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	providers := s.providers
+	regionMap := s.regionMap
+	s.mu.RUnlock()
+
+	var selected []cloud.Provider
+	for _, p := range providers {
+		if name == "" || p.Name() == name {
+			selected = append(selected, p)
+		}
+	}
+	if name != "" && len(selected) == 0 {
+		return nil, ValidationErrors{{Field: "provider", Message: fmt.Sprintf("Cloud provider %q is not configured", name)}}
+	}
+
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+	if err != nil {
+		return nil, err
+	}
+	dcs, err := newCloudDatacenterResolver(ctx, s.store, regionMap)
+	if err != nil {
+		return nil, err
+	}
+
+	nets, err := newCloudNetworkResolver(ctx, s.store)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.CloudSyncResult, 0, len(selected))
+	for _, p := range selected {
+		results = append(results, s.syncProvider(ctx, p, devices, dcs, nets))
+	}
+	return results, nil
+}
+
+// Import syncs servers listed outside rackd, such as Kubernetes nodes read
+// with a local kubeconfig, as if the named provider had reported them. The
+// provider does not need to be configured on the server.
+func (s *CloudService) Import(ctx context.Context, provider string, servers []cloud.Server) (*model.CloudSyncResult, error) {
+	if err := requirePermission(ctx, s.store, "devices", "create"); err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return nil, err
+	}
+	if err := cloud.ValidProviderName(provider); err != nil {
+		return nil, ValidationErrors{{Field: "provider", Message: err.Error()}}
+	}
+	fields := newCloudCustomFieldResolver(s.store)
+	for i, server := range servers {
+		if server.ID == "" || server.Name == "" {
+			return nil, ValidationErrors{{Field: fmt.Sprintf("servers[%d]", i), Message: "Server ID and name are required"}}
+		}
+		if _, err := fields.resolve(ctx, server.CustomFields); err != nil {
+			return nil, ValidationErrors{{Field: fmt.Sprintf("servers[%d].custom_fields", i), Message: err.Error()}}
+		}
+	}
+
+	s.mu.RLock()
+	regionMap := s.regionMap
+	s.mu.RUnlock()
+
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+	if err != nil {
+		return nil, err
+	}
+	dcs, err := newCloudDatacenterResolver(ctx, s.store, regionMap)
+	if err != nil {
+		return nil, err
+	}
+	nets, err := newCloudNetworkResolver(ctx, s.store)
+	if err != nil {
+		return nil, err
+	}
+	result := s.syncServers(ctx, provider, servers, devices, dcs, nets, fields)
+	return &result, nil
+}
+
+func (s *CloudService) syncProvider(ctx context.Context, p cloud.Provider, devices []model.Device, dcs *cloudDatacenterResolver, nets *cloudNetworkResolver) model.CloudSyncResult {
+	servers, err := p.ListServers(ctx)
+	if err != nil {
+		log.Warn("Cloud sync failed to list servers", "provider", p.Name(), "error", err)
+		result := newCloudSyncResult(p.Name())
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+	return s.syncServers(ctx, p.Name(), servers, devices, dcs, nets, newCloudCustomFieldResolver(s.store))
+}
+
+func newCloudSyncResult(provider string) model.CloudSyncResult {
+	return model.CloudSyncResult{
+		Provider: provider,
+		Created:  []string{},
+		Updated:  []string{},
+		Missing:  []string{},
+		Errors:   []string{},
+		SyncedAt: time.Now().UTC(),
+	}
+}
+
+// syncServers creates or updates a device for each server and reports the
+// provider's devices that are no longer listed
+func (s *CloudService) syncServers(ctx context.Context, provider string, servers []cloud.Server, devices []model.Device,
+	dcs *cloudDatacenterResolver, nets *cloudNetworkResolver, fields *cloudCustomFieldResolver) model.CloudSyncResult {
+	result := newCloudSyncResult(provider)
+
+	prefix := cloudTagPrefix(provider)
+	existing := make(map[string]*model.Device)
+	for i := range devices {
+		for _, tag := range devices[i].Tags {
+			if strings.HasPrefix(tag, prefix) {
+				existing[tag] = &devices[i]
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(servers))
+	for _, server := range servers {
+		idTag := prefix + server.ID
+		seen[idTag] = true
+
+		dcID, err := dcs.resolve(ctx, provider, server.Region)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", server.Name, err))
+			continue
+		}
+		network, err := nets.resolve(ctx, provider, dcID, server)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", server.Name, err))
+			continue
+		}
+		customFields, err := fields.resolve(ctx, server.CustomFields)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", server.Name, err))
+			continue
+		}
+
+		device, ok := existing[idTag]
+		if ok {
+			// Listed devices lack custom fields, which the update would clear
+			if device, err = s.store.GetDevice(ctx, device.ID); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", server.Name, err))
+				continue
+			}
+		} else {
+			device = &model.Device{Name: server.Name, Status: model.DeviceStatusActive}
+		}
+		updated := *device
+		applyCloudServer(&updated, provider, idTag, dcID, server)
+		applyCloudNetwork(&updated, network)
+		updated.CustomFields = mergeCustomFields(updated.CustomFields, customFields)
+
+		if !ok {
+			if err := s.devices.Create(ctx, &updated); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", server.Name, err))
+				continue
+			}
+			result.Created = append(result.Created, updated.Name)
+			continue
+		}
+		if cloudDeviceEqual(device, &updated) {
+			result.Unchanged++
+			continue
+		}
+		if err := s.devices.Update(ctx, &updated); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", server.Name, err))
+			continue
+		}
+		result.Updated = append(result.Updated, updated.Name)
+	}
+
+	for tag, device := range existing {
+		if !seen[tag] {
+			result.Missing = append(result.Missing, device.Name)
+		}
+	}
+	slices.Sort(result.Missing)
+
+	log.Info("Cloud sync completed", "provider", provider, "created", len(result.Created),
+		"updated", len(result.Updated), "unchanged", result.Unchanged, "missing", len(result.Missing), "errors", len(result.Errors))
+	return result
+}
+
+func cloudTagPrefix(provider string) string {
+	return "cloud:" + provider + ":"
+}
+
+// applyCloudServer copies provider-owned fields onto device. Tags are merged
+// so user-added tags survive, and only addresses labelled "public" or
+// "private" are managed by the sync.
+func applyCloudServer(device *model.Device, provider, idTag, datacenterID string, server cloud.Server) {
+	if device.Name == "" {
+		device.Name = server.Name
+	}
+	if server.Type != "" {
+		device.MakeModel = server.Type
+	}
+	if server.Image != "" {
+		device.OS = server.Image
+	}
+	if datacenterID != "" {
+		device.DatacenterID = datacenterID
+	}
+
+	tags := append([]string{}, device.Tags...)
+	for _, tag := range append([]string{"cloud", "cloud:" + provider, idTag}, server.Tags...) {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	device.Tags = tags
+
+	want := make(map[string]string)
+	for _, ip := range server.PrivateIPs {
+		want[ip] = "private"
+	}
+	for _, ip := range server.PublicIPs {
+		want[ip] = "public"
+	}
+
+	var addrs []model.Address
+	have := make(map[string]bool)
+	for _, addr := range device.Addresses {
+		label, managed := want[addr.IP]
+		if !managed && (addr.Label == "public" || addr.Label == "private") {
+			continue
+		}
+		if managed {
+			addr.Label = label
+		}
+		have[addr.IP] = true
+		addrs = append(addrs, addr)
+	}
+	for _, ips := range [][]string{server.PrivateIPs, server.PublicIPs} {
+		for _, ip := range ips {
+			if have[ip] {
+				continue
+			}
+			have[ip] = true
+			addrs = append(addrs, model.Address{IP: ip, Type: cloudAddressType(ip), Label: want[ip]})
+		}
+	}
+	device.Addresses = addrs
+}
+
+func cloudAddressType(ip string) string {
+	if family := ipFamily(ip); family != "" {
+		return family
+	}
+	return model.AddressTypeIPv4
+}
+
+// applyCloudNetwork links the private addresses inside the server's subnet
+// to its rackd network
+func applyCloudNetwork(device *model.Device, network *model.Network) {
+	if network == nil {
+		return
+	}
+	_, cidr, err := net.ParseCIDR(network.Subnet)
+	if err != nil {
+		return
+	}
+	for i, addr := range device.Addresses {
+		if ip := net.ParseIP(addr.IP); addr.Label == "private" && ip != nil && cidr.Contains(ip) {
+			device.Addresses[i].NetworkID = network.ID
+		}
+	}
+}
+
+func cloudDeviceEqual(a, b *model.Device) bool {
+	if a.Name != b.Name || a.MakeModel != b.MakeModel || a.OS != b.OS || a.DatacenterID != b.DatacenterID {
+		return false
+	}
+	if !slices.Equal(a.Tags, b.Tags) || len(a.Addresses) != len(b.Addresses) || !customFieldsEqual(a.CustomFields, b.CustomFields) {
+		return false
+	}
+	for i := range a.Addresses {
+		if a.Addresses[i].IP != b.Addresses[i].IP || a.Addresses[i].Label != b.Addresses[i].Label ||
+			a.Addresses[i].NetworkID != b.Addresses[i].NetworkID {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeCustomFields sets the given values, keeping the device's other
+// custom fields
+func mergeCustomFields(current, values []model.CustomFieldValueInput) []model.CustomFieldValueInput {
+	if len(values) == 0 {
+		return current
+	}
+	merged := append([]model.CustomFieldValueInput{}, current...)
+	for _, v := range values {
+		i := slices.IndexFunc(merged, func(cf model.CustomFieldValueInput) bool { return cf.FieldID == v.FieldID })
+		if i >= 0 {
+			merged[i] = v
+		} else {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+func customFieldsEqual(a, b []model.CustomFieldValueInput) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].FieldID != b[i].FieldID || fmt.Sprint(a[i].Value) != fmt.Sprint(b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// cloudDatacenterResolver maps provider regions to datacenter IDs, creating a
+// datacenter named after the region when neither the region map nor an
+// existing datacenter name matches
+type cloudDatacenterResolver struct {
+	store     storage.ExtendedStorage
+	regionMap map[string]string
+	byID      map[string]string
+	byName    map[string]string
+}
+
+func newCloudDatacenterResolver(ctx context.Context, store storage.ExtendedStorage, regionMap map[string]string) (*cloudDatacenterResolver, error) {
+	dcs, err := store.ListDatacenters(ctx, &model.DatacenterFilter{Pagination: model.Pagination{Limit: model.MaxPageSize}})
+	if err != nil {
+		return nil, err
+	}
+	r := &cloudDatacenterResolver{
+		store:     store,
+		regionMap: regionMap,
+		byID:      make(map[string]string, len(dcs)),
+		byName:    make(map[string]string, len(dcs)),
+	}
+	for _, dc := range dcs {
+		r.byID[dc.ID] = dc.ID
+		r.byName[strings.ToLower(dc.Name)] = dc.ID
+	}
+	return r, nil
+}
+
+func (r *cloudDatacenterResolver) resolve(ctx context.Context, provider, region string) (string, error) {
+	if region == "" {
+		return "", nil
+	}
+
+	target := region
+	if mapped, ok := r.regionMap[region]; ok {
+		if id, ok := r.byID[mapped]; ok {
+			return id, nil
+		}
+		target = mapped
+	}
+	if id, ok := r.byName[strings.ToLower(target)]; ok {
+		return id, nil
+	}
+
+	dc := &model.Datacenter{
+		Name:        target,
+		Location:    region,
+		Description: fmt.Sprintf("Created by %s cloud sync", provider),
+	}
+	if err := r.store.CreateDatacenter(enrichAuditCtx(ctx), dc); err != nil {
+		return "", fmt.Errorf("failed to create datacenter %q: %w", target, err)
+	}
+	r.byID[dc.ID] = dc.ID
+	r.byName[strings.ToLower(dc.Name)] = dc.ID
+	return dc.ID, nil
+}
+
+// cloudNetworkResolver maps provider networks and subnets to rackd networks
+// by CIDR within the server's datacenter, creating them when missing. A
+// subnet's network is nested under its VPC's.
+type cloudNetworkResolver struct {
+	store  storage.ExtendedStorage
+	byCIDR map[string]*model.Network
+}
+
+func newCloudNetworkResolver(ctx context.Context, store storage.ExtendedStorage) (*cloudNetworkResolver, error) {
+	networks, err := listAllNetworks(ctx, store, model.NetworkFilter{})
+	if err != nil {
+		return nil, err
+	}
+	r := &cloudNetworkResolver{store: store, byCIDR: make(map[string]*model.Network, len(networks))}
+	for i := range networks {
+		r.byCIDR[networks[i].DatacenterID+"|"+networks[i].Subnet] = &networks[i]
+	}
+	return r, nil
+}
+
+// resolve returns the network for the server's subnet, or its VPC when the
+// subnet is unknown. It returns nil when the provider reported neither.
+func (r *cloudNetworkResolver) resolve(ctx context.Context, provider, datacenterID string, server cloud.Server) (*model.Network, error) {
+	var network *model.Network
+	for _, n := range []*cloud.Network{server.Network, server.Subnet} {
+		if n == nil || n.CIDR == "" {
+			continue
+		}
+		parentID := ""
+		if network != nil {
+			parentID = network.ID
+		}
+		var err error
+		if network, err = r.ensure(ctx, provider, datacenterID, parentID, n); err != nil {
+			return nil, err
+		}
+	}
+	return network, nil
+}
+
+func (r *cloudNetworkResolver) ensure(ctx context.Context, provider, datacenterID, parentID string, n *cloud.Network) (*model.Network, error) {
+	key := datacenterID + "|" + n.CIDR
+	if network, ok := r.byCIDR[key]; ok {
+		return network, nil
+	}
+
+	name := n.Name
+	if name == "" {
+		name = n.ID
+	}
+	network := &model.Network{
+		Name:         name,
+		Subnet:       n.CIDR,
+		DatacenterID: datacenterID,
+		ParentID:     parentID,
+		Description:  fmt.Sprintf("Created by %s cloud sync (%s)", provider, n.ID),
+	}
+	if err := r.store.CreateNetwork(enrichAuditCtx(ctx), network); err != nil {
+		return nil, fmt.Errorf("failed to create network %q: %w", name, err)
+	}
+	r.byCIDR[key] = network
+	return network, nil
+}
+
+// cloudCustomFieldResolver turns custom field values keyed by field key into
+// device custom field values. Only text fields can be set.
+type cloudCustomFieldResolver struct {
+	store storage.ExtendedStorage
+	ids   map[string]string
+}
+
+func newCloudCustomFieldResolver(store storage.ExtendedStorage) *cloudCustomFieldResolver {
+	return &cloudCustomFieldResolver{store: store, ids: make(map[string]string)}
+}
+
+func (r *cloudCustomFieldResolver) resolve(ctx context.Context, values map[string]string) ([]model.CustomFieldValueInput, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var result []model.CustomFieldValueInput
+	for _, key := range keys {
+		id, ok := r.ids[key]
+		if !ok {
+			def, err := r.store.GetCustomFieldDefinitionByKey(ctx, key)
+			if err != nil {
+				return nil, fmt.Errorf("custom field %q not found", key)
+			}
+			if def.Type != model.CustomFieldTypeText {
+				return nil, fmt.Errorf("custom field %q must be a text field", key)
+			}
+			id = def.ID
+			r.ids[key] = id
+		}
+		result = append(result, model.CustomFieldValueInput{FieldID: id, Value: values[key]})
+	}
+	return result, nil
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/cloud"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+type fakeCloudProvider struct {
+	name    string
+	servers []cloud.Server
+	err     error
+}
+
+func (p *fakeCloudProvider) Name() string { return p.name }
+
+func (p *fakeCloudProvider) ListServers(_ context.Context) ([]cloud.Server, error) {
+	return p.servers, p.err
+}
+
+// cloudTestStorage assigns IDs on create and lists devices, which the shared
+// service test storage does not
+type cloudTestStorage struct {
+	*serviceTestStorage
+	nextID int
+}
+
+func newCloudTestStorage() *cloudTestStorage {
+	store := &cloudTestStorage{serviceTestStorage: newServiceTestStorage()}
+	store.setPermission("user-1", "devices", "create", true)
+	store.setPermission("user-1", "devices", "update", true)
+	store.setPermission("user-1", "devices", "list", true)
+	return store
+}
+
+func (s *cloudTestStorage) CreateDevice(ctx context.Context, device *model.Device) error {
+	s.nextID++
+	device.ID = fmt.Sprintf("dev-%d", s.nextID)
+	return s.serviceTestStorage.CreateDevice(ctx, device)
+}
+
+func (s *cloudTestStorage) CreateDatacenter(ctx context.Context, dc *model.Datacenter) error {
+	s.nextID++
+	dc.ID = fmt.Sprintf("dc-%d", s.nextID)
+	return s.serviceTestStorage.CreateDatacenter(ctx, dc)
+}
+
+func (s *cloudTestStorage) ListDevices(_ context.Context, filter *model.DeviceFilter) ([]model.Device, error) {
+	if filter != nil && filter.Offset > 0 {
+		return nil, nil
+	}
+	var devices []model.Device
+	for _, d := range s.devices {
+		devices = append(devices, *d)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+	return devices, nil
+}
+
+func (s *cloudTestStorage) CreateNetwork(ctx context.Context, network *model.Network) error {
+	s.nextID++
+	network.ID = fmt.Sprintf("net-%d", s.nextID)
+	return s.serviceTestStorage.CreateNetwork(ctx, network)
+}
+
+func (s *cloudTestStorage) GetCustomFieldDefinitionByKey(_ context.Context, key string) (*model.CustomFieldDefinition, error) {
+	for _, def := range s.customDefs {
+		if def.Key == key {
+			return def, nil
+		}
+	}
+	return nil, storage.ErrCustomFieldNotFound
+}
+
+func (s *cloudTestStorage) GetDevice(_ context.Context, id string) (*model.Device, error) {
+	d, ok := s.devices[id]
+	if !ok {
+		return nil, storage.ErrDeviceNotFound
+	}
+	cloned := *d
+	return &cloned, nil
+}
+
+func (s *cloudTestStorage) deviceByName(name string) *model.Device {
+	for _, d := range s.devices {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+func TestCloudService_SyncCreatesAndUpdatesDevices(t *testing.T) {
+	store := newCloudTestStorage()
+	store.datacenters = []model.Datacenter{{ID: "dc-fra", Name: "Frankfurt"}}
+
+	provider := &fakeCloudProvider{name: "aws", servers: []cloud.Server{
+		{ID: "i-1", Name: "api-1", Region: "eu-central-1", Type: "t3.micro", Image: "ami-1",
+			PrivateIPs: []string{"172.31.0.10"}, PublicIPs: []string{"3.3.3.3", "2600:1f18::10"}, Tags: []string{"team=platform"}},
+		{ID: "i-2", Name: "batch-1", Region: "us-east-1", Type: "m5.large"},
+	}}
+	svc := NewCloudService(store, NewDeviceService(store))
+	svc.SetProviders([]cloud.Provider{provider}, map[string]string{"eu-central-1": "Frankfurt"})
+
+	results, err := svc.Sync(userContext("user-1"), "")
+	if err != nil {
+		t.Fatalf("Sync returned unexpected error: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Created) != 2 || len(results[0].Errors) != 0 {
+		t.Fatalf("expected two created devices, got %#v", results)
+	}
+
+	api := store.deviceByName("api-1")
+	if api == nil || api.DatacenterID != "dc-fra" || api.MakeModel != "t3.micro" || api.OS != "ami-1" || api.Status != model.DeviceStatusActive {
+		t.Fatalf("unexpected api-1 device: %#v", api)
+	}
+	for _, tag := range []string{"cloud", "cloud:aws", "cloud:aws:i-1", "team=platform"} {
+		if !slices.Contains(api.Tags, tag) {
+			t.Errorf("expected tag %q, got %v", tag, api.Tags)
+		}
+	}
+	if len(api.Addresses) != 3 || api.Addresses[0].Label != "private" || api.Addresses[2].Type != "ipv6" {
+		t.Errorf("unexpected addresses: %#v", api.Addresses)
+	}
+
+	// Unmapped regions get a datacenter named after the region
+	batch := store.deviceByName("batch-1")
+	dc, _ := store.GetDatacenter(context.Background(), batch.DatacenterID)
+	if dc == nil || dc.Name != "us-east-1" {
+		t.Fatalf("expected datacenter created for us-east-1, got %#v", dc)
+	}
+
+	// A user rename and user tag survive the next sync, and only changed devices update
+	api.Name = "api-1-renamed"
+	api.Tags = append(api.Tags, "owned-by-me")
+	provider.servers[0].PublicIPs = []string{"4.4.4.4"}
+
+	results, err = svc.Sync(userContext("user-1"), "aws")
+	if err != nil {
+		t.Fatalf("second Sync returned unexpected error: %v", err)
+	}
+	if len(results[0].Created) != 0 || !slices.Equal(results[0].Updated, []string{"api-1-renamed"}) || results[0].Unchanged != 1 {
+		t.Fatalf("expected one update and one unchanged, got %#v", results[0])
+	}
+	api = store.deviceByName("api-1-renamed")
+	if !slices.Contains(api.Tags, "owned-by-me") {
+		t.Errorf("expected user tag to be kept, got %v", api.Tags)
+	}
+	var ips []string
+	for _, a := range api.Addresses {
+		ips = append(ips, a.IP)
+	}
+	if !slices.Equal(ips, []string{"172.31.0.10", "4.4.4.4"}) {
+		t.Errorf("expected stale public addresses to be replaced, got %v", ips)
+	}
+}
+
+func TestCloudService_SyncReportsMissingAndErrors(t *testing.T) {
+	store := newCloudTestStorage()
+	store.devices["dev-old"] = &model.Device{ID: "dev-old", Name: "gone", Tags: []string{"cloud:hetzner:99"}}
+
+	ok := &fakeCloudProvider{name: "hetzner"}
+	broken := &fakeCloudProvider{name: "aws", err: errors.New("AuthFailure")}
+	svc := NewCloudService(store, NewDeviceService(store))
+	svc.SetProviders([]cloud.Provider{ok, broken}, nil)
+
+	results, err := svc.Sync(userContext("user-1"), "")
+	if err != nil {
+		t.Fatalf("Sync returned unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected a result per provider, got %d", len(results))
+	}
+	if !slices.Equal(results[0].Missing, []string{"gone"}) {
+		t.Errorf("expected missing device to be reported, got %v", results[0].Missing)
+	}
+	if store.devices["dev-old"] == nil {
+		t.Error("expected missing device not to be deleted")
+	}
+	if len(results[1].Errors) != 1 || len(results[1].Missing) != 0 {
+		t.Errorf("expected provider error without missing devices, got %#v", results[1])
+	}
+}
+
+func TestCloudService_SyncValidation(t *testing.T) {
+	store := newCloudTestStorage()
+	svc := NewCloudService(store, NewDeviceService(store))
+
+	var verrs ValidationErrors
+	if _, err := svc.Sync(userContext("user-1"), "nope"); !errors.As(err, &verrs) {
+		t.Fatalf("expected validation error for unknown provider, got %v", err)
+	}
+	if _, err := svc.Sync(userContext("user-2"), ""); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestCloudService_Import(t *testing.T) {
+	store := newCloudTestStorage()
+	store.devices["dev-old"] = &model.Device{ID: "dev-old", Name: "node-0", Tags: []string{"cloud:k8s-prod:u-0"}}
+	svc := NewCloudService(store, NewDeviceService(store))
+
+	nodes := []cloud.Server{{ID: "u-1", Name: "node-1", Image: "Ubuntu 24.04 LTS (kernel 6.8.0-1)",
+		PrivateIPs: []string{"10.0.0.5"}, Tags: []string{"k8s-cluster=prod"}}}
+	result, err := svc.Import(userContext("user-1"), "k8s-prod", nodes)
+	if err != nil {
+		t.Fatalf("Import returned unexpected error: %v", err)
+	}
+	if !slices.Equal(result.Created, []string{"node-1"}) || !slices.Equal(result.Missing, []string{"node-0"}) {
+		t.Fatalf("unexpected import result: %#v", result)
+	}
+	node := store.deviceByName("node-1")
+	if node == nil || node.OS != "Ubuntu 24.04 LTS (kernel 6.8.0-1)" || !slices.Contains(node.Tags, "cloud:k8s-prod:u-1") {
+		t.Fatalf("unexpected node device: %#v", node)
+	}
+
+	// Importing again matches the device by its tag
+	if result, err = svc.Import(userContext("user-1"), "k8s-prod", nodes); err != nil || result.Unchanged != 1 {
+		t.Fatalf("expected unchanged device on re-import, got %#v (%v)", result, err)
+	}
+
+	var verrs ValidationErrors
+	if _, err := svc.Import(userContext("user-1"), "bad:name", nodes); !errors.As(err, &verrs) {
+		t.Errorf("expected validation error for provider name, got %v", err)
+	}
+	if _, err := svc.Import(userContext("user-1"), "k8s-prod", []cloud.Server{{Name: "no-id"}}); !errors.As(err, &verrs) {
+		t.Errorf("expected validation error for server without ID, got %v", err)
+	}
+	if _, err := svc.Import(userContext("user-2"), "k8s-prod", nodes); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestCloudService_ImportNetworksAndCustomFields(t *testing.T) {
+	store := newCloudTestStorage()
+	store.datacenters = []model.Datacenter{{ID: "dc-fra", Name: "eu-central-1"}}
+	store.networks = []model.Network{{ID: "net-vpc", Name: "prod", Subnet: "172.31.0.0/16", DatacenterID: "dc-fra"}}
+	store.customDefs["cf-id"] = &model.CustomFieldDefinition{ID: "cf-id", Key: "instance_id", Type: model.CustomFieldTypeText}
+	store.customDefs["cf-cost"] = &model.CustomFieldDefinition{ID: "cf-cost", Key: "cost_center", Type: model.CustomFieldTypeNumber}
+	svc := NewCloudService(store, NewDeviceService(store))
+	ctx := userContext("user-1")
+
+	instances := []cloud.Server{{ID: "i-0abc", Name: "i-0abc", Region: "eu-central-1",
+		PrivateIPs: []string{"172.31.0.10"}, PublicIPs: []string{"3.3.3.3"},
+		Network:      &cloud.Network{ID: "vpc-1", Name: "prod", CIDR: "172.31.0.0/16"},
+		Subnet:       &cloud.Network{ID: "subnet-1", CIDR: "172.31.0.0/20"},
+		CustomFields: map[string]string{"instance_id": "i-0abc"}}}
+	if _, err := svc.Import(ctx, "aws", instances); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	// The existing VPC network is reused and the subnet is created under it
+	if len(store.networks) != 2 {
+		t.Fatalf("expected the subnet to be created, got %+v", store.networks)
+	}
+	subnet := store.networks[1]
+	if subnet.Name != "subnet-1" || subnet.Subnet != "172.31.0.0/20" || subnet.ParentID != "net-vpc" || subnet.DatacenterID != "dc-fra" {
+		t.Errorf("unexpected subnet network: %+v", subnet)
+	}
+	device := store.deviceByName("i-0abc")
+	if device == nil {
+		t.Fatal("expected device to be created")
+	}
+	for _, addr := range device.Addresses {
+		if want := map[string]string{"172.31.0.10": subnet.ID}[addr.IP]; addr.NetworkID != want {
+			t.Errorf("address %s: expected network %q, got %q", addr.IP, want, addr.NetworkID)
+		}
+	}
+	if len(device.CustomFields) != 1 || device.CustomFields[0].FieldID != "cf-id" || device.CustomFields[0].Value != "i-0abc" {
+		t.Errorf("unexpected custom fields: %+v", device.CustomFields)
+	}
+
+	// Custom fields set in rackd survive a re-import
+	device.CustomFields = append(device.CustomFields, model.CustomFieldValueInput{FieldID: "cf-cost", Value: 42})
+	result, err := svc.Import(ctx, "aws", instances)
+	if err != nil || result.Unchanged != 1 {
+		t.Fatalf("expected unchanged device on re-import, got %#v (%v)", result, err)
+	}
+	if len(device.CustomFields) != 2 {
+		t.Errorf("expected custom fields to be kept, got %+v", device.CustomFields)
+	}
+
+	var verrs ValidationErrors
+	for _, key := range []string{"missing", "cost_center"} {
+		instances[0].CustomFields = map[string]string{key: "i-0abc"}
+		if _, err := svc.Import(ctx, "aws", instances); !errors.As(err, &verrs) {
+			t.Errorf("expected validation error for custom field %q, got %v", key, err)
+		}
+	}
+}
@@ -2,6 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/storage"
@@ -43,3 +48,144 @@ func (s *DashboardService) GetUtilizationTrend(ctx context.Context, resourceType
 
 	return s.store.GetUtilizationTrend(ctx, resourceType, resourceID, days)
 }
+
+// GetDatacenterStats retrieves the dashboard statistics of a datacenter,
+// returning ErrNotFound if the datacenter doesn't exist
+func (s *DashboardService) GetDatacenterStats(ctx context.Context, datacenterID string, recentLimit int) (*model.DatacenterStats, error) {
+	if err := requirePermission(ctx, s.store, "dashboard", "read"); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.GetDatacenter(ctx, datacenterID); err != nil {
+		if errors.Is(err, storage.ErrDatacenterNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if recentLimit <= 0 {
+		recentLimit = 10 // Default to 10 recent changes
+	}
+
+	return s.store.GetDatacenterStats(ctx, datacenterID, recentLimit)
+}
+
+// ListSnapshots returns every utilization snapshot of a type taken between
+// from and to, oldest first
+func (s *DashboardService) ListSnapshots(ctx context.Context, snapshotType model.SnapshotType, from, to time.Time) ([]model.UtilizationSnapshot, error) {
+	if err := requirePermission(ctx, s.store, "dashboard", "read"); err != nil {
+		return nil, err
+	}
+
+	var snapshots []model.UtilizationSnapshot
+	filter := &model.SnapshotFilter{Type: snapshotType, After: &from, Before: &to}
+	for {
+		filter.Pagination = model.Pagination{Limit: model.MaxPageSize, Offset: len(snapshots)}
+		page, err := s.store.ListSnapshots(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, page...)
+		if len(page) < model.MaxPageSize {
+			break
+		}
+	}
+	slices.Reverse(snapshots)
+	return snapshots, nil
+}
+
+// MetricsHistory returns the daily history of inventory metrics from the
+// last days, one series per metric and pool. Growing utilization series get
+// a forecast of when they reach 100%.
+func (s *DashboardService) MetricsHistory(ctx context.Context, names []string, resourceID string, days int) ([]model.MetricSeries, error) {
+	if err := requirePermission(ctx, s.store, "dashboard", "read"); err != nil {
+		return nil, err
+	}
+
+	if days <= 0 {
+		days = 90 // Default to 90 days
+	}
+	filter := &model.InventoryMetricFilter{
+		Names:      names,
+		ResourceID: resourceID,
+		Since:      time.Now().UTC().AddDate(0, 0, -days+1).Format(time.DateOnly),
+	}
+	metrics, err := s.store.ListInventoryMetrics(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Metrics come ordered by name, resource and day
+	series := []model.MetricSeries{}
+	for _, m := range metrics {
+		last := len(series) - 1
+		if last < 0 || series[last].Name != m.Name || series[last].ResourceID != m.ResourceID {
+			series = append(series, model.MetricSeries{Name: m.Name, ResourceID: m.ResourceID, Points: []model.MetricPoint{}})
+			last++
+		}
+		// The latest name of a pool wins
+		series[last].ResourceName = m.ResourceName
+		series[last].Points = append(series[last].Points, model.MetricPoint{Day: m.Day, Value: m.Value})
+	}
+	for i := range series {
+		if strings.HasSuffix(series[i].Name, ".utilization") {
+			series[i].Forecast = forecastExhaustion(series[i].Points)
+		}
+	}
+	return series, nil
+}
+
+// maxForecastDays is how far ahead exhaustion is forecast; slower growth
+// gets no forecast
+const maxForecastDays = 10 * 365
+
+// forecastExhaustion fits a least squares line to the daily utilization
+// points and extrapolates from the latest at its slope to the day it reaches
+// 100%. It returns nil with fewer than two days of history or when
+// utilization isn't growing.
+func forecastExhaustion(points []model.MetricPoint) *model.ExhaustionForecast {
+	if len(points) < 2 {
+		return nil
+	}
+	first, err := time.Parse(time.DateOnly, points[0].Day)
+	if err != nil {
+		return nil
+	}
+
+	var n, sumX, sumY, sumXY, sumXX float64
+	var lastDay time.Time
+	for _, p := range points {
+		day, err := time.Parse(time.DateOnly, p.Day)
+		if err != nil {
+			return nil
+		}
+		x := day.Sub(first).Hours() / 24
+		n++
+		sumX += x
+		sumY += p.Value
+		sumXY += x * p.Value
+		sumXX += x * x
+		lastDay = day
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return nil
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	if slope <= 0 {
+		return nil
+	}
+
+	daysLeft := 0
+	if latest := points[len(points)-1].Value; latest < 100 {
+		daysLeft = int(math.Ceil((100 - latest) / slope))
+	}
+	if daysLeft > maxForecastDays {
+		return nil
+	}
+	return &model.ExhaustionForecast{
+		GrowthPerDay: math.Round(slope*1000) / 1000,
+		DaysLeft:     daysLeft,
+		ExhaustedOn:  lastDay.AddDate(0, 0, daysLeft).Format(time.DateOnly),
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"testing"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -25,3 +26,60 @@ func TestDashboardService_DefaultsStatsAndTrendParameters(t *testing.T) {
 		t.Fatalf("expected default trend days 30, got %d", store.utilTrendDays)
 	}
 }
+
+func TestDashboardService_MetricsHistory(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "dashboard", "read", true)
+	svc := NewDashboardService(store)
+
+	store.inventoryMetrics = []model.InventoryMetric{
+		{Day: "2026-10-01", Name: model.MetricDevicesTotal, Value: 10},
+		{Day: "2026-10-02", Name: model.MetricDevicesTotal, Value: 12},
+		{Day: "2026-10-01", Name: model.MetricPoolsUtilization, ResourceID: "pool-1", ResourceName: "old name", Value: 50},
+		{Day: "2026-10-03", Name: model.MetricPoolsUtilization, ResourceID: "pool-1", ResourceName: "servers", Value: 54},
+		{Day: "2026-10-01", Name: model.MetricPoolsUtilization, ResourceID: "pool-2", Value: 20},
+		{Day: "2026-10-02", Name: model.MetricPoolsUtilization, ResourceID: "pool-2", Value: 19},
+	}
+	series, err := svc.MetricsHistory(userContext("user-1"), []string{model.MetricDevicesTotal}, "", 0)
+	if err != nil {
+		t.Fatalf("MetricsHistory returned unexpected error: %v", err)
+	}
+	if store.metricFilter.Since != time.Now().UTC().AddDate(0, 0, -89).Format(time.DateOnly) || len(store.metricFilter.Names) != 1 {
+		t.Errorf("expected 90 days of the named metric by default, got %+v", store.metricFilter)
+	}
+	if len(series) != 3 || len(series[0].Points) != 2 || series[0].Forecast != nil {
+		t.Fatalf("expected a series per metric and pool, got %+v", series)
+	}
+
+	// 50% to 54% over two days is 2 points a day, so 23 days to 100%
+	pool := series[1]
+	if pool.ResourceName != "servers" || pool.Forecast == nil {
+		t.Fatalf("expected the latest pool name and a forecast, got %+v", pool)
+	}
+	want := model.ExhaustionForecast{GrowthPerDay: 2, DaysLeft: 23, ExhaustedOn: "2026-10-26"}
+	if *pool.Forecast != want {
+		t.Errorf("expected forecast %+v, got %+v", want, *pool.Forecast)
+	}
+	// Shrinking utilization never runs out
+	if series[2].Forecast != nil {
+		t.Errorf("expected no forecast for a shrinking pool, got %+v", series[2].Forecast)
+	}
+
+	if _, err := svc.MetricsHistory(userContext("user-2"), nil, "", 30); err == nil {
+		t.Error("expected permission error")
+	}
+}
+
+func TestForecastExhaustion(t *testing.T) {
+	full := forecastExhaustion([]model.MetricPoint{{Day: "2026-10-01", Value: 90}, {Day: "2026-10-02", Value: 100}})
+	if full == nil || full.DaysLeft != 0 || full.ExhaustedOn != "2026-10-02" {
+		t.Errorf("expected a full pool to be exhausted now, got %+v", full)
+	}
+	if f := forecastExhaustion([]model.MetricPoint{{Day: "2026-10-01", Value: 10}}); f != nil {
+		t.Errorf("expected no forecast from one day, got %+v", f)
+	}
+	// 0.001 points a day would take far longer than forecasts reach
+	if f := forecastExhaustion([]model.MetricPoint{{Day: "2026-10-01", Value: 10}, {Day: "2026-10-11", Value: 10.01}}); f != nil {
+		t.Errorf("expected no forecast for negligible growth, got %+v", f)
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// DatabaseService checks and optimizes the database. Both span every table,
+// so they are limited to admins.
+type DatabaseService struct {
+	store storage.ExtendedStorage
+}
+
+func NewDatabaseService(store storage.ExtendedStorage) *DatabaseService {
+	return &DatabaseService{store: store}
+}
+
+// Check reports integrity problems, foreign key violations and orphaned rows
+func (s *DatabaseService) Check(ctx context.Context) (*model.DBCheckReport, error) {
+	if err := requireAdmin(ctx, s.store); err != nil {
+		return nil, err
+	}
+	report, err := s.store.CheckDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !report.OK {
+		log.Warn("Database check found problems", "integrity", report.Integrity, "foreign_key_violations", len(report.ForeignKeyViolations))
+	}
+	return report, nil
+}
+
+// Optimize runs VACUUM and ANALYZE
+func (s *DatabaseService) Optimize(ctx context.Context) (*model.DBOptimizeResult, error) {
+	if err := requireAdmin(ctx, s.store); err != nil {
+		return nil, err
+	}
+	result, err := s.store.OptimizeDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Database optimized", "size_before", result.SizeBefore, "size_after", result.SizeAfter, "duration_ms", result.Duration)
+	return result, nil
+}
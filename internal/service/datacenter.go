@@ -9,11 +9,12 @@ import (
 )
 
 type DatacenterService struct {
-	store storage.ExtendedStorage
+	store        storage.ExtendedStorage
+	searchLimits SearchLimits
 }
 
 func NewDatacenterService(store storage.ExtendedStorage) *DatacenterService {
-	return &DatacenterService{store: store}
+	return &DatacenterService{store: store, searchLimits: DefaultSearchLimits}
 }
 
 func (s *DatacenterService) List(ctx context.Context, filter *model.DatacenterFilter) ([]model.Datacenter, error) {
@@ -66,16 +67,20 @@ func (s *DatacenterService) Update(ctx context.Context, dc *model.Datacenter) er
 	return s.store.UpdateDatacenter(enrichAuditCtx(ctx), dc)
 }
 
-func (s *DatacenterService) Delete(ctx context.Context, id string) error {
+func (s *DatacenterService) Delete(ctx context.Context, id string, policy model.DeletePolicy) error {
 	if err := requirePermission(ctx, s.store, "datacenters", "delete"); err != nil {
 		return err
 	}
 
-	if err := s.store.DeleteDatacenter(enrichAuditCtx(ctx), id); err != nil {
+	if err := checkDeletePolicy(policy); err != nil {
+		return err
+	}
+
+	if err := s.store.TrashResource(enrichAuditCtx(ctx), model.TrashTypeDatacenter, id, policy); err != nil {
 		if errors.Is(err, storage.ErrDatacenterNotFound) {
 			return ErrNotFound
 		}
-		return err
+		return dependentsError(err)
 	}
 	return nil
 }
@@ -101,5 +106,11 @@ func (s *DatacenterService) Search(ctx context.Context, query string) ([]model.D
 		return nil, err
 	}
 
-	return s.store.SearchDatacenters(ctx, query)
+	var datacenters []model.Datacenter
+	err := s.searchLimits.search(ctx, func(ctx context.Context) error {
+		var err error
+		datacenters, err = s.store.SearchDatacenters(ctx, query)
+		return err
+	})
+	return datacenters, err
 }
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// Contacts are part of a datacenter, so reading them needs datacenters:read
+// and changing them needs datacenters:update.
+
+func (s *DatacenterService) ListContacts(ctx context.Context, datacenterID string) ([]model.DatacenterContact, error) {
+	if err := requirePermission(ctx, s.store, "datacenters", "read"); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetDatacenter(ctx, datacenterID); err != nil {
+		if errors.Is(err, storage.ErrDatacenterNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.store.ListDatacenterContacts(ctx, datacenterID)
+}
+
+func (s *DatacenterService) CreateContact(ctx context.Context, contact *model.DatacenterContact) error {
+	if err := requirePermission(ctx, s.store, "datacenters", "update"); err != nil {
+		return err
+	}
+	if err := validateDatacenterContact(contact); err != nil {
+		return err
+	}
+	if _, err := s.store.GetDatacenter(ctx, contact.DatacenterID); err != nil {
+		if errors.Is(err, storage.ErrDatacenterNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return s.store.CreateDatacenterContact(enrichAuditCtx(ctx), contact)
+}
+
+// GetContact returns a contact of a datacenter. A contact of another
+// datacenter is reported as not found.
+func (s *DatacenterService) GetContact(ctx context.Context, datacenterID, id string) (*model.DatacenterContact, error) {
+	if err := requirePermission(ctx, s.store, "datacenters", "read"); err != nil {
+		return nil, err
+	}
+	contact, err := s.store.GetDatacenterContact(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrContactNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if contact.DatacenterID != datacenterID {
+		return nil, ErrNotFound
+	}
+	return contact, nil
+}
+
+func (s *DatacenterService) UpdateContact(ctx context.Context, contact *model.DatacenterContact) error {
+	if err := requirePermission(ctx, s.store, "datacenters", "update"); err != nil {
+		return err
+	}
+	if contact.ID == "" {
+		return ValidationErrors{{Field: "id", Message: "ID is required"}}
+	}
+	if err := validateDatacenterContact(contact); err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateDatacenterContact(enrichAuditCtx(ctx), contact); err != nil {
+		if errors.Is(err, storage.ErrContactNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *DatacenterService) DeleteContact(ctx context.Context, datacenterID, id string) error {
+	if err := requirePermission(ctx, s.store, "datacenters", "update"); err != nil {
+		return err
+	}
+	if _, err := s.GetContact(ctx, datacenterID, id); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteDatacenterContact(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrContactNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func validateDatacenterContact(contact *model.DatacenterContact) error {
+	var errs ValidationErrors
+	contact.Role = strings.TrimSpace(contact.Role)
+	contact.Name = strings.TrimSpace(contact.Name)
+	contact.Email = strings.TrimSpace(contact.Email)
+	contact.Phone = strings.TrimSpace(contact.Phone)
+
+	if contact.DatacenterID == "" {
+		errs = append(errs, ValidationError{Field: "datacenter_id", Message: "Datacenter ID is required"})
+	}
+	if contact.Name == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "Name is required"})
+	}
+	if contact.Email != "" {
+		if addr, err := mail.ParseAddress(contact.Email); err != nil || addr.Address != contact.Email {
+			errs = append(errs, ValidationError{Field: "email", Message: "Email must be a plain email address"})
+		}
+	}
+	if contact.Email == "" && contact.Phone == "" {
+		errs = append(errs, ValidationError{Field: "phone", Message: "A phone number or email is required"})
+	}
+	if contact.EscalationOrder < 0 {
+		errs = append(errs, ValidationError{Field: "escalation_order", Message: "Escalation order must not be negative"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -49,7 +49,38 @@ func TestDatacenterService_CRUDAndGetDevicesPaths(t *testing.T) {
 	if err != nil || len(devices) != 1 {
 		t.Fatalf("expected datacenter devices, got %#v err=%v", devices, err)
 	}
-	if err := svc.Delete(userContext("user-1"), "missing"); !errors.Is(err, ErrNotFound) {
+	if err := svc.Delete(userContext("user-1"), "missing", model.DeleteDetach); !errors.Is(err, ErrNotFound) {
 		t.Fatalf("expected not found on delete, got %v", err)
 	}
 }
+
+func TestDatacenterService_CreateContactValidation(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "datacenters", "update", true)
+	svc := NewDatacenterService(store)
+
+	if err := svc.CreateContact(userContext("user-2"), &model.DatacenterContact{DatacenterID: "dc-1", Name: "Ana", Phone: "1"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden without datacenters:update, got %v", err)
+	}
+
+	contact := &model.DatacenterContact{DatacenterID: "dc-1", Name: " ", Email: "Ana <ana@example.com>", EscalationOrder: -1}
+	err := svc.CreateContact(userContext("user-1"), contact)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, v := range verrs {
+		fields[v.Field] = true
+	}
+	for _, f := range []string{"name", "email", "escalation_order"} {
+		if !fields[f] {
+			t.Errorf("expected validation error for %s, got %v", f, verrs)
+		}
+	}
+
+	err = svc.CreateContact(userContext("user-1"), &model.DatacenterContact{DatacenterID: "dc-1", Name: "Ana"})
+	if !errors.As(err, &verrs) || verrs[0].Field != "phone" {
+		t.Errorf("expected a phone or email to be required, got %v", err)
+	}
+}
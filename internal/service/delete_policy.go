@@ -0,0 +1,28 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// checkDeletePolicy rejects unknown delete policies. An empty policy is
+// allowed and means detach.
+func checkDeletePolicy(policy model.DeletePolicy) error {
+	switch policy {
+	case "", model.DeleteDetach, model.DeleteCascade, model.DeleteDeny:
+		return nil
+	}
+	return ValidationErrors{{Field: "cascade", Message: "Delete policy must be one of: true, detach, deny"}}
+}
+
+// dependentsError converts a storage DependentsError so callers can report
+// what blocked the delete; other errors are returned unchanged
+func dependentsError(err error) error {
+	var depErr *storage.DependentsError
+	if errors.As(err, &depErr) {
+		return &DependentsError{Resource: depErr.Resource, Dependents: depErr.Dependents, message: depErr.Error()}
+	}
+	return err
+}
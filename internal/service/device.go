@@ -4,20 +4,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/search"
 	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/martinsuchenak/rackd/internal/validate"
 )
 
 type DeviceService struct {
 	store           storage.ExtendedStorage
 	conflictService *ConflictService
 	dns             *DNSService
+	monitor         *MonitorService
+	searchLimits    SearchLimits
+	policies        *validate.Policies
+	uniqueNames     bool
 }
 
 func NewDeviceService(store storage.ExtendedStorage) *DeviceService {
-	return &DeviceService{store: store}
+	return &DeviceService{store: store, searchLimits: DefaultSearchLimits}
 }
 
 func (s *DeviceService) setConflictService(cs *ConflictService) {
@@ -28,6 +36,25 @@ func (s *DeviceService) setDNSService(dns *DNSService) {
 	s.dns = dns
 }
 
+func (s *DeviceService) setMonitorService(m *MonitorService) {
+	s.monitor = m
+}
+
+// withStatus attaches active and upcoming maintenance windows to devices,
+// and availability status when monitoring is wired up
+func (s *DeviceService) withStatus(ctx context.Context, devices []model.Device) ([]model.Device, error) {
+	if err := attachMaintenance(ctx, s.store, devices); err != nil {
+		return nil, err
+	}
+	if s.monitor == nil {
+		return devices, nil
+	}
+	if err := s.monitor.attachMonitoring(ctx, devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
 // boolPtr returns a pointer to the given bool value
 func boolPtr(v bool) *bool {
 	return &v
@@ -199,7 +226,250 @@ func (s *DeviceService) checkForIPConflicts(ctx context.Context, device *model.D
 // validateStatus validates the device status
 func validateStatus(status model.DeviceStatus) error {
 	if status != "" && !status.IsValid() {
-		return ValidationErrors{{Field: "status", Message: "Invalid status. Must be one of: planned, active, maintenance, decommissioned"}}
+		return ValidationErrors{{Field: "status", Message: "Invalid status. Must be one of: planned, ordered, active, maintenance, decommissioned"}}
+	}
+	return nil
+}
+
+// validateKind validates the device kind
+func validateKind(kind model.DeviceKind) error {
+	if kind != "" && !kind.IsValid() {
+		return ValidationErrors{{Field: "kind", Message: "Invalid kind. Must be one of: physical, vm, container_host, network, storage, pdu, other"}}
+	}
+	return nil
+}
+
+// normalizeAddressMACs rewrites address MACs in their canonical lowercase
+// colon-separated form
+func normalizeAddressMACs(device *model.Device) error {
+	var errs ValidationErrors
+	for i := range device.Addresses {
+		addr := &device.Addresses[i]
+		if addr.MACAddress == "" {
+			continue
+		}
+		mac, ok := model.NormalizeMAC(addr.MACAddress)
+		if !ok {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("addresses[%d].mac_address", i), Message: "Invalid MAC address"})
+			continue
+		}
+		addr.MACAddress = mac
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// normalizeAddressTypes sets each address's type to the family of its IP
+func normalizeAddressTypes(device *model.Device) error {
+	var errs ValidationErrors
+	for i := range device.Addresses {
+		addr := &device.Addresses[i]
+		addrType, err := model.ResolveAddressType(addr.IP, addr.Type)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("addresses[%d].type", i), Message: err.Error()})
+			continue
+		}
+		addr.Type = addrType
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// normalizeInterfaces checks a device's interfaces and the interfaces its
+// addresses are on. Names must be unique, each parent must be another
+// interface of the device without forming a loop, and MACs are rewritten in
+// canonical form.
+func normalizeInterfaces(device *model.Device) error {
+	var errs ValidationErrors
+	byName := make(map[string]int, len(device.Interfaces))
+	for i := range device.Interfaces {
+		iface := &device.Interfaces[i]
+		field := fmt.Sprintf("interfaces[%d]", i)
+		iface.Name = strings.TrimSpace(iface.Name)
+		iface.Parent = strings.TrimSpace(iface.Parent)
+
+		if iface.Name == "" {
+			errs = append(errs, ValidationError{Field: field + ".name", Message: "Name is required"})
+		} else if _, taken := byName[iface.Name]; taken {
+			errs = append(errs, ValidationError{Field: field + ".name", Message: fmt.Sprintf("Interface %q is listed twice", iface.Name)})
+		} else {
+			byName[iface.Name] = i
+		}
+		if iface.Type == "" {
+			iface.Type = model.InterfaceTypeEthernet
+		} else if !slices.Contains(model.ValidInterfaceTypes, iface.Type) {
+			errs = append(errs, ValidationError{Field: field + ".type", Message: "Type must be one of " + strings.Join(model.ValidInterfaceTypes, ", ")})
+		}
+		if iface.MACAddress != "" {
+			mac, ok := model.NormalizeMAC(iface.MACAddress)
+			if !ok {
+				errs = append(errs, ValidationError{Field: field + ".mac_address", Message: "Invalid MAC address"})
+			}
+			iface.MACAddress = mac
+		}
+		if iface.SpeedMbps < 0 {
+			errs = append(errs, ValidationError{Field: field + ".speed_mbps", Message: "Speed cannot be negative"})
+		}
+		if iface.VLAN < 0 || iface.VLAN > 4094 {
+			errs = append(errs, ValidationError{Field: field + ".vlan", Message: "VLAN must be between 1 and 4094, or 0 for none"})
+		}
+	}
+
+	for i, iface := range device.Interfaces {
+		// Following more parents than there are interfaces means a loop
+		parent := iface.Parent
+		for hops := 0; parent != ""; hops++ {
+			j, ok := byName[parent]
+			if !ok {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("interfaces[%d].parent", i), Message: fmt.Sprintf("Unknown interface %q", parent)})
+				break
+			}
+			if parent == iface.Name || hops >= len(device.Interfaces) {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("interfaces[%d].parent", i), Message: "Interface parents form a loop"})
+				break
+			}
+			parent = device.Interfaces[j].Parent
+		}
+	}
+
+	for i := range device.Addresses {
+		addr := &device.Addresses[i]
+		addr.Interface = strings.TrimSpace(addr.Interface)
+		if _, ok := byName[addr.Interface]; addr.Interface != "" && !ok {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("addresses[%d].interface", i), Message: fmt.Sprintf("Unknown interface %q", addr.Interface)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateAddressFamilies checks that each address assigned to a network or
+// pool is of the same family as that network's subnet or pool's range.
+// Addresses that don't parse and networks or pools that don't exist are left
+// for storage to deal with.
+func (s *DeviceService) validateAddressFamilies(ctx context.Context, device *model.Device) error {
+	networkFamilies := map[string]string{}
+	poolFamilies := map[string]string{}
+	var errs ValidationErrors
+	for i, addr := range device.Addresses {
+		family := ipFamily(addr.IP)
+		if family == "" {
+			continue
+		}
+
+		if addr.NetworkID != "" {
+			want, ok := networkFamilies[addr.NetworkID]
+			if !ok {
+				network, err := s.store.GetNetwork(ctx, addr.NetworkID)
+				if err != nil && !errors.Is(err, storage.ErrNetworkNotFound) {
+					return err
+				}
+				if network != nil {
+					want = subnetFamily(network.Subnet)
+				}
+				networkFamilies[addr.NetworkID] = want
+			}
+			if want != "" && want != family {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("addresses[%d].ip", i), Message: fmt.Sprintf("%s is not an %s address, as its network requires", addr.IP, familyName(want))})
+				continue
+			}
+		}
+
+		if addr.PoolID != "" {
+			want, ok := poolFamilies[addr.PoolID]
+			if !ok {
+				pool, err := s.store.GetNetworkPool(ctx, addr.PoolID)
+				if err != nil && !errors.Is(err, storage.ErrPoolNotFound) {
+					return err
+				}
+				if pool != nil {
+					want = ipFamily(pool.StartIP)
+				}
+				poolFamilies[addr.PoolID] = want
+			}
+			if want != "" && want != family {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("addresses[%d].ip", i), Message: fmt.Sprintf("%s is not an %s address, as its pool requires", addr.IP, familyName(want))})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validatePoolAllocations checks the addresses that name a pool but no IP,
+// which storage gives the pool's next free IP: the pool must exist and hold
+// addresses of the requested type's family. An address with neither an IP
+// nor a pool is rejected.
+func (s *DeviceService) validatePoolAllocations(ctx context.Context, device *model.Device) error {
+	var errs ValidationErrors
+	for i, addr := range device.Addresses {
+		if addr.IP != "" {
+			continue
+		}
+		field := fmt.Sprintf("addresses[%d]", i)
+		if addr.PoolID == "" {
+			errs = append(errs, ValidationError{Field: field + ".ip", Message: "IP address or pool_id is required"})
+			continue
+		}
+		pool, err := s.store.GetNetworkPool(ctx, addr.PoolID)
+		if errors.Is(err, storage.ErrPoolNotFound) {
+			errs = append(errs, ValidationError{Field: field + ".pool_id", Message: "Pool not found"})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		family := ipFamily(pool.StartIP)
+		if family != "" && (addr.Type == model.AddressTypeIPv4 || addr.Type == model.AddressTypeIPv6) && addr.Type != family {
+			errs = append(errs, ValidationError{Field: field + ".type", Message: fmt.Sprintf("type %s does not match pool %s, which holds %s addresses", addr.Type, pool.Name, familyName(family))})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkPolicies checks the device against the configured validation policies,
+// or only its hostname syntax when none are configured
+func (s *DeviceService) checkPolicies(ctx context.Context, device *model.Device) error {
+	policies := s.policies
+	if policies == nil {
+		policies = &validate.Policies{}
+	}
+	violations, err := policies.Device(ctx, s.store, device)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &PolicyViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// checkUniqueName rejects a device whose name another device already uses,
+// ignoring case, when unique names are enforced
+func (s *DeviceService) checkUniqueName(ctx context.Context, device *model.Device) error {
+	if !s.uniqueNames {
+		return nil
+	}
+	matches, err := s.store.FindDevicesByName(ctx, device.Name)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if match.ID != device.ID {
+			return fmt.Errorf("device name %q: %w", match.Name, ErrAlreadyExists)
+		}
 	}
 	return nil
 }
@@ -216,7 +486,48 @@ func (s *DeviceService) List(ctx context.Context, filter *model.DeviceFilter) ([
 	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
 		return nil, err
 	}
-	return s.store.ListDevices(ctx, filter)
+	devices, err := s.store.ListDevices(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.withStatus(ctx, devices)
+}
+
+// ChangedAt returns when the device listing last changed, counting
+// monitoring results and maintenance windows shown with the devices, and the
+// datacenters or networks shown inline by embed
+func (s *DeviceService) ChangedAt(ctx context.Context, embed []string) (time.Time, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return time.Time{}, err
+	}
+	changed, err := s.store.DevicesChangedAt(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, name := range embed {
+		var at time.Time
+		switch name {
+		case EmbedDatacenter:
+			at, err = s.store.DatacentersChangedAt(ctx)
+		case EmbedNetwork:
+			at, err = s.store.NetworksChangedAt(ctx)
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		if at.After(changed) {
+			changed = at
+		}
+	}
+	return changed, nil
+}
+
+// ListAll returns every device matching the filter, ignoring its pagination
+func (s *DeviceService) ListAll(ctx context.Context, filter model.DeviceFilter) ([]model.Device, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+	return listAllDevices(ctx, s.store, filter)
 }
 
 func (s *DeviceService) Create(ctx context.Context, device *model.Device) error {
@@ -232,11 +543,42 @@ func (s *DeviceService) Create(ctx context.Context, device *model.Device) error
 	if err := validateStatus(device.Status); err != nil {
 		return err
 	}
+	if err := validateKind(device.Kind); err != nil {
+		return err
+	}
+	if err := normalizeAddressMACs(device); err != nil {
+		return err
+	}
+	if err := normalizeAddressTypes(device); err != nil {
+		return err
+	}
+	if err := normalizeInterfaces(device); err != nil {
+		return err
+	}
+	if err := s.validatePoolAllocations(ctx, device); err != nil {
+		return err
+	}
+	if err := s.validateAddressFamilies(ctx, device); err != nil {
+		return err
+	}
+	if err := s.checkPolicies(ctx, device); err != nil {
+		return err
+	}
+	if err := s.checkUniqueName(ctx, device); err != nil {
+		return err
+	}
 
 	// Set status changed by from context
 	setStatusChangedBy(ctx, device)
 
+	if IsDryRun(ctx) {
+		return nil
+	}
+
 	err := s.store.CreateDevice(enrichAuditCtx(ctx), device)
+	if errors.Is(err, storage.ErrIPNotAvailable) {
+		return ErrIPNotAvailable
+	}
 	if err != nil {
 		return err
 	}
@@ -264,7 +606,54 @@ func (s *DeviceService) Get(ctx context.Context, id string) (*model.Device, erro
 		}
 		return nil, err
 	}
-	return device, nil
+	devices, err := s.withStatus(ctx, []model.Device{*device})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachSSHHostKeys(ctx, &devices[0]); err != nil {
+		return nil, err
+	}
+	return &devices[0], nil
+}
+
+// attachSSHHostKeys attaches the SSH host key fingerprints discovery
+// collected from the device's addresses
+func (s *DeviceService) attachSSHHostKeys(ctx context.Context, device *model.Device) error {
+	ips := make([]string, 0, len(device.Addresses))
+	for _, addr := range device.Addresses {
+		if addr.IP != "" {
+			ips = append(ips, addr.IP)
+		}
+	}
+	keys, err := s.store.ListSSHHostFingerprints(ctx, ips)
+	if err != nil {
+		return err
+	}
+	device.SSHHostKeys = keys
+	return nil
+}
+
+// Lookup returns the device whose ID is ref, or else the one named ref,
+// ignoring case. A name shared by several devices is an AmbiguousNameError
+// listing them.
+func (s *DeviceService) Lookup(ctx context.Context, ref string) (*model.Device, error) {
+	device, err := s.Get(ctx, ref)
+	if !errors.Is(err, ErrNotFound) {
+		return device, err
+	}
+
+	matches, err := s.store.FindDevicesByName(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	switch len(matches) {
+	case 0:
+		return nil, ErrNotFound
+	case 1:
+		return s.Get(ctx, matches[0].ID)
+	default:
+		return nil, &AmbiguousNameError{Name: ref, Matches: matches}
+	}
 }
 
 func (s *DeviceService) Update(ctx context.Context, device *model.Device) error {
@@ -284,11 +673,44 @@ func (s *DeviceService) Update(ctx context.Context, device *model.Device) error
 	if err := validateStatus(device.Status); err != nil {
 		return err
 	}
+	if err := validateKind(device.Kind); err != nil {
+		return err
+	}
+	if err := normalizeAddressMACs(device); err != nil {
+		return err
+	}
+	if err := normalizeAddressTypes(device); err != nil {
+		return err
+	}
+	if err := normalizeInterfaces(device); err != nil {
+		return err
+	}
+	if err := s.validatePoolAllocations(ctx, device); err != nil {
+		return err
+	}
+	if err := s.validateAddressFamilies(ctx, device); err != nil {
+		return err
+	}
+	if err := s.checkPolicies(ctx, device); err != nil {
+		return err
+	}
+	if err := s.checkUniqueName(ctx, device); err != nil {
+		return err
+	}
 
 	// Set status changed by from context
 	setStatusChangedBy(ctx, device)
 
+	if IsDryRun(ctx) {
+		// Report a missing device as the update would
+		_, err := s.store.GetDevice(ctx, device.ID)
+		return err
+	}
+
 	err := s.store.UpdateDevice(enrichAuditCtx(ctx), device)
+	if errors.Is(err, storage.ErrIPNotAvailable) {
+		return ErrIPNotAvailable
+	}
 	if err != nil {
 		return err
 	}
@@ -304,26 +726,210 @@ func (s *DeviceService) Update(ctx context.Context, device *model.Device) error
 	return nil
 }
 
-func (s *DeviceService) Delete(ctx context.Context, id string) error {
+func (s *DeviceService) Delete(ctx context.Context, id string, policy model.DeletePolicy) error {
 	if err := requirePermission(ctx, s.store, "devices", "delete"); err != nil {
 		return err
 	}
 
-	if err := s.store.DeleteDevice(enrichAuditCtx(ctx), id); err != nil {
+	if err := checkDeletePolicy(policy); err != nil {
+		return err
+	}
+
+	if err := s.store.TrashResource(enrichAuditCtx(ctx), model.TrashTypeDevice, id, policy); err != nil {
 		if errors.Is(err, storage.ErrDeviceNotFound) {
 			return ErrNotFound
 		}
-		return err
+		return dependentsError(err)
 	}
 	return nil
 }
 
+// ListVMs returns the virtual machines hosted on a device
+func (s *DeviceService) ListVMs(ctx context.Context, hostID string, pg model.Pagination) ([]model.Device, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetDevice(ctx, hostID); err != nil {
+		if errors.Is(err, storage.ErrDeviceNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	devices, err := s.store.ListDevices(ctx, &model.DeviceFilter{Pagination: pg, HostID: hostID})
+	if err != nil {
+		return nil, err
+	}
+	return s.withStatus(ctx, devices)
+}
+
+// Label returns the text for a device's asset label. The asset tag is the
+// value of the text custom field with the key assetField, if the device has
+// one.
+func (s *DeviceService) Label(ctx context.Context, id, assetField string) (*model.DeviceLabel, error) {
+	device, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	label := &model.DeviceLabel{
+		DeviceID: device.ID,
+		Name:     device.Name,
+		Hostname: device.Hostname,
+		Location: device.Location,
+	}
+	if device.DatacenterID != "" {
+		dc, err := s.store.GetDatacenter(ctx, device.DatacenterID)
+		if err != nil && !errors.Is(err, storage.ErrDatacenterNotFound) {
+			return nil, err
+		}
+		if dc != nil {
+			label.Datacenter = dc.Name
+		}
+	}
+	if assetField != "" {
+		def, err := s.store.GetCustomFieldDefinitionByKey(ctx, assetField)
+		if err != nil && !errors.Is(err, storage.ErrCustomFieldNotFound) {
+			return nil, err
+		}
+		for _, v := range device.CustomFields {
+			if def != nil && v.FieldID == def.ID && v.Value != nil {
+				label.AssetTag = fmt.Sprint(v.Value)
+			}
+		}
+	}
+	return label, nil
+}
+
+// Merge folds the source device into the target, for when the same host was
+// documented twice. The source is kept as a decommissioned record; see
+// storage.MergeDevices for what moves across.
+func (s *DeviceService) Merge(ctx context.Context, targetID, sourceID string) (*model.Device, error) {
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return nil, err
+	}
+
+	if sourceID == "" {
+		return nil, ValidationErrors{{Field: "source", Message: "Source device ID is required"}}
+	}
+	if sourceID == targetID {
+		return nil, ValidationErrors{{Field: "source", Message: "Cannot merge a device into itself"}}
+	}
+
+	var mergedBy string
+	if caller := CallerFrom(ctx); caller != nil {
+		mergedBy = caller.UserID
+	}
+
+	device, err := s.store.MergeDevices(enrichAuditCtx(ctx), targetID, sourceID, mergedBy)
+	if err != nil {
+		if errors.Is(err, storage.ErrDeviceNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	// Addresses moved onto the target may now clash with other devices
+	s.checkForIPConflicts(ctx, device)
+
+	devices, err := s.withStatus(ctx, []model.Device{*device})
+	if err != nil {
+		return nil, err
+	}
+	return &devices[0], nil
+}
+
 func (s *DeviceService) Search(ctx context.Context, query string) ([]model.Device, error) {
 	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
 		return nil, err
 	}
 
-	return s.store.SearchDevices(ctx, query)
+	if search.IsStructured(query) {
+		return s.searchStructured(ctx, query)
+	}
+
+	var devices []model.Device
+	err := s.searchLimits.search(ctx, func(ctx context.Context) error {
+		var err error
+		devices, err = s.store.SearchDevices(ctx, query)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.withStatus(ctx, devices)
+}
+
+// StructuredSearch parses a structured query and returns it with the
+// devices it matches, so clients can show how the query was understood. A
+// query that does not parse is an *InvalidQueryError locating the fault.
+func (s *DeviceService) StructuredSearch(ctx context.Context, query string) (*search.Query, []model.Device, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, nil, err
+	}
+
+	q, err := search.Parse(query)
+	if err != nil {
+		var syntaxErr *search.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, nil, &InvalidQueryError{Message: syntaxErr.Message, Start: syntaxErr.Start, End: syntaxErr.End}
+		}
+		return nil, nil, err
+	}
+	devices, err := s.matchStructured(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return q, devices, nil
+}
+
+// searchStructured evaluates a structured query (see package search) against
+// every device
+func (s *DeviceService) searchStructured(ctx context.Context, query string) ([]model.Device, error) {
+	q, err := search.Parse(query)
+	if err != nil {
+		return nil, ValidationErrors{{Field: "q", Message: "Invalid query: " + err.Error()}}
+	}
+	return s.matchStructured(ctx, q)
+}
+
+// matchStructured returns the devices matching a parsed query. CIDR and
+// wildcard terms have no SQL equivalent, so matching happens here rather
+// than in storage; the search limits still apply to the number of matches
+// and the time taken.
+func (s *DeviceService) matchStructured(ctx context.Context, q *search.Query) ([]model.Device, error) {
+	env := &search.Env{}
+	if q.Uses("datacenter") {
+		dcs, err := s.store.ListDatacenters(ctx, &model.DatacenterFilter{Pagination: model.Pagination{Limit: model.MaxPageSize}})
+		if err != nil {
+			return nil, err
+		}
+		env.DatacenterNames = make(map[string]string, len(dcs))
+		for _, dc := range dcs {
+			env.DatacenterNames[dc.ID] = dc.Name
+		}
+	}
+
+	var devices []model.Device
+	err := s.searchLimits.search(ctx, func(ctx context.Context) error {
+		all, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+		if err != nil {
+			return err
+		}
+		for i := range all {
+			if q.Match(&all[i], env) {
+				devices = append(devices, all[i])
+			}
+		}
+		if s.searchLimits.MaxRows > 0 && len(devices) > s.searchLimits.MaxRows {
+			return storage.ErrSearchTooBroad
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.withStatus(ctx, devices)
 }
 
 // GetStatusCounts returns the count of devices by status
@@ -334,3 +940,38 @@ func (s *DeviceService) GetStatusCounts(ctx context.Context) (map[model.DeviceSt
 
 	return s.store.GetDeviceStatusCounts(ctx)
 }
+
+// listAllDevices pages through ListDevices so callers see every device
+// matching the filter rather than only the first page.
+func listAllDevices(ctx context.Context, store storage.DeviceStorage, filter model.DeviceFilter) ([]model.Device, error) {
+	var all []model.Device
+	filter.Limit = model.MaxPageSize
+	filter.Offset = 0
+	for {
+		page, err := store.ListDevices(ctx, &filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < model.MaxPageSize {
+			return all, nil
+		}
+		filter.Offset += len(page)
+	}
+}
+
+// DuplicateMACs returns the other devices that share a MAC address with
+// device. Duplicates are not an error: virtual machines and failover pairs can
+// legitimately share one, so callers report them as warnings.
+func (s *DeviceService) DuplicateMACs(ctx context.Context, device *model.Device) ([]model.MACDuplicate, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+	var macs []string
+	for _, addr := range device.Addresses {
+		if mac, ok := model.NormalizeMAC(addr.MACAddress); ok && !slices.Contains(macs, mac) {
+			macs = append(macs, mac)
+		}
+	}
+	return s.store.FindDuplicateMACs(ctx, device.ID, macs)
+}
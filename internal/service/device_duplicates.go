@@ -0,0 +1,153 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// maxDuplicateGroup bounds how many devices may share a MAC, IP or name
+// before the value is treated as a placeholder rather than evidence, such as
+// 00:00:00:00:00:00 or a generic name like "server"
+const maxDuplicateGroup = 10
+
+// Duplicates returns pairs of devices that look like the same host because
+// they share a MAC address, an IP on the same network, or a name once case,
+// domain and separators are ignored. Decommissioned devices, including the
+// leftovers of earlier merges, are not considered.
+func (s *DeviceService) Duplicates(ctx context.Context) ([]model.DuplicateCandidate, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+
+	all, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+	if err != nil {
+		return nil, err
+	}
+	devices := slices.DeleteFunc(all, func(d model.Device) bool {
+		return d.Status == model.DeviceStatusDecommissioned
+	})
+
+	type holder struct {
+		device    int
+		networkID string
+	}
+	byMAC := map[string][]holder{}
+	byIP := map[string][]holder{}
+	byName := map[string][]holder{}
+	add := func(index map[string][]holder, key string, h holder) {
+		for _, existing := range index[key] {
+			if existing.device == h.device {
+				return
+			}
+		}
+		index[key] = append(index[key], h)
+	}
+	for i, d := range devices {
+		for _, addr := range d.Addresses {
+			if mac, ok := model.NormalizeMAC(addr.MACAddress); ok {
+				add(byMAC, mac, holder{device: i})
+			}
+			if ip := net.ParseIP(addr.IP); ip != nil && !ip.IsLoopback() && !ip.IsUnspecified() {
+				add(byIP, ip.String(), holder{device: i, networkID: addr.NetworkID})
+			}
+		}
+		for _, name := range []string{d.Name, d.Hostname} {
+			if key := duplicateNameKey(name); key != "" {
+				add(byName, key, holder{device: i})
+			}
+		}
+	}
+
+	type pair struct{ a, b int }
+	found := map[pair]*model.DuplicateCandidate{}
+	var order []pair
+	record := func(index map[string][]holder, reason model.DuplicateReason) {
+		keys := make([]string, 0, len(index))
+		for k := range index {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			holders := index[key]
+			if len(holders) < 2 || len(holders) > maxDuplicateGroup {
+				continue
+			}
+			for i := range holders {
+				for j := i + 1; j < len(holders); j++ {
+					x, y := holders[i], holders[j]
+					// The same IP on two different networks is two hosts
+					if x.networkID != "" && y.networkID != "" && x.networkID != y.networkID {
+						continue
+					}
+					p := pair{min(x.device, y.device), max(x.device, y.device)}
+					c, ok := found[p]
+					if !ok {
+						c = newDuplicateCandidate(&devices[p.a], &devices[p.b])
+						found[p] = c
+						order = append(order, p)
+					}
+					if !slices.Contains(c.Reasons, reason) {
+						c.Reasons = append(c.Reasons, reason)
+					}
+					if !slices.Contains(c.Matches, key) {
+						c.Matches = append(c.Matches, key)
+					}
+				}
+			}
+		}
+	}
+	record(byMAC, model.DuplicateReasonMAC)
+	record(byIP, model.DuplicateReasonIP)
+	record(byName, model.DuplicateReasonName)
+
+	candidates := make([]model.DuplicateCandidate, 0, len(order))
+	for _, p := range order {
+		candidates = append(candidates, *found[p])
+	}
+	// Pairs with the most kinds of evidence first
+	slices.SortStableFunc(candidates, func(a, b model.DuplicateCandidate) int {
+		return cmp.Or(
+			cmp.Compare(len(b.Reasons), len(a.Reasons)),
+			strings.Compare(a.DeviceName, b.DeviceName),
+			strings.Compare(a.DuplicateName, b.DuplicateName),
+		)
+	})
+	return candidates, nil
+}
+
+// newDuplicateCandidate pairs two devices, suggesting the older one to keep
+// since imports and discovery promotions create the later copy
+func newDuplicateCandidate(a, b *model.Device) *model.DuplicateCandidate {
+	if b.CreatedAt.Before(a.CreatedAt) {
+		a, b = b, a
+	}
+	return &model.DuplicateCandidate{
+		DeviceID:      a.ID,
+		DeviceName:    a.Name,
+		DuplicateID:   b.ID,
+		DuplicateName: b.Name,
+	}
+}
+
+// duplicateNameKey reduces a device name or hostname to what identifies the
+// host: lower case, without a domain and without separators, so that
+// "Web-01.example.com" and "web01" match
+func duplicateNameKey(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	// Devices promoted from discovery are often named after their IP
+	if net.ParseIP(name) != nil {
+		return name
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == ' ' {
+			return -1
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestDeviceService_Duplicates(t *testing.T) {
+	store := newCloudTestStorage()
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, d := range []*model.Device{
+		{Name: "web-01", Addresses: []model.Address{{IP: "10.0.0.5", MACAddress: "aa:bb:cc:00:00:01", NetworkID: "net-1"}}},
+		// Promoted from discovery later: same MAC and IP, name after the IP
+		{Name: "10.0.0.5", Addresses: []model.Address{{IP: "10.0.0.5", MACAddress: "AA-BB-CC-00-00-01", NetworkID: "net-1"}}},
+		// Imported with the FQDN
+		{Name: "WEB01.example.com"},
+		// Same IP on another network is a different host
+		{Name: "db-01", Addresses: []model.Address{{IP: "10.0.0.5", NetworkID: "net-2"}}},
+		// Merged earlier, so left out
+		{Name: "web01", Status: model.DeviceStatusDecommissioned},
+	} {
+		d.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+	}
+	// A placeholder MAC shared by many devices is not evidence
+	for i := 0; i <= maxDuplicateGroup; i++ {
+		d := &model.Device{Name: "blank", Addresses: []model.Address{{MACAddress: "00:00:00:00:00:00"}}}
+		d.Name += string(rune('a' + i))
+		store.CreateDevice(ctx, d)
+	}
+
+	svc := NewDeviceService(store)
+	candidates, err := svc.Duplicates(userContext("user-1"))
+	if err != nil {
+		t.Fatalf("Duplicates failed: %v", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %+v", candidates)
+	}
+
+	first := candidates[0]
+	if first.DeviceID != "dev-1" || first.DuplicateID != "dev-2" ||
+		!slices.Equal(first.Reasons, []model.DuplicateReason{model.DuplicateReasonMAC, model.DuplicateReasonIP}) ||
+		!slices.Equal(first.Matches, []string{"aa:bb:cc:00:00:01", "10.0.0.5"}) {
+		t.Errorf("unexpected MAC and IP candidate: %+v", first)
+	}
+	second := candidates[1]
+	if second.DeviceID != "dev-1" || second.DuplicateID != "dev-3" ||
+		!slices.Equal(second.Reasons, []model.DuplicateReason{model.DuplicateReasonName}) ||
+		!slices.Equal(second.Matches, []string{"web01"}) {
+		t.Errorf("unexpected name candidate: %+v", second)
+	}
+
+	if _, err := svc.Duplicates(userContext("user-2")); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected forbidden without devices:list, got %v", err)
+	}
+}
+
+func TestDuplicateNameKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"Web-01.example.com": "web01",
+		"web_01":             "web01",
+		" db 1 ":             "db1",
+		"10.0.0.5":           "10.0.0.5",
+		"":                   "",
+	} {
+		if got := duplicateNameKey(name); got != want {
+			t.Errorf("duplicateNameKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// Related records that can be shown inline in devices with ?embed=
+const (
+	EmbedDatacenter = "datacenter"
+	EmbedNetwork    = "network"
+)
+
+// ValidateEmbed checks that every name is one of the embeds
+func ValidateEmbed(embed []string) error {
+	for _, name := range embed {
+		if name != EmbedDatacenter && name != EmbedNetwork {
+			return ValidationErrors{{Field: "embed", Message: "Invalid embed " + name + ". Must be one of: datacenter, network"}}
+		}
+	}
+	return nil
+}
+
+// Embed shows the datacenter of each device and the network of each of its
+// addresses inline, as chosen by embed. Each is looked up with one query for
+// all the devices, and needs permission to list datacenters or networks.
+func (s *DeviceService) Embed(ctx context.Context, devices []model.Device, embed []string) error {
+	if err := ValidateEmbed(embed); err != nil {
+		return err
+	}
+	if slices.Contains(embed, EmbedDatacenter) {
+		if err := s.embedDatacenters(ctx, devices); err != nil {
+			return err
+		}
+	}
+	if slices.Contains(embed, EmbedNetwork) {
+		if err := s.embedNetworks(ctx, devices); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DeviceService) embedDatacenters(ctx context.Context, devices []model.Device) error {
+	if err := requirePermission(ctx, s.store, "datacenters", "list"); err != nil {
+		return err
+	}
+	var ids []string
+	for i := range devices {
+		ids = append(ids, devices[i].DatacenterID)
+	}
+	ids = uniqueIDs(ids)
+
+	byID := make(map[string]*model.DatacenterRef)
+	for chunk := range slices.Chunk(ids, model.MaxPageSize) {
+		filter := &model.DatacenterFilter{IDs: chunk}
+		filter.Limit = model.MaxPageSize
+		datacenters, err := s.store.ListDatacenters(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, dc := range datacenters {
+			byID[dc.ID] = &model.DatacenterRef{ID: dc.ID, Name: dc.Name, Location: dc.Location}
+		}
+	}
+	for i := range devices {
+		devices[i].Datacenter = byID[devices[i].DatacenterID]
+	}
+	return nil
+}
+
+func (s *DeviceService) embedNetworks(ctx context.Context, devices []model.Device) error {
+	if err := requirePermission(ctx, s.store, "networks", "list"); err != nil {
+		return err
+	}
+	var ids []string
+	for i := range devices {
+		for _, addr := range devices[i].Addresses {
+			ids = append(ids, addr.NetworkID)
+		}
+	}
+	ids = uniqueIDs(ids)
+
+	byID := make(map[string]*model.NetworkRef)
+	for chunk := range slices.Chunk(ids, model.MaxPageSize) {
+		filter := &model.NetworkFilter{IDs: chunk}
+		filter.Limit = model.MaxPageSize
+		networks, err := s.store.ListNetworks(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, n := range networks {
+			byID[n.ID] = &model.NetworkRef{ID: n.ID, Name: n.Name, Subnet: n.Subnet, VLANID: n.VLANID}
+		}
+	}
+	for i := range devices {
+		for j := range devices[i].Addresses {
+			devices[i].Addresses[j].Network = byID[devices[i].Addresses[j].NetworkID]
+		}
+	}
+	return nil
+}
+
+// uniqueIDs returns the distinct non-empty IDs
+func uniqueIDs(ids []string) []string {
+	ids = slices.DeleteFunc(ids, func(id string) bool { return id == "" })
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
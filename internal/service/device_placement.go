@@ -0,0 +1,246 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// maxPlacementRacks bounds how many racks a placement suggests
+const maxPlacementRacks = 5
+
+// rackSpace is the occupancy of a rack, read from the locations of the
+// devices in it
+type rackSpace struct {
+	name    string
+	used    map[int]bool
+	top     int
+	devices int
+}
+
+// FindFree suggests where a new device could go in a datacenter: racks with
+// a run of free units the device fits in, read from device locations such as
+// "Row A, Rack 07, U12", and the next free IP of a pool with the requested
+// tags, in the network or any network of the datacenter. Tightly fitting
+// runs come first so large runs stay free for large devices. The IP is not
+// reserved.
+func (s *DeviceService) FindFree(ctx context.Context, req model.PlacementRequest) (*model.PlacementSuggestion, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.store, "pools", "read"); err != nil {
+		return nil, err
+	}
+
+	units := cmp.Or(req.Units, 1)
+	height := cmp.Or(req.RackUnits, model.DefaultRackUnits)
+	var errs ValidationErrors
+	if units < 1 || height < 1 || units > height {
+		errs = append(errs, ValidationError{Field: "units", Message: fmt.Sprintf("Units must be between 1 and the rack height (%d)", height)})
+	}
+
+	var network *model.Network
+	if req.Network != "" {
+		var err error
+		if network, err = s.findNetwork(ctx, req.Network); err != nil {
+			return nil, err
+		}
+		if network == nil {
+			errs = append(errs, ValidationError{Field: "network", Message: "Unknown network " + req.Network})
+		}
+	}
+
+	dcs, err := s.store.ListDatacenters(ctx, &model.DatacenterFilter{Pagination: model.Pagination{Limit: model.MaxPageSize}})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(dcs))
+	for _, dc := range dcs {
+		names[dc.ID] = dc.Name
+	}
+	datacenterID := ""
+	switch {
+	case req.Datacenter != "":
+		if datacenterID = resolveDatacenter(names, req.Datacenter); datacenterID == "" {
+			errs = append(errs, ValidationError{Field: "datacenter", Message: "Unknown datacenter " + req.Datacenter})
+		}
+	case network != nil:
+		datacenterID = network.DatacenterID
+	}
+	if datacenterID == "" && req.Datacenter == "" {
+		errs = append(errs, ValidationError{Field: "datacenter", Message: "Datacenter is required unless the network is in one"})
+	}
+	if network != nil && datacenterID != "" && network.DatacenterID != "" && network.DatacenterID != datacenterID {
+		errs = append(errs, ValidationError{Field: "network", Message: fmt.Sprintf("Network %s is not in datacenter %s", network.Name, names[datacenterID])})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	suggestion := &model.PlacementSuggestion{
+		DatacenterID:   datacenterID,
+		DatacenterName: names[datacenterID],
+		Units:          units,
+		Racks:          []model.RackPlacement{},
+	}
+
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{DatacenterID: datacenterID})
+	if err != nil {
+		return nil, err
+	}
+	suggestion.Racks = placeInRacks(devices, units, height)
+	if len(suggestion.Racks) == 0 {
+		suggestion.Notes = append(suggestion.Notes, fmt.Sprintf("No rack has %d contiguous free units. Racks are found from device locations ending in units, such as \"Row A, Rack 07, U12\" or \"Rack 3, U20-U21\".", units))
+	}
+
+	networks := []model.Network{}
+	if network != nil {
+		networks = append(networks, *network)
+	} else if networks, err = listAllNetworks(ctx, s.store, model.NetworkFilter{DatacenterID: datacenterID}); err != nil {
+		return nil, err
+	}
+	pools, full := 0, 0
+	for _, n := range networks {
+		candidates, err := s.store.ListNetworkPools(ctx, &model.NetworkPoolFilter{
+			Pagination: model.Pagination{Limit: model.MaxPageSize},
+			NetworkID:  n.ID,
+			Tags:       req.Tags,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range candidates {
+			pools++
+			ip, err := s.store.GetNextAvailableIP(ctx, p.ID)
+			if errors.Is(err, storage.ErrIPNotAvailable) {
+				full++
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			suggestion.Address = &model.AddressPlacement{IP: ip, PoolID: p.ID, PoolName: p.Name, NetworkID: n.ID, NetworkName: n.Name}
+			return suggestion, nil
+		}
+	}
+
+	what := "No pool"
+	if len(req.Tags) > 0 {
+		what = "No pool tagged " + strings.Join(req.Tags, ", ")
+	}
+	if network != nil {
+		what += " in network " + network.Name
+	} else {
+		what += " in the datacenter's networks"
+	}
+	if pools == 0 {
+		suggestion.Notes = append(suggestion.Notes, what+".")
+	} else {
+		suggestion.Notes = append(suggestion.Notes, fmt.Sprintf("%s has a free IP; %d pools are full.", what, full))
+	}
+	return suggestion, nil
+}
+
+// findNetwork returns the network whose ID is ref, or else the one named
+// ref, ignoring case. It returns nil when there is neither.
+func (s *DeviceService) findNetwork(ctx context.Context, ref string) (*model.Network, error) {
+	network, err := s.store.GetNetwork(ctx, ref)
+	if err == nil {
+		return network, nil
+	}
+	if !errors.Is(err, storage.ErrNetworkNotFound) {
+		return nil, err
+	}
+	networks, err := listAllNetworks(ctx, s.store, model.NetworkFilter{Name: ref})
+	if err != nil {
+		return nil, err
+	}
+	for i := range networks {
+		if strings.EqualFold(networks[i].Name, ref) {
+			return &networks[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// placeInRacks finds a run of free units for a device of the given height in
+// each rack the devices' locations name. A rack is as tall as its highest
+// used unit if that is above height. Racks are returned tightest fit first,
+// then fullest first, and a run is placed at its bottom unit.
+func placeInRacks(devices []model.Device, units, height int) []model.RackPlacement {
+	racks := map[string]*rackSpace{}
+	for _, d := range devices {
+		// Virtual machines take no rack space
+		if d.Kind == model.DeviceKindVM {
+			continue
+		}
+		name, bottom, top, ok := model.ParseRackLocation(d.Location)
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(name)
+		r := racks[key]
+		if r == nil {
+			r = &rackSpace{name: name, used: map[int]bool{}}
+			racks[key] = r
+		}
+		r.devices++
+		r.top = max(r.top, top)
+		for u := bottom; u <= top; u++ {
+			r.used[u] = true
+		}
+	}
+
+	type candidate struct {
+		placement model.RackPlacement
+		run       int
+	}
+	var candidates []candidate
+	for _, r := range racks {
+		rackHeight := max(height, r.top)
+		best := candidate{run: -1}
+		free, start := 0, 0
+		for u := 1; u <= rackHeight+1; u++ {
+			if u <= rackHeight && !r.used[u] {
+				if start == 0 {
+					start = u
+				}
+				free++
+				continue
+			}
+			if start != 0 {
+				if run := u - start; run >= units && (best.run < 0 || run < best.run) {
+					best = candidate{run: run, placement: model.RackPlacement{Bottom: start, Top: start + units - 1}}
+				}
+				start = 0
+			}
+		}
+		if best.run < 0 {
+			continue
+		}
+		best.placement.Rack = r.name
+		best.placement.Location = model.RackLocation(r.name, best.placement.Bottom, best.placement.Top)
+		best.placement.FreeUnits = free
+		best.placement.Devices = r.devices
+		candidates = append(candidates, best)
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(a.run, b.run),
+			cmp.Compare(a.placement.FreeUnits, b.placement.FreeUnits),
+			cmp.Compare(a.placement.Rack, b.placement.Rack),
+		)
+	})
+	placements := []model.RackPlacement{}
+	for _, c := range candidates[:min(len(candidates), maxPlacementRacks)] {
+		placements = append(placements, c.placement)
+	}
+	return placements
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestPlaceInRacks(t *testing.T) {
+	devices := []model.Device{
+		// Rack 1: U1-U2 and U5 used, leaving a 2U run at U3-U4 and U6-U10
+		{Name: "a", Location: "Row A, Rack 01, U1-U2"},
+		{Name: "b", Location: "Row A, Rack 01, U5"},
+		// Rack 2: only U1 used, so U2-U10 is free
+		{Name: "c", Location: "row a, rack 02, U1"},
+		// Rack 3 is full
+		{Name: "d", Location: "Rack 03, U1-U10"},
+		// Virtual machines and unparsed locations take no space
+		{Name: "vm", Kind: model.DeviceKindVM, Location: "Row A, Rack 01, U3"},
+		{Name: "e", Location: "Cage 4"},
+	}
+
+	got := placeInRacks(devices, 2, 10)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 racks, got %+v", got)
+	}
+	// The tightest fitting run comes first
+	if got[0].Rack != "Row A, Rack 01" || got[0].Bottom != 3 || got[0].Top != 4 ||
+		got[0].Location != "Row A, Rack 01, U3-U4" || got[0].FreeUnits != 7 || got[0].Devices != 2 {
+		t.Errorf("unexpected first placement %+v", got[0])
+	}
+	if got[1].Rack != "row a, rack 02" || got[1].Bottom != 2 || got[1].FreeUnits != 9 {
+		t.Errorf("unexpected second placement %+v", got[1])
+	}
+
+	// Rack 3 grows past the rack height to fit its devices, but has no room
+	if got := placeInRacks(devices, 6, 8); len(got) != 1 || got[0].Rack != "row a, rack 02" || got[0].Top != 7 {
+		t.Errorf("expected only rack 02 to fit 6 units, got %+v", got)
+	}
+	if got := placeInRacks(nil, 1, 42); len(got) != 0 {
+		t.Errorf("expected no racks without device locations, got %+v", got)
+	}
+}
@@ -297,12 +297,41 @@ func TestDeviceService_SearchUsesListPermission(t *testing.T) {
 	}
 }
 
+func TestDeviceService_StructuredSearch(t *testing.T) {
+	store := newCloudTestStorage()
+	ctx := context.Background()
+	store.CreateDevice(ctx, &model.Device{Name: "web-01", Tags: []string{"prod"}})
+	store.CreateDevice(ctx, &model.Device{Name: "web-02", Tags: []string{"decom"}})
+	svc := NewDeviceService(store)
+
+	q, devices, err := svc.StructuredSearch(userContext("user-1"), "web -tag:decom")
+	if err != nil {
+		t.Fatalf("StructuredSearch returned unexpected error: %v", err)
+	}
+	if len(devices) != 1 || devices[0].Name != "web-01" {
+		t.Errorf("expected web-01, got %#v", devices)
+	}
+	if ast := q.AST(); ast.Type != "and" || len(ast.Children) != 2 || ast.Children[1].Type != "not" {
+		t.Errorf("unexpected AST %+v", ast)
+	}
+
+	_, _, err = svc.StructuredSearch(userContext("user-1"), "tag:prod status:broken")
+	var queryErr *InvalidQueryError
+	if !errors.As(err, &queryErr) || !errors.Is(err, ErrInvalidQuery) || queryErr.Start != 9 || queryErr.End != 22 {
+		t.Errorf("expected an invalid query error spanning status:broken, got %v", err)
+	}
+
+	if _, _, err := svc.StructuredSearch(userContext("user-2"), "web"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected forbidden without list permission, got %v", err)
+	}
+}
+
 func TestDeviceService_DeleteMapsMissingDeviceToNotFound(t *testing.T) {
 	store := newServiceTestStorage()
 	store.setPermission("user-1", "devices", "delete", true)
 	svc := NewDeviceService(store)
 
-	err := svc.Delete(userContext("user-1"), "missing")
+	err := svc.Delete(userContext("user-1"), "missing", model.DeleteDetach)
 	if !errors.Is(err, ErrNotFound) {
 		t.Fatalf("expected not found error, got %v", err)
 	}
@@ -322,3 +351,289 @@ func TestValidateStatusAndExtractPTRNameHelpers(t *testing.T) {
 		t.Fatalf("expected invalid IP PTR extraction to return empty string, got %q", ptr)
 	}
 }
+
+func TestDeviceService_MergeValidatesInput(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "update", true)
+	svc := NewDeviceService(store)
+
+	if _, err := svc.Merge(userContext("user-1"), "dev-1", ""); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error for missing source, got %v", err)
+	}
+	if _, err := svc.Merge(userContext("user-1"), "dev-1", "dev-1"); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error for self merge, got %v", err)
+	}
+	if _, err := svc.Merge(userContext("user-2"), "dev-1", "dev-2"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected forbidden without devices:update, got %v", err)
+	}
+}
+
+func TestDeviceService_CreateNormalizesMACAddresses(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "create", true)
+	svc := NewDeviceService(store)
+
+	err := svc.Create(userContext("user-1"), &model.Device{
+		Name:      "web-1",
+		Addresses: []model.Address{{IP: "10.0.0.1", MACAddress: "not-a-mac"}},
+	})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || verrs[0].Field != "addresses[0].mac_address" {
+		t.Fatalf("expected validation error for invalid MAC, got %v", err)
+	}
+
+	device := &model.Device{
+		Name:      "web-2",
+		Addresses: []model.Address{{IP: "10.0.0.2", MACAddress: "AA-BB-CC-DD-EE-FF"}},
+	}
+	if err := svc.Create(userContext("user-1"), device); err != nil {
+		t.Fatalf("expected create to succeed, got %v", err)
+	}
+	if device.Addresses[0].MACAddress != "aa:bb:cc:dd:ee:ff" {
+		t.Fatalf("expected normalized MAC, got %q", device.Addresses[0].MACAddress)
+	}
+}
+
+func TestDeviceService_CreateInfersAddressTypes(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "create", true)
+	svc := NewDeviceService(store)
+
+	device := &model.Device{
+		Name: "web-1",
+		Addresses: []model.Address{
+			{IP: "10.0.0.1"},
+			{IP: "2001:db8::1"},
+			{IP: "2001:db8::2", Type: "IPv6"},
+		},
+	}
+	if err := svc.Create(userContext("user-1"), device); err != nil {
+		t.Fatalf("expected create to succeed, got %v", err)
+	}
+	for i, want := range []string{"ipv4", "ipv6", "ipv6"} {
+		if device.Addresses[i].Type != want {
+			t.Errorf("address %d: expected type %s, got %q", i, want, device.Addresses[i].Type)
+		}
+	}
+
+	err := svc.Create(userContext("user-1"), &model.Device{
+		Name: "web-2",
+		Addresses: []model.Address{
+			{IP: "10.0.0.2", Type: "ssh"},
+			{IP: "2001:db8::3", Type: "ipv4"},
+			{IP: "10.0.0.3", Type: "ipv6"},
+		},
+	})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 2 || verrs[0].Field != "addresses[1].type" || verrs[1].Field != "addresses[2].type" {
+		t.Fatalf("expected validation errors for the contradictory types, got %v", err)
+	}
+}
+
+func TestDeviceService_CreateValidatesInterfaces(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "create", true)
+	svc := NewDeviceService(store)
+
+	device := &model.Device{
+		Name: "db-1",
+		Interfaces: []model.Interface{
+			{Name: " bond0 ", Type: model.InterfaceTypeBond},
+			{Name: "eth0", MACAddress: "AA-BB-CC-DD-EE-FF", Parent: "bond0"},
+		},
+		Addresses: []model.Address{{IP: "10.0.0.1", Interface: "bond0"}},
+	}
+	if err := svc.Create(userContext("user-1"), device); err != nil {
+		t.Fatalf("expected create to succeed, got %v", err)
+	}
+	if device.Interfaces[0].Name != "bond0" || device.Interfaces[1].Type != model.InterfaceTypeEthernet ||
+		device.Interfaces[1].MACAddress != "aa:bb:cc:dd:ee:ff" {
+		t.Fatalf("expected normalized interfaces, got %+v", device.Interfaces)
+	}
+
+	err := svc.Create(userContext("user-1"), &model.Device{
+		Name: "db-2",
+		Interfaces: []model.Interface{
+			{Name: "eth0", Type: "token-ring"},
+			{Name: "eth0"},
+			{Name: "bond0", Parent: "bond1"},
+			{Name: "bond1", Parent: "bond0", VLAN: 5000},
+			{Name: "eth1", Parent: "missing"},
+		},
+		Addresses: []model.Address{{IP: "10.0.0.2", Interface: "wlan0"}},
+	})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	fields := make([]string, len(verrs))
+	for i, v := range verrs {
+		fields[i] = v.Field
+	}
+	want := []string{
+		"interfaces[0].type", "interfaces[1].name", "interfaces[3].vlan",
+		"interfaces[2].parent", "interfaces[3].parent", "interfaces[4].parent", "addresses[0].interface",
+	}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected errors on %v, got %v", want, fields)
+	}
+}
+
+func TestDeviceService_CreateRejectsAddressOfOtherFamily(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "create", true)
+	store.networks = []model.Network{
+		{ID: "net-v4", Name: "v4", Subnet: "10.0.0.0/24"},
+		{ID: "net-v6", Name: "v6", Subnet: "2001:db8::/64"},
+	}
+	svc := NewDeviceService(store)
+
+	err := svc.Create(userContext("user-1"), &model.Device{
+		Name: "web-1",
+		Addresses: []model.Address{
+			{IP: "10.0.0.1", NetworkID: "net-v4"},
+			{IP: "10.0.0.2", NetworkID: "net-v6"},
+		},
+	})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Field != "addresses[1].ip" {
+		t.Fatalf("expected validation error for the IPv4 address in an IPv6 network, got %v", err)
+	}
+
+	err = svc.Create(userContext("user-1"), &model.Device{
+		Name: "web-2",
+		Addresses: []model.Address{
+			{IP: "10.0.0.3", NetworkID: "net-v4"},
+			{IP: "2001:db8::3", NetworkID: "net-v6"},
+			{IP: "2001:db8::4", NetworkID: "unknown-net"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected matching families to be accepted, got %v", err)
+	}
+}
+
+func TestDeviceService_DryRunDoesNotPersist(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "create", true)
+	store.setPermission("user-1", "devices", "update", true)
+	svc := NewDeviceService(store)
+	ctx := WithDryRun(userContext("user-1"))
+
+	device := &model.Device{Name: "web-1", Addresses: []model.Address{{IP: "10.0.0.1"}}}
+	if err := svc.Create(ctx, device); err != nil {
+		t.Fatalf("expected dry-run create to succeed, got %v", err)
+	}
+	if store.deviceCreated != nil {
+		t.Fatal("expected dry-run create not to reach storage")
+	}
+	if device.Addresses[0].Type != "ipv4" {
+		t.Errorf("expected dry-run create to normalize the address type, got %q", device.Addresses[0].Type)
+	}
+
+	if err := svc.Create(ctx, &model.Device{}); err == nil {
+		t.Fatal("expected dry-run create to still validate")
+	}
+
+	err := svc.Update(ctx, &model.Device{ID: "missing", Name: "web-2"})
+	if !errors.Is(err, storage.ErrDeviceNotFound) {
+		t.Fatalf("expected dry-run update of a missing device to return not found, got %v", err)
+	}
+	if store.deviceUpdated != nil {
+		t.Fatal("expected dry-run update not to reach storage")
+	}
+}
+
+func TestDeviceService_UniqueNames(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "create", true)
+	store.setPermission("user-1", "devices", "update", true)
+	store.devices["dev-1"] = &model.Device{ID: "dev-1", Name: "web-01"}
+	svc := NewDeviceService(store)
+	ctx := userContext("user-1")
+
+	// Duplicates are allowed until unique names are enforced
+	if err := svc.Create(ctx, &model.Device{ID: "dev-2", Name: "WEB-01"}); err != nil {
+		t.Fatalf("expected duplicate name to be allowed by default, got %v", err)
+	}
+	delete(store.devices, "dev-2")
+
+	svc.uniqueNames = true
+	err := svc.Create(ctx, &model.Device{ID: "dev-3", Name: "WEB-01"})
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+	if err := svc.Update(ctx, &model.Device{ID: "dev-1", Name: "Web-01"}); err != nil {
+		t.Fatalf("expected a device to keep its own name, got %v", err)
+	}
+	store.devices["dev-4"] = &model.Device{ID: "dev-4", Name: "db-01"}
+	if err := svc.Update(ctx, &model.Device{ID: "dev-4", Name: "web-01"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected rename onto a used name to fail, got %v", err)
+	}
+}
+
+func TestDeviceService_Lookup(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "read", true)
+	store.devices["dev-1"] = &model.Device{ID: "dev-1", Name: "web-01"}
+	store.devices["dev-2"] = &model.Device{ID: "dev-2", Name: "db"}
+	store.devices["dev-3"] = &model.Device{ID: "dev-3", Name: "DB"}
+	svc := NewDeviceService(store)
+	ctx := userContext("user-1")
+
+	for _, ref := range []string{"dev-1", "web-01", "WEB-01"} {
+		device, err := svc.Lookup(ctx, ref)
+		if err != nil || device.ID != "dev-1" {
+			t.Errorf("Lookup(%q) = %v, %v; want dev-1", ref, device, err)
+		}
+	}
+
+	_, err := svc.Lookup(ctx, "db")
+	var ambErr *AmbiguousNameError
+	if !errors.As(err, &ambErr) || len(ambErr.Matches) != 2 {
+		t.Fatalf("expected an ambiguous name error listing both devices, got %v", err)
+	}
+
+	if _, err := svc.Lookup(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDeviceService_GetAttachesSSHHostKeys(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "read", true)
+	store.devices["dev-1"] = &model.Device{ID: "dev-1", Name: "web-01", Addresses: []model.Address{{IP: "10.0.0.5"}}}
+	store.sshHostKeys = []model.SSHHostKey{
+		{IP: "10.0.0.5", KeyType: "ssh-ed25519", Fingerprint: "SHA256:abc"},
+		{IP: "10.0.0.6", KeyType: "ssh-ed25519", Fingerprint: "SHA256:def"},
+	}
+	svc := NewDeviceService(store)
+
+	device, err := svc.Get(userContext("user-1"), "dev-1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(device.SSHHostKeys) != 1 || device.SSHHostKeys[0].Fingerprint != "SHA256:abc" {
+		t.Errorf("expected the host key of the device's address, got %+v", device.SSHHostKeys)
+	}
+}
+
+func TestDeviceService_CreateValidatesPoolAllocations(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "devices", "create", true)
+	store.pools["pool-1"] = true
+	svc := NewDeviceService(store)
+
+	if err := svc.Create(userContext("user-1"), &model.Device{Name: "web-1", Addresses: []model.Address{{PoolID: "pool-1"}}}); err != nil {
+		t.Fatalf("expected an address with only a pool to be accepted, got %v", err)
+	}
+
+	err := svc.Create(userContext("user-1"), &model.Device{
+		Name:      "web-2",
+		Addresses: []model.Address{{Label: "mgmt"}, {PoolID: "missing"}},
+	})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 2 || verrs[0].Field != "addresses[0].ip" || verrs[1].Field != "addresses[1].pool_id" {
+		t.Fatalf("expected errors for the address without IP or pool and the missing pool, got %v", err)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/martinsuchenak/rackd/internal/discovery"
@@ -13,8 +17,9 @@ import (
 )
 
 type DiscoveryService struct {
-	store   storage.ExtendedStorage
-	scanner discovery.Scanner
+	store    storage.ExtendedStorage
+	scanner  discovery.Scanner
+	mappings []discovery.ServiceMapping
 }
 
 func NewDiscoveryService(store storage.ExtendedStorage, scanner discovery.Scanner) *DiscoveryService {
@@ -24,6 +29,12 @@ func NewDiscoveryService(store storage.ExtendedStorage, scanner discovery.Scanne
 	}
 }
 
+// SetServiceMappings configures the rules used to fill device fields from
+// discovered services during promotion
+func (s *DiscoveryService) SetServiceMappings(mappings []discovery.ServiceMapping) {
+	s.mappings = mappings
+}
+
 func (s *DiscoveryService) StartScan(ctx context.Context, networkID, scanType string) (*model.DiscoveryScan, error) {
 	if err := requirePermission(ctx, s.store, "discovery", "create"); err != nil {
 		return nil, err
@@ -96,6 +107,37 @@ func (s *DiscoveryService) CancelScan(ctx context.Context, id string) error {
 	return ErrValidation
 }
 
+// WatchScan returns the current state of a scan and, while it is running, a
+// channel of its progress that is closed when it finishes. The caller must
+// call the returned function to end the watch. The channel is nil for scans
+// that already finished or that the scanner cannot stream.
+func (s *DiscoveryService) WatchScan(ctx context.Context, id string) (*model.DiscoveryScan, <-chan model.DiscoveryScan, func(), error) {
+	if err := requirePermission(ctx, s.store, "discovery", "read"); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Watch before reading the scan so no update is missed in between
+	var updates <-chan model.DiscoveryScan
+	stop := func() {}
+	if watcher, ok := s.scanner.(discovery.ProgressWatcher); ok {
+		updates, stop = watcher.WatchScan(id)
+	}
+
+	scan, err := s.store.GetDiscoveryScan(ctx, id)
+	if err != nil {
+		stop()
+		if errors.Is(err, storage.ErrScanNotFound) {
+			return nil, nil, nil, ErrNotFound
+		}
+		return nil, nil, nil, err
+	}
+	if scan.Status == model.ScanStatusCompleted || scan.Status == model.ScanStatusFailed {
+		stop()
+		return scan, nil, func() {}, nil
+	}
+	return scan, updates, stop, nil
+}
+
 func (s *DiscoveryService) DeleteScan(ctx context.Context, id string) error {
 	if err := requirePermission(ctx, s.store, "discovery", "delete"); err != nil {
 		return err
@@ -110,11 +152,23 @@ func (s *DiscoveryService) DeleteScan(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *DiscoveryService) ListDevices(ctx context.Context, networkID string) ([]model.DiscoveredDevice, error) {
+// ListDevices returns the discovered devices of a network. Ignored devices
+// are left out unless includeIgnored is set.
+func (s *DiscoveryService) ListDevices(ctx context.Context, networkID string, includeIgnored bool) ([]model.DiscoveredDevice, error) {
 	if err := requirePermission(ctx, s.store, "discovery", "list"); err != nil {
 		return nil, err
 	}
-	return s.store.ListDiscoveredDevices(ctx, networkID)
+	devices, err := s.store.ListDiscoveredDevices(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !includeIgnored {
+		devices = slices.DeleteFunc(devices, func(d model.DiscoveredDevice) bool { return d.Ignored() })
+	}
+	for i := range devices {
+		devices[i].Services = discovery.ParseServices(devices[i].Services)
+	}
+	return devices, nil
 }
 
 func (s *DiscoveryService) GetDevice(ctx context.Context, id string) (*model.DiscoveredDevice, error) {
@@ -129,6 +183,7 @@ func (s *DiscoveryService) GetDevice(ctx context.Context, id string) (*model.Dis
 		}
 		return nil, err
 	}
+	device.Services = discovery.ParseServices(device.Services)
 	return device, nil
 }
 
@@ -154,6 +209,40 @@ func (s *DiscoveryService) DeleteDevice(ctx context.Context, id string) error {
 	return nil
 }
 
+// IgnoreDevice ignores a discovered device until the given time, or until
+// it is unignored when until is nil
+func (s *DiscoveryService) IgnoreDevice(ctx context.Context, id, reason string, until *time.Time) (*model.DiscoveredDevice, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "update"); err != nil {
+		return nil, err
+	}
+	if until != nil && !until.After(time.Now()) {
+		return nil, ValidationErrors{{Field: "until", Message: "Ignore expiry must be in the future"}}
+	}
+
+	if err := s.store.IgnoreDiscoveredDevice(enrichAuditCtx(ctx), id, strings.TrimSpace(reason), until); err != nil {
+		if errors.Is(err, storage.ErrDiscoveryNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.store.GetDiscoveredDevice(ctx, id)
+}
+
+// UnignoreDevice lifts the ignore of a discovered device
+func (s *DiscoveryService) UnignoreDevice(ctx context.Context, id string) (*model.DiscoveredDevice, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "update"); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UnignoreDiscoveredDevice(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrDiscoveryNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.store.GetDiscoveredDevice(ctx, id)
+}
+
 func (s *DiscoveryService) PromoteDevice(ctx context.Context, discoveredID string, device *model.Device) (*model.Device, error) {
 	if err := requirePermission(ctx, s.store, "discovery", "create"); err != nil {
 		return nil, err
@@ -178,12 +267,14 @@ func (s *DiscoveryService) PromoteDevice(ctx context.Context, discoveredID strin
 	// Carry over all discovered device data to supported Device fields
 	device.ID = uuid.Must(uuid.NewV7()).String()
 
-	// Set IP address from discovered device
+	// Set IP address from discovered device, keeping its MAC
+	mac, _ := model.NormalizeMAC(discovered.MACAddress)
+	discoveredAddr := model.Address{IP: discovered.IP, Type: ipFamily(discovered.IP), MACAddress: mac}
 	if device.Addresses == nil {
-		device.Addresses = []model.Address{{IP: discovered.IP, Type: "ipv4"}}
+		device.Addresses = []model.Address{discoveredAddr}
 	} else {
 		// Add discovered IP to existing addresses
-		device.Addresses = append(device.Addresses, model.Address{IP: discovered.IP, Type: "ipv4"})
+		device.Addresses = append(device.Addresses, discoveredAddr)
 	}
 
 	// Set hostname from discovered device
@@ -191,6 +282,16 @@ func (s *DiscoveryService) PromoteDevice(ctx context.Context, discoveredID strin
 		device.Hostname = discovered.Hostname
 	}
 
+	// Deployment mapping rules take precedence over heuristic guesses
+	discovered.Services = discovery.ParseServices(discovered.Services)
+	mapped := discovery.ApplyServiceMappings(s.mappings, discovered.Services)
+	if v := mapped[discovery.MappingTargetOS]; v != "" && device.OS == "" {
+		device.OS = v
+	}
+	if v := mapped[discovery.MappingTargetMakeModel]; v != "" && device.MakeModel == "" {
+		device.MakeModel = v
+	}
+
 	// Set OS guess from discovered device
 	if discovered.OSGuess != "" && device.OS == "" {
 		device.OS = discovered.OSGuess
@@ -219,7 +320,9 @@ func (s *DiscoveryService) PromoteDevice(ctx context.Context, discoveredID strin
 		if len(discovered.Services) > 0 {
 			servicesInfo := make([]string, len(discovered.Services))
 			for i, svc := range discovered.Services {
-				if svc.Version != "" {
+				if svc.Product != "" && svc.ProductVersion != "" {
+					servicesInfo[i] = fmt.Sprintf("%s@%d (%s %s %s)", svc.Service, svc.Port, svc.Protocol, svc.Product, svc.ProductVersion)
+				} else if svc.Version != "" {
 					servicesInfo[i] = fmt.Sprintf("%s@%d (%s %s)", svc.Service, svc.Port, svc.Protocol, svc.Version)
 				} else {
 					servicesInfo[i] = fmt.Sprintf("%s@%d (%s)", svc.Service, svc.Port, svc.Protocol)
@@ -275,6 +378,10 @@ func (s *DiscoveryService) CreateRule(ctx context.Context, rule *model.Discovery
 		return ValidationErrors{{Field: "network_id", Message: "Network ID is required"}}
 	}
 
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+
 	return s.store.SaveDiscoveryRule(enrichAuditCtx(ctx), rule)
 }
 
@@ -291,9 +398,34 @@ func (s *DiscoveryService) UpdateRule(ctx context.Context, rule *model.Discovery
 		return ValidationErrors{{Field: "network_id", Message: "Network ID is required"}}
 	}
 
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+
 	return s.store.SaveDiscoveryRule(enrichAuditCtx(ctx), rule)
 }
 
+// validateRule checks the scan concurrency cap of a rule and normalizes its
+// custom DNS server to host:port
+func validateRule(rule *model.DiscoveryRule) error {
+	var errs ValidationErrors
+	if rule.MaxConcurrentScans < 0 || rule.MaxConcurrentScans > model.MaxScanWorkers {
+		errs = append(errs, ValidationError{Field: "max_concurrent_scans", Message: fmt.Sprintf("Max concurrent scans must be between 0 and %d", model.MaxScanWorkers)})
+	}
+	if rule.DNSServer != "" {
+		addr, err := discovery.NormalizeDNSServer(rule.DNSServer)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: "dns_server", Message: "DNS server must be a host or host:port"})
+		} else {
+			rule.DNSServer = addr
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 func (s *DiscoveryService) DeleteRule(ctx context.Context, id string) error {
 	if err := requirePermission(ctx, s.store, "discovery", "delete"); err != nil {
 		return err
@@ -307,3 +439,233 @@ func (s *DiscoveryService) DeleteRule(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// DefaultDiffStaleDays is how recently a host must have been seen to count as
+// present in a discovery diff
+const DefaultDiffStaleDays = 7
+
+// CertificateWarningDays is how soon a certificate served by a documented
+// device must expire to be reported in a discovery diff
+const CertificateWarningDays = 30
+
+// Diff compares discovery results for a network with documented devices.
+// It reports hosts that were discovered but not documented, documented
+// devices not seen within staleDays, documented devices whose observed
+// hostname, OS, or ports differ from the record, and documented devices
+// serving a TLS certificate that expires within CertificateWarningDays.
+func (s *DiscoveryService) Diff(ctx context.Context, networkID string, staleDays int) (*model.DiscoveryDiff, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "list"); err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+	if staleDays <= 0 {
+		staleDays = DefaultDiffStaleDays
+	}
+
+	network, err := s.store.GetNetwork(ctx, networkID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNetworkNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	_, subnet, err := net.ParseCIDR(network.Subnet)
+	if err != nil {
+		return nil, ValidationErrors{{Field: "subnet", Message: "Network subnet is not a valid CIDR"}}
+	}
+
+	discovered, err := s.store.ListDiscoveredDevices(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+	if err != nil {
+		return nil, err
+	}
+	maintenance, err := inMaintenance(ctx, s.store, devices)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index documented addresses that belong to this network
+	type docAddr struct {
+		device *model.Device
+		addr   model.Address
+	}
+	byIP := make(map[string]docAddr)
+	var documented []*model.Device
+	for i := range devices {
+		d := &devices[i]
+		inNetwork := false
+		for _, a := range d.Addresses {
+			ip := net.ParseIP(a.IP)
+			if a.NetworkID == networkID || (ip != nil && subnet.Contains(ip)) {
+				byIP[a.IP] = docAddr{device: d, addr: a}
+				inNetwork = true
+			}
+		}
+		if inNetwork {
+			documented = append(documented, d)
+		}
+	}
+
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -staleDays)
+	diff := &model.DiscoveryDiff{
+		NetworkID:    networkID,
+		StaleDays:    staleDays,
+		GeneratedAt:  now,
+		Undocumented: []model.DiscoveredDevice{},
+		Missing:      []model.DiscoveryMissingEntry{},
+		Drift:        []model.DiscoveryDriftEntry{},
+		Certificates: []model.DiscoveryCertificateEntry{},
+	}
+
+	byID := make(map[string]*model.Device, len(documented))
+	for _, d := range documented {
+		byID[d.ID] = d
+	}
+
+	lastSeen := make(map[string]time.Time) // device ID -> most recent sighting
+	for _, dd := range discovered {
+		doc, ok := byIP[dd.IP]
+		if !ok {
+			// A promoted host that moved to an address not on its record
+			if d, promoted := byID[dd.PromotedToDeviceID]; promoted {
+				if dd.LastSeen.After(lastSeen[d.ID]) {
+					lastSeen[d.ID] = dd.LastSeen
+				}
+				diff.Drift = append(diff.Drift, model.DiscoveryDriftEntry{
+					DeviceID:     d.ID,
+					DeviceName:   d.Name,
+					DiscoveredID: dd.ID,
+					IP:           dd.IP,
+					Fields:       []model.DriftField{{Field: "ip", Documented: strings.Join(addressIPs(d), ", "), Observed: dd.IP}},
+				})
+				diff.Certificates = append(diff.Certificates, expiringCertificates(d, &dd, now)...)
+				continue
+			}
+			if !dd.LastSeen.Before(cutoff) && !dd.Ignored() {
+				diff.Undocumented = append(diff.Undocumented, dd)
+			}
+			continue
+		}
+		if dd.LastSeen.After(lastSeen[doc.device.ID]) {
+			lastSeen[doc.device.ID] = dd.LastSeen
+		}
+		if fields := driftFields(doc.device, doc.addr, &dd); len(fields) > 0 {
+			diff.Drift = append(diff.Drift, model.DiscoveryDriftEntry{
+				DeviceID:     doc.device.ID,
+				DeviceName:   doc.device.Name,
+				DiscoveredID: dd.ID,
+				IP:           dd.IP,
+				Fields:       fields,
+			})
+		}
+		diff.Certificates = append(diff.Certificates, expiringCertificates(doc.device, &dd, now)...)
+	}
+
+	for _, d := range documented {
+		// Planned, ordered and decommissioned devices are not expected to answer
+		switch d.Status {
+		case model.DeviceStatusPlanned, model.DeviceStatusOrdered, model.DeviceStatusDecommissioned:
+			continue
+		}
+		// Nor are devices taken down for maintenance
+		if maintenance[d.ID] {
+			continue
+		}
+		seen, ok := lastSeen[d.ID]
+		if ok && !seen.Before(cutoff) {
+			continue
+		}
+		entry := model.DiscoveryMissingEntry{DeviceID: d.ID, DeviceName: d.Name, IPs: []string{}}
+		for _, a := range d.Addresses {
+			if doc, ok := byIP[a.IP]; ok && doc.device.ID == d.ID {
+				entry.IPs = append(entry.IPs, a.IP)
+			}
+		}
+		if ok {
+			entry.LastSeen = &seen
+		}
+		diff.Missing = append(diff.Missing, entry)
+	}
+
+	return diff, nil
+}
+
+// driftFields compares a documented device with what discovery observed
+func driftFields(device *model.Device, addr model.Address, observed *model.DiscoveredDevice) []model.DriftField {
+	var fields []model.DriftField
+
+	if device.Hostname != "" && observed.Hostname != "" && !sameHostname(device.Hostname, observed.Hostname) {
+		fields = append(fields, model.DriftField{Field: "hostname", Documented: device.Hostname, Observed: observed.Hostname})
+	}
+
+	if device.OS != "" && observed.OSGuess != "" {
+		doc, obs := strings.ToLower(device.OS), strings.ToLower(observed.OSGuess)
+		if !strings.Contains(doc, obs) && !strings.Contains(obs, doc) {
+			fields = append(fields, model.DriftField{Field: "os", Documented: device.OS, Observed: observed.OSGuess})
+		}
+	}
+
+	// Only compare ports when the scan actually probed ports
+	if addr.Port != nil && len(observed.OpenPorts) > 0 && !slices.Contains(observed.OpenPorts, *addr.Port) {
+		fields = append(fields, model.DriftField{
+			Field:      "port",
+			Documented: strconv.Itoa(*addr.Port),
+			Observed:   fmt.Sprint(observed.OpenPorts),
+		})
+	}
+
+	return fields
+}
+
+// expiringCertificates returns the certificates a discovered host served
+// that expired or expire within CertificateWarningDays
+func expiringCertificates(device *model.Device, observed *model.DiscoveredDevice, now time.Time) []model.DiscoveryCertificateEntry {
+	var entries []model.DiscoveryCertificateEntry
+	warnAfter := now.AddDate(0, 0, CertificateWarningDays)
+	for _, svc := range observed.Services {
+		if svc.Certificate == nil || svc.Certificate.NotAfter.After(warnAfter) {
+			continue
+		}
+		left := svc.Certificate.NotAfter.Sub(now)
+		entries = append(entries, model.DiscoveryCertificateEntry{
+			DeviceID:     device.ID,
+			DeviceName:   device.Name,
+			DiscoveredID: observed.ID,
+			IP:           observed.IP,
+			Port:         svc.Port,
+			Certificate:  *svc.Certificate,
+			Expired:      left < 0,
+			DaysLeft:     int(left.Hours() / 24),
+		})
+	}
+	return entries
+}
+
+func addressIPs(d *model.Device) []string {
+	ips := make([]string, 0, len(d.Addresses))
+	for _, a := range d.Addresses {
+		ips = append(ips, a.IP)
+	}
+	return ips
+}
+
+// sameHostname compares hostnames case-insensitively, treating a short name
+// as equal to an FQDN with the same first label.
+func sameHostname(a, b string) bool {
+	a = strings.ToLower(strings.TrimSuffix(a, "."))
+	b = strings.ToLower(strings.TrimSuffix(b, "."))
+	if a == b {
+		return true
+	}
+	if !strings.Contains(a, ".") || !strings.Contains(b, ".") {
+		short := func(s string) string { return strings.SplitN(s, ".", 2)[0] }
+		return short(a) == short(b)
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// ListPorts returns the port history discovery recorded, one page at a time
+func (s *DiscoveryService) ListPorts(ctx context.Context, filter *model.PortObservationFilter) ([]model.PortObservation, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "list"); err != nil {
+		return nil, err
+	}
+	return s.store.ListPortObservations(ctx, filter)
+}
+
+// ScanPortChanges returns the ports a scan found newly opened on a host, or
+// found closed, compared with earlier scans
+func (s *DiscoveryService) ScanPortChanges(ctx context.Context, scanID string) (*model.PortChanges, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "read"); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetDiscoveryScan(ctx, scanID); err != nil {
+		if errors.Is(err, storage.ErrScanNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	opened, err := listAllPortObservations(ctx, s.store, model.PortObservationFilter{OpenedScanID: scanID})
+	if err != nil {
+		return nil, err
+	}
+	closed, err := listAllPortObservations(ctx, s.store, model.PortObservationFilter{ClosedScanID: scanID})
+	if err != nil {
+		return nil, err
+	}
+	return &model.PortChanges{ScanID: scanID, Opened: opened, Closed: closed}, nil
+}
+
+// DevicePorts returns the port history of the IPs of a device's addresses
+func (s *DiscoveryService) DevicePorts(ctx context.Context, deviceID string) ([]model.PortObservation, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "list"); err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.store, "devices", "read"); err != nil {
+		return nil, err
+	}
+
+	device, err := s.store.GetDevice(ctx, deviceID)
+	if err != nil {
+		if errors.Is(err, storage.ErrDeviceNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var ips []string
+	for _, addr := range device.Addresses {
+		ips = append(ips, addr.IP)
+	}
+	ips = uniqueIDs(ips)
+	if len(ips) == 0 {
+		return []model.PortObservation{}, nil
+	}
+	return listAllPortObservations(ctx, s.store, model.PortObservationFilter{IPs: ips})
+}
+
+func listAllPortObservations(ctx context.Context, store storage.DiscoveryStorage, filter model.PortObservationFilter) ([]model.PortObservation, error) {
+	all := []model.PortObservation{}
+	filter.Limit = model.MaxPageSize
+	filter.Offset = 0
+	for {
+		page, err := store.ListPortObservations(ctx, &filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < model.MaxPageSize {
+			return all, nil
+		}
+		filter.Offset += len(page)
+	}
+}
@@ -16,9 +16,12 @@ type discoveryTestStorage struct {
 	permissions map[string]bool
 	networks    map[string]*model.Network
 	discovered  map[string]*model.DiscoveredDevice
+	devices     []model.Device
+	rules       []model.AutoPromotionRule
 	created     *model.Device
 	promotedID  string
 	promotedTo  string
+	maintenance []model.MaintenanceWindow
 }
 
 func newDiscoveryTestStorage() *discoveryTestStorage {
@@ -55,6 +58,37 @@ func (s *discoveryTestStorage) GetDiscoveredDevice(_ context.Context, id string)
 	return &cloned, nil
 }
 
+func (s *discoveryTestStorage) ListDiscoveredDevices(_ context.Context, networkID string) ([]model.DiscoveredDevice, error) {
+	var result []model.DiscoveredDevice
+	for _, d := range s.discovered {
+		if d.NetworkID == networkID {
+			result = append(result, *d)
+		}
+	}
+	return result, nil
+}
+
+func (s *discoveryTestStorage) ListDevices(_ context.Context, filter *model.DeviceFilter) ([]model.Device, error) {
+	if filter.Offset >= len(s.devices) {
+		return nil, nil
+	}
+	return s.devices[filter.Offset:], nil
+}
+
+func (s *discoveryTestStorage) ListMaintenanceWindows(_ context.Context, _ *model.MaintenanceWindowFilter) ([]model.MaintenanceWindow, error) {
+	return s.maintenance, nil
+}
+
+func (s *discoveryTestStorage) ListAutoPromotionRules(_ context.Context, networkID string) ([]model.AutoPromotionRule, error) {
+	var result []model.AutoPromotionRule
+	for _, r := range s.rules {
+		if networkID == "" || r.NetworkID == networkID {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
 func (s *discoveryTestStorage) CreateDevice(_ context.Context, device *model.Device) error {
 	cloned := *device
 	s.created = &cloned
@@ -84,6 +118,53 @@ func (s *scannerStub) CancelScan(ctx context.Context, scanID string) error {
 	return s.cancelFn(ctx, scanID)
 }
 
+type watchingScannerStub struct {
+	scannerStub
+	updates chan model.DiscoveryScan
+	stopped bool
+}
+
+func (s *watchingScannerStub) WatchScan(_ string) (<-chan model.DiscoveryScan, func()) {
+	return s.updates, func() { s.stopped = true }
+}
+
+func TestDiscoveryService_WatchScan(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "discovery", "read", true)
+	store.discoveryScans["running"] = &model.DiscoveryScan{ID: "running", Status: model.ScanStatusRunning}
+	store.discoveryScans["done"] = &model.DiscoveryScan{ID: "done", Status: model.ScanStatusCompleted}
+	scanner := &watchingScannerStub{updates: make(chan model.DiscoveryScan, 1)}
+	svc := NewDiscoveryService(store, scanner)
+
+	scan, updates, stop, err := svc.WatchScan(userContext("user-1"), "running")
+	if err != nil {
+		t.Fatalf("WatchScan returned unexpected error: %v", err)
+	}
+	if scan.ID != "running" || updates == nil {
+		t.Fatalf("expected running scan with updates, got %+v %v", scan, updates)
+	}
+	stop()
+	if !scanner.stopped {
+		t.Fatal("expected stop to end the scanner watch")
+	}
+
+	scanner.stopped = false
+	scan, updates, _, err = svc.WatchScan(userContext("user-1"), "done")
+	if err != nil {
+		t.Fatalf("WatchScan returned unexpected error: %v", err)
+	}
+	if scan.Status != model.ScanStatusCompleted || updates != nil || !scanner.stopped {
+		t.Fatalf("expected finished scan without updates, got %+v %v stopped=%v", scan, updates, scanner.stopped)
+	}
+
+	if _, _, _, err := svc.WatchScan(userContext("user-1"), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found for missing scan, got %v", err)
+	}
+	if _, _, _, err := svc.WatchScan(userContext("user-2"), "running"); err == nil {
+		t.Fatal("expected permission error")
+	}
+}
+
 func TestDiscoveryService_StartScanDefaultsInvalidTypeToQuick(t *testing.T) {
 	store := newDiscoveryTestStorage()
 	store.setPermission("user-1", "discovery", "create", true)
@@ -154,8 +235,8 @@ func TestDiscoveryService_PromoteDeviceCarriesOverDiscoveredFields(t *testing.T)
 	if err != nil {
 		t.Fatalf("PromoteDevice returned unexpected error: %v", err)
 	}
-	if len(device.Addresses) != 1 || device.Addresses[0].IP != "10.0.0.15" {
-		t.Fatalf("expected discovered IP to be carried over, got %#v", device.Addresses)
+	if len(device.Addresses) != 1 || device.Addresses[0].IP != "10.0.0.15" || device.Addresses[0].MACAddress != "aa:bb:cc:dd:ee:ff" {
+		t.Fatalf("expected discovered IP and MAC to be carried over, got %#v", device.Addresses)
 	}
 	if device.Hostname != "ap-1" || device.MakeModel != "Ubiquiti" || device.OS != "EdgeOS" {
 		t.Fatalf("expected discovered metadata to be carried over, got %#v", device)
@@ -165,6 +246,200 @@ func TestDiscoveryService_PromoteDeviceCarriesOverDiscoveredFields(t *testing.T)
 	}
 }
 
+func TestDiscoveryService_PromoteDeviceAppliesServiceMappings(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "create", true)
+	store.discovered["disc-1"] = &model.DiscoveredDevice{
+		ID:      "disc-1",
+		IP:      "10.0.0.20",
+		OSGuess: "Linux",
+		Services: []model.ServiceInfo{
+			{Port: 22, Protocol: "tcp", Service: "ssh", Version: "OpenSSH_8.9p1 Ubuntu-3ubuntu0.1"},
+		},
+	}
+
+	mappings, err := discoverypkg.ParseServiceMappings("info=Ubuntu=>os=Ubuntu 22.04")
+	if err != nil {
+		t.Fatalf("ParseServiceMappings returned unexpected error: %v", err)
+	}
+	svc := NewDiscoveryService(store, nil)
+	svc.SetServiceMappings(mappings)
+
+	device, err := svc.PromoteDevice(userContext("user-1"), "disc-1", &model.Device{Name: "app-1"})
+	if err != nil {
+		t.Fatalf("PromoteDevice returned unexpected error: %v", err)
+	}
+	if device.OS != "Ubuntu 22.04" {
+		t.Fatalf("expected mapped OS to win over guess, got %q", device.OS)
+	}
+
+	device, err = svc.PromoteDevice(userContext("user-1"), "disc-1", &model.Device{Name: "app-2", OS: "Debian"})
+	if err != nil {
+		t.Fatalf("PromoteDevice returned unexpected error: %v", err)
+	}
+	if device.OS != "Debian" {
+		t.Fatalf("expected explicit OS to be kept, got %q", device.OS)
+	}
+}
+
+func TestDiscoveryService_GetDeviceParsesServiceVersions(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "read", true)
+	store.discovered["disc-1"] = &model.DiscoveredDevice{
+		ID:       "disc-1",
+		Services: []model.ServiceInfo{{Port: 80, Protocol: "tcp", Service: "http", Version: "nginx/1.24.0 (Ubuntu)"}},
+	}
+
+	device, err := NewDiscoveryService(store, nil).GetDevice(userContext("user-1"), "disc-1")
+	if err != nil {
+		t.Fatalf("GetDevice returned unexpected error: %v", err)
+	}
+	got := device.Services[0]
+	if got.Product != "nginx" || got.ProductVersion != "1.24.0" || got.Info != "Ubuntu" {
+		t.Fatalf("expected parsed service fields, got %#v", got)
+	}
+}
+
+func TestDiscoveryService_DiffReportsUndocumentedMissingAndDrift(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "list", true)
+	store.setPermission("user-1", "devices", "list", true)
+	store.networks["net-1"] = &model.Network{ID: "net-1", Subnet: "10.0.0.0/24"}
+
+	now := time.Now()
+	port := 8080
+	store.devices = []model.Device{
+		{ID: "dev-web", Name: "web-1", Hostname: "web-1", OS: "Ubuntu", Addresses: []model.Address{{IP: "10.0.0.10", Port: &port}}},
+		{ID: "dev-db", Name: "db-1", Addresses: []model.Address{{IP: "10.0.0.20", NetworkID: "net-1"}}},
+		{ID: "dev-new", Name: "rack-2", Status: model.DeviceStatusPlanned, Addresses: []model.Address{{IP: "10.0.0.30"}}},
+		{ID: "dev-fw", Name: "fw-1", Tags: []string{"rack-a"}, Addresses: []model.Address{{IP: "10.0.0.40"}}},
+		{ID: "dev-other", Name: "elsewhere", Addresses: []model.Address{{IP: "192.168.1.5"}}},
+	}
+	// fw-1 is down for maintenance; the db window has not started yet
+	store.maintenance = []model.MaintenanceWindow{
+		{ID: "mw-1", Tag: "rack-a", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+		{ID: "mw-2", DeviceID: "dev-db", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)},
+	}
+	store.discovered["disc-web"] = &model.DiscoveredDevice{
+		ID: "disc-web", NetworkID: "net-1", IP: "10.0.0.10", Hostname: "web-1.example.com",
+		OSGuess: "Windows", OpenPorts: []int{22, 443, 8443}, LastSeen: now,
+		Services: []model.ServiceInfo{
+			{Port: 443, Service: "https", Certificate: &model.TLSCertificate{CommonName: "web-1", NotAfter: now.Add(10*24*time.Hour + time.Hour)}},
+			{Port: 8443, Service: "https", Certificate: &model.TLSCertificate{CommonName: "admin", NotAfter: now.AddDate(1, 0, 0)}},
+		},
+	}
+	store.discovered["disc-db"] = &model.DiscoveredDevice{
+		ID: "disc-db", NetworkID: "net-1", IP: "10.0.0.20", LastSeen: now.AddDate(0, 0, -30),
+	}
+	store.discovered["disc-new"] = &model.DiscoveredDevice{
+		ID: "disc-new", NetworkID: "net-1", IP: "10.0.0.99", LastSeen: now,
+		Services: []model.ServiceInfo{{Port: 443, Certificate: &model.TLSCertificate{NotAfter: now.Add(-time.Hour)}}},
+	}
+	store.discovered["disc-old"] = &model.DiscoveredDevice{
+		ID: "disc-old", NetworkID: "net-1", IP: "10.0.0.98", LastSeen: now.AddDate(0, 0, -30),
+	}
+	store.discovered["disc-guest"] = &model.DiscoveredDevice{
+		ID: "disc-guest", NetworkID: "net-1", IP: "10.0.0.97", LastSeen: now, Status: model.DiscoveredStatusIgnored,
+	}
+
+	svc := NewDiscoveryService(store, nil)
+	diff, err := svc.Diff(userContext("user-1"), "net-1", 0)
+	if err != nil {
+		t.Fatalf("Diff returned unexpected error: %v", err)
+	}
+	if diff.StaleDays != DefaultDiffStaleDays {
+		t.Fatalf("expected default stale days %d, got %d", DefaultDiffStaleDays, diff.StaleDays)
+	}
+	if len(diff.Undocumented) != 1 || diff.Undocumented[0].IP != "10.0.0.99" {
+		t.Fatalf("expected only the recently seen unknown host, got %#v", diff.Undocumented)
+	}
+	if len(diff.Missing) != 1 || diff.Missing[0].DeviceID != "dev-db" || diff.Missing[0].LastSeen == nil {
+		t.Fatalf("expected stale db device to be missing, got %#v", diff.Missing)
+	}
+	if len(diff.Drift) != 1 || diff.Drift[0].DeviceID != "dev-web" {
+		t.Fatalf("expected drift on web device, got %#v", diff.Drift)
+	}
+	fields := make(map[string]bool)
+	for _, f := range diff.Drift[0].Fields {
+		fields[f.Field] = true
+	}
+	if fields["hostname"] || !fields["os"] || !fields["port"] {
+		t.Fatalf("expected os and port drift only, got %#v", diff.Drift[0].Fields)
+	}
+	// Only documented devices get certificate warnings
+	if len(diff.Certificates) != 1 {
+		t.Fatalf("expected one expiring certificate, got %#v", diff.Certificates)
+	}
+	cert := diff.Certificates[0]
+	if cert.DeviceID != "dev-web" || cert.Port != 443 || cert.Expired || cert.DaysLeft != 10 || cert.Certificate.CommonName != "web-1" {
+		t.Fatalf("unexpected certificate warning: %#v", cert)
+	}
+}
+
+func (s *discoveryTestStorage) IgnoreDiscoveredDevice(_ context.Context, id, reason string, until *time.Time) error {
+	device, ok := s.discovered[id]
+	if !ok {
+		return storage.ErrDiscoveryNotFound
+	}
+	device.Status = model.DiscoveredStatusIgnored
+	device.IgnoreReason = reason
+	device.IgnoredUntil = until
+	return nil
+}
+
+func TestDiscoveryService_IgnoreDevice(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "update", true)
+	store.setPermission("user-1", "discovery", "list", true)
+	store.discovered["guest"] = &model.DiscoveredDevice{ID: "guest", NetworkID: "net-1", IP: "10.0.0.50", Status: "online"}
+	store.discovered["server"] = &model.DiscoveredDevice{ID: "server", NetworkID: "net-1", IP: "10.0.0.51", Status: "online"}
+	svc := NewDiscoveryService(store, nil)
+
+	past := time.Now().Add(-time.Hour)
+	var verrs ValidationErrors
+	if _, err := svc.IgnoreDevice(userContext("user-1"), "guest", "", &past); !errors.As(err, &verrs) || verrs[0].Field != "until" {
+		t.Fatalf("expected until validation error, got %v", err)
+	}
+	if _, err := svc.IgnoreDevice(userContext("user-1"), "missing", "", nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	device, err := svc.IgnoreDevice(userContext("user-1"), "guest", " guest Wi-Fi ", nil)
+	if err != nil {
+		t.Fatalf("IgnoreDevice returned unexpected error: %v", err)
+	}
+	if !device.Ignored() || device.IgnoreReason != "guest Wi-Fi" {
+		t.Fatalf("expected ignored device with trimmed reason, got %+v", device)
+	}
+
+	devices, err := svc.ListDevices(userContext("user-1"), "net-1", false)
+	if err != nil || len(devices) != 1 || devices[0].ID != "server" {
+		t.Fatalf("expected ignored device left out, got %+v (%v)", devices, err)
+	}
+	if devices, _ := svc.ListDevices(userContext("user-1"), "net-1", true); len(devices) != 2 {
+		t.Fatalf("expected ignored device with include_ignored, got %+v", devices)
+	}
+
+	if _, err := svc.IgnoreDevice(userContext("user-2"), "server", "", nil); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestDiscoveryService_DiffRequiresPermissionsAndNetwork(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "list", true)
+	svc := NewDiscoveryService(store, nil)
+
+	if _, err := svc.Diff(userContext("user-1"), "net-1", 7); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden without devices:list, got %v", err)
+	}
+
+	store.setPermission("user-1", "devices", "list", true)
+	if _, err := svc.Diff(userContext("user-1"), "missing", 7); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for unknown network, got %v", err)
+	}
+}
+
 func TestDiscoveryService_RuleValidationAndDeleteMapping(t *testing.T) {
 	store := newServiceTestStorage()
 	store.setPermission("user-1", "discovery", "create", true)
@@ -182,6 +457,16 @@ func TestDiscoveryService_RuleValidationAndDeleteMapping(t *testing.T) {
 		t.Fatalf("expected validation error for missing rule ID, got %v", err)
 	}
 
+	err = svc.CreateRule(userContext("user-1"), &model.DiscoveryRule{NetworkID: "net-1", DNSResolution: true, DNSServer: "10.0.0.53:dns"})
+	if err == nil || !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error for invalid DNS server, got %v", err)
+	}
+
+	err = svc.CreateRule(userContext("user-1"), &model.DiscoveryRule{NetworkID: "net-1", MaxConcurrentScans: model.MaxScanWorkers + 1})
+	if err == nil || !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error for too many concurrent scans, got %v", err)
+	}
+
 	err = svc.DeleteRule(userContext("user-1"), "missing")
 	if !errors.Is(err, ErrNotFound) {
 		t.Fatalf("expected not found for missing rule, got %v", err)
@@ -204,7 +489,7 @@ func TestDiscoveryService_ListGetAndDeleteWrappers(t *testing.T) {
 	if _, err := svc.GetScan(userContext("user-1"), "scan-1"); err != nil {
 		t.Fatalf("GetScan returned unexpected error: %v", err)
 	}
-	if _, err := svc.ListDevices(userContext("user-1"), "net-1"); err != nil {
+	if _, err := svc.ListDevices(userContext("user-1"), "net-1", false); err != nil {
 		t.Fatalf("ListDevices returned unexpected error: %v", err)
 	}
 	if _, err := svc.GetDevice(userContext("user-1"), "disc-1"); err != nil {
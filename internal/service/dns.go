@@ -1326,13 +1326,10 @@ func (s *DNSService) PromoteRecord(ctx context.Context, recordID string, req *mo
 			{
 				ID:        addressID,
 				IP:        record.Value,
-				Type:      "ipv4",
+				Type:      ipFamily(record.Value),
 				NetworkID: networkID,
 			},
 		}
-		if record.Type == "AAAA" {
-			device.Addresses[0].Type = "ipv6"
-		}
 	}
 
 	// 5. Apply overrides (datacenter_id, tags)
@@ -719,6 +719,10 @@ func (s *promoteTestStorage) ListDNSZones(_ context.Context, _ *model.DNSZoneFil
 	return nil, nil // No auto-sync zones needed for promote tests
 }
 
+func (s *promoteTestStorage) GetNetwork(_ context.Context, _ string) (*model.Network, error) {
+	return nil, storage.ErrNetworkNotFound // Zone networks are not stored for promote tests
+}
+
 func buildPromoteTestService(ss *promoteTestStorage) *DNSService {
 	deviceSvc := &DeviceService{
 		store: ss,
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// DefaultZoneTTL is the $TTL of exported zone fragments when none is given
+const DefaultZoneTTL = 3600
+
+var dnsNamePattern = regexp.MustCompile(`^([a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ZoneExportOptions controls how a zone fragment is rendered
+type ZoneExportOptions struct {
+	// TTL is written as the fragment's $TTL; zero means DefaultZoneTTL
+	TTL int
+	// IncludePTR adds reverse records for every exported address
+	IncludePTR bool
+}
+
+type zoneRecord struct {
+	name  string
+	rtype string
+	value string
+	addr  netip.Addr
+}
+
+// ExportZone renders a BIND-style zone fragment for domain from device
+// addresses and domains. A device contributes A and AAAA records for each of
+// its domains inside the zone, and for its hostname when that is a fully
+// qualified name inside the zone. Decommissioned devices are left out. The
+// fragment has no SOA or NS records; it is meant to be included from a zone
+// file that has them. Its header notes the settings of the networks the
+// addresses are in.
+func (s *DNSService) ExportZone(ctx context.Context, domain string, opts ZoneExportOptions) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if !dnsNamePattern.MatchString(domain) || len(domain) > 253 {
+		return "", ValidationErrors{{Field: "domain", Message: "must be a valid DNS domain name"}}
+	}
+	if opts.TTL < 0 {
+		return "", ValidationErrors{{Field: "ttl", Message: "must not be negative"}}
+	}
+	if opts.TTL == 0 {
+		opts.TTL = DefaultZoneTTL
+	}
+	if s.devices == nil {
+		return "", fmt.Errorf("device service not configured")
+	}
+
+	devices, err := s.devices.ListAll(ctx, model.DeviceFilter{})
+	if err != nil {
+		return "", err
+	}
+	slices.SortFunc(devices, func(a, b model.Device) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+
+	var records, ptrs []zoneRecord
+	var networkIDs []string
+	seen := make(map[string]bool)
+	ptrSeen := make(map[netip.Addr]bool)
+	for _, d := range devices {
+		if d.Status == model.DeviceStatusDecommissioned {
+			continue
+		}
+		names := zoneNames(&d, domain)
+		if len(names) == 0 {
+			continue
+		}
+		for _, a := range d.Addresses {
+			addr, err := netip.ParseAddr(a.IP)
+			if err != nil {
+				continue
+			}
+			addr = addr.Unmap()
+			if a.NetworkID != "" && !slices.Contains(networkIDs, a.NetworkID) {
+				networkIDs = append(networkIDs, a.NetworkID)
+			}
+			rtype := "A"
+			if addr.Is6() {
+				rtype = "AAAA"
+			}
+			for _, name := range names {
+				key := name + " " + rtype + " " + addr.String()
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				records = append(records, zoneRecord{name: relativeName(name, domain), rtype: rtype, value: addr.String(), addr: addr})
+			}
+			// One PTR per address, pointing at the device's first name
+			if opts.IncludePTR && !ptrSeen[addr] {
+				ptrSeen[addr] = true
+				ptrs = append(ptrs, zoneRecord{name: s.extractPTRName(addr.String()), rtype: "PTR", value: names[0] + ".", addr: addr})
+			}
+		}
+	}
+
+	slices.SortFunc(records, func(a, b zoneRecord) int {
+		return cmp.Or(compareZoneNames(a.name, b.name), cmp.Compare(a.rtype, b.rtype), a.addr.Compare(b.addr))
+	})
+	slices.SortFunc(ptrs, func(a, b zoneRecord) int { return a.addr.Compare(b.addr) })
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "; Zone fragment for %s generated by rackd from device addresses and domains.\n", domain)
+	fmt.Fprintf(&buf, "; Include it from a zone file that defines the SOA and NS records.\n")
+	if notes, err := s.zoneNetworkNotes(ctx, networkIDs); err != nil {
+		return "", err
+	} else if len(notes) > 0 {
+		fmt.Fprintf(&buf, ";\n; Networks of these addresses:\n")
+		for _, note := range notes {
+			fmt.Fprintf(&buf, ";   %s\n", note)
+		}
+	}
+	fmt.Fprintf(&buf, "$ORIGIN %s.\n$TTL %d\n", domain, opts.TTL)
+	if len(records) > 0 {
+		buf.WriteByte('\n')
+		writeZoneRecords(&buf, records)
+	}
+	if len(ptrs) > 0 {
+		fmt.Fprintf(&buf, "\n; Reverse records for the matching in-addr.arpa and ip6.arpa zones\n")
+		writeZoneRecords(&buf, ptrs)
+	}
+	return buf.String(), nil
+}
+
+// zoneNetworkNotes describes the VLAN, gateway, DNS servers and MTU of the
+// networks the exported addresses are in, for the fragment's header. They
+// are left out for users who cannot list networks.
+func (s *DNSService) zoneNetworkNotes(ctx context.Context, networkIDs []string) ([]string, error) {
+	if len(networkIDs) == 0 || requirePermission(ctx, s.store, "networks", "list") != nil {
+		return nil, nil
+	}
+	var networks []*model.Network
+	for _, id := range networkIDs {
+		network, err := s.store.GetNetwork(ctx, id)
+		if errors.Is(err, storage.ErrNetworkNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	slices.SortFunc(networks, func(a, b *model.Network) int { return cmp.Compare(a.Name, b.Name) })
+
+	var notes []string
+	for _, n := range networks {
+		var settings []string
+		if n.VLANID > 0 {
+			settings = append(settings, fmt.Sprintf("VLAN %d", n.VLANID))
+		}
+		if n.Gateway != "" {
+			settings = append(settings, "gateway "+n.Gateway)
+		}
+		if len(n.DNSServers) > 0 {
+			settings = append(settings, "DNS "+strings.Join(n.DNSServers, " "))
+		}
+		if n.MTU > 0 {
+			settings = append(settings, fmt.Sprintf("MTU %d", n.MTU))
+		}
+		if len(settings) > 0 {
+			notes = append(notes, fmt.Sprintf("%s %s: %s", n.Name, n.Subnet, strings.Join(settings, ", ")))
+		}
+	}
+	return notes, nil
+}
+
+// zoneNames returns the fully qualified names of a device that lie in domain,
+// hostname first
+func zoneNames(d *model.Device, domain string) []string {
+	var names []string
+	add := func(name string) {
+		name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+		if (name == domain || strings.HasSuffix(name, "."+domain)) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if strings.Contains(d.Hostname, ".") {
+		add(d.Hostname)
+	}
+	for _, name := range d.Domains {
+		add(name)
+	}
+	return names
+}
+
+// relativeName returns name relative to the zone origin
+func relativeName(name, domain string) string {
+	if name == domain {
+		return "@"
+	}
+	return strings.TrimSuffix(name, "."+domain)
+}
+
+// compareZoneNames sorts the zone apex first
+func compareZoneNames(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "@" {
+		return -1
+	}
+	if b == "@" {
+		return 1
+	}
+	return cmp.Compare(a, b)
+}
+
+func writeZoneRecords(buf *bytes.Buffer, records []zoneRecord) {
+	tw := tabwriter.NewWriter(buf, 0, 8, 1, ' ', 0)
+	for _, r := range records {
+		fmt.Fprintf(tw, "%s\tIN\t%s\t%s\n", r.name, r.rtype, r.value)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+const dryRunKey contextKey = "dry_run"
+
+// WithDryRun marks ctx so that creates and updates of devices, networks and
+// pools run all their validation but persist nothing
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey, true)
+}
+
+// IsDryRun reports whether ctx was marked with WithDryRun
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey).(bool)
+	return dryRun
+}
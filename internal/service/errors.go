@@ -3,17 +3,29 @@ package service
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/validate"
 )
 
 var (
-	ErrNotFound        = errors.New("not found")
-	ErrAlreadyExists   = errors.New("already exists")
-	ErrValidation      = errors.New("validation error")
-	ErrForbidden       = errors.New("forbidden")
-	ErrUnauthenticated = errors.New("unauthenticated")
-	ErrSystemRole      = errors.New("cannot modify system role")
-	ErrSelfDelete      = errors.New("cannot delete own account")
-	ErrIPNotAvailable  = errors.New("no IP addresses available")
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrValidation         = errors.New("validation error")
+	ErrForbidden          = errors.New("forbidden")
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrSystemRole         = errors.New("cannot modify system role")
+	ErrSelfDelete         = errors.New("cannot delete own account")
+	ErrIPNotAvailable     = errors.New("no IP addresses available")
+	ErrSubnetNotAvailable = errors.New("no subnet of the requested size available")
+	ErrQueryTooBroad      = errors.New("query too broad")
+	ErrInvalidQuery       = errors.New("invalid query")
+	ErrHasDependents      = errors.New("resource has dependents")
+	ErrPolicyViolation    = errors.New("policy violation")
+	ErrAmbiguousName      = errors.New("ambiguous name")
+	ErrChangeNotPending   = errors.New("change request is not pending")
+	ErrApprovalRequired   = errors.New("changes need approval; submit them through the REST API as change requests")
 )
 
 type ValidationError struct {
@@ -42,3 +54,89 @@ func (e ValidationErrors) Error() string {
 func (e ValidationErrors) Unwrap() error {
 	return ErrValidation
 }
+
+// QueryTooBroadError is returned when a search exceeds its cost limits. Hints
+// suggest how to narrow the query.
+type QueryTooBroadError struct {
+	Reason string
+	Hints  []string
+}
+
+func (e *QueryTooBroadError) Error() string {
+	return "query too broad: " + e.Reason
+}
+
+// Unwrap returns ErrQueryTooBroad so errors.Is(err, ErrQueryTooBroad) works.
+func (e *QueryTooBroadError) Unwrap() error {
+	return ErrQueryTooBroad
+}
+
+// InvalidQueryError is returned when a structured query does not parse.
+// Start and End are the character offsets of the part at fault.
+type InvalidQueryError struct {
+	Message string
+	Start   int
+	End     int
+}
+
+func (e *InvalidQueryError) Error() string {
+	return "invalid query: " + e.Message
+}
+
+// Unwrap returns ErrInvalidQuery so errors.Is(err, ErrInvalidQuery) works.
+func (e *InvalidQueryError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// DependentsError is returned when a delete with the deny policy finds records
+// that still depend on the resource. Dependents maps each kind of record to
+// how many there are.
+type DependentsError struct {
+	Resource   string
+	Dependents map[string]int
+	message    string
+}
+
+func (e *DependentsError) Error() string {
+	return e.message
+}
+
+// Unwrap returns ErrHasDependents so errors.Is(err, ErrHasDependents) works.
+func (e *DependentsError) Unwrap() error {
+	return ErrHasDependents
+}
+
+// PolicyViolationError is returned when a device breaks the configured
+// validation policies
+type PolicyViolationError struct {
+	Violations []validate.Violation
+}
+
+func (e *PolicyViolationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = fmt.Sprintf("%s: %s", v.Field, v.Message)
+	}
+	return "policy violation: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns ErrPolicyViolation so errors.Is(err, ErrPolicyViolation) works.
+func (e *PolicyViolationError) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// AmbiguousNameError is returned when a lookup by name matches more than one
+// device. Matches lists them so the caller can pick one by ID.
+type AmbiguousNameError struct {
+	Name    string
+	Matches []model.Device
+}
+
+func (e *AmbiguousNameError) Error() string {
+	return fmt.Sprintf("%d devices are named %q", len(e.Matches), e.Name)
+}
+
+// Unwrap returns ErrAmbiguousName so errors.Is(err, ErrAmbiguousName) works.
+func (e *AmbiguousNameError) Unwrap() error {
+	return ErrAmbiguousName
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/martinsuchenak/rackd/internal/credentials"
+	"github.com/martinsuchenak/rackd/internal/hypervisor"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// hypervisorAddressLabel marks the addresses a hypervisor sync manages
+const hypervisorAddressLabel = "hypervisor"
+
+// HypervisorService manages hypervisor connectors and syncs their hosts and
+// VMs into the device inventory. Synced devices carry a
+// "hypervisor:<connector>:host:<id>" or "hypervisor:<connector>:vm:<id>" tag
+// which matches them on later syncs, and each VM is linked to its host with
+// a hosted_on relationship. Connector secrets are stored encrypted.
+type HypervisorService struct {
+	store     storage.ExtendedStorage
+	encryptor *credentials.Encryptor
+	devices   *DeviceService
+	syncMu    sync.Mutex
+
+	// newConnector builds the client for a connector; tests replace it
+	newConnector func(c *model.HypervisorConnector, secret string) (hypervisor.Connector, error)
+}
+
+// NewHypervisorService creates a hypervisor connector service
+func NewHypervisorService(store storage.ExtendedStorage, encryptor *credentials.Encryptor, devices *DeviceService) *HypervisorService {
+	return &HypervisorService{
+		store:        store,
+		encryptor:    encryptor,
+		devices:      devices,
+		newConnector: newHypervisorConnector,
+	}
+}
+
+func newHypervisorConnector(c *model.HypervisorConnector, secret string) (hypervisor.Connector, error) {
+	switch c.Type {
+	case model.HypervisorTypeProxmox:
+		return hypervisor.NewProxmox(c.Endpoint, c.Username, secret, c.InsecureSkipVerify), nil
+	case model.HypervisorTypeVSphere:
+		return hypervisor.NewVSphere(c.Endpoint, c.Username, secret, c.InsecureSkipVerify), nil
+	}
+	return nil, fmt.Errorf("unknown hypervisor type: %s", c.Type)
+}
+
+func (s *HypervisorService) List(ctx context.Context, filter *model.HypervisorConnectorFilter) ([]model.HypervisorConnector, error) {
+	if err := requirePermission(ctx, s.store, "hypervisors", "list"); err != nil {
+		return nil, err
+	}
+	connectors, err := s.store.ListHypervisorConnectors(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range connectors {
+		connectors[i].Secret = ""
+	}
+	return connectors, nil
+}
+
+func (s *HypervisorService) Get(ctx context.Context, id string) (*model.HypervisorConnector, error) {
+	if err := requirePermission(ctx, s.store, "hypervisors", "read"); err != nil {
+		return nil, err
+	}
+	c, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.Secret = ""
+	return c, nil
+}
+
+func (s *HypervisorService) get(ctx context.Context, id string) (*model.HypervisorConnector, error) {
+	c, err := s.store.GetHypervisorConnector(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrHypervisorConnectorNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *HypervisorService) Create(ctx context.Context, req *model.CreateHypervisorConnectorRequest) (*model.HypervisorConnector, error) {
+	if err := requirePermission(ctx, s.store, "hypervisors", "create"); err != nil {
+		return nil, err
+	}
+
+	c := &model.HypervisorConnector{
+		Name:               strings.TrimSpace(req.Name),
+		Type:               req.Type,
+		Endpoint:           strings.TrimSpace(req.Endpoint),
+		Username:           strings.TrimSpace(req.Username),
+		InsecureSkipVerify: req.InsecureSkipVerify,
+		DatacenterID:       req.DatacenterID,
+		Enabled:            req.Enabled == nil || *req.Enabled,
+		Description:        req.Description,
+	}
+
+	var errs ValidationErrors
+	switch {
+	case c.Name == "":
+		errs = append(errs, ValidationError{Field: "name", Message: "Name is required"})
+	case strings.ContainsAny(c.Name, ": ,"):
+		errs = append(errs, ValidationError{Field: "name", Message: "Name cannot contain spaces, colons or commas; it is used in device tags"})
+	}
+	if !c.Type.IsValid() {
+		errs = append(errs, ValidationError{Field: "type", Message: "Type must be one of: proxmox, vsphere"})
+	}
+	if req.Secret == "" {
+		errs = append(errs, ValidationError{Field: "secret", Message: "Secret is required"})
+	}
+	errs = append(errs, s.validateSettings(ctx, c)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	secret, err := s.encryptor.Encrypt(req.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	c.Secret = secret
+
+	if err := s.store.CreateHypervisorConnector(enrichAuditCtx(ctx), c); err != nil {
+		if errors.Is(err, storage.ErrDuplicateConnectorName) {
+			return nil, ErrAlreadyExists
+		}
+		return nil, err
+	}
+	c.Secret = ""
+	return c, nil
+}
+
+// Update changes a connector's settings. The name cannot change since it is
+// part of the tags that match synced devices.
+func (s *HypervisorService) Update(ctx context.Context, id string, req *model.UpdateHypervisorConnectorRequest) (*model.HypervisorConnector, error) {
+	if err := requirePermission(ctx, s.store, "hypervisors", "update"); err != nil {
+		return nil, err
+	}
+
+	c, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Endpoint != nil {
+		c.Endpoint = strings.TrimSpace(*req.Endpoint)
+	}
+	if req.Username != nil {
+		c.Username = strings.TrimSpace(*req.Username)
+	}
+	if req.InsecureSkipVerify != nil {
+		c.InsecureSkipVerify = *req.InsecureSkipVerify
+	}
+	if req.DatacenterID != nil {
+		c.DatacenterID = *req.DatacenterID
+	}
+	if req.Enabled != nil {
+		c.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		c.Description = *req.Description
+	}
+	errs := s.validateSettings(ctx, c)
+	if req.Secret != nil {
+		if *req.Secret == "" {
+			errs = append(errs, ValidationError{Field: "secret", Message: "Secret cannot be empty"})
+		} else if c.Secret, err = s.encryptor.Encrypt(*req.Secret); err != nil {
+			return nil, fmt.Errorf("failed to encrypt secret: %w", err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := s.store.UpdateHypervisorConnector(enrichAuditCtx(ctx), c); err != nil {
+		if errors.Is(err, storage.ErrHypervisorConnectorNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	c.Secret = ""
+	return c, nil
+}
+
+// Delete removes a connector. The devices it synced are kept.
+func (s *HypervisorService) Delete(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, s.store, "hypervisors", "delete"); err != nil {
+		return err
+	}
+	if err := s.store.DeleteHypervisorConnector(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrHypervisorConnectorNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// validateSettings checks the fields shared by create and update
+func (s *HypervisorService) validateSettings(ctx context.Context, c *model.HypervisorConnector) ValidationErrors {
+	var errs ValidationErrors
+	if u, err := url.Parse(c.Endpoint); c.Endpoint == "" || err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		errs = append(errs, ValidationError{Field: "endpoint", Message: "Endpoint must be an http or https URL"})
+	}
+	if c.Username == "" {
+		errs = append(errs, ValidationError{Field: "username", Message: "Username is required (the API token ID for Proxmox)"})
+	}
+	if c.DatacenterID != "" {
+		if _, err := s.store.GetDatacenter(ctx, c.DatacenterID); err != nil {
+			errs = append(errs, ValidationError{Field: "datacenter_id", Message: "Datacenter not found"})
+		}
+	}
+	return errs
+}
+
+// Sync pulls the hosts and VMs of a connector into the inventory, whether
+// or not the connector is enabled. Devices that disappeared from the
+// hypervisor are reported as missing but never deleted.
+func (s *HypervisorService) Sync(ctx context.Context, id string) (*model.HypervisorSyncResult, error) {
+	if err := s.requireSync(ctx); err != nil {
+		return nil, err
+	}
+	c, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Overlapping syncs would create duplicate devices
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+	if err != nil {
+		return nil, err
+	}
+	result := s.syncConnector(ctx, c, devices)
+	return &result, nil
+}
+
+// SyncAll syncs every enabled connector
+func (s *HypervisorService) SyncAll(ctx context.Context) ([]model.HypervisorSyncResult, error) {
+	if err := s.requireSync(ctx); err != nil {
+		return nil, err
+	}
+	enabled := true
+	connectors, err := s.store.ListHypervisorConnectors(ctx, &model.HypervisorConnectorFilter{Enabled: &enabled})
+	if err != nil {
+		return nil, err
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	results := make([]model.HypervisorSyncResult, 0, len(connectors))
+	for i := range connectors {
+		// Each sync sees the devices the previous one created
+		devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, s.syncConnector(ctx, &connectors[i], devices))
+	}
+	return results, nil
+}
+
+func (s *HypervisorService) requireSync(ctx context.Context) error {
+	if err := requirePermission(ctx, s.store, "hypervisors", "sync"); err != nil {
+		return err
+	}
+	if err := requirePermission(ctx, s.store, "devices", "create"); err != nil {
+		return err
+	}
+	return requirePermission(ctx, s.store, "devices", "update")
+}
+
+func (s *HypervisorService) syncConnector(ctx context.Context, c *model.HypervisorConnector, devices []model.Device) model.HypervisorSyncResult {
+	result := newCloudSyncResult(c.Name)
+	inv, err := s.inventory(ctx, c)
+	if err != nil {
+		log.Warn("Hypervisor sync failed to read inventory", "connector", c.Name, "error", err)
+		result.Errors = append(result.Errors, err.Error())
+	} else {
+		s.syncInventory(ctx, c, inv, devices, &result)
+	}
+
+	syncErr := ""
+	if len(result.Errors) > 0 {
+		syncErr = result.Errors[0]
+	}
+	if err := s.store.RecordHypervisorSync(ctx, c.ID, result.SyncedAt, syncErr); err != nil {
+		log.Warn("Failed to record hypervisor sync", "connector", c.Name, "error", err)
+	}
+	return result
+}
+
+func (s *HypervisorService) inventory(ctx context.Context, c *model.HypervisorConnector) (*hypervisor.Inventory, error) {
+	secret, err := s.encryptor.Decrypt(c.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt connector secret: %w", err)
+	}
+	conn, err := s.newConnector(c, secret)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Inventory(ctx)
+}
+
+// syncInventory creates or updates a device for each host and VM, links each
+// VM to its host and reports the connector's devices that are gone
+func (s *HypervisorService) syncInventory(ctx context.Context, c *model.HypervisorConnector, inv *hypervisor.Inventory, devices []model.Device, result *model.HypervisorSyncResult) {
+	prefix := "hypervisor:" + c.Name + ":"
+	existing := make(map[string]*model.Device)
+	for i := range devices {
+		for _, tag := range devices[i].Tags {
+			if strings.HasPrefix(tag, prefix) {
+				existing[tag] = &devices[i]
+			}
+		}
+	}
+	seen := make(map[string]bool)
+
+	hostDevices := make(map[string]string, len(inv.Hosts))
+	for _, h := range inv.Hosts {
+		idTag := prefix + "host:" + h.ID
+		seen[idTag] = true
+		device, changed, err := s.syncDevice(ctx, c, existing[idTag], idTag, h.Name, model.DeviceKindPhysical, h.OS, h.IPs, nil)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", h.Name, err))
+			continue
+		}
+		hostDevices[h.ID] = device.ID
+		recordSync(result, existing[idTag] == nil, changed, device.Name)
+	}
+
+	for _, vm := range inv.VMs {
+		idTag := prefix + "vm:" + vm.ID
+		seen[idTag] = true
+		device, changed, err := s.syncDevice(ctx, c, existing[idTag], idTag, vm.Name, model.DeviceKindVM, vm.OS, vm.IPs, vm.Tags)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", vm.Name, err))
+			continue
+		}
+		if hostID, ok := hostDevices[vm.HostID]; ok {
+			moved, err := s.linkHost(ctx, device.ID, hostID)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", vm.Name, err))
+			}
+			changed = changed || moved
+		}
+		recordSync(result, existing[idTag] == nil, changed, device.Name)
+	}
+
+	for tag, device := range existing {
+		if !seen[tag] {
+			result.Missing = append(result.Missing, device.Name)
+		}
+	}
+	slices.Sort(result.Missing)
+
+	log.Info("Hypervisor sync completed", "connector", c.Name, "created", len(result.Created),
+		"updated", len(result.Updated), "unchanged", result.Unchanged, "missing", len(result.Missing), "errors", len(result.Errors))
+}
+
+func recordSync(result *model.HypervisorSyncResult, created, changed bool, name string) {
+	switch {
+	case created:
+		result.Created = append(result.Created, name)
+	case changed:
+		result.Updated = append(result.Updated, name)
+	default:
+		result.Unchanged++
+	}
+}
+
+// syncDevice creates the device for a host or VM, or updates the matched
+// device when the hypervisor reports something new. It returns the device
+// and whether an existing device changed.
+func (s *HypervisorService) syncDevice(ctx context.Context, c *model.HypervisorConnector, device *model.Device, idTag, name string, kind model.DeviceKind, os string, ips, extraTags []string) (*model.Device, bool, error) {
+	created := device == nil
+	if created {
+		device = &model.Device{Name: name, Kind: kind, Status: model.DeviceStatusActive}
+	} else {
+		// Listed devices lack custom fields, which the update would clear
+		var err error
+		if device, err = s.store.GetDevice(ctx, device.ID); err != nil {
+			return nil, false, err
+		}
+	}
+	updated := *device
+
+	// A host the user marked as a container host keeps that kind
+	if kind == model.DeviceKindVM || !updated.Kind.CanHostVMs() {
+		updated.Kind = kind
+	}
+	if os != "" {
+		updated.OS = os
+	}
+	if c.DatacenterID != "" {
+		updated.DatacenterID = c.DatacenterID
+	}
+
+	tags := append([]string{}, updated.Tags...)
+	for _, tag := range append([]string{"hypervisor", "hypervisor:" + c.Name, idTag}, extraTags...) {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	updated.Tags = tags
+	applyHypervisorAddresses(&updated, ips)
+
+	if created {
+		if err := s.devices.Create(ctx, &updated); err != nil {
+			return nil, false, err
+		}
+		return &updated, false, nil
+	}
+	if updated.Kind == device.Kind && cloudDeviceEqual(device, &updated) {
+		return device, false, nil
+	}
+	if err := s.devices.Update(ctx, &updated); err != nil {
+		return nil, false, err
+	}
+	return &updated, true, nil
+}
+
+// applyHypervisorAddresses adds the reported addresses and drops the
+// hypervisor-labelled ones no longer reported. Other addresses are kept.
+func applyHypervisorAddresses(device *model.Device, ips []string) {
+	want := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		want[ip] = true
+	}
+
+	var addrs []model.Address
+	have := make(map[string]bool)
+	for _, addr := range device.Addresses {
+		if !want[addr.IP] && addr.Label == hypervisorAddressLabel {
+			continue
+		}
+		have[addr.IP] = true
+		addrs = append(addrs, addr)
+	}
+	for _, ip := range ips {
+		if have[ip] {
+			continue
+		}
+		have[ip] = true
+		addrs = append(addrs, model.Address{IP: ip, Type: cloudAddressType(ip), Label: hypervisorAddressLabel})
+	}
+	device.Addresses = addrs
+}
+
+// linkHost makes hostID the VM's only host, replacing the hosted_on link of
+// a VM that migrated. It reports whether the link changed.
+func (s *HypervisorService) linkHost(ctx context.Context, vmID, hostID string) (bool, error) {
+	rels, err := s.store.GetRelationships(ctx, vmID)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range rels {
+		if r.Type != model.RelationshipHostedOn || r.ParentID != vmID {
+			continue
+		}
+		if r.ChildID == hostID {
+			return false, nil
+		}
+		if err := s.store.RemoveRelationship(enrichAuditCtx(ctx), vmID, r.ChildID, model.RelationshipHostedOn); err != nil {
+			return false, err
+		}
+	}
+	if err := s.store.AddRelationship(enrichAuditCtx(ctx), vmID, hostID, model.RelationshipHostedOn, ""); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/credentials"
+	"github.com/martinsuchenak/rackd/internal/hypervisor"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+type fakeHypervisorConnector struct {
+	inv *hypervisor.Inventory
+	err error
+}
+
+func (c *fakeHypervisorConnector) Inventory(_ context.Context) (*hypervisor.Inventory, error) {
+	return c.inv, c.err
+}
+
+// hypervisorTestStorage keeps connectors and relationships in memory
+type hypervisorTestStorage struct {
+	*cloudTestStorage
+	connectors map[string]*model.HypervisorConnector
+	rels       []model.DeviceRelationship
+}
+
+func newHypervisorTestStorage() *hypervisorTestStorage {
+	store := &hypervisorTestStorage{cloudTestStorage: newCloudTestStorage(), connectors: map[string]*model.HypervisorConnector{}}
+	for _, action := range []string{"list", "read", "create", "update", "delete", "sync"} {
+		store.setPermission("user-1", "hypervisors", action, true)
+	}
+	return store
+}
+
+func (s *hypervisorTestStorage) CreateHypervisorConnector(_ context.Context, c *model.HypervisorConnector) error {
+	for _, existing := range s.connectors {
+		if existing.Name == c.Name {
+			return storage.ErrDuplicateConnectorName
+		}
+	}
+	c.ID = "hv-" + c.Name
+	stored := *c
+	s.connectors[c.ID] = &stored
+	return nil
+}
+
+func (s *hypervisorTestStorage) GetHypervisorConnector(_ context.Context, id string) (*model.HypervisorConnector, error) {
+	c, ok := s.connectors[id]
+	if !ok {
+		return nil, storage.ErrHypervisorConnectorNotFound
+	}
+	cloned := *c
+	return &cloned, nil
+}
+
+func (s *hypervisorTestStorage) ListHypervisorConnectors(_ context.Context, filter *model.HypervisorConnectorFilter) ([]model.HypervisorConnector, error) {
+	var connectors []model.HypervisorConnector
+	for _, c := range s.connectors {
+		if filter != nil && filter.Enabled != nil && c.Enabled != *filter.Enabled {
+			continue
+		}
+		connectors = append(connectors, *c)
+	}
+	slices.SortFunc(connectors, func(a, b model.HypervisorConnector) int { return strings.Compare(a.Name, b.Name) })
+	return connectors, nil
+}
+
+func (s *hypervisorTestStorage) UpdateHypervisorConnector(_ context.Context, c *model.HypervisorConnector) error {
+	if _, ok := s.connectors[c.ID]; !ok {
+		return storage.ErrHypervisorConnectorNotFound
+	}
+	stored := *c
+	s.connectors[c.ID] = &stored
+	return nil
+}
+
+func (s *hypervisorTestStorage) RecordHypervisorSync(_ context.Context, id string, syncedAt time.Time, syncErr string) error {
+	c, ok := s.connectors[id]
+	if !ok {
+		return storage.ErrHypervisorConnectorNotFound
+	}
+	c.LastSyncAt = &syncedAt
+	c.LastSyncError = syncErr
+	return nil
+}
+
+func (s *hypervisorTestStorage) GetRelationships(_ context.Context, deviceID string) ([]model.DeviceRelationship, error) {
+	var rels []model.DeviceRelationship
+	for _, r := range s.rels {
+		if r.ParentID == deviceID || r.ChildID == deviceID {
+			rels = append(rels, r)
+		}
+	}
+	return rels, nil
+}
+
+func (s *hypervisorTestStorage) AddRelationship(_ context.Context, parentID, childID, relationshipType, notes string) error {
+	s.rels = append(s.rels, model.DeviceRelationship{ParentID: parentID, ChildID: childID, Type: relationshipType, Notes: notes})
+	return nil
+}
+
+func (s *hypervisorTestStorage) RemoveRelationship(_ context.Context, parentID, childID, relationshipType string) error {
+	s.rels = slices.DeleteFunc(s.rels, func(r model.DeviceRelationship) bool {
+		return r.ParentID == parentID && r.ChildID == childID && r.Type == relationshipType
+	})
+	return nil
+}
+
+func newTestHypervisorService(t *testing.T, store *hypervisorTestStorage, conn hypervisor.Connector) *HypervisorService {
+	t.Helper()
+	encryptor, err := credentials.NewEncryptor([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewEncryptor failed: %v", err)
+	}
+	svc := NewHypervisorService(store, encryptor, NewDeviceService(store))
+	svc.newConnector = func(_ *model.HypervisorConnector, secret string) (hypervisor.Connector, error) {
+		if secret != "token-secret" {
+			t.Errorf("expected decrypted secret, got %q", secret)
+		}
+		return conn, nil
+	}
+	return svc
+}
+
+func createTestConnector(t *testing.T, svc *HypervisorService, ctx context.Context) *model.HypervisorConnector {
+	t.Helper()
+	c, err := svc.Create(ctx, &model.CreateHypervisorConnectorRequest{Name: "pve", Type: model.HypervisorTypeProxmox,
+		Endpoint: "https://pve1:8006", Username: "root@pam!rackd", Secret: "token-secret"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	return c
+}
+
+func TestHypervisorService_CreateValidation(t *testing.T) {
+	store := newHypervisorTestStorage()
+	svc := newTestHypervisorService(t, store, &fakeHypervisorConnector{})
+	ctx := userContext("user-1")
+
+	_, err := svc.Create(ctx, &model.CreateHypervisorConnectorRequest{Name: "my pve", Type: "xen", Endpoint: "pve1:8006"})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	fields := map[string]bool{}
+	for _, e := range verrs {
+		fields[e.Field] = true
+	}
+	for _, field := range []string{"name", "type", "secret", "endpoint", "username"} {
+		if !fields[field] {
+			t.Errorf("expected a %s validation error, got %v", field, verrs)
+		}
+	}
+
+	c := createTestConnector(t, svc, ctx)
+	if c.Secret != "" || !c.Enabled {
+		t.Errorf("expected an enabled connector without its secret, got %+v", c)
+	}
+	if stored := store.connectors[c.ID]; stored.Secret == "" || stored.Secret == "token-secret" {
+		t.Errorf("expected the secret to be stored encrypted, got %q", stored.Secret)
+	}
+	if _, err := svc.Create(ctx, &model.CreateHypervisorConnectorRequest{Name: "pve", Type: model.HypervisorTypeProxmox,
+		Endpoint: "https://pve2:8006", Username: "root@pam!rackd", Secret: "x"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got %v", err)
+	}
+}
+
+func TestHypervisorService_SyncHostsAndVMs(t *testing.T) {
+	store := newHypervisorTestStorage()
+	conn := &fakeHypervisorConnector{inv: &hypervisor.Inventory{
+		Hosts: []hypervisor.Host{
+			{ID: "pve1", Name: "pve1", OS: "Proxmox VE", IPs: []string{"10.0.0.11"}},
+			{ID: "pve2", Name: "pve2", OS: "Proxmox VE", IPs: []string{"10.0.0.12"}},
+		},
+		VMs: []hypervisor.VM{
+			{ID: "100", Name: "web-1", HostID: "pve1", OS: "Debian", IPs: []string{"10.0.1.5"}, Tags: []string{"prod"}},
+		},
+	}}
+	svc := newTestHypervisorService(t, store, conn)
+	ctx := userContext("user-1")
+	c := createTestConnector(t, svc, ctx)
+
+	result, err := svc.Sync(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Created) != 3 || len(result.Errors) != 0 || result.Provider != "pve" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	host := store.deviceByName("pve1")
+	vm := store.deviceByName("web-1")
+	if host == nil || host.Kind != model.DeviceKindPhysical || !slices.Contains(host.Tags, "hypervisor:pve:host:pve1") {
+		t.Errorf("unexpected host device: %+v", host)
+	}
+	if vm == nil || vm.Kind != model.DeviceKindVM || vm.OS != "Debian" || !slices.Contains(vm.Tags, "prod") ||
+		len(vm.Addresses) != 1 || vm.Addresses[0].Label != hypervisorAddressLabel {
+		t.Fatalf("unexpected VM device: %+v", vm)
+	}
+	if len(store.rels) != 1 || store.rels[0].ParentID != vm.ID || store.rels[0].ChildID != host.ID || store.rels[0].Type != model.RelationshipHostedOn {
+		t.Errorf("expected the VM hosted on pve1, got %+v", store.rels)
+	}
+	if stored := store.connectors[c.ID]; stored.LastSyncAt == nil || stored.LastSyncError != "" {
+		t.Errorf("expected a successful sync to be recorded, got %+v", stored)
+	}
+
+	// The VM migrates to pve2 and pve1 disappears
+	conn.inv.Hosts = conn.inv.Hosts[1:]
+	conn.inv.VMs[0].HostID = "pve2"
+	result, err = svc.Sync(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("second Sync failed: %v", err)
+	}
+	if len(result.Created) != 0 || !slices.Equal(result.Updated, []string{"web-1"}) || result.Unchanged != 1 ||
+		!slices.Equal(result.Missing, []string{"pve1"}) {
+		t.Errorf("unexpected second result: %+v", result)
+	}
+	pve2 := store.deviceByName("pve2")
+	if len(store.rels) != 1 || store.rels[0].ChildID != pve2.ID {
+		t.Errorf("expected the VM moved to pve2, got %+v", store.rels)
+	}
+}
+
+func TestHypervisorService_SyncRecordsErrors(t *testing.T) {
+	store := newHypervisorTestStorage()
+	svc := newTestHypervisorService(t, store, &fakeHypervisorConnector{err: errors.New("401 Unauthorized")})
+	ctx := userContext("user-1")
+	c := createTestConnector(t, svc, ctx)
+
+	results, err := svc.SyncAll(ctx)
+	if err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Errors) != 1 {
+		t.Fatalf("expected one failed sync, got %+v", results)
+	}
+	if stored := store.connectors[c.ID]; stored.LastSyncError != "401 Unauthorized" {
+		t.Errorf("expected the error to be recorded, got %q", stored.LastSyncError)
+	}
+
+	// Disabled connectors are skipped
+	disabled := false
+	if _, err := svc.Update(ctx, c.ID, &model.UpdateHypervisorConnectorRequest{Enabled: &disabled}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if results, _ := svc.SyncAll(ctx); len(results) != 0 {
+		t.Errorf("expected no syncs, got %+v", results)
+	}
+
+	if _, err := svc.Sync(userContext("user-2"), c.ID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+}
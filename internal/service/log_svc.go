@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	appLog "github.com/martinsuchenak/rackd/internal/log"
@@ -11,6 +12,10 @@ import (
 	"github.com/martinsuchenak/rackd/internal/storage"
 )
 
+// logStreamBuffer is how many entries a stream may fall behind before entries
+// are dropped
+const logStreamBuffer = 256
+
 type LogService struct {
 	store storage.ExtendedStorage
 }
@@ -53,6 +58,32 @@ func (s *LogService) Export(ctx context.Context, filter *model.LogFilter, format
 	}
 }
 
+// Stream returns up to backlog recent entries at or above minLevel, oldest
+// first, and a channel of entries logged from then on. The caller must call
+// the returned function to end the subscription. Streaming is limited to
+// admins because entries can include details of any request.
+func (s *LogService) Stream(ctx context.Context, minLevel string, backlog int) ([]model.LogEntry, <-chan model.LogEntry, func(), error) {
+	if err := requireAdmin(ctx, s.store); err != nil {
+		return nil, nil, nil, err
+	}
+	if minLevel != "" && !appLog.ValidLevel(minLevel) {
+		return nil, nil, nil, ValidationErrors{{Field: "level", Message: "level must be one of trace, debug, info, warn, error, fatal"}}
+	}
+
+	// Subscribe before reading the backlog so no entry is missed in between;
+	// callers skip streamed entries already present in the backlog.
+	entries, cancel := appLog.Subscribe(minLevel, logStreamBuffer)
+	recent := []model.LogEntry{}
+	if backlog > 0 {
+		recent = appLog.ListRecentEntries(&model.LogFilter{
+			Pagination: model.Pagination{Limit: backlog},
+			MinLevel:   minLevel,
+		})
+		slices.Reverse(recent)
+	}
+	return recent, entries, cancel, nil
+}
+
 func exportLogEntriesCSV(entries []model.LogEntry) []byte {
 	var b strings.Builder
 	b.WriteString("id,timestamp,level,source,message,fields\n")
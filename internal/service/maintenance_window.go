@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// MaintenanceService manages maintenance windows. A window covers a single
+// device or every device with a tag; while it is active the monitor and
+// discovery leave the device out of their problem reports. Window management
+// uses the devices permissions.
+type MaintenanceService struct {
+	store storage.ExtendedStorage
+}
+
+func NewMaintenanceService(store storage.ExtendedStorage) *MaintenanceService {
+	return &MaintenanceService{store: store}
+}
+
+func (s *MaintenanceService) List(ctx context.Context, filter *model.MaintenanceWindowFilter) ([]model.MaintenanceWindow, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+	if filter != nil {
+		switch filter.Status {
+		case "", model.MaintenanceActive, model.MaintenanceUpcoming, model.MaintenancePast, model.MaintenanceCurrent:
+		default:
+			return nil, ValidationErrors{{Field: "status", Message: "Status must be active, upcoming, past or current"}}
+		}
+	}
+	return s.store.ListMaintenanceWindows(ctx, filter)
+}
+
+func (s *MaintenanceService) Get(ctx context.Context, id string) (*model.MaintenanceWindow, error) {
+	if err := requirePermission(ctx, s.store, "devices", "read"); err != nil {
+		return nil, err
+	}
+
+	window, err := s.store.GetMaintenanceWindow(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrMaintenanceWindowNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return window, nil
+}
+
+func (s *MaintenanceService) Create(ctx context.Context, window *model.MaintenanceWindow) error {
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return err
+	}
+	if err := s.validate(ctx, window); err != nil {
+		return err
+	}
+	window.CreatedBy = ""
+	if caller := CallerFrom(ctx); caller != nil {
+		window.CreatedBy = caller.UserID
+	}
+	return s.store.CreateMaintenanceWindow(enrichAuditCtx(ctx), window)
+}
+
+func (s *MaintenanceService) Update(ctx context.Context, window *model.MaintenanceWindow) error {
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return err
+	}
+	if window.ID == "" {
+		return ValidationErrors{{Field: "id", Message: "ID is required"}}
+	}
+	if err := s.validate(ctx, window); err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateMaintenanceWindow(enrichAuditCtx(ctx), window); err != nil {
+		if errors.Is(err, storage.ErrMaintenanceWindowNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *MaintenanceService) Delete(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteMaintenanceWindow(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrMaintenanceWindowNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *MaintenanceService) validate(ctx context.Context, window *model.MaintenanceWindow) error {
+	var errs ValidationErrors
+	window.Tag = strings.TrimSpace(window.Tag)
+	window.Description = strings.TrimSpace(window.Description)
+
+	switch {
+	case window.DeviceID == "" && window.Tag == "":
+		errs = append(errs, ValidationError{Field: "device_id", Message: "Either a device ID or a tag is required"})
+	case window.DeviceID != "" && window.Tag != "":
+		errs = append(errs, ValidationError{Field: "tag", Message: "A maintenance window covers either a device or a tag, not both"})
+	case window.DeviceID != "":
+		if _, err := s.store.GetDevice(ctx, window.DeviceID); err != nil {
+			if !errors.Is(err, storage.ErrDeviceNotFound) {
+				return err
+			}
+			errs = append(errs, ValidationError{Field: "device_id", Message: "Device not found"})
+		}
+	}
+
+	if window.StartsAt.IsZero() {
+		errs = append(errs, ValidationError{Field: "starts_at", Message: "Start time is required"})
+	}
+	if window.EndsAt.IsZero() {
+		errs = append(errs, ValidationError{Field: "ends_at", Message: "End time is required"})
+	} else if !window.EndsAt.After(window.StartsAt) {
+		errs = append(errs, ValidationError{Field: "ends_at", Message: "End time must be after the start time"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// listCurrentMaintenance returns every active and upcoming maintenance window
+func listCurrentMaintenance(ctx context.Context, store storage.MaintenanceWindowStorage) ([]model.MaintenanceWindow, error) {
+	var all []model.MaintenanceWindow
+	filter := model.MaintenanceWindowFilter{Status: model.MaintenanceCurrent}
+	filter.Limit = model.MaxPageSize
+	for {
+		page, err := store.ListMaintenanceWindows(ctx, &filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < model.MaxPageSize {
+			return all, nil
+		}
+		filter.Offset += len(page)
+	}
+}
+
+// inMaintenance returns the IDs of the devices that an active maintenance
+// window covers
+func inMaintenance(ctx context.Context, store storage.MaintenanceWindowStorage, devices []model.Device) (map[string]bool, error) {
+	windows, err := listCurrentMaintenance(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	now := time.Now()
+	for _, w := range windows {
+		if w.StatusAt(now) != model.MaintenanceActive {
+			continue
+		}
+		for i := range devices {
+			if w.Covers(&devices[i]) {
+				ids[devices[i].ID] = true
+			}
+		}
+	}
+	return ids, nil
+}
+
+// attachMaintenance sets the active and upcoming maintenance windows on the
+// devices they cover
+func attachMaintenance(ctx context.Context, store storage.MaintenanceWindowStorage, devices []model.Device) error {
+	if len(devices) == 0 {
+		return nil
+	}
+	windows, err := listCurrentMaintenance(ctx, store)
+	if err != nil {
+		return err
+	}
+	for _, w := range windows {
+		for i := range devices {
+			if w.Covers(&devices[i]) {
+				devices[i].Maintenance = append(devices[i].Maintenance, w)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// maintenanceTestStorage records created windows on top of the monitor test
+// storage, which provides device lookups
+type maintenanceTestStorage struct {
+	*monitorTestStorage
+	created *model.MaintenanceWindow
+}
+
+func (s *maintenanceTestStorage) CreateMaintenanceWindow(_ context.Context, w *model.MaintenanceWindow) error {
+	w.ID = "mw-1"
+	s.created = w
+	return nil
+}
+
+func TestMaintenanceService_Validate(t *testing.T) {
+	store := &maintenanceTestStorage{monitorTestStorage: newMonitorTestStorage()}
+	addMonitorDevice(store.monitorTestStorage, "web1", "10.0.0.1")
+	svc := NewMaintenanceService(store)
+	ctx := userContext("user-1")
+
+	start := time.Now().Add(time.Hour)
+	end := start.Add(2 * time.Hour)
+	tests := []struct {
+		name   string
+		window model.MaintenanceWindow
+		field  string
+	}{
+		{"no target", model.MaintenanceWindow{StartsAt: start, EndsAt: end}, "device_id"},
+		{"both targets", model.MaintenanceWindow{DeviceID: "web1", Tag: "prod", StartsAt: start, EndsAt: end}, "tag"},
+		{"unknown device", model.MaintenanceWindow{DeviceID: "missing", StartsAt: start, EndsAt: end}, "device_id"},
+		{"no start", model.MaintenanceWindow{DeviceID: "web1", EndsAt: end}, "starts_at"},
+		{"end before start", model.MaintenanceWindow{DeviceID: "web1", StartsAt: end, EndsAt: start}, "ends_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := tt.window
+			err := svc.Create(ctx, &window)
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) || verrs[0].Field != tt.field {
+				t.Errorf("expected validation error on %s, got %v", tt.field, err)
+			}
+		})
+	}
+
+	window := &model.MaintenanceWindow{Tag: " prod ", StartsAt: start, EndsAt: end, Description: "Patching"}
+	if err := svc.Create(ctx, window); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if store.created.Tag != "prod" || store.created.CreatedBy != "user-1" {
+		t.Errorf("expected trimmed tag and creator, got %+v", store.created)
+	}
+
+	if _, err := svc.List(ctx, &model.MaintenanceWindowFilter{Status: "soon"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected validation error for unknown status, got %v", err)
+	}
+	if err := svc.Create(userContext("user-2"), window); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestMaintenance_SuppressesMonitorProblems(t *testing.T) {
+	store := newMonitorTestStorage()
+	addMonitorDevice(store, "web1", "10.0.0.1", "prod")
+	addMonitorDevice(store, "web2", "10.0.0.2", "prod", "rack-a")
+	addMonitorDevice(store, "web3", "10.0.0.3", "prod")
+	store.checks = []model.MonitorCheck{
+		{ID: "check-ping", Name: "ping", Tag: "prod", Type: model.MonitorCheckICMP, IntervalSeconds: 60, TimeoutSeconds: 1, Enabled: true},
+	}
+	now := time.Now()
+	store.maintenanceWindows = []model.MaintenanceWindow{
+		{ID: "mw-active", Tag: "rack-a", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+		{ID: "mw-upcoming", DeviceID: "web3", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)},
+		{ID: "mw-past", DeviceID: "web3", StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)},
+	}
+	svc := NewMonitorService(store)
+	svc.SetProber(&fakeProber{down: map[string]bool{"10.0.0.2": true, "10.0.0.3": true}})
+
+	if _, err := svc.RunDue(SystemContext(context.Background(), "test")); err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+
+	summary, err := svc.Summary(userContext("user-1"))
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if summary.Total != 3 || summary.Up != 1 || summary.Down != 1 || summary.Maintenance != 1 {
+		t.Errorf("unexpected summary counts: %+v", summary)
+	}
+	if len(summary.Problems) != 1 || summary.Problems[0].DeviceID != "web3" {
+		t.Errorf("expected only web3 as a problem, got %+v", summary.Problems)
+	}
+
+	devices := NewDeviceService(store)
+	device, err := devices.Get(userContext("user-1"), "web3")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(device.Maintenance) != 1 || device.Maintenance[0].ID != "mw-upcoming" || device.Maintenance[0].Status != model.MaintenanceUpcoming {
+		t.Errorf("expected the upcoming window on the device, got %+v", device.Maintenance)
+	}
+}
@@ -0,0 +1,532 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/monitor"
+	"github.com/martinsuchenak/rackd/internal/storage"
+	wh "github.com/martinsuchenak/rackd/internal/webhook"
+)
+
+const (
+	defaultMonitorInterval = 60
+	minMonitorInterval     = 10
+	defaultMonitorTimeout  = 5
+	defaultMonitorWorkers  = 20
+)
+
+// MonitorService manages availability checks and runs them. Checks target a
+// single device or every device with a tag; each run probes the device's
+// first address and keeps only the latest result. Check management uses the
+// devices permissions. A device going down or coming back up outside
+// maintenance publishes a monitor.device_down or monitor.device_up event.
+type MonitorService struct {
+	store   storage.ExtendedStorage
+	prober  monitor.Prober
+	workers int
+
+	// publish sends events to the event bus; tests replace it
+	publish func(eventType model.EventType, payload interface{})
+
+	mu      sync.Mutex
+	lastRun map[string]time.Time
+}
+
+func NewMonitorService(store storage.ExtendedStorage) *MonitorService {
+	return &MonitorService{
+		store:   store,
+		prober:  monitor.NewNetProber(),
+		workers: defaultMonitorWorkers,
+		publish: wh.Publish,
+		lastRun: make(map[string]time.Time),
+	}
+}
+
+// SetProber replaces the prober used to run checks
+func (s *MonitorService) SetProber(p monitor.Prober) {
+	s.prober = p
+}
+
+// SetWorkers limits how many probes run concurrently
+func (s *MonitorService) SetWorkers(n int) {
+	if n > 0 {
+		s.workers = n
+	}
+}
+
+func (s *MonitorService) ListChecks(ctx context.Context) ([]model.MonitorCheck, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+	return s.store.ListMonitorChecks(ctx)
+}
+
+func (s *MonitorService) GetCheck(ctx context.Context, id string) (*model.MonitorCheck, error) {
+	if err := requirePermission(ctx, s.store, "devices", "read"); err != nil {
+		return nil, err
+	}
+
+	check, err := s.store.GetMonitorCheck(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrMonitorCheckNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return check, nil
+}
+
+func (s *MonitorService) CreateCheck(ctx context.Context, check *model.MonitorCheck) error {
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return err
+	}
+	if err := s.validateCheck(ctx, check); err != nil {
+		return err
+	}
+	return s.store.CreateMonitorCheck(enrichAuditCtx(ctx), check)
+}
+
+func (s *MonitorService) UpdateCheck(ctx context.Context, check *model.MonitorCheck) error {
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return err
+	}
+	if check.ID == "" {
+		return ValidationErrors{{Field: "id", Message: "ID is required"}}
+	}
+	if err := s.validateCheck(ctx, check); err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateMonitorCheck(enrichAuditCtx(ctx), check); err != nil {
+		if errors.Is(err, storage.ErrMonitorCheckNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	// Run the changed check on the next tick
+	s.mu.Lock()
+	delete(s.lastRun, check.ID)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MonitorService) DeleteCheck(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteMonitorCheck(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrMonitorCheckNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *MonitorService) validateCheck(ctx context.Context, check *model.MonitorCheck) error {
+	var errs ValidationErrors
+	check.Name = strings.TrimSpace(check.Name)
+	check.Tag = strings.TrimSpace(check.Tag)
+	if check.Name == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "Name is required"})
+	}
+
+	switch {
+	case check.DeviceID == "" && check.Tag == "":
+		errs = append(errs, ValidationError{Field: "device_id", Message: "Either a device ID or a tag is required"})
+	case check.DeviceID != "" && check.Tag != "":
+		errs = append(errs, ValidationError{Field: "tag", Message: "A check targets either a device or a tag, not both"})
+	case check.DeviceID != "":
+		if _, err := s.store.GetDevice(ctx, check.DeviceID); err != nil {
+			if !errors.Is(err, storage.ErrDeviceNotFound) {
+				return err
+			}
+			errs = append(errs, ValidationError{Field: "device_id", Message: "Device not found"})
+		}
+	}
+
+	switch check.Type {
+	case model.MonitorCheckICMP:
+		check.Port = 0
+	case model.MonitorCheckTCP:
+		if check.Port < 1 || check.Port > 65535 {
+			errs = append(errs, ValidationError{Field: "port", Message: "Port must be between 1 and 65535"})
+		}
+	default:
+		errs = append(errs, ValidationError{Field: "type", Message: "Type must be icmp or tcp"})
+	}
+
+	if check.IntervalSeconds == 0 {
+		check.IntervalSeconds = defaultMonitorInterval
+	}
+	if check.TimeoutSeconds == 0 {
+		check.TimeoutSeconds = defaultMonitorTimeout
+	}
+	if check.IntervalSeconds < minMonitorInterval {
+		errs = append(errs, ValidationError{Field: "interval_seconds", Message: "Interval must be at least 10 seconds"})
+	}
+	if check.TimeoutSeconds < 1 || check.TimeoutSeconds >= check.IntervalSeconds {
+		errs = append(errs, ValidationError{Field: "timeout_seconds", Message: "Timeout must be at least 1 second and shorter than the interval"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// DeviceStatus returns the latest check results for a device. A device no
+// check has run against yet has status unknown.
+func (s *MonitorService) DeviceStatus(ctx context.Context, deviceID string) (*model.DeviceMonitoring, error) {
+	if err := requirePermission(ctx, s.store, "devices", "read"); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetDevice(ctx, deviceID); err != nil {
+		if errors.Is(err, storage.ErrDeviceNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	results, err := s.store.ListMonitorResults(ctx, []string{deviceID})
+	if err != nil {
+		return nil, err
+	}
+	return summarizeMonitorResults(results), nil
+}
+
+// Summary counts monitored devices by status. Devices targeted by an enabled
+// check that has not run against them yet count as unknown. Devices in an
+// active maintenance window count as in maintenance and are not problems.
+func (s *MonitorService) Summary(ctx context.Context) (*model.MonitorSummary, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return nil, err
+	}
+
+	checks, err := s.store.ListMonitorChecks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.store.ListMonitorResults(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+	if err != nil {
+		return nil, err
+	}
+	maintenance, err := inMaintenance(ctx, s.store, devices)
+	if err != nil {
+		return nil, err
+	}
+
+	byDevice := make(map[string][]model.MonitorResult)
+	for _, r := range results {
+		byDevice[r.DeviceID] = append(byDevice[r.DeviceID], r)
+	}
+
+	summary := &model.MonitorSummary{Problems: []model.MonitorDeviceStatus{}, CheckedAt: time.Now().UTC()}
+	for _, d := range devices {
+		deviceResults, hasResults := byDevice[d.ID]
+		if !hasResults && !monitoredByAny(checks, &d) {
+			continue
+		}
+
+		m := summarizeMonitorResults(deviceResults)
+		summary.Total++
+		if maintenance[d.ID] {
+			summary.Maintenance++
+			continue
+		}
+		switch m.Status {
+		case model.MonitorStatusUp:
+			summary.Up++
+			continue
+		case model.MonitorStatusDown:
+			summary.Down++
+		case model.MonitorStatusDegraded:
+			summary.Degraded++
+		default:
+			summary.Unknown++
+		}
+
+		status := model.MonitorDeviceStatus{DeviceID: d.ID, DeviceName: d.Name, Status: m.Status, LastSeenAt: m.LastSeenAt}
+		for _, r := range deviceResults {
+			if r.Status == model.MonitorStatusDown && (status.Since.IsZero() || r.LastChangeAt.Before(status.Since)) {
+				status.Since = r.LastChangeAt
+			}
+		}
+		summary.Problems = append(summary.Problems, status)
+	}
+
+	// Longest-running outages first
+	slices.SortStableFunc(summary.Problems, func(a, b model.MonitorDeviceStatus) int {
+		return a.Since.Compare(b.Since)
+	})
+	return summary, nil
+}
+
+func monitoredByAny(checks []model.MonitorCheck, device *model.Device) bool {
+	if device.Status == model.DeviceStatusDecommissioned {
+		return false
+	}
+	for _, c := range checks {
+		if c.Enabled && (c.DeviceID == device.ID || (c.Tag != "" && slices.Contains(device.Tags, c.Tag))) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeMonitorResults derives a device's overall status: up when every
+// check passed, down when every check failed, degraded when mixed
+func summarizeMonitorResults(results []model.MonitorResult) *model.DeviceMonitoring {
+	m := &model.DeviceMonitoring{Status: model.MonitorStatusUnknown, Checks: []model.MonitorResult{}}
+	up, down := 0, 0
+	for _, r := range results {
+		m.Checks = append(m.Checks, r)
+		switch r.Status {
+		case model.MonitorStatusUp:
+			up++
+		case model.MonitorStatusDown:
+			down++
+		}
+		if r.LastUpAt != nil && (m.LastSeenAt == nil || r.LastUpAt.After(*m.LastSeenAt)) {
+			m.LastSeenAt = r.LastUpAt
+		}
+	}
+
+	switch {
+	case up > 0 && down > 0:
+		m.Status = model.MonitorStatusDegraded
+	case up > 0:
+		m.Status = model.MonitorStatusUp
+	case down > 0:
+		m.Status = model.MonitorStatusDown
+	}
+	return m
+}
+
+// attachMonitoring sets the monitoring summary on devices that have results
+func (s *MonitorService) attachMonitoring(ctx context.Context, devices []model.Device) error {
+	if len(devices) == 0 {
+		return nil
+	}
+	ids := make([]string, len(devices))
+	for i := range devices {
+		ids[i] = devices[i].ID
+	}
+	results, err := s.store.ListMonitorResults(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	byDevice := make(map[string][]model.MonitorResult)
+	for _, r := range results {
+		byDevice[r.DeviceID] = append(byDevice[r.DeviceID], r)
+	}
+	for i := range devices {
+		if deviceResults, ok := byDevice[devices[i].ID]; ok {
+			devices[i].Monitoring = summarizeMonitorResults(deviceResults)
+		}
+	}
+	return nil
+}
+
+// RunDue runs every enabled check whose interval has elapsed and returns the
+// number of probes made
+func (s *MonitorService) RunDue(ctx context.Context) (int, error) {
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return 0, err
+	}
+
+	checks, err := s.store.ListMonitorChecks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var due []model.MonitorCheck
+	s.mu.Lock()
+	for _, c := range checks {
+		if !c.Enabled {
+			continue
+		}
+		if last, ok := s.lastRun[c.ID]; ok && now.Sub(last) < time.Duration(c.IntervalSeconds)*time.Second {
+			continue
+		}
+		s.lastRun[c.ID] = now
+		due = append(due, c)
+	}
+	s.mu.Unlock()
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	previous, err := s.store.ListMonitorResults(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	prev := make(map[string]model.MonitorResult, len(previous))
+	for _, r := range previous {
+		prev[r.CheckID+"/"+r.DeviceID] = r
+	}
+
+	probes := 0
+	for _, check := range due {
+		targets, err := s.checkTargets(ctx, &check)
+		if err != nil {
+			log.Warn("Failed to resolve monitor check targets", "check", check.Name, "error", err)
+			continue
+		}
+		maintenance, err := inMaintenance(ctx, s.store, targets)
+		if err != nil {
+			log.Warn("Failed to load maintenance windows", "check", check.Name, "error", err)
+		}
+		probes += s.runCheck(ctx, &check, targets, prev, maintenance)
+	}
+	return probes, nil
+}
+
+func (s *MonitorService) checkTargets(ctx context.Context, check *model.MonitorCheck) ([]model.Device, error) {
+	var devices []model.Device
+	if check.DeviceID != "" {
+		device, err := s.store.GetDevice(ctx, check.DeviceID)
+		if err != nil {
+			return nil, err
+		}
+		devices = []model.Device{*device}
+	} else {
+		var err error
+		devices, err = listAllDevices(ctx, s.store, model.DeviceFilter{Tags: []string{check.Tag}})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	targets := devices[:0]
+	for _, d := range devices {
+		if d.Status != model.DeviceStatusDecommissioned {
+			targets = append(targets, d)
+		}
+	}
+	return targets, nil
+}
+
+// runCheck probes targets and saves the results. Availability changes of
+// devices in maintenance are expected and only logged at debug level.
+func (s *MonitorService) runCheck(ctx context.Context, check *model.MonitorCheck, targets []model.Device, prev map[string]model.MonitorResult, maintenance map[string]bool) int {
+	timeout := time.Duration(check.TimeoutSeconds) * time.Second
+	sem := make(chan struct{}, s.workers)
+	var wg sync.WaitGroup
+	keep := make([]string, 0, len(targets))
+
+	for i := range targets {
+		device := &targets[i]
+		keep = append(keep, device.ID)
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := model.MonitorResult{CheckID: check.ID, DeviceID: device.ID, IP: monitorAddress(device)}
+			now := time.Now().UTC()
+			if result.IP == "" {
+				result.Status = model.MonitorStatusUnknown
+				result.Message = "device has no IP address"
+			} else if rtt, err := s.prober.Probe(ctx, check.Type, result.IP, check.Port, timeout); err != nil {
+				result.Status = model.MonitorStatusDown
+				result.Message = err.Error()
+			} else {
+				result.Status = model.MonitorStatusUp
+				result.LatencyMs = float64(rtt.Microseconds()) / 1000
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			result.LastCheckedAt = now
+			result.LastChangeAt = now
+			if result.Status == model.MonitorStatusUp {
+				result.LastUpAt = &now
+			}
+			if p, ok := prev[check.ID+"/"+device.ID]; ok {
+				if result.LastUpAt == nil {
+					result.LastUpAt = p.LastUpAt
+				}
+				if p.Status == result.Status {
+					result.LastChangeAt = p.LastChangeAt
+				} else if maintenance[device.ID] {
+					log.Debug("Device availability changed during maintenance", "device", device.Name, "check", check.Name,
+						"from", p.Status, "to", result.Status)
+				} else {
+					log.Info("Device availability changed", "device", device.Name, "check", check.Name,
+						"from", p.Status, "to", result.Status)
+					s.publishAvailability(check, device, &result, p.Status)
+				}
+			}
+
+			if err := s.store.SaveMonitorResult(ctx, &result); err != nil {
+				log.Warn("Failed to save monitor result", "check", check.Name, "device", device.Name, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := s.store.PruneMonitorResults(ctx, check.ID, keep); err != nil {
+		log.Warn("Failed to prune monitor results", "check", check.Name, "error", err)
+	}
+	return len(targets)
+}
+
+// publishAvailability publishes a device going down, or coming back up after
+// being down. Changes to and from unknown, such as a device losing its
+// address, are not alerts.
+func (s *MonitorService) publishAvailability(check *model.MonitorCheck, device *model.Device, result *model.MonitorResult, previous string) {
+	var eventType model.EventType
+	switch {
+	case result.Status == model.MonitorStatusDown:
+		eventType = model.EventTypeDeviceDown
+	case result.Status == model.MonitorStatusUp && previous == model.MonitorStatusDown:
+		eventType = model.EventTypeDeviceUp
+	default:
+		return
+	}
+	s.publish(eventType, &model.EventPayloadAvailability{
+		DeviceID:       device.ID,
+		DeviceName:     device.Name,
+		CheckID:        check.ID,
+		CheckName:      check.Name,
+		CheckType:      check.Type,
+		Port:           check.Port,
+		IP:             result.IP,
+		Status:         result.Status,
+		PreviousStatus: previous,
+		Message:        result.Message,
+	})
+}
+
+// monitorAddress returns the first valid IP address of a device
+func monitorAddress(device *model.Device) string {
+	for _, addr := range device.Addresses {
+		if net.ParseIP(addr.IP) != nil {
+			return addr.IP
+		}
+	}
+	return ""
+}
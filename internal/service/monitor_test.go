@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// fakeProber reports the IPs in down as unreachable
+type fakeProber struct {
+	mu    sync.Mutex
+	down  map[string]bool
+	calls []string
+}
+
+func (p *fakeProber) Probe(_ context.Context, checkType, ip string, port int, _ time.Duration) (time.Duration, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, ip)
+	if p.down[ip] {
+		return 0, errors.New("timeout")
+	}
+	return 2 * time.Millisecond, nil
+}
+
+// monitorTestStorage keeps checks and results in memory and looks up devices,
+// which the shared service test storage does not
+type monitorTestStorage struct {
+	*serviceTestStorage
+	mu      sync.Mutex
+	checks  []model.MonitorCheck
+	results map[string]model.MonitorResult
+}
+
+func newMonitorTestStorage() *monitorTestStorage {
+	store := &monitorTestStorage{serviceTestStorage: newServiceTestStorage(), results: make(map[string]model.MonitorResult)}
+	store.setPermission("user-1", "devices", "update", true)
+	store.setPermission("user-1", "devices", "read", true)
+	store.setPermission("user-1", "devices", "list", true)
+	return store
+}
+
+func (s *monitorTestStorage) GetDevice(_ context.Context, id string) (*model.Device, error) {
+	if d, ok := s.devices[id]; ok {
+		cloned := *d
+		return &cloned, nil
+	}
+	return nil, storage.ErrDeviceNotFound
+}
+
+func (s *monitorTestStorage) ListDevices(_ context.Context, filter *model.DeviceFilter) ([]model.Device, error) {
+	if filter != nil && filter.Offset > 0 {
+		return nil, nil
+	}
+	var devices []model.Device
+	for _, d := range s.devices {
+		if filter != nil && len(filter.Tags) > 0 && !slices.Contains(d.Tags, filter.Tags[0]) {
+			continue
+		}
+		devices = append(devices, *d)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+	return devices, nil
+}
+
+func (s *monitorTestStorage) CreateMonitorCheck(_ context.Context, check *model.MonitorCheck) error {
+	check.ID = "check-" + check.Name
+	s.checks = append(s.checks, *check)
+	return nil
+}
+
+func (s *monitorTestStorage) ListMonitorChecks(_ context.Context) ([]model.MonitorCheck, error) {
+	return s.checks, nil
+}
+
+func (s *monitorTestStorage) SaveMonitorResult(_ context.Context, r *model.MonitorResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.checks {
+		if c.ID == r.CheckID {
+			r.CheckName = c.Name
+		}
+	}
+	s.results[r.CheckID+"/"+r.DeviceID] = *r
+	return nil
+}
+
+func (s *monitorTestStorage) ListMonitorResults(_ context.Context, deviceIDs []string) ([]model.MonitorResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var results []model.MonitorResult
+	for _, r := range s.results {
+		if len(deviceIDs) == 0 || slices.Contains(deviceIDs, r.DeviceID) {
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CheckName < results[j].CheckName })
+	return results, nil
+}
+
+func (s *monitorTestStorage) PruneMonitorResults(_ context.Context, checkID string, keep []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, r := range s.results {
+		if r.CheckID == checkID && !slices.Contains(keep, r.DeviceID) {
+			delete(s.results, key)
+		}
+	}
+	return nil
+}
+
+func addMonitorDevice(store *monitorTestStorage, id, ip string, tags ...string) {
+	device := &model.Device{ID: id, Name: id, Status: model.DeviceStatusActive, Tags: tags}
+	if ip != "" {
+		device.Addresses = []model.Address{{IP: ip}}
+	}
+	store.devices[id] = device
+}
+
+func TestMonitorService_ValidateCheck(t *testing.T) {
+	store := newMonitorTestStorage()
+	addMonitorDevice(store, "web1", "10.0.0.1")
+	svc := NewMonitorService(store)
+	ctx := userContext("user-1")
+
+	tests := []struct {
+		name  string
+		check model.MonitorCheck
+		field string
+	}{
+		{"no target", model.MonitorCheck{Name: "c", Type: model.MonitorCheckICMP}, "device_id"},
+		{"both targets", model.MonitorCheck{Name: "c", DeviceID: "web1", Tag: "prod", Type: model.MonitorCheckICMP}, "tag"},
+		{"unknown device", model.MonitorCheck{Name: "c", DeviceID: "missing", Type: model.MonitorCheckICMP}, "device_id"},
+		{"bad type", model.MonitorCheck{Name: "c", DeviceID: "web1", Type: "http"}, "type"},
+		{"tcp without port", model.MonitorCheck{Name: "c", DeviceID: "web1", Type: model.MonitorCheckTCP}, "port"},
+		{"interval too short", model.MonitorCheck{Name: "c", DeviceID: "web1", Type: model.MonitorCheckICMP, IntervalSeconds: 5}, "interval_seconds"},
+		{"timeout not below interval", model.MonitorCheck{Name: "c", DeviceID: "web1", Type: model.MonitorCheckICMP, IntervalSeconds: 30, TimeoutSeconds: 30}, "timeout_seconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := tt.check
+			err := svc.CreateCheck(ctx, &check)
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) || verrs[0].Field != tt.field {
+				t.Errorf("expected validation error on %s, got %v", tt.field, err)
+			}
+		})
+	}
+
+	check := &model.MonitorCheck{Name: "ping", DeviceID: "web1", Type: model.MonitorCheckICMP, Port: 80}
+	if err := svc.CreateCheck(ctx, check); err != nil {
+		t.Fatalf("CreateCheck failed: %v", err)
+	}
+	if check.IntervalSeconds != 60 || check.TimeoutSeconds != 5 || check.Port != 0 {
+		t.Errorf("expected defaults and cleared port, got %+v", check)
+	}
+}
+
+func TestMonitorService_RunDueAndStatus(t *testing.T) {
+	store := newMonitorTestStorage()
+	addMonitorDevice(store, "web1", "10.0.0.1", "prod")
+	addMonitorDevice(store, "web2", "10.0.0.2", "prod")
+	addMonitorDevice(store, "noip", "", "prod")
+	addMonitorDevice(store, "dev1", "10.0.1.1", "dev")
+	store.checks = []model.MonitorCheck{
+		{ID: "check-ping", Name: "ping", Tag: "prod", Type: model.MonitorCheckICMP, IntervalSeconds: 60, TimeoutSeconds: 1, Enabled: true},
+		{ID: "check-ssh", Name: "ssh", DeviceID: "web1", Type: model.MonitorCheckTCP, Port: 22, IntervalSeconds: 60, TimeoutSeconds: 1, Enabled: true},
+		{ID: "check-off", Name: "off", Tag: "dev", Type: model.MonitorCheckICMP, IntervalSeconds: 60, TimeoutSeconds: 1},
+	}
+	prober := &fakeProber{down: map[string]bool{"10.0.0.2": true}}
+	svc := NewMonitorService(store)
+	svc.SetProber(prober)
+	ctx := SystemContext(context.Background(), "test")
+
+	probes, err := svc.RunDue(ctx)
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if probes != 4 {
+		t.Errorf("expected 4 probes (3 tagged devices + 1 device check), got %d", probes)
+	}
+	if slices.Contains(prober.calls, "10.0.1.1") {
+		t.Error("disabled check must not run")
+	}
+
+	// Checks are not due again until their interval has elapsed
+	if probes, _ := svc.RunDue(ctx); probes != 0 {
+		t.Errorf("expected no probes before interval elapsed, got %d", probes)
+	}
+
+	userCtx := userContext("user-1")
+	web1, err := svc.DeviceStatus(userCtx, "web1")
+	if err != nil {
+		t.Fatalf("DeviceStatus failed: %v", err)
+	}
+	if web1.Status != model.MonitorStatusUp || len(web1.Checks) != 2 || web1.LastSeenAt == nil {
+		t.Errorf("expected web1 up with 2 checks, got %+v", web1)
+	}
+	if web2, _ := svc.DeviceStatus(userCtx, "web2"); web2.Status != model.MonitorStatusDown || web2.Checks[0].Message != "timeout" {
+		t.Errorf("expected web2 down, got %+v", web2)
+	}
+	if noip, _ := svc.DeviceStatus(userCtx, "noip"); noip.Status != model.MonitorStatusUnknown {
+		t.Errorf("expected device without address to be unknown, got %+v", noip)
+	}
+	if _, err := svc.DeviceStatus(userCtx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	summary, err := svc.Summary(userCtx)
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if summary.Total != 3 || summary.Up != 1 || summary.Down != 1 || summary.Unknown != 1 {
+		t.Errorf("unexpected summary counts: %+v", summary)
+	}
+	if len(summary.Problems) != 2 || summary.Problems[0].DeviceID != "noip" {
+		t.Errorf("expected unknown then down devices as problems, got %+v", summary.Problems)
+	}
+
+	// Devices that lose the tag drop out of the check's results
+	store.devices["web2"].Tags = nil
+	svc.lastRun = map[string]time.Time{}
+	if _, err := svc.RunDue(ctx); err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if _, ok := store.results["check-ping/web2"]; ok {
+		t.Error("expected result for untagged device to be pruned")
+	}
+}
+
+func TestMonitorService_StatusTransitions(t *testing.T) {
+	store := newMonitorTestStorage()
+	addMonitorDevice(store, "web1", "10.0.0.1")
+	store.checks = []model.MonitorCheck{
+		{ID: "check-ping", Name: "ping", DeviceID: "web1", Type: model.MonitorCheckICMP, IntervalSeconds: 60, TimeoutSeconds: 1, Enabled: true},
+	}
+	prober := &fakeProber{down: map[string]bool{}}
+	svc := NewMonitorService(store)
+	svc.SetProber(prober)
+	var events []model.EventType
+	svc.publish = func(eventType model.EventType, payload interface{}) {
+		if p := payload.(*model.EventPayloadAvailability); p.DeviceName != "web1" || p.CheckName != "ping" {
+			t.Errorf("unexpected payload %+v", p)
+		}
+		events = append(events, eventType)
+	}
+	ctx := SystemContext(context.Background(), "test")
+
+	svc.RunDue(ctx)
+	first := store.results["check-ping/web1"]
+
+	prober.down["10.0.0.1"] = true
+	svc.lastRun = map[string]time.Time{}
+	svc.RunDue(ctx)
+	down := store.results["check-ping/web1"]
+
+	if down.Status != model.MonitorStatusDown {
+		t.Fatalf("expected down, got %s", down.Status)
+	}
+	if down.LastUpAt == nil || !down.LastUpAt.Equal(*first.LastUpAt) {
+		t.Error("expected last up time to be kept while down")
+	}
+	if !down.LastChangeAt.After(first.LastChangeAt) && !down.LastChangeAt.Equal(down.LastCheckedAt) {
+		t.Error("expected last change time to move on transition")
+	}
+
+	svc.lastRun = map[string]time.Time{}
+	svc.RunDue(ctx)
+	if still := store.results["check-ping/web1"]; !still.LastChangeAt.Equal(down.LastChangeAt) {
+		t.Error("expected last change time to stay while status is unchanged")
+	}
+
+	prober.down["10.0.0.1"] = false
+	svc.lastRun = map[string]time.Time{}
+	svc.RunDue(ctx)
+	if !slices.Equal(events, []model.EventType{model.EventTypeDeviceDown, model.EventTypeDeviceUp}) {
+		t.Errorf("expected down then up events, got %v", events)
+	}
+}
+
+func TestMonitorService_RequiresPermission(t *testing.T) {
+	svc := NewMonitorService(newMonitorTestStorage())
+	ctx := userContext("user-2")
+
+	if _, err := svc.Summary(ctx); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+	if err := svc.CreateCheck(ctx, &model.MonitorCheck{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestDeviceService_AttachesMonitoring(t *testing.T) {
+	store := newMonitorTestStorage()
+	addMonitorDevice(store, "web1", "10.0.0.1")
+	now := time.Now().UTC()
+	store.results["check-ping/web1"] = model.MonitorResult{CheckID: "check-ping", DeviceID: "web1", Status: model.MonitorStatusUp, LastUpAt: &now}
+
+	devices := NewDeviceService(store)
+	devices.setMonitorService(NewMonitorService(store))
+
+	device, err := devices.Get(userContext("user-1"), "web1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if device.Monitoring == nil || device.Monitoring.Status != model.MonitorStatusUp {
+		t.Errorf("expected monitoring status on device, got %+v", device.Monitoring)
+	}
+}
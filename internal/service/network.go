@@ -3,17 +3,23 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/storage"
 )
 
 type NetworkService struct {
-	store storage.ExtendedStorage
+	store        storage.ExtendedStorage
+	searchLimits SearchLimits
 }
 
 func NewNetworkService(store storage.ExtendedStorage) *NetworkService {
-	return &NetworkService{store: store}
+	return &NetworkService{store: store, searchLimits: DefaultSearchLimits}
 }
 
 func (s *NetworkService) List(ctx context.Context, filter *model.NetworkFilter) ([]model.Network, error) {
@@ -23,6 +29,14 @@ func (s *NetworkService) List(ctx context.Context, filter *model.NetworkFilter)
 	return s.store.ListNetworks(ctx, filter)
 }
 
+// ChangedAt returns when the network listing last changed
+func (s *NetworkService) ChangedAt(ctx context.Context) (time.Time, error) {
+	if err := requirePermission(ctx, s.store, "networks", "list"); err != nil {
+		return time.Time{}, err
+	}
+	return s.store.NetworksChangedAt(ctx)
+}
+
 func (s *NetworkService) Create(ctx context.Context, network *model.Network) error {
 	if err := requirePermission(ctx, s.store, "networks", "create"); err != nil {
 		return err
@@ -36,9 +50,134 @@ func (s *NetworkService) Create(ctx context.Context, network *model.Network) err
 		return ValidationErrors{{Field: "subnet", Message: "Subnet is required"}}
 	}
 
+	if subnetFamily(network.Subnet) == "" {
+		return ValidationErrors{{Field: "subnet", Message: "Invalid subnet, expected CIDR notation such as 10.0.0.0/24 or 2001:db8::/64"}}
+	}
+
+	if err := validateNetworkSettings(network); err != nil {
+		return err
+	}
+
+	if err := s.checkDuplicateSubnet(ctx, network); err != nil {
+		return err
+	}
+	if IsDryRun(ctx) {
+		return nil
+	}
+
 	return s.store.CreateNetwork(enrichAuditCtx(ctx), network)
 }
 
+// checkDuplicateSubnet rejects a network whose subnet is already defined by
+// another network in the same datacenter. Nested subnets are allowed; storage
+// links them to their enclosing network as parent and child.
+func (s *NetworkService) checkDuplicateSubnet(ctx context.Context, network *model.Network) error {
+	prefix, err := netip.ParsePrefix(network.Subnet)
+	if err != nil {
+		return nil
+	}
+	prefix = prefix.Masked()
+
+	var filter *model.NetworkFilter
+	if network.DatacenterID != "" {
+		filter = &model.NetworkFilter{DatacenterID: network.DatacenterID}
+	}
+	existing, err := s.store.ListNetworks(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID == network.ID || other.DatacenterID != network.DatacenterID {
+			continue
+		}
+		otherPrefix, err := netip.ParsePrefix(other.Subnet)
+		if err != nil {
+			continue
+		}
+		if otherPrefix.Masked() == prefix {
+			return ValidationErrors{{Field: "subnet", Message: "Subnet " + prefix.String() + " is already used by network " + other.Name + " in this datacenter"}}
+		}
+	}
+	return nil
+}
+
+// ipFamily returns "ipv4" or "ipv6" for an IP address, or "" if it does not
+// parse
+func ipFamily(ip string) string {
+	return model.AddressFamily(ip)
+}
+
+// familyName returns the display name of an address family
+func familyName(family string) string {
+	if family == "ipv6" {
+		return "IPv6"
+	}
+	return "IPv4"
+}
+
+// validateNetworkSettings checks the VLAN, gateway, DNS servers and MTU of a
+// network with a valid subnet. The gateway must lie inside the subnet; DNS
+// servers may be anywhere. IPs are normalized.
+func validateNetworkSettings(network *model.Network) error {
+	var errs ValidationErrors
+	prefix := netip.MustParsePrefix(network.Subnet)
+
+	if network.VLANID < 0 || network.VLANID > 4094 {
+		errs = append(errs, ValidationError{Field: "vlan_id", Message: "VLAN ID must be between 1 and 4094, or 0 for none"})
+	}
+
+	network.Gateway = strings.TrimSpace(network.Gateway)
+	if network.Gateway != "" {
+		gw, err := netip.ParseAddr(network.Gateway)
+		if err != nil || !prefix.Contains(gw.Unmap()) {
+			errs = append(errs, ValidationError{Field: "gateway", Message: fmt.Sprintf("Gateway must be an IP address in %s", network.Subnet)})
+		} else {
+			network.Gateway = gw.Unmap().String()
+		}
+	}
+
+	servers := make([]string, 0, len(network.DNSServers))
+	for _, server := range network.DNSServers {
+		server = strings.TrimSpace(server)
+		if server == "" {
+			continue
+		}
+		addr, err := netip.ParseAddr(server)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: "dns_servers", Message: fmt.Sprintf("Invalid DNS server IP %q", server)})
+			continue
+		}
+		if server = addr.Unmap().String(); !slices.Contains(servers, server) {
+			servers = append(servers, server)
+		}
+	}
+	network.DNSServers = servers
+
+	// IPv6 links carry at least 1280 bytes, IPv4 ones 68
+	minMTU := 68
+	if prefix.Addr().Is6() {
+		minMTU = 1280
+	}
+	if network.MTU != 0 && (network.MTU < minMTU || network.MTU > 65535) {
+		errs = append(errs, ValidationError{Field: "mtu", Message: fmt.Sprintf("MTU must be between %d and 65535, or 0 for the default", minMTU)})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// subnetFamily returns "ipv4" or "ipv6" for a CIDR subnet, or "" if it does
+// not parse
+func subnetFamily(subnet string) string {
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return ""
+	}
+	return ipFamily(prefix.Addr().String())
+}
+
 func (s *NetworkService) Get(ctx context.Context, id string) (*model.Network, error) {
 	if err := requirePermission(ctx, s.store, "networks", "read"); err != nil {
 		return nil, err
@@ -71,19 +210,40 @@ func (s *NetworkService) Update(ctx context.Context, network *model.Network) err
 		return ValidationErrors{{Field: "subnet", Message: "Subnet is required"}}
 	}
 
+	if subnetFamily(network.Subnet) == "" {
+		return ValidationErrors{{Field: "subnet", Message: "Invalid subnet, expected CIDR notation such as 10.0.0.0/24 or 2001:db8::/64"}}
+	}
+
+	if err := validateNetworkSettings(network); err != nil {
+		return err
+	}
+
+	if err := s.checkDuplicateSubnet(ctx, network); err != nil {
+		return err
+	}
+	if IsDryRun(ctx) {
+		// Report a missing network as the update would
+		_, err := s.store.GetNetwork(ctx, network.ID)
+		return err
+	}
+
 	return s.store.UpdateNetwork(enrichAuditCtx(ctx), network)
 }
 
-func (s *NetworkService) Delete(ctx context.Context, id string) error {
+func (s *NetworkService) Delete(ctx context.Context, id string, policy model.DeletePolicy) error {
 	if err := requirePermission(ctx, s.store, "networks", "delete"); err != nil {
 		return err
 	}
 
-	if err := s.store.DeleteNetwork(enrichAuditCtx(ctx), id); err != nil {
+	if err := checkDeletePolicy(policy); err != nil {
+		return err
+	}
+
+	if err := s.store.TrashResource(enrichAuditCtx(ctx), model.TrashTypeNetwork, id, policy); err != nil {
 		if errors.Is(err, storage.ErrNetworkNotFound) {
 			return ErrNotFound
 		}
-		return err
+		return dependentsError(err)
 	}
 	return nil
 }
@@ -104,6 +264,85 @@ func (s *NetworkService) GetDevices(ctx context.Context, networkID string) ([]mo
 	return s.store.GetNetworkDevices(ctx, networkID)
 }
 
+// AllocateSubnet creates a network for the next free subnet of the requested
+// prefix length inside the parent network
+func (s *NetworkService) AllocateSubnet(ctx context.Context, parentID string, req *model.AllocateSubnetRequest) (*model.Network, error) {
+	if err := requirePermission(ctx, s.store, "networks", "create"); err != nil {
+		return nil, err
+	}
+
+	parent, err := s.store.GetNetwork(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNetworkNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	prefix, err := netip.ParsePrefix(parent.Subnet)
+	if err != nil {
+		return nil, ValidationErrors{{Field: "subnet", Message: "Network subnet is not valid CIDR notation"}}
+	}
+	if req.PrefixLength <= prefix.Bits() || req.PrefixLength > prefix.Addr().BitLen() {
+		return nil, ValidationErrors{{Field: "prefix_length", Message: fmt.Sprintf("Prefix length must be between %d and %d for %s", prefix.Bits()+1, prefix.Addr().BitLen(), prefix.Masked())}}
+	}
+	if req.VLANID < 0 || req.VLANID > 4094 {
+		return nil, ValidationErrors{{Field: "vlan_id", Message: "VLAN ID must be between 0 and 4094"}}
+	}
+
+	network := &model.Network{
+		Name:        req.Name,
+		VLANID:      req.VLANID,
+		Description: req.Description,
+	}
+	if err := s.store.AllocateSubnet(enrichAuditCtx(ctx), parentID, req.PrefixLength, network); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNetworkNotFound):
+			return nil, ErrNotFound
+		case errors.Is(err, storage.ErrSubnetNotAvailable):
+			return nil, ErrSubnetNotAvailable
+		}
+		return nil, err
+	}
+	return network, nil
+}
+
+// Children returns the networks whose closest enclosing supernet is the given
+// network
+func (s *NetworkService) Children(ctx context.Context, networkID string) ([]model.Network, error) {
+	if err := requirePermission(ctx, s.store, "networks", "read"); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.GetNetwork(ctx, networkID); err != nil {
+		if errors.Is(err, storage.ErrNetworkNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return s.store.ListNetworks(ctx, &model.NetworkFilter{ParentID: networkID})
+}
+
+// listAllNetworks pages through ListNetworks so callers see every network
+// matching the filter rather than only the first page.
+func listAllNetworks(ctx context.Context, store storage.NetworkStorage, filter model.NetworkFilter) ([]model.Network, error) {
+	var all []model.Network
+	filter.Limit = model.MaxPageSize
+	filter.Offset = 0
+	for {
+		page, err := store.ListNetworks(ctx, &filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < model.MaxPageSize {
+			return all, nil
+		}
+		filter.Offset += len(page)
+	}
+}
+
 func (s *NetworkService) GetUtilization(ctx context.Context, networkID string) (*model.NetworkUtilization, error) {
 	if err := requirePermission(ctx, s.store, "networks", "read"); err != nil {
 		return nil, err
@@ -124,5 +363,53 @@ func (s *NetworkService) Search(ctx context.Context, query string) ([]model.Netw
 		return nil, err
 	}
 
-	return s.store.SearchNetworks(ctx, query)
+	var networks []model.Network
+	err := s.searchLimits.search(ctx, func(ctx context.Context) error {
+		var err error
+		networks, err = s.store.SearchNetworks(ctx, query)
+		return err
+	})
+	return networks, err
+}
+
+// ListAsOf returns the networks as they were at the given time
+func (s *NetworkService) ListAsOf(ctx context.Context, asOf time.Time, filter *model.NetworkFilter) ([]model.Network, error) {
+	if err := requirePermission(ctx, s.store, "networks", "list"); err != nil {
+		return nil, err
+	}
+	return s.store.ListNetworksAsOf(ctx, asOf, filter)
+}
+
+// GetAsOf returns a network as it was at the given time, or ErrNotFound if it
+// did not exist then
+func (s *NetworkService) GetAsOf(ctx context.Context, id string, asOf time.Time) (*model.Network, error) {
+	if err := requirePermission(ctx, s.store, "networks", "read"); err != nil {
+		return nil, err
+	}
+
+	network, err := s.store.GetNetworkAsOf(ctx, id, asOf)
+	if err != nil {
+		if errors.Is(err, storage.ErrNetworkNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return network, nil
+}
+
+// History returns every recorded version of a network, including after it
+// was deleted
+func (s *NetworkService) History(ctx context.Context, id string) ([]model.ResourceVersion, error) {
+	if err := requirePermission(ctx, s.store, "networks", "read"); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.store.ListResourceVersions(ctx, "network", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	return versions, nil
 }
@@ -52,7 +52,84 @@ func TestNetworkService_CRUDDevicesAndUtilizationPaths(t *testing.T) {
 	if _, err := svc.GetUtilization(userContext("user-1"), "net-1"); err != nil {
 		t.Fatalf("GetUtilization returned unexpected error: %v", err)
 	}
-	if err := svc.Delete(userContext("user-1"), "missing"); !errors.Is(err, ErrNotFound) {
+	if err := svc.Delete(userContext("user-1"), "missing", model.DeleteDetach); !errors.Is(err, ErrNotFound) {
 		t.Fatalf("expected not found on delete, got %v", err)
 	}
 }
+
+func TestNetworkService_CreateValidatesSubnet(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "networks", "create", true)
+	svc := NewNetworkService(store)
+
+	err := svc.Create(userContext("user-1"), &model.Network{Name: "bad", Subnet: "10.0.0.0"})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || verrs[0].Field != "subnet" {
+		t.Fatalf("expected validation error for a subnet without a prefix, got %v", err)
+	}
+
+	if err := svc.Create(userContext("user-1"), &model.Network{Name: "v6", Subnet: "2001:db8::/64"}); err != nil {
+		t.Fatalf("expected an IPv6 subnet to be accepted, got %v", err)
+	}
+}
+
+func TestNetworkService_CreateValidatesSettings(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "networks", "create", true)
+	svc := NewNetworkService(store)
+
+	err := svc.Create(userContext("user-1"), &model.Network{
+		Name: "bad", Subnet: "10.0.0.0/24", VLANID: 5000, Gateway: "10.0.1.1", DNSServers: []string{"dns.example.com"}, MTU: 20,
+	})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 4 {
+		t.Fatalf("expected errors for the VLAN, gateway, DNS server and MTU, got %v", err)
+	}
+
+	network := &model.Network{
+		Name: "lan", Subnet: "10.0.0.0/24", Gateway: " 10.0.0.1 ", DNSServers: []string{"10.0.0.53", "", "2001:db8::53", "10.0.0.53"}, MTU: 9000,
+	}
+	if err := svc.Create(userContext("user-1"), network); err != nil {
+		t.Fatalf("expected create to succeed, got %v", err)
+	}
+	if network.Gateway != "10.0.0.1" || len(network.DNSServers) != 2 {
+		t.Errorf("expected normalized settings, got %q %v", network.Gateway, network.DNSServers)
+	}
+
+	err = svc.Create(userContext("user-1"), &model.Network{Name: "v6", Subnet: "2001:db8::/64", MTU: 1000})
+	if !errors.As(err, &verrs) || verrs[0].Field != "mtu" {
+		t.Errorf("expected an IPv6 MTU below 1280 to be rejected, got %v", err)
+	}
+}
+
+func TestNetworkService_RejectsDuplicateSubnetInDatacenter(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "networks", "create", true)
+	store.setPermission("user-1", "networks", "update", true)
+	store.networks = []model.Network{
+		{ID: "net-1", Name: "prod", Subnet: "10.0.0.0/24", DatacenterID: "dc-1"},
+		{ID: "net-2", Name: "lab", Subnet: "10.1.0.0/24", DatacenterID: "dc-1"},
+	}
+	svc := NewNetworkService(store)
+
+	err := svc.Create(userContext("user-1"), &model.Network{Name: "dup", Subnet: "10.0.0.7/24", DatacenterID: "dc-1"})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || verrs[0].Field != "subnet" {
+		t.Fatalf("expected validation error for a duplicate subnet, got %v", err)
+	}
+
+	if err := svc.Create(userContext("user-1"), &model.Network{Name: "other-dc", Subnet: "10.0.0.0/24", DatacenterID: "dc-2"}); err != nil {
+		t.Fatalf("expected the same subnet in another datacenter to be accepted, got %v", err)
+	}
+	if err := svc.Create(userContext("user-1"), &model.Network{Name: "nested", Subnet: "10.0.0.0/26", DatacenterID: "dc-1"}); err != nil {
+		t.Fatalf("expected a nested subnet to be accepted, got %v", err)
+	}
+
+	if err := svc.Update(userContext("user-1"), &model.Network{ID: "net-1", Name: "prod", Subnet: "10.0.0.0/24", DatacenterID: "dc-1"}); err != nil {
+		t.Fatalf("expected a network to keep its own subnet, got %v", err)
+	}
+	err = svc.Update(userContext("user-1"), &model.Network{ID: "net-2", Name: "lab", Subnet: "10.0.0.0/24", DatacenterID: "dc-1"})
+	if !errors.As(err, &verrs) || verrs[0].Field != "subnet" {
+		t.Fatalf("expected validation error when updating onto a used subnet, got %v", err)
+	}
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/notify"
+	"github.com/martinsuchenak/rackd/internal/storage"
+	wh "github.com/martinsuchenak/rackd/internal/webhook"
+)
+
+// NotificationService manages the channels that receive human-readable
+// event summaries, such as the changes found by discovery scans, and sends
+// warranty reminders. Secrets are write-only: responses only report whether
+// one is set.
+type NotificationService struct {
+	store    storage.ExtendedStorage
+	notifier *notify.Notifier
+
+	// send delivers an event to a channel and publish sends events to the
+	// event bus; tests replace them
+	send    func(ctx context.Context, ch *model.NotificationChannel, event model.Event) error
+	publish func(eventType model.EventType, payload interface{})
+}
+
+// NewNotificationService creates a notification channel service. Email
+// channels must name their SMTP server until SetNotifier provides a relay.
+func NewNotificationService(store storage.ExtendedStorage) *NotificationService {
+	s := &NotificationService{store: store, publish: wh.Publish}
+	s.SetNotifier(notify.NewNotifier(store, notify.NewSender(notify.SMTPConfig{})))
+	return s
+}
+
+// SetNotifier sets the notifier test notifications are sent with, which
+// knows the server's SMTP relay
+func (s *NotificationService) SetNotifier(n *notify.Notifier) {
+	s.notifier = n
+	s.send = n.Notify
+}
+
+func (s *NotificationService) List(ctx context.Context, filter *model.NotificationChannelFilter) ([]model.NotificationChannel, error) {
+	if err := requirePermission(ctx, s.store, "notifications", "list"); err != nil {
+		return nil, err
+	}
+	channels, err := s.store.ListNotificationChannels(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range channels {
+		redactNotificationChannel(&channels[i])
+	}
+	return channels, nil
+}
+
+func (s *NotificationService) Get(ctx context.Context, id string) (*model.NotificationChannel, error) {
+	if err := requirePermission(ctx, s.store, "notifications", "read"); err != nil {
+		return nil, err
+	}
+	ch, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	redactNotificationChannel(ch)
+	return ch, nil
+}
+
+func (s *NotificationService) get(ctx context.Context, id string) (*model.NotificationChannel, error) {
+	ch, err := s.store.GetNotificationChannel(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotificationChannelNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (s *NotificationService) Create(ctx context.Context, req *model.CreateNotificationChannelRequest) (*model.NotificationChannel, error) {
+	if err := requirePermission(ctx, s.store, "notifications", "create"); err != nil {
+		return nil, err
+	}
+
+	ch := &model.NotificationChannel{
+		Name:          strings.TrimSpace(req.Name),
+		Type:          req.Type,
+		URL:           strings.TrimSpace(req.URL),
+		Secret:        req.Secret,
+		SMTPHost:      strings.TrimSpace(req.SMTPHost),
+		SMTPPort:      req.SMTPPort,
+		Username:      req.Username,
+		From:          strings.TrimSpace(req.From),
+		To:            trimAll(req.To),
+		UserIDs:       trimAll(req.UserIDs),
+		Events:        req.Events,
+		TitleTemplate: req.TitleTemplate,
+		BodyTemplate:  req.BodyTemplate,
+		ChangesOnly:   req.ChangesOnly,
+		Active:        req.Active == nil || *req.Active,
+		Description:   req.Description,
+	}
+	if len(ch.Events) == 0 {
+		ch.Events = []model.EventType{model.EventTypeDiscoveryCompleted}
+	}
+	if caller := CallerFrom(ctx); caller != nil {
+		ch.CreatedBy = caller.UserID
+	}
+
+	var errs ValidationErrors
+	if ch.Name == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "Name is required"})
+	}
+	if !ch.Type.IsValid() {
+		errs = append(errs, ValidationError{Field: "type", Message: "Type must be one of: webhook, email, gotify, ntfy"})
+	} else {
+		errs = append(errs, s.validateSettings(ctx, ch)...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := s.store.CreateNotificationChannel(enrichAuditCtx(ctx), ch); err != nil {
+		return nil, err
+	}
+	redactNotificationChannel(ch)
+	return ch, nil
+}
+
+// Update changes a channel's settings. The type cannot change, and an empty
+// secret clears it.
+func (s *NotificationService) Update(ctx context.Context, id string, req *model.UpdateNotificationChannelRequest) (*model.NotificationChannel, error) {
+	if err := requirePermission(ctx, s.store, "notifications", "update"); err != nil {
+		return nil, err
+	}
+
+	ch, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		ch.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.URL != nil {
+		ch.URL = strings.TrimSpace(*req.URL)
+	}
+	if req.Secret != nil {
+		ch.Secret = *req.Secret
+	}
+	if req.SMTPHost != nil {
+		ch.SMTPHost = strings.TrimSpace(*req.SMTPHost)
+	}
+	if req.SMTPPort != nil {
+		ch.SMTPPort = *req.SMTPPort
+	}
+	if req.Username != nil {
+		ch.Username = *req.Username
+	}
+	if req.From != nil {
+		ch.From = strings.TrimSpace(*req.From)
+	}
+	if req.To != nil {
+		ch.To = trimAll(*req.To)
+	}
+	if req.UserIDs != nil {
+		ch.UserIDs = trimAll(*req.UserIDs)
+	}
+	if req.Events != nil {
+		ch.Events = *req.Events
+	}
+	if req.TitleTemplate != nil {
+		ch.TitleTemplate = *req.TitleTemplate
+	}
+	if req.BodyTemplate != nil {
+		ch.BodyTemplate = *req.BodyTemplate
+	}
+	if req.ChangesOnly != nil {
+		ch.ChangesOnly = *req.ChangesOnly
+	}
+	if req.Active != nil {
+		ch.Active = *req.Active
+	}
+	if req.Description != nil {
+		ch.Description = *req.Description
+	}
+
+	var errs ValidationErrors
+	if ch.Name == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "Name cannot be empty"})
+	}
+	errs = append(errs, s.validateSettings(ctx, ch)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := s.store.UpdateNotificationChannel(enrichAuditCtx(ctx), ch); err != nil {
+		if errors.Is(err, storage.ErrNotificationChannelNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	redactNotificationChannel(ch)
+	return ch, nil
+}
+
+func (s *NotificationService) Delete(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, s.store, "notifications", "delete"); err != nil {
+		return err
+	}
+	if err := s.store.DeleteNotificationChannel(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrNotificationChannelNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Test sends a test notification to a channel, whether or not it is active.
+// A failed delivery is reported in the result rather than as an error.
+func (s *NotificationService) Test(ctx context.Context, id string) (*model.NotificationTestResult, error) {
+	if err := requirePermission(ctx, s.store, "notifications", "update"); err != nil {
+		return nil, err
+	}
+	ch, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	event := model.Event{
+		Type:      notify.EventTest,
+		Timestamp: time.Now().UTC(),
+		Payload:   map[string]string{"channel": ch.Name},
+	}
+
+	start := time.Now()
+	err = s.send(ctx, ch, event)
+	result := &model.NotificationTestResult{Success: err == nil, Duration: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+// SendWarrantyReminders publishes a device.warranty_expiring event for each
+// of days listing the devices whose warranty expires that many days after
+// today, and returns the number of devices reminded about. Decommissioned
+// devices are skipped.
+func (s *NotificationService) SendWarrantyReminders(ctx context.Context, days []int, today time.Time) (int, error) {
+	if err := requirePermission(ctx, s.store, "devices", "list"); err != nil {
+		return 0, err
+	}
+	if len(days) == 0 {
+		return 0, nil
+	}
+
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{WarrantyDays: slices.Max(days) + 1})
+	if err != nil {
+		return 0, err
+	}
+
+	start := today.UTC().Truncate(24 * time.Hour)
+	due := make(map[int][]model.WarrantyDevice)
+	for _, d := range devices {
+		if d.WarrantyExpiry == nil || d.Status == model.DeviceStatusDecommissioned {
+			continue
+		}
+		left := int(d.WarrantyExpiry.UTC().Truncate(24*time.Hour).Sub(start).Hours() / 24)
+		if slices.Contains(days, left) {
+			due[left] = append(due[left], model.WarrantyDevice{ID: d.ID, Name: d.Name, WarrantyExpiry: *d.WarrantyExpiry})
+		}
+	}
+
+	reminded := 0
+	for _, n := range days {
+		if len(due[n]) == 0 {
+			continue
+		}
+		s.publish(model.EventTypeDeviceWarrantyExpiring, &model.EventPayloadWarranty{Days: n, Devices: due[n]})
+		reminded += len(due[n])
+	}
+	return reminded, nil
+}
+
+// validateSettings checks the events, templates and the settings the
+// channel's type needs
+func (s *NotificationService) validateSettings(ctx context.Context, ch *model.NotificationChannel) ValidationErrors {
+	var errs ValidationErrors
+	if len(ch.Events) == 0 {
+		errs = append(errs, ValidationError{Field: "events", Message: "At least one event type is required"})
+	}
+	for _, et := range ch.Events {
+		if !et.IsValid() {
+			errs = append(errs, ValidationError{Field: "events", Message: "Invalid event type: " + string(et)})
+		}
+	}
+
+	switch ch.Type {
+	case model.NotificationChannelWebhook, model.NotificationChannelGotify, model.NotificationChannelNtfy:
+		if ch.URL == "" {
+			errs = append(errs, ValidationError{Field: "url", Message: "URL is required"})
+		} else if err := validateWebhookURL(ch.URL); err != nil {
+			var urlErrs ValidationErrors
+			if errors.As(err, &urlErrs) {
+				errs = append(errs, urlErrs...)
+			}
+		}
+		if ch.Type == model.NotificationChannelGotify && ch.Secret == "" {
+			errs = append(errs, ValidationError{Field: "secret", Message: "Secret is required (the Gotify application token)"})
+		}
+	case model.NotificationChannelEmail:
+		relay := ch.SMTPHost == "" && s.notifier.SMTPConfigured()
+		if ch.SMTPHost == "" && !relay {
+			errs = append(errs, ValidationError{Field: "smtp_host", Message: "SMTP host is required, as the server has no SMTP_HOST"})
+		}
+		if ch.SMTPPort < 0 || ch.SMTPPort > 65535 {
+			errs = append(errs, ValidationError{Field: "smtp_port", Message: "SMTP port must be between 1 and 65535, or 0 for 587"})
+		}
+		if ch.From != "" || !relay {
+			if _, err := mail.ParseAddress(ch.From); err != nil {
+				errs = append(errs, ValidationError{Field: "from", Message: "From must be an email address"})
+			}
+		}
+		if len(ch.To) == 0 && len(ch.UserIDs) == 0 {
+			errs = append(errs, ValidationError{Field: "to", Message: "At least one recipient address or user is required"})
+		}
+		for _, to := range ch.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				errs = append(errs, ValidationError{Field: "to", Message: "Invalid email address: " + to})
+			}
+		}
+		for _, id := range ch.UserIDs {
+			user, err := s.store.GetUser(ctx, id)
+			if err != nil {
+				errs = append(errs, ValidationError{Field: "user_ids", Message: "Unknown user: " + id})
+			} else if user.Email == "" {
+				errs = append(errs, ValidationError{Field: "user_ids", Message: "User " + user.Username + " has no email address"})
+			}
+		}
+	}
+	if len(ch.UserIDs) > 0 && ch.Type != model.NotificationChannelEmail {
+		errs = append(errs, ValidationError{Field: "user_ids", Message: "Users can only be recipients of email channels"})
+	}
+
+	if err := notify.ParseTemplate(ch.TitleTemplate); err != nil {
+		errs = append(errs, ValidationError{Field: "title_template", Message: "Invalid template: " + err.Error()})
+	}
+	if err := notify.ParseTemplate(ch.BodyTemplate); err != nil {
+		errs = append(errs, ValidationError{Field: "body_template", Message: "Invalid template: " + err.Error()})
+	}
+	return errs
+}
+
+// redactNotificationChannel clears the secret before a channel is returned
+func redactNotificationChannel(ch *model.NotificationChannel) {
+	ch.HasSecret = ch.Secret != ""
+	ch.Secret = ""
+}
+
+// trimAll trims each value and drops empty ones
+func trimAll(values []string) []string {
+	var trimmed []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/notify"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// notificationTestStorage keeps notification channels in memory
+type notificationTestStorage struct {
+	*serviceTestStorage
+	channels map[string]*model.NotificationChannel
+}
+
+func newNotificationTestStorage() *notificationTestStorage {
+	store := &notificationTestStorage{serviceTestStorage: newServiceTestStorage(), channels: map[string]*model.NotificationChannel{}}
+	for _, action := range []string{"list", "read", "create", "update", "delete"} {
+		store.setPermission("user-1", "notifications", action, true)
+	}
+	return store
+}
+
+func (s *notificationTestStorage) CreateNotificationChannel(_ context.Context, ch *model.NotificationChannel) error {
+	ch.ID = "nc-" + ch.Name
+	stored := *ch
+	s.channels[ch.ID] = &stored
+	return nil
+}
+
+func (s *notificationTestStorage) GetNotificationChannel(_ context.Context, id string) (*model.NotificationChannel, error) {
+	ch, ok := s.channels[id]
+	if !ok {
+		return nil, storage.ErrNotificationChannelNotFound
+	}
+	cloned := *ch
+	return &cloned, nil
+}
+
+func (s *notificationTestStorage) UpdateNotificationChannel(_ context.Context, ch *model.NotificationChannel) error {
+	if _, ok := s.channels[ch.ID]; !ok {
+		return storage.ErrNotificationChannelNotFound
+	}
+	stored := *ch
+	s.channels[ch.ID] = &stored
+	return nil
+}
+
+func TestNotificationService_CreateValidatesPerType(t *testing.T) {
+	store := newNotificationTestStorage()
+	store.users["user-1"] = &model.User{ID: "user-1", Username: "alice", Email: "alice@example.com"}
+	store.users["user-2"] = &model.User{ID: "user-2", Username: "bob"}
+	svc := NewNotificationService(store)
+	ctx := userContext("user-1")
+
+	tests := []struct {
+		name  string
+		req   model.CreateNotificationChannelRequest
+		field string
+	}{
+		{"unknown type", model.CreateNotificationChannelRequest{Name: "x", Type: "sms"}, "type"},
+		{"webhook without url", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelWebhook}, "url"},
+		{"loopback url", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelNtfy, URL: "http://127.0.0.1/topic"}, "url"},
+		{"gotify without token", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelGotify, URL: "https://gotify.example.com"}, "secret"},
+		{"email without host", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelEmail, From: "a@example.com", To: []string{"b@example.com"}}, "smtp_host"},
+		{"email bad recipient", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelEmail, SMTPHost: "mail", From: "a@example.com", To: []string{"nope"}}, "to"},
+		{"email without recipients", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelEmail, SMTPHost: "mail", From: "a@example.com"}, "to"},
+		{"unknown user", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelEmail, SMTPHost: "mail", From: "a@example.com", UserIDs: []string{"nobody"}}, "user_ids"},
+		{"user without email", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelEmail, SMTPHost: "mail", From: "a@example.com", UserIDs: []string{"user-2"}}, "user_ids"},
+		{"users on ntfy", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelNtfy, URL: "https://ntfy.sh/x", UserIDs: []string{"user-1"}}, "user_ids"},
+		{"bad template", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelNtfy, URL: "https://ntfy.sh/x", BodyTemplate: "{{.Payload"}, "body_template"},
+		{"invalid event", model.CreateNotificationChannelRequest{Name: "x", Type: model.NotificationChannelNtfy, URL: "https://ntfy.sh/x", Events: []model.EventType{"not.real"}}, "events"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Create(ctx, &tt.req)
+			var errs ValidationErrors
+			if !errors.As(err, &errs) || errs[0].Field != tt.field {
+				t.Fatalf("Create = %v, want validation error on %s", err, tt.field)
+			}
+		})
+	}
+
+	ch, err := svc.Create(ctx, &model.CreateNotificationChannelRequest{
+		Name: "ops", Type: model.NotificationChannelEmail, SMTPHost: "mail.example.com", Username: "rackd", Secret: "pass",
+		From: "rackd@example.com", To: []string{" ops@example.com ", ""},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if ch.Secret != "" || !ch.HasSecret || !ch.Active || ch.CreatedBy != "user-1" {
+		t.Errorf("created channel = %+v, want redacted secret, active, created by user-1", ch)
+	}
+	if len(ch.Events) != 1 || ch.Events[0] != model.EventTypeDiscoveryCompleted {
+		t.Errorf("Events = %v, want the discovery.completed default", ch.Events)
+	}
+	if len(ch.To) != 1 || ch.To[0] != "ops@example.com" {
+		t.Errorf("To = %q, want trimmed recipients", ch.To)
+	}
+	if store.channels[ch.ID].Secret != "pass" {
+		t.Error("secret was not stored")
+	}
+}
+
+func TestNotificationService_UpdateKeepsSecretUnlessGiven(t *testing.T) {
+	store := newNotificationTestStorage()
+	svc := NewNotificationService(store)
+	ctx := userContext("user-1")
+
+	ch, err := svc.Create(ctx, &model.CreateNotificationChannelRequest{Name: "push", Type: model.NotificationChannelGotify, URL: "https://gotify.example.com", Secret: "app"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	changesOnly := true
+	updated, err := svc.Update(ctx, ch.ID, &model.UpdateNotificationChannelRequest{ChangesOnly: &changesOnly})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if !updated.ChangesOnly || !updated.HasSecret || store.channels[ch.ID].Secret != "app" {
+		t.Errorf("updated channel = %+v, want changes only with the secret kept", updated)
+	}
+
+	empty := ""
+	if _, err := svc.Update(ctx, ch.ID, &model.UpdateNotificationChannelRequest{Secret: &empty}); !errors.Is(err, ErrValidation) {
+		t.Errorf("clearing the Gotify token = %v, want validation error", err)
+	}
+	if _, err := svc.Update(ctx, "missing", &model.UpdateNotificationChannelRequest{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update missing = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNotificationService_Test(t *testing.T) {
+	store := newNotificationTestStorage()
+	svc := NewNotificationService(store)
+	ctx := userContext("user-1")
+
+	ch, err := svc.Create(ctx, &model.CreateNotificationChannelRequest{Name: "topic", Type: model.NotificationChannelNtfy, URL: "https://ntfy.sh/rackd"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	var sent *model.Event
+	svc.send = func(_ context.Context, got *model.NotificationChannel, event model.Event) error {
+		if got.ID != ch.ID {
+			t.Errorf("sent to %s, want %s", got.ID, ch.ID)
+		}
+		sent = &event
+		return nil
+	}
+	result, err := svc.Test(ctx, ch.ID)
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+	if !result.Success || sent == nil || sent.Type != notify.EventTest {
+		t.Errorf("result = %+v, event = %+v", result, sent)
+	}
+
+	svc.send = func(context.Context, *model.NotificationChannel, model.Event) error {
+		return errors.New("ntfy.sh returned status 403")
+	}
+	result, err = svc.Test(ctx, ch.ID)
+	if err != nil || result.Success || result.Error != "ntfy.sh returned status 403" {
+		t.Errorf("failed test = %+v, %v; want the delivery error in the result", result, err)
+	}
+
+	if _, err := svc.Test(userContext("user-2"), ch.ID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Test without permission = %v, want ErrForbidden", err)
+	}
+}
+
+func TestNotificationService_EmailWithSMTPRelay(t *testing.T) {
+	store := newNotificationTestStorage()
+	store.users["user-1"] = &model.User{ID: "user-1", Username: "alice", Email: "alice@example.com"}
+	svc := NewNotificationService(store)
+	ctx := userContext("user-1")
+	req := &model.CreateNotificationChannelRequest{Name: "oncall", Type: model.NotificationChannelEmail, UserIDs: []string{"user-1"}}
+
+	var errs ValidationErrors
+	if _, err := svc.Create(ctx, req); !errors.As(err, &errs) || errs[0].Field != "smtp_host" {
+		t.Fatalf("Create without a relay = %v, want validation error on smtp_host", err)
+	}
+
+	svc.SetNotifier(notify.NewNotifier(store, notify.NewSender(notify.SMTPConfig{Host: "relay.example.com", Port: 25, From: "rackd@example.com"})))
+	ch, err := svc.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("Create with a relay failed: %v", err)
+	}
+	if ch.SMTPHost != "" || len(ch.UserIDs) != 1 {
+		t.Errorf("created channel = %+v, want the relay and one user recipient", ch)
+	}
+}
+
+func TestNotificationService_SendWarrantyReminders(t *testing.T) {
+	store := newMonitorTestStorage()
+	today := time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC)
+	for name, expiry := range map[string]time.Time{
+		"web1": time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC),
+		"web2": time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC),
+		"db1":  time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC),
+		"db2":  time.Date(2026, 10, 24, 0, 0, 0, 0, time.UTC),
+		"old1": time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC),
+	} {
+		addMonitorDevice(store, name, "")
+		store.devices[name].WarrantyExpiry = &expiry
+	}
+	store.devices["old1"].Status = model.DeviceStatusDecommissioned
+
+	svc := NewNotificationService(store)
+	published := map[int][]string{}
+	svc.publish = func(eventType model.EventType, payload interface{}) {
+		p := payload.(*model.EventPayloadWarranty)
+		if eventType != model.EventTypeDeviceWarrantyExpiring {
+			t.Errorf("published %s", eventType)
+		}
+		for _, d := range p.Devices {
+			published[p.Days] = append(published[p.Days], d.Name)
+		}
+	}
+
+	n, err := svc.SendWarrantyReminders(SystemContext(context.Background(), "test"), []int{30, 7, 1}, today)
+	if err != nil {
+		t.Fatalf("SendWarrantyReminders failed: %v", err)
+	}
+	if n != 3 || !slices.Equal(published[30], []string{"web1", "web2"}) || !slices.Equal(published[7], []string{"db1"}) || len(published) != 2 {
+		t.Errorf("reminded %d: %v, want web1 and web2 at 30 days and db1 at 7", n, published)
+	}
+
+	if _, err := svc.SendWarrantyReminders(userContext("user-2"), []int{30}, today); !errors.Is(err, ErrForbidden) {
+		t.Errorf("SendWarrantyReminders without permission = %v, want ErrForbidden", err)
+	}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/auth"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+var (
+	ErrOIDCInvalidState = errors.New("login request expired or unknown, please sign in again")
+	ErrOIDCNoRole       = errors.New("you are not in any group that grants access to rackd")
+	ErrOIDCUserExists   = errors.New("a local account with your username already exists; ask an administrator to link it")
+)
+
+// oidcLoginTTL is how long a user has to sign in at the provider
+const oidcLoginTTL = 10 * time.Minute
+
+// OIDCService signs users in through an OpenID Connect provider. Users are
+// created on first login and their roles follow their provider groups.
+type OIDCService struct {
+	store          storage.ExtendedStorage
+	sessionManager *auth.SessionManager
+	provider       *auth.OIDCProvider
+	roleMap        map[string][]string // group -> role names
+	defaultRole    string
+	linkExisting   bool
+
+	mu      sync.Mutex
+	pending map[string]oidcLogin // keyed by state
+}
+
+// oidcLogin is a login started with StartLogin and not yet completed
+type oidcLogin struct {
+	nonce        string
+	codeVerifier string
+	redirect     string
+	expiresAt    time.Time
+}
+
+// NewOIDCService creates the service. Users get the roles roleMap gives their
+// groups, or defaultRole if none; with neither they cannot sign in. With
+// linkExisting, a first login may take over the unlinked local account with
+// the same username; without it that login is refused.
+func NewOIDCService(store storage.ExtendedStorage, sm *auth.SessionManager, provider *auth.OIDCProvider, roleMap map[string][]string, defaultRole string, linkExisting bool) *OIDCService {
+	return &OIDCService{
+		store:          store,
+		sessionManager: sm,
+		provider:       provider,
+		roleMap:        roleMap,
+		defaultRole:    defaultRole,
+		linkExisting:   linkExisting,
+		pending:        make(map[string]oidcLogin),
+	}
+}
+
+// StartLogin returns the provider URL to send the browser to, and the state
+// that the callback must present. redirect is where to go after signing in.
+func (s *OIDCService) StartLogin(ctx context.Context, redirect string) (authURL, state string, err error) {
+	login := oidcLogin{redirect: redirect, expiresAt: time.Now().Add(oidcLoginTTL)}
+	if state, err = auth.GenerateOIDCSecret(); err != nil {
+		return "", "", err
+	}
+	if login.nonce, err = auth.GenerateOIDCSecret(); err != nil {
+		return "", "", err
+	}
+	if login.codeVerifier, err = auth.GenerateOIDCSecret(); err != nil {
+		return "", "", err
+	}
+
+	authURL, err = s.provider.AuthCodeURL(ctx, state, login.nonce, login.codeVerifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	for k, l := range s.pending {
+		if now.After(l.expiresAt) {
+			delete(s.pending, k)
+		}
+	}
+	s.pending[state] = login
+	s.mu.Unlock()
+
+	return authURL, state, nil
+}
+
+// CompleteLogin redeems the provider's authorization code for the login
+// started with state, and creates a session for the user. It returns the
+// redirect given to StartLogin.
+func (s *OIDCService) CompleteLogin(ctx context.Context, state, code string) (*LoginResult, string, error) {
+	s.mu.Lock()
+	login, ok := s.pending[state]
+	delete(s.pending, state)
+	s.mu.Unlock()
+	if !ok || time.Now().After(login.expiresAt) {
+		return nil, "", ErrOIDCInvalidState
+	}
+
+	identity, err := s.provider.Exchange(ctx, code, login.codeVerifier, login.nonce)
+	if err != nil {
+		return nil, "", err
+	}
+
+	roles := s.roles(identity.Groups)
+	if len(roles) == 0 {
+		log.Warn("OIDC login denied: no mapped group", "username", identity.Username, "groups", identity.Groups)
+		return nil, "", ErrOIDCNoRole
+	}
+
+	user, err := s.provisionUser(ctx, identity)
+	if err != nil {
+		return nil, "", err
+	}
+	if !user.IsActive {
+		return nil, "", ErrUnauthenticated
+	}
+	if err := s.syncRoles(ctx, user.ID, roles); err != nil {
+		return nil, "", err
+	}
+
+	isAdmin, _ := s.store.HasPermission(ctx, user.ID, "users", "create")
+	session, err := s.sessionManager.CreateSession(user.ID, user.Username, isAdmin)
+	if err != nil {
+		log.Error("Failed to create session", "error", err, "user_id", user.ID)
+		return nil, "", err
+	}
+
+	if err := s.store.UpdateUserLastLogin(ctx, user.ID, time.Now()); err != nil {
+		log.Warn("Failed to update last login", "error", err, "user_id", user.ID)
+	}
+
+	resp := user.ToResponse()
+	if roles, err := s.store.GetUserRoles(ctx, user.ID); err == nil {
+		resp.Roles = roles
+	}
+	return &LoginResult{User: resp, Session: session}, login.redirect, nil
+}
+
+// roles returns the names of the roles granted to groups
+func (s *OIDCService) roles(groups []string) []string {
+	var roles []string
+	for _, g := range groups {
+		for _, r := range s.roleMap[g] {
+			if !slices.Contains(roles, r) {
+				roles = append(roles, r)
+			}
+		}
+	}
+	if len(roles) == 0 && s.defaultRole != "" {
+		roles = []string{s.defaultRole}
+	}
+	return roles
+}
+
+// provisionUser returns the user linked to the identity's issuer and
+// subject, creating it on first login. Provisioned users have no usable
+// password. The username claim is only trusted to name new users: a login
+// whose username belongs to another account is refused, unless linking
+// existing users is enabled and that account has no identity yet.
+func (s *OIDCService) provisionUser(ctx context.Context, identity *auth.OIDCIdentity) (*model.User, error) {
+	user, err := s.store.GetUserByOIDCSubject(ctx, identity.Issuer, identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, storage.ErrUserNotFound) {
+		return nil, err
+	}
+
+	user, err = s.store.GetUserByUsername(ctx, identity.Username)
+	if err == nil {
+		return s.linkUser(ctx, user, identity)
+	}
+	if !errors.Is(err, storage.ErrUserNotFound) {
+		return nil, err
+	}
+
+	unusable, err := auth.GenerateOIDCSecret()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := auth.HashPassword(unusable)
+	if err != nil {
+		return nil, err
+	}
+	user = &model.User{
+		Username:     identity.Username,
+		Email:        identity.Email,
+		FullName:     identity.FullName,
+		PasswordHash: hash,
+		IsActive:     true,
+		OIDCIssuer:   identity.Issuer,
+		OIDCSubject:  identity.Subject,
+	}
+	if err := s.store.CreateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user %s: %w", identity.Username, err)
+	}
+	log.Info("Created user from OIDC login", "username", user.Username, "user_id", user.ID)
+	return user, nil
+}
+
+// linkUser links the existing user with the identity's username to the
+// identity, if that is allowed
+func (s *OIDCService) linkUser(ctx context.Context, user *model.User, identity *auth.OIDCIdentity) (*model.User, error) {
+	if !s.linkExisting || user.OIDCSubject != "" {
+		log.Warn("OIDC login denied: username belongs to another account",
+			"username", identity.Username, "issuer", identity.Issuer, "subject", identity.Subject)
+		return nil, ErrOIDCUserExists
+	}
+	if err := s.store.LinkUserOIDCSubject(ctx, user.ID, identity.Issuer, identity.Subject); err != nil {
+		if errors.Is(err, storage.ErrOIDCSubjectLinked) {
+			return nil, ErrOIDCUserExists
+		}
+		return nil, err
+	}
+	user.OIDCIssuer, user.OIDCSubject = identity.Issuer, identity.Subject
+	log.Info("Linked existing user to OIDC identity", "username", user.Username, "user_id", user.ID, "issuer", identity.Issuer)
+	return user, nil
+}
+
+// syncRoles makes the user's roles exactly roleNames, so removing a user
+// from a group at the provider takes effect at their next login
+func (s *OIDCService) syncRoles(ctx context.Context, userID string, roleNames []string) error {
+	want := make(map[string]bool)
+	for _, name := range roleNames {
+		role, err := s.store.GetRoleByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, storage.ErrRoleNotFound) {
+				log.Warn("OIDC role mapping names an unknown role", "role", name)
+				continue
+			}
+			return err
+		}
+		want[role.ID] = true
+	}
+	if len(want) == 0 {
+		return ErrOIDCNoRole
+	}
+
+	current, err := s.store.GetUserRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, role := range current {
+		if want[role.ID] {
+			delete(want, role.ID)
+		} else if err := s.store.RemoveRoleFromUser(ctx, userID, role.ID); err != nil {
+			return err
+		}
+	}
+	for roleID := range want {
+		if err := s.store.AssignRoleToUser(ctx, userID, roleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseOIDCRoleMap parses a group to role mapping in the form
+// "group=role,group=role". A group may be listed more than once.
+func ParseOIDCRoleMap(spec string) (map[string][]string, error) {
+	roles := make(map[string][]string)
+	for _, entry := range strings.Split(spec, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(entry, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("invalid role mapping %q (expected group=role)", entry)
+		}
+		roles[group] = append(roles[group], role)
+	}
+	return roles, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOIDCRoleMap(t *testing.T) {
+	got, err := ParseOIDCRoleMap(" rackd-admins=admin, netops=operator,netops=viewer,, ")
+	if err != nil {
+		t.Fatalf("ParseOIDCRoleMap failed: %v", err)
+	}
+	want := map[string][]string{
+		"rackd-admins": {"admin"},
+		"netops":       {"operator", "viewer"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for _, spec := range []string{"netops", "=admin", "netops="} {
+		if _, err := ParseOIDCRoleMap(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestOIDCServiceRoles(t *testing.T) {
+	roleMap := map[string][]string{"admins": {"admin"}, "netops": {"operator"}, "ops": {"operator"}}
+
+	svc := NewOIDCService(nil, nil, nil, roleMap, "", false)
+	if got := svc.roles([]string{"netops", "ops", "admins"}); !reflect.DeepEqual(got, []string{"operator", "admin"}) {
+		t.Fatalf("unexpected roles %v", got)
+	}
+	if got := svc.roles([]string{"contractors"}); len(got) != 0 {
+		t.Fatalf("expected no roles for an unmapped group, got %v", got)
+	}
+
+	svc = NewOIDCService(nil, nil, nil, roleMap, "viewer", false)
+	if got := svc.roles(nil); !reflect.DeepEqual(got, []string{"viewer"}) {
+		t.Fatalf("expected the default role, got %v", got)
+	}
+	if got := svc.roles([]string{"admins"}); !reflect.DeepEqual(got, []string{"admin"}) {
+		t.Fatalf("expected mapped roles to replace the default, got %v", got)
+	}
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/netip"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/storage"
@@ -54,7 +57,8 @@ func (s *PoolService) Create(ctx context.Context, pool *model.NetworkPool) error
 	}
 
 	// Verify network exists
-	if _, err := s.store.GetNetwork(ctx, pool.NetworkID); err != nil {
+	network, err := s.store.GetNetwork(ctx, pool.NetworkID)
+	if err != nil {
 		if errors.Is(err, storage.ErrNetworkNotFound) {
 			return ErrNotFound
 		}
@@ -69,6 +73,13 @@ func (s *PoolService) Create(ctx context.Context, pool *model.NetworkPool) error
 		return ValidationErrors{{Field: "end_ip", Message: "End IP is required"}}
 	}
 
+	if err := validatePoolRange(pool, network); err != nil {
+		return err
+	}
+	if IsDryRun(ctx) {
+		return nil
+	}
+
 	return s.store.CreateNetworkPool(enrichAuditCtx(ctx), pool)
 }
 
@@ -100,19 +111,68 @@ func (s *PoolService) Update(ctx context.Context, pool *model.NetworkPool) error
 		return ValidationErrors{{Field: "name", Message: "Name is required"}}
 	}
 
+	var network *model.Network
+	if pool.NetworkID != "" {
+		var err error
+		network, err = s.store.GetNetwork(ctx, pool.NetworkID)
+		if err != nil && !errors.Is(err, storage.ErrNetworkNotFound) {
+			return err
+		}
+	}
+	if err := validatePoolRange(pool, network); err != nil {
+		return err
+	}
+	if IsDryRun(ctx) {
+		// Report a missing pool as the update would
+		_, err := s.store.GetNetworkPool(ctx, pool.ID)
+		return err
+	}
+
 	return s.store.UpdateNetworkPool(enrichAuditCtx(ctx), pool)
 }
 
-func (s *PoolService) Delete(ctx context.Context, id string) error {
+// validatePoolRange checks that a pool's start and end IPs parse, are in
+// order, and are of the same family as each other and as the network's
+// subnet. network may be nil when it is not known.
+func validatePoolRange(pool *model.NetworkPool, network *model.Network) error {
+	start, err := netip.ParseAddr(pool.StartIP)
+	if err != nil {
+		return ValidationErrors{{Field: "start_ip", Message: "Invalid IP address"}}
+	}
+	end, err := netip.ParseAddr(pool.EndIP)
+	if err != nil {
+		return ValidationErrors{{Field: "end_ip", Message: "Invalid IP address"}}
+	}
+	start, end = start.Unmap(), end.Unmap()
+
+	if start.Is4() != end.Is4() {
+		return ValidationErrors{{Field: "end_ip", Message: "End IP must be the same address family as start IP"}}
+	}
+	if network != nil {
+		if family := subnetFamily(network.Subnet); family != "" && family != ipFamily(pool.StartIP) {
+			return ValidationErrors{{Field: "start_ip", Message: fmt.Sprintf("Pool addresses must be %s to match network subnet %s", familyName(family), network.Subnet)}}
+		}
+	}
+	if end.Less(start) {
+		return ValidationErrors{{Field: "end_ip", Message: "End IP must not be before start IP"}}
+	}
+	return nil
+}
+
+func (s *PoolService) Delete(ctx context.Context, id string, policy model.DeletePolicy) error {
 	if err := requirePermission(ctx, s.store, "pools", "delete"); err != nil {
 		return err
 	}
 
-	if err := s.store.DeleteNetworkPool(enrichAuditCtx(ctx), id); err != nil {
+	if err := checkDeletePolicy(policy); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteNetworkPool(enrichAuditCtx(ctx), id, policy); err != nil {
 		if errors.Is(err, storage.ErrPoolNotFound) {
 			return ErrNotFound
 		}
-		return err
+		return dependentsError(err)
 	}
 	return nil
 }
@@ -157,3 +217,104 @@ func (s *PoolService) GetHeatmap(ctx context.Context, poolID string) ([]storage.
 	}
 	return heatmap, nil
 }
+
+// GetStats returns the used, reserved and free IP counts of a pool
+func (s *PoolService) GetStats(ctx context.Context, poolID string) (*model.PoolStats, error) {
+	if err := requirePermission(ctx, s.store, "pools", "read"); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.store.GetPoolStats(ctx, poolID)
+	if err != nil {
+		if errors.Is(err, storage.ErrPoolNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return stats, nil
+}
+
+// NetworkStats returns the stats of a network's pools with their totals,
+// returning ErrNotFound if the network doesn't exist
+func (s *PoolService) NetworkStats(ctx context.Context, networkID string) (*model.PoolStatsSummary, error) {
+	if err := requirePermission(ctx, s.store, "pools", "list"); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.GetNetwork(ctx, networkID); err != nil {
+		if errors.Is(err, storage.ErrNetworkNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return s.store.GetPoolStatsSummary(ctx, &model.PoolStatsFilter{NetworkID: networkID})
+}
+
+// DatacenterStats returns the stats of the pools of a datacenter's networks
+// with their totals, returning ErrNotFound if the datacenter doesn't exist
+func (s *PoolService) DatacenterStats(ctx context.Context, datacenterID string) (*model.PoolStatsSummary, error) {
+	if err := requirePermission(ctx, s.store, "pools", "list"); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.GetDatacenter(ctx, datacenterID); err != nil {
+		if errors.Is(err, storage.ErrDatacenterNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return s.store.GetPoolStatsSummary(ctx, &model.PoolStatsFilter{DatacenterID: datacenterID})
+}
+
+// ListByNetworkAsOf lists the pools of a network as they were at the given
+// time, returning ErrNotFound if the network did not exist then
+func (s *PoolService) ListByNetworkAsOf(ctx context.Context, networkID string, asOf time.Time) ([]model.NetworkPool, error) {
+	if err := requirePermission(ctx, s.store, "pools", "list"); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.GetNetworkAsOf(ctx, networkID, asOf); err != nil {
+		if errors.Is(err, storage.ErrNetworkNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return s.store.ListNetworkPoolsAsOf(ctx, asOf, &model.NetworkPoolFilter{NetworkID: networkID})
+}
+
+// GetAsOf returns a pool as it was at the given time, or ErrNotFound if it
+// did not exist then
+func (s *PoolService) GetAsOf(ctx context.Context, id string, asOf time.Time) (*model.NetworkPool, error) {
+	if err := requirePermission(ctx, s.store, "pools", "read"); err != nil {
+		return nil, err
+	}
+
+	pool, err := s.store.GetNetworkPoolAsOf(ctx, id, asOf)
+	if err != nil {
+		if errors.Is(err, storage.ErrPoolNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return pool, nil
+}
+
+// History returns every recorded version of a pool, including after it was
+// deleted
+func (s *PoolService) History(ctx context.Context, id string) ([]model.ResourceVersion, error) {
+	if err := requirePermission(ctx, s.store, "pools", "read"); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.store.ListResourceVersions(ctx, "pool", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	return versions, nil
+}
@@ -57,3 +57,40 @@ func TestPoolService_ListByNetworkAndGetHeatmapMapMissingNetworkOrPool(t *testin
 		t.Fatalf("expected pool not found, got %v", err)
 	}
 }
+
+func TestValidatePoolRange(t *testing.T) {
+	v4 := &model.Network{Subnet: "10.0.0.0/24"}
+	v6 := &model.Network{Subnet: "2001:db8::/64"}
+	tests := []struct {
+		name    string
+		start   string
+		end     string
+		network *model.Network
+		field   string
+	}{
+		{"ipv4 range", "10.0.0.10", "10.0.0.20", v4, ""},
+		{"ipv6 range", "2001:db8::1", "2001:db8::ffff:ffff:ffff:ffff", v6, ""},
+		{"unknown network", "2001:db8::1", "2001:db8::2", nil, ""},
+		{"invalid start", "10.0.0", "10.0.0.20", v4, "start_ip"},
+		{"invalid end", "10.0.0.10", "nope", v4, "end_ip"},
+		{"mixed families", "10.0.0.10", "2001:db8::2", v4, "end_ip"},
+		{"ipv6 pool in ipv4 network", "2001:db8::1", "2001:db8::2", v4, "start_ip"},
+		{"ipv4 pool in ipv6 network", "10.0.0.10", "10.0.0.20", v6, "start_ip"},
+		{"reversed", "10.0.0.20", "10.0.0.10", v4, "end_ip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePoolRange(&model.NetworkPool{StartIP: tt.start, EndIP: tt.end}, tt.network)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("expected range to be valid, got %v", err)
+				}
+				return
+			}
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) || verrs[0].Field != tt.field {
+				t.Fatalf("expected validation error on %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/martinsuchenak/rackd/internal/auth"
 	"github.com/martinsuchenak/rackd/internal/log"
 )
 
@@ -25,6 +26,13 @@ func requirePermission(ctx context.Context, checker PermissionChecker, resource,
 		return ErrUnauthenticated
 	}
 
+	// In approval mode a caller that cannot open change requests, such as
+	// an MCP session, may only read
+	if caller.ReadOnly && action != "read" && action != "list" && action != "export" {
+		log.Debug("RBAC: read-only caller", "user_id", caller.UserID, "resource", resource, "action", action)
+		return ErrApprovalRequired
+	}
+
 	// Check OAuth scope restriction: if scopes are set, the requested
 	// resource:action must be in the token's scope list.
 	if caller.Scopes != nil {
@@ -55,3 +63,20 @@ func requirePermission(ctx context.Context, checker PermissionChecker, resource,
 
 	return nil
 }
+
+// requireAdmin limits an operation to users with the admin role, for
+// operations that expose or affect the whole database rather than one
+// resource type
+func requireAdmin(ctx context.Context, checker auth.Checker) error {
+	caller := CallerFrom(ctx)
+	if caller == nil || (caller.UserID == "" && !caller.IsSystem()) {
+		return ErrUnauthenticated
+	}
+	if caller.IsSystem() {
+		return nil
+	}
+	if isAdmin, _ := auth.IsAdmin(ctx, checker, caller.UserID); !isAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -3,11 +3,19 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/storage"
 )
 
+// Depth limits for impact analysis
+const (
+	DefaultImpactDepth = 5
+	MaxImpactDepth     = 10
+)
+
 type RelationshipService struct {
 	store storage.ExtendedStorage
 }
@@ -40,13 +48,73 @@ func (s *RelationshipService) Add(ctx context.Context, parentID, childID, relati
 		return ValidationErrors{{Field: "type", Message: "Relationship type is required"}}
 	}
 
-	if relationshipType != model.RelationshipContains &&
-		relationshipType != model.RelationshipConnectedTo &&
-		relationshipType != model.RelationshipDependsOn {
-		return ValidationErrors{{Field: "type", Message: "Relationship type must be one of: contains, connected_to, depends_on"}}
+	relType, ok := model.LookupRelationshipType(relationshipType)
+	if !ok {
+		return ValidationErrors{{Field: "type", Message: "Relationship type must be one of: " + strings.Join(model.RelationshipTypeNames(), ", ")}}
+	}
+
+	if parentID == childID {
+		return ValidationErrors{{Field: "child_id", Message: "A device cannot be related to itself"}}
+	}
+
+	if relType.Name == model.RelationshipHostedOn {
+		if err := s.validateHostedOn(ctx, parentID, childID); err != nil {
+			return err
+		}
 	}
 
-	return s.store.AddRelationship(enrichAuditCtx(ctx), parentID, childID, relationshipType, notes)
+	if err := s.store.AddRelationship(enrichAuditCtx(ctx), parentID, childID, relationshipType, notes); err != nil {
+		if errors.Is(err, storage.ErrRelationshipCycle) {
+			return ValidationErrors{{Field: "child_id", Message: "The child already " + relType.ParentLabel + " the parent, directly or indirectly; adding this relationship would create a cycle"}}
+		}
+		return err
+	}
+	return nil
+}
+
+// validateHostedOn checks that a hosted_on relationship puts a vm on a
+// device that can host it, and that the vm has no other host
+func (s *RelationshipService) validateHostedOn(ctx context.Context, vmID, hostID string) error {
+	vm, err := s.store.GetDevice(ctx, vmID)
+	if err != nil {
+		if errors.Is(err, storage.ErrDeviceNotFound) {
+			return ValidationErrors{{Field: "parent_id", Message: "Device not found"}}
+		}
+		return err
+	}
+	if vm.Kind != model.DeviceKindVM {
+		return ValidationErrors{{Field: "parent_id", Message: "Only a vm can be hosted on another device"}}
+	}
+
+	host, err := s.store.GetDevice(ctx, hostID)
+	if err != nil {
+		if errors.Is(err, storage.ErrDeviceNotFound) {
+			return ValidationErrors{{Field: "child_id", Message: "Device not found"}}
+		}
+		return err
+	}
+	if !host.Kind.CanHostVMs() {
+		return ValidationErrors{{Field: "child_id", Message: "A " + string(host.Kind) + " device cannot host virtual machines"}}
+	}
+
+	rels, err := s.store.GetRelationships(ctx, vmID)
+	if err != nil {
+		return err
+	}
+	for _, r := range rels {
+		if r.Type == model.RelationshipHostedOn && r.ParentID == vmID && r.ChildID != hostID {
+			return ValidationErrors{{Field: "parent_id", Message: "The vm is already hosted on another device"}}
+		}
+	}
+	return nil
+}
+
+// Types returns the catalog of relationship types
+func (s *RelationshipService) Types(ctx context.Context) ([]model.RelationshipType, error) {
+	if err := requirePermission(ctx, s.store, "relationships", "list"); err != nil {
+		return nil, err
+	}
+	return model.RelationshipTypes, nil
 }
 
 func (s *RelationshipService) Get(ctx context.Context, deviceID string) ([]model.DeviceRelationship, error) {
@@ -73,6 +141,37 @@ func (s *RelationshipService) GetRelated(ctx context.Context, deviceID, relation
 	return s.store.GetRelatedDevices(ctx, deviceID, relationshipType)
 }
 
+// Impact returns the devices affected by a device going down and the devices
+// it depends on, following depends_on, hosted_on and connected_to
+// relationships up to depth hops. A depth of 0 uses DefaultImpactDepth.
+func (s *RelationshipService) Impact(ctx context.Context, deviceID string, depth int) (*model.DeviceImpact, error) {
+	if err := requirePermission(ctx, s.store, "devices", "read"); err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.store, "relationships", "read"); err != nil {
+		return nil, err
+	}
+
+	if deviceID == "" {
+		return nil, ValidationErrors{{Field: "device_id", Message: "Device ID is required"}}
+	}
+	if depth == 0 {
+		depth = DefaultImpactDepth
+	}
+	if depth < 1 || depth > MaxImpactDepth {
+		return nil, ValidationErrors{{Field: "depth", Message: fmt.Sprintf("Depth must be between 1 and %d", MaxImpactDepth)}}
+	}
+
+	impact, err := s.store.GetDeviceImpact(ctx, deviceID, depth)
+	if err != nil {
+		if errors.Is(err, storage.ErrDeviceNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return impact, nil
+}
+
 func (s *RelationshipService) UpdateNotes(ctx context.Context, parentID, childID, relationshipType, notes string) error {
 	if err := requirePermission(ctx, s.store, "relationships", "update"); err != nil {
 		return err
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -38,3 +39,116 @@ func TestRelationshipService_RemoveMapsMissingDeviceToNotFound(t *testing.T) {
 		t.Fatalf("expected not found error, got %v", err)
 	}
 }
+
+func TestRelationshipService_AddRejectsSelfReferenceAndCycles(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "relationships", "create", true)
+	svc := NewRelationshipService(store)
+
+	var verrs ValidationErrors
+	err := svc.Add(userContext("user-1"), "dev-1", "dev-1", model.RelationshipConnectedTo, "")
+	if !errors.As(err, &verrs) || verrs[0].Field != "child_id" {
+		t.Fatalf("expected validation error for a self-reference, got %v", err)
+	}
+	if store.addedParentID != "" {
+		t.Fatal("expected a self-reference not to reach storage")
+	}
+
+	store.addErr = storage.ErrRelationshipCycle
+	err = svc.Add(userContext("user-1"), "dev-1", "dev-2", model.RelationshipDependsOn, "")
+	if !errors.As(err, &verrs) || verrs[0].Field != "child_id" {
+		t.Fatalf("expected validation error for a cycle, got %v", err)
+	}
+}
+
+func TestRelationshipService_TypesListsCatalog(t *testing.T) {
+	store := newServiceTestStorage()
+	store.setPermission("user-1", "relationships", "list", true)
+	svc := NewRelationshipService(store)
+
+	types, err := svc.Types(userContext("user-1"))
+	if err != nil {
+		t.Fatalf("Types returned unexpected error: %v", err)
+	}
+	if len(types) != 4 {
+		t.Fatalf("expected 4 relationship types, got %d", len(types))
+	}
+	for _, rt := range types {
+		if rt.Name == model.RelationshipConnectedTo && (rt.Directed || rt.Acyclic) {
+			t.Errorf("expected connected_to to be undirected and allow cycles, got %+v", rt)
+		}
+		if rt.Name == model.RelationshipContains && (!rt.Directed || !rt.Acyclic) {
+			t.Errorf("expected contains to be directed and acyclic, got %+v", rt)
+		}
+	}
+}
+
+// hostedOnTestStorage adds device and relationship lookups to the service
+// test storage
+type hostedOnTestStorage struct {
+	*serviceTestStorage
+	rels []model.DeviceRelationship
+}
+
+func (s *hostedOnTestStorage) GetDevice(_ context.Context, id string) (*model.Device, error) {
+	device, ok := s.devices[id]
+	if !ok {
+		return nil, storage.ErrDeviceNotFound
+	}
+	return device, nil
+}
+
+func (s *hostedOnTestStorage) GetRelationships(_ context.Context, deviceID string) ([]model.DeviceRelationship, error) {
+	var rels []model.DeviceRelationship
+	for _, r := range s.rels {
+		if r.ParentID == deviceID || r.ChildID == deviceID {
+			rels = append(rels, r)
+		}
+	}
+	return rels, nil
+}
+
+func TestRelationshipService_AddValidatesHostedOn(t *testing.T) {
+	store := &hostedOnTestStorage{serviceTestStorage: newServiceTestStorage()}
+	store.setPermission("user-1", "relationships", "create", true)
+	store.devices = map[string]*model.Device{
+		"vm-1":     {ID: "vm-1", Kind: model.DeviceKindVM},
+		"vm-2":     {ID: "vm-2", Kind: model.DeviceKindVM},
+		"esx-1":    {ID: "esx-1", Kind: model.DeviceKindPhysical},
+		"esx-2":    {ID: "esx-2", Kind: model.DeviceKindPhysical},
+		"pve-1":    {ID: "pve-1", Kind: model.DeviceKindContainerHost},
+		"switch-1": {ID: "switch-1", Kind: model.DeviceKindNetwork},
+	}
+	store.rels = []model.DeviceRelationship{{ParentID: "vm-2", ChildID: "esx-1", Type: model.RelationshipHostedOn}}
+	svc := NewRelationshipService(store)
+	ctx := userContext("user-1")
+
+	tests := []struct {
+		name          string
+		parent, child string
+		field         string
+	}{
+		{"vm on hypervisor", "vm-1", "esx-1", ""},
+		{"vm on container host", "vm-1", "pve-1", ""},
+		{"same host again", "vm-2", "esx-1", ""},
+		{"parent not a vm", "esx-2", "esx-1", "parent_id"},
+		{"host cannot run vms", "vm-1", "switch-1", "child_id"},
+		{"second host", "vm-2", "esx-2", "parent_id"},
+		{"unknown host", "vm-1", "missing", "child_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.Add(ctx, tt.parent, tt.child, model.RelationshipHostedOn, "")
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("expected hosted_on to be accepted, got %v", err)
+				}
+				return
+			}
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) || verrs[0].Field != tt.field {
+				t.Fatalf("expected validation error on %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// ReportService runs the canned reports. Each report needs the list
+// permission of the resources it summarises.
+type ReportService struct {
+	store storage.ExtendedStorage
+	now   func() time.Time
+}
+
+func NewReportService(store storage.ExtendedStorage) *ReportService {
+	return &ReportService{store: store, now: time.Now}
+}
+
+type report struct {
+	info     model.ReportInfo
+	resource string
+	run      func(s *ReportService, ctx context.Context, params model.ReportParams) ([]string, [][]any, error)
+}
+
+var reports = []report{
+	{
+		info:     model.ReportInfo{Name: "devices-per-datacenter", Title: "Devices per datacenter", Description: "Device count per datacenter, by status"},
+		resource: "devices",
+		run:      (*ReportService).devicesPerDatacenter,
+	},
+	{
+		info:     model.ReportInfo{Name: "os-distribution", Title: "OS distribution", Description: "Devices per operating system, leaving out decommissioned devices"},
+		resource: "devices",
+		run:      (*ReportService).osDistribution,
+	},
+	{
+		info:     model.ReportInfo{Name: "ip-utilization", Title: "IP utilization", Description: "Used and available addresses per network, fullest first, with each network's VLAN, gateway, DNS servers and MTU"},
+		resource: "networks",
+		run:      (*ReportService).ipUtilization,
+	},
+	{
+		info:     model.ReportInfo{Name: "warranty-expiry", Title: "Warranty expiry", Description: "Devices whose warranty expires within days (default 90), soonest first"},
+		resource: "devices",
+		run:      (*ReportService).warrantyExpiry,
+	},
+	{
+		info:     model.ReportInfo{Name: "unscanned-networks", Title: "Unscanned networks", Description: "Networks without a completed discovery scan in days (default 30)"},
+		resource: "networks",
+		run:      (*ReportService).unscannedNetworks,
+	},
+	{
+		info:     model.ReportInfo{Name: "exposure", Title: "Exposure", Description: "Open telnet, FTP, remote desktop, file sharing and database ports discovery found on device addresses not labelled as management, by datacenter and tag"},
+		resource: "devices",
+		run:      (*ReportService).exposure,
+	},
+}
+
+// List returns the available reports
+func (s *ReportService) List() []model.ReportInfo {
+	infos := make([]model.ReportInfo, len(reports))
+	for i, r := range reports {
+		infos[i] = r.info
+	}
+	return infos
+}
+
+// Run runs the named report
+func (s *ReportService) Run(ctx context.Context, name string, params model.ReportParams) (*model.Report, error) {
+	i := slices.IndexFunc(reports, func(r report) bool { return r.info.Name == name })
+	if i < 0 {
+		return nil, fmt.Errorf("report %q: %w", name, ErrNotFound)
+	}
+	r := reports[i]
+	if err := requirePermission(ctx, s.store, r.resource, "list"); err != nil {
+		return nil, err
+	}
+	if params.Days < 0 {
+		return nil, ValidationErrors{{Field: "days", Message: "days must not be negative"}}
+	}
+
+	columns, rows, err := r.run(s, ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = [][]any{}
+	}
+	return &model.Report{
+		Name:        r.info.Name,
+		Title:       r.info.Title,
+		GeneratedAt: s.now().UTC(),
+		Columns:     columns,
+		Rows:        rows,
+	}, nil
+}
+
+// datacenterNames maps datacenter IDs to names
+func (s *ReportService) datacenterNames(ctx context.Context) (map[string]string, error) {
+	dcs, err := s.store.ListDatacenters(ctx, &model.DatacenterFilter{Pagination: model.Pagination{Limit: model.MaxPageSize}})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(dcs))
+	for _, dc := range dcs {
+		names[dc.ID] = dc.Name
+	}
+	return names, nil
+}
+
+var reportStatuses = []model.DeviceStatus{
+	model.DeviceStatusPlanned,
+	model.DeviceStatusOrdered,
+	model.DeviceStatusActive,
+	model.DeviceStatusMaintenance,
+	model.DeviceStatusDecommissioned,
+}
+
+func (s *ReportService) devicesPerDatacenter(ctx context.Context, _ model.ReportParams) ([]string, [][]any, error) {
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+	names, err := s.datacenterNames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts := map[string]map[model.DeviceStatus]int{}
+	for _, d := range devices {
+		if counts[d.DatacenterID] == nil {
+			counts[d.DatacenterID] = map[model.DeviceStatus]int{}
+		}
+		counts[d.DatacenterID][d.Status]++
+	}
+
+	columns := []string{"datacenter", "devices"}
+	for _, status := range reportStatuses {
+		columns = append(columns, string(status))
+	}
+	var rows [][]any
+	for dcID, byStatus := range counts {
+		name := names[dcID]
+		if dcID == "" {
+			name = "(none)"
+		}
+		total := 0
+		for _, n := range byStatus {
+			total += n
+		}
+		row := []any{name, total}
+		for _, status := range reportStatuses {
+			row = append(row, byStatus[status])
+		}
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(a, b []any) int {
+		return cmp.Or(cmp.Compare(b[1].(int), a[1].(int)), cmp.Compare(a[0].(string), b[0].(string)))
+	})
+	return columns, rows, nil
+}
+
+func (s *ReportService) osDistribution(ctx context.Context, _ model.ReportParams) ([]string, [][]any, error) {
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts := map[string]int{}
+	total := 0
+	for _, d := range devices {
+		if d.Status == model.DeviceStatusDecommissioned {
+			continue
+		}
+		os := d.OS
+		if os == "" {
+			os = "(unknown)"
+		}
+		counts[os]++
+		total++
+	}
+
+	var rows [][]any
+	for os, n := range counts {
+		rows = append(rows, []any{os, n, percent(n, total)})
+	}
+	slices.SortFunc(rows, func(a, b []any) int {
+		return cmp.Or(cmp.Compare(b[1].(int), a[1].(int)), cmp.Compare(a[0].(string), b[0].(string)))
+	})
+	return []string{"os", "devices", "percent"}, rows, nil
+}
+
+func (s *ReportService) ipUtilization(ctx context.Context, _ model.ReportParams) ([]string, [][]any, error) {
+	networks, err := listAllNetworks(ctx, s.store, model.NetworkFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+	names, err := s.datacenterNames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type networkRow struct {
+		row         []any
+		utilization float64
+	}
+	var sorted []networkRow
+	for _, n := range networks {
+		u, err := s.store.GetNetworkUtilization(ctx, n.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		sorted = append(sorted, networkRow{
+			row: []any{n.Name, n.Subnet, names[n.DatacenterID], addressCount(u.TotalAddresses), u.UsedIPs, addressCount(u.AvailableAddresses), round2(u.Utilization),
+				n.VLANID, n.Gateway, strings.Join(n.DNSServers, " "), n.MTU},
+			utilization: u.Utilization,
+		})
+	}
+	slices.SortStableFunc(sorted, func(a, b networkRow) int {
+		return cmp.Compare(b.utilization, a.utilization)
+	})
+
+	rows := make([][]any, len(sorted))
+	for i, r := range sorted {
+		rows[i] = r.row
+	}
+	return []string{"network", "subnet", "datacenter", "total", "used", "available", "utilization_percent", "vlan_id", "gateway", "dns_servers", "mtu"}, rows, nil
+}
+
+func (s *ReportService) warrantyExpiry(ctx context.Context, params model.ReportParams) ([]string, [][]any, error) {
+	days := cmp.Or(params.Days, 90)
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{WarrantyDays: days})
+	if err != nil {
+		return nil, nil, err
+	}
+	names, err := s.datacenterNames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slices.SortStableFunc(devices, func(a, b model.Device) int {
+		return a.WarrantyExpiry.Compare(*b.WarrantyExpiry)
+	})
+	now := s.now()
+	var rows [][]any
+	for _, d := range devices {
+		daysLeft := int(d.WarrantyExpiry.Sub(now).Hours() / 24)
+		rows = append(rows, []any{d.Name, names[d.DatacenterID], d.MakeModel, string(d.Status), d.WarrantyExpiry.Format(time.DateOnly), daysLeft})
+	}
+	return []string{"device", "datacenter", "make_model", "status", "warranty_expiry", "days_left"}, rows, nil
+}
+
+func (s *ReportService) unscannedNetworks(ctx context.Context, params model.ReportParams) ([]string, [][]any, error) {
+	days := cmp.Or(params.Days, 30)
+	networks, err := listAllNetworks(ctx, s.store, model.NetworkFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+	scans, err := s.store.ListDiscoveryScans(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	names, err := s.datacenterNames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lastScan := map[string]time.Time{}
+	for _, scan := range scans {
+		if scan.Status != model.ScanStatusCompleted || scan.CompletedAt == nil {
+			continue
+		}
+		if scan.CompletedAt.After(lastScan[scan.NetworkID]) {
+			lastScan[scan.NetworkID] = *scan.CompletedAt
+		}
+	}
+
+	now := s.now()
+	cutoff := now.AddDate(0, 0, -days)
+	var rows [][]any
+	for _, n := range networks {
+		last, ok := lastScan[n.ID]
+		if ok && last.After(cutoff) {
+			continue
+		}
+		lastText, daysSince := "never", any("")
+		if ok {
+			lastText = last.UTC().Format(time.RFC3339)
+			daysSince = int(now.Sub(last).Hours() / 24)
+		}
+		rows = append(rows, []any{n.Name, n.Subnet, names[n.DatacenterID], lastText, daysSince})
+	}
+	return []string{"network", "subnet", "datacenter", "last_scan", "days_since_scan"}, rows, nil
+}
+
+// addressCount returns an address count as a number, or as the decimal
+// string when it is too large, as IPv6 subnets often are
+func addressCount(count string) any {
+	if n, err := strconv.ParseInt(count, 10, 64); err == nil {
+		return n
+	}
+	return count
+}
+
+// percent returns n as a percentage of total, to two decimal places
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return round2(float64(n) * 100 / float64(total))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// exposureRisk is a service that should not be reachable outside a
+// management network
+type exposureRisk struct {
+	service string
+	risk    string
+}
+
+// exposureRisks are the risky services by TCP port
+var exposureRisks = map[int]exposureRisk{
+	21:    {"ftp", "cleartext"},
+	23:    {"telnet", "cleartext"},
+	139:   {"netbios", "file-sharing"},
+	445:   {"smb", "file-sharing"},
+	3389:  {"rdp", "remote-desktop"},
+	5900:  {"vnc", "remote-desktop"},
+	1433:  {"mssql", "database"},
+	1521:  {"oracle", "database"},
+	3306:  {"mysql", "database"},
+	5432:  {"postgresql", "database"},
+	6379:  {"redis", "database"},
+	9200:  {"elasticsearch", "database"},
+	11211: {"memcached", "database"},
+	27017: {"mongodb", "database"},
+}
+
+// managementLabels are the address label words that mark an address as on a
+// management network, such as "mgmt", "oob" or "iDRAC"
+var managementLabels = []string{"mgmt", "management", "oob", "ipmi", "bmc", "idrac", "ilo"}
+
+// isManagementLabel reports whether any word of an address label is a
+// management label
+func isManagementLabel(label string) bool {
+	words := strings.FieldsFunc(strings.ToLower(label), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if slices.Contains(managementLabels, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ReportService) exposure(ctx context.Context, params model.ReportParams) ([]string, [][]any, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "list"); err != nil {
+		return nil, nil, err
+	}
+	names, err := s.datacenterNames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter := model.DeviceFilter{}
+	if params.Datacenter != "" {
+		filter.DatacenterID = resolveDatacenter(names, params.Datacenter)
+		if filter.DatacenterID == "" {
+			return nil, nil, ValidationErrors{{Field: "datacenter", Message: "Unknown datacenter " + params.Datacenter}}
+		}
+	}
+	if params.Tag != "" {
+		filter.Tags = []string{params.Tag}
+	}
+	devices, err := listAllDevices(ctx, s.store, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type exposedAddress struct {
+		device  *model.Device
+		address model.Address
+	}
+	byIP := map[string][]exposedAddress{}
+	for i := range devices {
+		for _, addr := range devices[i].Addresses {
+			if addr.IP != "" && !isManagementLabel(addr.Label) {
+				byIP[addr.IP] = append(byIP[addr.IP], exposedAddress{&devices[i], addr})
+			}
+		}
+	}
+	ips := slices.Sorted(maps.Keys(byIP))
+
+	networkNames := map[string]string{}
+	var rows [][]any
+	open := true
+	for chunk := range slices.Chunk(ips, model.MaxPageSize) {
+		ports, err := listAllPortObservations(ctx, s.store, model.PortObservationFilter{IPs: chunk, Open: &open})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range ports {
+			risk, ok := exposureRisks[p.Port]
+			if !ok {
+				continue
+			}
+			service := cmp.Or(p.Service, risk.service)
+			for _, exposed := range byIP[p.IP] {
+				network, err := s.networkName(ctx, networkNames, exposed.address.NetworkID)
+				if err != nil {
+					return nil, nil, err
+				}
+				rows = append(rows, []any{exposed.device.Name, names[exposed.device.DatacenterID], p.IP, network, exposed.address.Label,
+					p.Port, service, risk.risk, p.FirstSeen.UTC().Format(time.RFC3339), p.LastSeen.UTC().Format(time.RFC3339)})
+			}
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b []any) int {
+		return cmp.Or(cmp.Compare(a[0].(string), b[0].(string)), cmp.Compare(a[2].(string), b[2].(string)), cmp.Compare(a[5].(int), b[5].(int)))
+	})
+	return []string{"device", "datacenter", "ip", "network", "address_label", "port", "service", "risk", "first_seen", "last_seen"}, rows, nil
+}
+
+// resolveDatacenter returns the ID of the datacenter whose ID is ref, or else
+// the one named ref, ignoring case; names maps IDs to names
+func resolveDatacenter(names map[string]string, ref string) string {
+	if _, ok := names[ref]; ok {
+		return ref
+	}
+	for id, name := range names {
+		if strings.EqualFold(name, ref) {
+			return id
+		}
+	}
+	return ""
+}
+
+// networkName returns the name of a network, caching it in names. A network
+// that no longer exists has no name.
+func (s *ReportService) networkName(ctx context.Context, names map[string]string, id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+	if name, ok := names[id]; ok {
+		return name, nil
+	}
+	network, err := s.store.GetNetwork(ctx, id)
+	if errors.Is(err, storage.ErrNetworkNotFound) {
+		names[id] = ""
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	names[id] = network.Name
+	return network.Name, nil
+}
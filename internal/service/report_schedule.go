@@ -0,0 +1,341 @@
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/export"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/notify"
+	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/robfig/cron/v3"
+)
+
+// reportScheduleFormats are the attachment formats of scheduled reports, by
+// their content type
+var reportScheduleFormats = map[string]string{
+	"csv":  "text/csv",
+	"pdf":  "application/pdf",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ReportScheduleService manages the schedules that send canned reports to
+// notification channels, and runs them when they are due
+type ReportScheduleService struct {
+	store   storage.ExtendedStorage
+	reports *ReportService
+	now     func() time.Time
+
+	// send delivers a message to a channel; tests replace it
+	send func(ctx context.Context, ch *model.NotificationChannel, msg *notify.Message) error
+}
+
+// NewReportScheduleService creates a report schedule service. Email channels
+// must name their SMTP server until SetNotifier provides a relay.
+func NewReportScheduleService(store storage.ExtendedStorage, reports *ReportService) *ReportScheduleService {
+	s := &ReportScheduleService{store: store, reports: reports, now: time.Now}
+	s.SetNotifier(notify.NewNotifier(store, notify.NewSender(notify.SMTPConfig{})))
+	return s
+}
+
+// SetNotifier sets the notifier reports are sent with, which knows the
+// server's SMTP relay
+func (s *ReportScheduleService) SetNotifier(n *notify.Notifier) {
+	s.send = n.Deliver
+}
+
+func (s *ReportScheduleService) List(ctx context.Context, filter *model.ReportScheduleFilter) ([]model.ReportSchedule, error) {
+	if err := requirePermission(ctx, s.store, "report-schedules", "list"); err != nil {
+		return nil, err
+	}
+	return s.store.ListReportSchedules(ctx, filter)
+}
+
+func (s *ReportScheduleService) Get(ctx context.Context, id string) (*model.ReportSchedule, error) {
+	if err := requirePermission(ctx, s.store, "report-schedules", "read"); err != nil {
+		return nil, err
+	}
+	return s.get(ctx, id)
+}
+
+func (s *ReportScheduleService) get(ctx context.Context, id string) (*model.ReportSchedule, error) {
+	schedule, err := s.store.GetReportSchedule(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrReportScheduleNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Create adds a schedule. The caller must be allowed to run the report, as
+// the schedule runs it with their permissions.
+func (s *ReportScheduleService) Create(ctx context.Context, req *model.CreateReportScheduleRequest) (*model.ReportSchedule, error) {
+	if err := requirePermission(ctx, s.store, "report-schedules", "create"); err != nil {
+		return nil, err
+	}
+
+	schedule := &model.ReportSchedule{
+		Name:           strings.TrimSpace(req.Name),
+		Report:         strings.TrimSpace(req.Report),
+		Days:           req.Days,
+		Datacenter:     strings.TrimSpace(req.Datacenter),
+		Tag:            strings.TrimSpace(req.Tag),
+		Format:         cmp.Or(strings.ToLower(strings.TrimSpace(req.Format)), "csv"),
+		CronExpression: strings.TrimSpace(req.CronExpression),
+		ChannelIDs:     uniqueIDs(trimAll(req.ChannelIDs)),
+		Enabled:        req.Enabled == nil || *req.Enabled,
+		Description:    req.Description,
+	}
+	if caller := CallerFrom(ctx); caller != nil {
+		schedule.CreatedBy = caller.UserID
+	}
+
+	if err := s.validate(ctx, schedule); err != nil {
+		return nil, err
+	}
+	s.scheduleNext(schedule, s.now())
+
+	if err := s.store.CreateReportSchedule(enrichAuditCtx(ctx), schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Update changes a schedule's settings and works out its next run again
+func (s *ReportScheduleService) Update(ctx context.Context, id string, req *model.UpdateReportScheduleRequest) (*model.ReportSchedule, error) {
+	if err := requirePermission(ctx, s.store, "report-schedules", "update"); err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		schedule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Report != nil {
+		schedule.Report = strings.TrimSpace(*req.Report)
+	}
+	if req.Days != nil {
+		schedule.Days = *req.Days
+	}
+	if req.Datacenter != nil {
+		schedule.Datacenter = strings.TrimSpace(*req.Datacenter)
+	}
+	if req.Tag != nil {
+		schedule.Tag = strings.TrimSpace(*req.Tag)
+	}
+	if req.Format != nil {
+		schedule.Format = strings.ToLower(strings.TrimSpace(*req.Format))
+	}
+	if req.CronExpression != nil {
+		schedule.CronExpression = strings.TrimSpace(*req.CronExpression)
+	}
+	if req.ChannelIDs != nil {
+		schedule.ChannelIDs = uniqueIDs(trimAll(*req.ChannelIDs))
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		schedule.Description = *req.Description
+	}
+
+	if err := s.validate(ctx, schedule); err != nil {
+		return nil, err
+	}
+	s.scheduleNext(schedule, s.now())
+
+	if err := s.store.UpdateReportSchedule(enrichAuditCtx(ctx), schedule); err != nil {
+		if errors.Is(err, storage.ErrReportScheduleNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *ReportScheduleService) Delete(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, s.store, "report-schedules", "delete"); err != nil {
+		return err
+	}
+	if err := s.store.DeleteReportSchedule(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrReportScheduleNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Run runs a schedule now, whether or not it is enabled, and returns it with
+// the outcome. A failed run is reported in LastError rather than as an
+// error. The next scheduled run is unchanged.
+func (s *ReportScheduleService) Run(ctx context.Context, id string) (*model.ReportSchedule, error) {
+	if err := requirePermission(ctx, s.store, "report-schedules", "update"); err != nil {
+		return nil, err
+	}
+	schedule, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.run(ctx, schedule, schedule.NextRunAt); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// RunDue runs the enabled schedules that are due at now and returns how many
+// ran. Failed runs are recorded on their schedule.
+func (s *ReportScheduleService) RunDue(ctx context.Context, now time.Time) (int, error) {
+	if err := requirePermission(ctx, s.store, "report-schedules", "update"); err != nil {
+		return 0, err
+	}
+	due, err := s.store.ListDueReportSchedules(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	for i := range due {
+		schedule := &due[i]
+		s.scheduleNext(schedule, now)
+		if err := s.run(ctx, schedule, schedule.NextRunAt); err != nil {
+			return i, err
+		}
+	}
+	return len(due), nil
+}
+
+// run generates the schedule's report as its creator and sends it to the
+// schedule's active channels, then records the outcome and next run on
+// schedule. Only failing to record the run is returned.
+func (s *ReportScheduleService) run(ctx context.Context, schedule *model.ReportSchedule, next *time.Time) error {
+	ranAt := s.now().UTC()
+	runErr := s.deliver(ctx, schedule)
+
+	schedule.LastRunAt = &ranAt
+	schedule.LastError = ""
+	if runErr != nil {
+		schedule.LastError = runErr.Error()
+	}
+	schedule.NextRunAt = next
+	return s.store.RecordReportScheduleRun(ctx, schedule.ID, ranAt, schedule.LastError, next)
+}
+
+// deliver generates the report and sends it to every active channel of the
+// schedule, returning the channels it could not reach
+func (s *ReportScheduleService) deliver(ctx context.Context, schedule *model.ReportSchedule) error {
+	report, err := s.reports.Run(s.creatorContext(ctx, schedule), schedule.Report, schedule.Params())
+	if err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := export.ExportReport(report, export.Format(schedule.Format), &buf); err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+	msg := notify.FormatReport(schedule.Name, report, notify.Attachment{
+		Filename:    report.Name + "-" + report.GeneratedAt.UTC().Format(time.DateOnly) + "." + schedule.Format,
+		ContentType: reportScheduleFormats[schedule.Format],
+		Content:     buf.Bytes(),
+	})
+
+	var failed []string
+	for _, id := range schedule.ChannelIDs {
+		ch, err := s.store.GetNotificationChannel(ctx, id)
+		if errors.Is(err, storage.ErrNotificationChannelNotFound) {
+			failed = append(failed, id+": channel no longer exists")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !ch.Active {
+			continue
+		}
+		if err := s.send(ctx, ch, msg); err != nil {
+			failed = append(failed, ch.Name+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// creatorContext returns ctx acting as the user who created the schedule, or
+// as the system for schedules created without a user
+func (s *ReportScheduleService) creatorContext(ctx context.Context, schedule *model.ReportSchedule) context.Context {
+	if schedule.CreatedBy == "" {
+		return SystemContext(ctx, "report-schedule")
+	}
+	return WithCaller(ctx, &Caller{Type: CallerTypeUser, UserID: schedule.CreatedBy, Source: "report-schedule"})
+}
+
+// scheduleNext sets the schedule's next run after now, or clears it when
+// the schedule is disabled
+func (s *ReportScheduleService) scheduleNext(schedule *model.ReportSchedule, now time.Time) {
+	schedule.NextRunAt = nil
+	if !schedule.Enabled {
+		return
+	}
+	if sched, err := cron.ParseStandard(schedule.CronExpression); err == nil {
+		next := sched.Next(now).UTC()
+		schedule.NextRunAt = &next
+	}
+}
+
+// validate checks a schedule's settings. The caller must be allowed to run
+// the report, and the channels must exist.
+func (s *ReportScheduleService) validate(ctx context.Context, schedule *model.ReportSchedule) error {
+	var errs ValidationErrors
+	if schedule.Name == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "Name is required"})
+	}
+
+	i := slices.IndexFunc(reports, func(r report) bool { return r.info.Name == schedule.Report })
+	if i < 0 {
+		errs = append(errs, ValidationError{Field: "report", Message: "Unknown report: " + schedule.Report})
+	} else if err := requirePermission(ctx, s.store, reports[i].resource, "list"); err != nil {
+		return err
+	}
+	if schedule.Days < 0 {
+		errs = append(errs, ValidationError{Field: "days", Message: "days must not be negative"})
+	}
+	if _, ok := reportScheduleFormats[schedule.Format]; !ok {
+		errs = append(errs, ValidationError{Field: "format", Message: "Format must be one of: csv, pdf, xlsx"})
+	}
+
+	if schedule.CronExpression == "" {
+		errs = append(errs, ValidationError{Field: "cron_expression", Message: "Cron expression is required"})
+	} else if sched, err := cron.ParseStandard(schedule.CronExpression); err != nil {
+		errs = append(errs, ValidationError{Field: "cron_expression", Message: "Invalid cron expression: " + err.Error()})
+	} else {
+		first := sched.Next(s.now())
+		if interval := sched.Next(first).Sub(first); interval < model.MinReportInterval {
+			errs = append(errs, ValidationError{Field: "cron_expression", Message: fmt.Sprintf("Schedule runs too often: every %v (minimum %v)", interval, model.MinReportInterval)})
+		}
+	}
+
+	if len(schedule.ChannelIDs) == 0 {
+		errs = append(errs, ValidationError{Field: "channel_ids", Message: "At least one notification channel is required"})
+	}
+	for _, id := range schedule.ChannelIDs {
+		if _, err := s.store.GetNotificationChannel(ctx, id); errors.Is(err, storage.ErrNotificationChannelNotFound) {
+			errs = append(errs, ValidationError{Field: "channel_ids", Message: "Unknown notification channel: " + id})
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
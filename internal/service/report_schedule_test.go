@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/notify"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// reportScheduleTestStorage keeps report schedules and notification
+// channels in memory
+type reportScheduleTestStorage struct {
+	*notificationTestStorage
+	schedules map[string]*model.ReportSchedule
+}
+
+func newReportScheduleTestStorage() *reportScheduleTestStorage {
+	store := &reportScheduleTestStorage{notificationTestStorage: newNotificationTestStorage(), schedules: map[string]*model.ReportSchedule{}}
+	for _, action := range []string{"list", "read", "create", "update", "delete"} {
+		store.setPermission("user-1", "report-schedules", action, true)
+	}
+	store.setPermission("user-1", "networks", "list", true)
+	store.networks = append(store.networks, model.Network{ID: "net-1", Name: "lan", Subnet: "10.0.0.0/24"})
+	store.networkUtilization = &model.NetworkUtilization{TotalAddresses: "254", UsedIPs: 10, AvailableAddresses: "244", Utilization: 3.94}
+	store.channels["nc-ops"] = &model.NotificationChannel{ID: "nc-ops", Name: "ops", Type: model.NotificationChannelEmail, Active: true}
+	store.channels["nc-off"] = &model.NotificationChannel{ID: "nc-off", Name: "off", Type: model.NotificationChannelWebhook}
+	return store
+}
+
+func (s *reportScheduleTestStorage) CreateReportSchedule(_ context.Context, schedule *model.ReportSchedule) error {
+	schedule.ID = "rs-" + schedule.Name
+	stored := *schedule
+	s.schedules[schedule.ID] = &stored
+	return nil
+}
+
+func (s *reportScheduleTestStorage) GetReportSchedule(_ context.Context, id string) (*model.ReportSchedule, error) {
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return nil, storage.ErrReportScheduleNotFound
+	}
+	cloned := *schedule
+	return &cloned, nil
+}
+
+func (s *reportScheduleTestStorage) ListDueReportSchedules(_ context.Context, now time.Time) ([]model.ReportSchedule, error) {
+	var due []model.ReportSchedule
+	for _, schedule := range s.schedules {
+		if schedule.Enabled && schedule.NextRunAt != nil && !schedule.NextRunAt.After(now) {
+			due = append(due, *schedule)
+		}
+	}
+	return due, nil
+}
+
+func (s *reportScheduleTestStorage) RecordReportScheduleRun(_ context.Context, id string, ranAt time.Time, runErr string, nextRunAt *time.Time) error {
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return storage.ErrReportScheduleNotFound
+	}
+	schedule.LastRunAt, schedule.LastError, schedule.NextRunAt = &ranAt, runErr, nextRunAt
+	return nil
+}
+
+func TestReportScheduleService_CreateValidates(t *testing.T) {
+	store := newReportScheduleTestStorage()
+	svc := NewReportScheduleService(store, NewReportService(store))
+	ctx := userContext("user-1")
+
+	valid := model.CreateReportScheduleRequest{Name: "weekly", Report: "ip-utilization", CronExpression: "0 7 * * 1", ChannelIDs: []string{"nc-ops"}}
+	tests := []struct {
+		name  string
+		edit  func(req *model.CreateReportScheduleRequest)
+		field string
+	}{
+		{"no name", func(req *model.CreateReportScheduleRequest) { req.Name = " " }, "name"},
+		{"unknown report", func(req *model.CreateReportScheduleRequest) { req.Report = "nope" }, "report"},
+		{"negative days", func(req *model.CreateReportScheduleRequest) { req.Days = -1 }, "days"},
+		{"bad format", func(req *model.CreateReportScheduleRequest) { req.Format = "json" }, "format"},
+		{"bad cron", func(req *model.CreateReportScheduleRequest) { req.CronExpression = "every monday" }, "cron_expression"},
+		{"too often", func(req *model.CreateReportScheduleRequest) { req.CronExpression = "*/10 * * * *" }, "cron_expression"},
+		{"no channels", func(req *model.CreateReportScheduleRequest) { req.ChannelIDs = nil }, "channel_ids"},
+		{"unknown channel", func(req *model.CreateReportScheduleRequest) { req.ChannelIDs = []string{"nc-gone"} }, "channel_ids"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.edit(&req)
+			_, err := svc.Create(ctx, &req)
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) || verrs[0].Field != tt.field {
+				t.Errorf("expected a %s validation error, got %v", tt.field, err)
+			}
+		})
+	}
+
+	t.Run("report permission", func(t *testing.T) {
+		req := valid
+		req.Report = "warranty-expiry"
+		if _, err := svc.Create(ctx, &req); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for a report the caller cannot run, got %v", err)
+		}
+	})
+}
+
+func TestReportScheduleService_RunDue(t *testing.T) {
+	store := newReportScheduleTestStorage()
+	reports := NewReportService(store)
+	svc := NewReportScheduleService(store, reports)
+	now := time.Date(2026, 6, 1, 6, 30, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	reports.now = svc.now
+
+	var sent []string
+	var msgs []*notify.Message
+	svc.send = func(_ context.Context, ch *model.NotificationChannel, msg *notify.Message) error {
+		sent = append(sent, ch.Name)
+		msgs = append(msgs, msg)
+		return nil
+	}
+
+	schedule, err := svc.Create(userContext("user-1"), &model.CreateReportScheduleRequest{
+		Name: "daily", Report: "ip-utilization", Format: "PDF", CronExpression: "0 7 * * *", ChannelIDs: []string{"nc-ops", "nc-off", "nc-ops"},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if schedule.Format != "pdf" || len(schedule.ChannelIDs) != 2 || schedule.CreatedBy != "user-1" || !schedule.Enabled {
+		t.Errorf("unexpected schedule %+v", schedule)
+	}
+	if want := now.Add(30 * time.Minute); schedule.NextRunAt == nil || !schedule.NextRunAt.Equal(want) {
+		t.Fatalf("expected the next run at %v, got %v", want, schedule.NextRunAt)
+	}
+
+	sysCtx := SystemContext(context.Background(), "test")
+	if ran, err := svc.RunDue(sysCtx, now); err != nil || ran != 0 {
+		t.Fatalf("expected nothing due yet, got %d (%v)", ran, err)
+	}
+
+	now = now.Add(time.Hour)
+	if ran, err := svc.RunDue(sysCtx, now); err != nil || ran != 1 {
+		t.Fatalf("expected one schedule run, got %d (%v)", ran, err)
+	}
+	if len(sent) != 1 || sent[0] != "ops" {
+		t.Fatalf("expected the report sent to the active channel only, got %v", sent)
+	}
+	attachment := msgs[0].Attachments[0]
+	if attachment.Filename != "ip-utilization-2026-06-01.pdf" || attachment.ContentType != "application/pdf" || !strings.HasPrefix(string(attachment.Content), "%PDF-") {
+		t.Errorf("unexpected attachment %s (%s)", attachment.Filename, attachment.ContentType)
+	}
+	if msgs[0].Event.Type != notify.EventReportScheduled {
+		t.Errorf("expected a %s event, got %s", notify.EventReportScheduled, msgs[0].Event.Type)
+	}
+	got := store.schedules[schedule.ID]
+	if got.LastRunAt == nil || got.LastError != "" || !got.NextRunAt.Equal(time.Date(2026, 6, 2, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the run recorded and the next run tomorrow, got %+v", got)
+	}
+
+	t.Run("failures are recorded", func(t *testing.T) {
+		svc.send = func(context.Context, *model.NotificationChannel, *notify.Message) error {
+			return errors.New("email: no recipients")
+		}
+		got, err := svc.Run(userContext("user-1"), schedule.ID)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if got.LastError != "ops: email: no recipients" {
+			t.Errorf("expected the failed channel recorded, got %q", got.LastError)
+		}
+
+		// The report runs as its creator, who can no longer list networks
+		store.setPermission("user-1", "networks", "list", false)
+		got, _ = svc.Run(userContext("user-1"), schedule.ID)
+		if !strings.HasPrefix(got.LastError, "report: ") {
+			t.Errorf("expected the report error recorded, got %q", got.LastError)
+		}
+	})
+}
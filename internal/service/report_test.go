@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestReportService_Run(t *testing.T) {
+	store := newCloudTestStorage()
+	store.setPermission("user-1", "networks", "list", true)
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "Frankfurt"}
+	store.CreateDatacenter(ctx, dc)
+	for _, d := range []*model.Device{
+		{Name: "web-01", OS: "Debian 12", DatacenterID: dc.ID, Status: model.DeviceStatusActive},
+		{Name: "web-02", OS: "Debian 12", DatacenterID: dc.ID, Status: model.DeviceStatusMaintenance},
+		{Name: "db-01", OS: "Ubuntu 24.04", Status: model.DeviceStatusActive},
+		{Name: "old-01", OS: "CentOS 7", DatacenterID: dc.ID, Status: model.DeviceStatusDecommissioned},
+	} {
+		store.CreateDevice(ctx, d)
+	}
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	scanned, stale := &model.Network{Name: "lan", Subnet: "10.0.0.0/24"}, &model.Network{Name: "dmz", Subnet: "10.0.1.0/24"}
+	store.CreateNetwork(ctx, scanned)
+	store.CreateNetwork(ctx, stale)
+	recent, old := now.AddDate(0, 0, -2), now.AddDate(0, 0, -45)
+	store.discoveryScans["scan-1"] = &model.DiscoveryScan{ID: "scan-1", NetworkID: scanned.ID, Status: model.ScanStatusCompleted, CompletedAt: &recent}
+	store.discoveryScans["scan-2"] = &model.DiscoveryScan{ID: "scan-2", NetworkID: stale.ID, Status: model.ScanStatusCompleted, CompletedAt: &old}
+
+	svc := NewReportService(store)
+	svc.now = func() time.Time { return now }
+	userCtx := userContext("user-1")
+
+	report, err := svc.Run(userCtx, "devices-per-datacenter", model.ReportParams{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := [][]any{
+		{"Frankfurt", 3, 0, 0, 1, 1, 1},
+		{"(none)", 1, 0, 0, 1, 0, 0},
+	}
+	if !reflect.DeepEqual(report.Rows, want) {
+		t.Errorf("devices-per-datacenter: expected %v, got %v", want, report.Rows)
+	}
+
+	report, err = svc.Run(userCtx, "os-distribution", model.ReportParams{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want = [][]any{
+		{"Debian 12", 2, 66.67},
+		{"Ubuntu 24.04", 1, 33.33},
+	}
+	if !reflect.DeepEqual(report.Rows, want) || report.GeneratedAt != now {
+		t.Errorf("os-distribution: expected %v, got %+v", want, report)
+	}
+
+	report, err = svc.Run(userCtx, "unscanned-networks", model.ReportParams{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want = [][]any{{"dmz", "10.0.1.0/24", "", old.Format(time.RFC3339), 45}}
+	if !reflect.DeepEqual(report.Rows, want) {
+		t.Errorf("unscanned-networks: expected %v, got %v", want, report.Rows)
+	}
+	report, err = svc.Run(userCtx, "unscanned-networks", model.ReportParams{Days: 60})
+	if err != nil || len(report.Rows) != 0 {
+		t.Errorf("expected no networks unscanned in 60 days, got %v, %v", report, err)
+	}
+
+	if _, err := svc.Run(userCtx, "missing", model.ReportParams{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found for an unknown report, got %v", err)
+	}
+	if _, err := svc.Run(userContext("user-2"), "os-distribution", model.ReportParams{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected forbidden without devices:list, got %v", err)
+	}
+}
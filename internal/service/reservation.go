@@ -2,12 +2,21 @@ package service
 
 import (
 	"context"
+	"errors"
+	"net/netip"
 	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/storage"
 )
 
+// DefaultAllocationTTL is how long an allocated IP stays reserved when the
+// request does not say
+const DefaultAllocationTTL = time.Hour
+
+// maxAllocationTTL matches the 365 day limit on reservation expiry
+const maxAllocationTTL = 365 * 24 * time.Hour
+
 type ReservationService struct {
 	store storage.ExtendedStorage
 }
@@ -139,6 +148,102 @@ func (s *ReservationService) Create(ctx context.Context, req *model.CreateReserv
 	return nil, ValidationErrors{{Field: "ip_address", Message: "Failed to automatically allocate IP due to high contention, please try again later"}}
 }
 
+// Allocate reserves the next free IP of a pool, optionally for a device.
+// Unlike Pools.GetNextIP, which only reports the IP, the reservation holds it
+// until it is released or its TTL runs out, so concurrent callers each get a
+// different IP.
+func (s *ReservationService) Allocate(ctx context.Context, poolID string, req *model.AllocateIPRequest) (*model.Reservation, error) {
+	if err := requirePermission(ctx, s.store, "reservations", "create"); err != nil {
+		return nil, err
+	}
+
+	if poolID == "" {
+		return nil, ValidationErrors{{Field: "pool_id", Message: "Pool ID is required"}}
+	}
+
+	ttl := DefaultAllocationTTL
+	if req.TTLSeconds < 0 {
+		return nil, ValidationErrors{{Field: "ttl_seconds", Message: "TTL must not be negative"}}
+	}
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > maxAllocationTTL {
+		return nil, ValidationErrors{{Field: "ttl_seconds", Message: "TTL cannot exceed 365 days"}}
+	}
+
+	if req.DeviceID != "" {
+		if _, err := s.store.GetDevice(ctx, req.DeviceID); err != nil {
+			if errors.Is(err, storage.ErrDeviceNotFound) {
+				return nil, ValidationErrors{{Field: "device_id", Message: "Device not found"}}
+			}
+			return nil, err
+		}
+	}
+
+	// Get caller info for reserved_by field
+	caller := CallerFrom(ctx)
+	reservedBy := "system"
+	if caller != nil && caller.UserID != "" {
+		reservedBy = caller.UserID
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+	reservation := &model.Reservation{
+		PoolID:     poolID,
+		DeviceID:   req.DeviceID,
+		Hostname:   req.Hostname,
+		Purpose:    req.Purpose,
+		ReservedBy: reservedBy,
+		ReservedAt: now,
+		ExpiresAt:  &expiresAt,
+		Status:     model.ReservationStatusActive,
+		Notes:      req.Notes,
+	}
+
+	if err := s.store.AllocateIP(enrichAuditCtx(ctx), reservation); err != nil {
+		if errors.Is(err, storage.ErrPoolNotFound) {
+			return nil, ErrNotFound
+		}
+		if errors.Is(err, storage.ErrIPNotAvailable) {
+			return nil, ErrIPNotAvailable
+		}
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// ReleaseIP releases the active reservation of an IP in a pool, returning
+// ErrNotFound if the IP is not reserved
+func (s *ReservationService) ReleaseIP(ctx context.Context, poolID, ip string) error {
+	if err := requirePermission(ctx, s.store, "reservations", "update"); err != nil {
+		return err
+	}
+
+	if poolID == "" {
+		return ValidationErrors{{Field: "pool_id", Message: "Pool ID is required"}}
+	}
+	if ip == "" {
+		return ValidationErrors{{Field: "ip_address", Message: "IP address is required"}}
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ValidationErrors{{Field: "ip_address", Message: "Invalid IP address"}}
+	}
+
+	reservation, err := s.store.GetReservationByIP(ctx, poolID, addr.Unmap().String())
+	if err != nil {
+		if errors.Is(err, storage.ErrReservationNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	reservation.Status = model.ReservationStatusReleased
+	return s.store.UpdateReservation(enrichAuditCtx(ctx), reservation)
+}
+
 // Update updates an existing reservation
 func (s *ReservationService) Update(ctx context.Context, id string, req *model.UpdateReservationRequest) (*model.Reservation, error) {
 	if err := requirePermission(ctx, s.store, "reservations", "update"); err != nil {
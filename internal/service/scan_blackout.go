@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// MaxBlackoutDuration bounds how long a cron blackout window may last
+const MaxBlackoutDuration = 7 * 24 * time.Hour
+
+func (s *DiscoveryService) ListScanBlackouts(ctx context.Context, networkID string) ([]model.ScanBlackout, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "list"); err != nil {
+		return nil, err
+	}
+	return s.store.ListScanBlackouts(ctx, networkID)
+}
+
+func (s *DiscoveryService) GetScanBlackout(ctx context.Context, id string) (*model.ScanBlackout, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "read"); err != nil {
+		return nil, err
+	}
+
+	blackout, err := s.store.GetScanBlackout(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrScanBlackoutNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return blackout, nil
+}
+
+func (s *DiscoveryService) CreateScanBlackout(ctx context.Context, blackout *model.ScanBlackout) error {
+	if err := requirePermission(ctx, s.store, "discovery", "create"); err != nil {
+		return err
+	}
+	if err := s.validateScanBlackout(ctx, blackout); err != nil {
+		return err
+	}
+	return s.store.CreateScanBlackout(enrichAuditCtx(ctx), blackout)
+}
+
+func (s *DiscoveryService) UpdateScanBlackout(ctx context.Context, blackout *model.ScanBlackout) error {
+	if err := requirePermission(ctx, s.store, "discovery", "update"); err != nil {
+		return err
+	}
+	if blackout.ID == "" {
+		return ValidationErrors{{Field: "id", Message: "ID is required"}}
+	}
+	if err := s.validateScanBlackout(ctx, blackout); err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateScanBlackout(enrichAuditCtx(ctx), blackout); err != nil {
+		if errors.Is(err, storage.ErrScanBlackoutNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *DiscoveryService) DeleteScanBlackout(ctx context.Context, id string) error {
+	if err := requirePermission(ctx, s.store, "discovery", "delete"); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteScanBlackout(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrScanBlackoutNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *DiscoveryService) validateScanBlackout(ctx context.Context, blackout *model.ScanBlackout) error {
+	var errs ValidationErrors
+	blackout.Name = strings.TrimSpace(blackout.Name)
+	blackout.Description = strings.TrimSpace(blackout.Description)
+
+	if blackout.Name == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "Name is required"})
+	}
+	if blackout.NetworkID != "" {
+		if _, err := s.store.GetNetwork(ctx, blackout.NetworkID); err != nil {
+			if !errors.Is(err, storage.ErrNetworkNotFound) {
+				return err
+			}
+			errs = append(errs, ValidationError{Field: "network_id", Message: "Network not found"})
+		}
+	}
+	if _, err := blackout.Location(); err != nil {
+		errs = append(errs, ValidationError{Field: "timezone", Message: "Unknown time zone"})
+	}
+
+	switch blackout.Type {
+	case model.BlackoutWeekly:
+		for i, day := range blackout.Weekdays {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if _, ok := model.BlackoutWeekdays[day]; !ok {
+				errs = append(errs, ValidationError{Field: "weekdays", Message: fmt.Sprintf("Unknown weekday %q, use mon, tue, wed, thu, fri, sat or sun", day)})
+			}
+			blackout.Weekdays[i] = day
+		}
+		if _, err := time.Parse("15:04", blackout.StartTime); err != nil {
+			errs = append(errs, ValidationError{Field: "start_time", Message: "Start time must be HH:MM"})
+		}
+		if _, err := time.Parse("15:04", blackout.EndTime); err != nil {
+			errs = append(errs, ValidationError{Field: "end_time", Message: "End time must be HH:MM"})
+		}
+		blackout.CronExpression = ""
+		blackout.DurationMinutes = 0
+	case model.BlackoutCron:
+		if _, err := blackout.Schedule(); err != nil {
+			errs = append(errs, ValidationError{Field: "cron_expression", Message: "Cron expression is not valid"})
+		}
+		if blackout.DurationMinutes <= 0 || time.Duration(blackout.DurationMinutes)*time.Minute > MaxBlackoutDuration {
+			errs = append(errs, ValidationError{Field: "duration_minutes", Message: fmt.Sprintf("Duration must be between 1 and %d minutes", int(MaxBlackoutDuration.Minutes()))})
+		}
+		blackout.Weekdays = nil
+		blackout.StartTime = ""
+		blackout.EndTime = ""
+	default:
+		errs = append(errs, ValidationError{Field: "type", Message: "Type must be weekly or cron"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
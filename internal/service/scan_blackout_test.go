@@ -0,0 +1,50 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestDiscoveryService_CreateScanBlackoutValidation(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "create", true)
+	svc := NewDiscoveryService(store, nil)
+
+	tests := []struct {
+		name     string
+		blackout model.ScanBlackout
+		fields   []string
+	}{
+		{"unknown type", model.ScanBlackout{NetworkID: "missing", Type: "daily", Timezone: "Mars/Olympus"}, []string{"name", "network_id", "type", "timezone"}},
+		{"weekly", model.ScanBlackout{Name: "freeze", Type: model.BlackoutWeekly, Weekdays: []string{"monday"}, StartTime: "25:00"}, []string{"weekdays", "start_time", "end_time"}},
+		{"cron", model.ScanBlackout{Name: "freeze", Type: model.BlackoutCron, CronExpression: "every day"}, []string{"cron_expression", "duration_minutes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.CreateScanBlackout(userContext("user-1"), &tt.blackout)
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("expected validation errors, got %v", err)
+			}
+			fields := make(map[string]bool)
+			for _, v := range verrs {
+				fields[v.Field] = true
+			}
+			for _, f := range tt.fields {
+				if !fields[f] {
+					t.Errorf("expected validation error for %s, got %v", f, verrs)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscoveryService_CreateScanBlackoutRequiresPermission(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	blackout := &model.ScanBlackout{Name: "freeze", Type: model.BlackoutWeekly, StartTime: "22:00", EndTime: "06:00"}
+	if err := NewDiscoveryService(store, nil).CreateScanBlackout(userContext("user-1"), blackout); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// SearchLimits bounds the cost of a single search so broad queries cannot
+// tie up the SQLite connection. Zero values disable a limit.
+type SearchLimits struct {
+	// MaxRows is the number of matches a search may examine
+	MaxRows int
+	// Timeout is how long a search may run
+	Timeout time.Duration
+}
+
+// DefaultSearchLimits are used until Services.SetSearchLimits is called
+var DefaultSearchLimits = SearchLimits{MaxRows: 1000, Timeout: 5 * time.Second}
+
+var searchHints = []string{
+	"use a longer or more specific search term",
+	"use the list endpoints with filters (tags, datacenter_id, network_id, status) and limit/offset instead",
+}
+
+// search runs fn under the limits, turning a limit being hit into a
+// QueryTooBroadError
+func (l SearchLimits) search(ctx context.Context, fn func(ctx context.Context) error) error {
+	if l.MaxRows > 0 {
+		ctx = storage.WithSearchLimit(ctx, l.MaxRows)
+	}
+	searchCtx := ctx
+	if l.Timeout > 0 {
+		var cancel context.CancelFunc
+		searchCtx, cancel = context.WithTimeout(ctx, l.Timeout)
+		defer cancel()
+	}
+
+	err := fn(searchCtx)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, storage.ErrSearchTooBroad):
+		return &QueryTooBroadError{
+			Reason: fmt.Sprintf("more than %d matches", l.MaxRows),
+			Hints:  searchHints,
+		}
+	case ctx.Err() == nil && errors.Is(searchCtx.Err(), context.DeadlineExceeded):
+		return &QueryTooBroadError{
+			Reason: fmt.Sprintf("search took longer than %s", l.Timeout),
+			Hints:  searchHints,
+		}
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+func TestSearchLimits_TooManyRows(t *testing.T) {
+	limits := SearchLimits{MaxRows: 10}
+	err := limits.search(context.Background(), func(ctx context.Context) error {
+		return storage.ErrSearchTooBroad
+	})
+
+	var tooBroad *QueryTooBroadError
+	if !errors.As(err, &tooBroad) {
+		t.Fatalf("expected QueryTooBroadError, got %v", err)
+	}
+	if !errors.Is(err, ErrQueryTooBroad) {
+		t.Error("expected error to match ErrQueryTooBroad")
+	}
+	if len(tooBroad.Hints) == 0 {
+		t.Error("expected hints")
+	}
+}
+
+func TestSearchLimits_Timeout(t *testing.T) {
+	limits := SearchLimits{Timeout: 10 * time.Millisecond}
+	err := limits.search(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, ErrQueryTooBroad) {
+		t.Fatalf("expected ErrQueryTooBroad, got %v", err)
+	}
+}
+
+func TestSearchLimits_CallerCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	limits := SearchLimits{Timeout: time.Second}
+	err := limits.search(ctx, func(ctx context.Context) error {
+		return ctx.Err()
+	})
+	if errors.Is(err, ErrQueryTooBroad) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSearchLimits_PassesOtherErrors(t *testing.T) {
+	want := errors.New("boom")
+	err := DefaultSearchLimits.search(context.Background(), func(ctx context.Context) error {
+		return want
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("expected %v, got %v", want, err)
+	}
+}
@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/log"
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -56,6 +61,8 @@ type serviceTestStorage struct {
 	dashboardStaleDays int
 	dashboardRecentLimit int
 	utilTrendDays int
+	inventoryMetrics []model.InventoryMetric
+	metricFilter     *model.InventoryMetricFilter
 	bulkResult *storage.BulkResult
 	lastBulkOp string
 	auditLogs []model.AuditLog
@@ -70,6 +77,7 @@ type serviceTestStorage struct {
 	removedChildID  string
 	removedType     string
 	removeErr       error
+	addErr          error
 	addedParentID   string
 	addedChildID    string
 	addedType       string
@@ -84,6 +92,10 @@ type serviceTestStorage struct {
 	datacenterDevices    map[string][]model.Device
 	networkDevices       map[string][]model.Device
 	discoveredByNetwork  map[string][]model.DiscoveredDevice
+	maintenanceWindows   []model.MaintenanceWindow
+	sshHostKeys          []model.SSHHostKey
+	changes              map[string]*model.ChangeRequest
+	changeFilter         *model.ChangeRequestFilter
 }
 
 func newServiceTestStorage() *serviceTestStorage {
@@ -106,6 +118,7 @@ func newServiceTestStorage() *serviceTestStorage {
 		datacenterDevices: make(map[string][]model.Device),
 		networkDevices: make(map[string][]model.Device),
 		discoveredByNetwork: make(map[string][]model.DiscoveredDevice),
+		changes:     make(map[string]*model.ChangeRequest),
 	}
 }
 
@@ -226,7 +239,7 @@ func (s *serviceTestStorage) UpdateDatacenter(_ context.Context, dc *model.Datac
 	return storage.ErrDatacenterNotFound
 }
 
-func (s *serviceTestStorage) DeleteDatacenter(_ context.Context, id string) error {
+func (s *serviceTestStorage) DeleteDatacenter(_ context.Context, id string, _ model.DeletePolicy) error {
 	for i := range s.datacenters {
 		if s.datacenters[i].ID == id {
 			s.datacenters = append(s.datacenters[:i], s.datacenters[i+1:]...)
@@ -418,6 +431,26 @@ func (s *serviceTestStorage) DeleteCustomFieldValue(_ context.Context, _, _ stri
 	return s.deleteCustomFieldErr
 }
 
+func (s *serviceTestStorage) GetDevice(_ context.Context, id string) (*model.Device, error) {
+	device, ok := s.devices[id]
+	if !ok {
+		return nil, storage.ErrDeviceNotFound
+	}
+	cloned := *device
+	return &cloned, nil
+}
+
+func (s *serviceTestStorage) FindDevicesByName(_ context.Context, name string) ([]model.Device, error) {
+	matches := []model.Device{}
+	for _, device := range s.devices {
+		if strings.EqualFold(device.Name, name) {
+			matches = append(matches, *device)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches, nil
+}
+
 func (s *serviceTestStorage) CreateDevice(_ context.Context, device *model.Device) error {
 	cloned := *device
 	s.devices[cloned.ID] = &cloned
@@ -462,7 +495,7 @@ func (s *serviceTestStorage) UpdateNetwork(_ context.Context, network *model.Net
 	return storage.ErrNetworkNotFound
 }
 
-func (s *serviceTestStorage) DeleteNetwork(_ context.Context, id string) error {
+func (s *serviceTestStorage) DeleteNetwork(_ context.Context, id string, _ model.DeletePolicy) error {
 	for i := range s.networks {
 		if s.networks[i].ID == id {
 			s.networks = append(s.networks[:i], s.networks[i+1:]...)
@@ -518,6 +551,34 @@ func (s *serviceTestStorage) ListDiscoveredDevices(_ context.Context, networkID
 	return append([]model.DiscoveredDevice(nil), s.discoveredByNetwork[networkID]...), nil
 }
 
+// ListMaintenanceWindows only filters by status, which is all the device,
+// monitor and discovery services ask for
+func (s *serviceTestStorage) ListMaintenanceWindows(_ context.Context, filter *model.MaintenanceWindowFilter) ([]model.MaintenanceWindow, error) {
+	now := time.Now()
+	windows := []model.MaintenanceWindow{}
+	for _, w := range s.maintenanceWindows {
+		w.Status = w.StatusAt(now)
+		if filter.Status == model.MaintenanceCurrent && w.Status == model.MaintenancePast {
+			continue
+		}
+		if filter.Status != "" && filter.Status != model.MaintenanceCurrent && filter.Status != w.Status {
+			continue
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func (s *serviceTestStorage) ListSSHHostFingerprints(_ context.Context, ips []string) ([]model.SSHHostKey, error) {
+	keys := []model.SSHHostKey{}
+	for _, key := range s.sshHostKeys {
+		if slices.Contains(ips, key.IP) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
 func (s *serviceTestStorage) GetDiscoveredDevice(_ context.Context, id string) (*model.DiscoveredDevice, error) {
 	for _, devices := range s.discoveredByNetwork {
 		for i := range devices {
@@ -564,7 +625,7 @@ func (s *serviceTestStorage) GetDiscoveryRule(_ context.Context, id string) (*mo
 	return &cloned, nil
 }
 
-func (s *serviceTestStorage) DeleteDevice(_ context.Context, id string) error {
+func (s *serviceTestStorage) DeleteDevice(_ context.Context, id string, _ model.DeletePolicy) error {
 	if s.deleteDeviceErr != nil {
 		return s.deleteDeviceErr
 	}
@@ -575,6 +636,19 @@ func (s *serviceTestStorage) DeleteDevice(_ context.Context, id string) error {
 	return nil
 }
 
+// TrashResource removes the resource as a delete would; the mock keeps no trash
+func (s *serviceTestStorage) TrashResource(ctx context.Context, resourceType, id string, policy model.DeletePolicy) error {
+	switch resourceType {
+	case model.TrashTypeDevice:
+		return s.DeleteDevice(ctx, id, policy)
+	case model.TrashTypeNetwork:
+		return s.DeleteNetwork(ctx, id, policy)
+	case model.TrashTypeDatacenter:
+		return s.DeleteDatacenter(ctx, id, policy)
+	}
+	return nil
+}
+
 func (s *serviceTestStorage) SaveDiscoveryRule(_ context.Context, rule *model.DiscoveryRule) error {
 	cloned := *rule
 	if cloned.ID == "" {
@@ -689,6 +763,11 @@ func (s *serviceTestStorage) GetUtilizationTrend(_ context.Context, _ model.Snap
 	return []model.UtilizationTrendPoint{}, nil
 }
 
+func (s *serviceTestStorage) ListInventoryMetrics(_ context.Context, filter *model.InventoryMetricFilter) ([]model.InventoryMetric, error) {
+	s.metricFilter = filter
+	return s.inventoryMetrics, nil
+}
+
 func (s *serviceTestStorage) BulkCreateDevices(_ context.Context, _ []*model.Device) (*storage.BulkResult, error) {
 	s.lastBulkOp = "create-devices"
 	return s.bulkResult, nil
@@ -724,6 +803,11 @@ func (s *serviceTestStorage) BulkDeleteNetworks(_ context.Context, _ []string) (
 	return s.bulkResult, nil
 }
 
+func (s *serviceTestStorage) ApplyBulkDeviceOperations(_ context.Context, _ *model.BulkDeviceRequest, _ string) (*storage.BulkResult, error) {
+	s.lastBulkOp = "apply-device-operations"
+	return s.bulkResult, nil
+}
+
 func (s *serviceTestStorage) ListAuditLogs(_ context.Context, _ *model.AuditFilter) ([]model.AuditLog, error) {
 	return append([]model.AuditLog(nil), s.auditLogs...), nil
 }
@@ -743,7 +827,7 @@ func (s *serviceTestStorage) AddRelationship(_ context.Context, parentID, childI
 	s.addedChildID = childID
 	s.addedType = relationshipType
 	s.addedNotes = notes
-	return nil
+	return s.addErr
 }
 
 func (s *serviceTestStorage) RemoveRelationship(_ context.Context, parentID, childID, relationshipType string) error {
@@ -761,6 +845,47 @@ func (s *stubSessionInvalidator) InvalidateUserSessions(userID string) {
 	s.invalidated = append(s.invalidated, userID)
 }
 
+func (s *serviceTestStorage) CreateChangeRequest(_ context.Context, change *model.ChangeRequest) error {
+	change.ID = fmt.Sprintf("change-%d", len(s.changes)+1)
+	change.Status = model.ChangePending
+	cloned := *change
+	s.changes[change.ID] = &cloned
+	return nil
+}
+
+func (s *serviceTestStorage) GetChangeRequest(_ context.Context, id string) (*model.ChangeRequest, error) {
+	change, ok := s.changes[id]
+	if !ok {
+		return nil, storage.ErrChangeRequestNotFound
+	}
+	cloned := *change
+	return &cloned, nil
+}
+
+func (s *serviceTestStorage) ListChangeRequests(_ context.Context, filter *model.ChangeRequestFilter) ([]model.ChangeRequest, error) {
+	s.changeFilter = filter
+	var changes []model.ChangeRequest
+	for _, change := range s.changes {
+		if filter.RequestedBy == "" || change.RequestedBy == filter.RequestedBy {
+			changes = append(changes, *change)
+		}
+	}
+	return changes, nil
+}
+
+func (s *serviceTestStorage) ReviewChangeRequest(_ context.Context, change *model.ChangeRequest) error {
+	stored, ok := s.changes[change.ID]
+	if !ok {
+		return storage.ErrChangeRequestNotFound
+	}
+	if stored.Status != model.ChangePending {
+		return storage.ErrChangeNotPending
+	}
+	cloned := *change
+	s.changes[change.ID] = &cloned
+	return nil
+}
+
 func userContext(userID string) context.Context {
 	return WithCaller(context.Background(), &Caller{Type: CallerTypeUser, UserID: userID})
 }
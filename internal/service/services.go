@@ -5,38 +5,53 @@ import (
 	"github.com/martinsuchenak/rackd/internal/credentials"
 	"github.com/martinsuchenak/rackd/internal/discovery"
 	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/martinsuchenak/rackd/internal/validate"
 )
 
 type Services struct {
-	Devices        *DeviceService
-	Datacenters    *DatacenterService
-	Networks       *NetworkService
-	Pools          *PoolService
-	Relationships  *RelationshipService
-	Discovery      *DiscoveryService
-	Users          *UserService
-	Roles          *RoleService
-	Auth           *AuthService
-	Audit          *AuditService
-	Logs           *LogService
-	APIKeys        *APIKeyService
-	Bulk           *BulkService
-	Credentials    *CredentialService
-	ScanProfiles   *ScanProfileService
-	ScheduledScans *ScheduledScanService
-	OAuth          *OAuthService
-	Conflicts      *ConflictService
-	Reservations   *ReservationService
-	Dashboard      *DashboardService
-	Webhooks       *WebhookService
-	CustomFields   *CustomFieldService
-	Circuits       *CircuitService
-	NAT            *NATService
-	DNS            *DNSService
+	Devices         *DeviceService
+	Datacenters     *DatacenterService
+	Networks        *NetworkService
+	Pools           *PoolService
+	Relationships   *RelationshipService
+	Discovery       *DiscoveryService
+	Users           *UserService
+	Roles           *RoleService
+	Auth            *AuthService
+	Audit           *AuditService
+	Logs            *LogService
+	APIKeys         *APIKeyService
+	Bulk            *BulkService
+	Credentials     *CredentialService
+	ScanProfiles    *ScanProfileService
+	ScheduledScans  *ScheduledScanService
+	OAuth           *OAuthService
+	OIDC            *OIDCService
+	Conflicts       *ConflictService
+	Reservations    *ReservationService
+	Dashboard       *DashboardService
+	Reports         *ReportService
+	ReportSchedules *ReportScheduleService
+	Webhooks        *WebhookService
+	Notifications   *NotificationService
+	CustomFields    *CustomFieldService
+	Circuits        *CircuitService
+	NAT             *NATService
+	DNS             *DNSService
+	Cloud           *CloudService
+	Hypervisors     *HypervisorService
+	Agents          *AgentService
+	Monitor         *MonitorService
+	Maintenance     *MaintenanceService
+	Topology        *TopologyService
+	Trash           *TrashService
+	Backups         *BackupService
+	Database        *DatabaseService
+	Changes         *ChangeService
 }
 
 func NewServices(store storage.ExtendedStorage, sessionManager *auth.SessionManager, scanner discovery.Scanner) *Services {
-	return &Services{
+	s := &Services{
 		Devices:       NewDeviceService(store),
 		Datacenters:   NewDatacenterService(store),
 		Networks:      NewNetworkService(store),
@@ -53,10 +68,53 @@ func NewServices(store storage.ExtendedStorage, sessionManager *auth.SessionMana
 		Conflicts:     NewConflictService(store),
 		Reservations:  NewReservationService(store),
 		Dashboard:     NewDashboardService(store),
+		Reports:       NewReportService(store),
 		Webhooks:      NewWebhookService(store),
+		Notifications: NewNotificationService(store),
 		CustomFields:  NewCustomFieldService(store),
 		Circuits:      NewCircuitService(store),
 		NAT:           NewNATService(store),
+		Agents:        NewAgentService(store),
+		Monitor:       NewMonitorService(store),
+		Maintenance:   NewMaintenanceService(store),
+		Topology:      NewTopologyService(store),
+		Trash:         NewTrashService(store),
+		Backups:       NewBackupService(store),
+		Database:      NewDatabaseService(store),
+	}
+	s.Cloud = NewCloudService(store, s.Devices)
+	s.ReportSchedules = NewReportScheduleService(store, s.Reports)
+	// Include availability status in device responses
+	s.Devices.setMonitorService(s.Monitor)
+	return s
+}
+
+// SetSearchLimits sets the cost limits applied to device, network and
+// datacenter searches
+func (s *Services) SetSearchLimits(limits SearchLimits) {
+	s.Devices.searchLimits = limits
+	s.Networks.searchLimits = limits
+	s.Datacenters.searchLimits = limits
+}
+
+// SetValidationPolicies sets the policies devices are checked against when
+// created or updated
+func (s *Services) SetValidationPolicies(policies *validate.Policies) {
+	s.Devices.policies = policies
+}
+
+// SetUniqueDeviceNames makes device creates and updates fail with
+// ErrAlreadyExists when another device has the same name, ignoring case
+func (s *Services) SetUniqueDeviceNames(unique bool) {
+	s.Devices.uniqueNames = unique
+}
+
+// SetApprovalMode makes changes by users who are not admins wait for an
+// admin's approval as change requests. Changes is nil when it is off.
+func (s *Services) SetApprovalMode(enabled bool) {
+	s.Changes = nil
+	if enabled {
+		s.Changes = NewChangeService(s.Users.store)
 	}
 }
 
@@ -79,3 +137,9 @@ func (s *Services) SetDNSService(store storage.ExtendedStorage, encryptor *crede
 	// Set DeviceService on DNSService for promote operations
 	s.DNS.setDeviceService(s.Devices)
 }
+
+// SetHypervisorService enables hypervisor connectors, whose secrets need the
+// encryption key
+func (s *Services) SetHypervisorService(store storage.ExtendedStorage, encryptor *credentials.Encryptor) {
+	s.Hypervisors = NewHypervisorService(store, encryptor, s.Devices)
+}
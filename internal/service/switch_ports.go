@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/discovery"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// DefaultSwitchTag marks the devices whose neighbor tables are collected
+const DefaultSwitchTag = "switch"
+
+// CollectSwitchPorts reads the LLDP and CDP neighbor tables over SNMP of
+// every device carrying tag, "switch" when empty, and maps each neighbor to a
+// documented device by MAC address, then IP, then name. An address without a
+// switch port gets the port the neighbor was seen on; one with a different
+// port is reported as a mismatch and left alone. Every matched device is
+// linked to its switch with a connected_to relationship.
+func (s *DiscoveryService) CollectSwitchPorts(ctx context.Context, credentialID, tag string) (*model.SwitchPortReport, error) {
+	if err := requirePermission(ctx, s.store, "discovery", "create"); err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.store, "devices", "update"); err != nil {
+		return nil, err
+	}
+	if credentialID == "" {
+		return nil, ValidationErrors{{Field: "credential_id", Message: "SNMP credential ID is required"}}
+	}
+	collector, ok := s.scanner.(discovery.SwitchNeighborCollector)
+	if !ok {
+		return nil, ValidationErrors{{Field: "credential_id", Message: "Switch neighbor collection is not supported by this scanner"}}
+	}
+	if tag == "" {
+		tag = DefaultSwitchTag
+	}
+
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{})
+	if err != nil {
+		return nil, err
+	}
+	index := newNeighborIndex(devices)
+
+	report := &model.SwitchPortReport{Mappings: []model.SwitchPortMapping{}, Errors: []model.SwitchPortError{}}
+	fills := make(map[string]map[string]string) // device ID -> address ID -> port
+	for i := range devices {
+		sw := &devices[i]
+		if !hasTag(sw, tag) {
+			continue
+		}
+		report.Switches++
+
+		ip := managementIP(sw)
+		if ip == "" {
+			report.Errors = append(report.Errors, model.SwitchPortError{SwitchID: sw.ID, SwitchName: sw.Name, Error: "switch has no IP address"})
+			continue
+		}
+		neighbors, err := collector.SwitchNeighbors(ctx, ip, credentialID)
+		if err != nil {
+			log.Warn("Switch neighbor collection failed", "switch", sw.Name, "ip", ip, "error", err)
+			report.Errors = append(report.Errors, model.SwitchPortError{SwitchID: sw.ID, SwitchName: sw.Name, Error: err.Error()})
+			continue
+		}
+
+		links, err := s.connectedDevices(ctx, sw.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range neighbors {
+			m := model.SwitchPortMapping{
+				SwitchID:     sw.ID,
+				SwitchName:   sw.Name,
+				Port:         n.LocalPort,
+				Protocol:     n.Protocol,
+				NeighborName: n.Name,
+				NeighborPort: n.RemotePort,
+				MAC:          n.MAC,
+				IP:           n.IP,
+				Status:       model.SwitchPortUnmatched,
+			}
+			device, addr := index.match(n)
+			if device == nil || device.ID == sw.ID {
+				report.Mappings = append(report.Mappings, m)
+				continue
+			}
+			m.DeviceID = device.ID
+			m.DeviceName = device.Name
+			if addr != nil {
+				m.AddressID = addr.ID
+			}
+
+			switch {
+			case addr == nil:
+				m.Status = model.SwitchPortLinked
+			case addr.SwitchPort == "":
+				m.Status = model.SwitchPortFilled
+				addr.SwitchPort = n.LocalPort
+				if fills[device.ID] == nil {
+					fills[device.ID] = make(map[string]string)
+				}
+				fills[device.ID][addr.ID] = n.LocalPort
+			case addr.SwitchPort == n.LocalPort:
+				m.Status = model.SwitchPortVerified
+			default:
+				m.DocumentedPort = addr.SwitchPort
+				m.Status = model.SwitchPortMismatch
+			}
+			report.Mappings = append(report.Mappings, m)
+
+			if !links[device.ID] {
+				notes := strings.ToUpper(n.Protocol) + " " + n.LocalPort
+				if err := s.store.AddRelationship(enrichAuditCtx(ctx), sw.ID, device.ID, model.RelationshipConnectedTo, notes); err != nil {
+					return nil, err
+				}
+				links[device.ID] = true
+				report.Relationships++
+			}
+		}
+	}
+
+	// Reload before saving so only the switch ports change
+	for deviceID, ports := range fills {
+		device, err := s.store.GetDevice(ctx, deviceID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range device.Addresses {
+			if port, ok := ports[device.Addresses[i].ID]; ok && device.Addresses[i].SwitchPort == "" {
+				device.Addresses[i].SwitchPort = port
+			}
+		}
+		if err := s.store.UpdateDevice(enrichAuditCtx(ctx), device); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info("Collected switch port neighbors", "switches", report.Switches, "mappings", len(report.Mappings), "filled", len(fills))
+	return report, nil
+}
+
+// connectedDevices returns the devices already linked to a device with a
+// connected_to relationship, in either direction
+func (s *DiscoveryService) connectedDevices(ctx context.Context, deviceID string) (map[string]bool, error) {
+	rels, err := s.store.GetRelationships(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	linked := make(map[string]bool)
+	for _, r := range rels {
+		if r.Type != model.RelationshipConnectedTo {
+			continue
+		}
+		if r.ParentID == deviceID {
+			linked[r.ChildID] = true
+		} else {
+			linked[r.ParentID] = true
+		}
+	}
+	return linked, nil
+}
+
+// neighborIndex finds documented devices and addresses for switch neighbors
+type neighborIndex struct {
+	byMAC  map[string]neighborMatch
+	byIP   map[string]neighborMatch
+	byName map[string]*model.Device
+}
+
+type neighborMatch struct {
+	device *model.Device
+	addr   *model.Address
+}
+
+func newNeighborIndex(devices []model.Device) *neighborIndex {
+	idx := &neighborIndex{
+		byMAC:  make(map[string]neighborMatch),
+		byIP:   make(map[string]neighborMatch),
+		byName: make(map[string]*model.Device),
+	}
+	for i := range devices {
+		d := &devices[i]
+		for j := range d.Addresses {
+			a := &d.Addresses[j]
+			if mac, ok := model.NormalizeMAC(a.MACAddress); ok {
+				idx.byMAC[mac] = neighborMatch{device: d, addr: a}
+			}
+			if a.IP != "" {
+				idx.byIP[a.IP] = neighborMatch{device: d, addr: a}
+			}
+		}
+		for _, name := range []string{d.Name, d.Hostname} {
+			if name != "" {
+				idx.byName[strings.ToLower(name)] = d
+				short, _, _ := strings.Cut(strings.ToLower(name), ".")
+				if _, taken := idx.byName[short]; !taken {
+					idx.byName[short] = d
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// match returns the device a neighbor belongs to and, when it was matched by
+// MAC or IP, the address. A name match names an address only when the
+// device has just one.
+func (idx *neighborIndex) match(n discovery.SwitchNeighbor) (*model.Device, *model.Address) {
+	if mac, ok := model.NormalizeMAC(n.MAC); ok {
+		if m, found := idx.byMAC[mac]; found {
+			return m.device, m.addr
+		}
+	}
+	if m, found := idx.byIP[n.IP]; found && n.IP != "" {
+		return m.device, m.addr
+	}
+	if n.Name == "" {
+		return nil, nil
+	}
+	name := strings.ToLower(n.Name)
+	d, found := idx.byName[name]
+	if !found {
+		short, _, _ := strings.Cut(name, ".")
+		if d, found = idx.byName[short]; !found {
+			return nil, nil
+		}
+	}
+	if len(d.Addresses) == 1 {
+		return d, &d.Addresses[0]
+	}
+	return d, nil
+}
+
+func hasTag(device *model.Device, tag string) bool {
+	for _, t := range device.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// managementIP returns the first IP address documented on a device
+func managementIP(device *model.Device) string {
+	for _, a := range device.Addresses {
+		if a.IP != "" {
+			return a.IP
+		}
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	discoverypkg "github.com/martinsuchenak/rackd/internal/discovery"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+type switchPortTestStorage struct {
+	*discoveryTestStorage
+	relationships []model.DeviceRelationship
+	updated       map[string]*model.Device
+}
+
+func (s *switchPortTestStorage) GetDevice(_ context.Context, id string) (*model.Device, error) {
+	for _, d := range s.devices {
+		if d.ID == id {
+			cloned := d
+			cloned.Addresses = append([]model.Address(nil), d.Addresses...)
+			return &cloned, nil
+		}
+	}
+	return nil, storage.ErrDeviceNotFound
+}
+
+func (s *switchPortTestStorage) UpdateDevice(_ context.Context, device *model.Device) error {
+	s.updated[device.ID] = device
+	return nil
+}
+
+func (s *switchPortTestStorage) GetRelationships(_ context.Context, deviceID string) ([]model.DeviceRelationship, error) {
+	var result []model.DeviceRelationship
+	for _, r := range s.relationships {
+		if r.ParentID == deviceID || r.ChildID == deviceID {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (s *switchPortTestStorage) AddRelationship(_ context.Context, parentID, childID, relationshipType, notes string) error {
+	s.relationships = append(s.relationships, model.DeviceRelationship{ParentID: parentID, ChildID: childID, Type: relationshipType, Notes: notes})
+	return nil
+}
+
+type neighborScannerStub struct {
+	scannerStub
+	neighbors map[string][]discoverypkg.SwitchNeighbor
+	credID    string
+}
+
+func (s *neighborScannerStub) SwitchNeighbors(_ context.Context, ip, snmpCredID string) ([]discoverypkg.SwitchNeighbor, error) {
+	s.credID = snmpCredID
+	neighbors, ok := s.neighbors[ip]
+	if !ok {
+		return nil, errors.New("request timeout")
+	}
+	return neighbors, nil
+}
+
+func TestDiscoveryService_CollectSwitchPorts(t *testing.T) {
+	base := newDiscoveryTestStorage()
+	base.setPermission("user-1", "discovery", "create", true)
+	base.setPermission("user-1", "devices", "update", true)
+	base.devices = []model.Device{
+		{ID: "sw-1", Name: "sw-core-01", Tags: []string{"Switch"}, Addresses: []model.Address{{ID: "a-sw", IP: "10.0.0.2"}}},
+		{ID: "sw-2", Name: "sw-edge-02", Tags: []string{"switch"}, Addresses: []model.Address{{ID: "a-sw2", IP: "10.0.0.3"}}},
+		{ID: "web", Name: "web-01", Addresses: []model.Address{{ID: "a-web", IP: "10.0.0.10", MACAddress: "00-11-22-33-44-55"}}},
+		{ID: "db", Name: "db-01", Addresses: []model.Address{{ID: "a-db", IP: "10.0.0.20", SwitchPort: "Gi1/0/14"}}},
+		{ID: "app", Name: "app-01", Addresses: []model.Address{{ID: "a-app", IP: "10.0.0.30", SwitchPort: "Gi1/0/15"}}},
+		{ID: "nas", Name: "nas-01", Addresses: []model.Address{{ID: "a-nas1", IP: "10.0.0.40"}, {ID: "a-nas2", IP: "10.0.1.40"}}},
+	}
+	store := &switchPortTestStorage{
+		discoveryTestStorage: base,
+		relationships:        []model.DeviceRelationship{{ParentID: "app", ChildID: "sw-1", Type: model.RelationshipConnectedTo}},
+		updated:              make(map[string]*model.Device),
+	}
+	scanner := &neighborScannerStub{neighbors: map[string][]discoverypkg.SwitchNeighbor{
+		"10.0.0.2": {
+			{LocalPort: "Gi1/0/12", Protocol: discoverypkg.NeighborLLDP, MAC: "00:11:22:33:44:55", Name: "web-01"},
+			{LocalPort: "Gi1/0/13", Protocol: discoverypkg.NeighborCDP, IP: "10.0.0.20", Name: "db-01"},
+			{LocalPort: "Gi1/0/15", Protocol: discoverypkg.NeighborLLDP, Name: "app-01.example.com"},
+			{LocalPort: "Gi1/0/16", Protocol: discoverypkg.NeighborLLDP, Name: "nas-01"},
+			{LocalPort: "Gi1/0/17", Protocol: discoverypkg.NeighborLLDP, Name: "printer"},
+		},
+	}}
+	svc := NewDiscoveryService(store, scanner)
+
+	report, err := svc.CollectSwitchPorts(userContext("user-1"), "cred-1", "")
+	if err != nil {
+		t.Fatalf("CollectSwitchPorts returned unexpected error: %v", err)
+	}
+	if scanner.credID != "cred-1" {
+		t.Errorf("expected credential cred-1 to be used, got %q", scanner.credID)
+	}
+	if report.Switches != 2 || len(report.Errors) != 1 || report.Errors[0].SwitchID != "sw-2" {
+		t.Fatalf("expected 2 switches and an error for sw-2, got %+v", report)
+	}
+
+	statuses := make(map[string]string)
+	for _, m := range report.Mappings {
+		statuses[m.Port] = m.Status
+	}
+	want := map[string]string{
+		"Gi1/0/12": model.SwitchPortFilled,
+		"Gi1/0/13": model.SwitchPortMismatch,
+		"Gi1/0/15": model.SwitchPortVerified,
+		"Gi1/0/16": model.SwitchPortLinked,
+		"Gi1/0/17": model.SwitchPortUnmatched,
+	}
+	for port, status := range want {
+		if statuses[port] != status {
+			t.Errorf("port %s: expected %s, got %q", port, status, statuses[port])
+		}
+	}
+
+	if len(store.updated) != 1 || store.updated["web"] == nil || store.updated["web"].Addresses[0].SwitchPort != "Gi1/0/12" {
+		t.Fatalf("expected only web-01 to be updated with its port, got %+v", store.updated)
+	}
+
+	// app-01 was already linked, so web, db and nas get new relationships
+	if report.Relationships != 3 || len(store.relationships) != 4 {
+		t.Fatalf("expected 3 new relationships, got %d: %+v", report.Relationships, store.relationships)
+	}
+	if r := store.relationships[1]; r.ParentID != "sw-1" || r.ChildID != "web" || r.Notes != "LLDP Gi1/0/12" {
+		t.Errorf("unexpected relationship: %+v", r)
+	}
+}
+
+func TestDiscoveryService_CollectSwitchPortsValidation(t *testing.T) {
+	store := newDiscoveryTestStorage()
+	store.setPermission("user-1", "discovery", "create", true)
+
+	if _, err := NewDiscoveryService(store, &neighborScannerStub{}).CollectSwitchPorts(userContext("user-1"), "cred-1", ""); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden without devices:update, got %v", err)
+	}
+
+	store.setPermission("user-1", "devices", "update", true)
+	var verrs ValidationErrors
+	if _, err := NewDiscoveryService(store, &neighborScannerStub{}).CollectSwitchPorts(userContext("user-1"), "", ""); !errors.As(err, &verrs) || verrs[0].Field != "credential_id" {
+		t.Fatalf("expected credential_id validation error, got %v", err)
+	}
+	if _, err := NewDiscoveryService(store, &scannerStub{}).CollectSwitchPorts(userContext("user-1"), "cred-1", ""); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error for a scanner without neighbor collection, got %v", err)
+	}
+}
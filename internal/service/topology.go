@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/search"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// MaxTopologyQueryPaths is the most paths a topology query returns
+const MaxTopologyQueryPaths = 1000
+
+type TopologyService struct {
+	store storage.ExtendedStorage
+}
+
+func NewTopologyService(store storage.ExtendedStorage) *TopologyService {
+	return &TopologyService{store: store}
+}
+
+// Get builds the topology graph of a datacenter, or of everything when
+// datacenterID is empty. Networks outside the datacenter are included when a
+// device in it has an address on them, so no membership edge dangles.
+// Relationships to devices outside the datacenter are left out.
+func (s *TopologyService) Get(ctx context.Context, datacenterID string) (*model.Topology, error) {
+	for _, resource := range []string{"devices", "networks", "relationships"} {
+		if err := requirePermission(ctx, s.store, resource, "list"); err != nil {
+			return nil, err
+		}
+	}
+
+	if datacenterID != "" {
+		if _, err := s.store.GetDatacenter(ctx, datacenterID); err != nil {
+			if errors.Is(err, storage.ErrDatacenterNotFound) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+	}
+
+	devices, err := listAllDevices(ctx, s.store, model.DeviceFilter{DatacenterID: datacenterID})
+	if err != nil {
+		return nil, err
+	}
+	networks, err := listAllNetworks(ctx, s.store, model.NetworkFilter{DatacenterID: datacenterID})
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := s.store.ListAllRelationships(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &model.Topology{DatacenterID: datacenterID, Nodes: []model.TopologyNode{}, Edges: []model.TopologyEdge{}}
+	isDevice := make(map[string]bool, len(devices))
+	isNetwork := make(map[string]bool, len(networks))
+
+	addNetwork := func(n model.Network) {
+		isNetwork[n.ID] = true
+		topology.Nodes = append(topology.Nodes, model.TopologyNode{
+			ID: n.ID, Type: model.TopologyNodeNetwork, Label: n.Name,
+			DatacenterID: n.DatacenterID, Subnet: n.Subnet,
+		})
+	}
+	for _, n := range networks {
+		addNetwork(n)
+	}
+
+	for _, d := range devices {
+		isDevice[d.ID] = true
+		topology.Nodes = append(topology.Nodes, model.TopologyNode{
+			ID: d.ID, Type: model.TopologyNodeDevice, Label: d.Name,
+			DatacenterID: d.DatacenterID, Status: string(d.Status),
+		})
+		for _, a := range d.Addresses {
+			if a.NetworkID == "" {
+				continue
+			}
+			if !isNetwork[a.NetworkID] {
+				n, err := s.store.GetNetwork(ctx, a.NetworkID)
+				if errors.Is(err, storage.ErrNetworkNotFound) {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				networks = append(networks, *n)
+				addNetwork(*n)
+			}
+			topology.Edges = append(topology.Edges, model.TopologyEdge{
+				Source: d.ID, Target: a.NetworkID, Type: model.TopologyEdgeMember,
+				Label: a.IP, SwitchPort: a.SwitchPort,
+			})
+		}
+	}
+
+	for _, n := range networks {
+		if isNetwork[n.ParentID] {
+			topology.Edges = append(topology.Edges, model.TopologyEdge{
+				Source: n.ParentID, Target: n.ID, Type: model.TopologyEdgeSubnet,
+			})
+		}
+	}
+
+	sort.Slice(relationships, func(i, j int) bool {
+		a, b := relationships[i], relationships[j]
+		if a.ParentID != b.ParentID {
+			return a.ParentID < b.ParentID
+		}
+		if a.ChildID != b.ChildID {
+			return a.ChildID < b.ChildID
+		}
+		return a.Type < b.Type
+	})
+	for _, r := range relationships {
+		if isDevice[r.ParentID] && isDevice[r.ChildID] {
+			topology.Edges = append(topology.Edges, model.TopologyEdge{
+				Source: r.ParentID, Target: r.ChildID, Type: r.Type, Label: r.Notes,
+			})
+		}
+	}
+
+	return topology, nil
+}
+
+// Query walks a path through the inventory (see search.ParsePath) and returns
+// the chains of nodes it matched. It needs the list permission of each kind
+// of node the path reaches, and of relationships when it follows them.
+func (s *TopologyService) Query(ctx context.Context, query string) (*model.TopologyQuery, error) {
+	path, err := search.ParsePath(query)
+	if err != nil {
+		return nil, ValidationErrors{{Field: "q", Message: "Invalid query: " + err.Error()}}
+	}
+
+	resources := map[string]bool{
+		"devices":       path.Reaches(model.TopologyNodeDevice),
+		"networks":      path.Reaches(model.TopologyNodeNetwork),
+		"datacenters":   path.Reaches(model.TopologyNodeDatacenter),
+		"relationships": path.FollowsRelationships(),
+	}
+	for _, resource := range []string{"devices", "networks", "datacenters", "relationships"} {
+		if !resources[resource] {
+			continue
+		}
+		if err := requirePermission(ctx, s.store, resource, "list"); err != nil {
+			return nil, err
+		}
+	}
+
+	graph := &search.Graph{}
+	if resources["devices"] {
+		if graph.Devices, err = listAllDevices(ctx, s.store, model.DeviceFilter{}); err != nil {
+			return nil, err
+		}
+	}
+	if resources["networks"] {
+		if graph.Networks, err = listAllNetworks(ctx, s.store, model.NetworkFilter{}); err != nil {
+			return nil, err
+		}
+	}
+	// Device queries can match datacenter names
+	if resources["datacenters"] || path.Uses("datacenter") {
+		graph.Datacenters, err = s.store.ListDatacenters(ctx, &model.DatacenterFilter{Pagination: model.Pagination{Limit: model.MaxPageSize}})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if resources["relationships"] {
+		if graph.Relationships, err = s.store.ListAllRelationships(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	paths, truncated := path.Walk(graph, MaxTopologyQueryPaths)
+	return &model.TopologyQuery{
+		Query:     query,
+		Steps:     path.Steps(),
+		Count:     len(paths),
+		Paths:     paths,
+		Truncated: truncated,
+	}, nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// trashTypes are the resource types the trash holds
+var trashTypes = []string{model.TrashTypeDevice, model.TrashTypeNetwork, model.TrashTypeDatacenter}
+
+// TrashService lists, restores and purges deleted devices, networks and
+// datacenters. Access follows the permissions of each item's resource:
+// listing needs list, restoring needs create and purging needs delete.
+type TrashService struct {
+	store         storage.ExtendedStorage
+	retentionDays int
+}
+
+func NewTrashService(store storage.ExtendedStorage) *TrashService {
+	return &TrashService{store: store}
+}
+
+// SetRetention sets how many days items stay in the trash before
+// PurgeExpired removes them. Zero, the default, keeps them until purged by
+// hand.
+func (s *TrashService) SetRetention(days int) {
+	if days >= 0 {
+		s.retentionDays = days
+	}
+}
+
+// List returns the trashed items the caller may see. Without a type filter,
+// types the caller cannot list are left out rather than refused.
+func (s *TrashService) List(ctx context.Context, filter *model.TrashFilter) ([]model.TrashItem, error) {
+	if filter == nil {
+		filter = &model.TrashFilter{}
+	}
+	for _, t := range filter.Types {
+		if !slices.Contains(trashTypes, t) {
+			return nil, ValidationErrors{{Field: "type", Message: "Type must be one of: device, network, datacenter"}}
+		}
+	}
+
+	requested := filter.Types
+	if len(requested) == 0 {
+		requested = trashTypes
+	}
+	var allowed []string
+	var denied error
+	for _, t := range requested {
+		err := requirePermission(ctx, s.store, t+"s", "list")
+		if errors.Is(err, ErrForbidden) && len(filter.Types) == 0 {
+			denied = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		allowed = append(allowed, t)
+	}
+	if len(allowed) == 0 {
+		return nil, denied
+	}
+
+	query := *filter
+	query.Types = allowed
+	items, err := s.store.ListTrash(ctx, &query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		s.setPurgeAfter(&items[i])
+	}
+	return items, nil
+}
+
+// Restore takes an item out of the trash and returns what was restored
+func (s *TrashService) Restore(ctx context.Context, id string) (*model.TrashItem, error) {
+	item, err := s.get(ctx, id, "create")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.RestoreTrashItem(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrTrashItemNotFound) {
+			return nil, ErrNotFound
+		}
+		if errors.Is(err, storage.ErrSubnetInUse) {
+			return nil, ValidationErrors{{Field: "subnet", Message: "Subnet is already used by another network in this datacenter"}}
+		}
+		return nil, err
+	}
+	item.PurgeAfter = nil
+	return item, nil
+}
+
+// Purge permanently deletes a trashed item, applying to its dependents the
+// delete policy it was trashed with
+func (s *TrashService) Purge(ctx context.Context, id string) error {
+	if _, err := s.get(ctx, id, "delete"); err != nil {
+		return err
+	}
+
+	if err := s.store.PurgeTrashItem(enrichAuditCtx(ctx), id); err != nil {
+		if errors.Is(err, storage.ErrTrashItemNotFound) {
+			return ErrNotFound
+		}
+		return dependentsError(err)
+	}
+	return nil
+}
+
+// PurgeExpired purges the items that have been in the trash longer than the
+// retention period and returns how many were removed. Items that cannot be
+// purged, such as those trashed with deny that gained dependents, are
+// logged and kept.
+func (s *TrashService) PurgeExpired(ctx context.Context) (int, error) {
+	if s.retentionDays == 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -s.retentionDays)
+	purged, kept := 0, 0
+	for {
+		// Purged items leave the list, so only the kept ones are skipped
+		items, err := s.List(ctx, &model.TrashFilter{
+			Pagination:    model.Pagination{Limit: model.MaxPageSize, Offset: kept},
+			DeletedBefore: cutoff,
+		})
+		if err != nil {
+			return purged, err
+		}
+		for _, item := range items {
+			err := s.Purge(ctx, item.ID)
+			switch {
+			case err == nil:
+				purged++
+			case errors.Is(err, ErrNotFound):
+				// Already removed along with an item purged earlier
+			default:
+				log.Warn("Failed to purge trash item", "type", item.Type, "id", item.ID, "error", err)
+				kept++
+			}
+		}
+		if len(items) < model.MaxPageSize {
+			return purged, nil
+		}
+	}
+}
+
+// get returns a trashed item after checking the caller may act on its
+// resource
+func (s *TrashService) get(ctx context.Context, id, action string) (*model.TrashItem, error) {
+	item, err := s.store.GetTrashItem(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrTrashItemNotFound) || errors.Is(err, storage.ErrInvalidID) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.store, item.Type+"s", action); err != nil {
+		return nil, err
+	}
+	s.setPurgeAfter(item)
+	return item, nil
+}
+
+func (s *TrashService) setPurgeAfter(item *model.TrashItem) {
+	if s.retentionDays > 0 {
+		purgeAfter := item.DeletedAt.AddDate(0, 0, s.retentionDays)
+		item.PurgeAfter = &purgeAfter
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+type trashTestStorage struct {
+	*serviceTestStorage
+	items      map[string]model.TrashItem
+	listFilter *model.TrashFilter
+	restoreErr error
+	purgeErrs  map[string]error
+	purged     []string
+}
+
+func newTrashTestStorage(items ...model.TrashItem) *trashTestStorage {
+	s := &trashTestStorage{serviceTestStorage: newServiceTestStorage(), items: map[string]model.TrashItem{}, purgeErrs: map[string]error{}}
+	for _, item := range items {
+		s.items[item.ID] = item
+	}
+	return s
+}
+
+func (s *trashTestStorage) ListTrash(_ context.Context, filter *model.TrashFilter) ([]model.TrashItem, error) {
+	s.listFilter = filter
+	items := []model.TrashItem{}
+	for _, item := range s.items {
+		if !filter.DeletedBefore.IsZero() && !item.DeletedAt.Before(filter.DeletedBefore) {
+			continue
+		}
+		if filter.Offset > 0 {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *trashTestStorage) GetTrashItem(_ context.Context, id string) (*model.TrashItem, error) {
+	item, ok := s.items[id]
+	if !ok {
+		return nil, storage.ErrTrashItemNotFound
+	}
+	return &item, nil
+}
+
+func (s *trashTestStorage) RestoreTrashItem(_ context.Context, id string) error {
+	if s.restoreErr != nil {
+		return s.restoreErr
+	}
+	delete(s.items, id)
+	return nil
+}
+
+func (s *trashTestStorage) PurgeTrashItem(_ context.Context, id string) error {
+	if err := s.purgeErrs[id]; err != nil {
+		return err
+	}
+	delete(s.items, id)
+	s.purged = append(s.purged, id)
+	return nil
+}
+
+func TestTrashService_ListOnlyShowsPermittedTypes(t *testing.T) {
+	deletedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newTrashTestStorage(model.TrashItem{ID: "dev-1", Type: model.TrashTypeDevice, DeletedAt: deletedAt})
+	store.setPermission("user-1", "devices", "list", true)
+	svc := NewTrashService(store)
+	svc.SetRetention(30)
+
+	items, err := svc.List(userContext("user-1"), nil)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(store.listFilter.Types) != 1 || store.listFilter.Types[0] != model.TrashTypeDevice {
+		t.Errorf("expected only devices to be listed, got %v", store.listFilter.Types)
+	}
+	if len(items) != 1 || items[0].PurgeAfter == nil || !items[0].PurgeAfter.Equal(deletedAt.AddDate(0, 0, 30)) {
+		t.Errorf("expected the purge time to follow the retention, got %+v", items)
+	}
+
+	_, err = svc.List(userContext("user-1"), &model.TrashFilter{Types: []string{model.TrashTypeNetwork}})
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected forbidden for an explicit type, got %v", err)
+	}
+	_, err = svc.List(userContext("user-1"), &model.TrashFilter{Types: []string{"pool"}})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || verrs[0].Field != "type" {
+		t.Errorf("expected a validation error for an unknown type, got %v", err)
+	}
+	if _, err := svc.List(userContext("user-2"), nil); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected forbidden without any list permission, got %v", err)
+	}
+}
+
+func TestTrashService_Restore(t *testing.T) {
+	store := newTrashTestStorage(model.TrashItem{ID: "net-1", Type: model.TrashTypeNetwork})
+	svc := NewTrashService(store)
+
+	if _, err := svc.Restore(userContext("user-1"), "net-1"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected forbidden without networks:create, got %v", err)
+	}
+
+	store.setPermission("user-1", "networks", "create", true)
+	store.restoreErr = storage.ErrSubnetInUse
+	var verrs ValidationErrors
+	if _, err := svc.Restore(userContext("user-1"), "net-1"); !errors.As(err, &verrs) || verrs[0].Field != "subnet" {
+		t.Fatalf("expected a subnet validation error, got %v", err)
+	}
+
+	store.restoreErr = nil
+	item, err := svc.Restore(userContext("user-1"), "net-1")
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if item.ID != "net-1" || item.Type != model.TrashTypeNetwork {
+		t.Errorf("unexpected restored item %+v", item)
+	}
+	if _, err := svc.Restore(userContext("user-1"), "net-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found once restored, got %v", err)
+	}
+}
+
+func TestTrashService_PurgeExpired(t *testing.T) {
+	old := time.Now().UTC().AddDate(0, 0, -40)
+	store := newTrashTestStorage(
+		model.TrashItem{ID: "dev-old", Type: model.TrashTypeDevice, DeletedAt: old},
+		model.TrashItem{ID: "dc-old", Type: model.TrashTypeDatacenter, DeletedAt: old},
+		model.TrashItem{ID: "dev-new", Type: model.TrashTypeDevice, DeletedAt: time.Now().UTC()},
+	)
+	store.purgeErrs["dc-old"] = &storage.DependentsError{Resource: "datacenter", Dependents: map[string]int{"devices": 1}}
+	svc := NewTrashService(store)
+	ctx := SystemContext(context.Background(), "test")
+
+	if n, err := svc.PurgeExpired(ctx); err != nil || n != 0 {
+		t.Fatalf("expected nothing purged without a retention, got %d, %v", n, err)
+	}
+
+	svc.SetRetention(30)
+	n, err := svc.PurgeExpired(ctx)
+	if err != nil {
+		t.Fatalf("PurgeExpired failed: %v", err)
+	}
+	if n != 1 || len(store.purged) != 1 || store.purged[0] != "dev-old" {
+		t.Errorf("expected only dev-old to be purged, got %d %v", n, store.purged)
+	}
+	if _, ok := store.items["dc-old"]; !ok {
+		t.Error("expected the blocked datacenter to stay in the trash")
+	}
+}
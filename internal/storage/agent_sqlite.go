@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const agentColumns = `id, name, description, network_ids, hostname, version, last_heartbeat, created_at, updated_at`
+
+// CreateAgent registers a new agent with the hash of its token
+func (s *SQLiteStorage) CreateAgent(ctx context.Context, agent *model.Agent, tokenHash string) error {
+	if agent.ID == "" {
+		agent.ID = newUUID()
+	}
+	now := nowUTC()
+	agent.CreatedAt = now
+	agent.UpdatedAt = now
+	if agent.NetworkIDs == nil {
+		agent.NetworkIDs = []string{}
+	}
+	networkIDs, _ := json.Marshal(agent.NetworkIDs)
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO agents (id, name, description, token_hash, network_ids, hostname, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, agent.ID, agent.Name, agent.Description, tokenHash, string(networkIDs),
+		agent.Hostname, agent.Version, agent.CreatedAt, agent.UpdatedAt)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return ErrDuplicateAgentName
+		}
+		return err
+	}
+	s.auditLog(ctx, "create", "agent", agent.ID, agent)
+	return nil
+}
+
+// GetAgent retrieves an agent by ID
+func (s *SQLiteStorage) GetAgent(ctx context.Context, id string) (*model.Agent, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+agentColumns+` FROM agents WHERE id = ?`, id)
+	agent, err := scanAgent(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrAgentNotFound
+	}
+	return agent, err
+}
+
+// GetAgentByTokenHash retrieves the agent owning a token hash
+func (s *SQLiteStorage) GetAgentByTokenHash(ctx context.Context, tokenHash string) (*model.Agent, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+agentColumns+` FROM agents WHERE token_hash = ?`, tokenHash)
+	agent, err := scanAgent(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrAgentNotFound
+	}
+	return agent, err
+}
+
+// ListAgents returns all agents ordered by name
+func (s *SQLiteStorage) ListAgents(ctx context.Context) ([]model.Agent, error) {
+	rows, err := s.reader.QueryContext(ctx, `SELECT `+agentColumns+` FROM agents ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	agents := []model.Agent{}
+	for rows.Next() {
+		agent, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, *agent)
+	}
+	return agents, rows.Err()
+}
+
+// UpdateAgent updates an agent's name, description and network assignment
+func (s *SQLiteStorage) UpdateAgent(ctx context.Context, agent *model.Agent) error {
+	agent.UpdatedAt = nowUTC()
+	if agent.NetworkIDs == nil {
+		agent.NetworkIDs = []string{}
+	}
+	networkIDs, _ := json.Marshal(agent.NetworkIDs)
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE agents SET name = ?, description = ?, network_ids = ?, updated_at = ? WHERE id = ?
+	`, agent.Name, agent.Description, string(networkIDs), agent.UpdatedAt, agent.ID)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return ErrDuplicateAgentName
+		}
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrAgentNotFound
+	}
+	s.auditLog(ctx, "update", "agent", agent.ID, agent)
+	return nil
+}
+
+// UpdateAgentToken replaces an agent's token hash, invalidating the old token
+func (s *SQLiteStorage) UpdateAgentToken(ctx context.Context, id, tokenHash string) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE agents SET token_hash = ?, updated_at = ? WHERE id = ?`,
+		tokenHash, nowUTC(), id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrAgentNotFound
+	}
+	s.auditLog(ctx, "rotate_token", "agent", id, nil)
+	return nil
+}
+
+// UpdateAgentHeartbeat records a heartbeat. It is not audited since agents
+// send one every minute.
+func (s *SQLiteStorage) UpdateAgentHeartbeat(ctx context.Context, id string, hb *model.AgentHeartbeat) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE agents SET hostname = ?, version = ?, last_heartbeat = ? WHERE id = ?`,
+		hb.Hostname, hb.Version, nowUTC(), id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrAgentNotFound
+	}
+	return nil
+}
+
+// DeleteAgent removes an agent by ID
+func (s *SQLiteStorage) DeleteAgent(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM agents WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrAgentNotFound
+	}
+	s.auditLog(ctx, "delete", "agent", id, nil)
+	return nil
+}
+
+func scanAgent(row interface{ Scan(...any) error }) (*model.Agent, error) {
+	var agent model.Agent
+	var networkIDs string
+	var lastHeartbeat sql.NullTime
+	if err := row.Scan(&agent.ID, &agent.Name, &agent.Description, &networkIDs, &agent.Hostname,
+		&agent.Version, &lastHeartbeat, &agent.CreatedAt, &agent.UpdatedAt); err != nil {
+		return nil, err
+	}
+	agent.NetworkIDs = []string{}
+	json.Unmarshal([]byte(networkIDs), &agent.NetworkIDs)
+	if lastHeartbeat.Valid {
+		agent.LastHeartbeat = &lastHeartbeat.Time
+	}
+	return &agent, nil
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestAgentCRUD(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	agent := &model.Agent{Name: "branch-office", Description: "Remote site", NetworkIDs: []string{"net-1", "net-2"}}
+	if err := storage.CreateAgent(ctx, agent, "hash-1"); err != nil {
+		t.Fatalf("CreateAgent failed: %v", err)
+	}
+	if err := storage.CreateAgent(ctx, &model.Agent{Name: "branch-office"}, "hash-2"); !errors.Is(err, ErrDuplicateAgentName) {
+		t.Errorf("expected ErrDuplicateAgentName, got %v", err)
+	}
+
+	got, err := storage.GetAgentByTokenHash(ctx, "hash-1")
+	if err != nil {
+		t.Fatalf("GetAgentByTokenHash failed: %v", err)
+	}
+	if got.ID != agent.ID || len(got.NetworkIDs) != 2 || got.LastHeartbeat != nil {
+		t.Errorf("unexpected agent: %#v", got)
+	}
+
+	if err := storage.UpdateAgentHeartbeat(ctx, agent.ID, &model.AgentHeartbeat{Hostname: "scanner01", Version: "1.2.3"}); err != nil {
+		t.Fatalf("UpdateAgentHeartbeat failed: %v", err)
+	}
+	got, _ = storage.GetAgent(ctx, agent.ID)
+	if got.Hostname != "scanner01" || got.Version != "1.2.3" || got.LastHeartbeat == nil {
+		t.Errorf("expected heartbeat to be recorded, got %#v", got)
+	}
+
+	got.NetworkIDs = []string{"net-3"}
+	got.Description = "Updated"
+	if err := storage.UpdateAgent(ctx, got); err != nil {
+		t.Fatalf("UpdateAgent failed: %v", err)
+	}
+	if err := storage.UpdateAgentToken(ctx, agent.ID, "hash-3"); err != nil {
+		t.Fatalf("UpdateAgentToken failed: %v", err)
+	}
+	if _, err := storage.GetAgentByTokenHash(ctx, "hash-1"); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("expected old token to be invalid, got %v", err)
+	}
+
+	agents, err := storage.ListAgents(ctx)
+	if err != nil {
+		t.Fatalf("ListAgents failed: %v", err)
+	}
+	if len(agents) != 1 || agents[0].Description != "Updated" || agents[0].NetworkIDs[0] != "net-3" {
+		t.Errorf("unexpected agents: %#v", agents)
+	}
+
+	if err := storage.DeleteAgent(ctx, agent.ID); err != nil {
+		t.Fatalf("DeleteAgent failed: %v", err)
+	}
+	if _, err := storage.GetAgent(ctx, agent.ID); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("expected ErrAgentNotFound, got %v", err)
+	}
+	if err := storage.DeleteAgent(ctx, agent.ID); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("expected ErrAgentNotFound on second delete, got %v", err)
+	}
+}
@@ -59,7 +59,7 @@ func (s *SQLiteStorage) GetAPIKey(ctx context.Context, id string) (*model.APIKey
 	var key model.APIKey
 	var lastUsedAt, expiresAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
+	err := s.reader.QueryRowContext(ctx, query, id).Scan(
 		&key.ID, &key.Name, &key.Key, &key.UserID, &key.Description,
 		&key.CreatedAt, &lastUsedAt, &expiresAt,
 	)
@@ -92,7 +92,7 @@ func (s *SQLiteStorage) GetAPIKeyByKey(ctx context.Context, keyStr string) (*mod
 	var key model.APIKey
 	var lastUsedAt, expiresAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, query, keyStr).Scan(
+	err := s.reader.QueryRowContext(ctx, query, keyStr).Scan(
 		&key.ID, &key.Name, &key.Key, &key.UserID, &key.Description,
 		&key.CreatedAt, &lastUsedAt, &expiresAt,
 	)
@@ -146,7 +146,7 @@ func (s *SQLiteStorage) ListAPIKeys(ctx context.Context, filter *model.APIKeyFil
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list API keys: %w", err)
 	}
@@ -205,7 +205,7 @@ func (s *SQLiteStorage) DeleteAPIKey(ctx context.Context, id string) error {
 
 	// Check if key exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM api_keys WHERE id = ?)`, id).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM api_keys WHERE id = ?)`, id).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check API key existence: %w", err)
 	}
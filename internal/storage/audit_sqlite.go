@@ -65,7 +65,7 @@ func (s *SQLiteStorage) ListAuditLogs(ctx context.Context, filter *model.AuditFi
 
 	query, args = appendPagination(query, args, &filter.Pagination)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +92,7 @@ func (s *SQLiteStorage) ListAuditLogs(ctx context.Context, filter *model.AuditFi
 func (s *SQLiteStorage) GetAuditLog(ctx context.Context, id string) (*model.AuditLog, error) {
 	var log model.AuditLog
 	var source sql.NullString
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, timestamp, action, resource, resource_id, user_id, username, ip_address, changes, status, error, source
 		FROM audit_logs WHERE id = ?
 	`, id).Scan(&log.ID, &log.Timestamp, &log.Action, &log.Resource, &log.ResourceID, &log.UserID, &log.Username, &log.IPAddress, &log.Changes, &log.Status, &log.Error, &source)
@@ -2,9 +2,11 @@ package storage
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/martinsuchenak/rackd/internal/audit"
 	"github.com/martinsuchenak/rackd/internal/model"
 )
 
@@ -247,3 +249,33 @@ func TestAuditLogTimeFilter(t *testing.T) {
 		t.Errorf("Expected at least 2 logs before end time, got %d", len(filtered))
 	}
 }
+
+func TestCloseFlushesQueuedAuditLogs(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "rackd.db")
+	store, err := NewSQLiteStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	ctx := audit.WithContext(context.Background(), &audit.Context{Username: "cli", Source: "cli"})
+	if err := store.CreateDevice(ctx, &model.Device{Name: "queued-audit"}); err != nil {
+		t.Fatalf("failed to create device: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+
+	store, err = NewSQLiteStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen storage: %v", err)
+	}
+	defer store.Close()
+
+	logs, err := store.ListAuditLogs(context.Background(), &model.AuditFilter{Resource: "device"})
+	if err != nil {
+		t.Fatalf("failed to list audit logs: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected the queued audit log to be written on close, got %d entries", len(logs))
+	}
+}
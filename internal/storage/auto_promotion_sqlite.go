@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const autoPromotionRuleColumns = `id, network_id, name, enabled, min_confidence, min_factor_points,
+	hostname_pattern, min_scans, name_template, tags, datacenter_id, created_at, updated_at`
+
+// CreateAutoPromotionRule inserts a new auto-promotion rule
+func (s *SQLiteStorage) CreateAutoPromotionRule(ctx context.Context, rule *model.AutoPromotionRule) error {
+	if rule.ID == "" {
+		rule.ID = newUUID()
+	}
+	now := nowUTC()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	if rule.Tags == nil {
+		rule.Tags = []string{}
+	}
+	if rule.MinFactorPoints == nil {
+		rule.MinFactorPoints = map[string]int{}
+	}
+	tags, _ := json.Marshal(rule.Tags)
+	minFactorPoints, _ := json.Marshal(rule.MinFactorPoints)
+	enabled := 0
+	if rule.Enabled {
+		enabled = 1
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO auto_promotion_rules (`+autoPromotionRuleColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.ID, rule.NetworkID, rule.Name, enabled, rule.MinConfidence, string(minFactorPoints), rule.HostnamePattern,
+		rule.MinScans, rule.NameTemplate, string(tags), rule.DatacenterID, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	s.auditLog(ctx, "create", "auto_promotion_rule", rule.ID, rule)
+	return nil
+}
+
+// UpdateAutoPromotionRule updates an existing auto-promotion rule
+func (s *SQLiteStorage) UpdateAutoPromotionRule(ctx context.Context, rule *model.AutoPromotionRule) error {
+	rule.UpdatedAt = nowUTC()
+	if rule.Tags == nil {
+		rule.Tags = []string{}
+	}
+	if rule.MinFactorPoints == nil {
+		rule.MinFactorPoints = map[string]int{}
+	}
+	tags, _ := json.Marshal(rule.Tags)
+	minFactorPoints, _ := json.Marshal(rule.MinFactorPoints)
+	enabled := 0
+	if rule.Enabled {
+		enabled = 1
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE auto_promotion_rules SET network_id = ?, name = ?, enabled = ?, min_confidence = ?,
+			min_factor_points = ?, hostname_pattern = ?, min_scans = ?, name_template = ?, tags = ?,
+			datacenter_id = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.NetworkID, rule.Name, enabled, rule.MinConfidence, string(minFactorPoints), rule.HostnamePattern,
+		rule.MinScans, rule.NameTemplate, string(tags), rule.DatacenterID, rule.UpdatedAt, rule.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrAutoPromotionRuleNotFound
+	}
+	s.auditLog(ctx, "update", "auto_promotion_rule", rule.ID, rule)
+	return nil
+}
+
+// GetAutoPromotionRule retrieves an auto-promotion rule by ID
+func (s *SQLiteStorage) GetAutoPromotionRule(ctx context.Context, id string) (*model.AutoPromotionRule, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+autoPromotionRuleColumns+` FROM auto_promotion_rules WHERE id = ?`, id)
+	rule, err := scanAutoPromotionRule(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrAutoPromotionRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// ListAutoPromotionRules returns auto-promotion rules, optionally for one network
+func (s *SQLiteStorage) ListAutoPromotionRules(ctx context.Context, networkID string) ([]model.AutoPromotionRule, error) {
+	query := `SELECT ` + autoPromotionRuleColumns + ` FROM auto_promotion_rules`
+	var args []any
+	if networkID != "" {
+		query += " WHERE network_id = ?"
+		args = append(args, networkID)
+	}
+	query += " ORDER BY created_at"
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.AutoPromotionRule{}
+	for rows.Next() {
+		rule, err := scanAutoPromotionRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteAutoPromotionRule removes an auto-promotion rule by ID
+func (s *SQLiteStorage) DeleteAutoPromotionRule(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM auto_promotion_rules WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrAutoPromotionRuleNotFound
+	}
+	s.auditLog(ctx, "delete", "auto_promotion_rule", id, nil)
+	return nil
+}
+
+func scanAutoPromotionRule(row interface{ Scan(...any) error }) (*model.AutoPromotionRule, error) {
+	var rule model.AutoPromotionRule
+	var enabled int
+	var tags, minFactorPoints string
+	if err := row.Scan(&rule.ID, &rule.NetworkID, &rule.Name, &enabled, &rule.MinConfidence,
+		&minFactorPoints, &rule.HostnamePattern, &rule.MinScans, &rule.NameTemplate, &tags, &rule.DatacenterID,
+		&rule.CreatedAt, &rule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	rule.Enabled = enabled == 1
+	rule.Tags = []string{}
+	json.Unmarshal([]byte(tags), &rule.Tags)
+	rule.MinFactorPoints = map[string]int{}
+	json.Unmarshal([]byte(minFactorPoints), &rule.MinFactorPoints)
+	return &rule, nil
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestAutoPromotionRuleCRUD(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "TestNet", Subnet: "192.168.1.0/24"}
+	storage.CreateNetwork(ctx, network)
+	other := &model.Network{Name: "OtherNet", Subnet: "192.168.2.0/24"}
+	storage.CreateNetwork(ctx, other)
+
+	rule := &model.AutoPromotionRule{
+		NetworkID:       network.ID,
+		Name:            "servers",
+		Enabled:         true,
+		MinConfidence:   80,
+		MinFactorPoints: map[string]int{model.ConfidenceFactorReverseDNS: 20},
+		HostnamePattern: `^srv-`,
+		MinScans:        3,
+		NameTemplate:    "{short_hostname}",
+		Tags:            []string{"auto", "server"},
+	}
+	if err := storage.CreateAutoPromotionRule(ctx, rule); err != nil {
+		t.Fatalf("CreateAutoPromotionRule failed: %v", err)
+	}
+	storage.CreateAutoPromotionRule(ctx, &model.AutoPromotionRule{NetworkID: other.ID, Name: "other"})
+
+	got, err := storage.GetAutoPromotionRule(ctx, rule.ID)
+	if err != nil {
+		t.Fatalf("GetAutoPromotionRule failed: %v", err)
+	}
+	if !got.Enabled || got.MinConfidence != 80 || got.MinScans != 3 || got.HostnamePattern != `^srv-` || len(got.Tags) != 2 ||
+		got.MinFactorPoints[model.ConfidenceFactorReverseDNS] != 20 {
+		t.Errorf("rule mismatch: got %+v", got)
+	}
+
+	rules, err := storage.ListAutoPromotionRules(ctx, network.ID)
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("expected 1 rule for network, got %d (%v)", len(rules), err)
+	}
+	if all, _ := storage.ListAutoPromotionRules(ctx, ""); len(all) != 2 {
+		t.Errorf("expected 2 rules overall, got %d", len(all))
+	}
+
+	rule.Enabled = false
+	rule.Tags = nil
+	rule.MinFactorPoints = nil
+	if err := storage.UpdateAutoPromotionRule(ctx, rule); err != nil {
+		t.Fatalf("UpdateAutoPromotionRule failed: %v", err)
+	}
+	got, _ = storage.GetAutoPromotionRule(ctx, rule.ID)
+	if got.Enabled || len(got.Tags) != 0 || got.MinFactorPoints == nil || len(got.MinFactorPoints) != 0 {
+		t.Errorf("update failed: got %+v", got)
+	}
+
+	if err := storage.DeleteAutoPromotionRule(ctx, rule.ID); err != nil {
+		t.Fatalf("DeleteAutoPromotionRule failed: %v", err)
+	}
+	if _, err := storage.GetAutoPromotionRule(ctx, rule.ID); !errors.Is(err, ErrAutoPromotionRuleNotFound) {
+		t.Errorf("expected ErrAutoPromotionRuleNotFound, got %v", err)
+	}
+	if err := storage.DeleteAutoPromotionRule(ctx, rule.ID); !errors.Is(err, ErrAutoPromotionRuleNotFound) {
+		t.Errorf("expected ErrAutoPromotionRuleNotFound on second delete, got %v", err)
+	}
+	if err := storage.UpdateAutoPromotionRule(ctx, rule); !errors.Is(err, ErrAutoPromotionRuleNotFound) {
+		t.Errorf("expected ErrAutoPromotionRuleNotFound on update, got %v", err)
+	}
+}
+
+func TestDiscoveredDeviceScanCount(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "TestNet", Subnet: "192.168.1.0/24"}
+	storage.CreateNetwork(ctx, network)
+
+	device := &model.DiscoveredDevice{IP: "192.168.1.10", NetworkID: network.ID, ScanCount: 1}
+	if err := storage.CreateDiscoveredDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDiscoveredDevice failed: %v", err)
+	}
+	device.ScanCount++
+	if err := storage.UpdateDiscoveredDevice(ctx, device); err != nil {
+		t.Fatalf("UpdateDiscoveredDevice failed: %v", err)
+	}
+
+	got, err := storage.GetDiscoveredDeviceByIP(ctx, network.ID, "192.168.1.10")
+	if err != nil {
+		t.Fatalf("GetDiscoveredDeviceByIP failed: %v", err)
+	}
+	if got.ScanCount != 2 {
+		t.Errorf("expected scan count 2, got %d", got.ScanCount)
+	}
+}
+
+func TestDiscoveredDeviceConfidenceBreakdown(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "TestNet", Subnet: "192.168.1.0/24"}
+	storage.CreateNetwork(ctx, network)
+
+	device := &model.DiscoveredDevice{IP: "192.168.1.10", NetworkID: network.ID}
+	if err := storage.CreateDiscoveredDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDiscoveredDevice failed: %v", err)
+	}
+	got, _ := storage.GetDiscoveredDevice(ctx, device.ID)
+	if got.ConfidenceBreakdown == nil || len(got.ConfidenceBreakdown) != 0 {
+		t.Errorf("expected empty breakdown, got %+v", got.ConfidenceBreakdown)
+	}
+
+	device.Confidence = 40
+	device.ConfidenceBreakdown = []model.ConfidenceFactor{
+		{Factor: model.ConfidenceFactorPing, Points: 20, Max: 20, Detail: "host is up"},
+		{Factor: model.ConfidenceFactorSNMP, Points: 20, Max: 20, Detail: "answered SNMP"},
+	}
+	if err := storage.UpdateDiscoveredDevice(ctx, device); err != nil {
+		t.Fatalf("UpdateDiscoveredDevice failed: %v", err)
+	}
+
+	devices, err := storage.ListDiscoveredDevices(ctx, network.ID)
+	if err != nil || len(devices) != 1 {
+		t.Fatalf("expected 1 device, got %d (%v)", len(devices), err)
+	}
+	if devices[0].Confidence != 40 || len(devices[0].ConfidenceBreakdown) != 2 || devices[0].FactorPoints(model.ConfidenceFactorSNMP) != 20 {
+		t.Errorf("breakdown not stored: got %+v", devices[0])
+	}
+}
@@ -2,12 +2,21 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"github.com/martinsuchenak/rackd/internal/auth"
 	"github.com/martinsuchenak/rackd/internal/config"
 	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
 )
 
+// AdminTokenFile is the name of the file, inside the data directory, that
+// holds the API token minted by BootstrapAdminToken
+const AdminTokenFile = "admin.token"
+
 func BootstrapInitialAdmin(store ExtendedStorage, cfg *config.Config, sessionManager interface{}) error {
 	userCount, err := store.UserCount(context.Background())
 	if err != nil {
@@ -51,3 +60,79 @@ func BootstrapInitialAdmin(store ExtendedStorage, cfg *config.Config, sessionMan
 
 	return nil
 }
+
+// BootstrapAdminToken mints a strong API key for the initial admin user and
+// persists it to AdminTokenFile in the data directory. It does nothing if the
+// token file already exists, so it only takes effect on first run.
+func BootstrapAdminToken(store ExtendedStorage, cfg *config.Config) (string, error) {
+	ctx := context.Background()
+	path := filepath.Join(cfg.DataDir, AdminTokenFile)
+
+	if _, err := os.Stat(path); err == nil {
+		log.Info("Admin token already exists, not generating a new one", "path", path)
+		return path, nil
+	}
+
+	admin, err := findBootstrapAdmin(ctx, store, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := auth.GenerateKey()
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create admin token file: %w", err)
+	}
+	if _, err := fmt.Fprintln(f, token); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write admin token file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write admin token file: %w", err)
+	}
+
+	key := &model.APIKey{
+		Name:        "generated-admin-token",
+		Description: "Generated by rackd server --generate-token",
+		Key:         auth.HashToken(token),
+		UserID:      admin.ID,
+	}
+	if err := store.CreateAPIKey(ctx, key); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to create admin API key: %w", err)
+	}
+
+	log.Warn("Generated admin API token", "user", admin.Username, "path", path)
+	log.Warn("Use it with: export RACKD_TOKEN=$(cat " + path + ")")
+	return path, nil
+}
+
+// findBootstrapAdmin returns the configured initial admin, or the first
+// active admin user when no initial admin is configured
+func findBootstrapAdmin(ctx context.Context, store ExtendedStorage, cfg *config.Config) (*model.User, error) {
+	if cfg.InitialAdminUsername != "" {
+		user, err := store.GetUserByUsername(ctx, cfg.InitialAdminUsername)
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, ErrUserNotFound) {
+			return nil, err
+		}
+	}
+
+	isAdmin, isActive := true, true
+	users, err := store.ListUsers(ctx, &model.UserFilter{IsAdmin: &isAdmin, IsActive: &isActive})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("--generate-token needs an admin user: set INITIAL_ADMIN_USERNAME and INITIAL_ADMIN_PASSWORD for the first run")
+	}
+	return &users[0], nil
+}
@@ -2,8 +2,11 @@ package storage
 
 import (
 	"context"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/martinsuchenak/rackd/internal/auth"
 	"github.com/martinsuchenak/rackd/internal/config"
 	"github.com/martinsuchenak/rackd/internal/log"
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -189,3 +192,60 @@ func createTestUser(t *testing.T, db *SQLiteStorage, username, email string) (*m
 	err := db.CreateUser(ctx, user)
 	return user, err
 }
+
+func TestBootstrapAdminToken(t *testing.T) {
+	db := newTestStorage(t)
+	defer db.Close()
+
+	cfg := &config.Config{
+		DataDir:              t.TempDir(),
+		InitialAdminUsername: "testadmin",
+		InitialAdminPassword: "testpassword123",
+		InitialAdminEmail:    "admin@test.com",
+	}
+
+	if _, err := BootstrapAdminToken(db, cfg); err == nil {
+		t.Fatal("expected error when no admin user exists")
+	}
+
+	if err := BootstrapInitialAdmin(db, cfg, nil); err != nil {
+		t.Fatalf("BootstrapInitialAdmin() error = %v", err)
+	}
+
+	path, err := BootstrapAdminToken(db, cfg)
+	if err != nil {
+		t.Fatalf("BootstrapAdminToken() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("token file not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	data, _ := os.ReadFile(path)
+	token := strings.TrimSpace(string(data))
+	if len(token) < 40 {
+		t.Errorf("token too short: %d characters", len(token))
+	}
+
+	key, err := db.GetAPIKeyByKey(context.Background(), auth.HashToken(token))
+	if err != nil {
+		t.Fatalf("minted token not stored as API key: %v", err)
+	}
+	admin, _ := db.GetUserByUsername(context.Background(), "testadmin")
+	if key.UserID != admin.ID {
+		t.Errorf("API key owner = %s, want %s", key.UserID, admin.ID)
+	}
+
+	// A second run keeps the existing token
+	if _, err := BootstrapAdminToken(db, cfg); err != nil {
+		t.Fatalf("second BootstrapAdminToken() error = %v", err)
+	}
+	again, _ := os.ReadFile(path)
+	if string(again) != string(data) {
+		t.Error("expected existing token to be kept")
+	}
+}
@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -15,7 +17,9 @@ type BulkResult struct {
 	Errors  []string `json:"errors,omitempty"`
 }
 
-// BulkCreateDevices creates multiple devices in a transaction
+// BulkCreateDevices creates multiple devices in a transaction. All devices
+// are written with batched INSERTs; if that fails, each device is retried on
+// its own so the result reports which ones failed and the rest are kept.
 func (s *SQLiteStorage) BulkCreateDevices(ctx context.Context, devices []*model.Device) (*BulkResult, error) {
 	result := &BulkResult{Total: len(devices)}
 
@@ -25,12 +29,22 @@ func (s *SQLiteStorage) BulkCreateDevices(ctx context.Context, devices []*model.
 	}
 	defer tx.Rollback()
 
-	for _, device := range devices {
-		if err := s.createDeviceInTx(ctx, tx, device); err != nil {
-			result.Failed++
-			result.Errors = append(result.Errors, fmt.Sprintf("device %s: %v", device.Name, err))
-		} else {
-			result.Success++
+	err = inSavepoint(ctx, tx, func() error {
+		return s.createDevicesInTx(ctx, tx, devices)
+	})
+	if err == nil {
+		result.Success = len(devices)
+	} else {
+		for _, device := range devices {
+			err := inSavepoint(ctx, tx, func() error {
+				return s.createDeviceInTx(ctx, tx, device)
+			})
+			if err != nil {
+				result.Failed++
+				result.Errors = append(result.Errors, fmt.Sprintf("device %s: %v", device.Name, err))
+			} else {
+				result.Success++
+			}
 		}
 	}
 
@@ -42,6 +56,22 @@ func (s *SQLiteStorage) BulkCreateDevices(ctx context.Context, devices []*model.
 	return result, nil
 }
 
+// inSavepoint runs fn inside a savepoint of tx, undoing its writes if it fails
+func inSavepoint(ctx context.Context, tx *sql.Tx, fn func() error) error {
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT rackd_bulk`); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO rackd_bulk`); rbErr != nil {
+			return rbErr
+		}
+		tx.ExecContext(ctx, `RELEASE rackd_bulk`)
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `RELEASE rackd_bulk`)
+	return err
+}
+
 // BulkUpdateDevices updates multiple devices in a transaction
 func (s *SQLiteStorage) BulkUpdateDevices(ctx context.Context, devices []*model.Device) (*BulkResult, error) {
 	result := &BulkResult{Total: len(devices)}
@@ -69,7 +99,7 @@ func (s *SQLiteStorage) BulkUpdateDevices(ctx context.Context, devices []*model.
 	return result, nil
 }
 
-// BulkDeleteDevices deletes multiple devices in a transaction
+// BulkDeleteDevices moves multiple devices to the trash in a transaction
 func (s *SQLiteStorage) BulkDeleteDevices(ctx context.Context, ids []string) (*BulkResult, error) {
 	result := &BulkResult{Total: len(ids)}
 
@@ -80,7 +110,7 @@ func (s *SQLiteStorage) BulkDeleteDevices(ctx context.Context, ids []string) (*B
 	defer tx.Rollback()
 
 	for _, id := range ids {
-		if err := s.deleteDeviceInTx(ctx, tx, id); err != nil {
+		if err := s.trashInTx(ctx, tx, model.TrashTypeDevice, id, model.DeleteDetach); err != nil {
 			result.Failed++
 			result.Errors = append(result.Errors, fmt.Sprintf("device %s: %v", id, err))
 		} else {
@@ -181,6 +211,97 @@ func (s *SQLiteStorage) BulkRemoveTags(ctx context.Context, deviceIDs []string,
 	return result, nil
 }
 
+// ApplyBulkDeviceOperations applies the request's operations, in order, to
+// each of its devices in a single transaction. Any failure rolls the whole
+// request back. changedBy is recorded when an update changes a device status.
+func (s *SQLiteStorage) ApplyBulkDeviceOperations(ctx context.Context, req *model.BulkDeviceRequest, changedBy string) (*BulkResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range req.DeviceIDs {
+		var currentStatus model.DeviceStatus
+		err := tx.QueryRowContext(ctx, `SELECT status FROM devices WHERE id = ? AND deleted_at IS NULL`, id).Scan(&currentStatus)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("device %s: %w", id, ErrDeviceNotFound)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("device %s: failed to check device existence: %w", id, err)
+		}
+
+		for _, op := range req.Operations {
+			if err := s.applyBulkOperationInTx(ctx, tx, id, &currentStatus, op, changedBy); err != nil {
+				return nil, fmt.Errorf("device %s: %s: %w", id, op.Op, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	ops := make([]model.BulkOperationType, len(req.Operations))
+	for i, op := range req.Operations {
+		ops[i] = op.Op
+	}
+	s.auditLog(ctx, "bulk_apply", "device", "", map[string]interface{}{"count": len(req.DeviceIDs), "operations": ops})
+	return &BulkResult{Total: len(req.DeviceIDs), Success: len(req.DeviceIDs)}, nil
+}
+
+func (s *SQLiteStorage) applyBulkOperationInTx(ctx context.Context, tx *sql.Tx, id string, currentStatus *model.DeviceStatus, op model.BulkOperation, changedBy string) error {
+	now := nowUTC()
+	switch op.Op {
+	case model.BulkOpUpdate:
+		var sets []string
+		var args []any
+		set := func(column string, value *string) {
+			if value != nil {
+				sets = append(sets, column+" = ?")
+				args = append(args, *value)
+			}
+		}
+		set("description", op.Fields.Description)
+		set("make_model", op.Fields.MakeModel)
+		set("os", op.Fields.OS)
+		set("username", op.Fields.Username)
+		set("location", op.Fields.Location)
+		if status := op.Fields.Status; status != nil && *status != *currentStatus {
+			sets = append(sets, "status = ?", "status_changed_at = ?", "status_changed_by = ?")
+			args = append(args, *status, now, nullString(changedBy))
+			*currentStatus = *status
+		}
+		if len(sets) == 0 {
+			return nil
+		}
+		sets = append(sets, "updated_at = ?")
+		args = append(args, now, id)
+		_, err := tx.ExecContext(ctx, `UPDATE devices SET `+strings.Join(sets, ", ")+` WHERE id = ?`, args...)
+		return err
+	case model.BulkOpAddTags:
+		for _, tag := range op.Tags {
+			if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO tags (device_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+				return err
+			}
+		}
+	case model.BulkOpRemoveTags:
+		for _, tag := range op.Tags {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE device_id = ? AND tag = ?`, id, tag); err != nil {
+				return err
+			}
+		}
+	case model.BulkOpMoveDatacenter:
+		_, err := tx.ExecContext(ctx, `UPDATE devices SET datacenter_id = ?, updated_at = ? WHERE id = ?`, nullString(op.DatacenterID), now, id)
+		return err
+	case model.BulkOpDelete:
+		return s.trashInTx(ctx, tx, model.TrashTypeDevice, id, model.DeleteDetach)
+	default:
+		return fmt.Errorf("unknown operation")
+	}
+	return nil
+}
+
 // BulkCreateNetworks creates multiple networks in a transaction
 func (s *SQLiteStorage) BulkCreateNetworks(ctx context.Context, networks []*model.Network) (*BulkResult, error) {
 	result := &BulkResult{Total: len(networks)}
@@ -208,7 +329,7 @@ func (s *SQLiteStorage) BulkCreateNetworks(ctx context.Context, networks []*mode
 	return result, nil
 }
 
-// BulkDeleteNetworks deletes multiple networks in a transaction
+// BulkDeleteNetworks moves multiple networks to the trash in a transaction
 func (s *SQLiteStorage) BulkDeleteNetworks(ctx context.Context, ids []string) (*BulkResult, error) {
 	result := &BulkResult{Total: len(ids)}
 
@@ -219,7 +340,7 @@ func (s *SQLiteStorage) BulkDeleteNetworks(ctx context.Context, ids []string) (*
 	defer tx.Rollback()
 
 	for _, id := range ids {
-		if err := s.deleteNetworkInTx(ctx, tx, id); err != nil {
+		if err := s.trashInTx(ctx, tx, model.TrashTypeNetwork, id, model.DeleteDetach); err != nil {
 			result.Failed++
 			result.Errors = append(result.Errors, fmt.Sprintf("network %s: %v", id, err))
 		} else {
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -199,3 +201,105 @@ func TestBulkDeleteNetworks(t *testing.T) {
 		t.Errorf("expected success 2, got %d", result.Success)
 	}
 }
+
+func TestApplyBulkDeviceOperations(t *testing.T) {
+	store, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	device := &model.Device{Name: "device1", Status: model.DeviceStatusActive, Tags: []string{"a"}}
+	if err := store.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("failed to create device: %v", err)
+	}
+
+	status := model.DeviceStatusMaintenance
+	location := "Rack C3"
+	req := &model.BulkDeviceRequest{
+		DeviceIDs: []string{device.ID},
+		Operations: []model.BulkOperation{
+			{Op: model.BulkOpUpdate, Fields: &model.BulkDeviceFields{Status: &status, Location: &location}},
+			{Op: model.BulkOpAddTags, Tags: []string{"a", "b"}},
+			{Op: model.BulkOpRemoveTags, Tags: []string{"a"}},
+		},
+	}
+	result, err := store.ApplyBulkDeviceOperations(ctx, req, "user-1")
+	if err != nil {
+		t.Fatalf("ApplyBulkDeviceOperations failed: %v", err)
+	}
+	if result.Total != 1 || result.Success != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	got, err := store.GetDevice(ctx, device.ID)
+	if err != nil {
+		t.Fatalf("GetDevice failed: %v", err)
+	}
+	if got.Status != status || got.Location != location || got.StatusChangedBy != "user-1" || got.StatusChangedAt == nil {
+		t.Errorf("fields not updated: %+v", got)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "b" {
+		t.Errorf("expected tags [b], got %v", got.Tags)
+	}
+
+	// A missing device fails the request and leaves the others untouched
+	req = &model.BulkDeviceRequest{
+		DeviceIDs:  []string{device.ID, "missing"},
+		Operations: []model.BulkOperation{{Op: model.BulkOpDelete}},
+	}
+	if _, err := store.ApplyBulkDeviceOperations(ctx, req, ""); !errors.Is(err, ErrDeviceNotFound) {
+		t.Fatalf("expected ErrDeviceNotFound, got %v", err)
+	}
+	if _, err := store.GetDevice(ctx, device.ID); err != nil {
+		t.Errorf("expected device to survive the rolled back delete: %v", err)
+	}
+}
+
+func TestBulkCreateDevices_BatchesAndReportsFailures(t *testing.T) {
+	store, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	// More addresses than fit one INSERT statement
+	var addresses []model.Address
+	for i := range maxInsertParams + 20 {
+		addresses = append(addresses, model.Address{IP: fmt.Sprintf("10.%d.%d.1", i/250, i%250), Type: "ipv4"})
+	}
+	big := &model.Device{Name: "big", Addresses: addresses, Tags: []string{"a", "b"}, Domains: []string{"example.com"}}
+	// A repeated tag violates the tags primary key
+	broken := &model.Device{Name: "broken", Tags: []string{"dup", "dup"}, Addresses: []model.Address{{IP: "10.99.0.1"}}}
+	small := &model.Device{Name: "small", Tags: []string{"a"}}
+
+	result, err := store.BulkCreateDevices(ctx, []*model.Device{big, broken, small})
+	if err != nil {
+		t.Fatalf("BulkCreateDevices failed: %v", err)
+	}
+	if result.Success != 2 || result.Failed != 1 || len(result.Errors) != 1 {
+		t.Fatalf("expected 2 successes and 1 failure, got %+v", result)
+	}
+
+	got, err := store.GetDevice(ctx, big.ID)
+	if err != nil {
+		t.Fatalf("GetDevice failed: %v", err)
+	}
+	if len(got.Addresses) != len(addresses) || len(got.Tags) != 2 || len(got.Domains) != 1 {
+		t.Errorf("expected %d addresses, 2 tags and 1 domain, got %d, %d and %d", len(addresses), len(got.Addresses), len(got.Tags), len(got.Domains))
+	}
+	if _, err := store.GetDevice(ctx, small.ID); err != nil {
+		t.Errorf("expected small device to be created: %v", err)
+	}
+
+	// The failed device leaves nothing behind
+	if _, err := store.GetDevice(ctx, broken.ID); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("expected broken device to be rolled back, got %v", err)
+	}
+	var orphans int
+	if err := store.DB().QueryRow(`SELECT COUNT(*) FROM addresses WHERE ip = '10.99.0.1'`).Scan(&orphans); err != nil || orphans != 0 {
+		t.Errorf("expected no addresses of the failed device, got %d (%v)", orphans, err)
+	}
+}
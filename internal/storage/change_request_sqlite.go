@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const changeRequestColumns = `id, status, method, path, body, before_state, requested_by, requested_by_name,
+	reviewed_by, reviewed_by_name, review_comment, result_status, result, created_at, reviewed_at`
+
+// CreateChangeRequest stores a new pending change request
+func (s *SQLiteStorage) CreateChangeRequest(ctx context.Context, c *model.ChangeRequest) error {
+	if c.ID == "" {
+		c.ID = newUUID()
+	}
+	c.Status = model.ChangePending
+	c.CreatedAt = nowUTC()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO change_requests (`+changeRequestColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, c.ID, c.Status, c.Method, c.Path, string(c.Body), string(c.Before), c.RequestedBy, c.RequestedByName,
+		c.ReviewedBy, c.ReviewedByName, c.ReviewComment, c.ResultStatus, string(c.Result), c.CreatedAt, nullTime(c.ReviewedAt))
+	if err != nil {
+		return err
+	}
+	s.auditLog(ctx, "create", "change_request", c.ID, c)
+	return nil
+}
+
+// GetChangeRequest retrieves a change request by ID
+func (s *SQLiteStorage) GetChangeRequest(ctx context.Context, id string) (*model.ChangeRequest, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+changeRequestColumns+` FROM change_requests WHERE id = ?`, id)
+	c, err := scanChangeRequest(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrChangeRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ListChangeRequests returns change requests, newest first
+func (s *SQLiteStorage) ListChangeRequests(ctx context.Context, filter *model.ChangeRequestFilter) ([]model.ChangeRequest, error) {
+	query := `SELECT ` + changeRequestColumns + ` FROM change_requests`
+	var conditions []string
+	var args []any
+
+	if filter != nil {
+		if filter.Status != "" {
+			conditions = append(conditions, "status = ?")
+			args = append(args, filter.Status)
+		}
+		if filter.RequestedBy != "" {
+			conditions = append(conditions, "requested_by = ?")
+			args = append(args, filter.RequestedBy)
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id"
+
+	var pg *model.Pagination
+	if filter != nil {
+		pg = &filter.Pagination
+	}
+	query, args = appendPagination(query, args, pg)
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []model.ChangeRequest{}
+	for rows.Next() {
+		c, err := scanChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, *c)
+	}
+	return changes, rows.Err()
+}
+
+// ReviewChangeRequest records the review of a pending change request. Only
+// one review can win: a change that is no longer pending is left alone and
+// ErrChangeNotPending is returned.
+func (s *SQLiteStorage) ReviewChangeRequest(ctx context.Context, c *model.ChangeRequest) error {
+	now := nowUTC()
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE change_requests SET status = ?, reviewed_by = ?, reviewed_by_name = ?, review_comment = ?, reviewed_at = ?
+		WHERE id = ? AND status = ?
+	`, c.Status, c.ReviewedBy, c.ReviewedByName, c.ReviewComment, now, c.ID, model.ChangePending)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		if _, err := s.GetChangeRequest(ctx, c.ID); err != nil {
+			return err
+		}
+		return ErrChangeNotPending
+	}
+	c.ReviewedAt = &now
+	s.auditLog(ctx, string(c.Status), "change_request", c.ID, c)
+	return nil
+}
+
+// SetChangeRequestResult records the outcome of applying an approved change
+func (s *SQLiteStorage) SetChangeRequestResult(ctx context.Context, id string, status model.ChangeStatus, resultStatus int, body []byte) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE change_requests SET status = ?, result_status = ?, result = ? WHERE id = ?
+	`, status, resultStatus, string(body), id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrChangeRequestNotFound
+	}
+	return nil
+}
+
+func scanChangeRequest(row interface{ Scan(...any) error }) (*model.ChangeRequest, error) {
+	var c model.ChangeRequest
+	var body, before, result string
+	var reviewedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.Status, &c.Method, &c.Path, &body, &before, &c.RequestedBy, &c.RequestedByName,
+		&c.ReviewedBy, &c.ReviewedByName, &c.ReviewComment, &c.ResultStatus, &result, &c.CreatedAt, &reviewedAt); err != nil {
+		return nil, err
+	}
+	if body != "" {
+		c.Body = []byte(body)
+	}
+	if before != "" {
+		c.Before = []byte(before)
+	}
+	if result != "" {
+		c.Result = []byte(result)
+	}
+	if reviewedAt.Valid {
+		c.ReviewedAt = &reviewedAt.Time
+	}
+	return &c, nil
+}
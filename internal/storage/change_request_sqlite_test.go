@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestChangeRequestLifecycle(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	change := &model.ChangeRequest{
+		Method:          "PUT",
+		Path:            "/api/devices/dev-1",
+		Body:            []byte(`{"name":"web-02"}`),
+		Before:          []byte(`{"id":"dev-1","name":"web-01"}`),
+		RequestedBy:     "user-1",
+		RequestedByName: "alice",
+	}
+	if err := storage.CreateChangeRequest(ctx, change); err != nil {
+		t.Fatalf("CreateChangeRequest failed: %v", err)
+	}
+	storage.CreateChangeRequest(ctx, &model.ChangeRequest{Method: "DELETE", Path: "/api/devices/dev-2", RequestedBy: "user-2"})
+
+	got, err := storage.GetChangeRequest(ctx, change.ID)
+	if err != nil {
+		t.Fatalf("GetChangeRequest failed: %v", err)
+	}
+	if got.Status != model.ChangePending || string(got.Body) != `{"name":"web-02"}` || string(got.Before) == "" || got.ReviewedAt != nil {
+		t.Errorf("change mismatch: got %+v", got)
+	}
+	if _, err := storage.GetChangeRequest(ctx, "missing"); !errors.Is(err, ErrChangeRequestNotFound) {
+		t.Errorf("expected ErrChangeRequestNotFound, got %v", err)
+	}
+
+	mine, _ := storage.ListChangeRequests(ctx, &model.ChangeRequestFilter{RequestedBy: "user-1"})
+	if len(mine) != 1 || mine[0].ID != change.ID {
+		t.Errorf("expected only user-1's change, got %+v", mine)
+	}
+
+	change.Status = model.ChangeApproved
+	change.ReviewedBy = "admin-1"
+	change.ReviewComment = "ok"
+	if err := storage.ReviewChangeRequest(ctx, change); err != nil {
+		t.Fatalf("ReviewChangeRequest failed: %v", err)
+	}
+	// A second review loses
+	again := &model.ChangeRequest{ID: change.ID, Status: model.ChangeRejected}
+	if err := storage.ReviewChangeRequest(ctx, again); !errors.Is(err, ErrChangeNotPending) {
+		t.Errorf("expected ErrChangeNotPending, got %v", err)
+	}
+	if err := storage.ReviewChangeRequest(ctx, &model.ChangeRequest{ID: "missing", Status: model.ChangeRejected}); !errors.Is(err, ErrChangeRequestNotFound) {
+		t.Errorf("expected ErrChangeRequestNotFound, got %v", err)
+	}
+
+	if err := storage.SetChangeRequestResult(ctx, change.ID, model.ChangeApplied, 200, []byte(`{"id":"dev-1"}`)); err != nil {
+		t.Fatalf("SetChangeRequestResult failed: %v", err)
+	}
+	got, _ = storage.GetChangeRequest(ctx, change.ID)
+	if got.Status != model.ChangeApplied || got.ResultStatus != 200 || got.ReviewedBy != "admin-1" || got.ReviewedAt == nil {
+		t.Errorf("applied change mismatch: got %+v", got)
+	}
+
+	pending, _ := storage.ListChangeRequests(ctx, &model.ChangeRequestFilter{Status: model.ChangePending})
+	if len(pending) != 1 || pending[0].Path != "/api/devices/dev-2" {
+		t.Errorf("expected one pending change, got %+v", pending)
+	}
+}
@@ -52,7 +52,7 @@ func (s *SQLiteStorage) GetCircuit(ctx context.Context, id string) (*model.Circu
 	var datacenterAID, datacenterBID, deviceAID, deviceBID sql.NullString
 	var installDate, terminateDate sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, circuit_id, provider, type, status, capacity_mbps,
 			datacenter_a_id, datacenter_b_id, device_a_id, device_b_id,
 			port_a, port_b, ip_address_a, ip_address_b, vlan_id,
@@ -113,7 +113,7 @@ func (s *SQLiteStorage) GetCircuitByCircuitID(ctx context.Context, circuitID str
 	var datacenterAID, datacenterBID, deviceAID, deviceBID sql.NullString
 	var installDate, terminateDate sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, circuit_id, provider, type, status, capacity_mbps,
 			datacenter_a_id, datacenter_b_id, device_a_id, device_b_id,
 			port_a, port_b, ip_address_a, ip_address_b, vlan_id,
@@ -213,7 +213,7 @@ func (s *SQLiteStorage) ListCircuits(ctx context.Context, filter *model.CircuitF
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list circuits: %w", err)
 	}
@@ -344,7 +344,7 @@ func (s *SQLiteStorage) GetCircuitsByDevice(ctx context.Context, deviceID string
 		tags, created_at, updated_at
 		FROM circuits WHERE device_a_id = ? OR device_b_id = ?`
 
-	rows, err := s.db.QueryContext(ctx, query, deviceID, deviceID)
+	rows, err := s.reader.QueryContext(ctx, query, deviceID, deviceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get circuits by device: %w", err)
 	}
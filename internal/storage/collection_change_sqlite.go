@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// DevicesChangedAt returns when the device listing last changed. Triggers
+// stamp any change to devices, their interfaces, addresses, tags, domains and
+// relationships, monitoring results and maintenance windows; a window starting
+// or ending changes the listing too, without a write.
+func (s *SQLiteStorage) DevicesChangedAt(ctx context.Context) (time.Time, error) {
+	changed, err := s.collectionChangedAt(ctx, "devices")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := nowUTC()
+	for _, column := range []string{"starts_at", "ends_at"} {
+		var at time.Time
+		err := s.reader.QueryRowContext(ctx, `
+			SELECT `+column+` FROM maintenance_windows WHERE `+column+` <= ? ORDER BY `+column+` DESC LIMIT 1
+		`, now).Scan(&at)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		if at = at.UTC().Truncate(time.Second); at.After(changed) {
+			changed = at
+		}
+	}
+	return changed, nil
+}
+
+// NetworksChangedAt returns when the network listing last changed
+func (s *SQLiteStorage) NetworksChangedAt(ctx context.Context) (time.Time, error) {
+	return s.collectionChangedAt(ctx, "networks")
+}
+
+// DatacentersChangedAt returns when a datacenter was last created, changed or
+// deleted
+func (s *SQLiteStorage) DatacentersChangedAt(ctx context.Context) (time.Time, error) {
+	return s.collectionChangedAt(ctx, "datacenters")
+}
+
+func (s *SQLiteStorage) collectionChangedAt(ctx context.Context, collection string) (time.Time, error) {
+	var changedAt int64
+	err := s.reader.QueryRowContext(ctx, `SELECT changed_at FROM collection_changes WHERE collection = ?`, collection).Scan(&changedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(changedAt, 0).UTC(), nil
+}
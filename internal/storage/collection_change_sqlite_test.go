@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestCollectionChangedAt(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	// Wind the stamps back so changes can be told apart at second precision
+	rewind := func(t *testing.T) {
+		t.Helper()
+		if _, err := storage.DB().ExecContext(ctx, `UPDATE collection_changes SET changed_at = 0`); err != nil {
+			t.Fatalf("failed to reset collection_changes: %v", err)
+		}
+	}
+	changedRecently := func(t *testing.T, changedAt func(context.Context) (time.Time, error), what string) {
+		t.Helper()
+		at, err := changedAt(ctx)
+		if err != nil {
+			t.Fatalf("failed to read change time: %v", err)
+		}
+		if time.Since(at) > time.Minute {
+			t.Errorf("expected %s to stamp the listing, got %v", what, at)
+		}
+	}
+
+	rewind(t)
+	device := &model.Device{Name: "web-01"}
+	if err := storage.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+	changedRecently(t, storage.DevicesChangedAt, "creating a device")
+	if at, _ := storage.NetworksChangedAt(ctx); !at.Equal(time.Unix(0, 0)) {
+		t.Errorf("expected the network listing untouched, got %v", at)
+	}
+
+	rewind(t)
+	if _, err := storage.DB().ExecContext(ctx, `DELETE FROM devices WHERE id = ?`, device.ID); err != nil {
+		t.Fatalf("failed to delete device: %v", err)
+	}
+	changedRecently(t, storage.DevicesChangedAt, "deleting a device")
+
+	rewind(t)
+	if err := storage.CreateNetwork(ctx, &model.Network{Name: "lan", Subnet: "10.0.0.0/24"}); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	changedRecently(t, storage.NetworksChangedAt, "creating a network")
+
+	// A maintenance window ending changes the listing without a write
+	endedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	window := &model.MaintenanceWindow{Tag: "web", StartsAt: endedAt.Add(-time.Hour), EndsAt: endedAt}
+	if err := storage.CreateMaintenanceWindow(ctx, window); err != nil {
+		t.Fatalf("CreateMaintenanceWindow failed: %v", err)
+	}
+	rewind(t)
+	if at, _ := storage.DevicesChangedAt(ctx); !at.Equal(endedAt) {
+		t.Errorf("expected the listing changed when the window ended at %v, got %v", endedAt, at)
+	}
+}
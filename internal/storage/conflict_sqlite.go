@@ -80,7 +80,7 @@ func (s *SQLiteStorage) GetConflict(ctx context.Context, id string) (*model.Conf
 	var resolvedAt sql.NullTime
 	var deviceIDsJSON, deviceNamesJSON, networkIDsJSON, networkNamesJSON, subnetsJSON sql.NullString
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, type, status, description, ip_address, device_ids, device_names,
 		       network_ids, network_names, subnets, detected_at, resolved_at, resolved_by, notes
 		FROM conflicts WHERE id = ?
@@ -163,7 +163,7 @@ func (s *SQLiteStorage) ListConflicts(ctx context.Context, filter *model.Conflic
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list conflicts: %w", err)
 	}
@@ -267,11 +267,11 @@ func (s *SQLiteStorage) DeleteConflict(ctx context.Context, id string) error {
 // FindDuplicateIPs finds all IP addresses that are assigned to multiple devices
 func (s *SQLiteStorage) FindDuplicateIPs(ctx context.Context) ([]model.Conflict, error) {
 	// Find IPs that appear more than once in the addresses table
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT ip, GROUP_CONCAT(device_id) as device_ids, GROUP_CONCAT(d.name) as device_names, COUNT(*) as count
 		FROM addresses a
 		JOIN devices d ON a.device_id = d.id
-		WHERE ip != '' AND ip IS NOT NULL
+		WHERE ip != '' AND ip IS NOT NULL AND d.deleted_at IS NULL
 		GROUP BY ip
 		HAVING count > 1
 	`)
@@ -315,8 +315,8 @@ func (s *SQLiteStorage) FindDuplicateIPs(ctx context.Context) ([]model.Conflict,
 // FindOverlappingSubnets finds all network subnets that overlap
 func (s *SQLiteStorage) FindOverlappingSubnets(ctx context.Context) ([]model.Conflict, error) {
 	// Get all networks
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, subnet FROM networks ORDER BY subnet
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, name, subnet FROM networks WHERE deleted_at IS NULL ORDER BY subnet
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get networks: %w", err)
@@ -399,7 +399,7 @@ func (s *SQLiteStorage) GetConflictsByDeviceID(ctx context.Context, deviceID str
 	}
 
 	// Use JSON extraction to find conflicts containing this device ID
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT id, type, status, description, ip_address, device_ids, device_names,
 		       network_ids, network_names, subnets, detected_at, resolved_at, resolved_by, notes
 		FROM conflicts
@@ -421,7 +421,7 @@ func (s *SQLiteStorage) GetConflictsByIP(ctx context.Context, ip string) ([]mode
 		return nil, ErrInvalidID
 	}
 
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT id, type, status, description, ip_address, device_ids, device_names,
 		       network_ids, network_names, subnets, detected_at, resolved_at, resolved_by, notes
 		FROM conflicts
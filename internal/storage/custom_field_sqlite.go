@@ -43,7 +43,7 @@ func (s *SQLiteStorage) GetCustomFieldDefinition(ctx context.Context, id string)
 	def := &model.CustomFieldDefinition{}
 	var optionsJSON string
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, key, type, required, options, description, created_at, updated_at
 		FROM custom_field_definitions WHERE id = ?
 	`, id).Scan(&def.ID, &def.Name, &def.Key, &def.Type, &def.Required, &optionsJSON, &def.Description, &def.CreatedAt, &def.UpdatedAt)
@@ -67,7 +67,7 @@ func (s *SQLiteStorage) GetCustomFieldDefinitionByKey(ctx context.Context, key s
 	def := &model.CustomFieldDefinition{}
 	var optionsJSON string
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, key, type, required, options, description, created_at, updated_at
 		FROM custom_field_definitions WHERE key = ?
 	`, key).Scan(&def.ID, &def.Name, &def.Key, &def.Type, &def.Required, &optionsJSON, &def.Description, &def.CreatedAt, &def.UpdatedAt)
@@ -105,7 +105,7 @@ func (s *SQLiteStorage) ListCustomFieldDefinitions(ctx context.Context, filter *
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -198,7 +198,7 @@ func (s *SQLiteStorage) SetCustomFieldValue(ctx context.Context, value *model.Cu
 
 // GetCustomFieldValues retrieves all custom field values for a device
 func (s *SQLiteStorage) GetCustomFieldValues(ctx context.Context, deviceID string) ([]model.CustomFieldValue, error) {
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT id, device_id, field_id, string_value, number_value, bool_value
 		FROM custom_field_values WHERE device_id = ?
 	`, deviceID)
@@ -216,7 +216,7 @@ func (s *SQLiteStorage) GetCustomFieldValue(ctx context.Context, deviceID, field
 	var numberValue sql.NullInt64
 	var boolValue sql.NullBool
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, device_id, field_id, string_value, number_value, bool_value
 		FROM custom_field_values WHERE device_id = ? AND field_id = ?
 	`, deviceID, fieldID).Scan(&value.ID, &value.DeviceID, &value.FieldID, &value.StringValue, &numberValue, &boolValue)
@@ -329,7 +329,7 @@ func containsString(s, substr string) bool {
 func (s *SQLiteStorage) GetDevicesByCustomField(ctx context.Context, fieldKey, value string) ([]string, error) {
 	// First get the field ID from the key
 	var fieldID string
-	err := s.db.QueryRowContext(ctx, `SELECT id FROM custom_field_definitions WHERE key = ?`, fieldKey).Scan(&fieldID)
+	err := s.reader.QueryRowContext(ctx, `SELECT id FROM custom_field_definitions WHERE key = ?`, fieldKey).Scan(&fieldID)
 	if err == sql.ErrNoRows {
 		return nil, ErrCustomFieldNotFound
 	}
@@ -338,7 +338,7 @@ func (s *SQLiteStorage) GetDevicesByCustomField(ctx context.Context, fieldKey, v
 	}
 
 	// Find devices with matching value
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT device_id FROM custom_field_values WHERE field_id = ? AND string_value = ?
 	`, fieldID, value)
 	if err != nil {
@@ -369,7 +369,7 @@ func (s *SQLiteStorage) GetCustomFieldValuesWithDefinitions(ctx context.Context,
 		ORDER BY d.name ASC
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, deviceID)
+	rows, err := s.reader.QueryContext(ctx, query, deviceID)
 	if err != nil {
 		return nil, err
 	}
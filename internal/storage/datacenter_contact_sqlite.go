@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const datacenterContactColumns = `id, datacenter_id, role, name, email, phone, escalation_order, created_at, updated_at`
+
+// ListDatacenterContacts returns the contacts of a datacenter in escalation
+// order
+func (s *SQLiteStorage) ListDatacenterContacts(ctx context.Context, datacenterID string) ([]model.DatacenterContact, error) {
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT `+datacenterContactColumns+` FROM datacenter_contacts
+		WHERE datacenter_id = ? ORDER BY escalation_order, name
+	`, datacenterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := []model.DatacenterContact{}
+	for rows.Next() {
+		c, err := scanDatacenterContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, *c)
+	}
+	return contacts, rows.Err()
+}
+
+// GetDatacenterContact retrieves a datacenter contact by ID
+func (s *SQLiteStorage) GetDatacenterContact(ctx context.Context, id string) (*model.DatacenterContact, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+datacenterContactColumns+` FROM datacenter_contacts WHERE id = ?`, id)
+	c, err := scanDatacenterContact(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrContactNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CreateDatacenterContact adds a contact to a datacenter
+func (s *SQLiteStorage) CreateDatacenterContact(ctx context.Context, c *model.DatacenterContact) error {
+	if c.ID == "" {
+		c.ID = newUUID()
+	}
+	now := nowUTC()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO datacenter_contacts (`+datacenterContactColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, c.ID, c.DatacenterID, c.Role, c.Name, c.Email, c.Phone, c.EscalationOrder, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	s.auditLog(ctx, "create", "datacenter_contact", c.ID, c)
+	return nil
+}
+
+// UpdateDatacenterContact updates an existing datacenter contact. The
+// datacenter it belongs to cannot be changed.
+func (s *SQLiteStorage) UpdateDatacenterContact(ctx context.Context, c *model.DatacenterContact) error {
+	c.UpdatedAt = nowUTC()
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE datacenter_contacts SET role = ?, name = ?, email = ?, phone = ?, escalation_order = ?, updated_at = ?
+		WHERE id = ?
+	`, c.Role, c.Name, c.Email, c.Phone, c.EscalationOrder, c.UpdatedAt, c.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrContactNotFound
+	}
+	s.auditLog(ctx, "update", "datacenter_contact", c.ID, c)
+	return nil
+}
+
+// DeleteDatacenterContact removes a datacenter contact by ID
+func (s *SQLiteStorage) DeleteDatacenterContact(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM datacenter_contacts WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrContactNotFound
+	}
+	s.auditLog(ctx, "delete", "datacenter_contact", id, nil)
+	return nil
+}
+
+func scanDatacenterContact(row interface{ Scan(...any) error }) (*model.DatacenterContact, error) {
+	var c model.DatacenterContact
+	if err := row.Scan(&c.ID, &c.DatacenterID, &c.Role, &c.Name, &c.Email, &c.Phone, &c.EscalationOrder,
+		&c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -10,10 +10,12 @@ import (
 
 // Datacenter operations
 
+const datacenterColumns = `id, name, location, description, shipping_address, access_instructions, created_at, updated_at`
+
 // ensureDefaultDatacenter creates a default datacenter if none exists
 func (s *SQLiteStorage) ensureDefaultDatacenter(ctx context.Context) error {
 	var count int
-	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM datacenters`).Scan(&count)
+	err := s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM datacenters`).Scan(&count)
 	if err != nil {
 		return fmt.Errorf("failed to check datacenter count: %w", err)
 	}
@@ -36,13 +38,19 @@ func (s *SQLiteStorage) ensureDefaultDatacenter(ctx context.Context) error {
 // ListDatacenters retrieves all datacenters matching the filter criteria
 func (s *SQLiteStorage) ListDatacenters(ctx context.Context, filter *model.DatacenterFilter) ([]model.Datacenter, error) {
 
-	query := `SELECT id, name, location, description, created_at, updated_at FROM datacenters`
+	query := `SELECT ` + datacenterColumns + ` FROM datacenters WHERE deleted_at IS NULL`
 	var args []any
 
 	if filter != nil && filter.Name != "" {
-		query += " WHERE name LIKE ?"
+		query += " AND name LIKE ?"
 		args = append(args, "%"+filter.Name+"%")
 	}
+	if filter != nil && len(filter.IDs) > 0 {
+		query += " AND id IN (" + placeholders(len(filter.IDs)) + ")"
+		for _, id := range filter.IDs {
+			args = append(args, id)
+		}
+	}
 
 	query += " ORDER BY name"
 
@@ -52,7 +60,7 @@ func (s *SQLiteStorage) ListDatacenters(ctx context.Context, filter *model.Datac
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list datacenters: %w", err)
 	}
@@ -60,11 +68,11 @@ func (s *SQLiteStorage) ListDatacenters(ctx context.Context, filter *model.Datac
 
 	var datacenters []model.Datacenter
 	for rows.Next() {
-		var dc model.Datacenter
-		if err := rows.Scan(&dc.ID, &dc.Name, &dc.Location, &dc.Description, &dc.CreatedAt, &dc.UpdatedAt); err != nil {
+		dc, err := scanDatacenter(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan datacenter: %w", err)
 		}
-		datacenters = append(datacenters, dc)
+		datacenters = append(datacenters, *dc)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -85,14 +93,14 @@ func (s *SQLiteStorage) SearchDatacenters(ctx context.Context, query string) ([]
 	}
 
 	ftsQuery := escapeFTSQuery(query)
+	limitClause, limitArgs := searchLimitClause(ctx)
 
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT d.id, d.name, d.location, d.description, d.created_at, d.updated_at
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT d.id, d.name, d.location, d.description, d.shipping_address, d.access_instructions, d.created_at, d.updated_at
 		FROM datacenters d
 		INNER JOIN datacenters_fts fts ON d.id = fts.id
-		WHERE datacenters_fts MATCH ?
-		ORDER BY d.name
-	`, ftsQuery)
+		WHERE datacenters_fts MATCH ? AND d.deleted_at IS NULL
+		ORDER BY d.name`+limitClause, append([]any{ftsQuery}, limitArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search datacenters: %w", err)
 	}
@@ -100,16 +108,19 @@ func (s *SQLiteStorage) SearchDatacenters(ctx context.Context, query string) ([]
 
 	var datacenters []model.Datacenter
 	for rows.Next() {
-		var dc model.Datacenter
-		if err := rows.Scan(&dc.ID, &dc.Name, &dc.Location, &dc.Description, &dc.CreatedAt, &dc.UpdatedAt); err != nil {
+		dc, err := scanDatacenter(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan datacenter: %w", err)
 		}
-		datacenters = append(datacenters, dc)
+		datacenters = append(datacenters, *dc)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if err := checkSearchLimit(ctx, len(datacenters)); err != nil {
+		return nil, err
+	}
 
 	if datacenters == nil {
 		datacenters = []model.Datacenter{}
@@ -124,12 +135,11 @@ func (s *SQLiteStorage) GetDatacenter(ctx context.Context, id string) (*model.Da
 		return nil, ErrInvalidID
 	}
 
-	dc := &model.Datacenter{}
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, location, description, created_at, updated_at
-		FROM datacenters WHERE id = ?
-	`, id).Scan(&dc.ID, &dc.Name, &dc.Location, &dc.Description, &dc.CreatedAt, &dc.UpdatedAt)
-
+	row := s.reader.QueryRowContext(ctx, `
+		SELECT `+datacenterColumns+`
+		FROM datacenters WHERE id = ? AND deleted_at IS NULL
+	`, id)
+	dc, err := scanDatacenter(row)
 	if err == sql.ErrNoRows {
 		return nil, ErrDatacenterNotFound
 	}
@@ -137,6 +147,9 @@ func (s *SQLiteStorage) GetDatacenter(ctx context.Context, id string) (*model.Da
 		return nil, fmt.Errorf("failed to get datacenter: %w", err)
 	}
 
+	if dc.Contacts, err = s.ListDatacenterContacts(ctx, id); err != nil {
+		return nil, err
+	}
 	return dc, nil
 }
 
@@ -156,9 +169,9 @@ func (s *SQLiteStorage) CreateDatacenter(ctx context.Context, dc *model.Datacent
 	dc.UpdatedAt = now
 
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO datacenters (id, name, location, description, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, dc.ID, dc.Name, dc.Location, dc.Description, dc.CreatedAt, dc.UpdatedAt)
+		INSERT INTO datacenters (`+datacenterColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, dc.ID, dc.Name, dc.Location, dc.Description, dc.ShippingAddress, dc.AccessInstructions, dc.CreatedAt, dc.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create datacenter: %w", err)
@@ -179,7 +192,7 @@ func (s *SQLiteStorage) UpdateDatacenter(ctx context.Context, dc *model.Datacent
 
 	// Check if datacenter exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM datacenters WHERE id = ?)`, dc.ID).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM datacenters WHERE id = ? AND deleted_at IS NULL)`, dc.ID).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check datacenter existence: %w", err)
 	}
@@ -189,28 +202,58 @@ func (s *SQLiteStorage) UpdateDatacenter(ctx context.Context, dc *model.Datacent
 
 	dc.UpdatedAt = nowUTC()
 
-	_, err = s.db.ExecContext(ctx, `
-		UPDATE datacenters SET name = ?, location = ?, description = ?, updated_at = ?
-		WHERE id = ?
-	`, dc.Name, dc.Location, dc.Description, dc.UpdatedAt, dc.ID)
+	versionClause, versionArgs := updatedAtClause(ctx)
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE datacenters SET name = ?, location = ?, description = ?, shipping_address = ?,
+			access_instructions = ?, updated_at = ?
+		WHERE id = ?`+versionClause,
+		append([]any{dc.Name, dc.Location, dc.Description, dc.ShippingAddress, dc.AccessInstructions, dc.UpdatedAt, dc.ID}, versionArgs...)...)
 
 	if err != nil {
 		return fmt.Errorf("failed to update datacenter: %w", err)
 	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrModified
+	}
 
 	s.auditLog(ctx, "update", "datacenter", dc.ID, dc)
 	return nil
 }
 
-// DeleteDatacenter removes a datacenter by ID
-func (s *SQLiteStorage) DeleteDatacenter(ctx context.Context, id string) error {
+// DeleteDatacenter removes a datacenter by ID. Under detach its devices and
+// networks are kept without a datacenter, under cascade they are deleted with
+// the same policy, and deny refuses while any exist. Devices and networks in
+// the trash do not count under any policy; they stay in the trash without a
+// datacenter.
+func (s *SQLiteStorage) DeleteDatacenter(ctx context.Context, id string, policy model.DeletePolicy) error {
 	if id == "" {
 		return ErrInvalidID
 	}
 
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.deleteDatacenterInTx(ctx, tx, id, policy); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.auditLog(ctx, "delete", "datacenter", id, nil)
+	return nil
+}
+
+// deleteDatacenterInTx deletes a datacenter within an existing transaction
+func (s *SQLiteStorage) deleteDatacenterInTx(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error {
+
 	// Check if datacenter exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM datacenters WHERE id = ?)`, id).Scan(&exists)
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM datacenters WHERE id = ?)`, id).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check datacenter existence: %w", err)
 	}
@@ -218,31 +261,78 @@ func (s *SQLiteStorage) DeleteDatacenter(ctx context.Context, id string) error {
 		return ErrDatacenterNotFound
 	}
 
-	// Check for dependent devices
-	var deviceCount int
-	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM devices WHERE datacenter_id = ?`, id).Scan(&deviceCount)
-	if err != nil {
-		return fmt.Errorf("failed to check dependent devices: %w", err)
+	if err := applyDeletePolicy(ctx, tx, "datacenter", id, policy, s.datacenterDependents()); err != nil {
+		return err
 	}
 
-	// Unlink devices from datacenter (set datacenter_id to NULL)
-	if deviceCount > 0 {
-		_, err = s.db.ExecContext(ctx, `UPDATE devices SET datacenter_id = NULL WHERE datacenter_id = ?`, id)
-		if err != nil {
-			return fmt.Errorf("failed to unlink devices: %w", err)
-		}
+	// Trashed devices and networks are not dependents, whatever the policy:
+	// they stay in the trash and come back without a datacenter if restored
+	if _, err := tx.ExecContext(ctx, `UPDATE devices SET datacenter_id = NULL WHERE datacenter_id = ? AND deleted_at IS NOT NULL`, id); err != nil {
+		return fmt.Errorf("failed to detach trashed devices: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE networks SET datacenter_id = NULL WHERE datacenter_id = ? AND deleted_at IS NOT NULL`, id); err != nil {
+		return fmt.Errorf("failed to detach trashed networks: %w", err)
 	}
 
 	// Delete the datacenter
-	_, err = s.db.ExecContext(ctx, `DELETE FROM datacenters WHERE id = ?`, id)
+	_, err = tx.ExecContext(ctx, `DELETE FROM datacenters WHERE id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete datacenter: %w", err)
 	}
 
-	s.auditLog(ctx, "delete", "datacenter", id, nil)
 	return nil
 }
 
+// datacenterDependents are the records that refer to a datacenter
+func (s *SQLiteStorage) datacenterDependents() []dependent {
+	return []dependent{
+		{
+			name:  "devices",
+			count: `SELECT COUNT(*) FROM devices WHERE datacenter_id = ?1 AND deleted_at IS NULL`,
+			remove: func(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error {
+				if policy == model.DeleteDetach {
+					_, err := tx.ExecContext(ctx, `UPDATE devices SET datacenter_id = NULL WHERE datacenter_id = ?`, id)
+					return err
+				}
+				deviceIDs, err := idsInTx(ctx, tx, `SELECT id FROM devices WHERE datacenter_id = ? AND deleted_at IS NULL`, id)
+				if err != nil {
+					return err
+				}
+				for _, deviceID := range deviceIDs {
+					if err := s.deleteDeviceInTx(ctx, tx, deviceID, policy); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:  "networks",
+			count: `SELECT COUNT(*) FROM networks WHERE datacenter_id = ?1 AND deleted_at IS NULL`,
+			remove: func(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error {
+				if policy == model.DeleteDetach {
+					// The networks join those without a datacenter, so the
+					// hierarchy there is rebuilt
+					if _, err := tx.ExecContext(ctx, `UPDATE networks SET datacenter_id = NULL WHERE datacenter_id = ?`, id); err != nil {
+						return err
+					}
+					return relinkNetworks(ctx, tx, "")
+				}
+				networkIDs, err := idsInTx(ctx, tx, `SELECT id FROM networks WHERE datacenter_id = ? AND deleted_at IS NULL`, id)
+				if err != nil {
+					return err
+				}
+				for _, networkID := range networkIDs {
+					if err := s.deleteNetworkInTx(ctx, tx, networkID, policy); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
 // GetDatacenterDevices retrieves all devices in a datacenter
 func (s *SQLiteStorage) GetDatacenterDevices(ctx context.Context, datacenterID string) ([]model.Device, error) {
 	if datacenterID == "" {
@@ -251,7 +341,7 @@ func (s *SQLiteStorage) GetDatacenterDevices(ctx context.Context, datacenterID s
 
 	// First check if the datacenter exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM datacenters WHERE id = ?)`, datacenterID).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM datacenters WHERE id = ? AND deleted_at IS NULL)`, datacenterID).Scan(&exists)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check datacenter existence: %w", err)
 	}
@@ -262,3 +352,12 @@ func (s *SQLiteStorage) GetDatacenterDevices(ctx context.Context, datacenterID s
 	// Use ListDevices with a filter
 	return s.ListDevices(ctx, &model.DeviceFilter{DatacenterID: datacenterID})
 }
+
+func scanDatacenter(row interface{ Scan(...any) error }) (*model.Datacenter, error) {
+	var dc model.Datacenter
+	if err := row.Scan(&dc.ID, &dc.Name, &dc.Location, &dc.Description, &dc.ShippingAddress, &dc.AccessInstructions,
+		&dc.CreatedAt, &dc.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &dc, nil
+}
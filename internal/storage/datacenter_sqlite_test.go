@@ -138,7 +138,7 @@ func TestDatacenterOperations_Delete(t *testing.T) {
 	}
 
 	// Delete datacenter
-	if err := storage.DeleteDatacenter(context.Background(), dc.ID); err != nil {
+	if err := storage.DeleteDatacenter(context.Background(), dc.ID, model.DeleteDetach); err != nil {
 		t.Fatalf("DeleteDatacenter failed: %v", err)
 	}
 
@@ -166,7 +166,7 @@ func TestDatacenterOperations_DeleteWithDevices(t *testing.T) {
 	}
 
 	// Delete datacenter (should unlink devices)
-	if err := storage.DeleteDatacenter(context.Background(), dc.ID); err != nil {
+	if err := storage.DeleteDatacenter(context.Background(), dc.ID, model.DeleteDetach); err != nil {
 		t.Fatalf("DeleteDatacenter failed: %v", err)
 	}
 
@@ -184,7 +184,7 @@ func TestDatacenterOperations_DeleteNotFound(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
 
-	err := storage.DeleteDatacenter(context.Background(), "non-existent-id")
+	err := storage.DeleteDatacenter(context.Background(), "non-existent-id", model.DeleteDetach)
 	if err != ErrDatacenterNotFound {
 		t.Errorf("expected ErrDatacenterNotFound, got %v", err)
 	}
@@ -194,7 +194,7 @@ func TestDatacenterOperations_DeleteInvalidID(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
 
-	err := storage.DeleteDatacenter(context.Background(), "")
+	err := storage.DeleteDatacenter(context.Background(), "", model.DeleteDetach)
 	if err != ErrInvalidID {
 		t.Errorf("expected ErrInvalidID, got %v", err)
 	}
@@ -207,7 +207,7 @@ func TestDatacenterOperations_ListAll(t *testing.T) {
 	// Remove default datacenter to start clean
 	defaultDCs, _ := storage.ListDatacenters(context.Background(), &model.DatacenterFilter{Name: "Default"})
 	for _, dc := range defaultDCs {
-		storage.DeleteDatacenter(context.Background(), dc.ID)
+		storage.DeleteDatacenter(context.Background(), dc.ID, model.DeleteDetach)
 	}
 
 	// Create multiple datacenters
@@ -288,7 +288,7 @@ func TestDatacenterOperations_ListEmpty(t *testing.T) {
 	// Remove default datacenter to start clean
 	defaultDCs, _ := storage.ListDatacenters(context.Background(), &model.DatacenterFilter{Name: "Default"})
 	for _, dc := range defaultDCs {
-		storage.DeleteDatacenter(context.Background(), dc.ID)
+		storage.DeleteDatacenter(context.Background(), dc.ID, model.DeleteDetach)
 	}
 
 	result, err := storage.ListDatacenters(context.Background(), nil)
@@ -426,3 +426,59 @@ func TestListDatacentersWithNameFilter(t *testing.T) {
 		t.Errorf("expected 2 datacenters matching NYC, got %d", len(result))
 	}
 }
+
+func TestDatacenterContacts_CRUD(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "DC1", ShippingAddress: "1 Dock Road", AccessInstructions: "Badge at reception"}
+	if err := storage.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("CreateDatacenter failed: %v", err)
+	}
+	second := &model.DatacenterContact{DatacenterID: dc.ID, Role: "Facility manager", Name: "Bea", EscalationOrder: 2}
+	first := &model.DatacenterContact{DatacenterID: dc.ID, Role: "Remote hands", Name: "Ana", Phone: "+1 555 0100", EscalationOrder: 1}
+	for _, c := range []*model.DatacenterContact{second, first} {
+		if err := storage.CreateDatacenterContact(ctx, c); err != nil {
+			t.Fatalf("CreateDatacenterContact failed: %v", err)
+		}
+	}
+
+	got, err := storage.GetDatacenter(ctx, dc.ID)
+	if err != nil {
+		t.Fatalf("GetDatacenter failed: %v", err)
+	}
+	if got.ShippingAddress != "1 Dock Road" || got.AccessInstructions != "Badge at reception" {
+		t.Errorf("site metadata not stored: %+v", got)
+	}
+	if len(got.Contacts) != 2 || got.Contacts[0].Name != "Ana" || got.Contacts[1].Name != "Bea" {
+		t.Fatalf("expected contacts in escalation order, got %+v", got.Contacts)
+	}
+
+	first.EscalationOrder = 3
+	first.Email = "ana@example.com"
+	if err := storage.UpdateDatacenterContact(ctx, first); err != nil {
+		t.Fatalf("UpdateDatacenterContact failed: %v", err)
+	}
+	contacts, err := storage.ListDatacenterContacts(ctx, dc.ID)
+	if err != nil {
+		t.Fatalf("ListDatacenterContacts failed: %v", err)
+	}
+	if contacts[1].Name != "Ana" || contacts[1].Email != "ana@example.com" {
+		t.Errorf("expected updated contact last, got %+v", contacts)
+	}
+
+	if err := storage.DeleteDatacenterContact(ctx, second.ID); err != nil {
+		t.Fatalf("DeleteDatacenterContact failed: %v", err)
+	}
+	if err := storage.DeleteDatacenterContact(ctx, second.ID); err != ErrContactNotFound {
+		t.Errorf("expected ErrContactNotFound, got %v", err)
+	}
+
+	if err := storage.DeleteDatacenter(ctx, dc.ID, model.DeleteDetach); err != nil {
+		t.Fatalf("DeleteDatacenter failed: %v", err)
+	}
+	if _, err := storage.GetDatacenterContact(ctx, first.ID); err != ErrContactNotFound {
+		t.Errorf("expected contacts to be deleted with the datacenter, got %v", err)
+	}
+}
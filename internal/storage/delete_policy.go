@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// DependentsError is returned by a delete with the deny policy when records
+// still depend on the resource. Dependents maps each kind of record to how
+// many there are.
+type DependentsError struct {
+	Resource   string
+	Dependents map[string]int
+}
+
+func (e *DependentsError) Error() string {
+	kinds := make([]string, 0, len(e.Dependents))
+	for kind := range e.Dependents {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	parts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		parts[i] = fmt.Sprintf("%d %s", e.Dependents[kind], kind)
+	}
+	return fmt.Sprintf("%s has dependents: %s", e.Resource, strings.Join(parts, ", "))
+}
+
+// Unwrap returns ErrHasDependents so errors.Is(err, ErrHasDependents) works.
+func (e *DependentsError) Unwrap() error {
+	return ErrHasDependents
+}
+
+// dependent describes one kind of record that refers to a resource being
+// deleted. Queries take the resource ID as ?1.
+type dependent struct {
+	name   string
+	count  string
+	remove func(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error
+}
+
+// unlinkOrDelete returns a remove function running detach under the detach
+// policy and del under cascade. An empty detach means the dependents cannot
+// outlive the resource and are deleted either way.
+func unlinkOrDelete(detach, del string) func(context.Context, *sql.Tx, string, model.DeletePolicy) error {
+	return func(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error {
+		query := del
+		if policy == model.DeleteDetach && detach != "" {
+			query = detach
+		}
+		_, err := tx.ExecContext(ctx, query, id)
+		return err
+	}
+}
+
+// applyDeletePolicy deals with the dependents of a resource before it is
+// deleted: it refuses with a DependentsError under deny, and otherwise
+// unlinks or deletes each kind in order. An empty policy means detach.
+func applyDeletePolicy(ctx context.Context, tx *sql.Tx, resource, id string, policy model.DeletePolicy, deps []dependent) error {
+	if policy == "" {
+		policy = model.DeleteDetach
+	}
+
+	if policy == model.DeleteDeny {
+		counts := map[string]int{}
+		for _, d := range deps {
+			var n int
+			if err := tx.QueryRowContext(ctx, d.count, id).Scan(&n); err != nil {
+				return fmt.Errorf("failed to count %s: %w", d.name, err)
+			}
+			if n > 0 {
+				counts[d.name] = n
+			}
+		}
+		if len(counts) > 0 {
+			return &DependentsError{Resource: resource, Dependents: counts}
+		}
+		return nil
+	}
+
+	for _, d := range deps {
+		if err := d.remove(ctx, tx, id, policy); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", d.name, err)
+		}
+	}
+	return nil
+}
+
+// idsInTx returns the first column of every row of query
+func idsInTx(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
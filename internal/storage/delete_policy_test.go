@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// deviceWithDependents creates a device related to another device and
+// promoted from a discovery record, returning the device and the record
+func deviceWithDependents(t *testing.T, store *SQLiteStorage) (*model.Device, *model.DiscoveredDevice) {
+	t.Helper()
+	ctx := context.Background()
+
+	device := &model.Device{Name: "web-01"}
+	rack := &model.Device{Name: "rack-01"}
+	for _, d := range []*model.Device{device, rack} {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+	}
+	if err := store.AddRelationship(ctx, rack.ID, device.ID, model.RelationshipContains, ""); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+	network := &model.Network{Name: "lan", Subnet: "10.0.0.0/24"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	discovered := &model.DiscoveredDevice{IP: "10.0.0.5", NetworkID: network.ID}
+	if err := store.CreateDiscoveredDevice(ctx, discovered); err != nil {
+		t.Fatalf("CreateDiscoveredDevice failed: %v", err)
+	}
+	if err := store.PromoteDiscoveredDevice(ctx, discovered.ID, device.ID); err != nil {
+		t.Fatalf("PromoteDiscoveredDevice failed: %v", err)
+	}
+	return device, discovered
+}
+
+func TestDeleteDevicePolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deny", func(t *testing.T) {
+		store := newTestStorage(t)
+		defer store.Close()
+		device, _ := deviceWithDependents(t, store)
+
+		err := store.DeleteDevice(ctx, device.ID, model.DeleteDeny)
+		var depErr *DependentsError
+		if !errors.As(err, &depErr) || !errors.Is(err, ErrHasDependents) {
+			t.Fatalf("expected DependentsError, got %v", err)
+		}
+		if depErr.Dependents["relationships"] != 1 || depErr.Dependents["discovered_devices"] != 1 {
+			t.Errorf("unexpected dependents: %v", depErr.Dependents)
+		}
+		if _, err := store.GetDevice(ctx, device.ID); err != nil {
+			t.Errorf("expected the device to be kept, got %v", err)
+		}
+	})
+
+	t.Run("detach", func(t *testing.T) {
+		store := newTestStorage(t)
+		defer store.Close()
+		device, discovered := deviceWithDependents(t, store)
+
+		if err := store.DeleteDevice(ctx, device.ID, model.DeleteDetach); err != nil {
+			t.Fatalf("DeleteDevice failed: %v", err)
+		}
+		got, err := store.GetDiscoveredDevice(ctx, discovered.ID)
+		if err != nil {
+			t.Fatalf("expected the discovery record to be kept, got %v", err)
+		}
+		if got.PromotedToDeviceID != "" || got.PromotedAt != nil {
+			t.Errorf("expected the promotion link to be cleared, got %+v", got)
+		}
+		rels, _ := store.ListAllRelationships(ctx)
+		if len(rels) != 0 {
+			t.Errorf("expected relationships to be removed, got %+v", rels)
+		}
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		store := newTestStorage(t)
+		defer store.Close()
+		device, discovered := deviceWithDependents(t, store)
+
+		if err := store.DeleteDevice(ctx, device.ID, model.DeleteCascade); err != nil {
+			t.Fatalf("DeleteDevice failed: %v", err)
+		}
+		if _, err := store.GetDiscoveredDevice(ctx, discovered.ID); !errors.Is(err, ErrDiscoveryNotFound) {
+			t.Errorf("expected the discovery record to be deleted, got %v", err)
+		}
+	})
+}
+
+func TestDeleteDatacenterPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, store *SQLiteStorage) (*model.Datacenter, *model.Device, *model.Network) {
+		t.Helper()
+		dc := &model.Datacenter{Name: "DC1"}
+		if err := store.CreateDatacenter(ctx, dc); err != nil {
+			t.Fatalf("CreateDatacenter failed: %v", err)
+		}
+		device := &model.Device{Name: "web-01", DatacenterID: dc.ID}
+		if err := store.CreateDevice(ctx, device); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+		network := &model.Network{Name: "lan", Subnet: "10.0.0.0/24", DatacenterID: dc.ID}
+		if err := store.CreateNetwork(ctx, network); err != nil {
+			t.Fatalf("CreateNetwork failed: %v", err)
+		}
+		return dc, device, network
+	}
+
+	t.Run("deny", func(t *testing.T) {
+		store := newTestStorage(t)
+		defer store.Close()
+		dc, _, _ := setup(t, store)
+
+		err := store.DeleteDatacenter(ctx, dc.ID, model.DeleteDeny)
+		var depErr *DependentsError
+		if !errors.As(err, &depErr) {
+			t.Fatalf("expected DependentsError, got %v", err)
+		}
+		if depErr.Dependents["devices"] != 1 || depErr.Dependents["networks"] != 1 {
+			t.Errorf("unexpected dependents: %v", depErr.Dependents)
+		}
+		if _, err := store.GetDatacenter(ctx, dc.ID); err != nil {
+			t.Errorf("expected the datacenter to be kept, got %v", err)
+		}
+	})
+
+	t.Run("detach", func(t *testing.T) {
+		store := newTestStorage(t)
+		defer store.Close()
+		dc, device, network := setup(t, store)
+
+		if err := store.DeleteDatacenter(ctx, dc.ID, model.DeleteDetach); err != nil {
+			t.Fatalf("DeleteDatacenter failed: %v", err)
+		}
+		gotDevice, err := store.GetDevice(ctx, device.ID)
+		if err != nil || gotDevice.DatacenterID != "" {
+			t.Errorf("expected the device to be kept without a datacenter, got %+v, %v", gotDevice, err)
+		}
+		gotNetwork, err := store.GetNetwork(ctx, network.ID)
+		if err != nil || gotNetwork.DatacenterID != "" {
+			t.Errorf("expected the network to be kept without a datacenter, got %+v, %v", gotNetwork, err)
+		}
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		store := newTestStorage(t)
+		defer store.Close()
+		dc, device, network := setup(t, store)
+
+		if err := store.DeleteDatacenter(ctx, dc.ID, model.DeleteCascade); err != nil {
+			t.Fatalf("DeleteDatacenter failed: %v", err)
+		}
+		if _, err := store.GetDevice(ctx, device.ID); !errors.Is(err, ErrDeviceNotFound) {
+			t.Errorf("expected the device to be deleted, got %v", err)
+		}
+		if _, err := store.GetNetwork(ctx, network.ID); !errors.Is(err, ErrNetworkNotFound) {
+			t.Errorf("expected the network to be deleted, got %v", err)
+		}
+	})
+}
+
+func TestDeleteNetworkPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, store *SQLiteStorage) (*model.Network, *model.Device, *model.DiscoveredDevice) {
+		t.Helper()
+		network := &model.Network{Name: "lan", Subnet: "10.0.0.0/24"}
+		if err := store.CreateNetwork(ctx, network); err != nil {
+			t.Fatalf("CreateNetwork failed: %v", err)
+		}
+		pool := &model.NetworkPool{NetworkID: network.ID, Name: "servers", StartIP: "10.0.0.10", EndIP: "10.0.0.20"}
+		if err := store.CreateNetworkPool(ctx, pool); err != nil {
+			t.Fatalf("CreateNetworkPool failed: %v", err)
+		}
+		device := &model.Device{Name: "web-01", Addresses: []model.Address{
+			{IP: "10.0.0.10", Type: "ipv4", NetworkID: network.ID, PoolID: pool.ID},
+		}}
+		if err := store.CreateDevice(ctx, device); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+		discovered := &model.DiscoveredDevice{IP: "10.0.0.50", NetworkID: network.ID}
+		if err := store.CreateDiscoveredDevice(ctx, discovered); err != nil {
+			t.Fatalf("CreateDiscoveredDevice failed: %v", err)
+		}
+		return network, device, discovered
+	}
+
+	t.Run("deny", func(t *testing.T) {
+		store := newTestStorage(t)
+		defer store.Close()
+		network, _, _ := setup(t, store)
+
+		err := store.DeleteNetwork(ctx, network.ID, model.DeleteDeny)
+		var depErr *DependentsError
+		if !errors.As(err, &depErr) {
+			t.Fatalf("expected DependentsError, got %v", err)
+		}
+		want := map[string]int{"pools": 1, "addresses": 1, "discovered_devices": 1}
+		for kind, n := range want {
+			if depErr.Dependents[kind] != n {
+				t.Errorf("expected %d %s, got %v", n, kind, depErr.Dependents)
+			}
+		}
+	})
+
+	t.Run("detach", func(t *testing.T) {
+		store := newTestStorage(t)
+		defer store.Close()
+		network, device, discovered := setup(t, store)
+
+		if err := store.DeleteNetwork(ctx, network.ID, model.DeleteDetach); err != nil {
+			t.Fatalf("DeleteNetwork failed: %v", err)
+		}
+		got, err := store.GetDevice(ctx, device.ID)
+		if err != nil {
+			t.Fatalf("GetDevice failed: %v", err)
+		}
+		if len(got.Addresses) != 1 || got.Addresses[0].NetworkID != "" || got.Addresses[0].PoolID != "" {
+			t.Errorf("expected the address to be kept and unlinked, got %+v", got.Addresses)
+		}
+		if _, err := store.GetDiscoveredDevice(ctx, discovered.ID); !errors.Is(err, ErrDiscoveryNotFound) {
+			t.Errorf("expected discovery data to go with the network, got %v", err)
+		}
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		store := newTestStorage(t)
+		defer store.Close()
+		network, device, discovered := setup(t, store)
+
+		if err := store.DeleteNetwork(ctx, network.ID, model.DeleteCascade); err != nil {
+			t.Fatalf("DeleteNetwork failed: %v", err)
+		}
+		got, err := store.GetDevice(ctx, device.ID)
+		if err != nil {
+			t.Fatalf("GetDevice failed: %v", err)
+		}
+		if len(got.Addresses) != 0 {
+			t.Errorf("expected the address to be deleted, got %+v", got.Addresses)
+		}
+		if _, err := store.GetDiscoveredDevice(ctx, discovered.ID); !errors.Is(err, ErrDiscoveryNotFound) {
+			t.Errorf("expected the discovery record to be deleted, got %v", err)
+		}
+	})
+}
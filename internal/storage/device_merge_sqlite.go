@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// MergeDevices folds the source device into the target in a single
+// transaction. The target gains the source's interfaces, addresses, tags,
+// domains and custom field values it does not already have, and empty target fields are
+// filled from the source. Relationships, discovery links and other device
+// references are re-pointed to the target. The source is kept as a
+// decommissioned record noting the merge; mergedBy is recorded as the user
+// who changed its status.
+func (s *SQLiteStorage) MergeDevices(ctx context.Context, targetID, sourceID, mergedBy string) (*model.Device, error) {
+	if targetID == "" || sourceID == "" {
+		return nil, ErrInvalidID
+	}
+
+	target, err := s.GetDevice(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.GetDevice(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := nowUTC()
+	fillEmpty(&target.Hostname, source.Hostname)
+	fillEmpty(&target.Description, source.Description)
+	fillEmpty(&target.MakeModel, source.MakeModel)
+	fillEmpty(&target.OS, source.OS)
+	fillEmpty(&target.DatacenterID, source.DatacenterID)
+	fillEmpty(&target.Username, source.Username)
+	fillEmpty(&target.Location, source.Location)
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE devices SET hostname = ?, description = ?, make_model = ?, os = ?, datacenter_id = ?,
+			username = ?, location = ?, updated_at = ?
+		WHERE id = ?
+	`, target.Hostname, target.Description, target.MakeModel, target.OS, nullString(target.DatacenterID),
+		target.Username, target.Location, now, targetID); err != nil {
+		return nil, fmt.Errorf("failed to update target device: %w", err)
+	}
+
+	statements := []struct {
+		what  string
+		query string
+	}{
+		// Interfaces the target has by name are not moved; what was on the
+		// source's copy moves onto the target's
+		{"move interfaces", `UPDATE device_interfaces SET device_id = ?1 WHERE device_id = ?2
+			AND name NOT IN (SELECT name FROM device_interfaces WHERE device_id = ?1)`},
+		{"re-point interface parents", `UPDATE device_interfaces SET parent_id = (
+				SELECT t.id FROM device_interfaces t JOIN device_interfaces src ON src.name = t.name
+				WHERE src.id = device_interfaces.parent_id AND t.device_id = ?1)
+			WHERE device_id = ?1 AND parent_id IN (SELECT id FROM device_interfaces WHERE device_id = ?2)`},
+		// Addresses move rather than copy so an IP never belongs to two devices
+		{"move addresses", `UPDATE addresses SET device_id = ?1 WHERE device_id = ?2
+			AND ip NOT IN (SELECT ip FROM addresses WHERE device_id = ?1)`},
+		{"re-point address interfaces", `UPDATE addresses SET interface_id = (
+				SELECT t.id FROM device_interfaces t JOIN device_interfaces src ON src.name = t.name
+				WHERE src.id = addresses.interface_id AND t.device_id = ?1)
+			WHERE device_id = ?1 AND interface_id IN (SELECT id FROM device_interfaces WHERE device_id = ?2)`},
+		{"drop duplicate addresses", `DELETE FROM addresses WHERE device_id = ?2`},
+		{"drop duplicate interfaces", `DELETE FROM device_interfaces WHERE device_id = ?2`},
+		{"copy tags", `INSERT OR IGNORE INTO tags (device_id, tag) SELECT ?1, tag FROM tags WHERE device_id = ?2`},
+		{"copy domains", `INSERT OR IGNORE INTO domains (device_id, domain) SELECT ?1, domain FROM domains WHERE device_id = ?2`},
+		{"move custom field values", `UPDATE custom_field_values SET device_id = ?1 WHERE device_id = ?2
+			AND field_id NOT IN (SELECT field_id FROM custom_field_values WHERE device_id = ?1)`},
+		// Relationships between the two devices would become self-links
+		{"drop relationships between merged devices", `DELETE FROM device_relationships
+			WHERE (parent_id = ?2 AND child_id = ?1) OR (parent_id = ?1 AND child_id = ?2)`},
+		{"re-point parent relationships", `UPDATE OR IGNORE device_relationships SET parent_id = ?1 WHERE parent_id = ?2`},
+		{"re-point child relationships", `UPDATE OR IGNORE device_relationships SET child_id = ?1 WHERE child_id = ?2`},
+		{"drop duplicate relationships", `DELETE FROM device_relationships WHERE parent_id = ?2 OR child_id = ?2`},
+		{"re-point discovery links", `UPDATE discovered_devices SET promoted_to_device_id = ?1 WHERE promoted_to_device_id = ?2`},
+		{"re-point circuits", `UPDATE circuits SET device_a_id = ?1 WHERE device_a_id = ?2`},
+		{"re-point circuits", `UPDATE circuits SET device_b_id = ?1 WHERE device_b_id = ?2`},
+		{"re-point NAT mappings", `UPDATE nat_mappings SET device_id = ?1 WHERE device_id = ?2`},
+		{"re-point DNS records", `UPDATE dns_records SET device_id = ?1 WHERE device_id = ?2`},
+		{"re-point monitor checks", `UPDATE monitor_checks SET device_id = ?1 WHERE device_id = ?2`},
+		{"drop monitor results", `DELETE FROM monitor_results WHERE device_id = ?2`},
+		{"re-point maintenance windows", `UPDATE maintenance_windows SET device_id = ?1 WHERE device_id = ?2`},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt.query, targetID, sourceID); err != nil {
+			return nil, fmt.Errorf("failed to %s: %w", stmt.what, err)
+		}
+	}
+
+	description := fmt.Sprintf("Merged into %s (%s) on %s", target.Name, targetID, now.Format(time.DateOnly))
+	if source.Description != "" {
+		description = source.Description + "\n\n" + description
+	}
+	var decommissionDate any = now
+	if source.DecommissionDate != nil {
+		decommissionDate = *source.DecommissionDate
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE devices SET status = ?, description = ?, decommission_date = ?,
+			status_changed_at = ?, status_changed_by = ?, updated_at = ?
+		WHERE id = ?
+	`, model.DeviceStatusDecommissioned, description, decommissionDate,
+		now, nullString(mergedBy), now, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to archive source device: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.auditLog(ctx, "merge", "device", targetID, map[string]string{"source_id": sourceID, "target_id": targetID})
+	s.auditLog(ctx, "update", "device", sourceID, map[string]string{"merged_into": targetID})
+	return s.GetDevice(ctx, targetID)
+}
+
+func fillEmpty(dst *string, value string) {
+	if *dst == "" {
+		*dst = value
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestMergeDevices(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "lan", Subnet: "10.0.0.0/24"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+
+	target := &model.Device{
+		Name:       "web-01",
+		Tags:       []string{"web"},
+		Domains:    []string{"web-01.example.com"},
+		Interfaces: []model.Interface{{Name: "eth0"}},
+		Addresses:  []model.Address{{IP: "10.0.0.10", Type: "ipv4", Interface: "eth0"}},
+	}
+	source := &model.Device{
+		Name:       "web-01-discovered",
+		OS:         "Debian 12",
+		Tags:       []string{"web", "discovered"},
+		Domains:    []string{"web-01.internal"},
+		Interfaces: []model.Interface{{Name: "eth0"}, {Name: "eth1", Parent: "eth0"}},
+		Addresses:  []model.Address{{IP: "10.0.0.10", Type: "ipv4"}, {IP: "10.0.0.11", Type: "ipv4", Interface: "eth0"}},
+	}
+	rack := &model.Device{Name: "rack-a"}
+	for _, d := range []*model.Device{target, source, rack} {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+	}
+	if err := store.AddRelationship(ctx, rack.ID, source.ID, model.RelationshipContains, ""); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+	if err := store.AddRelationship(ctx, target.ID, source.ID, model.RelationshipConnectedTo, ""); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+
+	discovered := &model.DiscoveredDevice{IP: "10.0.0.11", NetworkID: network.ID}
+	if err := store.CreateDiscoveredDevice(ctx, discovered); err != nil {
+		t.Fatalf("CreateDiscoveredDevice failed: %v", err)
+	}
+	if err := store.PromoteDiscoveredDevice(ctx, discovered.ID, source.ID); err != nil {
+		t.Fatalf("PromoteDiscoveredDevice failed: %v", err)
+	}
+
+	merged, err := store.MergeDevices(ctx, target.ID, source.ID, "")
+	if err != nil {
+		t.Fatalf("MergeDevices failed: %v", err)
+	}
+
+	var ips []string
+	for _, a := range merged.Addresses {
+		ips = append(ips, a.IP)
+	}
+	slices.Sort(ips)
+	if !slices.Equal(ips, []string{"10.0.0.10", "10.0.0.11"}) {
+		t.Errorf("addresses = %v, want 10.0.0.10 and 10.0.0.11", ips)
+	}
+	if len(merged.Interfaces) != 2 || merged.Interfaces[1].Name != "eth1" || merged.Interfaces[1].Parent != "eth0" {
+		t.Errorf("interfaces = %+v, want eth0 and eth1 on eth0", merged.Interfaces)
+	}
+	for _, a := range merged.Addresses {
+		if a.Interface != "eth0" {
+			t.Errorf("address %s on %q, want the target's eth0", a.IP, a.Interface)
+		}
+	}
+	slices.Sort(merged.Tags)
+	if !slices.Equal(merged.Tags, []string{"discovered", "web"}) {
+		t.Errorf("tags = %v", merged.Tags)
+	}
+	if len(merged.Domains) != 2 {
+		t.Errorf("domains = %v, want both domains", merged.Domains)
+	}
+	if merged.OS != "Debian 12" {
+		t.Errorf("os = %q, want empty field filled from source", merged.OS)
+	}
+
+	rels, err := store.GetRelationships(ctx, target.ID)
+	if err != nil {
+		t.Fatalf("GetRelationships failed: %v", err)
+	}
+	if len(rels) != 1 || rels[0].ParentID != rack.ID || rels[0].ChildID != target.ID {
+		t.Errorf("expected rack relationship moved to target, got %+v", rels)
+	}
+
+	got, err := store.GetDiscoveredDevice(ctx, discovered.ID)
+	if err != nil {
+		t.Fatalf("GetDiscoveredDevice failed: %v", err)
+	}
+	if got.PromotedToDeviceID != target.ID {
+		t.Errorf("promoted_to_device_id = %q, want %q", got.PromotedToDeviceID, target.ID)
+	}
+
+	archived, err := store.GetDevice(ctx, source.ID)
+	if err != nil {
+		t.Fatalf("GetDevice(source) failed: %v", err)
+	}
+	if archived.Status != model.DeviceStatusDecommissioned || archived.DecommissionDate == nil {
+		t.Errorf("expected source decommissioned, got status %q", archived.Status)
+	}
+	if !strings.Contains(archived.Description, "Merged into web-01") {
+		t.Errorf("description = %q, want merge note", archived.Description)
+	}
+	if len(archived.Addresses) != 0 || len(archived.Interfaces) != 0 {
+		t.Errorf("source still has addresses: %+v", archived.Addresses)
+	}
+	if rels, _ := store.GetRelationships(ctx, source.ID); len(rels) != 0 {
+		t.Errorf("source still has relationships: %+v", rels)
+	}
+
+	if _, err := store.MergeDevices(ctx, target.ID, "missing", ""); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound, got %v", err)
+	}
+}
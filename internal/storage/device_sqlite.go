@@ -11,7 +11,8 @@ import (
 
 // Device operations
 
-// GetDevice retrieves a device by ID with its addresses, tags, and domains
+// GetDevice retrieves a device by ID with its interfaces, addresses, tags,
+// and domains
 func (s *SQLiteStorage) GetDevice(ctx context.Context, id string) (*model.Device, error) {
 	if id == "" {
 		return nil, ErrInvalidID
@@ -21,14 +22,17 @@ func (s *SQLiteStorage) GetDevice(ctx context.Context, id string) (*model.Device
 	device := &model.Device{}
 	var datacenterID, statusChangedBy sql.NullString
 	var decommissionDate, statusChangedAt sql.NullTime
-	err := s.db.QueryRowContext(ctx, `
+	var purchaseDate, warrantyExpiry, endOfLife sql.NullTime
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, hostname, description, make_model, os, datacenter_id, username, location,
-		       status, decommission_date, status_changed_at, status_changed_by, created_at, updated_at
-		FROM devices WHERE id = ?
+		       kind, status, decommission_date, status_changed_at, status_changed_by,
+		       purchase_date, warranty_expiry, end_of_life, created_at, updated_at
+		FROM devices WHERE id = ? AND deleted_at IS NULL
 	`, id).Scan(
 		&device.ID, &device.Name, &device.Hostname, &device.Description, &device.MakeModel,
 		&device.OS, &datacenterID, &device.Username, &device.Location,
-		&device.Status, &decommissionDate, &statusChangedAt, &statusChangedBy,
+		&device.Kind, &device.Status, &decommissionDate, &statusChangedAt, &statusChangedBy,
+		&purchaseDate, &warrantyExpiry, &endOfLife,
 		&device.CreatedAt, &device.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -49,6 +53,14 @@ func (s *SQLiteStorage) GetDevice(ctx context.Context, id string) (*model.Device
 	if statusChangedBy.Valid {
 		device.StatusChangedBy = statusChangedBy.String
 	}
+	setLifecycleDates(device, purchaseDate, warrantyExpiry, endOfLife)
+
+	// Get interfaces
+	interfaces, err := s.getDeviceInterfaces(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device interfaces: %w", err)
+	}
+	device.Interfaces = interfaces
 
 	// Get addresses
 	addresses, err := s.getDeviceAddresses(ctx, id)
@@ -87,11 +99,43 @@ func (s *SQLiteStorage) GetDevice(ctx context.Context, id string) (*model.Device
 	return device, nil
 }
 
+// getDeviceInterfaces retrieves all interfaces for a device, naming the
+// parent of each rather than giving its ID
+func (s *SQLiteStorage) getDeviceInterfaces(ctx context.Context, deviceID string) ([]model.Interface, error) {
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT i.id, i.name, i.type, i.mac_address, i.speed_mbps, i.switch_port, i.vlan, COALESCE(p.name, '')
+		FROM device_interfaces i
+		LEFT JOIN device_interfaces p ON p.id = i.parent_id
+		WHERE i.device_id = ?
+		ORDER BY i.name
+	`, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	interfaces := []model.Interface{}
+	for rows.Next() {
+		var iface model.Interface
+		var mac, switchPort sql.NullString
+		if err := rows.Scan(&iface.ID, &iface.Name, &iface.Type, &mac, &iface.SpeedMbps, &switchPort, &iface.VLAN, &iface.Parent); err != nil {
+			return nil, err
+		}
+		iface.MACAddress = mac.String
+		iface.SwitchPort = switchPort.String
+		interfaces = append(interfaces, iface)
+	}
+	return interfaces, rows.Err()
+}
+
 // getDeviceAddresses retrieves all addresses for a device
 func (s *SQLiteStorage) getDeviceAddresses(ctx context.Context, deviceID string) ([]model.Address, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, ip, port, type, label, network_id, switch_port, pool_id
-		FROM addresses WHERE device_id = ?
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT a.id, a.ip, a.port, a.type, a.label, a.network_id, a.switch_port, a.pool_id, a.mac_address,
+		       COALESCE(i.name, '')
+		FROM addresses a
+		LEFT JOIN device_interfaces i ON i.id = a.interface_id
+		WHERE a.device_id = ?
 	`, deviceID)
 	if err != nil {
 		return nil, err
@@ -101,9 +145,9 @@ func (s *SQLiteStorage) getDeviceAddresses(ctx context.Context, deviceID string)
 	var addresses []model.Address
 	for rows.Next() {
 		var addr model.Address
-		var networkID, switchPort, poolID sql.NullString
+		var networkID, switchPort, poolID, mac sql.NullString
 		var port sql.NullInt64
-		if err := rows.Scan(&addr.ID, &addr.IP, &port, &addr.Type, &addr.Label, &networkID, &switchPort, &poolID); err != nil {
+		if err := rows.Scan(&addr.ID, &addr.IP, &port, &addr.Type, &addr.Label, &networkID, &switchPort, &poolID, &mac, &addr.Interface); err != nil {
 			return nil, err
 		}
 		if port.Valid {
@@ -119,6 +163,9 @@ func (s *SQLiteStorage) getDeviceAddresses(ctx context.Context, deviceID string)
 		if poolID.Valid {
 			addr.PoolID = poolID.String
 		}
+		if mac.Valid {
+			addr.MACAddress = mac.String
+		}
 		addresses = append(addresses, addr)
 	}
 
@@ -131,7 +178,7 @@ func (s *SQLiteStorage) getDeviceAddresses(ctx context.Context, deviceID string)
 
 // getDeviceTags retrieves all tags for a device
 func (s *SQLiteStorage) getDeviceTags(ctx context.Context, deviceID string) ([]string, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT tag FROM tags WHERE device_id = ?`, deviceID)
+	rows, err := s.reader.QueryContext(ctx, `SELECT tag FROM tags WHERE device_id = ?`, deviceID)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +202,7 @@ func (s *SQLiteStorage) getDeviceTags(ctx context.Context, deviceID string) ([]s
 
 // getDeviceDomains retrieves all domains for a device
 func (s *SQLiteStorage) getDeviceDomains(ctx context.Context, deviceID string) ([]string, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT domain FROM domains WHERE device_id = ?`, deviceID)
+	rows, err := s.reader.QueryContext(ctx, `SELECT domain FROM domains WHERE device_id = ?`, deviceID)
 	if err != nil {
 		return nil, err
 	}
@@ -177,7 +224,8 @@ func (s *SQLiteStorage) getDeviceDomains(ctx context.Context, deviceID string) (
 	return domains, rows.Err()
 }
 
-// CreateDevice creates a new device with its addresses, tags, and domains
+// CreateDevice creates a new device with its interfaces, addresses, tags,
+// and domains
 func (s *SQLiteStorage) CreateDevice(ctx context.Context, device *model.Device) error {
 	if device == nil {
 		return fmt.Errorf("device is nil")
@@ -203,103 +251,232 @@ func (s *SQLiteStorage) CreateDevice(ctx context.Context, device *model.Device)
 
 // createDeviceInTx creates a device within an existing transaction
 func (s *SQLiteStorage) createDeviceInTx(ctx context.Context, tx *sql.Tx, device *model.Device) error {
+	return s.createDevicesInTx(ctx, tx, []*model.Device{device})
+}
 
-	// Generate ID if not provided
-	if device.ID == "" {
-		device.ID = newUUID()
+// createDevicesInTx creates devices within an existing transaction, writing
+// the devices and their interfaces, addresses, tags and domains with
+// multi-row INSERTs
+func (s *SQLiteStorage) createDevicesInTx(ctx context.Context, tx *sql.Tx, devices []*model.Device) error {
+	now := nowUTC()
+	rows := make([][]any, 0, len(devices))
+	for _, device := range devices {
+		// Generate ID if not provided
+		if device.ID == "" {
+			device.ID = newUUID()
+		}
+		device.CreatedAt = now
+		device.UpdatedAt = now
+
+		// Set default status and kind if not provided
+		if device.Status == "" {
+			device.Status = model.DeviceStatusActive
+		}
+		if device.Kind == "" {
+			device.Kind = model.DeviceKindPhysical
+		}
+
+		// Set status changed at for new devices
+		changedAt := now
+		device.StatusChangedAt = &changedAt
+
+		rows = append(rows, []any{device.ID, device.Name, device.Hostname, device.Description, device.MakeModel,
+			device.OS, nullString(device.DatacenterID), device.Username, device.Location,
+			device.Kind, device.Status, nullTime(device.DecommissionDate), nullTime(device.StatusChangedAt),
+			nullString(device.StatusChangedBy), nullTime(device.PurchaseDate), nullTime(device.WarrantyExpiry),
+			nullTime(device.EndOfLife), device.CreatedAt, device.UpdatedAt})
 	}
 
-	now := nowUTC()
-	device.CreatedAt = now
-	device.UpdatedAt = now
-
-	// Set default status if not provided
-	if device.Status == "" {
-		device.Status = model.DeviceStatusActive
-	}
-
-	// Set status changed at for new devices
-	device.StatusChangedAt = &now
-
-	// Insert device
-	_, err := tx.ExecContext(ctx, `
-		INSERT INTO devices (id, name, hostname, description, make_model, os, datacenter_id, username, location,
-		                     status, decommission_date, status_changed_at, status_changed_by, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, device.ID, device.Name, device.Hostname, device.Description, device.MakeModel,
-		device.OS, nullString(device.DatacenterID), device.Username, device.Location,
-		device.Status, nullTime(device.DecommissionDate), nullTime(device.StatusChangedAt),
-		nullString(device.StatusChangedBy), device.CreatedAt, device.UpdatedAt)
-	if err != nil {
+	// Insert devices
+	if err := insertRows(ctx, tx, `INSERT INTO devices (id, name, hostname, description, make_model, os, datacenter_id, username, location,
+		kind, status, decommission_date, status_changed_at, status_changed_by, purchase_date, warranty_expiry, end_of_life,
+		created_at, updated_at)`, rows); err != nil {
 		return fmt.Errorf("failed to insert device: %w", err)
 	}
 
+	if err := allocatePoolAddresses(ctx, tx, devices); err != nil {
+		return err
+	}
+
+	var interfaces, addresses, tags, domains [][]any
+	for _, device := range devices {
+		ifaceRows, ifaceIDs := interfaceRows(device.ID, device.Interfaces)
+		interfaces = append(interfaces, ifaceRows...)
+		addresses = append(addresses, addressRows(device.ID, device.Addresses, ifaceIDs)...)
+		tags = append(tags, valueRows(device.ID, device.Tags)...)
+		domains = append(domains, valueRows(device.ID, device.Domains)...)
+	}
+
+	// Insert interfaces, which addresses refer to
+	if err := insertRows(ctx, tx, interfaceInsert, interfaces); err != nil {
+		return fmt.Errorf("failed to insert interfaces: %w", err)
+	}
+
 	// Insert addresses
-	if err := s.insertDeviceAddresses(ctx, tx, device.ID, device.Addresses); err != nil {
+	if err := insertRows(ctx, tx, addressInsert, addresses); err != nil {
 		return fmt.Errorf("failed to insert addresses: %w", err)
 	}
 
 	// Insert tags
-	if err := s.insertDeviceTags(ctx, tx, device.ID, device.Tags); err != nil {
+	if err := insertRows(ctx, tx, tagInsert, tags); err != nil {
 		return fmt.Errorf("failed to insert tags: %w", err)
 	}
 
 	// Insert domains
-	if err := s.insertDeviceDomains(ctx, tx, device.ID, device.Domains); err != nil {
+	if err := insertRows(ctx, tx, domainInsert, domains); err != nil {
 		return fmt.Errorf("failed to insert domains: %w", err)
 	}
 
 	// Insert custom fields
-	if err := s.insertDeviceCustomFields(ctx, tx, device.ID, device.CustomFields); err != nil {
-		return fmt.Errorf("failed to insert custom fields: %w", err)
+	for _, device := range devices {
+		if err := s.insertDeviceCustomFields(ctx, tx, device.ID, device.CustomFields); err != nil {
+			return fmt.Errorf("failed to insert custom fields: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// insertDeviceAddresses inserts addresses for a device within a transaction
-func (s *SQLiteStorage) insertDeviceAddresses(ctx context.Context, tx *sql.Tx, deviceID string, addresses []model.Address) error {
+// allocatePoolAddresses gives each address that names a pool but no IP the
+// pool's next free IP. It runs in the transaction that writes the addresses,
+// so concurrent writers are never handed the same IP. An allocated address
+// joins the pool's network unless it names one, and is typed by its family
+// unless it has a type.
+func allocatePoolAddresses(ctx context.Context, tx *sql.Tx, devices []*model.Device) error {
+	pools := map[string]*model.NetworkPool{}
+	now := nowUTC()
+	for _, device := range devices {
+		for i := range device.Addresses {
+			addr := &device.Addresses[i]
+			if addr.IP != "" || addr.PoolID == "" {
+				continue
+			}
+
+			pool, ok := pools[addr.PoolID]
+			if !ok {
+				pool = &model.NetworkPool{ID: addr.PoolID}
+				err := tx.QueryRowContext(ctx, `SELECT network_id, start_ip, end_ip FROM network_pools WHERE id = ?`, addr.PoolID).Scan(&pool.NetworkID, &pool.StartIP, &pool.EndIP)
+				if err == sql.ErrNoRows {
+					return ErrPoolNotFound
+				}
+				if err != nil {
+					return fmt.Errorf("failed to get pool: %w", err)
+				}
+				if err := expirePoolReservations(ctx, tx, pool.ID, now); err != nil {
+					return err
+				}
+				pools[addr.PoolID] = pool
+			}
+
+			// IPs of the pool given to these devices are not written yet
+			var pending []string
+			for _, d := range devices {
+				for _, a := range d.Addresses {
+					if a.PoolID == pool.ID && a.IP != "" {
+						pending = append(pending, a.IP)
+					}
+				}
+			}
+			ip, err := nextFreeIP(ctx, tx, pool, now, pending)
+			if err != nil {
+				return err
+			}
+			addr.IP = ip
+			if addr.Type == "" {
+				addr.Type = model.AddressFamily(ip)
+			}
+			if addr.NetworkID == "" {
+				addr.NetworkID = pool.NetworkID
+			}
+		}
+	}
+	return nil
+}
+
+const (
+	interfaceInsert = `INSERT INTO device_interfaces (id, device_id, name, type, mac_address, speed_mbps, switch_port, vlan, parent_id)`
+	addressInsert   = `INSERT INTO addresses (id, device_id, ip, port, type, label, network_id, switch_port, pool_id, mac_address, interface_id)`
+	tagInsert       = `INSERT INTO tags (device_id, tag)`
+	domainInsert    = `INSERT INTO domains (device_id, domain)`
+)
+
+// interfaceRows returns the interface rows of a device and the interface IDs
+// by name. Interfaces without an ID are given one.
+func interfaceRows(deviceID string, interfaces []model.Interface) ([][]any, map[string]string) {
+	ids := make(map[string]string, len(interfaces))
+	for i := range interfaces {
+		if interfaces[i].ID == "" {
+			interfaces[i].ID = newUUID()
+		}
+		ids[interfaces[i].Name] = interfaces[i].ID
+	}
+
+	rows := make([][]any, 0, len(interfaces))
+	for _, iface := range interfaces {
+		if mac, ok := model.NormalizeMAC(iface.MACAddress); ok {
+			iface.MACAddress = mac
+		}
+		if iface.Type == "" {
+			iface.Type = model.InterfaceTypeEthernet
+		}
+		rows = append(rows, []any{iface.ID, deviceID, iface.Name, iface.Type, nullString(iface.MACAddress),
+			iface.SpeedMbps, nullString(iface.SwitchPort), iface.VLAN, nullString(ids[iface.Parent])})
+	}
+	return rows, ids
+}
+
+// addressRows returns the addresses rows of a device, linking each to the
+// interface it names
+func addressRows(deviceID string, addresses []model.Address, interfaceIDs map[string]string) [][]any {
+	rows := make([][]any, 0, len(addresses))
 	for _, addr := range addresses {
 		id := addr.ID
 		if id == "" {
 			id = newUUID()
 		}
-		_, err := tx.ExecContext(ctx, `
-			INSERT INTO addresses (id, device_id, ip, port, type, label, network_id, switch_port, pool_id)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`, id, deviceID, addr.IP, nullIntPtr(addr.Port), addr.Type, addr.Label,
-			nullString(addr.NetworkID), nullString(addr.SwitchPort), nullString(addr.PoolID))
-		if err != nil {
-			return err
+		if mac, ok := model.NormalizeMAC(addr.MACAddress); ok {
+			addr.MACAddress = mac
+		}
+		if addr.Type == "" {
+			addr.Type = model.AddressFamily(addr.IP)
 		}
+		rows = append(rows, []any{id, deviceID, addr.IP, nullIntPtr(addr.Port), addr.Type, addr.Label,
+			nullString(addr.NetworkID), nullString(addr.SwitchPort), nullString(addr.PoolID), nullString(addr.MACAddress),
+			nullString(interfaceIDs[addr.Interface])})
+	}
+	return rows
+}
+
+// valueRows returns (device_id, value) rows for tags and domains
+func valueRows(deviceID string, values []string) [][]any {
+	rows := make([][]any, 0, len(values))
+	for _, v := range values {
+		rows = append(rows, []any{deviceID, v})
+	}
+	return rows
+}
+
+// insertDeviceInterfaces inserts the interfaces and addresses of a device
+// within a transaction
+func (s *SQLiteStorage) insertDeviceInterfaces(ctx context.Context, tx *sql.Tx, device *model.Device) error {
+	rows, ids := interfaceRows(device.ID, device.Interfaces)
+	if err := insertRows(ctx, tx, interfaceInsert, rows); err != nil {
+		return fmt.Errorf("failed to insert interfaces: %w", err)
+	}
+	if err := insertRows(ctx, tx, addressInsert, addressRows(device.ID, device.Addresses, ids)); err != nil {
+		return fmt.Errorf("failed to insert addresses: %w", err)
 	}
 	return nil
 }
 
 // insertDeviceTags inserts tags for a device within a transaction
 func (s *SQLiteStorage) insertDeviceTags(ctx context.Context, tx *sql.Tx, deviceID string, tags []string) error {
-	for _, tag := range tags {
-		_, err := tx.ExecContext(ctx, `
-			INSERT INTO tags (device_id, tag) VALUES (?, ?)
-		`, deviceID, tag)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return insertRows(ctx, tx, tagInsert, valueRows(deviceID, tags))
 }
 
 // insertDeviceDomains inserts domains for a device within a transaction
 func (s *SQLiteStorage) insertDeviceDomains(ctx context.Context, tx *sql.Tx, deviceID string, domains []string) error {
-	for _, domain := range domains {
-		_, err := tx.ExecContext(ctx, `
-			INSERT INTO domains (device_id, domain) VALUES (?, ?)
-		`, deviceID, domain)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return insertRows(ctx, tx, domainInsert, valueRows(deviceID, domains))
 }
 
 // insertDeviceCustomFields inserts custom fields for a device within a transaction
@@ -367,7 +544,8 @@ func (s *SQLiteStorage) updateDeviceInTx(ctx context.Context, tx *sql.Tx, device
 
 	// Check if device exists and get current status
 	var currentStatus model.DeviceStatus
-	err := tx.QueryRowContext(ctx, `SELECT status FROM devices WHERE id = ?`, device.ID).Scan(&currentStatus)
+	var currentKind model.DeviceKind
+	err := tx.QueryRowContext(ctx, `SELECT status, kind FROM devices WHERE id = ? AND deleted_at IS NULL`, device.ID).Scan(&currentStatus, &currentKind)
 	if err == sql.ErrNoRows {
 		return ErrDeviceNotFound
 	}
@@ -386,27 +564,39 @@ func (s *SQLiteStorage) updateDeviceInTx(ctx context.Context, tx *sql.Tx, device
 		// Keep existing status if not provided
 		device.Status = currentStatus
 	}
+	if device.Kind == "" {
+		device.Kind = currentKind
+	}
 
 	// Update device
-	_, err = tx.ExecContext(ctx, `
+	versionClause, versionArgs := updatedAtClause(ctx)
+	result, err := tx.ExecContext(ctx, `
 		UPDATE devices SET
 			name = ?, hostname = ?, description = ?, make_model = ?, os = ?, datacenter_id = ?,
-			username = ?, location = ?, status = ?, decommission_date = ?,
-			status_changed_at = ?, status_changed_by = ?, updated_at = ?
-		WHERE id = ?
-	`, device.Name, device.Hostname, device.Description, device.MakeModel, device.OS,
-		nullString(device.DatacenterID), device.Username, device.Location,
-		device.Status, nullTime(device.DecommissionDate),
-		nullTime(device.StatusChangedAt), nullString(device.StatusChangedBy),
-		device.UpdatedAt, device.ID)
+			username = ?, location = ?, kind = ?, status = ?, decommission_date = ?,
+			status_changed_at = ?, status_changed_by = ?,
+			purchase_date = ?, warranty_expiry = ?, end_of_life = ?, updated_at = ?
+		WHERE id = ?`+versionClause,
+		append([]any{device.Name, device.Hostname, device.Description, device.MakeModel, device.OS,
+			nullString(device.DatacenterID), device.Username, device.Location,
+			device.Kind, device.Status, nullTime(device.DecommissionDate),
+			nullTime(device.StatusChangedAt), nullString(device.StatusChangedBy),
+			nullTime(device.PurchaseDate), nullTime(device.WarrantyExpiry), nullTime(device.EndOfLife),
+			device.UpdatedAt, device.ID}, versionArgs...)...)
 	if err != nil {
 		return fmt.Errorf("failed to update device: %w", err)
 	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrModified
+	}
 
-	// Delete existing addresses, tags, domains and reinsert
+	// Delete existing addresses, interfaces, tags, domains and reinsert
 	if _, err := tx.ExecContext(ctx, `DELETE FROM addresses WHERE device_id = ?`, device.ID); err != nil {
 		return fmt.Errorf("failed to delete addresses: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM device_interfaces WHERE device_id = ?`, device.ID); err != nil {
+		return fmt.Errorf("failed to delete interfaces: %w", err)
+	}
 	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE device_id = ?`, device.ID); err != nil {
 		return fmt.Errorf("failed to delete tags: %w", err)
 	}
@@ -417,9 +607,13 @@ func (s *SQLiteStorage) updateDeviceInTx(ctx context.Context, tx *sql.Tx, device
 		return fmt.Errorf("failed to delete custom field values: %w", err)
 	}
 
-	// Insert new addresses, tags, domains
-	if err := s.insertDeviceAddresses(ctx, tx, device.ID, device.Addresses); err != nil {
-		return fmt.Errorf("failed to insert addresses: %w", err)
+	if err := allocatePoolAddresses(ctx, tx, []*model.Device{device}); err != nil {
+		return err
+	}
+
+	// Insert new interfaces, addresses, tags, domains
+	if err := s.insertDeviceInterfaces(ctx, tx, device); err != nil {
+		return err
 	}
 	if err := s.insertDeviceTags(ctx, tx, device.ID, device.Tags); err != nil {
 		return fmt.Errorf("failed to insert tags: %w", err)
@@ -434,8 +628,11 @@ func (s *SQLiteStorage) updateDeviceInTx(ctx context.Context, tx *sql.Tx, device
 	return nil
 }
 
-// DeleteDevice removes a device and all related data (cascades via foreign keys)
-func (s *SQLiteStorage) DeleteDevice(ctx context.Context, id string) error {
+// DeleteDevice removes a device and the data it owns, such as addresses and
+// tags. Relationships are removed whatever the policy; discovery records
+// promoted to the device and reservations held for it are unlinked under
+// detach and deleted under cascade. Deny refuses while any of these exist.
+func (s *SQLiteStorage) DeleteDevice(ctx context.Context, id string, policy model.DeletePolicy) error {
 	if id == "" {
 		return ErrInvalidID
 	}
@@ -446,7 +643,7 @@ func (s *SQLiteStorage) DeleteDevice(ctx context.Context, id string) error {
 	}
 	defer tx.Rollback()
 
-	if err := s.deleteDeviceInTx(ctx, tx, id); err != nil {
+	if err := s.deleteDeviceInTx(ctx, tx, id, policy); err != nil {
 		return err
 	}
 
@@ -458,8 +655,32 @@ func (s *SQLiteStorage) DeleteDevice(ctx context.Context, id string) error {
 	return nil
 }
 
+// deviceDependents are the records that refer to a device without belonging
+// to it
+var deviceDependents = []dependent{
+	{
+		name:   "relationships",
+		count:  `SELECT COUNT(*) FROM device_relationships WHERE parent_id = ?1 OR child_id = ?1`,
+		remove: unlinkOrDelete("", `DELETE FROM device_relationships WHERE parent_id = ?1 OR child_id = ?1`),
+	},
+	{
+		name:  "discovered_devices",
+		count: `SELECT COUNT(*) FROM discovered_devices WHERE promoted_to_device_id = ?1`,
+		remove: unlinkOrDelete(
+			`UPDATE discovered_devices SET promoted_to_device_id = NULL, promoted_at = NULL WHERE promoted_to_device_id = ?1`,
+			`DELETE FROM discovered_devices WHERE promoted_to_device_id = ?1`),
+	},
+	{
+		name:  "reservations",
+		count: `SELECT COUNT(*) FROM reservations WHERE device_id = ?1`,
+		remove: unlinkOrDelete(
+			`UPDATE reservations SET device_id = NULL WHERE device_id = ?1`,
+			`DELETE FROM reservations WHERE device_id = ?1`),
+	},
+}
+
 // deleteDeviceInTx deletes a device within an existing transaction
-func (s *SQLiteStorage) deleteDeviceInTx(ctx context.Context, tx *sql.Tx, id string) error {
+func (s *SQLiteStorage) deleteDeviceInTx(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error {
 
 	// Check if device exists
 	var exists bool
@@ -471,7 +692,11 @@ func (s *SQLiteStorage) deleteDeviceInTx(ctx context.Context, tx *sql.Tx, id str
 		return ErrDeviceNotFound
 	}
 
-	// Delete the device (cascades to addresses, tags, domains, relationships)
+	if err := applyDeletePolicy(ctx, tx, "device", id, policy, deviceDependents); err != nil {
+		return err
+	}
+
+	// Delete the device (cascades to addresses, tags, domains)
 	_, err = tx.ExecContext(ctx, `DELETE FROM devices WHERE id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete device: %w", err)
@@ -484,10 +709,11 @@ func (s *SQLiteStorage) deleteDeviceInTx(ctx context.Context, tx *sql.Tx, id str
 func (s *SQLiteStorage) ListDevices(ctx context.Context, filter *model.DeviceFilter) ([]model.Device, error) {
 
 	query := `SELECT id, name, hostname, description, make_model, os, datacenter_id, username, location,
-	          status, decommission_date, status_changed_at, status_changed_by, created_at, updated_at
+	          kind, status, decommission_date, status_changed_at, status_changed_by,
+	          purchase_date, warranty_expiry, end_of_life, created_at, updated_at
 	          FROM devices`
 	var args []any
-	var conditions []string
+	conditions := []string{"deleted_at IS NULL"}
 
 	if filter != nil {
 		if filter.DatacenterID != "" {
@@ -510,6 +736,16 @@ func (s *SQLiteStorage) ListDevices(ctx context.Context, filter *model.DeviceFil
 			args = append(args, filter.Status)
 		}
 
+		if filter.Kind != "" {
+			conditions = append(conditions, "kind = ?")
+			args = append(args, filter.Kind)
+		}
+
+		if filter.HostID != "" {
+			conditions = append(conditions, "id IN (SELECT parent_id FROM device_relationships WHERE child_id = ? AND type = ?)")
+			args = append(args, filter.HostID, model.RelationshipHostedOn)
+		}
+
 		if len(filter.Tags) > 0 {
 			// Match devices that have ALL specified tags
 			for _, tag := range filter.Tags {
@@ -528,13 +764,22 @@ func (s *SQLiteStorage) ListDevices(ctx context.Context, filter *model.DeviceFil
 			)`)
 			args = append(args, staleCutoff)
 		}
-	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+		if filter.WarrantyDays > 0 {
+			// Warranties still running that expire within X days
+			now := nowUTC()
+			conditions = append(conditions, "warranty_expiry >= ? AND warranty_expiry <= ?")
+			args = append(args, now, now.AddDate(0, 0, filter.WarrantyDays))
+		}
 	}
 
-	query += " ORDER BY name"
+	query += " WHERE " + strings.Join(conditions, " AND ")
+	if filter != nil && filter.WarrantyDays > 0 {
+		// Soonest expiry first, so every page continues where the last one ended
+		query += " ORDER BY warranty_expiry ASC, name"
+	} else {
+		query += " ORDER BY name"
+	}
 
 	var pg *model.Pagination
 	if filter != nil {
@@ -542,7 +787,7 @@ func (s *SQLiteStorage) ListDevices(ctx context.Context, filter *model.DeviceFil
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list devices: %w", err)
 	}
@@ -553,10 +798,12 @@ func (s *SQLiteStorage) ListDevices(ctx context.Context, filter *model.DeviceFil
 		var device model.Device
 		var datacenterID, statusChangedBy sql.NullString
 		var decommissionDate, statusChangedAt sql.NullTime
+		var purchaseDate, warrantyExpiry, endOfLife sql.NullTime
 		if err := rows.Scan(
 			&device.ID, &device.Name, &device.Hostname, &device.Description, &device.MakeModel,
 			&device.OS, &datacenterID, &device.Username, &device.Location,
-			&device.Status, &decommissionDate, &statusChangedAt, &statusChangedBy,
+			&device.Kind, &device.Status, &decommissionDate, &statusChangedAt, &statusChangedBy,
+			&purchaseDate, &warrantyExpiry, &endOfLife,
 			&device.CreatedAt, &device.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan device: %w", err)
@@ -573,6 +820,7 @@ func (s *SQLiteStorage) ListDevices(ctx context.Context, filter *model.DeviceFil
 		if statusChangedBy.Valid {
 			device.StatusChangedBy = statusChangedBy.String
 		}
+		setLifecycleDates(&device, purchaseDate, warrantyExpiry, endOfLife)
 		devices = append(devices, device)
 	}
 
@@ -580,8 +828,14 @@ func (s *SQLiteStorage) ListDevices(ctx context.Context, filter *model.DeviceFil
 		return nil, err
 	}
 
-	// Load addresses, tags, and domains for each device
+	// Load interfaces, addresses, tags, and domains for each device
 	for i := range devices {
+		interfaces, err := s.getDeviceInterfaces(ctx, devices[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get interfaces for device %s: %w", devices[i].ID, err)
+		}
+		devices[i].Interfaces = interfaces
+
 		addresses, err := s.getDeviceAddresses(ctx, devices[i].ID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get addresses for device %s: %w", devices[i].ID, err)
@@ -615,42 +869,52 @@ func (s *SQLiteStorage) SearchDevices(ctx context.Context, query string) ([]mode
 	}
 	ftsQuery := escapeFTSQuery(query)
 	likePattern := "%" + query + "%"
+	// MACs are stored colon-separated; also match dash-separated queries
+	macPattern := "%" + strings.ReplaceAll(query, "-", ":") + "%"
+	limitClause, limitArgs := searchLimitClause(ctx)
 
 	// Use UNION to combine FTS results with tag/domain/address matches
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT DISTINCT d.id, d.name, d.hostname, d.description, d.make_model, d.os,
 		       d.datacenter_id, d.username, d.location,
-		       d.status, d.decommission_date, d.status_changed_at, d.status_changed_by,
-		       d.created_at, d.updated_at
+		       d.kind, d.status, d.decommission_date, d.status_changed_at, d.status_changed_by,
+		       d.purchase_date, d.warranty_expiry, d.end_of_life, d.created_at, d.updated_at
 		FROM devices d
 		INNER JOIN devices_fts fts ON d.id = fts.id
-		WHERE devices_fts MATCH ?
+		WHERE devices_fts MATCH ? AND d.deleted_at IS NULL
 		UNION
 		SELECT DISTINCT d.id, d.name, d.hostname, d.description, d.make_model, d.os,
 		       d.datacenter_id, d.username, d.location,
-		       d.status, d.decommission_date, d.status_changed_at, d.status_changed_by,
-		       d.created_at, d.updated_at
+		       d.kind, d.status, d.decommission_date, d.status_changed_at, d.status_changed_by,
+		       d.purchase_date, d.warranty_expiry, d.end_of_life, d.created_at, d.updated_at
 		FROM devices d
 		INNER JOIN tags t ON d.id = t.device_id
-		WHERE t.tag LIKE ?
+		WHERE t.tag LIKE ? AND d.deleted_at IS NULL
 		UNION
 		SELECT DISTINCT d.id, d.name, d.hostname, d.description, d.make_model, d.os,
 		       d.datacenter_id, d.username, d.location,
-		       d.status, d.decommission_date, d.status_changed_at, d.status_changed_by,
-		       d.created_at, d.updated_at
+		       d.kind, d.status, d.decommission_date, d.status_changed_at, d.status_changed_by,
+		       d.purchase_date, d.warranty_expiry, d.end_of_life, d.created_at, d.updated_at
 		FROM devices d
 		INNER JOIN domains dm ON d.id = dm.device_id
-		WHERE dm.domain LIKE ?
+		WHERE dm.domain LIKE ? AND d.deleted_at IS NULL
 		UNION
 		SELECT DISTINCT d.id, d.name, d.hostname, d.description, d.make_model, d.os,
 		       d.datacenter_id, d.username, d.location,
-		       d.status, d.decommission_date, d.status_changed_at, d.status_changed_by,
-		       d.created_at, d.updated_at
+		       d.kind, d.status, d.decommission_date, d.status_changed_at, d.status_changed_by,
+		       d.purchase_date, d.warranty_expiry, d.end_of_life, d.created_at, d.updated_at
 		FROM devices d
 		INNER JOIN addresses a ON d.id = a.device_id
-		WHERE a.ip LIKE ?
-		ORDER BY name
-	`, ftsQuery, likePattern, likePattern, likePattern)
+		WHERE (a.ip LIKE ? OR a.mac_address LIKE ?) AND d.deleted_at IS NULL
+		UNION
+		SELECT DISTINCT d.id, d.name, d.hostname, d.description, d.make_model, d.os,
+		       d.datacenter_id, d.username, d.location,
+		       d.kind, d.status, d.decommission_date, d.status_changed_at, d.status_changed_by,
+		       d.purchase_date, d.warranty_expiry, d.end_of_life, d.created_at, d.updated_at
+		FROM devices d
+		INNER JOIN device_interfaces di ON d.id = di.device_id
+		WHERE di.mac_address LIKE ? AND d.deleted_at IS NULL
+		ORDER BY name`+limitClause, append([]any{ftsQuery, likePattern, likePattern, likePattern, macPattern, macPattern}, limitArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search devices: %w", err)
 	}
@@ -661,10 +925,12 @@ func (s *SQLiteStorage) SearchDevices(ctx context.Context, query string) ([]mode
 		var device model.Device
 		var datacenterID, statusChangedBy sql.NullString
 		var decommissionDate, statusChangedAt sql.NullTime
+		var purchaseDate, warrantyExpiry, endOfLife sql.NullTime
 		if err := rows.Scan(
 			&device.ID, &device.Name, &device.Hostname, &device.Description, &device.MakeModel,
 			&device.OS, &datacenterID, &device.Username, &device.Location,
-			&device.Status, &decommissionDate, &statusChangedAt, &statusChangedBy,
+			&device.Kind, &device.Status, &decommissionDate, &statusChangedAt, &statusChangedBy,
+			&purchaseDate, &warrantyExpiry, &endOfLife,
 			&device.CreatedAt, &device.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan device: %w", err)
@@ -681,15 +947,25 @@ func (s *SQLiteStorage) SearchDevices(ctx context.Context, query string) ([]mode
 		if statusChangedBy.Valid {
 			device.StatusChangedBy = statusChangedBy.String
 		}
+		setLifecycleDates(&device, purchaseDate, warrantyExpiry, endOfLife)
 		devices = append(devices, device)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if err := checkSearchLimit(ctx, len(devices)); err != nil {
+		return nil, err
+	}
 
-	// Load addresses, tags, and domains for each device
+	// Load interfaces, addresses, tags, and domains for each device
 	for i := range devices {
+		interfaces, err := s.getDeviceInterfaces(ctx, devices[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get interfaces for device %s: %w", devices[i].ID, err)
+		}
+		devices[i].Interfaces = interfaces
+
 		addresses, err := s.getDeviceAddresses(ctx, devices[i].ID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get addresses for device %s: %w", devices[i].ID, err)
@@ -718,9 +994,10 @@ func (s *SQLiteStorage) SearchDevices(ctx context.Context, query string) ([]mode
 
 // GetDeviceStatusCounts returns the count of devices by status
 func (s *SQLiteStorage) GetDeviceStatusCounts(ctx context.Context) (map[model.DeviceStatus]int, error) {
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT status, COUNT(*) as count
 		FROM devices
+		WHERE deleted_at IS NULL
 		GROUP BY status
 	`)
 	if err != nil {
@@ -745,6 +1022,75 @@ func (s *SQLiteStorage) GetDeviceStatusCounts(ctx context.Context) (map[model.De
 	return counts, nil
 }
 
+// FindDuplicateMACs returns the other devices that have an address with one
+// of the given MAC addresses
+func (s *SQLiteStorage) FindDuplicateMACs(ctx context.Context, deviceID string, macs []string) ([]model.MACDuplicate, error) {
+	if len(macs) == 0 {
+		return []model.MACDuplicate{}, nil
+	}
+	args := []any{deviceID}
+	for _, mac := range macs {
+		args = append(args, mac)
+	}
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT DISTINCT a.mac_address, d.id, d.name
+		FROM addresses a
+		JOIN devices d ON a.device_id = d.id
+		WHERE a.device_id != ? AND d.deleted_at IS NULL AND a.mac_address IN (?`+strings.Repeat(", ?", len(macs)-1)+`)
+		ORDER BY a.mac_address, d.name
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate MACs: %w", err)
+	}
+	defer rows.Close()
+
+	duplicates := []model.MACDuplicate{}
+	for rows.Next() {
+		var dup model.MACDuplicate
+		if err := rows.Scan(&dup.MACAddress, &dup.DeviceID, &dup.DeviceName); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate MAC: %w", err)
+		}
+		duplicates = append(duplicates, dup)
+	}
+	return duplicates, rows.Err()
+}
+
+// FindDevicesByName returns the devices named name, ignoring case, oldest
+// first
+func (s *SQLiteStorage) FindDevicesByName(ctx context.Context, name string) ([]model.Device, error) {
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id FROM devices
+		WHERE name = ? COLLATE NOCASE AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find devices by name: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan device ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	devices := make([]model.Device, 0, len(ids))
+	for _, id := range ids {
+		device, err := s.GetDevice(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, *device)
+	}
+	return devices, nil
+}
+
 // escapeFTSQuery escapes special FTS5 characters and adds prefix matching
 func escapeFTSQuery(query string) string {
 	// Escape double quotes by doubling them
@@ -752,3 +1098,17 @@ func escapeFTSQuery(query string) string {
 	// Wrap in quotes and add * for prefix matching
 	return `"` + escaped + `"*`
 }
+
+// setLifecycleDates copies the purchase, warranty and end-of-life dates
+// scanned from a devices row onto device
+func setLifecycleDates(device *model.Device, purchaseDate, warrantyExpiry, endOfLife sql.NullTime) {
+	if purchaseDate.Valid {
+		device.PurchaseDate = &purchaseDate.Time
+	}
+	if warrantyExpiry.Valid {
+		device.WarrantyExpiry = &warrantyExpiry.Time
+	}
+	if endOfLife.Valid {
+		device.EndOfLife = &endOfLife.Time
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -26,7 +27,7 @@ func TestDeviceOperations_CreateAndGet(t *testing.T) {
 		Location:    "Rack 1, Unit 5",
 		Tags:        []string{"production", "web"},
 		Addresses: []model.Address{
-			{IP: "192.168.1.100", Port: intPtr(22), Type: "ipv4", Label: "primary"},
+			{IP: "192.168.1.100", Port: intPtr(22), Type: "ipv4", Label: "primary", MACAddress: "aa:bb:cc:dd:ee:01"},
 			{IP: "10.0.0.50", Type: "ipv4", Label: "management"},
 		},
 		Domains: []string{"server1.example.com", "www.example.com"},
@@ -77,6 +78,13 @@ func TestDeviceOperations_CreateAndGet(t *testing.T) {
 	if len(retrieved.Addresses) != len(device.Addresses) {
 		t.Errorf("expected %d addresses, got %d", len(device.Addresses), len(retrieved.Addresses))
 	}
+	macs := map[string]string{}
+	for _, a := range retrieved.Addresses {
+		macs[a.IP] = a.MACAddress
+	}
+	if macs["192.168.1.100"] != "aa:bb:cc:dd:ee:01" || macs["10.0.0.50"] != "" {
+		t.Errorf("unexpected address MACs: %v", macs)
+	}
 
 	// Verify domains
 	if len(retrieved.Domains) != len(device.Domains) {
@@ -175,6 +183,39 @@ func TestDeviceOperations_UpdateNotFound(t *testing.T) {
 	}
 }
 
+func TestDeviceOperations_UpdateExpectedUpdatedAt(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	if err := storage.CreateDevice(ctx, &model.Device{ID: "dev-1", Name: "original"}); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+	read, err := storage.GetDevice(ctx, "dev-1")
+	if err != nil {
+		t.Fatalf("GetDevice failed: %v", err)
+	}
+	stale := read.UpdatedAt
+
+	read.Name = "first"
+	if err := storage.UpdateDevice(WithExpectedUpdatedAt(ctx, stale), read); err != nil {
+		t.Fatalf("UpdateDevice with the read updated_at failed: %v", err)
+	}
+
+	// A second writer that read the same version must not overwrite the first
+	read.Name = "second"
+	if err := storage.UpdateDevice(WithExpectedUpdatedAt(ctx, stale), read); !errors.Is(err, ErrModified) {
+		t.Fatalf("expected ErrModified, got %v", err)
+	}
+	got, err := storage.GetDevice(ctx, "dev-1")
+	if err != nil {
+		t.Fatalf("GetDevice failed: %v", err)
+	}
+	if got.Name != "first" {
+		t.Errorf("expected name 'first', got %q", got.Name)
+	}
+}
+
 func TestDeviceOperations_Delete(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
@@ -191,7 +232,7 @@ func TestDeviceOperations_Delete(t *testing.T) {
 	}
 
 	// Delete device
-	if err := storage.DeleteDevice(context.Background(), device.ID); err != nil {
+	if err := storage.DeleteDevice(context.Background(), device.ID, model.DeleteDetach); err != nil {
 		t.Fatalf("DeleteDevice failed: %v", err)
 	}
 
@@ -220,7 +261,7 @@ func TestDeviceOperations_DeleteNotFound(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
 
-	err := storage.DeleteDevice(context.Background(), "non-existent-id")
+	err := storage.DeleteDevice(context.Background(), "non-existent-id", model.DeleteDetach)
 	if err != ErrDeviceNotFound {
 		t.Errorf("expected ErrDeviceNotFound, got %v", err)
 	}
@@ -230,7 +271,7 @@ func TestDeviceOperations_DeleteInvalidID(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
 
-	err := storage.DeleteDevice(context.Background(), "")
+	err := storage.DeleteDevice(context.Background(), "", model.DeleteDetach)
 	if err != ErrInvalidID {
 		t.Errorf("expected ErrInvalidID, got %v", err)
 	}
@@ -337,7 +378,7 @@ func TestDeviceOperations_Search(t *testing.T) {
 		Name:        "db-server",
 		Description: "Database server",
 		Tags:        []string{"production"},
-		Addresses:   []model.Address{{IP: "192.168.1.200", Type: "ipv4"}},
+		Addresses:   []model.Address{{IP: "192.168.1.200", Type: "ipv4", MACAddress: "AA-BB-CC-00-00-01"}},
 	}
 
 	storage.CreateDevice(context.Background(), device1)
@@ -352,6 +393,8 @@ func TestDeviceOperations_Search(t *testing.T) {
 		{"192.168.1", 2},   // Match IP addresses
 		{"production", 2},  // Match tags
 		{"example.com", 1}, // Match domains
+		{"aa:bb:cc:00", 1}, // Match MAC addresses
+		{"AA-BB-CC", 1},    // Match MAC addresses written with dashes
 		{"nonexistent", 0}, // No match
 	}
 
@@ -642,6 +685,29 @@ func TestSearchDevicesMultipleMatches(t *testing.T) {
 	}
 }
 
+func TestSearchDevicesLimit(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+
+	for _, name := range []string{"web-1", "web-2", "web-3"} {
+		if err := storage.CreateDevice(context.Background(), &model.Device{Name: name}); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+	}
+
+	if _, err := storage.SearchDevices(WithSearchLimit(context.Background(), 2), "web"); !errors.Is(err, ErrSearchTooBroad) {
+		t.Errorf("expected ErrSearchTooBroad, got %v", err)
+	}
+
+	result, err := storage.SearchDevices(WithSearchLimit(context.Background(), 3), "web")
+	if err != nil {
+		t.Fatalf("SearchDevices failed: %v", err)
+	}
+	if len(result) != 3 {
+		t.Errorf("expected 3 results at the limit, got %d", len(result))
+	}
+}
+
 func TestDeviceWithMultipleAddressTypes(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
@@ -820,6 +886,61 @@ func TestDeviceStatus_FilterByStatus(t *testing.T) {
 	}
 }
 
+func TestDeviceKind_DefaultAndFilters(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	host := &model.Device{Name: "esx-01"}
+	other := &model.Device{Name: "esx-02"}
+	vm1 := &model.Device{Name: "vm-01", Kind: model.DeviceKindVM}
+	vm2 := &model.Device{Name: "vm-02", Kind: model.DeviceKindVM}
+	for _, d := range []*model.Device{host, other, vm1, vm2} {
+		if err := storage.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+	}
+	if err := storage.AddRelationship(ctx, vm1.ID, host.ID, model.RelationshipHostedOn, ""); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+	if err := storage.AddRelationship(ctx, vm2.ID, other.ID, model.RelationshipHostedOn, ""); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+
+	retrieved, err := storage.GetDevice(ctx, host.ID)
+	if err != nil {
+		t.Fatalf("GetDevice failed: %v", err)
+	}
+	if retrieved.Kind != model.DeviceKindPhysical {
+		t.Errorf("expected default kind 'physical', got '%s'", retrieved.Kind)
+	}
+
+	// An update without a kind keeps the current one
+	vm1.Kind = ""
+	if err := storage.UpdateDevice(ctx, vm1); err != nil {
+		t.Fatalf("UpdateDevice failed: %v", err)
+	}
+	if vm1.Kind != model.DeviceKindVM {
+		t.Errorf("expected update to keep kind 'vm', got '%s'", vm1.Kind)
+	}
+
+	result, err := storage.ListDevices(ctx, &model.DeviceFilter{Kind: model.DeviceKindVM})
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if len(result) != 2 {
+		t.Errorf("expected 2 vm devices, got %d", len(result))
+	}
+
+	result, err = storage.ListDevices(ctx, &model.DeviceFilter{HostID: host.ID})
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if len(result) != 1 || result[0].ID != vm1.ID {
+		t.Errorf("expected only vm-01 on esx-01, got %+v", result)
+	}
+}
+
 func TestDeviceStatus_StatusCounts(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
@@ -888,6 +1009,66 @@ func TestDeviceStatus_DecommissionDate(t *testing.T) {
 	}
 }
 
+func TestDeviceLifecycleDates(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	at := func(days int) *time.Time {
+		t := now.AddDate(0, 0, days)
+		return &t
+	}
+	purchased := parseTime("2024-03-01T00:00:00Z")
+	devices := []*model.Device{
+		{Name: "soon", PurchaseDate: &purchased, WarrantyExpiry: at(30), EndOfLife: at(400)},
+		{Name: "later", WarrantyExpiry: at(200)},
+		{Name: "expired", WarrantyExpiry: at(-5)},
+		{Name: "unknown"},
+	}
+	for _, d := range devices {
+		if err := storage.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+	}
+
+	got, err := storage.GetDevice(ctx, devices[0].ID)
+	if err != nil {
+		t.Fatalf("GetDevice failed: %v", err)
+	}
+	if got.PurchaseDate == nil || !got.PurchaseDate.Equal(purchased) || got.WarrantyExpiry == nil || got.EndOfLife == nil {
+		t.Errorf("expected the lifecycle dates to round-trip, got %v %v %v", got.PurchaseDate, got.WarrantyExpiry, got.EndOfLife)
+	}
+
+	// Only warranties still running are expiring
+	result, err := storage.ListDevices(ctx, &model.DeviceFilter{WarrantyDays: 90})
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if len(result) != 1 || result[0].Name != "soon" {
+		t.Errorf("expected only soon within 90 days, got %v", result)
+	}
+	result, _ = storage.ListDevices(ctx, &model.DeviceFilter{WarrantyDays: 365})
+	if len(result) != 2 || result[0].Name != "soon" || result[1].Name != "later" {
+		t.Errorf("expected soon then later within a year, got %v", result)
+	}
+
+	// Pages follow expiry order, not name order
+	result, _ = storage.ListDevices(ctx, &model.DeviceFilter{WarrantyDays: 365, Pagination: model.Pagination{Limit: 1}})
+	if len(result) != 1 || result[0].Name != "soon" {
+		t.Errorf("expected the first page to hold soon, got %v", result)
+	}
+
+	// Dates can be cleared
+	got.WarrantyExpiry = nil
+	if err := storage.UpdateDevice(ctx, got); err != nil {
+		t.Fatalf("UpdateDevice failed: %v", err)
+	}
+	if got, _ = storage.GetDevice(ctx, got.ID); got.WarrantyExpiry != nil || got.PurchaseDate == nil {
+		t.Errorf("expected only the warranty to be cleared, got %v %v", got.WarrantyExpiry, got.PurchaseDate)
+	}
+}
+
 // Helper function to parse time for tests
 func parseTime(s string) time.Time {
 	t, _ := time.Parse(time.RFC3339, s)
@@ -977,9 +1158,161 @@ func TestAddressID_StorageRoundTrip(t *testing.T) {
 		}
 
 		// Clean up: delete the device so the shared store stays clean between iterations
-		err = store.DeleteDevice(context.Background(), device.ID)
+		err = store.DeleteDevice(context.Background(), device.ID, model.DeleteDetach)
 		if err != nil {
 			rt.Fatalf("DeleteDevice failed: %v", err)
 		}
 	})
 }
+
+func TestDeviceOperations_FindDuplicateMACs(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	web := &model.Device{Name: "web", Addresses: []model.Address{
+		{IP: "10.0.0.1", Type: "ipv4", MACAddress: "aa:bb:cc:00:00:01"},
+		{IP: "10.0.0.2", Type: "ipv4", MACAddress: "aa:bb:cc:00:00:02"},
+	}}
+	vm := &model.Device{Name: "vm", Addresses: []model.Address{{IP: "10.0.0.3", Type: "ipv4", MACAddress: "AA:BB:CC:00:00:01"}}}
+	other := &model.Device{Name: "other", Addresses: []model.Address{{IP: "10.0.0.4", Type: "ipv4", MACAddress: "aa:bb:cc:00:00:04"}}}
+	for _, d := range []*model.Device{web, vm, other} {
+		if err := storage.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+	}
+
+	dups, err := storage.FindDuplicateMACs(ctx, web.ID, []string{"aa:bb:cc:00:00:01", "aa:bb:cc:00:00:02"})
+	if err != nil {
+		t.Fatalf("FindDuplicateMACs failed: %v", err)
+	}
+	if len(dups) != 1 || dups[0].DeviceID != vm.ID || dups[0].MACAddress != "aa:bb:cc:00:00:01" {
+		t.Errorf("expected the vm to share web's MAC, got %+v", dups)
+	}
+
+	dups, err = storage.FindDuplicateMACs(ctx, other.ID, nil)
+	if err != nil || len(dups) != 0 {
+		t.Errorf("expected no duplicates without MACs, got %+v (%v)", dups, err)
+	}
+}
+
+func TestDeviceOperations_FindDevicesByName(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	first := &model.Device{Name: "web-01", Tags: []string{"prod"}}
+	second := &model.Device{Name: "WEB-01"}
+	other := &model.Device{Name: "web-02"}
+	trashed := &model.Device{Name: "web-01"}
+	for _, d := range []*model.Device{first, second, other, trashed} {
+		if err := storage.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice: %v", err)
+		}
+	}
+	if err := storage.TrashResource(ctx, model.TrashTypeDevice, trashed.ID, ""); err != nil {
+		t.Fatalf("TrashResource: %v", err)
+	}
+
+	matches, err := storage.FindDevicesByName(ctx, "Web-01")
+	if err != nil {
+		t.Fatalf("FindDevicesByName: %v", err)
+	}
+	if len(matches) != 2 || matches[0].ID != first.ID || matches[1].ID != second.ID {
+		t.Fatalf("expected the two live web-01 devices oldest first, got %+v", matches)
+	}
+	if len(matches[0].Tags) != 1 {
+		t.Errorf("expected matches to be loaded in full, got tags %v", matches[0].Tags)
+	}
+
+	matches, err = storage.FindDevicesByName(ctx, "missing")
+	if err != nil || len(matches) != 0 {
+		t.Errorf("expected no matches, got %v, %v", matches, err)
+	}
+}
+
+func TestDeviceInterfaces_RoundTrip(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	device := &model.Device{
+		Name: "db-01",
+		Interfaces: []model.Interface{
+			{Name: "eth0", MACAddress: "AA-BB-CC-DD-EE-01", SpeedMbps: 10000, SwitchPort: "Gi1/0/1", Parent: "bond0"},
+			{Name: "bond0", Type: model.InterfaceTypeBond},
+			{Name: "vlan20", Type: model.InterfaceTypeVLAN, VLAN: 20, Parent: "bond0"},
+		},
+		Addresses: []model.Address{
+			{IP: "10.0.0.10", Type: "ipv4", Interface: "bond0"},
+			{IP: "10.0.20.10", Type: "ipv4", Interface: "vlan20"},
+			{IP: "192.168.0.10", Type: "ipv4"},
+		},
+	}
+	if err := storage.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+
+	got, err := storage.GetDevice(ctx, device.ID)
+	if err != nil {
+		t.Fatalf("GetDevice failed: %v", err)
+	}
+	if len(got.Interfaces) != 3 {
+		t.Fatalf("expected 3 interfaces, got %d", len(got.Interfaces))
+	}
+	byName := make(map[string]model.Interface)
+	for _, iface := range got.Interfaces {
+		if iface.ID == "" {
+			t.Errorf("interface %s has no ID", iface.Name)
+		}
+		byName[iface.Name] = iface
+	}
+	eth0 := byName["eth0"]
+	if eth0.Type != model.InterfaceTypeEthernet || eth0.MACAddress != "aa:bb:cc:dd:ee:01" || eth0.SpeedMbps != 10000 ||
+		eth0.SwitchPort != "Gi1/0/1" || eth0.Parent != "bond0" {
+		t.Errorf("unexpected eth0: %+v", eth0)
+	}
+	if vlan := byName["vlan20"]; vlan.VLAN != 20 || vlan.Parent != "bond0" {
+		t.Errorf("unexpected vlan20: %+v", vlan)
+	}
+	if byName["bond0"].Parent != "" {
+		t.Errorf("expected bond0 to have no parent, got %q", byName["bond0"].Parent)
+	}
+	for _, addr := range got.Addresses {
+		want := map[string]string{"10.0.0.10": "bond0", "10.0.20.10": "vlan20", "192.168.0.10": ""}[addr.IP]
+		if addr.Interface != want {
+			t.Errorf("address %s: expected interface %q, got %q", addr.IP, want, addr.Interface)
+		}
+	}
+
+	results, err := storage.SearchDevices(ctx, "aa:bb:cc:dd:ee:01")
+	if err != nil {
+		t.Fatalf("SearchDevices failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != device.ID {
+		t.Errorf("expected an interface MAC to find the device, got %d results", len(results))
+	}
+
+	// Interfaces are replaced as a whole, and dropping one detaches its addresses
+	got.Interfaces = []model.Interface{{Name: "eth1", SpeedMbps: 1000}}
+	got.Addresses[0].Interface = "eth1"
+	if err := storage.UpdateDevice(ctx, got); err != nil {
+		t.Fatalf("UpdateDevice failed: %v", err)
+	}
+	updated, err := storage.GetDevice(ctx, device.ID)
+	if err != nil {
+		t.Fatalf("GetDevice failed: %v", err)
+	}
+	if len(updated.Interfaces) != 1 || updated.Interfaces[0].Name != "eth1" {
+		t.Fatalf("expected only eth1 after update, got %+v", updated.Interfaces)
+	}
+	for _, addr := range updated.Addresses {
+		want := ""
+		if addr.IP == got.Addresses[0].IP {
+			want = "eth1"
+		}
+		if addr.Interface != want {
+			t.Errorf("address %s: expected interface %q, got %q", addr.IP, want, addr.Interface)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -24,14 +25,17 @@ func (s *SQLiteStorage) CreateDiscoveredDevice(ctx context.Context, device *mode
 
 	openPorts, _ := json.Marshal(device.OpenPorts)
 	services, _ := json.Marshal(device.Services)
+	dnsNames, dnsMismatches := marshalDNSResults(device)
+	breakdown := marshalConfidenceBreakdown(device)
 
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO discovered_devices (id, ip, mac_address, hostname, network_id, status, confidence,
-			os_guess, vendor, open_ports, services, first_seen, last_seen, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			confidence_breakdown, os_guess, vendor, open_ports, services, first_seen, last_seen, scan_count,
+			dns_names, dns_mismatches, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, device.ID, device.IP, device.MACAddress, device.Hostname, device.NetworkID, device.Status,
-		device.Confidence, device.OSGuess, device.Vendor, string(openPorts), string(services),
-		device.FirstSeen, device.LastSeen, device.CreatedAt, device.UpdatedAt)
+		device.Confidence, breakdown, device.OSGuess, device.Vendor, string(openPorts), string(services),
+		device.FirstSeen, device.LastSeen, device.ScanCount, dnsNames, dnsMismatches, device.CreatedAt, device.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -39,22 +43,26 @@ func (s *SQLiteStorage) CreateDiscoveredDevice(ctx context.Context, device *mode
 	return nil
 }
 
-// UpdateDiscoveredDevice updates an existing discovered device
+// UpdateDiscoveredDevice updates an existing discovered device. The ignored
+// status is derived from the ignore columns and never stored, so a device
+// read while ignored keeps its stored status.
 func (s *SQLiteStorage) UpdateDiscoveredDevice(ctx context.Context, device *model.DiscoveredDevice) error {
 	device.UpdatedAt = nowUTC()
 	device.LastSeen = device.UpdatedAt
 
 	openPorts, _ := json.Marshal(device.OpenPorts)
 	services, _ := json.Marshal(device.Services)
+	dnsNames, dnsMismatches := marshalDNSResults(device)
+	breakdown := marshalConfidenceBreakdown(device)
 
 	result, err := s.db.ExecContext(ctx, `
 		UPDATE discovered_devices SET ip = ?, mac_address = ?, hostname = ?, network_id = ?,
-			status = ?, confidence = ?, os_guess = ?, vendor = ?, open_ports = ?, services = ?,
-			last_seen = ?, updated_at = ?
+			status = COALESCE(NULLIF(?, 'ignored'), status), confidence = ?, confidence_breakdown = ?, os_guess = ?, vendor = ?, open_ports = ?,
+			services = ?, last_seen = ?, scan_count = ?, dns_names = ?, dns_mismatches = ?, updated_at = ?
 		WHERE id = ?
 	`, device.IP, device.MACAddress, device.Hostname, device.NetworkID, device.Status,
-		device.Confidence, device.OSGuess, device.Vendor, string(openPorts), string(services),
-		device.LastSeen, device.UpdatedAt, device.ID)
+		device.Confidence, breakdown, device.OSGuess, device.Vendor, string(openPorts), string(services),
+		device.LastSeen, device.ScanCount, dnsNames, dnsMismatches, device.UpdatedAt, device.ID)
 	if err != nil {
 		return err
 	}
@@ -69,17 +77,19 @@ func (s *SQLiteStorage) UpdateDiscoveredDevice(ctx context.Context, device *mode
 // GetDiscoveredDevice retrieves a discovered device by ID
 func (s *SQLiteStorage) GetDiscoveredDevice(ctx context.Context, id string) (*model.DiscoveredDevice, error) {
 	var d model.DiscoveredDevice
-	var openPorts, services, promotedToDeviceID sql.NullString
-	var promotedAt sql.NullTime
+	var openPorts, services, dnsNames, dnsMismatches, breakdown, promotedToDeviceID sql.NullString
+	var promotedAt, ignoredAt, ignoredUntil sql.NullTime
+	var ignoreReason string
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, ip, mac_address, hostname, network_id, status, confidence, os_guess, vendor,
-			open_ports, services, first_seen, last_seen, promoted_to_device_id, promoted_at,
+			open_ports, services, first_seen, last_seen, scan_count, dns_names, dns_mismatches, confidence_breakdown,
+			promoted_to_device_id, promoted_at, ignored_at, ignore_reason, ignored_until,
 			created_at, updated_at
 		FROM discovered_devices WHERE id = ?
 	`, id).Scan(&d.ID, &d.IP, &d.MACAddress, &d.Hostname, &d.NetworkID, &d.Status, &d.Confidence,
-		&d.OSGuess, &d.Vendor, &openPorts, &services, &d.FirstSeen, &d.LastSeen,
-		&promotedToDeviceID, &promotedAt, &d.CreatedAt, &d.UpdatedAt)
+		&d.OSGuess, &d.Vendor, &openPorts, &services, &d.FirstSeen, &d.LastSeen, &d.ScanCount, &dnsNames, &dnsMismatches, &breakdown,
+		&promotedToDeviceID, &promotedAt, &ignoredAt, &ignoreReason, &ignoredUntil, &d.CreatedAt, &d.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrDiscoveryNotFound
 	}
@@ -93,29 +103,34 @@ func (s *SQLiteStorage) GetDiscoveredDevice(ctx context.Context, id string) (*mo
 	if services.Valid {
 		json.Unmarshal([]byte(services.String), &d.Services)
 	}
+	unmarshalDNSResults(&d, dnsNames, dnsMismatches)
+	unmarshalConfidenceBreakdown(&d, breakdown)
 	if promotedToDeviceID.Valid {
 		d.PromotedToDeviceID = promotedToDeviceID.String
 	}
 	if promotedAt.Valid {
 		d.PromotedAt = &promotedAt.Time
 	}
+	applyDiscoveredIgnore(&d, ignoredAt, ignoreReason, ignoredUntil)
 	return &d, nil
 }
 
 // GetDiscoveredDeviceByIP retrieves a discovered device by network and IP
 func (s *SQLiteStorage) GetDiscoveredDeviceByIP(ctx context.Context, networkID, ip string) (*model.DiscoveredDevice, error) {
 	var d model.DiscoveredDevice
-	var openPorts, services, promotedToDeviceID sql.NullString
-	var promotedAt sql.NullTime
+	var openPorts, services, dnsNames, dnsMismatches, breakdown, promotedToDeviceID sql.NullString
+	var promotedAt, ignoredAt, ignoredUntil sql.NullTime
+	var ignoreReason string
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, ip, mac_address, hostname, network_id, status, confidence, os_guess, vendor,
-			open_ports, services, first_seen, last_seen, promoted_to_device_id, promoted_at,
+			open_ports, services, first_seen, last_seen, scan_count, dns_names, dns_mismatches, confidence_breakdown,
+			promoted_to_device_id, promoted_at, ignored_at, ignore_reason, ignored_until,
 			created_at, updated_at
 		FROM discovered_devices WHERE network_id = ? AND ip = ?
 	`, networkID, ip).Scan(&d.ID, &d.IP, &d.MACAddress, &d.Hostname, &d.NetworkID, &d.Status,
-		&d.Confidence, &d.OSGuess, &d.Vendor, &openPorts, &services, &d.FirstSeen, &d.LastSeen,
-		&promotedToDeviceID, &promotedAt, &d.CreatedAt, &d.UpdatedAt)
+		&d.Confidence, &d.OSGuess, &d.Vendor, &openPorts, &services, &d.FirstSeen, &d.LastSeen, &d.ScanCount, &dnsNames, &dnsMismatches, &breakdown,
+		&promotedToDeviceID, &promotedAt, &ignoredAt, &ignoreReason, &ignoredUntil, &d.CreatedAt, &d.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrDiscoveryNotFound
 	}
@@ -129,19 +144,23 @@ func (s *SQLiteStorage) GetDiscoveredDeviceByIP(ctx context.Context, networkID,
 	if services.Valid {
 		json.Unmarshal([]byte(services.String), &d.Services)
 	}
+	unmarshalDNSResults(&d, dnsNames, dnsMismatches)
+	unmarshalConfidenceBreakdown(&d, breakdown)
 	if promotedToDeviceID.Valid {
 		d.PromotedToDeviceID = promotedToDeviceID.String
 	}
 	if promotedAt.Valid {
 		d.PromotedAt = &promotedAt.Time
 	}
+	applyDiscoveredIgnore(&d, ignoredAt, ignoreReason, ignoredUntil)
 	return &d, nil
 }
 
 // ListDiscoveredDevices returns all discovered devices for a network
 func (s *SQLiteStorage) ListDiscoveredDevices(ctx context.Context, networkID string) ([]model.DiscoveredDevice, error) {
 	query := `SELECT id, ip, mac_address, hostname, network_id, status, confidence, os_guess, vendor,
-		open_ports, services, first_seen, last_seen, promoted_to_device_id, promoted_at,
+		open_ports, services, first_seen, last_seen, scan_count, dns_names, dns_mismatches, confidence_breakdown,
+			promoted_to_device_id, promoted_at, ignored_at, ignore_reason, ignored_until,
 		created_at, updated_at FROM discovered_devices`
 	var args []any
 	if networkID != "" {
@@ -150,7 +169,7 @@ func (s *SQLiteStorage) ListDiscoveredDevices(ctx context.Context, networkID str
 	}
 	query += " ORDER BY last_seen DESC"
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -159,11 +178,12 @@ func (s *SQLiteStorage) ListDiscoveredDevices(ctx context.Context, networkID str
 	var devices []model.DiscoveredDevice
 	for rows.Next() {
 		var d model.DiscoveredDevice
-		var openPorts, services, promotedToDeviceID sql.NullString
-		var promotedAt sql.NullTime
+		var openPorts, services, dnsNames, dnsMismatches, breakdown, promotedToDeviceID sql.NullString
+		var promotedAt, ignoredAt, ignoredUntil sql.NullTime
+		var ignoreReason string
 		if err := rows.Scan(&d.ID, &d.IP, &d.MACAddress, &d.Hostname, &d.NetworkID, &d.Status,
-			&d.Confidence, &d.OSGuess, &d.Vendor, &openPorts, &services, &d.FirstSeen, &d.LastSeen,
-			&promotedToDeviceID, &promotedAt, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			&d.Confidence, &d.OSGuess, &d.Vendor, &openPorts, &services, &d.FirstSeen, &d.LastSeen, &d.ScanCount, &dnsNames, &dnsMismatches, &breakdown,
+			&promotedToDeviceID, &promotedAt, &ignoredAt, &ignoreReason, &ignoredUntil, &d.CreatedAt, &d.UpdatedAt); err != nil {
 			return nil, err
 		}
 		if openPorts.Valid {
@@ -172,12 +192,15 @@ func (s *SQLiteStorage) ListDiscoveredDevices(ctx context.Context, networkID str
 		if services.Valid {
 			json.Unmarshal([]byte(services.String), &d.Services)
 		}
+		unmarshalDNSResults(&d, dnsNames, dnsMismatches)
+		unmarshalConfidenceBreakdown(&d, breakdown)
 		if promotedToDeviceID.Valid {
 			d.PromotedToDeviceID = promotedToDeviceID.String
 		}
 		if promotedAt.Valid {
 			d.PromotedAt = &promotedAt.Time
 		}
+		applyDiscoveredIgnore(&d, ignoredAt, ignoreReason, ignoredUntil)
 		devices = append(devices, d)
 	}
 	return devices, rows.Err()
@@ -233,6 +256,43 @@ func (s *SQLiteStorage) PromoteDiscoveredDevice(ctx context.Context, discoveredI
 	return nil
 }
 
+// IgnoreDiscoveredDevice marks a discovered device as ignored, until the
+// given time or, when until is nil, until it is unignored. Scans keep
+// updating an ignored device without lifting the ignore.
+func (s *SQLiteStorage) IgnoreDiscoveredDevice(ctx context.Context, id, reason string, until *time.Time) error {
+	now := nowUTC()
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE discovered_devices SET ignored_at = ?, ignore_reason = ?, ignored_until = ?, updated_at = ?
+		WHERE id = ?
+	`, now, reason, until, now, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrDiscoveryNotFound
+	}
+	s.auditLog(ctx, "ignore", "discovered_device", id, map[string]any{"reason": reason, "until": until})
+	return nil
+}
+
+// UnignoreDiscoveredDevice lifts the ignore of a discovered device
+func (s *SQLiteStorage) UnignoreDiscoveredDevice(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE discovered_devices SET ignored_at = NULL, ignore_reason = '', ignored_until = NULL, updated_at = ?
+		WHERE id = ?
+	`, nowUTC(), id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrDiscoveryNotFound
+	}
+	s.auditLog(ctx, "unignore", "discovered_device", id, nil)
+	return nil
+}
+
 // CreateDiscoveryScan inserts a new discovery scan
 func (s *SQLiteStorage) CreateDiscoveryScan(ctx context.Context, scan *model.DiscoveryScan) error {
 	if scan.ID == "" {
@@ -281,7 +341,7 @@ func (s *SQLiteStorage) UpdateDiscoveryScan(ctx context.Context, scan *model.Dis
 func (s *SQLiteStorage) GetDiscoveryScan(ctx context.Context, id string) (*model.DiscoveryScan, error) {
 	var scan model.DiscoveryScan
 	var startedAt, completedAt sql.NullTime
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, network_id, status, scan_type, total_hosts, scanned_hosts, found_hosts,
 			progress_percent, error_message, started_at, completed_at, created_at, updated_at
 		FROM discovery_scans WHERE id = ?
@@ -315,7 +375,7 @@ func (s *SQLiteStorage) ListDiscoveryScans(ctx context.Context, networkID string
 	}
 	query += " ORDER BY created_at DESC"
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -344,12 +404,13 @@ func (s *SQLiteStorage) ListDiscoveryScans(ctx context.Context, networkID string
 // GetDiscoveryRule retrieves a discovery rule by ID
 func (s *SQLiteStorage) GetDiscoveryRule(ctx context.Context, id string) (*model.DiscoveryRule, error) {
 	var rule model.DiscoveryRule
-	var enabled int
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, network_id, enabled, scan_type, interval_hours, exclude_ips, created_at, updated_at
+	var enabled, passive, dnsResolution int
+	err := s.reader.QueryRowContext(ctx, `
+		SELECT id, network_id, enabled, scan_type, interval_hours, exclude_ips, passive_enabled,
+			dns_resolution, dns_server, max_concurrent_scans, created_at, updated_at
 		FROM discovery_rules WHERE id = ?
 	`, id).Scan(&rule.ID, &rule.NetworkID, &enabled, &rule.ScanType, &rule.IntervalHours,
-		&rule.ExcludeIPs, &rule.CreatedAt, &rule.UpdatedAt)
+		&rule.ExcludeIPs, &passive, &dnsResolution, &rule.DNSServer, &rule.MaxConcurrentScans, &rule.CreatedAt, &rule.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrRuleNotFound
 	}
@@ -357,18 +418,21 @@ func (s *SQLiteStorage) GetDiscoveryRule(ctx context.Context, id string) (*model
 		return nil, err
 	}
 	rule.Enabled = enabled == 1
+	rule.PassiveEnabled = passive == 1
+	rule.DNSResolution = dnsResolution == 1
 	return &rule, nil
 }
 
 // GetDiscoveryRuleByNetwork retrieves a discovery rule by network ID
 func (s *SQLiteStorage) GetDiscoveryRuleByNetwork(ctx context.Context, networkID string) (*model.DiscoveryRule, error) {
 	var rule model.DiscoveryRule
-	var enabled int
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, network_id, enabled, scan_type, interval_hours, exclude_ips, created_at, updated_at
+	var enabled, passive, dnsResolution int
+	err := s.reader.QueryRowContext(ctx, `
+		SELECT id, network_id, enabled, scan_type, interval_hours, exclude_ips, passive_enabled,
+			dns_resolution, dns_server, max_concurrent_scans, created_at, updated_at
 		FROM discovery_rules WHERE network_id = ?
 	`, networkID).Scan(&rule.ID, &rule.NetworkID, &enabled, &rule.ScanType, &rule.IntervalHours,
-		&rule.ExcludeIPs, &rule.CreatedAt, &rule.UpdatedAt)
+		&rule.ExcludeIPs, &passive, &dnsResolution, &rule.DNSServer, &rule.MaxConcurrentScans, &rule.CreatedAt, &rule.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrRuleNotFound
 	}
@@ -376,6 +440,8 @@ func (s *SQLiteStorage) GetDiscoveryRuleByNetwork(ctx context.Context, networkID
 		return nil, err
 	}
 	rule.Enabled = enabled == 1
+	rule.PassiveEnabled = passive == 1
+	rule.DNSResolution = dnsResolution == 1
 	return &rule, nil
 }
 
@@ -391,15 +457,27 @@ func (s *SQLiteStorage) SaveDiscoveryRule(ctx context.Context, rule *model.Disco
 	if rule.Enabled {
 		enabled = 1
 	}
+	passive := 0
+	if rule.PassiveEnabled {
+		passive = 1
+	}
+	dnsResolution := 0
+	if rule.DNSResolution {
+		dnsResolution = 1
+	}
 
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO discovery_rules (id, network_id, enabled, scan_type, interval_hours, exclude_ips, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO discovery_rules (id, network_id, enabled, scan_type, interval_hours, exclude_ips, passive_enabled,
+			dns_resolution, dns_server, max_concurrent_scans, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(network_id) DO UPDATE SET
 			enabled = excluded.enabled, scan_type = excluded.scan_type,
 			interval_hours = excluded.interval_hours, exclude_ips = excluded.exclude_ips,
+			passive_enabled = excluded.passive_enabled, dns_resolution = excluded.dns_resolution,
+			dns_server = excluded.dns_server, max_concurrent_scans = excluded.max_concurrent_scans,
 			updated_at = excluded.updated_at
-	`, rule.ID, rule.NetworkID, enabled, rule.ScanType, rule.IntervalHours, rule.ExcludeIPs, now, now)
+	`, rule.ID, rule.NetworkID, enabled, rule.ScanType, rule.IntervalHours, rule.ExcludeIPs, passive,
+		dnsResolution, rule.DNSServer, rule.MaxConcurrentScans, now, now)
 	if err != nil {
 		return err
 	}
@@ -408,8 +486,9 @@ func (s *SQLiteStorage) SaveDiscoveryRule(ctx context.Context, rule *model.Disco
 }
 
 func (s *SQLiteStorage) ListDiscoveryRules(ctx context.Context) ([]model.DiscoveryRule, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, network_id, enabled, scan_type, interval_hours, exclude_ips, created_at, updated_at
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, network_id, enabled, scan_type, interval_hours, exclude_ips, passive_enabled,
+			dns_resolution, dns_server, max_concurrent_scans, created_at, updated_at
 		FROM discovery_rules ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -420,12 +499,14 @@ func (s *SQLiteStorage) ListDiscoveryRules(ctx context.Context) ([]model.Discove
 	var rules []model.DiscoveryRule
 	for rows.Next() {
 		var rule model.DiscoveryRule
-		var enabled int
+		var enabled, passive, dnsResolution int
 		if err := rows.Scan(&rule.ID, &rule.NetworkID, &enabled, &rule.ScanType, &rule.IntervalHours,
-			&rule.ExcludeIPs, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+			&rule.ExcludeIPs, &passive, &dnsResolution, &rule.DNSServer, &rule.MaxConcurrentScans, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
 			return nil, err
 		}
 		rule.Enabled = enabled == 1
+		rule.PassiveEnabled = passive == 1
+		rule.DNSResolution = dnsResolution == 1
 		rules = append(rules, rule)
 	}
 	return rules, rows.Err()
@@ -487,11 +568,68 @@ func (s *SQLiteStorage) DeleteDiscoveredDevicesByNetwork(ctx context.Context, ne
 	return nil
 }
 
-// CleanupOldDiscoveries removes discovered devices older than specified days
+// CleanupOldDiscoveries removes discovered devices, and port observations,
+// older than specified days
 func (s *SQLiteStorage) CleanupOldDiscoveries(ctx context.Context, olderThanDays int) error {
 	cutoff := nowUTC().AddDate(0, 0, -olderThanDays)
 	_, err := s.db.ExecContext(ctx, `
 		DELETE FROM discovered_devices WHERE last_seen < ? AND promoted_to_device_id IS NULL
 	`, cutoff)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `DELETE FROM port_observations WHERE last_seen < ?`, cutoff)
 	return err
 }
+
+func marshalDNSResults(device *model.DiscoveredDevice) (string, string) {
+	if device.DNSNames == nil {
+		device.DNSNames = []string{}
+	}
+	if device.DNSMismatches == nil {
+		device.DNSMismatches = []string{}
+	}
+	names, _ := json.Marshal(device.DNSNames)
+	mismatches, _ := json.Marshal(device.DNSMismatches)
+	return string(names), string(mismatches)
+}
+
+func unmarshalDNSResults(d *model.DiscoveredDevice, names, mismatches sql.NullString) {
+	d.DNSNames = []string{}
+	d.DNSMismatches = []string{}
+	if names.Valid {
+		json.Unmarshal([]byte(names.String), &d.DNSNames)
+	}
+	if mismatches.Valid {
+		json.Unmarshal([]byte(mismatches.String), &d.DNSMismatches)
+	}
+}
+
+func marshalConfidenceBreakdown(device *model.DiscoveredDevice) string {
+	if device.ConfidenceBreakdown == nil {
+		device.ConfidenceBreakdown = []model.ConfidenceFactor{}
+	}
+	breakdown, _ := json.Marshal(device.ConfidenceBreakdown)
+	return string(breakdown)
+}
+
+func unmarshalConfidenceBreakdown(d *model.DiscoveredDevice, breakdown sql.NullString) {
+	d.ConfidenceBreakdown = []model.ConfidenceFactor{}
+	if breakdown.Valid {
+		json.Unmarshal([]byte(breakdown.String), &d.ConfidenceBreakdown)
+	}
+}
+
+// applyDiscoveredIgnore reports a discovered device as ignored while its
+// ignore lasts. An expired ignore is not reported.
+func applyDiscoveredIgnore(d *model.DiscoveredDevice, ignoredAt sql.NullTime, reason string, until sql.NullTime) {
+	if !ignoredAt.Valid || (until.Valid && !until.Time.After(time.Now())) {
+		return
+	}
+	d.Status = model.DiscoveredStatusIgnored
+	d.IgnoredAt = &ignoredAt.Time
+	d.IgnoreReason = reason
+	if until.Valid {
+		d.IgnoredUntil = &until.Time
+	}
+}
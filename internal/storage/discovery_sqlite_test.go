@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -718,6 +720,10 @@ func TestDiscoveryRuleWithAllFields(t *testing.T) {
 		ScanType:      model.ScanTypeDeep,
 		IntervalHours: 12,
 		ExcludeIPs:    "192.168.1.1,192.168.1.254",
+		DNSResolution: true,
+		DNSServer:     "10.0.0.53:53",
+
+		MaxConcurrentScans: 8,
 	}
 	storage.SaveDiscoveryRule(context.Background(), rule)
 
@@ -731,6 +737,50 @@ func TestDiscoveryRuleWithAllFields(t *testing.T) {
 	if got.ExcludeIPs != "192.168.1.1,192.168.1.254" {
 		t.Errorf("exclude_ips mismatch")
 	}
+	if !got.DNSResolution || got.DNSServer != "10.0.0.53:53" {
+		t.Errorf("expected DNS resolution settings to round-trip, got %v %q", got.DNSResolution, got.DNSServer)
+	}
+	if got.MaxConcurrentScans != 8 {
+		t.Errorf("expected max concurrent scans 8, got %d", got.MaxConcurrentScans)
+	}
+}
+
+func TestDiscoveredDeviceDNSResults(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+
+	network := &model.Network{Name: "TestNet", Subnet: "192.168.1.0/24"}
+	storage.CreateNetwork(context.Background(), network)
+
+	device := &model.DiscoveredDevice{
+		IP:            "192.168.1.10",
+		NetworkID:     network.ID,
+		DNSNames:      []string{"web1.example.com"},
+		DNSMismatches: []string{"PTR name web1.example.com resolves to 192.168.1.11, not 192.168.1.10"},
+	}
+	if err := storage.CreateDiscoveredDevice(context.Background(), device); err != nil {
+		t.Fatalf("CreateDiscoveredDevice failed: %v", err)
+	}
+
+	got, err := storage.GetDiscoveredDeviceByIP(context.Background(), network.ID, "192.168.1.10")
+	if err != nil {
+		t.Fatalf("GetDiscoveredDeviceByIP failed: %v", err)
+	}
+	if len(got.DNSNames) != 1 || got.DNSNames[0] != "web1.example.com" {
+		t.Errorf("dns_names = %v", got.DNSNames)
+	}
+	if len(got.DNSMismatches) != 1 {
+		t.Errorf("dns_mismatches = %v", got.DNSMismatches)
+	}
+
+	got.DNSMismatches = nil
+	if err := storage.UpdateDiscoveredDevice(context.Background(), got); err != nil {
+		t.Fatalf("UpdateDiscoveredDevice failed: %v", err)
+	}
+	devices, _ := storage.ListDiscoveredDevices(context.Background(), network.ID)
+	if len(devices) != 1 || devices[0].DNSMismatches == nil || len(devices[0].DNSMismatches) != 0 {
+		t.Errorf("expected mismatches to be cleared, got %v", devices)
+	}
 }
 
 func TestListDiscoveryRulesMultiple(t *testing.T) {
@@ -835,3 +885,60 @@ func TestDeleteDiscoveryScanAndDiscoveredDevicesByNetwork(t *testing.T) {
 		t.Fatalf("expected all discovered devices to be deleted, got %d", len(devices2))
 	}
 }
+
+func TestIgnoreDiscoveredDevice(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "TestNet", Subnet: "192.168.1.0/24"}
+	storage.CreateNetwork(ctx, network)
+	device := &model.DiscoveredDevice{IP: "192.168.1.10", NetworkID: network.ID, Status: "online"}
+	if err := storage.CreateDiscoveredDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDiscoveredDevice failed: %v", err)
+	}
+
+	if err := storage.IgnoreDiscoveredDevice(ctx, device.ID, "guest", nil); err != nil {
+		t.Fatalf("IgnoreDiscoveredDevice failed: %v", err)
+	}
+	got, _ := storage.GetDiscoveredDevice(ctx, device.ID)
+	if !got.Ignored() || got.IgnoreReason != "guest" || got.IgnoredAt == nil || got.IgnoredUntil != nil {
+		t.Fatalf("expected ignored device, got %+v", got)
+	}
+
+	// A scan updating the device read while ignored does not lift the ignore
+	// or store the ignored status
+	got.OpenPorts = []int{22}
+	if err := storage.UpdateDiscoveredDevice(ctx, got); err != nil {
+		t.Fatalf("UpdateDiscoveredDevice failed: %v", err)
+	}
+	devices, _ := storage.ListDiscoveredDevices(ctx, network.ID)
+	if len(devices) != 1 || !devices[0].Ignored() || len(devices[0].OpenPorts) != 1 {
+		t.Fatalf("expected update to keep the ignore, got %+v", devices)
+	}
+
+	if err := storage.UnignoreDiscoveredDevice(ctx, device.ID); err != nil {
+		t.Fatalf("UnignoreDiscoveredDevice failed: %v", err)
+	}
+	got, _ = storage.GetDiscoveredDeviceByIP(ctx, network.ID, "192.168.1.10")
+	if got.Ignored() || got.Status != "online" || got.IgnoreReason != "" {
+		t.Fatalf("expected ignore lifted and status restored, got %+v", got)
+	}
+
+	// An expired ignore is not reported
+	past := time.Now().Add(-time.Minute)
+	storage.IgnoreDiscoveredDevice(ctx, device.ID, "", &past)
+	if got, _ = storage.GetDiscoveredDevice(ctx, device.ID); got.Ignored() {
+		t.Fatalf("expected expired ignore not reported, got %+v", got)
+	}
+
+	if err := storage.IgnoreDiscoveredDevice(ctx, "missing", "", nil); !errors.Is(err, ErrDiscoveryNotFound) {
+		t.Errorf("expected ErrDiscoveryNotFound, got %v", err)
+	}
+	if err := storage.UnignoreDiscoveredDevice(ctx, "missing"); !errors.Is(err, ErrDiscoveryNotFound) {
+		t.Errorf("expected ErrDiscoveryNotFound, got %v", err)
+	}
+}
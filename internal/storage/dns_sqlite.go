@@ -68,7 +68,7 @@ func (s *SQLiteStorage) GetDNSProvider(ctx context.Context, id string) (*model.D
 	}
 
 	provider := &model.DNSProviderConfig{}
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, type, endpoint, token, description, created_at, updated_at
 		FROM dns_provider_configs WHERE id = ?
 	`, id).Scan(
@@ -93,7 +93,7 @@ func (s *SQLiteStorage) GetDNSProviderByName(ctx context.Context, name string) (
 	}
 
 	provider := &model.DNSProviderConfig{}
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, type, endpoint, token, description, created_at, updated_at
 		FROM dns_provider_configs WHERE name = ?
 	`, name).Scan(
@@ -137,7 +137,7 @@ func (s *SQLiteStorage) ListDNSProviders(ctx context.Context, filter *model.DNSP
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list DNS providers: %w", err)
 	}
@@ -177,7 +177,7 @@ func (s *SQLiteStorage) UpdateDNSProvider(ctx context.Context, provider *model.D
 
 	// Check if provider exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_provider_configs WHERE id = ?)`, provider.ID).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_provider_configs WHERE id = ?)`, provider.ID).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check DNS provider existence: %w", err)
 	}
@@ -209,7 +209,7 @@ func (s *SQLiteStorage) DeleteDNSProvider(ctx context.Context, id string) error
 
 	// Check if provider exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_provider_configs WHERE id = ?)`, id).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_provider_configs WHERE id = ?)`, id).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check DNS provider existence: %w", err)
 	}
@@ -219,7 +219,7 @@ func (s *SQLiteStorage) DeleteDNSProvider(ctx context.Context, id string) error
 
 	// Check if provider is in use by any zones
 	var zoneCount int
-	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM dns_zones WHERE provider_id = ?`, id).Scan(&zoneCount)
+	err = s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM dns_zones WHERE provider_id = ?`, id).Scan(&zoneCount)
 	if err != nil {
 		return fmt.Errorf("failed to check DNS provider usage: %w", err)
 	}
@@ -317,7 +317,7 @@ func (s *SQLiteStorage) GetDNSZone(ctx context.Context, id string) (*model.DNSZo
 	var networkID, ptrZone, lastSyncError sql.NullString
 	var lastSyncAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, provider_id, network_id, auto_sync, create_ptr, ptr_zone,
 		       ttl, description, last_sync_at, last_sync_status, last_sync_error, created_at, updated_at
 		FROM dns_zones WHERE id = ?
@@ -361,7 +361,7 @@ func (s *SQLiteStorage) GetDNSZoneByName(ctx context.Context, name string) (*mod
 	var networkID, ptrZone, lastSyncError sql.NullString
 	var lastSyncAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, provider_id, network_id, auto_sync, create_ptr, ptr_zone,
 		       ttl, description, last_sync_at, last_sync_status, last_sync_error, created_at, updated_at
 		FROM dns_zones WHERE name = ?
@@ -431,7 +431,7 @@ func (s *SQLiteStorage) ListDNSZones(ctx context.Context, filter *model.DNSZoneF
 	}
 	query, args = appendPagination(query, args, pgz)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list DNS zones: %w", err)
 	}
@@ -490,7 +490,7 @@ func (s *SQLiteStorage) UpdateDNSZone(ctx context.Context, zone *model.DNSZone)
 
 	// Check if zone exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_zones WHERE id = ?)`, zone.ID).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_zones WHERE id = ?)`, zone.ID).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check DNS zone existence: %w", err)
 	}
@@ -584,7 +584,7 @@ func (s *SQLiteStorage) GetDNSZonesByNetwork(ctx context.Context, networkID stri
 	        ttl, description, last_sync_at, last_sync_status, last_sync_error, created_at, updated_at
 	        FROM dns_zones WHERE network_id = ? ORDER BY name`
 
-	rows, err := s.db.QueryContext(ctx, query, networkID)
+	rows, err := s.reader.QueryContext(ctx, query, networkID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get DNS zones by network: %w", err)
 	}
@@ -721,7 +721,7 @@ func (s *SQLiteStorage) GetDNSRecord(ctx context.Context, id string) (*model.DNS
 	var deviceID, addressID, errorMessage sql.NullString
 	var lastSyncAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, zone_id, device_id, address_id, name, type, value, ttl, sync_status, last_sync_at, error_message, created_at, updated_at
 		FROM dns_records WHERE id = ?
 	`, id).Scan(
@@ -764,7 +764,7 @@ func (s *SQLiteStorage) GetDNSRecordByName(ctx context.Context, zoneID, name str
 	var deviceID, addressID, errorMessage sql.NullString
 	var lastSyncAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, zone_id, device_id, address_id, name, type, value, ttl, sync_status, last_sync_at, error_message, created_at, updated_at
 		FROM dns_records WHERE zone_id = ? AND name = ? AND type = ?
 	`, zoneID, name, recordType).Scan(
@@ -844,7 +844,7 @@ func (s *SQLiteStorage) ListDNSRecords(ctx context.Context, filter *model.DNSRec
 	}
 	query, args = appendPagination(query, args, pgr)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list DNS records: %w", err)
 	}
@@ -903,7 +903,7 @@ func (s *SQLiteStorage) UpdateDNSRecord(ctx context.Context, record *model.DNSRe
 
 	// Check if record exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_records WHERE id = ?)`, record.ID).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_records WHERE id = ?)`, record.ID).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check DNS record existence: %w", err)
 	}
@@ -948,7 +948,7 @@ func (s *SQLiteStorage) DeleteDNSRecord(ctx context.Context, id string) error {
 
 	// Check if record exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_records WHERE id = ?)`, id).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_records WHERE id = ?)`, id).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check DNS record existence: %w", err)
 	}
@@ -973,7 +973,7 @@ func (s *SQLiteStorage) DeleteDNSRecordsByZone(ctx context.Context, zoneID strin
 
 	// Check if zone exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_zones WHERE id = ?)`, zoneID).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dns_zones WHERE id = ?)`, zoneID).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check DNS zone existence: %w", err)
 	}
@@ -998,7 +998,7 @@ func (s *SQLiteStorage) DeleteDNSRecordsByDevice(ctx context.Context, deviceID s
 
 	// Check if device exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM devices WHERE id = ?)`, deviceID).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM devices WHERE id = ? AND deleted_at IS NULL)`, deviceID).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check device existence: %w", err)
 	}
@@ -1023,7 +1023,7 @@ func (s *SQLiteStorage) GetDNSRecordsByDevice(ctx context.Context, deviceID stri
 
 	// Check if device exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM devices WHERE id = ?)`, deviceID).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM devices WHERE id = ? AND deleted_at IS NULL)`, deviceID).Scan(&exists)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check device existence: %w", err)
 	}
@@ -1034,7 +1034,7 @@ func (s *SQLiteStorage) GetDNSRecordsByDevice(ctx context.Context, deviceID stri
 	query := `SELECT id, zone_id, device_id, address_id, name, type, value, ttl, sync_status, last_sync_at, error_message, created_at, updated_at
 	        FROM dns_records WHERE device_id = ? ORDER BY zone_id, name, type`
 
-	rows, err := s.db.QueryContext(ctx, query, deviceID)
+	rows, err := s.reader.QueryContext(ctx, query, deviceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get DNS records by device: %w", err)
 	}
@@ -1779,7 +1779,7 @@ func TestDNSRecordAddressID_StorageRoundTrip(t *testing.T) {
 		// Clean up
 		_ = store.DeleteDNSRecord(ctx, record.ID)
 		_ = store.DeleteDNSZone(ctx, zone.ID)
-		_ = store.DeleteDevice(ctx, device.ID)
+		_ = store.DeleteDevice(ctx, device.ID, model.DeleteDetach)
 		_ = store.DeleteDNSProvider(ctx, provider.ID)
 	})
 }
@@ -1938,7 +1938,7 @@ func TestDNSRecordLinkStatusFilter(t *testing.T) {
 			_ = store.DeleteDNSRecord(ctx, id)
 		}
 		_ = store.DeleteDNSZone(ctx, zone.ID)
-		_ = store.DeleteDevice(ctx, device.ID)
+		_ = store.DeleteDevice(ctx, device.ID, model.DeleteDetach)
 		_ = store.DeleteDNSProvider(ctx, provider.ID)
 	})
 }
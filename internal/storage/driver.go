@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultDriver is the storage driver used unless another is configured
+const DefaultDriver = "sqlite"
+
+// Factory opens a storage backend. The data source is driver specific: a
+// data directory for sqlite, a DSN or URL for others.
+type Factory func(dataSource string) (ExtendedStorage, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Factory{}
+)
+
+func init() {
+	Register(DefaultDriver, func(dataSource string) (ExtendedStorage, error) {
+		return NewSQLiteStorage(dataSource)
+	})
+}
+
+// Register makes a storage driver available by name, so builds of rackd can
+// add backends from their own packages, typically in an init function. It
+// panics if the name is taken or the factory is nil, like database/sql.
+func Register(name string, factory Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if factory == nil {
+		panic("storage: Register factory is nil for driver " + name)
+	}
+	if _, dup := drivers[name]; dup {
+		panic("storage: Register called twice for driver " + name)
+	}
+	drivers[name] = factory
+}
+
+// Drivers returns the names of the registered storage drivers, sorted
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Open opens storage with the named driver
+func Open(driver, dataSource string) (ExtendedStorage, error) {
+	driversMu.RLock()
+	factory, ok := drivers[driver]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown storage driver %q (available: %s)", driver, strings.Join(Drivers(), ", "))
+	}
+	return factory(dataSource)
+}
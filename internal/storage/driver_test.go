@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegisterDriver(t *testing.T) {
+	errTest := errors.New("test driver")
+	var got string
+	Register("test-driver", func(dataSource string) (ExtendedStorage, error) {
+		got = dataSource
+		return nil, errTest
+	})
+	t.Cleanup(func() {
+		driversMu.Lock()
+		delete(drivers, "test-driver")
+		driversMu.Unlock()
+	})
+
+	if names := Drivers(); !slices.Contains(names, "test-driver") || !slices.Contains(names, DefaultDriver) || !slices.IsSorted(names) {
+		t.Errorf("unexpected drivers %v", names)
+	}
+	if _, err := Open("test-driver", "test://db"); !errors.Is(err, errTest) || got != "test://db" {
+		t.Errorf("expected the test driver to be opened with its data source, got %v %q", err, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a driver twice to panic")
+		}
+	}()
+	Register("test-driver", func(string) (ExtendedStorage, error) { return nil, nil })
+}
+
+func TestOpenDriver(t *testing.T) {
+	store, err := Open(DefaultDriver, ":memory:")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer store.Close()
+	if err := store.DB().Ping(); err != nil {
+		t.Errorf("failed to ping database: %v", err)
+	}
+
+	_, err = Open("mysql", "")
+	if err == nil || !strings.Contains(err.Error(), `unknown storage driver "mysql"`) || !strings.Contains(err.Error(), DefaultDriver) {
+		t.Errorf("expected an unknown driver error listing the drivers, got %v", err)
+	}
+}
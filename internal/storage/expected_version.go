@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+type expectedUpdatedAtKey struct{}
+
+// WithExpectedUpdatedAt makes updates of devices, networks and datacenters
+// under ctx apply only while the row's updated_at is still updatedAt. An
+// update of a row changed since then fails with ErrModified, so a client
+// that read the row first cannot overwrite a change it has not seen.
+func WithExpectedUpdatedAt(ctx context.Context, updatedAt time.Time) context.Context {
+	return context.WithValue(ctx, expectedUpdatedAtKey{}, updatedAt)
+}
+
+// updatedAtClause returns the condition and its argument that hold an update
+// under ctx to the expected updated_at, or nothing when there is none
+func updatedAtClause(ctx context.Context) (string, []any) {
+	updatedAt, ok := ctx.Value(expectedUpdatedAtKey{}).(time.Time)
+	if !ok {
+		return "", nil
+	}
+	return " AND updated_at = ?", []any{updatedAt.UTC()}
+}
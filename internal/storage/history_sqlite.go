@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/audit"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// recordVersion appends a version of a network or pool. It runs in the same
+// transaction as the change so history cannot drift from the live tables.
+func recordVersion(ctx context.Context, db execer, resource, resourceID, action string, obj any) error {
+	var data string
+	if obj != nil {
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s version: %w", resource, err)
+		}
+		data = string(b)
+	}
+
+	var changedBy string
+	if auditCtx, ok := audit.FromContext(ctx); ok {
+		changedBy = auditCtx.Username
+	}
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO resource_versions (resource, resource_id, action, data, changed_by, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, resource, resourceID, action, data, changedBy, nowUTC())
+	if err != nil {
+		return fmt.Errorf("failed to record %s version: %w", resource, err)
+	}
+	return nil
+}
+
+// ListResourceVersions returns the recorded versions of a resource, oldest first
+func (s *SQLiteStorage) ListResourceVersions(ctx context.Context, resource, resourceID string) ([]model.ResourceVersion, error) {
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, resource, resource_id, action, data, changed_by, changed_at
+		FROM resource_versions WHERE resource = ? AND resource_id = ?
+		ORDER BY id
+	`, resource, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []model.ResourceVersion{}
+	for rows.Next() {
+		var v model.ResourceVersion
+		var data string
+		if err := rows.Scan(&v.Version, &v.Resource, &v.ResourceID, &v.Action, &data, &v.ChangedBy, &v.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan version: %w", err)
+		}
+		if data != "" {
+			v.Data = json.RawMessage(data)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// statesAsOf returns the data of the latest version at asOf of every resource
+// of the given type that existed then, or of a single resource when
+// resourceID is set
+func (s *SQLiteStorage) statesAsOf(ctx context.Context, resource, resourceID string, asOf time.Time) ([]string, error) {
+	query := `
+		SELECT data FROM resource_versions
+		WHERE id IN (
+			SELECT MAX(id) FROM resource_versions
+			WHERE resource = ? AND changed_at <= ?`
+	args := []any{resource, asOf.UTC()}
+	if resourceID != "" {
+		query += " AND resource_id = ?"
+		args = append(args, resourceID)
+	}
+	query += `
+			GROUP BY resource_id
+		) AND action != 'delete'`
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s history: %w", resource, err)
+	}
+	defer rows.Close()
+
+	var states []string
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan %s version: %w", resource, err)
+		}
+		states = append(states, data)
+	}
+	return states, rows.Err()
+}
+
+// ListNetworksAsOf returns the networks as they were at asOf
+func (s *SQLiteStorage) ListNetworksAsOf(ctx context.Context, asOf time.Time, filter *model.NetworkFilter) ([]model.Network, error) {
+	states, err := s.statesAsOf(ctx, "network", "", asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	networks := []model.Network{}
+	for _, data := range states {
+		var n model.Network
+		if err := json.Unmarshal([]byte(data), &n); err != nil {
+			return nil, fmt.Errorf("failed to decode network version: %w", err)
+		}
+		if filter != nil {
+			if filter.Name != "" && !strings.Contains(strings.ToLower(n.Name), strings.ToLower(filter.Name)) {
+				continue
+			}
+			if filter.DatacenterID != "" && n.DatacenterID != filter.DatacenterID {
+				continue
+			}
+			if filter.ParentID != "" && n.ParentID != filter.ParentID {
+				continue
+			}
+			if filter.VLANID > 0 && n.VLANID != filter.VLANID {
+				continue
+			}
+		}
+		networks = append(networks, n)
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+
+	var pg *model.Pagination
+	if filter != nil {
+		pg = &filter.Pagination
+	}
+	return paginateSlice(networks, pg), nil
+}
+
+// GetNetworkAsOf returns a network as it was at asOf
+func (s *SQLiteStorage) GetNetworkAsOf(ctx context.Context, id string, asOf time.Time) (*model.Network, error) {
+	if id == "" {
+		return nil, ErrInvalidID
+	}
+	states, err := s.statesAsOf(ctx, "network", id, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, ErrNetworkNotFound
+	}
+
+	var network model.Network
+	if err := json.Unmarshal([]byte(states[0]), &network); err != nil {
+		return nil, fmt.Errorf("failed to decode network version: %w", err)
+	}
+	return &network, nil
+}
+
+// ListNetworkPoolsAsOf returns the pools as they were at asOf
+func (s *SQLiteStorage) ListNetworkPoolsAsOf(ctx context.Context, asOf time.Time, filter *model.NetworkPoolFilter) ([]model.NetworkPool, error) {
+	states, err := s.statesAsOf(ctx, "pool", "", asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	pools := []model.NetworkPool{}
+	for _, data := range states {
+		var p model.NetworkPool
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			return nil, fmt.Errorf("failed to decode pool version: %w", err)
+		}
+		if filter != nil {
+			if filter.NetworkID != "" && p.NetworkID != filter.NetworkID {
+				continue
+			}
+			if !containsAll(p.Tags, filter.Tags) {
+				continue
+			}
+		}
+		if p.Tags == nil {
+			p.Tags = []string{}
+		}
+		pools = append(pools, p)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+
+	var pg *model.Pagination
+	if filter != nil {
+		pg = &filter.Pagination
+	}
+	return paginateSlice(pools, pg), nil
+}
+
+// GetNetworkPoolAsOf returns a pool as it was at asOf
+func (s *SQLiteStorage) GetNetworkPoolAsOf(ctx context.Context, id string, asOf time.Time) (*model.NetworkPool, error) {
+	if id == "" {
+		return nil, ErrInvalidID
+	}
+	states, err := s.statesAsOf(ctx, "pool", id, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, ErrPoolNotFound
+	}
+
+	var pool model.NetworkPool
+	if err := json.Unmarshal([]byte(states[0]), &pool); err != nil {
+		return nil, fmt.Errorf("failed to decode pool version: %w", err)
+	}
+	if pool.Tags == nil {
+		pool.Tags = []string{}
+	}
+	return &pool, nil
+}
+
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// paginateSlice applies the same limit and offset as appendPagination to
+// results filtered in memory
+func paginateSlice[T any](items []T, p *model.Pagination) []T {
+	if p == nil {
+		p = &model.Pagination{}
+	}
+	p.Clamp()
+	if p.Offset >= len(items) {
+		return items[:0]
+	}
+	items = items[p.Offset:]
+	if len(items) > p.Limit {
+		items = items[:p.Limit]
+	}
+	return items
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// moment returns the current time and waits so later writes get a later timestamp
+func moment() time.Time {
+	t := time.Now().UTC()
+	time.Sleep(2 * time.Millisecond)
+	return t
+}
+
+func TestNetworkHistory_AsOf(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	beforeCreate := moment()
+
+	network := &model.Network{Name: "office", Subnet: "10.0.0.0/24"}
+	if err := storage.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	afterCreate := moment()
+
+	network.Subnet = "10.0.0.0/23"
+	if err := storage.UpdateNetwork(ctx, network); err != nil {
+		t.Fatalf("UpdateNetwork failed: %v", err)
+	}
+	afterUpdate := moment()
+
+	if err := storage.DeleteNetwork(ctx, network.ID, model.DeleteDetach); err != nil {
+		t.Fatalf("DeleteNetwork failed: %v", err)
+	}
+
+	if _, err := storage.GetNetworkAsOf(ctx, network.ID, beforeCreate); !errors.Is(err, ErrNetworkNotFound) {
+		t.Errorf("expected ErrNetworkNotFound before creation, got %v", err)
+	}
+
+	got, err := storage.GetNetworkAsOf(ctx, network.ID, afterCreate)
+	if err != nil {
+		t.Fatalf("GetNetworkAsOf failed: %v", err)
+	}
+	if got.Subnet != "10.0.0.0/24" {
+		t.Errorf("expected original subnet, got %s", got.Subnet)
+	}
+
+	networks, err := storage.ListNetworksAsOf(ctx, afterUpdate, nil)
+	if err != nil {
+		t.Fatalf("ListNetworksAsOf failed: %v", err)
+	}
+	if len(networks) != 1 || networks[0].Subnet != "10.0.0.0/23" {
+		t.Errorf("expected updated subnet, got %+v", networks)
+	}
+
+	networks, _ = storage.ListNetworksAsOf(ctx, time.Now(), nil)
+	if len(networks) != 0 {
+		t.Errorf("expected no networks after deletion, got %d", len(networks))
+	}
+
+	versions, err := storage.ListResourceVersions(ctx, "network", network.ID)
+	if err != nil {
+		t.Fatalf("ListResourceVersions failed: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	for i, action := range []string{"create", "update", "delete"} {
+		if versions[i].Action != action {
+			t.Errorf("version %d: expected %s, got %s", i, action, versions[i].Action)
+		}
+	}
+	if versions[2].Data != nil {
+		t.Error("expected no data for delete version")
+	}
+}
+
+func TestPoolHistory_AsOf(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "office", Subnet: "10.0.0.0/24"}
+	if err := storage.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "dhcp", StartIP: "10.0.0.100", EndIP: "10.0.0.150", Tags: []string{"dhcp"}}
+	if err := storage.CreateNetworkPool(ctx, pool); err != nil {
+		t.Fatalf("CreateNetworkPool failed: %v", err)
+	}
+	other := &model.NetworkPool{NetworkID: network.ID, Name: "static", StartIP: "10.0.0.10", EndIP: "10.0.0.50"}
+	if err := storage.CreateNetworkPool(ctx, other); err != nil {
+		t.Fatalf("CreateNetworkPool failed: %v", err)
+	}
+	afterCreate := moment()
+
+	pool.EndIP = "10.0.0.200"
+	if err := storage.UpdateNetworkPool(ctx, pool); err != nil {
+		t.Fatalf("UpdateNetworkPool failed: %v", err)
+	}
+
+	got, err := storage.GetNetworkPoolAsOf(ctx, pool.ID, afterCreate)
+	if err != nil {
+		t.Fatalf("GetNetworkPoolAsOf failed: %v", err)
+	}
+	if got.EndIP != "10.0.0.150" {
+		t.Errorf("expected original range end, got %s", got.EndIP)
+	}
+
+	pools, err := storage.ListNetworkPoolsAsOf(ctx, afterCreate, &model.NetworkPoolFilter{NetworkID: network.ID, Tags: []string{"dhcp"}})
+	if err != nil {
+		t.Fatalf("ListNetworkPoolsAsOf failed: %v", err)
+	}
+	if len(pools) != 1 || pools[0].Name != "dhcp" {
+		t.Errorf("expected the dhcp pool, got %+v", pools)
+	}
+
+	// Deleting the network removes its pools, which must show in their history
+	afterUpdate := moment()
+	if err := storage.DeleteNetwork(ctx, network.ID, model.DeleteDetach); err != nil {
+		t.Fatalf("DeleteNetwork failed: %v", err)
+	}
+
+	pools, _ = storage.ListNetworkPoolsAsOf(ctx, afterUpdate, &model.NetworkPoolFilter{NetworkID: network.ID})
+	if len(pools) != 2 {
+		t.Errorf("expected 2 pools before network deletion, got %d", len(pools))
+	}
+	if _, err := storage.GetNetworkPoolAsOf(ctx, other.ID, time.Now()); !errors.Is(err, ErrPoolNotFound) {
+		t.Errorf("expected ErrPoolNotFound after network deletion, got %v", err)
+	}
+}
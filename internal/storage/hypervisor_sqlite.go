@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const hypervisorConnectorColumns = `id, name, type, endpoint, username, secret, insecure_skip_verify, datacenter_id,
+	enabled, description, last_sync_at, last_sync_error, created_at, updated_at`
+
+// CreateHypervisorConnector inserts a new hypervisor connector. The secret
+// must already be encrypted.
+func (s *SQLiteStorage) CreateHypervisorConnector(ctx context.Context, c *model.HypervisorConnector) error {
+	if c.ID == "" {
+		c.ID = newUUID()
+	}
+	now := nowUTC()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO hypervisor_connectors (`+hypervisorConnectorColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, c.ID, c.Name, c.Type, c.Endpoint, c.Username, c.Secret, c.InsecureSkipVerify, nullString(c.DatacenterID),
+		c.Enabled, c.Description, c.LastSyncAt, c.LastSyncError, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return ErrDuplicateConnectorName
+		}
+		return err
+	}
+	s.auditLog(ctx, "create", "hypervisor_connector", c.ID, c)
+	return nil
+}
+
+// GetHypervisorConnector retrieves a hypervisor connector by ID
+func (s *SQLiteStorage) GetHypervisorConnector(ctx context.Context, id string) (*model.HypervisorConnector, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+hypervisorConnectorColumns+` FROM hypervisor_connectors WHERE id = ?`, id)
+	c, err := scanHypervisorConnector(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrHypervisorConnectorNotFound
+	}
+	return c, err
+}
+
+// ListHypervisorConnectors returns the connectors matching the filter,
+// ordered by name
+func (s *SQLiteStorage) ListHypervisorConnectors(ctx context.Context, filter *model.HypervisorConnectorFilter) ([]model.HypervisorConnector, error) {
+	query := `SELECT ` + hypervisorConnectorColumns + ` FROM hypervisor_connectors`
+	var conditions []string
+	var args []any
+
+	if filter != nil {
+		if filter.Type != "" {
+			conditions = append(conditions, "type = ?")
+			args = append(args, filter.Type)
+		}
+		if filter.Enabled != nil {
+			conditions = append(conditions, "enabled = ?")
+			args = append(args, *filter.Enabled)
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY name"
+
+	var pg *model.Pagination
+	if filter != nil {
+		pg = &filter.Pagination
+	}
+	query, args = appendPagination(query, args, pg)
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	connectors := []model.HypervisorConnector{}
+	for rows.Next() {
+		c, err := scanHypervisorConnector(rows)
+		if err != nil {
+			return nil, err
+		}
+		connectors = append(connectors, *c)
+	}
+	return connectors, rows.Err()
+}
+
+// UpdateHypervisorConnector updates a connector's settings. The sync status
+// is left alone; RecordHypervisorSync sets it.
+func (s *SQLiteStorage) UpdateHypervisorConnector(ctx context.Context, c *model.HypervisorConnector) error {
+	c.UpdatedAt = nowUTC()
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE hypervisor_connectors SET name = ?, endpoint = ?, username = ?, secret = ?, insecure_skip_verify = ?,
+			datacenter_id = ?, enabled = ?, description = ?, updated_at = ?
+		WHERE id = ?
+	`, c.Name, c.Endpoint, c.Username, c.Secret, c.InsecureSkipVerify, nullString(c.DatacenterID),
+		c.Enabled, c.Description, c.UpdatedAt, c.ID)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return ErrDuplicateConnectorName
+		}
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrHypervisorConnectorNotFound
+	}
+	s.auditLog(ctx, "update", "hypervisor_connector", c.ID, c)
+	return nil
+}
+
+// DeleteHypervisorConnector removes a connector. Devices it synced are kept.
+func (s *SQLiteStorage) DeleteHypervisorConnector(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM hypervisor_connectors WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrHypervisorConnectorNotFound
+	}
+	s.auditLog(ctx, "delete", "hypervisor_connector", id, nil)
+	return nil
+}
+
+// RecordHypervisorSync stores the time and error of a connector's last sync.
+// It is not audited; the devices the sync changes are.
+func (s *SQLiteStorage) RecordHypervisorSync(ctx context.Context, id string, syncedAt time.Time, syncErr string) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE hypervisor_connectors SET last_sync_at = ?, last_sync_error = ? WHERE id = ?`,
+		syncedAt.UTC(), syncErr, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrHypervisorConnectorNotFound
+	}
+	return nil
+}
+
+func scanHypervisorConnector(row interface{ Scan(...any) error }) (*model.HypervisorConnector, error) {
+	var c model.HypervisorConnector
+	var datacenterID sql.NullString
+	var lastSyncAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.Name, &c.Type, &c.Endpoint, &c.Username, &c.Secret, &c.InsecureSkipVerify, &datacenterID,
+		&c.Enabled, &c.Description, &lastSyncAt, &c.LastSyncError, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	c.DatacenterID = datacenterID.String
+	if lastSyncAt.Valid {
+		c.LastSyncAt = &lastSyncAt.Time
+	}
+	return &c, nil
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestHypervisorConnectorCRUD(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "DC1"}
+	if err := storage.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("CreateDatacenter failed: %v", err)
+	}
+
+	pve := &model.HypervisorConnector{Name: "pve", Type: model.HypervisorTypeProxmox, Endpoint: "https://pve:8006",
+		Username: "root@pam!rackd", Secret: "encrypted", DatacenterID: dc.ID, Enabled: true}
+	vc := &model.HypervisorConnector{Name: "vcenter", Type: model.HypervisorTypeVSphere, Endpoint: "https://vc",
+		Username: "reader", Secret: "encrypted", InsecureSkipVerify: true}
+	for _, c := range []*model.HypervisorConnector{pve, vc} {
+		if err := storage.CreateHypervisorConnector(ctx, c); err != nil {
+			t.Fatalf("CreateHypervisorConnector failed: %v", err)
+		}
+	}
+	if err := storage.CreateHypervisorConnector(ctx, &model.HypervisorConnector{Name: "pve", Type: model.HypervisorTypeProxmox}); !errors.Is(err, ErrDuplicateConnectorName) {
+		t.Errorf("expected ErrDuplicateConnectorName, got %v", err)
+	}
+
+	got, err := storage.GetHypervisorConnector(ctx, pve.ID)
+	if err != nil {
+		t.Fatalf("GetHypervisorConnector failed: %v", err)
+	}
+	if got.Secret != "encrypted" || got.DatacenterID != dc.ID || !got.Enabled || got.LastSyncAt != nil {
+		t.Errorf("connector mismatch: got %+v", got)
+	}
+
+	enabled := true
+	list, err := storage.ListHypervisorConnectors(ctx, &model.HypervisorConnectorFilter{Enabled: &enabled})
+	if err != nil {
+		t.Fatalf("ListHypervisorConnectors failed: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != pve.ID {
+		t.Errorf("expected only the enabled connector, got %+v", list)
+	}
+	list, _ = storage.ListHypervisorConnectors(ctx, &model.HypervisorConnectorFilter{Type: model.HypervisorTypeVSphere})
+	if len(list) != 1 || list[0].ID != vc.ID || !list[0].InsecureSkipVerify {
+		t.Errorf("expected the vSphere connector, got %+v", list)
+	}
+
+	vc.Enabled = true
+	vc.DatacenterID = dc.ID
+	if err := storage.UpdateHypervisorConnector(ctx, vc); err != nil {
+		t.Fatalf("UpdateHypervisorConnector failed: %v", err)
+	}
+	syncedAt := time.Now().UTC().Truncate(time.Second)
+	if err := storage.RecordHypervisorSync(ctx, vc.ID, syncedAt, "login failed"); err != nil {
+		t.Fatalf("RecordHypervisorSync failed: %v", err)
+	}
+	got, _ = storage.GetHypervisorConnector(ctx, vc.ID)
+	if !got.Enabled || got.LastSyncAt == nil || !got.LastSyncAt.Equal(syncedAt) || got.LastSyncError != "login failed" {
+		t.Errorf("update mismatch: got %+v", got)
+	}
+
+	// Deleting the datacenter unlinks it from the connector
+	if err := storage.DeleteDatacenter(ctx, dc.ID, model.DeleteDetach); err != nil {
+		t.Fatalf("DeleteDatacenter failed: %v", err)
+	}
+	if got, _ := storage.GetHypervisorConnector(ctx, pve.ID); got.DatacenterID != "" {
+		t.Errorf("expected datacenter to be cleared, got %q", got.DatacenterID)
+	}
+
+	if err := storage.DeleteHypervisorConnector(ctx, pve.ID); err != nil {
+		t.Fatalf("DeleteHypervisorConnector failed: %v", err)
+	}
+	if _, err := storage.GetHypervisorConnector(ctx, pve.ID); !errors.Is(err, ErrHypervisorConnectorNotFound) {
+		t.Errorf("expected ErrHypervisorConnectorNotFound, got %v", err)
+	}
+	if err := storage.DeleteHypervisorConnector(ctx, pve.ID); !errors.Is(err, ErrHypervisorConnectorNotFound) {
+		t.Errorf("expected ErrHypervisorConnectorNotFound on second delete, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const idempotencyColumns = `idempotency_key, user_id, method, path, request_hash, status, headers, body, created_at, expires_at`
+
+// ReserveIdempotencyKey claims a key for a new request, returning the record
+// already holding it if there is one. Expired keys are deleted first, so
+// they can be claimed again.
+func (s *SQLiteStorage) ReserveIdempotencyKey(ctx context.Context, rec *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	now := nowUTC()
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= ?`, now); err != nil {
+		return nil, err
+	}
+
+	rec.Status = 0
+	rec.CreatedAt = now
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (`+idempotencyColumns+`)
+		VALUES (?, ?, ?, ?, ?, 0, '{}', NULL, ?, ?)
+		ON CONFLICT (user_id, idempotency_key) DO NOTHING
+	`, rec.Key, rec.UserID, rec.Method, rec.Path, rec.RequestHash, rec.CreatedAt, rec.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 1 {
+		return nil, nil
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+idempotencyColumns+` FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ?
+	`, rec.UserID, rec.Key)
+	return scanIdempotencyRecord(row)
+}
+
+// CompleteIdempotencyKey stores the response to a claimed key
+func (s *SQLiteStorage) CompleteIdempotencyKey(ctx context.Context, rec *model.IdempotencyRecord) error {
+	headers, _ := json.Marshal(rec.Headers)
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE idempotency_keys SET status = ?, headers = ?, body = ? WHERE user_id = ? AND idempotency_key = ?
+	`, rec.Status, string(headers), rec.Body, rec.UserID, rec.Key)
+	return err
+}
+
+// ReleaseIdempotencyKey frees a claimed key, as when the request failed and
+// may be retried
+func (s *SQLiteStorage) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ?`, userID, key)
+	return err
+}
+
+func scanIdempotencyRecord(row interface{ Scan(...any) error }) (*model.IdempotencyRecord, error) {
+	var rec model.IdempotencyRecord
+	var headers string
+	if err := row.Scan(&rec.Key, &rec.UserID, &rec.Method, &rec.Path, &rec.RequestHash, &rec.Status,
+		&headers, &rec.Body, &rec.CreatedAt, &rec.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(headers), &rec.Headers); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
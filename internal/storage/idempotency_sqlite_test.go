@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	rec := &model.IdempotencyRecord{
+		Key:         "retry-1",
+		UserID:      "user-1",
+		Method:      "POST",
+		Path:        "/api/devices",
+		RequestHash: "abc",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	existing, err := storage.ReserveIdempotencyKey(ctx, rec)
+	if err != nil || existing != nil {
+		t.Fatalf("expected the key to be claimed, got %+v (%v)", existing, err)
+	}
+
+	// A second claim sees the request in progress
+	existing, err = storage.ReserveIdempotencyKey(ctx, &model.IdempotencyRecord{Key: "retry-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil || existing == nil || existing.Status != 0 || existing.RequestHash != "abc" {
+		t.Fatalf("expected the in-progress record, got %+v (%v)", existing, err)
+	}
+
+	// Keys are per user
+	if existing, _ := storage.ReserveIdempotencyKey(ctx, &model.IdempotencyRecord{Key: "retry-1", UserID: "user-2", ExpiresAt: time.Now().Add(time.Hour)}); existing != nil {
+		t.Errorf("expected another user's key to be independent, got %+v", existing)
+	}
+
+	rec.Status = 201
+	rec.Headers = map[string]string{"Content-Type": "application/json"}
+	rec.Body = []byte(`{"id":"dev-1"}`)
+	if err := storage.CompleteIdempotencyKey(ctx, rec); err != nil {
+		t.Fatalf("CompleteIdempotencyKey failed: %v", err)
+	}
+	existing, _ = storage.ReserveIdempotencyKey(ctx, &model.IdempotencyRecord{Key: "retry-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)})
+	if existing == nil || existing.Status != 201 || string(existing.Body) != `{"id":"dev-1"}` || existing.Headers["Content-Type"] != "application/json" {
+		t.Fatalf("expected the stored response, got %+v", existing)
+	}
+
+	// Released and expired keys can be claimed again
+	if err := storage.ReleaseIdempotencyKey(ctx, "user-1", "retry-1"); err != nil {
+		t.Fatalf("ReleaseIdempotencyKey failed: %v", err)
+	}
+	if existing, _ := storage.ReserveIdempotencyKey(ctx, &model.IdempotencyRecord{Key: "retry-1", UserID: "user-1", ExpiresAt: time.Now().Add(-time.Second)}); existing != nil {
+		t.Errorf("expected a released key to be claimable, got %+v", existing)
+	}
+	if existing, _ := storage.ReserveIdempotencyKey(ctx, &model.IdempotencyRecord{Key: "retry-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}); existing != nil {
+		t.Errorf("expected an expired key to be claimable, got %+v", existing)
+	}
+}
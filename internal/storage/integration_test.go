@@ -92,7 +92,7 @@ func TestDeviceLifecycle(t *testing.T) {
 	}
 
 	// 4. DELETE
-	if err := store.DeleteDevice(ctx, deviceID); err != nil {
+	if err := store.DeleteDevice(ctx, deviceID, model.DeleteDetach); err != nil {
 		t.Fatalf("DELETE failed: %v", err)
 	}
 
@@ -172,7 +172,7 @@ func TestNetworkPoolLifecycle(t *testing.T) {
 	}
 
 	// 6. DELETE pool
-	if err := store.DeleteNetworkPool(ctx, poolID); err != nil {
+	if err := store.DeleteNetworkPool(ctx, poolID, model.DeleteDetach); err != nil {
 		t.Fatalf("DELETE pool failed: %v", err)
 	}
 
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// RecordInventoryMetrics stores metric values, replacing any already
+// recorded for the same metric, resource and day
+func (s *SQLiteStorage) RecordInventoryMetrics(ctx context.Context, metrics []model.InventoryMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO inventory_metrics (day, name, resource_id, resource_name, value, recorded_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name, resource_id, day) DO UPDATE SET
+			resource_name = excluded.resource_name,
+			value = excluded.value,
+			recorded_at = excluded.recorded_at
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare metric insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := nowUTC()
+	for i := range metrics {
+		m := &metrics[i]
+		if m.Day == "" || m.Name == "" {
+			return errors.New("metric day and name are required")
+		}
+		if m.RecordedAt.IsZero() {
+			m.RecordedAt = now
+		}
+		if _, err := stmt.ExecContext(ctx, m.Day, m.Name, m.ResourceID, m.ResourceName, m.Value, m.RecordedAt); err != nil {
+			return fmt.Errorf("failed to record metric %s: %w", m.Name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListInventoryMetrics returns the recorded metric values matching the
+// filter, ordered by metric, resource and day
+func (s *SQLiteStorage) ListInventoryMetrics(ctx context.Context, filter *model.InventoryMetricFilter) ([]model.InventoryMetric, error) {
+	query := `SELECT day, name, resource_id, resource_name, value, recorded_at FROM inventory_metrics WHERE 1=1`
+	var args []any
+
+	if filter != nil {
+		if len(filter.Names) > 0 {
+			query += " AND name IN (" + strings.TrimSuffix(strings.Repeat("?,", len(filter.Names)), ",") + ")"
+			for _, name := range filter.Names {
+				args = append(args, name)
+			}
+		}
+		if filter.ResourceID != "" {
+			query += " AND resource_id = ?"
+			args = append(args, filter.ResourceID)
+		}
+		if filter.Since != "" {
+			query += " AND day >= ?"
+			args = append(args, filter.Since)
+		}
+	}
+	query += " ORDER BY name, resource_id, day"
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inventory metrics: %w", err)
+	}
+	defer rows.Close()
+
+	metrics := []model.InventoryMetric{}
+	for rows.Next() {
+		var m model.InventoryMetric
+		if err := rows.Scan(&m.Day, &m.Name, &m.ResourceID, &m.ResourceName, &m.Value, &m.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan inventory metric: %w", err)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, rows.Err()
+}
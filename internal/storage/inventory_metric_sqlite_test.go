@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestInventoryMetrics(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	record := func(metrics ...model.InventoryMetric) {
+		t.Helper()
+		if err := storage.RecordInventoryMetrics(ctx, metrics); err != nil {
+			t.Fatalf("RecordInventoryMetrics failed: %v", err)
+		}
+	}
+	record(
+		model.InventoryMetric{Day: "2026-10-01", Name: model.MetricDevicesTotal, Value: 10},
+		model.InventoryMetric{Day: "2026-10-02", Name: model.MetricDevicesTotal, Value: 12},
+		model.InventoryMetric{Day: "2026-10-01", Name: model.MetricPoolsUtilization, ResourceID: "pool-1", ResourceName: "servers", Value: 40},
+		model.InventoryMetric{Day: "2026-10-01", Name: model.MetricPoolsUtilization, Value: 30},
+	)
+	// A later run on the same day replaces its value
+	record(model.InventoryMetric{Day: "2026-10-02", Name: model.MetricDevicesTotal, Value: 13})
+
+	all, err := storage.ListInventoryMetrics(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListInventoryMetrics failed: %v", err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("expected 4 metrics, got %d", len(all))
+	}
+	if all[0].Name != model.MetricDevicesTotal || all[1].Value != 13 || all[1].RecordedAt.IsZero() {
+		t.Errorf("expected device totals ordered by day with the replaced value, got %+v", all[:2])
+	}
+	// The all-pools value sorts before the per-pool one
+	if all[2].ResourceID != "" || all[3].ResourceName != "servers" {
+		t.Errorf("unexpected pool metrics %+v", all[2:])
+	}
+
+	got, err := storage.ListInventoryMetrics(ctx, &model.InventoryMetricFilter{Names: []string{model.MetricDevicesTotal}, Since: "2026-10-02"})
+	if err != nil || len(got) != 1 || got[0].Day != "2026-10-02" {
+		t.Errorf("expected the filtered metric, got %+v, %v", got, err)
+	}
+	got, err = storage.ListInventoryMetrics(ctx, &model.InventoryMetricFilter{ResourceID: "pool-1"})
+	if err != nil || len(got) != 1 || got[0].Value != 40 {
+		t.Errorf("expected the pool metric, got %+v, %v", got, err)
+	}
+
+	if err := storage.RecordInventoryMetrics(ctx, []model.InventoryMetric{{Name: model.MetricDevicesTotal}}); err == nil {
+		t.Error("expected an error for a metric without a day")
+	}
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// orphanChecks are the references reported by CheckDatabase. Foreign keys
+// guard them, but rows written while enforcement was off, by an old version
+// or from the sqlite3 shell, can still point at records that are gone.
+var orphanChecks = []struct {
+	table, column, references string
+}{
+	{"tags", "device_id", "devices"},
+	{"addresses", "device_id", "devices"},
+	{"addresses", "network_id", "networks"},
+	{"addresses", "pool_id", "network_pools"},
+	{"device_relationships", "parent_id", "devices"},
+	{"device_relationships", "child_id", "devices"},
+}
+
+// CheckDatabase runs PRAGMA integrity_check and foreign_key_check and counts
+// the orphaned tags, addresses and relationships. It only reads.
+func CheckDatabase(ctx context.Context, db *sql.DB) (*model.DBCheckReport, error) {
+	start := time.Now()
+	report := &model.DBCheckReport{
+		ForeignKeyViolations: []model.DBForeignKeyViolation{},
+		Orphans:              []model.DBOrphanCount{},
+	}
+
+	rows, err := db.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return nil, fmt.Errorf("integrity check failed: %w", err)
+	}
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.Integrity = append(report.Integrity, result)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return nil, fmt.Errorf("foreign key check failed: %w", err)
+	}
+	counts := map[[2]string]int{}
+	var order [][2]string
+	for rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		key := [2]string{table, parent}
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, key := range order {
+		report.ForeignKeyViolations = append(report.ForeignKeyViolations, model.DBForeignKeyViolation{
+			Table: key[0], Parent: key[1], Count: counts[key],
+		})
+	}
+
+	for _, c := range orphanChecks {
+		var count int
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s IS NOT NULL AND %s != '' AND %s NOT IN (SELECT id FROM %s)`,
+			c.table, c.column, c.column, c.column, c.references)
+		if err := db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count orphaned %s: %w", c.table, err)
+		}
+		report.Orphans = append(report.Orphans, model.DBOrphanCount{
+			Table: c.table, Column: c.column, References: c.references, Count: count,
+		})
+	}
+
+	report.OK = len(report.Integrity) == 1 && report.Integrity[0] == "ok" && len(report.ForeignKeyViolations) == 0
+	for _, o := range report.Orphans {
+		if o.Count > 0 {
+			report.OK = false
+		}
+	}
+	report.Duration = time.Since(start).Milliseconds()
+	return report, nil
+}
+
+// OptimizeDatabase rebuilds the database with VACUUM to reclaim free pages,
+// refreshes the query planner statistics with ANALYZE and truncates the WAL
+// the rebuild went through. Writes wait until it is done.
+func OptimizeDatabase(ctx context.Context, db *sql.DB) (*model.DBOptimizeResult, error) {
+	start := time.Now()
+	result := &model.DBOptimizeResult{}
+
+	var err error
+	if result.SizeBefore, err = databaseSize(ctx, db); err != nil {
+		return nil, err
+	}
+	for _, stmt := range []string{`VACUUM`, `ANALYZE`, `PRAGMA wal_checkpoint(TRUNCATE)`} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("%s failed: %w", stmt, err)
+		}
+	}
+	if result.SizeAfter, err = databaseSize(ctx, db); err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// databaseSize returns the size of the database in bytes
+func databaseSize(ctx context.Context, db *sql.DB) (int64, error) {
+	var pages, pageSize int64
+	if err := db.QueryRowContext(ctx, `PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := db.QueryRowContext(ctx, `PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pages * pageSize, nil
+}
+
+// CheckDatabase checks the database through the read connections
+func (s *SQLiteStorage) CheckDatabase(ctx context.Context) (*model.DBCheckReport, error) {
+	return CheckDatabase(ctx, s.reader)
+}
+
+// OptimizeDatabase optimizes the database through the writer
+func (s *SQLiteStorage) OptimizeDatabase(ctx context.Context) (*model.DBOptimizeResult, error) {
+	return OptimizeDatabase(ctx, s.db)
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckDatabase(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	report, err := store.CheckDatabase(ctx)
+	if err != nil {
+		t.Fatalf("CheckDatabase failed: %v", err)
+	}
+	if !report.OK || len(report.Integrity) != 1 || report.Integrity[0] != "ok" {
+		t.Errorf("expected a fresh database to pass, got %+v", report)
+	}
+	if len(report.Orphans) != len(orphanChecks) {
+		t.Errorf("expected %d orphan counts, got %d", len(orphanChecks), len(report.Orphans))
+	}
+
+	// Write a tag for a device that does not exist, as an old version could
+	if _, err := store.db.Exec(`PRAGMA foreign_keys = OFF`); err != nil {
+		t.Fatalf("failed to disable foreign keys: %v", err)
+	}
+	if _, err := store.db.Exec(`INSERT INTO tags (device_id, tag) VALUES ('missing', 'web'), ('missing', 'db')`); err != nil {
+		t.Fatalf("failed to insert orphaned tags: %v", err)
+	}
+	if _, err := store.db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
+		t.Fatalf("failed to enable foreign keys: %v", err)
+	}
+
+	report, err = store.CheckDatabase(ctx)
+	if err != nil {
+		t.Fatalf("CheckDatabase failed: %v", err)
+	}
+	if report.OK {
+		t.Error("expected the orphaned tags to fail the check")
+	}
+	if len(report.ForeignKeyViolations) != 1 || report.ForeignKeyViolations[0].Table != "tags" ||
+		report.ForeignKeyViolations[0].Parent != "devices" || report.ForeignKeyViolations[0].Count != 2 {
+		t.Errorf("unexpected foreign key violations: %+v", report.ForeignKeyViolations)
+	}
+	for _, o := range report.Orphans {
+		want := 0
+		if o.Table == "tags" {
+			want = 2
+		}
+		if o.Count != want {
+			t.Errorf("expected %d orphans in %s.%s, got %d", want, o.Table, o.Column, o.Count)
+		}
+	}
+}
+
+func TestOptimizeDatabase(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+
+	result, err := store.OptimizeDatabase(context.Background())
+	if err != nil {
+		t.Fatalf("OptimizeDatabase failed: %v", err)
+	}
+	if result.SizeBefore == 0 || result.SizeAfter == 0 {
+		t.Errorf("unexpected sizes: %+v", result)
+	}
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const maintenanceWindowColumns = `id, device_id, tag, starts_at, ends_at, description, created_by,
+	created_at, updated_at`
+
+// CreateMaintenanceWindow inserts a new maintenance window
+func (s *SQLiteStorage) CreateMaintenanceWindow(ctx context.Context, w *model.MaintenanceWindow) error {
+	if w.ID == "" {
+		w.ID = newUUID()
+	}
+	now := nowUTC()
+	w.CreatedAt = now
+	w.UpdatedAt = now
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO maintenance_windows (`+maintenanceWindowColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, w.ID, nullString(w.DeviceID), w.Tag, w.StartsAt.UTC(), w.EndsAt.UTC(), w.Description, w.CreatedBy,
+		w.CreatedAt, w.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	w.Status = w.StatusAt(now)
+	s.auditLog(ctx, "create", "maintenance_window", w.ID, w)
+	return nil
+}
+
+// GetMaintenanceWindow retrieves a maintenance window by ID
+func (s *SQLiteStorage) GetMaintenanceWindow(ctx context.Context, id string) (*model.MaintenanceWindow, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+maintenanceWindowColumns+` FROM maintenance_windows WHERE id = ?`, id)
+	w, err := scanMaintenanceWindow(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrMaintenanceWindowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.Status = w.StatusAt(nowUTC())
+	return w, nil
+}
+
+// ListMaintenanceWindows returns the windows matching the filter, soonest
+// start first
+func (s *SQLiteStorage) ListMaintenanceWindows(ctx context.Context, filter *model.MaintenanceWindowFilter) ([]model.MaintenanceWindow, error) {
+	query := `SELECT ` + maintenanceWindowColumns + ` FROM maintenance_windows`
+	var conditions []string
+	var args []any
+	now := nowUTC()
+
+	if filter != nil {
+		var scope []string
+		if filter.DeviceID != "" {
+			scope = append(scope, "device_id = ?")
+			args = append(args, filter.DeviceID)
+		}
+		if len(filter.Tags) > 0 {
+			scope = append(scope, "tag IN ("+placeholders(len(filter.Tags))+")")
+			for _, tag := range filter.Tags {
+				args = append(args, tag)
+			}
+		}
+		if len(scope) > 0 {
+			conditions = append(conditions, "("+strings.Join(scope, " OR ")+")")
+		}
+
+		switch filter.Status {
+		case model.MaintenanceActive:
+			conditions = append(conditions, "starts_at <= ? AND ends_at > ?")
+			args = append(args, now, now)
+		case model.MaintenanceUpcoming:
+			conditions = append(conditions, "starts_at > ?")
+			args = append(args, now)
+		case model.MaintenancePast:
+			conditions = append(conditions, "ends_at <= ?")
+			args = append(args, now)
+		case model.MaintenanceCurrent:
+			conditions = append(conditions, "ends_at > ?")
+			args = append(args, now)
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY starts_at, created_at"
+
+	var pg *model.Pagination
+	if filter != nil {
+		pg = &filter.Pagination
+	}
+	query, args = appendPagination(query, args, pg)
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []model.MaintenanceWindow{}
+	for rows.Next() {
+		w, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		w.Status = w.StatusAt(now)
+		windows = append(windows, *w)
+	}
+	return windows, rows.Err()
+}
+
+// UpdateMaintenanceWindow updates an existing maintenance window
+func (s *SQLiteStorage) UpdateMaintenanceWindow(ctx context.Context, w *model.MaintenanceWindow) error {
+	w.UpdatedAt = nowUTC()
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE maintenance_windows SET device_id = ?, tag = ?, starts_at = ?, ends_at = ?,
+			description = ?, updated_at = ?
+		WHERE id = ?
+	`, nullString(w.DeviceID), w.Tag, w.StartsAt.UTC(), w.EndsAt.UTC(), w.Description, w.UpdatedAt, w.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrMaintenanceWindowNotFound
+	}
+	w.Status = w.StatusAt(w.UpdatedAt)
+	s.auditLog(ctx, "update", "maintenance_window", w.ID, w)
+	return nil
+}
+
+// DeleteMaintenanceWindow removes a maintenance window
+func (s *SQLiteStorage) DeleteMaintenanceWindow(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM maintenance_windows WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrMaintenanceWindowNotFound
+	}
+	s.auditLog(ctx, "delete", "maintenance_window", id, nil)
+	return nil
+}
+
+func scanMaintenanceWindow(row interface{ Scan(...any) error }) (*model.MaintenanceWindow, error) {
+	var w model.MaintenanceWindow
+	var deviceID sql.NullString
+	if err := row.Scan(&w.ID, &deviceID, &w.Tag, &w.StartsAt, &w.EndsAt, &w.Description, &w.CreatedBy,
+		&w.CreatedAt, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+	w.DeviceID = deviceID.String
+	return &w, nil
+}
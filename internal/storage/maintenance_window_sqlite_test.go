@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestMaintenanceWindowCRUD(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	device := &model.Device{Name: "web1", Status: model.DeviceStatusActive}
+	if err := storage.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+
+	now := time.Now().UTC()
+	active := &model.MaintenanceWindow{DeviceID: device.ID, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Description: "Firmware"}
+	upcoming := &model.MaintenanceWindow{Tag: "rack-a", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(26 * time.Hour), CreatedBy: "user-1"}
+	past := &model.MaintenanceWindow{Tag: "rack-b", StartsAt: now.Add(-48 * time.Hour), EndsAt: now.Add(-47 * time.Hour)}
+	for _, w := range []*model.MaintenanceWindow{active, upcoming, past} {
+		if err := storage.CreateMaintenanceWindow(ctx, w); err != nil {
+			t.Fatalf("CreateMaintenanceWindow failed: %v", err)
+		}
+	}
+
+	got, err := storage.GetMaintenanceWindow(ctx, active.ID)
+	if err != nil {
+		t.Fatalf("GetMaintenanceWindow failed: %v", err)
+	}
+	if got.DeviceID != device.ID || got.Description != "Firmware" || got.Status != model.MaintenanceActive {
+		t.Errorf("window mismatch: got %+v", got)
+	}
+	if got, _ := storage.GetMaintenanceWindow(ctx, upcoming.ID); got.DeviceID != "" || got.Tag != "rack-a" || got.CreatedBy != "user-1" {
+		t.Errorf("tag window mismatch: got %+v", got)
+	}
+
+	tests := []struct {
+		name   string
+		filter model.MaintenanceWindowFilter
+		want   []string
+	}{
+		{"all", model.MaintenanceWindowFilter{}, []string{past.ID, active.ID, upcoming.ID}},
+		{"active", model.MaintenanceWindowFilter{Status: model.MaintenanceActive}, []string{active.ID}},
+		{"upcoming", model.MaintenanceWindowFilter{Status: model.MaintenanceUpcoming}, []string{upcoming.ID}},
+		{"past", model.MaintenanceWindowFilter{Status: model.MaintenancePast}, []string{past.ID}},
+		{"current", model.MaintenanceWindowFilter{Status: model.MaintenanceCurrent}, []string{active.ID, upcoming.ID}},
+		{"device or tag", model.MaintenanceWindowFilter{DeviceID: device.ID, Tags: []string{"rack-b"}}, []string{past.ID, active.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows, err := storage.ListMaintenanceWindows(ctx, &tt.filter)
+			if err != nil {
+				t.Fatalf("ListMaintenanceWindows failed: %v", err)
+			}
+			if len(windows) != len(tt.want) {
+				t.Fatalf("expected %d windows, got %+v", len(tt.want), windows)
+			}
+			for i, id := range tt.want {
+				if windows[i].ID != id {
+					t.Errorf("window %d: expected %s, got %s", i, id, windows[i].ID)
+				}
+			}
+		})
+	}
+
+	upcoming.Tag = "rack-c"
+	if err := storage.UpdateMaintenanceWindow(ctx, upcoming); err != nil {
+		t.Fatalf("UpdateMaintenanceWindow failed: %v", err)
+	}
+	if got, _ := storage.GetMaintenanceWindow(ctx, upcoming.ID); got.Tag != "rack-c" {
+		t.Errorf("expected updated tag, got %q", got.Tag)
+	}
+	if err := storage.UpdateMaintenanceWindow(ctx, &model.MaintenanceWindow{ID: "missing"}); !errors.Is(err, ErrMaintenanceWindowNotFound) {
+		t.Errorf("expected ErrMaintenanceWindowNotFound, got %v", err)
+	}
+
+	if err := storage.DeleteMaintenanceWindow(ctx, past.ID); err != nil {
+		t.Fatalf("DeleteMaintenanceWindow failed: %v", err)
+	}
+	if _, err := storage.GetMaintenanceWindow(ctx, past.ID); !errors.Is(err, ErrMaintenanceWindowNotFound) {
+		t.Errorf("expected ErrMaintenanceWindowNotFound after delete, got %v", err)
+	}
+
+	// Windows go with their device
+	if err := storage.DeleteDevice(ctx, device.ID, model.DeleteDetach); err != nil {
+		t.Fatalf("DeleteDevice failed: %v", err)
+	}
+	if _, err := storage.GetMaintenanceWindow(ctx, active.ID); !errors.Is(err, ErrMaintenanceWindowNotFound) {
+		t.Errorf("expected device window to be deleted with the device, got %v", err)
+	}
+}
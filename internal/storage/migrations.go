@@ -5,8 +5,12 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
 )
 
 // Migration represents a single database migration
@@ -28,62 +32,11 @@ type MigrationRecord struct {
 	Success         bool
 }
 
-// migrations is the ordered list of all migrations
+// migrations is the ordered list of all migrations. Schema changes that are
+// plain SQL live in migrations/ instead and are merged in by version; the ones
+// here need Go to transform existing data or, in the older ones, to seed
+// permissions with generated IDs.
 var migrations = []*Migration{
-	{
-		Version: "20240120080000",
-		Name:    "initial_schema",
-		Up:      migrateInitialSchemaUp,
-		Down:    migrateInitialSchemaDown,
-	},
-	{
-		Version: "20240121080000",
-		Name:    "add_pool_tags",
-		Up:      migrateAddPoolTagsUp,
-		Down:    migrateAddPoolTagsDown,
-	},
-	{
-		Version: "20240122080000",
-		Name:    "add_device_hostname",
-		Up:      migrateAddDeviceHostnameUp,
-		Down:    migrateAddDeviceHostnameDown,
-	},
-	{
-		Version: "20260203000000",
-		Name:    "add_relationship_notes",
-		Up:      migrateAddRelationshipNotesUp,
-		Down:    migrateAddRelationshipNotesDown,
-	},
-	{
-		Version: "20260203110000",
-		Name:    "add_fts_search",
-		Up:      migrateFTSUp,
-		Down:    migrateFTSDown,
-	},
-	{
-		Version: "20260203120000",
-		Name:    "add_api_keys",
-		Up:      migrateAddAPIKeysUp,
-		Down:    migrateAddAPIKeysDown,
-	},
-	{
-		Version: "20260203160000",
-		Name:    "add_audit_logs",
-		Up:      migrateAddAuditLogsUp,
-		Down:    migrateAddAuditLogsDown,
-	},
-	{
-		Version: "20260203170000",
-		Name:    "add_audit_source",
-		Up:      migrateAddAuditSourceUp,
-		Down:    migrateAddAuditSourceDown,
-	},
-	{
-		Version: "20260206120000",
-		Name:    "add_users",
-		Up:      migrateAddUsersUp,
-		Down:    migrateAddUsersDown,
-	},
 	{
 		Version: "20260206130000",
 		Name:    "add_rbac",
@@ -102,78 +55,30 @@ var migrations = []*Migration{
 		Up:      migrateAssignRolesToExistingAdminsUp,
 		Down:    migrateAssignRolesToExistingAdminsDown,
 	},
-	{
-		Version: "20260207120000",
-		Name:    "add_apikey_user_id",
-		Up:      migrateAddAPIKeyUserIDUp,
-		Down:    migrateAddAPIKeyUserIDDown,
-	},
-	{
-		Version: "20260210100000",
-		Name:    "add_oauth_tables",
-		Up:      migrateAddOAuthTablesUp,
-		Down:    migrateAddOAuthTablesDown,
-	},
-	{
-		Version: "20260213100000",
-		Name:    "add_conflicts",
-		Up:      migrateAddConflictsUp,
-		Down:    migrateAddConflictsDown,
-	},
 	{
 		Version: "20260213110000",
 		Name:    "add_conflict_permissions",
 		Up:      migrateAddConflictPermissionsUp,
 		Down:    migrateAddConflictPermissionsDown,
 	},
-	{
-		Version: "20260227120000",
-		Name:    "add_reservations",
-		Up:      migrateAddReservationsUp,
-		Down:    migrateAddReservationsDown,
-	},
 	{
 		Version: "20260227130000",
 		Name:    "add_reservation_permissions",
 		Up:      migrateAddReservationPermissionsUp,
 		Down:    migrateAddReservationPermissionsDown,
 	},
-	{
-		Version: "20260228000000",
-		Name:    "add_device_status",
-		Up:      migrateAddDeviceStatusUp,
-		Down:    migrateAddDeviceStatusDown,
-	},
-	{
-		Version: "20260228010000",
-		Name:    "add_utilization_snapshots",
-		Up:      migrateAddUtilizationSnapshotsUp,
-		Down:    migrateAddUtilizationSnapshotsDown,
-	},
 	{
 		Version: "20260228020000",
 		Name:    "add_dashboard_permissions",
 		Up:      migrateAddDashboardPermissionsUp,
 		Down:    migrateAddDashboardPermissionsDown,
 	},
-	{
-		Version: "20260228030000",
-		Name:    "add_webhooks",
-		Up:      migrateAddWebhooksUp,
-		Down:    migrateAddWebhooksDown,
-	},
 	{
 		Version: "20260228040000",
 		Name:    "add_webhook_permissions",
 		Up:      migrateAddWebhookPermissionsUp,
 		Down:    migrateAddWebhookPermissionsDown,
 	},
-	{
-		Version: "20260228050000",
-		Name:    "add_custom_fields",
-		Up:      migrateAddCustomFieldsUp,
-		Down:    migrateAddCustomFieldsDown,
-	},
 	{
 		Version: "20260228060000",
 		Name:    "add_custom_field_permissions",
@@ -204,42 +109,42 @@ var migrations = []*Migration{
 		Up:      migrateAddDNSProviderTestPermissionUp,
 		Down:    migrateAddDNSProviderTestPermissionDown,
 	},
-	{
-		Version: "20260302000000",
-		Name:    "rename_webhook_resource_to_plural",
-		Up:      migrateRenameWebhookResourceUp,
-		Down:    migrateRenameWebhookResourceDown,
-	},
-	{
-		Version: "20260302100000",
-		Name:    "add_dns_record_address_id",
-		Up:      migrateAddDNSRecordAddressIDUp,
-		Down:    migrateAddDNSRecordAddressIDDown,
-	},
 	{
 		Version: "20260304100000",
 		Name:    "add_discovery_update_permission",
 		Up:      migrateAddDiscoveryUpdatePermissionUp,
 		Down:    migrateAddDiscoveryUpdatePermissionDown,
 	},
-	{
-		Version: "20260304170000",
-		Name:    "add_sessions",
-		Up:      migrateAddSessionsUp,
-		Down:    migrateAddSessionsDown,
-	},
-	{
-		Version: "20260305080000",
-		Name:    "add_ssh_host_keys",
-		Up:      migrateAddSSHHostKeysUp,
-		Down:    migrateAddSSHHostKeysDown,
-	},
 	{
 		Version: "20260409100000",
 		Name:    "add_audit_and_logs_permissions",
 		Up:      migrateAddAuditAndLogsPermissionsUp,
 		Down:    migrateAddAuditAndLogsPermissionsDown,
 	},
+	{
+		Version: "20261015140000",
+		Name:    "add_resource_versions",
+		Up:      migrateAddResourceVersionsUp,
+		Down:    migrateAddResourceVersionsDown,
+	},
+	{
+		Version: "20261015170000",
+		Name:    "add_network_parent_id",
+		Up:      migrateAddNetworkParentIDUp,
+		Down:    migrateAddNetworkParentIDDown,
+	},
+	{
+		Version: "20261016140000",
+		Name:    "fix_address_types",
+		Up:      migrateFixAddressTypesUp,
+		Down:    migrateFixAddressTypesDown,
+	},
+	{
+		Version: "20261016220000",
+		Name:    "add_device_interfaces",
+		Up:      migrateAddDeviceInterfacesUp,
+		Down:    migrateAddDeviceInterfacesDown,
+	},
 }
 
 // calculateChecksum generates a checksum for a migration
@@ -251,28 +156,110 @@ func calculateChecksum(m *Migration) string {
 
 // RunMigrations runs all pending migrations
 func RunMigrations(ctx context.Context, db *sql.DB) error {
+	_, err := MigrateUp(ctx, db, false)
+	return err
+}
+
+// MigrateUp applies the pending migrations in version order and returns them.
+// With dryRun they all run in one transaction that is rolled back, so a
+// failing migration shows up without the database changing.
+func MigrateUp(ctx context.Context, db *sql.DB, dryRun bool) ([]*Migration, error) {
 	// Create migration tracking table if it doesn't exist
 	if err := createMigrationTable(ctx, db); err != nil {
-		return fmt.Errorf("failed to create migration table: %w", err)
+		return nil, fmt.Errorf("failed to create migration table: %w", err)
 	}
 
 	// Get applied migrations
 	applied, err := getAppliedMigrations(ctx, db)
 	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
-	// Run pending migrations
+	var pending []*Migration
 	for _, m := range migrations {
-		if _, ok := applied[m.Version]; ok {
-			continue // Already applied
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
 		}
+	}
 
-		if err := runMigration(ctx, db, m); err != nil {
-			return fmt.Errorf("migration %s failed: %w", m.Version, err)
+	if err := runMigrations(ctx, db, pending, dryRun, applyMigration); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first, and
+// returns them. dryRun works as it does for MigrateUp.
+func MigrateDown(ctx context.Context, db *sql.DB, steps int, dryRun bool) ([]*Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1")
+	}
+	if err := createMigrationTable(ctx, db); err != nil {
+		return nil, fmt.Errorf("failed to create migration table: %w", err)
+	}
+	applied, err := getAppliedMigrations(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	if steps > len(versions) {
+		steps = len(versions)
+	}
+
+	known := make(map[string]*Migration, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+	var reverting []*Migration
+	for _, version := range versions[:steps] {
+		m, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %s (%s) is not known to this version of rackd", version, applied[version].Name)
+		}
+		reverting = append(reverting, m)
+	}
+
+	if err := runMigrations(ctx, db, reverting, dryRun, revertMigration); err != nil {
+		return nil, err
+	}
+	return reverting, nil
+}
+
+// runMigrations runs step for each migration, each in its own transaction,
+// or all in one transaction that is rolled back for a dry run
+func runMigrations(ctx context.Context, db *sql.DB, ms []*Migration, dryRun bool, step func(context.Context, *sql.Tx, *Migration) error) error {
+	if dryRun {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		for _, m := range ms {
+			if err := step(ctx, tx, m); err != nil {
+				return fmt.Errorf("migration %s failed: %w", m.Version, err)
+			}
 		}
+		return nil
 	}
 
+	for _, m := range ms {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := step(ctx, tx, m); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s failed: %w", m.Version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.Version, err)
+		}
+	}
 	return nil
 }
 
@@ -345,16 +332,10 @@ func getAppliedMigrations(ctx context.Context, db *sql.DB) (map[string]Migration
 	return applied, rows.Err()
 }
 
-// runMigration runs a single migration within a transaction
-func runMigration(ctx context.Context, db *sql.DB, m *Migration) error {
+// applyMigration runs a migration's up step and records it
+func applyMigration(ctx context.Context, tx *sql.Tx, m *Migration) error {
 	start := time.Now()
 
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
 	// Run the migration
 	if err := m.Up(ctx, tx); err != nil {
 		return err
@@ -364,713 +345,20 @@ func runMigration(ctx context.Context, db *sql.DB, m *Migration) error {
 	duration := time.Since(start)
 	checksum := calculateChecksum(m)
 
-	_, err = tx.ExecContext(ctx, `
+	_, err := tx.ExecContext(ctx, `
 		INSERT INTO schema_migrations (version, name, applied_at, checksum, execution_time_ms, success)
 		VALUES (?, ?, ?, ?, ?, 1)
 	`, m.Version, m.Name, time.Now().UTC(), checksum, duration.Milliseconds())
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
-
-// migrateInitialSchemaUp creates all initial tables
-func migrateInitialSchemaUp(ctx context.Context, tx *sql.Tx) error {
-	// Create datacenters table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS datacenters (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			location TEXT,
-			description TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create datacenters table: %w", err)
-	}
-
-	// Create networks table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS networks (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			subnet TEXT NOT NULL,
-			vlan_id INTEGER,
-			datacenter_id TEXT,
-			description TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (datacenter_id) REFERENCES datacenters(id)
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create networks table: %w", err)
-	}
-
-	// Create network_pools table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS network_pools (
-			id TEXT PRIMARY KEY,
-			network_id TEXT NOT NULL,
-			name TEXT NOT NULL,
-			start_ip TEXT NOT NULL,
-			end_ip TEXT NOT NULL,
-			description TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create network_pools table: %w", err)
-	}
-
-	// Create devices table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS devices (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			description TEXT,
-			make_model TEXT,
-			os TEXT,
-			datacenter_id TEXT,
-			username TEXT,
-			location TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (datacenter_id) REFERENCES datacenters(id)
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create devices table: %w", err)
-	}
-
-	// Create addresses table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS addresses (
-			id TEXT PRIMARY KEY,
-			device_id TEXT NOT NULL,
-			ip TEXT NOT NULL,
-			port INTEGER,
-			type TEXT DEFAULT 'ipv4',
-			label TEXT,
-			network_id TEXT,
-			switch_port TEXT,
-			pool_id TEXT,
-			FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
-			FOREIGN KEY (network_id) REFERENCES networks(id),
-			FOREIGN KEY (pool_id) REFERENCES network_pools(id)
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create addresses table: %w", err)
-	}
-
-	// Create tags table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS tags (
-			device_id TEXT NOT NULL,
-			tag TEXT NOT NULL,
-			PRIMARY KEY (device_id, tag),
-			FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create tags table: %w", err)
-	}
-
-	// Create domains table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS domains (
-			device_id TEXT NOT NULL,
-			domain TEXT NOT NULL,
-			PRIMARY KEY (device_id, domain),
-			FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create domains table: %w", err)
-	}
-
-	// Create device_relationships table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS device_relationships (
-			parent_id TEXT NOT NULL,
-			child_id TEXT NOT NULL,
-			type TEXT NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			PRIMARY KEY (parent_id, child_id, type),
-			FOREIGN KEY (parent_id) REFERENCES devices(id) ON DELETE CASCADE,
-			FOREIGN KEY (child_id) REFERENCES devices(id) ON DELETE CASCADE
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create device_relationships table: %w", err)
-	}
-
-	// Create discovered_devices table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS discovered_devices (
-			id TEXT PRIMARY KEY,
-			ip TEXT NOT NULL,
-			mac_address TEXT,
-			hostname TEXT,
-			network_id TEXT,
-			status TEXT DEFAULT 'unknown',
-			confidence INTEGER DEFAULT 0,
-			os_guess TEXT,
-			vendor TEXT,
-			open_ports TEXT,
-			services TEXT,
-			first_seen TIMESTAMP,
-			last_seen TIMESTAMP,
-			promoted_to_device_id TEXT,
-			promoted_at TIMESTAMP,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (network_id) REFERENCES networks(id),
-			FOREIGN KEY (promoted_to_device_id) REFERENCES devices(id)
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create discovered_devices table: %w", err)
-	}
-
-	// Create discovery_scans table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS discovery_scans (
-			id TEXT PRIMARY KEY,
-			network_id TEXT,
-			status TEXT DEFAULT 'pending',
-			scan_type TEXT DEFAULT 'full',
-			total_hosts INTEGER DEFAULT 0,
-			scanned_hosts INTEGER DEFAULT 0,
-			found_hosts INTEGER DEFAULT 0,
-			progress_percent REAL DEFAULT 0,
-			error_message TEXT,
-			started_at TIMESTAMP,
-			completed_at TIMESTAMP,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (network_id) REFERENCES networks(id)
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create discovery_scans table: %w", err)
-	}
-
-	// Create discovery_rules table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS discovery_rules (
-			id TEXT PRIMARY KEY,
-			network_id TEXT UNIQUE,
-			enabled INTEGER DEFAULT 1,
-			scan_type TEXT DEFAULT 'full',
-			interval_hours INTEGER DEFAULT 24,
-			exclude_ips TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (network_id) REFERENCES networks(id)
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create discovery_rules table: %w", err)
-	}
-
-	// Create indexes for common queries
-	indexes := []string{
-		`CREATE INDEX IF NOT EXISTS idx_devices_name ON devices(name)`,
-		`CREATE INDEX IF NOT EXISTS idx_devices_datacenter ON devices(datacenter_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_addresses_device ON addresses(device_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_addresses_ip ON addresses(ip)`,
-		`CREATE INDEX IF NOT EXISTS idx_addresses_network ON addresses(network_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_addresses_pool ON addresses(pool_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_tags_device ON tags(device_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_domains_device ON domains(device_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_networks_datacenter ON networks(datacenter_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_network_pools_network ON network_pools(network_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_discovered_devices_network ON discovered_devices(network_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_discovered_devices_ip ON discovered_devices(ip)`,
-		`CREATE INDEX IF NOT EXISTS idx_discovery_scans_network ON discovery_scans(network_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_device_relationships_parent ON device_relationships(parent_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_device_relationships_child ON device_relationships(child_id)`,
-	}
-
-	for _, idx := range indexes {
-		if _, err := tx.ExecContext(ctx, idx); err != nil {
-			return fmt.Errorf("failed to create index: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// migrateInitialSchemaDown drops all tables
-func migrateInitialSchemaDown(ctx context.Context, tx *sql.Tx) error {
-	// Drop tables in reverse order of dependencies
-	tables := []string{
-		"discovery_rules",
-		"discovery_scans",
-		"discovered_devices",
-		"device_relationships",
-		"domains",
-		"tags",
-		"addresses",
-		"devices",
-		"network_pools",
-		"networks",
-		"datacenters",
-	}
-
-	for _, table := range tables {
-		if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS `+table); err != nil {
-			return fmt.Errorf("failed to drop table %s: %w", table, err)
-		}
-	}
-
-	return nil
-}
-
-// migrateAddPoolTagsUp creates the pool_tags table
-func migrateAddPoolTagsUp(ctx context.Context, tx *sql.Tx) error {
-	// Create pool_tags table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS pool_tags (
-			pool_id TEXT NOT NULL,
-			tag TEXT NOT NULL,
-			PRIMARY KEY (pool_id, tag),
-			FOREIGN KEY (pool_id) REFERENCES network_pools(id) ON DELETE CASCADE
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create pool_tags table: %w", err)
-	}
-
-	// Create index for pool_tags
-	if _, err := tx.ExecContext(ctx, `
-		CREATE INDEX IF NOT EXISTS idx_pool_tags_pool ON pool_tags(pool_id)
-	`); err != nil {
-		return fmt.Errorf("failed to create pool_tags index: %w", err)
-	}
-
-	return nil
-}
-
-// migrateAddPoolTagsDown drops the pool_tags table
-func migrateAddPoolTagsDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS pool_tags`); err != nil {
-		return fmt.Errorf("failed to drop pool_tags table: %w", err)
-	}
-	return nil
-}
-
-// migrateAddDeviceHostnameUp adds the hostname column to devices table
-func migrateAddDeviceHostnameUp(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `ALTER TABLE devices ADD COLUMN hostname TEXT DEFAULT ''`); err != nil {
-		return fmt.Errorf("failed to add hostname column: %w", err)
-	}
-	return nil
-}
-
-// migrateAddDeviceHostnameDown removes the hostname column from devices table
-func migrateAddDeviceHostnameDown(ctx context.Context, tx *sql.Tx) error {
-	// SQLite doesn't support DROP COLUMN directly, so we'd need to recreate the table
-	// For simplicity, we'll just leave the column (it's safe to have extra columns)
-	return nil
-}
-
-// migrateAddRelationshipNotesUp adds the notes column to device_relationships table
-func migrateAddRelationshipNotesUp(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `ALTER TABLE device_relationships ADD COLUMN notes TEXT DEFAULT ''`); err != nil {
-		return fmt.Errorf("failed to add notes column: %w", err)
-	}
-	return nil
-}
-
-// migrateAddRelationshipNotesDown removes the notes column from device_relationships table
-func migrateAddRelationshipNotesDown(ctx context.Context, tx *sql.Tx) error {
-	return nil
-}
-
-// migrateFTSUp creates FTS5 virtual tables for full-text search
-func migrateFTSUp(ctx context.Context, tx *sql.Tx) error {
-	// Create standalone FTS5 virtual table for devices
-	if _, err := tx.ExecContext(ctx, `
-		CREATE VIRTUAL TABLE IF NOT EXISTS devices_fts USING fts5(
-			id UNINDEXED,
-			name,
-			hostname,
-			description,
-			make_model,
-			os,
-			location
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create devices_fts table: %w", err)
-	}
-
-	// Create triggers to keep FTS table in sync
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TRIGGER IF NOT EXISTS devices_fts_insert AFTER INSERT ON devices BEGIN
-			INSERT INTO devices_fts(id, name, hostname, description, make_model, os, location)
-			VALUES (new.id, new.name, COALESCE(new.hostname, ''), COALESCE(new.description, ''), 
-				   COALESCE(new.make_model, ''), COALESCE(new.os, ''), COALESCE(new.location, ''));
-		END
-	`); err != nil {
-		return fmt.Errorf("failed to create devices_fts_insert trigger: %w", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TRIGGER IF NOT EXISTS devices_fts_delete AFTER DELETE ON devices BEGIN
-			DELETE FROM devices_fts WHERE id = old.id;
-		END
-	`); err != nil {
-		return fmt.Errorf("failed to create devices_fts_delete trigger: %w", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TRIGGER IF NOT EXISTS devices_fts_update AFTER UPDATE ON devices BEGIN
-			UPDATE devices_fts SET 
-				name = new.name,
-				hostname = COALESCE(new.hostname, ''),
-				description = COALESCE(new.description, ''),
-				make_model = COALESCE(new.make_model, ''),
-				os = COALESCE(new.os, ''),
-				location = COALESCE(new.location, '')
-			WHERE id = old.id;
-		END
-	`); err != nil {
-		return fmt.Errorf("failed to create devices_fts_update trigger: %w", err)
-	}
-
-	// Populate FTS table with existing data
-	if _, err := tx.ExecContext(ctx, `
-		INSERT INTO devices_fts(id, name, hostname, description, make_model, os, location)
-		SELECT id, name, COALESCE(hostname, ''), COALESCE(description, ''),
-			   COALESCE(make_model, ''), COALESCE(os, ''), COALESCE(location, '')
-		FROM devices
-	`); err != nil {
-		return fmt.Errorf("failed to populate devices_fts: %w", err)
-	}
-
-	// Create standalone FTS5 virtual table for networks
-	if _, err := tx.ExecContext(ctx, `
-		CREATE VIRTUAL TABLE IF NOT EXISTS networks_fts USING fts5(
-			id UNINDEXED,
-			name,
-			subnet,
-			description
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create networks_fts table: %w", err)
-	}
-
-	// Network FTS triggers
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TRIGGER IF NOT EXISTS networks_fts_insert AFTER INSERT ON networks BEGIN
-			INSERT INTO networks_fts(id, name, subnet, description)
-			VALUES (new.id, new.name, new.subnet, COALESCE(new.description, ''));
-		END
-	`); err != nil {
-		return fmt.Errorf("failed to create networks_fts_insert trigger: %w", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TRIGGER IF NOT EXISTS networks_fts_delete AFTER DELETE ON networks BEGIN
-			DELETE FROM networks_fts WHERE id = old.id;
-		END
-	`); err != nil {
-		return fmt.Errorf("failed to create networks_fts_delete trigger: %w", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TRIGGER IF NOT EXISTS networks_fts_update AFTER UPDATE ON networks BEGIN
-			UPDATE networks_fts SET 
-				name = new.name,
-				subnet = new.subnet,
-				description = COALESCE(new.description, '')
-			WHERE id = old.id;
-		END
-	`); err != nil {
-		return fmt.Errorf("failed to create networks_fts_update trigger: %w", err)
-	}
-
-	// Populate networks FTS
-	if _, err := tx.ExecContext(ctx, `
-		INSERT INTO networks_fts(id, name, subnet, description)
-		SELECT id, name, subnet, COALESCE(description, '')
-		FROM networks
-	`); err != nil {
-		return fmt.Errorf("failed to populate networks_fts: %w", err)
-	}
-
-	// Create standalone FTS5 virtual table for datacenters
-	if _, err := tx.ExecContext(ctx, `
-		CREATE VIRTUAL TABLE IF NOT EXISTS datacenters_fts USING fts5(
-			id UNINDEXED,
-			name,
-			location,
-			description
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create datacenters_fts table: %w", err)
-	}
-
-	// Datacenter FTS triggers
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TRIGGER IF NOT EXISTS datacenters_fts_insert AFTER INSERT ON datacenters BEGIN
-			INSERT INTO datacenters_fts(id, name, location, description)
-			VALUES (new.id, new.name, COALESCE(new.location, ''), COALESCE(new.description, ''));
-		END
-	`); err != nil {
-		return fmt.Errorf("failed to create datacenters_fts_insert trigger: %w", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TRIGGER IF NOT EXISTS datacenters_fts_delete AFTER DELETE ON datacenters BEGIN
-			DELETE FROM datacenters_fts WHERE id = old.id;
-		END
-	`); err != nil {
-		return fmt.Errorf("failed to create datacenters_fts_delete trigger: %w", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TRIGGER IF NOT EXISTS datacenters_fts_update AFTER UPDATE ON datacenters BEGIN
-			UPDATE datacenters_fts SET 
-				name = new.name,
-				location = COALESCE(new.location, ''),
-				description = COALESCE(new.description, '')
-			WHERE id = old.id;
-		END
-	`); err != nil {
-		return fmt.Errorf("failed to create datacenters_fts_update trigger: %w", err)
-	}
-
-	// Populate datacenters FTS
-	if _, err := tx.ExecContext(ctx, `
-		INSERT INTO datacenters_fts(id, name, location, description)
-		SELECT id, name, COALESCE(location, ''), COALESCE(description, '')
-		FROM datacenters
-	`); err != nil {
-		return fmt.Errorf("failed to populate datacenters_fts: %w", err)
-	}
-
-	return nil
-}
-
-// migrateFTSDown drops FTS5 virtual tables and triggers
-func migrateFTSDown(ctx context.Context, tx *sql.Tx) error {
-	// Drop triggers
-	for _, trigger := range []string{
-		"devices_fts_insert", "devices_fts_delete", "devices_fts_update",
-		"networks_fts_insert", "networks_fts_delete", "networks_fts_update",
-		"datacenters_fts_insert", "datacenters_fts_delete", "datacenters_fts_update",
-	} {
-		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER IF EXISTS %s", trigger)); err != nil {
-			return fmt.Errorf("failed to drop trigger %s: %w", trigger, err)
-		}
-	}
-
-	// Drop FTS tables
-	for _, table := range []string{"devices_fts", "networks_fts", "datacenters_fts"} {
-		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", table)); err != nil {
-			return fmt.Errorf("failed to drop table %s: %w", table, err)
-		}
-	}
-
-	return nil
-}
-
-// migrateAddAPIKeysUp creates the api_keys table
-func migrateAddAPIKeysUp(ctx context.Context, tx *sql.Tx) error {
-	_, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS api_keys (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL UNIQUE,
-			key TEXT NOT NULL UNIQUE,
-			description TEXT,
-			created_at DATETIME NOT NULL,
-			last_used_at DATETIME,
-			expires_at DATETIME
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create api_keys table: %w", err)
-	}
-
-	// Create indexes
-	if _, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_api_keys_key ON api_keys(key)`); err != nil {
-		return fmt.Errorf("failed to create api_keys key index: %w", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_api_keys_name ON api_keys(name)`); err != nil {
-		return fmt.Errorf("failed to create api_keys name index: %w", err)
-	}
-
-	return nil
-}
-
-// migrateAddAPIKeysDown drops the api_keys table
-func migrateAddAPIKeysDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS api_keys`); err != nil {
-		return fmt.Errorf("failed to drop api_keys table: %w", err)
-	}
-	return nil
-}
-
-// migrateAddAuditLogsUp creates the audit_logs table
-func migrateAddAuditLogsUp(ctx context.Context, tx *sql.Tx) error {
-	_, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS audit_logs (
-			id TEXT PRIMARY KEY,
-			timestamp DATETIME NOT NULL,
-			action TEXT NOT NULL,
-			resource TEXT NOT NULL,
-			resource_id TEXT,
-			user_id TEXT,
-			username TEXT,
-			ip_address TEXT,
-			changes TEXT,
-			status TEXT NOT NULL,
-			error TEXT
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create audit_logs table: %w", err)
-	}
-
-	// Create indexes for common queries
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS idx_audit_logs_timestamp ON audit_logs(timestamp DESC)",
-		"CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs(resource, resource_id)",
-		"CREATE INDEX IF NOT EXISTS idx_audit_logs_user ON audit_logs(user_id)",
-		"CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action)",
-	}
-
-	for _, idx := range indexes {
-		if _, err := tx.ExecContext(ctx, idx); err != nil {
-			return fmt.Errorf("failed to create audit_logs index: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// migrateAddAuditLogsDown drops the audit_logs table
-func migrateAddAuditLogsDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS audit_logs`); err != nil {
-		return fmt.Errorf("failed to drop audit_logs table: %w", err)
-	}
-	return nil
-}
-
-// migrateAddAuditSourceUp adds source column to audit_logs table
-func migrateAddAuditSourceUp(ctx context.Context, tx *sql.Tx) error {
-	// Add source column
-	if _, err := tx.ExecContext(ctx, `ALTER TABLE audit_logs ADD COLUMN source TEXT`); err != nil {
-		return fmt.Errorf("failed to add source column to audit_logs: %w", err)
-	}
-
-	// Create index on source column
-	if _, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_audit_logs_source ON audit_logs(source)`); err != nil {
-		return fmt.Errorf("failed to create idx_audit_logs_source index: %w", err)
-	}
-
-	return nil
-}
-
-// migrateAddAuditSourceDown removes source column from audit_logs table
-func migrateAddAuditSourceDown(ctx context.Context, tx *sql.Tx) error {
-	// Drop index first
-	if _, err := tx.ExecContext(ctx, `DROP INDEX IF EXISTS idx_audit_logs_source`); err != nil {
-		return fmt.Errorf("failed to drop idx_audit_logs_source index: %w", err)
-	}
-
-	// SQLite doesn't support ALTER TABLE DROP COLUMN directly, need to recreate table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE audit_logs_new (
-			id TEXT PRIMARY KEY,
-			timestamp DATETIME NOT NULL,
-			action TEXT NOT NULL,
-			resource TEXT NOT NULL,
-			resource_id TEXT,
-			user_id TEXT,
-			username TEXT,
-			ip_address TEXT,
-			changes TEXT,
-			status TEXT NOT NULL,
-			error TEXT
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create audit_logs_new table: %w", err)
-	}
-
-	// Copy data from old table to new table
-	if _, err := tx.ExecContext(ctx, `
-		INSERT INTO audit_logs_new (id, timestamp, action, resource, resource_id, user_id, username, ip_address, changes, status, error)
-		SELECT id, timestamp, action, resource, resource_id, user_id, username, ip_address, changes, status, error
-		FROM audit_logs
-	`); err != nil {
-		return fmt.Errorf("failed to copy data to audit_logs_new: %w", err)
-	}
-
-	// Drop old table
-	if _, err := tx.ExecContext(ctx, `DROP TABLE audit_logs`); err != nil {
-		return fmt.Errorf("failed to drop old audit_logs table: %w", err)
-	}
-
-	// Rename new table to original name
-	if _, err := tx.ExecContext(ctx, `ALTER TABLE audit_logs_new RENAME TO audit_logs`); err != nil {
-		return fmt.Errorf("failed to rename audit_logs_new to audit_logs: %w", err)
-	}
-
-	// Recreate indexes (they were dropped with the table)
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS idx_audit_logs_timestamp ON audit_logs(timestamp DESC)",
-		"CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs(resource, resource_id)",
-		"CREATE INDEX IF NOT EXISTS idx_audit_logs_user ON audit_logs(user_id)",
-		"CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action)",
-	}
-
-	for _, idx := range indexes {
-		if _, err := tx.ExecContext(ctx, idx); err != nil {
-			return fmt.Errorf("failed to recreate audit_logs index: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func migrateAddUsersUp(ctx context.Context, tx *sql.Tx) error {
-	_, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS users (
-			id TEXT PRIMARY KEY,
-			username TEXT NOT NULL UNIQUE,
-			email TEXT UNIQUE,
-			full_name TEXT,
-			password_hash TEXT NOT NULL,
-			is_active INTEGER NOT NULL DEFAULT 1,
-			is_admin INTEGER NOT NULL DEFAULT 0,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL,
-			last_login_at DATETIME
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create users table: %w", err)
-	}
-
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS idx_users_username ON users(username)",
-		"CREATE INDEX IF NOT EXISTS idx_users_email ON users(email)",
-		"CREATE INDEX IF NOT EXISTS idx_users_is_active ON users(is_active)",
-	}
-
-	for _, idx := range indexes {
-		if _, err := tx.ExecContext(ctx, idx); err != nil {
-			return fmt.Errorf("failed to create user index: %w", err)
-		}
-	}
-
-	return nil
+	return err
 }
 
-func migrateAddUsersDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS users`); err != nil {
-		return fmt.Errorf("failed to drop users table: %w", err)
+// revertMigration runs a migration's down step and removes its record
+func revertMigration(ctx context.Context, tx *sql.Tx, m *Migration) error {
+	if err := m.Down(ctx, tx); err != nil {
+		return err
 	}
-	return nil
+	_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.Version)
+	return err
 }
 
 func migrateAddRBACUp(ctx context.Context, tx *sql.Tx) error {
@@ -1415,84 +703,6 @@ func migrateAssignRolesToExistingAdminsDown(ctx context.Context, tx *sql.Tx) err
 	return nil
 }
 
-// migrateAddAPIKeyUserIDUp adds user_id column to api_keys table
-func migrateAddAPIKeyUserIDUp(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `ALTER TABLE api_keys ADD COLUMN user_id TEXT REFERENCES users(id)`); err != nil {
-		return fmt.Errorf("failed to add user_id column to api_keys: %w", err)
-	}
-	if _, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id)`); err != nil {
-		return fmt.Errorf("failed to create api_keys user_id index: %w", err)
-	}
-	return nil
-}
-
-// migrateAddAPIKeyUserIDDown removes user_id column from api_keys table
-func migrateAddAPIKeyUserIDDown(ctx context.Context, tx *sql.Tx) error {
-	// SQLite doesn't support DROP COLUMN before 3.35.0, so recreate the table
-	queries := []string{
-		`CREATE TABLE api_keys_backup AS SELECT id, name, key, description, created_at, last_used_at, expires_at FROM api_keys`,
-		`DROP TABLE api_keys`,
-		`ALTER TABLE api_keys_backup RENAME TO api_keys`,
-		`CREATE INDEX IF NOT EXISTS idx_api_keys_key ON api_keys(key)`,
-		`CREATE INDEX IF NOT EXISTS idx_api_keys_name ON api_keys(name)`,
-	}
-	for _, q := range queries {
-		if _, err := tx.ExecContext(ctx, q); err != nil {
-			return fmt.Errorf("failed to remove user_id column from api_keys: %w", err)
-		}
-	}
-	return nil
-}
-
-// migrateAddConflictsUp creates the conflicts table for tracking IP conflicts
-func migrateAddConflictsUp(ctx context.Context, tx *sql.Tx) error {
-	_, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS conflicts (
-			id TEXT PRIMARY KEY,
-			type TEXT NOT NULL,
-			status TEXT NOT NULL DEFAULT 'active',
-			description TEXT,
-			ip_address TEXT,
-			device_ids TEXT,
-			device_names TEXT,
-			network_ids TEXT,
-			network_names TEXT,
-			subnets TEXT,
-			detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			resolved_at TIMESTAMP,
-			resolved_by TEXT,
-			notes TEXT
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create conflicts table: %w", err)
-	}
-
-	// Create indexes for common queries
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS idx_conflicts_type ON conflicts(type)",
-		"CREATE INDEX IF NOT EXISTS idx_conflicts_status ON conflicts(status)",
-		"CREATE INDEX IF NOT EXISTS idx_conflicts_ip ON conflicts(ip_address)",
-		"CREATE INDEX IF NOT EXISTS idx_conflicts_detected ON conflicts(detected_at DESC)",
-	}
-
-	for _, idx := range indexes {
-		if _, err := tx.ExecContext(ctx, idx); err != nil {
-			return fmt.Errorf("failed to create conflicts index: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// migrateAddConflictsDown drops the conflicts table
-func migrateAddConflictsDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS conflicts`); err != nil {
-		return fmt.Errorf("failed to drop conflicts table: %w", err)
-	}
-	return nil
-}
-
 // migrateAddConflictPermissionsUp adds permissions for conflict management
 func migrateAddConflictPermissionsUp(ctx context.Context, tx *sql.Tx) error {
 	now := time.Now()
@@ -1569,57 +779,8 @@ func migrateAddConflictPermissionsDown(ctx context.Context, tx *sql.Tx) error {
 	}
 	for _, name := range permNames {
 		if _, err := tx.ExecContext(ctx, `DELETE FROM permissions WHERE name = ?`, name); err != nil {
-			return fmt.Errorf("failed to delete conflict permission %s: %w", name, err)
-		}
-	}
-	return nil
-}
-
-// migrateAddReservationsUp creates the reservations table
-func migrateAddReservationsUp(ctx context.Context, tx *sql.Tx) error {
-	_, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS reservations (
-			id TEXT PRIMARY KEY,
-			pool_id TEXT NOT NULL REFERENCES network_pools(id) ON DELETE CASCADE,
-			ip_address TEXT NOT NULL,
-			hostname TEXT,
-			purpose TEXT,
-			reserved_by TEXT NOT NULL,
-			reserved_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			expires_at TIMESTAMP,
-			status TEXT NOT NULL DEFAULT 'active',
-			notes TEXT,
-			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create reservations table: %w", err)
-	}
-
-	// Create indexes for common queries
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS idx_reservations_pool_id ON reservations(pool_id)",
-		"CREATE INDEX IF NOT EXISTS idx_reservations_ip_address ON reservations(pool_id, ip_address)",
-		"CREATE INDEX IF NOT EXISTS idx_reservations_status ON reservations(status)",
-		"CREATE INDEX IF NOT EXISTS idx_reservations_reserved_by ON reservations(reserved_by)",
-		"CREATE INDEX IF NOT EXISTS idx_reservations_expires_at ON reservations(expires_at)",
-		"CREATE UNIQUE INDEX IF NOT EXISTS idx_reservations_pool_ip_unique ON reservations(pool_id, ip_address) WHERE status = 'active'",
-	}
-
-	for _, idx := range indexes {
-		if _, err := tx.ExecContext(ctx, idx); err != nil {
-			return fmt.Errorf("failed to create reservations index: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// migrateAddReservationsDown drops the reservations table
-func migrateAddReservationsDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS reservations`); err != nil {
-		return fmt.Errorf("failed to drop reservations table: %w", err)
+			return fmt.Errorf("failed to delete conflict permission %s: %w", name, err)
+		}
 	}
 	return nil
 }
@@ -1706,91 +867,6 @@ func migrateAddReservationPermissionsDown(ctx context.Context, tx *sql.Tx) error
 	return nil
 }
 
-// migrateAddDeviceStatusUp adds status tracking fields to devices table
-func migrateAddDeviceStatusUp(ctx context.Context, tx *sql.Tx) error {
-	// Add status column with default 'active'
-	if _, err := tx.ExecContext(ctx, `
-		ALTER TABLE devices ADD COLUMN status TEXT NOT NULL DEFAULT 'active'
-	`); err != nil {
-		return fmt.Errorf("failed to add status column: %w", err)
-	}
-
-	// Add decommission_date column
-	if _, err := tx.ExecContext(ctx, `
-		ALTER TABLE devices ADD COLUMN decommission_date TIMESTAMP
-	`); err != nil {
-		return fmt.Errorf("failed to add decommission_date column: %w", err)
-	}
-
-	// Add status_changed_at column
-	if _, err := tx.ExecContext(ctx, `
-		ALTER TABLE devices ADD COLUMN status_changed_at TIMESTAMP
-	`); err != nil {
-		return fmt.Errorf("failed to add status_changed_at column: %w", err)
-	}
-
-	// Add status_changed_by column
-	if _, err := tx.ExecContext(ctx, `
-		ALTER TABLE devices ADD COLUMN status_changed_by TEXT
-	`); err != nil {
-		return fmt.Errorf("failed to add status_changed_by column: %w", err)
-	}
-
-	// Create index on status for filtering
-	if _, err := tx.ExecContext(ctx, `
-		CREATE INDEX IF NOT EXISTS idx_devices_status ON devices(status)
-	`); err != nil {
-		return fmt.Errorf("failed to create status index: %w", err)
-	}
-
-	return nil
-}
-
-// migrateAddDeviceStatusDown removes device status columns
-func migrateAddDeviceStatusDown(ctx context.Context, tx *sql.Tx) error {
-	// SQLite doesn't support DROP COLUMN directly in older versions
-	// We need to recreate the table without the status columns
-	// For simplicity, we'll just drop the index
-	if _, err := tx.ExecContext(ctx, `DROP INDEX IF EXISTS idx_devices_status`); err != nil {
-		return fmt.Errorf("failed to drop status index: %w", err)
-	}
-	// Note: In production SQLite, columns cannot be easily dropped
-	// The columns will remain but be unused
-	return nil
-}
-
-// migrateAddUtilizationSnapshotsUp creates the utilization_snapshots table
-func migrateAddUtilizationSnapshotsUp(ctx context.Context, tx *sql.Tx) error {
-	_, err := tx.ExecContext(ctx, `
-		CREATE TABLE utilization_snapshots (
-			id TEXT PRIMARY KEY,
-			type TEXT NOT NULL,
-			resource_id TEXT NOT NULL,
-			resource_name TEXT NOT NULL,
-			total_ips INTEGER NOT NULL,
-			used_ips INTEGER NOT NULL,
-			utilization REAL NOT NULL,
-			timestamp DATETIME NOT NULL,
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-		);
-		CREATE INDEX idx_snapshots_type_resource ON utilization_snapshots(type, resource_id);
-		CREATE INDEX idx_snapshots_timestamp ON utilization_snapshots(timestamp);
-		CREATE INDEX idx_snapshots_type_timestamp ON utilization_snapshots(type, timestamp);
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create utilization_snapshots table: %w", err)
-	}
-	return nil
-}
-
-// migrateAddUtilizationSnapshotsDown drops the utilization_snapshots table
-func migrateAddUtilizationSnapshotsDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS utilization_snapshots`); err != nil {
-		return fmt.Errorf("failed to drop utilization_snapshots table: %w", err)
-	}
-	return nil
-}
-
 // migrateAddDashboardPermissionsUp adds dashboard permissions
 func migrateAddDashboardPermissionsUp(ctx context.Context, tx *sql.Tx) error {
 	now := time.Now().UTC()
@@ -1863,79 +939,6 @@ func migrateAddDashboardPermissionsDown(ctx context.Context, tx *sql.Tx) error {
 	return nil
 }
 
-// migrateAddWebhooksUp creates the webhooks and webhook_deliveries tables
-func migrateAddWebhooksUp(ctx context.Context, tx *sql.Tx) error {
-	// Create webhooks table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS webhooks (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			url TEXT NOT NULL,
-			secret TEXT,
-			events TEXT NOT NULL,
-			active INTEGER NOT NULL DEFAULT 1,
-			description TEXT,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL,
-			created_by TEXT
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create webhooks table: %w", err)
-	}
-
-	// Create indexes for webhooks
-	if _, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_webhooks_active ON webhooks(active)`); err != nil {
-		return fmt.Errorf("failed to create webhooks active index: %w", err)
-	}
-
-	// Create webhook_deliveries table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS webhook_deliveries (
-			id TEXT PRIMARY KEY,
-			webhook_id TEXT NOT NULL,
-			event_type TEXT NOT NULL,
-			payload TEXT NOT NULL,
-			response_code INTEGER,
-			response_body TEXT,
-			error TEXT,
-			duration_ms INTEGER,
-			status TEXT NOT NULL,
-			attempt_number INTEGER NOT NULL DEFAULT 1,
-			next_retry DATETIME,
-			created_at DATETIME NOT NULL,
-			FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create webhook_deliveries table: %w", err)
-	}
-
-	// Create indexes for webhook_deliveries
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id)",
-		"CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries(status)",
-		"CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries(created_at DESC)",
-		"CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_retry ON webhook_deliveries(next_retry)",
-	}
-	for _, idx := range indexes {
-		if _, err := tx.ExecContext(ctx, idx); err != nil {
-			return fmt.Errorf("failed to create webhook_deliveries index: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// migrateAddWebhooksDown drops the webhooks and webhook_deliveries tables
-func migrateAddWebhooksDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS webhook_deliveries`); err != nil {
-		return fmt.Errorf("failed to drop webhook_deliveries table: %w", err)
-	}
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS webhooks`); err != nil {
-		return fmt.Errorf("failed to drop webhooks table: %w", err)
-	}
-	return nil
-}
-
 // migrateAddWebhookPermissionsUp adds webhook permissions
 func migrateAddWebhookPermissionsUp(ctx context.Context, tx *sql.Tx) error {
 	now := time.Now().UTC()
@@ -2014,69 +1017,6 @@ func migrateAddWebhookPermissionsDown(ctx context.Context, tx *sql.Tx) error {
 	return nil
 }
 
-// migrateAddCustomFieldsUp creates the custom field tables
-func migrateAddCustomFieldsUp(ctx context.Context, tx *sql.Tx) error {
-	// Create custom field definitions table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS custom_field_definitions (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			key TEXT NOT NULL UNIQUE,
-			type TEXT NOT NULL CHECK(type IN ('text', 'number', 'boolean', 'select')),
-			required INTEGER NOT NULL DEFAULT 0,
-			options TEXT DEFAULT '[]',
-			description TEXT DEFAULT '',
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create custom_field_definitions table: %w", err)
-	}
-
-	// Create custom field values table
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS custom_field_values (
-			id TEXT PRIMARY KEY,
-			device_id TEXT NOT NULL,
-			field_id TEXT NOT NULL,
-			string_value TEXT DEFAULT '',
-			number_value INTEGER,
-			bool_value INTEGER,
-			FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
-			FOREIGN KEY (field_id) REFERENCES custom_field_definitions(id) ON DELETE CASCADE,
-			UNIQUE(device_id, field_id)
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create custom_field_values table: %w", err)
-	}
-
-	// Create indexes
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS idx_cfv_device ON custom_field_values(device_id)",
-		"CREATE INDEX IF NOT EXISTS idx_cfv_field ON custom_field_values(field_id)",
-		"CREATE INDEX IF NOT EXISTS idx_cfv_string ON custom_field_values(string_value)",
-		"CREATE INDEX IF NOT EXISTS idx_cfd_key ON custom_field_definitions(key)",
-	}
-	for _, idx := range indexes {
-		if _, err := tx.ExecContext(ctx, idx); err != nil {
-			return fmt.Errorf("failed to create custom field index: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// migrateAddCustomFieldsDown drops the custom field tables
-func migrateAddCustomFieldsDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS custom_field_values`); err != nil {
-		return fmt.Errorf("failed to drop custom_field_values table: %w", err)
-	}
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS custom_field_definitions`); err != nil {
-		return fmt.Errorf("failed to drop custom_field_definitions table: %w", err)
-	}
-	return nil
-}
-
 // migrateAddCustomFieldPermissionsUp adds custom field permissions
 func migrateAddCustomFieldPermissionsUp(ctx context.Context, tx *sql.Tx) error {
 	now := time.Now().UTC()
@@ -2679,58 +1619,6 @@ func migrateAddDNSProviderTestPermissionDown(ctx context.Context, tx *sql.Tx) er
 	return nil
 }
 
-// migrateRenameWebhookResourceUp renames the "webhook" resource to "webhooks" for consistency
-// with the plural convention used by all other resources (devices, networks, circuits, etc.).
-func migrateRenameWebhookResourceUp(ctx context.Context, tx *sql.Tx) error {
-	// Update the resource column
-	_, err := tx.ExecContext(ctx, `UPDATE permissions SET resource = 'webhooks' WHERE resource = 'webhook'`)
-	if err != nil {
-		return fmt.Errorf("failed to rename webhook resource: %w", err)
-	}
-
-	// Update the name column (webhook:list -> webhooks:list, etc.)
-	_, err = tx.ExecContext(ctx, `UPDATE permissions SET name = REPLACE(name, 'webhook:', 'webhooks:') WHERE name LIKE 'webhook:%'`)
-	if err != nil {
-		return fmt.Errorf("failed to rename webhook permission names: %w", err)
-	}
-
-	return nil
-}
-
-// migrateRenameWebhookResourceDown reverts the resource name back to singular
-func migrateRenameWebhookResourceDown(ctx context.Context, tx *sql.Tx) error {
-	_, err := tx.ExecContext(ctx, `UPDATE permissions SET resource = 'webhook' WHERE resource = 'webhooks'`)
-	if err != nil {
-		return fmt.Errorf("failed to revert webhook resource: %w", err)
-	}
-
-	_, err = tx.ExecContext(ctx, `UPDATE permissions SET name = REPLACE(name, 'webhooks:', 'webhook:') WHERE name LIKE 'webhooks:%'`)
-	if err != nil {
-		return fmt.Errorf("failed to revert webhook permission names: %w", err)
-	}
-
-	return nil
-}
-
-func migrateAddDNSRecordAddressIDUp(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `ALTER TABLE dns_records ADD COLUMN address_id TEXT REFERENCES addresses(id) ON DELETE SET NULL`); err != nil {
-		return fmt.Errorf("failed to add address_id column to dns_records: %w", err)
-	}
-	if _, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_dns_records_address ON dns_records(address_id)`); err != nil {
-		return fmt.Errorf("failed to create index on dns_records.address_id: %w", err)
-	}
-	return nil
-}
-
-func migrateAddDNSRecordAddressIDDown(ctx context.Context, tx *sql.Tx) error {
-	// SQLite doesn't support DROP COLUMN directly, so we'd need to recreate the table
-	// For simplicity, we'll just leave the column (it's safe to have extra columns)
-	if _, err := tx.ExecContext(ctx, `DROP INDEX IF EXISTS idx_dns_records_address`); err != nil {
-		return fmt.Errorf("failed to drop index idx_dns_records_address: %w", err)
-	}
-	return nil
-}
-
 // migrateAddDiscoveryUpdatePermissionUp adds the missing discovery:update permission
 func migrateAddDiscoveryUpdatePermissionUp(ctx context.Context, tx *sql.Tx) error {
 	now := time.Now()
@@ -2807,45 +1695,6 @@ func migrateAddDiscoveryUpdatePermissionDown(ctx context.Context, tx *sql.Tx) er
 	return nil
 }
 
-// migrateAddSessionsUp creates the sessions table
-func migrateAddSessionsUp(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS sessions (
-			token TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL,
-			username TEXT NOT NULL,
-			is_admin INTEGER NOT NULL DEFAULT 0,
-			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			expires_at TIMESTAMP NOT NULL,
-			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create sessions table: %w", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, `
-		CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_id)
-	`); err != nil {
-		return fmt.Errorf("failed to create sessions user index: %w", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, `
-		CREATE INDEX IF NOT EXISTS idx_sessions_expires ON sessions(expires_at)
-	`); err != nil {
-		return fmt.Errorf("failed to create sessions expires index: %w", err)
-	}
-
-	return nil
-}
-
-// migrateAddSessionsDown drops the sessions table
-func migrateAddSessionsDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS sessions`); err != nil {
-		return fmt.Errorf("failed to drop sessions table: %w", err)
-	}
-	return nil
-}
-
 func migrateAddAuditAndLogsPermissionsUp(ctx context.Context, tx *sql.Tx) error {
 	now := time.Now()
 
@@ -2917,25 +1766,277 @@ func migrateAddAuditAndLogsPermissionsDown(ctx context.Context, tx *sql.Tx) erro
 	return nil
 }
 
-// migrateAddSSHHostKeysUp creates the ssh_host_keys table
-func migrateAddSSHHostKeysUp(ctx context.Context, tx *sql.Tx) error {
+// migrateAddResourceVersionsUp creates the version history of networks and
+// pools and seeds it with their current state, so as_of queries work from the
+// last change before the upgrade onwards
+func migrateAddResourceVersionsUp(ctx context.Context, tx *sql.Tx) error {
 	if _, err := tx.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS ssh_host_keys (
-			host TEXT PRIMARY KEY,
-			key_data BLOB NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		CREATE TABLE IF NOT EXISTS resource_versions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			resource TEXT NOT NULL,
+			resource_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			data TEXT NOT NULL DEFAULT '',
+			changed_by TEXT NOT NULL DEFAULT '',
+			changed_at TIMESTAMP NOT NULL
 		)
 	`); err != nil {
-		return fmt.Errorf("failed to create ssh_host_keys table: %w", err)
+		return fmt.Errorf("failed to create resource_versions table: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_resource_versions_lookup ON resource_versions(resource, resource_id, changed_at)`); err != nil {
+		return fmt.Errorf("failed to create resource_versions index: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, name, subnet, vlan_id, datacenter_id, description, created_at, updated_at FROM networks`)
+	if err != nil {
+		return fmt.Errorf("failed to read networks: %w", err)
+	}
+	var networks []model.Network
+	for rows.Next() {
+		var n model.Network
+		var vlanID sql.NullInt64
+		var datacenterID sql.NullString
+		if err := rows.Scan(&n.ID, &n.Name, &n.Subnet, &vlanID, &datacenterID, &n.Description, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan network: %w", err)
+		}
+		n.VLANID = int(vlanID.Int64)
+		n.DatacenterID = datacenterID.String
+		networks = append(networks, n)
+	}
+	rows.Close()
+
+	rows, err = tx.QueryContext(ctx, `SELECT id, network_id, name, start_ip, end_ip, description, created_at, updated_at FROM network_pools`)
+	if err != nil {
+		return fmt.Errorf("failed to read network pools: %w", err)
+	}
+	var pools []model.NetworkPool
+	for rows.Next() {
+		var p model.NetworkPool
+		if err := rows.Scan(&p.ID, &p.NetworkID, &p.Name, &p.StartIP, &p.EndIP, &p.Description, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan network pool: %w", err)
+		}
+		p.Tags = []string{}
+		pools = append(pools, p)
+	}
+	rows.Close()
+
+	for i := range pools {
+		tagRows, err := tx.QueryContext(ctx, `SELECT tag FROM pool_tags WHERE pool_id = ?`, pools[i].ID)
+		if err != nil {
+			return fmt.Errorf("failed to read pool tags: %w", err)
+		}
+		for tagRows.Next() {
+			var tag string
+			if err := tagRows.Scan(&tag); err != nil {
+				tagRows.Close()
+				return fmt.Errorf("failed to scan pool tag: %w", err)
+			}
+			pools[i].Tags = append(pools[i].Tags, tag)
+		}
+		tagRows.Close()
+	}
+
+	insert := `INSERT INTO resource_versions (resource, resource_id, action, data, changed_at) VALUES (?, ?, 'create', ?, ?)`
+	for _, n := range networks {
+		data, _ := json.Marshal(n)
+		if _, err := tx.ExecContext(ctx, insert, "network", n.ID, string(data), n.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to seed network version: %w", err)
+		}
+	}
+	for _, p := range pools {
+		data, _ := json.Marshal(p)
+		if _, err := tx.ExecContext(ctx, insert, "pool", p.ID, string(data), p.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to seed pool version: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateAddResourceVersionsDown drops the version history
+func migrateAddResourceVersionsDown(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS resource_versions`); err != nil {
+		return fmt.Errorf("failed to drop resource_versions table: %w", err)
+	}
+	return nil
+}
+
+// migrateAddNetworkParentIDUp adds the supernet link to networks and computes it
+// for the networks that already exist
+func migrateAddNetworkParentIDUp(ctx context.Context, tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE networks ADD COLUMN parent_id TEXT REFERENCES networks(id) ON DELETE SET NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_networks_parent_id ON networks(parent_id)`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to add network parent column: %w", err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT datacenter_id FROM networks`)
+	if err != nil {
+		return fmt.Errorf("failed to list network datacenters: %w", err)
+	}
+	var datacenterIDs []string
+	for rows.Next() {
+		var datacenterID sql.NullString
+		if err := rows.Scan(&datacenterID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan network datacenter: %w", err)
+		}
+		datacenterIDs = append(datacenterIDs, datacenterID.String)
+	}
+	rows.Close()
+
+	// Networks cannot be trashed yet at this version, so there is no
+	// deleted_at to filter on
+	for _, datacenterID := range datacenterIDs {
+		if err := relinkNetworksWhere(ctx, tx, `datacenter_id IS ?`, nullString(datacenterID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateAddNetworkParentIDDown removes the supernet link
+func migrateAddNetworkParentIDDown(ctx context.Context, tx *sql.Tx) error {
+	statements := []string{
+		`DROP INDEX IF EXISTS idx_networks_parent_id`,
+		`ALTER TABLE networks DROP COLUMN parent_id`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to remove network parent column: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateFixAddressTypesUp sets the type of addresses with no type, or with
+// the type of the other family, to the family of their IP. Free-form types
+// are left alone.
+func migrateFixAddressTypesUp(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, ip, COALESCE(type, '') FROM addresses
+		WHERE type IS NULL OR type IN ('', 'ipv4', 'ipv6')
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to list addresses: %w", err)
+	}
+	fixes := map[string]string{}
+	for rows.Next() {
+		var id, ip, addrType string
+		if err := rows.Scan(&id, &ip, &addrType); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan address: %w", err)
+		}
+		if family := model.AddressFamily(ip); family != "" && family != addrType {
+			fixes[id] = family
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, family := range fixes {
+		if _, err := tx.ExecContext(ctx, `UPDATE addresses SET type = ? WHERE id = ?`, family, id); err != nil {
+			return fmt.Errorf("failed to fix address type: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateFixAddressTypesDown leaves the corrected types in place, as the
+// mislabeled ones are not worth restoring
+func migrateFixAddressTypesDown(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+
+// migrateAddDeviceInterfacesUp creates device interfaces and moves the
+// addresses of each device onto a default interface. The default interface
+// takes the MAC and switch port its addresses agree on, if any.
+func migrateAddDeviceInterfacesUp(ctx context.Context, tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS device_interfaces (
+			id TEXT PRIMARY KEY,
+			device_id TEXT NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			type TEXT NOT NULL DEFAULT 'ethernet',
+			mac_address TEXT,
+			speed_mbps INTEGER NOT NULL DEFAULT 0,
+			switch_port TEXT,
+			vlan INTEGER NOT NULL DEFAULT 0,
+			parent_id TEXT REFERENCES device_interfaces(id) ON DELETE SET NULL DEFERRABLE INITIALLY DEFERRED,
+			UNIQUE (device_id, name)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_device_interfaces_mac ON device_interfaces(mac_address)`,
+		`ALTER TABLE addresses ADD COLUMN interface_id TEXT REFERENCES device_interfaces(id) ON DELETE SET NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_addresses_interface_id ON addresses(interface_id)`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to add device interfaces: %w", err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT device_id,
+		       CASE WHEN COUNT(DISTINCT mac_address) = 1 THEN MAX(mac_address) END,
+		       CASE WHEN COUNT(DISTINCT switch_port) = 1 THEN MAX(switch_port) END
+		FROM addresses GROUP BY device_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to list device addresses: %w", err)
+	}
+	type defaultInterface struct {
+		deviceID        string
+		mac, switchPort sql.NullString
+	}
+	var defaults []defaultInterface
+	for rows.Next() {
+		var d defaultInterface
+		if err := rows.Scan(&d.deviceID, &d.mac, &d.switchPort); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan device addresses: %w", err)
+		}
+		defaults = append(defaults, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range defaults {
+		id := newUUID()
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO device_interfaces (id, device_id, name, type, mac_address, switch_port)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, id, d.deviceID, model.DefaultInterfaceName, model.InterfaceTypeEthernet, d.mac, d.switchPort); err != nil {
+			return fmt.Errorf("failed to create default interface: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE addresses SET interface_id = ? WHERE device_id = ?`, id, d.deviceID); err != nil {
+			return fmt.Errorf("failed to move addresses to default interface: %w", err)
+		}
 	}
 	return nil
 }
 
-// migrateAddSSHHostKeysDown drops the ssh_host_keys table
-func migrateAddSSHHostKeysDown(ctx context.Context, tx *sql.Tx) error {
-	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS ssh_host_keys`); err != nil {
-		return fmt.Errorf("failed to drop ssh_host_keys table: %w", err)
+// migrateAddDeviceInterfacesDown drops device interfaces. Addresses keep
+// their own MAC and switch port, so nothing else is lost.
+func migrateAddDeviceInterfacesDown(ctx context.Context, tx *sql.Tx) error {
+	statements := []string{
+		`DROP INDEX IF EXISTS idx_addresses_interface_id`,
+		`ALTER TABLE addresses DROP COLUMN interface_id`,
+		`DROP TABLE IF EXISTS device_interfaces`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to drop device interfaces: %w", err)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,13 @@
+-- Drops all tables in reverse order of dependencies
+
+DROP TABLE IF EXISTS discovery_rules;
+DROP TABLE IF EXISTS discovery_scans;
+DROP TABLE IF EXISTS discovered_devices;
+DROP TABLE IF EXISTS device_relationships;
+DROP TABLE IF EXISTS domains;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS addresses;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS network_pools;
+DROP TABLE IF EXISTS networks;
+DROP TABLE IF EXISTS datacenters;
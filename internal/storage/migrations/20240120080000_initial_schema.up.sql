@@ -0,0 +1,168 @@
+-- Creates all initial tables
+
+CREATE TABLE IF NOT EXISTS datacenters (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	location TEXT,
+	description TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS networks (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	subnet TEXT NOT NULL,
+	vlan_id INTEGER,
+	datacenter_id TEXT,
+	description TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (datacenter_id) REFERENCES datacenters(id)
+);
+
+CREATE TABLE IF NOT EXISTS network_pools (
+	id TEXT PRIMARY KEY,
+	network_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	start_ip TEXT NOT NULL,
+	end_ip TEXT NOT NULL,
+	description TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS devices (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	description TEXT,
+	make_model TEXT,
+	os TEXT,
+	datacenter_id TEXT,
+	username TEXT,
+	location TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (datacenter_id) REFERENCES datacenters(id)
+);
+
+CREATE TABLE IF NOT EXISTS addresses (
+	id TEXT PRIMARY KEY,
+	device_id TEXT NOT NULL,
+	ip TEXT NOT NULL,
+	port INTEGER,
+	type TEXT DEFAULT 'ipv4',
+	label TEXT,
+	network_id TEXT,
+	switch_port TEXT,
+	pool_id TEXT,
+	FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
+	FOREIGN KEY (network_id) REFERENCES networks(id),
+	FOREIGN KEY (pool_id) REFERENCES network_pools(id)
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+	device_id TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (device_id, tag),
+	FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS domains (
+	device_id TEXT NOT NULL,
+	domain TEXT NOT NULL,
+	PRIMARY KEY (device_id, domain),
+	FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS device_relationships (
+	parent_id TEXT NOT NULL,
+	child_id TEXT NOT NULL,
+	type TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (parent_id, child_id, type),
+	FOREIGN KEY (parent_id) REFERENCES devices(id) ON DELETE CASCADE,
+	FOREIGN KEY (child_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS discovered_devices (
+	id TEXT PRIMARY KEY,
+	ip TEXT NOT NULL,
+	mac_address TEXT,
+	hostname TEXT,
+	network_id TEXT,
+	status TEXT DEFAULT 'unknown',
+	confidence INTEGER DEFAULT 0,
+	os_guess TEXT,
+	vendor TEXT,
+	open_ports TEXT,
+	services TEXT,
+	first_seen TIMESTAMP,
+	last_seen TIMESTAMP,
+	promoted_to_device_id TEXT,
+	promoted_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (network_id) REFERENCES networks(id),
+	FOREIGN KEY (promoted_to_device_id) REFERENCES devices(id)
+);
+
+CREATE TABLE IF NOT EXISTS discovery_scans (
+	id TEXT PRIMARY KEY,
+	network_id TEXT,
+	status TEXT DEFAULT 'pending',
+	scan_type TEXT DEFAULT 'full',
+	total_hosts INTEGER DEFAULT 0,
+	scanned_hosts INTEGER DEFAULT 0,
+	found_hosts INTEGER DEFAULT 0,
+	progress_percent REAL DEFAULT 0,
+	error_message TEXT,
+	started_at TIMESTAMP,
+	completed_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (network_id) REFERENCES networks(id)
+);
+
+CREATE TABLE IF NOT EXISTS discovery_rules (
+	id TEXT PRIMARY KEY,
+	network_id TEXT UNIQUE,
+	enabled INTEGER DEFAULT 1,
+	scan_type TEXT DEFAULT 'full',
+	interval_hours INTEGER DEFAULT 24,
+	exclude_ips TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (network_id) REFERENCES networks(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_devices_name ON devices(name);
+
+CREATE INDEX IF NOT EXISTS idx_devices_datacenter ON devices(datacenter_id);
+
+CREATE INDEX IF NOT EXISTS idx_addresses_device ON addresses(device_id);
+
+CREATE INDEX IF NOT EXISTS idx_addresses_ip ON addresses(ip);
+
+CREATE INDEX IF NOT EXISTS idx_addresses_network ON addresses(network_id);
+
+CREATE INDEX IF NOT EXISTS idx_addresses_pool ON addresses(pool_id);
+
+CREATE INDEX IF NOT EXISTS idx_tags_device ON tags(device_id);
+
+CREATE INDEX IF NOT EXISTS idx_domains_device ON domains(device_id);
+
+CREATE INDEX IF NOT EXISTS idx_networks_datacenter ON networks(datacenter_id);
+
+CREATE INDEX IF NOT EXISTS idx_network_pools_network ON network_pools(network_id);
+
+CREATE INDEX IF NOT EXISTS idx_discovered_devices_network ON discovered_devices(network_id);
+
+CREATE INDEX IF NOT EXISTS idx_discovered_devices_ip ON discovered_devices(ip);
+
+CREATE INDEX IF NOT EXISTS idx_discovery_scans_network ON discovery_scans(network_id);
+
+CREATE INDEX IF NOT EXISTS idx_device_relationships_parent ON device_relationships(parent_id);
+
+CREATE INDEX IF NOT EXISTS idx_device_relationships_child ON device_relationships(child_id);
@@ -0,0 +1,3 @@
+-- Drops the pool_tags table
+
+DROP TABLE IF EXISTS pool_tags;
@@ -0,0 +1,10 @@
+-- Creates the pool_tags table
+
+CREATE TABLE IF NOT EXISTS pool_tags (
+	pool_id TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (pool_id, tag),
+	FOREIGN KEY (pool_id) REFERENCES network_pools(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_tags_pool ON pool_tags(pool_id);
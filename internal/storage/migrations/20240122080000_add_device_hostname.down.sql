@@ -0,0 +1,3 @@
+-- Removes the hostname column from devices table
+
+ALTER TABLE devices DROP COLUMN hostname;
@@ -0,0 +1,3 @@
+-- Adds the hostname column to devices table
+
+ALTER TABLE devices ADD COLUMN hostname TEXT DEFAULT '';
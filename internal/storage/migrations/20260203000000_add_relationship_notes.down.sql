@@ -0,0 +1,3 @@
+-- Removes the notes column from device_relationships table
+
+ALTER TABLE device_relationships DROP COLUMN notes;
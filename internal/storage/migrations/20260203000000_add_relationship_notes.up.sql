@@ -0,0 +1,3 @@
+-- Adds the notes column to device_relationships table
+
+ALTER TABLE device_relationships ADD COLUMN notes TEXT DEFAULT '';
@@ -0,0 +1,15 @@
+-- Drops FTS5 virtual tables and triggers
+
+DROP TRIGGER IF EXISTS devices_fts_insert;
+DROP TRIGGER IF EXISTS devices_fts_delete;
+DROP TRIGGER IF EXISTS devices_fts_update;
+DROP TRIGGER IF EXISTS networks_fts_insert;
+DROP TRIGGER IF EXISTS networks_fts_delete;
+DROP TRIGGER IF EXISTS networks_fts_update;
+DROP TRIGGER IF EXISTS datacenters_fts_insert;
+DROP TRIGGER IF EXISTS datacenters_fts_delete;
+DROP TRIGGER IF EXISTS datacenters_fts_update;
+
+DROP TABLE IF EXISTS devices_fts;
+DROP TABLE IF EXISTS networks_fts;
+DROP TABLE IF EXISTS datacenters_fts;
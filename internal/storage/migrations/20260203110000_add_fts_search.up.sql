@@ -0,0 +1,93 @@
+-- Creates FTS5 virtual tables for full-text search
+
+CREATE VIRTUAL TABLE IF NOT EXISTS devices_fts USING fts5(
+	id UNINDEXED,
+	name,
+	hostname,
+	description,
+	make_model,
+	os,
+	location
+);
+
+CREATE TRIGGER IF NOT EXISTS devices_fts_insert AFTER INSERT ON devices BEGIN
+	INSERT INTO devices_fts(id, name, hostname, description, make_model, os, location)
+	VALUES (new.id, new.name, COALESCE(new.hostname, ''), COALESCE(new.description, ''), 
+		   COALESCE(new.make_model, ''), COALESCE(new.os, ''), COALESCE(new.location, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS devices_fts_delete AFTER DELETE ON devices BEGIN
+	DELETE FROM devices_fts WHERE id = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS devices_fts_update AFTER UPDATE ON devices BEGIN
+	UPDATE devices_fts SET 
+		name = new.name,
+		hostname = COALESCE(new.hostname, ''),
+		description = COALESCE(new.description, ''),
+		make_model = COALESCE(new.make_model, ''),
+		os = COALESCE(new.os, ''),
+		location = COALESCE(new.location, '')
+	WHERE id = old.id;
+END;
+
+INSERT INTO devices_fts(id, name, hostname, description, make_model, os, location)
+SELECT id, name, COALESCE(hostname, ''), COALESCE(description, ''),
+	   COALESCE(make_model, ''), COALESCE(os, ''), COALESCE(location, '')
+FROM devices;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS networks_fts USING fts5(
+	id UNINDEXED,
+	name,
+	subnet,
+	description
+);
+
+CREATE TRIGGER IF NOT EXISTS networks_fts_insert AFTER INSERT ON networks BEGIN
+	INSERT INTO networks_fts(id, name, subnet, description)
+	VALUES (new.id, new.name, new.subnet, COALESCE(new.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS networks_fts_delete AFTER DELETE ON networks BEGIN
+	DELETE FROM networks_fts WHERE id = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS networks_fts_update AFTER UPDATE ON networks BEGIN
+	UPDATE networks_fts SET 
+		name = new.name,
+		subnet = new.subnet,
+		description = COALESCE(new.description, '')
+	WHERE id = old.id;
+END;
+
+INSERT INTO networks_fts(id, name, subnet, description)
+SELECT id, name, subnet, COALESCE(description, '')
+FROM networks;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS datacenters_fts USING fts5(
+	id UNINDEXED,
+	name,
+	location,
+	description
+);
+
+CREATE TRIGGER IF NOT EXISTS datacenters_fts_insert AFTER INSERT ON datacenters BEGIN
+	INSERT INTO datacenters_fts(id, name, location, description)
+	VALUES (new.id, new.name, COALESCE(new.location, ''), COALESCE(new.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS datacenters_fts_delete AFTER DELETE ON datacenters BEGIN
+	DELETE FROM datacenters_fts WHERE id = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS datacenters_fts_update AFTER UPDATE ON datacenters BEGIN
+	UPDATE datacenters_fts SET 
+		name = new.name,
+		location = COALESCE(new.location, ''),
+		description = COALESCE(new.description, '')
+	WHERE id = old.id;
+END;
+
+INSERT INTO datacenters_fts(id, name, location, description)
+SELECT id, name, COALESCE(location, ''), COALESCE(description, '')
+FROM datacenters;
@@ -0,0 +1,3 @@
+-- Drops the api_keys table
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,15 @@
+-- Creates the api_keys table
+
+CREATE TABLE IF NOT EXISTS api_keys (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	key TEXT NOT NULL UNIQUE,
+	description TEXT,
+	created_at DATETIME NOT NULL,
+	last_used_at DATETIME,
+	expires_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_key ON api_keys(key);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_name ON api_keys(name);
@@ -0,0 +1,3 @@
+-- Drops the audit_logs table
+
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,23 @@
+-- Creates the audit_logs table
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id TEXT PRIMARY KEY,
+	timestamp DATETIME NOT NULL,
+	action TEXT NOT NULL,
+	resource TEXT NOT NULL,
+	resource_id TEXT,
+	user_id TEXT,
+	username TEXT,
+	ip_address TEXT,
+	changes TEXT,
+	status TEXT NOT NULL,
+	error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_timestamp ON audit_logs(timestamp DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs(resource, resource_id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user ON audit_logs(user_id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
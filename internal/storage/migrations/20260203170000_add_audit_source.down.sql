@@ -0,0 +1,5 @@
+-- Removes source column from audit_logs table
+
+DROP INDEX IF EXISTS idx_audit_logs_source;
+
+ALTER TABLE audit_logs DROP COLUMN source;
@@ -0,0 +1,5 @@
+-- Adds source column to audit_logs table
+
+ALTER TABLE audit_logs ADD COLUMN source TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_source ON audit_logs(source);
@@ -0,0 +1,3 @@
+-- Drops the users table
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,20 @@
+-- Creates the users table
+
+CREATE TABLE IF NOT EXISTS users (
+	id TEXT PRIMARY KEY,
+	username TEXT NOT NULL UNIQUE,
+	email TEXT UNIQUE,
+	full_name TEXT,
+	password_hash TEXT NOT NULL,
+	is_active INTEGER NOT NULL DEFAULT 1,
+	is_admin INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	last_login_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
+
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+
+CREATE INDEX IF NOT EXISTS idx_users_is_active ON users(is_active);
@@ -0,0 +1,5 @@
+-- Removes user_id column from api_keys table
+
+DROP INDEX IF EXISTS idx_api_keys_user_id;
+
+ALTER TABLE api_keys DROP COLUMN user_id;
@@ -0,0 +1,5 @@
+-- Adds user_id column to api_keys table
+
+ALTER TABLE api_keys ADD COLUMN user_id TEXT REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
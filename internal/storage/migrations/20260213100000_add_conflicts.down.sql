@@ -0,0 +1,3 @@
+-- Drops the conflicts table
+
+DROP TABLE IF EXISTS conflicts;
@@ -0,0 +1,26 @@
+-- Creates the conflicts table for tracking IP conflicts
+
+CREATE TABLE IF NOT EXISTS conflicts (
+	id TEXT PRIMARY KEY,
+	type TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'active',
+	description TEXT,
+	ip_address TEXT,
+	device_ids TEXT,
+	device_names TEXT,
+	network_ids TEXT,
+	network_names TEXT,
+	subnets TEXT,
+	detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	resolved_at TIMESTAMP,
+	resolved_by TEXT,
+	notes TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_conflicts_type ON conflicts(type);
+
+CREATE INDEX IF NOT EXISTS idx_conflicts_status ON conflicts(status);
+
+CREATE INDEX IF NOT EXISTS idx_conflicts_ip ON conflicts(ip_address);
+
+CREATE INDEX IF NOT EXISTS idx_conflicts_detected ON conflicts(detected_at DESC);
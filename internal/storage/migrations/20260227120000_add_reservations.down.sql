@@ -0,0 +1,3 @@
+-- Drops the reservations table
+
+DROP TABLE IF EXISTS reservations;
@@ -0,0 +1,28 @@
+-- Creates the reservations table
+
+CREATE TABLE IF NOT EXISTS reservations (
+	id TEXT PRIMARY KEY,
+	pool_id TEXT NOT NULL REFERENCES network_pools(id) ON DELETE CASCADE,
+	ip_address TEXT NOT NULL,
+	hostname TEXT,
+	purpose TEXT,
+	reserved_by TEXT NOT NULL,
+	reserved_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	expires_at TIMESTAMP,
+	status TEXT NOT NULL DEFAULT 'active',
+	notes TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_pool_id ON reservations(pool_id);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_ip_address ON reservations(pool_id, ip_address);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_status ON reservations(status);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_reserved_by ON reservations(reserved_by);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_expires_at ON reservations(expires_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reservations_pool_ip_unique ON reservations(pool_id, ip_address) WHERE status = 'active';
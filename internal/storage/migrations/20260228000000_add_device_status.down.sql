@@ -0,0 +1,8 @@
+-- Removes device status columns
+
+DROP INDEX IF EXISTS idx_devices_status;
+
+ALTER TABLE devices DROP COLUMN status;
+ALTER TABLE devices DROP COLUMN decommission_date;
+ALTER TABLE devices DROP COLUMN status_changed_at;
+ALTER TABLE devices DROP COLUMN status_changed_by;
@@ -0,0 +1,11 @@
+-- Adds status tracking fields to devices table
+
+ALTER TABLE devices ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+
+ALTER TABLE devices ADD COLUMN decommission_date TIMESTAMP;
+
+ALTER TABLE devices ADD COLUMN status_changed_at TIMESTAMP;
+
+ALTER TABLE devices ADD COLUMN status_changed_by TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_devices_status ON devices(status);
@@ -0,0 +1,3 @@
+-- Drops the utilization_snapshots table
+
+DROP TABLE IF EXISTS utilization_snapshots;
@@ -0,0 +1,16 @@
+-- Creates the utilization_snapshots table
+
+CREATE TABLE utilization_snapshots (
+	id TEXT PRIMARY KEY,
+	type TEXT NOT NULL,
+	resource_id TEXT NOT NULL,
+	resource_name TEXT NOT NULL,
+	total_ips INTEGER NOT NULL,
+	used_ips INTEGER NOT NULL,
+	utilization REAL NOT NULL,
+	timestamp DATETIME NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_snapshots_type_resource ON utilization_snapshots(type, resource_id);
+CREATE INDEX idx_snapshots_timestamp ON utilization_snapshots(timestamp);
+CREATE INDEX idx_snapshots_type_timestamp ON utilization_snapshots(type, timestamp);
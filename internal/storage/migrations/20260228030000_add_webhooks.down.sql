@@ -0,0 +1,5 @@
+-- Drops the webhooks and webhook_deliveries tables
+
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,40 @@
+-- Creates the webhooks and webhook_deliveries tables
+
+CREATE TABLE IF NOT EXISTS webhooks (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	url TEXT NOT NULL,
+	secret TEXT,
+	events TEXT NOT NULL,
+	active INTEGER NOT NULL DEFAULT 1,
+	description TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	created_by TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_active ON webhooks(active);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id TEXT PRIMARY KEY,
+	webhook_id TEXT NOT NULL,
+	event_type TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	response_code INTEGER,
+	response_body TEXT,
+	error TEXT,
+	duration_ms INTEGER,
+	status TEXT NOT NULL,
+	attempt_number INTEGER NOT NULL DEFAULT 1,
+	next_retry DATETIME,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries(status);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries(created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_retry ON webhook_deliveries(next_retry);
@@ -0,0 +1,5 @@
+-- Drops the custom field tables
+
+DROP TABLE IF EXISTS custom_field_values;
+
+DROP TABLE IF EXISTS custom_field_definitions;
@@ -0,0 +1,33 @@
+-- Creates the custom field tables
+
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	key TEXT NOT NULL UNIQUE,
+	type TEXT NOT NULL CHECK(type IN ('text', 'number', 'boolean', 'select')),
+	required INTEGER NOT NULL DEFAULT 0,
+	options TEXT DEFAULT '[]',
+	description TEXT DEFAULT '',
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS custom_field_values (
+	id TEXT PRIMARY KEY,
+	device_id TEXT NOT NULL,
+	field_id TEXT NOT NULL,
+	string_value TEXT DEFAULT '',
+	number_value INTEGER,
+	bool_value INTEGER,
+	FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
+	FOREIGN KEY (field_id) REFERENCES custom_field_definitions(id) ON DELETE CASCADE,
+	UNIQUE(device_id, field_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cfv_device ON custom_field_values(device_id);
+
+CREATE INDEX IF NOT EXISTS idx_cfv_field ON custom_field_values(field_id);
+
+CREATE INDEX IF NOT EXISTS idx_cfv_string ON custom_field_values(string_value);
+
+CREATE INDEX IF NOT EXISTS idx_cfd_key ON custom_field_definitions(key);
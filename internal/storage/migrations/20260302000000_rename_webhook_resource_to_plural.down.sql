@@ -0,0 +1,5 @@
+-- Reverts the resource name back to singular
+
+UPDATE permissions SET resource = 'webhook' WHERE resource = 'webhooks';
+
+UPDATE permissions SET name = REPLACE(name, 'webhooks:', 'webhook:') WHERE name LIKE 'webhooks:%';
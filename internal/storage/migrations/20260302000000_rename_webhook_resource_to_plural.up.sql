@@ -0,0 +1,6 @@
+-- Renames the "webhook" resource to "webhooks" for consistency with the plural
+-- convention used by all other resources (devices, networks, circuits, etc.).
+
+UPDATE permissions SET resource = 'webhooks' WHERE resource = 'webhook';
+
+UPDATE permissions SET name = REPLACE(name, 'webhook:', 'webhooks:') WHERE name LIKE 'webhook:%';
@@ -0,0 +1,5 @@
+-- Removes the address link from DNS records
+
+DROP INDEX IF EXISTS idx_dns_records_address;
+
+ALTER TABLE dns_records DROP COLUMN address_id;
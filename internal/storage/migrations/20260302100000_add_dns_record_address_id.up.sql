@@ -0,0 +1,5 @@
+-- Links DNS records to the address they were created for
+
+ALTER TABLE dns_records ADD COLUMN address_id TEXT REFERENCES addresses(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_dns_records_address ON dns_records(address_id);
@@ -0,0 +1,3 @@
+-- Drops the sessions table
+
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,3 @@
+-- Drops the ssh_host_keys table
+
+DROP TABLE IF EXISTS ssh_host_keys;
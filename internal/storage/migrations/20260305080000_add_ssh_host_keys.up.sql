@@ -0,0 +1,8 @@
+-- Creates the ssh_host_keys table
+
+CREATE TABLE IF NOT EXISTS ssh_host_keys (
+	host TEXT PRIMARY KEY,
+	key_data BLOB NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- Removes the passive listener toggle
+
+ALTER TABLE discovery_rules DROP COLUMN passive_enabled;
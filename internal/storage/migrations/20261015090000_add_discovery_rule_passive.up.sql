@@ -0,0 +1,3 @@
+-- Adds the passive listener toggle to discovery rules
+
+ALTER TABLE discovery_rules ADD COLUMN passive_enabled INTEGER NOT NULL DEFAULT 0;
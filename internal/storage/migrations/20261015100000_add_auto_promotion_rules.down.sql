@@ -0,0 +1,5 @@
+-- Drops the rules table and the scan counts
+
+DROP TABLE IF EXISTS auto_promotion_rules;
+
+ALTER TABLE discovered_devices DROP COLUMN scan_count;
@@ -0,0 +1,22 @@
+-- Tracks how many scans saw each discovered device and adds per-network
+-- auto-promotion rules
+
+ALTER TABLE discovered_devices ADD COLUMN scan_count INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS auto_promotion_rules (
+	id TEXT PRIMARY KEY,
+	network_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	enabled INTEGER NOT NULL DEFAULT 1,
+	min_confidence INTEGER NOT NULL DEFAULT 0,
+	hostname_pattern TEXT NOT NULL DEFAULT '',
+	min_scans INTEGER NOT NULL DEFAULT 0,
+	name_template TEXT NOT NULL DEFAULT '',
+	tags TEXT NOT NULL DEFAULT '[]',
+	datacenter_id TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_promotion_rules_network ON auto_promotion_rules(network_id);
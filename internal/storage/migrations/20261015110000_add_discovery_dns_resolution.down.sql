@@ -0,0 +1,6 @@
+-- Removes the DNS resolution settings and results
+
+ALTER TABLE discovery_rules DROP COLUMN dns_resolution;
+ALTER TABLE discovery_rules DROP COLUMN dns_server;
+ALTER TABLE discovered_devices DROP COLUMN dns_names;
+ALTER TABLE discovered_devices DROP COLUMN dns_mismatches;
@@ -0,0 +1,10 @@
+-- Adds the per-rule DNS resolver settings and stores resolved names and
+-- mismatches on discovered devices
+
+ALTER TABLE discovery_rules ADD COLUMN dns_resolution INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE discovery_rules ADD COLUMN dns_server TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE discovered_devices ADD COLUMN dns_names TEXT NOT NULL DEFAULT '[]';
+
+ALTER TABLE discovered_devices ADD COLUMN dns_mismatches TEXT NOT NULL DEFAULT '[]';
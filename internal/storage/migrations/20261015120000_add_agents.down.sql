@@ -0,0 +1,3 @@
+-- Drops the agents table
+
+DROP TABLE IF EXISTS agents;
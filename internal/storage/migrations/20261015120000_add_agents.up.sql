@@ -0,0 +1,15 @@
+-- Creates the remote scanning agents table. Agents authenticate with a token
+-- stored as a hash, like API keys.
+
+CREATE TABLE IF NOT EXISTS agents (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	description TEXT NOT NULL DEFAULT '',
+	token_hash TEXT NOT NULL UNIQUE,
+	network_ids TEXT NOT NULL DEFAULT '[]',
+	hostname TEXT NOT NULL DEFAULT '',
+	version TEXT NOT NULL DEFAULT '',
+	last_heartbeat TIMESTAMP,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,5 @@
+-- Drops the monitoring tables
+
+DROP TABLE IF EXISTS monitor_results;
+
+DROP TABLE IF EXISTS monitor_checks;
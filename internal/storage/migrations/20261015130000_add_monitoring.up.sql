@@ -0,0 +1,34 @@
+-- Creates the availability check definitions and the latest result of each
+-- check per device
+
+CREATE TABLE IF NOT EXISTS monitor_checks (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	device_id TEXT,
+	tag TEXT NOT NULL DEFAULT '',
+	type TEXT NOT NULL,
+	port INTEGER NOT NULL DEFAULT 0,
+	interval_seconds INTEGER NOT NULL DEFAULT 60,
+	timeout_seconds INTEGER NOT NULL DEFAULT 5,
+	enabled INTEGER NOT NULL DEFAULT 1,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS monitor_results (
+	check_id TEXT NOT NULL,
+	device_id TEXT NOT NULL,
+	ip TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL,
+	latency_ms REAL NOT NULL DEFAULT 0,
+	message TEXT NOT NULL DEFAULT '',
+	last_checked_at TIMESTAMP NOT NULL,
+	last_up_at TIMESTAMP,
+	last_change_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (check_id, device_id),
+	FOREIGN KEY (check_id) REFERENCES monitor_checks(id) ON DELETE CASCADE,
+	FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_monitor_results_device ON monitor_results(device_id);
@@ -0,0 +1,5 @@
+-- Removes the address MAC column
+
+DROP INDEX IF EXISTS idx_addresses_mac;
+
+ALTER TABLE addresses DROP COLUMN mac_address;
@@ -0,0 +1,6 @@
+-- Records the hardware address of device addresses, so discovered MACs survive
+-- promotion
+
+ALTER TABLE addresses ADD COLUMN mac_address TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_addresses_mac ON addresses(mac_address);
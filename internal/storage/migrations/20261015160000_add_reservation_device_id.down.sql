@@ -0,0 +1,5 @@
+-- Removes the reservation device link
+
+DROP INDEX IF EXISTS idx_reservations_device_id;
+
+ALTER TABLE reservations DROP COLUMN device_id;
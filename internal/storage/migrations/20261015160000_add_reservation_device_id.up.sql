@@ -0,0 +1,5 @@
+-- Lets a reservation name the device it is held for
+
+ALTER TABLE reservations ADD COLUMN device_id TEXT REFERENCES devices(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_reservations_device_id ON reservations(device_id);
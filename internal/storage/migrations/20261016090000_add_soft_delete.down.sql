@@ -0,0 +1,16 @@
+-- Removes the trash columns
+
+DROP INDEX IF EXISTS idx_devices_deleted_at;
+ALTER TABLE devices DROP COLUMN deleted_at;
+ALTER TABLE devices DROP COLUMN deleted_by;
+ALTER TABLE devices DROP COLUMN delete_policy;
+
+DROP INDEX IF EXISTS idx_networks_deleted_at;
+ALTER TABLE networks DROP COLUMN deleted_at;
+ALTER TABLE networks DROP COLUMN deleted_by;
+ALTER TABLE networks DROP COLUMN delete_policy;
+
+DROP INDEX IF EXISTS idx_datacenters_deleted_at;
+ALTER TABLE datacenters DROP COLUMN deleted_at;
+ALTER TABLE datacenters DROP COLUMN deleted_by;
+ALTER TABLE datacenters DROP COLUMN delete_policy;
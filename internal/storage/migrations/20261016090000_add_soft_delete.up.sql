@@ -0,0 +1,17 @@
+-- Lets devices, networks and datacenters sit in the trash. delete_policy is
+-- the policy their dependents get when purged.
+
+ALTER TABLE devices ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE devices ADD COLUMN deleted_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE devices ADD COLUMN delete_policy TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_devices_deleted_at ON devices(deleted_at);
+
+ALTER TABLE networks ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE networks ADD COLUMN deleted_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE networks ADD COLUMN delete_policy TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_networks_deleted_at ON networks(deleted_at);
+
+ALTER TABLE datacenters ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE datacenters ADD COLUMN deleted_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE datacenters ADD COLUMN delete_policy TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_datacenters_deleted_at ON datacenters(deleted_at);
@@ -0,0 +1,7 @@
+-- Removes device purchase, warranty and end-of-life dates
+
+DROP INDEX IF EXISTS idx_devices_warranty_expiry;
+
+ALTER TABLE devices DROP COLUMN purchase_date;
+ALTER TABLE devices DROP COLUMN warranty_expiry;
+ALTER TABLE devices DROP COLUMN end_of_life;
@@ -0,0 +1,9 @@
+-- Adds purchase, warranty and vendor end-of-life dates to devices
+
+ALTER TABLE devices ADD COLUMN purchase_date TIMESTAMP;
+
+ALTER TABLE devices ADD COLUMN warranty_expiry TIMESTAMP;
+
+ALTER TABLE devices ADD COLUMN end_of_life TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_devices_warranty_expiry ON devices(warranty_expiry);
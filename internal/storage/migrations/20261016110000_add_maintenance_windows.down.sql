@@ -0,0 +1,3 @@
+-- Drops the maintenance windows table
+
+DROP TABLE IF EXISTS maintenance_windows;
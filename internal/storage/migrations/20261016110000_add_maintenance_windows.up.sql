@@ -0,0 +1,18 @@
+-- Creates maintenance windows, scoped to one device or to a tag
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+	id TEXT PRIMARY KEY,
+	device_id TEXT,
+	tag TEXT NOT NULL DEFAULT '',
+	starts_at TIMESTAMP NOT NULL,
+	ends_at TIMESTAMP NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	created_by TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_device ON maintenance_windows(device_id);
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_tag ON maintenance_windows(tag);
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_ends_at ON maintenance_windows(ends_at);
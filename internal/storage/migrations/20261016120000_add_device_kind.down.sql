@@ -0,0 +1,5 @@
+-- Removes the device kind
+
+DROP INDEX IF EXISTS idx_devices_kind;
+
+ALTER TABLE devices DROP COLUMN kind;
@@ -0,0 +1,6 @@
+-- Adds the device kind, telling physical servers from virtual machines,
+-- network gear and the like
+
+ALTER TABLE devices ADD COLUMN kind TEXT NOT NULL DEFAULT 'physical';
+
+CREATE INDEX IF NOT EXISTS idx_devices_kind ON devices(kind);
@@ -0,0 +1,6 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE resource = 'hypervisors');
+
+DELETE FROM permissions WHERE resource = 'hypervisors';
+
+DROP TABLE IF EXISTS hypervisor_connectors;
@@ -0,0 +1,35 @@
+-- Hypervisor connectors sync hosts and VMs from Proxmox VE and vSphere into the
+-- inventory. Operators may trigger syncs but not manage credentials.
+
+CREATE TABLE IF NOT EXISTS hypervisor_connectors (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	type TEXT NOT NULL,
+	endpoint TEXT NOT NULL,
+	username TEXT NOT NULL DEFAULT '',
+	secret TEXT NOT NULL DEFAULT '',
+	insecure_skip_verify INTEGER NOT NULL DEFAULT 0,
+	datacenter_id TEXT REFERENCES datacenters(id) ON DELETE SET NULL,
+	enabled INTEGER NOT NULL DEFAULT 1,
+	description TEXT NOT NULL DEFAULT '',
+	last_sync_at TIMESTAMP,
+	last_sync_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+INSERT OR IGNORE INTO permissions (id, name, resource, action, created_at)
+SELECT lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' ||
+	substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) ||
+	substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))),
+	'hypervisors:' || column1, 'hypervisors', column1, datetime('now')
+FROM (VALUES ('list'), ('read'), ('create'), ('update'), ('delete'), ('sync'));
+
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, datetime('now')
+FROM roles r, permissions p
+WHERE p.resource = 'hypervisors' AND (
+	r.name = 'admin'
+	OR (r.name = 'operator' AND p.action IN ('list', 'read', 'sync'))
+	OR (r.name = 'viewer' AND p.action IN ('list', 'read'))
+);
@@ -0,0 +1,6 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE resource = 'notifications');
+
+DELETE FROM permissions WHERE resource = 'notifications';
+
+DROP TABLE IF EXISTS notification_channels;
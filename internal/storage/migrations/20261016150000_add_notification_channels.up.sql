@@ -0,0 +1,39 @@
+-- Notification channels send readable event summaries to a webhook, email,
+-- Gotify or ntfy. Channels hold credentials, so only admins manage them.
+
+CREATE TABLE IF NOT EXISTS notification_channels (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL,
+	url TEXT NOT NULL DEFAULT '',
+	secret TEXT NOT NULL DEFAULT '',
+	smtp_host TEXT NOT NULL DEFAULT '',
+	smtp_port INTEGER NOT NULL DEFAULT 0,
+	username TEXT NOT NULL DEFAULT '',
+	email_from TEXT NOT NULL DEFAULT '',
+	email_to TEXT NOT NULL DEFAULT '[]',
+	events TEXT NOT NULL,
+	changes_only INTEGER NOT NULL DEFAULT 0,
+	active INTEGER NOT NULL DEFAULT 1,
+	description TEXT NOT NULL DEFAULT '',
+	created_by TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_channels_active ON notification_channels(active);
+
+INSERT OR IGNORE INTO permissions (id, name, resource, action, created_at)
+SELECT lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' ||
+	substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) ||
+	substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))),
+	'notifications:' || column1, 'notifications', column1, datetime('now')
+FROM (VALUES ('list'), ('read'), ('create'), ('update'), ('delete'));
+
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, datetime('now')
+FROM roles r, permissions p
+WHERE p.resource = 'notifications' AND (
+	r.name = 'admin'
+	OR (r.name IN ('operator', 'viewer') AND p.action IN ('list', 'read'))
+);
@@ -0,0 +1,5 @@
+-- Removes user recipients and message templates from notification channels
+
+ALTER TABLE notification_channels DROP COLUMN body_template;
+ALTER TABLE notification_channels DROP COLUMN title_template;
+ALTER TABLE notification_channels DROP COLUMN user_ids;
@@ -0,0 +1,5 @@
+-- Adds user recipients and message templates to notification channels
+
+ALTER TABLE notification_channels ADD COLUMN user_ids TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE notification_channels ADD COLUMN title_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE notification_channels ADD COLUMN body_template TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Removes the per-rule scan concurrency cap
+
+ALTER TABLE discovery_rules DROP COLUMN max_concurrent_scans;
@@ -0,0 +1,4 @@
+-- Lets a discovery rule cap how many hosts its scans probe at once; 0 keeps
+-- the adaptive default
+
+ALTER TABLE discovery_rules ADD COLUMN max_concurrent_scans INTEGER NOT NULL DEFAULT 0;
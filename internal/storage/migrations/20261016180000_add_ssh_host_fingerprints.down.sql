@@ -0,0 +1,3 @@
+-- Drops the SSH host key fingerprints collected by discovery
+
+DROP TABLE IF EXISTS ssh_host_fingerprints;
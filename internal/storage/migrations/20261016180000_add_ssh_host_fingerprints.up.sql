@@ -0,0 +1,13 @@
+-- Records the SSH host key fingerprints discovery collects per IP and key
+-- type, keeping the previous fingerprint when a key changes
+
+CREATE TABLE IF NOT EXISTS ssh_host_fingerprints (
+	ip TEXT NOT NULL,
+	key_type TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	previous_fingerprint TEXT NOT NULL DEFAULT '',
+	changed_at TIMESTAMP,
+	first_seen TIMESTAMP NOT NULL,
+	last_seen TIMESTAMP NOT NULL,
+	PRIMARY KEY (ip, key_type)
+);
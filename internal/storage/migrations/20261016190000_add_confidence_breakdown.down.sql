@@ -0,0 +1,4 @@
+-- Removes confidence breakdowns and per-factor auto-promotion thresholds
+
+ALTER TABLE auto_promotion_rules DROP COLUMN min_factor_points;
+ALTER TABLE discovered_devices DROP COLUMN confidence_breakdown;
@@ -0,0 +1,5 @@
+-- Stores the breakdown of discovered device confidence scores, and the
+-- per-factor thresholds of auto-promotion rules
+
+ALTER TABLE discovered_devices ADD COLUMN confidence_breakdown TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE auto_promotion_rules ADD COLUMN min_factor_points TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,5 @@
+-- Removes ignoring of discovered devices
+
+ALTER TABLE discovered_devices DROP COLUMN ignored_until;
+ALTER TABLE discovered_devices DROP COLUMN ignore_reason;
+ALTER TABLE discovered_devices DROP COLUMN ignored_at;
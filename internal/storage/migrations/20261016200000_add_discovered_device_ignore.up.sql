@@ -0,0 +1,5 @@
+-- Lets users ignore discovered devices, optionally until a given time
+
+ALTER TABLE discovered_devices ADD COLUMN ignored_at TIMESTAMP;
+ALTER TABLE discovered_devices ADD COLUMN ignore_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE discovered_devices ADD COLUMN ignored_until TIMESTAMP;
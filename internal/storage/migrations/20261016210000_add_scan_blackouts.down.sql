@@ -0,0 +1,3 @@
+-- Drops the scan blackout windows
+
+DROP TABLE IF EXISTS scan_blackouts;
@@ -0,0 +1,22 @@
+-- Creates recurring blackout windows for scheduled discovery scans, scoped to
+-- one network or global when network_id is NULL
+
+CREATE TABLE IF NOT EXISTS scan_blackouts (
+	id TEXT PRIMARY KEY,
+	network_id TEXT,
+	name TEXT NOT NULL,
+	enabled INTEGER NOT NULL DEFAULT 1,
+	type TEXT NOT NULL,
+	weekdays TEXT NOT NULL DEFAULT '[]',
+	start_time TEXT NOT NULL DEFAULT '',
+	end_time TEXT NOT NULL DEFAULT '',
+	cron_expression TEXT NOT NULL DEFAULT '',
+	duration_minutes INTEGER NOT NULL DEFAULT 0,
+	timezone TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_scan_blackouts_network ON scan_blackouts(network_id);
@@ -0,0 +1,5 @@
+-- Removes network gateway, DNS server and MTU settings
+
+ALTER TABLE networks DROP COLUMN mtu;
+ALTER TABLE networks DROP COLUMN dns_servers;
+ALTER TABLE networks DROP COLUMN gateway;
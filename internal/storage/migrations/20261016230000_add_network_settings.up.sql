@@ -0,0 +1,5 @@
+-- Records the default gateway, DNS servers and MTU handed out on a network
+
+ALTER TABLE networks ADD COLUMN gateway TEXT NOT NULL DEFAULT '';
+ALTER TABLE networks ADD COLUMN dns_servers TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE networks ADD COLUMN mtu INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Removes datacenter contacts and site metadata
+
+DROP TABLE IF EXISTS datacenter_contacts;
+ALTER TABLE datacenters DROP COLUMN access_instructions;
+ALTER TABLE datacenters DROP COLUMN shipping_address;
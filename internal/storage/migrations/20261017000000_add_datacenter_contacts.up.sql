@@ -0,0 +1,20 @@
+-- Adds site metadata for remote hands and the contacts to call about each
+-- datacenter, in escalation order
+
+ALTER TABLE datacenters ADD COLUMN shipping_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE datacenters ADD COLUMN access_instructions TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS datacenter_contacts (
+	id TEXT PRIMARY KEY,
+	datacenter_id TEXT NOT NULL,
+	role TEXT NOT NULL DEFAULT '',
+	name TEXT NOT NULL,
+	email TEXT NOT NULL DEFAULT '',
+	phone TEXT NOT NULL DEFAULT '',
+	escalation_order INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (datacenter_id) REFERENCES datacenters(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_datacenter_contacts_datacenter ON datacenter_contacts(datacenter_id, escalation_order);
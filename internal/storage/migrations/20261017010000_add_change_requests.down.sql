@@ -0,0 +1,3 @@
+-- Removes change requests
+
+DROP TABLE IF EXISTS change_requests;
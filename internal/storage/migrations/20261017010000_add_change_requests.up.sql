@@ -0,0 +1,23 @@
+-- Adds change requests: API mutations held for review when approval mode
+-- is enabled
+
+CREATE TABLE IF NOT EXISTS change_requests (
+	id TEXT PRIMARY KEY,
+	status TEXT NOT NULL DEFAULT 'pending',
+	method TEXT NOT NULL,
+	path TEXT NOT NULL,
+	body TEXT NOT NULL DEFAULT '',
+	before_state TEXT NOT NULL DEFAULT '',
+	requested_by TEXT NOT NULL,
+	requested_by_name TEXT NOT NULL DEFAULT '',
+	reviewed_by TEXT NOT NULL DEFAULT '',
+	reviewed_by_name TEXT NOT NULL DEFAULT '',
+	review_comment TEXT NOT NULL DEFAULT '',
+	result_status INTEGER NOT NULL DEFAULT 0,
+	result TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	reviewed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_requests_status ON change_requests(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_change_requests_requested_by ON change_requests(requested_by, created_at);
@@ -0,0 +1,3 @@
+-- Removes stored idempotency key responses
+
+DROP TABLE IF EXISTS idempotency_keys;
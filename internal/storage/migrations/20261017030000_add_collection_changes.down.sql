@@ -0,0 +1,30 @@
+-- Drops the listing change tracking and its triggers
+
+DROP TRIGGER IF EXISTS devices_changed_insert;
+DROP TRIGGER IF EXISTS devices_changed_update;
+DROP TRIGGER IF EXISTS devices_changed_delete;
+DROP TRIGGER IF EXISTS device_interfaces_changed_insert;
+DROP TRIGGER IF EXISTS device_interfaces_changed_update;
+DROP TRIGGER IF EXISTS device_interfaces_changed_delete;
+DROP TRIGGER IF EXISTS addresses_changed_insert;
+DROP TRIGGER IF EXISTS addresses_changed_update;
+DROP TRIGGER IF EXISTS addresses_changed_delete;
+DROP TRIGGER IF EXISTS tags_changed_insert;
+DROP TRIGGER IF EXISTS tags_changed_update;
+DROP TRIGGER IF EXISTS tags_changed_delete;
+DROP TRIGGER IF EXISTS domains_changed_insert;
+DROP TRIGGER IF EXISTS domains_changed_update;
+DROP TRIGGER IF EXISTS domains_changed_delete;
+DROP TRIGGER IF EXISTS device_relationships_changed_insert;
+DROP TRIGGER IF EXISTS device_relationships_changed_update;
+DROP TRIGGER IF EXISTS device_relationships_changed_delete;
+DROP TRIGGER IF EXISTS monitor_results_changed_insert;
+DROP TRIGGER IF EXISTS monitor_results_changed_update;
+DROP TRIGGER IF EXISTS monitor_results_changed_delete;
+DROP TRIGGER IF EXISTS maintenance_windows_changed_insert;
+DROP TRIGGER IF EXISTS maintenance_windows_changed_update;
+DROP TRIGGER IF EXISTS maintenance_windows_changed_delete;
+DROP TRIGGER IF EXISTS networks_changed_insert;
+DROP TRIGGER IF EXISTS networks_changed_update;
+DROP TRIGGER IF EXISTS networks_changed_delete;
+DROP TABLE IF EXISTS collection_changes;
@@ -0,0 +1,103 @@
+-- Tracks when each listing last changed, so GET /api/devices and
+-- GET /api/networks can answer If-Modified-Since. Triggers stamp the time on
+-- any insert, update or delete of the rows a listing is built from, which
+-- also catches deletes and cascades that leave no updated_at behind.
+
+CREATE TABLE IF NOT EXISTS collection_changes (
+	collection TEXT PRIMARY KEY,
+	changed_at INTEGER NOT NULL
+);
+
+INSERT OR IGNORE INTO collection_changes (collection, changed_at) VALUES
+	('devices', CAST(strftime('%s', 'now') AS INTEGER)),
+	('networks', CAST(strftime('%s', 'now') AS INTEGER));
+
+CREATE TRIGGER IF NOT EXISTS devices_changed_insert AFTER INSERT ON devices BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS devices_changed_update AFTER UPDATE ON devices BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS devices_changed_delete AFTER DELETE ON devices BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+
+CREATE TRIGGER IF NOT EXISTS device_interfaces_changed_insert AFTER INSERT ON device_interfaces BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS device_interfaces_changed_update AFTER UPDATE ON device_interfaces BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS device_interfaces_changed_delete AFTER DELETE ON device_interfaces BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+
+CREATE TRIGGER IF NOT EXISTS addresses_changed_insert AFTER INSERT ON addresses BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS addresses_changed_update AFTER UPDATE ON addresses BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS addresses_changed_delete AFTER DELETE ON addresses BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+
+CREATE TRIGGER IF NOT EXISTS tags_changed_insert AFTER INSERT ON tags BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS tags_changed_update AFTER UPDATE ON tags BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS tags_changed_delete AFTER DELETE ON tags BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+
+CREATE TRIGGER IF NOT EXISTS domains_changed_insert AFTER INSERT ON domains BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS domains_changed_update AFTER UPDATE ON domains BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS domains_changed_delete AFTER DELETE ON domains BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+
+CREATE TRIGGER IF NOT EXISTS device_relationships_changed_insert AFTER INSERT ON device_relationships BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS device_relationships_changed_update AFTER UPDATE ON device_relationships BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS device_relationships_changed_delete AFTER DELETE ON device_relationships BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+
+CREATE TRIGGER IF NOT EXISTS monitor_results_changed_insert AFTER INSERT ON monitor_results BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS monitor_results_changed_update AFTER UPDATE ON monitor_results BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS monitor_results_changed_delete AFTER DELETE ON monitor_results BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+
+CREATE TRIGGER IF NOT EXISTS maintenance_windows_changed_insert AFTER INSERT ON maintenance_windows BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS maintenance_windows_changed_update AFTER UPDATE ON maintenance_windows BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+CREATE TRIGGER IF NOT EXISTS maintenance_windows_changed_delete AFTER DELETE ON maintenance_windows BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'devices';
+END;
+
+CREATE TRIGGER IF NOT EXISTS networks_changed_insert AFTER INSERT ON networks BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'networks';
+END;
+CREATE TRIGGER IF NOT EXISTS networks_changed_update AFTER UPDATE ON networks BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'networks';
+END;
+CREATE TRIGGER IF NOT EXISTS networks_changed_delete AFTER DELETE ON networks BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'networks';
+END;
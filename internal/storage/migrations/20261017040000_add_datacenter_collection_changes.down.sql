@@ -0,0 +1,6 @@
+-- Drops the datacenter change tracking
+
+DROP TRIGGER IF EXISTS datacenters_changed_insert;
+DROP TRIGGER IF EXISTS datacenters_changed_update;
+DROP TRIGGER IF EXISTS datacenters_changed_delete;
+DELETE FROM collection_changes WHERE collection = 'datacenters';
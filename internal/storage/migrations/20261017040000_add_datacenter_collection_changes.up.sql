@@ -0,0 +1,17 @@
+-- Tracks when the datacenters last changed, for device listings that show
+-- their datacenter inline with ?embed=datacenter
+
+INSERT OR IGNORE INTO collection_changes (collection, changed_at) VALUES
+	('datacenters', CAST(strftime('%s', 'now') AS INTEGER));
+
+CREATE TRIGGER IF NOT EXISTS datacenters_changed_insert AFTER INSERT ON datacenters BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'datacenters';
+END;
+
+CREATE TRIGGER IF NOT EXISTS datacenters_changed_update AFTER UPDATE ON datacenters BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'datacenters';
+END;
+
+CREATE TRIGGER IF NOT EXISTS datacenters_changed_delete AFTER DELETE ON datacenters BEGIN
+	UPDATE collection_changes SET changed_at = CAST(strftime('%s', 'now') AS INTEGER) WHERE collection = 'datacenters';
+END;
@@ -0,0 +1,6 @@
+-- Drops the port_observations table
+
+DROP INDEX IF EXISTS idx_port_observations_closed_scan;
+DROP INDEX IF EXISTS idx_port_observations_opened_scan;
+DROP INDEX IF EXISTS idx_port_observations_network;
+DROP TABLE IF EXISTS port_observations;
@@ -0,0 +1,50 @@
+-- Records the history of the TCP ports discovery finds open on each IP: when
+-- a port was first and last seen open, when its current open period began
+-- and, once a scan probes it and finds it closed, when it closed. Seeded from
+-- the open ports and services of the discovered devices, with every seeded
+-- port starting at the host's last sighting.
+
+CREATE TABLE IF NOT EXISTS port_observations (
+	ip TEXT NOT NULL,
+	port INTEGER NOT NULL,
+	network_id TEXT NOT NULL DEFAULT '',
+	service TEXT NOT NULL DEFAULT '',
+	banner TEXT NOT NULL DEFAULT '',
+	first_seen TIMESTAMP NOT NULL,
+	last_seen TIMESTAMP NOT NULL,
+	opened_at TIMESTAMP NOT NULL,
+	opened_scan_id TEXT NOT NULL DEFAULT '',
+	closed_at TIMESTAMP,
+	closed_scan_id TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (ip, port)
+);
+
+CREATE INDEX IF NOT EXISTS idx_port_observations_network ON port_observations(network_id);
+CREATE INDEX IF NOT EXISTS idx_port_observations_opened_scan ON port_observations(opened_scan_id);
+CREATE INDEX IF NOT EXISTS idx_port_observations_closed_scan ON port_observations(closed_scan_id);
+
+-- The most recently seen host wins when an IP was discovered on several
+-- networks
+INSERT OR IGNORE INTO port_observations (ip, port, network_id, service, banner, first_seen, last_seen, opened_at)
+SELECT d.ip, p.value, COALESCE(d.network_id, ''),
+	COALESCE((
+		SELECT json_extract(s.value, '$.service')
+		FROM json_each(CASE WHEN json_valid(d.services) THEN d.services ELSE '[]' END) s
+		WHERE json_extract(s.value, '$.port') = p.value
+			AND json_extract(s.value, '$.protocol') = 'tcp'
+			AND COALESCE(json_extract(s.value, '$.service'), '') != ''
+		LIMIT 1
+	), ''),
+	COALESCE((
+		SELECT json_extract(s.value, '$.version')
+		FROM json_each(CASE WHEN json_valid(d.services) THEN d.services ELSE '[]' END) s
+		WHERE json_extract(s.value, '$.port') = p.value
+			AND json_extract(s.value, '$.protocol') = 'tcp'
+			AND COALESCE(json_extract(s.value, '$.version'), '') != ''
+		LIMIT 1
+	), ''),
+	d.last_seen, d.last_seen, d.last_seen
+FROM discovered_devices d,
+	json_each(CASE WHEN json_valid(d.open_ports) THEN d.open_ports ELSE '[]' END) p
+WHERE d.last_seen IS NOT NULL AND p.type = 'integer'
+ORDER BY d.last_seen DESC;
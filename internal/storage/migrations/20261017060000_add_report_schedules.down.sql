@@ -0,0 +1,6 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE resource = 'report-schedules');
+
+DELETE FROM permissions WHERE resource = 'report-schedules';
+
+DROP TABLE IF EXISTS report_schedules;
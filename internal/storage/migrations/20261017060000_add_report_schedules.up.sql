@@ -0,0 +1,40 @@
+-- Report schedules run a canned report on a cron expression and send it to
+-- notification channels. Admins and operators manage schedules; viewers can
+-- see them.
+
+CREATE TABLE IF NOT EXISTS report_schedules (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	report TEXT NOT NULL,
+	days INTEGER NOT NULL DEFAULT 0,
+	datacenter TEXT NOT NULL DEFAULT '',
+	tag TEXT NOT NULL DEFAULT '',
+	format TEXT NOT NULL DEFAULT 'csv',
+	cron_expression TEXT NOT NULL,
+	channel_ids TEXT NOT NULL DEFAULT '[]',
+	enabled INTEGER NOT NULL DEFAULT 1,
+	description TEXT NOT NULL DEFAULT '',
+	created_by TEXT NOT NULL DEFAULT '',
+	last_run_at TIMESTAMP,
+	last_error TEXT NOT NULL DEFAULT '',
+	next_run_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_schedules_due ON report_schedules(enabled, next_run_at);
+
+INSERT OR IGNORE INTO permissions (id, name, resource, action, created_at)
+SELECT lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' ||
+	substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) ||
+	substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))),
+	'report-schedules:' || column1, 'report-schedules', column1, datetime('now')
+FROM (VALUES ('list'), ('read'), ('create'), ('update'), ('delete'));
+
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, datetime('now')
+FROM roles r, permissions p
+WHERE p.resource = 'report-schedules' AND (
+	r.name IN ('admin', 'operator')
+	OR (r.name = 'viewer' AND p.action IN ('list', 'read'))
+);
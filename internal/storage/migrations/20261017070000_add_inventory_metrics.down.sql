@@ -0,0 +1,4 @@
+-- Drops the inventory_metrics table
+
+DROP INDEX IF EXISTS idx_inventory_metrics_day;
+DROP TABLE IF EXISTS inventory_metrics;
@@ -0,0 +1,17 @@
+-- Records a daily value of each inventory metric: device counts, pool and
+-- network utilization and discovered devices not yet promoted. The snapshot
+-- worker overwrites the current day's values on every run, so each day keeps
+-- the last values recorded on it. Pool metrics are recorded for each pool
+-- (resource_id) and for all pools together (resource_id '').
+
+CREATE TABLE IF NOT EXISTS inventory_metrics (
+	day TEXT NOT NULL,
+	name TEXT NOT NULL,
+	resource_id TEXT NOT NULL DEFAULT '',
+	resource_name TEXT NOT NULL DEFAULT '',
+	value REAL NOT NULL,
+	recorded_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (name, resource_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_metrics_day ON inventory_metrics(day);
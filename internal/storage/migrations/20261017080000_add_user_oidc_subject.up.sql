@@ -0,0 +1,7 @@
+-- Adds the OIDC issuer and subject a user signs in as, so a provider login
+-- is matched to its account by identity rather than by username
+
+ALTER TABLE users ADD COLUMN oidc_issuer TEXT;
+ALTER TABLE users ADD COLUMN oidc_subject TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_oidc_subject ON users(oidc_issuer, oidc_subject);
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// sqlMigrationFiles holds the SQL migrations. Each one is a pair of scripts
+// named <version>_<name>.up.sql and <version>_<name>.down.sql, where the
+// version is a UTC timestamp like the Go migrations use.
+//
+//go:embed migrations/*.sql
+var sqlMigrationFiles embed.FS
+
+var sqlMigrationName = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// loadSQLMigrations reads the migration scripts in the root of fsys. Every
+// up script needs a down script, even if it only holds a comment.
+func loadSQLMigrations(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[string]*Migration{}
+	for _, entry := range entries {
+		match := sqlMigrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named <version>_<name>.up.sql or .down.sql", entry.Name())
+		}
+		version, name, direction := match[1], match[2], match[3]
+
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %s is named both %s and %s", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = execScript(entry.Name(), string(script))
+		} else {
+			m.Down = execScript(entry.Name(), string(script))
+		}
+	}
+
+	loaded := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil || m.Down == nil {
+			return nil, fmt.Errorf("migration %s_%s needs both an up and a down script", m.Version, m.Name)
+		}
+		loaded = append(loaded, m)
+	}
+	return loaded, nil
+}
+
+// execScript returns a migration step that runs every statement in script
+func execScript(file, script string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		if strings.TrimSpace(stripSQLComments(script)) == "" {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return fmt.Errorf("%s: %w", path.Base(file), err)
+		}
+		return nil
+	}
+}
+
+// stripSQLComments drops -- line comments, so a script holding only an
+// explanation of why there is nothing to undo counts as empty
+func stripSQLComments(script string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// mergeMigrations combines the Go and SQL migrations into one list in version
+// order
+func mergeMigrations(goMigrations, sqlMigrations []*Migration) ([]*Migration, error) {
+	all := append(append([]*Migration{}, goMigrations...), sqlMigrations...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	for i := 1; i < len(all); i++ {
+		if all[i].Version == all[i-1].Version {
+			return nil, fmt.Errorf("migration version %s is used by both %s and %s", all[i].Version, all[i-1].Name, all[i].Name)
+		}
+	}
+	return all, nil
+}
+
+func init() {
+	files, err := fs.Sub(sqlMigrationFiles, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	sqlMigrations, err := loadSQLMigrations(files)
+	if err != nil {
+		panic(fmt.Sprintf("invalid SQL migrations: %v", err))
+	}
+	if migrations, err = mergeMigrations(migrations, sqlMigrations); err != nil {
+		panic(err)
+	}
+}
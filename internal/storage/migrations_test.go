@@ -3,7 +3,12 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
 
 	_ "modernc.org/sqlite"
 )
@@ -206,3 +211,352 @@ func TestIndexesCreated(t *testing.T) {
 		}
 	}
 }
+
+// schemaOf returns the schema of db, leaving out the migration records
+func schemaOf(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT type || ' ' || name || ' ' || COALESCE(sql, '') FROM sqlite_master WHERE tbl_name != 'schema_migrations' AND name NOT LIKE 'sqlite_%' ORDER BY type, name`)
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	defer rows.Close()
+	var schema []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			t.Fatalf("failed to read schema: %v", err)
+		}
+		schema = append(schema, s)
+	}
+	return schema
+}
+
+func openMigrationTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	// One connection, so every query sees the same in-memory database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateDownRoundTrip(t *testing.T) {
+	db := openMigrationTestDB(t)
+	ctx := context.Background()
+
+	if _, err := MigrateUp(ctx, db, false); err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+	want := schemaOf(t, db)
+
+	reverted, err := MigrateDown(ctx, db, len(migrations), false)
+	if err != nil {
+		t.Fatalf("MigrateDown failed: %v", err)
+	}
+	if len(reverted) != len(migrations) || reverted[0] != migrations[len(migrations)-1] {
+		t.Errorf("expected every migration to be reverted newest first, got %d", len(reverted))
+	}
+	if left := schemaOf(t, db); len(left) != 0 {
+		t.Errorf("expected an empty schema after reverting everything, got %v", left)
+	}
+
+	if _, err := MigrateUp(ctx, db, false); err != nil {
+		t.Fatalf("MigrateUp after MigrateDown failed: %v", err)
+	}
+	if got := schemaOf(t, db); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Error("expected migrating up again to rebuild the same schema")
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	db := openMigrationTestDB(t)
+	ctx := context.Background()
+
+	planned, err := MigrateUp(ctx, db, true)
+	if err != nil {
+		t.Fatalf("MigrateUp dry run failed: %v", err)
+	}
+	if len(planned) != len(migrations) {
+		t.Errorf("expected %d migrations planned, got %d", len(migrations), len(planned))
+	}
+	if schema := schemaOf(t, db); len(schema) != 0 {
+		t.Errorf("expected a dry run to leave the database alone, got %v", schema)
+	}
+
+	if err := RunMigrations(ctx, db); err != nil {
+		t.Fatalf("RunMigrations failed: %v", err)
+	}
+	planned, err = MigrateDown(ctx, db, 2, true)
+	if err != nil {
+		t.Fatalf("MigrateDown dry run failed: %v", err)
+	}
+	if len(planned) != 2 || planned[0].Version != migrations[len(migrations)-1].Version {
+		t.Errorf("expected the two newest migrations planned, got %d", len(planned))
+	}
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+	if count != len(migrations) {
+		t.Errorf("expected a dry run to keep every migration record, got %d", count)
+	}
+}
+
+func TestMigrateDownUnknownMigration(t *testing.T) {
+	db := openMigrationTestDB(t)
+	ctx := context.Background()
+
+	if err := RunMigrations(ctx, db); err != nil {
+		t.Fatalf("RunMigrations failed: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, name, checksum, execution_time_ms) VALUES ('29990101000000', 'from_the_future', '', 0)`); err != nil {
+		t.Fatalf("failed to record migration: %v", err)
+	}
+	if _, err := MigrateDown(ctx, db, 1, false); err == nil || !strings.Contains(err.Error(), "not known") {
+		t.Errorf("expected an unknown migration error, got %v", err)
+	}
+	if _, err := MigrateDown(ctx, db, 0, false); err == nil {
+		t.Error("expected an error for zero steps")
+	}
+}
+
+func TestLoadSQLMigrations(t *testing.T) {
+	loaded, err := loadSQLMigrations(fstest.MapFS{
+		"20260101000000_add_widgets.up.sql":   {Data: []byte("CREATE TABLE widgets (id TEXT);")},
+		"20260101000000_add_widgets.down.sql": {Data: []byte("-- nothing to undo\n")},
+	})
+	if err != nil {
+		t.Fatalf("loadSQLMigrations failed: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Version != "20260101000000" || loaded[0].Name != "add_widgets" {
+		t.Fatalf("unexpected migrations: %+v", loaded)
+	}
+
+	db := openMigrationTestDB(t)
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	defer tx.Rollback()
+	if err := loaded[0].Up(context.Background(), tx); err != nil {
+		t.Errorf("up script failed: %v", err)
+	}
+	if err := loaded[0].Down(context.Background(), tx); err != nil {
+		t.Errorf("a comment-only down script should do nothing, got %v", err)
+	}
+
+	for name, files := range map[string]fstest.MapFS{
+		"missing down": {"20260101000000_add_widgets.up.sql": {}},
+		"bad name":     {"add_widgets.sql": {}},
+		"two names": {
+			"20260101000000_add_widgets.up.sql":   {},
+			"20260101000000_add_gadgets.down.sql": {},
+		},
+	} {
+		if _, err := loadSQLMigrations(files); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMergeMigrationsDuplicateVersion(t *testing.T) {
+	_, err := mergeMigrations(
+		[]*Migration{{Version: "20260101000000", Name: "go_one"}},
+		[]*Migration{{Version: "20260101000000", Name: "sql_one"}},
+	)
+	if err == nil {
+		t.Error("expected an error for a version used twice")
+	}
+}
+
+func TestMigrationVersionsAreTimestamps(t *testing.T) {
+	for _, m := range migrations {
+		if _, err := time.Parse("20060102150405", m.Version); err != nil {
+			t.Errorf("migration %s has version %s, which is not a UTC timestamp: %v", m.Name, m.Version, err)
+		}
+	}
+}
+
+func TestMigrateFixAddressTypes(t *testing.T) {
+	db := openMigrationTestDB(t)
+	ctx := context.Background()
+
+	if err := RunMigrations(ctx, db); err != nil {
+		t.Fatalf("RunMigrations failed: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO devices (id, name) VALUES ('dev-1', 'web-01')`); err != nil {
+		t.Fatalf("failed to insert device: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO addresses (id, device_id, ip, type, label) VALUES
+			('a1', 'dev-1', '2001:db8::1', 'ipv4', ''),
+			('a2', 'dev-1', '10.0.0.1', 'ipv4', 'lan'),
+			('a3', 'dev-1', '2001:db8::2', 'management', ''),
+			('a4', 'dev-1', '::ffff:10.0.0.3', 'ipv6', 'mapped'),
+			('a5', 'dev-1', 'not-an-ip', 'ipv6', ''),
+			('a6', 'dev-1', '2001:db8::3', '', '')
+	`); err != nil {
+		t.Fatalf("failed to insert addresses: %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := migrateFixAddressTypesUp(ctx, tx); err != nil {
+		t.Fatalf("migrateFixAddressTypesUp failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	want := map[string][2]string{
+		"a1": {"ipv6", ""},
+		"a2": {"ipv4", "lan"},
+		"a3": {"management", ""},
+		"a4": {"ipv4", "mapped"},
+		"a5": {"ipv6", ""},
+		"a6": {"ipv6", ""},
+	}
+	for id, w := range want {
+		var addrType, label string
+		if err := db.QueryRow(`SELECT type, label FROM addresses WHERE id = ?`, id).Scan(&addrType, &label); err != nil {
+			t.Fatalf("failed to read address %s: %v", id, err)
+		}
+		if addrType != w[0] || label != w[1] {
+			t.Errorf("address %s: expected type %q and label %q, got %q and %q", id, w[0], w[1], addrType, label)
+		}
+	}
+}
+
+func TestMigrateAddDeviceInterfaces(t *testing.T) {
+	db := openMigrationTestDB(t)
+	ctx := context.Background()
+
+	if _, err := MigrateUp(ctx, db, false); err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+	// Revert back to just before device interfaces
+	steps := 0
+	for _, m := range migrations {
+		if m.Version >= "20261016220000" {
+			steps++
+		}
+	}
+	if _, err := MigrateDown(ctx, db, steps, false); err != nil {
+		t.Fatalf("MigrateDown failed: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO devices (id, name) VALUES ('dev-1', 'web-01'), ('dev-2', 'db-01'), ('dev-3', 'rack-a')`); err != nil {
+		t.Fatalf("failed to insert devices: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO addresses (id, device_id, ip, type, mac_address, switch_port) VALUES
+			('a1', 'dev-1', '10.0.0.1', 'ipv4', 'aa:bb:cc:dd:ee:01', 'Gi1/0/1'),
+			('a2', 'dev-1', '10.0.0.2', 'ipv4', 'aa:bb:cc:dd:ee:01', 'Gi1/0/1'),
+			('a3', 'dev-2', '10.0.0.3', 'ipv4', 'aa:bb:cc:dd:ee:02', NULL),
+			('a4', 'dev-2', '10.0.0.4', 'ipv4', 'aa:bb:cc:dd:ee:03', NULL)
+	`); err != nil {
+		t.Fatalf("failed to insert addresses: %v", err)
+	}
+	if _, err := MigrateUp(ctx, db, false); err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM device_interfaces`).Scan(&count); err != nil {
+		t.Fatalf("failed to count interfaces: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected a default interface only for the devices with addresses, got %d", count)
+	}
+
+	want := map[string][2]string{
+		"dev-1": {"aa:bb:cc:dd:ee:01", "Gi1/0/1"},
+		"dev-2": {"", ""},
+	}
+	for deviceID, w := range want {
+		var id, name string
+		var mac, port sql.NullString
+		if err := db.QueryRow(`SELECT id, name, mac_address, switch_port FROM device_interfaces WHERE device_id = ?`, deviceID).
+			Scan(&id, &name, &mac, &port); err != nil {
+			t.Fatalf("failed to read interface of %s: %v", deviceID, err)
+		}
+		if name != model.DefaultInterfaceName || mac.String != w[0] || port.String != w[1] {
+			t.Errorf("%s: expected %q with MAC %q on %q, got %q with %q on %q", deviceID, model.DefaultInterfaceName, w[0], w[1], name, mac.String, port.String)
+		}
+		var unattached int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM addresses WHERE device_id = ? AND (interface_id IS NULL OR interface_id != ?)`, deviceID, id).Scan(&unattached); err != nil {
+			t.Fatalf("failed to count addresses: %v", err)
+		}
+		if unattached != 0 {
+			t.Errorf("%s: expected every address on the default interface, %d are not", deviceID, unattached)
+		}
+	}
+}
+
+func TestSQLMigrationsSeedPermissions(t *testing.T) {
+	db := openMigrationTestDB(t)
+	ctx := context.Background()
+
+	if _, err := MigrateUp(ctx, db, false); err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+
+	var id string
+	var createdAt time.Time
+	err := db.QueryRow(`SELECT id, created_at FROM permissions WHERE name = 'hypervisors:sync'`).Scan(&id, &createdAt)
+	if err != nil {
+		t.Fatalf("failed to read permission: %v", err)
+	}
+	if len(id) != 36 || strings.Count(id, "-") != 4 || time.Since(createdAt) > time.Hour {
+		t.Errorf("expected a UUID and the current time, got %q %v", id, createdAt)
+	}
+
+	granted := func(role, permission string) bool {
+		var n int
+		db.QueryRow(`
+			SELECT COUNT(*) FROM role_permissions rp
+			JOIN roles r ON r.id = rp.role_id
+			JOIN permissions p ON p.id = rp.permission_id
+			WHERE r.name = ? AND p.name = ?
+		`, role, permission).Scan(&n)
+		return n == 1
+	}
+	tests := []struct {
+		role, permission string
+		want             bool
+	}{
+		{"admin", "hypervisors:delete", true},
+		{"operator", "hypervisors:sync", true},
+		{"operator", "hypervisors:update", false},
+		{"viewer", "hypervisors:read", true},
+		{"admin", "notifications:create", true},
+		{"operator", "notifications:create", false},
+		{"viewer", "notifications:list", true},
+		{"operator", "report-schedules:delete", true},
+		{"viewer", "report-schedules:read", true},
+		{"viewer", "report-schedules:create", false},
+	}
+	for _, tt := range tests {
+		if got := granted(tt.role, tt.permission); got != tt.want {
+			t.Errorf("%s has %s = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+
+	// Converted from Go, so databases that applied them keep the same records
+	for version, name := range map[string]string{
+		"20261016130000": "add_hypervisor_connectors",
+		"20261016150000": "add_notification_channels",
+		"20261017060000": "add_report_schedules",
+	} {
+		var checksum string
+		if err := db.QueryRow(`SELECT checksum FROM schema_migrations WHERE version = ? AND name = ?`, version, name).Scan(&checksum); err != nil {
+			t.Errorf("migration %s_%s not applied: %v", version, name, err)
+			continue
+		}
+		if checksum != calculateChecksum(&Migration{Version: version, Name: name}) {
+			t.Errorf("checksum of %s changed", name)
+		}
+	}
+}
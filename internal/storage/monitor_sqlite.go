@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const monitorCheckColumns = `id, name, device_id, tag, type, port, interval_seconds, timeout_seconds,
+	enabled, created_at, updated_at`
+
+// CreateMonitorCheck inserts a new availability check
+func (s *SQLiteStorage) CreateMonitorCheck(ctx context.Context, check *model.MonitorCheck) error {
+	if check.ID == "" {
+		check.ID = newUUID()
+	}
+	now := nowUTC()
+	check.CreatedAt = now
+	check.UpdatedAt = now
+	enabled := 0
+	if check.Enabled {
+		enabled = 1
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO monitor_checks (`+monitorCheckColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, check.ID, check.Name, nullString(check.DeviceID), check.Tag, check.Type, check.Port,
+		check.IntervalSeconds, check.TimeoutSeconds, enabled, check.CreatedAt, check.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	s.auditLog(ctx, "create", "monitor_check", check.ID, check)
+	return nil
+}
+
+// GetMonitorCheck retrieves an availability check by ID
+func (s *SQLiteStorage) GetMonitorCheck(ctx context.Context, id string) (*model.MonitorCheck, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+monitorCheckColumns+` FROM monitor_checks WHERE id = ?`, id)
+	check, err := scanMonitorCheck(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrMonitorCheckNotFound
+	}
+	return check, err
+}
+
+// ListMonitorChecks returns all availability checks ordered by name
+func (s *SQLiteStorage) ListMonitorChecks(ctx context.Context) ([]model.MonitorCheck, error) {
+	rows, err := s.reader.QueryContext(ctx, `SELECT `+monitorCheckColumns+` FROM monitor_checks ORDER BY name, created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := []model.MonitorCheck{}
+	for rows.Next() {
+		check, err := scanMonitorCheck(rows)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, *check)
+	}
+	return checks, rows.Err()
+}
+
+// UpdateMonitorCheck updates an existing availability check
+func (s *SQLiteStorage) UpdateMonitorCheck(ctx context.Context, check *model.MonitorCheck) error {
+	check.UpdatedAt = nowUTC()
+	enabled := 0
+	if check.Enabled {
+		enabled = 1
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE monitor_checks SET name = ?, device_id = ?, tag = ?, type = ?, port = ?,
+			interval_seconds = ?, timeout_seconds = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, check.Name, nullString(check.DeviceID), check.Tag, check.Type, check.Port,
+		check.IntervalSeconds, check.TimeoutSeconds, enabled, check.UpdatedAt, check.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrMonitorCheckNotFound
+	}
+	s.auditLog(ctx, "update", "monitor_check", check.ID, check)
+	return nil
+}
+
+// DeleteMonitorCheck removes an availability check and its results
+func (s *SQLiteStorage) DeleteMonitorCheck(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM monitor_checks WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrMonitorCheckNotFound
+	}
+	s.auditLog(ctx, "delete", "monitor_check", id, nil)
+	return nil
+}
+
+// SaveMonitorResult stores the latest result of a check for a device. Results
+// are written on every check run so they are not audited.
+func (s *SQLiteStorage) SaveMonitorResult(ctx context.Context, r *model.MonitorResult) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO monitor_results (check_id, device_id, ip, status, latency_ms, message,
+			last_checked_at, last_up_at, last_change_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(check_id, device_id) DO UPDATE SET
+			ip = excluded.ip, status = excluded.status, latency_ms = excluded.latency_ms,
+			message = excluded.message, last_checked_at = excluded.last_checked_at,
+			last_up_at = excluded.last_up_at, last_change_at = excluded.last_change_at
+	`, r.CheckID, r.DeviceID, r.IP, r.Status, r.LatencyMs, r.Message,
+		r.LastCheckedAt, r.LastUpAt, r.LastChangeAt)
+	return err
+}
+
+// ListMonitorResults returns the latest check results for the given devices,
+// or for every device when deviceIDs is empty
+func (s *SQLiteStorage) ListMonitorResults(ctx context.Context, deviceIDs []string) ([]model.MonitorResult, error) {
+	query := `
+		SELECT r.check_id, c.name, r.device_id, c.type, c.port, r.ip, r.status, r.latency_ms,
+			r.message, r.last_checked_at, r.last_up_at, r.last_change_at
+		FROM monitor_results r
+		JOIN monitor_checks c ON c.id = r.check_id`
+	var args []any
+	if len(deviceIDs) > 0 {
+		query += " WHERE r.device_id IN (" + placeholders(len(deviceIDs)) + ")"
+		for _, id := range deviceIDs {
+			args = append(args, id)
+		}
+	}
+	query += " ORDER BY r.device_id, c.name"
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []model.MonitorResult{}
+	for rows.Next() {
+		var r model.MonitorResult
+		var lastUp sql.NullTime
+		if err := rows.Scan(&r.CheckID, &r.CheckName, &r.DeviceID, &r.Type, &r.Port, &r.IP, &r.Status,
+			&r.LatencyMs, &r.Message, &r.LastCheckedAt, &lastUp, &r.LastChangeAt); err != nil {
+			return nil, err
+		}
+		if lastUp.Valid {
+			r.LastUpAt = &lastUp.Time
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// PruneMonitorResults removes results of a check for devices it no longer
+// targets, e.g. after a tag was removed from a device
+func (s *SQLiteStorage) PruneMonitorResults(ctx context.Context, checkID string, keepDeviceIDs []string) error {
+	query := "DELETE FROM monitor_results WHERE check_id = ?"
+	args := []any{checkID}
+	if len(keepDeviceIDs) > 0 {
+		query += " AND device_id NOT IN (" + placeholders(len(keepDeviceIDs)) + ")"
+		for _, id := range keepDeviceIDs {
+			args = append(args, id)
+		}
+	}
+	_, err := s.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func scanMonitorCheck(row interface{ Scan(...any) error }) (*model.MonitorCheck, error) {
+	var check model.MonitorCheck
+	var deviceID sql.NullString
+	var enabled int
+	if err := row.Scan(&check.ID, &check.Name, &deviceID, &check.Tag, &check.Type, &check.Port,
+		&check.IntervalSeconds, &check.TimeoutSeconds, &enabled, &check.CreatedAt, &check.UpdatedAt); err != nil {
+		return nil, err
+	}
+	check.DeviceID = deviceID.String
+	check.Enabled = enabled == 1
+	return &check, nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestMonitorCheckCRUD(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	device := &model.Device{Name: "web1", Status: model.DeviceStatusActive}
+	if err := storage.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+
+	check := &model.MonitorCheck{Name: "ping", DeviceID: device.ID, Type: model.MonitorCheckICMP, IntervalSeconds: 60, TimeoutSeconds: 2, Enabled: true}
+	if err := storage.CreateMonitorCheck(ctx, check); err != nil {
+		t.Fatalf("CreateMonitorCheck failed: %v", err)
+	}
+	tagCheck := &model.MonitorCheck{Name: "ssh", Tag: "linux", Type: model.MonitorCheckTCP, Port: 22, IntervalSeconds: 300, TimeoutSeconds: 5}
+	if err := storage.CreateMonitorCheck(ctx, tagCheck); err != nil {
+		t.Fatalf("CreateMonitorCheck (tag) failed: %v", err)
+	}
+
+	got, err := storage.GetMonitorCheck(ctx, check.ID)
+	if err != nil {
+		t.Fatalf("GetMonitorCheck failed: %v", err)
+	}
+	if got.DeviceID != device.ID || !got.Enabled || got.Type != model.MonitorCheckICMP {
+		t.Errorf("check mismatch: got %+v", got)
+	}
+	if got, _ := storage.GetMonitorCheck(ctx, tagCheck.ID); got.DeviceID != "" || got.Tag != "linux" || got.Port != 22 {
+		t.Errorf("tag check mismatch: got %+v", got)
+	}
+
+	tagCheck.Enabled = true
+	tagCheck.Port = 2222
+	if err := storage.UpdateMonitorCheck(ctx, tagCheck); err != nil {
+		t.Fatalf("UpdateMonitorCheck failed: %v", err)
+	}
+	checks, err := storage.ListMonitorChecks(ctx)
+	if err != nil || len(checks) != 2 {
+		t.Fatalf("expected 2 checks, got %d (%v)", len(checks), err)
+	}
+
+	if err := storage.UpdateMonitorCheck(ctx, &model.MonitorCheck{ID: "missing"}); !errors.Is(err, ErrMonitorCheckNotFound) {
+		t.Errorf("expected ErrMonitorCheckNotFound, got %v", err)
+	}
+	if err := storage.DeleteMonitorCheck(ctx, tagCheck.ID); err != nil {
+		t.Fatalf("DeleteMonitorCheck failed: %v", err)
+	}
+	if _, err := storage.GetMonitorCheck(ctx, tagCheck.ID); !errors.Is(err, ErrMonitorCheckNotFound) {
+		t.Errorf("expected ErrMonitorCheckNotFound after delete, got %v", err)
+	}
+}
+
+func TestMonitorResults(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	web := &model.Device{Name: "web1", Status: model.DeviceStatusActive}
+	db := &model.Device{Name: "db1", Status: model.DeviceStatusActive}
+	storage.CreateDevice(ctx, web)
+	storage.CreateDevice(ctx, db)
+
+	check := &model.MonitorCheck{Name: "ping", Tag: "prod", Type: model.MonitorCheckICMP, IntervalSeconds: 60, TimeoutSeconds: 2, Enabled: true}
+	if err := storage.CreateMonitorCheck(ctx, check); err != nil {
+		t.Fatalf("CreateMonitorCheck failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, d := range []*model.Device{web, db} {
+		r := &model.MonitorResult{CheckID: check.ID, DeviceID: d.ID, IP: "10.0.0.1", Status: model.MonitorStatusUp,
+			LatencyMs: 1.5, LastCheckedAt: now, LastUpAt: &now, LastChangeAt: now}
+		if err := storage.SaveMonitorResult(ctx, r); err != nil {
+			t.Fatalf("SaveMonitorResult failed: %v", err)
+		}
+	}
+
+	// Saving again updates in place
+	later := now.Add(time.Minute)
+	if err := storage.SaveMonitorResult(ctx, &model.MonitorResult{CheckID: check.ID, DeviceID: web.ID, IP: "10.0.0.1",
+		Status: model.MonitorStatusDown, Message: "timeout", LastCheckedAt: later, LastUpAt: &now, LastChangeAt: later}); err != nil {
+		t.Fatalf("SaveMonitorResult (update) failed: %v", err)
+	}
+
+	results, err := storage.ListMonitorResults(ctx, []string{web.ID})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d (%v)", len(results), err)
+	}
+	if r := results[0]; r.Status != model.MonitorStatusDown || r.CheckName != "ping" || r.Message != "timeout" || r.LastUpAt == nil || !r.LastUpAt.Equal(now) {
+		t.Errorf("result mismatch: got %+v", r)
+	}
+	if all, _ := storage.ListMonitorResults(ctx, nil); len(all) != 2 {
+		t.Errorf("expected 2 results overall, got %d", len(all))
+	}
+
+	if err := storage.PruneMonitorResults(ctx, check.ID, []string{db.ID}); err != nil {
+		t.Fatalf("PruneMonitorResults failed: %v", err)
+	}
+	if all, _ := storage.ListMonitorResults(ctx, nil); len(all) != 1 || all[0].DeviceID != db.ID {
+		t.Errorf("expected only db1 result after prune, got %+v", all)
+	}
+
+	// Deleting the device removes its results
+	if err := storage.DeleteDevice(ctx, db.ID, model.DeleteDetach); err != nil {
+		t.Fatalf("DeleteDevice failed: %v", err)
+	}
+	if all, _ := storage.ListMonitorResults(ctx, nil); len(all) != 0 {
+		t.Errorf("expected results to be removed with the device, got %d", len(all))
+	}
+}
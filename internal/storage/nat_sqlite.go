@@ -48,7 +48,7 @@ func (s *SQLiteStorage) GetNATMapping(ctx context.Context, id string) (*model.NA
 	var tagsJSON string
 	var deviceID, datacenterID, networkID sql.NullString
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, external_ip, external_port, internal_ip, internal_port,
 			protocol, device_id, description, enabled, datacenter_id, network_id,
 			tags, created_at, updated_at
@@ -144,7 +144,7 @@ func (s *SQLiteStorage) ListNATMappings(ctx context.Context, filter *model.NATFi
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list NAT mappings: %w", err)
 	}
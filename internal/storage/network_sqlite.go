@@ -3,8 +3,12 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"net"
+	"net/netip"
 	"strings"
 
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -12,12 +16,15 @@ import (
 
 // Network operations
 
+const networkColumns = `id, name, subnet, vlan_id, gateway, dns_servers, mtu, datacenter_id, parent_id,
+	description, created_at, updated_at`
+
 // ListNetworks retrieves all networks matching the filter criteria
 func (s *SQLiteStorage) ListNetworks(ctx context.Context, filter *model.NetworkFilter) ([]model.Network, error) {
 
-	query := `SELECT id, name, subnet, vlan_id, datacenter_id, description, created_at, updated_at FROM networks`
+	query := `SELECT ` + networkColumns + ` FROM networks`
 	var args []any
-	var conditions []string
+	conditions := []string{"deleted_at IS NULL"}
 
 	if filter != nil {
 		if filter.Name != "" {
@@ -28,16 +35,23 @@ func (s *SQLiteStorage) ListNetworks(ctx context.Context, filter *model.NetworkF
 			conditions = append(conditions, "datacenter_id = ?")
 			args = append(args, filter.DatacenterID)
 		}
+		if filter.ParentID != "" {
+			conditions = append(conditions, "parent_id = ?")
+			args = append(args, filter.ParentID)
+		}
 		if filter.VLANID > 0 {
 			conditions = append(conditions, "vlan_id = ?")
 			args = append(args, filter.VLANID)
 		}
+		if len(filter.IDs) > 0 {
+			conditions = append(conditions, "id IN ("+placeholders(len(filter.IDs))+")")
+			for _, id := range filter.IDs {
+				args = append(args, id)
+			}
+		}
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
+	query += " WHERE " + strings.Join(conditions, " AND ")
 	query += " ORDER BY name"
 
 	var pg *model.Pagination
@@ -46,7 +60,7 @@ func (s *SQLiteStorage) ListNetworks(ctx context.Context, filter *model.NetworkF
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list networks: %w", err)
 	}
@@ -54,22 +68,11 @@ func (s *SQLiteStorage) ListNetworks(ctx context.Context, filter *model.NetworkF
 
 	var networks []model.Network
 	for rows.Next() {
-		var network model.Network
-		var vlanID sql.NullInt64
-		var datacenterID sql.NullString
-		if err := rows.Scan(
-			&network.ID, &network.Name, &network.Subnet, &vlanID,
-			&datacenterID, &network.Description, &network.CreatedAt, &network.UpdatedAt,
-		); err != nil {
+		network, err := scanNetwork(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan network: %w", err)
 		}
-		if vlanID.Valid {
-			network.VLANID = int(vlanID.Int64)
-		}
-		if datacenterID.Valid {
-			network.DatacenterID = datacenterID.String
-		}
-		networks = append(networks, network)
+		networks = append(networks, *network)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -90,15 +93,15 @@ func (s *SQLiteStorage) SearchNetworks(ctx context.Context, query string) ([]mod
 	}
 
 	ftsQuery := escapeFTSQuery(query)
+	limitClause, limitArgs := searchLimitClause(ctx)
 
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT n.id, n.name, n.subnet, n.vlan_id, n.datacenter_id, n.description,
-		       n.created_at, n.updated_at
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT n.id, n.name, n.subnet, n.vlan_id, n.gateway, n.dns_servers, n.mtu, n.datacenter_id,
+		       n.parent_id, n.description, n.created_at, n.updated_at
 		FROM networks n
 		INNER JOIN networks_fts fts ON n.id = fts.id
-		WHERE networks_fts MATCH ?
-		ORDER BY n.name
-	`, ftsQuery)
+		WHERE networks_fts MATCH ? AND n.deleted_at IS NULL
+		ORDER BY n.name`+limitClause, append([]any{ftsQuery}, limitArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search networks: %w", err)
 	}
@@ -106,27 +109,19 @@ func (s *SQLiteStorage) SearchNetworks(ctx context.Context, query string) ([]mod
 
 	var networks []model.Network
 	for rows.Next() {
-		var network model.Network
-		var vlanID sql.NullInt64
-		var datacenterID sql.NullString
-		if err := rows.Scan(
-			&network.ID, &network.Name, &network.Subnet, &vlanID,
-			&datacenterID, &network.Description, &network.CreatedAt, &network.UpdatedAt,
-		); err != nil {
+		network, err := scanNetwork(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan network: %w", err)
 		}
-		if vlanID.Valid {
-			network.VLANID = int(vlanID.Int64)
-		}
-		if datacenterID.Valid {
-			network.DatacenterID = datacenterID.String
-		}
-		networks = append(networks, network)
+		networks = append(networks, *network)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if err := checkSearchLimit(ctx, len(networks)); err != nil {
+		return nil, err
+	}
 
 	if networks == nil {
 		networks = []model.Network{}
@@ -140,17 +135,20 @@ func (s *SQLiteStorage) GetNetwork(ctx context.Context, id string) (*model.Netwo
 	if id == "" {
 		return nil, ErrInvalidID
 	}
+	return getNetwork(ctx, s.reader, id)
+}
 
-	network := &model.Network{}
-	var vlanID sql.NullInt64
-	var datacenterID sql.NullString
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, subnet, vlan_id, datacenter_id, description, created_at, updated_at
-		FROM networks WHERE id = ?
-	`, id).Scan(
-		&network.ID, &network.Name, &network.Subnet, &vlanID,
-		&datacenterID, &network.Description, &network.CreatedAt, &network.UpdatedAt,
-	)
+// rowGetter is the single-row query method shared by *sql.DB and *sql.Tx
+type rowGetter interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// getNetwork reads a network that is not in the trash
+func getNetwork(ctx context.Context, q rowGetter, id string) (*model.Network, error) {
+	network, err := scanNetwork(q.QueryRowContext(ctx, `
+		SELECT `+networkColumns+`
+		FROM networks WHERE id = ? AND deleted_at IS NULL
+	`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, ErrNetworkNotFound
@@ -159,16 +157,46 @@ func (s *SQLiteStorage) GetNetwork(ctx context.Context, id string) (*model.Netwo
 		return nil, fmt.Errorf("failed to get network: %w", err)
 	}
 
+	return network, nil
+}
+
+// scanNetwork reads a row of networkColumns
+func scanNetwork(row interface{ Scan(...any) error }) (*model.Network, error) {
+	network := &model.Network{}
+	var vlanID sql.NullInt64
+	var datacenterID, parentID sql.NullString
+	var dnsServers string
+	if err := row.Scan(
+		&network.ID, &network.Name, &network.Subnet, &vlanID, &network.Gateway, &dnsServers, &network.MTU,
+		&datacenterID, &parentID, &network.Description, &network.CreatedAt, &network.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
 	if vlanID.Valid {
 		network.VLANID = int(vlanID.Int64)
 	}
 	if datacenterID.Valid {
 		network.DatacenterID = datacenterID.String
 	}
+	if parentID.Valid {
+		network.ParentID = parentID.String
+	}
+	network.DNSServers = []string{}
+	json.Unmarshal([]byte(dnsServers), &network.DNSServers)
 
 	return network, nil
 }
 
+// dnsServersJSON encodes the DNS servers of a network for storage
+func dnsServersJSON(network *model.Network) string {
+	if network.DNSServers == nil {
+		network.DNSServers = []string{}
+	}
+	data, _ := json.Marshal(network.DNSServers)
+	return string(data)
+}
+
 // CreateNetwork creates a new network
 func (s *SQLiteStorage) CreateNetwork(ctx context.Context, network *model.Network) error {
 	if network == nil {
@@ -206,16 +234,23 @@ func (s *SQLiteStorage) createNetworkInTx(ctx context.Context, tx *sql.Tx, netwo
 	network.UpdatedAt = now
 
 	_, err := tx.ExecContext(ctx, `
-		INSERT INTO networks (id, name, subnet, vlan_id, datacenter_id, description, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, network.ID, network.Name, network.Subnet, nullInt(network.VLANID),
+		INSERT INTO networks (id, name, subnet, vlan_id, gateway, dns_servers, mtu, datacenter_id, description, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, network.ID, network.Name, network.Subnet, nullInt(network.VLANID), network.Gateway, dnsServersJSON(network), network.MTU,
 		nullString(network.DatacenterID), network.Description, network.CreatedAt, network.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create network: %w", err)
 	}
 
-	return nil
+	if err := relinkNetworks(ctx, tx, network.DatacenterID); err != nil {
+		return err
+	}
+	if network.ParentID, err = networkParentID(ctx, tx, network.ID); err != nil {
+		return err
+	}
+
+	return recordVersion(ctx, tx, "network", network.ID, model.VersionActionCreate, network)
 }
 
 // UpdateNetwork updates an existing network
@@ -227,34 +262,71 @@ func (s *SQLiteStorage) UpdateNetwork(ctx context.Context, network *model.Networ
 		return ErrInvalidID
 	}
 
-	// Check if network exists
-	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM networks WHERE id = ?)`, network.ID).Scan(&exists)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to check network existence: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	if !exists {
+	defer tx.Rollback()
+
+	// Check if network exists, remembering its datacenter so the hierarchy it
+	// leaves behind can be relinked
+	var previousDatacenterID sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT datacenter_id FROM networks WHERE id = ? AND deleted_at IS NULL`, network.ID).Scan(&previousDatacenterID)
+	if err == sql.ErrNoRows {
 		return ErrNetworkNotFound
 	}
+	if err != nil {
+		return fmt.Errorf("failed to check network existence: %w", err)
+	}
 
 	network.UpdatedAt = nowUTC()
 
-	_, err = s.db.ExecContext(ctx, `
-		UPDATE networks SET name = ?, subnet = ?, vlan_id = ?, datacenter_id = ?, description = ?, updated_at = ?
-		WHERE id = ?
-	`, network.Name, network.Subnet, nullInt(network.VLANID),
-		nullString(network.DatacenterID), network.Description, network.UpdatedAt, network.ID)
+	versionClause, versionArgs := updatedAtClause(ctx)
+	result, err := tx.ExecContext(ctx, `
+		UPDATE networks SET name = ?, subnet = ?, vlan_id = ?, gateway = ?, dns_servers = ?, mtu = ?,
+			datacenter_id = ?, description = ?, updated_at = ?
+		WHERE id = ?`+versionClause,
+		append([]any{network.Name, network.Subnet, nullInt(network.VLANID), network.Gateway, dnsServersJSON(network), network.MTU,
+			nullString(network.DatacenterID), network.Description, network.UpdatedAt, network.ID}, versionArgs...)...)
 
 	if err != nil {
 		return fmt.Errorf("failed to update network: %w", err)
 	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrModified
+	}
+
+	if err := relinkNetworks(ctx, tx, network.DatacenterID); err != nil {
+		return err
+	}
+	if previousDatacenterID.String != network.DatacenterID {
+		if err := relinkNetworks(ctx, tx, previousDatacenterID.String); err != nil {
+			return err
+		}
+	}
+	if network.ParentID, err = networkParentID(ctx, tx, network.ID); err != nil {
+		return err
+	}
+
+	if err := recordVersion(ctx, tx, "network", network.ID, model.VersionActionUpdate, network); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
 	s.auditLog(ctx, "update", "network", network.ID, network)
 	return nil
 }
 
-// DeleteNetwork removes a network by ID
-func (s *SQLiteStorage) DeleteNetwork(ctx context.Context, id string) error {
+// DeleteNetwork removes a network by ID. Its pools and discovery data are
+// deleted whatever the policy, with each pool handled under the same policy.
+// Addresses on the network are unlinked under detach and deleted under
+// cascade. Deny refuses while any of these exist.
+// Nested networks are never dependents: they move up to the next enclosing
+// network.
+func (s *SQLiteStorage) DeleteNetwork(ctx context.Context, id string, policy model.DeletePolicy) error {
 	if id == "" {
 		return ErrInvalidID
 	}
@@ -265,7 +337,7 @@ func (s *SQLiteStorage) DeleteNetwork(ctx context.Context, id string) error {
 	}
 	defer tx.Rollback()
 
-	if err := s.deleteNetworkInTx(ctx, tx, id); err != nil {
+	if err := s.deleteNetworkInTx(ctx, tx, id, policy); err != nil {
 		return err
 	}
 
@@ -277,40 +349,296 @@ func (s *SQLiteStorage) DeleteNetwork(ctx context.Context, id string) error {
 	return nil
 }
 
+// networkDependents are the records that refer to a network
+func (s *SQLiteStorage) networkDependents() []dependent {
+	return []dependent{
+		{
+			name:  "pools",
+			count: `SELECT COUNT(*) FROM network_pools WHERE network_id = ?1`,
+			remove: func(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error {
+				poolIDs, err := idsInTx(ctx, tx, `SELECT id FROM network_pools WHERE network_id = ?`, id)
+				if err != nil {
+					return err
+				}
+				for _, poolID := range poolIDs {
+					if err := s.deleteNetworkPoolInTx(ctx, tx, poolID, policy); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:  "addresses",
+			count: `SELECT COUNT(*) FROM addresses WHERE network_id = ?1`,
+			remove: unlinkOrDelete(
+				`UPDATE addresses SET network_id = NULL WHERE network_id = ?1`,
+				`DELETE FROM addresses WHERE network_id = ?1`),
+		},
+		{
+			name:   "discovered_devices",
+			count:  `SELECT COUNT(*) FROM discovered_devices WHERE network_id = ?1`,
+			remove: unlinkOrDelete("", `DELETE FROM discovered_devices WHERE network_id = ?1`),
+		},
+		{
+			name:   "discovery_scans",
+			count:  `SELECT COUNT(*) FROM discovery_scans WHERE network_id = ?1`,
+			remove: unlinkOrDelete("", `DELETE FROM discovery_scans WHERE network_id = ?1`),
+		},
+		{
+			name:   "discovery_rules",
+			count:  `SELECT COUNT(*) FROM discovery_rules WHERE network_id = ?1`,
+			remove: unlinkOrDelete("", `DELETE FROM discovery_rules WHERE network_id = ?1`),
+		},
+	}
+}
+
 // deleteNetworkInTx deletes a network within an existing transaction
-func (s *SQLiteStorage) deleteNetworkInTx(ctx context.Context, tx *sql.Tx, id string) error {
+func (s *SQLiteStorage) deleteNetworkInTx(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error {
 
-	// Check if network exists
-	var exists bool
-	err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM networks WHERE id = ?)`, id).Scan(&exists)
+	// Check if network exists, remembering its datacenter so its children can
+	// be relinked to the next enclosing network
+	var datacenterID sql.NullString
+	var trashed bool
+	err := tx.QueryRowContext(ctx, `SELECT datacenter_id, deleted_at IS NOT NULL FROM networks WHERE id = ?`, id).Scan(&datacenterID, &trashed)
+	if err == sql.ErrNoRows {
+		return ErrNetworkNotFound
+	}
 	if err != nil {
 		return fmt.Errorf("failed to check network existence: %w", err)
 	}
-	if !exists {
-		return ErrNetworkNotFound
+
+	if err := applyDeletePolicy(ctx, tx, "network", id, policy, s.networkDependents()); err != nil {
+		return err
 	}
 
-	// Unlink addresses from this network (set network_id to NULL)
-	_, err = tx.ExecContext(ctx, `UPDATE addresses SET network_id = NULL WHERE network_id = ?`, id)
+	// Delete the network
+	_, err = tx.ExecContext(ctx, `DELETE FROM networks WHERE id = ?`, id)
 	if err != nil {
-		return fmt.Errorf("failed to unlink addresses: %w", err)
+		return fmt.Errorf("failed to delete network: %w", err)
+	}
+
+	if err := relinkNetworks(ctx, tx, datacenterID.String); err != nil {
+		return err
 	}
 
-	// Delete network pools (cascades via foreign key, but explicit for clarity)
-	_, err = tx.ExecContext(ctx, `DELETE FROM network_pools WHERE network_id = ?`, id)
+	// The delete was recorded when the network went to the trash
+	if trashed {
+		return nil
+	}
+	return recordVersion(ctx, tx, "network", id, model.VersionActionDelete, nil)
+}
+
+// AllocateSubnet creates network as the first free subnet of prefixLength
+// inside the parent network. A block is free when it overlaps no other network
+// nested in the parent. The search and the insert share one write
+// transaction, so concurrent callers never receive the same subnet.
+func (s *SQLiteStorage) AllocateSubnet(ctx context.Context, parentID string, prefixLength int, network *model.Network) error {
+	if parentID == "" {
+		return ErrInvalidID
+	}
+	if network == nil {
+		return fmt.Errorf("network is nil")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to delete network pools: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Delete the network
-	_, err = tx.ExecContext(ctx, `DELETE FROM networks WHERE id = ?`, id)
+	var parentSubnet string
+	var datacenterID sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT subnet, datacenter_id FROM networks WHERE id = ? AND deleted_at IS NULL`, parentID).Scan(&parentSubnet, &datacenterID)
+	if err == sql.ErrNoRows {
+		return ErrNetworkNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("failed to delete network: %w", err)
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+	parent, err := netip.ParsePrefix(parentSubnet)
+	if err != nil {
+		return fmt.Errorf("failed to parse subnet CIDR: %w", err)
+	}
+	parent = parent.Masked()
+	if prefixLength <= parent.Bits() || prefixLength > parent.Addr().BitLen() {
+		return fmt.Errorf("prefix length /%d does not fit in %s", prefixLength, parent)
 	}
 
+	// Trashed networks do not hold their subnet, as with networks created by
+	// hand; restoring one whose subnet was taken meanwhile is refused
+	rows, err := tx.QueryContext(ctx, `
+		SELECT subnet FROM networks WHERE datacenter_id IS ? AND id != ? AND deleted_at IS NULL
+	`, datacenterID, parentID)
+	if err != nil {
+		return fmt.Errorf("failed to list networks: %w", err)
+	}
+	var taken []netip.Prefix
+	for rows.Next() {
+		var subnet string
+		if err := rows.Scan(&subnet); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan network: %w", err)
+		}
+		prefix, err := netip.ParsePrefix(subnet)
+		if err != nil || prefix.Bits() <= parent.Bits() || !parent.Overlaps(prefix) {
+			continue
+		}
+		taken = append(taken, prefix.Masked())
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	subnet, ok := nextFreeSubnet(parent, prefixLength, taken)
+	if !ok {
+		return ErrSubnetNotAvailable
+	}
+
+	network.Subnet = subnet.String()
+	network.DatacenterID = datacenterID.String
+	if network.Name == "" {
+		network.Name = network.Subnet
+	}
+	if err := s.createNetworkInTx(ctx, tx, network); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.auditLog(ctx, "create", "network", network.ID, network)
 	return nil
 }
 
+// nextFreeSubnet returns the lowest block of prefixLength inside parent that
+// overlaps none of the taken prefixes. Each collision skips past the larger of
+// the candidate and the prefix it hit, so the search is bounded by the number
+// of taken prefixes rather than the size of the parent.
+func nextFreeSubnet(parent netip.Prefix, prefixLength int, taken []netip.Prefix) (netip.Prefix, bool) {
+	last := lastAddr(parent)
+	addr := parent.Addr()
+	for {
+		candidate := netip.PrefixFrom(addr, prefixLength)
+		end := lastAddr(candidate)
+		free := true
+		for _, t := range taken {
+			if !candidate.Overlaps(t) {
+				continue
+			}
+			free = false
+			if t.Bits() < prefixLength {
+				if tEnd := lastAddr(t); tEnd.Compare(end) > 0 {
+					end = tEnd
+				}
+			}
+		}
+		if free {
+			return candidate, true
+		}
+		if end.Compare(last) >= 0 {
+			return netip.Prefix{}, false
+		}
+		addr = end.Next()
+	}
+}
+
+// lastAddr returns the highest address of a masked prefix
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// relinkNetworks recomputes parent_id for every live network in a datacenter.
+// A network's parent is the smallest other network of the same family whose
+// subnet strictly contains it; networks sharing an identical subnet are not
+// nested. Trashed networks are neither parents nor relinked; they get their
+// place back when restored. Only rows whose parent changes are written, and
+// updated_at is left alone since the hierarchy is derived rather than edited.
+func relinkNetworks(ctx context.Context, tx *sql.Tx, datacenterID string) error {
+	return relinkNetworksWhere(ctx, tx, `datacenter_id IS ? AND deleted_at IS NULL`, nullString(datacenterID))
+}
+
+// relinkNetworksWhere runs the relinking of relinkNetworks over the networks
+// matching the condition where
+func relinkNetworksWhere(ctx context.Context, tx *sql.Tx, where string, args ...any) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, subnet, parent_id FROM networks WHERE `+where, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list networks for hierarchy: %w", err)
+	}
+
+	type node struct {
+		id       string
+		ipNet    *net.IPNet
+		ones     int
+		parentID string
+	}
+	var nodes []node
+	for rows.Next() {
+		var id, subnet string
+		var parentID sql.NullString
+		if err := rows.Scan(&id, &subnet, &parentID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan network for hierarchy: %w", err)
+		}
+		n := node{id: id, parentID: parentID.String}
+		if _, ipNet, err := net.ParseCIDR(subnet); err == nil {
+			n.ipNet = ipNet
+			n.ones, _ = ipNet.Mask.Size()
+		}
+		nodes = append(nodes, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, child := range nodes {
+		var parent *node
+		if child.ipNet != nil {
+			for i := range nodes {
+				candidate := &nodes[i]
+				if candidate.ipNet == nil || candidate.ones >= child.ones ||
+					len(candidate.ipNet.IP) != len(child.ipNet.IP) ||
+					!candidate.ipNet.Contains(child.ipNet.IP) {
+					continue
+				}
+				if parent == nil || candidate.ones > parent.ones ||
+					(candidate.ones == parent.ones && candidate.id < parent.id) {
+					parent = candidate
+				}
+			}
+		}
+
+		parentID := ""
+		if parent != nil {
+			parentID = parent.id
+		}
+		if parentID == child.parentID {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE networks SET parent_id = ? WHERE id = ?`, nullString(parentID), child.id); err != nil {
+			return fmt.Errorf("failed to link network parent: %w", err)
+		}
+	}
+	return nil
+}
+
+// networkParentID reads the parent assigned to a network by relinkNetworks
+func networkParentID(ctx context.Context, tx *sql.Tx, id string) (string, error) {
+	var parentID sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT parent_id FROM networks WHERE id = ?`, id).Scan(&parentID); err != nil {
+		return "", fmt.Errorf("failed to read network parent: %w", err)
+	}
+	return parentID.String, nil
+}
+
 // GetNetworkDevices retrieves all devices that have addresses in a network
 func (s *SQLiteStorage) GetNetworkDevices(ctx context.Context, networkID string) ([]model.Device, error) {
 	if networkID == "" {
@@ -319,7 +647,7 @@ func (s *SQLiteStorage) GetNetworkDevices(ctx context.Context, networkID string)
 
 	// Check if network exists
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM networks WHERE id = ?)`, networkID).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM networks WHERE id = ? AND deleted_at IS NULL)`, networkID).Scan(&exists)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check network existence: %w", err)
 	}
@@ -344,72 +672,80 @@ func (s *SQLiteStorage) GetNetworkUtilization(ctx context.Context, networkID str
 	}
 
 	// Calculate total IPs from CIDR
-	totalIPs, err := calculateCIDRSize(network.Subnet)
+	size, err := subnetSize(network.Subnet)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse subnet CIDR: %w", err)
 	}
 
 	// Count used IPs (addresses assigned to this network)
 	var usedIPs int
-	err = s.db.QueryRowContext(ctx, `
+	err = s.reader.QueryRowContext(ctx, `
 		SELECT COUNT(DISTINCT ip) FROM addresses WHERE network_id = ?
 	`, networkID).Scan(&usedIPs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count used IPs: %w", err)
 	}
 
-	availableIPs := max(totalIPs-usedIPs, 0)
+	used := big.NewInt(int64(usedIPs))
+	available := new(big.Int).Sub(size.total, used)
+	if available.Sign() < 0 {
+		available.SetInt64(0)
+	}
 
 	var utilization float64
-	if totalIPs > 0 {
-		utilization = float64(usedIPs) / float64(totalIPs) * 100
+	if size.total.Sign() > 0 {
+		utilization, _ = new(big.Float).Quo(new(big.Float).SetInt(used), new(big.Float).SetInt(size.total)).Float64()
+		utilization *= 100
 	}
 
 	return &model.NetworkUtilization{
-		NetworkID:    networkID,
-		TotalIPs:     totalIPs,
-		UsedIPs:      usedIPs,
-		AvailableIPs: availableIPs,
-		Utilization:  utilization,
+		NetworkID:          networkID,
+		Family:             size.family,
+		PrefixLength:       size.prefixLength,
+		TotalIPs:           saturatingInt(size.total),
+		UsedIPs:            usedIPs,
+		AvailableIPs:       saturatingInt(available),
+		TotalAddresses:     size.total.String(),
+		AvailableAddresses: available.String(),
+		Utilization:        utilization,
 	}, nil
 }
 
-// calculateCIDRSize calculates the number of usable host IPs in a CIDR block
-func calculateCIDRSize(cidr string) (int, error) {
-	// Parse CIDR (e.g., "192.168.1.0/24")
+// cidrSize describes the host addresses of a subnet
+type cidrSize struct {
+	family       string
+	prefixLength int
+	total        *big.Int
+}
+
+// subnetSize counts the usable host addresses of a CIDR block. IPv4 subnets
+// of more than two addresses lose the network and broadcast addresses; IPv6
+// has no broadcast, so every address counts.
+func subnetSize(cidr string) (cidrSize, error) {
 	_, ipNet, err := net.ParseCIDR(cidr)
 	if err != nil {
-		return 0, err
+		return cidrSize{}, err
 	}
 
-	// Get the prefix length
 	ones, bits := ipNet.Mask.Size()
 	if bits == 0 {
-		return 0, fmt.Errorf("invalid mask")
-	}
-
-	// Calculate total addresses: 2^(bits - ones)
-	hostBits := bits - ones
-	if hostBits <= 0 {
-		return 1, nil // /32 or /128 has 1 address
-	}
-
-	// For large subnets, cap at a reasonable number
-	if hostBits > 20 {
-		// For subnets larger than /12, return a capped value
-		return 1 << 20, nil // ~1 million
+		return cidrSize{}, fmt.Errorf("invalid mask")
 	}
 
-	total := 1 << hostBits
-
-	// Subtract network and broadcast addresses for IPv4 subnets with more than 2 hosts
-	if bits == 32 && hostBits >= 2 {
-		total -= 2 // Subtract network and broadcast addresses
+	size := cidrSize{family: "ipv6", prefixLength: ones, total: new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))}
+	if bits == 32 {
+		size.family = "ipv4"
+		if bits-ones >= 2 {
+			size.total.Sub(size.total, big.NewInt(2))
+		}
 	}
+	return size, nil
+}
 
-	if total < 0 {
-		total = 0
+// saturatingInt returns n as an int, or the largest int if n does not fit
+func saturatingInt(n *big.Int) int {
+	if !n.IsInt64() || n.Int64() > math.MaxInt {
+		return math.MaxInt
 	}
-
-	return total, nil
+	return int(n.Int64())
 }
@@ -2,6 +2,10 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"math"
+	"net/netip"
+	"slices"
 	"testing"
 
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -128,6 +132,9 @@ func TestNetworkOperations_Update(t *testing.T) {
 	network.Name = "Updated Network"
 	network.Subnet = "10.0.0.0/16"
 	network.VLANID = 200
+	network.Gateway = "10.0.0.1"
+	network.DNSServers = []string{"10.0.0.53", "10.0.0.54"}
+	network.MTU = 9000
 	network.Description = "Updated description"
 
 	if err := storage.UpdateNetwork(context.Background(), network); err != nil {
@@ -152,6 +159,9 @@ func TestNetworkOperations_Update(t *testing.T) {
 	if retrieved.Description != "Updated description" {
 		t.Errorf("expected description 'Updated description', got '%s'", retrieved.Description)
 	}
+	if retrieved.Gateway != "10.0.0.1" || !slices.Equal(retrieved.DNSServers, []string{"10.0.0.53", "10.0.0.54"}) || retrieved.MTU != 9000 {
+		t.Errorf("expected gateway, DNS servers and MTU to be stored, got %q %v %d", retrieved.Gateway, retrieved.DNSServers, retrieved.MTU)
+	}
 	if !retrieved.UpdatedAt.After(originalUpdatedAt) {
 		t.Error("updated_at should be updated")
 	}
@@ -198,7 +208,7 @@ func TestNetworkOperations_Delete(t *testing.T) {
 	}
 
 	// Delete network
-	if err := storage.DeleteNetwork(context.Background(), network.ID); err != nil {
+	if err := storage.DeleteNetwork(context.Background(), network.ID, model.DeleteDetach); err != nil {
 		t.Fatalf("DeleteNetwork failed: %v", err)
 	}
 
@@ -231,7 +241,7 @@ func TestNetworkOperations_DeleteWithAddresses(t *testing.T) {
 	}
 
 	// Delete network (should unlink addresses)
-	if err := storage.DeleteNetwork(context.Background(), network.ID); err != nil {
+	if err := storage.DeleteNetwork(context.Background(), network.ID, model.DeleteDetach); err != nil {
 		t.Fatalf("DeleteNetwork failed: %v", err)
 	}
 
@@ -289,7 +299,7 @@ func TestNetworkOperations_DeleteNotFound(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
 
-	err := storage.DeleteNetwork(context.Background(), "non-existent-id")
+	err := storage.DeleteNetwork(context.Background(), "non-existent-id", model.DeleteDetach)
 	if err != ErrNetworkNotFound {
 		t.Errorf("expected ErrNetworkNotFound, got %v", err)
 	}
@@ -299,7 +309,7 @@ func TestNetworkOperations_DeleteInvalidID(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
 
-	err := storage.DeleteNetwork(context.Background(), "")
+	err := storage.DeleteNetwork(context.Background(), "", model.DeleteDetach)
 	if err != ErrInvalidID {
 		t.Errorf("expected ErrInvalidID, got %v", err)
 	}
@@ -548,6 +558,40 @@ func TestNetworkOperations_GetNetworkUtilization(t *testing.T) {
 	}
 }
 
+func TestNetworkOperations_GetNetworkUtilizationIPv6(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+
+	network := &model.Network{Name: "v6", Subnet: "2001:db8:1::/64"}
+	if err := storage.CreateNetwork(context.Background(), network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	device := &model.Device{
+		Name:      "v6-host",
+		Addresses: []model.Address{{IP: "2001:db8:1::10", Type: "ipv6", NetworkID: network.ID}},
+	}
+	if err := storage.CreateDevice(context.Background(), device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+
+	util, err := storage.GetNetworkUtilization(context.Background(), network.ID)
+	if err != nil {
+		t.Fatalf("GetNetworkUtilization failed: %v", err)
+	}
+	if util.Family != "ipv6" || util.PrefixLength != 64 {
+		t.Errorf("expected ipv6 /64, got %s /%d", util.Family, util.PrefixLength)
+	}
+	if util.TotalAddresses != "18446744073709551616" || util.AvailableAddresses != "18446744073709551615" {
+		t.Errorf("expected exact counts for a /64, got total %s available %s", util.TotalAddresses, util.AvailableAddresses)
+	}
+	if util.TotalIPs != math.MaxInt || util.UsedIPs != 1 {
+		t.Errorf("expected saturated total and 1 used, got %d and %d", util.TotalIPs, util.UsedIPs)
+	}
+	if util.Utilization <= 0 || util.Utilization > 1e-15 {
+		t.Errorf("expected a tiny non-zero utilization, got %g", util.Utilization)
+	}
+}
+
 func TestNetworkOperations_GetNetworkUtilizationNotFound(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
@@ -591,24 +635,29 @@ func TestNetworkOperations_GetNetworkUtilizationEmpty(t *testing.T) {
 	}
 }
 
-func TestCalculateCIDRSize(t *testing.T) {
+func TestSubnetSize(t *testing.T) {
 	tests := []struct {
 		cidr     string
-		expected int
+		expected string
+		family   string
 		hasError bool
 	}{
-		{"192.168.1.0/24", 254, false}, // 256 - 2 (network + broadcast)
-		{"10.0.0.0/16", 65534, false},  // 65536 - 2
-		{"192.168.1.0/30", 2, false},   // 4 - 2
-		{"192.168.1.0/31", 2, false},   // Point-to-point link
-		{"192.168.1.1/32", 1, false},   // Single host
-		{"invalid", 0, true},           // Invalid CIDR
-		{"192.168.1.0", 0, true},       // Missing prefix
+		{"192.168.1.0/24", "254", "ipv4", false},                 // 256 - 2 (network + broadcast)
+		{"10.0.0.0/16", "65534", "ipv4", false},                  // 65536 - 2
+		{"10.0.0.0/8", "16777214", "ipv4", false},                // Not capped
+		{"192.168.1.0/30", "2", "ipv4", false},                   // 4 - 2
+		{"192.168.1.0/31", "2", "ipv4", false},                   // Point-to-point link
+		{"192.168.1.1/32", "1", "ipv4", false},                   // Single host
+		{"2001:db8::/64", "18446744073709551616", "ipv6", false}, // No broadcast in IPv6
+		{"2001:db8::/120", "256", "ipv6", false},
+		{"2001:db8::1/128", "1", "ipv6", false},
+		{"invalid", "", "", true},     // Invalid CIDR
+		{"192.168.1.0", "", "", true}, // Missing prefix
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.cidr, func(t *testing.T) {
-			result, err := calculateCIDRSize(tt.cidr)
+			result, err := subnetSize(tt.cidr)
 			if tt.hasError {
 				if err == nil {
 					t.Errorf("expected error for CIDR %s", tt.cidr)
@@ -619,8 +668,8 @@ func TestCalculateCIDRSize(t *testing.T) {
 				t.Errorf("unexpected error for CIDR %s: %v", tt.cidr, err)
 				return
 			}
-			if result != tt.expected {
-				t.Errorf("calculateCIDRSize(%s) = %d, expected %d", tt.cidr, result, tt.expected)
+			if result.total.String() != tt.expected || result.family != tt.family {
+				t.Errorf("subnetSize(%s) = %s %s, expected %s %s", tt.cidr, result.total, result.family, tt.expected, tt.family)
 			}
 		})
 	}
@@ -677,7 +726,7 @@ func TestDeleteNetworkWithPools(t *testing.T) {
 	storage.CreateNetworkPool(context.Background(), pool)
 
 	// Delete network (should cascade to pools)
-	if err := storage.DeleteNetwork(context.Background(), network.ID); err != nil {
+	if err := storage.DeleteNetwork(context.Background(), network.ID, model.DeleteDetach); err != nil {
 		t.Fatalf("DeleteNetwork failed: %v", err)
 	}
 
@@ -706,13 +755,13 @@ func TestNetworkWithZeroVLAN(t *testing.T) {
 	}
 }
 
-func TestCalculateCIDRSizeEdgeCases(t *testing.T) {
+func TestSubnetSizeEdgeCases(t *testing.T) {
 	tests := []struct {
 		cidr     string
 		expected int
 		hasError bool
 	}{
-		{"10.0.0.0/8", 1 << 20, false},   // Large network (capped at ~1M)
+		{"10.0.0.0/8", 16777214, false},  // Large network, counted exactly
 		{"192.168.1.128/25", 126, false}, // /25 subnet
 		{"192.168.1.192/26", 62, false},  // /26 subnet
 		{"192.168.1.240/28", 14, false},  // /28 subnet
@@ -721,7 +770,7 @@ func TestCalculateCIDRSizeEdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.cidr, func(t *testing.T) {
-			result, err := calculateCIDRSize(tt.cidr)
+			size, err := subnetSize(tt.cidr)
 			if tt.hasError {
 				if err == nil {
 					t.Errorf("expected error for CIDR %s", tt.cidr)
@@ -732,9 +781,195 @@ func TestCalculateCIDRSizeEdgeCases(t *testing.T) {
 				t.Errorf("unexpected error for CIDR %s: %v", tt.cidr, err)
 				return
 			}
-			if result != tt.expected {
-				t.Errorf("calculateCIDRSize(%s) = %d, expected %d", tt.cidr, result, tt.expected)
+			if size.total.Int64() != int64(tt.expected) {
+				t.Errorf("subnetSize(%s) = %s, expected %d", tt.cidr, size.total, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNetworkOperations_Hierarchy(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "DC1"}
+	if err := storage.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("CreateDatacenter failed: %v", err)
+	}
+
+	// Children created before their supernet are linked once it appears
+	leaf := &model.Network{Name: "leaf", Subnet: "10.0.1.0/26", DatacenterID: dc.ID}
+	mid := &model.Network{Name: "mid", Subnet: "10.0.1.0/24", DatacenterID: dc.ID}
+	if err := storage.CreateNetwork(ctx, leaf); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	if err := storage.CreateNetwork(ctx, mid); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	top := &model.Network{Name: "top", Subnet: "10.0.0.0/16", DatacenterID: dc.ID}
+	if err := storage.CreateNetwork(ctx, top); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	other := &model.Network{Name: "elsewhere", Subnet: "10.0.2.0/24"}
+	if err := storage.CreateNetwork(ctx, other); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	v6 := &model.Network{Name: "v6", Subnet: "2001:db8::/64", DatacenterID: dc.ID}
+	if err := storage.CreateNetwork(ctx, v6); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+
+	parentOf := func(id string) string {
+		t.Helper()
+		n, err := storage.GetNetwork(ctx, id)
+		if err != nil {
+			t.Fatalf("GetNetwork failed: %v", err)
+		}
+		return n.ParentID
+	}
+
+	if top.ParentID != "" || parentOf(top.ID) != "" {
+		t.Errorf("expected top to have no parent, got %q", parentOf(top.ID))
+	}
+	if got := parentOf(mid.ID); got != top.ID {
+		t.Errorf("expected mid parent %s, got %q", top.ID, got)
+	}
+	if got := parentOf(leaf.ID); got != mid.ID {
+		t.Errorf("expected leaf parent %s, got %q", mid.ID, got)
+	}
+	if got := parentOf(other.ID); got != "" {
+		t.Errorf("expected a network in another datacenter to have no parent, got %q", got)
+	}
+	if got := parentOf(v6.ID); got != "" {
+		t.Errorf("expected an IPv6 network to have no IPv4 parent, got %q", got)
+	}
+
+	children, err := storage.ListNetworks(ctx, &model.NetworkFilter{ParentID: top.ID})
+	if err != nil {
+		t.Fatalf("ListNetworks failed: %v", err)
+	}
+	if len(children) != 1 || children[0].ID != mid.ID {
+		t.Errorf("expected mid as the only child of top, got %+v", children)
+	}
+
+	// Resizing mid so it no longer contains leaf moves leaf up to top
+	mid.Subnet = "10.0.4.0/24"
+	if err := storage.UpdateNetwork(ctx, mid); err != nil {
+		t.Fatalf("UpdateNetwork failed: %v", err)
+	}
+	if got := parentOf(leaf.ID); got != top.ID {
+		t.Errorf("expected leaf parent %s after resize, got %q", top.ID, got)
+	}
+
+	// Deleting the supernet leaves its children at the top level
+	if err := storage.DeleteNetwork(ctx, top.ID, model.DeleteDetach); err != nil {
+		t.Fatalf("DeleteNetwork failed: %v", err)
+	}
+	if got := parentOf(leaf.ID); got != "" {
+		t.Errorf("expected leaf to have no parent after delete, got %q", got)
+	}
+}
+
+func TestNextFreeSubnet(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent string
+		length int
+		taken  []string
+		want   string
+	}{
+		{"empty", "10.0.0.0/16", 24, nil, "10.0.0.0/24"},
+		{"skips taken", "10.0.0.0/16", 24, []string{"10.0.0.0/24", "10.0.1.0/24"}, "10.0.2.0/24"},
+		{"fills gap", "10.0.0.0/16", 24, []string{"10.0.0.0/24", "10.0.2.0/24"}, "10.0.1.0/24"},
+		{"skips larger block", "10.0.0.0/16", 26, []string{"10.0.0.0/23"}, "10.0.2.0/26"},
+		{"skips block with small subnet", "10.0.0.0/16", 24, []string{"10.0.0.64/26"}, "10.0.1.0/24"},
+		{"full", "10.0.0.0/23", 24, []string{"10.0.0.0/24", "10.0.1.0/24"}, ""},
+		{"end of address space", "255.255.255.0/24", 25, []string{"255.255.255.0/25", "255.255.255.128/25"}, ""},
+		{"ipv6", "2001:db8::/48", 64, []string{"2001:db8::/64", "2001:db8:0:1::/64", "2001:db8:0:2::/63"}, "2001:db8:0:4::/64"},
+		{"ignores other family", "10.0.0.0/16", 24, []string{"2001:db8::/64"}, "10.0.0.0/24"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var taken []netip.Prefix
+			for _, s := range tt.taken {
+				taken = append(taken, netip.MustParsePrefix(s))
+			}
+			got, ok := nextFreeSubnet(netip.MustParsePrefix(tt.parent), tt.length, taken)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("expected no free subnet, got %s", got)
+				}
+				return
+			}
+			if !ok || got.String() != tt.want {
+				t.Errorf("expected %s, got %s (ok=%v)", tt.want, got, ok)
 			}
 		})
 	}
 }
+
+func TestNetworkOperations_AllocateSubnet(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "DC1"}
+	if err := storage.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("CreateDatacenter failed: %v", err)
+	}
+	supernet := &model.Network{Name: "super", Subnet: "10.1.0.0/23", DatacenterID: dc.ID}
+	if err := storage.CreateNetwork(ctx, supernet); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	if err := storage.CreateNetwork(ctx, &model.Network{Name: "existing", Subnet: "10.1.0.0/25", DatacenterID: dc.ID}); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	// The same range in another datacenter does not block allocation
+	if err := storage.CreateNetwork(ctx, &model.Network{Name: "unrelated", Subnet: "10.1.0.128/25"}); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+
+	first := &model.Network{}
+	if err := storage.AllocateSubnet(ctx, supernet.ID, 25, first); err != nil {
+		t.Fatalf("AllocateSubnet failed: %v", err)
+	}
+	if first.Subnet != "10.1.0.128/25" || first.Name != "10.1.0.128/25" {
+		t.Errorf("expected 10.1.0.128/25 named after its subnet, got %s %q", first.Subnet, first.Name)
+	}
+	if first.DatacenterID != dc.ID || first.ParentID != supernet.ID {
+		t.Errorf("expected the allocation in %s under %s, got %s under %s", dc.ID, supernet.ID, first.DatacenterID, first.ParentID)
+	}
+
+	second := &model.Network{Name: "second"}
+	if err := storage.AllocateSubnet(ctx, supernet.ID, 24, second); err != nil {
+		t.Fatalf("AllocateSubnet failed: %v", err)
+	}
+	if second.Subnet != "10.1.1.0/24" {
+		t.Errorf("expected 10.1.1.0/24, got %s", second.Subnet)
+	}
+
+	if err := storage.AllocateSubnet(ctx, supernet.ID, 30, &model.Network{}); !errors.Is(err, ErrSubnetNotAvailable) {
+		t.Errorf("expected ErrSubnetNotAvailable from a full supernet, got %v", err)
+	}
+	if err := storage.AllocateSubnet(ctx, "missing", 24, &model.Network{}); !errors.Is(err, ErrNetworkNotFound) {
+		t.Errorf("expected ErrNetworkNotFound, got %v", err)
+	}
+
+	// A trashed network frees its subnet, and cannot come back once it is
+	// allocated again
+	if err := storage.TrashResource(ctx, model.TrashTypeNetwork, second.ID, ""); err != nil {
+		t.Fatalf("TrashResource failed: %v", err)
+	}
+	third := &model.Network{Name: "third"}
+	if err := storage.AllocateSubnet(ctx, supernet.ID, 24, third); err != nil {
+		t.Fatalf("AllocateSubnet next to a trashed network failed: %v", err)
+	}
+	if third.Subnet != "10.1.1.0/24" {
+		t.Errorf("expected the trashed 10.1.1.0/24 to be reused, got %s", third.Subnet)
+	}
+	if err := storage.RestoreTrashItem(ctx, second.ID); !errors.Is(err, ErrSubnetInUse) {
+		t.Errorf("expected ErrSubnetInUse restoring the trashed network, got %v", err)
+	}
+}
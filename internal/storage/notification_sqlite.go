@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const notificationChannelColumns = `id, name, type, url, secret, smtp_host, smtp_port, username, email_from, email_to,
+	user_ids, events, title_template, body_template, changes_only, active, description, created_by, created_at, updated_at`
+
+// CreateNotificationChannel stores a new notification channel
+func (s *SQLiteStorage) CreateNotificationChannel(ctx context.Context, ch *model.NotificationChannel) error {
+	if ch.ID == "" {
+		ch.ID = newUUID()
+	}
+	ch.CreatedAt = nowUTC()
+	ch.UpdatedAt = ch.CreatedAt
+
+	to, users, events, err := marshalNotificationLists(ch)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO notification_channels (`+notificationChannelColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, ch.ID, ch.Name, ch.Type, ch.URL, ch.Secret, ch.SMTPHost, ch.SMTPPort, ch.Username, ch.From, to,
+		users, events, ch.TitleTemplate, ch.BodyTemplate, ch.ChangesOnly, ch.Active, ch.Description, ch.CreatedBy,
+		ch.CreatedAt, ch.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	s.auditLog(ctx, "create", "notification_channel", ch.ID, ch)
+	return nil
+}
+
+// GetNotificationChannel retrieves a notification channel by ID
+func (s *SQLiteStorage) GetNotificationChannel(ctx context.Context, id string) (*model.NotificationChannel, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+notificationChannelColumns+` FROM notification_channels WHERE id = ?`, id)
+	ch, err := scanNotificationChannel(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotificationChannelNotFound
+	}
+	return ch, err
+}
+
+// ListNotificationChannels returns the channels matching the filter, ordered
+// by name
+func (s *SQLiteStorage) ListNotificationChannels(ctx context.Context, filter *model.NotificationChannelFilter) ([]model.NotificationChannel, error) {
+	query := `SELECT ` + notificationChannelColumns + ` FROM notification_channels`
+	var conditions []string
+	var args []any
+
+	if filter != nil {
+		if filter.Type != "" {
+			conditions = append(conditions, "type = ?")
+			args = append(args, filter.Type)
+		}
+		if filter.Active != nil {
+			conditions = append(conditions, "active = ?")
+			args = append(args, *filter.Active)
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY name"
+
+	var pg *model.Pagination
+	if filter != nil {
+		pg = &filter.Pagination
+	}
+	query, args = appendPagination(query, args, pg)
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotificationChannels(rows)
+}
+
+// GetNotificationChannelsForEvent retrieves the active channels subscribed to
+// an event
+func (s *SQLiteStorage) GetNotificationChannelsForEvent(ctx context.Context, eventType model.EventType) ([]model.NotificationChannel, error) {
+	rows, err := s.reader.QueryContext(ctx, `SELECT `+notificationChannelColumns+` FROM notification_channels
+		WHERE active = 1 AND EXISTS (SELECT 1 FROM json_each(events) WHERE value = ?)
+		ORDER BY name`, string(eventType))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotificationChannels(rows)
+}
+
+// UpdateNotificationChannel updates a notification channel's settings
+func (s *SQLiteStorage) UpdateNotificationChannel(ctx context.Context, ch *model.NotificationChannel) error {
+	ch.UpdatedAt = nowUTC()
+
+	to, users, events, err := marshalNotificationLists(ch)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE notification_channels SET name = ?, url = ?, secret = ?, smtp_host = ?, smtp_port = ?, username = ?,
+			email_from = ?, email_to = ?, user_ids = ?, events = ?, title_template = ?, body_template = ?,
+			changes_only = ?, active = ?, description = ?, updated_at = ?
+		WHERE id = ?
+	`, ch.Name, ch.URL, ch.Secret, ch.SMTPHost, ch.SMTPPort, ch.Username,
+		ch.From, to, users, events, ch.TitleTemplate, ch.BodyTemplate,
+		ch.ChangesOnly, ch.Active, ch.Description, ch.UpdatedAt, ch.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrNotificationChannelNotFound
+	}
+	s.auditLog(ctx, "update", "notification_channel", ch.ID, ch)
+	return nil
+}
+
+// DeleteNotificationChannel removes a notification channel
+func (s *SQLiteStorage) DeleteNotificationChannel(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM notification_channels WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrNotificationChannelNotFound
+	}
+	s.auditLog(ctx, "delete", "notification_channel", id, nil)
+	return nil
+}
+
+// marshalNotificationLists encodes the recipients and events of a channel
+func marshalNotificationLists(ch *model.NotificationChannel) (to, users, events string, err error) {
+	if to, err = marshalStrings(ch.To); err != nil {
+		return "", "", "", err
+	}
+	if users, err = marshalStrings(ch.UserIDs); err != nil {
+		return "", "", "", err
+	}
+	eventsJSON, err := json.Marshal(ch.Events)
+	if err != nil {
+		return "", "", "", err
+	}
+	return to, users, string(eventsJSON), nil
+}
+
+// marshalStrings encodes a list as a JSON array, never null
+func marshalStrings(values []string) (string, error) {
+	if values == nil {
+		values = []string{}
+	}
+	b, err := json.Marshal(values)
+	return string(b), err
+}
+
+func scanNotificationChannels(rows *sql.Rows) ([]model.NotificationChannel, error) {
+	channels := []model.NotificationChannel{}
+	for rows.Next() {
+		ch, err := scanNotificationChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, *ch)
+	}
+	return channels, rows.Err()
+}
+
+func scanNotificationChannel(row interface{ Scan(...any) error }) (*model.NotificationChannel, error) {
+	var ch model.NotificationChannel
+	var to, users, events string
+	if err := row.Scan(&ch.ID, &ch.Name, &ch.Type, &ch.URL, &ch.Secret, &ch.SMTPHost, &ch.SMTPPort, &ch.Username,
+		&ch.From, &to, &users, &events, &ch.TitleTemplate, &ch.BodyTemplate, &ch.ChangesOnly, &ch.Active,
+		&ch.Description, &ch.CreatedBy, &ch.CreatedAt, &ch.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(to), &ch.To); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(users), &ch.UserIDs); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &ch.Events); err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestNotificationChannelCRUD(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	email := &model.NotificationChannel{
+		Name:          "ops-mail",
+		Type:          model.NotificationChannelEmail,
+		Secret:        "smtp-pass",
+		SMTPHost:      "mail.example.com",
+		SMTPPort:      587,
+		Username:      "rackd",
+		From:          "rackd@example.com",
+		To:            []string{"ops@example.com", "noc@example.com"},
+		UserIDs:       []string{"user-1"},
+		Events:        []model.EventType{model.EventTypeDiscoveryCompleted},
+		Active:        true,
+		TitleTemplate: "{{.Title}}",
+	}
+	ntfy := &model.NotificationChannel{
+		Name:        "ntfy",
+		Type:        model.NotificationChannelNtfy,
+		URL:         "https://ntfy.sh/rackd",
+		Events:      []model.EventType{model.EventTypeDiscoveryCompleted, model.EventTypeConflictDetected},
+		ChangesOnly: true,
+		Active:      false,
+	}
+	for _, ch := range []*model.NotificationChannel{email, ntfy} {
+		if err := storage.CreateNotificationChannel(ctx, ch); err != nil {
+			t.Fatalf("CreateNotificationChannel failed: %v", err)
+		}
+	}
+
+	got, err := storage.GetNotificationChannel(ctx, email.ID)
+	if err != nil {
+		t.Fatalf("GetNotificationChannel failed: %v", err)
+	}
+	if got.Secret != "smtp-pass" || got.SMTPHost != "mail.example.com" || got.SMTPPort != 587 ||
+		got.From != "rackd@example.com" || len(got.To) != 2 || got.To[1] != "noc@example.com" ||
+		len(got.UserIDs) != 1 || got.UserIDs[0] != "user-1" || got.TitleTemplate != "{{.Title}}" {
+		t.Errorf("email channel mismatch: got %+v", got)
+	}
+	if got, _ := storage.GetNotificationChannel(ctx, ntfy.ID); !got.ChangesOnly || got.Active || len(got.To) != 0 {
+		t.Errorf("ntfy channel mismatch: got %+v", got)
+	}
+
+	active := true
+	channels, err := storage.ListNotificationChannels(ctx, &model.NotificationChannelFilter{Active: &active})
+	if err != nil {
+		t.Fatalf("ListNotificationChannels failed: %v", err)
+	}
+	if len(channels) != 1 || channels[0].ID != email.ID {
+		t.Errorf("active channels = %+v, want only %s", channels, email.ID)
+	}
+	channels, _ = storage.ListNotificationChannels(ctx, &model.NotificationChannelFilter{Type: model.NotificationChannelNtfy})
+	if len(channels) != 1 || channels[0].ID != ntfy.ID {
+		t.Errorf("ntfy channels = %+v, want only %s", channels, ntfy.ID)
+	}
+
+	// Inactive channels and channels not subscribed to the event are skipped
+	channels, err = storage.GetNotificationChannelsForEvent(ctx, model.EventTypeConflictDetected)
+	if err != nil {
+		t.Fatalf("GetNotificationChannelsForEvent failed: %v", err)
+	}
+	if len(channels) != 0 {
+		t.Errorf("conflict channels = %+v, want none", channels)
+	}
+	ntfy.Active = true
+	ntfy.URL = "https://ntfy.example.com/rackd"
+	if err := storage.UpdateNotificationChannel(ctx, ntfy); err != nil {
+		t.Fatalf("UpdateNotificationChannel failed: %v", err)
+	}
+	channels, _ = storage.GetNotificationChannelsForEvent(ctx, model.EventTypeConflictDetected)
+	if len(channels) != 1 || channels[0].URL != "https://ntfy.example.com/rackd" {
+		t.Errorf("conflict channels = %+v, want the updated ntfy channel", channels)
+	}
+	channels, _ = storage.GetNotificationChannelsForEvent(ctx, model.EventTypeDiscoveryCompleted)
+	if len(channels) != 2 {
+		t.Errorf("discovery channels = %d, want 2", len(channels))
+	}
+
+	if err := storage.DeleteNotificationChannel(ctx, email.ID); err != nil {
+		t.Fatalf("DeleteNotificationChannel failed: %v", err)
+	}
+	if _, err := storage.GetNotificationChannel(ctx, email.ID); !errors.Is(err, ErrNotificationChannelNotFound) {
+		t.Errorf("GetNotificationChannel after delete = %v, want ErrNotificationChannelNotFound", err)
+	}
+	if err := storage.DeleteNotificationChannel(ctx, email.ID); !errors.Is(err, ErrNotificationChannelNotFound) {
+		t.Errorf("second delete = %v, want ErrNotificationChannelNotFound", err)
+	}
+	if err := storage.UpdateNotificationChannel(ctx, email); !errors.Is(err, ErrNotificationChannelNotFound) {
+		t.Errorf("update of deleted channel = %v, want ErrNotificationChannelNotFound", err)
+	}
+}
@@ -44,7 +44,7 @@ func (s *SQLiteStorage) GetOAuthClient(ctx context.Context, clientID string) (*m
 	var redirectURIs, grantTypes, responseTypes string
 	var createdByUserID sql.NullString
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, secret_hash, redirect_uris, grant_types, response_types,
 			token_endpoint_auth, scope, client_uri, logo_uri, is_confidential,
 			created_by_user_id, created_at, updated_at
@@ -74,14 +74,14 @@ func (s *SQLiteStorage) ListOAuthClients(ctx context.Context, createdByUserID st
 	var err error
 
 	if createdByUserID == "" {
-		rows, err = s.db.QueryContext(ctx, `
+		rows, err = s.reader.QueryContext(ctx, `
 			SELECT id, name, secret_hash, redirect_uris, grant_types, response_types,
 				token_endpoint_auth, scope, client_uri, logo_uri, is_confidential,
 				created_by_user_id, created_at, updated_at
 			FROM oauth_clients ORDER BY created_at DESC
 		`)
 	} else {
-		rows, err = s.db.QueryContext(ctx, `
+		rows, err = s.reader.QueryContext(ctx, `
 			SELECT id, name, secret_hash, redirect_uris, grant_types, response_types,
 				token_endpoint_auth, scope, client_uri, logo_uri, is_confidential,
 				created_by_user_id, created_at, updated_at
@@ -140,7 +140,7 @@ func (s *SQLiteStorage) CreateAuthorizationCode(ctx context.Context, code *model
 
 func (s *SQLiteStorage) GetAuthorizationCode(ctx context.Context, codeHash string) (*model.OAuthAuthorizationCode, error) {
 	var code model.OAuthAuthorizationCode
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT code_hash, client_id, user_id, redirect_uri, scope,
 			code_challenge, code_challenge_method, expires_at, created_at, used
 		FROM oauth_authorization_codes WHERE code_hash = ?
@@ -205,7 +205,7 @@ func (s *SQLiteStorage) CreateOAuthToken(ctx context.Context, token *model.OAuth
 
 func (s *SQLiteStorage) GetOAuthTokenByHash(ctx context.Context, tokenHash string) (*model.OAuthToken, error) {
 	var token model.OAuthToken
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, token_type, token_hash, client_id, user_id, scope,
 			expires_at, created_at, revoked_at, parent_token_id
 		FROM oauth_tokens WHERE token_hash = ?
@@ -232,7 +232,7 @@ func (s *SQLiteStorage) GetOAuthTokenByHash(ctx context.Context, tokenHash strin
 // This is used for refresh token replay detection.
 func (s *SQLiteStorage) GetOAuthTokenByHashIncludingRevoked(ctx context.Context, tokenHash string) (*model.OAuthToken, error) {
 	var token model.OAuthToken
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, token_type, token_hash, client_id, user_id, scope,
 			expires_at, created_at, revoked_at, parent_token_id
 		FROM oauth_tokens WHERE token_hash = ?
@@ -1,11 +1,15 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
+	"math/big"
 	"net"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -20,7 +24,7 @@ func (s *SQLiteStorage) CreateNetworkPool(ctx context.Context, pool *model.Netwo
 
 	// Validate network exists
 	var networkExists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM networks WHERE id = ?)`, pool.NetworkID).Scan(&networkExists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM networks WHERE id = ? AND deleted_at IS NULL)`, pool.NetworkID).Scan(&networkExists)
 	if err != nil {
 		return fmt.Errorf("failed to check network existence: %w", err)
 	}
@@ -58,6 +62,10 @@ func (s *SQLiteStorage) CreateNetworkPool(ctx context.Context, pool *model.Netwo
 		return fmt.Errorf("failed to insert pool tags: %w", err)
 	}
 
+	if err := recordVersion(ctx, tx, "pool", pool.ID, model.VersionActionCreate, pool); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return err
 	}
@@ -81,7 +89,7 @@ func (s *SQLiteStorage) insertPoolTags(ctx context.Context, tx *sql.Tx, poolID s
 
 // getPoolTags retrieves all tags for a pool
 func (s *SQLiteStorage) getPoolTags(ctx context.Context, poolID string) ([]string, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT tag FROM pool_tags WHERE pool_id = ?`, poolID)
+	rows, err := s.reader.QueryContext(ctx, `SELECT tag FROM pool_tags WHERE pool_id = ?`, poolID)
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +118,7 @@ func (s *SQLiteStorage) GetNetworkPool(ctx context.Context, id string) (*model.N
 	}
 
 	pool := &model.NetworkPool{}
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, network_id, name, start_ip, end_ip, description, created_at, updated_at
 		FROM network_pools WHERE id = ?
 	`, id).Scan(
@@ -180,6 +188,10 @@ func (s *SQLiteStorage) UpdateNetworkPool(ctx context.Context, pool *model.Netwo
 		return fmt.Errorf("failed to insert pool tags: %w", err)
 	}
 
+	if err := recordVersion(ctx, tx, "pool", pool.ID, model.VersionActionUpdate, pool); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return err
 	}
@@ -188,8 +200,10 @@ func (s *SQLiteStorage) UpdateNetworkPool(ctx context.Context, pool *model.Netwo
 	return nil
 }
 
-// DeleteNetworkPool removes a network pool by ID
-func (s *SQLiteStorage) DeleteNetworkPool(ctx context.Context, id string) error {
+// DeleteNetworkPool removes a network pool by ID. Its reservations are
+// deleted whatever the policy; addresses allocated from it are unlinked under
+// detach and deleted under cascade. Deny refuses while either exist.
+func (s *SQLiteStorage) DeleteNetworkPool(ctx context.Context, id string, policy model.DeletePolicy) error {
 	if id == "" {
 		return ErrInvalidID
 	}
@@ -201,9 +215,39 @@ func (s *SQLiteStorage) DeleteNetworkPool(ctx context.Context, id string) error
 	}
 	defer tx.Rollback()
 
+	if err := s.deleteNetworkPoolInTx(ctx, tx, id, policy); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.auditLog(ctx, "delete", "pool", id, nil)
+	return nil
+}
+
+// poolDependents are the records that refer to a pool
+var poolDependents = []dependent{
+	{
+		name:  "addresses",
+		count: `SELECT COUNT(*) FROM addresses WHERE pool_id = ?1`,
+		remove: unlinkOrDelete(
+			`UPDATE addresses SET pool_id = NULL WHERE pool_id = ?1`,
+			`DELETE FROM addresses WHERE pool_id = ?1`),
+	},
+	{
+		name:   "reservations",
+		count:  `SELECT COUNT(*) FROM reservations WHERE pool_id = ?1`,
+		remove: unlinkOrDelete("", `DELETE FROM reservations WHERE pool_id = ?1`),
+	},
+}
+
+// deleteNetworkPoolInTx deletes a pool within an existing transaction
+func (s *SQLiteStorage) deleteNetworkPoolInTx(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error {
 	// Check if pool exists
 	var exists bool
-	err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM network_pools WHERE id = ?)`, id).Scan(&exists)
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM network_pools WHERE id = ?)`, id).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check pool existence: %w", err)
 	}
@@ -211,10 +255,8 @@ func (s *SQLiteStorage) DeleteNetworkPool(ctx context.Context, id string) error
 		return ErrPoolNotFound
 	}
 
-	// Unlink addresses from this pool (set pool_id to NULL)
-	_, err = tx.ExecContext(ctx, `UPDATE addresses SET pool_id = NULL WHERE pool_id = ?`, id)
-	if err != nil {
-		return fmt.Errorf("failed to unlink addresses: %w", err)
+	if err := applyDeletePolicy(ctx, tx, "pool", id, policy, poolDependents); err != nil {
+		return err
 	}
 
 	// Delete pool (tags cascade via foreign key)
@@ -223,12 +265,7 @@ func (s *SQLiteStorage) DeleteNetworkPool(ctx context.Context, id string) error
 		return fmt.Errorf("failed to delete network pool: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return err
-	}
-
-	s.auditLog(ctx, "delete", "pool", id, nil)
-	return nil
+	return recordVersion(ctx, tx, "pool", id, model.VersionActionDelete, nil)
 }
 
 // ListNetworkPools retrieves pools matching the filter criteria
@@ -265,7 +302,7 @@ func (s *SQLiteStorage) ListNetworkPools(ctx context.Context, filter *model.Netw
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list network pools: %w", err)
 	}
@@ -303,7 +340,11 @@ func (s *SQLiteStorage) ListNetworkPools(ctx context.Context, filter *model.Netw
 	return pools, nil
 }
 
-// GetNextAvailableIP finds the first unused IP address in a pool's range
+// GetNextAvailableIP finds the first IP address in a pool's range that is
+// neither used nor reserved, without reserving it. It walks the sorted used
+// addresses rather than the range itself, so the cost
+// depends on how many addresses are in use, not on the size of the range,
+// which for an IPv6 pool can be 2^64 addresses or more.
 func (s *SQLiteStorage) GetNextAvailableIP(ctx context.Context, poolID string) (string, error) {
 	if poolID == "" {
 		return "", ErrInvalidID
@@ -315,59 +356,111 @@ func (s *SQLiteStorage) GetNextAvailableIP(ctx context.Context, poolID string) (
 		return "", err
 	}
 
-	// Parse start and end IPs
-	startIP := net.ParseIP(pool.StartIP)
-	endIP := net.ParseIP(pool.EndIP)
-
-	if startIP == nil || endIP == nil {
-		return "", fmt.Errorf("invalid IP range: %s - %s", pool.StartIP, pool.EndIP)
-	}
+	return nextFreeIP(ctx, s.reader, pool, nowUTC(), nil)
+}
 
-	// Convert to IPv4 if applicable
-	startIP = startIP.To4()
-	endIP = endIP.To4()
+// rowQuerier is the query method shared by *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
 
-	if startIP == nil || endIP == nil {
-		// Handle IPv6 or other formats
-		return "", fmt.Errorf("only IPv4 addresses are currently supported")
+// nextFreeIP returns the lowest IP of the pool that is neither used by an
+// address nor held by a reservation still active at now. Pending IPs are
+// taken too: they are being written by the caller's transaction but are not
+// in the addresses table yet.
+func nextFreeIP(ctx context.Context, q rowQuerier, pool *model.NetworkPool, now time.Time, pending []string) (string, error) {
+	startIP, endIP, err := poolRange(pool)
+	if err != nil {
+		return "", err
 	}
 
-	// Get all used IPs in this pool
-	usedIPs := make(map[string]bool)
-	rows, err := s.db.QueryContext(ctx, `SELECT ip FROM addresses WHERE pool_id = ?`, poolID)
+	// Get the taken IPs inside the range, in order
+	rows, err := q.QueryContext(ctx, `
+		SELECT ip FROM addresses WHERE pool_id = ?
+		UNION ALL
+		SELECT ip_address FROM reservations
+		WHERE pool_id = ? AND status = ? AND (expires_at IS NULL OR expires_at >= ?)
+	`, pool.ID, pool.ID, string(model.ReservationStatusActive), now)
 	if err != nil {
 		return "", fmt.Errorf("failed to query used IPs: %w", err)
 	}
 	defer rows.Close()
 
+	var used []net.IP
 	for rows.Next() {
 		var ip string
 		if err := rows.Scan(&ip); err != nil {
 			return "", fmt.Errorf("failed to scan IP: %w", err)
 		}
-		usedIPs[ip] = true
+		if parsed := sameFamily(net.ParseIP(ip), startIP); parsed != nil && ipInRange(parsed, startIP, endIP) {
+			used = append(used, parsed)
+		}
 	}
 	if err := rows.Err(); err != nil {
 		return "", err
 	}
-
-	// Iterate through the range to find the first available IP
-	current := make(net.IP, len(startIP))
-	copy(current, startIP)
-
-	for {
-		ipStr := current.String()
-		if !usedIPs[ipStr] {
-			return ipStr, nil
+	for _, ip := range pending {
+		if parsed := sameFamily(net.ParseIP(ip), startIP); parsed != nil && ipInRange(parsed, startIP, endIP) {
+			used = append(used, parsed)
 		}
-
-		// Increment IP
-		if !incrementIP(current, endIP) {
-			break
+	}
+	slices.SortFunc(used, func(a, b net.IP) int { return bytes.Compare(a, b) })
+
+	// Each used IP equal to the candidate pushes it one further; the first
+	// gap is free
+	candidate := slices.Clone(startIP)
+	for _, ip := range used {
+		switch bytes.Compare(ip, candidate) {
+		case -1:
+			// Duplicate of an IP already passed
+			continue
+		case 0:
+			if candidate.Equal(endIP) {
+				return "", ErrIPNotAvailable
+			}
+			incrementIP(candidate, endIP)
+			continue
 		}
+		break
+	}
+	return candidate.String(), nil
+}
+
+// poolRange parses a pool's start and end IPs, both in the same form: 4 bytes
+// for IPv4 and 16 for IPv6
+func poolRange(pool *model.NetworkPool) (net.IP, net.IP, error) {
+	startIP := net.ParseIP(pool.StartIP)
+	endIP := net.ParseIP(pool.EndIP)
+	if startIP == nil || endIP == nil {
+		return nil, nil, fmt.Errorf("invalid IP range: %s - %s", pool.StartIP, pool.EndIP)
 	}
 
-	return "", ErrIPNotAvailable
+	if v4 := startIP.To4(); v4 != nil {
+		startIP = v4
+	}
+	if endIP = sameFamily(endIP, startIP); endIP == nil {
+		return nil, nil, fmt.Errorf("pool range %s - %s mixes IPv4 and IPv6", pool.StartIP, pool.EndIP)
+	}
+	if bytes.Compare(startIP, endIP) > 0 {
+		return nil, nil, fmt.Errorf("invalid IP range: %s is after %s", pool.StartIP, pool.EndIP)
+	}
+	return startIP, endIP, nil
+}
+
+// sameFamily returns ip in the same form as ref, or nil if ip is nil or of
+// the other family
+func sameFamily(ip, ref net.IP) net.IP {
+	if ip == nil {
+		return nil
+	}
+	v4 := ip.To4()
+	if len(ref) == net.IPv4len {
+		return v4
+	}
+	if v4 != nil {
+		return nil
+	}
+	return ip.To16()
 }
 
 // incrementIP increments an IP address by 1, returns false if it exceeds endIP
@@ -394,7 +487,8 @@ func incrementIP(ip net.IP, endIP net.IP) bool {
 	return true
 }
 
-// ValidateIPInPool checks if an IP address is within a pool's range
+// ValidateIPInPool checks if an IP address is within a pool's range. An
+// address of the other family is never in range.
 func (s *SQLiteStorage) ValidateIPInPool(ctx context.Context, poolID, ip string) (bool, error) {
 	if poolID == "" {
 		return false, ErrInvalidID
@@ -406,22 +500,16 @@ func (s *SQLiteStorage) ValidateIPInPool(ctx context.Context, poolID, ip string)
 		return false, err
 	}
 
-	// Parse all IPs
+	startIP, endIP, err := poolRange(pool)
+	if err != nil {
+		return false, err
+	}
 	checkIP := net.ParseIP(ip)
-	startIP := net.ParseIP(pool.StartIP)
-	endIP := net.ParseIP(pool.EndIP)
-
-	if checkIP == nil || startIP == nil || endIP == nil {
+	if checkIP == nil {
 		return false, fmt.Errorf("invalid IP address")
 	}
-
-	// Convert to IPv4 if applicable
-	checkIP = checkIP.To4()
-	startIP = startIP.To4()
-	endIP = endIP.To4()
-
-	if checkIP == nil || startIP == nil || endIP == nil {
-		return false, fmt.Errorf("only IPv4 addresses are currently supported")
+	if checkIP = sameFamily(checkIP, startIP); checkIP == nil {
+		return false, nil
 	}
 
 	// Check if checkIP is within range [startIP, endIP]
@@ -465,25 +553,15 @@ func (s *SQLiteStorage) GetPoolHeatmap(ctx context.Context, poolID string) ([]IP
 		return nil, err
 	}
 
-	// Parse start and end IPs
-	startIP := net.ParseIP(pool.StartIP)
-	endIP := net.ParseIP(pool.EndIP)
-
-	if startIP == nil || endIP == nil {
-		return nil, fmt.Errorf("invalid IP range: %s - %s", pool.StartIP, pool.EndIP)
-	}
-
-	// Convert to IPv4
-	startIP = startIP.To4()
-	endIP = endIP.To4()
-
-	if startIP == nil || endIP == nil {
-		return nil, fmt.Errorf("only IPv4 addresses are currently supported")
+	startIP, endIP, err := poolRange(pool)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get all addresses in this pool with their device IDs
+	// Get all addresses in this pool with their device IDs, keyed by the
+	// canonical form of the IP so differently written IPv6 addresses match
 	addressMap := make(map[string]string) // ip -> device_id
-	rows, err := s.db.QueryContext(ctx, `SELECT ip, device_id FROM addresses WHERE pool_id = ?`, poolID)
+	rows, err := s.reader.QueryContext(ctx, `SELECT ip, device_id FROM addresses WHERE pool_id = ?`, poolID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query addresses: %w", err)
 	}
@@ -494,7 +572,7 @@ func (s *SQLiteStorage) GetPoolHeatmap(ctx context.Context, poolID string) ([]IP
 		if err := rows.Scan(&ip, &deviceID); err != nil {
 			return nil, fmt.Errorf("failed to scan address: %w", err)
 		}
-		addressMap[ip] = deviceID
+		addressMap[canonicalIP(ip)] = deviceID
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -502,7 +580,7 @@ func (s *SQLiteStorage) GetPoolHeatmap(ctx context.Context, poolID string) ([]IP
 
 	// Get all active reservations in this pool
 	reservationMap := make(map[string]string) // ip -> reservation_id
-	resRows, err := s.db.QueryContext(ctx, `
+	resRows, err := s.reader.QueryContext(ctx, `
 		SELECT ip_address, id FROM reservations
 		WHERE pool_id = ? AND status = ?
 	`, poolID, "active")
@@ -516,7 +594,7 @@ func (s *SQLiteStorage) GetPoolHeatmap(ctx context.Context, poolID string) ([]IP
 		if err := resRows.Scan(&ip, &reservationID); err != nil {
 			return nil, fmt.Errorf("failed to scan reservation: %w", err)
 		}
-		reservationMap[ip] = reservationID
+		reservationMap[canonicalIP(ip)] = reservationID
 	}
 	if err := resRows.Err(); err != nil {
 		return nil, err
@@ -524,10 +602,11 @@ func (s *SQLiteStorage) GetPoolHeatmap(ctx context.Context, poolID string) ([]IP
 
 	// Build heatmap
 	var heatmap []IPStatus
-	current := make(net.IP, len(startIP))
-	copy(current, startIP)
+	current := slices.Clone(startIP)
 
-	// Safety limit: don't enumerate more than 65536 IPs to prevent memory issues
+	// Safety limit: don't enumerate more than 65536 IPs to prevent memory
+	// issues. An IPv6 pool is usually far larger, so its heatmap covers only
+	// the start of the range.
 	const maxIPs = 65536
 	count := 0
 
@@ -565,3 +644,220 @@ func (s *SQLiteStorage) GetPoolHeatmap(ctx context.Context, poolID string) ([]IP
 
 	return heatmap, nil
 }
+
+// canonicalIP returns ip in the form net.IP.String gives it, or ip unchanged
+// if it does not parse
+func canonicalIP(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil {
+		return parsed.String()
+	}
+	return ip
+}
+
+// GetPoolStats counts the used, reserved and free IPs of a pool. An IP is
+// used when an address of the pool has it, and reserved when an active,
+// unexpired reservation holds it and no address does.
+func (s *SQLiteStorage) GetPoolStats(ctx context.Context, poolID string) (*model.PoolStats, error) {
+	if poolID == "" {
+		return nil, ErrInvalidID
+	}
+
+	tallies, err := s.tallyPools(ctx, "p.id = ?", poolID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tallies) == 0 {
+		return nil, ErrPoolNotFound
+	}
+	return &tallies[0].stats, nil
+}
+
+// GetPoolStatsSummary returns the stats of every pool matching the filter,
+// with totals per address family
+func (s *SQLiteStorage) GetPoolStatsSummary(ctx context.Context, filter *model.PoolStatsFilter) (*model.PoolStatsSummary, error) {
+	conditions := []string{"n.deleted_at IS NULL"}
+	var args []any
+	if filter != nil {
+		if filter.NetworkID != "" {
+			conditions = append(conditions, "p.network_id = ?")
+			args = append(args, filter.NetworkID)
+		}
+		if filter.DatacenterID != "" {
+			conditions = append(conditions, "n.datacenter_id = ?")
+			args = append(args, filter.DatacenterID)
+		}
+	}
+	tallies, err := s.tallyPools(ctx, strings.Join(conditions, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &model.PoolStatsSummary{PoolCount: len(tallies), Pools: make([]model.PoolStats, 0, len(tallies))}
+	if filter != nil {
+		summary.NetworkID = filter.NetworkID
+		summary.DatacenterID = filter.DatacenterID
+	}
+
+	// Sum each family exactly, then saturate once
+	type familyTotal struct {
+		total          *big.Int
+		used, reserved int
+	}
+	totals := map[string]*familyTotal{
+		"ipv4": {total: new(big.Int)},
+		"ipv6": {total: new(big.Int)},
+	}
+	for _, t := range tallies {
+		summary.Pools = append(summary.Pools, t.stats)
+		ft := totals[t.stats.Family]
+		ft.total.Add(ft.total, t.total)
+		ft.used += t.stats.Used
+		ft.reserved += t.stats.Reserved
+	}
+	summary.IPv4 = newPoolCounts(totals["ipv4"].total, totals["ipv4"].used, totals["ipv4"].reserved)
+	summary.IPv6 = newPoolCounts(totals["ipv6"].total, totals["ipv6"].used, totals["ipv6"].reserved)
+	return summary, nil
+}
+
+// poolTally is the stats of one pool along with its exact size
+type poolTally struct {
+	stats model.PoolStats
+	total *big.Int
+}
+
+// tallyPools counts the IPs of the pools matching where, a condition on
+// network_pools p joined to networks n. Pools whose range does not parse are
+// skipped.
+func (s *SQLiteStorage) tallyPools(ctx context.Context, where string, args ...any) ([]poolTally, error) {
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT p.id, p.name, p.network_id, p.start_ip, p.end_ip
+		FROM network_pools p JOIN networks n ON n.id = p.network_id
+		WHERE `+where+` ORDER BY p.name`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pools: %w", err)
+	}
+	var pools []model.NetworkPool
+	for rows.Next() {
+		var pool model.NetworkPool
+		if err := rows.Scan(&pool.ID, &pool.Name, &pool.NetworkID, &pool.StartIP, &pool.EndIP); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan pool: %w", err)
+		}
+		pools = append(pools, pool)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(pools) == 0 {
+		return nil, nil
+	}
+
+	// Collect every pool's used and reserved IPs in one pass, keyed by the
+	// canonical form of the IP
+	used := make(map[string]map[string]bool)
+	reserved := make(map[string]map[string]bool)
+	takenArgs := append(append(slices.Clone(args), args...), string(model.ReservationStatusActive), nowUTC())
+	rows, err = s.reader.QueryContext(ctx, `
+		SELECT a.pool_id, a.ip, 0
+		FROM addresses a
+		JOIN network_pools p ON p.id = a.pool_id JOIN networks n ON n.id = p.network_id
+		WHERE `+where+`
+		UNION ALL
+		SELECT r.pool_id, r.ip_address, 1
+		FROM reservations r
+		JOIN network_pools p ON p.id = r.pool_id JOIN networks n ON n.id = p.network_id
+		WHERE `+where+` AND r.status = ? AND (r.expires_at IS NULL OR r.expires_at >= ?)
+	`, takenArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pool IPs: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var poolID, ip string
+		var isReservation bool
+		if err := rows.Scan(&poolID, &ip, &isReservation); err != nil {
+			return nil, fmt.Errorf("failed to scan pool IP: %w", err)
+		}
+		set := used
+		if isReservation {
+			set = reserved
+		}
+		if set[poolID] == nil {
+			set[poolID] = make(map[string]bool)
+		}
+		set[poolID][canonicalIP(ip)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tallies := make([]poolTally, 0, len(pools))
+	for _, pool := range pools {
+		startIP, endIP, err := poolRange(&pool)
+		if err != nil {
+			continue
+		}
+		inRange := func(ip string) bool {
+			parsed := sameFamily(net.ParseIP(ip), startIP)
+			return parsed != nil && ipInRange(parsed, startIP, endIP)
+		}
+
+		var usedCount, reservedCount int
+		for ip := range used[pool.ID] {
+			if inRange(ip) {
+				usedCount++
+			}
+		}
+		for ip := range reserved[pool.ID] {
+			if !used[pool.ID][ip] && inRange(ip) {
+				reservedCount++
+			}
+		}
+
+		total := new(big.Int).Sub(new(big.Int).SetBytes(endIP), new(big.Int).SetBytes(startIP))
+		total.Add(total, big.NewInt(1))
+		family := "ipv6"
+		if len(startIP) == net.IPv4len {
+			family = "ipv4"
+		}
+
+		tallies = append(tallies, poolTally{
+			stats: model.PoolStats{
+				PoolID:     pool.ID,
+				PoolName:   pool.Name,
+				NetworkID:  pool.NetworkID,
+				Family:     family,
+				PoolCounts: newPoolCounts(total, usedCount, reservedCount),
+			},
+			total: total,
+		})
+	}
+	return tallies, nil
+}
+
+// newPoolCounts derives the free count and utilization of total IPs of which
+// used and reserved are taken
+func newPoolCounts(total *big.Int, used, reserved int) model.PoolCounts {
+	taken := big.NewInt(int64(used + reserved))
+	free := new(big.Int).Sub(total, taken)
+	if free.Sign() < 0 {
+		free.SetInt64(0)
+	}
+
+	var utilization float64
+	if total.Sign() > 0 {
+		utilization, _ = new(big.Float).Quo(new(big.Float).SetInt(taken), new(big.Float).SetInt(total)).Float64()
+		utilization *= 100
+	}
+
+	return model.PoolCounts{
+		Total:          saturatingInt(total),
+		Used:           used,
+		Reserved:       reserved,
+		Free:           saturatingInt(free),
+		TotalAddresses: total.String(),
+		FreeAddresses:  free.String(),
+		Utilization:    utilization,
+	}
+}
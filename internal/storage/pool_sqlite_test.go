@@ -3,7 +3,9 @@ package storage
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -235,7 +237,7 @@ func TestPoolOperations_Delete(t *testing.T) {
 	}
 
 	// Delete pool
-	if err := storage.DeleteNetworkPool(context.Background(), pool.ID); err != nil {
+	if err := storage.DeleteNetworkPool(context.Background(), pool.ID, model.DeleteDetach); err != nil {
 		t.Fatalf("DeleteNetworkPool failed: %v", err)
 	}
 
@@ -281,7 +283,7 @@ func TestPoolOperations_DeleteWithAddresses(t *testing.T) {
 	}
 
 	// Delete pool (should unlink addresses)
-	if err := storage.DeleteNetworkPool(context.Background(), pool.ID); err != nil {
+	if err := storage.DeleteNetworkPool(context.Background(), pool.ID, model.DeleteDetach); err != nil {
 		t.Fatalf("DeleteNetworkPool failed: %v", err)
 	}
 
@@ -302,7 +304,7 @@ func TestPoolOperations_DeleteNotFound(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
 
-	err := storage.DeleteNetworkPool(context.Background(), "non-existent-id")
+	err := storage.DeleteNetworkPool(context.Background(), "non-existent-id", model.DeleteDetach)
 	if err != ErrPoolNotFound {
 		t.Errorf("expected ErrPoolNotFound, got %v", err)
 	}
@@ -312,7 +314,7 @@ func TestPoolOperations_DeleteInvalidID(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
 
-	err := storage.DeleteNetworkPool(context.Background(), "")
+	err := storage.DeleteNetworkPool(context.Background(), "", model.DeleteDetach)
 	if err != ErrInvalidID {
 		t.Errorf("expected ErrInvalidID, got %v", err)
 	}
@@ -519,6 +521,78 @@ func TestPoolOperations_GetNextAvailableIP(t *testing.T) {
 	}
 }
 
+func TestPoolOperations_GetNextAvailableIP_IPv6(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+
+	network := &model.Network{Name: "v6", Subnet: "2001:db8::/64"}
+	storage.CreateNetwork(context.Background(), network)
+
+	// A whole /64, far too large to walk address by address
+	pool := &model.NetworkPool{
+		NetworkID: network.ID,
+		Name:      "v6 pool",
+		StartIP:   "2001:db8::",
+		EndIP:     "2001:db8::ffff:ffff:ffff:ffff",
+	}
+	if err := storage.CreateNetworkPool(context.Background(), pool); err != nil {
+		t.Fatalf("CreateNetworkPool failed: %v", err)
+	}
+
+	// Used addresses written in non-canonical form still count
+	device := &model.Device{
+		Name: "v6-server",
+		Addresses: []model.Address{
+			{IP: "2001:DB8::0", Type: "ipv6", PoolID: pool.ID},
+			{IP: "2001:db8:0::1", Type: "ipv6", PoolID: pool.ID},
+			{IP: "2001:db8::5", Type: "ipv6", PoolID: pool.ID},
+		},
+	}
+	if err := storage.CreateDevice(context.Background(), device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+
+	ip, err := storage.GetNextAvailableIP(context.Background(), pool.ID)
+	if err != nil {
+		t.Fatalf("GetNextAvailableIP failed: %v", err)
+	}
+	if ip != "2001:db8::2" {
+		t.Errorf("expected 2001:db8::2, got %s", ip)
+	}
+
+	ok, err := storage.ValidateIPInPool(context.Background(), pool.ID, "2001:db8::abcd")
+	if err != nil || !ok {
+		t.Errorf("expected 2001:db8::abcd in the pool, got %v (err %v)", ok, err)
+	}
+	ok, err = storage.ValidateIPInPool(context.Background(), pool.ID, "10.0.0.1")
+	if err != nil || ok {
+		t.Errorf("expected an IPv4 address to be outside an IPv6 pool, got %v (err %v)", ok, err)
+	}
+
+	heatmap, err := storage.GetPoolHeatmap(context.Background(), pool.ID)
+	if err != nil {
+		t.Fatalf("GetPoolHeatmap failed: %v", err)
+	}
+	if len(heatmap) != 65536 || heatmap[1].Status != "used" || heatmap[2].Status != "available" {
+		t.Errorf("expected a capped heatmap marking ::1 used and ::2 available, got %d entries", len(heatmap))
+	}
+}
+
+func TestPoolOperations_GetNextAvailableIP_MixedFamilies(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+
+	network := &model.Network{Name: "Network1", Subnet: "192.168.1.0/24"}
+	storage.CreateNetwork(context.Background(), network)
+
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "mixed", StartIP: "192.168.1.10", EndIP: "2001:db8::1"}
+	storage.CreateNetworkPool(context.Background(), pool)
+
+	if _, err := storage.GetNextAvailableIP(context.Background(), pool.ID); err == nil {
+		t.Error("expected an error for a range mixing IPv4 and IPv6")
+	}
+}
+
 func TestPoolOperations_GetNextAvailableIP_AllUsed(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
@@ -552,6 +626,68 @@ func TestPoolOperations_GetNextAvailableIP_AllUsed(t *testing.T) {
 	}
 }
 
+func TestPoolOperations_AllocateDeviceAddresses(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "Network1", Subnet: "192.168.1.0/24"}
+	storage.CreateNetwork(ctx, network)
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "Small Pool", StartIP: "192.168.1.100", EndIP: "192.168.1.103"}
+	storage.CreateNetworkPool(ctx, pool)
+	if err := storage.CreateReservation(ctx, &model.Reservation{PoolID: pool.ID, IPAddress: "192.168.1.100"}); err != nil {
+		t.Fatalf("CreateReservation: %v", err)
+	}
+
+	// Two pool-only addresses on one device get distinct IPs, skipping the
+	// reserved one and the one given explicitly
+	device := &model.Device{
+		Name: "server1",
+		Addresses: []model.Address{
+			{PoolID: pool.ID},
+			{IP: "192.168.1.101", PoolID: pool.ID},
+			{PoolID: pool.ID, Label: "mgmt"},
+		},
+	}
+	if err := storage.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDevice: %v", err)
+	}
+	if device.Addresses[0].IP != "192.168.1.102" || device.Addresses[2].IP != "192.168.1.103" {
+		t.Fatalf("expected 192.168.1.102 and .103, got %+v", device.Addresses)
+	}
+	if device.Addresses[0].NetworkID != network.ID || device.Addresses[0].Type != "ipv4" {
+		t.Errorf("expected the pool's network and ipv4 type, got %+v", device.Addresses[0])
+	}
+	stored, err := storage.GetDevice(ctx, device.ID)
+	if err != nil {
+		t.Fatalf("GetDevice: %v", err)
+	}
+	if len(stored.Addresses) != 3 || stored.Addresses[2].IP != "192.168.1.103" {
+		t.Errorf("expected the allocated IPs to be saved, got %+v", stored.Addresses)
+	}
+
+	// The pool is now full
+	err = storage.CreateDevice(ctx, &model.Device{Name: "server2", Addresses: []model.Address{{PoolID: pool.ID}}})
+	if err != ErrIPNotAvailable {
+		t.Errorf("expected ErrIPNotAvailable, got %v", err)
+	}
+
+	// Freeing an address lets an update allocate it again
+	device.Addresses = device.Addresses[1:]
+	device.Addresses = append(device.Addresses, model.Address{PoolID: pool.ID})
+	if err := storage.UpdateDevice(ctx, device); err != nil {
+		t.Fatalf("UpdateDevice: %v", err)
+	}
+	if device.Addresses[2].IP != "192.168.1.102" {
+		t.Errorf("expected the freed 192.168.1.102, got %+v", device.Addresses)
+	}
+
+	err = storage.CreateDevice(ctx, &model.Device{Name: "server3", Addresses: []model.Address{{PoolID: "missing"}}})
+	if err != ErrPoolNotFound {
+		t.Errorf("expected ErrPoolNotFound, got %v", err)
+	}
+}
+
 func TestPoolOperations_GetNextAvailableIP_PoolNotFound(t *testing.T) {
 	storage := newTestStorage(t)
 	defer storage.Close()
@@ -1072,3 +1208,89 @@ func TestListNetworkPoolsWithCombinedFilters(t *testing.T) {
 		t.Errorf("expected 2 pools with 'dhcp' tag, got %d", len(result))
 	}
 }
+
+func TestPoolOperations_GetPoolStats(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "stats-dc"}
+	if err := storage.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("CreateDatacenter failed: %v", err)
+	}
+	v4 := &model.Network{Name: "stats-v4", Subnet: "10.6.0.0/24", DatacenterID: dc.ID}
+	v6 := &model.Network{Name: "stats-v6", Subnet: "2001:db8:6::/64", DatacenterID: dc.ID}
+	for _, n := range []*model.Network{v4, v6} {
+		if err := storage.CreateNetwork(ctx, n); err != nil {
+			t.Fatalf("CreateNetwork failed: %v", err)
+		}
+	}
+
+	pool := &model.NetworkPool{NetworkID: v4.ID, Name: "a-v4", StartIP: "10.6.0.1", EndIP: "10.6.0.10"}
+	v6Pool := &model.NetworkPool{NetworkID: v6.ID, Name: "b-v6", StartIP: "2001:db8:6::", EndIP: "2001:db8:6::ffff:ffff:ffff:ffff"}
+	for _, p := range []*model.NetworkPool{pool, v6Pool} {
+		if err := storage.CreateNetworkPool(ctx, p); err != nil {
+			t.Fatalf("CreateNetworkPool failed: %v", err)
+		}
+	}
+
+	// Two used IPs, one of them also reserved, plus one reserved IP and one
+	// expired reservation that no longer counts
+	device := &model.Device{Name: "stats-dev", Addresses: []model.Address{
+		{IP: "10.6.0.1", PoolID: pool.ID},
+		{IP: "10.6.0.2", PoolID: pool.ID},
+	}}
+	if err := storage.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+	past := time.Now().UTC().Add(-time.Minute)
+	for _, r := range []*model.Reservation{
+		{PoolID: pool.ID, IPAddress: "10.6.0.3", ReservedBy: "admin"},
+		{PoolID: pool.ID, IPAddress: "10.6.0.4", ReservedBy: "admin", ExpiresAt: &past},
+		{PoolID: v6Pool.ID, IPAddress: "2001:db8:6::1", ReservedBy: "admin"},
+	} {
+		if err := storage.CreateReservation(ctx, r); err != nil {
+			t.Fatalf("CreateReservation failed: %v", err)
+		}
+	}
+
+	stats, err := storage.GetPoolStats(ctx, pool.ID)
+	if err != nil {
+		t.Fatalf("GetPoolStats failed: %v", err)
+	}
+	if stats.Family != "ipv4" || stats.Total != 10 || stats.Used != 2 || stats.Reserved != 1 || stats.Free != 7 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.Utilization != 30 {
+		t.Errorf("expected 30%% utilization, got %v", stats.Utilization)
+	}
+
+	if _, err := storage.GetPoolStats(ctx, "missing"); err != ErrPoolNotFound {
+		t.Errorf("expected ErrPoolNotFound, got %v", err)
+	}
+
+	summary, err := storage.GetPoolStatsSummary(ctx, &model.PoolStatsFilter{DatacenterID: dc.ID})
+	if err != nil {
+		t.Fatalf("GetPoolStatsSummary failed: %v", err)
+	}
+	if summary.PoolCount != 2 || len(summary.Pools) != 2 {
+		t.Fatalf("expected 2 pools, got %+v", summary)
+	}
+	if summary.IPv4.Total != 10 || summary.IPv4.Used != 2 || summary.IPv4.Reserved != 1 {
+		t.Errorf("unexpected IPv4 totals: %+v", summary.IPv4)
+	}
+	if summary.IPv6.TotalAddresses != "18446744073709551616" || summary.IPv6.Total != math.MaxInt || summary.IPv6.Reserved != 1 {
+		t.Errorf("unexpected IPv6 totals: %+v", summary.IPv6)
+	}
+	if summary.IPv6.FreeAddresses != "18446744073709551615" {
+		t.Errorf("expected 2^64 - 1 free IPv6 addresses, got %s", summary.IPv6.FreeAddresses)
+	}
+
+	summary, err = storage.GetPoolStatsSummary(ctx, &model.PoolStatsFilter{NetworkID: v4.ID})
+	if err != nil {
+		t.Fatalf("GetPoolStatsSummary failed: %v", err)
+	}
+	if summary.PoolCount != 1 || summary.IPv6.Total != 0 || summary.IPv6.Utilization != 0 {
+		t.Errorf("expected only the IPv4 pool, got %+v", summary)
+	}
+}
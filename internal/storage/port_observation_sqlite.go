@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// RecordPortObservations updates the port history of a host a scan found:
+// its open ports are seen open, opening anew if they were closed, and the
+// ports the scan probed but found closed are closed. Ports the scan did not
+// probe are left as they were. Discovery records ports on every scan, so they
+// are not audited.
+func (s *SQLiteStorage) RecordPortObservations(ctx context.Context, scanID string, device *model.DiscoveredDevice, probed []int) error {
+	// A port can have several services, such as a banner and an HTTP
+	// fingerprint; the first name and banner found describe it
+	services := make(map[int]model.ServiceInfo)
+	for _, svc := range device.Services {
+		if svc.Protocol != "tcp" {
+			continue
+		}
+		known := services[svc.Port]
+		if known.Service == "" {
+			known.Service = svc.Service
+		}
+		if known.Version == "" {
+			known.Version = svc.Version
+		}
+		services[svc.Port] = known
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := nowUTC()
+	for _, port := range device.OpenPorts {
+		svc := services[port]
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO port_observations (ip, port, network_id, service, banner, first_seen, last_seen, opened_at, opened_scan_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(ip, port) DO UPDATE SET
+				network_id = excluded.network_id,
+				service = CASE WHEN excluded.service != '' THEN excluded.service ELSE service END,
+				banner = CASE WHEN excluded.banner != '' THEN excluded.banner ELSE banner END,
+				last_seen = excluded.last_seen,
+				opened_at = CASE WHEN closed_at IS NULL THEN opened_at ELSE excluded.opened_at END,
+				opened_scan_id = CASE WHEN closed_at IS NULL THEN opened_scan_id ELSE excluded.opened_scan_id END,
+				closed_at = NULL,
+				closed_scan_id = ''
+		`, device.IP, port, device.NetworkID, svc.Service, svc.Version, now, now, now, scanID)
+		if err != nil {
+			return fmt.Errorf("failed to record port %d of %s: %w", port, device.IP, err)
+		}
+	}
+
+	if len(probed) > 0 {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT port FROM port_observations WHERE ip = ? AND closed_at IS NULL
+		`, device.IP)
+		if err != nil {
+			return err
+		}
+		var closed []int
+		for rows.Next() {
+			var port int
+			if err := rows.Scan(&port); err != nil {
+				rows.Close()
+				return err
+			}
+			if slices.Contains(probed, port) && !slices.Contains(device.OpenPorts, port) {
+				closed = append(closed, port)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, port := range closed {
+			_, err := tx.ExecContext(ctx, `
+				UPDATE port_observations SET closed_at = ?, closed_scan_id = ? WHERE ip = ? AND port = ?
+			`, now, scanID, device.IP, port)
+			if err != nil {
+				return fmt.Errorf("failed to close port %d of %s: %w", port, device.IP, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ListPortObservations returns the port observations matching the filter,
+// ordered by IP and port
+func (s *SQLiteStorage) ListPortObservations(ctx context.Context, filter *model.PortObservationFilter) ([]model.PortObservation, error) {
+	query := `SELECT ` + portObservationColumns + ` FROM port_observations WHERE 1=1`
+	var args []any
+
+	if filter != nil {
+		if filter.NetworkID != "" {
+			query += " AND network_id = ?"
+			args = append(args, filter.NetworkID)
+		}
+		if len(filter.IPs) > 0 {
+			query += " AND ip IN (" + placeholders(len(filter.IPs)) + ")"
+			for _, ip := range filter.IPs {
+				args = append(args, ip)
+			}
+		}
+		if filter.Open != nil {
+			if *filter.Open {
+				query += " AND closed_at IS NULL"
+			} else {
+				query += " AND closed_at IS NOT NULL"
+			}
+		}
+		if filter.OpenedSince != nil {
+			query += " AND opened_at >= ?"
+			args = append(args, filter.OpenedSince.UTC())
+		}
+		if filter.OpenedScanID != "" {
+			query += " AND opened_scan_id = ?"
+			args = append(args, filter.OpenedScanID)
+		}
+		if filter.ClosedScanID != "" {
+			query += " AND closed_scan_id = ?"
+			args = append(args, filter.ClosedScanID)
+		}
+	}
+
+	query += " ORDER BY ip, port"
+
+	var pg *model.Pagination
+	if filter != nil {
+		pg = &filter.Pagination
+	}
+	query, args = appendPagination(query, args, pg)
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list port observations: %w", err)
+	}
+	defer rows.Close()
+
+	observations := []model.PortObservation{}
+	for rows.Next() {
+		o, err := scanPortObservation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan port observation: %w", err)
+		}
+		observations = append(observations, *o)
+	}
+	return observations, rows.Err()
+}
+
+const portObservationColumns = `ip, port, network_id, service, banner, first_seen, last_seen, opened_at, opened_scan_id, closed_at, closed_scan_id`
+
+func scanPortObservation(row interface{ Scan(...any) error }) (*model.PortObservation, error) {
+	var o model.PortObservation
+	var closedAt sql.NullTime
+	if err := row.Scan(&o.IP, &o.Port, &o.NetworkID, &o.Service, &o.Banner, &o.FirstSeen, &o.LastSeen,
+		&o.OpenedAt, &o.OpenedScanID, &closedAt, &o.ClosedScanID); err != nil {
+		return nil, err
+	}
+	if closedAt.Valid {
+		o.ClosedAt = &closedAt.Time
+	}
+	o.Open = o.ClosedAt == nil
+	return &o, nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestRecordPortObservations(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	ports := func(t *testing.T, filter *model.PortObservationFilter) map[int]model.PortObservation {
+		t.Helper()
+		observations, err := storage.ListPortObservations(ctx, filter)
+		if err != nil {
+			t.Fatalf("ListPortObservations failed: %v", err)
+		}
+		byPort := make(map[int]model.PortObservation)
+		for _, o := range observations {
+			byPort[o.Port] = o
+		}
+		return byPort
+	}
+	host := &model.PortObservationFilter{IPs: []string{"10.0.0.5"}}
+
+	first := &model.DiscoveredDevice{
+		IP:        "10.0.0.5",
+		NetworkID: "net-1",
+		OpenPorts: []int{22, 80},
+		Services: []model.ServiceInfo{
+			{Port: 22, Protocol: "tcp", Service: "ssh", Version: "SSH-2.0-OpenSSH_9.6"},
+			{Port: 80, Protocol: "tcp", Service: "http"},
+			{Port: 161, Protocol: "udp", Service: "snmp"},
+		},
+	}
+	if err := storage.RecordPortObservations(ctx, "scan-1", first, []int{22, 80, 443}); err != nil {
+		t.Fatalf("first record failed: %v", err)
+	}
+	got := ports(t, host)
+	if len(got) != 2 || !got[22].Open || got[22].Service != "ssh" || got[22].Banner != "SSH-2.0-OpenSSH_9.6" || got[22].OpenedScanID != "scan-1" {
+		t.Fatalf("first scan ports = %+v", got)
+	}
+
+	// A quick scan that did not grab banners keeps them, and a port it did
+	// not probe stays open
+	quick := &model.DiscoveredDevice{IP: "10.0.0.5", NetworkID: "net-1", OpenPorts: []int{22, 443}}
+	if err := storage.RecordPortObservations(ctx, "scan-2", quick, []int{22, 443}); err != nil {
+		t.Fatalf("quick record failed: %v", err)
+	}
+	got = ports(t, host)
+	if got[22].Banner != "SSH-2.0-OpenSSH_9.6" || got[22].OpenedScanID != "scan-1" {
+		t.Errorf("expected port 22 unchanged but seen again, got %+v", got[22])
+	}
+	if !got[80].Open {
+		t.Errorf("expected unprobed port 80 to stay open, got %+v", got[80])
+	}
+	if !got[443].Open || got[443].OpenedScanID != "scan-2" {
+		t.Errorf("expected port 443 opened by scan-2, got %+v", got[443])
+	}
+
+	closing := &model.DiscoveredDevice{IP: "10.0.0.5", NetworkID: "net-1", OpenPorts: []int{22}}
+	if err := storage.RecordPortObservations(ctx, "scan-3", closing, []int{22, 80, 443}); err != nil {
+		t.Fatalf("closing record failed: %v", err)
+	}
+	closed := ports(t, &model.PortObservationFilter{ClosedScanID: "scan-3"})
+	if len(closed) != 2 || closed[80].Open || closed[80].ClosedAt == nil || closed[443].Open {
+		t.Errorf("expected ports 80 and 443 closed by scan-3, got %+v", closed)
+	}
+
+	// Reopening starts a new open period but keeps the first sighting
+	if err := storage.RecordPortObservations(ctx, "scan-4", first, []int{22, 80}); err != nil {
+		t.Fatalf("reopen record failed: %v", err)
+	}
+	reopened := ports(t, &model.PortObservationFilter{OpenedScanID: "scan-4"})
+	if len(reopened) != 1 || !reopened[80].Open || reopened[80].ClosedScanID != "" || !reopened[80].OpenedAt.After(reopened[80].FirstSeen) {
+		t.Errorf("expected port 80 reopened by scan-4, got %+v", reopened)
+	}
+
+	open := false
+	if got := ports(t, &model.PortObservationFilter{Open: &open}); len(got) != 1 || got[443].Open {
+		t.Errorf("expected only port 443 closed, got %+v", got)
+	}
+	if got := ports(t, &model.PortObservationFilter{NetworkID: "net-2"}); len(got) != 0 {
+		t.Errorf("expected no ports on another network, got %+v", got)
+	}
+}
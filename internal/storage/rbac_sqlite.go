@@ -60,7 +60,7 @@ func (s *SQLiteStorage) GetPermission(ctx context.Context, id string) (*model.Pe
 	query := `SELECT id, name, resource, action, created_at FROM permissions WHERE id = ?`
 
 	var perm model.Permission
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&perm.ID, &perm.Name, &perm.Resource, &perm.Action, &perm.CreatedAt)
+	err := s.reader.QueryRowContext(ctx, query, id).Scan(&perm.ID, &perm.Name, &perm.Resource, &perm.Action, &perm.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrPermissionNotFound
 	}
@@ -79,7 +79,7 @@ func (s *SQLiteStorage) GetPermissionByName(ctx context.Context, name string) (*
 	query := `SELECT id, name, resource, action, created_at FROM permissions WHERE name = ?`
 
 	var perm model.Permission
-	err := s.db.QueryRowContext(ctx, query, name).Scan(&perm.ID, &perm.Name, &perm.Resource, &perm.Action, &perm.CreatedAt)
+	err := s.reader.QueryRowContext(ctx, query, name).Scan(&perm.ID, &perm.Name, &perm.Resource, &perm.Action, &perm.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrPermissionNotFound
 	}
@@ -112,7 +112,7 @@ func (s *SQLiteStorage) ListPermissions(ctx context.Context, filter *model.Permi
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list permissions: %w", err)
 	}
@@ -176,7 +176,7 @@ func (s *SQLiteStorage) GetRole(ctx context.Context, id string) (*model.Role, er
 	query := `SELECT id, name, description, is_system, created_at, updated_at FROM roles WHERE id = ?`
 
 	var role model.Role
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem, &role.CreatedAt, &role.UpdatedAt)
+	err := s.reader.QueryRowContext(ctx, query, id).Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem, &role.CreatedAt, &role.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrRoleNotFound
 	}
@@ -195,7 +195,7 @@ func (s *SQLiteStorage) GetRoleByName(ctx context.Context, name string) (*model.
 	query := `SELECT id, name, description, is_system, created_at, updated_at FROM roles WHERE name = ?`
 
 	var role model.Role
-	err := s.db.QueryRowContext(ctx, query, name).Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem, &role.CreatedAt, &role.UpdatedAt)
+	err := s.reader.QueryRowContext(ctx, query, name).Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem, &role.CreatedAt, &role.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrRoleNotFound
 	}
@@ -228,7 +228,7 @@ func (s *SQLiteStorage) ListRoles(ctx context.Context, filter *model.RoleFilter)
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list roles: %w", err)
 	}
@@ -282,7 +282,7 @@ func (s *SQLiteStorage) DeleteRole(ctx context.Context, id string) error {
 	}
 
 	var role model.Role
-	err := s.db.QueryRowContext(ctx, `SELECT is_system FROM roles WHERE id = ?`, id).Scan(&role.IsSystem)
+	err := s.reader.QueryRowContext(ctx, `SELECT is_system FROM roles WHERE id = ?`, id).Scan(&role.IsSystem)
 	if err == sql.ErrNoRows {
 		return ErrRoleNotFound
 	}
@@ -315,7 +315,7 @@ func (s *SQLiteStorage) GetRolePermissions(ctx context.Context, roleID string) (
 		ORDER BY p.resource, p.action
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, roleID)
+	rows, err := s.reader.QueryContext(ctx, query, roleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get role permissions: %w", err)
 	}
@@ -406,7 +406,7 @@ func (s *SQLiteStorage) GetUserRoles(ctx context.Context, userID string) ([]mode
 		ORDER BY r.name
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, userID)
+	rows, err := s.reader.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user roles: %w", err)
 	}
@@ -442,7 +442,7 @@ func (s *SQLiteStorage) GetUserPermissions(ctx context.Context, userID string) (
 		ORDER BY p.resource, p.action
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, userID)
+	rows, err := s.reader.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user permissions: %w", err)
 	}
@@ -506,7 +506,7 @@ func (s *SQLiteStorage) HasPermission(ctx context.Context, userID, resource, act
 		WHERE ur.user_id = ? AND p.resource = ? AND p.action = ?
 	`
 
-	err := s.db.QueryRowContext(ctx, query, userID, resource, action).Scan(&count)
+	err := s.reader.QueryRowContext(ctx, query, userID, resource, action).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check permission: %w", err)
 	}
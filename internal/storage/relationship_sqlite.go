@@ -3,14 +3,67 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
 
 // Relationship operations
 
+// AddRelationship links two devices, updating the notes when the link already
+// exists. For undirected types an existing link in the other direction counts
+// as the same link. For acyclic types the link is refused with
+// ErrRelationshipCycle when the child already reaches the parent.
 func (s *SQLiteStorage) AddRelationship(ctx context.Context, parentID, childID, relationshipType, notes string) error {
-	_, err := s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	relType, known := model.LookupRelationshipType(relationshipType)
+	if known && !relType.Directed {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE device_relationships SET notes = ?
+			WHERE parent_id = ? AND child_id = ? AND type = ?
+		`, notes, childID, parentID, relationshipType)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			if err := tx.Commit(); err != nil {
+				return err
+			}
+			s.auditLog(ctx, "add", "relationship", childID+":"+parentID, nil)
+			return nil
+		}
+	}
+	if known && relType.Acyclic {
+		if parentID == childID {
+			return ErrRelationshipCycle
+		}
+		var cycle bool
+		err := tx.QueryRowContext(ctx, `
+			WITH RECURSIVE reachable(id) AS (
+				SELECT child_id FROM device_relationships WHERE parent_id = ? AND type = ?
+				UNION
+				SELECT r.child_id FROM device_relationships r
+				JOIN reachable ON r.parent_id = reachable.id
+				WHERE r.type = ?
+			)
+			SELECT EXISTS(SELECT 1 FROM reachable WHERE id = ?)
+		`, childID, relationshipType, relationshipType, parentID).Scan(&cycle)
+		if err != nil {
+			return fmt.Errorf("failed to check relationship cycle: %w", err)
+		}
+		if cycle {
+			return ErrRelationshipCycle
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
 		INSERT INTO device_relationships (parent_id, child_id, type, notes)
 		VALUES (?, ?, ?, ?)
 		ON CONFLICT (parent_id, child_id, type) DO UPDATE SET notes = excluded.notes
@@ -18,15 +71,26 @@ func (s *SQLiteStorage) AddRelationship(ctx context.Context, parentID, childID,
 	if err != nil {
 		return err
 	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 	s.auditLog(ctx, "add", "relationship", parentID+":"+childID, nil)
 	return nil
 }
 
+// relationshipMatch returns the WHERE clause and arguments selecting a link.
+// Links of undirected types match in either direction.
+func relationshipMatch(parentID, childID, relationshipType string) (string, []any) {
+	if t, ok := model.LookupRelationshipType(relationshipType); ok && !t.Directed {
+		return `((parent_id = ? AND child_id = ?) OR (parent_id = ? AND child_id = ?)) AND type = ?`,
+			[]any{parentID, childID, childID, parentID, relationshipType}
+	}
+	return `parent_id = ? AND child_id = ? AND type = ?`, []any{parentID, childID, relationshipType}
+}
+
 func (s *SQLiteStorage) RemoveRelationship(ctx context.Context, parentID, childID, relationshipType string) error {
-	_, err := s.db.ExecContext(ctx, `
-		DELETE FROM device_relationships
-		WHERE parent_id = ? AND child_id = ? AND type = ?
-	`, parentID, childID, relationshipType)
+	where, args := relationshipMatch(parentID, childID, relationshipType)
+	_, err := s.db.ExecContext(ctx, `DELETE FROM device_relationships WHERE `+where, args...)
 	if err != nil {
 		return err
 	}
@@ -35,11 +99,8 @@ func (s *SQLiteStorage) RemoveRelationship(ctx context.Context, parentID, childI
 }
 
 func (s *SQLiteStorage) UpdateRelationshipNotes(ctx context.Context, parentID, childID, relationshipType, notes string) error {
-	_, err := s.db.ExecContext(ctx, `
-		UPDATE device_relationships
-		SET notes = ?
-		WHERE parent_id = ? AND child_id = ? AND type = ?
-	`, notes, parentID, childID, relationshipType)
+	where, args := relationshipMatch(parentID, childID, relationshipType)
+	_, err := s.db.ExecContext(ctx, `UPDATE device_relationships SET notes = ? WHERE `+where, append([]any{notes}, args...)...)
 	if err != nil {
 		return err
 	}
@@ -47,12 +108,17 @@ func (s *SQLiteStorage) UpdateRelationshipNotes(ctx context.Context, parentID, c
 	return nil
 }
 
+// liveRelationship is a condition on device_relationships that hides links
+// to devices in the trash
+const liveRelationship = `NOT EXISTS (
+	SELECT 1 FROM devices td WHERE td.id IN (parent_id, child_id) AND td.deleted_at IS NOT NULL
+)`
+
 func (s *SQLiteStorage) GetRelationships(ctx context.Context, deviceID string) ([]model.DeviceRelationship, error) {
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT parent_id, child_id, type, notes, created_at
 		FROM device_relationships
-		WHERE parent_id = ? OR child_id = ?
-	`, deviceID, deviceID)
+		WHERE (parent_id = ? OR child_id = ?) AND `+liveRelationship, deviceID, deviceID)
 	if err != nil {
 		return nil, err
 	}
@@ -70,10 +136,10 @@ func (s *SQLiteStorage) GetRelationships(ctx context.Context, deviceID string) (
 }
 
 func (s *SQLiteStorage) ListAllRelationships(ctx context.Context) ([]model.DeviceRelationship, error) {
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT parent_id, child_id, type, notes, created_at
 		FROM device_relationships
-	`)
+		WHERE `+liveRelationship)
 	if err != nil {
 		return nil, err
 	}
@@ -91,12 +157,12 @@ func (s *SQLiteStorage) ListAllRelationships(ctx context.Context) ([]model.Devic
 }
 
 func (s *SQLiteStorage) GetRelatedDevices(ctx context.Context, deviceID, relationshipType string) ([]model.Device, error) {
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT d.id, d.name, d.description, d.make_model, d.os, d.datacenter_id,
-		       d.username, d.location, d.created_at, d.updated_at
+		       d.username, d.location, d.kind, d.created_at, d.updated_at
 		FROM devices d
 		JOIN device_relationships r ON (d.id = r.child_id OR d.id = r.parent_id)
-		WHERE (r.parent_id = ? OR r.child_id = ?) AND r.type = ? AND d.id != ?
+		WHERE (r.parent_id = ? OR r.child_id = ?) AND r.type = ? AND d.id != ? AND d.deleted_at IS NULL
 	`, deviceID, deviceID, relationshipType, deviceID)
 	if err != nil {
 		return nil, err
@@ -108,7 +174,7 @@ func (s *SQLiteStorage) GetRelatedDevices(ctx context.Context, deviceID, relatio
 		var d model.Device
 		var dcID sql.NullString
 		if err := rows.Scan(&d.ID, &d.Name, &d.Description, &d.MakeModel, &d.OS,
-			&dcID, &d.Username, &d.Location, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			&dcID, &d.Username, &d.Location, &d.Kind, &d.CreatedAt, &d.UpdatedAt); err != nil {
 			return nil, err
 		}
 		d.DatacenterID = dcID.String
@@ -120,9 +186,138 @@ func (s *SQLiteStorage) GetRelatedDevices(ctx context.Context, deviceID, relatio
 
 	// Fetch related data after closing rows
 	for i := range devices {
+		devices[i].Interfaces, _ = s.getDeviceInterfaces(ctx, devices[i].ID)
 		devices[i].Addresses, _ = s.getDeviceAddresses(ctx, devices[i].ID)
 		devices[i].Tags, _ = s.getDeviceTags(ctx, devices[i].ID)
 		devices[i].Domains, _ = s.getDeviceDomains(ctx, devices[i].ID)
 	}
 	return devices, nil
 }
+
+// impactEdge is one hop of the impact walk
+type impactEdge struct {
+	to      string
+	relType string
+}
+
+// GetDeviceImpact walks depends_on, hosted_on and connected_to
+// relationships from a device up to maxDepth hops. Affected follows
+// dependants (the parents of a depends_on or hosted_on link), DependsOn
+// follows dependencies (its children); connected_to links are followed both
+// ways in each walk.
+func (s *SQLiteStorage) GetDeviceImpact(ctx context.Context, deviceID string, maxDepth int) (*model.DeviceImpact, error) {
+	var exists bool
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM devices WHERE id = ? AND deleted_at IS NULL)`, deviceID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check device: %w", err)
+	}
+	if !exists {
+		return nil, ErrDeviceNotFound
+	}
+
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT parent_id, child_id, type FROM device_relationships
+		WHERE type IN (?, ?, ?) AND `+liveRelationship,
+		model.RelationshipDependsOn, model.RelationshipHostedOn, model.RelationshipConnectedTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load relationships: %w", err)
+	}
+	defer rows.Close()
+
+	dependants := map[string][]impactEdge{}
+	dependencies := map[string][]impactEdge{}
+	for rows.Next() {
+		var parentID, childID, relType string
+		if err := rows.Scan(&parentID, &childID, &relType); err != nil {
+			return nil, err
+		}
+		dependants[childID] = append(dependants[childID], impactEdge{parentID, relType})
+		dependencies[parentID] = append(dependencies[parentID], impactEdge{childID, relType})
+		if relType == model.RelationshipConnectedTo {
+			dependants[parentID] = append(dependants[parentID], impactEdge{childID, relType})
+			dependencies[childID] = append(dependencies[childID], impactEdge{parentID, relType})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	affected, truncA := walkImpact(deviceID, dependants, maxDepth)
+	dependsOn, truncD := walkImpact(deviceID, dependencies, maxDepth)
+
+	ids := make([]any, 0, len(affected)+len(dependsOn))
+	for _, d := range append(append([]model.ImpactedDevice{}, affected...), dependsOn...) {
+		ids = append(ids, d.DeviceID)
+	}
+	names := map[string]string{}
+	if len(ids) > 0 {
+		nameRows, err := s.reader.QueryContext(ctx,
+			`SELECT id, name FROM devices WHERE deleted_at IS NULL AND id IN (?`+strings.Repeat(",?", len(ids)-1)+`)`, ids...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load device names: %w", err)
+		}
+		defer nameRows.Close()
+		for nameRows.Next() {
+			var id, name string
+			if err := nameRows.Scan(&id, &name); err != nil {
+				return nil, err
+			}
+			names[id] = name
+		}
+		if err := nameRows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, list := range [][]model.ImpactedDevice{affected, dependsOn} {
+		for i := range list {
+			list[i].Name = names[list[i].DeviceID]
+		}
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Depth != list[j].Depth {
+				return list[i].Depth < list[j].Depth
+			}
+			return list[i].Name < list[j].Name
+		})
+	}
+
+	return &model.DeviceImpact{
+		DeviceID:  deviceID,
+		MaxDepth:  maxDepth,
+		Affected:  affected,
+		DependsOn: dependsOn,
+		Truncated: truncA || truncD,
+	}, nil
+}
+
+// walkImpact runs a breadth-first walk from start over graph, stopping at
+// maxDepth. It reports whether unvisited devices lay beyond the limit.
+func walkImpact(start string, graph map[string][]impactEdge, maxDepth int) ([]model.ImpactedDevice, bool) {
+	found := []model.ImpactedDevice{}
+	seen := map[string]bool{start: true}
+	frontier := []string{start}
+	truncated := false
+	for depth := 1; len(frontier) > 0; depth++ {
+		var next []string
+		for _, id := range frontier {
+			for _, e := range graph[id] {
+				if seen[e.to] {
+					continue
+				}
+				if depth > maxDepth {
+					truncated = true
+					continue
+				}
+				seen[e.to] = true
+				found = append(found, model.ImpactedDevice{DeviceID: e.to, Depth: depth, Via: id, Type: e.relType})
+				next = append(next, e.to)
+			}
+		}
+		if depth > maxDepth {
+			break
+		}
+		frontier = next
+	}
+	return found, truncated
+}
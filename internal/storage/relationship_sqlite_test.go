@@ -2,6 +2,9 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -210,7 +213,7 @@ func TestDeleteDeviceWithRelationships(t *testing.T) {
 	storage.AddRelationship(context.Background(), parent.ID, child.ID, model.RelationshipContains, "")
 
 	// Delete parent - should cascade relationships
-	if err := storage.DeleteDevice(context.Background(), parent.ID); err != nil {
+	if err := storage.DeleteDevice(context.Background(), parent.ID, model.DeleteDetach); err != nil {
 		t.Fatalf("DeleteDevice failed: %v", err)
 	}
 
@@ -253,3 +256,143 @@ func TestRelationshipNotesAndListAll(t *testing.T) {
 		t.Fatalf("expected updated notes, got %+v", all[0])
 	}
 }
+
+func TestAddRelationshipRejectsCycles(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	var devices []*model.Device
+	for _, name := range []string{"app", "db", "storage"} {
+		d := &model.Device{Name: name}
+		if err := storage.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+		devices = append(devices, d)
+	}
+	app, db, san := devices[0], devices[1], devices[2]
+
+	if err := storage.AddRelationship(ctx, app.ID, db.ID, model.RelationshipDependsOn, ""); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+	if err := storage.AddRelationship(ctx, db.ID, san.ID, model.RelationshipDependsOn, ""); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+
+	if err := storage.AddRelationship(ctx, db.ID, app.ID, model.RelationshipDependsOn, ""); !errors.Is(err, ErrRelationshipCycle) {
+		t.Errorf("expected ErrRelationshipCycle for a direct cycle, got %v", err)
+	}
+	if err := storage.AddRelationship(ctx, san.ID, app.ID, model.RelationshipDependsOn, ""); !errors.Is(err, ErrRelationshipCycle) {
+		t.Errorf("expected ErrRelationshipCycle for an indirect cycle, got %v", err)
+	}
+	if err := storage.AddRelationship(ctx, app.ID, app.ID, model.RelationshipContains, ""); !errors.Is(err, ErrRelationshipCycle) {
+		t.Errorf("expected ErrRelationshipCycle for a self-reference, got %v", err)
+	}
+
+	// Cycles are only checked within one type
+	if err := storage.AddRelationship(ctx, db.ID, app.ID, model.RelationshipContains, ""); err != nil {
+		t.Errorf("expected a contains link against a depends_on chain to succeed, got %v", err)
+	}
+}
+
+func TestAddRelationshipUndirected(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	server := &model.Device{Name: "server"}
+	sw := &model.Device{Name: "switch"}
+	storage.CreateDevice(ctx, server)
+	storage.CreateDevice(ctx, sw)
+
+	if err := storage.AddRelationship(ctx, server.ID, sw.ID, model.RelationshipConnectedTo, "eth0"); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+	// The reverse link is the same connection, so only its notes change
+	if err := storage.AddRelationship(ctx, sw.ID, server.ID, model.RelationshipConnectedTo, "Gi1/0/1"); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+
+	rels, err := storage.GetRelationships(ctx, server.ID)
+	if err != nil {
+		t.Fatalf("GetRelationships failed: %v", err)
+	}
+	if len(rels) != 1 || rels[0].ParentID != server.ID || rels[0].Notes != "Gi1/0/1" {
+		t.Fatalf("expected one connection with updated notes, got %+v", rels)
+	}
+
+	if err := storage.RemoveRelationship(ctx, sw.ID, server.ID, model.RelationshipConnectedTo); err != nil {
+		t.Fatalf("RemoveRelationship failed: %v", err)
+	}
+	rels, _ = storage.GetRelationships(ctx, server.ID)
+	if len(rels) != 0 {
+		t.Errorf("expected the connection to be removable from either end, got %+v", rels)
+	}
+}
+
+func TestGetDeviceImpact(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	// lb depends on app, app depends on db, db depends on san; db is
+	// connected to sw; rack contains db and is ignored
+	names := []string{"lb", "app", "db", "san", "sw", "rack"}
+	ids := map[string]string{}
+	for _, n := range names {
+		d := &model.Device{Name: n}
+		if err := storage.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+		ids[n] = d.ID
+	}
+	links := []struct{ parent, child, typ string }{
+		{"lb", "app", model.RelationshipDependsOn},
+		{"app", "db", model.RelationshipDependsOn},
+		{"db", "san", model.RelationshipDependsOn},
+		{"sw", "db", model.RelationshipConnectedTo},
+		{"rack", "db", model.RelationshipContains},
+	}
+	for _, l := range links {
+		if err := storage.AddRelationship(ctx, ids[l.parent], ids[l.child], l.typ, ""); err != nil {
+			t.Fatalf("AddRelationship failed: %v", err)
+		}
+	}
+
+	impact, err := storage.GetDeviceImpact(ctx, ids["db"], 5)
+	if err != nil {
+		t.Fatalf("GetDeviceImpact failed: %v", err)
+	}
+	var affected []string
+	for _, d := range impact.Affected {
+		affected = append(affected, fmt.Sprintf("%s@%d", d.Name, d.Depth))
+	}
+	if got := strings.Join(affected, ","); got != "app@1,sw@1,lb@2" {
+		t.Errorf("affected = %s, want app@1,sw@1,lb@2", got)
+	}
+	var dependsOn []string
+	for _, d := range impact.DependsOn {
+		dependsOn = append(dependsOn, d.Name)
+	}
+	if got := strings.Join(dependsOn, ","); got != "san,sw" {
+		t.Errorf("depends_on = %s, want san,sw", got)
+	}
+	if impact.Affected[2].Via != ids["app"] || impact.Affected[2].Type != model.RelationshipDependsOn {
+		t.Errorf("expected lb to be reached via app, got %+v", impact.Affected[2])
+	}
+	if impact.Truncated {
+		t.Error("expected the walk to finish within the depth limit")
+	}
+
+	impact, err = storage.GetDeviceImpact(ctx, ids["db"], 1)
+	if err != nil {
+		t.Fatalf("GetDeviceImpact failed: %v", err)
+	}
+	if len(impact.Affected) != 2 || !impact.Truncated {
+		t.Errorf("expected two affected devices and a truncated walk at depth 1, got %+v", impact)
+	}
+
+	if _, err := storage.GetDeviceImpact(ctx, "missing", 5); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound, got %v", err)
+	}
+}
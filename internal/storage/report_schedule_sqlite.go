@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const reportScheduleColumns = `id, name, report, days, datacenter, tag, format, cron_expression, channel_ids, enabled,
+	description, created_by, last_run_at, last_error, next_run_at, created_at, updated_at`
+
+// CreateReportSchedule stores a new report schedule
+func (s *SQLiteStorage) CreateReportSchedule(ctx context.Context, schedule *model.ReportSchedule) error {
+	if schedule.ID == "" {
+		schedule.ID = newUUID()
+	}
+	schedule.CreatedAt = nowUTC()
+	schedule.UpdatedAt = schedule.CreatedAt
+
+	channels, err := marshalStrings(schedule.ChannelIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO report_schedules (`+reportScheduleColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, schedule.ID, schedule.Name, schedule.Report, schedule.Days, schedule.Datacenter, schedule.Tag, schedule.Format,
+		schedule.CronExpression, channels, schedule.Enabled, schedule.Description, schedule.CreatedBy,
+		utcTime(schedule.LastRunAt), schedule.LastError, utcTime(schedule.NextRunAt), schedule.CreatedAt, schedule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	s.auditLog(ctx, "create", "report_schedule", schedule.ID, schedule)
+	return nil
+}
+
+// GetReportSchedule retrieves a report schedule by ID
+func (s *SQLiteStorage) GetReportSchedule(ctx context.Context, id string) (*model.ReportSchedule, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+reportScheduleColumns+` FROM report_schedules WHERE id = ?`, id)
+	schedule, err := scanReportSchedule(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrReportScheduleNotFound
+	}
+	return schedule, err
+}
+
+// ListReportSchedules returns the schedules matching the filter, ordered by
+// name
+func (s *SQLiteStorage) ListReportSchedules(ctx context.Context, filter *model.ReportScheduleFilter) ([]model.ReportSchedule, error) {
+	query := `SELECT ` + reportScheduleColumns + ` FROM report_schedules`
+	var conditions []string
+	var args []any
+
+	if filter != nil {
+		if filter.Report != "" {
+			conditions = append(conditions, "report = ?")
+			args = append(args, filter.Report)
+		}
+		if filter.Enabled != nil {
+			conditions = append(conditions, "enabled = ?")
+			args = append(args, *filter.Enabled)
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY name"
+
+	var pg *model.Pagination
+	if filter != nil {
+		pg = &filter.Pagination
+	}
+	query, args = appendPagination(query, args, pg)
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReportSchedules(rows)
+}
+
+// ListDueReportSchedules returns the enabled schedules whose next run is at
+// or before now, oldest first
+func (s *SQLiteStorage) ListDueReportSchedules(ctx context.Context, now time.Time) ([]model.ReportSchedule, error) {
+	rows, err := s.reader.QueryContext(ctx, `SELECT `+reportScheduleColumns+` FROM report_schedules
+		WHERE enabled = 1 AND next_run_at IS NOT NULL AND next_run_at <= ?
+		ORDER BY next_run_at`, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReportSchedules(rows)
+}
+
+// UpdateReportSchedule updates a schedule's settings and next run. The
+// outcome of the last run is left alone; RecordReportScheduleRun sets it.
+func (s *SQLiteStorage) UpdateReportSchedule(ctx context.Context, schedule *model.ReportSchedule) error {
+	schedule.UpdatedAt = nowUTC()
+
+	channels, err := marshalStrings(schedule.ChannelIDs)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE report_schedules SET name = ?, report = ?, days = ?, datacenter = ?, tag = ?, format = ?,
+			cron_expression = ?, channel_ids = ?, enabled = ?, description = ?, next_run_at = ?, updated_at = ?
+		WHERE id = ?
+	`, schedule.Name, schedule.Report, schedule.Days, schedule.Datacenter, schedule.Tag, schedule.Format,
+		schedule.CronExpression, channels, schedule.Enabled, schedule.Description, utcTime(schedule.NextRunAt),
+		schedule.UpdatedAt, schedule.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrReportScheduleNotFound
+	}
+	s.auditLog(ctx, "update", "report_schedule", schedule.ID, schedule)
+	return nil
+}
+
+// RecordReportScheduleRun stores the time and error of a schedule's last run
+// and when it runs next; runErr is empty on success. It is not audited.
+func (s *SQLiteStorage) RecordReportScheduleRun(ctx context.Context, id string, ranAt time.Time, runErr string, nextRunAt *time.Time) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE report_schedules SET last_run_at = ?, last_error = ?, next_run_at = ? WHERE id = ?`,
+		ranAt.UTC(), runErr, utcTime(nextRunAt), id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrReportScheduleNotFound
+	}
+	return nil
+}
+
+// DeleteReportSchedule removes a report schedule
+func (s *SQLiteStorage) DeleteReportSchedule(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM report_schedules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrReportScheduleNotFound
+	}
+	s.auditLog(ctx, "delete", "report_schedule", id, nil)
+	return nil
+}
+
+// utcTime returns t in UTC, or nil
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func scanReportSchedules(rows *sql.Rows) ([]model.ReportSchedule, error) {
+	schedules := []model.ReportSchedule{}
+	for rows.Next() {
+		schedule, err := scanReportSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, rows.Err()
+}
+
+func scanReportSchedule(row interface{ Scan(...any) error }) (*model.ReportSchedule, error) {
+	var schedule model.ReportSchedule
+	var channels string
+	var lastRunAt, nextRunAt sql.NullTime
+	if err := row.Scan(&schedule.ID, &schedule.Name, &schedule.Report, &schedule.Days, &schedule.Datacenter, &schedule.Tag,
+		&schedule.Format, &schedule.CronExpression, &channels, &schedule.Enabled, &schedule.Description, &schedule.CreatedBy,
+		&lastRunAt, &schedule.LastError, &nextRunAt, &schedule.CreatedAt, &schedule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(channels), &schedule.ChannelIDs); err != nil {
+		return nil, err
+	}
+	if lastRunAt.Valid {
+		schedule.LastRunAt = &lastRunAt.Time
+	}
+	if nextRunAt.Valid {
+		schedule.NextRunAt = &nextRunAt.Time
+	}
+	return &schedule, nil
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestReportScheduleCRUD(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	weekly := &model.ReportSchedule{
+		Name:           "weekly exposure",
+		Report:         "exposure",
+		Datacenter:     "dc1",
+		Tag:            "prod",
+		Format:         "pdf",
+		CronExpression: "0 7 * * 1",
+		ChannelIDs:     []string{"ch-1", "ch-2"},
+		Enabled:        true,
+		CreatedBy:      "user-1",
+		NextRunAt:      &past,
+	}
+	daily := &model.ReportSchedule{
+		Name:           "daily warranty",
+		Report:         "warranty-expiry",
+		Days:           30,
+		Format:         "csv",
+		CronExpression: "0 6 * * *",
+		Enabled:        true,
+		NextRunAt:      &future,
+	}
+	paused := &model.ReportSchedule{
+		Name:           "paused",
+		Report:         "ip-utilization",
+		Format:         "xlsx",
+		CronExpression: "0 6 * * *",
+		NextRunAt:      &past,
+	}
+	for _, schedule := range []*model.ReportSchedule{weekly, daily, paused} {
+		if err := storage.CreateReportSchedule(ctx, schedule); err != nil {
+			t.Fatalf("CreateReportSchedule failed: %v", err)
+		}
+	}
+
+	got, err := storage.GetReportSchedule(ctx, weekly.ID)
+	if err != nil {
+		t.Fatalf("GetReportSchedule failed: %v", err)
+	}
+	if got.Report != "exposure" || got.Datacenter != "dc1" || got.Format != "pdf" || len(got.ChannelIDs) != 2 ||
+		got.CreatedBy != "user-1" || got.NextRunAt == nil || !got.NextRunAt.Equal(past) || got.LastRunAt != nil {
+		t.Errorf("unexpected schedule %+v", got)
+	}
+	if got, _ := storage.GetReportSchedule(ctx, daily.ID); got.ChannelIDs == nil || len(got.ChannelIDs) != 0 {
+		t.Errorf("expected no channels, got %v", got.ChannelIDs)
+	}
+
+	enabled := true
+	list, err := storage.ListReportSchedules(ctx, &model.ReportScheduleFilter{Enabled: &enabled})
+	if err != nil || len(list) != 2 || list[0].Name != "daily warranty" {
+		t.Errorf("expected the two enabled schedules by name, got %+v (%v)", list, err)
+	}
+	if list, _ := storage.ListReportSchedules(ctx, &model.ReportScheduleFilter{Report: "exposure"}); len(list) != 1 {
+		t.Errorf("expected one exposure schedule, got %d", len(list))
+	}
+
+	due, err := storage.ListDueReportSchedules(ctx, now)
+	if err != nil || len(due) != 1 || due[0].ID != weekly.ID {
+		t.Fatalf("expected only the weekly schedule due, got %+v (%v)", due, err)
+	}
+
+	next := now.Add(7 * 24 * time.Hour)
+	if err := storage.RecordReportScheduleRun(ctx, weekly.ID, now, "email: no recipients", &next); err != nil {
+		t.Fatalf("RecordReportScheduleRun failed: %v", err)
+	}
+	got, _ = storage.GetReportSchedule(ctx, weekly.ID)
+	if got.LastRunAt == nil || !got.LastRunAt.Equal(now) || got.LastError != "email: no recipients" || !got.NextRunAt.Equal(next) {
+		t.Errorf("expected the run recorded, got %+v", got)
+	}
+	if due, _ := storage.ListDueReportSchedules(ctx, now); len(due) != 0 {
+		t.Errorf("expected nothing due after the run, got %d", len(due))
+	}
+
+	got.Enabled = false
+	got.NextRunAt = nil
+	got.ChannelIDs = []string{"ch-3"}
+	if err := storage.UpdateReportSchedule(ctx, got); err != nil {
+		t.Fatalf("UpdateReportSchedule failed: %v", err)
+	}
+	got, _ = storage.GetReportSchedule(ctx, weekly.ID)
+	if got.Enabled || got.NextRunAt != nil || len(got.ChannelIDs) != 1 || got.LastError != "email: no recipients" {
+		t.Errorf("expected the update to keep the last run, got %+v", got)
+	}
+
+	if err := storage.DeleteReportSchedule(ctx, weekly.ID); err != nil {
+		t.Fatalf("DeleteReportSchedule failed: %v", err)
+	}
+	if _, err := storage.GetReportSchedule(ctx, weekly.ID); !errors.Is(err, ErrReportScheduleNotFound) {
+		t.Errorf("expected ErrReportScheduleNotFound, got %v", err)
+	}
+	if err := storage.DeleteReportSchedule(ctx, weekly.ID); !errors.Is(err, ErrReportScheduleNotFound) {
+		t.Errorf("expected ErrReportScheduleNotFound deleting twice, got %v", err)
+	}
+}
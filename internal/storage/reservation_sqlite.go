@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -30,8 +31,8 @@ func (s *SQLiteStorage) CreateReservation(ctx context.Context, reservation *mode
 	defer tx.Rollback()
 
 	// Validate pool exists and get IP range for validation
-	var startIP, endIP string
-	err = tx.QueryRowContext(ctx, `SELECT start_ip, end_ip FROM network_pools WHERE id = ?`, reservation.PoolID).Scan(&startIP, &endIP)
+	pool := &model.NetworkPool{ID: reservation.PoolID}
+	err = tx.QueryRowContext(ctx, `SELECT start_ip, end_ip FROM network_pools WHERE id = ?`, reservation.PoolID).Scan(&pool.StartIP, &pool.EndIP)
 	if err == sql.ErrNoRows {
 		return ErrPoolNotFound
 	}
@@ -40,19 +41,15 @@ func (s *SQLiteStorage) CreateReservation(ctx context.Context, reservation *mode
 	}
 
 	// Validate IP is in pool range
+	poolStartIP, poolEndIP, err := poolRange(pool)
+	if err != nil {
+		return err
+	}
 	checkIP := net.ParseIP(reservation.IPAddress)
-	poolStartIP := net.ParseIP(startIP)
-	poolEndIP := net.ParseIP(endIP)
-	if checkIP == nil || poolStartIP == nil || poolEndIP == nil {
+	if checkIP == nil {
 		return fmt.Errorf("invalid IP address")
 	}
-	checkIP = checkIP.To4()
-	poolStartIP = poolStartIP.To4()
-	poolEndIP = poolEndIP.To4()
-	if checkIP == nil || poolStartIP == nil || poolEndIP == nil {
-		return fmt.Errorf("only IPv4 addresses are currently supported")
-	}
-	if !ipInRange(checkIP, poolStartIP, poolEndIP) {
+	if checkIP = sameFamily(checkIP, poolStartIP); checkIP == nil || !ipInRange(checkIP, poolStartIP, poolEndIP) {
 		return fmt.Errorf("IP address %s is not within pool range", reservation.IPAddress)
 	}
 
@@ -80,6 +77,92 @@ func (s *SQLiteStorage) CreateReservation(ctx context.Context, reservation *mode
 		return ErrIPAlreadyReserved
 	}
 
+	if err := insertReservation(ctx, tx, reservation); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.auditLog(ctx, "create", "reservation", reservation.ID, reservation)
+	return nil
+}
+
+// AllocateIP reserves the lowest IP of the reservation's pool that is neither
+// used by an address nor held by an active reservation, and sets
+// reservation.IPAddress to it. The search and the insert share one write
+// transaction, so concurrent callers are never handed the same IP. Active
+// reservations past their expiry are marked expired first, freeing their IPs.
+func (s *SQLiteStorage) AllocateIP(ctx context.Context, reservation *model.Reservation) error {
+	if reservation == nil {
+		return fmt.Errorf("reservation is nil")
+	}
+	if reservation.PoolID == "" {
+		return ErrInvalidID
+	}
+
+	// Generate ID if not provided
+	if reservation.ID == "" {
+		reservation.ID = newUUID()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	pool := &model.NetworkPool{ID: reservation.PoolID}
+	err = tx.QueryRowContext(ctx, `SELECT start_ip, end_ip FROM network_pools WHERE id = ?`, reservation.PoolID).Scan(&pool.StartIP, &pool.EndIP)
+	if err == sql.ErrNoRows {
+		return ErrPoolNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check pool existence: %w", err)
+	}
+
+	now := nowUTC()
+	if err := expirePoolReservations(ctx, tx, reservation.PoolID, now); err != nil {
+		return err
+	}
+
+	ip, err := nextFreeIP(ctx, tx, pool, now, nil)
+	if err != nil {
+		return err
+	}
+	reservation.IPAddress = ip
+	reservation.Status = model.ReservationStatusActive
+
+	if err := insertReservation(ctx, tx, reservation); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.auditLog(ctx, "create", "reservation", reservation.ID, reservation)
+	return nil
+}
+
+// expirePoolReservations marks the pool's active reservations that expired
+// before now as expired, so their IPs can be reserved again
+func expirePoolReservations(ctx context.Context, tx *sql.Tx, poolID string, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE reservations SET status = ?, updated_at = ?
+		WHERE pool_id = ? AND status = ? AND expires_at IS NOT NULL AND expires_at < ?
+	`, string(model.ReservationStatusExpired), now, poolID,
+		string(model.ReservationStatusActive), now)
+	if err != nil {
+		return fmt.Errorf("failed to expire reservations: %w", err)
+	}
+	return nil
+}
+
+// insertReservation writes a new reservation row within a transaction,
+// filling in its timestamps and default status
+func insertReservation(ctx context.Context, tx *sql.Tx, reservation *model.Reservation) error {
 	now := nowUTC()
 	if reservation.ReservedAt.IsZero() {
 		reservation.ReservedAt = now
@@ -92,15 +175,15 @@ func (s *SQLiteStorage) CreateReservation(ctx context.Context, reservation *mode
 		reservation.Status = model.ReservationStatusActive
 	}
 
-	_, err = tx.ExecContext(ctx, `
+	_, err := tx.ExecContext(ctx, `
 		INSERT INTO reservations (
-			id, pool_id, ip_address, hostname, purpose, reserved_by, reserved_at,
+			id, pool_id, ip_address, device_id, hostname, purpose, reserved_by, reserved_at,
 			expires_at, status, notes, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, reservation.ID, reservation.PoolID, reservation.IPAddress, nullString(reservation.Hostname),
-		nullString(reservation.Purpose), reservation.ReservedBy, reservation.ReservedAt,
-		nullTime(reservation.ExpiresAt), string(reservation.Status), nullString(reservation.Notes),
-		reservation.CreatedAt, reservation.UpdatedAt)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, reservation.ID, reservation.PoolID, reservation.IPAddress, nullString(reservation.DeviceID),
+		nullString(reservation.Hostname), nullString(reservation.Purpose), reservation.ReservedBy,
+		reservation.ReservedAt, nullTime(reservation.ExpiresAt), string(reservation.Status),
+		nullString(reservation.Notes), reservation.CreatedAt, reservation.UpdatedAt)
 
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
@@ -108,12 +191,6 @@ func (s *SQLiteStorage) CreateReservation(ctx context.Context, reservation *mode
 		}
 		return fmt.Errorf("failed to create reservation: %w", err)
 	}
-
-	if err := tx.Commit(); err != nil {
-		return err
-	}
-
-	s.auditLog(ctx, "create", "reservation", reservation.ID, reservation)
 	return nil
 }
 
@@ -124,15 +201,15 @@ func (s *SQLiteStorage) GetReservation(ctx context.Context, id string) (*model.R
 	}
 
 	var reservation model.Reservation
-	var hostname, purpose, notes sql.NullString
+	var deviceID, hostname, purpose, notes sql.NullString
 	var expiresAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, pool_id, ip_address, hostname, purpose, reserved_by, reserved_at,
+	err := s.reader.QueryRowContext(ctx, `
+		SELECT id, pool_id, ip_address, device_id, hostname, purpose, reserved_by, reserved_at,
 		       expires_at, status, notes, created_at, updated_at
 		FROM reservations WHERE id = ?
 	`, id).Scan(
-		&reservation.ID, &reservation.PoolID, &reservation.IPAddress, &hostname, &purpose,
+		&reservation.ID, &reservation.PoolID, &reservation.IPAddress, &deviceID, &hostname, &purpose,
 		&reservation.ReservedBy, &reservation.ReservedAt, &expiresAt, &reservation.Status,
 		&notes, &reservation.CreatedAt, &reservation.UpdatedAt,
 	)
@@ -144,6 +221,9 @@ func (s *SQLiteStorage) GetReservation(ctx context.Context, id string) (*model.R
 		return nil, fmt.Errorf("failed to get reservation: %w", err)
 	}
 
+	if deviceID.Valid {
+		reservation.DeviceID = deviceID.String
+	}
 	if hostname.Valid {
 		reservation.Hostname = hostname.String
 	}
@@ -167,15 +247,15 @@ func (s *SQLiteStorage) GetReservationByIP(ctx context.Context, poolID, ip strin
 	}
 
 	var reservation model.Reservation
-	var hostname, purpose, notes sql.NullString
+	var deviceID, hostname, purpose, notes sql.NullString
 	var expiresAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, pool_id, ip_address, hostname, purpose, reserved_by, reserved_at,
+	err := s.reader.QueryRowContext(ctx, `
+		SELECT id, pool_id, ip_address, device_id, hostname, purpose, reserved_by, reserved_at,
 		       expires_at, status, notes, created_at, updated_at
 		FROM reservations WHERE pool_id = ? AND ip_address = ? AND status = ?
 	`, poolID, ip, string(model.ReservationStatusActive)).Scan(
-		&reservation.ID, &reservation.PoolID, &reservation.IPAddress, &hostname, &purpose,
+		&reservation.ID, &reservation.PoolID, &reservation.IPAddress, &deviceID, &hostname, &purpose,
 		&reservation.ReservedBy, &reservation.ReservedAt, &expiresAt, &reservation.Status,
 		&notes, &reservation.CreatedAt, &reservation.UpdatedAt,
 	)
@@ -187,6 +267,9 @@ func (s *SQLiteStorage) GetReservationByIP(ctx context.Context, poolID, ip strin
 		return nil, fmt.Errorf("failed to get reservation by IP: %w", err)
 	}
 
+	if deviceID.Valid {
+		reservation.DeviceID = deviceID.String
+	}
 	if hostname.Valid {
 		reservation.Hostname = hostname.String
 	}
@@ -206,7 +289,7 @@ func (s *SQLiteStorage) GetReservationByIP(ctx context.Context, poolID, ip strin
 // ListReservations retrieves reservations matching filter criteria
 func (s *SQLiteStorage) ListReservations(ctx context.Context, filter *model.ReservationFilter) ([]model.Reservation, error) {
 
-	query := `SELECT id, pool_id, ip_address, hostname, purpose, reserved_by, reserved_at,
+	query := `SELECT id, pool_id, ip_address, device_id, hostname, purpose, reserved_by, reserved_at,
 	          expires_at, status, notes, created_at, updated_at
 	          FROM reservations`
 	var args []any
@@ -243,7 +326,7 @@ func (s *SQLiteStorage) ListReservations(ctx context.Context, filter *model.Rese
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list reservations: %w", err)
 	}
@@ -265,9 +348,9 @@ func (s *SQLiteStorage) UpdateReservation(ctx context.Context, reservation *mode
 
 	_, err := s.db.ExecContext(ctx, `
 		UPDATE reservations SET
-			hostname = ?, purpose = ?, expires_at = ?, status = ?, notes = ?, updated_at = ?
+			device_id = ?, hostname = ?, purpose = ?, expires_at = ?, status = ?, notes = ?, updated_at = ?
 		WHERE id = ?
-	`, nullString(reservation.Hostname), nullString(reservation.Purpose),
+	`, nullString(reservation.DeviceID), nullString(reservation.Hostname), nullString(reservation.Purpose),
 		nullTime(reservation.ExpiresAt), string(reservation.Status),
 		nullString(reservation.Notes), reservation.UpdatedAt, reservation.ID)
 
@@ -300,8 +383,8 @@ func (s *SQLiteStorage) GetReservationsByPool(ctx context.Context, poolID string
 		return nil, ErrInvalidID
 	}
 
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, pool_id, ip_address, hostname, purpose, reserved_by, reserved_at,
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, pool_id, ip_address, device_id, hostname, purpose, reserved_by, reserved_at,
 		       expires_at, status, notes, created_at, updated_at
 		FROM reservations WHERE pool_id = ? AND status = ?
 		ORDER BY ip_address
@@ -320,8 +403,8 @@ func (s *SQLiteStorage) GetReservationsByUser(ctx context.Context, userID string
 		return nil, ErrInvalidID
 	}
 
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, pool_id, ip_address, hostname, purpose, reserved_by, reserved_at,
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, pool_id, ip_address, device_id, hostname, purpose, reserved_by, reserved_at,
 		       expires_at, status, notes, created_at, updated_at
 		FROM reservations WHERE reserved_by = ?
 		ORDER BY reserved_at DESC
@@ -360,7 +443,7 @@ func (s *SQLiteStorage) IsIPReserved(ctx context.Context, poolID, ip string) (bo
 	}
 
 	var count int
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM reservations
 		WHERE pool_id = ? AND ip_address = ? AND status = ?
 	`, poolID, ip, string(model.ReservationStatusActive)).Scan(&count)
@@ -376,17 +459,20 @@ func scanReservations(rows *sql.Rows) ([]model.Reservation, error) {
 	var reservations []model.Reservation
 	for rows.Next() {
 		var reservation model.Reservation
-		var hostname, purpose, notes sql.NullString
+		var deviceID, hostname, purpose, notes sql.NullString
 		var expiresAt sql.NullTime
 
 		if err := rows.Scan(
-			&reservation.ID, &reservation.PoolID, &reservation.IPAddress, &hostname, &purpose,
+			&reservation.ID, &reservation.PoolID, &reservation.IPAddress, &deviceID, &hostname, &purpose,
 			&reservation.ReservedBy, &reservation.ReservedAt, &expiresAt, &reservation.Status,
 			&notes, &reservation.CreatedAt, &reservation.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan reservation: %w", err)
 		}
 
+		if deviceID.Valid {
+			reservation.DeviceID = deviceID.String
+		}
 		if hostname.Valid {
 			reservation.Hostname = hostname.String
 		}
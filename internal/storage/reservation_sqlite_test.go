@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -731,3 +732,125 @@ func TestReservationOperations_CreateNil(t *testing.T) {
 		t.Error("expected error for nil reservation")
 	}
 }
+
+func TestReservationOperations_AllocateIP(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "Alloc Network", Subnet: "10.5.0.0/24"}
+	if err := storage.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "Alloc Pool", StartIP: "10.5.0.10", EndIP: "10.5.0.13"}
+	if err := storage.CreateNetworkPool(ctx, pool); err != nil {
+		t.Fatalf("CreateNetworkPool failed: %v", err)
+	}
+
+	// .10 is used by a device and .11 is reserved
+	device := &model.Device{Name: "alloc-dev", Addresses: []model.Address{{IP: "10.5.0.10", PoolID: pool.ID}}}
+	if err := storage.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+	if err := storage.CreateReservation(ctx, &model.Reservation{PoolID: pool.ID, IPAddress: "10.5.0.11", ReservedBy: "admin"}); err != nil {
+		t.Fatalf("CreateReservation failed: %v", err)
+	}
+
+	// .12 was reserved but has expired, so it is free again
+	past := time.Now().UTC().Add(-time.Minute)
+	stale := &model.Reservation{PoolID: pool.ID, IPAddress: "10.5.0.12", ReservedBy: "admin", ExpiresAt: &past}
+	if err := storage.CreateReservation(ctx, stale); err != nil {
+		t.Fatalf("CreateReservation failed: %v", err)
+	}
+
+	next, err := storage.GetNextAvailableIP(ctx, pool.ID)
+	if err != nil {
+		t.Fatalf("GetNextAvailableIP failed: %v", err)
+	}
+	if next != "10.5.0.12" {
+		t.Errorf("expected next IP 10.5.0.12, got %s", next)
+	}
+
+	first := &model.Reservation{PoolID: pool.ID, DeviceID: device.ID, ReservedBy: "admin"}
+	if err := storage.AllocateIP(ctx, first); err != nil {
+		t.Fatalf("AllocateIP failed: %v", err)
+	}
+	if first.IPAddress != "10.5.0.12" {
+		t.Errorf("expected 10.5.0.12, got %s", first.IPAddress)
+	}
+
+	got, err := storage.GetReservation(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetReservation failed: %v", err)
+	}
+	if got.DeviceID != device.ID || got.Status != model.ReservationStatusActive {
+		t.Errorf("unexpected reservation: %+v", got)
+	}
+	if got, _ := storage.GetReservation(ctx, stale.ID); got.Status != model.ReservationStatusExpired {
+		t.Errorf("expected stale reservation to be expired, got %s", got.Status)
+	}
+
+	second := &model.Reservation{PoolID: pool.ID, ReservedBy: "admin"}
+	if err := storage.AllocateIP(ctx, second); err != nil {
+		t.Fatalf("AllocateIP failed: %v", err)
+	}
+	if second.IPAddress != "10.5.0.13" {
+		t.Errorf("expected 10.5.0.13, got %s", second.IPAddress)
+	}
+
+	if err := storage.AllocateIP(ctx, &model.Reservation{PoolID: pool.ID, ReservedBy: "admin"}); err != ErrIPNotAvailable {
+		t.Errorf("expected ErrIPNotAvailable, got %v", err)
+	}
+	if err := storage.AllocateIP(ctx, &model.Reservation{PoolID: "missing", ReservedBy: "admin"}); err != ErrPoolNotFound {
+		t.Errorf("expected ErrPoolNotFound, got %v", err)
+	}
+}
+
+func TestReservationOperations_AllocateIPConcurrent(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "Race Network", Subnet: "2001:db8:5::/64"}
+	if err := storage.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "Race Pool", StartIP: "2001:db8:5::1", EndIP: "2001:db8:5::ffff:ffff"}
+	if err := storage.CreateNetworkPool(ctx, pool); err != nil {
+		t.Fatalf("CreateNetworkPool failed: %v", err)
+	}
+
+	const callers = 20
+	ips := make(chan string, callers)
+	errs := make(chan error, callers)
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := &model.Reservation{PoolID: pool.ID, ReservedBy: "admin"}
+			if err := storage.AllocateIP(ctx, r); err != nil {
+				errs <- err
+				return
+			}
+			ips <- r.IPAddress
+		}()
+	}
+	wg.Wait()
+	close(ips)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("AllocateIP failed: %v", err)
+	}
+	seen := make(map[string]bool)
+	for ip := range ips {
+		if seen[ip] {
+			t.Errorf("IP %s allocated twice", ip)
+		}
+		seen[ip] = true
+	}
+	if len(seen) != callers {
+		t.Errorf("expected %d distinct IPs, got %d", callers, len(seen))
+	}
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+const scanBlackoutColumns = `id, network_id, name, enabled, type, weekdays, start_time, end_time,
+	cron_expression, duration_minutes, timezone, description, created_at, updated_at`
+
+// CreateScanBlackout inserts a new scan blackout window
+func (s *SQLiteStorage) CreateScanBlackout(ctx context.Context, b *model.ScanBlackout) error {
+	if b.ID == "" {
+		b.ID = newUUID()
+	}
+	now := nowUTC()
+	b.CreatedAt = now
+	b.UpdatedAt = now
+	if b.Weekdays == nil {
+		b.Weekdays = []string{}
+	}
+	weekdays, _ := json.Marshal(b.Weekdays)
+	enabled := 0
+	if b.Enabled {
+		enabled = 1
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO scan_blackouts (`+scanBlackoutColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, b.ID, nullString(b.NetworkID), b.Name, enabled, b.Type, string(weekdays), b.StartTime, b.EndTime,
+		b.CronExpression, b.DurationMinutes, b.Timezone, b.Description, b.CreatedAt, b.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	s.auditLog(ctx, "create", "scan_blackout", b.ID, b)
+	return nil
+}
+
+// UpdateScanBlackout updates an existing scan blackout window
+func (s *SQLiteStorage) UpdateScanBlackout(ctx context.Context, b *model.ScanBlackout) error {
+	b.UpdatedAt = nowUTC()
+	if b.Weekdays == nil {
+		b.Weekdays = []string{}
+	}
+	weekdays, _ := json.Marshal(b.Weekdays)
+	enabled := 0
+	if b.Enabled {
+		enabled = 1
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE scan_blackouts SET network_id = ?, name = ?, enabled = ?, type = ?, weekdays = ?,
+			start_time = ?, end_time = ?, cron_expression = ?, duration_minutes = ?, timezone = ?,
+			description = ?, updated_at = ?
+		WHERE id = ?
+	`, nullString(b.NetworkID), b.Name, enabled, b.Type, string(weekdays), b.StartTime, b.EndTime,
+		b.CronExpression, b.DurationMinutes, b.Timezone, b.Description, b.UpdatedAt, b.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrScanBlackoutNotFound
+	}
+	s.auditLog(ctx, "update", "scan_blackout", b.ID, b)
+	return nil
+}
+
+// GetScanBlackout retrieves a scan blackout window by ID
+func (s *SQLiteStorage) GetScanBlackout(ctx context.Context, id string) (*model.ScanBlackout, error) {
+	row := s.reader.QueryRowContext(ctx, `SELECT `+scanBlackoutColumns+` FROM scan_blackouts WHERE id = ?`, id)
+	b, err := scanScanBlackout(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrScanBlackoutNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ListScanBlackouts returns scan blackout windows. With a network ID only
+// the windows applying to that network are returned, including global ones.
+func (s *SQLiteStorage) ListScanBlackouts(ctx context.Context, networkID string) ([]model.ScanBlackout, error) {
+	query := `SELECT ` + scanBlackoutColumns + ` FROM scan_blackouts`
+	var args []any
+	if networkID != "" {
+		query += " WHERE network_id IS NULL OR network_id = ?"
+		args = append(args, networkID)
+	}
+	query += " ORDER BY created_at"
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blackouts := []model.ScanBlackout{}
+	for rows.Next() {
+		b, err := scanScanBlackout(rows)
+		if err != nil {
+			return nil, err
+		}
+		blackouts = append(blackouts, *b)
+	}
+	return blackouts, rows.Err()
+}
+
+// DeleteScanBlackout removes a scan blackout window by ID
+func (s *SQLiteStorage) DeleteScanBlackout(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM scan_blackouts WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrScanBlackoutNotFound
+	}
+	s.auditLog(ctx, "delete", "scan_blackout", id, nil)
+	return nil
+}
+
+func scanScanBlackout(row interface{ Scan(...any) error }) (*model.ScanBlackout, error) {
+	var b model.ScanBlackout
+	var networkID sql.NullString
+	var enabled int
+	var weekdays string
+	if err := row.Scan(&b.ID, &networkID, &b.Name, &enabled, &b.Type, &weekdays, &b.StartTime, &b.EndTime,
+		&b.CronExpression, &b.DurationMinutes, &b.Timezone, &b.Description, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		return nil, err
+	}
+	b.NetworkID = networkID.String
+	b.Enabled = enabled == 1
+	b.Weekdays = []string{}
+	json.Unmarshal([]byte(weekdays), &b.Weekdays)
+	return &b, nil
+}
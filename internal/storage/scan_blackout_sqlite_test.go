@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestScanBlackoutCRUD(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "TestNet", Subnet: "192.168.1.0/24"}
+	storage.CreateNetwork(ctx, network)
+	other := &model.Network{Name: "OtherNet", Subnet: "192.168.2.0/24"}
+	storage.CreateNetwork(ctx, other)
+
+	blackout := &model.ScanBlackout{
+		NetworkID: network.ID,
+		Name:      "change freeze",
+		Enabled:   true,
+		Type:      model.BlackoutWeekly,
+		Weekdays:  []string{"mon", "fri"},
+		StartTime: "22:00",
+		EndTime:   "06:00",
+		Timezone:  "Europe/London",
+	}
+	if err := storage.CreateScanBlackout(ctx, blackout); err != nil {
+		t.Fatalf("CreateScanBlackout failed: %v", err)
+	}
+	global := &model.ScanBlackout{Name: "month end", Enabled: true, Type: model.BlackoutCron, CronExpression: "0 0 1 * *", DurationMinutes: 120}
+	storage.CreateScanBlackout(ctx, global)
+	storage.CreateScanBlackout(ctx, &model.ScanBlackout{NetworkID: other.ID, Name: "other", Type: model.BlackoutWeekly, StartTime: "01:00", EndTime: "02:00"})
+
+	got, err := storage.GetScanBlackout(ctx, blackout.ID)
+	if err != nil {
+		t.Fatalf("GetScanBlackout failed: %v", err)
+	}
+	if !got.Enabled || got.NetworkID != network.ID || len(got.Weekdays) != 2 || got.StartTime != "22:00" || got.Timezone != "Europe/London" {
+		t.Errorf("blackout mismatch: got %+v", got)
+	}
+	got, _ = storage.GetScanBlackout(ctx, global.ID)
+	if got.NetworkID != "" || got.CronExpression != "0 0 1 * *" || got.DurationMinutes != 120 || got.Weekdays == nil {
+		t.Errorf("global blackout mismatch: got %+v", got)
+	}
+
+	// A network's blackouts include the global ones
+	blackouts, err := storage.ListScanBlackouts(ctx, network.ID)
+	if err != nil || len(blackouts) != 2 {
+		t.Fatalf("expected 2 blackouts for network, got %d (%v)", len(blackouts), err)
+	}
+	if all, _ := storage.ListScanBlackouts(ctx, ""); len(all) != 3 {
+		t.Errorf("expected 3 blackouts overall, got %d", len(all))
+	}
+
+	blackout.Enabled = false
+	blackout.Weekdays = nil
+	if err := storage.UpdateScanBlackout(ctx, blackout); err != nil {
+		t.Fatalf("UpdateScanBlackout failed: %v", err)
+	}
+	got, _ = storage.GetScanBlackout(ctx, blackout.ID)
+	if got.Enabled || got.Weekdays == nil || len(got.Weekdays) != 0 {
+		t.Errorf("update failed: got %+v", got)
+	}
+
+	if err := storage.DeleteScanBlackout(ctx, blackout.ID); err != nil {
+		t.Fatalf("DeleteScanBlackout failed: %v", err)
+	}
+	if _, err := storage.GetScanBlackout(ctx, blackout.ID); !errors.Is(err, ErrScanBlackoutNotFound) {
+		t.Errorf("expected ErrScanBlackoutNotFound, got %v", err)
+	}
+	if err := storage.DeleteScanBlackout(ctx, blackout.ID); !errors.Is(err, ErrScanBlackoutNotFound) {
+		t.Errorf("expected ErrScanBlackoutNotFound on second delete, got %v", err)
+	}
+	if err := storage.UpdateScanBlackout(ctx, blackout); !errors.Is(err, ErrScanBlackoutNotFound) {
+		t.Errorf("expected ErrScanBlackoutNotFound on update, got %v", err)
+	}
+}
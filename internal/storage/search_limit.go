@@ -0,0 +1,30 @@
+package storage
+
+import "context"
+
+type searchLimitKey struct{}
+
+// WithSearchLimit caps the number of rows a search may match. Searches that
+// match more fail with ErrSearchTooBroad before loading addresses, tags and
+// domains for the matches.
+func WithSearchLimit(ctx context.Context, maxRows int) context.Context {
+	return context.WithValue(ctx, searchLimitKey{}, maxRows)
+}
+
+// searchLimitClause returns the LIMIT clause and its argument for a search
+// under ctx. One row past the cap is fetched so checkSearchLimit can tell a
+// search that hit the cap from one that exceeded it.
+func searchLimitClause(ctx context.Context) (string, []any) {
+	maxRows, _ := ctx.Value(searchLimitKey{}).(int)
+	if maxRows <= 0 {
+		return "", nil
+	}
+	return " LIMIT ?", []any{maxRows + 1}
+}
+
+func checkSearchLimit(ctx context.Context, matched int) error {
+	if maxRows, _ := ctx.Value(searchLimitKey{}).(int); maxRows > 0 && matched > maxRows {
+		return ErrSearchTooBroad
+	}
+	return nil
+}
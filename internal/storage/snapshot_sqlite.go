@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"strings"
 
 	"github.com/martinsuchenak/rackd/internal/model"
@@ -57,7 +58,7 @@ func (s *SQLiteStorage) ListSnapshots(ctx context.Context, filter *model.Snapsho
 
 	query, args = appendPagination(query, args, &filter.Pagination)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +78,7 @@ func (s *SQLiteStorage) GetLatestSnapshots(ctx context.Context, snapshotType mod
 		)
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, snapshotType)
+	rows, err := s.reader.QueryContext(ctx, query, snapshotType)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +104,7 @@ func (s *SQLiteStorage) GetUtilizationTrend(ctx context.Context, resourceType mo
 		ORDER BY timestamp ASC
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, resourceType, resourceID, cutoff)
+	rows, err := s.reader.QueryContext(ctx, query, resourceType, resourceID, cutoff)
 	if err != nil {
 		return nil, err
 	}
@@ -131,39 +132,40 @@ func (s *SQLiteStorage) GetDashboardStats(ctx context.Context, staleDays int, re
 	}
 
 	// Total devices
-	s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM devices`).Scan(&stats.TotalDevices)
+	s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM devices WHERE deleted_at IS NULL`).Scan(&stats.TotalDevices)
 
 	// Total networks
-	s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM networks`).Scan(&stats.TotalNetworks)
+	s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM networks WHERE deleted_at IS NULL`).Scan(&stats.TotalNetworks)
 
 	// Total pools
-	s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM network_pools`).Scan(&stats.TotalPools)
+	s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM network_pools`).Scan(&stats.TotalPools)
 
 	// Total datacenters
-	s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM datacenters`).Scan(&stats.TotalDatacenters)
+	s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM datacenters WHERE deleted_at IS NULL`).Scan(&stats.TotalDatacenters)
 
 	// Device status counts
-	s.db.QueryRowContext(ctx, `
+	s.reader.QueryRowContext(ctx, `
 		SELECT
 			SUM(CASE WHEN status = 'planned' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'ordered' THEN 1 ELSE 0 END),
 			SUM(CASE WHEN status = 'active' THEN 1 ELSE 0 END),
 			SUM(CASE WHEN status = 'maintenance' THEN 1 ELSE 0 END),
 			SUM(CASE WHEN status = 'decommissioned' THEN 1 ELSE 0 END)
-		FROM devices
-	`).Scan(&stats.DeviceStatusCounts.Planned, &stats.DeviceStatusCounts.Active,
+		FROM devices WHERE deleted_at IS NULL
+	`).Scan(&stats.DeviceStatusCounts.Planned, &stats.DeviceStatusCounts.Ordered, &stats.DeviceStatusCounts.Active,
 		&stats.DeviceStatusCounts.Maintenance, &stats.DeviceStatusCounts.Decommissioned)
 
 	// Discovered devices count
-	s.db.QueryRowContext(ctx,
+	s.reader.QueryRowContext(ctx,
 		`SELECT COUNT(*) FROM discovered_devices WHERE promoted_to_device_id IS NULL`).Scan(&stats.DiscoveredDevices)
 
 	// Stale devices (active devices not seen in discovery for X days)
 	staleCutoff := nowUTC().AddDate(0, 0, -staleDays)
 
 	// Get count
-	s.db.QueryRowContext(ctx, `
+	s.reader.QueryRowContext(ctx, `
 		SELECT COUNT(DISTINCT d.id) FROM devices d
-		WHERE d.status = 'active'
+		WHERE d.status = 'active' AND d.deleted_at IS NULL
 		AND NOT EXISTS (
 			SELECT 1 FROM discovered_devices dd
 			WHERE dd.promoted_to_device_id = d.id
@@ -172,10 +174,10 @@ func (s *SQLiteStorage) GetDashboardStats(ctx context.Context, staleDays int, re
 	`, staleCutoff).Scan(&stats.StaleDevices)
 
 	// Get list of stale devices (limit to 20 for dashboard)
-	staleRows, err := s.db.QueryContext(ctx, `
+	staleRows, err := s.reader.QueryContext(ctx, `
 		SELECT d.id, d.name, d.hostname
 		FROM devices d
-		WHERE d.status = 'active'
+		WHERE d.status = 'active' AND d.deleted_at IS NULL
 		AND NOT EXISTS (
 			SELECT 1 FROM discovered_devices dd
 			WHERE dd.promoted_to_device_id = d.id
@@ -198,7 +200,7 @@ func (s *SQLiteStorage) GetDashboardStats(ctx context.Context, staleDays int, re
 	}
 
 	// Recent discoveries
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT id, ip, hostname, vendor, network_id, first_seen, last_seen
 		FROM discovered_devices
 		WHERE promoted_to_device_id IS NULL
@@ -247,6 +249,143 @@ func (s *SQLiteStorage) GetDashboardStats(ctx context.Context, staleDays int, re
 	return stats, nil
 }
 
+// GetDatacenterStats aggregates the devices, networks, pools and racks of a
+// datacenter along with its most recently changed devices and networks
+func (s *SQLiteStorage) GetDatacenterStats(ctx context.Context, datacenterID string, recentLimit int) (*model.DatacenterStats, error) {
+	if datacenterID == "" {
+		return nil, ErrInvalidID
+	}
+
+	stats := &model.DatacenterStats{DatacenterID: datacenterID}
+
+	err := s.reader.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN status = 'planned' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'ordered' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'active' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'maintenance' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'decommissioned' THEN 1 ELSE 0 END), 0)
+		FROM devices WHERE datacenter_id = ? AND deleted_at IS NULL
+	`, datacenterID).Scan(&stats.TotalDevices, &stats.DeviceStatusCounts.Planned, &stats.DeviceStatusCounts.Ordered,
+		&stats.DeviceStatusCounts.Active, &stats.DeviceStatusCounts.Maintenance, &stats.DeviceStatusCounts.Decommissioned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count devices: %w", err)
+	}
+
+	if stats.DevicesByTag, err = s.countValues(ctx, `
+		SELECT t.tag, COUNT(*) FROM tags t JOIN devices d ON d.id = t.device_id
+		WHERE d.datacenter_id = ? AND d.deleted_at IS NULL
+		GROUP BY t.tag ORDER BY COUNT(*) DESC, t.tag
+	`, datacenterID); err != nil {
+		return nil, fmt.Errorf("failed to count device tags: %w", err)
+	}
+
+	if stats.DevicesByOS, err = s.countValues(ctx, `
+		SELECT COALESCE(os, ''), COUNT(*) FROM devices
+		WHERE datacenter_id = ? AND deleted_at IS NULL
+		GROUP BY COALESCE(os, '') ORDER BY COUNT(*) DESC, COALESCE(os, '')
+	`, datacenterID); err != nil {
+		return nil, fmt.Errorf("failed to count device operating systems: %w", err)
+	}
+
+	if err := s.reader.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM networks WHERE datacenter_id = ? AND deleted_at IS NULL`, datacenterID,
+	).Scan(&stats.TotalNetworks); err != nil {
+		return nil, fmt.Errorf("failed to count networks: %w", err)
+	}
+
+	pools, err := s.GetPoolStatsSummary(ctx, &model.PoolStatsFilter{DatacenterID: datacenterID})
+	if err != nil {
+		return nil, err
+	}
+	stats.PoolUtilization = *pools
+
+	if stats.Racks, err = s.rackUsage(ctx, datacenterID); err != nil {
+		return nil, err
+	}
+
+	if stats.RecentChanges, err = s.recentChanges(ctx, datacenterID, recentLimit); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// countValues runs a query selecting a value and a count per row
+func (s *SQLiteStorage) countValues(ctx context.Context, query string, args ...any) ([]model.ValueCount, error) {
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []model.ValueCount{}
+	for rows.Next() {
+		var c model.ValueCount
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// rackUsage counts the members of each rack in a datacenter, fullest first
+func (s *SQLiteStorage) rackUsage(ctx context.Context, datacenterID string) ([]model.RackUsage, error) {
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT r.id, r.name, COALESCE(r.location, ''), COUNT(*)
+		FROM devices r
+		JOIN device_relationships dr ON dr.parent_id = r.id AND dr.type = ?
+		JOIN devices m ON m.id = dr.child_id AND m.deleted_at IS NULL
+		WHERE r.datacenter_id = ? AND r.deleted_at IS NULL
+		GROUP BY r.id, r.name, r.location
+		ORDER BY COUNT(*) DESC, r.name
+	`, model.RelationshipContains, datacenterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query racks: %w", err)
+	}
+	defer rows.Close()
+
+	racks := []model.RackUsage{}
+	for rows.Next() {
+		var r model.RackUsage
+		if err := rows.Scan(&r.DeviceID, &r.Name, &r.Location, &r.Devices); err != nil {
+			return nil, fmt.Errorf("failed to scan rack: %w", err)
+		}
+		racks = append(racks, r)
+	}
+	return racks, rows.Err()
+}
+
+// recentChanges returns the devices and networks of a datacenter that were
+// created or updated last, newest first
+func (s *SQLiteStorage) recentChanges(ctx context.Context, datacenterID string, limit int) ([]model.RecentChange, error) {
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT 'device', id, name, updated_at FROM devices
+		WHERE datacenter_id = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT 'network', id, name, updated_at FROM networks
+		WHERE datacenter_id = ? AND deleted_at IS NULL
+		ORDER BY 4 DESC
+		LIMIT ?
+	`, datacenterID, datacenterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []model.RecentChange{}
+	for rows.Next() {
+		var c model.RecentChange
+		if err := rows.Scan(&c.Type, &c.ID, &c.Name, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recent change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
 // scanSnapshots helper function
 func scanSnapshots(rows *sql.Rows) ([]model.UtilizationSnapshot, error) {
 	var snapshots []model.UtilizationSnapshot
@@ -581,3 +581,79 @@ func TestDashboardStats_NetworkUtilization(t *testing.T) {
 		t.Errorf("expected network name '%s', got '%s'", network.Name, stats.NetworkUtilization[0].NetworkName)
 	}
 }
+
+func TestDatacenterStats(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "fra1"}
+	other := &model.Datacenter{Name: "ams1"}
+	for _, d := range []*model.Datacenter{dc, other} {
+		if err := storage.CreateDatacenter(ctx, d); err != nil {
+			t.Fatalf("CreateDatacenter failed: %v", err)
+		}
+	}
+
+	devices := []*model.Device{
+		{Name: "rack-a", DatacenterID: dc.ID, Status: model.DeviceStatusActive},
+		{Name: "web-01", DatacenterID: dc.ID, OS: "Debian 12", Tags: []string{"web", "prod"}, Status: model.DeviceStatusActive},
+		{Name: "web-02", DatacenterID: dc.ID, OS: "Debian 12", Tags: []string{"web"}, Status: model.DeviceStatusPlanned},
+		{Name: "db-01", DatacenterID: dc.ID, OS: "Ubuntu 24.04", Tags: []string{"prod"}, Status: model.DeviceStatusMaintenance},
+		{Name: "elsewhere", DatacenterID: other.ID, OS: "Debian 12", Tags: []string{"web"}, Status: model.DeviceStatusActive},
+	}
+	for _, d := range devices {
+		if err := storage.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+	}
+	for _, child := range devices[1:3] {
+		if err := storage.AddRelationship(ctx, devices[0].ID, child.ID, model.RelationshipContains, ""); err != nil {
+			t.Fatalf("AddRelationship failed: %v", err)
+		}
+	}
+
+	network := &model.Network{Name: "fra1-lan", Subnet: "10.0.0.0/24", DatacenterID: dc.ID}
+	if err := storage.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	pool := &model.NetworkPool{NetworkID: network.ID, Name: "servers", StartIP: "10.0.0.10", EndIP: "10.0.0.19"}
+	if err := storage.CreateNetworkPool(ctx, pool); err != nil {
+		t.Fatalf("CreateNetworkPool failed: %v", err)
+	}
+
+	stats, err := storage.GetDatacenterStats(ctx, dc.ID, 3)
+	if err != nil {
+		t.Fatalf("GetDatacenterStats failed: %v", err)
+	}
+
+	if stats.TotalDevices != 4 {
+		t.Errorf("expected 4 devices, got %d", stats.TotalDevices)
+	}
+	if stats.DeviceStatusCounts.Active != 2 || stats.DeviceStatusCounts.Planned != 1 || stats.DeviceStatusCounts.Maintenance != 1 {
+		t.Errorf("unexpected status counts: %+v", stats.DeviceStatusCounts)
+	}
+	wantTags := []model.ValueCount{{Value: "prod", Count: 2}, {Value: "web", Count: 2}}
+	if len(stats.DevicesByTag) != 2 || stats.DevicesByTag[0] != wantTags[0] || stats.DevicesByTag[1] != wantTags[1] {
+		t.Errorf("expected tags %v, got %v", wantTags, stats.DevicesByTag)
+	}
+	if len(stats.DevicesByOS) != 3 || stats.DevicesByOS[0] != (model.ValueCount{Value: "Debian 12", Count: 2}) {
+		t.Errorf("unexpected OS counts: %v", stats.DevicesByOS)
+	}
+	if stats.TotalNetworks != 1 {
+		t.Errorf("expected 1 network, got %d", stats.TotalNetworks)
+	}
+	if stats.PoolUtilization.PoolCount != 1 || stats.PoolUtilization.IPv4.Total != 10 {
+		t.Errorf("unexpected pool utilization: %+v", stats.PoolUtilization)
+	}
+	if len(stats.Racks) != 1 || stats.Racks[0].DeviceID != devices[0].ID || stats.Racks[0].Devices != 2 {
+		t.Errorf("unexpected racks: %+v", stats.Racks)
+	}
+	if len(stats.RecentChanges) != 3 {
+		t.Errorf("expected 3 recent changes, got %d", len(stats.RecentChanges))
+	}
+
+	if _, err := storage.GetDatacenterStats(ctx, "", 10); err != ErrInvalidID {
+		t.Errorf("expected ErrInvalidID, got %v", err)
+	}
+}
@@ -7,6 +7,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,65 +20,52 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-// SQLiteStorage implements ExtendedStorage using SQLite
+// SQLiteStorage implements ExtendedStorage using SQLite.
+//
+// Writes go through db, a single connection, since SQLite allows one writer at
+// a time. Reads go through reader, a pool of read-only connections that WAL
+// mode lets run alongside the writer and each other. An in-memory database
+// exists per connection, so there reader is the writer.
 type SQLiteStorage struct {
 	db        *sql.DB
-	auditChan chan *model.AuditLog
+	reader    *sql.DB
+	auditChan chan auditJob
+
+	// auditMu guards auditClosed so Close can stop the audit queue without
+	// racing a send, and auditDone is closed once the queue has drained
+	auditMu     sync.RWMutex
+	auditClosed bool
+	auditDone   chan struct{}
 }
 
-// NewSQLiteStorage creates a new SQLite storage instance
-func NewSQLiteStorage(dataDir string) (*SQLiteStorage, error) {
-	var dbPath string
-
-	if dataDir == ":memory:" {
-		dbPath = ":memory:"
-	} else {
-		// Ensure data directory exists
-		if err := os.MkdirAll(dataDir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create data directory: %w", err)
-		}
-		dbPath = filepath.Join(dataDir, "rackd.db")
-	}
-
-	// Open database with SQLite pragma settings
-	db, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)")
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
-
-	// Configure connection pool
-	db.SetMaxOpenConns(1) // SQLite only supports one writer
-	db.SetMaxIdleConns(1)
-	db.SetConnMaxLifetime(time.Hour)
-
-	// Test connection
-	if err := db.Ping(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
+// auditJob is an audit log entry queued for the audit worker, with the ID of
+// the request that produced it
+type auditJob struct {
+	entry     *model.AuditLog
+	requestID string
+}
 
-	s := &SQLiteStorage{
-		db:        db,
-		auditChan: make(chan *model.AuditLog, 1000),
-	}
+// sqlitePragmas are applied to every connection. busy_timeout makes a
+// connection wait for a lock held by another process, such as rackd migrate,
+// rather than fail at once.
+const sqlitePragmas = "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
 
-	// Start audit log worker
-	go s.auditWorker()
+// readerPoolSize is the number of read connections of a file-backed database
+func readerPoolSize() int {
+	return max(4, runtime.GOMAXPROCS(0))
+}
 
-	// Run migrations
-	ctx := context.Background()
-	if err := RunMigrations(ctx, db); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+// NewSQLiteStorage creates a new SQLite storage instance
+func NewSQLiteStorage(dataDir string) (*SQLiteStorage, error) {
+	if dataDir == ":memory:" {
+		return openSQLiteStorage(":memory:")
 	}
 
-	// Create default datacenter if none exists
-	if err := s.ensureDefaultDatacenter(ctx); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to ensure default datacenter: %w", err)
+	// Ensure data directory exists
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
-
-	return s, nil
+	return openSQLiteStorage(filepath.Join(dataDir, "rackd.db"))
 }
 
 // NewSQLiteStorageWithPath creates a new SQLite storage instance with a specific database file path
@@ -83,9 +74,11 @@ func NewSQLiteStorageWithPath(dbPath string) (*SQLiteStorage, error) {
 	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
+	return openSQLiteStorage(dbPath)
+}
 
-	// Open database with SQLite pragma settings
-	db, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)")
+func openSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
+	db, err := sql.Open("sqlite", dbPath+sqlitePragmas)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -101,36 +94,70 @@ func NewSQLiteStorageWithPath(dbPath string) (*SQLiteStorage, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Run migrations before the readers connect, so WAL mode is in place
+	ctx := context.Background()
+	if err := RunMigrations(ctx, db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	reader := db
+	if dbPath != ":memory:" {
+		reader, err = sql.Open("sqlite", dbPath+sqlitePragmas+"&_pragma=query_only(1)")
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to open database readers: %w", err)
+		}
+		reader.SetMaxOpenConns(readerPoolSize())
+		reader.SetMaxIdleConns(readerPoolSize())
+		reader.SetConnMaxLifetime(time.Hour)
+		if err := reader.Ping(); err != nil {
+			reader.Close()
+			db.Close()
+			return nil, fmt.Errorf("failed to connect to database readers: %w", err)
+		}
+	}
+
 	s := &SQLiteStorage{
 		db:        db,
-		auditChan: make(chan *model.AuditLog, 1000),
+		reader:    reader,
+		auditChan: make(chan auditJob, 1000),
+		auditDone: make(chan struct{}),
 	}
 
 	// Start audit log worker
 	go s.auditWorker()
 
-	// Run migrations
-	ctx := context.Background()
-	if err := RunMigrations(ctx, db); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
 	// Create default datacenter if none exists
 	if err := s.ensureDefaultDatacenter(ctx); err != nil {
-		db.Close()
+		s.Close()
 		return nil, fmt.Errorf("failed to ensure default datacenter: %w", err)
 	}
 
 	return s, nil
 }
 
-// Close closes the database connection
+// Close writes any queued audit log entries and closes the database
+// connections
 func (s *SQLiteStorage) Close() error {
+	s.auditMu.Lock()
+	if s.auditChan != nil && !s.auditClosed {
+		s.auditClosed = true
+		close(s.auditChan)
+		s.auditMu.Unlock()
+		<-s.auditDone
+	} else {
+		s.auditMu.Unlock()
+	}
+
+	if s.reader != s.db {
+		s.reader.Close()
+	}
 	return s.db.Close()
 }
 
-// DB returns the underlying database connection for testing
+// DB returns the underlying database connection for testing. It is the
+// writer connection.
 func (s *SQLiteStorage) DB() *sql.DB {
 	return s.db
 }
@@ -174,11 +201,40 @@ func nullIntPtr(i *int) sql.NullInt64 {
 	return sql.NullInt64{Int64: int64(*i), Valid: true}
 }
 
+// maxInsertParams caps the bound parameters of one multi-row INSERT. The
+// driver matches every placeholder against the whole argument list, so binding
+// cost grows with the square of the statement's size; small chunks keep the
+// win of fewer round trips without paying for it in bind.
+const maxInsertParams = 128
+
+// insertRows writes rows with multi-row INSERT statements. insert is the
+// statement up to VALUES, e.g. "INSERT INTO tags (device_id, tag)", and every
+// row holds one value per column.
+func insertRows(ctx context.Context, tx *sql.Tx, insert string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	for chunk := range slices.Chunk(rows, max(1, maxInsertParams/len(rows[0]))) {
+		placeholder := "(?" + strings.Repeat(", ?", len(chunk[0])-1) + ")"
+		args := make([]any, 0, len(chunk)*len(chunk[0]))
+		for _, row := range chunk {
+			args = append(args, row...)
+		}
+		query := insert + " VALUES " + placeholder + strings.Repeat(", "+placeholder, len(chunk)-1)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // auditWorker processes audit logs from the queue
 func (s *SQLiteStorage) auditWorker() {
-	for logEntry := range s.auditChan {
-		if err := s.CreateAuditLog(context.Background(), logEntry); err != nil {
-			log.Error("Failed to create audit log", "error", err)
+	defer close(s.auditDone)
+	for job := range s.auditChan {
+		ctx := log.WithRequestID(context.Background(), job.requestID)
+		if err := s.CreateAuditLog(ctx, job.entry); err != nil {
+			log.Ctx(ctx).Error("Failed to create audit log", "error", err)
 		}
 	}
 }
@@ -221,9 +277,15 @@ func (s *SQLiteStorage) auditLog(ctx context.Context, action, resource, resource
 		return
 	}
 
+	s.auditMu.RLock()
+	defer s.auditMu.RUnlock()
+	if s.auditClosed {
+		log.Ctx(ctx).Error("Storage closed, dropping audit log entry", "action", action)
+		return
+	}
 	select {
-	case s.auditChan <- entry:
+	case s.auditChan <- auditJob{entry: entry, requestID: log.RequestID(ctx)}:
 	default:
-		log.Error("Audit log channel full, dropping log entry", "action", action)
+		log.Ctx(ctx).Error("Audit log channel full, dropping log entry", "action", action)
 	}
 }
@@ -0,0 +1,149 @@
+//go:build !short
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// newBenchStorage opens a file-backed store seeded with devices. With
+// singleConn set, reads share the writer connection as they did before the
+// read pool, for comparison.
+func newBenchStorage(b *testing.B, singleConn bool) (*SQLiteStorage, []string) {
+	b.Helper()
+	s, err := NewSQLiteStorage(b.TempDir())
+	if err != nil {
+		b.Fatalf("storage: %v", err)
+	}
+	b.Cleanup(func() { s.Close() })
+	if singleConn {
+		s.reader.Close()
+		s.reader = s.db
+	}
+
+	ctx := context.Background()
+	ids := make([]string, 0, 200)
+	for i := range 200 {
+		d := &model.Device{
+			Name:      fmt.Sprintf("bench-%03d", i),
+			Tags:      []string{"bench"},
+			Addresses: []model.Address{{IP: fmt.Sprintf("10.0.%d.%d", i/250, i%250+1), Type: "ipv4"}},
+		}
+		if err := s.CreateDevice(ctx, d); err != nil {
+			b.Fatalf("create device: %v", err)
+		}
+		ids = append(ids, d.ID)
+	}
+	return s, ids
+}
+
+// runWriter updates a device in a loop until the benchmark ends, so reads
+// compete with writes the way they do under discovery or bulk imports
+func runWriter(b *testing.B, s *SQLiteStorage, id string) {
+	b.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d, err := s.GetDevice(ctx, id)
+		if err != nil {
+			return
+		}
+		for i := 0; ctx.Err() == nil; i++ {
+			d.Description = fmt.Sprintf("write %d", i)
+			s.UpdateDevice(ctx, d)
+		}
+	}()
+	b.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+}
+
+func benchmarkConcurrentReads(b *testing.B, singleConn bool) {
+	s, ids := newBenchStorage(b, singleConn)
+	runWriter(b, s, ids[0])
+	ctx := context.Background()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := s.GetDevice(ctx, ids[i%len(ids)]); err != nil {
+				b.Errorf("get device: %v", err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkConcurrentReads_ReadPool(b *testing.B) {
+	benchmarkConcurrentReads(b, false)
+}
+
+func BenchmarkConcurrentReads_SingleConnection(b *testing.B) {
+	benchmarkConcurrentReads(b, true)
+}
+
+func benchmarkConcurrentLists(b *testing.B, singleConn bool) {
+	s, ids := newBenchStorage(b, singleConn)
+	runWriter(b, s, ids[0])
+	ctx := context.Background()
+	filter := &model.DeviceFilter{Tags: []string{"bench"}, Pagination: model.Pagination{Limit: 50}}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := s.ListDevices(ctx, filter); err != nil {
+				b.Errorf("list devices: %v", err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkConcurrentLists_ReadPool(b *testing.B) {
+	benchmarkConcurrentLists(b, false)
+}
+
+func BenchmarkConcurrentLists_SingleConnection(b *testing.B) {
+	benchmarkConcurrentLists(b, true)
+}
+
+// BenchmarkBulkCreateDevices imports 100 devices with 20 addresses each, the
+// shape of a rack-by-rack import
+func BenchmarkBulkCreateDevices(b *testing.B) {
+	s, err := NewSQLiteStorage(b.TempDir())
+	if err != nil {
+		b.Fatalf("storage: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for n := range b.N {
+		devices := make([]*model.Device, 100)
+		for i := range devices {
+			addresses := make([]model.Address, 20)
+			for j := range addresses {
+				addresses[j] = model.Address{IP: fmt.Sprintf("10.%d.%d.%d", n%250, i, j+1), Type: "ipv4"}
+			}
+			devices[i] = &model.Device{
+				Name:      fmt.Sprintf("import-%d-%d", n, i),
+				Tags:      []string{"imported", "rack-a"},
+				Domains:   []string{fmt.Sprintf("host-%d-%d.example.com", n, i)},
+				Addresses: addresses,
+			}
+		}
+		if _, err := s.BulkCreateDevices(ctx, devices); err != nil {
+			b.Fatalf("bulk create: %v", err)
+		}
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+
+	"github.com/martinsuchenak/rackd/internal/model"
 )
 
 // GetSSHHostKey retrieves a stored SSH host key for a specific host.
 func (s *SQLiteStorage) GetSSHHostKey(ctx context.Context, host string) ([]byte, error) {
 	var keyBytes []byte
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT key_data
 		FROM ssh_host_keys
 		WHERE host = ?
@@ -37,3 +40,110 @@ func (s *SQLiteStorage) SaveSSHHostKey(ctx context.Context, host string, key []b
 
 	return err
 }
+
+// RecordSSHHostFingerprint saves the fingerprint a host presented for a key
+// type and returns the one recorded before it, or nil for a new key type.
+// When the fingerprint changed, the old one is kept as the previous
+// fingerprint. Discovery records fingerprints on every scan, so they are not
+// audited.
+func (s *SQLiteStorage) RecordSSHHostFingerprint(ctx context.Context, key *model.SSHHostKey) (*model.SSHHostKey, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	previous, err := scanSSHHostFingerprint(tx.QueryRowContext(ctx, `
+		SELECT `+sshHostFingerprintColumns+`
+		FROM ssh_host_fingerprints
+		WHERE ip = ? AND key_type = ?
+	`, key.IP, key.KeyType))
+	if errors.Is(err, sql.ErrNoRows) {
+		previous = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := nowUTC()
+	key.LastSeen = now
+	switch {
+	case previous == nil:
+		key.FirstSeen = now
+		key.PreviousFingerprint = ""
+		key.ChangedAt = nil
+	case previous.Fingerprint == key.Fingerprint:
+		key.FirstSeen = previous.FirstSeen
+		key.PreviousFingerprint = previous.PreviousFingerprint
+		key.ChangedAt = previous.ChangedAt
+	default:
+		key.FirstSeen = now
+		key.PreviousFingerprint = previous.Fingerprint
+		key.ChangedAt = &now
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO ssh_host_fingerprints (`+sshHostFingerprintColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ip, key_type) DO UPDATE SET
+			fingerprint = excluded.fingerprint,
+			previous_fingerprint = excluded.previous_fingerprint,
+			changed_at = excluded.changed_at,
+			first_seen = excluded.first_seen,
+			last_seen = excluded.last_seen
+	`, key.IP, key.KeyType, key.Fingerprint, key.PreviousFingerprint, key.ChangedAt, key.FirstSeen, key.LastSeen)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return previous, nil
+}
+
+// ListSSHHostFingerprints returns the fingerprints collected from the given
+// IPs, ordered by IP and key type
+func (s *SQLiteStorage) ListSSHHostFingerprints(ctx context.Context, ips []string) ([]model.SSHHostKey, error) {
+	keys := []model.SSHHostKey{}
+	if len(ips) == 0 {
+		return keys, nil
+	}
+	args := make([]any, len(ips))
+	for i, ip := range ips {
+		args[i] = ip
+	}
+
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT `+sshHostFingerprintColumns+`
+		FROM ssh_host_fingerprints
+		WHERE ip IN (`+placeholders(len(ips))+`)
+		ORDER BY ip, key_type
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanSSHHostFingerprint(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+const sshHostFingerprintColumns = `ip, key_type, fingerprint, previous_fingerprint, changed_at, first_seen, last_seen`
+
+func scanSSHHostFingerprint(row interface{ Scan(...any) error }) (*model.SSHHostKey, error) {
+	var key model.SSHHostKey
+	var changedAt sql.NullTime
+	if err := row.Scan(&key.IP, &key.KeyType, &key.Fingerprint, &key.PreviousFingerprint, &changedAt,
+		&key.FirstSeen, &key.LastSeen); err != nil {
+		return nil, err
+	}
+	if changedAt.Valid {
+		key.ChangedAt = &changedAt.Time
+	}
+	return &key, nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestRecordSSHHostFingerprint(t *testing.T) {
+	storage, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+	ctx := context.Background()
+
+	previous, err := storage.RecordSSHHostFingerprint(ctx, &model.SSHHostKey{IP: "10.0.0.5", KeyType: "ssh-ed25519", Fingerprint: "SHA256:old"})
+	if err != nil || previous != nil {
+		t.Fatalf("first record = %+v, %v; want nil", previous, err)
+	}
+
+	// The same key again only moves last_seen
+	key := &model.SSHHostKey{IP: "10.0.0.5", KeyType: "ssh-ed25519", Fingerprint: "SHA256:old"}
+	previous, err = storage.RecordSSHHostFingerprint(ctx, key)
+	if err != nil || previous == nil || previous.Fingerprint != "SHA256:old" {
+		t.Fatalf("repeat record = %+v, %v", previous, err)
+	}
+	if key.ChangedAt != nil || key.PreviousFingerprint != "" || !key.FirstSeen.Equal(previous.FirstSeen) {
+		t.Errorf("unchanged key = %+v", key)
+	}
+
+	key = &model.SSHHostKey{IP: "10.0.0.5", KeyType: "ssh-ed25519", Fingerprint: "SHA256:new"}
+	if previous, err = storage.RecordSSHHostFingerprint(ctx, key); err != nil || previous.Fingerprint != "SHA256:old" {
+		t.Fatalf("changed record = %+v, %v", previous, err)
+	}
+	if _, err := storage.RecordSSHHostFingerprint(ctx, &model.SSHHostKey{IP: "10.0.0.6", KeyType: "ssh-rsa", Fingerprint: "SHA256:rsa"}); err != nil {
+		t.Fatalf("record other host: %v", err)
+	}
+
+	keys, err := storage.ListSSHHostFingerprints(ctx, []string{"10.0.0.5"})
+	if err != nil {
+		t.Fatalf("ListSSHHostFingerprints failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Fingerprint != "SHA256:new" || keys[0].PreviousFingerprint != "SHA256:old" || keys[0].ChangedAt == nil {
+		t.Errorf("expected the changed key with its previous fingerprint, got %+v", keys)
+	}
+	if keys, _ := storage.ListSSHHostFingerprints(ctx, nil); len(keys) != 0 {
+		t.Errorf("expected no keys without IPs, got %+v", keys)
+	}
+}
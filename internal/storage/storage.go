@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/martinsuchenak/rackd/internal/model"
 )
@@ -13,15 +14,18 @@ var (
 	ErrDeviceNotFound      = errors.New("device not found")
 	ErrInvalidID           = errors.New("invalid ID")
 	ErrDatacenterNotFound  = errors.New("datacenter not found")
+	ErrContactNotFound     = errors.New("datacenter contact not found")
 	ErrNetworkNotFound     = errors.New("network not found")
 	ErrPoolNotFound        = errors.New("network pool not found")
 	ErrDiscoveryNotFound   = errors.New("discovered device not found")
 	ErrScanNotFound        = errors.New("scan not found")
 	ErrRuleNotFound        = errors.New("discovery rule not found")
 	ErrIPNotAvailable      = errors.New("no IP addresses available")
+	ErrSubnetNotAvailable  = errors.New("no subnet of the requested size available")
 	ErrIPConflict          = errors.New("IP address already in use")
 	ErrAuditLogNotFound    = errors.New("audit log not found")
 	ErrUserNotFound        = errors.New("user not found")
+	ErrOIDCSubjectLinked   = errors.New("user is already linked to an OIDC identity")
 	ErrOAuthClientNotFound = errors.New("oauth client not found")
 	ErrOAuthCodeNotFound   = errors.New("oauth authorization code not found")
 	ErrOAuthCodeExpired    = errors.New("oauth authorization code expired")
@@ -45,6 +49,28 @@ var (
 	ErrRoleNotFound        = errors.New("role not found")
 	ErrPermissionNotFound  = errors.New("permission not found")
 	ErrConflictNotFound    = errors.New("conflict not found")
+
+	ErrAutoPromotionRuleNotFound = errors.New("auto-promotion rule not found")
+	ErrAgentNotFound             = errors.New("agent not found")
+	ErrDuplicateAgentName        = errors.New("agent name already exists")
+	ErrMonitorCheckNotFound      = errors.New("monitor check not found")
+	ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+	ErrScanBlackoutNotFound      = errors.New("scan blackout not found")
+	ErrSearchTooBroad            = errors.New("search matched too many rows")
+	ErrRelationshipCycle         = errors.New("relationship would create a cycle")
+	ErrHasDependents             = errors.New("resource has dependents")
+	ErrTrashItemNotFound         = errors.New("trash item not found")
+	ErrSubnetInUse               = errors.New("subnet is already used by another network")
+	ErrModified                  = errors.New("modified since it was read")
+
+	ErrHypervisorConnectorNotFound = errors.New("hypervisor connector not found")
+	ErrDuplicateConnectorName      = errors.New("hypervisor connector name already exists")
+
+	ErrNotificationChannelNotFound = errors.New("notification channel not found")
+	ErrReportScheduleNotFound      = errors.New("report schedule not found")
+
+	ErrChangeRequestNotFound = errors.New("change request not found")
+	ErrChangeNotPending      = errors.New("change request is not pending")
 )
 
 // DeviceStorage defines device persistence operations
@@ -52,10 +78,13 @@ type DeviceStorage interface {
 	GetDevice(ctx context.Context, id string) (*model.Device, error)
 	CreateDevice(ctx context.Context, device *model.Device) error
 	UpdateDevice(ctx context.Context, device *model.Device) error
-	DeleteDevice(ctx context.Context, id string) error
+	DeleteDevice(ctx context.Context, id string, policy model.DeletePolicy) error
 	ListDevices(ctx context.Context, filter *model.DeviceFilter) ([]model.Device, error)
 	SearchDevices(ctx context.Context, query string) ([]model.Device, error)
 	GetDeviceStatusCounts(ctx context.Context) (map[model.DeviceStatus]int, error)
+	MergeDevices(ctx context.Context, targetID, sourceID, mergedBy string) (*model.Device, error)
+	FindDuplicateMACs(ctx context.Context, deviceID string, macs []string) ([]model.MACDuplicate, error)
+	FindDevicesByName(ctx context.Context, name string) ([]model.Device, error)
 }
 
 // DatacenterStorage defines datacenter persistence operations
@@ -64,9 +93,16 @@ type DatacenterStorage interface {
 	GetDatacenter(ctx context.Context, id string) (*model.Datacenter, error)
 	CreateDatacenter(ctx context.Context, dc *model.Datacenter) error
 	UpdateDatacenter(ctx context.Context, dc *model.Datacenter) error
-	DeleteDatacenter(ctx context.Context, id string) error
+	DeleteDatacenter(ctx context.Context, id string, policy model.DeletePolicy) error
 	GetDatacenterDevices(ctx context.Context, datacenterID string) ([]model.Device, error)
 	SearchDatacenters(ctx context.Context, query string) ([]model.Datacenter, error)
+
+	// Contacts
+	ListDatacenterContacts(ctx context.Context, datacenterID string) ([]model.DatacenterContact, error)
+	GetDatacenterContact(ctx context.Context, id string) (*model.DatacenterContact, error)
+	CreateDatacenterContact(ctx context.Context, contact *model.DatacenterContact) error
+	UpdateDatacenterContact(ctx context.Context, contact *model.DatacenterContact) error
+	DeleteDatacenterContact(ctx context.Context, id string) error
 }
 
 // NetworkStorage defines network persistence operations
@@ -75,22 +111,25 @@ type NetworkStorage interface {
 	GetNetwork(ctx context.Context, id string) (*model.Network, error)
 	CreateNetwork(ctx context.Context, network *model.Network) error
 	UpdateNetwork(ctx context.Context, network *model.Network) error
-	DeleteNetwork(ctx context.Context, id string) error
+	DeleteNetwork(ctx context.Context, id string, policy model.DeletePolicy) error
 	GetNetworkDevices(ctx context.Context, networkID string) ([]model.Device, error)
 	GetNetworkUtilization(ctx context.Context, networkID string) (*model.NetworkUtilization, error)
 	SearchNetworks(ctx context.Context, query string) ([]model.Network, error)
+	AllocateSubnet(ctx context.Context, parentID string, prefixLength int, network *model.Network) error
 }
 
 // NetworkPoolStorage defines network pool persistence operations
 type NetworkPoolStorage interface {
 	CreateNetworkPool(ctx context.Context, pool *model.NetworkPool) error
 	UpdateNetworkPool(ctx context.Context, pool *model.NetworkPool) error
-	DeleteNetworkPool(ctx context.Context, id string) error
+	DeleteNetworkPool(ctx context.Context, id string, policy model.DeletePolicy) error
 	GetNetworkPool(ctx context.Context, id string) (*model.NetworkPool, error)
 	ListNetworkPools(ctx context.Context, filter *model.NetworkPoolFilter) ([]model.NetworkPool, error)
 	GetNextAvailableIP(ctx context.Context, poolID string) (string, error)
 	ValidateIPInPool(ctx context.Context, poolID, ip string) (bool, error)
 	GetPoolHeatmap(ctx context.Context, poolID string) ([]IPStatus, error)
+	GetPoolStats(ctx context.Context, poolID string) (*model.PoolStats, error)
+	GetPoolStatsSummary(ctx context.Context, filter *model.PoolStatsFilter) (*model.PoolStatsSummary, error)
 }
 
 // IPStatus represents the status of an IP in a pool heatmap
@@ -108,6 +147,7 @@ type RelationshipStorage interface {
 	ListAllRelationships(ctx context.Context) ([]model.DeviceRelationship, error)
 	GetRelatedDevices(ctx context.Context, deviceID, relationshipType string) ([]model.Device, error)
 	UpdateRelationshipNotes(ctx context.Context, parentID, childID, relationshipType, notes string) error
+	GetDeviceImpact(ctx context.Context, deviceID string, maxDepth int) (*model.DeviceImpact, error)
 }
 
 // DiscoveryStorage defines discovery persistence operations
@@ -121,6 +161,8 @@ type DiscoveryStorage interface {
 	DeleteDiscoveredDevice(ctx context.Context, id string) error
 	DeleteDiscoveredDevicesByNetwork(ctx context.Context, networkID string) error
 	PromoteDiscoveredDevice(ctx context.Context, discoveredID, deviceID string) error
+	IgnoreDiscoveredDevice(ctx context.Context, id, reason string, until *time.Time) error
+	UnignoreDiscoveredDevice(ctx context.Context, id string) error
 
 	// Discovery scans
 	CreateDiscoveryScan(ctx context.Context, scan *model.DiscoveryScan) error
@@ -136,6 +178,17 @@ type DiscoveryStorage interface {
 	ListDiscoveryRules(ctx context.Context) ([]model.DiscoveryRule, error)
 	DeleteDiscoveryRule(ctx context.Context, id string) error
 
+	// Auto-promotion rules
+	CreateAutoPromotionRule(ctx context.Context, rule *model.AutoPromotionRule) error
+	UpdateAutoPromotionRule(ctx context.Context, rule *model.AutoPromotionRule) error
+	GetAutoPromotionRule(ctx context.Context, id string) (*model.AutoPromotionRule, error)
+	ListAutoPromotionRules(ctx context.Context, networkID string) ([]model.AutoPromotionRule, error)
+	DeleteAutoPromotionRule(ctx context.Context, id string) error
+
+	// Port observations
+	RecordPortObservations(ctx context.Context, scanID string, device *model.DiscoveredDevice, probed []int) error
+	ListPortObservations(ctx context.Context, filter *model.PortObservationFilter) ([]model.PortObservation, error)
+
 	// Cleanup
 	CleanupOldDiscoveries(ctx context.Context, olderThanDays int) error
 }
@@ -149,6 +202,7 @@ type BulkOperations interface {
 	BulkRemoveTags(ctx context.Context, deviceIDs []string, tags []string) (*BulkResult, error)
 	BulkCreateNetworks(ctx context.Context, networks []*model.Network) (*BulkResult, error)
 	BulkDeleteNetworks(ctx context.Context, ids []string) (*BulkResult, error)
+	ApplyBulkDeviceOperations(ctx context.Context, req *model.BulkDeviceRequest, changedBy string) (*BulkResult, error)
 }
 
 // AuditStorage defines audit log persistence operations
@@ -204,6 +258,7 @@ type ConflictStorage interface {
 // ReservationStorage defines reservation persistence operations
 type ReservationStorage interface {
 	CreateReservation(ctx context.Context, reservation *model.Reservation) error
+	AllocateIP(ctx context.Context, reservation *model.Reservation) error
 	GetReservation(ctx context.Context, id string) (*model.Reservation, error)
 	GetReservationByIP(ctx context.Context, poolID, ip string) (*model.Reservation, error)
 	ListReservations(ctx context.Context, filter *model.ReservationFilter) ([]model.Reservation, error)
@@ -223,9 +278,14 @@ type SnapshotStorage interface {
 	GetLatestSnapshots(ctx context.Context, snapshotType model.SnapshotType) ([]model.UtilizationSnapshot, error)
 	DeleteOldSnapshots(ctx context.Context, olderThanDays int) error
 
+	// Daily inventory metrics
+	RecordInventoryMetrics(ctx context.Context, metrics []model.InventoryMetric) error
+	ListInventoryMetrics(ctx context.Context, filter *model.InventoryMetricFilter) ([]model.InventoryMetric, error)
+
 	// Dashboard operations
 	GetDashboardStats(ctx context.Context, staleDays int, recentLimit int) (*model.DashboardStats, error)
 	GetUtilizationTrend(ctx context.Context, resourceType model.SnapshotType, resourceID string, days int) ([]model.UtilizationTrendPoint, error)
+	GetDatacenterStats(ctx context.Context, datacenterID string, recentLimit int) (*model.DatacenterStats, error)
 }
 
 // WebhookStorage defines webhook persistence operations
@@ -322,10 +382,147 @@ type DNSStorage interface {
 	GetDNSRecordByName(ctx context.Context, zoneID, name string, recordType string) (*model.DNSRecord, error)
 }
 
-// SSHHostKeyStorage defines SSH host key persistence operations
+// SSHHostKeyStorage defines SSH host key persistence operations: the keys
+// trusted by credentialed scans, and the fingerprints discovery collects
 type SSHHostKeyStorage interface {
 	GetSSHHostKey(ctx context.Context, host string) ([]byte, error)
 	SaveSSHHostKey(ctx context.Context, host string, key []byte) error
+	RecordSSHHostFingerprint(ctx context.Context, key *model.SSHHostKey) (*model.SSHHostKey, error)
+	ListSSHHostFingerprints(ctx context.Context, ips []string) ([]model.SSHHostKey, error)
+}
+
+// AgentStorage defines remote scanning agent persistence operations
+type AgentStorage interface {
+	CreateAgent(ctx context.Context, agent *model.Agent, tokenHash string) error
+	GetAgent(ctx context.Context, id string) (*model.Agent, error)
+	GetAgentByTokenHash(ctx context.Context, tokenHash string) (*model.Agent, error)
+	ListAgents(ctx context.Context) ([]model.Agent, error)
+	UpdateAgent(ctx context.Context, agent *model.Agent) error
+	UpdateAgentToken(ctx context.Context, id, tokenHash string) error
+	UpdateAgentHeartbeat(ctx context.Context, id string, hb *model.AgentHeartbeat) error
+	DeleteAgent(ctx context.Context, id string) error
+}
+
+// MonitorStorage defines availability check persistence operations
+type MonitorStorage interface {
+	CreateMonitorCheck(ctx context.Context, check *model.MonitorCheck) error
+	GetMonitorCheck(ctx context.Context, id string) (*model.MonitorCheck, error)
+	ListMonitorChecks(ctx context.Context) ([]model.MonitorCheck, error)
+	UpdateMonitorCheck(ctx context.Context, check *model.MonitorCheck) error
+	DeleteMonitorCheck(ctx context.Context, id string) error
+
+	// Results hold the latest outcome of each check per device
+	SaveMonitorResult(ctx context.Context, result *model.MonitorResult) error
+	ListMonitorResults(ctx context.Context, deviceIDs []string) ([]model.MonitorResult, error)
+	PruneMonitorResults(ctx context.Context, checkID string, keepDeviceIDs []string) error
+}
+
+// HypervisorConnectorStorage defines hypervisor connector persistence
+// operations
+type HypervisorConnectorStorage interface {
+	CreateHypervisorConnector(ctx context.Context, connector *model.HypervisorConnector) error
+	GetHypervisorConnector(ctx context.Context, id string) (*model.HypervisorConnector, error)
+	ListHypervisorConnectors(ctx context.Context, filter *model.HypervisorConnectorFilter) ([]model.HypervisorConnector, error)
+	UpdateHypervisorConnector(ctx context.Context, connector *model.HypervisorConnector) error
+	DeleteHypervisorConnector(ctx context.Context, id string) error
+	// RecordHypervisorSync stores the outcome of a sync; syncErr is empty on success
+	RecordHypervisorSync(ctx context.Context, id string, syncedAt time.Time, syncErr string) error
+}
+
+// NotificationStorage defines notification channel persistence operations
+type NotificationStorage interface {
+	CreateNotificationChannel(ctx context.Context, channel *model.NotificationChannel) error
+	GetNotificationChannel(ctx context.Context, id string) (*model.NotificationChannel, error)
+	ListNotificationChannels(ctx context.Context, filter *model.NotificationChannelFilter) ([]model.NotificationChannel, error)
+	GetNotificationChannelsForEvent(ctx context.Context, eventType model.EventType) ([]model.NotificationChannel, error)
+	UpdateNotificationChannel(ctx context.Context, channel *model.NotificationChannel) error
+	DeleteNotificationChannel(ctx context.Context, id string) error
+}
+
+// ReportScheduleStorage defines report schedule persistence operations
+type ReportScheduleStorage interface {
+	CreateReportSchedule(ctx context.Context, schedule *model.ReportSchedule) error
+	GetReportSchedule(ctx context.Context, id string) (*model.ReportSchedule, error)
+	ListReportSchedules(ctx context.Context, filter *model.ReportScheduleFilter) ([]model.ReportSchedule, error)
+	ListDueReportSchedules(ctx context.Context, now time.Time) ([]model.ReportSchedule, error)
+	UpdateReportSchedule(ctx context.Context, schedule *model.ReportSchedule) error
+	// RecordReportScheduleRun stores the outcome of a run; runErr is empty on success
+	RecordReportScheduleRun(ctx context.Context, id string, ranAt time.Time, runErr string, nextRunAt *time.Time) error
+	DeleteReportSchedule(ctx context.Context, id string) error
+}
+
+// MaintenanceWindowStorage defines maintenance window persistence operations
+type MaintenanceWindowStorage interface {
+	CreateMaintenanceWindow(ctx context.Context, window *model.MaintenanceWindow) error
+	GetMaintenanceWindow(ctx context.Context, id string) (*model.MaintenanceWindow, error)
+	ListMaintenanceWindows(ctx context.Context, filter *model.MaintenanceWindowFilter) ([]model.MaintenanceWindow, error)
+	UpdateMaintenanceWindow(ctx context.Context, window *model.MaintenanceWindow) error
+	DeleteMaintenanceWindow(ctx context.Context, id string) error
+}
+
+// ScanBlackoutStorage defines scan blackout window persistence operations
+type ScanBlackoutStorage interface {
+	CreateScanBlackout(ctx context.Context, blackout *model.ScanBlackout) error
+	UpdateScanBlackout(ctx context.Context, blackout *model.ScanBlackout) error
+	GetScanBlackout(ctx context.Context, id string) (*model.ScanBlackout, error)
+	ListScanBlackouts(ctx context.Context, networkID string) ([]model.ScanBlackout, error)
+	DeleteScanBlackout(ctx context.Context, id string) error
+}
+
+// ChangeRequestStorage defines persistence for changes held in approval mode
+type ChangeRequestStorage interface {
+	CreateChangeRequest(ctx context.Context, change *model.ChangeRequest) error
+	GetChangeRequest(ctx context.Context, id string) (*model.ChangeRequest, error)
+	ListChangeRequests(ctx context.Context, filter *model.ChangeRequestFilter) ([]model.ChangeRequest, error)
+	// ReviewChangeRequest moves a pending change to the status in the
+	// change, failing with ErrChangeNotPending if it was already reviewed.
+	ReviewChangeRequest(ctx context.Context, change *model.ChangeRequest) error
+	SetChangeRequestResult(ctx context.Context, id string, status model.ChangeStatus, resultStatus int, result []byte) error
+}
+
+// IdempotencyStorage stores responses to requests sent with an
+// Idempotency-Key header. Keys are scoped to the user who sent them.
+type IdempotencyStorage interface {
+	// ReserveIdempotencyKey claims a key for a new request. It returns nil
+	// when the key was claimed, or the live record already holding it.
+	ReserveIdempotencyKey(ctx context.Context, rec *model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, rec *model.IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, userID, key string) error
+}
+
+// CollectionChangeStorage reports when the device, network and datacenter
+// listings last changed, for answering If-Modified-Since. Times have second
+// precision.
+type CollectionChangeStorage interface {
+	DevicesChangedAt(ctx context.Context) (time.Time, error)
+	NetworksChangedAt(ctx context.Context) (time.Time, error)
+	DatacentersChangedAt(ctx context.Context) (time.Time, error)
+}
+
+// HistoryStorage reads the recorded versions of networks and pools.
+// Versions are written by the network and pool storage operations.
+type HistoryStorage interface {
+	ListResourceVersions(ctx context.Context, resource, resourceID string) ([]model.ResourceVersion, error)
+	ListNetworksAsOf(ctx context.Context, asOf time.Time, filter *model.NetworkFilter) ([]model.Network, error)
+	GetNetworkAsOf(ctx context.Context, id string, asOf time.Time) (*model.Network, error)
+	ListNetworkPoolsAsOf(ctx context.Context, asOf time.Time, filter *model.NetworkPoolFilter) ([]model.NetworkPool, error)
+	GetNetworkPoolAsOf(ctx context.Context, id string, asOf time.Time) (*model.NetworkPool, error)
+}
+
+// TrashStorage defines soft delete operations. Trashed devices, networks and
+// datacenters are hidden from reads until they are restored or purged.
+type TrashStorage interface {
+	TrashResource(ctx context.Context, resourceType, id string, policy model.DeletePolicy) error
+	ListTrash(ctx context.Context, filter *model.TrashFilter) ([]model.TrashItem, error)
+	GetTrashItem(ctx context.Context, id string) (*model.TrashItem, error)
+	RestoreTrashItem(ctx context.Context, id string) error
+	PurgeTrashItem(ctx context.Context, id string) error
+}
+
+// MaintenanceStorage defines database health checks and upkeep
+type MaintenanceStorage interface {
+	CheckDatabase(ctx context.Context) (*model.DBCheckReport, error)
+	OptimizeDatabase(ctx context.Context) (*model.DBOptimizeResult, error)
 }
 
 // Storage is the base interface
@@ -351,21 +548,35 @@ type ExtendedStorage interface {
 	ReservationStorage
 	SnapshotStorage
 	WebhookStorage
+	NotificationStorage
+	ReportScheduleStorage
 	CustomFieldStorage
 	CircuitStorage
 	NATStorage
 	DNSStorage
 	SSHHostKeyStorage
+	AgentStorage
+	MonitorStorage
+	MaintenanceWindowStorage
+	ScanBlackoutStorage
+	ChangeRequestStorage
+	IdempotencyStorage
+	CollectionChangeStorage
+	HypervisorConnectorStorage
+	HistoryStorage
+	TrashStorage
+	MaintenanceStorage
 	Close() error
 	DB() *sql.DB
 }
 
-// NewStorage creates a new base storage instance
+// NewStorage creates a new base storage instance with the default driver
 func NewStorage(dataDir string) (Storage, error) {
-	return NewSQLiteStorage(dataDir)
+	return Open(DefaultDriver, dataDir)
 }
 
-// NewExtendedStorage creates a new extended storage instance
+// NewExtendedStorage creates a new extended storage instance with the default
+// driver
 func NewExtendedStorage(dataDir string) (ExtendedStorage, error) {
-	return NewSQLiteStorage(dataDir)
+	return Open(DefaultDriver, dataDir)
 }
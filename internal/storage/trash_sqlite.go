@@ -0,0 +1,312 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/audit"
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+// trashTypes lists the resource types that can be trashed, in listing order
+var trashTypes = []string{model.TrashTypeDevice, model.TrashTypeNetwork, model.TrashTypeDatacenter}
+
+// trashSpec describes how one resource type is trashed and purged
+type trashSpec struct {
+	table      string
+	notFound   error
+	dependents []dependent
+	purge      func(ctx context.Context, tx *sql.Tx, id string, policy model.DeletePolicy) error
+}
+
+func (s *SQLiteStorage) trashSpecs() map[string]trashSpec {
+	return map[string]trashSpec{
+		model.TrashTypeDevice: {
+			table:      "devices",
+			notFound:   ErrDeviceNotFound,
+			dependents: deviceDependents,
+			purge:      s.deleteDeviceInTx,
+		},
+		model.TrashTypeNetwork: {
+			table:      "networks",
+			notFound:   ErrNetworkNotFound,
+			dependents: s.networkDependents(),
+			purge:      s.deleteNetworkInTx,
+		},
+		model.TrashTypeDatacenter: {
+			table:      "datacenters",
+			notFound:   ErrDatacenterNotFound,
+			dependents: s.datacenterDependents(),
+			purge:      s.deleteDatacenterInTx,
+		},
+	}
+}
+
+// TrashResource moves a device, network or datacenter to the trash. The
+// policy is kept for when the item is purged; only deny is checked now, so
+// the dependents stay untouched and a restore loses nothing.
+func (s *SQLiteStorage) TrashResource(ctx context.Context, resourceType, id string, policy model.DeletePolicy) error {
+	if id == "" {
+		return ErrInvalidID
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.trashInTx(ctx, tx, resourceType, id, policy); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditLog(ctx, "delete", resourceType, id, map[string]string{"delete_policy": string(policy)})
+	return nil
+}
+
+// trashInTx moves a resource to the trash within an existing transaction
+func (s *SQLiteStorage) trashInTx(ctx context.Context, tx *sql.Tx, resourceType, id string, policy model.DeletePolicy) error {
+	spec, ok := s.trashSpecs()[resourceType]
+	if !ok {
+		return fmt.Errorf("unknown trash type %q", resourceType)
+	}
+	if policy == "" {
+		policy = model.DeleteDetach
+	}
+
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM `+spec.table+` WHERE id = ? AND deleted_at IS NULL)`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check %s existence: %w", resourceType, err)
+	}
+	if !exists {
+		return spec.notFound
+	}
+
+	if policy == model.DeleteDeny {
+		if err := applyDeletePolicy(ctx, tx, resourceType, id, policy, spec.dependents); err != nil {
+			return err
+		}
+	}
+
+	var deletedBy string
+	if auditCtx, ok := audit.FromContext(ctx); ok {
+		deletedBy = auditCtx.Username
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE `+spec.table+` SET deleted_at = ?, deleted_by = ?, delete_policy = ? WHERE id = ?`,
+		nowUTC(), deletedBy, string(policy), id)
+	if err != nil {
+		return fmt.Errorf("failed to trash %s: %w", resourceType, err)
+	}
+
+	if resourceType == model.TrashTypeNetwork {
+		// Its children move up to the next enclosing live network
+		if err := relinkTrashedNetwork(ctx, tx, id); err != nil {
+			return err
+		}
+		return recordVersion(ctx, tx, "network", id, model.VersionActionDelete, nil)
+	}
+	return nil
+}
+
+// relinkTrashedNetwork relinks the datacenter of network id after it went to
+// or came back from the trash
+func relinkTrashedNetwork(ctx context.Context, tx *sql.Tx, id string) error {
+	var datacenterID sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT datacenter_id FROM networks WHERE id = ?`, id).Scan(&datacenterID); err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+	return relinkNetworks(ctx, tx, datacenterID.String)
+}
+
+// ListTrash returns the trashed items matching the filter, most recently
+// deleted first
+func (s *SQLiteStorage) ListTrash(ctx context.Context, filter *model.TrashFilter) ([]model.TrashItem, error) {
+	var selects []string
+	var args []any
+	specs := s.trashSpecs()
+	for _, t := range trashTypes {
+		if filter != nil && len(filter.Types) > 0 && !slices.Contains(filter.Types, t) {
+			continue
+		}
+		query := `SELECT id, '` + t + `' AS type, name, deleted_at, deleted_by, delete_policy FROM ` + specs[t].table +
+			` WHERE deleted_at IS NOT NULL`
+		if filter != nil && !filter.DeletedBefore.IsZero() {
+			query += ` AND deleted_at < ?`
+			args = append(args, filter.DeletedBefore)
+		}
+		selects = append(selects, query)
+	}
+	if len(selects) == 0 {
+		return []model.TrashItem{}, nil
+	}
+
+	query := strings.Join(selects, " UNION ALL ") + " ORDER BY deleted_at DESC, id"
+	var pg *model.Pagination
+	if filter != nil {
+		pg = &filter.Pagination
+	}
+	query, args = appendPagination(query, args, pg)
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	defer rows.Close()
+
+	items := []model.TrashItem{}
+	for rows.Next() {
+		var item model.TrashItem
+		if err := rows.Scan(&item.ID, &item.Type, &item.Name, &item.DeletedAt, &item.DeletedBy, &item.DeletePolicy); err != nil {
+			return nil, fmt.Errorf("failed to scan trash item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetTrashItem finds a trashed item by the ID of the device, network or
+// datacenter
+func (s *SQLiteStorage) GetTrashItem(ctx context.Context, id string) (*model.TrashItem, error) {
+	if id == "" {
+		return nil, ErrInvalidID
+	}
+	return getTrashItem(ctx, s.reader, s.trashSpecs(), id)
+}
+
+func getTrashItem(ctx context.Context, q rowGetter, specs map[string]trashSpec, id string) (*model.TrashItem, error) {
+	for _, t := range trashTypes {
+		item := &model.TrashItem{ID: id, Type: t}
+		err := q.QueryRowContext(ctx, `
+			SELECT name, deleted_at, deleted_by, delete_policy FROM `+specs[t].table+`
+			WHERE id = ? AND deleted_at IS NOT NULL
+		`, id).Scan(&item.Name, &item.DeletedAt, &item.DeletedBy, &item.DeletePolicy)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trash item: %w", err)
+		}
+		return item, nil
+	}
+	return nil, ErrTrashItemNotFound
+}
+
+// RestoreTrashItem takes an item out of the trash. A network is refused with
+// ErrSubnetInUse when a network with the same subnet was created in its
+// datacenter in the meantime.
+func (s *SQLiteStorage) RestoreTrashItem(ctx context.Context, id string) error {
+	if id == "" {
+		return ErrInvalidID
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	specs := s.trashSpecs()
+	item, err := getTrashItem(ctx, tx, specs, id)
+	if err != nil {
+		return err
+	}
+
+	if item.Type == model.TrashTypeNetwork {
+		if err := checkRestoredSubnet(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE `+specs[item.Type].table+` SET deleted_at = NULL, deleted_by = '', delete_policy = '' WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", item.Type, err)
+	}
+
+	if item.Type == model.TrashTypeNetwork {
+		if err := relinkTrashedNetwork(ctx, tx, id); err != nil {
+			return err
+		}
+		network, err := getNetwork(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := recordVersion(ctx, tx, "network", id, model.VersionActionCreate, network); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditLog(ctx, "restore", item.Type, id, nil)
+	return nil
+}
+
+// checkRestoredSubnet returns ErrSubnetInUse when a live network in the same
+// datacenter has the subnet of the trashed network id
+func checkRestoredSubnet(ctx context.Context, tx *sql.Tx, id string) error {
+	var subnet string
+	var datacenterID sql.NullString
+	err := tx.QueryRowContext(ctx, `SELECT subnet, datacenter_id FROM networks WHERE id = ?`, id).Scan(&subnet, &datacenterID)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return nil
+	}
+
+	others, err := idsInTx(ctx, tx, `
+		SELECT subnet FROM networks WHERE datacenter_id IS ? AND id != ? AND deleted_at IS NULL
+	`, datacenterID, id)
+	if err != nil {
+		return fmt.Errorf("failed to list networks: %w", err)
+	}
+	for _, other := range others {
+		if otherPrefix, err := netip.ParsePrefix(other); err == nil && otherPrefix.Masked() == prefix.Masked() {
+			return ErrSubnetInUse
+		}
+	}
+	return nil
+}
+
+// PurgeTrashItem permanently deletes a trashed item, applying the delete
+// policy it was trashed with to its dependents
+func (s *SQLiteStorage) PurgeTrashItem(ctx context.Context, id string) error {
+	if id == "" {
+		return ErrInvalidID
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	specs := s.trashSpecs()
+	item, err := getTrashItem(ctx, tx, specs, id)
+	if err != nil {
+		return err
+	}
+
+	if err := specs[item.Type].purge(ctx, tx, id, item.DeletePolicy); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.auditLog(ctx, "purge", item.Type, id, item)
+	return nil
+}
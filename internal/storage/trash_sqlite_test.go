@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestTrashAndRestoreDevice(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	device, discovered := deviceWithDependents(t, store)
+
+	if err := store.TrashResource(ctx, model.TrashTypeDevice, device.ID, model.DeleteCascade); err != nil {
+		t.Fatalf("TrashResource failed: %v", err)
+	}
+	if _, err := store.GetDevice(ctx, device.ID); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("expected the trashed device to be hidden, got %v", err)
+	}
+	devices, _ := store.ListDevices(ctx, nil)
+	for _, d := range devices {
+		if d.ID == device.ID {
+			t.Error("expected the trashed device to be left out of the list")
+		}
+	}
+	if err := store.TrashResource(ctx, model.TrashTypeDevice, device.ID, ""); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("expected trashing twice to fail, got %v", err)
+	}
+
+	items, err := store.ListTrash(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != device.ID || items[0].Type != model.TrashTypeDevice ||
+		items[0].Name != "web-01" || items[0].DeletePolicy != model.DeleteCascade {
+		t.Fatalf("unexpected trash: %+v", items)
+	}
+
+	if err := store.RestoreTrashItem(ctx, device.ID); err != nil {
+		t.Fatalf("RestoreTrashItem failed: %v", err)
+	}
+	got, err := store.GetDevice(ctx, device.ID)
+	if err != nil {
+		t.Fatalf("expected the device to be back, got %v", err)
+	}
+	if got.Name != "web-01" {
+		t.Errorf("unexpected device: %+v", got)
+	}
+	rels, _ := store.GetRelationships(ctx, device.ID)
+	if len(rels) != 1 {
+		t.Errorf("expected the relationship to survive the trash, got %+v", rels)
+	}
+	gotDiscovered, err := store.GetDiscoveredDevice(ctx, discovered.ID)
+	if err != nil || gotDiscovered.PromotedToDeviceID != device.ID {
+		t.Errorf("expected the promotion link to survive the trash, got %+v, %v", gotDiscovered, err)
+	}
+	if err := store.RestoreTrashItem(ctx, device.ID); !errors.Is(err, ErrTrashItemNotFound) {
+		t.Errorf("expected ErrTrashItemNotFound, got %v", err)
+	}
+}
+
+func TestTrashDenyChecksDependents(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	device, _ := deviceWithDependents(t, store)
+
+	err := store.TrashResource(ctx, model.TrashTypeDevice, device.ID, model.DeleteDeny)
+	if !errors.Is(err, ErrHasDependents) {
+		t.Fatalf("expected ErrHasDependents, got %v", err)
+	}
+	if _, err := store.GetDevice(ctx, device.ID); err != nil {
+		t.Errorf("expected the device to stay live, got %v", err)
+	}
+}
+
+func TestPurgeTrashItemAppliesPolicy(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	device, discovered := deviceWithDependents(t, store)
+
+	if err := store.TrashResource(ctx, model.TrashTypeDevice, device.ID, model.DeleteCascade); err != nil {
+		t.Fatalf("TrashResource failed: %v", err)
+	}
+	if err := store.PurgeTrashItem(ctx, device.ID); err != nil {
+		t.Fatalf("PurgeTrashItem failed: %v", err)
+	}
+	if _, err := store.GetDiscoveredDevice(ctx, discovered.ID); !errors.Is(err, ErrDiscoveryNotFound) {
+		t.Errorf("expected the cascade policy to delete the discovery record, got %v", err)
+	}
+	if err := store.RestoreTrashItem(ctx, device.ID); !errors.Is(err, ErrTrashItemNotFound) {
+		t.Errorf("expected the purged device to be gone, got %v", err)
+	}
+}
+
+func TestTrashNetwork(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	network := &model.Network{Name: "lan", Subnet: "10.0.0.0/24"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	if err := store.TrashResource(ctx, model.TrashTypeNetwork, network.ID, ""); err != nil {
+		t.Fatalf("TrashResource failed: %v", err)
+	}
+	if _, err := store.GetNetwork(ctx, network.ID); !errors.Is(err, ErrNetworkNotFound) {
+		t.Errorf("expected the trashed network to be hidden, got %v", err)
+	}
+
+	// A new network took the subnet, so the old one cannot come back
+	replacement := &model.Network{Name: "lan-2", Subnet: "10.0.0.0/24"}
+	if err := store.CreateNetwork(ctx, replacement); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	if err := store.RestoreTrashItem(ctx, network.ID); !errors.Is(err, ErrSubnetInUse) {
+		t.Fatalf("expected ErrSubnetInUse, got %v", err)
+	}
+
+	if err := store.DeleteNetwork(ctx, replacement.ID, model.DeleteDetach); err != nil {
+		t.Fatalf("DeleteNetwork failed: %v", err)
+	}
+	if err := store.RestoreTrashItem(ctx, network.ID); err != nil {
+		t.Fatalf("RestoreTrashItem failed: %v", err)
+	}
+
+	versions, err := store.ListResourceVersions(ctx, "network", network.ID)
+	if err != nil {
+		t.Fatalf("ListResourceVersions failed: %v", err)
+	}
+	var actions []string
+	for _, v := range versions {
+		actions = append(actions, v.Action)
+	}
+	want := []string{model.VersionActionCreate, model.VersionActionDelete, model.VersionActionCreate}
+	if len(actions) != len(want) {
+		t.Fatalf("expected versions %v, got %v", want, actions)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Fatalf("expected versions %v, got %v", want, actions)
+		}
+	}
+}
+
+func TestListTrashFilter(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "DC1"}
+	if err := store.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("CreateDatacenter failed: %v", err)
+	}
+	device := &model.Device{Name: "web-01"}
+	if err := store.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+	for typ, id := range map[string]string{model.TrashTypeDatacenter: dc.ID, model.TrashTypeDevice: device.ID} {
+		if err := store.TrashResource(ctx, typ, id, ""); err != nil {
+			t.Fatalf("TrashResource failed: %v", err)
+		}
+	}
+
+	items, err := store.ListTrash(ctx, &model.TrashFilter{Types: []string{model.TrashTypeDatacenter}})
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != dc.ID {
+		t.Errorf("expected only the datacenter, got %+v", items)
+	}
+
+	items, err = store.ListTrash(ctx, &model.TrashFilter{DeletedBefore: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("expected nothing deleted over an hour ago, got %+v", items)
+	}
+}
+
+func TestDeleteDatacenterIgnoresTrash(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	dc := &model.Datacenter{Name: "fra1"}
+	if err := store.CreateDatacenter(ctx, dc); err != nil {
+		t.Fatalf("CreateDatacenter failed: %v", err)
+	}
+	live := &model.Device{Name: "live", DatacenterID: dc.ID}
+	trashed := &model.Device{Name: "trashed", DatacenterID: dc.ID}
+	for _, d := range []*model.Device{live, trashed} {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+	}
+	network := &model.Network{Name: "lan", Subnet: "10.0.0.0/24", DatacenterID: dc.ID}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	if err := store.TrashResource(ctx, model.TrashTypeDevice, trashed.ID, ""); err != nil {
+		t.Fatalf("TrashResource failed: %v", err)
+	}
+	if err := store.TrashResource(ctx, model.TrashTypeNetwork, network.ID, ""); err != nil {
+		t.Fatalf("TrashResource failed: %v", err)
+	}
+
+	// Only the live device blocks a deny delete
+	var depErr *DependentsError
+	err := store.DeleteDatacenter(ctx, dc.ID, model.DeleteDeny)
+	if !errors.As(err, &depErr) || len(depErr.Dependents) != 1 || depErr.Dependents["devices"] != 1 {
+		t.Fatalf("expected 1 live device as the only dependent, got %v", err)
+	}
+
+	// Cascade deletes the live device and leaves the trash alone
+	if err := store.DeleteDatacenter(ctx, dc.ID, model.DeleteCascade); err != nil {
+		t.Fatalf("DeleteDatacenter failed: %v", err)
+	}
+	if _, err := store.GetDevice(ctx, live.ID); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("expected the live device to be deleted, got %v", err)
+	}
+	for _, id := range []string{trashed.ID, network.ID} {
+		if _, err := store.GetTrashItem(ctx, id); err != nil {
+			t.Errorf("expected %s to stay in the trash, got %v", id, err)
+		}
+	}
+
+	if err := store.RestoreTrashItem(ctx, trashed.ID); err != nil {
+		t.Fatalf("RestoreTrashItem failed: %v", err)
+	}
+	got, err := store.GetDevice(ctx, trashed.ID)
+	if err != nil || got.DatacenterID != "" {
+		t.Errorf("expected the restored device without a datacenter, got %+v, %v", got, err)
+	}
+}
+
+func TestTrashNetworkRelinksChildren(t *testing.T) {
+	store := newTestStorage(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	supernet := &model.Network{Name: "super", Subnet: "10.0.0.0/8"}
+	parent := &model.Network{Name: "parent", Subnet: "10.1.0.0/16"}
+	child := &model.Network{Name: "child", Subnet: "10.1.2.0/24"}
+	for _, n := range []*model.Network{supernet, parent, child} {
+		if err := store.CreateNetwork(ctx, n); err != nil {
+			t.Fatalf("CreateNetwork failed: %v", err)
+		}
+	}
+	parentOf := func(id string) string {
+		t.Helper()
+		n, err := store.GetNetwork(ctx, id)
+		if err != nil {
+			t.Fatalf("GetNetwork failed: %v", err)
+		}
+		return n.ParentID
+	}
+	if got := parentOf(child.ID); got != parent.ID {
+		t.Fatalf("expected child under parent, got %q", got)
+	}
+
+	// The trashed parent is skipped, so the child moves up
+	if err := store.TrashResource(ctx, model.TrashTypeNetwork, parent.ID, ""); err != nil {
+		t.Fatalf("TrashResource failed: %v", err)
+	}
+	if got := parentOf(child.ID); got != supernet.ID {
+		t.Errorf("expected child under the supernet while its parent is trashed, got %q", got)
+	}
+
+	// A network created meanwhile does not nest under the trashed one either
+	sibling := &model.Network{Name: "sibling", Subnet: "10.1.3.0/24"}
+	if err := store.CreateNetwork(ctx, sibling); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+	if got := parentOf(sibling.ID); got != supernet.ID {
+		t.Errorf("expected sibling under the supernet, got %q", got)
+	}
+
+	if err := store.RestoreTrashItem(ctx, parent.ID); err != nil {
+		t.Fatalf("RestoreTrashItem failed: %v", err)
+	}
+	for _, id := range []string{child.ID, sibling.ID} {
+		if got := parentOf(id); got != parent.ID {
+			t.Errorf("expected %s back under the restored parent, got %q", id, got)
+		}
+	}
+	if got := parentOf(parent.ID); got != supernet.ID {
+		t.Errorf("expected the restored parent under the supernet, got %q", got)
+	}
+}
@@ -15,6 +15,8 @@ type UserStorage interface {
 	GetUser(ctx context.Context, id string) (*model.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	GetUserByOIDCSubject(ctx context.Context, issuer, subject string) (*model.User, error)
+	LinkUserOIDCSubject(ctx context.Context, id, issuer, subject string) error
 	ListUsers(ctx context.Context, filter *model.UserFilter) ([]model.User, error)
 	UpdateUser(ctx context.Context, user *model.User) error
 	DeleteUser(ctx context.Context, id string) error
@@ -35,13 +37,15 @@ func (s *SQLiteStorage) CreateUser(ctx context.Context, user *model.User) error
 		user.UpdatedAt = nowUTC()
 	}
 
-	query := `INSERT INTO users (id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at) 
-	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO users (id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at,
+	          oidc_issuer, oidc_subject)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := s.db.ExecContext(ctx, query,
 		user.ID, user.Username, user.Email, user.FullName,
 		user.PasswordHash, user.IsActive, user.IsAdmin,
 		user.CreatedAt, user.UpdatedAt, user.LastLoginAt,
+		nullString(user.OIDCIssuer), nullString(user.OIDCSubject),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
@@ -55,16 +59,18 @@ func (s *SQLiteStorage) GetUser(ctx context.Context, id string) (*model.User, er
 		return nil, ErrInvalidID
 	}
 
-	query := `SELECT id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at 
+	query := `SELECT id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at,
+	          COALESCE(oidc_issuer, ''), COALESCE(oidc_subject, '')
 	          FROM users WHERE id = ?`
 
 	var user model.User
 	var lastLoginAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
+	err := s.reader.QueryRowContext(ctx, query, id).Scan(
 		&user.ID, &user.Username, &user.Email, &user.FullName,
 		&user.PasswordHash, &user.IsActive, &user.IsAdmin,
 		&user.CreatedAt, &user.UpdatedAt, &lastLoginAt,
+		&user.OIDCIssuer, &user.OIDCSubject,
 	)
 	if err == sql.ErrNoRows {
 		return nil, ErrUserNotFound
@@ -85,16 +91,18 @@ func (s *SQLiteStorage) GetUserByUsername(ctx context.Context, username string)
 		return nil, fmt.Errorf("username cannot be empty")
 	}
 
-	query := `SELECT id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at 
+	query := `SELECT id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at,
+	          COALESCE(oidc_issuer, ''), COALESCE(oidc_subject, '')
 	          FROM users WHERE username = ?`
 
 	var user model.User
 	var lastLoginAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, query, username).Scan(
+	err := s.reader.QueryRowContext(ctx, query, username).Scan(
 		&user.ID, &user.Username, &user.Email, &user.FullName,
 		&user.PasswordHash, &user.IsActive, &user.IsAdmin,
 		&user.CreatedAt, &user.UpdatedAt, &lastLoginAt,
+		&user.OIDCIssuer, &user.OIDCSubject,
 	)
 	if err == sql.ErrNoRows {
 		return nil, ErrUserNotFound
@@ -115,16 +123,18 @@ func (s *SQLiteStorage) GetUserByEmail(ctx context.Context, email string) (*mode
 		return nil, fmt.Errorf("email cannot be empty")
 	}
 
-	query := `SELECT id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at 
+	query := `SELECT id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at,
+	          COALESCE(oidc_issuer, ''), COALESCE(oidc_subject, '')
 	          FROM users WHERE email = ?`
 
 	var user model.User
 	var lastLoginAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, query, email).Scan(
+	err := s.reader.QueryRowContext(ctx, query, email).Scan(
 		&user.ID, &user.Username, &user.Email, &user.FullName,
 		&user.PasswordHash, &user.IsActive, &user.IsAdmin,
 		&user.CreatedAt, &user.UpdatedAt, &lastLoginAt,
+		&user.OIDCIssuer, &user.OIDCSubject,
 	)
 	if err == sql.ErrNoRows {
 		return nil, ErrUserNotFound
@@ -140,8 +150,69 @@ func (s *SQLiteStorage) GetUserByEmail(ctx context.Context, email string) (*mode
 	return &user, nil
 }
 
+// GetUserByOIDCSubject returns the user signed in as subject at the OIDC
+// provider issuer
+func (s *SQLiteStorage) GetUserByOIDCSubject(ctx context.Context, issuer, subject string) (*model.User, error) {
+	if issuer == "" || subject == "" {
+		return nil, fmt.Errorf("issuer and subject cannot be empty")
+	}
+
+	query := `SELECT id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at,
+	          COALESCE(oidc_issuer, ''), COALESCE(oidc_subject, '')
+	          FROM users WHERE oidc_issuer = ? AND oidc_subject = ?`
+
+	var user model.User
+	var lastLoginAt sql.NullTime
+
+	err := s.reader.QueryRowContext(ctx, query, issuer, subject).Scan(
+		&user.ID, &user.Username, &user.Email, &user.FullName,
+		&user.PasswordHash, &user.IsActive, &user.IsAdmin,
+		&user.CreatedAt, &user.UpdatedAt, &lastLoginAt,
+		&user.OIDCIssuer, &user.OIDCSubject,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
+
+	return &user, nil
+}
+
+// LinkUserOIDCSubject records that the user signs in as subject at the OIDC
+// provider issuer. A user already linked to an identity is not relinked; that
+// fails with ErrOIDCSubjectLinked.
+func (s *SQLiteStorage) LinkUserOIDCSubject(ctx context.Context, id, issuer, subject string) error {
+	if id == "" {
+		return ErrInvalidID
+	}
+	if issuer == "" || subject == "" {
+		return fmt.Errorf("issuer and subject cannot be empty")
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE users SET oidc_issuer = ?, oidc_subject = ?, updated_at = ?
+		WHERE id = ? AND oidc_subject IS NULL`, issuer, subject, nowUTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to link user OIDC subject: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		if _, err := s.GetUser(ctx, id); err != nil {
+			return err
+		}
+		return ErrOIDCSubjectLinked
+	}
+	return nil
+}
+
 func (s *SQLiteStorage) ListUsers(ctx context.Context, filter *model.UserFilter) ([]model.User, error) {
-	query := `SELECT id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at 
+	query := `SELECT id, username, email, full_name, password_hash, is_active, is_admin, created_at, updated_at, last_login_at,
+	          COALESCE(oidc_issuer, ''), COALESCE(oidc_subject, '')
 	          FROM users WHERE 1=1`
 	var args []interface{}
 
@@ -173,7 +244,7 @@ func (s *SQLiteStorage) ListUsers(ctx context.Context, filter *model.UserFilter)
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -188,6 +259,7 @@ func (s *SQLiteStorage) ListUsers(ctx context.Context, filter *model.UserFilter)
 			&user.ID, &user.Username, &user.Email, &user.FullName,
 			&user.PasswordHash, &user.IsActive, &user.IsAdmin,
 			&user.CreatedAt, &user.UpdatedAt, &lastLoginAt,
+			&user.OIDCIssuer, &user.OIDCSubject,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -242,7 +314,7 @@ func (s *SQLiteStorage) DeleteUser(ctx context.Context, id string) error {
 	}
 
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)`, id).Scan(&exists)
+	err := s.reader.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)`, id).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check user existence: %w", err)
 	}
@@ -290,7 +362,7 @@ func (s *SQLiteStorage) UpdateUserPassword(ctx context.Context, id, passwordHash
 
 func (s *SQLiteStorage) UserCount(ctx context.Context) (int, error) {
 	var count int
-	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	err := s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count users: %w", err)
 	}
@@ -129,6 +129,42 @@ func TestGetUserByEmail(t *testing.T) {
 	}
 }
 
+func TestUserOIDCSubject(t *testing.T) {
+	db := newTestStorage(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	provisioned := &model.User{Username: "carol", Email: "carol@example.com", PasswordHash: "x", IsActive: true,
+		OIDCIssuer: "https://idp.example.com", OIDCSubject: "sub-1"}
+	local := &model.User{Username: "dave", Email: "dave@example.com", PasswordHash: "x", IsActive: true}
+	for _, u := range []*model.User{provisioned, local} {
+		if err := db.CreateUser(ctx, u); err != nil {
+			t.Fatalf("CreateUser() error = %v", err)
+		}
+	}
+
+	got, err := db.GetUserByOIDCSubject(ctx, "https://idp.example.com", "sub-1")
+	if err != nil || got.ID != provisioned.ID {
+		t.Fatalf("GetUserByOIDCSubject() = %+v, %v, want %s", got, err, provisioned.ID)
+	}
+	if _, err := db.GetUserByOIDCSubject(ctx, "https://other.example.com", "sub-1"); err != ErrUserNotFound {
+		t.Errorf("expected ErrUserNotFound for another issuer, got %v", err)
+	}
+
+	if err := db.LinkUserOIDCSubject(ctx, local.ID, "https://idp.example.com", "sub-2"); err != nil {
+		t.Fatalf("LinkUserOIDCSubject() error = %v", err)
+	}
+	if got, _ := db.GetUser(ctx, local.ID); got.OIDCSubject != "sub-2" {
+		t.Errorf("expected dave to be linked to sub-2, got %q", got.OIDCSubject)
+	}
+	if err := db.LinkUserOIDCSubject(ctx, provisioned.ID, "https://idp.example.com", "sub-3"); err != ErrOIDCSubjectLinked {
+		t.Errorf("expected ErrOIDCSubjectLinked relinking a linked user, got %v", err)
+	}
+	if err := db.LinkUserOIDCSubject(ctx, "missing", "https://idp.example.com", "sub-4"); err != ErrUserNotFound {
+		t.Errorf("expected ErrUserNotFound for a missing user, got %v", err)
+	}
+}
+
 func TestListUsers(t *testing.T) {
 	db := newTestStorage(t)
 	defer db.Close()
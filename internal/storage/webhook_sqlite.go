@@ -37,7 +37,7 @@ func (s *SQLiteStorage) GetWebhook(ctx context.Context, id string) (*model.Webho
 	webhook := &model.Webhook{}
 	var eventsJSON string
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, name, url, secret, events, active, description, created_at, updated_at, created_by
 		FROM webhooks WHERE id = ?
 	`, id).Scan(&webhook.ID, &webhook.Name, &webhook.URL, &webhook.Secret, &eventsJSON,
@@ -83,7 +83,7 @@ func (s *SQLiteStorage) ListWebhooks(ctx context.Context, filter *model.WebhookF
 	}
 	query, args = appendPagination(query, args, pg)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -149,7 +149,7 @@ func (s *SQLiteStorage) GetWebhooksForEvent(ctx context.Context, eventType model
 	query := `SELECT id, name, url, secret, events, active, description, created_at, updated_at, created_by
 		FROM webhooks WHERE active = 1 AND EXISTS (SELECT 1 FROM json_each(events) WHERE value = ?)`
 
-	rows, err := s.db.QueryContext(ctx, query, string(eventType))
+	rows, err := s.reader.QueryContext(ctx, query, string(eventType))
 	if err != nil {
 		return nil, err
 	}
@@ -184,7 +184,7 @@ func (s *SQLiteStorage) CreateDelivery(ctx context.Context, delivery *model.Webh
 func (s *SQLiteStorage) GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
 	delivery := &model.WebhookDelivery{}
 
-	err := s.db.QueryRowContext(ctx, `
+	err := s.reader.QueryRowContext(ctx, `
 		SELECT id, webhook_id, event_type, payload, response_code, response_body, error, duration_ms, status, attempt_number, next_retry, created_at
 		FROM webhook_deliveries WHERE id = ?
 	`, id).Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventType, &delivery.Payload,
@@ -239,7 +239,7 @@ func (s *SQLiteStorage) ListDeliveries(ctx context.Context, filter *model.Delive
 
 	query, args = appendPagination(query, args, &filter.Pagination)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -289,7 +289,7 @@ func (s *SQLiteStorage) GetPendingDeliveries(ctx context.Context, limit int) ([]
 		query += " LIMIT ?"
 	}
 
-	rows, err := s.db.QueryContext(ctx, query, model.DeliveryStatusPending, model.DeliveryStatusRetrying, nowUTC(), limit)
+	rows, err := s.reader.QueryContext(ctx, query, model.DeliveryStatusPending, model.DeliveryStatusRetrying, nowUTC(), limit)
 	if err != nil {
 		return nil, err
 	}
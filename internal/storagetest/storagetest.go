@@ -0,0 +1,67 @@
+// Package storagetest sets up in-memory storage for rackd's own tests.
+// Code outside this module uses pkg/storagetest, which serves the REST API
+// on top of it.
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/auth"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// NewStorage returns a fresh in-memory storage, an SQLite ":memory:"
+// database with all migrations applied, that is closed when the test ends
+func NewStorage(t testing.TB) storage.ExtendedStorage {
+	t.Helper()
+
+	store, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("storagetest: failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// CreateAdmin creates user with the admin role holding every permission, and
+// an API key for it whose bearer token is apiKey
+func CreateAdmin(t testing.TB, store storage.ExtendedStorage, user *model.User, apiKey string) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := store.CreateUser(ctx, user); err != nil {
+		t.Fatalf("storagetest: failed to create user: %v", err)
+	}
+
+	role, err := store.GetRoleByName(ctx, "admin")
+	if err != nil {
+		t.Fatalf("storagetest: failed to get admin role: %v", err)
+	}
+	perms, err := store.ListPermissions(ctx, &model.PermissionFilter{
+		Pagination: model.Pagination{Limit: model.MaxPageSize},
+	})
+	if err != nil {
+		t.Fatalf("storagetest: failed to list permissions: %v", err)
+	}
+	permissionIDs := make([]string, 0, len(perms))
+	for _, p := range perms {
+		permissionIDs = append(permissionIDs, p.ID)
+	}
+	if err := store.SetRolePermissions(ctx, role.ID, permissionIDs); err != nil {
+		t.Fatalf("storagetest: failed to set role permissions: %v", err)
+	}
+	if err := store.AssignRoleToUser(ctx, user.ID, role.ID); err != nil {
+		t.Fatalf("storagetest: failed to assign admin role: %v", err)
+	}
+
+	key := &model.APIKey{
+		Name:   user.Username,
+		Key:    auth.HashToken(apiKey),
+		UserID: user.ID,
+	}
+	if err := store.CreateAPIKey(ctx, key); err != nil {
+		t.Fatalf("storagetest: failed to create API key: %v", err)
+	}
+}
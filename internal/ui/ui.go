@@ -1,44 +1,262 @@
 package ui
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"io/fs"
 	"net/http"
+	"regexp"
 	"strings"
+	"sync"
 )
 
 //go:embed assets/*
 var assets embed.FS
 
-// IndexHTML returns the index.html content for SPA routing
-func IndexHTML() []byte {
-	sub, _ := fs.Sub(assets, "assets")
-	data, _ := fs.ReadFile(sub, "index.html")
-	return data
+// Cache-Control values. Fingerprinted asset URLs change with their content,
+// so they can be cached for a year; everything else is revalidated against
+// its ETag on each use.
+const (
+	cacheImmutable   = "public, max-age=31536000, immutable"
+	cacheRevalidate  = "no-cache"
+	fingerprintParam = "v"
+)
+
+// Option configures the UI handler
+type Option func(*options)
+
+type options struct {
+	basePath string
 }
 
-// RegisterRoutes serves the embedded UI assets with SPA fallback
-func RegisterRoutes(mux *http.ServeMux) {
-	sub, _ := fs.Sub(assets, "assets")
+// WithBasePath serves the UI under a path prefix, such as /rackd behind a
+// reverse proxy. Links in index.html get the prefix, and the web UI reads it
+// from the rackd-base-path meta tag for its own links and API calls.
+func WithBasePath(basePath string) Option {
+	return func(o *options) {
+		o.basePath = strings.TrimSuffix(basePath, "/")
+	}
+}
 
-	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
-		path := strings.TrimPrefix(r.URL.Path, "/")
-		if path == "" || !hasExtension(r.URL.Path) {
-			path = "index.html"
-		}
+// asset is an embedded file ready to serve, with its pre-compressed variants
+type asset struct {
+	data        []byte
+	contentType string
+	etag        string
+	// hash fingerprints the content in asset URLs: /app.js?v=<hash>
+	hash string
+	// encoded holds Brotli and gzip variants by content coding, from .br and
+	// .gz files next to the asset
+	encoded map[string][]byte
+}
+
+// AssetHandler serves the embedded UI assets with ETags, cache headers and
+// pre-compressed variants. Paths without a file extension, and files that
+// do not exist, get index.html so the web UI can route them.
+type AssetHandler struct {
+	assets map[string]*asset
+	index  *asset
+}
+
+// NewAssetHandler creates a handler for the embedded UI assets
+func NewAssetHandler(opts ...Option) *AssetHandler {
+	return newAssetHandler(assets, opts...)
+}
+
+func newAssetHandler(fsys fs.FS, opts ...Option) *AssetHandler {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
 
+	sub, _ := fs.Sub(fsys, "assets")
+	h := &AssetHandler{assets: map[string]*asset{}}
+	fs.WalkDir(sub, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(path, ".br") || strings.HasSuffix(path, ".gz") {
+			return err
+		}
 		data, err := fs.ReadFile(sub, path)
 		if err != nil {
-			// File not found - serve index.html for SPA
-			data, _ = fs.ReadFile(sub, "index.html")
-			path = "index.html"
+			return err
+		}
+		a := newAsset(path, data)
+		for coding, ext := range map[string]string{"br": ".br", "gzip": ".gz"} {
+			if encoded, err := fs.ReadFile(sub, path+ext); err == nil {
+				a.encoded[coding] = encoded
+			}
+		}
+		h.assets[path] = a
+		return nil
+	})
+
+	// index.html links to the other assets by fingerprinted URL under the
+	// base path. It is rewritten here, so it is gzipped here too.
+	index := newAsset("index.html", h.rewriteIndex(h.assets["index.html"], o.basePath))
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(index.data)
+	zw.Close()
+	index.encoded["gzip"] = gz.Bytes()
+	h.index = index
+	h.assets["index.html"] = index
+	return h
+}
+
+func newAsset(path string, data []byte) *asset {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:8])
+	return &asset{
+		data:        data,
+		contentType: contentType(path),
+		etag:        `"` + hash + `"`,
+		hash:        hash,
+		encoded:     map[string][]byte{},
+	}
+}
+
+// assetRef matches root-relative href and src attributes
+var assetRef = regexp.MustCompile(`(\s(?:href|src)=")(/[^"]*)"`)
+
+// rewriteIndex prefixes the root-relative links in index.html with the base
+// path, fingerprints the links to embedded assets, and adds the
+// rackd-base-path meta tag
+func (h *AssetHandler) rewriteIndex(index *asset, basePath string) []byte {
+	if index == nil {
+		return nil
+	}
+	html := assetRef.ReplaceAllStringFunc(string(index.data), func(m string) string {
+		parts := assetRef.FindStringSubmatch(m)
+		ref := parts[2]
+		if a := h.assets[strings.TrimPrefix(ref, "/")]; a != nil {
+			ref += "?" + fingerprintParam + "=" + a.hash
+		}
+		return parts[1] + basePath + ref + `"`
+	})
+	meta := `<meta name="rackd-base-path" content="` + basePath + `">`
+	if i := strings.Index(html, "<head>"); i >= 0 {
+		html = html[:i+len("<head>")] + "\n  " + meta + html[i+len("<head>"):]
+	}
+	return []byte(html)
+}
+
+// ServeHTTP serves an asset, or index.html for web UI routes
+func (h *AssetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	a := h.index
+	if path != "" && hasExtension(r.URL.Path) {
+		if found := h.assets[path]; found != nil {
+			a = found
+		}
+	}
+	if a == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", a.contentType)
+	if a != h.index && r.URL.Query().Get(fingerprintParam) == a.hash {
+		header.Set("Cache-Control", cacheImmutable)
+	} else {
+		header.Set("Cache-Control", cacheRevalidate)
+	}
+
+	data, etag := a.data, a.etag
+	if len(a.encoded) > 0 {
+		header.Add("Vary", "Accept-Encoding")
+		for _, coding := range []string{"br", "gzip"} {
+			if encoded, ok := a.encoded[coding]; ok && acceptsEncoding(r.Header.Get("Accept-Encoding"), coding) {
+				header.Set("Content-Encoding", coding)
+				data = encoded
+				// Variants need their own ETags, as their bytes differ
+				etag = strings.TrimSuffix(a.etag, `"`) + "-" + coding + `"`
+				break
+			}
 		}
+	}
+	header.Set("ETag", etag)
 
-		w.Header().Set("Content-Type", contentType(path))
-		w.Write(data)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(data)
+}
+
+// RegisterRoutes serves the embedded UI assets with SPA fallback
+func RegisterRoutes(mux *http.ServeMux, opts ...Option) {
+	mux.Handle("GET /", NewAssetHandler(opts...))
+}
+
+// indexes caches index.html by base path, as building it hashes every asset
+var indexes sync.Map
+
+// IndexHTML returns the index.html content for SPA routing, with its links
+// under the base path
+func IndexHTML(opts ...Option) []byte {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if data, ok := indexes.Load(o.basePath); ok {
+		return data.([]byte)
+	}
+	data := NewAssetHandler(opts...).index.data
+	indexes.Store(o.basePath, data)
+	return data
+}
+
+// StripBasePath removes the base path from requests that carry it, so rackd
+// works behind a reverse proxy whether or not the proxy strips the prefix.
+// A request for the base path itself is redirected to it with a trailing
+// slash.
+func StripBasePath(basePath string, next http.Handler) http.Handler {
+	basePath = strings.TrimSuffix(basePath, "/")
+	if basePath == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == basePath {
+			http.Redirect(w, r, basePath+"/", http.StatusMovedPermanently)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, basePath+"/") {
+			http.StripPrefix(basePath, next).ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
 }
 
+// acceptsEncoding reports whether an Accept-Encoding header allows the
+// content coding by name, taking q=0 as a refusal
+func acceptsEncoding(header, coding string) bool {
+	for part := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), coding) {
+			continue
+		}
+		_, q, found := strings.Cut(params, "q=")
+		return !found || strings.Trim(strings.TrimSpace(q), "0.") != ""
+	}
+	return false
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag, or is
+// *. Weak comparison is used, as for GET requests.
+func etagMatches(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
 func hasExtension(path string) bool {
 	lastSlash := strings.LastIndex(path, "/")
 	lastDot := strings.LastIndex(path, ".")
@@ -1,10 +1,13 @@
 package ui
 
 import (
+	"compress/gzip"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestRegisterRoutes(t *testing.T) {
@@ -59,3 +62,148 @@ func TestHasExtension(t *testing.T) {
 		}
 	}
 }
+
+func testAssets() fstest.MapFS {
+	return fstest.MapFS{
+		"assets/index.html": {Data: []byte(`<!DOCTYPE html><html><head>
+  <link rel="stylesheet" href="/output.css">
+  <script src="/app.js"></script>
+</head><body><a href="/devices">Devices</a></body></html>`)},
+		"assets/app.js":     {Data: []byte("console.log('app')")},
+		"assets/app.js.br":  {Data: []byte("brotli")},
+		"assets/app.js.gz":  {Data: []byte("gzip")},
+		"assets/output.css": {Data: []byte("body{}")},
+	}
+}
+
+func TestAssetHandlerCaching(t *testing.T) {
+	h := newAssetHandler(testAssets())
+	js := h.assets["app.js"]
+
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/app.js?v=" + js.hash)
+	if w.Header().Get("Cache-Control") != cacheImmutable || w.Header().Get("ETag") != js.etag || w.Body.String() != "console.log('app')" {
+		t.Errorf("fingerprinted asset: %v %q", w.Header(), w.Body.String())
+	}
+	if w := get("/app.js"); w.Header().Get("Cache-Control") != cacheRevalidate {
+		t.Errorf("expected an unfingerprinted asset to be revalidated, got %q", w.Header().Get("Cache-Control"))
+	}
+	if w := get("/app.js?v=stale"); w.Header().Get("Cache-Control") != cacheRevalidate {
+		t.Errorf("expected a stale fingerprint to be revalidated, got %q", w.Header().Get("Cache-Control"))
+	}
+	if w := get("/devices"); w.Header().Get("Cache-Control") != cacheRevalidate || !strings.Contains(w.Body.String(), "<!DOCTYPE html>") {
+		t.Errorf("expected index.html to be revalidated, got %q", w.Header().Get("Cache-Control"))
+	}
+
+	if w := get("/app.js", "If-None-Match", js.etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("expected 304 for a matching ETag, got %d", w.Code)
+	}
+	if w := get("/app.js", "If-None-Match", `"other", W/`+js.etag); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for a weak ETag in a list, got %d", w.Code)
+	}
+	if w := get("/app.js", "If-None-Match", `"other"`); w.Code != http.StatusOK {
+		t.Errorf("expected 200 for another ETag, got %d", w.Code)
+	}
+}
+
+func TestAssetHandlerEncodings(t *testing.T) {
+	h := newAssetHandler(testAssets())
+
+	tests := []struct {
+		accept, encoding, body string
+	}{
+		{"gzip, deflate, br", "br", "brotli"},
+		{"gzip", "gzip", "gzip"},
+		{"br;q=0, gzip", "gzip", "gzip"},
+		{"", "", "console.log('app')"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/app.js", nil)
+		req.Header.Set("Accept-Encoding", tt.accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Header().Get("Content-Encoding") != tt.encoding || w.Body.String() != tt.body || w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Accept-Encoding %q: got %q %q", tt.accept, w.Header().Get("Content-Encoding"), w.Body.String())
+		}
+		if tt.encoding != "" && !strings.HasSuffix(w.Header().Get("ETag"), "-"+tt.encoding+`"`) {
+			t.Errorf("Accept-Encoding %q: expected a variant ETag, got %q", tt.accept, w.Header().Get("ETag"))
+		}
+	}
+
+	// The pre-compressed files are not served on their own
+	req := httptest.NewRequest("GET", "/app.js.br", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "<!DOCTYPE html>") {
+		t.Errorf("expected index.html for a .br file, got %q", w.Body.String())
+	}
+
+	// index.html is rewritten, so it is gzipped at startup
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzipped index.html: %v", err)
+	}
+	if body, _ := io.ReadAll(zr); !strings.Contains(string(body), "<!DOCTYPE html>") {
+		t.Errorf("unexpected index.html %q", body)
+	}
+}
+
+func TestAssetHandlerBasePath(t *testing.T) {
+	h := newAssetHandler(testAssets(), WithBasePath("/rackd/"))
+	index := string(h.index.data)
+
+	for _, want := range []string{
+		`<meta name="rackd-base-path" content="/rackd">`,
+		`href="/rackd/output.css?v=` + h.assets["output.css"].hash + `"`,
+		`src="/rackd/app.js?v=` + h.assets["app.js"].hash + `"`,
+		`href="/rackd/devices"`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html is missing %s:\n%s", want, index)
+		}
+	}
+
+	if root := string(newAssetHandler(testAssets()).index.data); !strings.Contains(root, `<meta name="rackd-base-path" content="">`) ||
+		!strings.Contains(root, `src="/app.js?v=`) {
+		t.Errorf("unexpected index.html at the root:\n%s", root)
+	}
+}
+
+func TestStripBasePath(t *testing.T) {
+	var got string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r.URL.Path })
+	handler := StripBasePath("/rackd", next)
+
+	for path, want := range map[string]string{
+		"/rackd/":            "/",
+		"/rackd/api/devices": "/api/devices",
+		"/api/devices":       "/api/devices",
+		"/rackdx/devices":    "/rackdx/devices",
+	} {
+		got = ""
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		if got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/rackd", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/rackd/" {
+		t.Errorf("expected a redirect to /rackd/, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+}
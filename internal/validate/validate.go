@@ -0,0 +1,259 @@
+// Package validate checks devices against the site's policies: hostname
+// format, tags required on production devices, naming conventions per
+// datacenter and addresses lying within their declared network. The API, CLI
+// and MCP all reach these checks through the device service.
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+// Rule names the policy a violation breaks
+type Rule string
+
+const (
+	RuleHostname         Rule = "hostname"
+	RuleRequiredTags     Rule = "required_tags"
+	RuleNaming           Rule = "naming"
+	RuleAddressInNetwork Rule = "address_in_network"
+)
+
+// Violation is a device field that breaks a policy
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    Rule   `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Policies are the rules devices must follow on top of the checks every
+// device gets. The zero value only checks hostname syntax.
+type Policies struct {
+	// HostnamePattern, when set, must match the whole hostname
+	HostnamePattern *regexp.Regexp
+	// RequireFQDN requires hostnames to include a domain
+	RequireFQDN bool
+	// ProductionTag marks production devices, which must carry every one of
+	// RequiredTags. A required tag is also satisfied by a key=value tag with
+	// that key.
+	ProductionTag string
+	RequiredTags  []string
+	// NamingPatterns are the patterns device names must match, by datacenter
+	// ID or name. The "*" pattern applies to devices in a datacenter without
+	// its own pattern and to devices without a datacenter.
+	NamingPatterns map[string]*regexp.Regexp
+	// AddressInNetwork requires addresses assigned to a network to lie
+	// within its subnet
+	AddressInNetwork bool
+}
+
+// Lookup resolves the datacenters and networks a device refers to
+type Lookup interface {
+	GetDatacenter(ctx context.Context, id string) (*model.Datacenter, error)
+	GetNetwork(ctx context.Context, id string) (*model.Network, error)
+}
+
+// FromConfig builds the policies from the VALIDATION_* settings
+func FromConfig(cfg *config.Config) (*Policies, error) {
+	p := &Policies{
+		RequireFQDN:      cfg.ValidationHostnameFQDN,
+		ProductionTag:    strings.TrimSpace(cfg.ValidationProductionTag),
+		RequiredTags:     splitList(cfg.ValidationRequiredTags, ","),
+		AddressInNetwork: cfg.ValidationAddressInNetwork,
+	}
+	if cfg.ValidationHostnamePattern != "" {
+		re, err := compileAnchored(cfg.ValidationHostnamePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid VALIDATION_HOSTNAME_PATTERN: %w", err)
+		}
+		p.HostnamePattern = re
+	}
+	patterns, err := ParseNamingPatterns(cfg.ValidationNamingPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VALIDATION_NAMING_PATTERNS: %w", err)
+	}
+	p.NamingPatterns = patterns
+	return p, nil
+}
+
+// ParseNamingPatterns parses device name patterns by datacenter in the form
+// "datacenter=pattern;datacenter=pattern". Entries are separated by
+// semicolons since patterns often contain commas. Datacenters may be given by
+// ID or name, or as "*" for every other device.
+func ParseNamingPatterns(spec string) (map[string]*regexp.Regexp, error) {
+	patterns := make(map[string]*regexp.Regexp)
+	for _, entry := range splitList(spec, ";") {
+		dc, pattern, ok := strings.Cut(entry, "=")
+		dc, pattern = strings.TrimSpace(dc), strings.TrimSpace(pattern)
+		if !ok || dc == "" || pattern == "" {
+			return nil, fmt.Errorf("invalid naming pattern %q (expected datacenter=pattern)", entry)
+		}
+		re, err := compileAnchored(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("datacenter %s: %w", dc, err)
+		}
+		patterns[strings.ToLower(dc)] = re
+	}
+	return patterns, nil
+}
+
+// IsHostname reports whether name is a valid RFC 1123 hostname
+func IsHostname(name string) bool {
+	return len(name) <= 253 && hostnameRegex.MatchString(name)
+}
+
+var hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// Device returns the policies device breaks. Datacenters and networks that
+// don't exist are left for storage to report.
+func (p *Policies) Device(ctx context.Context, lookup Lookup, device *model.Device) ([]Violation, error) {
+	var violations []Violation
+	violations = append(violations, p.hostname(device)...)
+	violations = append(violations, p.requiredTags(device)...)
+
+	naming, err := p.naming(ctx, lookup, device)
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, naming...)
+
+	addresses, err := p.addressesInNetwork(ctx, lookup, device)
+	if err != nil {
+		return nil, err
+	}
+	return append(violations, addresses...), nil
+}
+
+func (p *Policies) hostname(device *model.Device) []Violation {
+	if device.Hostname == "" {
+		return nil
+	}
+	violation := func(msg string) []Violation {
+		return []Violation{{Field: "hostname", Rule: RuleHostname, Message: msg}}
+	}
+	if !IsHostname(device.Hostname) {
+		return violation(fmt.Sprintf("%q is not a valid hostname", device.Hostname))
+	}
+	if p.RequireFQDN && !strings.Contains(device.Hostname, ".") {
+		return violation(fmt.Sprintf("%q must be a fully qualified domain name", device.Hostname))
+	}
+	if p.HostnamePattern != nil && !p.HostnamePattern.MatchString(device.Hostname) {
+		return violation(fmt.Sprintf("%q does not match the hostname pattern %s", device.Hostname, p.HostnamePattern))
+	}
+	return nil
+}
+
+func (p *Policies) requiredTags(device *model.Device) []Violation {
+	if p.ProductionTag == "" || len(p.RequiredTags) == 0 || !hasTag(device.Tags, p.ProductionTag) {
+		return nil
+	}
+	var missing []string
+	for _, tag := range p.RequiredTags {
+		if !hasTag(device.Tags, tag) {
+			missing = append(missing, tag)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []Violation{{
+		Field:   "tags",
+		Rule:    RuleRequiredTags,
+		Message: fmt.Sprintf("%s devices must be tagged %s", p.ProductionTag, strings.Join(missing, ", ")),
+	}}
+}
+
+func (p *Policies) naming(ctx context.Context, lookup Lookup, device *model.Device) ([]Violation, error) {
+	if len(p.NamingPatterns) == 0 {
+		return nil, nil
+	}
+	pattern, dcName := p.NamingPatterns["*"], ""
+	if device.DatacenterID != "" {
+		if re, ok := p.NamingPatterns[strings.ToLower(device.DatacenterID)]; ok {
+			pattern = re
+		}
+		dc, err := lookup.GetDatacenter(ctx, device.DatacenterID)
+		if err != nil && !errors.Is(err, storage.ErrDatacenterNotFound) {
+			return nil, err
+		}
+		if dc != nil {
+			dcName = dc.Name
+			if re, ok := p.NamingPatterns[strings.ToLower(dc.Name)]; ok {
+				pattern = re
+			}
+		}
+	}
+	if pattern == nil || pattern.MatchString(device.Name) {
+		return nil, nil
+	}
+	msg := fmt.Sprintf("%q does not match the naming pattern %s", device.Name, pattern)
+	if dcName != "" {
+		msg = fmt.Sprintf("%q does not match the naming pattern %s for datacenter %s", device.Name, pattern, dcName)
+	}
+	return []Violation{{Field: "name", Rule: RuleNaming, Message: msg}}, nil
+}
+
+func (p *Policies) addressesInNetwork(ctx context.Context, lookup Lookup, device *model.Device) ([]Violation, error) {
+	if !p.AddressInNetwork {
+		return nil, nil
+	}
+	subnets := map[string]*net.IPNet{}
+	var violations []Violation
+	for i, addr := range device.Addresses {
+		ip := net.ParseIP(addr.IP)
+		if ip == nil || addr.NetworkID == "" {
+			continue
+		}
+		subnet, ok := subnets[addr.NetworkID]
+		if !ok {
+			network, err := lookup.GetNetwork(ctx, addr.NetworkID)
+			if err != nil && !errors.Is(err, storage.ErrNetworkNotFound) {
+				return nil, err
+			}
+			if network != nil {
+				_, subnet, _ = net.ParseCIDR(network.Subnet)
+			}
+			subnets[addr.NetworkID] = subnet
+		}
+		if subnet != nil && !subnet.Contains(ip) {
+			violations = append(violations, Violation{
+				Field:   fmt.Sprintf("addresses[%d].ip", i),
+				Rule:    RuleAddressInNetwork,
+				Message: fmt.Sprintf("%s is outside its network's subnet %s", addr.IP, subnet),
+			})
+		}
+	}
+	return violations, nil
+}
+
+// hasTag reports whether tags holds tag, or a key=value tag with tag as the key
+func hasTag(tags []string, tag string) bool {
+	return slices.ContainsFunc(tags, func(t string) bool {
+		key, _, _ := strings.Cut(t, "=")
+		return strings.EqualFold(t, tag) || strings.EqualFold(key, tag)
+	})
+}
+
+// compileAnchored compiles pattern to match whole values only
+func compileAnchored(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+func splitList(s, sep string) []string {
+	var out []string
+	for _, part := range strings.Split(s, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
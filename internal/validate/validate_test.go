@@ -0,0 +1,146 @@
+package validate
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/martinsuchenak/rackd/internal/config"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+type fakeLookup struct {
+	datacenters map[string]*model.Datacenter
+	networks    map[string]*model.Network
+}
+
+func (l fakeLookup) GetDatacenter(ctx context.Context, id string) (*model.Datacenter, error) {
+	if dc, ok := l.datacenters[id]; ok {
+		return dc, nil
+	}
+	return nil, storage.ErrDatacenterNotFound
+}
+
+func (l fakeLookup) GetNetwork(ctx context.Context, id string) (*model.Network, error) {
+	if n, ok := l.networks[id]; ok {
+		return n, nil
+	}
+	return nil, storage.ErrNetworkNotFound
+}
+
+func TestPolicies_Device(t *testing.T) {
+	policies, err := FromConfig(&config.Config{
+		ValidationHostnamePattern:  `[a-z0-9.-]+\.example\.com`,
+		ValidationHostnameFQDN:     true,
+		ValidationProductionTag:    "production",
+		ValidationRequiredTags:     "owner, cost-center",
+		ValidationNamingPatterns:   `Frankfurt=fra-[a-z]+-\d{2};*=[a-z0-9-]+`,
+		ValidationAddressInNetwork: true,
+	})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	lookup := fakeLookup{
+		datacenters: map[string]*model.Datacenter{"dc-1": {ID: "dc-1", Name: "Frankfurt"}},
+		networks:    map[string]*model.Network{"net-1": {ID: "net-1", Subnet: "10.0.0.0/24"}},
+	}
+
+	tests := []struct {
+		name   string
+		device model.Device
+		want   []Rule
+	}{
+		{
+			name: "compliant",
+			device: model.Device{
+				Name: "fra-web-01", Hostname: "web01.example.com", DatacenterID: "dc-1",
+				Tags:      []string{"production", "owner=ops", "cost-center"},
+				Addresses: []model.Address{{IP: "10.0.0.5", NetworkID: "net-1"}},
+			},
+		},
+		{
+			name:   "invalid hostname syntax",
+			device: model.Device{Name: "web", Hostname: "web_01"},
+			want:   []Rule{RuleHostname},
+		},
+		{
+			name:   "hostname without domain",
+			device: model.Device{Name: "web", Hostname: "web01"},
+			want:   []Rule{RuleHostname},
+		},
+		{
+			name:   "hostname outside pattern",
+			device: model.Device{Name: "web", Hostname: "web01.example.org"},
+			want:   []Rule{RuleHostname},
+		},
+		{
+			name:   "production without required tags",
+			device: model.Device{Name: "web", Tags: []string{"Production", "owner"}},
+			want:   []Rule{RuleRequiredTags},
+		},
+		{
+			name:   "non-production without required tags",
+			device: model.Device{Name: "web", Tags: []string{"staging"}},
+		},
+		{
+			name:   "name breaks datacenter pattern",
+			device: model.Device{Name: "web-01", DatacenterID: "dc-1"},
+			want:   []Rule{RuleNaming},
+		},
+		{
+			name:   "name breaks default pattern",
+			device: model.Device{Name: "Web 01"},
+			want:   []Rule{RuleNaming},
+		},
+		{
+			name: "address outside network",
+			device: model.Device{Name: "web", Addresses: []model.Address{
+				{IP: "10.0.1.5", NetworkID: "net-1"},
+				{IP: "10.0.1.6", NetworkID: "missing"},
+				{IP: "10.0.1.7"},
+			}},
+			want: []Rule{RuleAddressInNetwork},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := policies.Device(context.Background(), lookup, &tt.device)
+			if err != nil {
+				t.Fatalf("Device failed: %v", err)
+			}
+			var got []Rule
+			for _, v := range violations {
+				got = append(got, v.Rule)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %+v", tt.want, violations)
+			}
+		})
+	}
+}
+
+func TestPolicies_ZeroValue(t *testing.T) {
+	device := &model.Device{Name: "Anything Goes", Hostname: "web01", Tags: []string{"production"},
+		Addresses: []model.Address{{IP: "192.168.1.1", NetworkID: "net-1"}}}
+	violations, err := (&Policies{}).Device(context.Background(), fakeLookup{}, device)
+	if err != nil || len(violations) != 0 {
+		t.Errorf("expected no violations, got %+v, %v", violations, err)
+	}
+}
+
+func TestParseNamingPatterns(t *testing.T) {
+	patterns, err := ParseNamingPatterns(`Frankfurt=fra-\d{1,3} ; *=.+`)
+	if err != nil {
+		t.Fatalf("ParseNamingPatterns failed: %v", err)
+	}
+	if len(patterns) != 2 || !patterns["frankfurt"].MatchString("fra-12") || patterns["frankfurt"].MatchString("xfra-12") {
+		t.Errorf("unexpected patterns: %v", patterns)
+	}
+
+	for _, spec := range []string{"Frankfurt", "=fra-.*", "Frankfurt=fra-("} {
+		if _, err := ParseNamingPatterns(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
 	"time"
@@ -16,6 +17,7 @@ type EventHandler func(event model.Event)
 type EventBus struct {
 	handlers []EventHandler
 	mu       sync.RWMutex
+	inflight sync.WaitGroup // handlers started by Publish
 }
 
 // NewEventBus creates a new event bus
@@ -48,10 +50,20 @@ func (b *EventBus) Publish(eventType model.EventType, payload interface{}) {
 
 	// Call handlers asynchronously
 	for _, handler := range handlers {
-		go handler(event)
+		b.inflight.Add(1)
+		go func(handler EventHandler) {
+			defer b.inflight.Done()
+			handler(event)
+		}(handler)
 	}
 }
 
+// Drain waits for the handlers of events already published to return, or
+// for ctx to be done
+func (b *EventBus) Drain(ctx context.Context) error {
+	return waitContext(ctx, &b.inflight)
+}
+
 // PublishSync sends an event to all subscribers synchronously
 func (b *EventBus) PublishSync(eventType model.EventType, payload interface{}) {
 	event := model.Event{
@@ -93,6 +105,11 @@ func Publish(eventType model.EventType, payload interface{}) {
 	globalEventBus.Publish(eventType, payload)
 }
 
+// Drain waits for the handlers of the global event bus to return
+func Drain(ctx context.Context) error {
+	return globalEventBus.Drain(ctx)
+}
+
 // Subscribe registers a handler with the global event bus
 func Subscribe(handler EventHandler) {
 	globalEventBus.Subscribe(handler)
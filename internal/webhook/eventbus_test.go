@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/model"
+)
+
+func TestEventBusDrain(t *testing.T) {
+	bus := NewEventBus()
+	release := make(chan struct{})
+	var handled atomic.Int32
+	bus.Subscribe(func(model.Event) {
+		<-release
+		handled.Add(1)
+	})
+	bus.Publish(model.EventType("device.created"), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := bus.Drain(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected Drain to time out while a handler runs, got %v", err)
+	}
+
+	close(release)
+	if err := bus.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if handled.Load() != 1 {
+		t.Fatalf("expected the handler to have run once, got %d", handled.Load())
+	}
+}
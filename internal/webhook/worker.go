@@ -22,7 +22,8 @@ type Worker struct {
 	interval        time.Duration
 	stopCh          chan struct{}
 	wg              sync.WaitGroup
-	sem             chan struct{} // semaphore to bound concurrent deliveries
+	deliveries      sync.WaitGroup // deliveries started by handleEvent
+	sem             chan struct{}  // semaphore to bound concurrent deliveries
 }
 
 // NewWorker creates a new webhook worker
@@ -48,6 +49,32 @@ func (w *Worker) Stop() {
 	w.wg.Wait()
 }
 
+// Shutdown stops the worker and flushes pending deliveries: events already
+// published are delivered, and deliveries in progress finish, or ctx is done
+// first. Deliveries that fail are stored for retry as usual.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	w.Stop()
+	if err := Drain(ctx); err != nil {
+		return err
+	}
+	return waitContext(ctx, &w.deliveries)
+}
+
+// waitContext waits for wg, or for ctx to be done
+func waitContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // run is the main worker loop
 func (w *Worker) run() {
 	defer w.wg.Done()
@@ -85,7 +112,9 @@ func (w *Worker) handleEvent(event model.Event) {
 	// Deliver to each webhook (bounded concurrency)
 	for _, webhook := range webhooks {
 		w.sem <- struct{}{} // acquire
+		w.deliveries.Add(1)
 		go func(wh model.Webhook) {
+			defer w.deliveries.Done()
 			defer func() { <-w.sem }() // release
 			_, err := w.deliveryService.Deliver(ctx, &wh, event)
 			if err != nil {
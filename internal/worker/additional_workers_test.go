@@ -3,15 +3,19 @@ package worker
 import (
 	"context"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/martinsuchenak/rackd/internal/backup"
 	"github.com/martinsuchenak/rackd/internal/config"
 	"github.com/martinsuchenak/rackd/internal/discovery"
 	"github.com/martinsuchenak/rackd/internal/log"
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/service"
 	"github.com/martinsuchenak/rackd/internal/storage"
+	"github.com/martinsuchenak/rackd/internal/webhook"
 )
 
 type mockAdvancedScanner struct {
@@ -64,6 +68,25 @@ func TestDNSWorkerRunOnceAndStartStop(t *testing.T) {
 	worker.Stop()
 }
 
+func TestHypervisorSyncWorkerStartStop(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer store.Close()
+
+	services := service.NewServices(store, nil, nil)
+	services.SetHypervisorService(store, nil)
+
+	// No connectors are configured, so each sync is a no-op
+	worker := NewHypervisorSyncWorker(services.Hypervisors, 10*time.Millisecond)
+	worker.Start()
+	worker.Start()
+	time.Sleep(25 * time.Millisecond)
+	worker.Stop()
+	worker.Stop()
+}
+
 func TestSnapshotWorkerRunOnceAndCleanup(t *testing.T) {
 	store, err := storage.NewSQLiteStorage(":memory:")
 	if err != nil {
@@ -98,6 +121,19 @@ func TestSnapshotWorkerRunOnceAndCleanup(t *testing.T) {
 		t.Fatal("expected snapshots to be created")
 	}
 
+	// Running again the same day replaces the day's metrics
+	if err := worker.RunOnce(); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	metrics, err := store.ListInventoryMetrics(ctx, &model.InventoryMetricFilter{Names: []string{model.MetricPoolsUtilization, model.MetricPoolsTotalIPs}})
+	if err != nil {
+		t.Fatalf("ListInventoryMetrics failed: %v", err)
+	}
+	// The all-pools total, then utilization for all pools and for the pool
+	if len(metrics) != 3 || metrics[0].Value != 11 || metrics[2].ResourceID != pool.ID || metrics[2].Day != time.Now().UTC().Format(time.DateOnly) {
+		t.Errorf("unexpected pool metrics %+v", metrics)
+	}
+
 	worker.Start()
 	time.Sleep(25 * time.Millisecond)
 	worker.Stop()
@@ -148,7 +184,7 @@ func TestScheduledScanWorkerLifecycle(t *testing.T) {
 	mockScanner := &mockAdvancedScanner{
 		networks: map[string]*model.Network{network.ID: network},
 	}
-	worker := NewScheduledScanWorker(scheduledStore, profiles, mockScanner)
+	worker := NewScheduledScanWorker(scheduledStore, profiles, store, mockScanner)
 
 	if err := worker.Start(); err != nil {
 		t.Fatalf("Start failed: %v", err)
@@ -172,6 +208,16 @@ func TestScheduledScanWorkerLifecycle(t *testing.T) {
 		t.Fatalf("expected run timestamps to be updated, got %+v", got)
 	}
 
+	// An hourly scan is skipped rather than deferred past an all-day blackout
+	blackout := &model.ScanBlackout{Name: "freeze", Enabled: true, Type: model.BlackoutWeekly, StartTime: "00:00", EndTime: "00:00"}
+	if err := store.CreateScanBlackout(ctx, blackout); err != nil {
+		t.Fatalf("CreateScanBlackout failed: %v", err)
+	}
+	worker.runScheduledScan(scan)
+	if mockScanner.scanCount != 1 {
+		t.Fatalf("expected scheduled scan skipped during blackout, got %d scans", mockScanner.scanCount)
+	}
+
 	worker.RemoveSchedule(scan.ID)
 	if len(worker.jobs) != 0 {
 		t.Fatal("expected scheduled job to be removed")
@@ -195,3 +241,114 @@ func TestSchedulerCleanupRunsWithoutRules(t *testing.T) {
 }
 
 var _ discovery.AdvancedScanner = (*mockAdvancedScanner)(nil)
+
+func TestTrashPurgeWorkerPurgesExpiredItems(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	expired := &model.Device{Name: "expired"}
+	recent := &model.Device{Name: "recent"}
+	for _, d := range []*model.Device{expired, recent} {
+		if err := store.CreateDevice(ctx, d); err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+		if err := store.TrashResource(ctx, model.TrashTypeDevice, d.ID, ""); err != nil {
+			t.Fatalf("TrashResource failed: %v", err)
+		}
+	}
+	if _, err := store.DB().Exec(`UPDATE devices SET deleted_at = ? WHERE id = ?`, time.Now().UTC().AddDate(0, 0, -31), expired.ID); err != nil {
+		t.Fatalf("failed to age trash item: %v", err)
+	}
+
+	trash := service.NewTrashService(store)
+	trash.SetRetention(30)
+	worker := NewTrashPurgeWorker(trash)
+	worker.Start()
+	time.Sleep(100 * time.Millisecond)
+	worker.Stop()
+
+	items, err := store.ListTrash(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != recent.ID {
+		t.Errorf("expected only the recent device to remain, got %+v", items)
+	}
+}
+
+func TestWarrantyReminderWorkerRemindsOncePerDay(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	expiry := now.Truncate(24*time.Hour).AddDate(0, 0, 7)
+	if err := store.CreateDevice(ctx, &model.Device{Name: "web1", WarrantyExpiry: &expiry}); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+
+	reminders := make(chan *model.EventPayloadWarranty, 10)
+	webhook.Subscribe(func(event model.Event) {
+		if p, ok := event.Payload.(*model.EventPayloadWarranty); ok {
+			select {
+			case reminders <- p:
+			default:
+			}
+		}
+	})
+
+	worker := NewWarrantyReminderWorker(service.NewNotificationService(store), []int{30, 7})
+	worker.remind(now)
+	worker.remind(now.Add(time.Minute))
+	if err := webhook.Drain(ctx); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	if len(reminders) != 1 {
+		t.Fatalf("expected one reminder, got %d", len(reminders))
+	}
+	if p := <-reminders; p.Days != 7 || len(p.Devices) != 1 || p.Devices[0].Name != "web1" {
+		t.Errorf("unexpected reminder %+v", p)
+	}
+}
+
+func TestBackupWorkerTakesDueBackup(t *testing.T) {
+	dataDir := t.TempDir()
+	store, err := storage.NewSQLiteStorage(dataDir)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer store.Close()
+
+	backupDir := filepath.Join(dataDir, "backups")
+	backups := service.NewBackupService(store)
+	backups.SetManager(backup.NewManager(filepath.Join(dataDir, "rackd.db"), backupDir, backup.NewDir(backupDir), 0))
+
+	// No backup yet, so one is taken on start
+	worker := NewBackupWorker(backups, time.Hour)
+	worker.Start()
+	time.Sleep(100 * time.Millisecond)
+	worker.Stop()
+
+	entries, _ := os.ReadDir(backupDir)
+	if len(entries) != 1 {
+		t.Fatalf("expected one backup, got %d", len(entries))
+	}
+
+	// The backup just taken is recent, so a restart waits for the interval
+	worker = NewBackupWorker(backups, time.Hour)
+	worker.Start()
+	time.Sleep(100 * time.Millisecond)
+	worker.Stop()
+
+	if entries, _ := os.ReadDir(backupDir); len(entries) != 1 {
+		t.Errorf("expected no new backup after a restart, got %d", len(entries))
+	}
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// autoPromotionInterval controls how often auto-promotion rules are evaluated
+const autoPromotionInterval = 5 * time.Minute
+
+// AutoPromotionWorker periodically promotes discovered devices that match an
+// enabled auto-promotion rule
+type AutoPromotionWorker struct {
+	discovery *service.DiscoveryService
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	running   bool
+	mu        sync.Mutex
+}
+
+// NewAutoPromotionWorker creates a new auto-promotion worker
+func NewAutoPromotionWorker(discoverySvc *service.DiscoveryService) *AutoPromotionWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AutoPromotionWorker{
+		discovery: discoverySvc,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start begins the auto-promotion worker
+func (w *AutoPromotionWorker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	log.Info("Auto-promotion worker started", "interval", autoPromotionInterval)
+}
+
+// Stop halts the auto-promotion worker
+func (w *AutoPromotionWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+	log.Info("Auto-promotion worker stopped")
+}
+
+func (w *AutoPromotionWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(autoPromotionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.promote()
+		}
+	}
+}
+
+func (w *AutoPromotionWorker) promote() {
+	// Create system context to bypass RBAC for internal operations
+	sysCtx := service.SystemContext(w.ctx, "auto-promotion-worker")
+	if _, err := w.discovery.RunAutoPromotion(sysCtx, ""); err != nil {
+		log.Error("Failed to run auto-promotion rules", "error", err)
+	}
+}
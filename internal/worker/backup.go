@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// BackupWorker takes a database backup every interval
+type BackupWorker struct {
+	backups  *service.BackupService
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	running  bool
+	mu       sync.Mutex
+}
+
+// NewBackupWorker creates a new backup worker
+func NewBackupWorker(backupSvc *service.BackupService, interval time.Duration) *BackupWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BackupWorker{
+		backups:  backupSvc,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins the backup worker
+func (w *BackupWorker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	log.Info("Backup worker started", "interval", w.interval)
+}
+
+// Stop halts the backup worker
+func (w *BackupWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+	log.Info("Backup worker stopped")
+}
+
+func (w *BackupWorker) run() {
+	defer w.wg.Done()
+
+	// Create system context to bypass RBAC for internal operations
+	sysCtx := service.SystemContext(w.ctx, "backup-worker")
+
+	// Pick up the schedule from the newest backup, so restarting the server
+	// neither skips a backup nor takes an extra one
+	timer := time.NewTimer(w.untilDue(sysCtx))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-timer.C:
+			if _, err := w.backups.Create(sysCtx); err != nil {
+				log.Error("Scheduled backup failed", "error", err)
+			}
+			timer.Reset(w.interval)
+		}
+	}
+}
+
+// untilDue returns how long until the next backup is due
+func (w *BackupWorker) untilDue(ctx context.Context) time.Duration {
+	backups, err := w.backups.List(ctx)
+	if err != nil {
+		log.Warn("Failed to list backups", "error", err)
+		return 0
+	}
+	if len(backups) == 0 {
+		return 0
+	}
+	return max(time.Until(backups[0].CreatedAt.Add(w.interval)), 0)
+}
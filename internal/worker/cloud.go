@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// CloudSyncWorker periodically syncs servers from the configured cloud providers
+type CloudSyncWorker struct {
+	cloud    *service.CloudService
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	running  bool
+	mu       sync.Mutex
+}
+
+// NewCloudSyncWorker creates a new cloud sync worker
+func NewCloudSyncWorker(cloudSvc *service.CloudService, interval time.Duration) *CloudSyncWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CloudSyncWorker{
+		cloud:    cloudSvc,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins the cloud sync worker
+func (w *CloudSyncWorker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	log.Info("Cloud sync worker started", "interval", w.interval)
+}
+
+// Stop halts the cloud sync worker
+func (w *CloudSyncWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+	log.Info("Cloud sync worker stopped")
+}
+
+func (w *CloudSyncWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.sync()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.sync()
+		}
+	}
+}
+
+func (w *CloudSyncWorker) sync() {
+	sysCtx := service.SystemContext(w.ctx, "cloud-sync-worker")
+	if _, err := w.cloud.Sync(sysCtx, ""); err != nil {
+		log.Error("Cloud sync failed", "error", err)
+	}
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// HypervisorSyncWorker periodically syncs hosts and VMs from the enabled
+// hypervisor connectors
+type HypervisorSyncWorker struct {
+	hypervisors *service.HypervisorService
+	interval    time.Duration
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	running     bool
+	mu          sync.Mutex
+}
+
+// NewHypervisorSyncWorker creates a new hypervisor sync worker
+func NewHypervisorSyncWorker(hypervisorSvc *service.HypervisorService, interval time.Duration) *HypervisorSyncWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &HypervisorSyncWorker{
+		hypervisors: hypervisorSvc,
+		interval:    interval,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start begins the hypervisor sync worker
+func (w *HypervisorSyncWorker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	log.Info("Hypervisor sync worker started", "interval", w.interval)
+}
+
+// Stop halts the hypervisor sync worker
+func (w *HypervisorSyncWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+	log.Info("Hypervisor sync worker stopped")
+}
+
+func (w *HypervisorSyncWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.sync()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.sync()
+		}
+	}
+}
+
+func (w *HypervisorSyncWorker) sync() {
+	sysCtx := service.SystemContext(w.ctx, "hypervisor-sync-worker")
+	if _, err := w.hypervisors.SyncAll(sysCtx); err != nil {
+		log.Error("Hypervisor sync failed", "error", err)
+	}
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// monitorTick is how often the worker looks for due checks. Each check runs
+// on its own interval, so this only bounds how late a check can start.
+const monitorTick = 5 * time.Second
+
+// MonitorWorker runs availability checks when their interval has elapsed
+type MonitorWorker struct {
+	monitor *service.MonitorService
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewMonitorWorker creates a new availability monitoring worker
+func NewMonitorWorker(monitorSvc *service.MonitorService) *MonitorWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &MonitorWorker{
+		monitor: monitorSvc,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start begins the monitoring worker
+func (w *MonitorWorker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	log.Info("Availability monitor started")
+}
+
+// Stop halts the monitoring worker
+func (w *MonitorWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+	log.Info("Availability monitor stopped")
+}
+
+func (w *MonitorWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(monitorTick)
+	defer ticker.Stop()
+
+	w.check()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+func (w *MonitorWorker) check() {
+	sysCtx := service.SystemContext(w.ctx, "monitor-worker")
+	if _, err := w.monitor.RunDue(sysCtx); err != nil {
+		log.Error("Availability checks failed", "error", err)
+	}
+}
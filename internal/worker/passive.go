@@ -0,0 +1,264 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/discovery"
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+const (
+	// passiveRefreshInterval controls how often discovery rules are re-read
+	passiveRefreshInterval = 1 * time.Minute
+	// passiveDebounce suppresses repeated writes for the same announcement
+	passiveDebounce = 1 * time.Minute
+)
+
+type passiveNetwork struct {
+	id     string
+	subnet *net.IPNet
+}
+
+// PassiveDiscoveryWorker listens for mDNS and SSDP announcements on networks
+// whose discovery rule has passive listening enabled, and records the
+// announcing hosts as discovered devices without sending any probes.
+type PassiveDiscoveryWorker struct {
+	storage  storage.ExtendedStorage
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	running  bool
+	mu       sync.Mutex
+	netMu    sync.RWMutex
+	networks []passiveNetwork
+	recent   map[string]time.Time
+
+	listenerCancel context.CancelFunc
+	listenerDone   chan struct{}
+}
+
+// NewPassiveDiscoveryWorker creates a new passive discovery worker
+func NewPassiveDiscoveryWorker(store storage.ExtendedStorage) *PassiveDiscoveryWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PassiveDiscoveryWorker{
+		storage: store,
+		ctx:     ctx,
+		cancel:  cancel,
+		recent:  make(map[string]time.Time),
+	}
+}
+
+// Start begins watching discovery rules and listening when any network opts in
+func (w *PassiveDiscoveryWorker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	log.Info("Passive discovery worker started")
+}
+
+// Stop halts the listener and the worker
+func (w *PassiveDiscoveryWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+	w.stopListener()
+	log.Info("Passive discovery worker stopped")
+}
+
+func (w *PassiveDiscoveryWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(passiveRefreshInterval)
+	defer ticker.Stop()
+
+	w.refresh()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.refresh()
+		}
+	}
+}
+
+// refresh reloads the set of passive networks and starts or stops the
+// multicast listener so sockets are only open while something opted in.
+func (w *PassiveDiscoveryWorker) refresh() {
+	rules, err := w.storage.ListDiscoveryRules(w.ctx)
+	if err != nil {
+		log.Error("Failed to list discovery rules for passive discovery", "error", err)
+		return
+	}
+
+	var networks []passiveNetwork
+	for _, rule := range rules {
+		if !rule.PassiveEnabled {
+			continue
+		}
+		network, err := w.storage.GetNetwork(w.ctx, rule.NetworkID)
+		if err != nil {
+			log.Error("Failed to get network for passive discovery", "network_id", rule.NetworkID, "error", err)
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(network.Subnet)
+		if err != nil {
+			continue
+		}
+		networks = append(networks, passiveNetwork{id: network.ID, subnet: subnet})
+	}
+
+	w.netMu.Lock()
+	w.networks = networks
+	for key, seen := range w.recent {
+		if time.Since(seen) > passiveDebounce {
+			delete(w.recent, key)
+		}
+	}
+	w.netMu.Unlock()
+
+	if len(networks) > 0 {
+		w.startListener()
+	} else {
+		w.stopListener()
+	}
+}
+
+func (w *PassiveDiscoveryWorker) startListener() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.listenerCancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(w.ctx)
+	done := make(chan struct{})
+	w.listenerCancel = cancel
+	w.listenerDone = done
+
+	listener := discovery.NewPassiveListener(w.handleAnnouncement)
+	go func() {
+		defer close(done)
+		if err := listener.Run(ctx); err != nil {
+			log.Error("Passive discovery listener failed", "error", err)
+		}
+	}()
+	log.Info("Passive discovery listener started")
+}
+
+func (w *PassiveDiscoveryWorker) stopListener() {
+	w.mu.Lock()
+	cancel, done := w.listenerCancel, w.listenerDone
+	w.listenerCancel, w.listenerDone = nil, nil
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	log.Info("Passive discovery listener stopped")
+}
+
+// networkFor returns the passive network containing ip, if any
+func (w *PassiveDiscoveryWorker) networkFor(ip net.IP) string {
+	w.netMu.RLock()
+	defer w.netMu.RUnlock()
+	for _, n := range w.networks {
+		if n.subnet.Contains(ip) {
+			return n.id
+		}
+	}
+	return ""
+}
+
+func (w *PassiveDiscoveryWorker) handleAnnouncement(a discovery.PassiveAnnouncement) {
+	ip := net.ParseIP(a.IP)
+	if ip == nil {
+		return
+	}
+	networkID := w.networkFor(ip)
+	if networkID == "" {
+		return
+	}
+
+	key := networkID + "|" + a.IP + "|" + a.Source + "|" + a.Service.Service + "|" + a.Hostname
+	w.netMu.Lock()
+	if seen, ok := w.recent[key]; ok && a.SeenAt.Sub(seen) < passiveDebounce {
+		w.netMu.Unlock()
+		return
+	}
+	w.recent[key] = a.SeenAt
+	w.netMu.Unlock()
+
+	if err := w.record(networkID, a); err != nil {
+		log.Error("Failed to record passive announcement", "ip", a.IP, "source", a.Source, "error", err)
+	}
+}
+
+// record creates or updates the discovered device for an announcement
+func (w *PassiveDiscoveryWorker) record(networkID string, a discovery.PassiveAnnouncement) error {
+	existing, err := w.storage.GetDiscoveredDeviceByIP(w.ctx, networkID, a.IP)
+	if err != nil && !errors.Is(err, storage.ErrDiscoveryNotFound) {
+		return err
+	}
+
+	if existing != nil {
+		if existing.Hostname == "" && a.Hostname != "" {
+			existing.Hostname = a.Hostname
+		}
+		existing.Services = mergeService(existing.Services, a.Service)
+		existing.Status = "online"
+		return w.storage.UpdateDiscoveredDevice(w.ctx, existing)
+	}
+
+	device := &model.DiscoveredDevice{
+		IP:        a.IP,
+		Hostname:  a.Hostname,
+		NetworkID: networkID,
+		Status:    "online",
+		FirstSeen: a.SeenAt,
+		OpenPorts: []int{},
+		Services:  []model.ServiceInfo{a.Service},
+	}
+	// Announcing itself shows the host is up; the other factors need a scan
+	discovery.ScoreConfidence(device, discovery.ConfidenceEvidence{Responded: true})
+	return w.storage.CreateDiscoveredDevice(w.ctx, device)
+}
+
+func mergeService(services []model.ServiceInfo, svc model.ServiceInfo) []model.ServiceInfo {
+	for i, s := range services {
+		if s.Port == svc.Port && s.Protocol == svc.Protocol && s.Service == svc.Service {
+			if svc.Version != "" {
+				services[i].Version = svc.Version
+				services[i].Product = svc.Product
+				services[i].ProductVersion = svc.ProductVersion
+				services[i].Info = svc.Info
+			}
+			return services
+		}
+	}
+	return append(services, svc)
+}
@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/discovery"
+	"github.com/martinsuchenak/rackd/internal/model"
+	"github.com/martinsuchenak/rackd/internal/storage"
+)
+
+func TestPassiveDiscoveryWorkerRecordsAnnouncements(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	network := &model.Network{Name: "lan", Subnet: "192.168.1.0/24"}
+	if err := store.CreateNetwork(ctx, network); err != nil {
+		t.Fatalf("CreateNetwork failed: %v", err)
+	}
+
+	w := NewPassiveDiscoveryWorker(store)
+	_, subnet, _ := net.ParseCIDR(network.Subnet)
+	w.networks = []passiveNetwork{{id: network.ID, subnet: subnet}}
+
+	now := time.Now()
+	w.handleAnnouncement(discovery.PassiveAnnouncement{
+		IP:       "192.168.1.20",
+		Hostname: "printer",
+		Source:   discovery.PassiveSourceMDNS,
+		Service:  model.ServiceInfo{Port: 5353, Protocol: "mdns", Service: "Printer (IPP)"},
+		SeenAt:   now,
+	})
+	w.handleAnnouncement(discovery.PassiveAnnouncement{
+		IP:      "192.168.1.20",
+		Source:  discovery.PassiveSourceSSDP,
+		Service: model.ServiceInfo{Port: 1900, Protocol: "ssdp", Service: "upnp:rootdevice", Version: "HP/1.0"},
+		SeenAt:  now,
+	})
+	// Outside every passive network: ignored
+	w.handleAnnouncement(discovery.PassiveAnnouncement{
+		IP:     "10.0.0.5",
+		Source: discovery.PassiveSourceSSDP,
+		SeenAt: now,
+	})
+
+	devices, err := store.ListDiscoveredDevices(ctx, "")
+	if err != nil {
+		t.Fatalf("ListDiscoveredDevices failed: %v", err)
+	}
+	if len(devices) != 1 {
+		t.Fatalf("expected 1 discovered device, got %d", len(devices))
+	}
+	d := devices[0]
+	if d.Hostname != "printer" || d.NetworkID != network.ID {
+		t.Errorf("unexpected device: %+v", d)
+	}
+	if len(d.Services) != 2 {
+		t.Errorf("expected 2 services, got %+v", d.Services)
+	}
+}
+
+func TestPassiveDiscoveryWorkerRefresh(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	active := &model.Network{Name: "active", Subnet: "192.168.1.0/24"}
+	quiet := &model.Network{Name: "quiet", Subnet: "192.168.2.0/24"}
+	for _, n := range []*model.Network{active, quiet} {
+		if err := store.CreateNetwork(ctx, n); err != nil {
+			t.Fatalf("CreateNetwork failed: %v", err)
+		}
+	}
+	if err := store.SaveDiscoveryRule(ctx, &model.DiscoveryRule{NetworkID: active.ID, Enabled: true, ScanType: model.ScanTypeQuick, PassiveEnabled: true}); err != nil {
+		t.Fatalf("SaveDiscoveryRule failed: %v", err)
+	}
+	if err := store.SaveDiscoveryRule(ctx, &model.DiscoveryRule{NetworkID: quiet.ID, Enabled: true, ScanType: model.ScanTypeQuick}); err != nil {
+		t.Fatalf("SaveDiscoveryRule failed: %v", err)
+	}
+
+	w := NewPassiveDiscoveryWorker(store)
+	w.refresh()
+	defer w.stopListener()
+
+	if got := w.networkFor(net.ParseIP("192.168.1.9")); got != active.ID {
+		t.Errorf("networkFor(active) = %q, want %q", got, active.ID)
+	}
+	if got := w.networkFor(net.ParseIP("192.168.2.9")); got != "" {
+		t.Errorf("networkFor(quiet) = %q, want empty", got)
+	}
+}
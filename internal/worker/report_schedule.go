@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// reportScheduleTick is how often the worker looks for due report
+// schedules. Cron expressions have minute resolution.
+const reportScheduleTick = time.Minute
+
+// ReportScheduleWorker runs the report schedules that are due and sends
+// their reports to notification channels
+type ReportScheduleWorker struct {
+	schedules *service.ReportScheduleService
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	running   bool
+	mu        sync.Mutex
+}
+
+// NewReportScheduleWorker creates a new report schedule worker
+func NewReportScheduleWorker(scheduleSvc *service.ReportScheduleService) *ReportScheduleWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ReportScheduleWorker{
+		schedules: scheduleSvc,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start begins the report schedule worker
+func (w *ReportScheduleWorker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	log.Info("Report schedule worker started")
+}
+
+// Stop halts the report schedule worker
+func (w *ReportScheduleWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+	log.Info("Report schedule worker stopped")
+}
+
+func (w *ReportScheduleWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(reportScheduleTick)
+	defer ticker.Stop()
+
+	w.runDue(time.Now())
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.runDue(now)
+		}
+	}
+}
+
+// runDue runs the schedules due at now. Schedules missed while the server
+// was down run once when it starts.
+func (w *ReportScheduleWorker) runDue(now time.Time) {
+	sysCtx := service.SystemContext(w.ctx, "report-schedule-worker")
+	ran, err := w.schedules.RunDue(sysCtx, now)
+	if err != nil {
+		log.Error("Failed to run report schedules", "error", err)
+	}
+	if ran > 0 {
+		log.Info("Ran report schedules", "schedules", ran)
+	}
+}
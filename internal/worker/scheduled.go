@@ -7,6 +7,7 @@ import (
 
 	"github.com/martinsuchenak/rackd/internal/audit"
 	"github.com/martinsuchenak/rackd/internal/discovery"
+	"github.com/martinsuchenak/rackd/internal/log"
 	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/storage"
 	"github.com/robfig/cron/v3"
@@ -15,6 +16,7 @@ import (
 type ScheduledScanWorker struct {
 	scheduledStore   storage.ScheduledScanStorage
 	profileStore     storage.ProfileStorage
+	blackoutStore    storage.ScanBlackoutStorage
 	discoveryService discovery.AdvancedScanner
 	cron             *cron.Cron
 	jobs             map[string]cron.EntryID
@@ -26,6 +28,7 @@ type ScheduledScanWorker struct {
 func NewScheduledScanWorker(
 	scheduledStore storage.ScheduledScanStorage,
 	profileStore storage.ProfileStorage,
+	blackoutStore storage.ScanBlackoutStorage,
 	discoveryService discovery.AdvancedScanner,
 ) *ScheduledScanWorker {
 	// Create base context with audit info for scheduler operations
@@ -37,6 +40,7 @@ func NewScheduledScanWorker(
 	return &ScheduledScanWorker{
 		scheduledStore:   scheduledStore,
 		profileStore:     profileStore,
+		blackoutStore:    blackoutStore,
 		discoveryService: discoveryService,
 		cron:             cron.New(),
 		jobs:             make(map[string]cron.EntryID),
@@ -111,6 +115,10 @@ func (w *ScheduledScanWorker) scheduleJob(scan *model.ScheduledScan) error {
 }
 
 func (w *ScheduledScanWorker) runScheduledScan(scan *model.ScheduledScan) {
+	if !w.waitForBlackout(scan) {
+		return
+	}
+
 	network, err := w.discoveryService.GetNetwork(w.ctx, scan.NetworkID)
 	if err != nil {
 		return
@@ -139,3 +147,40 @@ func (w *ScheduledScanWorker) runScheduledScan(scan *model.ScheduledScan) {
 
 	w.scheduledStore.Update(scan)
 }
+
+// waitForBlackout holds a run while a blackout window covers the scan's
+// network. The run is deferred to the end of the window, or skipped when the
+// next scheduled run would come first. It returns whether the scan may run.
+func (w *ScheduledScanWorker) waitForBlackout(scan *model.ScheduledScan) bool {
+	for {
+		blackouts, err := w.blackoutStore.ListScanBlackouts(w.ctx, scan.NetworkID)
+		if err != nil {
+			log.Error("Failed to list scan blackouts", "scan", scan.Name, "error", err)
+			return false
+		}
+		blackout, until := model.ActiveBlackout(blackouts, scan.NetworkID, time.Now())
+		if blackout == nil {
+			return true
+		}
+
+		var next time.Time
+		w.mu.Lock()
+		if entryID, ok := w.jobs[scan.ID]; ok {
+			next = w.cron.Entry(entryID).Next
+		}
+		w.mu.Unlock()
+		if next.IsZero() || !until.Before(next) {
+			log.Info("Skipping scheduled scan during blackout window", "scan", scan.Name, "blackout", blackout.Name, "until", until)
+			return false
+		}
+
+		log.Info("Deferring scheduled scan until blackout window ends", "scan", scan.Name, "blackout", blackout.Name, "until", until)
+		timer := time.NewTimer(time.Until(until))
+		select {
+		case <-w.ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+	}
+}
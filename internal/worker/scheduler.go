@@ -8,6 +8,7 @@ import (
 	"github.com/martinsuchenak/rackd/internal/config"
 	"github.com/martinsuchenak/rackd/internal/discovery"
 	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/model"
 	"github.com/martinsuchenak/rackd/internal/storage"
 )
 
@@ -93,11 +94,42 @@ func (s *Scheduler) runScheduledScans() {
 
 	log.Debug("Found discovery rules", "count", len(rules))
 
+	// Networks assigned to a remote agent are scanned by that agent
+	agentNetworks := make(map[string]bool)
+	if agents, err := s.storage.ListAgents(s.ctx); err != nil {
+		log.Error("Failed to list agents", "error", err)
+	} else {
+		for _, agent := range agents {
+			for _, id := range agent.NetworkIDs {
+				agentNetworks[id] = true
+			}
+		}
+	}
+
+	// Scans are skipped while a blackout window covers their network
+	blackouts, err := s.storage.ListScanBlackouts(s.ctx, "")
+	if err != nil {
+		log.Error("Failed to list scan blackouts", "error", err)
+		return
+	}
+
 	for _, rule := range rules {
+		if s.ctx.Err() != nil {
+			// Shutting down; remaining rules run on the next start
+			return
+		}
 		if !rule.Enabled {
 			log.Trace("Skipping disabled rule", "network_id", rule.NetworkID)
 			continue
 		}
+		if agentNetworks[rule.NetworkID] {
+			log.Trace("Skipping rule for agent-scanned network", "network_id", rule.NetworkID)
+			continue
+		}
+		if blackout, until := model.ActiveBlackout(blackouts, rule.NetworkID, time.Now()); blackout != nil {
+			log.Info("Skipping scheduled discovery scan during blackout window", "network_id", rule.NetworkID, "blackout", blackout.Name, "until", until)
+			continue
+		}
 
 		network, err := s.storage.GetNetwork(s.ctx, rule.NetworkID)
 		if err != nil {
@@ -199,6 +199,28 @@ func TestScheduler_SkipsDisabledRules(t *testing.T) {
 	}
 }
 
+func TestScheduler_SkipsBlackoutNetworks(t *testing.T) {
+	scheduler, store, scanner := newTestScheduler(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	for _, id := range []string{"net-1", "net-2"} {
+		store.CreateNetwork(ctx, &model.Network{ID: id, Name: id, Subnet: "192.168." + id[4:] + ".0/24"})
+		store.SaveDiscoveryRule(ctx, &model.DiscoveryRule{ID: "rule-" + id, NetworkID: id, Enabled: true, ScanType: model.ScanTypeQuick, IntervalHours: 24})
+	}
+
+	// A window starting and ending at midnight lasts all day
+	store.CreateScanBlackout(ctx, &model.ScanBlackout{
+		NetworkID: "net-1", Name: "freeze", Enabled: true, Type: model.BlackoutWeekly, StartTime: "00:00", EndTime: "00:00",
+	})
+
+	scheduler.runScheduledScans()
+
+	if scanner.scanCount != 1 {
+		t.Errorf("expected only the network outside the blackout scanned, got %d scans", scanner.scanCount)
+	}
+}
+
 func TestScheduler_HandlesNetworkNotFound(t *testing.T) {
 	scheduler, store, scanner := newTestScheduler(t)
 	defer store.Close()
@@ -127,6 +127,7 @@ func (w *SnapshotWorker) takeSnapshots() error {
 	}
 
 	// Snapshot all pools
+	var poolSnapshots []*model.UtilizationSnapshot
 	pools, err := w.storage.ListNetworkPools(w.ctx, nil)
 	if err != nil {
 		log.Error("Failed to list pools for snapshot", "error", err)
@@ -166,12 +167,62 @@ func (w *SnapshotWorker) takeSnapshots() error {
 		if err := w.storage.CreateSnapshot(w.ctx, snapshot); err != nil {
 			log.Error("Failed to create pool snapshot", "pool_id", pool.ID, "error", err)
 		}
+		poolSnapshots = append(poolSnapshots, snapshot)
+	}
+
+	if err := w.recordInventoryMetrics(now, poolSnapshots); err != nil {
+		log.Error("Failed to record inventory metrics", "error", err)
 	}
 
 	log.Info("Snapshots completed", "networks", len(networks), "pools", len(pools))
 	return nil
 }
 
+// recordInventoryMetrics records the day's device counts and utilization.
+// Each run overwrites the day's values, so a day keeps its last run's.
+func (w *SnapshotWorker) recordInventoryMetrics(now time.Time, pools []*model.UtilizationSnapshot) error {
+	stats, err := w.storage.GetDashboardStats(w.ctx, 7, 1)
+	if err != nil {
+		return err
+	}
+
+	day := now.Format(time.DateOnly)
+	metric := func(name string, value float64) model.InventoryMetric {
+		return model.InventoryMetric{Day: day, Name: name, Value: value, RecordedAt: now}
+	}
+	counts := stats.DeviceStatusCounts
+	metrics := []model.InventoryMetric{
+		metric(model.MetricDevicesTotal, float64(stats.TotalDevices)),
+		metric(model.MetricDevicesStatus(model.DeviceStatusPlanned), float64(counts.Planned)),
+		metric(model.MetricDevicesStatus(model.DeviceStatusOrdered), float64(counts.Ordered)),
+		metric(model.MetricDevicesStatus(model.DeviceStatusActive), float64(counts.Active)),
+		metric(model.MetricDevicesStatus(model.DeviceStatusMaintenance), float64(counts.Maintenance)),
+		metric(model.MetricDevicesStatus(model.DeviceStatusDecommissioned), float64(counts.Decommissioned)),
+		metric(model.MetricDevicesDiscovered, float64(stats.DiscoveredDevices)),
+		metric(model.MetricNetworksUtilization, stats.OverallUtilization),
+	}
+
+	var usedIPs, totalIPs int
+	for _, p := range pools {
+		m := metric(model.MetricPoolsUtilization, p.Utilization)
+		m.ResourceID, m.ResourceName = p.ResourceID, p.ResourceName
+		metrics = append(metrics, m)
+		usedIPs += p.UsedIPs
+		totalIPs += p.TotalIPs
+	}
+	var utilization float64
+	if totalIPs > 0 {
+		utilization = float64(usedIPs) / float64(totalIPs) * 100
+	}
+	metrics = append(metrics,
+		metric(model.MetricPoolsUtilization, utilization),
+		metric(model.MetricPoolsUsedIPs, float64(usedIPs)),
+		metric(model.MetricPoolsTotalIPs, float64(totalIPs)),
+	)
+
+	return w.storage.RecordInventoryMetrics(w.ctx, metrics)
+}
+
 func (w *SnapshotWorker) cleanupOldSnapshots() {
 	if w.config.SnapshotRetentionDays > 0 {
 		if err := w.storage.DeleteOldSnapshots(w.ctx, w.config.SnapshotRetentionDays); err != nil {
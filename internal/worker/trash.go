@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// trashPurgeInterval controls how often expired trash items are purged
+const trashPurgeInterval = time.Hour
+
+// TrashPurgeWorker periodically purges deleted items that have outlived the
+// trash retention period
+type TrashPurgeWorker struct {
+	trash   *service.TrashService
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewTrashPurgeWorker creates a new trash purge worker
+func NewTrashPurgeWorker(trashSvc *service.TrashService) *TrashPurgeWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TrashPurgeWorker{
+		trash:  trashSvc,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start begins the trash purge worker
+func (w *TrashPurgeWorker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	log.Info("Trash purge worker started", "interval", trashPurgeInterval)
+}
+
+// Stop halts the trash purge worker
+func (w *TrashPurgeWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+	log.Info("Trash purge worker stopped")
+}
+
+func (w *TrashPurgeWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	w.purge()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.purge()
+		}
+	}
+}
+
+func (w *TrashPurgeWorker) purge() {
+	// Create system context to bypass RBAC for internal operations
+	sysCtx := service.SystemContext(w.ctx, "trash-purge-worker")
+	purged, err := w.trash.PurgeExpired(sysCtx)
+	if err != nil {
+		log.Error("Failed to purge trash", "error", err)
+		return
+	}
+	if purged > 0 {
+		log.Info("Purged expired trash items", "count", purged)
+	}
+}
@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/martinsuchenak/rackd/internal/log"
+	"github.com/martinsuchenak/rackd/internal/service"
+)
+
+// warrantyReminderTick is how often the worker checks whether the day
+// changed. Reminders go out once a day, and again after a restart.
+const warrantyReminderTick = time.Hour
+
+// WarrantyReminderWorker sends a reminder each day for the warranties that
+// expire a configured number of days later
+type WarrantyReminderWorker struct {
+	notifications *service.NotificationService
+	days          []int
+	lastDay       string
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+	running       bool
+	mu            sync.Mutex
+}
+
+// NewWarrantyReminderWorker creates a new warranty reminder worker
+func NewWarrantyReminderWorker(notificationSvc *service.NotificationService, days []int) *WarrantyReminderWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WarrantyReminderWorker{
+		notifications: notificationSvc,
+		days:          days,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start begins the warranty reminder worker
+func (w *WarrantyReminderWorker) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	log.Info("Warranty reminder worker started", "days", w.days)
+}
+
+// Stop halts the warranty reminder worker
+func (w *WarrantyReminderWorker) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+	log.Info("Warranty reminder worker stopped")
+}
+
+func (w *WarrantyReminderWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(warrantyReminderTick)
+	defer ticker.Stop()
+
+	w.remind(time.Now())
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.remind(now)
+		}
+	}
+}
+
+// remind sends the reminders for now's day unless they were already sent
+func (w *WarrantyReminderWorker) remind(now time.Time) {
+	day := now.UTC().Format(time.DateOnly)
+	if day == w.lastDay {
+		return
+	}
+
+	sysCtx := service.SystemContext(w.ctx, "warranty-reminder-worker")
+	reminded, err := w.notifications.SendWarrantyReminders(sysCtx, w.days, now)
+	if err != nil {
+		log.Error("Failed to send warranty reminders", "error", err)
+		return
+	}
+	w.lastDay = day
+	if reminded > 0 {
+		log.Info("Sent warranty reminders", "devices", reminded)
+	}
+}
@@ -4,30 +4,47 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/martinsuchenak/rackd/cmd/agent"
+	"github.com/martinsuchenak/rackd/cmd/ansible"
 	"github.com/martinsuchenak/rackd/cmd/apikey"
 	"github.com/martinsuchenak/rackd/cmd/audit"
 	"github.com/martinsuchenak/rackd/cmd/backup"
+	"github.com/martinsuchenak/rackd/cmd/bench"
 	"github.com/martinsuchenak/rackd/cmd/circuit"
+	"github.com/martinsuchenak/rackd/cmd/client"
+	"github.com/martinsuchenak/rackd/cmd/cloud"
 	cmdconflict "github.com/martinsuchenak/rackd/cmd/conflict"
 	"github.com/martinsuchenak/rackd/cmd/credential"
 	"github.com/martinsuchenak/rackd/cmd/customfield"
 	"github.com/martinsuchenak/rackd/cmd/datacenter"
+	"github.com/martinsuchenak/rackd/cmd/db"
 	"github.com/martinsuchenak/rackd/cmd/device"
+	"github.com/martinsuchenak/rackd/cmd/dhcp"
 	"github.com/martinsuchenak/rackd/cmd/discovery"
+	"github.com/martinsuchenak/rackd/cmd/dns"
 	"github.com/martinsuchenak/rackd/cmd/export"
+	"github.com/martinsuchenak/rackd/cmd/hypervisor"
 	importcmd "github.com/martinsuchenak/rackd/cmd/import"
+	"github.com/martinsuchenak/rackd/cmd/maintenance"
+	"github.com/martinsuchenak/rackd/cmd/mcpstdio"
 	"github.com/martinsuchenak/rackd/cmd/migrate"
 	"github.com/martinsuchenak/rackd/cmd/nat"
 	"github.com/martinsuchenak/rackd/cmd/network"
 	"github.com/martinsuchenak/rackd/cmd/oauth"
+	"github.com/martinsuchenak/rackd/cmd/report"
 	"github.com/martinsuchenak/rackd/cmd/reservation"
 	"github.com/martinsuchenak/rackd/cmd/role"
 	"github.com/martinsuchenak/rackd/cmd/scanprofile"
 	"github.com/martinsuchenak/rackd/cmd/scheduledscan"
+	"github.com/martinsuchenak/rackd/cmd/seed"
 	"github.com/martinsuchenak/rackd/cmd/server"
+	synccmd "github.com/martinsuchenak/rackd/cmd/sync"
+	"github.com/martinsuchenak/rackd/cmd/tui"
 	"github.com/martinsuchenak/rackd/cmd/user"
 	"github.com/martinsuchenak/rackd/cmd/webhook"
+	"github.com/martinsuchenak/rackd/internal/storage"
 	"github.com/paularlott/cli"
 )
 
@@ -42,16 +59,25 @@ func main() {
 		Name:    "rackd",
 		Usage:   "Device inventory and IPAM management",
 		Version: version,
+		Flags:   client.Flags(),
+		PreRun: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return ctx, client.ApplyFlags(cmd)
+		},
 		Commands: []*cli.Command{
 			server.Command(),
 			device.Command(),
 			network.Command(),
 			datacenter.Command(),
 			discovery.Command(),
+			dns.Command(),
+			cloud.Command(),
+			hypervisor.Command(),
+			agent.Command(version),
 			cmdconflict.Command(),
 			credential.Command(),
 			circuit.Command(),
 			nat.Command(),
+			maintenance.Command(),
 			reservation.Command(),
 			webhook.Command(),
 			customfield.Command(),
@@ -60,17 +86,29 @@ func main() {
 			role.Command(),
 			audit.Command(),
 			export.Command(),
+			report.Command(),
+			synccmd.Command(),
+			ansible.Command(),
+			dhcp.Command(),
 			importcmd.Command(),
 			scanprofile.Command(),
 			scheduledscan.Command(),
+			tui.Command(),
 			oauth.Command(),
 			backup.Command(),
+			backup.RestoreCommand(),
 			migrate.Command(),
+			db.Command(),
+			seed.Command(),
+			bench.Command(),
+			mcpstdio.Command(),
+			cli.GenerateCompletionCommand(),
 			{
 				Name:  "version",
 				Usage: "Show version information",
 				Run: func(ctx context.Context, cmd *cli.Command) error {
 					fmt.Printf("Version: %s\nCommit: %s\nBuilt: %s\n", version, commit, date)
+					fmt.Printf("Storage drivers: %s\n", strings.Join(storage.Drivers(), ", "))
 					return nil
 				},
 			},
@@ -78,7 +116,7 @@ func main() {
 	}
 
 	if err := app.Execute(context.Background()); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		client.PrintErr(err)
+		os.Exit(client.ExitCode(err))
 	}
 }
@@ -0,0 +1,89 @@
+package storagetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewServer(t *testing.T) {
+	srv := NewServer(t)
+
+	req, err := srv.NewRequest("POST", "/api/datacenters", strings.NewReader(`{"name":"fra1"}`))
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var created struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	// Data written through the API is visible on later requests
+	get, _ := http.NewRequest("GET", srv.URL+"/api/datacenters/"+created.ID, nil)
+	resp, err = srv.Do(get)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var got struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if got.Name != "fra1" {
+		t.Errorf("Name = %s, want fra1", got.Name)
+	}
+}
+
+func TestNewServerIdentifiesAdmin(t *testing.T) {
+	srv := NewServer(t)
+
+	req, _ := srv.NewRequest("GET", "/api/auth/me", nil)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var me struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&me); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if me.ID != srv.UserID {
+		t.Errorf("ID = %s, want %s", me.ID, srv.UserID)
+	}
+}
+
+func TestNewServerRequiresAuth(t *testing.T) {
+	srv := NewServer(t)
+
+	resp, err := http.Get(srv.URL + "/api/datacenters")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
@@ -0,0 +1,35 @@
+// Build script to write Brotli and gzip variants of the built assets. The
+// server sends them to clients that accept the encoding.
+import { brotliCompressSync, gzipSync, constants } from 'zlib';
+import { readdirSync, readFileSync, writeFileSync, statSync } from 'fs';
+import { join, relative } from 'path';
+
+const distDir = './dist';
+const compressible = /\.(js|css|html|svg|json|txt)$/;
+
+// index.html is rewritten by the server for the base path and compressed there
+const skip = new Set(['index.html']);
+
+function walk(dir: string): string[] {
+  return readdirSync(dir).flatMap((name) => {
+    const path = join(dir, name);
+    return statSync(path).isDirectory() ? walk(path) : [path];
+  });
+}
+
+let count = 0;
+for (const path of walk(distDir)) {
+  if (!compressible.test(path) || skip.has(relative(distDir, path))) continue;
+
+  const data = readFileSync(path);
+  const br = brotliCompressSync(data, {
+    params: { [constants.BROTLI_PARAM_QUALITY]: constants.BROTLI_MAX_QUALITY },
+  });
+  const gz = gzipSync(data, { level: 9 });
+
+  // Only keep variants that are actually smaller
+  if (br.length < data.length) writeFileSync(path + '.br', br);
+  if (gz.length < data.length) writeFileSync(path + '.gz', gz);
+  count++;
+}
+console.log(`Compressed ${count} assets`);
@@ -38,6 +38,7 @@ import { dnsProvidersComponent, dnsZonesComponent, dnsRecordsComponent } from '.
 import { apiKeysList } from './components/api-keys';
 import { auditLogsPage } from './components/audit';
 import { logsPage } from './components/logs';
+import { appPath, appPathname, withBase } from './core/base-path';
 
 function parseModelPath(expression: string): string[] | null {
   const trimmed = expression.trim();
@@ -200,7 +201,7 @@ function checkRoutePermission(path: string): boolean {
 // Router component for SPA navigation
 function router() {
   return {
-    route: window.location.pathname + window.location.search,
+    route: appPath(),
     sidebarOpen: false,
     accessDenied: false,
     activeConflictCount: 0,
@@ -234,7 +235,7 @@ function router() {
         this.updateConflictCount();
       }
       window.addEventListener('popstate', () => {
-        this.route = window.location.pathname + window.location.search;
+        this.route = appPath();
         this.accessDenied = !checkRoutePermission(this.route);
         updatePageTitle(this.route);
       });
@@ -242,7 +243,7 @@ function router() {
 
     navigate(path: string) {
       if (path !== this.route) {
-        history.pushState({}, '', path);
+        history.pushState({}, '', withBase(path));
         this.route = path;
         this.accessDenied = !checkRoutePermission(path);
         updatePageTitle(path);
@@ -380,9 +381,10 @@ async function init(): Promise<void> {
   }
 
   // Auth guard: redirect to login if not authenticated (and not already on login page or OAuth consent)
-  const isPublicRoute = window.location.pathname === '/login' || window.location.pathname.startsWith('/mcp-oauth/authorize');
+  const pathname = appPathname();
+  const isPublicRoute = pathname === '/login' || pathname.startsWith('/mcp-oauth/authorize');
   if (!window.rackdConfig?.user && !isPublicRoute) {
-    window.location.href = '/login';
+    window.location.href = withBase('/login');
     return;
   }
 
@@ -1,5 +1,6 @@
 import type { AuditFilter, AuditLog } from '../core/types';
 import { api, RackdAPIError } from '../core/api';
+import { withBase } from '../core/base-path';
 
 type ModalType = '' | 'detail';
 
@@ -84,7 +85,7 @@ export function auditLogsPage() {
         }
       }
       const query = params.toString();
-      history.replaceState({}, '', query ? `/audit?${query}` : '/audit');
+      history.replaceState({}, '', withBase(query ? `/audit?${query}` : '/audit'));
     },
 
     normalizePagination(): void {
@@ -3,6 +3,7 @@
 import type { Datacenter, Device } from '../core/types';
 import { api, RackdAPIError } from '../core/api';
 import { debounce } from '../core/utils';
+import { withBase } from '../core/base-path';
 
 interface DatacenterListData {
   datacenters: Datacenter[];
@@ -288,7 +289,7 @@ export function datacenterDetail(): DatacenterDetailData {
       this.deleting = true;
       try {
         await api.deleteDatacenter(this.datacenter.id);
-        window.location.href = '/datacenters';
+        window.location.href = withBase('/datacenters');
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to delete datacenter';
         this.deleting = false;
@@ -397,7 +398,7 @@ export function datacenterForm(): DatacenterFormData {
         } else {
           await api.createDatacenter(this.datacenter);
         }
-        window.location.href = '/datacenters';
+        window.location.href = withBase('/datacenters');
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to save datacenter';
       } finally {
@@ -406,7 +407,7 @@ export function datacenterForm(): DatacenterFormData {
     },
 
     cancel(): void {
-      window.location.href = '/datacenters';
+      window.location.href = withBase('/datacenters');
     },
   };
 }
@@ -1,9 +1,10 @@
 // Device Components for Rackd Web UI
 
-import type { Address, Datacenter, Device, DeviceFilter, DeviceRelationship, Network, NetworkPool, CustomFieldDefinition, CustomFieldValueInput } from '../core/types';
+import type { Address, Datacenter, Device, DeviceFilter, DeviceKind, DeviceRelationship, Network, NetworkPool, CustomFieldDefinition, CustomFieldValueInput } from '../core/types';
 import { api, RackdAPIError } from '../core/api';
 import { watchAlpineProperty } from '../core/alpine';
 import { debounce, formatDate, createFocusTrap, isValidIP } from '../core/utils';
+import { appPathname, withBase } from '../core/base-path';
 
 interface DeviceListData {
   devices: Device[];
@@ -58,6 +59,7 @@ export function deviceList() {
     networkFilter: '',
     poolFilter: '',
     statusFilter: '',
+    kindFilter: '',
     staleFilter: false,
     staleDays: 7,
     loading: true,
@@ -82,7 +84,8 @@ export function deviceList() {
       datacenter_id: '',
       username: '',
       location: '',
-      status: 'active' as 'planned' | 'active' | 'maintenance' | 'decommissioned',
+      kind: 'physical' as DeviceKind,
+      status: 'active' as 'planned' | 'ordered' | 'active' | 'maintenance' | 'decommissioned',
       tags: [] as string[],
       addresses: [] as Address[],
       domains: [] as string[],
@@ -227,6 +230,9 @@ export function deviceList() {
           if (this.statusFilter) {
             filter.status = this.statusFilter as DeviceFilter['status'];
           }
+          if (this.kindFilter) {
+            filter.kind = this.kindFilter as DeviceKind;
+          }
           if (this.staleFilter) {
             filter.stale = true;
             filter.stale_days = this.staleDays;
@@ -252,6 +258,9 @@ export function deviceList() {
         if (this.search && this.statusFilter) {
           devices = devices.filter(d => d.status === this.statusFilter);
         }
+        if (this.search && this.kindFilter) {
+          devices = devices.filter(d => d.kind === this.kindFilter);
+        }
 
         this.devices = devices;
         this.page = 1;
@@ -294,9 +303,10 @@ export function deviceList() {
       this.networkFilter = '';
       this.poolFilter = '';
       this.statusFilter = '';
+      this.kindFilter = '';
       // Update URL to remove query parameters
       if (window.location.search) {
-        window.history.pushState({}, '', '/devices');
+        window.history.pushState({}, '', withBase('/devices'));
       }
       this.loadDevices();
     },
@@ -406,6 +416,7 @@ export function deviceList() {
         datacenter_id: this.datacenters.length === 1 ? this.datacenters[0].id : '',
         username: '',
         location: '',
+        kind: 'physical',
         status: 'active',
         tags: [],
         addresses: [],
@@ -449,6 +460,7 @@ export function deviceList() {
         datacenter_id: fullDevice.datacenter_id || '',
         username: fullDevice.username || '',
         location: fullDevice.location || '',
+        kind: fullDevice.kind || 'physical',
         status: fullDevice.status || 'active',
         tags: [...(fullDevice.tags || [])],
         addresses: (fullDevice.addresses || []).map((a) => ({ ...a })),
@@ -510,7 +522,7 @@ export function deviceList() {
     addAddress(): void {
       this.editDevice.addresses = [
         ...(this.editDevice.addresses ?? []),
-        { ip: '', type: 'ipv4', label: '', network_id: '', switch_port: '', pool_id: '' },
+        { ip: '', type: '', label: '', network_id: '', switch_port: '', pool_id: '' },
       ];
     },
 
@@ -527,7 +539,10 @@ export function deviceList() {
 
       this.editDevice.addresses?.forEach((addr, i) => {
         if (!addr.ip?.trim()) {
-          this.validationErrors[`addr_${i}_ip`] = 'IP address is required';
+          // The server allocates the pool's next free IP
+          if (!addr.pool_id) {
+            this.validationErrors[`addr_${i}_ip`] = 'IP address or pool is required';
+          }
         } else if (!isValidIP(addr.ip)) {
           this.validationErrors[`addr_${i}_ip`] = 'Invalid IP address format';
         }
@@ -564,6 +579,7 @@ export function deviceList() {
       const s = status || 'unknown';
       switch (s) {
         case 'planned': return 'bg-blue-100 text-blue-800 dark:bg-blue-900 dark:text-blue-200';
+        case 'ordered': return 'bg-indigo-100 text-indigo-800 dark:bg-indigo-900 dark:text-indigo-200';
         case 'active': return 'bg-green-100 text-green-800 dark:bg-green-900 dark:text-green-200';
         case 'maintenance': return 'bg-yellow-100 text-yellow-800 dark:bg-yellow-900 dark:text-yellow-200';
         case 'decommissioned': return 'bg-gray-100 text-gray-800 dark:bg-gray-700 dark:text-gray-300';
@@ -687,6 +703,7 @@ interface DeviceDetailData {
   getHostname(): string;
   getMakeModel(): string;
   getOs(): string;
+  getKind(): string;
   getDatacenterId(): string | undefined;
   getLocation(): string;
   getUsername(): string;
@@ -764,7 +781,7 @@ export function deviceDetail() {
 
       // Also check periodically for pushState changes
       const interval = setInterval(() => {
-        if (window.location.pathname !== '/devices/detail') {
+        if (appPathname() !== '/devices/detail') {
           clearInterval(interval);
           return;
         }
@@ -932,7 +949,7 @@ export function deviceDetail() {
       this.deleting = true;
       try {
         await api.deleteDevice(this.device.id);
-        window.location.href = '/devices';
+        window.location.href = withBase('/devices');
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to delete device';
         this.deleting = false;
@@ -956,6 +973,7 @@ export function deviceDetail() {
         datacenter_id: this.device.datacenter_id || '',
         username: this.device.username || '',
         location: this.device.location || '',
+        kind: this.device.kind || 'physical',
         status: this.device.status || 'active',
         tags: [...(this.device.tags || [])],
         addresses: (this.device.addresses || []).map((a) => ({ ...a })),
@@ -1030,7 +1048,7 @@ export function deviceDetail() {
     addAddress(): void {
       this.editDevice.addresses = [
         ...(this.editDevice.addresses ?? []),
-        { ip: '', type: 'ipv4', label: '', network_id: '', switch_port: '', pool_id: '' },
+        { ip: '', type: '', label: '', network_id: '', switch_port: '', pool_id: '' },
       ];
     },
 
@@ -1273,6 +1291,10 @@ export function deviceDetail() {
       return this.device?.os || '-';
     },
 
+    getKind(): string {
+      return this.device?.kind || '-';
+    },
+
     getDatacenterId(): string | undefined {
       return this.device?.datacenter_id;
     },
@@ -1395,7 +1417,7 @@ export function deviceForm(): DeviceFormData {
     addAddress(): void {
       this.device.addresses = [
         ...(this.device.addresses ?? []),
-        { ip: '', type: 'ipv4', label: '' },
+        { ip: '', type: '', label: '' },
       ];
     },
 
@@ -1412,7 +1434,7 @@ export function deviceForm(): DeviceFormData {
         } else {
           await api.createDevice(this.device);
         }
-        window.location.href = '/devices';
+        window.location.href = withBase('/devices');
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to save device';
       } finally {
@@ -1421,7 +1443,7 @@ export function deviceForm(): DeviceFormData {
     },
 
     cancel(): void {
-      window.location.href = '/devices';
+      window.location.href = withBase('/devices');
     },
   };
 }
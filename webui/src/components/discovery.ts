@@ -2,6 +2,36 @@
 
 import type { DiscoveredDevice, DiscoveryScan, Network, Datacenter, Device } from '../core/types';
 import { api, RackdAPIError } from '../core/api';
+import { withBase } from '../core/base-path';
+
+function isScanFinished(scan: DiscoveryScan): boolean {
+  return scan.status === 'completed' || scan.status === 'failed';
+}
+
+// watchScanProgress follows a scan's progress stream, calling onUpdate for
+// every update. The stream closes itself once the scan finishes; onError is
+// called instead if it cannot be opened or drops, so callers can poll.
+function watchScanProgress(
+  id: string,
+  onUpdate: (scan: DiscoveryScan) => void,
+  onError: () => void,
+): EventSource | null {
+  if (typeof EventSource === 'undefined') {
+    onError();
+    return null;
+  }
+  const source = new EventSource(api.getScanEventsURL(id));
+  source.addEventListener('progress', (e) => {
+    const scan = JSON.parse((e as MessageEvent).data) as DiscoveryScan;
+    if (isScanFinished(scan)) source.close();
+    onUpdate(scan);
+  });
+  source.onerror = () => {
+    source.close();
+    onError();
+  };
+  return source;
+}
 
 interface DiscoveryListData {
   networks: Network[];
@@ -12,6 +42,7 @@ interface DiscoveryListData {
   loading: boolean;
   error: string;
   pollInterval: ReturnType<typeof setInterval> | null;
+  scanStreams: Map<string, EventSource>;
   init(): Promise<void>;
   loadNetworks(): Promise<void>;
   loadScans(): Promise<void>;
@@ -20,6 +51,9 @@ interface DiscoveryListData {
   selectNetwork(id: string): void;
   hasActiveScan(): boolean;
   startPolling(): void;
+  watchScan(id: string): void;
+  pollScans(): void;
+  updateScan(scan: DiscoveryScan): void;
   stopPolling(): void;
   destroy(): void;
   formatDate(date: string): string;
@@ -51,6 +85,7 @@ interface DiscoveryListData {
   getPromoteVendorPlaceholder(): string;
   getNetworkOptionLabel(network: Network): string;
   getScanProgressLabel(scan: DiscoveryScan): string;
+  getScanProgressWidth(scan: DiscoveryScan): string;
   getScanHostsLabel(scan: DiscoveryScan): string;
   getFoundHostsLabel(scan: DiscoveryScan): string;
   getHostnameLabel(device: DiscoveredDevice): string;
@@ -61,6 +96,7 @@ interface DiscoveryListData {
   getLimitedServices(device: DiscoveredDevice, limit: number): any[];
   getServiceTitle(service: any): string;
   getConfidenceLabel(device: DiscoveredDevice | null): string;
+  getConfidenceBreakdownTitle(device: DiscoveredDevice | null): string;
   getServiceLabel(service: any): string;
   setDeviceSort(column: 'confidence' | 'last_seen' | 'ip'): void;
   getDeviceSortAriaSort(column: 'confidence' | 'last_seen' | 'ip'): 'ascending' | 'descending' | 'none';
@@ -77,6 +113,7 @@ export function discoveryList() {
     loading: true,
     error: '',
     pollInterval: null as ReturnType<typeof setInterval> | null,
+    scanStreams: new Map<string, EventSource>(),
     deviceFilter: '',
     deviceSortColumn: 'confidence' as 'confidence' | 'last_seen' | 'ip',
     deviceSortDirection: 'desc' as 'asc' | 'desc',
@@ -241,7 +278,28 @@ export function discoveryList() {
       return this.scans.some((s) => s.status === 'pending' || s.status === 'running');
     },
 
+    // Running scans stream their progress; polling is the fallback when a
+    // stream cannot be kept open
     startPolling(): void {
+      for (const s of this.scans) {
+        if (!isScanFinished(s)) this.watchScan(s.id);
+      }
+    },
+
+    watchScan(id: string): void {
+      if (this.scanStreams.has(id)) return;
+      const source = watchScanProgress(
+        id,
+        (scan) => this.updateScan(scan),
+        () => {
+          this.scanStreams.delete(id);
+          this.pollScans();
+        },
+      );
+      if (source) this.scanStreams.set(id, source);
+    },
+
+    pollScans(): void {
       if (this.pollInterval) return;
       this.pollInterval = setInterval(async () => {
         await this.loadScans();
@@ -250,11 +308,22 @@ export function discoveryList() {
       }, 3000);
     },
 
+    updateScan(scan: DiscoveryScan): void {
+      const previous = this.scans.find((s) => s.id === scan.id);
+      this.scans = this.scans.map((s) => (s.id === scan.id ? scan : s));
+      if (isScanFinished(scan)) this.scanStreams.delete(scan.id);
+      if (isScanFinished(scan) || (previous && previous.found_hosts !== scan.found_hosts)) {
+        this.loadDiscoveredDevices();
+      }
+    },
+
     stopPolling(): void {
       if (this.pollInterval) {
         clearInterval(this.pollInterval);
         this.pollInterval = null;
       }
+      this.scanStreams.forEach((source) => source.close());
+      this.scanStreams.clear();
     },
 
     destroy(): void {
@@ -335,6 +404,18 @@ export function discoveryList() {
       }
     },
 
+    async ignoreDevice(deviceId: string): Promise<void> {
+      const reason = prompt('Ignore this discovered device? It will no longer be listed or reported.\n\nReason (optional):');
+      if (reason === null) return;
+      this.error = '';
+      try {
+        await api.ignoreDiscoveredDevice(deviceId, reason);
+        await this.loadDiscoveredDevices();
+      } catch (e) {
+        this.error = e instanceof RackdAPIError ? e.message : 'Failed to ignore device';
+      }
+    },
+
     async deleteScan(scanId: string): Promise<void> {
       this.error = '';
       try {
@@ -402,12 +483,12 @@ export function discoveryList() {
     },
     getConfidenceStyle(): string {
       const conf = this.promoteDevice?.confidence || 0;
-      return `width: ${Math.max(0, Math.min(100, (conf / 3) * 100))}%`;
+      return `width: ${Math.max(0, Math.min(100, conf))}%`;
     },
     getConfidenceClass(): string {
       const conf = this.promoteDevice?.confidence || 0;
-      if (conf >= 3) return 'bg-green-500';
-      if (conf >= 2) return 'bg-yellow-500';
+      if (conf >= 70) return 'bg-green-500';
+      if (conf >= 40) return 'bg-yellow-500';
       return 'bg-gray-500';
     },
     getPromoteVendorPlaceholder(): string {
@@ -500,9 +581,9 @@ export function discoveryList() {
     },
     getConfidenceBadgeClass(conf: number): any {
       return {
-        'bg-green-100 text-green-800 dark:bg-green-900/30 dark:text-green-400': conf >= 3,
-        'bg-yellow-100 text-yellow-800 dark:bg-yellow-900/30 dark:text-yellow-400': conf >= 2 && conf < 3,
-        'bg-gray-100 text-gray-800 dark:bg-gray-700 dark:text-gray-400': conf < 2
+        'bg-green-100 text-green-800 dark:bg-green-900/30 dark:text-green-400': conf >= 70,
+        'bg-yellow-100 text-yellow-800 dark:bg-yellow-900/30 dark:text-yellow-400': conf >= 40 && conf < 70,
+        'bg-gray-100 text-gray-800 dark:bg-gray-700 dark:text-gray-400': conf < 40
       };
     },
     getScanStatusClass(status: string): any {
@@ -525,6 +606,9 @@ export function discoveryList() {
     getScanProgressLabel(s: DiscoveryScan): string {
       return `${Math.round(s.progress_percent)}%`;
     },
+    getScanProgressWidth(s: DiscoveryScan): string {
+      return `width: ${Math.min(100, Math.max(0, s.progress_percent || 0))}%`;
+    },
     getScanHostsLabel(s: DiscoveryScan): string {
       return `${s.scanned_hosts}/${s.total_hosts} hosts`;
     },
@@ -551,11 +635,22 @@ export function discoveryList() {
     },
     getServiceTitle(svc: any): string {
       if (!svc) return '';
-      return `${svc.protocol}/${svc.port}${svc.version ? ' ' + svc.version : ''}`;
+      let title = `${svc.protocol}/${svc.port}${svc.version ? ' ' + svc.version : ''}`;
+      if (svc.http_title) title += ` - ${svc.http_title}`;
+      if (svc.certificate) {
+        title += ` (certificate ${svc.certificate.common_name || 'without CN'}, expires ${svc.certificate.not_after.slice(0, 10)})`;
+      }
+      return title;
     },
     getConfidenceLabel(d: DiscoveredDevice | null): string {
       const conf = d ? d.confidence : 0;
-      return `${conf}/3`;
+      return `${conf}/100`;
+    },
+    getConfidenceBreakdownTitle(d: DiscoveredDevice | null): string {
+      if (!d || !d.confidence_breakdown) return '';
+      return d.confidence_breakdown
+        .map((f) => `${f.factor}: ${f.points}/${f.max}${f.detail ? ` (${f.detail})` : ''}`)
+        .join('\n');
     },
     getServiceLabel(svc: any): string {
       if (!svc) return '';
@@ -593,7 +688,7 @@ export function scanForm(): ScanFormData {
       this.error = '';
       try {
         await api.startScan(this.networkId, this.scanType);
-        window.location.href = '/discovery';
+        window.location.href = withBase('/discovery');
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to start scan';
       } finally {
@@ -609,10 +704,12 @@ interface ScanDetailData {
   loading: boolean;
   error: string;
   pollInterval: ReturnType<typeof setInterval> | null;
+  stream: EventSource | null;
   init(): Promise<void>;
   loadScan(): Promise<void>;
   loadNetwork(): Promise<void>;
   startPolling(): void;
+  pollScan(): void;
   stopPolling(): void;
   destroy(): void;
 }
@@ -624,6 +721,7 @@ export function scanDetail(): ScanDetailData {
     loading: true,
     error: '',
     pollInterval: null,
+    stream: null,
 
     async init(): Promise<void> {
       const id = new URLSearchParams(window.location.search).get('id');
@@ -662,6 +760,21 @@ export function scanDetail(): ScanDetailData {
     },
 
     startPolling(): void {
+      if (this.stream || this.pollInterval || !this.scan) return;
+      this.stream = watchScanProgress(
+        this.scan.id,
+        (scan) => {
+          this.scan = scan;
+          if (isScanFinished(scan)) this.stream = null;
+        },
+        () => {
+          this.stream = null;
+          this.pollScan();
+        },
+      );
+    },
+
+    pollScan(): void {
       if (this.pollInterval) return;
       this.pollInterval = setInterval(async () => {
         await this.loadScan();
@@ -676,6 +789,8 @@ export function scanDetail(): ScanDetailData {
         clearInterval(this.pollInterval);
         this.pollInterval = null;
       }
+      this.stream?.close();
+      this.stream = null;
     },
 
     destroy(): void {
@@ -732,7 +847,7 @@ export function promoteForm(): PromoteFormData {
       this.error = '';
       try {
         await api.promoteDevice(this.device.id, this.name.trim());
-        window.location.href = '/discovery';
+        window.location.href = withBase('/discovery');
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to promote device';
       } finally {
@@ -741,7 +856,7 @@ export function promoteForm(): PromoteFormData {
     },
 
     cancel(): void {
-      window.location.href = '/discovery';
+      window.location.href = withBase('/discovery');
     },
   };
 }
@@ -9,6 +9,7 @@ import type {
   SyncStatus, RecordSyncStatus, Network, Device, Datacenter
 } from '../core/types';
 import { api, RackdAPIError } from '../core/api';
+import { withBase } from '../core/base-path';
 
 // ==================== DNS PROVIDERS COMPONENT ====================
 
@@ -802,7 +803,7 @@ export function dnsZonesComponent(): DNSZonesData {
     },
 
     navigateToRecords(zoneId: string): void {
-      window.location.href = this.getRecordsUrl(zoneId);
+      window.location.href = withBase(this.getRecordsUrl(zoneId));
     },
 
     getZoneAriaLabel(zoneName: string, action: string): string {
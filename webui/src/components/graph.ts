@@ -446,6 +446,7 @@ export function deviceGraph(): GraphData {
       const colors: Record<DeviceStatus, string> = {
         active: '#10B981',
         planned: '#3B82F6',
+        ordered: '#6366F1',
         maintenance: '#F59E0B',
         decommissioned: '#6B7280'
       };
@@ -480,6 +481,7 @@ export function deviceGraph(): GraphData {
       const classes: Record<string, string> = {
         'active': 'text-green-600 dark:text-green-400',
         'planned': 'text-blue-600 dark:text-blue-400',
+        'ordered': 'text-indigo-600 dark:text-indigo-400',
         'maintenance': 'text-yellow-600 dark:text-yellow-400',
         'decommissioned': 'text-gray-600 dark:text-gray-400'
       };
@@ -2,12 +2,15 @@
 
 import type { LoginRequest } from '../core/types';
 import { api } from '../core/api';
+import { withBase } from '../core/base-path';
 
 interface LoginData {
   username: string;
   password: string;
   loading: boolean;
   error: string;
+  sso: boolean;
+  ssoURL: string;
   init(): void;
   submit(): Promise<void>;
   showError(message: string): void;
@@ -19,13 +22,27 @@ export function login() {
     password: '',
     loading: false,
     error: '',
+    sso: false,
+    ssoURL: withBase('/api/auth/oidc/login'),
 
     async init(): Promise<void> {
+      const params = new URLSearchParams(window.location.search);
+      // Set by the server when a single sign-on attempt fails
+      const ssoError = params.get('error');
+      if (ssoError) {
+        this.error = ssoError;
+      }
+      const redirect = params.get('redirect');
+      if (redirect) {
+        this.ssoURL += '?' + new URLSearchParams({ redirect }).toString();
+      }
+
       try {
         const config = await api.getConfig();
         if (config.user) {
-          window.location.href = '/';
+          window.location.href = withBase('/');
         }
+        this.sso = config.features.includes('oidc');
       } catch {
       }
     },
@@ -50,7 +67,7 @@ export function login() {
           password: this.password,
         };
 
-        const response = await fetch('/api/auth/login', {
+        const response = await fetch(withBase('/api/auth/login'), {
           method: 'POST',
           headers: { 'Content-Type': 'application/json' },
           credentials: 'same-origin',
@@ -69,9 +86,9 @@ export function login() {
         const redirect = params.get('redirect');
         // Security: Only allow relative paths starting with '/' to prevent open redirect attacks
         if (redirect && redirect.startsWith('/') && !redirect.startsWith('//')) {
-          window.location.href = redirect;
+          window.location.href = withBase(redirect);
         } else {
-          window.location.href = '/';
+          window.location.href = withBase('/');
         }
       } catch (err) {
         this.showError('Network error. Please try again.');
@@ -1,5 +1,6 @@
 import type { LogEntry, LogFilter } from '../core/types';
 import { api, RackdAPIError } from '../core/api';
+import { withBase } from '../core/base-path';
 
 type ModalType = '' | 'detail';
 
@@ -81,7 +82,7 @@ export function logsPage() {
         }
       }
       const query = params.toString();
-      history.replaceState({}, '', query ? `/logs?${query}` : '/logs');
+      history.replaceState({}, '', withBase(query ? `/logs?${query}` : '/logs'));
     },
 
     normalizePagination(): void {
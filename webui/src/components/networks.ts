@@ -4,6 +4,7 @@ import type { Datacenter, Network, NetworkPool, NetworkUtilization, Device } fro
 import { api, RackdAPIError } from '../core/api';
 import { watchAlpineProperty } from '../core/alpine';
 import { debounce, isValidCIDR, createFocusTrap } from '../core/utils';
+import { withBase } from '../core/base-path';
 
 interface NetworkListData {
   networks: Network[];
@@ -516,7 +517,7 @@ export function networkDetail(): NetworkDetailData {
       this.deleting = true;
       try {
         await api.deleteNetwork(this.network.id);
-        window.location.href = '/networks';
+        window.location.href = withBase('/networks');
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to delete network';
         this.deleting = false;
@@ -764,7 +765,7 @@ export function networkForm(): NetworkFormData {
         } else {
           await api.createNetwork(this.network);
         }
-        window.location.href = '/networks';
+        window.location.href = withBase('/networks');
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to save network';
       } finally {
@@ -773,7 +774,7 @@ export function networkForm(): NetworkFormData {
     },
 
     cancel(): void {
-      window.location.href = '/networks';
+      window.location.href = withBase('/networks');
     },
   };
 }
@@ -1,3 +1,5 @@
+import { withBase } from '../core/base-path';
+
 interface ConsentData {
   client_name: string;
   client_uri: string;
@@ -21,7 +23,7 @@ export function oauthConsent() {
       try {
         // Fetch consent data from the authorize endpoint using current URL params
         const params = new URLSearchParams(window.location.search);
-        const response = await fetch('/mcp-oauth/authorize?' + params.toString(), {
+        const response = await fetch(withBase('/mcp-oauth/authorize?' + params.toString()), {
           credentials: 'same-origin',
           headers: {
             'Accept': 'application/json',
@@ -63,7 +65,7 @@ export function oauthConsent() {
       this.error = '';
 
       try {
-        const response = await fetch('/mcp-oauth/authorize', {
+        const response = await fetch(withBase('/mcp-oauth/authorize'), {
           method: 'POST',
           headers: { 'Content-Type': 'application/json' },
           credentials: 'same-origin',
@@ -100,7 +102,7 @@ export function oauthConsent() {
       this.loading = true;
 
       try {
-        const response = await fetch('/mcp-oauth/authorize', {
+        const response = await fetch(withBase('/mcp-oauth/authorize'), {
           method: 'POST',
           headers: { 'Content-Type': 'application/json' },
           credentials: 'same-origin',
@@ -2,6 +2,7 @@
 
 import type { IPStatus, Network, NetworkPool, Device, Reservation, CreateReservationRequest } from '../core/types';
 import { api, RackdAPIError } from '../core/api';
+import { withBase } from '../core/base-path';
 
 interface PoolDetailData {
   pool: NetworkPool | null;
@@ -209,9 +210,9 @@ export function poolDetail(): PoolDetailData {
       this.deleting = true;
       try {
         await api.deleteNetworkPool(this.pool.id);
-        window.location.href = this.network
+        window.location.href = withBase(this.network
           ? `/networks/detail?id=${this.network.id}`
-          : '/networks';
+          : '/networks');
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to delete pool';
         this.deleting = false;
@@ -420,7 +421,7 @@ export function poolForm(): PoolFormData {
         } else {
           await api.createNetworkPool(this.networkId, this.editPool);
         }
-        window.location.href = `/networks/detail?id=${this.networkId}`;
+        window.location.href = withBase(`/networks/detail?id=${this.networkId}`);
       } catch (e) {
         this.error = e instanceof RackdAPIError ? e.message : 'Failed to save pool';
       } finally {
@@ -429,9 +430,9 @@ export function poolForm(): PoolFormData {
     },
 
     cancel(): void {
-      window.location.href = this.networkId
+      window.location.href = withBase(this.networkId
         ? `/networks/detail?id=${this.networkId}`
-        : '/networks';
+        : '/networks');
     },
   };
 }
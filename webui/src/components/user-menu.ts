@@ -2,6 +2,7 @@
 
 import type { User, UpdateUserRequest, Permission, Role } from '../core/types';
 import { api, RackdAPIError } from '../core/api';
+import { withBase } from '../core/base-path';
 
 export function userMenu() {
   return {
@@ -223,7 +224,7 @@ export function userMenu() {
       } catch {
         // Continue with redirect even if server call fails
       }
-      window.location.href = '/login';
+      window.location.href = withBase('/login');
     },
 
     openPermissionsModal(): void {
@@ -5,6 +5,7 @@ import { api, RackdAPIError } from '../core/api';
 import { getPermissionsStore } from '../core/alpine';
 import { formatDate } from '../core/utils';
 import type { ListPageState } from '../core/page-state';
+import { withBase } from '../core/base-path';
 
 type ModalType = '' | 'create' | 'edit' | 'delete' | 'password' | 'reset-password' | 'roles';
 
@@ -651,7 +652,7 @@ export function usersList() {
       } catch {
         // Continue with redirect even if server call fails
       }
-      window.location.href = '/login';
+      window.location.href = withBase('/login');
     },
 
     formatDate: (dateString: string) => {
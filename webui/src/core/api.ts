@@ -42,6 +42,7 @@ import type {
   ScanProfile,
   SearchResult,
   ServiceInfo,
+  StructuredSearchResponse,
   UIConfig,
   UpdateReservationRequest,
   UpdateUserRequest,
@@ -115,6 +116,7 @@ export type {
   ScheduledScan,
   OAuthClient,
 } from './types';
+import { appPathname, basePath, withBase } from './base-path';
 
 export class RackdAPIError extends Error {
   constructor(
@@ -127,6 +129,13 @@ export class RackdAPIError extends Error {
   }
 }
 
+// ifMatch turns the updated_at of the copy being edited into an If-Match
+// header, so saving over someone else's change fails with PRECONDITION_FAILED
+// instead of silently overwriting it
+function ifMatch(resource: { updated_at?: string }): Record<string, string> | undefined {
+  return resource.updated_at ? { 'If-Match': `"${resource.updated_at}"` } : undefined;
+}
+
 export interface RackdAPIOptions {
   baseURL?: string;
 }
@@ -136,10 +145,10 @@ export class RackdAPI {
   private inFlightRequests: Map<string, Promise<unknown>> = new Map();
 
   constructor(options: RackdAPIOptions = {}) {
-    this.baseURL = options.baseURL || '';
+    this.baseURL = options.baseURL ?? basePath;
   }
 
-  private async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+  private async request<T>(method: string, path: string, body?: unknown, extraHeaders?: Record<string, string>): Promise<T> {
     // Only deduplicate GET requests
     const cacheKey = method === 'GET' ? `${method}:${path}` : null;
 
@@ -149,7 +158,8 @@ export class RackdAPI {
 
     const headers: Record<string, string> = {
       'Content-Type': 'application/json',
-      'X-Requested-With': 'XMLHttpRequest'
+      'X-Requested-With': 'XMLHttpRequest',
+      ...extraHeaders,
     };
 
     const requestPromise = (async () => {
@@ -179,8 +189,8 @@ export class RackdAPI {
         // Handle 401 Unauthorized
         if (response.status === 401) {
           // Redirect to login if not already there
-          if (window.location.pathname !== '/login') {
-            window.location.href = '/login';
+          if (appPathname() !== '/login') {
+            window.location.href = withBase('/login');
           }
         }
 
@@ -236,8 +246,8 @@ export class RackdAPI {
         throw new RackdAPIError('FORBIDDEN', message, error.details);
       }
 
-      if (response.status === 401 && window.location.pathname !== '/login') {
-        window.location.href = '/login';
+      if (response.status === 401 && appPathname() !== '/login') {
+        window.location.href = withBase('/login');
       }
 
       throw new RackdAPIError(error.code, error.message, error.details);
@@ -295,8 +305,11 @@ export class RackdAPI {
     if (filter?.network_id) params.set('network_id', filter.network_id);
     if (filter?.pool_id) params.set('pool_id', filter.pool_id);
     if (filter?.status) params.set('status', filter.status);
+    if (filter?.kind) params.set('kind', filter.kind);
+    if (filter?.host_id) params.set('host_id', filter.host_id);
     if (filter?.stale) params.set('stale', 'true');
     if (filter?.stale_days) params.set('stale_days', String(filter.stale_days));
+    if (filter?.warranty_expiring_within) params.set('warranty_expiring_within', filter.warranty_expiring_within);
     const query = params.toString();
     return this.request<Device[]>('GET', `/api/devices${query ? `?${query}` : ''}`);
   }
@@ -305,12 +318,16 @@ export class RackdAPI {
     return this.request<Device>('GET', `/api/devices/${id}`);
   }
 
+  async getDeviceVMs(id: string): Promise<Device[]> {
+    return this.request<Device[]>('GET', `/api/devices/${id}/vms`);
+  }
+
   async createDevice(device: Partial<Device>): Promise<Device> {
     return this.request<Device>('POST', '/api/devices', device);
   }
 
   async updateDevice(id: string, updates: Partial<Device>): Promise<Device> {
-    return this.request<Device>('PUT', `/api/devices/${id}`, updates);
+    return this.request<Device>('PUT', `/api/devices/${id}`, updates, ifMatch(updates));
   }
 
   async deleteDevice(id: string): Promise<void> {
@@ -330,6 +347,12 @@ export class RackdAPI {
     return response.results;
   }
 
+  // structuredSearch parses a structured query and runs it. An invalid query
+  // throws INVALID_QUERY with the start and end of the fault in details.
+  async structuredSearch(query: string): Promise<StructuredSearchResponse> {
+    return this.request<StructuredSearchResponse>('POST', '/api/search/structured', { query });
+  }
+
   // Relationships
   async addRelationship(deviceId: string, childId: string, type: DeviceRelationship['type'], notes?: string): Promise<void> {
     return this.request<void>('POST', `/api/devices/${deviceId}/relationships`, { child_id: childId, type, notes: notes || '' });
@@ -369,7 +392,7 @@ export class RackdAPI {
   }
 
   async updateDatacenter(id: string, updates: Partial<Datacenter>): Promise<Datacenter> {
-    return this.request<Datacenter>('PUT', `/api/datacenters/${id}`, updates);
+    return this.request<Datacenter>('PUT', `/api/datacenters/${id}`, updates, ifMatch(updates));
   }
 
   async deleteDatacenter(id: string): Promise<void> {
@@ -395,7 +418,7 @@ export class RackdAPI {
   }
 
   async updateNetwork(id: string, updates: Partial<Network>): Promise<Network> {
-    return this.request<Network>('PUT', `/api/networks/${id}`, updates);
+    return this.request<Network>('PUT', `/api/networks/${id}`, updates, ifMatch(updates));
   }
 
   async deleteNetwork(id: string): Promise<void> {
@@ -461,6 +484,10 @@ export class RackdAPI {
     return this.request<DiscoveryScan>('POST', `/api/discovery/scans/${id}/cancel`);
   }
 
+  getScanEventsURL(id: string): string {
+    return `${this.baseURL}/api/discovery/scans/${id}/events`;
+  }
+
   async listDiscoveredDevices(networkId?: string): Promise<DiscoveredDevice[]> {
     const query = networkId ? `?network_id=${networkId}` : '';
     return this.request<DiscoveredDevice[]>('GET', `/api/discovery/devices${query}`);
@@ -470,6 +497,10 @@ export class RackdAPI {
     return this.request<void>('DELETE', `/api/discovery/devices/${id}`);
   }
 
+  async ignoreDiscoveredDevice(id: string, reason?: string): Promise<DiscoveredDevice> {
+    return this.request<DiscoveredDevice>('POST', `/api/discovery/devices/${id}/ignore`, { reason: reason || '' });
+  }
+
   async promoteDevice(id: string, name: string, datacenterId?: string, makeModel?: string): Promise<Device> {
     const body: any = { name };
     if (datacenterId) body.datacenter_id = datacenterId;
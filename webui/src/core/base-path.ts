@@ -0,0 +1,29 @@
+// Base path the UI is served under, such as /rackd behind a reverse proxy.
+// The server writes it into the rackd-base-path meta tag of index.html.
+
+function readBasePath(): string {
+  if (typeof document === 'undefined') return '';
+  const meta = document.querySelector('meta[name="rackd-base-path"]');
+  return (meta?.getAttribute('content') || '').replace(/\/+$/, '');
+}
+
+export const basePath = readBasePath();
+
+// withBase turns an app path such as /devices into a browser URL
+export function withBase(path: string): string {
+  return basePath + path;
+}
+
+// appPath returns the current app path and query, without the base path
+export function appPath(): string {
+  let path = window.location.pathname;
+  if (basePath && (path === basePath || path.startsWith(basePath + '/'))) {
+    path = path.slice(basePath.length) || '/';
+  }
+  return path + window.location.search;
+}
+
+// appPathname returns the current app path, without the base path or query
+export function appPathname(): string {
+  return appPath().split('?')[0];
+}
@@ -13,7 +13,9 @@ export interface Address {
   pool_id?: string;
 }
 
-export type DeviceStatus = 'planned' | 'active' | 'maintenance' | 'decommissioned';
+export type DeviceStatus = 'planned' | 'ordered' | 'active' | 'maintenance' | 'decommissioned';
+
+export type DeviceKind = 'physical' | 'vm' | 'container_host' | 'network' | 'storage' | 'pdu' | 'other';
 
 export interface Device {
   id: string;
@@ -25,10 +27,14 @@ export interface Device {
   datacenter_id?: string;
   username?: string;
   location?: string;
+  kind: DeviceKind;
   status: DeviceStatus;
   decommission_date?: string;
   status_changed_at?: string;
   status_changed_by?: string;
+  purchase_date?: string;
+  warranty_expiry?: string;
+  end_of_life?: string;
   tags: string[];
   addresses: Address[];
   domains: string[];
@@ -43,12 +49,16 @@ export interface DeviceFilter {
   network_id?: string;
   pool_id?: string;
   status?: DeviceStatus;
+  kind?: DeviceKind;
+  host_id?: string;
   stale?: boolean;
   stale_days?: number;
+  warranty_expiring_within?: string;
 }
 
 export interface DeviceStatusCounts {
   planned: number;
+  ordered: number;
   active: number;
   maintenance: number;
   decommissioned: number;
@@ -69,6 +79,7 @@ export interface Network {
   subnet: string;
   vlan_id: number;
   datacenter_id: string;
+  parent_id?: string;
   description: string;
   created_at: string;
   updated_at: string;
@@ -90,9 +101,13 @@ export interface NetworkPool {
 
 export interface NetworkUtilization {
   network_id: string;
+  family: string;
+  prefix_length: number;
   total_ips: number;
   used_ips: number;
   available_ips: number;
+  total_addresses: string;
+  available_addresses: string;
   utilization: number;
 }
 
@@ -135,6 +150,7 @@ export interface Reservation {
   id: string;
   pool_id: string;
   ip_address: string;
+  device_id?: string;
   hostname?: string;
   purpose?: string;
   reserved_by: string;
@@ -169,11 +185,29 @@ export interface UpdateReservationRequest {
   notes?: string;
 }
 
+export interface TLSCertificate {
+  common_name: string;
+  sans?: string[];
+  issuer: string;
+  not_before: string;
+  not_after: string;
+}
+
 export interface ServiceInfo {
   port: number;
   protocol: string;
   service: string;
   version: string;
+  http_server?: string;
+  http_title?: string;
+  certificate?: TLSCertificate;
+}
+
+export interface ConfidenceFactor {
+  factor: string;
+  points: number;
+  max: number;
+  detail?: string;
 }
 
 export interface DiscoveredDevice {
@@ -184,6 +218,7 @@ export interface DiscoveredDevice {
   network_id: string;
   status: string;
   confidence: number;
+  confidence_breakdown?: ConfidenceFactor[];
   os_guess: string;
   vendor: string;
   open_ports: number[];
@@ -481,6 +516,24 @@ export interface SearchResult {
   datacenter?: Datacenter;
 }
 
+// A node of a parsed structured query. start and end are character offsets
+// in the query, end exclusive.
+export interface QueryNode {
+  type: 'and' | 'or' | 'not' | 'term';
+  field?: string;
+  value?: string;
+  children?: QueryNode[];
+  start: number;
+  end: number;
+}
+
+export interface StructuredSearchResponse {
+  query: string;
+  ast: QueryNode;
+  count: number;
+  devices: Device[];
+}
+
 // Dashboard types
 export interface RecentDiscovery {
   id: string;
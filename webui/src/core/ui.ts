@@ -1,3 +1,5 @@
+import { withBase } from './base-path';
+
 export type ModalSize = 'md' | 'lg' | '2xl' | '4xl';
 
 const modalSizeClasses: Record<ModalSize, string> = {
@@ -12,6 +14,7 @@ export interface UIStore {
   modalBackdrop(): string;
   modalPanel(size?: ModalSize, scrollable?: boolean): string;
   modalCloseButton(): string;
+  href(path: string): string;
 }
 
 export function createUIStore(): UIStore {
@@ -46,5 +49,11 @@ export function createUIStore(): UIStore {
     modalCloseButton(): string {
       return 'absolute top-4 right-4 text-gray-600 hover:text-gray-800 dark:text-gray-300 dark:hover:text-gray-100 focus:outline-none focus:ring-[3px] focus:ring-blue-500 rounded-full p-2 cursor-pointer transition-colors min-w-[44px] min-h-[44px] flex items-center justify-center';
     },
+
+    // href turns an app path into a link under the base path; anchors and
+    // external URLs are left alone
+    href(path: string): string {
+      return path.startsWith('/') && !path.startsWith('//') ? withBase(path) : path;
+    },
   };
 }